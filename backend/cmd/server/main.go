@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"breakoutglobe/internal/config"
 	"breakoutglobe/internal/server"
@@ -10,16 +13,59 @@ import (
 
 func main() {
 	cfg := config.Load()
-	
+
 	srv := server.New(cfg)
-	
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8080"
 	}
-	
+
+	// Deployments stop the old instance with SIGTERM
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+	// Rolling restarts drain the instance first, by SIGUSR1 or POST /api/internal/drain
+	drain := make(chan os.Signal, 1)
+	signal.Notify(drain, syscall.SIGUSR1)
+	go func() {
+		for range drain {
+			srv.RequestDrain()
+		}
+	}()
+
 	log.Printf("Starting server on port %s", port)
-	if err := srv.Start(":" + port); err != nil {
-		log.Fatal("Failed to start server:", err)
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Start(":" + port)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			log.Fatal("Failed to start server:", err)
+		}
+		return
+	case sig := <-stop:
+		log.Printf("Received %s, shutting down", sig)
+	case <-srv.DrainRequested():
+		log.Printf("Draining WebSocket clients over %s for a rolling restart", cfg.DrainWindow)
+		drainCtx, cancel := context.WithTimeout(context.Background(), cfg.DrainTimeout)
+		if err := srv.Drain(drainCtx); err != nil {
+			log.Printf("⚠️ Drain deadline reached with clients still connected: %v", err)
+		}
+		cancel()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("⚠️ Shutdown did not complete: %v", err)
+	}
+
+	// Start returns after the scheduler and exporters are stopped
+	if err := <-done; err != nil {
+		log.Printf("⚠️ Server stopped with error: %v", err)
 	}
-}
\ No newline at end of file
+	log.Println("Server stopped")
+}
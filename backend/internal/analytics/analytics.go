@@ -0,0 +1,165 @@
+// Package analytics reports product events to a configurable analytics
+// service, skipping users who asked not to be tracked
+package analytics
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Product events
+const (
+	// EventMapJoined is sent when a user starts a session on a map
+	EventMapJoined = "map_joined"
+	// EventPOICreated is sent when a user creates a POI
+	EventPOICreated = "poi_created"
+	// EventPOILeft is sent when a participant leaves a POI, with how long
+	// they stayed as dwellSeconds if known
+	EventPOILeft = "poi_left"
+	// EventCallStarted is sent to both participants when a call is accepted
+	EventCallStarted = "call_started"
+	// EventCallMissed is sent to the called user when a call rang out
+	// without an answer
+	EventCallMissed = "call_missed"
+)
+
+const (
+	// trackBufferSize bounds the events waiting for the sink; more are dropped
+	trackBufferSize = 1024
+	// flushInterval is how often batching sinks are flushed
+	flushInterval = 5 * time.Second
+	// consentTimeout bounds looking up whether a user may be tracked
+	consentTimeout = 5 * time.Second
+)
+
+// Event is one product event
+type Event struct {
+	Name       string                 `json:"event"`
+	UserID     string                 `json:"userId"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+	Time       time.Time              `json:"time"`
+}
+
+// Sink sends events to an analytics service
+type Sink interface {
+	Send(event Event) error
+	Close() error
+}
+
+// Flusher is a sink that batches events
+type Flusher interface {
+	Flush() error
+}
+
+// ConsentChecker reports whether a user opted out of tracking
+type ConsentChecker interface {
+	DoNotTrack(ctx context.Context, userID string) (bool, error)
+}
+
+// Tracker sends events to a sink in the background, so tracking never blocks
+// requests
+type Tracker struct {
+	sink    Sink
+	consent ConsentChecker
+	events  chan Event
+	done    chan struct{}
+	once    sync.Once
+	logger  *slog.Logger
+}
+
+// NewTracker creates a tracker sending to the sink
+func NewTracker(sink Sink) *Tracker {
+	t := &Tracker{
+		sink:   sink,
+		events: make(chan Event, trackBufferSize),
+		done:   make(chan struct{}),
+		logger: slog.Default(),
+	}
+	go t.run()
+	return t
+}
+
+// SetConsentChecker sets how users' do-not-track preference is looked up.
+// Without a checker every user is tracked.
+func (t *Tracker) SetConsentChecker(consent ConsentChecker) {
+	t.consent = consent
+}
+
+// Track queues an event of a user. It is dropped if the user opted out of
+// tracking or the sink can't keep up.
+func (t *Tracker) Track(name, userID string, properties map[string]interface{}) {
+	// Nothing is sent, so don't look up consent either
+	if _, ok := t.sink.(NopSink); ok {
+		return
+	}
+
+	select {
+	case t.events <- Event{Name: name, UserID: userID, Properties: properties, Time: time.Now().UTC()}:
+	default:
+		t.logger.Warn("Analytics buffer full, dropping event", "event", name)
+	}
+}
+
+// Close sends the queued events and closes the sink
+func (t *Tracker) Close() error {
+	t.once.Do(func() {
+		close(t.events)
+	})
+	<-t.done
+	return t.sink.Close()
+}
+
+func (t *Tracker) run() {
+	defer close(t.done)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-t.events:
+			if !ok {
+				t.flush()
+				return
+			}
+			if !t.allowed(event.UserID) {
+				continue
+			}
+			if err := t.sink.Send(event); err != nil {
+				t.logger.Warn("Failed to send analytics event", "event", event.Name, "error", err)
+			}
+		case <-ticker.C:
+			t.flush()
+		}
+	}
+}
+
+// allowed reports whether events of the user may be sent. Users whose
+// preference can't be read aren't tracked.
+func (t *Tracker) allowed(userID string) bool {
+	if t.consent == nil {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), consentTimeout)
+	defer cancel()
+
+	doNotTrack, err := t.consent.DoNotTrack(ctx, userID)
+	if err != nil {
+		t.logger.Warn("Failed to check do-not-track preference, dropping event", "userId", userID, "error", err)
+		return false
+	}
+	return !doNotTrack
+}
+
+func (t *Tracker) flush() {
+	flusher, ok := t.sink.(Flusher)
+	if !ok {
+		return
+	}
+	if err := flusher.Flush(); err != nil {
+		t.logger.Warn("Failed to flush analytics events", "error", err)
+	}
+}
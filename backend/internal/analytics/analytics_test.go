@@ -0,0 +1,112 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingSink struct {
+	mutex  sync.Mutex
+	events []Event
+}
+
+func (s *recordingSink) Send(event Event) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *recordingSink) Close() error {
+	return nil
+}
+
+type fakeConsent map[string]bool
+
+func (c fakeConsent) DoNotTrack(ctx context.Context, userID string) (bool, error) {
+	doNotTrack, known := c[userID]
+	if !known {
+		return false, errors.New("user not found")
+	}
+	return doNotTrack, nil
+}
+
+func TestTracker_RespectsDoNotTrack(t *testing.T) {
+	sink := &recordingSink{}
+	tracker := NewTracker(sink)
+	tracker.SetConsentChecker(fakeConsent{"tracked": false, "opted-out": true})
+
+	tracker.Track(EventMapJoined, "tracked", map[string]interface{}{"mapId": "map-1"})
+	tracker.Track(EventMapJoined, "opted-out", map[string]interface{}{"mapId": "map-1"})
+	// Users whose preference can't be read aren't tracked
+	tracker.Track(EventMapJoined, "unknown", nil)
+	require.NoError(t, tracker.Close())
+
+	require.Len(t, sink.events, 1)
+	assert.Equal(t, EventMapJoined, sink.events[0].Name)
+	assert.Equal(t, "tracked", sink.events[0].UserID)
+	assert.Equal(t, "map-1", sink.events[0].Properties["mapId"])
+}
+
+func TestPostHogSink_PostsBatches(t *testing.T) {
+	var batches []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		batches = append(batches, body)
+	}))
+	defer server.Close()
+
+	sink := NewPostHogSink(server.URL, "phc_key")
+	for i := 0; i < httpBatchSize+1; i++ {
+		require.NoError(t, sink.Send(Event{Name: EventPOICreated, UserID: "user-1"}))
+	}
+	require.NoError(t, sink.Close())
+
+	require.Len(t, batches, 2)
+	assert.Equal(t, "phc_key", batches[0]["api_key"])
+	assert.Len(t, batches[0]["batch"], httpBatchSize)
+	event := batches[1]["batch"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, EventPOICreated, event["event"])
+	assert.Equal(t, "user-1", event["distinct_id"])
+}
+
+func TestSegmentSink_AuthorizesWithWriteKey(t *testing.T) {
+	var body map[string]interface{}
+	var writeKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeKey, _, _ = r.BasicAuth()
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+	}))
+	defer server.Close()
+
+	sink := NewSegmentSink(server.URL, "write-key")
+	require.NoError(t, sink.Send(Event{Name: EventCallStarted, UserID: "user-1"}))
+	require.NoError(t, sink.Flush())
+
+	assert.Equal(t, "write-key", writeKey)
+	event := body["batch"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "track", event["type"])
+	assert.Equal(t, EventCallStarted, event["event"])
+	assert.Equal(t, "user-1", event["userId"])
+}
+
+func TestHTTPSink_DropsRejectedBatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	sink := NewSegmentSink(server.URL, "wrong-key")
+	require.NoError(t, sink.Send(Event{Name: EventMapJoined}))
+	assert.Error(t, sink.Flush())
+	assert.Empty(t, sink.pending)
+}
@@ -0,0 +1,168 @@
+package analytics
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// httpBatchSize is how many events are sent in one request
+	httpBatchSize = 100
+	// DefaultPostHogURL is the batch endpoint of PostHog Cloud
+	DefaultPostHogURL = "https://us.i.posthog.com/batch/"
+	// DefaultSegmentURL is the batch endpoint of Segment
+	DefaultSegmentURL = "https://api.segment.io/v1/batch"
+)
+
+// NopSink discards events, for deployments without product analytics
+type NopSink struct{}
+
+// Send discards the event
+func (NopSink) Send(event Event) error {
+	return nil
+}
+
+// Close does nothing
+func (NopSink) Close() error {
+	return nil
+}
+
+// LogSink logs events, to check what would be sent before picking a service
+type LogSink struct {
+	logger *slog.Logger
+}
+
+// NewLogSink creates a sink logging to logger
+func NewLogSink(logger *slog.Logger) *LogSink {
+	return &LogSink{logger: logger}
+}
+
+// Send logs the event
+func (s *LogSink) Send(event Event) error {
+	s.logger.Info("📊 Analytics event", "event", event.Name, "userId", event.UserID, "properties", event.Properties)
+	return nil
+}
+
+// Close does nothing; the logger is owned by the caller
+func (s *LogSink) Close() error {
+	return nil
+}
+
+// HTTPSink posts batches of events to an analytics service like PostHog or
+// Segment
+type HTTPSink struct {
+	url       string
+	client    *http.Client
+	encode    func(batch []Event) ([]byte, error)
+	authorize func(req *http.Request)
+	pending   []Event
+	mutex     sync.Mutex
+}
+
+// NewPostHogSink creates a sink posting to a PostHog batch endpoint with the
+// project API key
+func NewPostHogSink(url, apiKey string) *HTTPSink {
+	return &HTTPSink{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+		encode: func(batch []Event) ([]byte, error) {
+			type posthogEvent struct {
+				Event      string                 `json:"event"`
+				DistinctID string                 `json:"distinct_id"`
+				Properties map[string]interface{} `json:"properties,omitempty"`
+				Timestamp  time.Time              `json:"timestamp"`
+			}
+			events := make([]posthogEvent, len(batch))
+			for i, event := range batch {
+				events[i] = posthogEvent{Event: event.Name, DistinctID: event.UserID, Properties: event.Properties, Timestamp: event.Time}
+			}
+			return json.Marshal(map[string]interface{}{"api_key": apiKey, "batch": events})
+		},
+		authorize: func(req *http.Request) {},
+	}
+}
+
+// NewSegmentSink creates a sink posting to a Segment batch endpoint with the
+// source write key
+func NewSegmentSink(url, writeKey string) *HTTPSink {
+	return &HTTPSink{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+		encode: func(batch []Event) ([]byte, error) {
+			type segmentEvent struct {
+				Type       string                 `json:"type"`
+				Event      string                 `json:"event"`
+				UserID     string                 `json:"userId"`
+				Properties map[string]interface{} `json:"properties,omitempty"`
+				Timestamp  time.Time              `json:"timestamp"`
+			}
+			events := make([]segmentEvent, len(batch))
+			for i, event := range batch {
+				events[i] = segmentEvent{Type: "track", Event: event.Name, UserID: event.UserID, Properties: event.Properties, Timestamp: event.Time}
+			}
+			return json.Marshal(map[string]interface{}{"batch": events})
+		},
+		authorize: func(req *http.Request) {
+			req.SetBasicAuth(writeKey, "")
+		},
+	}
+}
+
+// Send buffers an event and sends the batch once it is full
+func (s *HTTPSink) Send(event Event) error {
+	s.mutex.Lock()
+	s.pending = append(s.pending, event)
+	full := len(s.pending) >= httpBatchSize
+	s.mutex.Unlock()
+
+	if full {
+		return s.Flush()
+	}
+	return nil
+}
+
+// Flush sends the buffered events. They are dropped if the service rejects
+// them, so an unavailable service can't exhaust memory.
+func (s *HTTPSink) Flush() error {
+	s.mutex.Lock()
+	batch := s.pending
+	s.pending = nil
+	s.mutex.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	body, err := s.encode(batch)
+	if err != nil {
+		return fmt.Errorf("failed to encode analytics events: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create analytics request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	s.authorize(req)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send %d analytics events: %w", len(batch), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("analytics service rejected %d events with status %d", len(batch), resp.StatusCode)
+	}
+	return nil
+}
+
+// Close sends the remaining events
+func (s *HTTPSink) Close() error {
+	return s.Flush()
+}
@@ -20,8 +20,19 @@ func RunMigrations(db *gorm.DB) error {
 	err := db.AutoMigrate(
 		&models.User{}, // Must be first since other models reference it
 		&models.Map{},
+		&models.MapSnapshot{},
 		&models.Session{},
 		&models.POI{},
+		&models.UploadReference{},
+		&models.MapHeatCell{},
+		&models.DigestSubscription{},
+		&models.Invitation{},
+		&models.POIRSVP{},
+		&models.UserPreference{},
+		&models.UserOnboardingStep{},
+		&models.AuthSession{},
+		&models.ConnectionError{},
+		&models.MapUsage{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to run auto-migrations: %w", err)
@@ -59,6 +70,16 @@ func CreateIndexes(db *gorm.DB) error {
 		return fmt.Errorf("failed to create sessions last_active index: %w", err)
 	}
 
+	// A user can only have one active session per map. Duplicates left behind by
+	// concurrent session creation are deactivated first so the index can be built.
+	if err := DeactivateDuplicateSessions(db); err != nil {
+		return fmt.Errorf("failed to deactivate duplicate sessions: %w", err)
+	}
+
+	if err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_sessions_active_user_map ON sessions (user_id, map_id) WHERE is_active = true AND deleted_at IS NULL").Error; err != nil {
+		return fmt.Errorf("failed to create sessions active user/map unique index: %w", err)
+	}
+
 	// POI indexes
 	if err := db.Exec("CREATE INDEX IF NOT EXISTS idx_pois_map_id ON pois (map_id)").Error; err != nil {
 		return fmt.Errorf("failed to create pois map_id index: %w", err)
@@ -86,6 +107,37 @@ func CreateIndexes(db *gorm.DB) error {
 	return nil
 }
 
+// DeactivateDuplicateSessions keeps only the most recently active session per
+// user and map active, deactivating all others
+func DeactivateDuplicateSessions(db *gorm.DB) error {
+	if db == nil {
+		return fmt.Errorf("database connection is nil")
+	}
+
+	result := db.Exec(`
+		UPDATE sessions SET is_active = false
+		WHERE id IN (
+			SELECT id FROM (
+				SELECT id, ROW_NUMBER() OVER (
+					PARTITION BY user_id, map_id
+					ORDER BY last_active DESC, created_at DESC
+				) AS row_rank
+				FROM sessions
+				WHERE is_active = true AND deleted_at IS NULL
+			) ranked
+			WHERE ranked.row_rank > 1
+		)`)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		log.Printf("⚠️ Deactivated %d duplicate active sessions", result.RowsAffected)
+	}
+
+	return nil
+}
+
 // CreateDefaultMapIfNotExists creates the default map if it doesn't exist
 func CreateDefaultMapIfNotExists(db *gorm.DB) error {
 	if db == nil {
@@ -160,6 +212,16 @@ func DropAllTables(db *gorm.DB) error {
 
 	// Drop tables in reverse dependency order
 	tables := []interface{}{
+		&models.MapUsage{},
+		&models.ConnectionError{},
+		&models.AuthSession{},
+		&models.UserOnboardingStep{},
+		&models.UserPreference{},
+		&models.POIRSVP{},
+		&models.Invitation{},
+		&models.DigestSubscription{},
+		&models.MapHeatCell{},
+		&models.UploadReference{},
 		&models.POI{},     // Has foreign key to maps and users
 		&models.Session{}, // Has foreign key to maps and users
 		&models.Map{},     // Has foreign key to users
@@ -197,6 +259,14 @@ func GetMigrationStatus(db *gorm.DB) (map[string]bool, error) {
 	status["maps"] = db.Migrator().HasTable(&models.Map{})
 	status["sessions"] = db.Migrator().HasTable(&models.Session{})
 	status["pois"] = db.Migrator().HasTable(&models.POI{})
+	status["upload_references"] = db.Migrator().HasTable(&models.UploadReference{})
+	status["map_heat_cells"] = db.Migrator().HasTable(&models.MapHeatCell{})
+	status["digest_subscriptions"] = db.Migrator().HasTable(&models.DigestSubscription{})
+	status["invitations"] = db.Migrator().HasTable(&models.Invitation{})
+	status["poi_rsvps"] = db.Migrator().HasTable(&models.POIRSVP{})
+	status["user_preferences"] = db.Migrator().HasTable(&models.UserPreference{})
+	status["user_onboarding_steps"] = db.Migrator().HasTable(&models.UserOnboardingStep{})
+	status["map_usage"] = db.Migrator().HasTable(&models.MapUsage{})
 
 	// Check if indexes exist
 	status["idx_sessions_user_id"] = db.Migrator().HasIndex(&models.Session{}, "idx_sessions_user_id")
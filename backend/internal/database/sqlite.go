@@ -0,0 +1,82 @@
+package database
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// DialectSQLite is the dialector name of SQLite connections
+const DialectSQLite = "sqlite"
+
+// sqlitePragmas are run on the connection. The exclusive lock keeps a second
+// server from opening the same file, since real-time state isn't shared
+// between instances.
+var sqlitePragmas = []string{
+	"busy_timeout(5000)",
+	"foreign_keys(1)",
+	"locking_mode(EXCLUSIVE)",
+	"journal_mode(WAL)",
+}
+
+// NewSQLiteConnection opens the SQLite database file at path, creating it if
+// it doesn't exist
+func NewSQLiteConnection(path string) (*gorm.DB, error) {
+	if path == "" {
+		return nil, fmt.Errorf("database path is required")
+	}
+
+	query := url.Values{}
+	for _, pragma := range sqlitePragmas {
+		query.Add("_pragma", pragma)
+	}
+	query.Set("_txlock", "immediate")
+
+	config := &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Warn),
+		NowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+	}
+
+	db, err := gorm.Open(sqlite.Open(path+"?"+query.Encode()), config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get underlying sql.DB: %w", err)
+	}
+	// The exclusive lock belongs to a connection, so all queries share one
+	sqlDB.SetMaxOpenConns(1)
+	sqlDB.SetConnMaxLifetime(0)
+	sqlDB.SetConnMaxIdleTime(0)
+
+	// Take the lock now rather than on the first write
+	if err := db.Exec("BEGIN IMMEDIATE; COMMIT").Error; err != nil {
+		CloseConnection(db)
+		return nil, fmt.Errorf("database %s is in use, is another server running on it? %w", path, err)
+	}
+
+	return db, nil
+}
+
+// InitializeSQLite opens the SQLite database file at path and runs migrations
+func InitializeSQLite(path string) (*gorm.DB, error) {
+	db, err := NewSQLiteConnection(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := RunMigrations(db); err != nil {
+		CloseConnection(db)
+		return nil, err
+	}
+
+	return db, nil
+}
@@ -0,0 +1,148 @@
+// Package geoip derives approximate locations from client IP addresses.
+package geoip
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"breakoutglobe/internal/models"
+)
+
+// ErrLocationUnknown is returned when no location is known for an IP address,
+// e.g. for private or loopback addresses
+var ErrLocationUnknown = errors.New("location unknown")
+
+// DefaultMaxMindHost is the host of MaxMind's free GeoLite2 web service
+const DefaultMaxMindHost = "geolite.info"
+
+const (
+	maxMindTimeout      = 2 * time.Second
+	maxMindCacheTTL     = 24 * time.Hour
+	maxMindCacheEntries = 10000
+)
+
+// MaxMindProvider looks up IP locations with the MaxMind GeoIP2/GeoLite2 City web service.
+// Lookups are cached since clients reconnect from the same address.
+type MaxMindProvider struct {
+	accountID  string
+	licenseKey string
+	baseURL    string
+	client     *http.Client
+
+	mutex sync.Mutex
+	cache map[string]cachedLocation
+	now   func() time.Time
+}
+
+type cachedLocation struct {
+	location  models.LatLng
+	err       error
+	expiresAt time.Time
+}
+
+// NewMaxMindProvider creates a provider for the web service at host, e.g.
+// DefaultMaxMindHost or "geoip.maxmind.com" for the paid GeoIP2 service
+func NewMaxMindProvider(accountID, licenseKey, host string) *MaxMindProvider {
+	return &MaxMindProvider{
+		accountID:  accountID,
+		licenseKey: licenseKey,
+		baseURL:    "https://" + host,
+		client:     &http.Client{Timeout: maxMindTimeout},
+		cache:      make(map[string]cachedLocation),
+		now:        time.Now,
+	}
+}
+
+// maxMindCityResponse is the part of the city response we use
+type maxMindCityResponse struct {
+	Location struct {
+		Latitude  *float64 `json:"latitude"`
+		Longitude *float64 `json:"longitude"`
+	} `json:"location"`
+}
+
+// Locate returns the approximate location of an IP address
+func (p *MaxMindProvider) Locate(ctx context.Context, ip net.IP) (models.LatLng, error) {
+	if ip == nil || ip.IsPrivate() || ip.IsLoopback() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() {
+		return models.LatLng{}, ErrLocationUnknown
+	}
+
+	key := ip.String()
+	if location, err, ok := p.cached(key); ok {
+		return location, err
+	}
+
+	location, err := p.lookup(ctx, key)
+
+	// Cache unknown addresses too, but not transient failures
+	if err == nil || errors.Is(err, ErrLocationUnknown) {
+		p.store(key, location, err)
+	}
+
+	return location, err
+}
+
+func (p *MaxMindProvider) lookup(ctx context.Context, ip string) (models.LatLng, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/geoip/v2.1/city/"+ip, nil)
+	if err != nil {
+		return models.LatLng{}, fmt.Errorf("failed to create GeoIP request: %w", err)
+	}
+	req.SetBasicAuth(p.accountID, p.licenseKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return models.LatLng{}, fmt.Errorf("GeoIP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return models.LatLng{}, ErrLocationUnknown
+	}
+	if resp.StatusCode != http.StatusOK {
+		return models.LatLng{}, fmt.Errorf("GeoIP request failed with status %d", resp.StatusCode)
+	}
+
+	var city maxMindCityResponse
+	if err := json.NewDecoder(resp.Body).Decode(&city); err != nil {
+		return models.LatLng{}, fmt.Errorf("failed to decode GeoIP response: %w", err)
+	}
+	if city.Location.Latitude == nil || city.Location.Longitude == nil {
+		return models.LatLng{}, ErrLocationUnknown
+	}
+
+	location := models.LatLng{Lat: *city.Location.Latitude, Lng: *city.Location.Longitude}
+	if err := location.Validate(); err != nil {
+		return models.LatLng{}, ErrLocationUnknown
+	}
+
+	return location, nil
+}
+
+func (p *MaxMindProvider) cached(key string) (models.LatLng, error, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	entry, exists := p.cache[key]
+	if !exists || p.now().After(entry.expiresAt) {
+		return models.LatLng{}, nil, false
+	}
+	return entry.location, entry.err, true
+}
+
+func (p *MaxMindProvider) store(key string, location models.LatLng, err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	// Start over rather than tracking recency; lookups are cheap to repeat
+	if len(p.cache) >= maxMindCacheEntries {
+		p.cache = make(map[string]cachedLocation)
+	}
+	p.cache[key] = cachedLocation{location: location, err: err, expiresAt: p.now().Add(maxMindCacheTTL)}
+}
@@ -0,0 +1,76 @@
+package geoip
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestProvider(t *testing.T, handler http.HandlerFunc) (*MaxMindProvider, *int) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	provider := NewMaxMindProvider("account", "key", strings.TrimPrefix(server.URL, "https://"))
+	provider.baseURL = server.URL
+	return provider, &requests
+}
+
+func TestMaxMindProvider_Locate(t *testing.T) {
+	provider, requests := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "account", user)
+		assert.Equal(t, "key", pass)
+		assert.Equal(t, "/geoip/v2.1/city/81.2.69.160", r.URL.Path)
+		w.Write([]byte(`{"location":{"latitude":51.5142,"longitude":-0.0931,"accuracy_radius":20}}`))
+	})
+
+	location, err := provider.Locate(context.Background(), net.ParseIP("81.2.69.160"))
+	require.NoError(t, err)
+	assert.Equal(t, models.LatLng{Lat: 51.5142, Lng: -0.0931}, location)
+
+	// Repeated lookups are served from the cache
+	_, err = provider.Locate(context.Background(), net.ParseIP("81.2.69.160"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, *requests)
+}
+
+func TestMaxMindProvider_PrivateAddress(t *testing.T) {
+	provider, requests := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {})
+
+	for _, ip := range []string{"10.0.0.1", "127.0.0.1", "::1", "192.168.1.20"} {
+		_, err := provider.Locate(context.Background(), net.ParseIP(ip))
+		assert.ErrorIs(t, err, ErrLocationUnknown, ip)
+	}
+	assert.Equal(t, 0, *requests)
+}
+
+func TestMaxMindProvider_UnknownAndFailedLookups(t *testing.T) {
+	status := http.StatusNotFound
+	provider, requests := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	})
+
+	_, err := provider.Locate(context.Background(), net.ParseIP("8.8.8.8"))
+	assert.ErrorIs(t, err, ErrLocationUnknown)
+
+	// Server errors are not cached
+	status = http.StatusServiceUnavailable
+	_, err = provider.Locate(context.Background(), net.ParseIP("1.1.1.1"))
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrLocationUnknown)
+	_, _ = provider.Locate(context.Background(), net.ParseIP("1.1.1.1"))
+	assert.Equal(t, 3, *requests)
+}
@@ -2,9 +2,12 @@ package handlers
 
 import (
 	"context"
+	"fmt"
+	"net"
 	"net/http"
 	"time"
 
+	"breakoutglobe/internal/middleware"
 	"breakoutglobe/internal/models"
 	"breakoutglobe/internal/services"
 
@@ -14,6 +17,7 @@ import (
 // AuthServiceInterface defines the interface for authentication operations
 type AuthServiceInterface interface {
 	GenerateJWT(userID, email string, role models.UserRole) (string, time.Time, error)
+	GenerateSessionJWT(userID, email string, role models.UserRole, sessionID string) (string, time.Time, error)
 	ValidateJWT(token string) (*services.JWTClaims, error)
 }
 
@@ -25,11 +29,29 @@ type AuthUserServiceInterface interface {
 	VerifyPassword(ctx context.Context, userID, password string) error
 }
 
+// AuthPreferenceServiceInterface defines the interface for loading preferences with the current user
+type AuthPreferenceServiceInterface interface {
+	GetPreferences(ctx context.Context, userID string) (models.Preferences, error)
+}
+
+// AuthOnboardingServiceInterface defines the interface for loading the welcome checklist with the current user
+type AuthOnboardingServiceInterface interface {
+	GetProgress(ctx context.Context, userID string) (models.OnboardingProgress, error)
+}
+
+// AuthSessionStarter defines the interface for recording sign-ins
+type AuthSessionStarter interface {
+	StartSession(ctx context.Context, user *models.User, userAgent string, ip net.IP) (*models.AuthSession, error)
+}
+
 // AuthHandler handles authentication-related HTTP requests
 type AuthHandler struct {
-	authService AuthServiceInterface
-	userService AuthUserServiceInterface
-	rateLimiter services.RateLimiterInterface
+	authService       AuthServiceInterface
+	userService       AuthUserServiceInterface
+	rateLimiter       services.RateLimiterInterface
+	preferenceService AuthPreferenceServiceInterface
+	onboardingService AuthOnboardingServiceInterface
+	sessionService    AuthSessionStarter
 }
 
 // NewAuthHandler creates a new AuthHandler instance
@@ -41,6 +63,36 @@ func NewAuthHandler(authService AuthServiceInterface, userService AuthUserServic
 	}
 }
 
+// SetPreferenceService sets the service whose preferences are included in the
+// current user response
+func (h *AuthHandler) SetPreferenceService(preferenceService AuthPreferenceServiceInterface) {
+	h.preferenceService = preferenceService
+}
+
+// SetOnboardingService sets the service whose welcome checklist progress is
+// included in the current user response
+func (h *AuthHandler) SetOnboardingService(onboardingService AuthOnboardingServiceInterface) {
+	h.onboardingService = onboardingService
+}
+
+// SetSessionService records every signup and login as a sign-in that can be
+// listed and revoked. Without it, tokens are only invalidated by expiring.
+func (h *AuthHandler) SetSessionService(sessionService AuthSessionStarter) {
+	h.sessionService = sessionService
+}
+
+// RegisterRoutes registers auth routes; authMiddleware guards the current user
+// endpoint. Signup and login attempts are rate limited per email.
+func (h *AuthHandler) RegisterRoutes(router *gin.Engine, authMiddleware ...gin.HandlerFunc) {
+	auth := router.Group("/api/auth")
+	{
+		auth.POST("/signup", rateLimited(h.rateLimiter, services.ActionCreatePOI, middleware.RateLimitByField("signup:", "email"), nil, h.Signup)...)
+		auth.POST("/login", rateLimited(h.rateLimiter, services.ActionCreatePOI, middleware.RateLimitByField("login:", "email"), nil, h.Login)...)
+		auth.POST("/logout", h.Logout)
+		auth.GET("/me", append(authMiddleware, h.GetCurrentUser)...)
+	}
+}
+
 // Request/Response DTOs
 
 // SignupRequest represents the request body for user signup
@@ -74,6 +126,11 @@ type UserResponse struct {
 	AvatarURL   string `json:"avatarUrl,omitempty"`
 	AboutMe     string `json:"aboutMe,omitempty"`
 	CreatedAt   string `json:"createdAt"`
+
+	// Preferences are only included in the current user response
+	Preferences models.Preferences `json:"preferences,omitempty"`
+	// Onboarding is the welcome checklist progress, only included in the current user response
+	Onboarding *models.OnboardingProgress `json:"onboarding,omitempty"`
 }
 
 // Signup handles POST /api/auth/signup
@@ -88,12 +145,6 @@ func (h *AuthHandler) Signup(c *gin.Context) {
 		return
 	}
 
-	// Check rate limit
-	if err := h.rateLimiter.CheckRateLimit(c, "signup:"+req.Email, services.ActionCreatePOI); err != nil {
-		h.handleRateLimitError(c, err)
-		return
-	}
-
 	// Create full account
 	user, err := h.userService.CreateFullAccount(c, req.Email, req.Password, req.DisplayName, req.AboutMe)
 	if err != nil {
@@ -129,7 +180,7 @@ func (h *AuthHandler) Signup(c *gin.Context) {
 	}
 
 	// Generate JWT token
-	token, expiresAt, err := h.authService.GenerateJWT(user.ID, *user.Email, user.Role)
+	token, expiresAt, err := h.issueToken(c, user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Code:    "TOKEN_GENERATION_FAILED",
@@ -158,12 +209,6 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	// Check rate limit
-	if err := h.rateLimiter.CheckRateLimit(c, "login:"+req.Email, services.ActionCreatePOI); err != nil {
-		h.handleRateLimitError(c, err)
-		return
-	}
-
 	// Get user by email
 	user, err := h.userService.GetUserByEmail(c, req.Email)
 	if err != nil {
@@ -184,7 +229,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	// Generate JWT token
-	token, expiresAt, err := h.authService.GenerateJWT(user.ID, *user.Email, user.Role)
+	token, expiresAt, err := h.issueToken(c, user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Code:    "TOKEN_GENERATION_FAILED",
@@ -232,12 +277,42 @@ func (h *AuthHandler) GetCurrentUser(c *gin.Context) {
 		return
 	}
 
-	// Return user data
-	c.JSON(http.StatusOK, mapUserToResponse(user))
+	// Return user data together with the preferences that replace client-only settings
+	response := mapUserToResponse(user)
+	if h.preferenceService != nil {
+		preferences, err := h.preferenceService.GetPreferences(c.Request.Context(), user.ID)
+		if err != nil {
+			fmt.Printf("Warning: failed to load preferences of user %s: %v\n", user.ID, err)
+		} else {
+			response.Preferences = preferences
+		}
+	}
+	if h.onboardingService != nil {
+		progress, err := h.onboardingService.GetProgress(c.Request.Context(), user.ID)
+		if err != nil {
+			fmt.Printf("Warning: failed to load onboarding progress of user %s: %v\n", user.ID, err)
+		} else {
+			response.Onboarding = &progress
+		}
+	}
+	c.JSON(http.StatusOK, response)
 }
 
 // Helper methods
 
+// issueToken generates a JWT for a user, bound to a new sign-in if sessions are tracked
+func (h *AuthHandler) issueToken(c *gin.Context, user *models.User) (string, time.Time, error) {
+	if h.sessionService == nil {
+		return h.authService.GenerateJWT(user.ID, *user.Email, user.Role)
+	}
+
+	session, err := h.sessionService.StartSession(c.Request.Context(), user, c.Request.UserAgent(), net.ParseIP(c.ClientIP()))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return h.authService.GenerateSessionJWT(user.ID, *user.Email, user.Role, session.ID)
+}
+
 // mapUserToResponse converts a User model to UserResponse
 func mapUserToResponse(user *models.User) UserResponse {
 	response := UserResponse{
@@ -276,20 +351,3 @@ func findSubstringInString(s, substr string) bool {
 	return false
 }
 
-// handleRateLimitError handles rate limit errors
-func (h *AuthHandler) handleRateLimitError(c *gin.Context, err error) {
-	if rateLimitErr, ok := err.(*services.RateLimitError); ok {
-		c.Header("Retry-After", "3600")
-		c.JSON(http.StatusTooManyRequests, ErrorResponse{
-			Code:    "RATE_LIMIT_EXCEEDED",
-			Message: "Too many requests. Please try again later.",
-			Details: rateLimitErr.Error(),
-		})
-		return
-	}
-
-	c.JSON(http.StatusTooManyRequests, ErrorResponse{
-		Code:    "RATE_LIMIT_EXCEEDED",
-		Message: "Too many requests. Please try again later.",
-	})
-}
@@ -29,6 +29,11 @@ func (m *MockAuthService) GenerateJWT(userID, email string, role models.UserRole
 	return args.String(0), args.Get(1).(time.Time), args.Error(2)
 }
 
+func (m *MockAuthService) GenerateSessionJWT(userID, email string, role models.UserRole, sessionID string) (string, time.Time, error) {
+	args := m.Called(userID, email, role, sessionID)
+	return args.String(0), args.Get(1).(time.Time), args.Error(2)
+}
+
 func (m *MockAuthService) ValidateJWT(token string) (*services.JWTClaims, error) {
 	args := m.Called(token)
 	if args.Get(0) == nil {
@@ -147,7 +152,7 @@ func TestSignup_Success(t *testing.T) {
 	
 	handler := NewAuthHandler(mockAuthService, mockUserService, mockRateLimiter)
 	router := setupAuthTestRouter()
-	router.POST("/auth/signup", handler.Signup)
+	handler.RegisterRoutes(router)
 	
 	// Setup test data
 	signupReq := SignupRequest{
@@ -162,12 +167,13 @@ func TestSignup_Success(t *testing.T) {
 	
 	// Setup expectations
 	mockRateLimiter.On("CheckRateLimit", mock.Anything, "signup:test@example.com", services.ActionCreatePOI).Return(nil)
+	mockRateLimiter.On("GetRateLimitHeaders", mock.Anything, "signup:test@example.com", services.ActionCreatePOI).Return(map[string]string{}, nil)
 	mockUserService.On("CreateFullAccount", mock.Anything, "test@example.com", "Password123!", "Test User", "Hello world").Return(user, nil)
 	mockAuthService.On("GenerateJWT", "user-123", "test@example.com", models.UserRoleUser).Return("test-token", expiresAt, nil)
 	
 	// Make request
 	body, _ := json.Marshal(signupReq)
-	req := httptest.NewRequest("POST", "/auth/signup", bytes.NewBuffer(body))
+	req := httptest.NewRequest("POST", "/api/auth/signup", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	
@@ -252,7 +258,7 @@ func TestSignup_EmailAlreadyInUse(t *testing.T) {
 	
 	handler := NewAuthHandler(mockAuthService, mockUserService, mockRateLimiter)
 	router := setupAuthTestRouter()
-	router.POST("/auth/signup", handler.Signup)
+	handler.RegisterRoutes(router)
 	
 	signupReq := SignupRequest{
 		Email:       "existing@example.com",
@@ -261,11 +267,12 @@ func TestSignup_EmailAlreadyInUse(t *testing.T) {
 	}
 	
 	mockRateLimiter.On("CheckRateLimit", mock.Anything, "signup:existing@example.com", services.ActionCreatePOI).Return(nil)
+	mockRateLimiter.On("GetRateLimitHeaders", mock.Anything, "signup:existing@example.com", services.ActionCreatePOI).Return(map[string]string{}, nil)
 	mockUserService.On("CreateFullAccount", mock.Anything, "existing@example.com", "Password123!", "Test User", "").
 		Return(nil, errors.New("email already in use"))
 	
 	body, _ := json.Marshal(signupReq)
-	req := httptest.NewRequest("POST", "/auth/signup", bytes.NewBuffer(body))
+	req := httptest.NewRequest("POST", "/api/auth/signup", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	
@@ -285,7 +292,7 @@ func TestSignup_WeakPassword(t *testing.T) {
 	
 	handler := NewAuthHandler(mockAuthService, mockUserService, mockRateLimiter)
 	router := setupAuthTestRouter()
-	router.POST("/auth/signup", handler.Signup)
+	handler.RegisterRoutes(router)
 	
 	signupReq := SignupRequest{
 		Email:       "test@example.com",
@@ -294,11 +301,12 @@ func TestSignup_WeakPassword(t *testing.T) {
 	}
 	
 	mockRateLimiter.On("CheckRateLimit", mock.Anything, "signup:test@example.com", services.ActionCreatePOI).Return(nil)
+	mockRateLimiter.On("GetRateLimitHeaders", mock.Anything, "signup:test@example.com", services.ActionCreatePOI).Return(map[string]string{}, nil)
 	mockUserService.On("CreateFullAccount", mock.Anything, "test@example.com", "weakpass", "Test User", "").
 		Return(nil, errors.New("password does not meet requirements"))
 	
 	body, _ := json.Marshal(signupReq)
-	req := httptest.NewRequest("POST", "/auth/signup", bytes.NewBuffer(body))
+	req := httptest.NewRequest("POST", "/api/auth/signup", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	
@@ -318,7 +326,7 @@ func TestSignup_RateLimited(t *testing.T) {
 	
 	handler := NewAuthHandler(mockAuthService, mockUserService, mockRateLimiter)
 	router := setupAuthTestRouter()
-	router.POST("/auth/signup", handler.Signup)
+	handler.RegisterRoutes(router)
 	
 	signupReq := SignupRequest{
 		Email:       "test@example.com",
@@ -336,7 +344,7 @@ func TestSignup_RateLimited(t *testing.T) {
 	mockRateLimiter.On("CheckRateLimit", mock.Anything, "signup:test@example.com", services.ActionCreatePOI).Return(rateLimitErr)
 	
 	body, _ := json.Marshal(signupReq)
-	req := httptest.NewRequest("POST", "/auth/signup", bytes.NewBuffer(body))
+	req := httptest.NewRequest("POST", "/api/auth/signup", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	
@@ -357,7 +365,7 @@ func TestLogin_Success(t *testing.T) {
 	
 	handler := NewAuthHandler(mockAuthService, mockUserService, mockRateLimiter)
 	router := setupAuthTestRouter()
-	router.POST("/auth/login", handler.Login)
+	handler.RegisterRoutes(router)
 	
 	loginReq := LoginRequest{
 		Email:    "test@example.com",
@@ -368,12 +376,13 @@ func TestLogin_Success(t *testing.T) {
 	expiresAt := time.Now().Add(24 * time.Hour)
 	
 	mockRateLimiter.On("CheckRateLimit", mock.Anything, "login:test@example.com", services.ActionCreatePOI).Return(nil)
+	mockRateLimiter.On("GetRateLimitHeaders", mock.Anything, "login:test@example.com", services.ActionCreatePOI).Return(map[string]string{}, nil)
 	mockUserService.On("GetUserByEmail", mock.Anything, "test@example.com").Return(user, nil)
 	mockUserService.On("VerifyPassword", mock.Anything, "user-123", "Password123!").Return(nil)
 	mockAuthService.On("GenerateJWT", "user-123", "test@example.com", models.UserRoleUser).Return("test-token", expiresAt, nil)
 	
 	body, _ := json.Marshal(loginReq)
-	req := httptest.NewRequest("POST", "/auth/login", bytes.NewBuffer(body))
+	req := httptest.NewRequest("POST", "/api/auth/login", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	
@@ -400,7 +409,7 @@ func TestLogin_InvalidCredentials_UserNotFound(t *testing.T) {
 	
 	handler := NewAuthHandler(mockAuthService, mockUserService, mockRateLimiter)
 	router := setupAuthTestRouter()
-	router.POST("/auth/login", handler.Login)
+	handler.RegisterRoutes(router)
 	
 	loginReq := LoginRequest{
 		Email:    "nonexistent@example.com",
@@ -408,10 +417,11 @@ func TestLogin_InvalidCredentials_UserNotFound(t *testing.T) {
 	}
 	
 	mockRateLimiter.On("CheckRateLimit", mock.Anything, "login:nonexistent@example.com", services.ActionCreatePOI).Return(nil)
+	mockRateLimiter.On("GetRateLimitHeaders", mock.Anything, "login:nonexistent@example.com", services.ActionCreatePOI).Return(map[string]string{}, nil)
 	mockUserService.On("GetUserByEmail", mock.Anything, "nonexistent@example.com").Return(nil, errors.New("user not found"))
 	
 	body, _ := json.Marshal(loginReq)
-	req := httptest.NewRequest("POST", "/auth/login", bytes.NewBuffer(body))
+	req := httptest.NewRequest("POST", "/api/auth/login", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	
@@ -431,7 +441,7 @@ func TestLogin_InvalidCredentials_WrongPassword(t *testing.T) {
 	
 	handler := NewAuthHandler(mockAuthService, mockUserService, mockRateLimiter)
 	router := setupAuthTestRouter()
-	router.POST("/auth/login", handler.Login)
+	handler.RegisterRoutes(router)
 	
 	loginReq := LoginRequest{
 		Email:    "test@example.com",
@@ -441,11 +451,12 @@ func TestLogin_InvalidCredentials_WrongPassword(t *testing.T) {
 	user := createTestUser("user-123", "test@example.com", "Test User", models.AccountTypeFull, models.UserRoleUser)
 	
 	mockRateLimiter.On("CheckRateLimit", mock.Anything, "login:test@example.com", services.ActionCreatePOI).Return(nil)
+	mockRateLimiter.On("GetRateLimitHeaders", mock.Anything, "login:test@example.com", services.ActionCreatePOI).Return(map[string]string{}, nil)
 	mockUserService.On("GetUserByEmail", mock.Anything, "test@example.com").Return(user, nil)
 	mockUserService.On("VerifyPassword", mock.Anything, "user-123", "WrongPassword123!").Return(errors.New("invalid password"))
 	
 	body, _ := json.Marshal(loginReq)
-	req := httptest.NewRequest("POST", "/auth/login", bytes.NewBuffer(body))
+	req := httptest.NewRequest("POST", "/api/auth/login", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	
@@ -465,7 +476,7 @@ func TestLogin_RateLimited(t *testing.T) {
 	
 	handler := NewAuthHandler(mockAuthService, mockUserService, mockRateLimiter)
 	router := setupAuthTestRouter()
-	router.POST("/auth/login", handler.Login)
+	handler.RegisterRoutes(router)
 	
 	loginReq := LoginRequest{
 		Email:    "test@example.com",
@@ -482,7 +493,7 @@ func TestLogin_RateLimited(t *testing.T) {
 	mockRateLimiter.On("CheckRateLimit", mock.Anything, "login:test@example.com", services.ActionCreatePOI).Return(rateLimitErr)
 	
 	body, _ := json.Marshal(loginReq)
-	req := httptest.NewRequest("POST", "/auth/login", bytes.NewBuffer(body))
+	req := httptest.NewRequest("POST", "/api/auth/login", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	
@@ -594,3 +605,78 @@ func TestGetCurrentUser_UserNotFound(t *testing.T) {
 	
 	mockUserService.AssertExpectations(t)
 }
+
+func TestGetCurrentUser_IncludesPreferences(t *testing.T) {
+	mockAuthService := &MockAuthService{}
+	mockUserService := &MockAuthUserService{}
+	mockRateLimiter := &MockAuthRateLimiter{}
+	
+	handler := NewAuthHandler(mockAuthService, mockUserService, mockRateLimiter)
+	handler.SetPreferenceService(&stubPreferenceService{preferences: map[string]models.Preferences{
+		"user-123": {models.PreferenceA11y: {"reducedMotion": true}},
+	}})
+	router := setupAuthTestRouter()
+	
+	router.GET("/auth/me", func(c *gin.Context) {
+		c.Set("userID", "user-123")
+		handler.GetCurrentUser(c)
+	})
+	
+	user := createTestUser("user-123", "test@example.com", "Test User", models.AccountTypeFull, models.UserRoleUser)
+	mockUserService.On("GetUser", mock.Anything, "user-123").Return(user, nil)
+	
+	req := httptest.NewRequest("GET", "/auth/me", nil)
+	w := httptest.NewRecorder()
+	
+	router.ServeHTTP(w, req)
+	
+	require.Equal(t, http.StatusOK, w.Code)
+	
+	var response UserResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, true, response.Preferences[models.PreferenceA11y]["reducedMotion"])
+	assert.Equal(t, true, response.Preferences[models.PreferenceVideo]["cameraOn"])
+}
+
+type stubOnboardingService struct {
+	completed []*models.UserOnboardingStep
+}
+
+func (s *stubOnboardingService) GetProgress(ctx context.Context, userID string) (models.OnboardingProgress, error) {
+	return models.NewOnboardingProgress(s.completed), nil
+}
+
+func TestGetCurrentUser_IncludesOnboarding(t *testing.T) {
+	mockAuthService := &MockAuthService{}
+	mockUserService := &MockAuthUserService{}
+	mockRateLimiter := &MockAuthRateLimiter{}
+	
+	handler := NewAuthHandler(mockAuthService, mockUserService, mockRateLimiter)
+	handler.SetOnboardingService(&stubOnboardingService{completed: []*models.UserOnboardingStep{
+		{UserID: "user-123", Step: models.OnboardingFirstMove, CompletedAt: time.Now()},
+	}})
+	router := setupAuthTestRouter()
+	
+	router.GET("/auth/me", func(c *gin.Context) {
+		c.Set("userID", "user-123")
+		handler.GetCurrentUser(c)
+	})
+	
+	user := createTestUser("user-123", "test@example.com", "Test User", models.AccountTypeFull, models.UserRoleUser)
+	mockUserService.On("GetUser", mock.Anything, "user-123").Return(user, nil)
+	
+	req := httptest.NewRequest("GET", "/auth/me", nil)
+	w := httptest.NewRecorder()
+	
+	router.ServeHTTP(w, req)
+	
+	require.Equal(t, http.StatusOK, w.Code)
+	
+	var response UserResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.NotNil(t, response.Onboarding)
+	assert.False(t, response.Onboarding.Complete)
+	require.Len(t, response.Onboarding.Steps, len(models.OnboardingSteps))
+	assert.False(t, response.Onboarding.Steps[0].Completed)
+	assert.True(t, response.Onboarding.Steps[1].Completed)
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuthSessionServiceInterface defines the interface for managing a user's sign-ins
+type AuthSessionServiceInterface interface {
+	ListActiveSessions(ctx context.Context, userID string) ([]*models.AuthSession, error)
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+	RevokeOtherSessions(ctx context.Context, userID, currentSessionID string) (int, error)
+}
+
+// AuthSessionHandler handles the sign-in endpoints of the current user
+type AuthSessionHandler struct {
+	sessionService AuthSessionServiceInterface
+}
+
+// NewAuthSessionHandler creates a new AuthSessionHandler
+func NewAuthSessionHandler(sessionService AuthSessionServiceInterface) *AuthSessionHandler {
+	return &AuthSessionHandler{
+		sessionService: sessionService,
+	}
+}
+
+// RegisterRoutes registers sign-in routes
+// authMiddleware should require a valid JWT
+func (h *AuthSessionHandler) RegisterRoutes(router *gin.Engine, authMiddleware ...gin.HandlerFunc) {
+	users := router.Group("/api/users/me", authMiddleware...)
+	{
+		users.GET("/sessions", h.ListSessions)
+		users.DELETE("/sessions", h.RevokeOtherSessions)
+		users.DELETE("/sessions/:sessionId", h.RevokeSession)
+	}
+}
+
+// AuthSessionResponse represents one sign-in of the current user
+type AuthSessionResponse struct {
+	ID         string         `json:"id"`
+	UserAgent  string         `json:"userAgent"`
+	IPAddress  string         `json:"ipAddress"`
+	Location   *models.LatLng `json:"location,omitempty"`
+	CreatedAt  string         `json:"createdAt"`
+	LastSeenAt string         `json:"lastSeenAt"`
+	ExpiresAt  string         `json:"expiresAt"`
+	Current    bool           `json:"current"` // The sign-in of the requesting token
+}
+
+// AuthSessionsResponse represents the active sign-ins of the current user
+type AuthSessionsResponse struct {
+	Sessions []AuthSessionResponse `json:"sessions"`
+}
+
+// RevokeSessionsResponse reports how many sign-ins were revoked
+type RevokeSessionsResponse struct {
+	Revoked int `json:"revoked"`
+}
+
+// ListSessions handles GET /api/users/me/sessions
+func (h *AuthSessionHandler) ListSessions(c *gin.Context) {
+	userID := c.GetString("userID")
+	sessions, err := h.sessionService.ListActiveSessions(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: "Failed to list sessions",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	currentSessionID := c.GetString("authSessionID")
+	response := AuthSessionsResponse{Sessions: make([]AuthSessionResponse, 0, len(sessions))}
+	for _, session := range sessions {
+		item := AuthSessionResponse{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt.Format(time.RFC3339),
+			LastSeenAt: session.LastSeenAt.Format(time.RFC3339),
+			ExpiresAt:  session.ExpiresAt.Format(time.RFC3339),
+			Current:    session.ID == currentSessionID,
+		}
+		if location, ok := session.Location(); ok {
+			item.Location = &location
+		}
+		response.Sessions = append(response.Sessions, item)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// RevokeSession handles DELETE /api/users/me/sessions/:sessionId
+func (h *AuthSessionHandler) RevokeSession(c *gin.Context) {
+	err := h.sessionService.RevokeSession(c.Request.Context(), c.GetString("userID"), c.Param("sessionId"))
+	if err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Code:    "SESSION_NOT_FOUND",
+				Message: "Session not found or already signed out",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: "Failed to revoke session",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, RevokeSessionsResponse{Revoked: 1})
+}
+
+// RevokeOtherSessions handles DELETE /api/users/me/sessions
+// All sign-ins except the one of the requesting token are revoked.
+func (h *AuthSessionHandler) RevokeOtherSessions(c *gin.Context) {
+	revoked, err := h.sessionService.RevokeOtherSessions(c.Request.Context(), c.GetString("userID"), c.GetString("authSessionID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: "Failed to revoke sessions",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, RevokeSessionsResponse{Revoked: revoked})
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubAuthSessionService struct {
+	sessions []*models.AuthSession
+	revoked  []string
+}
+
+func (s *stubAuthSessionService) ListActiveSessions(ctx context.Context, userID string) ([]*models.AuthSession, error) {
+	var sessions []*models.AuthSession
+	for _, session := range s.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (s *stubAuthSessionService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	for _, session := range s.sessions {
+		if session.ID == sessionID && session.UserID == userID && session.RevokedAt == nil {
+			now := time.Now()
+			session.RevokedAt = &now
+			s.revoked = append(s.revoked, sessionID)
+			return nil
+		}
+	}
+	return fmt.Errorf("session %w", services.ErrNotFound)
+}
+
+func (s *stubAuthSessionService) RevokeOtherSessions(ctx context.Context, userID, currentSessionID string) (int, error) {
+	revoked := 0
+	for _, session := range s.sessions {
+		if session.UserID == userID && session.ID != currentSessionID && s.RevokeSession(ctx, userID, session.ID) == nil {
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+func setupAuthSessionTest() (*gin.Engine, *stubAuthSessionService) {
+	gin.SetMode(gin.TestMode)
+
+	lat, lng := 51.5142, -0.0931
+	service := &stubAuthSessionService{sessions: []*models.AuthSession{
+		{ID: "session-1", UserID: "user-1", UserAgent: "Firefox", IPAddress: "81.2.69.160", Lat: &lat, Lng: &lng},
+		{ID: "session-2", UserID: "user-1", UserAgent: "Safari", IPAddress: "8.8.8.8"},
+		{ID: "session-3", UserID: "user-2", UserAgent: "Chrome"},
+	}}
+
+	// Stands in for RequireAuth with a token of session-1
+	fakeAuth := func(c *gin.Context) {
+		c.Set("userID", "user-1")
+		c.Set("authSessionID", "session-1")
+		c.Next()
+	}
+
+	router := gin.New()
+	NewAuthSessionHandler(service).RegisterRoutes(router, fakeAuth)
+	return router, service
+}
+
+func TestAuthSessionHandler_ListSessions(t *testing.T) {
+	router, _ := setupAuthSessionTest()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/users/me/sessions", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response AuthSessionsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Sessions, 2)
+	assert.True(t, response.Sessions[0].Current)
+	assert.NotNil(t, response.Sessions[0].Location)
+	assert.False(t, response.Sessions[1].Current)
+	assert.Nil(t, response.Sessions[1].Location)
+}
+
+func TestAuthSessionHandler_RevokeSession(t *testing.T) {
+	router, service := setupAuthSessionTest()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/users/me/sessions/session-2", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"session-2"}, service.revoked)
+
+	// Sessions of other users cannot be revoked
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/users/me/sessions/session-3", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAuthSessionHandler_RevokeOtherSessions(t *testing.T) {
+	router, service := setupAuthSessionTest()
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/users/me/sessions", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response RevokeSessionsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Revoked)
+	assert.Equal(t, []string{"session-2"}, service.revoked)
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"breakoutglobe/internal/middleware"
+	"breakoutglobe/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EnabledFeaturesInterface defines the interface for features rolled out to a user
+type EnabledFeaturesInterface interface {
+	EnabledFeatures(ctx context.Context, userID string) []models.Feature
+}
+
+// ClientConfig is the public runtime configuration of a deployment, so the
+// frontend doesn't have to be rebuilt for each environment
+type ClientConfig struct {
+	WebSocketURL     string           `json:"webSocketUrl"`
+	AssetBaseURL     string           `json:"assetBaseUrl"`
+	Features         []models.Feature `json:"features"`
+	TileStyle        TileStyle        `json:"tileStyle"`
+	MaxUploadSizes   MaxUploadSizes   `json:"maxUploadSizes"`
+	ProtocolVersion  int              `json:"protocolVersion"`
+	MinClientVersion string           `json:"minClientVersion,omitempty"`
+	API              APIVersionInfo   `json:"api"`
+}
+
+// APIVersionInfo tells clients which REST API version to use. Clients call
+// BasePath; unversioned /api paths alias the current version until
+// UnversionedSunset, and a breaking version is added to Supported before
+// the old one is dropped.
+type APIVersionInfo struct {
+	Version           string     `json:"version"`
+	BasePath          string     `json:"basePath"`
+	Supported         []string   `json:"supported"`
+	UnversionedSunset *time.Time `json:"unversionedSunset,omitempty"`
+}
+
+// TileStyle describes the raster tiles the map is drawn with
+type TileStyle struct {
+	Tiles       []string `json:"tiles"`
+	TileSize    int      `json:"tileSize"`
+	Attribution string   `json:"attribution"`
+}
+
+// MaxUploadSizes are the largest accepted uploads in bytes
+type MaxUploadSizes struct {
+	Avatar     int64 `json:"avatar"`
+	POIImage   int64 `json:"poiImage"`
+	MapArchive int64 `json:"mapArchive"`
+}
+
+// ConfigHandler serves the public runtime configuration
+type ConfigHandler struct {
+	config  ClientConfig
+	rollout EnabledFeaturesInterface
+}
+
+// NewConfigHandler creates a new ConfigHandler. Upload limits enforced by
+// handlers of this package are filled in; empty URLs are derived from the
+// request. The API version is that of the middleware package.
+func NewConfigHandler(config ClientConfig) *ConfigHandler {
+	config.MaxUploadSizes.Avatar = maxAvatarBytes
+	config.MaxUploadSizes.MapArchive = maxMapArchiveBytes
+	config.API.Version = middleware.APIVersion
+	config.API.BasePath = middleware.APIBasePath
+	config.API.Supported = []string{middleware.APIVersion}
+	return &ConfigHandler{
+		config: config,
+	}
+}
+
+// SetRolloutService lists the features rolled out to everyone. Without it, no
+// features are listed.
+func (h *ConfigHandler) SetRolloutService(rollout EnabledFeaturesInterface) {
+	h.rollout = rollout
+}
+
+// RegisterRoutes registers the config route
+func (h *ConfigHandler) RegisterRoutes(router *gin.Engine) {
+	api := router.Group("/api")
+	{
+		api.GET("/config", h.GetConfig)
+	}
+}
+
+// GetConfig handles GET /api/config
+func (h *ConfigHandler) GetConfig(c *gin.Context) {
+	config := h.config
+	if config.WebSocketURL == "" {
+		config.WebSocketURL = strings.Replace(middleware.AbsoluteURL(c, "/ws"), "http", "ws", 1)
+	}
+	if config.AssetBaseURL == "" {
+		config.AssetBaseURL = middleware.AbsoluteURL(c, "/uploads")
+	}
+
+	// The config is public, so only features rolled out to everyone are listed
+	config.Features = []models.Feature{}
+	if h.rollout != nil {
+		config.Features = h.rollout.EnabledFeatures(c.Request.Context(), "")
+	}
+
+	c.Header("Cache-Control", "public, max-age=60")
+	c.JSON(http.StatusOK, config)
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubEnabledFeatures map[string][]models.Feature
+
+func (s stubEnabledFeatures) EnabledFeatures(ctx context.Context, userID string) []models.Feature {
+	return s[userID]
+}
+
+func getConfig(t *testing.T, handler *ConfigHandler, host string) ClientConfig {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler.RegisterRoutes(router)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/config", nil)
+	req.Host = host
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "public, max-age=60", w.Header().Get("Cache-Control"))
+
+	var config ClientConfig
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &config))
+	return config
+}
+
+func TestConfigHandler_GetConfig(t *testing.T) {
+	handler := NewConfigHandler(ClientConfig{
+		WebSocketURL:    "wss://api.example.com/ws",
+		AssetBaseURL:    "https://cdn.example.com/uploads",
+		TileStyle:       TileStyle{Tiles: []string{"https://tiles.example.com/{z}/{x}/{y}.png"}, TileSize: 256},
+		MaxUploadSizes:  MaxUploadSizes{POIImage: 5 << 20},
+		ProtocolVersion: 1,
+	})
+	// Only features rolled out to everyone are public
+	handler.SetRolloutService(stubEnabledFeatures{
+		"":       {models.FeatureBatchedMovement},
+		"user-1": {models.FeatureBatchedMovement, models.Feature("canary_only")},
+	})
+
+	config := getConfig(t, handler, "api.example.com")
+	assert.Equal(t, "wss://api.example.com/ws", config.WebSocketURL)
+	assert.Equal(t, "https://cdn.example.com/uploads", config.AssetBaseURL)
+	assert.Equal(t, []models.Feature{models.FeatureBatchedMovement}, config.Features)
+	assert.Equal(t, []string{"https://tiles.example.com/{z}/{x}/{y}.png"}, config.TileStyle.Tiles)
+	assert.Equal(t, MaxUploadSizes{Avatar: maxAvatarBytes, POIImage: 5 << 20, MapArchive: maxMapArchiveBytes}, config.MaxUploadSizes)
+	assert.Equal(t, 1, config.ProtocolVersion)
+	assert.Equal(t, APIVersionInfo{Version: "v1", BasePath: "/api/v1", Supported: []string{"v1"}}, config.API)
+}
+
+func TestConfigHandler_GetConfig_DerivesURLsFromRequest(t *testing.T) {
+	config := getConfig(t, NewConfigHandler(ClientConfig{}), "localhost:8080")
+
+	assert.Equal(t, "ws://localhost:8080/ws", config.WebSocketURL)
+	assert.Equal(t, "http://localhost:8080/uploads", config.AssetBaseURL)
+	assert.Empty(t, config.Features)
+	assert.NotNil(t, config.Features)
+}
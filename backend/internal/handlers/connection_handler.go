@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConnectionListerInterface defines the interface for listing live WebSocket connections
+type ConnectionListerInterface interface {
+	Connections(mapID string) []models.ConnectionInfo
+}
+
+// ConnectionErrorListerInterface defines the interface for listing recorded abnormal closes
+type ConnectionErrorListerInterface interface {
+	ListConnectionErrors(ctx context.Context, userID string, limit int) ([]models.ConnectionError, error)
+}
+
+// EventJournalReaderInterface defines the interface for inspecting a map's broadcast journal
+type EventJournalReaderInterface interface {
+	Recent(ctx context.Context, mapID string, count int64) ([]models.MapEvent, error)
+}
+
+// defaultConnectionErrorLimit and maxConnectionErrorLimit bound connection error listings
+const (
+	defaultConnectionErrorLimit = 100
+	maxConnectionErrorLimit     = 1000
+)
+
+// ConnectionHandler handles admin endpoints for live connections
+type ConnectionHandler struct {
+	connections      ConnectionListerInterface
+	connectionErrors ConnectionErrorListerInterface
+	journal          EventJournalReaderInterface
+}
+
+// NewConnectionHandler creates a new ConnectionHandler
+func NewConnectionHandler(connections ConnectionListerInterface) *ConnectionHandler {
+	return &ConnectionHandler{
+		connections: connections,
+	}
+}
+
+// SetConnectionErrorLister enables listing recorded abnormal closes
+func (h *ConnectionHandler) SetConnectionErrorLister(connectionErrors ConnectionErrorListerInterface) {
+	h.connectionErrors = connectionErrors
+}
+
+// SetEventJournalReader enables inspecting a map's journaled broadcasts
+func (h *ConnectionHandler) SetEventJournalReader(journal EventJournalReaderInterface) {
+	h.journal = journal
+}
+
+// RegisterRoutes registers connection routes
+// adminMiddleware should authenticate the caller and require an admin role
+func (h *ConnectionHandler) RegisterRoutes(router *gin.Engine, adminMiddleware ...gin.HandlerFunc) {
+	admin := router.Group("/api/admin", adminMiddleware...)
+	{
+		admin.GET("/connections", h.ListConnections)
+		if h.connectionErrors != nil {
+			admin.GET("/connections/errors", h.ListConnectionErrors)
+		}
+		if h.journal != nil {
+			admin.GET("/maps/:mapId/journal", h.ListJournalEvents)
+		}
+	}
+}
+
+// ConnectionsResponse represents the live connections
+type ConnectionsResponse struct {
+	Connections []models.ConnectionInfo `json:"connections"`
+	Count       int                     `json:"count"`
+}
+
+// ListConnections handles GET /api/admin/connections
+// The optional "mapId" query parameter limits the listing to one map
+func (h *ConnectionHandler) ListConnections(c *gin.Context) {
+	connections := h.connections.Connections(c.Query("mapId"))
+
+	c.JSON(http.StatusOK, ConnectionsResponse{
+		Connections: connections,
+		Count:       len(connections),
+	})
+}
+
+// ConnectionErrorsResponse represents recorded abnormal closes
+type ConnectionErrorsResponse struct {
+	Errors []models.ConnectionError `json:"errors"`
+	Count  int                      `json:"count"`
+}
+
+// ListConnectionErrors handles GET /api/admin/connections/errors
+// The optional "userId" query parameter limits the listing to one user and
+// "limit" to the most recent closes (default 100, at most 1000).
+func (h *ConnectionHandler) ListConnectionErrors(c *gin.Context) {
+	limit := defaultConnectionErrorLimit
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxConnectionErrorLimit {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Code:    "INVALID_REQUEST",
+				Message: "limit must be between 1 and 1000",
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	connectionErrors, err := h.connectionErrors.ListConnectionErrors(c.Request.Context(), c.Query("userId"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: "Failed to list connection errors",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ConnectionErrorsResponse{
+		Errors: connectionErrors,
+		Count:  len(connectionErrors),
+	})
+}
+
+// JournalEventsResponse represents the most recent journaled broadcasts of a map
+type JournalEventsResponse struct {
+	Events []models.MapEvent `json:"events"`
+	Count  int               `json:"count"`
+}
+
+// ListJournalEvents handles GET /api/admin/maps/:mapId/journal
+// The optional "limit" query parameter bounds the listing to the most recent
+// events (default 100, at most 1000), newest first.
+func (h *ConnectionHandler) ListJournalEvents(c *gin.Context) {
+	limit := defaultConnectionErrorLimit
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxConnectionErrorLimit {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Code:    "INVALID_REQUEST",
+				Message: "limit must be between 1 and 1000",
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	events, err := h.journal.Recent(c.Request.Context(), c.Param("mapId"), int64(limit))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: "Failed to list journal events",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, JournalEventsResponse{
+		Events: events,
+		Count:  len(events),
+	})
+}
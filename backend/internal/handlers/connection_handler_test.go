@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubConnectionLister struct {
+	connections []models.ConnectionInfo
+}
+
+func (l *stubConnectionLister) Connections(mapID string) []models.ConnectionInfo {
+	connections := []models.ConnectionInfo{}
+	for _, connection := range l.connections {
+		if mapID == "" || connection.MapID == mapID {
+			connections = append(connections, connection)
+		}
+	}
+	return connections
+}
+
+func TestConnectionHandler_ListConnections(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	connectedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	lister := &stubConnectionLister{connections: []models.ConnectionInfo{
+		{SessionID: "session-1", UserID: "user-1", MapID: "map-1", ClientVersion: "1.4.0", UserAgent: "Mozilla/5.0", ConnectedAt: connectedAt},
+		{SessionID: "session-2", UserID: "user-2", MapID: "map-2", ConnectedAt: connectedAt},
+	}}
+	router := gin.New()
+	NewConnectionHandler(lister).RegisterRoutes(router)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/connections?mapId=map-1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response ConnectionsResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Count)
+	assert.Equal(t, "1.4.0", response.Connections[0].ClientVersion)
+	assert.Equal(t, "Mozilla/5.0", response.Connections[0].UserAgent)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/admin/connections", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Count)
+}
+
+type stubConnectionErrorLister struct {
+	errors []models.ConnectionError
+}
+
+func (l *stubConnectionErrorLister) ListConnectionErrors(ctx context.Context, userID string, limit int) ([]models.ConnectionError, error) {
+	connectionErrors := []models.ConnectionError{}
+	for _, connectionError := range l.errors {
+		if (userID == "" || connectionError.UserID == userID) && len(connectionErrors) < limit {
+			connectionErrors = append(connectionErrors, connectionError)
+		}
+	}
+	return connectionErrors, nil
+}
+
+func TestConnectionHandler_ListConnectionErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	closedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	lister := &stubConnectionErrorLister{errors: []models.ConnectionError{
+		{ID: "error-1", UserID: "user-1", CloseCode: 4408, Reason: "idle_timeout", ClosedAt: closedAt},
+		{ID: "error-2", UserID: "user-2", CloseCode: 1006, Reason: "connection_lost", ClosedAt: closedAt},
+	}}
+	handler := NewConnectionHandler(&stubConnectionLister{})
+	handler.SetConnectionErrorLister(lister)
+	router := gin.New()
+	handler.RegisterRoutes(router)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/connections/errors?userId=user-1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response ConnectionErrorsResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Count)
+	assert.Equal(t, "idle_timeout", response.Errors[0].Reason)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/admin/connections/errors?limit=5000", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+type stubEventJournalReader struct {
+	events []models.MapEvent
+}
+
+func (r *stubEventJournalReader) Recent(ctx context.Context, mapID string, count int64) ([]models.MapEvent, error) {
+	if int64(len(r.events)) > count {
+		return r.events[:count], nil
+	}
+	return r.events, nil
+}
+
+func TestConnectionHandler_ListJournalEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewConnectionHandler(&stubConnectionLister{})
+	handler.SetEventJournalReader(&stubEventJournalReader{events: []models.MapEvent{
+		{Seq: 2, Type: "poi_updated", Data: json.RawMessage(`{"poiId":"poi-1"}`)},
+		{Seq: 1, Type: "poi_created", Data: json.RawMessage(`{"poiId":"poi-1"}`)},
+	}})
+	router := gin.New()
+	handler.RegisterRoutes(router)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/maps/map-1/journal?limit=1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response JournalEventsResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Count)
+	assert.Equal(t, uint64(2), response.Events[0].Seq)
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DigestServiceInterface defines the interface for managing digest subscriptions
+type DigestServiceInterface interface {
+	Subscribe(ctx context.Context, mapID, userID string, frequency models.DigestFrequency) (*models.DigestSubscription, error)
+	GetSubscription(ctx context.Context, mapID, userID string) (*models.DigestSubscription, error)
+	Unsubscribe(ctx context.Context, mapID, userID string) error
+}
+
+// DigestHandler handles map digest subscription endpoints
+type DigestHandler struct {
+	digestService DigestServiceInterface
+}
+
+// NewDigestHandler creates a new DigestHandler
+func NewDigestHandler(digestService DigestServiceInterface) *DigestHandler {
+	return &DigestHandler{
+		digestService: digestService,
+	}
+}
+
+// RegisterRoutes registers digest subscription routes
+// facilitatorMiddleware should authenticate the caller and require an admin role
+func (h *DigestHandler) RegisterRoutes(router *gin.Engine, facilitatorMiddleware ...gin.HandlerFunc) {
+	maps := router.Group("/api/maps", facilitatorMiddleware...)
+	{
+		maps.GET("/:mapId/digest-subscription", h.GetSubscription)
+		maps.PUT("/:mapId/digest-subscription", h.Subscribe)
+		maps.DELETE("/:mapId/digest-subscription", h.Unsubscribe)
+	}
+}
+
+// SubscribeDigestRequest represents the request body for subscribing to digests
+type SubscribeDigestRequest struct {
+	Frequency models.DigestFrequency `json:"frequency" binding:"required"`
+}
+
+// GetSubscription handles GET /api/maps/:mapId/digest-subscription
+func (h *DigestHandler) GetSubscription(c *gin.Context) {
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	sub, err := h.digestService.GetSubscription(c.Request.Context(), c.Param("mapId"), userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to get digest subscription")
+		return
+	}
+
+	c.JSON(http.StatusOK, sub)
+}
+
+// Subscribe handles PUT /api/maps/:mapId/digest-subscription
+func (h *DigestHandler) Subscribe(c *gin.Context) {
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	// Digests are emailed, so guests without an address can't subscribe
+	if email, _ := c.Get("email"); email == nil || email == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "VALIDATION_ERROR",
+			Message: "An email address is required to subscribe to digests",
+		})
+		return
+	}
+
+	var req SubscribeDigestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	sub, err := h.digestService.Subscribe(c.Request.Context(), c.Param("mapId"), userID, req.Frequency)
+	if err != nil {
+		h.handleError(c, err, "Failed to subscribe to digests")
+		return
+	}
+
+	c.JSON(http.StatusOK, sub)
+}
+
+// Unsubscribe handles DELETE /api/maps/:mapId/digest-subscription
+func (h *DigestHandler) Unsubscribe(c *gin.Context) {
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.digestService.Unsubscribe(c.Request.Context(), c.Param("mapId"), userID); err != nil {
+		h.handleError(c, err, "Failed to unsubscribe from digests")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// requireUser returns the authenticated user ID or writes an unauthorized response
+func (h *DigestHandler) requireUser(c *gin.Context) (string, bool) {
+	userID, _ := c.Get("userID")
+	id, ok := userID.(string)
+	if !ok || id == "" {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Code:    "UNAUTHORIZED",
+			Message: "Authentication required",
+		})
+		return "", false
+	}
+	return id, true
+}
+
+// handleError maps digest service errors to responses
+func (h *DigestHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Code:    "NOT_FOUND",
+			Message: "Digest subscription not found",
+		})
+	case strings.Contains(err.Error(), "invalid subscription"):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "VALIDATION_ERROR",
+			Message: message,
+			Details: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: message,
+			Details: err.Error(),
+		})
+	}
+}
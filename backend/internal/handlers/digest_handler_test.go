@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubDigestService struct {
+	subs map[string]*models.DigestSubscription
+}
+
+func (s *stubDigestService) Subscribe(ctx context.Context, mapID, userID string, frequency models.DigestFrequency) (*models.DigestSubscription, error) {
+	sub, err := models.NewDigestSubscription(mapID, userID, frequency)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription: %w", err)
+	}
+	s.subs[mapID+":"+userID] = sub
+	return sub, nil
+}
+
+func (s *stubDigestService) GetSubscription(ctx context.Context, mapID, userID string) (*models.DigestSubscription, error) {
+	sub, exists := s.subs[mapID+":"+userID]
+	if !exists {
+		return nil, fmt.Errorf("digest subscription %w", services.ErrNotFound)
+	}
+	return sub, nil
+}
+
+func (s *stubDigestService) Unsubscribe(ctx context.Context, mapID, userID string) error {
+	if _, exists := s.subs[mapID+":"+userID]; !exists {
+		return fmt.Errorf("digest subscription %w", services.ErrNotFound)
+	}
+	delete(s.subs, mapID+":"+userID)
+	return nil
+}
+
+func setupDigestTest(email string) (*gin.Engine, *stubDigestService) {
+	gin.SetMode(gin.TestMode)
+
+	service := &stubDigestService{subs: make(map[string]*models.DigestSubscription)}
+	router := gin.New()
+	NewDigestHandler(service).RegisterRoutes(router, func(c *gin.Context) {
+		c.Set("userID", "admin-1")
+		c.Set("email", email)
+		c.Next()
+	})
+	return router, service
+}
+
+func TestDigestHandler_SubscribeAndUnsubscribe(t *testing.T) {
+	router, service := setupDigestTest("admin@example.com")
+
+	req := httptest.NewRequest(http.MethodPut, "/api/maps/map-1/digest-subscription", strings.NewReader(`{"frequency":"weekly"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var sub models.DigestSubscription
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &sub))
+	assert.Equal(t, models.DigestFrequencyWeekly, sub.Frequency)
+	assert.Contains(t, service.subs, "map-1:admin-1")
+
+	req = httptest.NewRequest(http.MethodGet, "/api/maps/map-1/digest-subscription", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/maps/map-1/digest-subscription", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/maps/map-1/digest-subscription", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDigestHandler_Subscribe_InvalidFrequency(t *testing.T) {
+	router, _ := setupDigestTest("admin@example.com")
+
+	req := httptest.NewRequest(http.MethodPut, "/api/maps/map-1/digest-subscription", strings.NewReader(`{"frequency":"hourly"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response ErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "VALIDATION_ERROR", response.Code)
+}
+
+func TestDigestHandler_Subscribe_RequiresEmail(t *testing.T) {
+	router, service := setupDigestTest("")
+
+	req := httptest.NewRequest(http.MethodPut, "/api/maps/map-1/digest-subscription", strings.NewReader(`{"frequency":"daily"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Empty(t, service.subs)
+}
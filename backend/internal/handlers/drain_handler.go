@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DrainerInterface defines the interface for draining an instance before a rolling restart
+type DrainerInterface interface {
+	RequestDrain() bool
+}
+
+// DrainHandler handles the internal endpoint for rolling restarts
+type DrainHandler struct {
+	drainer DrainerInterface
+}
+
+// NewDrainHandler creates a new DrainHandler
+func NewDrainHandler(drainer DrainerInterface) *DrainHandler {
+	return &DrainHandler{
+		drainer: drainer,
+	}
+}
+
+// RegisterRoutes registers the drain route
+// opsMiddleware should restrict access to operators, like the /metrics token
+func (h *DrainHandler) RegisterRoutes(router *gin.Engine, opsMiddleware ...gin.HandlerFunc) {
+	internal := router.Group("/api/internal", opsMiddleware...)
+	{
+		internal.POST("/drain", h.Drain)
+	}
+}
+
+// DrainResponse represents the response to a drain request
+type DrainResponse struct {
+	Draining bool `json:"draining"`
+	// AlreadyDraining is set if an earlier request started the drain
+	AlreadyDraining bool `json:"alreadyDraining"`
+}
+
+// Drain handles POST /api/internal/drain
+// The instance stops accepting connections and stops once its clients moved
+// to other instances; the request returns right away.
+func (h *DrainHandler) Drain(c *gin.Context) {
+	requested := h.drainer.RequestDrain()
+
+	c.JSON(http.StatusAccepted, DrainResponse{
+		Draining:        true,
+		AlreadyDraining: !requested,
+	})
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubDrainer struct {
+	requests int
+}
+
+func (d *stubDrainer) RequestDrain() bool {
+	d.requests++
+	return d.requests == 1
+}
+
+func TestDrainHandler_Drain(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	drainer := &stubDrainer{}
+	router := gin.New()
+	NewDrainHandler(drainer).RegisterRoutes(router)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/internal/drain", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	var response DrainResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Draining)
+	assert.False(t, response.AlreadyDraining)
+
+	// Repeated requests don't start another drain
+	req = httptest.NewRequest(http.MethodPost, "/api/internal/drain", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.AlreadyDraining)
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HandQueueServiceInterface defines the interface for reading the raise-hand queue of maps
+type HandQueueServiceInterface interface {
+	GetHands(ctx context.Context, mapID string) ([]models.RaisedHand, error)
+}
+
+// HandQueueHandler handles the raise-hand queue endpoint
+type HandQueueHandler struct {
+	handQueue HandQueueServiceInterface
+}
+
+// NewHandQueueHandler creates a new HandQueueHandler
+func NewHandQueueHandler(handQueue HandQueueServiceInterface) *HandQueueHandler {
+	return &HandQueueHandler{
+		handQueue: handQueue,
+	}
+}
+
+// RegisterRoutes registers raise-hand queue routes
+// organizerMiddleware should authenticate the caller and require an organizer (admin) role
+func (h *HandQueueHandler) RegisterRoutes(router *gin.Engine, organizerMiddleware ...gin.HandlerFunc) {
+	maps := router.Group("/api/maps", organizerMiddleware...)
+	{
+		maps.GET("/:mapId/hands", h.GetHands)
+	}
+}
+
+// HandsResponse represents the raise-hand queue of a map
+type HandsResponse struct {
+	MapID string              `json:"mapId"`
+	Hands []models.RaisedHand `json:"hands"`
+	Count int                 `json:"count"`
+}
+
+// GetHands handles GET /api/maps/:mapId/hands
+// Hands are ordered by when they were raised, so facilitators can call on
+// participants in order
+func (h *HandQueueHandler) GetHands(c *gin.Context) {
+	mapID := c.Param("mapId")
+
+	hands, err := h.handQueue.GetHands(c.Request.Context(), mapID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: "Failed to get raised hands",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, HandsResponse{
+		MapID: mapID,
+		Hands: hands,
+		Count: len(hands),
+	})
+}
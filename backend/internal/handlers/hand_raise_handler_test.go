@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubHandQueueService struct {
+	hands map[string][]models.RaisedHand
+}
+
+func (s *stubHandQueueService) GetHands(ctx context.Context, mapID string) ([]models.RaisedHand, error) {
+	return s.hands[mapID], nil
+}
+
+func TestHandQueueHandler_GetHands(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	raisedAt := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	service := &stubHandQueueService{hands: map[string][]models.RaisedHand{
+		"map-1": {{UserID: "user-2", RaisedAt: raisedAt}, {UserID: "user-1", RaisedAt: raisedAt.Add(time.Second)}},
+	}}
+	router := gin.New()
+	NewHandQueueHandler(service).RegisterRoutes(router)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/maps/map-1/hands", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response HandsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "map-1", response.MapID)
+	assert.Equal(t, 2, response.Count)
+	assert.Equal(t, "user-2", response.Hands[0].UserID)
+	assert.True(t, raisedAt.Equal(response.Hands[0].RaisedAt))
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HeatmapServiceInterface defines the interface for reading map heatmaps
+type HeatmapServiceInterface interface {
+	GetHeatmap(ctx context.Context, mapID string, from, to time.Time) (*services.Heatmap, error)
+}
+
+// HeatmapHandler handles map traffic analytics endpoints
+type HeatmapHandler struct {
+	heatmapService HeatmapServiceInterface
+}
+
+// NewHeatmapHandler creates a new HeatmapHandler
+func NewHeatmapHandler(heatmapService HeatmapServiceInterface) *HeatmapHandler {
+	return &HeatmapHandler{
+		heatmapService: heatmapService,
+	}
+}
+
+// RegisterRoutes registers heatmap routes
+// organizerMiddleware should authenticate the caller and require an organizer (admin) role
+func (h *HeatmapHandler) RegisterRoutes(router *gin.Engine, organizerMiddleware ...gin.HandlerFunc) {
+	maps := router.Group("/api/maps", organizerMiddleware...)
+	{
+		maps.GET("/:mapId/heatmap", h.GetHeatmap)
+	}
+}
+
+// GetHeatmap handles GET /api/maps/:mapId/heatmap
+// The optional "from" and "to" query parameters are RFC 3339 timestamps and
+// default to the last 24 hours
+func (h *HeatmapHandler) GetHeatmap(c *gin.Context) {
+	mapID := c.Param("mapId")
+	if mapID == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "Map ID is required",
+		})
+		return
+	}
+
+	from, ok := parseTimeQuery(c, "from")
+	if !ok {
+		return
+	}
+	to, ok := parseTimeQuery(c, "to")
+	if !ok {
+		return
+	}
+
+	heatmap, err := h.heatmapService.GetHeatmap(c.Request.Context(), mapID, from, to)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid time range") {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid time range",
+				Details: err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: "Failed to get heatmap",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, heatmap)
+}
+
+// parseTimeQuery parses an optional RFC 3339 query parameter and writes a
+// validation error response if it is malformed
+func parseTimeQuery(c *gin.Context, name string) (time.Time, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return time.Time{}, true
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "VALIDATION_ERROR",
+			Message: "Invalid " + name + " time, expected RFC 3339",
+			Details: err.Error(),
+		})
+		return time.Time{}, false
+	}
+
+	return parsed, true
+}
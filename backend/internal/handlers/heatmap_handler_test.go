@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubHeatmapService struct {
+	mapID    string
+	from, to time.Time
+	err      error
+}
+
+func (s *stubHeatmapService) GetHeatmap(ctx context.Context, mapID string, from, to time.Time) (*services.Heatmap, error) {
+	s.mapID, s.from, s.to = mapID, from, to
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &services.Heatmap{
+		MapID:    mapID,
+		From:     from,
+		To:       to,
+		CellSize: models.HeatmapCellSize,
+		Cells:    []services.HeatmapCell{{Position: models.LatLng{Lat: 52.525, Lng: 13.405}, Count: 7}},
+		POIs:     []services.POITraffic{{POIID: "poi-1", Name: "Main stage", Count: 7}},
+	}, nil
+}
+
+func setupHeatmapTest(service *stubHeatmapService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	NewHeatmapHandler(service).RegisterRoutes(router)
+	return router
+}
+
+func TestHeatmapHandler_GetHeatmap(t *testing.T) {
+	service := &stubHeatmapService{}
+	router := setupHeatmapTest(service)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/maps/map-1/heatmap?from=2024-05-01T00:00:00Z&to=2024-05-02T00:00:00Z", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "map-1", service.mapID)
+	assert.Equal(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), service.from)
+	assert.Equal(t, time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC), service.to)
+
+	var response services.Heatmap
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response.Cells, 1)
+	assert.Equal(t, int64(7), response.Cells[0].Count)
+	assert.Equal(t, "poi-1", response.POIs[0].POIID)
+}
+
+func TestHeatmapHandler_GetHeatmap_DefaultRange(t *testing.T) {
+	service := &stubHeatmapService{}
+	router := setupHeatmapTest(service)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/maps/map-1/heatmap", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, service.from.IsZero())
+	assert.True(t, service.to.IsZero())
+}
+
+func TestHeatmapHandler_GetHeatmap_InvalidTime(t *testing.T) {
+	router := setupHeatmapTest(&stubHeatmapService{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/maps/map-1/heatmap?from=yesterday", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response ErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "VALIDATION_ERROR", response.Code)
+}
+
+func TestHeatmapHandler_GetHeatmap_InvalidRange(t *testing.T) {
+	router := setupHeatmapTest(&stubHeatmapService{err: fmt.Errorf("invalid time range: from must be before to")})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/maps/map-1/heatmap?from=2024-05-02T00:00:00Z&to=2024-05-01T00:00:00Z", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
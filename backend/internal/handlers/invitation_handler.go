@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// InvitationServiceInterface defines the interface for map invitations
+type InvitationServiceInterface interface {
+	InviteEmails(ctx context.Context, mapID, invitedBy string, emails []string) ([]services.InvitationResult, error)
+	AcceptInvitation(ctx context.Context, token string) (*models.Invitation, *models.User, error)
+	ListInvitations(ctx context.Context, mapID string) ([]*models.Invitation, error)
+}
+
+// InvitationHandler handles map invitation endpoints
+type InvitationHandler struct {
+	invitationService InvitationServiceInterface
+}
+
+// NewInvitationHandler creates a new InvitationHandler
+func NewInvitationHandler(invitationService InvitationServiceInterface) *InvitationHandler {
+	return &InvitationHandler{
+		invitationService: invitationService,
+	}
+}
+
+// RegisterRoutes registers invitation routes
+// facilitatorMiddleware should authenticate the caller and require an admin role;
+// accepting an invitation is public since the token identifies the invitee
+func (h *InvitationHandler) RegisterRoutes(router *gin.Engine, facilitatorMiddleware ...gin.HandlerFunc) {
+	maps := router.Group("/api/maps", facilitatorMiddleware...)
+	{
+		maps.POST("/:mapId/invitations", h.InviteEmails)
+		maps.GET("/:mapId/invitations", h.ListInvitations)
+	}
+
+	router.POST("/api/invitations/accept", h.AcceptInvitation)
+}
+
+// InviteEmailsRequest represents the request body for inviting email addresses
+type InviteEmailsRequest struct {
+	Emails []string `json:"emails" binding:"required"`
+}
+
+// InviteEmailsResponse reports the outcome for every invited address
+type InviteEmailsResponse struct {
+	Results []services.InvitationResult `json:"results"`
+	Sent    int                         `json:"sent"`
+}
+
+// AcceptInvitationRequest represents the request body for accepting an invitation
+type AcceptInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// AcceptInvitationResponse returns the invited map and the invitee's guest profile
+type AcceptInvitationResponse struct {
+	MapID   string                `json:"mapId"`
+	Profile CreateProfileResponse `json:"profile"`
+}
+
+// InviteEmails handles POST /api/maps/:mapId/invitations
+func (h *InvitationHandler) InviteEmails(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Code:    "UNAUTHORIZED",
+			Message: "Authentication required",
+		})
+		return
+	}
+
+	var req InviteEmailsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	results, err := h.invitationService.InviteEmails(c.Request.Context(), c.Param("mapId"), userID, req.Emails)
+	if err != nil {
+		h.handleError(c, err, "Failed to send invitations")
+		return
+	}
+
+	sent := 0
+	for _, result := range results {
+		if result.Status == services.InvitationStatusSent {
+			sent++
+		}
+	}
+
+	c.JSON(http.StatusOK, InviteEmailsResponse{
+		Results: results,
+		Sent:    sent,
+	})
+}
+
+// ListInvitations handles GET /api/maps/:mapId/invitations
+func (h *InvitationHandler) ListInvitations(c *gin.Context) {
+	invitations, err := h.invitationService.ListInvitations(c.Request.Context(), c.Param("mapId"))
+	if err != nil {
+		h.handleError(c, err, "Failed to list invitations")
+		return
+	}
+
+	c.JSON(http.StatusOK, invitations)
+}
+
+// AcceptInvitation handles POST /api/invitations/accept
+func (h *InvitationHandler) AcceptInvitation(c *gin.Context) {
+	var req AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	invitation, user, err := h.invitationService.AcceptInvitation(c.Request.Context(), req.Token)
+	if err != nil {
+		h.handleError(c, err, "Failed to accept invitation")
+		return
+	}
+
+	c.JSON(http.StatusOK, AcceptInvitationResponse{
+		MapID: invitation.MapID,
+		Profile: CreateProfileResponse{
+			ID:          user.ID,
+			DisplayName: user.DisplayName,
+			AccountType: string(user.AccountType),
+			Role:        string(user.Role),
+			IsActive:    user.IsActive,
+			CreatedAt:   user.CreatedAt.Format(time.RFC3339),
+			AvatarURL:   stringPtrToString(user.AvatarURL),
+			AboutMe:     user.AboutMe,
+		},
+	})
+}
+
+// handleError maps invitation service errors to responses
+func (h *InvitationHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Code:    "MAP_NOT_FOUND",
+			Message: "Map not found",
+		})
+	case errors.Is(err, services.ErrNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Code:    "NOT_FOUND",
+			Message: "Invitation not found",
+		})
+	case strings.Contains(err.Error(), "invitation expired"):
+		c.JSON(http.StatusGone, ErrorResponse{
+			Code:    "INVITATION_EXPIRED",
+			Message: "Invitation has expired",
+		})
+	case strings.Contains(err.Error(), "invalid invitation"):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "VALIDATION_ERROR",
+			Message: message,
+			Details: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: message,
+			Details: err.Error(),
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type stubInvitationService struct {
+	invitedBy string
+	emails    []string
+}
+
+func (s *stubInvitationService) InviteEmails(ctx context.Context, mapID, invitedBy string, emails []string) ([]services.InvitationResult, error) {
+	if mapID != "map-1" {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if len(emails) == 0 {
+		return nil, fmt.Errorf("invalid invitation: at least one email address is required")
+	}
+	s.invitedBy, s.emails = invitedBy, emails
+
+	results := make([]services.InvitationResult, 0, len(emails))
+	for _, email := range emails {
+		status := services.InvitationStatusSent
+		if !strings.Contains(email, "@") {
+			status = services.InvitationStatusInvalid
+		}
+		results = append(results, services.InvitationResult{Email: email, Status: status})
+	}
+	return results, nil
+}
+
+func (s *stubInvitationService) AcceptInvitation(ctx context.Context, token string) (*models.Invitation, *models.User, error) {
+	switch token {
+	case "valid-token":
+		user, err := models.NewGuestUser("Jane Doe")
+		if err != nil {
+			return nil, nil, err
+		}
+		return &models.Invitation{MapID: "map-1", UserID: user.ID}, user, nil
+	case "expired-token":
+		return nil, nil, fmt.Errorf("invitation expired")
+	default:
+		return nil, nil, fmt.Errorf("invitation %w", services.ErrNotFound)
+	}
+}
+
+func (s *stubInvitationService) ListInvitations(ctx context.Context, mapID string) ([]*models.Invitation, error) {
+	return []*models.Invitation{{ID: "invitation-1", MapID: mapID, Email: "jane@example.com", ExpiresAt: time.Now()}}, nil
+}
+
+func setupInvitationTest() (*gin.Engine, *stubInvitationService) {
+	gin.SetMode(gin.TestMode)
+
+	service := &stubInvitationService{}
+	router := gin.New()
+	NewInvitationHandler(service).RegisterRoutes(router, func(c *gin.Context) {
+		c.Set("userID", "admin-1")
+		c.Next()
+	})
+	return router, service
+}
+
+func TestInvitationHandler_InviteEmails(t *testing.T) {
+	router, service := setupInvitationTest()
+
+	body := `{"emails":["jane@example.com","bob@example.com","nope"]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/maps/map-1/invitations", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response InviteEmailsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response.Results, 3)
+	assert.Equal(t, 2, response.Sent)
+	assert.Equal(t, "admin-1", service.invitedBy)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/maps/map-1/invitations", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "jane@example.com")
+}
+
+func TestInvitationHandler_InviteEmails_Errors(t *testing.T) {
+	router, _ := setupInvitationTest()
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+		code   string
+	}{
+		{"missing emails", "/api/maps/map-1/invitations", `{}`, http.StatusBadRequest, "INVALID_REQUEST"},
+		{"empty emails", "/api/maps/map-1/invitations", `{"emails":[]}`, http.StatusBadRequest, "VALIDATION_ERROR"},
+		{"unknown map", "/api/maps/missing/invitations", `{"emails":["jane@example.com"]}`, http.StatusNotFound, "MAP_NOT_FOUND"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			var response ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.code, response.Code)
+		})
+	}
+}
+
+func TestInvitationHandler_AcceptInvitation(t *testing.T) {
+	router, _ := setupInvitationTest()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/invitations/accept", strings.NewReader(`{"token":"valid-token"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response AcceptInvitationResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "map-1", response.MapID)
+	assert.Equal(t, "Jane Doe", response.Profile.DisplayName)
+	assert.Equal(t, "guest", response.Profile.AccountType)
+
+	for token, status := range map[string]int{
+		"expired-token": http.StatusGone,
+		"unknown-token": http.StatusNotFound,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/invitations/accept", strings.NewReader(`{"token":"`+token+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code, token)
+	}
+}
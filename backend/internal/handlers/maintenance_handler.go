@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaintenanceServiceInterface defines the interface for the server's maintenance mode
+type MaintenanceServiceInterface interface {
+	Status() services.Maintenance
+	SetMaintenance(ctx context.Context, userID string, enabled bool, message string) (services.Maintenance, error)
+}
+
+// MaintenanceHandler handles maintenance mode endpoints
+type MaintenanceHandler struct {
+	maintenanceService MaintenanceServiceInterface
+}
+
+// NewMaintenanceHandler creates a new MaintenanceHandler
+func NewMaintenanceHandler(maintenanceService MaintenanceServiceInterface) *MaintenanceHandler {
+	return &MaintenanceHandler{
+		maintenanceService: maintenanceService,
+	}
+}
+
+// RegisterRoutes registers the maintenance status route, which clients read
+// to show the maintenance banner before connecting to the WebSocket
+func (h *MaintenanceHandler) RegisterRoutes(router *gin.Engine) {
+	router.GET("/api/maintenance", h.GetMaintenance)
+}
+
+// RegisterAdminRoutes registers maintenance mode management routes
+// operatorMiddleware should authenticate the caller and require the superadmin
+// role, since maintenance mode applies to every map of the deployment
+func (h *MaintenanceHandler) RegisterAdminRoutes(router *gin.Engine, operatorMiddleware ...gin.HandlerFunc) {
+	admin := router.Group("/api/admin", operatorMiddleware...)
+	{
+		admin.GET("/maintenance", h.GetMaintenance)
+		admin.PUT("/maintenance", h.SetMaintenance)
+	}
+}
+
+// SetMaintenanceRequest represents the request body for changing maintenance mode
+type SetMaintenanceRequest struct {
+	Enabled *bool  `json:"enabled" binding:"required"`
+	Message string `json:"message"`
+}
+
+// GetMaintenance handles GET /api/maintenance and GET /api/admin/maintenance
+func (h *MaintenanceHandler) GetMaintenance(c *gin.Context) {
+	c.JSON(http.StatusOK, h.maintenanceService.Status())
+}
+
+// SetMaintenance handles PUT /api/admin/maintenance
+func (h *MaintenanceHandler) SetMaintenance(c *gin.Context) {
+	var req SetMaintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	maintenance, err := h.maintenanceService.SetMaintenance(c.Request.Context(), c.GetString("userID"), *req.Enabled, req.Message)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid maintenance mode",
+				Details: err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: "Failed to change maintenance mode",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, maintenance)
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubMaintenanceService struct {
+	maintenance services.Maintenance
+	userID      string
+	err         error
+}
+
+func (s *stubMaintenanceService) Status() services.Maintenance {
+	return s.maintenance
+}
+
+func (s *stubMaintenanceService) SetMaintenance(ctx context.Context, userID string, enabled bool, message string) (services.Maintenance, error) {
+	if s.err != nil {
+		return services.Maintenance{}, s.err
+	}
+	s.userID = userID
+	s.maintenance = services.Maintenance{Enabled: enabled, Message: message}
+	return s.maintenance, nil
+}
+
+func setupMaintenanceTest(service *stubMaintenanceService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	handler := NewMaintenanceHandler(service)
+	handler.RegisterRoutes(router)
+	handler.RegisterAdminRoutes(router, func(c *gin.Context) {
+		c.Set("userID", "admin-1")
+		c.Next()
+	})
+	return router
+}
+
+func TestMaintenanceHandler_SetAndGet(t *testing.T) {
+	service := &stubMaintenanceService{}
+	router := setupMaintenanceTest(service)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/admin/maintenance", strings.NewReader(`{"enabled":true,"message":"Upgrading"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "admin-1", service.userID)
+
+	// Anyone can read the maintenance mode
+	req = httptest.NewRequest(http.MethodGet, "/api/maintenance", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response services.Maintenance
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Enabled)
+	assert.Equal(t, "Upgrading", response.Message)
+}
+
+func TestMaintenanceHandler_SetMaintenance_InvalidRequest(t *testing.T) {
+	router := setupMaintenanceTest(&stubMaintenanceService{})
+
+	req := httptest.NewRequest(http.MethodPut, "/api/admin/maintenance", strings.NewReader(`{"message":"no flag"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "INVALID_REQUEST")
+}
+
+func TestMaintenanceHandler_SetMaintenance_ValidationError(t *testing.T) {
+	service := &stubMaintenanceService{err: fmt.Errorf("%w: message too long", services.ErrInvalidInput)}
+	router := setupMaintenanceTest(service)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/admin/maintenance", strings.NewReader(`{"enabled":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "VALIDATION_ERROR")
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxMapArchiveBytes bounds the size of imported map archives
+const maxMapArchiveBytes = 10 << 20
+
+// MapArchiveServiceInterface defines the interface for exporting and importing maps
+type MapArchiveServiceInterface interface {
+	ExportMap(ctx context.Context, mapID string) (*models.MapArchive, error)
+	ImportMap(ctx context.Context, archive *models.MapArchive, importedBy string) (*services.MapImportResult, error)
+}
+
+// MapArchiveHandler handles map export and import endpoints
+type MapArchiveHandler struct {
+	archiveService MapArchiveServiceInterface
+}
+
+// NewMapArchiveHandler creates a new MapArchiveHandler
+func NewMapArchiveHandler(archiveService MapArchiveServiceInterface) *MapArchiveHandler {
+	return &MapArchiveHandler{
+		archiveService: archiveService,
+	}
+}
+
+// RegisterRoutes registers map export and import routes
+// organizerMiddleware should authenticate the caller and require an organizer (admin) role
+func (h *MapArchiveHandler) RegisterRoutes(router *gin.Engine, organizerMiddleware ...gin.HandlerFunc) {
+	maps := router.Group("/api/maps", organizerMiddleware...)
+	{
+		maps.GET("/:mapId/export", h.ExportMap)
+		maps.POST("/import", h.ImportMap)
+	}
+}
+
+// ExportMap handles GET /api/maps/:mapId/export
+// The archive is returned as a JSON attachment
+func (h *MapArchiveHandler) ExportMap(c *gin.Context) {
+	mapID := c.Param("mapId")
+
+	archive, err := h.archiveService.ExportMap(c.Request.Context(), mapID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Code:    "MAP_NOT_FOUND",
+				Message: "Map not found",
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: "Failed to export map",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="map-%s-export.json"`, mapID))
+	c.JSON(http.StatusOK, archive)
+}
+
+// ImportMap handles POST /api/maps/import
+// The map and its POIs are recreated with fresh IDs and owned by the caller
+func (h *MapArchiveHandler) ImportMap(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Code:    "UNAUTHORIZED",
+			Message: "User ID required",
+		})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxMapArchiveBytes)
+
+	var archive models.MapArchive
+	if err := c.ShouldBindJSON(&archive); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	result, err := h.archiveService.ImportMap(c.Request.Context(), &archive, userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid archive") {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid map archive",
+				Details: err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: "Failed to import map",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type fakeArchiveStore struct {
+	maps map[string]*models.Map
+	pois map[string][]*models.POI
+}
+
+func (s *fakeArchiveStore) GetByID(ctx context.Context, id string) (*models.Map, error) {
+	m, exists := s.maps[id]
+	if !exists {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return m, nil
+}
+
+func (s *fakeArchiveStore) CreateWithPOIs(ctx context.Context, m *models.Map, pois []*models.POI) error {
+	s.maps[m.ID] = m
+	s.pois[m.ID] = pois
+	return nil
+}
+
+func (s *fakeArchiveStore) GetPOIsForMap(ctx context.Context, mapID string) ([]*models.POI, error) {
+	return s.pois[mapID], nil
+}
+
+func setupMapArchiveTest(userID string) (*gin.Engine, *fakeArchiveStore) {
+	gin.SetMode(gin.TestMode)
+
+	now := time.Now()
+	store := &fakeArchiveStore{
+		maps: map[string]*models.Map{
+			"map-1": {ID: "map-1", Name: "Workshop", CreatedBy: "organizer", CreatedAt: now,
+				PersonalSpace: models.PersonalSpace{Mode: models.PersonalSpaceAdjust, RadiusMeters: 5}},
+		},
+		pois: map[string][]*models.POI{
+			"map-1": {
+				{ID: "poi-1", MapID: "map-1", Name: "Coffee", Position: models.LatLng{Lat: 52.52, Lng: 13.405}, MaxParticipants: 8,
+					ImageURL: "https://cdn.example.com/pois/poi-1-original.jpg", ThumbnailURL: "https://cdn.example.com/pois/poi-1-thumb.jpg"},
+				{ID: "poi-2", MapID: "map-1", Name: "Lounge", Position: models.LatLng{Lat: 48.137, Lng: 11.575}, MaxParticipants: 10},
+			},
+		},
+	}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if userID != "" {
+			c.Set("userID", userID)
+		}
+		c.Next()
+	})
+	NewMapArchiveHandler(services.NewMapArchiveService(store, store)).RegisterRoutes(router)
+	return router, store
+}
+
+func TestMapArchiveHandler_ExportImport(t *testing.T) {
+	router, store := setupMapArchiveTest("importer")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/maps/map-1/export", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "map-map-1-export.json")
+
+	var archive models.MapArchive
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &archive))
+	assert.Equal(t, models.MapArchiveVersion, archive.Version)
+	assert.Len(t, archive.POIs, 2)
+	assert.Equal(t, []models.MapArchiveImage{{
+		POIID:        "poi-1",
+		ImageURL:     "https://cdn.example.com/pois/poi-1-original.jpg",
+		ThumbnailURL: "https://cdn.example.com/pois/poi-1-thumb.jpg",
+	}}, archive.Images)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/maps/import", bytes.NewReader(w.Body.Bytes()))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var result services.MapImportResult
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.NotEqual(t, "map-1", result.Map.ID)
+	assert.Equal(t, "importer", result.Map.CreatedBy)
+	assert.Equal(t, models.PersonalSpaceAdjust, result.Map.PersonalSpace.Mode)
+
+	// Everything is recreated with fresh IDs
+	imported := store.pois[result.Map.ID]
+	assert.Len(t, imported, 2)
+	for _, poi := range imported {
+		assert.NotEqual(t, "poi-1", poi.ID)
+		assert.NotEqual(t, "poi-2", poi.ID)
+		assert.Equal(t, result.Map.ID, poi.MapID)
+	}
+	coffee := imported[0]
+	assert.Equal(t, result.POIIDs["poi-1"], coffee.ID)
+	assert.Equal(t, 8, coffee.MaxParticipants)
+	assert.Equal(t, "https://cdn.example.com/pois/poi-1-thumb.jpg", coffee.ThumbnailURL)
+}
+
+func TestMapArchiveHandler_ExportUnknownMap(t *testing.T) {
+	router, _ := setupMapArchiveTest("importer")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/maps/missing/export", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestMapArchiveHandler_ImportInvalid(t *testing.T) {
+	router, _ := setupMapArchiveTest("importer")
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"unsupported version", `{"version":99,"map":{"name":"Workshop"}}`},
+		{"unknown image POI", `{"version":1,"map":{"name":"Workshop"},"images":[{"poiId":"poi-9","imageUrl":"https://cdn.example.com/a.jpg"}]}`},
+		{"unsafe image URL", `{"version":1,"map":{"name":"Workshop"},"pois":[{"id":"poi-1","name":"Coffee","position":{"lat":1,"lng":1}}],"images":[{"poiId":"poi-1","imageUrl":"javascript:alert(1)"}]}`},
+		{"invalid POI", `{"version":1,"map":{"name":"Workshop"},"pois":[{"id":"poi-1","name":"Coffee","position":{"lat":95,"lng":1}}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/maps/import", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var response ErrorResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, "VALIDATION_ERROR", response.Code)
+		})
+	}
+}
+
+func TestMapArchiveHandler_ImportRequiresUser(t *testing.T) {
+	router, _ := setupMapArchiveTest("")
+
+	req := httptest.NewRequest(http.MethodPost, "/api/maps/import", strings.NewReader(`{"version":1,"map":{"name":"Workshop"}}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
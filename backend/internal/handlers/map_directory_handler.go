@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// MapDirectoryServiceInterface defines the interface for the public map directory
+type MapDirectoryServiceInterface interface {
+	ListPublicMaps(ctx context.Context, query services.MapDirectoryQuery) ([]services.PublicMap, error)
+	GetListing(ctx context.Context, mapID string) (models.MapListing, error)
+	SetListing(ctx context.Context, mapID string, listing models.MapListing) (models.MapListing, error)
+}
+
+// MapDirectoryHandler handles the public map directory and the listing settings of maps
+type MapDirectoryHandler struct {
+	directoryService MapDirectoryServiceInterface
+}
+
+// NewMapDirectoryHandler creates a new MapDirectoryHandler
+func NewMapDirectoryHandler(directoryService MapDirectoryServiceInterface) *MapDirectoryHandler {
+	return &MapDirectoryHandler{
+		directoryService: directoryService,
+	}
+}
+
+// RegisterRoutes registers map directory routes
+// The directory itself is public; organizerMiddleware guards the listing settings of a map
+func (h *MapDirectoryHandler) RegisterRoutes(router *gin.Engine, organizerMiddleware ...gin.HandlerFunc) {
+	router.GET("/api/maps/public", h.ListPublicMaps)
+
+	maps := router.Group("/api/maps", organizerMiddleware...)
+	{
+		maps.GET("/:mapId/listing", h.GetListing)
+		maps.PUT("/:mapId/listing", h.SetListing)
+	}
+}
+
+// PublicMapsResponse represents the maps of the public directory
+type PublicMapsResponse struct {
+	Maps  []services.PublicMap `json:"maps"`
+	Count int                  `json:"count"`
+}
+
+// ListPublicMaps handles GET /api/maps/public
+// Supports ?tags=a,b (all must match), ?q= (name or description), ?sort=active|newest|name and ?limit=
+func (h *MapDirectoryHandler) ListPublicMaps(c *gin.Context) {
+	query := services.MapDirectoryQuery{
+		Query: c.Query("q"),
+		Sort:  c.Query("sort"),
+	}
+	for _, value := range c.QueryArray("tags") {
+		query.Tags = append(query.Tags, strings.Split(value, ",")...)
+	}
+	if limit := c.Query("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Code:    "INVALID_REQUEST",
+				Message: "limit must be a positive number",
+			})
+			return
+		}
+		query.Limit = parsed
+	}
+
+	maps, err := h.directoryService.ListPublicMaps(c.Request.Context(), query)
+	if err != nil {
+		h.handleError(c, err, "Failed to list public maps")
+		return
+	}
+
+	c.JSON(http.StatusOK, PublicMapsResponse{Maps: maps, Count: len(maps)})
+}
+
+// GetListing handles GET /api/maps/:mapId/listing
+func (h *MapDirectoryHandler) GetListing(c *gin.Context) {
+	listing, err := h.directoryService.GetListing(c.Request.Context(), c.Param("mapId"))
+	if err != nil {
+		h.handleError(c, err, "Failed to get map listing")
+		return
+	}
+
+	c.JSON(http.StatusOK, listing)
+}
+
+// SetListing handles PUT /api/maps/:mapId/listing
+func (h *MapDirectoryHandler) SetListing(c *gin.Context) {
+	var req models.MapListing
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	listing, err := h.directoryService.SetListing(c.Request.Context(), c.Param("mapId"), req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update map listing")
+		return
+	}
+
+	c.JSON(http.StatusOK, listing)
+}
+
+// handleError maps map directory service errors to responses
+func (h *MapDirectoryHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Code:    "MAP_NOT_FOUND",
+			Message: "Map not found",
+		})
+	case strings.Contains(err.Error(), "invalid listing"):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "VALIDATION_ERROR",
+			Message: message,
+			Details: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: message,
+			Details: err.Error(),
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type stubMapDirectoryService struct {
+	lastQuery services.MapDirectoryQuery
+	listings  map[string]models.MapListing
+}
+
+func (s *stubMapDirectoryService) ListPublicMaps(ctx context.Context, query services.MapDirectoryQuery) ([]services.PublicMap, error) {
+	s.lastQuery = query
+	if query.Sort == "popular" {
+		return nil, fmt.Errorf("invalid listing query: unknown sort %q", query.Sort)
+	}
+	return []services.PublicMap{{ID: "map-1", Name: "Zen garden", Tags: []string{"quiet"}, ParticipantCount: 4}}, nil
+}
+
+func (s *stubMapDirectoryService) GetListing(ctx context.Context, mapID string) (models.MapListing, error) {
+	listing, exists := s.listings[mapID]
+	if !exists {
+		return models.MapListing{}, gorm.ErrRecordNotFound
+	}
+	return listing, nil
+}
+
+func (s *stubMapDirectoryService) SetListing(ctx context.Context, mapID string, listing models.MapListing) (models.MapListing, error) {
+	if _, exists := s.listings[mapID]; !exists {
+		return models.MapListing{}, gorm.ErrRecordNotFound
+	}
+	if err := listing.Normalize(); err != nil {
+		return models.MapListing{}, fmt.Errorf("invalid listing: %w", err)
+	}
+	s.listings[mapID] = listing
+	return listing, nil
+}
+
+func setupMapDirectoryTest() (*gin.Engine, *stubMapDirectoryService) {
+	gin.SetMode(gin.TestMode)
+
+	service := &stubMapDirectoryService{listings: map[string]models.MapListing{"map-1": {}}}
+	router := gin.New()
+	NewMapDirectoryHandler(service).RegisterRoutes(router)
+	// Organizer routes on /api/maps/:mapId must not shadow the public directory
+	NewMapHandler(&stubSpawnPointService{}, &stubPersonalSpaceService{}).RegisterRoutes(router)
+	return router, service
+}
+
+func TestMapDirectoryHandler_ListPublicMaps(t *testing.T) {
+	router, service := setupMapDirectoryTest()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/maps/public?tags=tech,meetup&tags=agile&q=garden&sort=newest&limit=5", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response PublicMapsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Count)
+	assert.Equal(t, 4, response.Maps[0].ParticipantCount)
+	assert.Equal(t, services.MapDirectoryQuery{
+		Tags:  []string{"tech", "meetup", "agile"},
+		Query: "garden",
+		Sort:  "newest",
+		Limit: 5,
+	}, service.lastQuery)
+}
+
+func TestMapDirectoryHandler_ListPublicMaps_InvalidQuery(t *testing.T) {
+	router, _ := setupMapDirectoryTest()
+
+	for _, path := range []string{"/api/maps/public?limit=-1", "/api/maps/public?sort=popular"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, path)
+	}
+}
+
+func TestMapDirectoryHandler_SetListing(t *testing.T) {
+	router, service := setupMapDirectoryTest()
+
+	req := httptest.NewRequest(http.MethodPut, "/api/maps/map-1/listing", strings.NewReader(`{"discoverable":true,"tags":["Tech"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.MapListing{Discoverable: true, Tags: []string{"tech"}}, service.listings["map-1"])
+
+	req = httptest.NewRequest(http.MethodPut, "/api/maps/map-1/listing", strings.NewReader(`{"tags":["no spaces"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/maps/unknown/listing", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
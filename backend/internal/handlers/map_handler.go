@@ -0,0 +1,470 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// SpawnPointServiceInterface defines the interface for managing map spawn points
+type SpawnPointServiceInterface interface {
+	GetSpawnPoints(ctx context.Context, mapID string) ([]models.SpawnPoint, error)
+	SetSpawnPoints(ctx context.Context, mapID string, points []models.SpawnPoint) error
+}
+
+// PersonalSpaceServiceInterface defines the interface for managing the personal space setting of maps
+type PersonalSpaceServiceInterface interface {
+	GetPersonalSpace(ctx context.Context, mapID string) (models.PersonalSpace, error)
+	SetPersonalSpace(ctx context.Context, mapID string, personalSpace models.PersonalSpace) error
+}
+
+// SlowConsumerPolicyServiceInterface defines the interface for managing the slow consumer policy of maps
+type SlowConsumerPolicyServiceInterface interface {
+	GetSlowConsumerPolicy(ctx context.Context, mapID string) (models.SlowConsumerPolicy, error)
+	SetSlowConsumerPolicy(ctx context.Context, mapID string, policy models.SlowConsumerPolicy) error
+}
+
+// PositionPrivacyServiceInterface defines the interface for managing the position privacy setting of maps
+type PositionPrivacyServiceInterface interface {
+	GetPositionPrivacy(ctx context.Context, mapID string) (models.PositionPrivacy, error)
+	SetPositionPrivacy(ctx context.Context, mapID string, privacy models.PositionPrivacy) error
+}
+
+// ProfilePrivacyServiceInterface defines the interface for managing the profile privacy setting of maps
+type ProfilePrivacyServiceInterface interface {
+	GetProfilePrivacy(ctx context.Context, mapID string) (models.ProfilePrivacy, error)
+	SetProfilePrivacy(ctx context.Context, mapID string, privacy models.ProfilePrivacy) error
+}
+
+// GuestAnonymizationServiceInterface defines the interface for managing the guest anonymization setting of maps
+type GuestAnonymizationServiceInterface interface {
+	GetGuestAnonymization(ctx context.Context, mapID string) (models.GuestAnonymization, error)
+	SetGuestAnonymization(ctx context.Context, mapID string, anonymization models.GuestAnonymization) error
+}
+
+// MessageAuditPolicyServiceInterface defines the interface for managing the message audit policy of maps
+type MessageAuditPolicyServiceInterface interface {
+	GetMessageAuditPolicy(ctx context.Context, mapID string) (models.MessageAuditPolicy, error)
+	SetMessageAuditPolicy(ctx context.Context, mapID string, policy models.MessageAuditPolicy) error
+}
+
+// LocaleServiceInterface defines the interface for managing the locale of maps
+type LocaleServiceInterface interface {
+	GetLocale(ctx context.Context, mapID string) (models.MapLocale, error)
+	SetLocale(ctx context.Context, mapID string, locale models.MapLocale) (models.MapLocale, error)
+}
+
+// MapHandler handles map configuration endpoints
+type MapHandler struct {
+	spawnService    SpawnPointServiceInterface
+	settingsService PersonalSpaceServiceInterface
+	slowConsumer    SlowConsumerPolicyServiceInterface
+	privacy         PositionPrivacyServiceInterface
+	profilePrivacy  ProfilePrivacyServiceInterface
+	anonymization   GuestAnonymizationServiceInterface
+	messageAudit    MessageAuditPolicyServiceInterface
+	locale          LocaleServiceInterface
+}
+
+// NewMapHandler creates a new MapHandler
+func NewMapHandler(spawnService SpawnPointServiceInterface, settingsService PersonalSpaceServiceInterface) *MapHandler {
+	return &MapHandler{
+		spawnService:    spawnService,
+		settingsService: settingsService,
+	}
+}
+
+// SetSlowConsumerPolicyService enables configuring how maps treat clients that fall behind broadcasts
+func (h *MapHandler) SetSlowConsumerPolicyService(slowConsumer SlowConsumerPolicyServiceInterface) {
+	h.slowConsumer = slowConsumer
+}
+
+// SetPositionPrivacyService enables configuring coarse avatar positions for regular participants
+func (h *MapHandler) SetPositionPrivacyService(privacy PositionPrivacyServiceInterface) {
+	h.privacy = privacy
+}
+
+// SetProfilePrivacyService enables configuring which profile fields maps share with their participants
+func (h *MapHandler) SetProfilePrivacyService(profilePrivacy ProfilePrivacyServiceInterface) {
+	h.profilePrivacy = profilePrivacy
+}
+
+// SetGuestAnonymizationService enables configuring the anonymization of guests who left maps
+func (h *MapHandler) SetGuestAnonymizationService(anonymization GuestAnonymizationServiceInterface) {
+	h.anonymization = anonymization
+}
+
+// SetMessageAuditPolicyService enables configuring which WebSocket messages of maps are audited
+func (h *MapHandler) SetMessageAuditPolicyService(messageAudit MessageAuditPolicyServiceInterface) {
+	h.messageAudit = messageAudit
+}
+
+// SetLocaleService enables configuring the language of server-generated content of maps
+func (h *MapHandler) SetLocaleService(locale LocaleServiceInterface) {
+	h.locale = locale
+}
+
+// RegisterRoutes registers map configuration routes
+// organizerMiddleware should authenticate the caller and require an organizer (admin) role
+func (h *MapHandler) RegisterRoutes(router *gin.Engine, organizerMiddleware ...gin.HandlerFunc) {
+	maps := router.Group("/api/maps", organizerMiddleware...)
+	{
+		maps.GET("/:mapId/spawn-points", h.GetSpawnPoints)
+		maps.PUT("/:mapId/spawn-points", h.SetSpawnPoints)
+		maps.GET("/:mapId/personal-space", h.GetPersonalSpace)
+		maps.PUT("/:mapId/personal-space", h.SetPersonalSpace)
+		if h.slowConsumer != nil {
+			maps.GET("/:mapId/slow-consumer-policy", h.GetSlowConsumerPolicy)
+			maps.PUT("/:mapId/slow-consumer-policy", h.SetSlowConsumerPolicy)
+		}
+		if h.privacy != nil {
+			maps.GET("/:mapId/position-privacy", h.GetPositionPrivacy)
+			maps.PUT("/:mapId/position-privacy", h.SetPositionPrivacy)
+		}
+		if h.profilePrivacy != nil {
+			maps.GET("/:mapId/profile-privacy", h.GetProfilePrivacy)
+			maps.PUT("/:mapId/profile-privacy", h.SetProfilePrivacy)
+		}
+		if h.anonymization != nil {
+			maps.GET("/:mapId/guest-anonymization", h.GetGuestAnonymization)
+			maps.PUT("/:mapId/guest-anonymization", h.SetGuestAnonymization)
+		}
+		if h.messageAudit != nil {
+			maps.GET("/:mapId/message-audit-policy", h.GetMessageAuditPolicy)
+			maps.PUT("/:mapId/message-audit-policy", h.SetMessageAuditPolicy)
+		}
+		if h.locale != nil {
+			maps.GET("/:mapId/locale", h.GetLocale)
+			maps.PUT("/:mapId/locale", h.SetLocale)
+		}
+	}
+}
+
+// SpawnPointsRequest represents the spawn points of a map
+type SpawnPointsRequest struct {
+	SpawnPoints []models.SpawnPoint `json:"spawnPoints"`
+}
+
+// GetSpawnPoints handles GET /api/maps/:mapId/spawn-points
+func (h *MapHandler) GetSpawnPoints(c *gin.Context) {
+	mapID := c.Param("mapId")
+
+	points, err := h.spawnService.GetSpawnPoints(c.Request.Context(), mapID)
+	if err != nil {
+		h.handleError(c, err, "Failed to get spawn points")
+		return
+	}
+
+	c.JSON(http.StatusOK, SpawnPointsRequest{SpawnPoints: points})
+}
+
+// SetSpawnPoints handles PUT /api/maps/:mapId/spawn-points
+// An empty list removes the spawn points of the map
+func (h *MapHandler) SetSpawnPoints(c *gin.Context) {
+	mapID := c.Param("mapId")
+
+	var req SpawnPointsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+	if req.SpawnPoints == nil {
+		req.SpawnPoints = []models.SpawnPoint{}
+	}
+
+	if err := h.spawnService.SetSpawnPoints(c.Request.Context(), mapID, req.SpawnPoints); err != nil {
+		h.handleError(c, err, "Failed to update spawn points")
+		return
+	}
+
+	c.JSON(http.StatusOK, req)
+}
+
+// GetPersonalSpace handles GET /api/maps/:mapId/personal-space
+func (h *MapHandler) GetPersonalSpace(c *gin.Context) {
+	mapID := c.Param("mapId")
+
+	personalSpace, err := h.settingsService.GetPersonalSpace(c.Request.Context(), mapID)
+	if err != nil {
+		h.handleError(c, err, "Failed to get personal space")
+		return
+	}
+
+	c.JSON(http.StatusOK, personalSpace)
+}
+
+// SetPersonalSpace handles PUT /api/maps/:mapId/personal-space
+// An empty mode turns the personal space check off
+func (h *MapHandler) SetPersonalSpace(c *gin.Context) {
+	mapID := c.Param("mapId")
+
+	var req models.PersonalSpace
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if err := h.settingsService.SetPersonalSpace(c.Request.Context(), mapID, req); err != nil {
+		h.handleError(c, err, "Failed to update personal space")
+		return
+	}
+
+	c.JSON(http.StatusOK, req)
+}
+
+// GetSlowConsumerPolicy handles GET /api/maps/:mapId/slow-consumer-policy
+func (h *MapHandler) GetSlowConsumerPolicy(c *gin.Context) {
+	mapID := c.Param("mapId")
+
+	policy, err := h.slowConsumer.GetSlowConsumerPolicy(c.Request.Context(), mapID)
+	if err != nil {
+		h.handleError(c, err, "Failed to get slow consumer policy")
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// SetSlowConsumerPolicy handles PUT /api/maps/:mapId/slow-consumer-policy
+// The policy applies to clients connecting afterwards
+func (h *MapHandler) SetSlowConsumerPolicy(c *gin.Context) {
+	mapID := c.Param("mapId")
+
+	var req models.SlowConsumerPolicy
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if err := h.slowConsumer.SetSlowConsumerPolicy(c.Request.Context(), mapID, req); err != nil {
+		h.handleError(c, err, "Failed to update slow consumer policy")
+		return
+	}
+
+	c.JSON(http.StatusOK, req)
+}
+
+// GetPositionPrivacy handles GET /api/maps/:mapId/position-privacy
+func (h *MapHandler) GetPositionPrivacy(c *gin.Context) {
+	mapID := c.Param("mapId")
+
+	privacy, err := h.privacy.GetPositionPrivacy(c.Request.Context(), mapID)
+	if err != nil {
+		h.handleError(c, err, "Failed to get position privacy")
+		return
+	}
+
+	c.JSON(http.StatusOK, privacy)
+}
+
+// SetPositionPrivacy handles PUT /api/maps/:mapId/position-privacy
+// Positions broadcast afterwards use the new grid
+func (h *MapHandler) SetPositionPrivacy(c *gin.Context) {
+	mapID := c.Param("mapId")
+
+	var req models.PositionPrivacy
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if err := h.privacy.SetPositionPrivacy(c.Request.Context(), mapID, req); err != nil {
+		h.handleError(c, err, "Failed to update position privacy")
+		return
+	}
+
+	c.JSON(http.StatusOK, req)
+}
+
+// GetProfilePrivacy handles GET /api/maps/:mapId/profile-privacy
+func (h *MapHandler) GetProfilePrivacy(c *gin.Context) {
+	mapID := c.Param("mapId")
+
+	privacy, err := h.profilePrivacy.GetProfilePrivacy(c.Request.Context(), mapID)
+	if err != nil {
+		h.handleError(c, err, "Failed to get profile privacy")
+		return
+	}
+
+	c.JSON(http.StatusOK, privacy)
+}
+
+// SetProfilePrivacy handles PUT /api/maps/:mapId/profile-privacy
+// Presence and participant payloads built afterwards leave out the hidden fields
+func (h *MapHandler) SetProfilePrivacy(c *gin.Context) {
+	mapID := c.Param("mapId")
+
+	var req models.ProfilePrivacy
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if err := h.profilePrivacy.SetProfilePrivacy(c.Request.Context(), mapID, req); err != nil {
+		h.handleError(c, err, "Failed to update profile privacy")
+		return
+	}
+
+	c.JSON(http.StatusOK, req)
+}
+
+// GetGuestAnonymization handles GET /api/maps/:mapId/guest-anonymization
+func (h *MapHandler) GetGuestAnonymization(c *gin.Context) {
+	mapID := c.Param("mapId")
+
+	anonymization, err := h.anonymization.GetGuestAnonymization(c.Request.Context(), mapID)
+	if err != nil {
+		h.handleError(c, err, "Failed to get guest anonymization")
+		return
+	}
+
+	c.JSON(http.StatusOK, anonymization)
+}
+
+// SetGuestAnonymization handles PUT /api/maps/:mapId/guest-anonymization
+// Guests who already left are anonymized on the next run once their retention window passed
+func (h *MapHandler) SetGuestAnonymization(c *gin.Context) {
+	mapID := c.Param("mapId")
+
+	var req models.GuestAnonymization
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if err := h.anonymization.SetGuestAnonymization(c.Request.Context(), mapID, req); err != nil {
+		h.handleError(c, err, "Failed to update guest anonymization")
+		return
+	}
+
+	c.JSON(http.StatusOK, req)
+}
+
+// GetMessageAuditPolicy handles GET /api/maps/:mapId/message-audit-policy
+func (h *MapHandler) GetMessageAuditPolicy(c *gin.Context) {
+	mapID := c.Param("mapId")
+
+	policy, err := h.messageAudit.GetMessageAuditPolicy(c.Request.Context(), mapID)
+	if err != nil {
+		h.handleError(c, err, "Failed to get message audit policy")
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// SetMessageAuditPolicy handles PUT /api/maps/:mapId/message-audit-policy
+// Messages sent and received afterwards are sampled by the new policy
+func (h *MapHandler) SetMessageAuditPolicy(c *gin.Context) {
+	mapID := c.Param("mapId")
+
+	var req models.MessageAuditPolicy
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	if err := h.messageAudit.SetMessageAuditPolicy(c.Request.Context(), mapID, req); err != nil {
+		h.handleError(c, err, "Failed to update message audit policy")
+		return
+	}
+
+	c.JSON(http.StatusOK, req)
+}
+
+// GetLocale handles GET /api/maps/:mapId/locale
+func (h *MapHandler) GetLocale(c *gin.Context) {
+	mapID := c.Param("mapId")
+
+	locale, err := h.locale.GetLocale(c.Request.Context(), mapID)
+	if err != nil {
+		h.handleError(c, err, "Failed to get locale")
+		return
+	}
+
+	c.JSON(http.StatusOK, locale)
+}
+
+// SetLocale handles PUT /api/maps/:mapId/locale
+// Digest and invitation emails sent afterwards are written in the new locale
+func (h *MapHandler) SetLocale(c *gin.Context) {
+	mapID := c.Param("mapId")
+
+	var req models.MapLocale
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	locale, err := h.locale.SetLocale(c.Request.Context(), mapID, req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update locale")
+		return
+	}
+
+	c.JSON(http.StatusOK, locale)
+}
+
+// handleError maps map configuration service errors to responses
+func (h *MapHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Code:    "MAP_NOT_FOUND",
+			Message: "Map not found",
+		})
+	case strings.Contains(err.Error(), "invalid spawn points"), strings.Contains(err.Error(), "invalid personal space"),
+		strings.Contains(err.Error(), "invalid slow consumer policy"), strings.Contains(err.Error(), "invalid position privacy"),
+		strings.Contains(err.Error(), "invalid guest anonymization"), strings.Contains(err.Error(), "invalid message audit policy"),
+		strings.Contains(err.Error(), "invalid locale"):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "VALIDATION_ERROR",
+			Message: message,
+			Details: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: message,
+			Details: err.Error(),
+		})
+	}
+}
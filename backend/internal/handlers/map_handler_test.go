@@ -0,0 +1,483 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type stubSpawnPointService struct {
+	points map[string][]models.SpawnPoint
+}
+
+func (s *stubSpawnPointService) GetSpawnPoints(ctx context.Context, mapID string) ([]models.SpawnPoint, error) {
+	points, exists := s.points[mapID]
+	if !exists {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return points, nil
+}
+
+func (s *stubSpawnPointService) SetSpawnPoints(ctx context.Context, mapID string, points []models.SpawnPoint) error {
+	if _, exists := s.points[mapID]; !exists {
+		return gorm.ErrRecordNotFound
+	}
+	if err := models.ValidateSpawnPoints(points); err != nil {
+		return fmt.Errorf("invalid spawn points: %w", err)
+	}
+	s.points[mapID] = points
+	return nil
+}
+
+type stubPersonalSpaceService struct {
+	settings map[string]models.PersonalSpace
+}
+
+func (s *stubPersonalSpaceService) GetPersonalSpace(ctx context.Context, mapID string) (models.PersonalSpace, error) {
+	personalSpace, exists := s.settings[mapID]
+	if !exists {
+		return models.PersonalSpace{}, gorm.ErrRecordNotFound
+	}
+	return personalSpace, nil
+}
+
+func (s *stubPersonalSpaceService) SetPersonalSpace(ctx context.Context, mapID string, personalSpace models.PersonalSpace) error {
+	if _, exists := s.settings[mapID]; !exists {
+		return gorm.ErrRecordNotFound
+	}
+	if err := personalSpace.Validate(); err != nil {
+		return fmt.Errorf("invalid personal space: %w", err)
+	}
+	s.settings[mapID] = personalSpace
+	return nil
+}
+
+func setupMapTest() (*gin.Engine, *stubSpawnPointService) {
+	router, service, _ := setupMapSettingsTest()
+	return router, service
+}
+
+func setupMapSettingsTest() (*gin.Engine, *stubSpawnPointService, *stubPersonalSpaceService) {
+	gin.SetMode(gin.TestMode)
+
+	service := &stubSpawnPointService{points: map[string][]models.SpawnPoint{"map-1": {}}}
+	settings := &stubPersonalSpaceService{settings: map[string]models.PersonalSpace{"map-1": {}}}
+	router := gin.New()
+	NewMapHandler(service, settings).RegisterRoutes(router)
+	return router, service, settings
+}
+
+func TestMapHandler_SetSpawnPoints(t *testing.T) {
+	router, service := setupMapTest()
+
+	body := `{"spawnPoints":[{"position":{"lat":52.52,"lng":13.405},"radiusMeters":25}]}`
+	req := httptest.NewRequest(http.MethodPut, "/api/maps/map-1/spawn-points", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []models.SpawnPoint{{Position: models.LatLng{Lat: 52.52, Lng: 13.405}, RadiusMeters: 25}}, service.points["map-1"])
+
+	req = httptest.NewRequest(http.MethodGet, "/api/maps/map-1/spawn-points", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response SpawnPointsRequest
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Len(t, response.SpawnPoints, 1)
+}
+
+func TestMapHandler_SetSpawnPoints_Invalid(t *testing.T) {
+	router, _ := setupMapTest()
+
+	body := `{"spawnPoints":[{"position":{"lat":95,"lng":13.405}}]}`
+	req := httptest.NewRequest(http.MethodPut, "/api/maps/map-1/spawn-points", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response ErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "VALIDATION_ERROR", response.Code)
+}
+
+func TestMapHandler_UnknownMap(t *testing.T) {
+	router, _ := setupMapTest()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/maps/missing/spawn-points", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestMapHandler_SetPersonalSpace(t *testing.T) {
+	router, _, settings := setupMapSettingsTest()
+
+	body := `{"mode":"adjust","radiusMeters":8}`
+	req := httptest.NewRequest(http.MethodPut, "/api/maps/map-1/personal-space", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.PersonalSpace{Mode: models.PersonalSpaceAdjust, RadiusMeters: 8}, settings.settings["map-1"])
+
+	req = httptest.NewRequest(http.MethodGet, "/api/maps/map-1/personal-space", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.PersonalSpace
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, models.PersonalSpaceAdjust, response.Mode)
+
+	// Unknown modes are rejected
+	req = httptest.NewRequest(http.MethodPut, "/api/maps/map-1/personal-space", strings.NewReader(`{"mode":"bounce","radiusMeters":8}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var errResponse ErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &errResponse))
+	assert.Equal(t, "VALIDATION_ERROR", errResponse.Code)
+}
+
+type stubSlowConsumerPolicyService struct {
+	policies map[string]models.SlowConsumerPolicy
+}
+
+func (s *stubSlowConsumerPolicyService) GetSlowConsumerPolicy(ctx context.Context, mapID string) (models.SlowConsumerPolicy, error) {
+	policy, exists := s.policies[mapID]
+	if !exists {
+		return models.SlowConsumerPolicy{}, gorm.ErrRecordNotFound
+	}
+	return policy, nil
+}
+
+func (s *stubSlowConsumerPolicyService) SetSlowConsumerPolicy(ctx context.Context, mapID string, policy models.SlowConsumerPolicy) error {
+	if _, exists := s.policies[mapID]; !exists {
+		return gorm.ErrRecordNotFound
+	}
+	if err := policy.Validate(); err != nil {
+		return fmt.Errorf("invalid slow consumer policy: %w", err)
+	}
+	s.policies[mapID] = policy
+	return nil
+}
+
+func TestMapHandler_SetSlowConsumerPolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	policies := &stubSlowConsumerPolicyService{policies: map[string]models.SlowConsumerPolicy{"map-1": {}}}
+	handler := NewMapHandler(&stubSpawnPointService{}, &stubPersonalSpaceService{})
+	handler.SetSlowConsumerPolicyService(policies)
+	router := gin.New()
+	handler.RegisterRoutes(router)
+
+	body := `{"sendBufferSize":1024,"dropPolicy":"drop_oldest","evictAfter":500}`
+	req := httptest.NewRequest(http.MethodPut, "/api/maps/map-1/slow-consumer-policy", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.SlowConsumerPolicy{SendBufferSize: 1024, DropPolicy: models.SlowConsumerDropOldest, EvictAfter: 500}, policies.policies["map-1"])
+
+	req = httptest.NewRequest(http.MethodPut, "/api/maps/map-1/slow-consumer-policy", strings.NewReader(`{"dropPolicy":"drop_all"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/maps/missing/slow-consumer-policy", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+type stubPositionPrivacyService struct {
+	settings map[string]models.PositionPrivacy
+}
+
+func (s *stubPositionPrivacyService) GetPositionPrivacy(ctx context.Context, mapID string) (models.PositionPrivacy, error) {
+	privacy, exists := s.settings[mapID]
+	if !exists {
+		return models.PositionPrivacy{}, gorm.ErrRecordNotFound
+	}
+	return privacy, nil
+}
+
+func (s *stubPositionPrivacyService) SetPositionPrivacy(ctx context.Context, mapID string, privacy models.PositionPrivacy) error {
+	if _, exists := s.settings[mapID]; !exists {
+		return gorm.ErrRecordNotFound
+	}
+	if err := privacy.Validate(); err != nil {
+		return fmt.Errorf("invalid position privacy: %w", err)
+	}
+	s.settings[mapID] = privacy
+	return nil
+}
+
+func TestMapHandler_SetPositionPrivacy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	privacy := &stubPositionPrivacyService{settings: map[string]models.PositionPrivacy{"map-1": {}}}
+	handler := NewMapHandler(&stubSpawnPointService{}, &stubPersonalSpaceService{})
+	handler.SetPositionPrivacyService(privacy)
+	router := gin.New()
+	handler.RegisterRoutes(router)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/maps/map-1/position-privacy", strings.NewReader(`{"gridMeters":500}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.PositionPrivacy{GridMeters: 500}, privacy.settings["map-1"])
+
+	req = httptest.NewRequest(http.MethodPut, "/api/maps/map-1/position-privacy", strings.NewReader(`{"gridMeters":2}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/maps/missing/position-privacy", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+type stubProfilePrivacyService struct {
+	settings map[string]models.ProfilePrivacy
+}
+
+func (s *stubProfilePrivacyService) GetProfilePrivacy(ctx context.Context, mapID string) (models.ProfilePrivacy, error) {
+	privacy, exists := s.settings[mapID]
+	if !exists {
+		return models.ProfilePrivacy{}, gorm.ErrRecordNotFound
+	}
+	return privacy, nil
+}
+
+func (s *stubProfilePrivacyService) SetProfilePrivacy(ctx context.Context, mapID string, privacy models.ProfilePrivacy) error {
+	if _, exists := s.settings[mapID]; !exists {
+		return gorm.ErrRecordNotFound
+	}
+	s.settings[mapID] = privacy
+	return nil
+}
+
+func TestMapHandler_SetProfilePrivacy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	privacy := &stubProfilePrivacyService{settings: map[string]models.ProfilePrivacy{"map-1": {}}}
+	handler := NewMapHandler(&stubSpawnPointService{}, &stubPersonalSpaceService{})
+	handler.SetProfilePrivacyService(privacy)
+	router := gin.New()
+	handler.RegisterRoutes(router)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/maps/map-1/profile-privacy", strings.NewReader(`{"hideAboutMe":true,"hideAvatarUrl":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.ProfilePrivacy{HideAboutMe: true, HideAvatarURL: true}, privacy.settings["map-1"])
+
+	req = httptest.NewRequest(http.MethodGet, "/api/maps/map-1/profile-privacy", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"hideAboutMe":true,"hideAvatarUrl":true}`, w.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/api/maps/missing/profile-privacy", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+type stubGuestAnonymizationService struct {
+	settings map[string]models.GuestAnonymization
+}
+
+func (s *stubGuestAnonymizationService) GetGuestAnonymization(ctx context.Context, mapID string) (models.GuestAnonymization, error) {
+	anonymization, exists := s.settings[mapID]
+	if !exists {
+		return models.GuestAnonymization{}, gorm.ErrRecordNotFound
+	}
+	return anonymization, nil
+}
+
+func (s *stubGuestAnonymizationService) SetGuestAnonymization(ctx context.Context, mapID string, anonymization models.GuestAnonymization) error {
+	if _, exists := s.settings[mapID]; !exists {
+		return gorm.ErrRecordNotFound
+	}
+	if err := anonymization.Validate(); err != nil {
+		return fmt.Errorf("invalid guest anonymization: %w", err)
+	}
+	s.settings[mapID] = anonymization
+	return nil
+}
+
+func TestMapHandler_SetGuestAnonymization(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	anonymization := &stubGuestAnonymizationService{settings: map[string]models.GuestAnonymization{"map-1": {}}}
+	handler := NewMapHandler(&stubSpawnPointService{}, &stubPersonalSpaceService{})
+	handler.SetGuestAnonymizationService(anonymization)
+	router := gin.New()
+	handler.RegisterRoutes(router)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/maps/map-1/guest-anonymization", strings.NewReader(`{"enabled":true,"retentionHours":24}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.GuestAnonymization{Enabled: true, RetentionHours: 24}, anonymization.settings["map-1"])
+
+	req = httptest.NewRequest(http.MethodPut, "/api/maps/map-1/guest-anonymization", strings.NewReader(`{"enabled":true,"retentionHours":-5}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/maps/missing/guest-anonymization", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+type stubMessageAuditPolicyService struct {
+	policies map[string]models.MessageAuditPolicy
+}
+
+func (s *stubMessageAuditPolicyService) GetMessageAuditPolicy(ctx context.Context, mapID string) (models.MessageAuditPolicy, error) {
+	policy, exists := s.policies[mapID]
+	if !exists {
+		return models.MessageAuditPolicy{}, gorm.ErrRecordNotFound
+	}
+	return policy, nil
+}
+
+func (s *stubMessageAuditPolicyService) SetMessageAuditPolicy(ctx context.Context, mapID string, policy models.MessageAuditPolicy) error {
+	if _, exists := s.policies[mapID]; !exists {
+		return gorm.ErrRecordNotFound
+	}
+	if err := policy.Validate(); err != nil {
+		return fmt.Errorf("invalid message audit policy: %w", err)
+	}
+	s.policies[mapID] = policy
+	return nil
+}
+
+func TestMapHandler_SetMessageAuditPolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	messageAudit := &stubMessageAuditPolicyService{policies: map[string]models.MessageAuditPolicy{"map-1": {}}}
+	handler := NewMapHandler(&stubSpawnPointService{}, &stubPersonalSpaceService{})
+	handler.SetMessageAuditPolicyService(messageAudit)
+	router := gin.New()
+	handler.RegisterRoutes(router)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/maps/map-1/message-audit-policy", strings.NewReader(`{"sampleRate":0.01,"flaggedSessions":["session-1"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.MessageAuditPolicy{SampleRate: 0.01, FlaggedSessions: []string{"session-1"}}, messageAudit.policies["map-1"])
+
+	req = httptest.NewRequest(http.MethodPut, "/api/maps/map-1/message-audit-policy", strings.NewReader(`{"sampleRate":2}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/maps/missing/message-audit-policy", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+type stubLocaleService struct {
+	locales map[string]string
+}
+
+func (s *stubLocaleService) GetLocale(ctx context.Context, mapID string) (models.MapLocale, error) {
+	locale, exists := s.locales[mapID]
+	if !exists {
+		return models.MapLocale{}, gorm.ErrRecordNotFound
+	}
+	return models.MapLocale{Locale: locale}, nil
+}
+
+func (s *stubLocaleService) SetLocale(ctx context.Context, mapID string, locale models.MapLocale) (models.MapLocale, error) {
+	if _, exists := s.locales[mapID]; !exists {
+		return models.MapLocale{}, gorm.ErrRecordNotFound
+	}
+	normalized, err := models.NormalizeMapLocale(locale.Locale)
+	if err != nil {
+		return models.MapLocale{}, fmt.Errorf("invalid locale: %w", err)
+	}
+	s.locales[mapID] = normalized
+	return models.MapLocale{Locale: normalized}, nil
+}
+
+func TestMapHandler_SetLocale(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	locales := &stubLocaleService{locales: map[string]string{"map-1": models.DefaultMapLocale}}
+	handler := NewMapHandler(&stubSpawnPointService{}, &stubPersonalSpaceService{})
+	handler.SetLocaleService(locales)
+	router := gin.New()
+	handler.RegisterRoutes(router)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/maps/map-1/locale", strings.NewReader(`{"locale":"de-DE"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"locale":"de"}`, w.Body.String())
+	assert.Equal(t, "de", locales.locales["map-1"])
+
+	req = httptest.NewRequest(http.MethodPut, "/api/maps/map-1/locale", strings.NewReader(`{"locale":"xx"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/maps/missing/locale", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// MapOwnershipServiceInterface defines the interface for transferring and deleting maps
+type MapOwnershipServiceInterface interface {
+	TransferOwnership(ctx context.Context, mapID string, actor services.MapActor, newOwnerID string) (*models.Map, error)
+	RequestDeletion(ctx context.Context, mapID string, actor services.MapActor) (*services.MapDeletionTicket, error)
+	GetDeletionRequest(ctx context.Context, mapID string, actor services.MapActor) (*models.MapDeletionRequest, error)
+	CancelDeletion(ctx context.Context, mapID string, actor services.MapActor) error
+	ConfirmDeletion(ctx context.Context, mapID string, actor services.MapActor, token string) (*models.MapSnapshot, error)
+	RestoreSnapshot(ctx context.Context, snapshotID string, actor services.MapActor) (*services.MapImportResult, error)
+}
+
+// MapOwnershipHandler handles map ownership transfer and deletion endpoints
+type MapOwnershipHandler struct {
+	ownershipService MapOwnershipServiceInterface
+}
+
+// NewMapOwnershipHandler creates a new MapOwnershipHandler
+func NewMapOwnershipHandler(ownershipService MapOwnershipServiceInterface) *MapOwnershipHandler {
+	return &MapOwnershipHandler{
+		ownershipService: ownershipService,
+	}
+}
+
+// RegisterRoutes registers map ownership routes
+// organizerMiddleware should authenticate the caller and require an organizer (admin) role
+func (h *MapOwnershipHandler) RegisterRoutes(router *gin.Engine, organizerMiddleware ...gin.HandlerFunc) {
+	maps := router.Group("/api/maps", organizerMiddleware...)
+	{
+		maps.PUT("/:mapId/owner", h.TransferOwnership)
+		maps.POST("/:mapId/deletion", h.RequestDeletion)
+		maps.GET("/:mapId/deletion", h.GetDeletionRequest)
+		maps.DELETE("/:mapId/deletion", h.CancelDeletion)
+		maps.POST("/:mapId/deletion/confirm", h.ConfirmDeletion)
+		maps.POST("/snapshots/:snapshotId/restore", h.RestoreSnapshot)
+	}
+}
+
+// TransferOwnershipRequest names the new owner of a map
+type TransferOwnershipRequest struct {
+	UserID string `json:"userId" binding:"required"`
+}
+
+// ConfirmDeletionRequest carries the token of a map deletion request
+type ConfirmDeletionRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// MapDeletedResponse represents a deleted map and the snapshot stored with the deletion
+type MapDeletedResponse struct {
+	MapID    string              `json:"mapId"`
+	Snapshot *models.MapSnapshot `json:"snapshot"`
+}
+
+// TransferOwnership handles PUT /api/maps/:mapId/owner
+// The new owner must be an organizer; a pending deletion request is cancelled
+func (h *MapOwnershipHandler) TransferOwnership(c *gin.Context) {
+	var req TransferOwnershipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	m, err := h.ownershipService.TransferOwnership(c.Request.Context(), c.Param("mapId"), mapActor(c), req.UserID)
+	if err != nil {
+		h.handleError(c, err, "Failed to transfer map")
+		return
+	}
+
+	c.JSON(http.StatusOK, m)
+}
+
+// RequestDeletion handles POST /api/maps/:mapId/deletion
+// The returned token confirms the deletion once the cool-down passed
+func (h *MapOwnershipHandler) RequestDeletion(c *gin.Context) {
+	ticket, err := h.ownershipService.RequestDeletion(c.Request.Context(), c.Param("mapId"), mapActor(c))
+	if err != nil {
+		h.handleError(c, err, "Failed to request map deletion")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, ticket)
+}
+
+// GetDeletionRequest handles GET /api/maps/:mapId/deletion
+func (h *MapOwnershipHandler) GetDeletionRequest(c *gin.Context) {
+	request, err := h.ownershipService.GetDeletionRequest(c.Request.Context(), c.Param("mapId"), mapActor(c))
+	if err != nil {
+		h.handleError(c, err, "Failed to get map deletion request")
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
+
+// CancelDeletion handles DELETE /api/maps/:mapId/deletion
+func (h *MapOwnershipHandler) CancelDeletion(c *gin.Context) {
+	if err := h.ownershipService.CancelDeletion(c.Request.Context(), c.Param("mapId"), mapActor(c)); err != nil {
+		h.handleError(c, err, "Failed to cancel map deletion")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ConfirmDeletion handles POST /api/maps/:mapId/deletion/confirm
+// The map's snapshot is stored with the deletion and returned, so it can be
+// restored with POST /api/maps/snapshots/:snapshotId/restore
+func (h *MapOwnershipHandler) ConfirmDeletion(c *gin.Context) {
+	var req ConfirmDeletionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	mapID := c.Param("mapId")
+	snapshot, err := h.ownershipService.ConfirmDeletion(c.Request.Context(), mapID, mapActor(c), req.Token)
+	if err != nil {
+		h.handleError(c, err, "Failed to delete map")
+		return
+	}
+
+	c.JSON(http.StatusOK, MapDeletedResponse{
+		MapID:    mapID,
+		Snapshot: snapshot,
+	})
+}
+
+// RestoreSnapshot handles POST /api/maps/snapshots/:snapshotId/restore
+// The deleted map is recreated with a new ID, owned by its previous owner
+func (h *MapOwnershipHandler) RestoreSnapshot(c *gin.Context) {
+	result, err := h.ownershipService.RestoreSnapshot(c.Request.Context(), c.Param("snapshotId"), mapActor(c))
+	if err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Code:    "SNAPSHOT_NOT_FOUND",
+				Message: "Snapshot not found",
+			})
+			return
+		}
+		h.handleError(c, err, "Failed to restore map")
+		return
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// mapActor returns the signed-in user changing a map's ownership
+func mapActor(c *gin.Context) services.MapActor {
+	actor := services.MapActor{UserID: c.GetString("userID")}
+	if role, exists := c.Get("role"); exists {
+		actor.Role, _ = role.(models.UserRole)
+	}
+	return actor
+}
+
+// handleError maps map ownership service errors to responses
+func (h *MapOwnershipHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Code:    "MAP_NOT_FOUND",
+			Message: "Map not found",
+		})
+	case errors.Is(err, services.ErrNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Code:    "NO_PENDING_DELETION",
+			Message: message,
+			Details: err.Error(),
+		})
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Code:    "FORBIDDEN",
+			Message: message,
+			Details: err.Error(),
+		})
+	case errors.Is(err, services.ErrDeletionCoolingDown):
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Code:    "DELETION_COOLING_DOWN",
+			Message: message,
+			Details: err.Error(),
+		})
+	case errors.Is(err, services.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "VALIDATION_ERROR",
+			Message: message,
+			Details: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: message,
+			Details: err.Error(),
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type fakeMapOwnershipService struct {
+	actor   services.MapActor
+	pending bool
+	deleted bool
+}
+
+func (s *fakeMapOwnershipService) TransferOwnership(ctx context.Context, mapID string, actor services.MapActor, newOwnerID string) (*models.Map, error) {
+	s.actor = actor
+	if mapID != "map-1" {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if newOwnerID == "participant" {
+		return nil, fmt.Errorf("%w: new owner must be an organizer", services.ErrInvalidInput)
+	}
+	return &models.Map{ID: mapID, CreatedBy: newOwnerID}, nil
+}
+
+func (s *fakeMapOwnershipService) RequestDeletion(ctx context.Context, mapID string, actor services.MapActor) (*services.MapDeletionTicket, error) {
+	if actor.UserID != "owner" {
+		return nil, fmt.Errorf("%w: only the owner can delete the map", services.ErrForbidden)
+	}
+	s.pending = true
+	now := time.Now()
+	return &services.MapDeletionTicket{Token: "token-1", ConfirmableAt: now.Add(models.MapDeletionCooldown), ExpiresAt: now.Add(models.MapDeletionValidity)}, nil
+}
+
+func (s *fakeMapOwnershipService) GetDeletionRequest(ctx context.Context, mapID string, actor services.MapActor) (*models.MapDeletionRequest, error) {
+	if !s.pending {
+		return nil, fmt.Errorf("%w: no pending deletion request", services.ErrNotFound)
+	}
+	return &models.MapDeletionRequest{RequestedBy: "owner"}, nil
+}
+
+func (s *fakeMapOwnershipService) CancelDeletion(ctx context.Context, mapID string, actor services.MapActor) error {
+	if !s.pending {
+		return fmt.Errorf("%w: no pending deletion request", services.ErrNotFound)
+	}
+	s.pending = false
+	return nil
+}
+
+func (s *fakeMapOwnershipService) ConfirmDeletion(ctx context.Context, mapID string, actor services.MapActor, token string) (*models.MapSnapshot, error) {
+	if token != "token-1" {
+		return nil, fmt.Errorf("%w: invalid deletion token", services.ErrInvalidInput)
+	}
+	if s.pending {
+		return nil, fmt.Errorf("%w: try again later", services.ErrDeletionCoolingDown)
+	}
+	s.deleted = true
+	return &models.MapSnapshot{ID: "snapshot-1", MapID: mapID}, nil
+}
+
+func (s *fakeMapOwnershipService) RestoreSnapshot(ctx context.Context, snapshotID string, actor services.MapActor) (*services.MapImportResult, error) {
+	if snapshotID != "snapshot-1" {
+		return nil, fmt.Errorf("%w: snapshot %s does not exist", services.ErrNotFound, snapshotID)
+	}
+	return &services.MapImportResult{Map: &models.Map{ID: "map-2", CreatedBy: "owner"}}, nil
+}
+
+func setupMapOwnershipTest(userID string) (*gin.Engine, *fakeMapOwnershipService) {
+	gin.SetMode(gin.TestMode)
+
+	service := &fakeMapOwnershipService{}
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("userID", userID)
+		c.Set("role", models.UserRoleAdmin)
+		c.Next()
+	})
+	NewMapOwnershipHandler(service).RegisterRoutes(router)
+	return router, service
+}
+
+func serveMapOwnership(router *gin.Engine, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var payload bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&payload).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestMapOwnershipHandler_TransferOwnership(t *testing.T) {
+	router, service := setupMapOwnershipTest("owner")
+
+	w := serveMapOwnership(router, http.MethodPut, "/api/maps/map-1/owner", TransferOwnershipRequest{UserID: "organizer"})
+	require.Equal(t, http.StatusOK, w.Code)
+	var m models.Map
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &m))
+	assert.Equal(t, "organizer", m.CreatedBy)
+	assert.Equal(t, services.MapActor{UserID: "owner", Role: models.UserRoleAdmin}, service.actor)
+
+	w = serveMapOwnership(router, http.MethodPut, "/api/maps/map-1/owner", TransferOwnershipRequest{UserID: "participant"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = serveMapOwnership(router, http.MethodPut, "/api/maps/map-1/owner", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = serveMapOwnership(router, http.MethodPut, "/api/maps/missing/owner", TransferOwnershipRequest{UserID: "organizer"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "MAP_NOT_FOUND")
+}
+
+func TestMapOwnershipHandler_DeletionFlow(t *testing.T) {
+	router, service := setupMapOwnershipTest("owner")
+
+	w := serveMapOwnership(router, http.MethodGet, "/api/maps/map-1/deletion", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "NO_PENDING_DELETION")
+
+	w = serveMapOwnership(router, http.MethodPost, "/api/maps/map-1/deletion", nil)
+	require.Equal(t, http.StatusAccepted, w.Code)
+	var ticket services.MapDeletionTicket
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &ticket))
+	assert.Equal(t, "token-1", ticket.Token)
+
+	w = serveMapOwnership(router, http.MethodGet, "/api/maps/map-1/deletion", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "token")
+
+	// The deletion can't be confirmed during the cool-down
+	w = serveMapOwnership(router, http.MethodPost, "/api/maps/map-1/deletion/confirm", ConfirmDeletionRequest{Token: "token-1"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	w = serveMapOwnership(router, http.MethodPost, "/api/maps/map-1/deletion/confirm", ConfirmDeletionRequest{Token: "wrong"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = serveMapOwnership(router, http.MethodDelete, "/api/maps/map-1/deletion", nil)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = serveMapOwnership(router, http.MethodPost, "/api/maps/map-1/deletion/confirm", ConfirmDeletionRequest{Token: "token-1"})
+	require.Equal(t, http.StatusOK, w.Code)
+	var deleted MapDeletedResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &deleted))
+	assert.Equal(t, "map-1", deleted.MapID)
+	require.NotNil(t, deleted.Snapshot)
+	assert.True(t, service.deleted)
+
+	w = serveMapOwnership(router, http.MethodPost, "/api/maps/snapshots/"+deleted.Snapshot.ID+"/restore", nil)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var restored services.MapImportResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &restored))
+	assert.Equal(t, "map-2", restored.Map.ID)
+
+	w = serveMapOwnership(router, http.MethodPost, "/api/maps/snapshots/missing/restore", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "SNAPSHOT_NOT_FOUND")
+}
+
+func TestMapOwnershipHandler_RequestDeletion_Forbidden(t *testing.T) {
+	router, service := setupMapOwnershipTest("organizer")
+
+	w := serveMapOwnership(router, http.MethodPost, "/api/maps/map-1/deletion", nil)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "FORBIDDEN")
+	assert.False(t, service.pending)
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AbuseGuardInterface defines the interface for managing automatic restrictions
+type AbuseGuardInterface interface {
+	GetRestrictions(ctx context.Context, userID string) []*services.Restriction
+	LiftRestriction(ctx context.Context, userID string, restriction services.RestrictionType) error
+}
+
+// ModerationHandler handles admin moderation endpoints
+type ModerationHandler struct {
+	abuseGuard AbuseGuardInterface
+}
+
+// NewModerationHandler creates a new ModerationHandler
+func NewModerationHandler(abuseGuard AbuseGuardInterface) *ModerationHandler {
+	return &ModerationHandler{
+		abuseGuard: abuseGuard,
+	}
+}
+
+// RegisterRoutes registers moderation routes
+// adminMiddleware should authenticate the caller and require an admin role
+func (h *ModerationHandler) RegisterRoutes(router *gin.Engine, adminMiddleware ...gin.HandlerFunc) {
+	admin := router.Group("/api/admin", adminMiddleware...)
+	{
+		admin.GET("/restrictions/:userId", h.GetRestrictions)
+		admin.DELETE("/restrictions/:userId", h.LiftRestriction)
+	}
+}
+
+// Request/Response DTOs
+
+// RestrictionsResponse represents the active restrictions for a user
+type RestrictionsResponse struct {
+	UserID       string                  `json:"userId"`
+	Restrictions []*services.Restriction `json:"restrictions"`
+}
+
+// GetRestrictions handles GET /api/admin/restrictions/:userId
+func (h *ModerationHandler) GetRestrictions(c *gin.Context) {
+	userID := c.Param("userId")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "User ID is required",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, RestrictionsResponse{
+		UserID:       userID,
+		Restrictions: h.abuseGuard.GetRestrictions(c.Request.Context(), userID),
+	})
+}
+
+// LiftRestriction handles DELETE /api/admin/restrictions/:userId
+// The optional "type" query parameter lifts a single restriction type
+func (h *ModerationHandler) LiftRestriction(c *gin.Context) {
+	userID := c.Param("userId")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "User ID is required",
+		})
+		return
+	}
+
+	restrictionType := services.RestrictionType(c.Query("type"))
+	if err := h.abuseGuard.LiftRestriction(c.Request.Context(), userID, restrictionType); err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid restriction type",
+				Details: err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: "Failed to lift restriction",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, RestrictionsResponse{
+		UserID:       userID,
+		Restrictions: h.abuseGuard.GetRestrictions(c.Request.Context(), userID),
+	})
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupModerationTest() (*gin.Engine, *services.AbuseGuard) {
+	gin.SetMode(gin.TestMode)
+
+	guard := services.NewAbuseGuard([]services.AbuseRule{
+		{
+			Name:        "rejected_calls",
+			Signal:      services.SignalCallRejected,
+			Threshold:   1,
+			Window:      time.Minute,
+			Restriction: services.RestrictionBlockCalls,
+			Duration:    time.Minute,
+		},
+	})
+
+	router := gin.New()
+	NewModerationHandler(guard).RegisterRoutes(router)
+	return router, guard
+}
+
+func TestModerationHandler_GetRestrictions(t *testing.T) {
+	router, guard := setupModerationTest()
+	guard.RecordSignal(context.Background(), "user-1", "map-1", services.SignalCallRejected)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/restrictions/user-1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response RestrictionsResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "user-1", response.UserID)
+	assert.Len(t, response.Restrictions, 1)
+	assert.Equal(t, services.RestrictionBlockCalls, response.Restrictions[0].Type)
+}
+
+func TestModerationHandler_LiftRestriction(t *testing.T) {
+	router, guard := setupModerationTest()
+	guard.RecordSignal(context.Background(), "user-1", "map-1", services.SignalCallRejected)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/admin/restrictions/user-1?type=block_calls", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, guard.IsRestricted(context.Background(), "user-1", services.RestrictionBlockCalls))
+}
+
+func TestModerationHandler_LiftRestriction_InvalidType(t *testing.T) {
+	router, _ := setupModerationTest()
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/admin/restrictions/user-1?type=bogus", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response ErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "VALIDATION_ERROR", response.Code)
+}
@@ -3,17 +3,18 @@ package handlers
 import (
 	"context"
 	"errors"
+	"fmt"
 	"mime/multipart"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	"breakoutglobe/internal/middleware"
 	"breakoutglobe/internal/models"
 	"breakoutglobe/internal/services"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
 // POIServiceInterface defines the interface for POI service operations
@@ -31,6 +32,7 @@ type POIServiceInterface interface {
 	GetPOIParticipantCount(ctx context.Context, poiID string) (int, error)
 	GetPOIParticipantsWithInfo(ctx context.Context, poiID string) ([]services.POIParticipantInfo, error)
 	GetUserPOIs(ctx context.Context, userID string) ([]string, error)
+	GetCurrentPOI(ctx context.Context, userID string) (string, error)
 	ValidatePOI(ctx context.Context, poiID string) (*models.POI, error)
 	ClearAllPOIs(ctx context.Context, mapID string) error
 }
@@ -40,11 +42,24 @@ type POIUserServiceInterface interface {
 	GetUser(ctx context.Context, userID string) (*models.User, error)
 }
 
+// POIRSVPCounterInterface defines the interface for counting RSVPs to scheduled POIs
+type POIRSVPCounterInterface interface {
+	GetRSVPCounts(ctx context.Context, poiIDs []string) (map[string]models.RSVPCounts, error)
+}
+
+// POIPopularityInterface defines the interface for counting recent joins of POIs
+type POIPopularityInterface interface {
+	GetPOIPopularity(ctx context.Context, poiIDs []string) (map[string]models.POIPopularity, error)
+}
+
 // POIHandler handles HTTP requests for POI operations
 type POIHandler struct {
 	poiService  POIServiceInterface
 	userService POIUserServiceInterface
 	rateLimiter services.RateLimiterInterface
+	rsvpCounter POIRSVPCounterInterface
+	popularity  POIPopularityInterface
+	identity    gin.HandlerFunc
 }
 
 // NewPOIHandler creates a new POIHandler instance
@@ -56,6 +71,23 @@ func NewPOIHandler(poiService POIServiceInterface, userService POIUserServiceInt
 	}
 }
 
+// SetRSVPCounter sets the source of RSVP counts included for scheduled POIs
+func (h *POIHandler) SetRSVPCounter(rsvpCounter POIRSVPCounterInterface) {
+	h.rsvpCounter = rsvpCounter
+}
+
+// SetPopularity sets the source of the recent joins included for POIs, so
+// clients can badge trending ones
+func (h *POIHandler) SetPopularity(popularity POIPopularityInterface) {
+	h.popularity = popularity
+}
+
+// SetIdentityMiddleware sets the middleware that resolves the acting user for
+// joining and leaving POIs. It must be set before RegisterRoutes.
+func (h *POIHandler) SetIdentityMiddleware(identity gin.HandlerFunc) {
+	h.identity = identity
+}
+
 // RegisterRoutes registers POI-related routes
 // authMiddleware is optional - if provided, it will be applied to write operations
 func (h *POIHandler) RegisterRoutes(router *gin.Engine, authMiddleware ...gin.HandlerFunc) {
@@ -65,30 +97,31 @@ func (h *POIHandler) RegisterRoutes(router *gin.Engine, authMiddleware ...gin.Ha
 		api.GET("/pois", h.GetPOIs)
 		api.GET("/pois/:poiId", h.GetPOI)
 		api.GET("/pois/:poiId/participants", h.GetPOIParticipants)
+		api.GET("/users/:userId/current-poi", h.GetUserCurrentPOI)
 		
-		// POI management - write operations require authentication
-		if len(authMiddleware) > 0 {
-			api.POST("/pois", append(authMiddleware, h.CreatePOI)...)
-			api.PUT("/pois/:poiId", append(authMiddleware, h.UpdatePOI)...)
-			api.DELETE("/pois/:poiId", append(authMiddleware, h.DeletePOI)...)
-			
-			// POI participation requires authentication
-			api.POST("/pois/:poiId/join", append(authMiddleware, h.JoinPOI)...)
-			api.POST("/pois/:poiId/leave", append(authMiddleware, h.LeavePOI)...)
-		} else {
-			// Fallback for backward compatibility (no auth)
-			api.POST("/pois", h.CreatePOI)
-			api.PUT("/pois/:poiId", h.UpdatePOI)
-			api.DELETE("/pois/:poiId", h.DeletePOI)
-			api.POST("/pois/:poiId/join", h.JoinPOI)
-			api.POST("/pois/:poiId/leave", h.LeavePOI)
-		}
+		// POI management - write operations require authentication if middleware provided
+		api.POST("/pois", rateLimited(h.rateLimiter, services.ActionCreatePOI, middleware.RateLimitByField("", "createdBy"), authMiddleware, h.CreatePOI)...)
+		api.PUT("/pois/:poiId", rateLimited(h.rateLimiter, services.ActionUpdatePOI, middleware.RateLimitByUser, authMiddleware, h.UpdatePOI)...)
+		api.DELETE("/pois/:poiId", rateLimited(h.rateLimiter, services.ActionDeletePOI, middleware.RateLimitByUser, authMiddleware, h.DeletePOI)...)
+		
+		// POI participation acts for the user resolved by the identity middleware
+		api.POST("/pois/:poiId/join", rateLimited(h.rateLimiter, services.ActionJoinPOI, middleware.RateLimitByUser, h.withIdentity(authMiddleware), h.JoinPOI)...)
+		api.POST("/pois/:poiId/leave", rateLimited(h.rateLimiter, services.ActionLeavePOI, middleware.RateLimitByUser, h.withIdentity(authMiddleware), h.LeavePOI)...)
 		
 		// Development endpoints (TODO: Remove in production)
 		api.DELETE("/pois/dev/clear-all", h.ClearAllPOIs)
 	}
 }
 
+// withIdentity appends the identity middleware, if set, to authMiddleware
+func (h *POIHandler) withIdentity(authMiddleware []gin.HandlerFunc) []gin.HandlerFunc {
+	chain := append([]gin.HandlerFunc{}, authMiddleware...)
+	if h.identity != nil {
+		chain = append(chain, h.identity)
+	}
+	return chain
+}
+
 // Request/Response DTOs
 
 // CreatePOIRequest represents the request body for creating a POI
@@ -112,21 +145,26 @@ type CreatePOIResponse struct {
 	MaxParticipants int           `json:"maxParticipants"`
 	ImageURL        string        `json:"imageUrl,omitempty"`
 	ThumbnailURL    string        `json:"thumbnailUrl,omitempty"`
+	StartsAt        *time.Time    `json:"startsAt,omitempty"`
 	CreatedAt       time.Time     `json:"createdAt"`
 }
 
 // GetPOIResponse represents the response for getting a POI
+// RSVPs are only included for scheduled POIs
 type GetPOIResponse struct {
-	ID              string        `json:"id"`
-	MapID           string        `json:"mapId"`
-	Name            string        `json:"name"`
-	Description     string        `json:"description"`
-	Position        models.LatLng `json:"position"`
-	CreatedBy       string        `json:"createdBy"`
-	MaxParticipants int           `json:"maxParticipants"`
-	ImageURL        string        `json:"imageUrl,omitempty"`
-	ThumbnailURL    string        `json:"thumbnailUrl,omitempty"`
-	CreatedAt       time.Time     `json:"createdAt"`
+	ID              string             `json:"id"`
+	MapID           string             `json:"mapId"`
+	Name            string             `json:"name"`
+	Description     string             `json:"description"`
+	Position        models.LatLng      `json:"position"`
+	CreatedBy       string             `json:"createdBy"`
+	MaxParticipants int                `json:"maxParticipants"`
+	ImageURL        string             `json:"imageUrl,omitempty"`
+	ThumbnailURL    string             `json:"thumbnailUrl,omitempty"`
+	StartsAt        *time.Time         `json:"startsAt,omitempty"`
+	RSVPs           *models.RSVPCounts `json:"rsvps,omitempty"`
+	PopularSince    *models.POIPopularity `json:"popularSince,omitempty"`
+	CreatedAt       time.Time          `json:"createdAt"`
 }
 
 // POIInfo represents POI information in list responses
@@ -147,6 +185,13 @@ type POIInfo struct {
 	DiscussionStartTime *time.Time `json:"discussionStartTime,omitempty"`
 	IsDiscussionActive  bool       `json:"isDiscussionActive"`
 	
+	// Scheduled event fields - RSVPs are only included for scheduled POIs
+	StartsAt *time.Time         `json:"startsAt,omitempty"`
+	RSVPs    *models.RSVPCounts `json:"rsvps,omitempty"`
+	
+	// Users who joined within the popularity window, if joins are recorded
+	PopularSince *models.POIPopularity `json:"popularSince,omitempty"`
+	
 	CreatedAt       time.Time          `json:"createdAt"`
 }
 
@@ -164,11 +209,15 @@ type GetPOIsResponse struct {
 	Count int       `json:"count"`
 }
 
-// UpdatePOIRequest represents the request body for updating a POI
+// UpdatePOIRequest represents the request body for updating a POI. It is a
+// partial update: omitted fields keep their value, while fields present in the
+// body are set, so "description": "" clears the description. maxParticipants
+// cannot be lowered below the number of current participants.
 type UpdatePOIRequest struct {
-	Name            string `json:"name,omitempty"`
-	Description     string `json:"description,omitempty"`
-	MaxParticipants int    `json:"maxParticipants,omitempty"`
+	Name            *string    `json:"name,omitempty"`
+	Description     *string    `json:"description,omitempty"`
+	MaxParticipants *int       `json:"maxParticipants,omitempty"`
+	StartsAt        *time.Time `json:"startsAt,omitempty"` // Schedules an event at the POI
 }
 
 // UpdatePOIResponse represents the response for updating a POI
@@ -182,12 +231,22 @@ type UpdatePOIResponse struct {
 	MaxParticipants int           `json:"maxParticipants"`
 	ImageURL        string        `json:"imageUrl,omitempty"`
 	ThumbnailURL    string        `json:"thumbnailUrl,omitempty"`
+	StartsAt        *time.Time    `json:"startsAt,omitempty"`
 	CreatedAt       time.Time     `json:"createdAt"`
+	
+	// CurrentCount can exceed maxParticipants if a join raced with lowering it
+	CurrentCount int `json:"currentCount"`
+}
+
+// CapacityConflictResponse represents a rejected maxParticipants change
+type CapacityConflictResponse struct {
+	ErrorResponse
+	CurrentCount int `json:"currentCount"`
 }
 
 // JoinPOIRequest represents the request body for joining a POI
 type JoinPOIRequest struct {
-	UserID string `json:"userId" binding:"required"`
+	UserID string `json:"userId"` // Optional; must match the acting user when set
 }
 
 // JoinPOIResponse represents the response for joining a POI
@@ -199,7 +258,7 @@ type JoinPOIResponse struct {
 
 // LeavePOIRequest represents the request body for leaving a POI
 type LeavePOIRequest struct {
-	UserID string `json:"userId" binding:"required"`
+	UserID string `json:"userId"` // Optional; must match the acting user when set
 }
 
 // LeavePOIResponse represents the response for leaving a POI
@@ -216,6 +275,12 @@ type GetPOIParticipantsResponse struct {
 	Count        int      `json:"count"`
 }
 
+// CurrentPOIResponse represents the POI a user is currently in
+type CurrentPOIResponse struct {
+	UserID       string  `json:"userId"`
+	CurrentPOIID *string `json:"currentPoiId"` // null if the user isn't in a POI
+}
+
 // GetPOIs handles GET /api/pois
 func (h *POIHandler) GetPOIs(c *gin.Context) {
 	mapID := c.Query("mapId")
@@ -273,6 +338,10 @@ func (h *POIHandler) GetPOIs(c *gin.Context) {
 		return
 	}
 	
+	// RSVP counts of scheduled POIs and recent joins
+	rsvpCounts := h.getRSVPCounts(c, pois)
+	popularity := h.getPopularity(c, pois)
+	
 	// Convert to response format with participant information
 	poiInfos := make([]POIInfo, len(pois))
 	for i, poi := range pois {
@@ -320,6 +389,11 @@ func (h *POIHandler) GetPOIs(c *gin.Context) {
 			DiscussionStartTime: poi.DiscussionStartTime,
 			IsDiscussionActive:  isDiscussionActive,
 			
+			StartsAt: poi.StartsAt,
+			RSVPs:    rsvpCounts[poi.ID],
+			
+			PopularSince: popularity[poi.ID],
+			
 			CreatedAt:        poi.CreatedAt,
 		}
 	}
@@ -365,12 +439,6 @@ func (h *POIHandler) CreatePOI(c *gin.Context) {
 		return
 	}
 	
-	// Check rate limit
-	if err := h.rateLimiter.CheckRateLimit(c, req.CreatedBy, services.ActionCreatePOI); err != nil {
-		h.handleRateLimitError(c, err)
-		return
-	}
-	
 	// Set default max participants if not provided
 	maxParticipants := req.MaxParticipants
 	if maxParticipants <= 0 {
@@ -380,7 +448,7 @@ func (h *POIHandler) CreatePOI(c *gin.Context) {
 	// Create POI
 	poi, err := h.poiService.CreatePOI(c, req.MapID, req.Name, req.Description, req.Position, req.CreatedBy, maxParticipants)
 	if err != nil {
-		if isDuplicateLocationError(err) {
+		if errors.Is(err, services.ErrDuplicateLocation) {
 			c.JSON(http.StatusConflict, ErrorResponse{
 				Code:    "DUPLICATE_LOCATION",
 				Message: "A POI already exists at this location",
@@ -388,6 +456,14 @@ func (h *POIHandler) CreatePOI(c *gin.Context) {
 			return
 		}
 		
+		if errors.Is(err, services.ErrMapFrozen) {
+			c.JSON(http.StatusLocked, ErrorResponse{
+				Code:    "MAP_FROZEN",
+				Message: "The map is frozen by a facilitator",
+			})
+			return
+		}
+		
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Code:    "INTERNAL_ERROR",
 			Message: "Failed to create POI",
@@ -396,9 +472,6 @@ func (h *POIHandler) CreatePOI(c *gin.Context) {
 		return
 	}
 	
-	// Add rate limit headers
-	h.addRateLimitHeaders(c, req.CreatedBy, services.ActionCreatePOI)
-	
 	// Return response
 	response := CreatePOIResponse{
 		ID:              poi.ID,
@@ -410,6 +483,7 @@ func (h *POIHandler) CreatePOI(c *gin.Context) {
 		MaxParticipants: poi.MaxParticipants,
 		ImageURL:        poi.ImageURL,
 		ThumbnailURL:    poi.ThumbnailURL,
+		StartsAt:        poi.StartsAt,
 		CreatedAt:       poi.CreatedAt,
 	}
 	
@@ -439,12 +513,6 @@ func (h *POIHandler) createPOIFromForm(c *gin.Context) {
 		return
 	}
 	
-	// Check rate limit
-	if err := h.rateLimiter.CheckRateLimit(c, req.CreatedBy, services.ActionCreatePOI); err != nil {
-		h.handleRateLimitError(c, err)
-		return
-	}
-	
 	// Set default max participants if not provided
 	maxParticipants := req.MaxParticipants
 	if maxParticipants <= 0 {
@@ -461,7 +529,7 @@ func (h *POIHandler) createPOIFromForm(c *gin.Context) {
 	}
 	
 	if err != nil {
-		if isDuplicateLocationError(err) {
+		if errors.Is(err, services.ErrDuplicateLocation) {
 			c.JSON(http.StatusConflict, ErrorResponse{
 				Code:    "DUPLICATE_LOCATION",
 				Message: "A POI already exists at this location",
@@ -469,6 +537,14 @@ func (h *POIHandler) createPOIFromForm(c *gin.Context) {
 			return
 		}
 		
+		if errors.Is(err, services.ErrMapFrozen) {
+			c.JSON(http.StatusLocked, ErrorResponse{
+				Code:    "MAP_FROZEN",
+				Message: "The map is frozen by a facilitator",
+			})
+			return
+		}
+		
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Code:    "INTERNAL_ERROR",
 			Message: "Failed to create POI",
@@ -477,9 +553,6 @@ func (h *POIHandler) createPOIFromForm(c *gin.Context) {
 		return
 	}
 	
-	// Add rate limit headers
-	h.addRateLimitHeaders(c, req.CreatedBy, services.ActionCreatePOI)
-	
 	// Return response
 	response := CreatePOIResponse{
 		ID:              poi.ID,
@@ -491,6 +564,7 @@ func (h *POIHandler) createPOIFromForm(c *gin.Context) {
 		MaxParticipants: poi.MaxParticipants,
 		ImageURL:        poi.ImageURL,
 		ThumbnailURL:    poi.ThumbnailURL,
+		StartsAt:        poi.StartsAt,
 		CreatedAt:       poi.CreatedAt,
 	}
 	
@@ -511,7 +585,7 @@ func (h *POIHandler) GetPOI(c *gin.Context) {
 	// Get POI
 	poi, err := h.poiService.GetPOI(c, poiID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+		if errors.Is(err, services.ErrNotFound) {
 			c.JSON(http.StatusNotFound, ErrorResponse{
 				Code:    "POI_NOT_FOUND",
 				Message: "POI not found",
@@ -538,6 +612,9 @@ func (h *POIHandler) GetPOI(c *gin.Context) {
 		MaxParticipants: poi.MaxParticipants,
 		ImageURL:        poi.ImageURL,
 		ThumbnailURL:    poi.ThumbnailURL,
+		StartsAt:        poi.StartsAt,
+		RSVPs:           h.getRSVPCounts(c, []*models.POI{poi})[poi.ID],
+		PopularSince:    h.getPopularity(c, []*models.POI{poi})[poi.ID],
 		CreatedAt:       poi.CreatedAt,
 	}
 	
@@ -570,12 +647,13 @@ func (h *POIHandler) UpdatePOI(c *gin.Context) {
 		Name:            req.Name,
 		Description:     req.Description,
 		MaxParticipants: req.MaxParticipants,
+		StartsAt:        req.StartsAt,
 	}
 	
 	// Update POI
 	poi, err := h.poiService.UpdatePOI(c, poiID, updateData)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+		if errors.Is(err, services.ErrNotFound) {
 			c.JSON(http.StatusNotFound, ErrorResponse{
 				Code:    "POI_NOT_FOUND",
 				Message: "POI not found",
@@ -583,6 +661,36 @@ func (h *POIHandler) UpdatePOI(c *gin.Context) {
 			return
 		}
 		
+		var capacityErr *services.CapacityBelowOccupancyError
+		if errors.As(err, &capacityErr) {
+			c.JSON(http.StatusConflict, CapacityConflictResponse{
+				ErrorResponse: ErrorResponse{
+					Code:    "CAPACITY_BELOW_OCCUPANCY",
+					Message: "Max participants cannot be below the current participant count",
+					Details: err.Error(),
+				},
+				CurrentCount: capacityErr.CurrentCount,
+			})
+			return
+		}
+		
+		if errors.Is(err, services.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid POI update",
+				Details: err.Error(),
+			})
+			return
+		}
+		
+		if errors.Is(err, services.ErrMapFrozen) {
+			c.JSON(http.StatusLocked, ErrorResponse{
+				Code:    "MAP_FROZEN",
+				Message: "The map is frozen by a facilitator",
+			})
+			return
+		}
+		
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Code:    "INTERNAL_ERROR",
 			Message: "Failed to update POI",
@@ -592,6 +700,13 @@ func (h *POIHandler) UpdatePOI(c *gin.Context) {
 	}
 	
 	// Return response
+	// Get participant count
+	currentCount, err := h.poiService.GetPOIParticipantCount(c, poi.ID)
+	if err != nil {
+		// Log error but don't fail the request
+		currentCount = 0
+	}
+	
 	response := UpdatePOIResponse{
 		ID:              poi.ID,
 		MapID:           poi.MapID,
@@ -602,7 +717,9 @@ func (h *POIHandler) UpdatePOI(c *gin.Context) {
 		MaxParticipants: poi.MaxParticipants,
 		ImageURL:        poi.ImageURL,
 		ThumbnailURL:    poi.ThumbnailURL,
+		StartsAt:        poi.StartsAt,
 		CreatedAt:       poi.CreatedAt,
+		CurrentCount:    currentCount,
 	}
 	
 	c.JSON(http.StatusOK, response)
@@ -621,7 +738,7 @@ func (h *POIHandler) DeletePOI(c *gin.Context) {
 	
 	// Delete POI
 	if err := h.poiService.DeletePOI(c, poiID); err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+		if errors.Is(err, services.ErrNotFound) {
 			c.JSON(http.StatusNotFound, ErrorResponse{
 				Code:    "POI_NOT_FOUND",
 				Message: "POI not found",
@@ -629,6 +746,14 @@ func (h *POIHandler) DeletePOI(c *gin.Context) {
 			return
 		}
 		
+		if errors.Is(err, services.ErrMapFrozen) {
+			c.JSON(http.StatusLocked, ErrorResponse{
+				Code:    "MAP_FROZEN",
+				Message: "The map is frozen by a facilitator",
+			})
+			return
+		}
+		
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Code:    "INTERNAL_ERROR",
 			Message: "Failed to delete POI",
@@ -665,15 +790,14 @@ func (h *POIHandler) JoinPOI(c *gin.Context) {
 		return
 	}
 	
-	// Check rate limit
-	if err := h.rateLimiter.CheckRateLimit(c, req.UserID, services.ActionJoinPOI); err != nil {
-		h.handleRateLimitError(c, err)
+	userID, ok := actingUserID(c, req.UserID)
+	if !ok {
 		return
 	}
 	
 	// Join POI
-	if err := h.poiService.JoinPOI(c, poiID, req.UserID); err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+	if err := h.poiService.JoinPOI(c, poiID, userID); err != nil {
+		if errors.Is(err, services.ErrNotFound) {
 			c.JSON(http.StatusNotFound, ErrorResponse{
 				Code:    "POI_NOT_FOUND",
 				Message: "POI not found",
@@ -681,7 +805,7 @@ func (h *POIHandler) JoinPOI(c *gin.Context) {
 			return
 		}
 		
-		if isCapacityExceededError(err) {
+		if errors.Is(err, services.ErrCapacityExceeded) {
 			c.JSON(http.StatusConflict, ErrorResponse{
 				Code:    "CAPACITY_EXCEEDED",
 				Message: "POI has reached maximum capacity",
@@ -689,7 +813,7 @@ func (h *POIHandler) JoinPOI(c *gin.Context) {
 			return
 		}
 		
-		if isAlreadyJoinedError(err) {
+		if errors.Is(err, services.ErrAlreadyJoined) {
 			c.JSON(http.StatusConflict, ErrorResponse{
 				Code:    "ALREADY_JOINED",
 				Message: "User has already joined this POI",
@@ -697,6 +821,14 @@ func (h *POIHandler) JoinPOI(c *gin.Context) {
 			return
 		}
 		
+		if errors.Is(err, services.ErrMapFrozen) {
+			c.JSON(http.StatusLocked, ErrorResponse{
+				Code:    "MAP_FROZEN",
+				Message: "The map is frozen by a facilitator",
+			})
+			return
+		}
+		
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Code:    "INTERNAL_ERROR",
 			Message: "Failed to join POI",
@@ -705,14 +837,11 @@ func (h *POIHandler) JoinPOI(c *gin.Context) {
 		return
 	}
 	
-	// Add rate limit headers
-	h.addRateLimitHeaders(c, req.UserID, services.ActionJoinPOI)
-	
 	// Return response
 	response := JoinPOIResponse{
 		Success: true,
 		POIID:   poiID,
-		UserID:  req.UserID,
+		UserID:  userID,
 	}
 	
 	c.JSON(http.StatusOK, response)
@@ -739,9 +868,14 @@ func (h *POIHandler) LeavePOI(c *gin.Context) {
 		return
 	}
 	
+	userID, ok := actingUserID(c, req.UserID)
+	if !ok {
+		return
+	}
+	
 	// Leave POI
-	if err := h.poiService.LeavePOI(c, poiID, req.UserID); err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+	if err := h.poiService.LeavePOI(c, poiID, userID); err != nil {
+		if errors.Is(err, services.ErrNotFound) {
 			c.JSON(http.StatusNotFound, ErrorResponse{
 				Code:    "POI_NOT_FOUND",
 				Message: "POI not found",
@@ -749,6 +883,14 @@ func (h *POIHandler) LeavePOI(c *gin.Context) {
 			return
 		}
 		
+		if errors.Is(err, services.ErrMapFrozen) {
+			c.JSON(http.StatusLocked, ErrorResponse{
+				Code:    "MAP_FROZEN",
+				Message: "The map is frozen by a facilitator",
+			})
+			return
+		}
+		
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Code:    "INTERNAL_ERROR",
 			Message: "Failed to leave POI",
@@ -761,7 +903,7 @@ func (h *POIHandler) LeavePOI(c *gin.Context) {
 	response := LeavePOIResponse{
 		Success: true,
 		POIID:   poiID,
-		UserID:  req.UserID,
+		UserID:  userID,
 	}
 	
 	c.JSON(http.StatusOK, response)
@@ -781,7 +923,7 @@ func (h *POIHandler) GetPOIParticipants(c *gin.Context) {
 	// Get participants
 	participants, err := h.poiService.GetPOIParticipants(c, poiID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+		if errors.Is(err, services.ErrNotFound) {
 			c.JSON(http.StatusNotFound, ErrorResponse{
 				Code:    "POI_NOT_FOUND",
 				Message: "POI not found",
@@ -807,6 +949,28 @@ func (h *POIHandler) GetPOIParticipants(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// GetUserCurrentPOI handles GET /api/users/:userId/current-poi
+func (h *POIHandler) GetUserCurrentPOI(c *gin.Context) {
+	userID := c.Param("userId")
+	
+	poiID, err := h.poiService.GetCurrentPOI(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: "Failed to get current POI",
+			Details: err.Error(),
+		})
+		return
+	}
+	
+	response := CurrentPOIResponse{UserID: userID}
+	if poiID != "" {
+		response.CurrentPOIID = &poiID
+	}
+	
+	c.JSON(http.StatusOK, response)
+}
+
 // Helper methods
 
 // parseCreatePOIForm parses multipart form data into CreatePOIRequest
@@ -878,54 +1042,83 @@ func (h *POIHandler) validateCreatePOIRequest(req CreatePOIRequest) error {
 	return nil
 }
 
-// handleRateLimitError handles rate limit errors
-func (h *POIHandler) handleRateLimitError(c *gin.Context, err error) {
-	if rateLimitErr, ok := err.(*services.RateLimitError); ok {
-		// Set Retry-After header
-		c.Header("Retry-After", strconv.Itoa(int(rateLimitErr.RetryAfter.Seconds())))
-		
-		c.JSON(http.StatusTooManyRequests, ErrorResponse{
-			Code:    "RATE_LIMIT_EXCEEDED",
-			Message: "Rate limit exceeded",
-			Details: err.Error(),
-		})
-		return
+// getRSVPCounts returns the RSVP counts of the scheduled POIs among pois.
+// Counts are best effort, so failures leave them out.
+func (h *POIHandler) getRSVPCounts(c *gin.Context, pois []*models.POI) map[string]*models.RSVPCounts {
+	counts := make(map[string]*models.RSVPCounts)
+	if h.rsvpCounter == nil {
+		return counts
 	}
 	
-	// Generic rate limit error
-	c.JSON(http.StatusTooManyRequests, ErrorResponse{
-		Code:    "RATE_LIMIT_EXCEEDED",
-		Message: "Rate limit exceeded",
-		Details: err.Error(),
-	})
-}
-
-// addRateLimitHeaders adds rate limit headers to the response
-func (h *POIHandler) addRateLimitHeaders(c *gin.Context, userID string, action services.ActionType) {
-	headers, err := h.rateLimiter.GetRateLimitHeaders(c, userID, action)
+	var scheduled []string
+	for _, poi := range pois {
+		if poi.IsScheduled() {
+			scheduled = append(scheduled, poi.ID)
+		}
+	}
+	if len(scheduled) == 0 {
+		return counts
+	}
+	
+	rsvpCounts, err := h.rsvpCounter.GetRSVPCounts(c.Request.Context(), scheduled)
 	if err != nil {
-		// Log error but don't fail the request
-		return
+		fmt.Printf("Warning: failed to get RSVP counts: %v\n", err)
+		return counts
 	}
 	
-	for key, value := range headers {
-		c.Header(key, value)
+	for _, poiID := range scheduled {
+		poiCounts := rsvpCounts[poiID]
+		counts[poiID] = &poiCounts
 	}
+	return counts
 }
 
-// isDuplicateLocationError checks if the error indicates duplicate location
-func isDuplicateLocationError(err error) bool {
-	return err != nil && strings.Contains(err.Error(), "duplicate POI location")
-}
-
-// isCapacityExceededError checks if the error indicates capacity exceeded
-func isCapacityExceededError(err error) bool {
-	return err != nil && strings.Contains(err.Error(), "capacity exceeded")
+// getPopularity returns the recent joins of POIs by ID, or none if they
+// aren't counted or can't be told
+func (h *POIHandler) getPopularity(c *gin.Context, pois []*models.POI) map[string]*models.POIPopularity {
+	popularity := make(map[string]*models.POIPopularity)
+	if h.popularity == nil || len(pois) == 0 {
+		return popularity
+	}
+	
+	poiIDs := make([]string, len(pois))
+	for i, poi := range pois {
+		poiIDs[i] = poi.ID
+	}
+	
+	joins, err := h.popularity.GetPOIPopularity(c.Request.Context(), poiIDs)
+	if err != nil {
+		fmt.Printf("Warning: failed to get POI popularity: %v\n", err)
+		return popularity
+	}
+	
+	for poiID, poiPopularity := range joins {
+		poiPopularity := poiPopularity
+		popularity[poiID] = &poiPopularity
+	}
+	return popularity
 }
 
-// isAlreadyJoinedError checks if the error indicates user already joined
-func isAlreadyJoinedError(err error) bool {
-	return err != nil && strings.Contains(err.Error(), "already joined")
+// actingUserID returns the user a request acts for, which the identity
+// middleware resolved. A user ID in the body must match it; it never stands in
+// for a missing identity.
+func actingUserID(c *gin.Context, bodyUserID string) (string, bool) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Code:    "UNAUTHORIZED",
+			Message: "Sign in or join the map to act as a user",
+		})
+		return "", false
+	}
+	if bodyUserID != "" && bodyUserID != userID {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Code:    "USER_MISMATCH",
+			Message: "User ID does not match the authenticated user",
+		})
+		return "", false
+	}
+	return userID, true
 }
 
 // ClearAllPOIs handles DELETE /api/pois/dev/clear-all - Development endpoint to clear all POIs
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// benchPOIService serves a fixed POI list with a few participants per POI.
+// Unlisted methods are not used by GetPOIs.
+type benchPOIService struct {
+	POIServiceInterface
+	pois         []*models.POI
+	participants []services.POIParticipantInfo
+}
+
+func (s *benchPOIService) GetPOIsForMap(ctx context.Context, mapID string) ([]*models.POI, error) {
+	return s.pois, nil
+}
+
+func (s *benchPOIService) GetPOIParticipantCount(ctx context.Context, poiID string) (int, error) {
+	return len(s.participants), nil
+}
+
+func (s *benchPOIService) GetPOIParticipantsWithInfo(ctx context.Context, poiID string) ([]services.POIParticipantInfo, error) {
+	return s.participants, nil
+}
+
+// BenchmarkGetPOIs measures listing a map with many POIs, including the
+// per-POI participant lookups and JSON encoding
+func BenchmarkGetPOIs(b *testing.B) {
+	gin.SetMode(gin.TestMode)
+
+	for _, poiCount := range []int{100, 10000} {
+		b.Run(fmt.Sprintf("pois=%d", poiCount), func(b *testing.B) {
+			service := &benchPOIService{
+				pois: make([]*models.POI, poiCount),
+				participants: []services.POIParticipantInfo{
+					{ID: "user-1", Name: "Alice"},
+					{ID: "user-2", Name: "Bob", AvatarURL: "/uploads/avatars/bob.png"},
+				},
+			}
+			for i := range service.pois {
+				service.pois[i] = &models.POI{
+					ID:              fmt.Sprintf("poi-%d", i),
+					MapID:           "bench-map",
+					Name:            fmt.Sprintf("POI %d", i),
+					Description:     "Benchmark POI",
+					Position:        models.LatLng{Lat: float64(i%180) - 90, Lng: float64(i%360) - 180},
+					CreatedBy:       "user-1",
+					MaxParticipants: 10,
+				}
+			}
+
+			router := gin.New()
+			NewPOIHandler(service, nil, nil).RegisterRoutes(router)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				req := httptest.NewRequest(http.MethodGet, "/api/pois?mapId=bench-map", nil)
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				if w.Code != http.StatusOK {
+					b.Fatalf("expected 200, got %d", w.Code)
+				}
+			}
+		})
+	}
+}
@@ -14,7 +14,6 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
-	"gorm.io/gorm"
 )
 
 // TestPOIHandler_Migrated demonstrates the new test infrastructure
@@ -38,6 +37,7 @@ func newSimplePOIScenario(t *testing.T) *simplePOIScenario {
 	mockRateLimiter := new(MockRateLimiter)
 	mockUserService := &MockPOIUserService{}
 	handler := NewPOIHandler(mockPOIService, mockUserService, mockRateLimiter)
+	handler.SetIdentityMiddleware(headerIdentity)
 	
 	router := gin.New()
 	handler.RegisterRoutes(router)
@@ -179,6 +179,7 @@ func TestJoinPOI_Success_Migrated(t *testing.T) {
 	body, _ := json.Marshal(JoinPOIRequest{UserID: "user-456"})
 	req := httptest.NewRequest(http.MethodPost, "/api/pois/poi-123/join", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", "user-456")
 	recorder := httptest.NewRecorder()
 	
 	scenario.router.ServeHTTP(recorder, req)
@@ -199,14 +200,16 @@ func TestJoinPOI_POINotFound_Migrated(t *testing.T) {
 
 	// Setup rate limit success
 	scenario.mockRateLimiter.On("CheckRateLimit", mock.Anything, "user-456", services.ActionJoinPOI).Return(nil)
+	scenario.mockRateLimiter.On("GetRateLimitHeaders", mock.Anything, "user-456", services.ActionJoinPOI).Return(map[string]string{}, nil)
 	
 	// Setup POI not found
-	scenario.mockPOIService.On("JoinPOI", mock.Anything, "non-existent-poi", "user-456").Return(gorm.ErrRecordNotFound)
+	scenario.mockPOIService.On("JoinPOI", mock.Anything, "non-existent-poi", "user-456").Return(services.ErrNotFound)
 
 	// Execute request
 	body, _ := json.Marshal(JoinPOIRequest{UserID: "user-456"})
 	req := httptest.NewRequest(http.MethodPost, "/api/pois/non-existent-poi/join", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", "user-456")
 	recorder := httptest.NewRecorder()
 	
 	scenario.router.ServeHTTP(recorder, req)
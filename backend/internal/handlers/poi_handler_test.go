@@ -4,7 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
-	"errors"
+	"fmt"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
@@ -17,7 +17,6 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
-	"gorm.io/gorm"
 )
 
 // MockPOIService is a mock implementation of POIServiceInterface
@@ -102,6 +101,11 @@ func (m *MockPOIService) GetUserPOIs(ctx context.Context, userID string) ([]stri
 	return args.Get(0).([]string), args.Error(1)
 }
 
+func (m *MockPOIService) GetCurrentPOI(ctx context.Context, userID string) (string, error) {
+	args := m.Called(ctx, userID)
+	return args.String(0), args.Error(1)
+}
+
 func (m *MockPOIService) ValidatePOI(ctx context.Context, poiID string) (*models.POI, error) {
 	args := m.Called(ctx, poiID)
 	if args.Get(0) == nil {
@@ -133,6 +137,7 @@ func (suite *POIHandlerTestSuite) SetupTest() {
 	suite.mockRateLimiter = new(MockRateLimiter)
 	suite.mockUserService = &MockPOIUserService{}
 	suite.handler = NewPOIHandler(suite.mockPOIService, suite.mockUserService, suite.mockRateLimiter)
+	suite.handler.SetIdentityMiddleware(headerIdentity)
 	
 	// Setup router
 	suite.router = gin.New()
@@ -211,6 +216,82 @@ func (suite *POIHandlerTestSuite) TestGetPOIs() {
 	suite.Equal("User-session-2", response.POIs[0].Participants[1].Name)
 }
 
+func (suite *POIHandlerTestSuite) TestGetPOIs_IncludesRSVPCountsOfScheduledPOIs() {
+	mapID := "map-123"
+	startsAt := time.Now().Add(time.Hour).UTC()
+	expectedPOIs := []*models.POI{
+		{ID: "poi-scheduled", MapID: mapID, Name: "Keynote", StartsAt: &startsAt, CreatedAt: time.Now()},
+		{ID: "poi-open", MapID: mapID, Name: "Coffee Shop", CreatedAt: time.Now()},
+	}
+	suite.handler.SetRSVPCounter(&stubRSVPService{rsvps: map[string]*models.POIRSVP{
+		"poi-scheduled:user-1": {POIID: "poi-scheduled", UserID: "user-1", Status: models.RSVPGoing},
+		"poi-scheduled:user-2": {POIID: "poi-scheduled", UserID: "user-2", Status: models.RSVPNo},
+	}})
+	
+	suite.mockPOIService.On("GetPOIsForMap", mock.AnythingOfType("*gin.Context"), mapID).Return(expectedPOIs, nil)
+	for _, poi := range expectedPOIs {
+		suite.mockPOIService.On("GetPOIParticipantCount", mock.AnythingOfType("*gin.Context"), poi.ID).Return(0, nil)
+		suite.mockPOIService.On("GetPOIParticipantsWithInfo", mock.AnythingOfType("*gin.Context"), poi.ID).Return([]services.POIParticipantInfo{}, nil)
+	}
+	
+	req := httptest.NewRequest(http.MethodGet, "/api/pois?mapId="+mapID, nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	
+	suite.Equal(http.StatusOK, w.Code)
+	
+	var response GetPOIsResponse
+	suite.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	suite.Require().Len(response.POIs, 2)
+	suite.Require().NotNil(response.POIs[0].StartsAt)
+	suite.True(startsAt.Equal(*response.POIs[0].StartsAt))
+	suite.Equal(&models.RSVPCounts{Going: 1, No: 1}, response.POIs[0].RSVPs)
+	suite.Nil(response.POIs[1].StartsAt)
+	suite.Nil(response.POIs[1].RSVPs)
+}
+
+type stubPOIPopularity struct {
+	popularity map[string]models.POIPopularity
+}
+
+func (s *stubPOIPopularity) GetPOIPopularity(ctx context.Context, poiIDs []string) (map[string]models.POIPopularity, error) {
+	return s.popularity, nil
+}
+
+func (suite *POIHandlerTestSuite) TestGetPOIs_IncludesPopularity() {
+	mapID := "map-123"
+	since := time.Now().Add(-time.Hour).UTC()
+	expectedPOIs := []*models.POI{
+		{ID: "poi-trending", MapID: mapID, Name: "Keynote", CreatedAt: time.Now()},
+		{ID: "poi-quiet", MapID: mapID, Name: "Coffee Shop", CreatedAt: time.Now()},
+	}
+	suite.handler.SetPopularity(&stubPOIPopularity{popularity: map[string]models.POIPopularity{
+		"poi-trending": {Since: since, Joins: 12},
+		"poi-quiet":    {Since: since},
+	}})
+	
+	suite.mockPOIService.On("GetPOIsForMap", mock.AnythingOfType("*gin.Context"), mapID).Return(expectedPOIs, nil)
+	for _, poi := range expectedPOIs {
+		suite.mockPOIService.On("GetPOIParticipantCount", mock.AnythingOfType("*gin.Context"), poi.ID).Return(0, nil)
+		suite.mockPOIService.On("GetPOIParticipantsWithInfo", mock.AnythingOfType("*gin.Context"), poi.ID).Return([]services.POIParticipantInfo{}, nil)
+	}
+	
+	req := httptest.NewRequest(http.MethodGet, "/api/pois?mapId="+mapID, nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	
+	suite.Equal(http.StatusOK, w.Code)
+	
+	var response GetPOIsResponse
+	suite.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	suite.Require().Len(response.POIs, 2)
+	suite.Require().NotNil(response.POIs[0].PopularSince)
+	suite.Equal(12, response.POIs[0].PopularSince.Joins)
+	suite.True(since.Equal(response.POIs[0].PopularSince.Since))
+	suite.Require().NotNil(response.POIs[1].PopularSince)
+	suite.Equal(0, response.POIs[1].PopularSince.Joins)
+}
+
 func (suite *POIHandlerTestSuite) TestGetPOIsWithBounds() {
 	mapID := "map-123"
 	bounds := services.POIBounds{
@@ -376,6 +457,10 @@ func (suite *POIHandlerTestSuite) TestCreatePOI_RateLimited() {
 }
 
 func (suite *POIHandlerTestSuite) TestCreatePOI_InvalidJSON() {
+	// Requests without a creator are counted per client IP
+	suite.mockRateLimiter.On("CheckRateLimit", mock.AnythingOfType("*gin.Context"), "ip:192.0.2.1", services.ActionCreatePOI).Return(nil)
+	suite.mockRateLimiter.On("GetRateLimitHeaders", mock.AnythingOfType("*gin.Context"), "ip:192.0.2.1", services.ActionCreatePOI).Return(map[string]string{}, nil)
+	
 	// Create request with invalid JSON
 	req := httptest.NewRequest(http.MethodPost, "/api/pois", bytes.NewBufferString("invalid json"))
 	req.Header.Set("Content-Type", "application/json")
@@ -403,6 +488,10 @@ func (suite *POIHandlerTestSuite) TestCreatePOI_ValidationError() {
 		MaxParticipants: 15,
 	}
 	
+	// Mock expectations
+	suite.mockRateLimiter.On("CheckRateLimit", mock.AnythingOfType("*gin.Context"), reqBody.CreatedBy, services.ActionCreatePOI).Return(nil)
+	suite.mockRateLimiter.On("GetRateLimitHeaders", mock.AnythingOfType("*gin.Context"), reqBody.CreatedBy, services.ActionCreatePOI).Return(map[string]string{}, nil)
+	
 	// Create request
 	body, _ := json.Marshal(reqBody)
 	req := httptest.NewRequest(http.MethodPost, "/api/pois", bytes.NewBuffer(body))
@@ -433,7 +522,8 @@ func (suite *POIHandlerTestSuite) TestCreatePOI_DuplicateLocation() {
 	
 	// Mock expectations
 	suite.mockRateLimiter.On("CheckRateLimit", mock.AnythingOfType("*gin.Context"), reqBody.CreatedBy, services.ActionCreatePOI).Return(nil)
-	suite.mockPOIService.On("CreatePOI", mock.AnythingOfType("*gin.Context"), reqBody.MapID, reqBody.Name, reqBody.Description, reqBody.Position, reqBody.CreatedBy, reqBody.MaxParticipants).Return((*models.POI)(nil), errors.New("duplicate POI location"))
+	suite.mockRateLimiter.On("GetRateLimitHeaders", mock.AnythingOfType("*gin.Context"), reqBody.CreatedBy, services.ActionCreatePOI).Return(map[string]string{}, nil)
+	suite.mockPOIService.On("CreatePOI", mock.AnythingOfType("*gin.Context"), reqBody.MapID, reqBody.Name, reqBody.Description, reqBody.Position, reqBody.CreatedBy, reqBody.MaxParticipants).Return((*models.POI)(nil), fmt.Errorf("%w: a POI already exists at lat 40.712800, lng -74.006000", services.ErrDuplicateLocation))
 	
 	// Create request
 	body, _ := json.Marshal(reqBody)
@@ -470,6 +560,7 @@ func (suite *POIHandlerTestSuite) TestJoinPOI() {
 	// Create request
 	body, _ := json.Marshal(reqBody)
 	req := httptest.NewRequest(http.MethodPost, "/api/pois/"+poiID+"/join", bytes.NewBuffer(body))
+	req.Header.Set("X-User-ID", reqBody.UserID)
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	
@@ -499,11 +590,13 @@ func (suite *POIHandlerTestSuite) TestJoinPOI_CapacityExceeded() {
 	
 	// Mock expectations
 	suite.mockRateLimiter.On("CheckRateLimit", mock.AnythingOfType("*gin.Context"), reqBody.UserID, services.ActionJoinPOI).Return(nil)
-	suite.mockPOIService.On("JoinPOI", mock.AnythingOfType("*gin.Context"), poiID, reqBody.UserID).Return(errors.New("POI capacity exceeded"))
+	suite.mockRateLimiter.On("GetRateLimitHeaders", mock.AnythingOfType("*gin.Context"), reqBody.UserID, services.ActionJoinPOI).Return(map[string]string{}, nil)
+	suite.mockPOIService.On("JoinPOI", mock.AnythingOfType("*gin.Context"), poiID, reqBody.UserID).Return(fmt.Errorf("%w: POI is at maximum capacity (15 participants)", services.ErrCapacityExceeded))
 	
 	// Create request
 	body, _ := json.Marshal(reqBody)
 	req := httptest.NewRequest(http.MethodPost, "/api/pois/"+poiID+"/join", bytes.NewBuffer(body))
+	req.Header.Set("X-User-ID", reqBody.UserID)
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	
@@ -519,6 +612,36 @@ func (suite *POIHandlerTestSuite) TestJoinPOI_CapacityExceeded() {
 	suite.Equal("CAPACITY_EXCEEDED", response.Code)
 }
 
+func (suite *POIHandlerTestSuite) TestJoinPOI_MapFrozen() {
+	poiID := "poi-123"
+	reqBody := JoinPOIRequest{
+		UserID: "user-456",
+	}
+	
+	// Mock expectations
+	suite.mockRateLimiter.On("CheckRateLimit", mock.AnythingOfType("*gin.Context"), reqBody.UserID, services.ActionJoinPOI).Return(nil)
+	suite.mockRateLimiter.On("GetRateLimitHeaders", mock.AnythingOfType("*gin.Context"), reqBody.UserID, services.ActionJoinPOI).Return(map[string]string{}, nil)
+	suite.mockPOIService.On("JoinPOI", mock.AnythingOfType("*gin.Context"), poiID, reqBody.UserID).Return(fmt.Errorf("%w: map-123", services.ErrMapFrozen))
+	
+	// Create request
+	body, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest(http.MethodPost, "/api/pois/"+poiID+"/join", bytes.NewBuffer(body))
+	req.Header.Set("X-User-ID", reqBody.UserID)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	
+	// Execute
+	suite.router.ServeHTTP(w, req)
+	
+	// Assert
+	suite.Equal(http.StatusLocked, w.Code)
+	
+	var response ErrorResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	suite.NoError(err)
+	suite.Equal("MAP_FROZEN", response.Code)
+}
+
 func (suite *POIHandlerTestSuite) TestJoinPOI_POINotFound() {
 	poiID := "non-existent-poi"
 	reqBody := JoinPOIRequest{
@@ -527,11 +650,13 @@ func (suite *POIHandlerTestSuite) TestJoinPOI_POINotFound() {
 	
 	// Mock expectations
 	suite.mockRateLimiter.On("CheckRateLimit", mock.AnythingOfType("*gin.Context"), reqBody.UserID, services.ActionJoinPOI).Return(nil)
-	suite.mockPOIService.On("JoinPOI", mock.AnythingOfType("*gin.Context"), poiID, reqBody.UserID).Return(gorm.ErrRecordNotFound)
+	suite.mockRateLimiter.On("GetRateLimitHeaders", mock.AnythingOfType("*gin.Context"), reqBody.UserID, services.ActionJoinPOI).Return(map[string]string{}, nil)
+	suite.mockPOIService.On("JoinPOI", mock.AnythingOfType("*gin.Context"), poiID, reqBody.UserID).Return(fmt.Errorf("POI %w: %s", services.ErrNotFound, poiID))
 	
 	// Create request
 	body, _ := json.Marshal(reqBody)
 	req := httptest.NewRequest(http.MethodPost, "/api/pois/"+poiID+"/join", bytes.NewBuffer(body))
+	req.Header.Set("X-User-ID", reqBody.UserID)
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	
@@ -554,11 +679,14 @@ func (suite *POIHandlerTestSuite) TestLeavePOI() {
 	}
 	
 	// Mock expectations
+	suite.mockRateLimiter.On("CheckRateLimit", mock.AnythingOfType("*gin.Context"), reqBody.UserID, services.ActionLeavePOI).Return(nil)
+	suite.mockRateLimiter.On("GetRateLimitHeaders", mock.AnythingOfType("*gin.Context"), reqBody.UserID, services.ActionLeavePOI).Return(map[string]string{}, nil)
 	suite.mockPOIService.On("LeavePOI", mock.AnythingOfType("*gin.Context"), poiID, reqBody.UserID).Return(nil)
 	
 	// Create request
 	body, _ := json.Marshal(reqBody)
 	req := httptest.NewRequest(http.MethodPost, "/api/pois/"+poiID+"/leave", bytes.NewBuffer(body))
+	req.Header.Set("X-User-ID", reqBody.UserID)
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	
@@ -583,11 +711,14 @@ func (suite *POIHandlerTestSuite) TestLeavePOI_POINotFound() {
 	}
 	
 	// Mock expectations
-	suite.mockPOIService.On("LeavePOI", mock.AnythingOfType("*gin.Context"), poiID, reqBody.UserID).Return(gorm.ErrRecordNotFound)
+	suite.mockRateLimiter.On("CheckRateLimit", mock.AnythingOfType("*gin.Context"), reqBody.UserID, services.ActionLeavePOI).Return(nil)
+	suite.mockRateLimiter.On("GetRateLimitHeaders", mock.AnythingOfType("*gin.Context"), reqBody.UserID, services.ActionLeavePOI).Return(map[string]string{}, nil)
+	suite.mockPOIService.On("LeavePOI", mock.AnythingOfType("*gin.Context"), poiID, reqBody.UserID).Return(fmt.Errorf("POI %w: %s", services.ErrNotFound, poiID))
 	
 	// Create request
 	body, _ := json.Marshal(reqBody)
 	req := httptest.NewRequest(http.MethodPost, "/api/pois/"+poiID+"/leave", bytes.NewBuffer(body))
+	req.Header.Set("X-User-ID", reqBody.UserID)
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	
@@ -603,6 +734,169 @@ func (suite *POIHandlerTestSuite) TestLeavePOI_POINotFound() {
 	suite.Equal("POI_NOT_FOUND", response.Code)
 }
 
+func (suite *POIHandlerTestSuite) TestUpdatePOI_PartialUpdate() {
+	poiID := "poi-123"
+	
+	// Mock expectations
+	suite.mockRateLimiter.On("CheckRateLimit", mock.AnythingOfType("*gin.Context"), "user-123", services.ActionUpdatePOI).Return(nil)
+	suite.mockRateLimiter.On("GetRateLimitHeaders", mock.AnythingOfType("*gin.Context"), "user-123", services.ActionUpdatePOI).Return(map[string]string{}, nil)
+	
+	// Only the description is sent, and it is explicitly cleared
+	onlyDescriptionCleared := mock.MatchedBy(func(data services.POIUpdateData) bool {
+		return data.Name == nil && data.MaxParticipants == nil && data.Description != nil && *data.Description == ""
+	})
+	suite.mockPOIService.On("UpdatePOI", mock.AnythingOfType("*gin.Context"), poiID, onlyDescriptionCleared).Return(&models.POI{
+		ID:              poiID,
+		MapID:           "map-456",
+		Name:            "Coffee Shop",
+		CreatedBy:       "user-123",
+		MaxParticipants: 10,
+	}, nil)
+	suite.mockPOIService.On("GetPOIParticipantCount", mock.AnythingOfType("*gin.Context"), poiID).Return(4, nil)
+	
+	req := httptest.NewRequest(http.MethodPut, "/api/pois/"+poiID, bytes.NewBufferString(`{"description":""}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", "user-123")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	
+	suite.Equal(http.StatusOK, w.Code)
+	var response UpdatePOIResponse
+	suite.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	suite.Equal("Coffee Shop", response.Name)
+	suite.Equal("", response.Description)
+	suite.Equal(4, response.CurrentCount)
+}
+
+func (suite *POIHandlerTestSuite) TestUpdatePOI_ValidationError() {
+	poiID := "poi-123"
+	
+	// Mock expectations
+	suite.mockRateLimiter.On("CheckRateLimit", mock.AnythingOfType("*gin.Context"), "user-123", services.ActionUpdatePOI).Return(nil)
+	suite.mockRateLimiter.On("GetRateLimitHeaders", mock.AnythingOfType("*gin.Context"), "user-123", services.ActionUpdatePOI).Return(map[string]string{}, nil)
+	suite.mockPOIService.On("UpdatePOI", mock.AnythingOfType("*gin.Context"), poiID, mock.AnythingOfType("services.POIUpdateData")).Return((*models.POI)(nil),
+		fmt.Errorf("%w: max participants must be at least 1", services.ErrInvalidInput))
+	
+	req := httptest.NewRequest(http.MethodPut, "/api/pois/"+poiID, bytes.NewBufferString(`{"maxParticipants":0}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", "user-123")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	
+	suite.Equal(http.StatusBadRequest, w.Code)
+	var response ErrorResponse
+	suite.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	suite.Equal("VALIDATION_ERROR", response.Code)
+}
+
+func (suite *POIHandlerTestSuite) TestUpdatePOI_CapacityBelowOccupancy() {
+	poiID := "poi-123"
+	
+	// Mock expectations
+	suite.mockRateLimiter.On("CheckRateLimit", mock.AnythingOfType("*gin.Context"), "user-123", services.ActionUpdatePOI).Return(nil)
+	suite.mockRateLimiter.On("GetRateLimitHeaders", mock.AnythingOfType("*gin.Context"), "user-123", services.ActionUpdatePOI).Return(map[string]string{}, nil)
+	suite.mockPOIService.On("UpdatePOI", mock.AnythingOfType("*gin.Context"), poiID, mock.AnythingOfType("services.POIUpdateData")).Return((*models.POI)(nil),
+		&services.CapacityBelowOccupancyError{MaxParticipants: 2, CurrentCount: 3})
+	
+	req := httptest.NewRequest(http.MethodPut, "/api/pois/"+poiID, bytes.NewBufferString(`{"maxParticipants":2}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", "user-123")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	
+	suite.Equal(http.StatusConflict, w.Code)
+	var response CapacityConflictResponse
+	suite.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	suite.Equal("CAPACITY_BELOW_OCCUPANCY", response.Code)
+	suite.Equal(3, response.CurrentCount)
+}
+
+func (suite *POIHandlerTestSuite) TestJoinLeavePOI_UsesResolvedIdentity() {
+	// Stand-in for RequireIdentity resolving the caller's session
+	suite.handler.SetIdentityMiddleware(func(c *gin.Context) {
+		c.Set("userID", "user-456")
+		c.Next()
+	})
+	router := gin.New()
+	suite.handler.RegisterRoutes(router)
+
+	// Both requests count against the resolved user
+	for _, action := range []services.ActionType{services.ActionJoinPOI, services.ActionLeavePOI} {
+		suite.mockRateLimiter.On("CheckRateLimit", mock.AnythingOfType("*gin.Context"), "user-456", action).Return(nil)
+		suite.mockRateLimiter.On("GetRateLimitHeaders", mock.AnythingOfType("*gin.Context"), "user-456", action).Return(map[string]string{}, nil)
+	}
+
+	// A body naming another user is rejected
+	req := httptest.NewRequest(http.MethodPost, "/api/pois/poi-123/join", bytes.NewBufferString(`{"userId":"user-999"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusForbidden, w.Code)
+	var response ErrorResponse
+	suite.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	suite.Equal("USER_MISMATCH", response.Code)
+
+	// Without a body user ID the resolved identity is used
+	suite.mockPOIService.On("LeavePOI", mock.AnythingOfType("*gin.Context"), "poi-123", "user-456").Return(nil)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/pois/poi-123/leave", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusOK, w.Code)
+	var leaveResponse LeavePOIResponse
+	suite.NoError(json.Unmarshal(w.Body.Bytes(), &leaveResponse))
+	suite.Equal("user-456", leaveResponse.UserID)
+}
+
+func (suite *POIHandlerTestSuite) TestJoinPOI_RequiresIdentity() {
+	suite.mockRateLimiter.On("CheckRateLimit", mock.AnythingOfType("*gin.Context"), mock.Anything, services.ActionJoinPOI).Return(nil)
+	suite.mockRateLimiter.On("GetRateLimitHeaders", mock.AnythingOfType("*gin.Context"), mock.Anything, services.ActionJoinPOI).Return(map[string]string{}, nil)
+
+	// A user ID in the body doesn't stand in for an unresolved identity
+	req := httptest.NewRequest(http.MethodPost, "/api/pois/poi-123/join", bytes.NewBufferString(`{"userId":"user-456"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	suite.Equal(http.StatusUnauthorized, w.Code)
+	suite.mockPOIService.AssertNotCalled(suite.T(), "JoinPOI", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (suite *POIHandlerTestSuite) TestGetUserCurrentPOI() {
+	suite.mockPOIService.On("GetCurrentPOI", mock.Anything, "user-in-poi").Return("poi-123", nil)
+	suite.mockPOIService.On("GetCurrentPOI", mock.Anything, "user-outside").Return("", nil)
+	
+	req := httptest.NewRequest(http.MethodGet, "/api/users/user-in-poi/current-poi", nil)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	
+	suite.Equal(http.StatusOK, w.Code)
+	var response CurrentPOIResponse
+	suite.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	suite.Equal("user-in-poi", response.UserID)
+	suite.Require().NotNil(response.CurrentPOIID)
+	suite.Equal("poi-123", *response.CurrentPOIID)
+	
+	req = httptest.NewRequest(http.MethodGet, "/api/users/user-outside/current-poi", nil)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	
+	suite.Equal(http.StatusOK, w.Code)
+	suite.JSONEq(`{"userId":"user-outside","currentPoiId":null}`, w.Body.String())
+}
+
+// headerIdentity stands in for RequireIdentity, resolving the acting user
+// from the X-User-ID header
+func headerIdentity(c *gin.Context) {
+	if userID := c.GetHeader("X-User-ID"); userID != "" {
+		c.Set("userID", userID)
+	}
+	c.Next()
+}
+
 func TestPOIHandlerTestSuite(t *testing.T) {
 	suite.Run(t, new(POIHandlerTestSuite))
 }
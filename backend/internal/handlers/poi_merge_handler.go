@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// POIMergeServiceInterface defines the interface for merging duplicate POIs
+type POIMergeServiceInterface interface {
+	MergePOIs(ctx context.Context, sourceID, targetID, mergedBy string) (*models.POI, error)
+}
+
+// POIMergeHandler handles the endpoint facilitators merge duplicate POIs with
+type POIMergeHandler struct {
+	merger POIMergeServiceInterface
+}
+
+// NewPOIMergeHandler creates a new POIMergeHandler
+func NewPOIMergeHandler(merger POIMergeServiceInterface) *POIMergeHandler {
+	return &POIMergeHandler{
+		merger: merger,
+	}
+}
+
+// RegisterRoutes registers POI merge routes
+// organizerMiddleware should authenticate the caller and require an organizer (admin) role
+func (h *POIMergeHandler) RegisterRoutes(router *gin.Engine, organizerMiddleware ...gin.HandlerFunc) {
+	pois := router.Group("/api/pois", organizerMiddleware...)
+	{
+		pois.POST("/:poiId/merge", h.MergePOI)
+	}
+}
+
+// MergePOIRequest selects the POI the source POI is merged into
+type MergePOIRequest struct {
+	TargetPOIID string `json:"targetPoiId" binding:"required"`
+}
+
+// MergePOI handles POST /api/pois/:poiId/merge
+// The POI's participants, RSVPs and image move to the target POI, the POI is
+// deleted and clients are told to use the target's ID instead. Targets that
+// can't seat everyone are rejected with 409.
+func (h *POIMergeHandler) MergePOI(c *gin.Context) {
+	var req MergePOIRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	poi, err := h.merger.MergePOIs(c.Request.Context(), c.Param("poiId"), req.TargetPOIID, c.GetString("userID"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Code:    "POI_NOT_FOUND",
+				Message: "POI not found",
+				Details: err.Error(),
+			})
+		case errors.Is(err, services.ErrInvalidInput):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Code:    "VALIDATION_ERROR",
+				Message: "Failed to merge POIs",
+				Details: err.Error(),
+			})
+		case errors.Is(err, services.ErrCapacityExceeded):
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Code:    "CAPACITY_EXCEEDED",
+				Message: "The target POI can't seat everyone",
+				Details: err.Error(),
+			})
+		case errors.Is(err, services.ErrMapFrozen):
+			c.JSON(http.StatusLocked, ErrorResponse{
+				Code:    "MAP_FROZEN",
+				Message: "The map is frozen by a facilitator",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to merge POIs",
+				Details: err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"sourcePoiId": c.Param("poiId"),
+		"poi":         poi,
+	})
+}
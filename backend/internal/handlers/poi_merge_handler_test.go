@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePOIMerger struct {
+	mergedBy string
+}
+
+func (m *fakePOIMerger) MergePOIs(ctx context.Context, sourceID, targetID, mergedBy string) (*models.POI, error) {
+	switch {
+	case sourceID == targetID:
+		return nil, fmt.Errorf("%w: a POI can't be merged into itself", services.ErrInvalidInput)
+	case targetID != "poi-1":
+		return nil, fmt.Errorf("POI %w: %s", services.ErrNotFound, targetID)
+	case sourceID == "poi-frozen":
+		return nil, fmt.Errorf("%w: map-1", services.ErrMapFrozen)
+	case sourceID == "poi-crowded":
+		return nil, fmt.Errorf("%w: POI poi-1 can't seat the 12 users of both POIs (10 participants)", services.ErrCapacityExceeded)
+	}
+	m.mergedBy = mergedBy
+	return &models.POI{ID: targetID, MapID: "map-1", Name: "Coffee Corner"}, nil
+}
+
+func setupPOIMergeTest() (*gin.Engine, *fakePOIMerger) {
+	gin.SetMode(gin.TestMode)
+
+	merger := &fakePOIMerger{}
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("userID", "facilitator")
+		c.Next()
+	})
+	NewPOIMergeHandler(merger).RegisterRoutes(router)
+	return router, merger
+}
+
+func TestPOIMergeHandler_MergePOI(t *testing.T) {
+	router, merger := setupPOIMergeTest()
+
+	w := serveMapOwnership(router, http.MethodPost, "/api/pois/poi-2/merge", MergePOIRequest{TargetPOIID: "poi-1"})
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		SourcePOIID string     `json:"sourcePoiId"`
+		POI         models.POI `json:"poi"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "poi-2", response.SourcePOIID)
+	assert.Equal(t, "poi-1", response.POI.ID)
+	assert.Equal(t, "facilitator", merger.mergedBy)
+
+	w = serveMapOwnership(router, http.MethodPost, "/api/pois/poi-2/merge", MergePOIRequest{TargetPOIID: "poi-9"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serveMapOwnership(router, http.MethodPost, "/api/pois/poi-1/merge", MergePOIRequest{TargetPOIID: "poi-1"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = serveMapOwnership(router, http.MethodPost, "/api/pois/poi-frozen/merge", MergePOIRequest{TargetPOIID: "poi-1"})
+	assert.Equal(t, http.StatusLocked, w.Code)
+
+	w = serveMapOwnership(router, http.MethodPost, "/api/pois/poi-crowded/merge", MergePOIRequest{TargetPOIID: "poi-1"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = serveMapOwnership(router, http.MethodPost, "/api/pois/poi-2/merge", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PreferenceServiceInterface defines the interface for user preferences
+type PreferenceServiceInterface interface {
+	GetPreferences(ctx context.Context, userID string) (models.Preferences, error)
+	UpdatePreferences(ctx context.Context, userID string, update models.Preferences) (models.Preferences, error)
+}
+
+// PreferenceHandler handles the preference endpoints of the current user
+type PreferenceHandler struct {
+	preferenceService PreferenceServiceInterface
+}
+
+// NewPreferenceHandler creates a new PreferenceHandler
+func NewPreferenceHandler(preferenceService PreferenceServiceInterface) *PreferenceHandler {
+	return &PreferenceHandler{
+		preferenceService: preferenceService,
+	}
+}
+
+// RegisterRoutes registers preference routes
+// authMiddleware is optional - guests identify themselves with the X-User-ID header
+func (h *PreferenceHandler) RegisterRoutes(router *gin.Engine, authMiddleware ...gin.HandlerFunc) {
+	users := router.Group("/api/users/me", authMiddleware...)
+	{
+		users.GET("/preferences", h.GetPreferences)
+		users.PUT("/preferences", h.UpdatePreferences)
+	}
+}
+
+// PreferencesResponse represents all preferences of a user by namespace
+type PreferencesResponse struct {
+	Preferences models.Preferences `json:"preferences"`
+}
+
+// GetPreferences handles GET /api/users/me/preferences
+func (h *PreferenceHandler) GetPreferences(c *gin.Context) {
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	preferences, err := h.preferenceService.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to get preferences")
+		return
+	}
+
+	c.JSON(http.StatusOK, PreferencesResponse{Preferences: preferences})
+}
+
+// UpdatePreferences handles PUT /api/users/me/preferences
+// Only the given namespaces and keys change, e.g. {"video":{"cameraOn":false}}
+func (h *PreferenceHandler) UpdatePreferences(c *gin.Context) {
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.Preferences
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	preferences, err := h.preferenceService.UpdatePreferences(c.Request.Context(), userID, req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update preferences")
+		return
+	}
+
+	c.JSON(http.StatusOK, PreferencesResponse{Preferences: preferences})
+}
+
+// requireUser returns the caller's user ID or writes an unauthorized response
+func (h *PreferenceHandler) requireUser(c *gin.Context) (string, bool) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		userID = c.GetHeader("X-User-ID")
+	}
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Code:    "UNAUTHORIZED",
+			Message: "User identification required",
+		})
+		return "", false
+	}
+	return userID, true
+}
+
+// handleError maps preference service errors to responses
+func (h *PreferenceHandler) handleError(c *gin.Context, err error, message string) {
+	if strings.Contains(err.Error(), "invalid preferences") {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "VALIDATION_ERROR",
+			Message: message,
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusInternalServerError, ErrorResponse{
+		Code:    "INTERNAL_ERROR",
+		Message: message,
+		Details: err.Error(),
+	})
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubPreferenceService struct {
+	preferences map[string]models.Preferences
+}
+
+func (s *stubPreferenceService) GetPreferences(ctx context.Context, userID string) (models.Preferences, error) {
+	preferences := models.DefaultPreferences()
+	preferences.Merge(s.preferences[userID])
+	return preferences, nil
+}
+
+func (s *stubPreferenceService) UpdatePreferences(ctx context.Context, userID string, update models.Preferences) (models.Preferences, error) {
+	if err := update.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid preferences: %w", err)
+	}
+	if s.preferences[userID] == nil {
+		s.preferences[userID] = models.Preferences{}
+	}
+	s.preferences[userID].Merge(update)
+	return s.GetPreferences(ctx, userID)
+}
+
+func setupPreferenceTest() (*gin.Engine, *stubPreferenceService) {
+	gin.SetMode(gin.TestMode)
+
+	service := &stubPreferenceService{preferences: make(map[string]models.Preferences)}
+	router := gin.New()
+	NewPreferenceHandler(service).RegisterRoutes(router)
+	return router, service
+}
+
+func sendPreferences(router *gin.Engine, method, userID, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/api/users/me/preferences", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if userID != "" {
+		req.Header.Set("X-User-ID", userID)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestPreferenceHandler_UpdateAndGetPreferences(t *testing.T) {
+	router, service := setupPreferenceTest()
+
+	w := sendPreferences(router, http.MethodPut, "user-1", `{"video":{"cameraOn":false},"a11y":{"fontScale":1.5}}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, false, service.preferences["user-1"][models.PreferenceVideo]["cameraOn"])
+
+	w = sendPreferences(router, http.MethodGet, "user-1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var response PreferencesResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, false, response.Preferences[models.PreferenceVideo]["cameraOn"])
+	assert.Equal(t, 1.5, response.Preferences[models.PreferenceA11y]["fontScale"])
+	assert.Equal(t, true, response.Preferences[models.PreferenceNotifications]["sound"])
+}
+
+func TestPreferenceHandler_Errors(t *testing.T) {
+	router, _ := setupPreferenceTest()
+
+	tests := []struct {
+		name   string
+		userID string
+		body   string
+		status int
+		code   string
+	}{
+		{"no user", "", `{"video":{"cameraOn":false}}`, http.StatusUnauthorized, "UNAUTHORIZED"},
+		{"malformed", "user-1", `{"video":true}`, http.StatusBadRequest, "INVALID_REQUEST"},
+		{"unknown namespace", "user-1", `{"theme":{"dark":true}}`, http.StatusBadRequest, "VALIDATION_ERROR"},
+		{"wrong type", "user-1", `{"notifications":{"sound":1}}`, http.StatusBadRequest, "VALIDATION_ERROR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := sendPreferences(router, http.MethodPut, tt.userID, tt.body)
+			assert.Equal(t, tt.status, w.Code)
+
+			var response ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.code, response.Code)
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package handlers
+
+import (
+	"breakoutglobe/internal/middleware"
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// rateLimited builds the handler chain of a write route. The rate limit runs
+// after the route's auth middleware so it can count requests per acting user.
+func rateLimited(limiter services.RateLimiterInterface, action services.ActionType, key middleware.RateLimitKeyFunc, authMiddleware []gin.HandlerFunc, handler gin.HandlerFunc) []gin.HandlerFunc {
+	chain := append([]gin.HandlerFunc{}, authMiddleware...)
+	return append(chain, middleware.RateLimit(limiter, action, key), handler)
+}
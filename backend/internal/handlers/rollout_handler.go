@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RolloutServiceInterface defines the interface for feature rollouts
+type RolloutServiceInterface interface {
+	EnabledFeatures(ctx context.Context, userID string) []models.Feature
+	GetRollouts(ctx context.Context) []models.FeatureRollout
+	SetRollout(ctx context.Context, rollout models.FeatureRollout) (models.FeatureRollout, error)
+}
+
+// RolloutHandler handles feature rollout endpoints
+type RolloutHandler struct {
+	rolloutService RolloutServiceInterface
+}
+
+// NewRolloutHandler creates a new RolloutHandler
+func NewRolloutHandler(rolloutService RolloutServiceInterface) *RolloutHandler {
+	return &RolloutHandler{
+		rolloutService: rolloutService,
+	}
+}
+
+// RegisterRoutes registers the feature lookup route for clients
+func (h *RolloutHandler) RegisterRoutes(router *gin.Engine, authMiddleware ...gin.HandlerFunc) {
+	api := router.Group("/api", authMiddleware...)
+	{
+		api.GET("/features", h.GetFeatures)
+	}
+}
+
+// RegisterAdminRoutes registers rollout management routes
+// adminMiddleware should authenticate the caller and require an admin role
+func (h *RolloutHandler) RegisterAdminRoutes(router *gin.Engine, adminMiddleware ...gin.HandlerFunc) {
+	admin := router.Group("/api/admin", adminMiddleware...)
+	{
+		admin.GET("/rollouts", h.GetRollouts)
+		admin.PUT("/rollouts/:feature", h.SetRollout)
+	}
+}
+
+// FeaturesResponse represents the features enabled for the caller
+type FeaturesResponse struct {
+	Features []models.Feature `json:"features"`
+}
+
+// RolloutsResponse represents the rollouts of all known features
+type RolloutsResponse struct {
+	Rollouts []models.FeatureRollout `json:"rollouts"`
+}
+
+// SetRolloutRequest represents the request body for changing a rollout
+type SetRolloutRequest struct {
+	Percentage *int     `json:"percentage" binding:"required"`
+	Allowlist  []string `json:"allowlist"`
+}
+
+// GetFeatures handles GET /api/features
+// Guests identify themselves with the X-User-ID header like other user endpoints
+func (h *RolloutHandler) GetFeatures(c *gin.Context) {
+	var userID string
+	if contextUserID, exists := c.Get("userID"); exists {
+		userID = contextUserID.(string)
+	} else {
+		userID = c.GetHeader("X-User-ID")
+	}
+
+	c.JSON(http.StatusOK, FeaturesResponse{
+		Features: h.rolloutService.EnabledFeatures(c.Request.Context(), userID),
+	})
+}
+
+// GetRollouts handles GET /api/admin/rollouts
+func (h *RolloutHandler) GetRollouts(c *gin.Context) {
+	c.JSON(http.StatusOK, RolloutsResponse{
+		Rollouts: h.rolloutService.GetRollouts(c.Request.Context()),
+	})
+}
+
+// SetRollout handles PUT /api/admin/rollouts/:feature
+func (h *RolloutHandler) SetRollout(c *gin.Context) {
+	var req SetRolloutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	rollout, err := h.rolloutService.SetRollout(c.Request.Context(), models.FeatureRollout{
+		Feature:    models.Feature(c.Param("feature")),
+		Percentage: *req.Percentage,
+		Allowlist:  req.Allowlist,
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid rollout") {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid rollout",
+				Details: err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: "Failed to update rollout",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, rollout)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupRolloutTest() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewRolloutHandler(services.NewRolloutService(nil))
+	router := gin.New()
+	handler.RegisterRoutes(router)
+	handler.RegisterAdminRoutes(router)
+	return router
+}
+
+func TestRolloutHandler_SetRollout(t *testing.T) {
+	router := setupRolloutTest()
+
+	body := `{"percentage":0,"allowlist":["canary-user"]}`
+	req := httptest.NewRequest(http.MethodPut, "/api/admin/rollouts/batched_movement", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// The allowlisted guest gets the feature
+	req = httptest.NewRequest(http.MethodGet, "/api/features", nil)
+	req.Header.Set("X-User-ID", "canary-user")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var features FeaturesResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &features))
+	assert.Equal(t, []models.Feature{models.FeatureBatchedMovement}, features.Features)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/admin/rollouts", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var rollouts RolloutsResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &rollouts))
+	assert.Len(t, rollouts.Rollouts, len(models.KnownFeatures))
+	assert.Equal(t, []string{"canary-user"}, rollouts.Rollouts[0].Allowlist)
+}
+
+func TestRolloutHandler_SetRollout_Invalid(t *testing.T) {
+	router := setupRolloutTest()
+
+	tests := []struct {
+		name    string
+		feature string
+		body    string
+		code    string
+	}{
+		{"unknown feature", "teleport", `{"percentage":10}`, "VALIDATION_ERROR"},
+		{"percentage out of range", "batched_movement", `{"percentage":150}`, "VALIDATION_ERROR"},
+		{"missing percentage", "batched_movement", `{}`, "INVALID_REQUEST"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/api/admin/rollouts/"+tt.feature, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var response ErrorResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.code, response.Code)
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RSVPServiceInterface defines the interface for RSVPs to scheduled POIs
+type RSVPServiceInterface interface {
+	SetRSVP(ctx context.Context, poiID, userID string, status models.RSVPStatus) (*models.POIRSVP, error)
+	GetRSVP(ctx context.Context, poiID, userID string) (*models.POIRSVP, error)
+	CancelRSVP(ctx context.Context, poiID, userID string) error
+	GetRSVPCounts(ctx context.Context, poiIDs []string) (map[string]models.RSVPCounts, error)
+}
+
+// RSVPHandler handles RSVP endpoints of scheduled POIs
+type RSVPHandler struct {
+	rsvpService RSVPServiceInterface
+}
+
+// NewRSVPHandler creates a new RSVPHandler
+func NewRSVPHandler(rsvpService RSVPServiceInterface) *RSVPHandler {
+	return &RSVPHandler{
+		rsvpService: rsvpService,
+	}
+}
+
+// RegisterRoutes registers RSVP routes
+// authMiddleware is optional - guests identify themselves with the X-User-ID header
+func (h *RSVPHandler) RegisterRoutes(router *gin.Engine, authMiddleware ...gin.HandlerFunc) {
+	pois := router.Group("/api/pois", authMiddleware...)
+	{
+		pois.GET("/:poiId/rsvps", h.GetRSVPs)
+		pois.PUT("/:poiId/rsvp", h.SetRSVP)
+		pois.DELETE("/:poiId/rsvp", h.CancelRSVP)
+	}
+}
+
+// SetRSVPRequest represents the request body for answering a scheduled POI
+type SetRSVPRequest struct {
+	Status models.RSVPStatus `json:"status" binding:"required"`
+}
+
+// RSVPsResponse represents the RSVP counts of a POI and the caller's own answer
+type RSVPsResponse struct {
+	POIID  string            `json:"poiId"`
+	Counts models.RSVPCounts `json:"counts"`
+	Status models.RSVPStatus `json:"status,omitempty"`
+}
+
+// GetRSVPs handles GET /api/pois/:poiId/rsvps
+func (h *RSVPHandler) GetRSVPs(c *gin.Context) {
+	poiID := c.Param("poiId")
+
+	counts, err := h.rsvpService.GetRSVPCounts(c.Request.Context(), []string{poiID})
+	if err != nil {
+		h.handleError(c, err, "Failed to get RSVPs")
+		return
+	}
+
+	response := RSVPsResponse{
+		POIID:  poiID,
+		Counts: counts[poiID],
+	}
+	if userID := rsvpUserID(c); userID != "" {
+		if rsvp, err := h.rsvpService.GetRSVP(c.Request.Context(), poiID, userID); err == nil {
+			response.Status = rsvp.Status
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// SetRSVP handles PUT /api/pois/:poiId/rsvp
+func (h *RSVPHandler) SetRSVP(c *gin.Context) {
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	var req SetRSVPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	rsvp, err := h.rsvpService.SetRSVP(c.Request.Context(), c.Param("poiId"), userID, req.Status)
+	if err != nil {
+		h.handleError(c, err, "Failed to save RSVP")
+		return
+	}
+
+	c.JSON(http.StatusOK, rsvp)
+}
+
+// CancelRSVP handles DELETE /api/pois/:poiId/rsvp
+func (h *RSVPHandler) CancelRSVP(c *gin.Context) {
+	userID, ok := h.requireUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.rsvpService.CancelRSVP(c.Request.Context(), c.Param("poiId"), userID); err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Code:    "NOT_FOUND",
+				Message: "RSVP not found",
+			})
+			return
+		}
+		h.handleError(c, err, "Failed to cancel RSVP")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// rsvpUserID returns the authenticated user ID, or the guest's X-User-ID header
+func rsvpUserID(c *gin.Context) string {
+	if userID := c.GetString("userID"); userID != "" {
+		return userID
+	}
+	return c.GetHeader("X-User-ID")
+}
+
+// requireUser returns the caller's user ID or writes an unauthorized response
+func (h *RSVPHandler) requireUser(c *gin.Context) (string, bool) {
+	userID := rsvpUserID(c)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Code:    "UNAUTHORIZED",
+			Message: "User identification required",
+		})
+		return "", false
+	}
+	return userID, true
+}
+
+// handleError maps RSVP service errors to responses
+func (h *RSVPHandler) handleError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrNotFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Code:    "POI_NOT_FOUND",
+			Message: "POI not found",
+		})
+	case errors.Is(err, services.ErrCapacityExceeded):
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Code:    "CAPACITY_EXCEEDED",
+			Message: "All seats of this POI are reserved",
+		})
+	case strings.Contains(err.Error(), "invalid rsvp"):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "VALIDATION_ERROR",
+			Message: message,
+			Details: err.Error(),
+		})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: message,
+			Details: err.Error(),
+		})
+	}
+}
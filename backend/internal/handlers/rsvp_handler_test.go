@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubRSVPService struct {
+	rsvps map[string]*models.POIRSVP // poiID:userID -> RSVP
+}
+
+func (s *stubRSVPService) SetRSVP(ctx context.Context, poiID, userID string, status models.RSVPStatus) (*models.POIRSVP, error) {
+	switch poiID {
+	case "poi-full":
+		return nil, fmt.Errorf("RSVP %w: all 2 seats are reserved", services.ErrCapacityExceeded)
+	case "poi-missing":
+		return nil, fmt.Errorf("POI %w: %s", services.ErrNotFound, poiID)
+	}
+
+	rsvp, err := models.NewPOIRSVP(poiID, userID, status)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rsvp: %w", err)
+	}
+	s.rsvps[poiID+":"+userID] = rsvp
+	return rsvp, nil
+}
+
+func (s *stubRSVPService) GetRSVP(ctx context.Context, poiID, userID string) (*models.POIRSVP, error) {
+	rsvp, exists := s.rsvps[poiID+":"+userID]
+	if !exists {
+		return nil, fmt.Errorf("RSVP %w", services.ErrNotFound)
+	}
+	return rsvp, nil
+}
+
+func (s *stubRSVPService) CancelRSVP(ctx context.Context, poiID, userID string) error {
+	if _, exists := s.rsvps[poiID+":"+userID]; !exists {
+		return fmt.Errorf("RSVP %w", services.ErrNotFound)
+	}
+	delete(s.rsvps, poiID+":"+userID)
+	return nil
+}
+
+func (s *stubRSVPService) GetRSVPCounts(ctx context.Context, poiIDs []string) (map[string]models.RSVPCounts, error) {
+	counts := make(map[string]models.RSVPCounts)
+	for _, rsvp := range s.rsvps {
+		c := counts[rsvp.POIID]
+		c.Add(rsvp.Status, 1)
+		counts[rsvp.POIID] = c
+	}
+	return counts, nil
+}
+
+func setupRSVPTest() (*gin.Engine, *stubRSVPService) {
+	gin.SetMode(gin.TestMode)
+
+	service := &stubRSVPService{rsvps: make(map[string]*models.POIRSVP)}
+	router := gin.New()
+	NewRSVPHandler(service).RegisterRoutes(router)
+	return router, service
+}
+
+func sendRSVP(router *gin.Engine, method, path, userID, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if userID != "" {
+		req.Header.Set("X-User-ID", userID)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRSVPHandler_SetAndCancelRSVP(t *testing.T) {
+	router, service := setupRSVPTest()
+
+	w := sendRSVP(router, http.MethodPut, "/api/pois/poi-1/rsvp", "user-1", `{"status":"going"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.RSVPGoing, service.rsvps["poi-1:user-1"].Status)
+
+	sendRSVP(router, http.MethodPut, "/api/pois/poi-1/rsvp", "user-2", `{"status":"maybe"}`)
+
+	w = sendRSVP(router, http.MethodGet, "/api/pois/poi-1/rsvps", "user-1", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var response RSVPsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, models.RSVPCounts{Going: 1, Maybe: 1}, response.Counts)
+	assert.Equal(t, models.RSVPGoing, response.Status)
+
+	w = sendRSVP(router, http.MethodDelete, "/api/pois/poi-1/rsvp", "user-1", "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = sendRSVP(router, http.MethodDelete, "/api/pois/poi-1/rsvp", "user-1", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRSVPHandler_SetRSVP_Errors(t *testing.T) {
+	router, _ := setupRSVPTest()
+
+	tests := []struct {
+		name   string
+		path   string
+		userID string
+		body   string
+		status int
+		code   string
+	}{
+		{"no user", "/api/pois/poi-1/rsvp", "", `{"status":"going"}`, http.StatusUnauthorized, "UNAUTHORIZED"},
+		{"missing status", "/api/pois/poi-1/rsvp", "user-1", `{}`, http.StatusBadRequest, "INVALID_REQUEST"},
+		{"invalid status", "/api/pois/poi-1/rsvp", "user-1", `{"status":"perhaps"}`, http.StatusBadRequest, "VALIDATION_ERROR"},
+		{"full", "/api/pois/poi-full/rsvp", "user-1", `{"status":"going"}`, http.StatusConflict, "CAPACITY_EXCEEDED"},
+		{"unknown POI", "/api/pois/poi-missing/rsvp", "user-1", `{"status":"going"}`, http.StatusNotFound, "POI_NOT_FOUND"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := sendRSVP(router, http.MethodPut, tt.path, tt.userID, tt.body)
+			assert.Equal(t, tt.status, w.Code)
+
+			var response ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.code, response.Code)
+		})
+	}
+}
@@ -5,9 +5,9 @@ import (
 	"errors"
 	"log"
 	"net/http"
-	"strconv"
 	"time"
 
+	"breakoutglobe/internal/middleware"
 	"breakoutglobe/internal/models"
 	"breakoutglobe/internal/services"
 
@@ -26,10 +26,23 @@ type SessionServiceInterface interface {
 	CleanupExpiredSessions(ctx context.Context) error
 }
 
+// SpawnLocatorInterface defines the interface for choosing default avatar spawn positions
+type SpawnLocatorInterface interface {
+	DefaultSpawnPosition(ctx context.Context, mapID, clientIP string) models.LatLng
+}
+
+// PositionPrivacyProviderInterface defines the interface for per-map position privacy settings
+type PositionPrivacyProviderInterface interface {
+	GetPositionPrivacy(ctx context.Context, mapID string) (models.PositionPrivacy, error)
+}
+
 // SessionHandler handles HTTP requests for session operations
 type SessionHandler struct {
 	sessionService SessionServiceInterface
 	rateLimiter    services.RateLimiterInterface
+	spawnLocator   SpawnLocatorInterface
+	privacy        PositionPrivacyProviderInterface
+	sessionTokens  *services.SessionTokens
 }
 
 // NewSessionHandler creates a new SessionHandler instance
@@ -37,9 +50,28 @@ func NewSessionHandler(sessionService SessionServiceInterface, rateLimiter servi
 	return &SessionHandler{
 		sessionService: sessionService,
 		rateLimiter:    rateLimiter,
+		sessionTokens:  services.NewSessionTokens(nil),
 	}
 }
 
+// SetSessionTokens sets how the tokens proving session ownership are signed.
+// Instances behind the same load balancer must share them.
+func (h *SessionHandler) SetSessionTokens(tokens *services.SessionTokens) {
+	h.sessionTokens = tokens
+}
+
+// SetSpawnLocator sets how the avatar position is chosen for sessions created
+// without one. Without a locator such avatars spawn at 0,0.
+func (h *SessionHandler) SetSpawnLocator(locator SpawnLocatorInterface) {
+	h.spawnLocator = locator
+}
+
+// SetPositionPrivacyProvider makes the map session list report coarse avatar
+// positions to callers who aren't admins on maps with position privacy
+func (h *SessionHandler) SetPositionPrivacyProvider(provider PositionPrivacyProviderInterface) {
+	h.privacy = provider
+}
+
 // RegisterRoutes registers session-related routes
 // authMiddleware is optional - if provided, it will be applied to all session operations
 func (h *SessionHandler) RegisterRoutes(router *gin.Engine, authMiddleware ...gin.HandlerFunc) {
@@ -47,17 +79,17 @@ func (h *SessionHandler) RegisterRoutes(router *gin.Engine, authMiddleware ...gi
 	{
 		// Session management - all operations require authentication if middleware provided
 		if len(authMiddleware) > 0 {
-			api.POST("/sessions", append(authMiddleware, h.CreateSession)...)
+			api.POST("/sessions", rateLimited(h.rateLimiter, services.ActionCreateSession, middleware.RateLimitByField("", "userId"), authMiddleware, h.CreateSession)...)
 			api.GET("/sessions/:sessionId", append(authMiddleware, h.GetSession)...)
-			api.PUT("/sessions/:sessionId/avatar", append(authMiddleware, h.UpdateAvatarPosition)...)
+			api.PUT("/sessions/:sessionId/avatar", rateLimited(h.rateLimiter, services.ActionUpdateAvatar, middleware.RateLimitByParam("session:", "sessionId"), authMiddleware, h.UpdateAvatarPosition)...)
 			api.POST("/sessions/:sessionId/heartbeat", append(authMiddleware, h.SessionHeartbeat)...)
 			api.DELETE("/sessions/:sessionId", append(authMiddleware, h.EndSession)...)
 			api.GET("/maps/:mapId/sessions", append(authMiddleware, h.GetActiveSessionsForMap)...)
 		} else {
 			// Fallback for backward compatibility (no auth)
-			api.POST("/sessions", h.CreateSession)
+			api.POST("/sessions", rateLimited(h.rateLimiter, services.ActionCreateSession, middleware.RateLimitByField("", "userId"), nil, h.CreateSession)...)
 			api.GET("/sessions/:sessionId", h.GetSession)
-			api.PUT("/sessions/:sessionId/avatar", h.UpdateAvatarPosition)
+			api.PUT("/sessions/:sessionId/avatar", rateLimited(h.rateLimiter, services.ActionUpdateAvatar, middleware.RateLimitByParam("session:", "sessionId"), nil, h.UpdateAvatarPosition)...)
 			api.POST("/sessions/:sessionId/heartbeat", h.SessionHeartbeat)
 			api.DELETE("/sessions/:sessionId", h.EndSession)
 			api.GET("/maps/:mapId/sessions", h.GetActiveSessionsForMap)
@@ -71,12 +103,13 @@ func (h *SessionHandler) RegisterRoutes(router *gin.Engine, authMiddleware ...gi
 type CreateSessionRequest struct {
 	UserID         string         `json:"userId" binding:"required"`
 	MapID          string         `json:"mapId" binding:"required"`
-	AvatarPosition models.LatLng  `json:"avatarPosition" binding:"required"`
+	AvatarPosition *models.LatLng `json:"avatarPosition"` // Optional, defaults to a spawn position for the map
 }
 
 // CreateSessionResponse represents the response for creating a session
 type CreateSessionResponse struct {
 	SessionID      string         `json:"sessionId"`
+	SessionToken   string         `json:"sessionToken"` // Proves ownership of the session, only returned here
 	UserID         string         `json:"userId"`
 	MapID          string         `json:"mapId"`
 	AvatarPosition models.LatLng  `json:"avatarPosition"`
@@ -117,7 +150,6 @@ type EndSessionResponse struct {
 
 // SessionInfo represents session information in responses
 type SessionInfo struct {
-	SessionID      string         `json:"sessionId"`
 	UserID         string         `json:"userId"`
 	AvatarPosition models.LatLng  `json:"avatarPosition"`
 	LastActive     time.Time      `json:"lastActive"`
@@ -160,15 +192,26 @@ func (h *SessionHandler) CreateSession(c *gin.Context) {
 		return
 	}
 	
-	// Check rate limit
-	if err := h.rateLimiter.CheckRateLimit(c, req.UserID, services.ActionCreateSession); err != nil {
-		h.handleRateLimitError(c, err)
+	// Signed in users only create sessions for themselves
+	if userID := c.GetString("userID"); userID != "" && userID != req.UserID {
+		c.JSON(http.StatusForbidden, ErrorResponse{
+			Code:    "FORBIDDEN",
+			Message: "Cannot create a session for another user",
+		})
 		return
 	}
 	
+	// Spawn avatars without a requested position near their client location
+	var position models.LatLng
+	if req.AvatarPosition != nil {
+		position = *req.AvatarPosition
+	} else if h.spawnLocator != nil {
+		position = h.spawnLocator.DefaultSpawnPosition(c.Request.Context(), req.MapID, c.ClientIP())
+	}
+	
 	// Create session
-	log.Printf("🔄 CreateSession: Creating session for user %s on map %s at position %+v", req.UserID, req.MapID, req.AvatarPosition)
-	session, err := h.sessionService.CreateSession(c, req.UserID, req.MapID, req.AvatarPosition)
+	log.Printf("🔄 CreateSession: Creating session for user %s on map %s at position %+v", req.UserID, req.MapID, position)
+	session, err := h.sessionService.CreateSession(c, req.UserID, req.MapID, position)
 	if err != nil {
 		log.Printf("❌ CreateSession: Failed to create session: %v", err)
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -195,12 +238,10 @@ func (h *SessionHandler) CreateSession(c *gin.Context) {
 		return
 	}
 	
-	// Add rate limit headers
-	h.addRateLimitHeaders(c, req.UserID, services.ActionCreateSession)
-	
 	// Return response
 	response := CreateSessionResponse{
 		SessionID:      session.ID,
+		SessionToken:   h.sessionTokens.Issue(session.ID),
 		UserID:         session.UserID,
 		MapID:          session.MapID,
 		AvatarPosition: session.AvatarPos,
@@ -225,7 +266,7 @@ func (h *SessionHandler) GetSession(c *gin.Context) {
 	// Get session
 	session, err := h.sessionService.GetSession(c, sessionID)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+		if errors.Is(err, services.ErrNotFound) {
 			c.JSON(http.StatusNotFound, ErrorResponse{
 				Code:    "SESSION_NOT_FOUND",
 				Message: "Session not found",
@@ -265,6 +306,9 @@ func (h *SessionHandler) UpdateAvatarPosition(c *gin.Context) {
 		})
 		return
 	}
+	if !h.ownsSession(c, sessionID) {
+		return
+	}
 	
 	var req UpdateAvatarPositionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -286,34 +330,9 @@ func (h *SessionHandler) UpdateAvatarPosition(c *gin.Context) {
 		return
 	}
 	
-	// Get session to extract user ID for rate limiting
-	session, err := h.sessionService.GetSession(c, sessionID)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			c.JSON(http.StatusNotFound, ErrorResponse{
-				Code:    "SESSION_NOT_FOUND",
-				Message: "Session not found",
-			})
-			return
-		}
-		
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Code:    "INTERNAL_ERROR",
-			Message: "Failed to get session",
-			Details: err.Error(),
-		})
-		return
-	}
-	
-	// Check rate limit
-	if err := h.rateLimiter.CheckRateLimit(c, session.UserID, services.ActionUpdateAvatar); err != nil {
-		h.handleRateLimitError(c, err)
-		return
-	}
-	
 	// Update avatar position
 	if err := h.sessionService.UpdateAvatarPosition(c, sessionID, req.Position); err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+		if errors.Is(err, services.ErrNotFound) {
 			c.JSON(http.StatusNotFound, ErrorResponse{
 				Code:    "SESSION_NOT_FOUND",
 				Message: "Session not found",
@@ -329,9 +348,6 @@ func (h *SessionHandler) UpdateAvatarPosition(c *gin.Context) {
 		return
 	}
 	
-	// Add rate limit headers
-	h.addRateLimitHeaders(c, session.UserID, services.ActionUpdateAvatar)
-	
 	// Return response
 	c.JSON(http.StatusOK, UpdateAvatarPositionResponse{
 		Success: true,
@@ -348,10 +364,13 @@ func (h *SessionHandler) SessionHeartbeat(c *gin.Context) {
 		})
 		return
 	}
+	if !h.ownsSession(c, sessionID) {
+		return
+	}
 	
 	// Update session heartbeat
 	if err := h.sessionService.SessionHeartbeat(c, sessionID); err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+		if errors.Is(err, services.ErrNotFound) {
 			c.JSON(http.StatusNotFound, ErrorResponse{
 				Code:    "SESSION_NOT_FOUND",
 				Message: "Session not found",
@@ -383,10 +402,13 @@ func (h *SessionHandler) EndSession(c *gin.Context) {
 		})
 		return
 	}
+	if !h.ownsSession(c, sessionID) {
+		return
+	}
 	
 	// End session
 	if err := h.sessionService.EndSession(c, sessionID); err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
+		if errors.Is(err, services.ErrNotFound) {
 			c.JSON(http.StatusNotFound, ErrorResponse{
 				Code:    "SESSION_NOT_FOUND",
 				Message: "Session not found",
@@ -430,13 +452,14 @@ func (h *SessionHandler) GetActiveSessionsForMap(c *gin.Context) {
 		return
 	}
 	
+	privacy := h.positionPrivacy(c, mapID)
+	
 	// Convert to response format
 	sessionInfos := make([]SessionInfo, len(sessions))
 	for i, session := range sessions {
 		sessionInfos[i] = SessionInfo{
-			SessionID:      session.ID,
 			UserID:         session.UserID,
-			AvatarPosition: session.AvatarPos,
+			AvatarPosition: privacy.Coarsen(session.AvatarPos),
 			LastActive:     session.LastActive,
 			IsActive:       session.IsActive,
 		}
@@ -454,6 +477,38 @@ func (h *SessionHandler) GetActiveSessionsForMap(c *gin.Context) {
 
 // Helper methods
 
+// ownsSession checks the caller sent the token of the session. Session IDs
+// are visible to everyone on the map, so they don't authorize changes alone.
+func (h *SessionHandler) ownsSession(c *gin.Context, sessionID string) bool {
+	if !h.sessionTokens.VerifySessionToken(sessionID, c.GetHeader(middleware.SessionTokenHeader)) {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Code:    "INVALID_SESSION_TOKEN",
+			Message: "A valid session token is required",
+		})
+		return false
+	}
+	return true
+}
+
+// positionPrivacy returns the position privacy that applies to the caller.
+// Admins always see exact positions.
+func (h *SessionHandler) positionPrivacy(c *gin.Context, mapID string) models.PositionPrivacy {
+	if h.privacy == nil {
+		return models.PositionPrivacy{}
+	}
+	if role, exists := c.Get("role"); exists {
+		if role == models.UserRoleAdmin || role == models.UserRoleSuperAdmin {
+			return models.PositionPrivacy{}
+		}
+	}
+	
+	privacy, err := h.privacy.GetPositionPrivacy(c.Request.Context(), mapID)
+	if err != nil {
+		return models.PositionPrivacy{}
+	}
+	return privacy
+}
+
 // validateCreateSessionRequest validates the create session request
 func (h *SessionHandler) validateCreateSessionRequest(req CreateSessionRequest) error {
 	if req.UserID == "" {
@@ -462,47 +517,14 @@ func (h *SessionHandler) validateCreateSessionRequest(req CreateSessionRequest)
 	if req.MapID == "" {
 		return errors.New("map ID is required")
 	}
-	if err := req.AvatarPosition.Validate(); err != nil {
-		return errors.New("invalid avatar position: " + err.Error())
+	if req.AvatarPosition != nil {
+		if err := req.AvatarPosition.Validate(); err != nil {
+			return errors.New("invalid avatar position: " + err.Error())
+		}
 	}
 	return nil
 }
 
-// handleRateLimitError handles rate limit errors
-func (h *SessionHandler) handleRateLimitError(c *gin.Context, err error) {
-	if rateLimitErr, ok := err.(*services.RateLimitError); ok {
-		// Set Retry-After header
-		c.Header("Retry-After", strconv.Itoa(int(rateLimitErr.RetryAfter.Seconds())))
-		
-		c.JSON(http.StatusTooManyRequests, ErrorResponse{
-			Code:    "RATE_LIMIT_EXCEEDED",
-			Message: "Rate limit exceeded",
-			Details: err.Error(),
-		})
-		return
-	}
-	
-	// Generic rate limit error
-	c.JSON(http.StatusTooManyRequests, ErrorResponse{
-		Code:    "RATE_LIMIT_EXCEEDED",
-		Message: "Rate limit exceeded",
-		Details: err.Error(),
-	})
-}
-
-// addRateLimitHeaders adds rate limit headers to the response
-func (h *SessionHandler) addRateLimitHeaders(c *gin.Context, userID string, action services.ActionType) {
-	headers, err := h.rateLimiter.GetRateLimitHeaders(c, userID, action)
-	if err != nil {
-		// Log error but don't fail the request
-		return
-	}
-	
-	for key, value := range headers {
-		c.Header(key, value)
-	}
-}
-
 // isUserAlreadyInMapError checks if the error indicates user already in map
 func isUserAlreadyInMapError(err error) bool {
 	// This would depend on how the session service reports this error
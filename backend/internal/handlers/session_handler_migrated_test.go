@@ -8,13 +8,13 @@ import (
 	"testing"
 	"time"
 
+	"breakoutglobe/internal/middleware"
 	"breakoutglobe/internal/models"
 	"breakoutglobe/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
-	"gorm.io/gorm"
 )
 
 // TestSessionHandler_Migrated demonstrates the new test infrastructure for session handlers
@@ -64,8 +64,8 @@ func (s *simpleSessionScenario) expectCreateRateLimitSuccess() *simpleSessionSce
 }
 
 func (s *simpleSessionScenario) expectUpdateRateLimitSuccess() *simpleSessionScenario {
-	s.mockRateLimiter.On("CheckRateLimit", mock.Anything, s.userID, services.ActionUpdateAvatar).Return(nil)
-	s.mockRateLimiter.On("GetRateLimitHeaders", mock.Anything, s.userID, services.ActionUpdateAvatar).Return(map[string]string{
+	s.mockRateLimiter.On("CheckRateLimit", mock.Anything, "session:"+s.sessionID, services.ActionUpdateAvatar).Return(nil)
+	s.mockRateLimiter.On("GetRateLimitHeaders", mock.Anything, "session:"+s.sessionID, services.ActionUpdateAvatar).Return(map[string]string{
 		"X-RateLimit-Limit":     "60",
 		"X-RateLimit-Remaining": "59",
 	}, nil)
@@ -143,6 +143,7 @@ func (s *simpleSessionScenario) updateAvatarPosition(sessionID string, position
 	body, _ := json.Marshal(request)
 	
 	req := httptest.NewRequest(http.MethodPut, "/api/sessions/"+sessionID+"/avatar", bytes.NewBuffer(body))
+	req.Header.Set(middleware.SessionTokenHeader, s.handler.sessionTokens.Issue(sessionID))
 	req.Header.Set("Content-Type", "application/json")
 	recorder := httptest.NewRecorder()
 	
@@ -152,6 +153,7 @@ func (s *simpleSessionScenario) updateAvatarPosition(sessionID string, position
 
 func (s *simpleSessionScenario) sessionHeartbeat(sessionID string) *httptest.ResponseRecorder {
 	req := httptest.NewRequest(http.MethodPost, "/api/sessions/"+sessionID+"/heartbeat", nil)
+	req.Header.Set(middleware.SessionTokenHeader, s.handler.sessionTokens.Issue(sessionID))
 	recorder := httptest.NewRecorder()
 	
 	s.router.ServeHTTP(recorder, req)
@@ -176,7 +178,7 @@ func TestCreateSession_Success_Migrated(t *testing.T) {
 	session := scenario.createSession(CreateSessionRequest{
 		UserID:         "user-123",
 		MapID:          "map-456",
-		AvatarPosition: models.LatLng{Lat: 40.7128, Lng: -74.0060},
+		AvatarPosition: &models.LatLng{Lat: 40.7128, Lng: -74.0060},
 	})
 
 	// Assertions focus on business logic, not HTTP details
@@ -206,7 +208,7 @@ func TestCreateSession_RateLimited_Migrated(t *testing.T) {
 	body, _ := json.Marshal(CreateSessionRequest{
 		UserID:         "user-123",
 		MapID:          "map-456",
-		AvatarPosition: models.LatLng{Lat: 40.7128, Lng: -74.0060},
+		AvatarPosition: &models.LatLng{Lat: 40.7128, Lng: -74.0060},
 	})
 	req := httptest.NewRequest(http.MethodPost, "/api/sessions", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
@@ -245,7 +247,7 @@ func TestGetSession_NotFound_Migrated(t *testing.T) {
 	defer scenario.cleanup()
 
 	// Setup session not found
-	scenario.mockSessionService.On("GetSession", mock.Anything, "non-existent").Return((*models.Session)(nil), gorm.ErrRecordNotFound)
+	scenario.mockSessionService.On("GetSession", mock.Anything, "non-existent").Return((*models.Session)(nil), services.ErrNotFound)
 
 	// Execute request
 	req := httptest.NewRequest(http.MethodGet, "/api/sessions/non-existent", nil)
@@ -266,8 +268,7 @@ func TestUpdateAvatarPosition_Success_Migrated(t *testing.T) {
 	defer scenario.cleanup()
 
 	// Setup successful position update
-	scenario.expectGetSessionSuccess().
-		expectUpdateRateLimitSuccess().
+	scenario.expectUpdateRateLimitSuccess().
 		expectUpdatePositionSuccess()
 
 	// Execute request
@@ -5,18 +5,19 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"breakoutglobe/internal/middleware"
 	"breakoutglobe/internal/models"
 	"breakoutglobe/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
-	"gorm.io/gorm"
 )
 
 // MockSessionService is a mock implementation of SessionServiceInterface
@@ -144,14 +145,14 @@ func (suite *SessionHandlerTestSuite) TestCreateSession() {
 	reqBody := CreateSessionRequest{
 		UserID:         "user-123",
 		MapID:          "map-456",
-		AvatarPosition: models.LatLng{Lat: 40.7128, Lng: -74.0060},
+		AvatarPosition: &models.LatLng{Lat: 40.7128, Lng: -74.0060},
 	}
 	
 	expectedSession := &models.Session{
 		ID:         "session-789",
 		UserID:     reqBody.UserID,
 		MapID:      reqBody.MapID,
-		AvatarPos:  reqBody.AvatarPosition,
+		AvatarPos:  *reqBody.AvatarPosition,
 		CreatedAt:  time.Now(),
 		LastActive: time.Now(),
 		IsActive:   true,
@@ -159,7 +160,7 @@ func (suite *SessionHandlerTestSuite) TestCreateSession() {
 	
 	// Mock expectations
 	suite.mockRateLimiter.On("CheckRateLimit", mock.AnythingOfType("*gin.Context"), reqBody.UserID, services.ActionCreateSession).Return(nil)
-	suite.mockSessionService.On("CreateSession", mock.AnythingOfType("*gin.Context"), reqBody.UserID, reqBody.MapID, reqBody.AvatarPosition).Return(expectedSession, nil)
+	suite.mockSessionService.On("CreateSession", mock.AnythingOfType("*gin.Context"), reqBody.UserID, reqBody.MapID, *reqBody.AvatarPosition).Return(expectedSession, nil)
 	suite.mockRateLimiter.On("GetRateLimitHeaders", mock.AnythingOfType("*gin.Context"), reqBody.UserID, services.ActionCreateSession).Return(map[string]string{
 		"X-RateLimit-Limit":     "10",
 		"X-RateLimit-Remaining": "9",
@@ -181,6 +182,7 @@ func (suite *SessionHandlerTestSuite) TestCreateSession() {
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	suite.NoError(err)
 	suite.Equal(expectedSession.ID, response.SessionID)
+	suite.True(suite.handler.sessionTokens.VerifySessionToken(expectedSession.ID, response.SessionToken))
 	suite.Equal(expectedSession.UserID, response.UserID)
 	suite.Equal(expectedSession.MapID, response.MapID)
 	suite.Equal(expectedSession.AvatarPos.Lat, response.AvatarPosition.Lat)
@@ -195,7 +197,7 @@ func (suite *SessionHandlerTestSuite) TestCreateSession_RateLimited() {
 	reqBody := CreateSessionRequest{
 		UserID:         "user-123",
 		MapID:          "map-456",
-		AvatarPosition: models.LatLng{Lat: 40.7128, Lng: -74.0060},
+		AvatarPosition: &models.LatLng{Lat: 40.7128, Lng: -74.0060},
 	}
 	
 	// Mock rate limit exceeded
@@ -230,6 +232,10 @@ func (suite *SessionHandlerTestSuite) TestCreateSession_RateLimited() {
 }
 
 func (suite *SessionHandlerTestSuite) TestCreateSession_InvalidJSON() {
+	// Requests without a user ID are counted per client IP
+	suite.mockRateLimiter.On("CheckRateLimit", mock.AnythingOfType("*gin.Context"), "ip:192.0.2.1", services.ActionCreateSession).Return(nil)
+	suite.mockRateLimiter.On("GetRateLimitHeaders", mock.AnythingOfType("*gin.Context"), "ip:192.0.2.1", services.ActionCreateSession).Return(map[string]string{}, nil)
+	
 	// Create request with invalid JSON
 	req := httptest.NewRequest(http.MethodPost, "/api/sessions", bytes.NewBufferString("invalid json"))
 	req.Header.Set("Content-Type", "application/json")
@@ -251,9 +257,13 @@ func (suite *SessionHandlerTestSuite) TestCreateSession_ValidationError() {
 	reqBody := CreateSessionRequest{
 		UserID:         "", // Invalid: empty user ID
 		MapID:          "map-456",
-		AvatarPosition: models.LatLng{Lat: 40.7128, Lng: -74.0060},
+		AvatarPosition: &models.LatLng{Lat: 40.7128, Lng: -74.0060},
 	}
 	
+	// Requests without a user ID are counted per client IP
+	suite.mockRateLimiter.On("CheckRateLimit", mock.AnythingOfType("*gin.Context"), "ip:192.0.2.1", services.ActionCreateSession).Return(nil)
+	suite.mockRateLimiter.On("GetRateLimitHeaders", mock.AnythingOfType("*gin.Context"), "ip:192.0.2.1", services.ActionCreateSession).Return(map[string]string{}, nil)
+	
 	// Create request
 	body, _ := json.Marshal(reqBody)
 	req := httptest.NewRequest(http.MethodPost, "/api/sessions", bytes.NewBuffer(body))
@@ -276,12 +286,13 @@ func (suite *SessionHandlerTestSuite) TestCreateSession_ServiceError() {
 	reqBody := CreateSessionRequest{
 		UserID:         "user-123",
 		MapID:          "map-456",
-		AvatarPosition: models.LatLng{Lat: 40.7128, Lng: -74.0060},
+		AvatarPosition: &models.LatLng{Lat: 40.7128, Lng: -74.0060},
 	}
 	
 	// Mock expectations
 	suite.mockRateLimiter.On("CheckRateLimit", mock.AnythingOfType("*gin.Context"), reqBody.UserID, services.ActionCreateSession).Return(nil)
-	suite.mockSessionService.On("CreateSession", mock.AnythingOfType("*gin.Context"), reqBody.UserID, reqBody.MapID, reqBody.AvatarPosition).Return((*models.Session)(nil), errors.New("service error"))
+	suite.mockRateLimiter.On("GetRateLimitHeaders", mock.AnythingOfType("*gin.Context"), reqBody.UserID, services.ActionCreateSession).Return(map[string]string{}, nil)
+	suite.mockSessionService.On("CreateSession", mock.AnythingOfType("*gin.Context"), reqBody.UserID, reqBody.MapID, *reqBody.AvatarPosition).Return((*models.Session)(nil), errors.New("service error"))
 	
 	// Create request
 	body, _ := json.Marshal(reqBody)
@@ -301,6 +312,52 @@ func (suite *SessionHandlerTestSuite) TestCreateSession_ServiceError() {
 	suite.Equal("INTERNAL_ERROR", response.Code)
 }
 
+type stubSpawnLocator struct {
+	mapID, clientIP string
+	position        models.LatLng
+}
+
+func (s *stubSpawnLocator) DefaultSpawnPosition(ctx context.Context, mapID, clientIP string) models.LatLng {
+	s.mapID, s.clientIP = mapID, clientIP
+	return s.position
+}
+
+func (suite *SessionHandlerTestSuite) TestCreateSession_DefaultSpawnPosition() {
+	locator := &stubSpawnLocator{position: models.LatLng{Lat: 52.52, Lng: 13.405}}
+	suite.handler.SetSpawnLocator(locator)
+	
+	expectedSession := &models.Session{
+		ID:        "session-789",
+		UserID:    "user-123",
+		MapID:     "map-456",
+		AvatarPos: locator.position,
+		IsActive:  true,
+	}
+	
+	// Mock expectations
+	suite.mockRateLimiter.On("CheckRateLimit", mock.AnythingOfType("*gin.Context"), "user-123", services.ActionCreateSession).Return(nil)
+	suite.mockSessionService.On("CreateSession", mock.AnythingOfType("*gin.Context"), "user-123", "map-456", locator.position).Return(expectedSession, nil)
+	suite.mockRateLimiter.On("GetRateLimitHeaders", mock.AnythingOfType("*gin.Context"), "user-123", services.ActionCreateSession).Return(map[string]string{}, nil)
+	
+	// Create request without an avatar position
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions", bytes.NewBufferString(`{"userId":"user-123","mapId":"map-456"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "81.2.69.160:51234"
+	w := httptest.NewRecorder()
+	
+	// Execute
+	suite.router.ServeHTTP(w, req)
+	
+	// Assert
+	suite.Equal(http.StatusCreated, w.Code)
+	suite.Equal("map-456", locator.mapID)
+	suite.Equal("81.2.69.160", locator.clientIP)
+	
+	var response CreateSessionResponse
+	suite.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	suite.Equal(locator.position, response.AvatarPosition)
+}
+
 func (suite *SessionHandlerTestSuite) TestGetSession() {
 	sessionID := "session-789"
 	expectedSession := &models.Session{
@@ -340,7 +397,7 @@ func (suite *SessionHandlerTestSuite) TestGetSession_NotFound() {
 	sessionID := "non-existent-session"
 	
 	// Mock expectations
-	suite.mockSessionService.On("GetSession", mock.AnythingOfType("*gin.Context"), sessionID).Return((*models.Session)(nil), gorm.ErrRecordNotFound)
+	suite.mockSessionService.On("GetSession", mock.AnythingOfType("*gin.Context"), sessionID).Return((*models.Session)(nil), fmt.Errorf("session %w", services.ErrNotFound))
 	
 	// Create request
 	req := httptest.NewRequest(http.MethodGet, "/api/sessions/"+sessionID, nil)
@@ -364,21 +421,10 @@ func (suite *SessionHandlerTestSuite) TestUpdateAvatarPosition() {
 		Position: models.LatLng{Lat: 41.0, Lng: -75.0},
 	}
 	
-	existingSession := &models.Session{
-		ID:         sessionID,
-		UserID:     "user-123",
-		MapID:      "map-456",
-		AvatarPos:  models.LatLng{Lat: 40.7128, Lng: -74.0060},
-		CreatedAt:  time.Now(),
-		LastActive: time.Now(),
-		IsActive:   true,
-	}
-	
 	// Mock expectations
-	suite.mockSessionService.On("GetSession", mock.AnythingOfType("*gin.Context"), sessionID).Return(existingSession, nil)
-	suite.mockRateLimiter.On("CheckRateLimit", mock.AnythingOfType("*gin.Context"), existingSession.UserID, services.ActionUpdateAvatar).Return(nil)
+	suite.mockRateLimiter.On("CheckRateLimit", mock.AnythingOfType("*gin.Context"), "session:"+sessionID, services.ActionUpdateAvatar).Return(nil)
 	suite.mockSessionService.On("UpdateAvatarPosition", mock.AnythingOfType("*gin.Context"), sessionID, reqBody.Position).Return(nil)
-	suite.mockRateLimiter.On("GetRateLimitHeaders", mock.AnythingOfType("*gin.Context"), existingSession.UserID, services.ActionUpdateAvatar).Return(map[string]string{
+	suite.mockRateLimiter.On("GetRateLimitHeaders", mock.AnythingOfType("*gin.Context"), "session:"+sessionID, services.ActionUpdateAvatar).Return(map[string]string{
 		"X-RateLimit-Limit":     "60",
 		"X-RateLimit-Remaining": "59",
 	}, nil)
@@ -386,6 +432,7 @@ func (suite *SessionHandlerTestSuite) TestUpdateAvatarPosition() {
 	// Create request
 	body, _ := json.Marshal(reqBody)
 	req := httptest.NewRequest(http.MethodPut, "/api/sessions/"+sessionID+"/avatar", bytes.NewBuffer(body))
+	req.Header.Set(middleware.SessionTokenHeader, suite.handler.sessionTokens.Issue(sessionID))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	
@@ -411,9 +458,14 @@ func (suite *SessionHandlerTestSuite) TestUpdateAvatarPosition_InvalidPosition()
 		Position: models.LatLng{Lat: 91.0, Lng: -75.0}, // Invalid latitude
 	}
 	
+	// Mock expectations
+	suite.mockRateLimiter.On("CheckRateLimit", mock.AnythingOfType("*gin.Context"), "session:"+sessionID, services.ActionUpdateAvatar).Return(nil)
+	suite.mockRateLimiter.On("GetRateLimitHeaders", mock.AnythingOfType("*gin.Context"), "session:"+sessionID, services.ActionUpdateAvatar).Return(map[string]string{}, nil)
+	
 	// Create request
 	body, _ := json.Marshal(reqBody)
 	req := httptest.NewRequest(http.MethodPut, "/api/sessions/"+sessionID+"/avatar", bytes.NewBuffer(body))
+	req.Header.Set(middleware.SessionTokenHeader, suite.handler.sessionTokens.Issue(sessionID))
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 	
@@ -437,6 +489,7 @@ func (suite *SessionHandlerTestSuite) TestSessionHeartbeat() {
 	
 	// Create request
 	req := httptest.NewRequest(http.MethodPost, "/api/sessions/"+sessionID+"/heartbeat", nil)
+	req.Header.Set(middleware.SessionTokenHeader, suite.handler.sessionTokens.Issue(sessionID))
 	w := httptest.NewRecorder()
 	
 	// Execute
@@ -459,6 +512,7 @@ func (suite *SessionHandlerTestSuite) TestEndSession() {
 	
 	// Create request
 	req := httptest.NewRequest(http.MethodDelete, "/api/sessions/"+sessionID, nil)
+	req.Header.Set(middleware.SessionTokenHeader, suite.handler.sessionTokens.Issue(sessionID))
 	w := httptest.NewRecorder()
 	
 	// Execute
@@ -473,6 +527,44 @@ func (suite *SessionHandlerTestSuite) TestEndSession() {
 	suite.True(response.Success)
 }
 
+func (suite *SessionHandlerTestSuite) TestSessionRoutes_RequireSessionToken() {
+	sessionID := "session-789"
+	suite.mockRateLimiter.On("CheckRateLimit", mock.AnythingOfType("*gin.Context"), "session:"+sessionID, services.ActionUpdateAvatar).Return(nil)
+	suite.mockRateLimiter.On("GetRateLimitHeaders", mock.AnythingOfType("*gin.Context"), "session:"+sessionID, services.ActionUpdateAvatar).Return(map[string]string{}, nil)
+	
+	// Session IDs are listed in broadcasts, so knowing one isn't enough
+	for _, token := range []string{"", sessionID, suite.handler.sessionTokens.Issue("session-other")} {
+		for _, req := range []*http.Request{
+			httptest.NewRequest(http.MethodPut, "/api/sessions/"+sessionID+"/avatar", bytes.NewBufferString(`{"position":{"lat":1,"lng":2}}`)),
+			httptest.NewRequest(http.MethodPost, "/api/sessions/"+sessionID+"/heartbeat", nil),
+			httptest.NewRequest(http.MethodDelete, "/api/sessions/"+sessionID, nil),
+		} {
+			req.Header.Set(middleware.SessionTokenHeader, token)
+			w := httptest.NewRecorder()
+			suite.router.ServeHTTP(w, req)
+			
+			suite.Equal(http.StatusUnauthorized, w.Code, req.Method+" "+req.URL.Path)
+			suite.Contains(w.Body.String(), "INVALID_SESSION_TOKEN")
+		}
+	}
+}
+
+func (suite *SessionHandlerTestSuite) TestCreateSession_ForOtherUser() {
+	router := gin.New()
+	suite.handler.RegisterRoutes(router, func(c *gin.Context) {
+		c.Set("userID", "user-1")
+	})
+	suite.mockRateLimiter.On("CheckRateLimit", mock.AnythingOfType("*gin.Context"), "user-1", services.ActionCreateSession).Return(nil)
+	suite.mockRateLimiter.On("GetRateLimitHeaders", mock.AnythingOfType("*gin.Context"), "user-1", services.ActionCreateSession).Return(map[string]string{}, nil)
+	
+	req := httptest.NewRequest(http.MethodPost, "/api/sessions", bytes.NewBufferString(`{"userId":"user-2","mapId":"map-456"}`))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	
+	suite.Equal(http.StatusForbidden, w.Code)
+	suite.mockSessionService.AssertNotCalled(suite.T(), "CreateSession", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
 func (suite *SessionHandlerTestSuite) TestGetActiveSessionsForMap() {
 	mapID := "map-456"
 	expectedSessions := []*models.Session{
@@ -509,8 +601,40 @@ func (suite *SessionHandlerTestSuite) TestGetActiveSessionsForMap() {
 	err := json.Unmarshal(w.Body.Bytes(), &response)
 	suite.NoError(err)
 	suite.Equal(len(expectedSessions), len(response.Sessions))
-	suite.Equal(expectedSessions[0].ID, response.Sessions[0].SessionID)
-	suite.Equal(expectedSessions[1].ID, response.Sessions[1].SessionID)
+	suite.Equal(expectedSessions[0].UserID, response.Sessions[0].UserID)
+	suite.Equal(expectedSessions[1].UserID, response.Sessions[1].UserID)
+	
+	// Session IDs aren't listed to everyone on the map
+	suite.NotContains(w.Body.String(), expectedSessions[0].ID)
+}
+
+func (suite *SessionHandlerTestSuite) TestGetActiveSessionsForMap_PositionPrivacy() {
+	mapID := "map-456"
+	position := models.LatLng{Lat: 40.7128, Lng: -74.0060}
+	suite.mockSessionService.On("GetActiveSessionsForMap", mock.AnythingOfType("*gin.Context"), mapID).Return([]*models.Session{
+		{ID: "session-1", UserID: "user-1", MapID: mapID, AvatarPos: position, IsActive: true},
+	}, nil)
+	
+	privacy := models.PositionPrivacy{GridMeters: 500}
+	suite.handler.SetPositionPrivacyProvider(&stubPositionPrivacyService{settings: map[string]models.PositionPrivacy{mapID: privacy}})
+	
+	positionFor := func(role models.UserRole) models.LatLng {
+		router := gin.New()
+		suite.handler.RegisterRoutes(router, func(c *gin.Context) { c.Set("role", role) })
+		
+		req := httptest.NewRequest(http.MethodGet, "/api/maps/"+mapID+"/sessions", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		suite.Equal(http.StatusOK, w.Code)
+		
+		var response GetActiveSessionsResponse
+		suite.NoError(json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Sessions[0].AvatarPosition
+	}
+	
+	// Participants see the grid cell, admins the exact position
+	suite.Equal(privacy.Coarsen(position), positionFor(models.UserRoleUser))
+	suite.Equal(position, positionFor(models.UserRoleAdmin))
 }
 
 func TestSessionHandlerTestSuite(t *testing.T) {
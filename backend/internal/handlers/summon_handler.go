@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxSummonUsers bounds the users selected in one summon request
+const maxSummonUsers = 500
+
+// SummonServiceInterface defines the interface for moving participants to a POI
+type SummonServiceInterface interface {
+	Summon(ctx context.Context, mapID, summonedBy, poiID string, userIDs []string) (*services.SummonResult, error)
+}
+
+// SummonHandler handles the endpoint facilitators move participants to a POI with
+type SummonHandler struct {
+	summon SummonServiceInterface
+}
+
+// NewSummonHandler creates a new SummonHandler
+func NewSummonHandler(summon SummonServiceInterface) *SummonHandler {
+	return &SummonHandler{
+		summon: summon,
+	}
+}
+
+// RegisterRoutes registers summon routes
+// organizerMiddleware should authenticate the caller and require an organizer (admin) role
+func (h *SummonHandler) RegisterRoutes(router *gin.Engine, organizerMiddleware ...gin.HandlerFunc) {
+	maps := router.Group("/api/maps", organizerMiddleware...)
+	{
+		maps.POST("/:mapId/summon", h.Summon)
+	}
+}
+
+// SummonRequest selects the POI to move participants to and, optionally,
+// the participants; everyone on the map but the caller is moved without them
+type SummonRequest struct {
+	POIID   string   `json:"poiId" binding:"required"`
+	UserIDs []string `json:"userIds"`
+}
+
+// Summon handles POST /api/maps/:mapId/summon
+// Avatars are placed around the POI and their users join it; the response
+// tells who was moved and joined
+func (h *SummonHandler) Summon(c *gin.Context) {
+	var req SummonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+	if len(req.UserIDs) > maxSummonUsers {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "VALIDATION_ERROR",
+			Message: "Too many users selected",
+		})
+		return
+	}
+
+	result, err := h.summon.Summon(c.Request.Context(), c.Param("mapId"), c.GetString("userID"), req.POIID, req.UserIDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNotFound):
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Code:    "POI_NOT_FOUND",
+				Message: "POI not found on this map",
+				Details: err.Error(),
+			})
+		case errors.Is(err, services.ErrInvalidInput):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Code:    "VALIDATION_ERROR",
+				Message: "Failed to summon participants",
+				Details: err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Code:    "INTERNAL_ERROR",
+				Message: "Failed to summon participants",
+				Details: err.Error(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSummonService struct {
+	summonedBy string
+	userIDs    []string
+}
+
+func (s *fakeSummonService) Summon(ctx context.Context, mapID, summonedBy, poiID string, userIDs []string) (*services.SummonResult, error) {
+	if poiID != "poi-1" {
+		return nil, fmt.Errorf("POI %w: %s", services.ErrNotFound, poiID)
+	}
+	s.summonedBy, s.userIDs = summonedBy, userIDs
+	return &services.SummonResult{POIID: poiID, Users: []models.SummonedUser{{UserID: "user-2", SessionIDs: []string{"session-2"}, Moved: true, Joined: true}}}, nil
+}
+
+func setupSummonTest() (*gin.Engine, *fakeSummonService) {
+	gin.SetMode(gin.TestMode)
+
+	service := &fakeSummonService{}
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("userID", "facilitator")
+		c.Next()
+	})
+	NewSummonHandler(service).RegisterRoutes(router)
+	return router, service
+}
+
+func TestSummonHandler_Summon(t *testing.T) {
+	router, service := setupSummonTest()
+
+	w := serveMapOwnership(router, http.MethodPost, "/api/maps/map-1/summon", SummonRequest{POIID: "poi-1", UserIDs: []string{"user-2"}})
+	require.Equal(t, http.StatusOK, w.Code)
+	var result services.SummonResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	require.Len(t, result.Users, 1)
+	assert.True(t, result.Users[0].Joined)
+	assert.Equal(t, "facilitator", service.summonedBy)
+	assert.Equal(t, []string{"user-2"}, service.userIDs)
+
+	w = serveMapOwnership(router, http.MethodPost, "/api/maps/map-1/summon", SummonRequest{POIID: "poi-2"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serveMapOwnership(router, http.MethodPost, "/api/maps/map-1/summon", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = serveMapOwnership(router, http.MethodPost, "/api/maps/map-1/summon", SummonRequest{POIID: "poi-1", UserIDs: make([]string, maxSummonUsers+1)})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
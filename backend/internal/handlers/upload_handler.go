@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContentLookupInterface defines the interface for finding and claiming already stored uploads
+type ContentLookupInterface interface {
+	LookupContent(ctx context.Context, contentHash string) (string, bool)
+	ClaimContent(ctx context.Context, ownerID, contentHash string) (string, bool, error)
+}
+
+// UploadHandler handles upload-related HTTP requests
+type UploadHandler struct {
+	contentLookup ContentLookupInterface
+}
+
+// NewUploadHandler creates a new UploadHandler
+func NewUploadHandler(contentLookup ContentLookupInterface) *UploadHandler {
+	return &UploadHandler{
+		contentLookup: contentLookup,
+	}
+}
+
+// RegisterRoutes registers upload routes
+// authMiddleware should resolve the acting user into the "userID" context value
+func (h *UploadHandler) RegisterRoutes(router *gin.Engine, authMiddleware ...gin.HandlerFunc) {
+	api := router.Group("/api", authMiddleware...)
+	{
+		api.GET("/uploads/content/:hash", h.LookupContent)
+		api.POST("/uploads/content/:hash/claim", h.ClaimContent)
+	}
+}
+
+// ContentLookupResponse represents the result of a content hash lookup
+type ContentLookupResponse struct {
+	ContentHash string `json:"contentHash"`
+	Exists      bool   `json:"exists"`
+	URL         string `json:"url,omitempty"`
+}
+
+// LookupContent handles GET /api/uploads/content/:hash
+// Clients hash a file (SHA-256, hex) before uploading and skip the upload if it already exists
+func (h *UploadHandler) LookupContent(c *gin.Context) {
+	contentHash := c.Param("hash")
+	if contentHash == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "Content hash is required",
+		})
+		return
+	}
+
+	url, exists := h.contentLookup.LookupContent(c.Request.Context(), contentHash)
+	if !exists {
+		c.JSON(http.StatusNotFound, ContentLookupResponse{
+			ContentHash: contentHash,
+			Exists:      false,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ContentLookupResponse{
+		ContentHash: contentHash,
+		Exists:      true,
+		URL:         url,
+	})
+}
+
+// ClaimContent handles POST /api/uploads/content/:hash/claim
+// Clients that skipped an upload claim the stored file instead, so it isn't
+// deleted while they use its URL
+func (h *UploadHandler) ClaimContent(c *gin.Context) {
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Code:    "UNAUTHORIZED",
+			Message: "Authentication required",
+		})
+		return
+	}
+
+	contentHash := c.Param("hash")
+	url, exists, err := h.contentLookup.ClaimContent(c.Request.Context(), userID, contentHash)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: "Failed to claim upload",
+			Details: err.Error(),
+		})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, ContentLookupResponse{
+			ContentHash: contentHash,
+			Exists:      false,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, ContentLookupResponse{
+		ContentHash: contentHash,
+		Exists:      true,
+		URL:         url,
+	})
+}
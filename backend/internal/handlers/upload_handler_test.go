@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeContentLookup is a simple in-memory ContentLookupInterface
+type fakeContentLookup struct {
+	urls   map[string]string
+	claims []string
+}
+
+func (f *fakeContentLookup) LookupContent(ctx context.Context, contentHash string) (string, bool) {
+	url, ok := f.urls[contentHash]
+	return url, ok
+}
+
+func (f *fakeContentLookup) ClaimContent(ctx context.Context, ownerID, contentHash string) (string, bool, error) {
+	url, ok := f.urls[contentHash]
+	if ok {
+		f.claims = append(f.claims, ownerID+":"+contentHash)
+	}
+	return url, ok, nil
+}
+
+// setupUploadTest serves upload routes behind a stand-in for the identity
+// middleware that authenticates requests with an X-User-ID header
+func setupUploadTest() (*gin.Engine, *fakeContentLookup) {
+	gin.SetMode(gin.TestMode)
+
+	lookup := &fakeContentLookup{urls: map[string]string{
+		"abc123": "http://localhost:8080/uploads/blobs/ab/abc123.png",
+	}}
+	router := gin.New()
+	NewUploadHandler(lookup).RegisterRoutes(router, func(c *gin.Context) {
+		userID := c.GetHeader("X-User-ID")
+		if userID == "" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Set("userID", userID)
+	})
+	return router, lookup
+}
+
+func serveUpload(router *gin.Engine, method, path, userID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if userID != "" {
+		req.Header.Set("X-User-ID", userID)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestUploadHandler_LookupContent(t *testing.T) {
+	router, lookup := setupUploadTest()
+
+	t.Run("known content", func(t *testing.T) {
+		w := serveUpload(router, http.MethodGet, "/api/uploads/content/abc123", "user-1")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response ContentLookupResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.True(t, response.Exists)
+		assert.Equal(t, lookup.urls["abc123"], response.URL)
+	})
+
+	t.Run("unknown content", func(t *testing.T) {
+		w := serveUpload(router, http.MethodGet, "/api/uploads/content/def456", "user-1")
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		var response ContentLookupResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.False(t, response.Exists)
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		w := serveUpload(router, http.MethodGet, "/api/uploads/content/abc123", "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestUploadHandler_ClaimContent(t *testing.T) {
+	router, lookup := setupUploadTest()
+
+	w := serveUpload(router, http.MethodPost, "/api/uploads/content/abc123/claim", "user-1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response ContentLookupResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, lookup.urls["abc123"], response.URL)
+	assert.Equal(t, []string{"user-1:abc123"}, lookup.claims)
+
+	w = serveUpload(router, http.MethodPost, "/api/uploads/content/def456/claim", "user-1")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serveUpload(router, http.MethodPost, "/api/uploads/content/abc123/claim", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Len(t, lookup.claims, 1)
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UsageServiceInterface defines the interface for reporting metered map usage
+type UsageServiceInterface interface {
+	GetReport(ctx context.Context, mapID string, from, to time.Time) (*services.UsageReport, error)
+}
+
+// UsageHandler handles the usage report of hosted deployments
+type UsageHandler struct {
+	usageService UsageServiceInterface
+}
+
+// NewUsageHandler creates a new UsageHandler
+func NewUsageHandler(usageService UsageServiceInterface) *UsageHandler {
+	return &UsageHandler{
+		usageService: usageService,
+	}
+}
+
+// RegisterRoutes registers usage routes
+// operatorMiddleware should authenticate the caller and require the superadmin
+// role, since usage spans every map of the deployment
+func (h *UsageHandler) RegisterRoutes(router *gin.Engine, operatorMiddleware ...gin.HandlerFunc) {
+	admin := router.Group("/api/admin", operatorMiddleware...)
+	{
+		admin.GET("/usage", h.GetUsage)
+	}
+}
+
+// GetUsage handles GET /api/admin/usage
+// The optional "mapId" query parameter limits the report to one map. "from"
+// and "to" are RFC 3339 timestamps and default to the current calendar month.
+func (h *UsageHandler) GetUsage(c *gin.Context) {
+	from, ok := parseTimeQuery(c, "from")
+	if !ok {
+		return
+	}
+	to, ok := parseTimeQuery(c, "to")
+	if !ok {
+		return
+	}
+
+	report, err := h.usageService.GetReport(c.Request.Context(), c.Query("mapId"), from, to)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid time range",
+				Details: err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Code:    "INTERNAL_ERROR",
+			Message: "Failed to get usage",
+			Details: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
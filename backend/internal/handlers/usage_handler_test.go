@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubUsageService struct {
+	mapID    string
+	from, to time.Time
+	err      error
+}
+
+func (s *stubUsageService) GetReport(ctx context.Context, mapID string, from, to time.Time) (*services.UsageReport, error) {
+	s.mapID, s.from, s.to = mapID, from, to
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &services.UsageReport{
+		From: from,
+		To:   to,
+		Maps: []services.MapUsageTotal{{MapID: "map-1", WebSocketBytes: 2048, StorageBytes: 512, CallMinutes: 3}},
+	}, nil
+}
+
+func setupUsageTest(service *stubUsageService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	NewUsageHandler(service).RegisterRoutes(router)
+	return router
+}
+
+func TestUsageHandler_GetUsage(t *testing.T) {
+	service := &stubUsageService{}
+	router := setupUsageTest(service)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/usage?mapId=map-1&from=2026-05-01T00:00:00Z&to=2026-06-01T00:00:00Z", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "map-1", service.mapID)
+	assert.Equal(t, time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), service.from)
+	assert.Equal(t, time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), service.to)
+
+	var response services.UsageReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Maps, 1)
+	assert.Equal(t, int64(2048), response.Maps[0].WebSocketBytes)
+	assert.Equal(t, int64(3), response.Maps[0].CallMinutes)
+}
+
+func TestUsageHandler_GetUsage_DefaultsToAllMaps(t *testing.T) {
+	service := &stubUsageService{}
+	router := setupUsageTest(service)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/usage", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, service.mapID)
+	assert.True(t, service.from.IsZero())
+	assert.True(t, service.to.IsZero())
+}
+
+func TestUsageHandler_GetUsage_InvalidRange(t *testing.T) {
+	service := &stubUsageService{err: fmt.Errorf("%w: from must be before to", services.ErrInvalidInput)}
+	router := setupUsageTest(service)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/usage?from=2026-06-01T00:00:00Z&to=2026-05-01T00:00:00Z", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "VALIDATION_ERROR")
+}
+
+func TestUsageHandler_GetUsage_MalformedTime(t *testing.T) {
+	router := setupUsageTest(&stubUsageService{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/usage?from=yesterday", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
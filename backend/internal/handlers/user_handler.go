@@ -8,12 +8,16 @@ import (
 	"strings"
 	"time"
 
+	"breakoutglobe/internal/middleware"
 	"breakoutglobe/internal/models"
 	"breakoutglobe/internal/services"
 
 	"github.com/gin-gonic/gin"
 )
 
+// maxAvatarBytes bounds the size of uploaded avatar images
+const maxAvatarBytes = 2 << 20
+
 // UserServiceInterface defines the interface for user service operations
 type UserServiceInterface interface {
 	CreateGuestProfile(ctx context.Context, displayName string) (*models.User, error)
@@ -21,6 +25,8 @@ type UserServiceInterface interface {
 	GetUser(ctx context.Context, userID string) (*models.User, error)
 	UploadAvatar(ctx context.Context, userID string, filename string, fileData []byte) (*models.User, error)
 	UpdateProfile(ctx context.Context, userID string, req *services.UpdateProfileRequest) (*models.User, error)
+	DeleteAvatar(ctx context.Context, userID string) (*models.User, error)
+	UpdateRole(ctx context.Context, userID string, role models.UserRole) (*models.User, error)
 	ClearAllUsers(ctx context.Context) error
 }
 
@@ -45,24 +51,28 @@ func (h *UserHandler) RegisterRoutes(router *gin.Engine, authMiddleware ...gin.H
 	{
 		// User profile management
 		// Guest profile creation is public (no auth required)
-		api.POST("/users/profile", h.CreateProfile)
+		api.POST("/users/profile", rateLimited(h.rateLimiter, services.ActionCreatePOI, middleware.RateLimitByIP("ip:"), nil, h.CreateProfile)...)
 		api.GET("/users/profile", h.GetProfile)
 		
-		// Profile updates and avatar uploads require authentication
-		if len(authMiddleware) > 0 {
-			api.PUT("/users/profile", append(authMiddleware, h.UpdateProfile)...)
-			api.POST("/users/avatar", append(authMiddleware, h.UploadAvatar)...)
-		} else {
-			// Fallback for backward compatibility (no auth)
-			api.PUT("/users/profile", h.UpdateProfile)
-			api.POST("/users/avatar", h.UploadAvatar)
-		}
+		// Profile updates and avatar uploads require authentication if middleware provided
+		api.PUT("/users/profile", rateLimited(h.rateLimiter, services.ActionUpdateProfile, middleware.RateLimitByUser, authMiddleware, h.UpdateProfile)...)
+		api.POST("/users/avatar", rateLimited(h.rateLimiter, services.ActionCreatePOI, middleware.RateLimitByUser, authMiddleware, h.UploadAvatar)...)
+		api.DELETE("/users/me/avatar", rateLimited(h.rateLimiter, services.ActionUpdateProfile, middleware.RateLimitByUser, authMiddleware, h.DeleteAvatar)...)
 		
 		// Development endpoints (TODO: Remove in production)
 		api.DELETE("/users/dev/clear-all", h.ClearAllUsers)
 	}
 }
 
+// RegisterAdminRoutes registers user management routes
+// adminMiddleware should authenticate the caller and require a superadmin role
+func (h *UserHandler) RegisterAdminRoutes(router *gin.Engine, adminMiddleware ...gin.HandlerFunc) {
+	admin := router.Group("/api/admin", adminMiddleware...)
+	{
+		admin.PUT("/users/:userId/role", h.UpdateUserRole)
+	}
+}
+
 // Request/Response DTOs
 
 // CreateProfileRequest represents the request body for creating a user profile
@@ -124,12 +134,6 @@ func (h *UserHandler) CreateProfile(c *gin.Context) {
 		return
 	}
 	
-	// Check rate limit (using existing ActionCreatePOI for now)
-	if err := h.rateLimiter.CheckRateLimit(c, "anonymous", services.ActionCreatePOI); err != nil {
-		h.handleRateLimitError(c, err)
-		return
-	}
-	
 	// Log the incoming request for debugging
 	fmt.Printf("🚀 UserHandler: CreateProfile called with DisplayName='%s', AboutMe='%s'\n", req.DisplayName, req.AboutMe)
 	
@@ -160,9 +164,6 @@ func (h *UserHandler) CreateProfile(c *gin.Context) {
 	
 	fmt.Printf("✅ UserHandler: Profile created successfully, AboutMe='%v'\n", user.AboutMe)
 	
-	// Add rate limit headers
-	h.addRateLimitHeaders(c, user.ID, services.ActionCreatePOI)
-	
 	// Return response
 	response := CreateProfileResponse{
 		ID:          user.ID,
@@ -196,14 +197,8 @@ func (h *UserHandler) UploadAvatar(c *gin.Context) {
 		return
 	}
 	
-	// Check rate limit
-	if err := h.rateLimiter.CheckRateLimit(c, userID, services.ActionCreatePOI); err != nil {
-		h.handleRateLimitError(c, err)
-		return
-	}
-	
 	// Parse multipart form
-	err := c.Request.ParseMultipartForm(2 << 20) // 2MB max
+	err := c.Request.ParseMultipartForm(maxAvatarBytes)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Code:    "INVALID_REQUEST",
@@ -225,8 +220,8 @@ func (h *UserHandler) UploadAvatar(c *gin.Context) {
 	}
 	defer file.Close()
 	
-	// Validate file size (max 2MB)
-	if header.Size > 2*1024*1024 {
+	// Validate file size
+	if header.Size > maxAvatarBytes {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Code:    "FILE_TOO_LARGE",
 			Message: "File size must be less than 2MB",
@@ -273,8 +268,55 @@ func (h *UserHandler) UploadAvatar(c *gin.Context) {
 		return
 	}
 	
-	// Add rate limit headers
-	h.addRateLimitHeaders(c, userID, services.ActionCreatePOI)
+	// Return updated user profile
+	response := CreateProfileResponse{
+		ID:          user.ID,
+		DisplayName: user.DisplayName,
+		AccountType: string(user.AccountType),
+		Role:        string(user.Role),
+		IsActive:    user.IsActive,
+		CreatedAt:   user.CreatedAt.Format(time.RFC3339),
+		AvatarURL:   stringPtrToString(user.AvatarURL),
+		AboutMe:     user.AboutMe,
+	}
+	
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteAvatar handles DELETE /api/users/me/avatar
+func (h *UserHandler) DeleteAvatar(c *gin.Context) {
+	// Get user ID from context (set by auth middleware) or header (for guest users)
+	var userID string
+	if contextUserID, exists := c.Get("userID"); exists {
+		userID = contextUserID.(string)
+	} else {
+		userID = c.GetHeader("X-User-ID")
+	}
+	
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Code:    "UNAUTHORIZED",
+			Message: "User ID required",
+		})
+		return
+	}
+	
+	user, err := h.userService.DeleteAvatar(c, userID)
+	if err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Code:    "USER_NOT_FOUND",
+				Message: "User not found",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Code:    "DELETE_FAILED",
+			Message: "Failed to delete avatar",
+			Details: err.Error(),
+		})
+		return
+	}
 	
 	// Return updated user profile
 	response := CreateProfileResponse{
@@ -291,6 +333,62 @@ func (h *UserHandler) UploadAvatar(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// UpdateRoleRequest represents the request body for promoting or demoting a user
+type UpdateRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// UpdateUserRole handles PUT /api/admin/users/:userId/role
+func (h *UserHandler) UpdateUserRole(c *gin.Context) {
+	var req UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_REQUEST",
+			Message: "Invalid request format",
+			Details: err.Error(),
+		})
+		return
+	}
+	
+	user, err := h.userService.UpdateRole(c, c.Param("userId"), models.UserRole(req.Role))
+	if err != nil {
+		if errors.Is(err, services.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Code:    "USER_NOT_FOUND",
+				Message: "User not found",
+			})
+			return
+		}
+		if errors.Is(err, services.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Code:    "VALIDATION_ERROR",
+				Message: "Invalid role change",
+				Details: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Code:    "UPDATE_FAILED",
+			Message: "Failed to update role",
+			Details: err.Error(),
+		})
+		return
+	}
+	
+	response := CreateProfileResponse{
+		ID:          user.ID,
+		DisplayName: user.DisplayName,
+		AccountType: string(user.AccountType),
+		Role:        string(user.Role),
+		IsActive:    user.IsActive,
+		CreatedAt:   user.CreatedAt.Format(time.RFC3339),
+		AvatarURL:   stringPtrToString(user.AvatarURL),
+		AboutMe:     user.AboutMe,
+	}
+	
+	c.JSON(http.StatusOK, response)
+}
+
 // GetProfile handles GET /api/users/profile
 func (h *UserHandler) GetProfile(c *gin.Context) {
 	// Get user ID from header (session-based user identification)
@@ -308,7 +406,7 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 	// Get user from service
 	user, err := h.userService.GetUser(c.Request.Context(), userID)
 	if err != nil {
-		if err.Error() == "user not found" {
+		if errors.Is(err, services.ErrNotFound) {
 			c.JSON(http.StatusNotFound, ErrorResponse{
 				Code:    "USER_NOT_FOUND",
 				Message: "User not found",
@@ -357,12 +455,6 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 		return
 	}
 	
-	// Check rate limit
-	if err := h.rateLimiter.CheckRateLimit(c, userID, services.ActionUpdateProfile); err != nil {
-		h.handleRateLimitError(c, err)
-		return
-	}
-	
 	// Parse request body
 	var req UpdateProfileRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -401,9 +493,6 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 		return
 	}
 	
-	// Add rate limit headers
-	h.addRateLimitHeaders(c, userID, services.ActionUpdateProfile)
-	
 	// Return updated profile
 	response := UpdateProfileResponse{
 		ID:          user.ID,
@@ -461,41 +550,6 @@ func (h *UserHandler) validateUpdateProfileRequest(req UpdateProfileRequest) err
 	return nil
 }
 
-// handleRateLimitError handles rate limit errors
-func (h *UserHandler) handleRateLimitError(c *gin.Context, err error) {
-	if _, ok := err.(*services.RateLimitError); ok {
-		// Set Retry-After header
-		c.Header("Retry-After", "3600") // Use fixed value for simplicity
-		
-		c.JSON(http.StatusTooManyRequests, ErrorResponse{
-			Code:    "RATE_LIMIT_EXCEEDED",
-			Message: "Rate limit exceeded",
-			Details: err.Error(),
-		})
-		return
-	}
-	
-	// Generic rate limit error
-	c.JSON(http.StatusTooManyRequests, ErrorResponse{
-		Code:    "RATE_LIMIT_EXCEEDED",
-		Message: "Rate limit exceeded",
-		Details: err.Error(),
-	})
-}
-
-// addRateLimitHeaders adds rate limit headers to the response
-func (h *UserHandler) addRateLimitHeaders(c *gin.Context, userID string, action services.ActionType) {
-	headers, err := h.rateLimiter.GetRateLimitHeaders(c, userID, action)
-	if err != nil {
-		// Log error but don't fail the request
-		return
-	}
-	
-	for key, value := range headers {
-		c.Header(key, value)
-	}
-}
-
 // stringPtrToString converts a string pointer to string, returning empty string if nil
 func stringPtrToString(ptr *string) string {
 	if ptr == nil {
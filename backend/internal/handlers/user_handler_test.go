@@ -51,6 +51,7 @@ func NewUserTestScenario(t *testing.T) *UserTestScenario {
 	gin.SetMode(gin.TestMode)
 	scenario.router = gin.New()
 	scenario.handler.RegisterRoutes(scenario.router)
+	scenario.handler.RegisterAdminRoutes(scenario.router)
 	
 	return scenario
 }
@@ -78,7 +79,7 @@ func (s *UserTestScenario) ExpectRateLimitExceeded() *UserTestScenario {
 		UserID:     s.userID,
 		Action:     services.ActionCreatePOI,
 		Limit:      5,
-		RetryAfter: 3600,
+		RetryAfter: time.Hour,
 	}
 	s.mockRateLimiter.On("CheckRateLimit", mock.Anything, mock.Anything, services.ActionCreatePOI).Return(rateLimitErr)
 	
@@ -306,7 +307,8 @@ func TestCreateGuestProfile_ValidationError(t *testing.T) {
 	scenario := NewUserTestScenario(t)
 	defer scenario.Cleanup(t)
 
-	// Validation happens before rate limiting and service calls, so no expectations needed
+	// Rate limiting runs before validation, so the attempt still counts
+	scenario.ExpectRateLimitSuccess()
 
 	// Execute request expecting validation error
 	recorder := scenario.CreateGuestProfileExpectingError(t, "AB", 400)
@@ -409,6 +411,7 @@ func TestUploadAvatar_InvalidFileType(t *testing.T) {
 
 	// Rate limiting happens before file validation, but rate limit headers are not added on validation errors
 	scenario.mockRateLimiter.On("CheckRateLimit", mock.Anything, mock.Anything, services.ActionCreatePOI).Return(nil)
+	scenario.mockRateLimiter.On("GetRateLimitHeaders", mock.Anything, mock.Anything, services.ActionCreatePOI).Return(map[string]string{}, nil)
 
 	// Execute request expecting validation error
 	recorder := scenario.UploadAvatarExpectingError(t, userID, filename, fileData, "text/plain", 400)
@@ -431,6 +434,7 @@ func TestUploadAvatar_FileTooLarge(t *testing.T) {
 
 	// Rate limiting happens before file validation, but rate limit headers are not added on validation errors
 	scenario.mockRateLimiter.On("CheckRateLimit", mock.Anything, mock.Anything, services.ActionCreatePOI).Return(nil)
+	scenario.mockRateLimiter.On("GetRateLimitHeaders", mock.Anything, mock.Anything, services.ActionCreatePOI).Return(map[string]string{}, nil)
 
 	// Execute request expecting validation error
 	recorder := scenario.UploadAvatarExpectingError(t, userID, filename, fileData, "image/jpeg", 400)
@@ -470,6 +474,63 @@ func TestUploadAvatar_RateLimited(t *testing.T) {
 // Note: CreateProfileRequest and CreateProfileResponse are defined in user_handler.go
 
 // Helper function to check if a string contains a substring
+// DeleteAvatar executes an avatar deletion request and returns the response
+func (s *UserTestScenario) DeleteAvatar(t *testing.T, userID string) *httptest.ResponseRecorder {
+	s.mockRateLimiter.On("CheckRateLimit", mock.Anything, userID, services.ActionUpdateProfile).Return(nil)
+	s.mockRateLimiter.On("GetRateLimitHeaders", mock.Anything, userID, services.ActionUpdateProfile).Return(map[string]string{}, nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/users/me/avatar", nil)
+	req.Header.Set("X-User-ID", userID)
+	recorder := httptest.NewRecorder()
+	s.router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestDeleteAvatar_Success(t *testing.T) {
+	scenario := NewUserTestScenario(t)
+	defer scenario.Cleanup(t)
+
+	userID := uuid.New().String()
+	scenario.mockUserService.On("DeleteAvatar", mock.Anything, userID).Return(&models.User{
+		ID:          userID,
+		DisplayName: "Test User",
+		AccountType: models.AccountTypeGuest,
+		Role:        models.UserRoleUser,
+		IsActive:    true,
+		CreatedAt:   time.Now(),
+	}, nil)
+
+	recorder := scenario.DeleteAvatar(t, userID)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d. Response: %s",
+			http.StatusOK, recorder.Code, recorder.Body.String())
+	}
+
+	var response CreateProfileResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v. Body: %s", err, recorder.Body.String())
+	}
+	if response.AvatarURL != "" {
+		t.Errorf("Expected avatar URL to be cleared, got %s", response.AvatarURL)
+	}
+}
+
+func TestDeleteAvatar_UserNotFound(t *testing.T) {
+	scenario := NewUserTestScenario(t)
+	defer scenario.Cleanup(t)
+
+	userID := "non-existent-user"
+	scenario.mockUserService.On("DeleteAvatar", mock.Anything, userID).Return(nil, fmt.Errorf("user %w", services.ErrNotFound))
+
+	recorder := scenario.DeleteAvatar(t, userID)
+
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d. Response: %s",
+			http.StatusNotFound, recorder.Code, recorder.Body.String())
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || 
 		len(substr) == 0 || 
@@ -537,6 +598,22 @@ func (m *MockUserService) UploadAvatar(ctx context.Context, userID string, filen
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
+func (m *MockUserService) DeleteAvatar(ctx context.Context, userID string) (*models.User, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserService) UpdateRole(ctx context.Context, userID string, role models.UserRole) (*models.User, error) {
+	args := m.Called(ctx, userID, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
 func (m *MockUserService) UpdateProfile(ctx context.Context, userID string, req *services.UpdateProfileRequest) (*models.User, error) {
 	args := m.Called(ctx, userID, req)
 	if args.Get(0) == nil {
@@ -629,7 +706,7 @@ func TestGetProfile_UserNotFound(t *testing.T) {
 	userID := "non-existent-user"
 	
 	// Setup expectations for user not found
-	scenario.ExpectProfileRetrievalError(userID, errors.New("user not found"))
+	scenario.ExpectProfileRetrievalError(userID, services.ErrNotFound)
 	
 	// Execute request
 	response := scenario.GetProfile(t, userID)
@@ -674,4 +751,67 @@ func TestGetProfile_ServiceError(t *testing.T) {
 		t.Errorf("Expected status %d, got %d. Response: %s", 
 			http.StatusInternalServerError, response.Code, response.Body.String())
 	}
-}
\ No newline at end of file
+}
+// UpdateUserRole executes a role change request and returns the response
+func (s *UserTestScenario) UpdateUserRole(t *testing.T, userID, role string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(UpdateRoleRequest{Role: role})
+	req := httptest.NewRequest(http.MethodPut, "/api/admin/users/"+userID+"/role", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	s.router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestUpdateUserRole_Success(t *testing.T) {
+	scenario := NewUserTestScenario(t)
+	defer scenario.Cleanup(t)
+
+	userID := uuid.New().String()
+	scenario.mockUserService.On("UpdateRole", mock.Anything, userID, models.UserRoleAdmin).Return(&models.User{
+		ID:          userID,
+		DisplayName: "Facilitator",
+		AccountType: models.AccountTypeFull,
+		Role:        models.UserRoleAdmin,
+		IsActive:    true,
+		CreatedAt:   time.Now(),
+	}, nil)
+
+	recorder := scenario.UpdateUserRole(t, userID, "admin")
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", recorder.Code)
+	}
+	var response CreateProfileResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if response.Role != "admin" {
+		t.Errorf("Expected role admin, got %s", response.Role)
+	}
+}
+
+func TestUpdateUserRole_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"not found", fmt.Errorf("user %w", services.ErrNotFound), http.StatusNotFound},
+		{"invalid role", fmt.Errorf("%w: role must be user or admin", services.ErrInvalidInput), http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scenario := NewUserTestScenario(t)
+			defer scenario.Cleanup(t)
+
+			scenario.mockUserService.On("UpdateRole", mock.Anything, "user-1", models.UserRoleSuperAdmin).Return(nil, tt.err)
+
+			recorder := scenario.UpdateUserRole(t, "user-1", "superadmin")
+
+			if recorder.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, recorder.Code)
+			}
+		})
+	}
+}
@@ -236,6 +236,10 @@ func TestProfileUpdateFunctionality(t *testing.T) {
 		scenario := newProfileUpdateScenario(t)
 		defer scenario.cleanup(t)
 		
+		// Anonymous requests are counted per client IP
+		scenario.mockRateLimiter.On("CheckRateLimit", mock.Anything, "ip:192.0.2.1", services.ActionUpdateProfile).Return(nil)
+		scenario.mockRateLimiter.On("GetRateLimitHeaders", mock.Anything, "ip:192.0.2.1", services.ActionUpdateProfile).Return(map[string]string{}, nil)
+		
 		// Act - no X-User-ID header
 		updateData := map[string]interface{}{
 			"aboutMe": "Some update",
@@ -265,6 +269,7 @@ func TestProfileUpdateFunctionality(t *testing.T) {
 		
 		// Set up rate limiting expectations (validation happens after rate limiting)
 		scenario.mockRateLimiter.On("CheckRateLimit", mock.Anything, userID, services.ActionUpdateProfile).Return(nil)
+		scenario.mockRateLimiter.On("GetRateLimitHeaders", mock.Anything, userID, services.ActionUpdateProfile).Return(map[string]string{}, nil)
 		
 		// Act - try to update with too long AboutMe
 		longAboutMe := make([]byte, 1001) // Assuming 1000 char limit
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebSocketStatsProviderInterface defines the interface for reading the health of the WebSocket hub
+type WebSocketStatsProviderInterface interface {
+	WebSocketStats() models.WebSocketStats
+}
+
+// WebSocketStatsHandler handles the internal WebSocket stats endpoint
+type WebSocketStatsHandler struct {
+	stats WebSocketStatsProviderInterface
+}
+
+// NewWebSocketStatsHandler creates a new WebSocketStatsHandler
+func NewWebSocketStatsHandler(stats WebSocketStatsProviderInterface) *WebSocketStatsHandler {
+	return &WebSocketStatsHandler{
+		stats: stats,
+	}
+}
+
+// RegisterRoutes registers the WebSocket stats route
+// opsMiddleware should restrict access to operators, like the /metrics token
+func (h *WebSocketStatsHandler) RegisterRoutes(router *gin.Engine, opsMiddleware ...gin.HandlerFunc) {
+	internal := router.Group("/api/internal", opsMiddleware...)
+	{
+		internal.GET("/ws/stats", h.GetStats)
+	}
+}
+
+// GetStats handles GET /api/internal/ws/stats
+// The stats cover the connections of this instance only.
+func (h *WebSocketStatsHandler) GetStats(c *gin.Context) {
+	c.JSON(http.StatusOK, h.stats.WebSocketStats())
+}
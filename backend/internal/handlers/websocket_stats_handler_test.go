@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubWebSocketStatsProvider struct {
+	stats models.WebSocketStats
+}
+
+func (p *stubWebSocketStatsProvider) WebSocketStats() models.WebSocketStats {
+	return p.stats
+}
+
+func TestWebSocketStatsHandler_GetStats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	provider := &stubWebSocketStatsProvider{stats: models.WebSocketStats{
+		Connections:       3,
+		QueuedMessages:    7,
+		BroadcastsTotal:   120,
+		DroppedDeliveries: 2,
+		Maps: []models.MapWebSocketStats{
+			{MapID: "map-1", Connections: 3, QueuedMessages: 7, MaxQueuedMessages: 5},
+		},
+	}}
+	router := gin.New()
+	NewWebSocketStatsHandler(provider).RegisterRoutes(router)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/internal/ws/stats", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.WebSocketStats
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 3, response.Connections)
+	assert.Equal(t, uint64(120), response.BroadcastsTotal)
+	assert.Equal(t, uint64(2), response.DroppedDeliveries)
+	assert.Equal(t, 5, response.Maps[0].MaxQueuedMessages)
+}
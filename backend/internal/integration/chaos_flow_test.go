@@ -0,0 +1,61 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSessionCreationWithDatabaseFaults verifies session creation reports
+// database failures and recovers once the database is healthy again
+func TestSessionCreationWithDatabaseFaults(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping chaos integration test in short mode")
+	}
+
+	env := SetupFlowTest(t)
+
+	request := CreateSessionRequest{
+		UserID:         "user-chaos-db",
+		MapID:          "map-chaos-db",
+		AvatarPosition: LatLng{Lat: 40.7128, Lng: -74.0060},
+	}
+
+	// Database fails mid-flow
+	env.DBFaults().InjectError(nil, "create")
+	response := env.POST("/api/sessions", request)
+	env.AssertHTTPError(response, http.StatusInternalServerError)
+	assert.Positive(t, env.DBFaults().Triggered())
+
+	// Database recovers
+	env.DBFaults().Clear()
+	env.DBFaults().InjectLatency(100*time.Millisecond, "create")
+
+	start := time.Now()
+	response = env.POST("/api/sessions", request)
+	env.AssertHTTPSuccess(response)
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+}
+
+// TestSessionCreationWithRedisDisconnect verifies session creation degrades
+// gracefully when Redis presence tracking is unavailable
+func TestSessionCreationWithRedisDisconnect(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping chaos integration test in short mode")
+	}
+
+	env := SetupFlowTest(t)
+	env.RedisFaults().InjectDisconnect()
+
+	response := env.POST("/api/sessions", CreateSessionRequest{
+		UserID:         "user-chaos-redis",
+		MapID:          "map-chaos-redis",
+		AvatarPosition: LatLng{Lat: 40.7128, Lng: -74.0060},
+	})
+
+	// Presence is best effort, the session is still persisted
+	env.AssertHTTPSuccess(response)
+	assert.Positive(t, env.RedisFaults().Triggered())
+}
@@ -76,7 +76,7 @@ func TestCrossLayerErrorPropagation(t *testing.T) {
 			UserID: "user-redis-test",
 		}
 
-		joinResponse := env.POST("/api/pois/"+poiID+"/join", joinRequest)
+		joinResponse := env.POSTAs(joinRequest.UserID, "/api/pois/"+poiID+"/join", joinRequest)
 		// This should succeed even if Redis operations fail gracefully
 		env.AssertHTTPSuccess(joinResponse)
 	})
@@ -107,7 +107,7 @@ func TestCrossLayerErrorPropagation(t *testing.T) {
 			UserID: "user-no-ws",
 		}
 
-		joinResponse := env.POST("/api/pois/"+poiID+"/join", joinRequest)
+		joinResponse := env.POSTAs(joinRequest.UserID, "/api/pois/"+poiID+"/join", joinRequest)
 		env.AssertHTTPSuccess(joinResponse)
 
 		// Verify database and Redis state
@@ -188,7 +188,7 @@ func TestConcurrentOperationsAcrossLayers(t *testing.T) {
 						UserID: fmt.Sprintf("concurrent-user-%s-%d", pid, userIndex),
 					}
 
-					env.POST("/api/pois/"+pid+"/join", joinRequest)
+					env.POSTAs(joinRequest.UserID, "/api/pois/"+pid+"/join", joinRequest)
 				}(poiID, j)
 			}
 		}
@@ -264,6 +264,20 @@ func TestConcurrentOperationsAcrossLayers(t *testing.T) {
 		}
 		assert.GreaterOrEqual(t, successfulSessions, numSessions/2, "At least half of the sessions should be created successfully")
 
+		// Requests for the same user and map must resolve to a single active session
+		sessionsByUserMap := make(map[string]string)
+		for index, sessionID := range sessionIDs {
+			if sessionID == "" {
+				continue
+			}
+			key := fmt.Sprintf("test-user-%d/%t", (index%2)+1, index >= 2)
+			if existing, ok := sessionsByUserMap[key]; ok {
+				assert.Equal(t, existing, sessionID, "Concurrent creates for the same user and map should resume one session")
+			} else {
+				sessionsByUserMap[key] = sessionID
+			}
+		}
+
 		// Update avatar positions concurrently
 		for _, sessionID := range sessionIDs {
 			if sessionID == "" {
@@ -503,7 +517,7 @@ func TestPerformanceUnderLoad(t *testing.T) {
 						joinRequest := JoinPOIRequest{
 							UserID: fmt.Sprintf("load-user-%d-%d", workerID, j),
 						}
-						env.POST("/api/pois/"+poiResponse.ID+"/join", joinRequest)
+						env.POSTAs(joinRequest.UserID, "/api/pois/"+poiResponse.ID+"/join", joinRequest)
 					}
 				}
 			}(i)
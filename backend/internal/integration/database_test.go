@@ -13,13 +13,13 @@ import (
 )
 
 func TestMain(m *testing.M) {
-	// Skip integration tests if TEST_INTEGRATION is not set
-	if os.Getenv("TEST_INTEGRATION") == "" {
+	// Skip integration tests unless infrastructure is provided or containers are requested
+	if os.Getenv("TEST_INTEGRATION") == "" && !testdata.ContainersEnabled() {
 		os.Exit(0)
 	}
 	
-	// Run tests
-	code := m.Run()
+	// Run tests, starting Postgres and Redis containers when TEST_CONTAINERS is set
+	code := testdata.RunWithContainers(m)
 	os.Exit(code)
 }
 
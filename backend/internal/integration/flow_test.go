@@ -6,10 +6,12 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"breakoutglobe/internal/handlers"
+	"breakoutglobe/internal/middleware"
 	"breakoutglobe/internal/models"
 	"breakoutglobe/internal/redis"
 	"breakoutglobe/internal/repository"
@@ -38,6 +40,8 @@ type FlowTestEnvironment struct {
 	poiHandler     *handlers.POIHandler
 	sessionHandler *handlers.SessionHandler
 	wsHandler      *websocket.Handler
+	dbFaults       *testdata.FaultInjector
+	redisFaults    *testdata.FaultInjector
 }
 
 // SetupFlowTest creates a complete integration testing environment
@@ -57,6 +61,10 @@ func SetupFlowTest(t testing.TB) *FlowTestEnvironment {
 		t.Fatal("Failed to setup Redis for integration tests")
 	}
 
+	// Attach fault injectors; they are inactive until a test injects a fault
+	dbFaults := testDB.EnableChaos()
+	redisFaults := testRedis.EnableChaos()
+
 	// Setup WebSocket
 	testWS := testdata.SetupWebSocket(t)
 
@@ -91,14 +99,26 @@ func SetupFlowTest(t testing.TB) *FlowTestEnvironment {
 	// Create WebSocket handler
 	wsHandler := websocket.NewHandler(sessionService, rateLimiter, userService, poiService)
 
+	// Sessions created over REST are accepted by WebSocket upgrades
+	sessionHandler.SetSessionTokens(testdata.SessionTokens)
+	wsHandler.SetSessionTokens(testdata.SessionTokens)
+
 	// Setup Gin router
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 
+	// Stand-in for RequireIdentity resolving the caller's session
+	poiHandler.SetIdentityMiddleware(func(c *gin.Context) {
+		if userID := c.GetHeader("X-User-ID"); userID != "" {
+			c.Set("userID", userID)
+		}
+		c.Next()
+	})
+
 	// Register routes
 	poiHandler.RegisterRoutes(router)
 	sessionHandler.RegisterRoutes(router)
-	router.GET("/ws", wsHandler.HandleWebSocket)
+	wsHandler.RegisterRoutes(router, nil)
 
 	// Create test server
 	server := httptest.NewServer(router)
@@ -117,6 +137,8 @@ func SetupFlowTest(t testing.TB) *FlowTestEnvironment {
 		poiHandler:     poiHandler,
 		sessionHandler: sessionHandler,
 		wsHandler:      wsHandler,
+		dbFaults:       dbFaults,
+		redisFaults:    redisFaults,
 	}
 
 	// Register cleanup
@@ -135,6 +157,17 @@ func (env *FlowTestEnvironment) Cleanup() {
 	// Other cleanup is handled by individual component cleanup
 }
 
+// DBFaults returns the fault injector for the repository layer, used to inject
+// latency, errors or disconnections into database calls mid-test
+func (env *FlowTestEnvironment) DBFaults() *testdata.FaultInjector {
+	return env.dbFaults
+}
+
+// RedisFaults returns the fault injector for the Redis layer
+func (env *FlowTestEnvironment) RedisFaults() *testdata.FaultInjector {
+	return env.redisFaults
+}
+
 // CreatePOIRequest represents a POI creation request
 type CreatePOIRequest struct {
 	MapID           string  `json:"mapId"`
@@ -207,9 +240,22 @@ func (m *MockRateLimiter) GetRateLimitHeaders(ctx context.Context, userID string
 
 // POST makes a POST request to the test server
 func (env *FlowTestEnvironment) POST(path string, body interface{}) *httptest.ResponseRecorder {
+	jsonBody, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewBuffer(jsonBody))
+	setSessionToken(req)
+	req.Header.Set("Content-Type", "application/json")
+	
+	recorder := httptest.NewRecorder()
+	env.router.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// POSTAs makes a POST request acting as the user
+func (env *FlowTestEnvironment) POSTAs(userID, path string, body interface{}) *httptest.ResponseRecorder {
 	jsonBody, _ := json.Marshal(body)
 	req := httptest.NewRequest(http.MethodPost, path, bytes.NewBuffer(jsonBody))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", userID)
 	
 	recorder := httptest.NewRecorder()
 	env.router.ServeHTTP(recorder, req)
@@ -229,6 +275,7 @@ func (env *FlowTestEnvironment) GET(path string) *httptest.ResponseRecorder {
 func (env *FlowTestEnvironment) PUT(path string, body interface{}) *httptest.ResponseRecorder {
 	jsonBody, _ := json.Marshal(body)
 	req := httptest.NewRequest("PUT", path, bytes.NewBuffer(jsonBody))
+	setSessionToken(req)
 	req.Header.Set("Content-Type", "application/json")
 	
 	recorder := httptest.NewRecorder()
@@ -239,12 +286,22 @@ func (env *FlowTestEnvironment) PUT(path string, body interface{}) *httptest.Res
 // DELETE makes a DELETE request to the test server
 func (env *FlowTestEnvironment) DELETE(path string) *httptest.ResponseRecorder {
 	req := httptest.NewRequest(http.MethodDelete, path, nil)
+	setSessionToken(req)
 	
 	recorder := httptest.NewRecorder()
 	env.router.ServeHTTP(recorder, req)
 	return recorder
 }
 
+// setSessionToken sends the token of the session a session route acts on,
+// like the client that created the session does
+func setSessionToken(req *http.Request) {
+	if rest, ok := strings.CutPrefix(req.URL.Path, "/api/sessions/"); ok {
+		sessionID, _, _ := strings.Cut(rest, "/")
+		req.Header.Set(middleware.SessionTokenHeader, testdata.SessionTokens.Issue(sessionID))
+	}
+}
+
 // AssertHTTPSuccess asserts that an HTTP response is successful
 func (env *FlowTestEnvironment) AssertHTTPSuccess(recorder *httptest.ResponseRecorder) {
 	env.t.Helper()
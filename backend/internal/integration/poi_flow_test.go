@@ -184,7 +184,7 @@ func TestPOIJoinFlow(t *testing.T) {
 		UserID: "user-participant",
 	}
 
-	joinResponse := env.POST("/api/pois/"+poiID+"/join", joinRequest)
+	joinResponse := env.POSTAs(joinRequest.UserID, "/api/pois/"+poiID+"/join", joinRequest)
 	env.AssertHTTPSuccess(joinResponse)
 
 	// Step 4: Verify Redis State - User should be added to participants
@@ -268,7 +268,7 @@ func TestPOILeaveFlow(t *testing.T) {
 		UserID: "user-leaver",
 	}
 
-	joinResponse := env.POST("/api/pois/"+poiID+"/join", joinRequest)
+	joinResponse := env.POSTAs(joinRequest.UserID, "/api/pois/"+poiID+"/join", joinRequest)
 	env.AssertHTTPSuccess(joinResponse)
 
 	// Verify user is in participants
@@ -359,7 +359,7 @@ func TestPOICapacityEnforcement(t *testing.T) {
 			UserID: fmt.Sprintf("user-%d", i),
 		}
 
-		joinResponse := env.POST("/api/pois/"+poiID+"/join", joinRequest)
+		joinResponse := env.POSTAs(joinRequest.UserID, "/api/pois/"+poiID+"/join", joinRequest)
 		env.AssertHTTPSuccess(joinResponse)
 
 		// Verify user was added
@@ -374,7 +374,7 @@ func TestPOICapacityEnforcement(t *testing.T) {
 		UserID: "user-overflow",
 	}
 
-	overflowResponse := env.POST("/api/pois/"+poiID+"/join", overflowRequest)
+	overflowResponse := env.POSTAs(overflowRequest.UserID, "/api/pois/"+poiID+"/join", overflowRequest)
 	env.AssertHTTPError(overflowResponse, 400) // Should be rejected
 
 	// Verify user was not added
@@ -389,7 +389,7 @@ func TestPOICapacityEnforcement(t *testing.T) {
 	env.redis.AssertSetSize("poi:participants:"+poiID, 1)
 
 	// Now the overflow user should be able to join
-	retryResponse := env.POST("/api/pois/"+poiID+"/join", overflowRequest)
+	retryResponse := env.POSTAs(overflowRequest.UserID, "/api/pois/"+poiID+"/join", overflowRequest)
 	env.AssertHTTPSuccess(retryResponse)
 
 	// Verify user was added
@@ -432,7 +432,7 @@ func TestPOIDeletionFlow(t *testing.T) {
 		joinRequest := JoinPOIRequest{
 			UserID: fmt.Sprintf("participant-%d", i),
 		}
-		joinResponse := env.POST("/api/pois/"+poiID+"/join", joinRequest)
+		joinResponse := env.POSTAs(joinRequest.UserID, "/api/pois/"+poiID+"/join", joinRequest)
 		env.AssertHTTPSuccess(joinResponse)
 	}
 
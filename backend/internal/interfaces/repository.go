@@ -11,6 +11,7 @@ type UserRepositoryInterface interface {
 	Create(ctx context.Context, user *models.User) error
 	GetByID(ctx context.Context, id string) (*models.User, error)
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	ListByRole(ctx context.Context, role models.UserRole) ([]*models.User, error)
 	Update(ctx context.Context, user *models.User) error
 	ClearAllUsers(ctx context.Context) error
 }
\ No newline at end of file
@@ -0,0 +1,146 @@
+// Package logexport exports structured access and event logs to a configurable
+// sink and purges them after their category's retention period
+package logexport
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Log categories with their own retention
+const (
+	// CategoryAccess are HTTP requests
+	CategoryAccess = "access"
+	// CategoryEvents are WebSocket connects and disconnects
+	CategoryEvents = "events"
+	// CategoryMessages are WebSocket messages sampled by the map's message audit policy
+	CategoryMessages = "messages"
+	// CategoryConnectionErrors are recorded abnormal WebSocket closes, kept in the database
+	CategoryConnectionErrors = "connection_errors"
+)
+
+const (
+	// exportBufferSize bounds the entries waiting for the sink; more are dropped
+	exportBufferSize = 1024
+	// flushInterval is how often buffering sinks are flushed
+	flushInterval = time.Second
+)
+
+// Entry is one exported log record
+type Entry struct {
+	Time     time.Time              `json:"time"`
+	Category string                 `json:"category"`
+	Fields   map[string]interface{} `json:"fields"`
+}
+
+// Sink receives exported entries
+type Sink interface {
+	Write(entry Entry) error
+	Close() error
+}
+
+// Flusher is a sink that buffers entries
+type Flusher interface {
+	Flush() error
+}
+
+// Purger is a sink that stores entries and can delete them
+type Purger interface {
+	Purge(category string, before time.Time) (int, error)
+}
+
+// Exporter writes entries to a sink in the background, so logging never
+// blocks requests
+type Exporter struct {
+	sink    Sink
+	entries chan Entry
+	done    chan struct{}
+	once    sync.Once
+	logger  *slog.Logger
+}
+
+// NewExporter creates an exporter writing to the sink
+func NewExporter(sink Sink) *Exporter {
+	e := &Exporter{
+		sink:    sink,
+		entries: make(chan Entry, exportBufferSize),
+		done:    make(chan struct{}),
+		logger:  slog.Default(),
+	}
+	go e.run()
+	return e
+}
+
+// Export queues an entry. It is dropped if the sink can't keep up.
+func (e *Exporter) Export(category string, fields map[string]interface{}) {
+	select {
+	case e.entries <- Entry{Time: time.Now().UTC(), Category: category, Fields: fields}:
+	default:
+		e.logger.Warn("Log export buffer full, dropping entry", "category", category)
+	}
+}
+
+// Purge deletes entries older than their category's retention from sinks that
+// store them and returns how many files or records were deleted
+func (e *Exporter) Purge(ctx context.Context, retention Retention) (int, error) {
+	purger, ok := e.sink.(Purger)
+	if !ok {
+		return 0, nil
+	}
+
+	purged := 0
+	for _, category := range []string{CategoryAccess, CategoryEvents, CategoryMessages} {
+		if err := ctx.Err(); err != nil {
+			return purged, err
+		}
+		deleted, err := purger.Purge(category, retention.Cutoff(category, time.Now()))
+		purged += deleted
+		if err != nil {
+			return purged, err
+		}
+	}
+	return purged, nil
+}
+
+// Close writes the queued entries and closes the sink
+func (e *Exporter) Close() error {
+	e.once.Do(func() {
+		close(e.entries)
+	})
+	<-e.done
+	return e.sink.Close()
+}
+
+func (e *Exporter) run() {
+	defer close(e.done)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case entry, ok := <-e.entries:
+			if !ok {
+				e.flush()
+				return
+			}
+			if err := e.sink.Write(entry); err != nil {
+				e.logger.Warn("Failed to export log entry", "category", entry.Category, "error", err)
+			}
+		case <-ticker.C:
+			e.flush()
+		}
+	}
+}
+
+func (e *Exporter) flush() {
+	flusher, ok := e.sink.(Flusher)
+	if !ok {
+		return
+	}
+	if err := flusher.Flush(); err != nil {
+		e.logger.Warn("Failed to flush exported logs", "error", err)
+	}
+}
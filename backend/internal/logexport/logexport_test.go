@@ -0,0 +1,118 @@
+package logexport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRetention(t *testing.T) {
+	retention, err := ParseRetention([]string{"access:7d", "events:36h"})
+	require.NoError(t, err)
+	assert.Equal(t, 7*24*time.Hour, retention[CategoryAccess])
+	assert.Equal(t, 36*time.Hour, retention[CategoryEvents])
+	assert.Equal(t, 30*24*time.Hour, retention[CategoryConnectionErrors])
+
+	for _, value := range []string{"access", "unknown:7d", "access:0d", "access:soon"} {
+		_, err := ParseRetention([]string{value})
+		assert.Error(t, err, value)
+	}
+}
+
+func TestExporter_WritesJSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	exporter := NewExporter(NewWriterSink(&buf))
+
+	exporter.Export(CategoryAccess, map[string]interface{}{"path": "/api/maps", "status": 200})
+	require.NoError(t, exporter.Close())
+
+	var entry Entry
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, CategoryAccess, entry.Category)
+	assert.Equal(t, "/api/maps", entry.Fields["path"])
+}
+
+func TestFileSink_RotatesAndPurgesByCategory(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := NewFileSink(dir)
+	require.NoError(t, err)
+	defer sink.Close()
+
+	day := func(value string) time.Time {
+		date, err := time.Parse(fileDateLayout, value)
+		require.NoError(t, err)
+		return date.Add(12 * time.Hour)
+	}
+	require.NoError(t, sink.Write(Entry{Time: day("2024-03-01"), Category: CategoryAccess}))
+	require.NoError(t, sink.Write(Entry{Time: day("2024-03-02"), Category: CategoryAccess}))
+	require.NoError(t, sink.Write(Entry{Time: day("2024-03-01"), Category: CategoryEvents}))
+
+	names, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	require.NoError(t, err)
+	assert.Len(t, names, 3)
+
+	// Only whole days before the cutoff are deleted
+	deleted, err := sink.Purge(CategoryAccess, day("2024-03-02"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	_, err = os.Stat(filepath.Join(dir, "access-2024-03-01.jsonl"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(dir, "access-2024-03-02.jsonl"))
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(dir, "events-2024-03-01.jsonl"))
+	assert.NoError(t, err)
+}
+
+func TestExporter_PurgeUsesCategoryRetention(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := NewFileSink(dir)
+	require.NoError(t, err)
+	exporter := NewExporter(sink)
+	defer exporter.Close()
+
+	old := time.Now().UTC().AddDate(0, 0, -10)
+	require.NoError(t, sink.Write(Entry{Time: old, Category: CategoryAccess}))
+	require.NoError(t, sink.Write(Entry{Time: old, Category: CategoryEvents}))
+
+	retention, err := ParseRetention([]string{"access:7d"})
+	require.NoError(t, err)
+	purged, err := exporter.Purge(context.Background(), retention)
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+}
+
+func TestHTTPSink_PostsBatches(t *testing.T) {
+	var mutex sync.Mutex
+	var batches [][]Entry
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var batch []Entry
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&batch))
+		mutex.Lock()
+		batches = append(batches, batch)
+		mutex.Unlock()
+	}))
+	defer server.Close()
+
+	sink := NewHTTPSink(server.URL)
+	for i := 0; i < httpBatchSize+1; i++ {
+		require.NoError(t, sink.Write(Entry{Category: CategoryEvents}))
+	}
+	require.NoError(t, sink.Close())
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	require.Len(t, batches, 2)
+	assert.Len(t, batches[0], httpBatchSize)
+	assert.Len(t, batches[1], 1)
+}
@@ -0,0 +1,64 @@
+package logexport
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Retention is how long each log category is kept
+type Retention map[string]time.Duration
+
+// DefaultRetention keeps access logs and connection errors for 30 days,
+// connection events for 90 days and audited messages, which carry their full
+// payload, for 7 days
+func DefaultRetention() Retention {
+	return Retention{
+		CategoryAccess:           30 * 24 * time.Hour,
+		CategoryEvents:           90 * 24 * time.Hour,
+		CategoryMessages:         7 * 24 * time.Hour,
+		CategoryConnectionErrors: 30 * 24 * time.Hour,
+	}
+}
+
+// Cutoff returns the time before which entries of the category are purged
+func (r Retention) Cutoff(category string, now time.Time) time.Time {
+	return now.Add(-r[category])
+}
+
+// ParseRetention overrides the default retention with values formatted as
+// category:duration, where the duration is given in days ("90d") or as a Go
+// duration ("36h")
+func ParseRetention(values []string) (Retention, error) {
+	retention := DefaultRetention()
+
+	for _, value := range values {
+		category, durationText, ok := strings.Cut(value, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid log retention %q: expected category:duration", value)
+		}
+		if _, known := retention[category]; !known {
+			return nil, fmt.Errorf("invalid log retention %q: unknown category %q", value, category)
+		}
+
+		duration, err := parseRetentionDuration(durationText)
+		if err != nil || duration <= 0 {
+			return nil, fmt.Errorf("invalid log retention %q: duration must be positive, like 30d or 36h", value)
+		}
+		retention[category] = duration
+	}
+
+	return retention, nil
+}
+
+func parseRetentionDuration(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
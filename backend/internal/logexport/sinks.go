@@ -0,0 +1,214 @@
+package logexport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WriterSink writes entries as JSON lines, e.g. to stdout for a log shipper
+type WriterSink struct {
+	w     io.Writer
+	mutex sync.Mutex
+}
+
+// NewWriterSink creates a sink writing JSON lines to w
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+// Write writes an entry as one JSON line
+func (s *WriterSink) Write(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode log entry: %w", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, err = s.w.Write(append(line, '\n'))
+	return err
+}
+
+// Close does nothing; the writer is owned by the caller
+func (s *WriterSink) Close() error {
+	return nil
+}
+
+// fileDateLayout is the date in file names, one file per category and day
+const fileDateLayout = "2006-01-02"
+
+// FileSink writes entries as JSON lines to one file per category and day
+// (access-2024-03-01.jsonl), so expired days can be deleted as whole files
+type FileSink struct {
+	dir   string
+	files map[string]*os.File // category -> file of the current day
+	days  map[string]string   // category -> day of the open file
+	mutex sync.Mutex
+}
+
+// NewFileSink creates a sink writing to dir, creating it if needed
+func NewFileSink(dir string) (*FileSink, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create log export directory: %w", err)
+	}
+	return &FileSink{
+		dir:   dir,
+		files: make(map[string]*os.File),
+		days:  make(map[string]string),
+	}, nil
+}
+
+// Write appends an entry to the file of its category and day, rotating files at midnight UTC
+func (s *FileSink) Write(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode log entry: %w", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	file, err := s.file(entry.Category, entry.Time.UTC().Format(fileDateLayout))
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
+
+// file returns the open file of a category and day. Callers must hold the mutex.
+func (s *FileSink) file(category, day string) (*os.File, error) {
+	if file, ok := s.files[category]; ok && s.days[category] == day {
+		return file, nil
+	}
+
+	if file, ok := s.files[category]; ok {
+		file.Close()
+		delete(s.files, category)
+	}
+
+	file, err := os.OpenFile(filepath.Join(s.dir, category+"-"+day+".jsonl"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log export file: %w", err)
+	}
+	s.files[category] = file
+	s.days[category] = day
+	return file, nil
+}
+
+// Purge deletes the files of a category whose day ended before the cutoff
+func (s *FileSink) Purge(category string, before time.Time) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	names, err := filepath.Glob(filepath.Join(s.dir, category+"-*.jsonl"))
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, name := range names {
+		day := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(name), category+"-"), ".jsonl")
+		date, err := time.Parse(fileDateLayout, day)
+		if err != nil || !date.AddDate(0, 0, 1).Before(before) {
+			continue
+		}
+
+		if s.days[category] == day {
+			s.files[category].Close()
+			delete(s.files, category)
+			delete(s.days, category)
+		}
+		if err := os.Remove(name); err != nil {
+			return deleted, fmt.Errorf("failed to delete log export file: %w", err)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// Close closes the open files
+func (s *FileSink) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for category, file := range s.files {
+		file.Close()
+		delete(s.files, category)
+	}
+	return nil
+}
+
+// httpBatchSize is the number of entries sent per request to a collector
+const httpBatchSize = 100
+
+// HTTPSink posts batches of entries as a JSON array to a collector
+type HTTPSink struct {
+	url     string
+	client  *http.Client
+	pending []Entry
+	mutex   sync.Mutex
+}
+
+// NewHTTPSink creates a sink posting to the collector URL
+func NewHTTPSink(url string) *HTTPSink {
+	return &HTTPSink{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Write buffers an entry and sends the batch once it is full
+func (s *HTTPSink) Write(entry Entry) error {
+	s.mutex.Lock()
+	s.pending = append(s.pending, entry)
+	full := len(s.pending) >= httpBatchSize
+	s.mutex.Unlock()
+
+	if full {
+		return s.Flush()
+	}
+	return nil
+}
+
+// Flush sends the buffered entries. They are dropped if the collector
+// rejects them, so an unavailable collector can't exhaust memory.
+func (s *HTTPSink) Flush() error {
+	s.mutex.Lock()
+	batch := s.pending
+	s.pending = nil
+	s.mutex.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return fmt.Errorf("failed to encode log entries: %w", err)
+	}
+
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to send %d log entries: %w", len(batch), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("log collector rejected %d entries with status %d", len(batch), resp.StatusCode)
+	}
+	return nil
+}
+
+// Close sends the remaining entries
+func (s *HTTPSink) Close() error {
+	return s.Flush()
+}
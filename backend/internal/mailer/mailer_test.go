@@ -0,0 +1,31 @@
+package mailer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildMessage(t *testing.T) {
+	date := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	msg := Message{
+		To:      []string{"a@example.com", "b@example.com"},
+		Subject: "Daily digest\r\nBcc: evil@example.com",
+		Body:    "Line one\nLine two",
+	}
+
+	raw := string(buildMessage("digest@example.com", msg, date))
+
+	assert.Contains(t, raw, "From: digest@example.com\r\n")
+	assert.Contains(t, raw, "To: a@example.com, b@example.com\r\n")
+	assert.Contains(t, raw, "Subject: Daily digest  Bcc: evil@example.com\r\n")
+	assert.NotContains(t, raw, "\r\nBcc:")
+	assert.Contains(t, raw, "\r\n\r\nLine one\r\nLine two")
+}
+
+func TestSMTPMailer_RequiresRecipients(t *testing.T) {
+	mailer := NewSMTPMailer(SMTPConfig{Host: "localhost", Port: "25"})
+	assert.Error(t, mailer.Send(context.Background(), Message{Subject: "Hi"}))
+}
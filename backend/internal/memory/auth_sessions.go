@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"breakoutglobe/internal/models"
+)
+
+// AuthSessionRepository stores the sign-ins of full accounts in memory
+type AuthSessionRepository struct {
+	store *Store
+}
+
+// Create stores a new auth session
+func (r *AuthSessionRepository) Create(ctx context.Context, session *models.AuthSession) error {
+	r.store.mutex.Lock()
+	defer r.store.mutex.Unlock()
+
+	if _, exists := r.store.authSessions[session.ID]; exists {
+		return fmt.Errorf("failed to create auth session: %s already exists", session.ID)
+	}
+	r.store.authSessions[session.ID] = *session
+	return nil
+}
+
+// GetByID returns an auth session, or nil if it does not exist
+func (r *AuthSessionRepository) GetByID(ctx context.Context, id string) (*models.AuthSession, error) {
+	r.store.mutex.RLock()
+	defer r.store.mutex.RUnlock()
+
+	session, exists := r.store.authSessions[id]
+	if !exists {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+// ListByUser returns all auth sessions of a user created after since, newest first
+func (r *AuthSessionRepository) ListByUser(ctx context.Context, userID string, since time.Time) ([]*models.AuthSession, error) {
+	r.store.mutex.RLock()
+	defer r.store.mutex.RUnlock()
+
+	sessions := []*models.AuthSession{}
+	for _, session := range r.store.authSessions {
+		if session.UserID == userID && session.CreatedAt.After(since) {
+			session := session
+			sessions = append(sessions, &session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.After(sessions[j].CreatedAt)
+	})
+	return sessions, nil
+}
+
+// Touch updates when an auth session was last used
+func (r *AuthSessionRepository) Touch(ctx context.Context, id string, at time.Time) error {
+	r.store.mutex.Lock()
+	defer r.store.mutex.Unlock()
+
+	if session, exists := r.store.authSessions[id]; exists {
+		session.LastSeenAt = at
+		r.store.authSessions[id] = session
+	}
+	return nil
+}
+
+// Revoke revokes the active auth sessions of a user with the given IDs and
+// returns how many were revoked
+func (r *AuthSessionRepository) Revoke(ctx context.Context, userID string, ids []string, at time.Time) (int64, error) {
+	r.store.mutex.Lock()
+	defer r.store.mutex.Unlock()
+
+	var revoked int64
+	for _, id := range ids {
+		session, exists := r.store.authSessions[id]
+		if !exists || session.UserID != userID || session.RevokedAt != nil {
+			continue
+		}
+		revokedAt := at
+		session.RevokedAt = &revokedAt
+		r.store.authSessions[id] = session
+		revoked++
+	}
+	return revoked, nil
+}
+
+// DeleteExpiredBefore deletes auth sessions that expired before the given time
+func (r *AuthSessionRepository) DeleteExpiredBefore(ctx context.Context, before time.Time) (int64, error) {
+	r.store.mutex.Lock()
+	defer r.store.mutex.Unlock()
+
+	var deleted int64
+	for id, session := range r.store.authSessions {
+		if session.ExpiresAt.Before(before) {
+			delete(r.store.authSessions, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"breakoutglobe/internal/models"
+)
+
+// MapBans keeps the users banned from each map in memory
+type MapBans struct {
+	mutex sync.Mutex
+	bans  map[string]map[string]models.MapBan // mapID -> userID -> ban
+}
+
+// NewMapBans creates an empty map ban store
+func NewMapBans() *MapBans {
+	return &MapBans{
+		bans: make(map[string]map[string]models.MapBan),
+	}
+}
+
+// AddMapBan bans a user from a map until the ban's end, replacing an earlier
+// ban of the user
+func (mb *MapBans) AddMapBan(ctx context.Context, ban models.MapBan) error {
+	if !ban.Active(time.Now()) {
+		return nil
+	}
+
+	mb.mutex.Lock()
+	defer mb.mutex.Unlock()
+
+	if mb.bans[ban.MapID] == nil {
+		mb.bans[ban.MapID] = make(map[string]models.MapBan)
+	}
+	mb.bans[ban.MapID][ban.UserID] = ban
+	return nil
+}
+
+// GetMapBan returns the ban of a user from a map, or nil if they aren't
+// banned. Expired bans are dropped.
+func (mb *MapBans) GetMapBan(ctx context.Context, mapID, userID string) (*models.MapBan, error) {
+	mb.mutex.Lock()
+	defer mb.mutex.Unlock()
+
+	ban, exists := mb.bans[mapID][userID]
+	if !exists {
+		return nil, nil
+	}
+	if !ban.Active(time.Now()) {
+		delete(mb.bans[mapID], userID)
+		if len(mb.bans[mapID]) == 0 {
+			delete(mb.bans, mapID)
+		}
+		return nil, nil
+	}
+	return &ban, nil
+}
@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"breakoutglobe/internal/models"
+)
+
+// HandQueue keeps the raise-hand queue of each map in memory
+type HandQueue struct {
+	mutex sync.RWMutex
+	hands map[string][]models.RaisedHand // mapID -> raised hands, the earliest first
+}
+
+// NewHandQueue creates an empty hand queue store
+func NewHandQueue() *HandQueue {
+	return &HandQueue{
+		hands: make(map[string][]models.RaisedHand),
+	}
+}
+
+// RaiseHand adds a user to the end of a map's queue. It returns false if the
+// user's hand was already raised, keeping their place.
+func (hq *HandQueue) RaiseHand(ctx context.Context, mapID, userID string, at time.Time) (bool, error) {
+	hq.mutex.Lock()
+	defer hq.mutex.Unlock()
+
+	for _, hand := range hq.hands[mapID] {
+		if hand.UserID == userID {
+			return false, nil
+		}
+	}
+	hq.hands[mapID] = append(hq.hands[mapID], models.RaisedHand{UserID: userID, RaisedAt: at})
+	return true, nil
+}
+
+// LowerHand removes a user from a map's queue. It returns false if the user's
+// hand wasn't raised.
+func (hq *HandQueue) LowerHand(ctx context.Context, mapID, userID string) (bool, error) {
+	hq.mutex.Lock()
+	defer hq.mutex.Unlock()
+
+	hands := hq.hands[mapID]
+	for i, hand := range hands {
+		if hand.UserID != userID {
+			continue
+		}
+		hands = append(hands[:i:i], hands[i+1:]...)
+		if len(hands) == 0 {
+			delete(hq.hands, mapID)
+		} else {
+			hq.hands[mapID] = hands
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// GetHands returns the raised hands of a map, the earliest first
+func (hq *HandQueue) GetHands(ctx context.Context, mapID string) ([]models.RaisedHand, error) {
+	hq.mutex.RLock()
+	defer hq.mutex.RUnlock()
+
+	hands := make([]models.RaisedHand, len(hq.hands[mapID]))
+	copy(hands, hq.hands[mapID])
+	return hands, nil
+}
@@ -0,0 +1,255 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// MapRepository stores maps in memory
+type MapRepository struct {
+	store *Store
+}
+
+// GetByID retrieves a map by ID
+func (r *MapRepository) GetByID(ctx context.Context, id string) (*models.Map, error) {
+	r.store.mutex.RLock()
+	defer r.store.mutex.RUnlock()
+
+	m, exists := r.store.maps[id]
+	if !exists {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &m, nil
+}
+
+// UpdateSpawnPoints replaces the spawn points of a map
+func (r *MapRepository) UpdateSpawnPoints(ctx context.Context, id string, points []models.SpawnPoint) error {
+	return r.update(id, func(m *models.Map) {
+		m.SpawnPoints = points
+	})
+}
+
+// UpdatePersonalSpace replaces the personal space setting of a map
+func (r *MapRepository) UpdatePersonalSpace(ctx context.Context, id string, personalSpace models.PersonalSpace) error {
+	return r.update(id, func(m *models.Map) {
+		m.PersonalSpace = personalSpace
+	})
+}
+
+// UpdatePositionPrivacy replaces the position privacy setting of a map
+func (r *MapRepository) UpdatePositionPrivacy(ctx context.Context, id string, privacy models.PositionPrivacy) error {
+	return r.update(id, func(m *models.Map) {
+		m.PositionPrivacy = privacy
+	})
+}
+
+// UpdateGuestAnonymization replaces the guest anonymization setting of a map
+func (r *MapRepository) UpdateGuestAnonymization(ctx context.Context, id string, anonymization models.GuestAnonymization) error {
+	return r.update(id, func(m *models.Map) {
+		m.GuestAnonymization = anonymization
+	})
+}
+
+// UpdateSlowConsumerPolicy replaces the slow consumer policy of a map
+func (r *MapRepository) UpdateSlowConsumerPolicy(ctx context.Context, id string, policy models.SlowConsumerPolicy) error {
+	return r.update(id, func(m *models.Map) {
+		m.SlowConsumer = policy
+	})
+}
+
+// UpdateMessageAuditPolicy replaces the message audit policy of a map
+func (r *MapRepository) UpdateMessageAuditPolicy(ctx context.Context, id string, policy models.MessageAuditPolicy) error {
+	return r.update(id, func(m *models.Map) {
+		m.MessageAudit = policy
+	})
+}
+
+// UpdateProfilePrivacy replaces the profile privacy setting of a map
+func (r *MapRepository) UpdateProfilePrivacy(ctx context.Context, id string, privacy models.ProfilePrivacy) error {
+	return r.update(id, func(m *models.Map) {
+		m.ProfilePrivacy = privacy
+	})
+}
+
+// UpdateLocale replaces the locale of a map's server-generated content
+func (r *MapRepository) UpdateLocale(ctx context.Context, id string, locale string) error {
+	return r.update(id, func(m *models.Map) {
+		m.Locale = locale
+	})
+}
+
+// UpdateListing replaces the directory listing of a map
+func (r *MapRepository) UpdateListing(ctx context.Context, id string, listing models.MapListing) error {
+	return r.update(id, func(m *models.Map) {
+		m.Discoverable = listing.Discoverable
+		m.Tags = listing.Tags
+	})
+}
+
+// UpdateOwner transfers a map to another user and drops its pending deletion
+// request, which the previous owner can't confirm anymore
+func (r *MapRepository) UpdateOwner(ctx context.Context, id string, ownerID string) error {
+	return r.update(id, func(m *models.Map) {
+		m.CreatedBy = ownerID
+		m.Deletion = models.MapDeletionRequest{}
+	})
+}
+
+// UpdateDeletionRequest replaces the pending deletion request of a map. An
+// empty request cancels it.
+func (r *MapRepository) UpdateDeletionRequest(ctx context.Context, id string, request models.MapDeletionRequest) error {
+	return r.update(id, func(m *models.Map) {
+		m.Deletion = request
+	})
+}
+
+// DeleteWithPOIs deletes a map together with its POIs and sessions, and
+// stores its snapshot
+func (r *MapRepository) DeleteWithPOIs(ctx context.Context, id string, snapshot *models.MapSnapshot) error {
+	r.store.mutex.Lock()
+	defer r.store.mutex.Unlock()
+
+	if _, exists := r.store.maps[id]; !exists {
+		return gorm.ErrRecordNotFound
+	}
+	r.store.mapSnapshots[snapshot.ID] = *snapshot
+	for poiID, poi := range r.store.pois {
+		if poi.MapID == id {
+			delete(r.store.pois, poiID)
+		}
+	}
+	for sessionID, session := range r.store.sessions {
+		if session.MapID == id {
+			delete(r.store.sessions, sessionID)
+		}
+	}
+	delete(r.store.maps, id)
+	return nil
+}
+
+// GetSnapshot retrieves the snapshot of a deleted map by ID
+func (r *MapRepository) GetSnapshot(ctx context.Context, id string) (*models.MapSnapshot, error) {
+	r.store.mutex.RLock()
+	defer r.store.mutex.RUnlock()
+
+	snapshot, exists := r.store.mapSnapshots[id]
+	if !exists {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &snapshot, nil
+}
+
+// MarkSnapshotRestored records the map a snapshot was restored as. It returns
+// gorm.ErrRecordNotFound if the snapshot doesn't exist or was already restored.
+func (r *MapRepository) MarkSnapshotRestored(ctx context.Context, id string, mapID string) error {
+	r.store.mutex.Lock()
+	defer r.store.mutex.Unlock()
+
+	snapshot, exists := r.store.mapSnapshots[id]
+	if !exists || snapshot.RestoredMapID != "" {
+		return gorm.ErrRecordNotFound
+	}
+	snapshot.RestoredMapID = mapID
+	r.store.mapSnapshots[id] = snapshot
+	return nil
+}
+
+// CreateWithPOIs stores a map together with its POIs. Nothing is stored if
+// the map or one of the POIs already exists.
+func (r *MapRepository) CreateWithPOIs(ctx context.Context, m *models.Map, pois []*models.POI) error {
+	r.store.mutex.Lock()
+	defer r.store.mutex.Unlock()
+
+	if _, exists := r.store.maps[m.ID]; exists {
+		return fmt.Errorf("failed to create map: map %s already exists", m.ID)
+	}
+	for _, poi := range pois {
+		if _, exists := r.store.pois[poi.ID]; exists {
+			return fmt.Errorf("failed to create POIs: POI %s already exists", poi.ID)
+		}
+	}
+
+	now := time.Now()
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = now
+	}
+	m.UpdatedAt = now
+	m.IsActive = true
+	r.store.maps[m.ID] = *m
+	for _, poi := range pois {
+		if poi.CreatedAt.IsZero() {
+			poi.CreatedAt = now
+		}
+		poi.UpdatedAt = now
+		r.store.pois[poi.ID] = *poi
+	}
+	return nil
+}
+
+// ListDiscoverable lists the active maps listed in the public directory, newest
+// first. query matches name or description case-insensitively, and a map must
+// carry all tags.
+func (r *MapRepository) ListDiscoverable(ctx context.Context, query string, tags []string) ([]*models.Map, error) {
+	query = strings.ToLower(query)
+
+	r.store.mutex.RLock()
+	defer r.store.mutex.RUnlock()
+
+	maps := []*models.Map{}
+	for _, m := range r.store.maps {
+		if !m.Discoverable || !m.IsActive {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(m.Name), query) && !strings.Contains(strings.ToLower(m.Description), query) {
+			continue
+		}
+		if !hasAllTags(m.Tags, tags) {
+			continue
+		}
+		m := m
+		maps = append(maps, &m)
+	}
+	sort.Slice(maps, func(i, j int) bool {
+		return maps[i].CreatedAt.After(maps[j].CreatedAt)
+	})
+	return maps, nil
+}
+
+// update applies change to a stored map
+func (r *MapRepository) update(id string, change func(m *models.Map)) error {
+	r.store.mutex.Lock()
+	defer r.store.mutex.Unlock()
+
+	m, exists := r.store.maps[id]
+	if !exists {
+		return gorm.ErrRecordNotFound
+	}
+	change(&m)
+	m.UpdatedAt = time.Now()
+	r.store.maps[id] = m
+	return nil
+}
+
+// hasAllTags reports whether tags contains every wanted tag
+func hasAllTags(tags, wanted []string) bool {
+	for _, tag := range wanted {
+		found := false
+		for _, candidate := range tags {
+			if candidate == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"breakoutglobe/internal/models"
+)
+
+// OnboardingRepository stores the welcome checklist steps users completed in memory
+type OnboardingRepository struct {
+	store *Store
+}
+
+// ListByUser returns the steps a user completed
+func (r *OnboardingRepository) ListByUser(ctx context.Context, userID string) ([]*models.UserOnboardingStep, error) {
+	r.store.mutex.RLock()
+	defer r.store.mutex.RUnlock()
+
+	steps := []*models.UserOnboardingStep{}
+	for _, step := range r.store.onboarding[userID] {
+		step := step
+		steps = append(steps, &step)
+	}
+	sort.Slice(steps, func(i, j int) bool {
+		return steps[i].CompletedAt.Before(steps[j].CompletedAt)
+	})
+	return steps, nil
+}
+
+// Complete records a completed step. It returns false if the user had already
+// completed it, keeping the original completion time.
+func (r *OnboardingRepository) Complete(ctx context.Context, step *models.UserOnboardingStep) (bool, error) {
+	r.store.mutex.Lock()
+	defer r.store.mutex.Unlock()
+
+	if r.store.onboarding[step.UserID] == nil {
+		r.store.onboarding[step.UserID] = make(map[models.OnboardingStep]models.UserOnboardingStep)
+	}
+	if _, exists := r.store.onboarding[step.UserID][step.Step]; exists {
+		return false, nil
+	}
+	r.store.onboarding[step.UserID][step.Step] = *step
+	return true, nil
+}
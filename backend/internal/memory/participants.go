@@ -0,0 +1,221 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"breakoutglobe/internal/redis"
+)
+
+// POIParticipants tracks who is in which POI in memory
+type POIParticipants struct {
+	mutex        sync.RWMutex
+	participants map[string]map[string]time.Time // poiID -> sessionID -> joined at
+	rosterSeqs   map[string]int64
+	recentJoins  map[string]map[string]time.Time // poiID -> userID -> last joined at
+}
+
+// NewPOIParticipants creates an empty POI participants store
+func NewPOIParticipants() *POIParticipants {
+	return &POIParticipants{
+		participants: make(map[string]map[string]time.Time),
+		rosterSeqs:   make(map[string]int64),
+		recentJoins:  make(map[string]map[string]time.Time),
+	}
+}
+
+// JoinPOI adds a session to a POI's participants
+func (pp *POIParticipants) JoinPOI(ctx context.Context, poiID, sessionID string) error {
+	pp.mutex.Lock()
+	defer pp.mutex.Unlock()
+
+	pp.add(poiID, sessionID)
+	return nil
+}
+
+// LeavePOI removes a session from a POI's participants
+func (pp *POIParticipants) LeavePOI(ctx context.Context, poiID, sessionID string) error {
+	pp.mutex.Lock()
+	defer pp.mutex.Unlock()
+
+	delete(pp.participants[poiID], sessionID)
+	if len(pp.participants[poiID]) == 0 {
+		delete(pp.participants, poiID)
+	}
+	return nil
+}
+
+// GetParticipantCount returns the number of participants in a POI
+func (pp *POIParticipants) GetParticipantCount(ctx context.Context, poiID string) (int, error) {
+	pp.mutex.RLock()
+	defer pp.mutex.RUnlock()
+
+	return len(pp.participants[poiID]), nil
+}
+
+// GetParticipants returns all participant session IDs for a POI
+func (pp *POIParticipants) GetParticipants(ctx context.Context, poiID string) ([]string, error) {
+	pp.mutex.RLock()
+	defer pp.mutex.RUnlock()
+
+	participants := make([]string, 0, len(pp.participants[poiID]))
+	for sessionID := range pp.participants[poiID] {
+		participants = append(participants, sessionID)
+	}
+	return participants, nil
+}
+
+// IsParticipant checks if a session is a participant in a POI
+func (pp *POIParticipants) IsParticipant(ctx context.Context, poiID, sessionID string) (bool, error) {
+	pp.mutex.RLock()
+	defer pp.mutex.RUnlock()
+
+	_, ok := pp.participants[poiID][sessionID]
+	return ok, nil
+}
+
+// CanJoinPOI checks if a POI has capacity for another participant
+func (pp *POIParticipants) CanJoinPOI(ctx context.Context, poiID string, maxParticipants int) (bool, error) {
+	pp.mutex.RLock()
+	defer pp.mutex.RUnlock()
+
+	return len(pp.participants[poiID]) < maxParticipants, nil
+}
+
+// JoinPOIWithCapacityCheck adds a session to a POI only if there's capacity.
+// Sessions already in the POI stay in it.
+func (pp *POIParticipants) JoinPOIWithCapacityCheck(ctx context.Context, poiID, sessionID string, maxParticipants int) error {
+	pp.mutex.Lock()
+	defer pp.mutex.Unlock()
+
+	if _, ok := pp.participants[poiID][sessionID]; ok {
+		return nil
+	}
+	if len(pp.participants[poiID]) >= maxParticipants {
+		return fmt.Errorf("%w (max: %d participants)", redis.ErrPOIAtCapacity, maxParticipants)
+	}
+	pp.add(poiID, sessionID)
+	return nil
+}
+
+// RemoveAllParticipants removes all participants from a POI
+func (pp *POIParticipants) RemoveAllParticipants(ctx context.Context, poiID string) error {
+	pp.mutex.Lock()
+	defer pp.mutex.Unlock()
+
+	delete(pp.participants, poiID)
+	return nil
+}
+
+// RemoveParticipantFromAllPOIs removes a session from all POIs it's in
+func (pp *POIParticipants) RemoveParticipantFromAllPOIs(ctx context.Context, sessionID string) error {
+	pp.mutex.Lock()
+	defer pp.mutex.Unlock()
+
+	for poiID, participants := range pp.participants {
+		delete(participants, sessionID)
+		if len(participants) == 0 {
+			delete(pp.participants, poiID)
+		}
+	}
+	return nil
+}
+
+// GetPOIsForParticipant returns all POI IDs that a session is in
+func (pp *POIParticipants) GetPOIsForParticipant(ctx context.Context, sessionID string) ([]string, error) {
+	pp.mutex.RLock()
+	defer pp.mutex.RUnlock()
+
+	poiIDs := []string{}
+	for poiID, participants := range pp.participants {
+		if _, ok := participants[sessionID]; ok {
+			poiIDs = append(poiIDs, poiID)
+		}
+	}
+	return poiIDs, nil
+}
+
+// GetJoinTimes returns when each participant of a POI joined it
+func (pp *POIParticipants) GetJoinTimes(ctx context.Context, poiID string) (map[string]time.Time, error) {
+	pp.mutex.RLock()
+	defer pp.mutex.RUnlock()
+
+	joinTimes := make(map[string]time.Time, len(pp.participants[poiID]))
+	for sessionID, joinedAt := range pp.participants[poiID] {
+		joinTimes[sessionID] = joinedAt
+	}
+	return joinTimes, nil
+}
+
+// RecordJoin records when a user last joined a POI, so users joining again
+// are counted once, and forgets joins older than the window
+func (pp *POIParticipants) RecordJoin(ctx context.Context, poiID, userID string, at time.Time, window time.Duration) error {
+	pp.mutex.Lock()
+	defer pp.mutex.Unlock()
+
+	cutoff := at.Add(-window)
+	for id, joins := range pp.recentJoins {
+		for joinedBy, joinedAt := range joins {
+			if joinedAt.Before(cutoff) {
+				delete(joins, joinedBy)
+			}
+		}
+		if len(joins) == 0 {
+			delete(pp.recentJoins, id)
+		}
+	}
+
+	if pp.recentJoins[poiID] == nil {
+		pp.recentJoins[poiID] = make(map[string]time.Time)
+	}
+	pp.recentJoins[poiID][userID] = at
+	return nil
+}
+
+// CountJoinsSince counts the users who last joined each POI at or after since
+func (pp *POIParticipants) CountJoinsSince(ctx context.Context, poiIDs []string, since time.Time) (map[string]int, error) {
+	pp.mutex.RLock()
+	defer pp.mutex.RUnlock()
+
+	joins := make(map[string]int, len(poiIDs))
+	for _, poiID := range poiIDs {
+		for _, joinedAt := range pp.recentJoins[poiID] {
+			if !joinedAt.Before(since) {
+				joins[poiID]++
+			}
+		}
+	}
+	return joins, nil
+}
+
+// NextRosterSequence increments and returns the roster sequence number of a
+// POI. Every change to the participants gets the next number.
+func (pp *POIParticipants) NextRosterSequence(ctx context.Context, poiID string) (int64, error) {
+	pp.mutex.Lock()
+	defer pp.mutex.Unlock()
+
+	pp.rosterSeqs[poiID]++
+	return pp.rosterSeqs[poiID], nil
+}
+
+// GetRosterSequence returns the current roster sequence number of a POI, or 0
+// if its roster has never changed
+func (pp *POIParticipants) GetRosterSequence(ctx context.Context, poiID string) (int64, error) {
+	pp.mutex.RLock()
+	defer pp.mutex.RUnlock()
+
+	return pp.rosterSeqs[poiID], nil
+}
+
+// add adds a session to a POI, keeping the join time of sessions already in
+// it. The caller must hold the mutex.
+func (pp *POIParticipants) add(poiID, sessionID string) {
+	if pp.participants[poiID] == nil {
+		pp.participants[poiID] = make(map[string]time.Time)
+	}
+	if _, ok := pp.participants[poiID][sessionID]; !ok {
+		pp.participants[poiID][sessionID] = time.Now()
+	}
+}
@@ -0,0 +1,138 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// minPOIDistanceKm is how close POIs of a map may be to each other
+const minPOIDistanceKm = 0.1
+
+// POIRepository stores POIs in memory
+type POIRepository struct {
+	store *Store
+}
+
+// Create stores a new POI unless another POI of the map is within 100 meters
+func (r *POIRepository) Create(ctx context.Context, poi *models.POI) error {
+	if poi.ID == "" {
+		poi.ID = uuid.New().String()
+	}
+	now := time.Now()
+	if poi.CreatedAt.IsZero() {
+		poi.CreatedAt = now
+	}
+	if poi.UpdatedAt.IsZero() {
+		poi.UpdatedAt = now
+	}
+	if poi.MaxParticipants == 0 {
+		poi.MaxParticipants = 10
+	}
+
+	if err := poi.Validate(); err != nil {
+		return fmt.Errorf("POI validation failed: %w", err)
+	}
+
+	r.store.mutex.Lock()
+	defer r.store.mutex.Unlock()
+
+	for _, existing := range r.store.pois {
+		if existing.MapID == poi.MapID && existing.DistanceTo(poi.Position) < minPOIDistanceKm {
+			return fmt.Errorf("POI too close to existing POI '%s' (minimum distance: %.0fm)",
+				existing.Name, minPOIDistanceKm*1000)
+		}
+	}
+
+	r.store.pois[poi.ID] = *poi
+	return nil
+}
+
+// GetByID retrieves a POI by its ID
+func (r *POIRepository) GetByID(ctx context.Context, id string) (*models.POI, error) {
+	r.store.mutex.RLock()
+	defer r.store.mutex.RUnlock()
+
+	poi, exists := r.store.pois[id]
+	if !exists {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &poi, nil
+}
+
+// GetByMapID retrieves the POIs of a map, newest first
+func (r *POIRepository) GetByMapID(ctx context.Context, mapID string) ([]*models.POI, error) {
+	return r.filter(func(poi models.POI) bool {
+		return poi.MapID == mapID
+	}), nil
+}
+
+// GetInBounds retrieves the POIs of a map within the bounds, newest first
+func (r *POIRepository) GetInBounds(ctx context.Context, mapID string, minLat, maxLat, minLng, maxLng float64) ([]*models.POI, error) {
+	bounds := models.Bounds{North: maxLat, South: minLat, East: maxLng, West: minLng}
+	return r.filter(func(poi models.POI) bool {
+		return poi.MapID == mapID && bounds.Contains(poi.Position)
+	}), nil
+}
+
+// Update replaces a stored POI
+func (r *POIRepository) Update(ctx context.Context, poi *models.POI) error {
+	if err := poi.Validate(); err != nil {
+		return fmt.Errorf("POI validation failed: %w", err)
+	}
+
+	r.store.mutex.Lock()
+	defer r.store.mutex.Unlock()
+
+	poi.UpdatedAt = time.Now()
+	stored := *poi
+	stored.Map = nil
+	stored.Creator = nil
+	r.store.pois[poi.ID] = stored
+	return nil
+}
+
+// Delete removes a POI
+func (r *POIRepository) Delete(ctx context.Context, id string) error {
+	r.store.mutex.Lock()
+	defer r.store.mutex.Unlock()
+
+	if _, exists := r.store.pois[id]; !exists {
+		return gorm.ErrRecordNotFound
+	}
+	delete(r.store.pois, id)
+	return nil
+}
+
+// CheckDuplicateLocation returns the POIs of a map within 100 meters of the
+// position, except excludeID
+func (r *POIRepository) CheckDuplicateLocation(ctx context.Context, mapID string, lat, lng float64, excludeID string) ([]*models.POI, error) {
+	position := models.LatLng{Lat: lat, Lng: lng}
+	return r.filter(func(poi models.POI) bool {
+		return poi.MapID == mapID && poi.ID != excludeID && poi.DistanceTo(position) < minPOIDistanceKm
+	}), nil
+}
+
+// filter returns copies of the POIs matching, newest first
+func (r *POIRepository) filter(match func(poi models.POI) bool) []*models.POI {
+	r.store.mutex.RLock()
+	defer r.store.mutex.RUnlock()
+
+	pois := []*models.POI{}
+	for _, poi := range r.store.pois {
+		if match(poi) {
+			poi := poi
+			pois = append(pois, &poi)
+		}
+	}
+	sort.Slice(pois, func(i, j int) bool {
+		return pois[i].CreatedAt.After(pois[j].CreatedAt)
+	})
+	return pois
+}
@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"breakoutglobe/internal/models"
+)
+
+// PreferenceRepository stores the preferences of users in memory
+type PreferenceRepository struct {
+	store *Store
+}
+
+// ListByUser returns the stored preference namespaces of a user
+func (r *PreferenceRepository) ListByUser(ctx context.Context, userID string) ([]*models.UserPreference, error) {
+	r.store.mutex.RLock()
+	defer r.store.mutex.RUnlock()
+
+	preferences := []*models.UserPreference{}
+	for _, preference := range r.store.preferences[userID] {
+		preference := preference
+		preferences = append(preferences, &preference)
+	}
+	sort.Slice(preferences, func(i, j int) bool {
+		return preferences[i].Namespace < preferences[j].Namespace
+	})
+	return preferences, nil
+}
+
+// SaveAll creates or replaces the given preference namespaces
+func (r *PreferenceRepository) SaveAll(ctx context.Context, preferences []*models.UserPreference) error {
+	r.store.mutex.Lock()
+	defer r.store.mutex.Unlock()
+
+	for _, preference := range preferences {
+		if r.store.preferences[preference.UserID] == nil {
+			r.store.preferences[preference.UserID] = make(map[models.PreferenceNamespace]models.UserPreference)
+		}
+		r.store.preferences[preference.UserID][preference.Namespace] = *preference
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/redis"
+)
+
+// ErrSessionNotFound is returned for sessions without presence data, or whose
+// presence data expired
+var ErrSessionNotFound = errors.New("session not found")
+
+// defaultPresenceTTL is kept when presence data without an expiry is updated,
+// like Redis keys without a TTL
+const defaultPresenceTTL = 30 * time.Minute
+
+// presenceEntry is presence data with the time it expires, zero if it doesn't
+type presenceEntry struct {
+	data      redis.SessionPresenceData
+	expiresAt time.Time
+}
+
+// SessionPresence keeps the presence data and heartbeat presence of sessions
+// in memory, expiring them like Redis keys
+type SessionPresence struct {
+	mutex    sync.RWMutex
+	sessions map[string]presenceEntry
+	present  map[string]time.Time
+	now      func() time.Time
+}
+
+// NewSessionPresence creates an empty session presence store
+func NewSessionPresence() *SessionPresence {
+	return &SessionPresence{
+		sessions: make(map[string]presenceEntry),
+		present:  make(map[string]time.Time),
+		now:      time.Now,
+	}
+}
+
+// SetSessionPresence stores session presence data for ttl, or without expiry
+// if ttl is zero
+func (sp *SessionPresence) SetSessionPresence(ctx context.Context, sessionID string, data *redis.SessionPresenceData, ttl time.Duration) error {
+	sp.mutex.Lock()
+	defer sp.mutex.Unlock()
+
+	entry := presenceEntry{data: *data}
+	if ttl > 0 {
+		entry.expiresAt = sp.now().Add(ttl)
+	}
+	sp.sessions[sessionID] = entry
+	return nil
+}
+
+// GetSessionPresence retrieves session presence data
+func (sp *SessionPresence) GetSessionPresence(ctx context.Context, sessionID string) (*redis.SessionPresenceData, error) {
+	sp.mutex.RLock()
+	defer sp.mutex.RUnlock()
+
+	entry, ok := sp.session(sessionID)
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	return &entry.data, nil
+}
+
+// UpdateSessionActivity updates the last active timestamp for a session
+func (sp *SessionPresence) UpdateSessionActivity(ctx context.Context, sessionID string) error {
+	return sp.update(sessionID, func(data *redis.SessionPresenceData) {})
+}
+
+// UpdateAvatarPosition updates the avatar position for a session
+func (sp *SessionPresence) UpdateAvatarPosition(ctx context.Context, sessionID string, position models.LatLng) error {
+	return sp.update(sessionID, func(data *redis.SessionPresenceData) {
+		data.AvatarPosition = position
+	})
+}
+
+// SetCurrentPOI sets or clears the current POI for a session
+func (sp *SessionPresence) SetCurrentPOI(ctx context.Context, sessionID string, poiID string) error {
+	return sp.update(sessionID, func(data *redis.SessionPresenceData) {
+		if poiID == "" {
+			data.CurrentPOI = nil
+		} else {
+			data.CurrentPOI = &poiID
+		}
+	})
+}
+
+// RemoveSessionPresence removes session presence data
+func (sp *SessionPresence) RemoveSessionPresence(ctx context.Context, sessionID string) error {
+	sp.mutex.Lock()
+	defer sp.mutex.Unlock()
+
+	delete(sp.sessions, sessionID)
+	return nil
+}
+
+// GetActiveSessionsForMap retrieves the presence data of all sessions on a map
+func (sp *SessionPresence) GetActiveSessionsForMap(ctx context.Context, mapID string) ([]*redis.SessionPresenceData, error) {
+	sp.mutex.RLock()
+	defer sp.mutex.RUnlock()
+
+	var active []*redis.SessionPresenceData
+	for sessionID := range sp.sessions {
+		entry, ok := sp.session(sessionID)
+		if ok && entry.data.MapID == mapID {
+			data := entry.data
+			active = append(active, &data)
+		}
+	}
+	return active, nil
+}
+
+// SessionHeartbeat extends the TTL for a session and updates last active time
+func (sp *SessionPresence) SessionHeartbeat(ctx context.Context, sessionID string, ttl time.Duration) error {
+	sp.mutex.Lock()
+	defer sp.mutex.Unlock()
+
+	entry, ok := sp.session(sessionID)
+	if !ok {
+		return fmt.Errorf("session not found")
+	}
+	entry.data.LastActive = sp.now()
+	entry.expiresAt = sp.now().Add(ttl)
+	sp.sessions[sessionID] = entry
+	return nil
+}
+
+// CleanupExpiredSessions removes expired sessions and sessions inactive for
+// longer than maxAge, and returns how many were removed
+func (sp *SessionPresence) CleanupExpiredSessions(ctx context.Context, maxAge time.Duration) (int, error) {
+	sp.mutex.Lock()
+	defer sp.mutex.Unlock()
+
+	cleaned := 0
+	for sessionID, entry := range sp.sessions {
+		if _, ok := sp.session(sessionID); !ok || sp.now().Sub(entry.data.LastActive) > maxAge {
+			delete(sp.sessions, sessionID)
+			cleaned++
+		}
+	}
+	for sessionID := range sp.present {
+		if !sp.isPresent(sessionID) {
+			delete(sp.present, sessionID)
+		}
+	}
+	return cleaned, nil
+}
+
+// TouchPresence marks a session as present for ttl
+func (sp *SessionPresence) TouchPresence(ctx context.Context, sessionID string, ttl time.Duration) error {
+	sp.mutex.Lock()
+	defer sp.mutex.Unlock()
+
+	sp.present[sessionID] = sp.now().Add(ttl)
+	return nil
+}
+
+// ClearPresence removes the heartbeat presence of a session
+func (sp *SessionPresence) ClearPresence(ctx context.Context, sessionID string) error {
+	sp.mutex.Lock()
+	defer sp.mutex.Unlock()
+
+	delete(sp.present, sessionID)
+	return nil
+}
+
+// FilterPresent returns the sessions from sessionIDs that are present,
+// preserving their order
+func (sp *SessionPresence) FilterPresent(ctx context.Context, sessionIDs []string) ([]string, error) {
+	sp.mutex.RLock()
+	defer sp.mutex.RUnlock()
+
+	present := make([]string, 0, len(sessionIDs))
+	for _, sessionID := range sessionIDs {
+		if sp.isPresent(sessionID) {
+			present = append(present, sessionID)
+		}
+	}
+	return present, nil
+}
+
+// CountParticipantsByMap counts the distinct present users on each map. Maps
+// without participants are left out.
+func (sp *SessionPresence) CountParticipantsByMap(ctx context.Context) (map[string]int, error) {
+	sp.mutex.RLock()
+	defer sp.mutex.RUnlock()
+
+	users := make(map[string]map[string]bool)
+	for sessionID := range sp.sessions {
+		entry, ok := sp.session(sessionID)
+		if !ok || entry.data.MapID == "" || !sp.isPresent(sessionID) {
+			continue
+		}
+		if users[entry.data.MapID] == nil {
+			users[entry.data.MapID] = make(map[string]bool)
+		}
+		users[entry.data.MapID][entry.data.UserID] = true
+	}
+
+	counts := make(map[string]int, len(users))
+	for mapID, mapUsers := range users {
+		counts[mapID] = len(mapUsers)
+	}
+	return counts, nil
+}
+
+// update changes the presence data of a session and marks it active, keeping
+// its expiry
+func (sp *SessionPresence) update(sessionID string, change func(data *redis.SessionPresenceData)) error {
+	sp.mutex.Lock()
+	defer sp.mutex.Unlock()
+
+	entry, ok := sp.session(sessionID)
+	if !ok {
+		return fmt.Errorf("failed to get session data: %w", ErrSessionNotFound)
+	}
+	change(&entry.data)
+	entry.data.LastActive = sp.now()
+	if entry.expiresAt.IsZero() {
+		entry.expiresAt = sp.now().Add(defaultPresenceTTL)
+	}
+	sp.sessions[sessionID] = entry
+	return nil
+}
+
+// session returns the presence data of a session unless it expired. The
+// caller must hold the mutex.
+func (sp *SessionPresence) session(sessionID string) (presenceEntry, bool) {
+	entry, exists := sp.sessions[sessionID]
+	if !exists || (!entry.expiresAt.IsZero() && !sp.now().Before(entry.expiresAt)) {
+		return presenceEntry{}, false
+	}
+	return entry, true
+}
+
+// isPresent reports whether a session's heartbeat presence is live. The
+// caller must hold the mutex.
+func (sp *SessionPresence) isPresent(sessionID string) bool {
+	expiresAt, exists := sp.present[sessionID]
+	return exists && sp.now().Before(expiresAt)
+}
@@ -0,0 +1,177 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"breakoutglobe/internal/redis"
+)
+
+// subscriberBufferSize bounds the events waiting for a slow subscriber; more
+// are dropped, like messages of a Redis subscription that can't keep up
+const subscriberBufferSize = 1024
+
+// PubSub delivers real-time events to the subscribers of the same process
+type PubSub struct {
+	mutex       sync.RWMutex
+	subscribers map[chan redis.Event]bool
+}
+
+// NewPubSub creates a PubSub without subscribers
+func NewPubSub() *PubSub {
+	return &PubSub{
+		subscribers: make(map[chan redis.Event]bool),
+	}
+}
+
+// PublishAvatarMovement publishes an avatar movement event
+func (ps *PubSub) PublishAvatarMovement(ctx context.Context, event redis.AvatarMovementEvent) error {
+	return ps.publish(redis.EventTypeAvatarMovement, event)
+}
+
+// PublishPOICreated publishes a POI created event
+func (ps *PubSub) PublishPOICreated(ctx context.Context, event redis.POICreatedEvent) error {
+	return ps.publish(redis.EventTypePOICreated, event)
+}
+
+// PublishPOIUpdated publishes a POI updated event
+func (ps *PubSub) PublishPOIUpdated(ctx context.Context, event redis.POIUpdatedEvent) error {
+	return ps.publish(redis.EventTypePOIUpdated, event)
+}
+
+// PublishPOIDeleted publishes a POI deleted event
+func (ps *PubSub) PublishPOIDeleted(ctx context.Context, event redis.POIDeletedEvent) error {
+	return ps.publish(redis.EventTypePOIDeleted, event)
+}
+
+// PublishPOIJoined publishes a POI joined event
+func (ps *PubSub) PublishPOIJoined(ctx context.Context, event redis.POIJoinedEvent) error {
+	return ps.publish(redis.EventTypePOIJoined, event)
+}
+
+// PublishPOILeft publishes a POI left event
+func (ps *PubSub) PublishPOILeft(ctx context.Context, event redis.POILeftEvent) error {
+	return ps.publish(redis.EventTypePOILeft, event)
+}
+
+// PublishPOIParticipantAdded publishes a roster delta for a user joining a POI
+func (ps *PubSub) PublishPOIParticipantAdded(ctx context.Context, event redis.POIParticipantDeltaEvent) error {
+	return ps.publish(redis.EventTypePOIParticipantAdded, event)
+}
+
+// PublishPOIParticipantRemoved publishes a roster delta for a user leaving a POI
+func (ps *PubSub) PublishPOIParticipantRemoved(ctx context.Context, event redis.POIParticipantDeltaEvent) error {
+	return ps.publish(redis.EventTypePOIParticipantRemoved, event)
+}
+
+// PublishUserProfileUpdated publishes a user profile updated event
+func (ps *PubSub) PublishUserProfileUpdated(ctx context.Context, event redis.UserProfileUpdatedEvent) error {
+	return ps.publish(redis.EventTypeUserProfileUpdated, event)
+}
+
+// PublishUserRoleChanged publishes a user role changed event
+func (ps *PubSub) PublishUserRoleChanged(ctx context.Context, event redis.UserRoleChangedEvent) error {
+	return ps.publish(redis.EventTypeUserRoleChanged, event)
+}
+
+// PublishMapFrozen publishes a map frozen event
+func (ps *PubSub) PublishMapFrozen(ctx context.Context, event redis.MapFreezeEvent) error {
+	return ps.publish(redis.EventTypeMapFrozen, event)
+}
+
+// PublishMapUnfrozen publishes a map unfrozen event
+func (ps *PubSub) PublishMapUnfrozen(ctx context.Context, event redis.MapFreezeEvent) error {
+	return ps.publish(redis.EventTypeMapUnfrozen, event)
+}
+
+// PublishOnboardingProgress publishes an onboarding progress event
+func (ps *PubSub) PublishOnboardingProgress(ctx context.Context, event redis.OnboardingProgressEvent) error {
+	return ps.publish(redis.EventTypeOnboardingProgress, event)
+}
+
+// PublishHandQueueUpdated publishes a hand queue updated event
+func (ps *PubSub) PublishHandQueueUpdated(ctx context.Context, event redis.HandQueueEvent) error {
+	return ps.publish(redis.EventTypeHandQueueUpdated, event)
+}
+
+// PublishPOIMerged publishes a POI merged event
+func (ps *PubSub) PublishPOIMerged(ctx context.Context, event redis.POIMergedEvent) error {
+	return ps.publish(redis.EventTypePOIMerged, event)
+}
+
+// PublishSummonedToPOI publishes a summoned to POI event
+func (ps *PubSub) PublishSummonedToPOI(ctx context.Context, event redis.SummonEvent) error {
+	return ps.publish(redis.EventTypeSummonedToPOI, event)
+}
+
+// PublishUserKicked publishes a user kicked event
+func (ps *PubSub) PublishUserKicked(ctx context.Context, event redis.UserKickedEvent) error {
+	return ps.publish(redis.EventTypeUserKicked, event)
+}
+
+// PublishMapDeleted publishes a map deleted event
+func (ps *PubSub) PublishMapDeleted(ctx context.Context, event redis.MapDeletedEvent) error {
+	return ps.publish(redis.EventTypeMapDeleted, event)
+}
+
+// PublishPOIReminder publishes a POI reminder event
+func (ps *PubSub) PublishPOIReminder(ctx context.Context, event redis.POIReminderEvent) error {
+	return ps.publish(redis.EventTypePOIReminder, event)
+}
+
+// PublishMaintenanceChanged publishes a maintenance changed event
+func (ps *PubSub) PublishMaintenanceChanged(ctx context.Context, event redis.MaintenanceEvent) error {
+	return ps.publish(redis.EventTypeMaintenanceChanged, event)
+}
+
+// SubscribePOIEvents calls the callback for every POI-related event, user
+// profile and role change, map freeze, onboarding progress, hand queue update
+// and maintenance change until ctx is cancelled
+func (ps *PubSub) SubscribePOIEvents(ctx context.Context, callback func(eventType string, data interface{})) error {
+	events := make(chan redis.Event, subscriberBufferSize)
+
+	ps.mutex.Lock()
+	ps.subscribers[events] = true
+	ps.mutex.Unlock()
+
+	defer func() {
+		ps.mutex.Lock()
+		delete(ps.subscribers, events)
+		ps.mutex.Unlock()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event := <-events:
+			redis.DispatchPOIEvent(event, callback)
+		}
+	}
+}
+
+// publish encodes an event like the Redis PubSub does and hands it to every
+// subscriber
+func (ps *PubSub) publish(eventType redis.EventType, eventData interface{}) error {
+	data, err := json.Marshal(eventData)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event data: %w", err)
+	}
+	event := redis.Event{Type: eventType, Data: data, Timestamp: time.Now()}
+
+	ps.mutex.RLock()
+	defer ps.mutex.RUnlock()
+
+	for subscriber := range ps.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			log.Printf("⚠️ Subscriber can't keep up, dropping %s event", eventType)
+		}
+	}
+	return nil
+}
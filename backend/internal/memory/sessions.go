@@ -0,0 +1,235 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// SessionRepository stores avatar sessions in memory
+type SessionRepository struct {
+	store *Store
+}
+
+// Create stores a new session unless the user already has an active session
+// in the map
+func (r *SessionRepository) Create(session *models.Session) error {
+	if session == nil {
+		return fmt.Errorf("session cannot be nil")
+	}
+
+	if err := prepareSession(session); err != nil {
+		return err
+	}
+
+	r.store.mutex.Lock()
+	defer r.store.mutex.Unlock()
+
+	if r.store.activeSession(session.UserID, session.MapID) != nil {
+		return repository.ErrActiveSessionExists
+	}
+
+	r.store.sessions[session.ID] = *session
+	return nil
+}
+
+// CreateOrGetActive creates the session unless the user already has an active
+// session in the map, in which case the existing session is returned.
+// The returned bool reports whether a new session was created.
+func (r *SessionRepository) CreateOrGetActive(session *models.Session) (*models.Session, bool, error) {
+	if session == nil {
+		return nil, false, fmt.Errorf("session cannot be nil")
+	}
+
+	if err := prepareSession(session); err != nil {
+		return nil, false, err
+	}
+
+	r.store.mutex.Lock()
+	defer r.store.mutex.Unlock()
+
+	if existing := r.store.activeSession(session.UserID, session.MapID); existing != nil {
+		return existing, false, nil
+	}
+
+	r.store.sessions[session.ID] = *session
+	return session, true, nil
+}
+
+// prepareSession fills in generated fields and validates a session before it
+// is stored
+func prepareSession(session *models.Session) error {
+	if session.ID == "" {
+		newSession, err := models.NewSession(session.UserID, session.MapID, session.AvatarPos)
+		if err != nil {
+			return fmt.Errorf("failed to create session: %w", err)
+		}
+		session.ID = newSession.ID
+		session.CreatedAt = newSession.CreatedAt
+		session.LastActive = newSession.LastActive
+	}
+	session.IsActive = true
+
+	if err := session.Validate(); err != nil {
+		return fmt.Errorf("session validation failed: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a session by its ID
+func (r *SessionRepository) GetByID(id string) (*models.Session, error) {
+	r.store.mutex.RLock()
+	defer r.store.mutex.RUnlock()
+
+	session, exists := r.store.sessions[id]
+	if !exists {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &session, nil
+}
+
+// GetByIDWithUser retrieves a session by its ID with its user
+func (r *SessionRepository) GetByIDWithUser(id string) (*models.Session, error) {
+	r.store.mutex.RLock()
+	defer r.store.mutex.RUnlock()
+
+	session, exists := r.store.sessions[id]
+	if !exists {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if user, exists := r.store.users[session.UserID]; exists {
+		session.User = &user
+	}
+	return &session, nil
+}
+
+// GetByUserAndMap retrieves the active session of a user in a map
+func (r *SessionRepository) GetByUserAndMap(userID, mapID string) (*models.Session, error) {
+	r.store.mutex.RLock()
+	defer r.store.mutex.RUnlock()
+
+	session := r.store.activeSession(userID, mapID)
+	if session == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return session, nil
+}
+
+// Update replaces a stored session
+func (r *SessionRepository) Update(session *models.Session) error {
+	if session == nil {
+		return fmt.Errorf("session cannot be nil")
+	}
+
+	if err := session.Validate(); err != nil {
+		return fmt.Errorf("session validation failed: %w", err)
+	}
+
+	r.store.mutex.Lock()
+	defer r.store.mutex.Unlock()
+
+	stored := *session
+	stored.User = nil
+	stored.Map = nil
+	r.store.sessions[session.ID] = stored
+	return nil
+}
+
+// UpdateAvatarPosition updates the avatar position of a session
+func (r *SessionRepository) UpdateAvatarPosition(sessionID string, position models.LatLng) error {
+	if err := position.Validate(); err != nil {
+		return fmt.Errorf("invalid position: %w", err)
+	}
+
+	r.store.mutex.Lock()
+	defer r.store.mutex.Unlock()
+
+	session, exists := r.store.sessions[sessionID]
+	if !exists {
+		return gorm.ErrRecordNotFound
+	}
+	session.AvatarPos = position
+	session.LastActive = time.Now()
+	r.store.sessions[sessionID] = session
+	return nil
+}
+
+// Delete removes a session
+func (r *SessionRepository) Delete(id string) error {
+	r.store.mutex.Lock()
+	defer r.store.mutex.Unlock()
+
+	if _, exists := r.store.sessions[id]; !exists {
+		return gorm.ErrRecordNotFound
+	}
+	delete(r.store.sessions, id)
+	return nil
+}
+
+// GetActiveByMap retrieves the active sessions of a map, most recently active
+// first
+func (r *SessionRepository) GetActiveByMap(mapID string) ([]*models.Session, error) {
+	r.store.mutex.RLock()
+	defer r.store.mutex.RUnlock()
+
+	sessions := []*models.Session{}
+	for _, session := range r.store.sessions {
+		if session.MapID == mapID && session.IsActive {
+			session := session
+			sessions = append(sessions, &session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastActive.After(sessions[j].LastActive)
+	})
+	return sessions, nil
+}
+
+// GetActiveMapIDsByUser retrieves the distinct maps a user has active sessions in
+func (r *SessionRepository) GetActiveMapIDsByUser(userID string) ([]string, error) {
+	r.store.mutex.RLock()
+	defer r.store.mutex.RUnlock()
+
+	seen := make(map[string]bool)
+	mapIDs := []string{}
+	for _, session := range r.store.sessions {
+		if session.UserID == userID && session.IsActive && !seen[session.MapID] {
+			seen[session.MapID] = true
+			mapIDs = append(mapIDs, session.MapID)
+		}
+	}
+	return mapIDs, nil
+}
+
+// ExpireOldSessions marks sessions inactive that weren't active within timeout
+func (r *SessionRepository) ExpireOldSessions(timeout time.Duration) error {
+	cutoff := time.Now().Add(-timeout)
+
+	r.store.mutex.Lock()
+	defer r.store.mutex.Unlock()
+
+	for id, session := range r.store.sessions {
+		if session.IsActive && session.LastActive.Before(cutoff) {
+			session.IsActive = false
+			r.store.sessions[id] = session
+		}
+	}
+	return nil
+}
+
+// activeSession returns a copy of the active session of a user in a map, or
+// nil. The caller must hold the mutex.
+func (s *Store) activeSession(userID, mapID string) *models.Session {
+	for _, session := range s.sessions {
+		if session.UserID == userID && session.MapID == mapID && session.IsActive {
+			return &session
+		}
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Package memory keeps all data in process memory instead of Postgres and
+// Redis, so the server runs without dependencies for frontend development and
+// demos. Everything is lost on restart, and state isn't shared between
+// instances.
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"breakoutglobe/internal/models"
+)
+
+// Store holds the rows of the in-memory repositories. Repositories of the
+// same store see each other's rows, like tables of one database.
+type Store struct {
+	mutex        sync.RWMutex
+	users        map[string]models.User
+	sessions     map[string]models.Session
+	pois         map[string]models.POI
+	maps         map[string]models.Map
+	mapSnapshots map[string]models.MapSnapshot
+	preferences  map[string]map[models.PreferenceNamespace]models.UserPreference
+	onboarding   map[string]map[models.OnboardingStep]models.UserOnboardingStep
+	authSessions map[string]models.AuthSession
+}
+
+// NewStore creates a store with the system user and the default map, like a
+// freshly migrated database
+func NewStore() *Store {
+	s := &Store{}
+	s.reset()
+	return s
+}
+
+// reset drops all rows and recreates the system defaults. The caller must hold
+// the mutex or own the store exclusively.
+func (s *Store) reset() {
+	s.users = make(map[string]models.User)
+	s.sessions = make(map[string]models.Session)
+	s.pois = make(map[string]models.POI)
+	s.maps = make(map[string]models.Map)
+	s.mapSnapshots = make(map[string]models.MapSnapshot)
+	s.preferences = make(map[string]map[models.PreferenceNamespace]models.UserPreference)
+	s.onboarding = make(map[string]map[models.OnboardingStep]models.UserOnboardingStep)
+	s.authSessions = make(map[string]models.AuthSession)
+
+	now := time.Now()
+	email := "system@breakoutglobe.local"
+	s.users["system"] = models.User{
+		ID:          "system",
+		DisplayName: "System",
+		Email:       &email,
+		AccountType: "system",
+		Role:        "admin",
+		IsActive:    true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	s.maps["default-map"] = models.Map{
+		ID:          "default-map",
+		Name:        "Default Map",
+		Description: "The default map for BreakoutGlobe sessions",
+		CreatedBy:   "system",
+		IsActive:    true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// Users returns the user repository of the store
+func (s *Store) Users() *UserRepository {
+	return &UserRepository{store: s}
+}
+
+// Sessions returns the session repository of the store
+func (s *Store) Sessions() *SessionRepository {
+	return &SessionRepository{store: s}
+}
+
+// POIs returns the POI repository of the store
+func (s *Store) POIs() *POIRepository {
+	return &POIRepository{store: s}
+}
+
+// Maps returns the map repository of the store
+func (s *Store) Maps() *MapRepository {
+	return &MapRepository{store: s}
+}
+
+// Preferences returns the preference repository of the store
+func (s *Store) Preferences() *PreferenceRepository {
+	return &PreferenceRepository{store: s}
+}
+
+// Onboarding returns the onboarding repository of the store
+func (s *Store) Onboarding() *OnboardingRepository {
+	return &OnboardingRepository{store: s}
+}
+
+// AuthSessions returns the auth session repository of the store
+func (s *Store) AuthSessions() *AuthSessionRepository {
+	return &AuthSessionRepository{store: s}
+}
@@ -0,0 +1,188 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/redis"
+	"breakoutglobe/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionRepository_OneActiveSessionPerUserAndMap(t *testing.T) {
+	sessions := NewStore().Sessions()
+	position := models.LatLng{Lat: 52.52, Lng: 13.405}
+
+	first, created, err := sessions.CreateOrGetActive(&models.Session{UserID: "user-1", MapID: "default-map", AvatarPos: position})
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	second, created, err := sessions.CreateOrGetActive(&models.Session{UserID: "user-1", MapID: "default-map", AvatarPos: position})
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, first.ID, second.ID)
+
+	err = sessions.Create(&models.Session{UserID: "user-1", MapID: "default-map", AvatarPos: position})
+	assert.ErrorIs(t, err, repository.ErrActiveSessionExists)
+
+	mapIDs, err := sessions.GetActiveMapIDsByUser("user-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"default-map"}, mapIDs)
+}
+
+func TestPOIParticipants_JoinWithCapacityCheck(t *testing.T) {
+	ctx := context.Background()
+	participants := NewPOIParticipants()
+
+	require.NoError(t, participants.JoinPOIWithCapacityCheck(ctx, "poi-1", "session-1", 1))
+	// Joining again doesn't take another seat
+	require.NoError(t, participants.JoinPOIWithCapacityCheck(ctx, "poi-1", "session-1", 1))
+
+	err := participants.JoinPOIWithCapacityCheck(ctx, "poi-1", "session-2", 1)
+	assert.ErrorIs(t, err, redis.ErrPOIAtCapacity)
+
+	require.NoError(t, participants.RemoveParticipantFromAllPOIs(ctx, "session-1"))
+	count, err := participants.GetParticipantCount(ctx, "poi-1")
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestPOIParticipants_JoinTimes(t *testing.T) {
+	ctx := context.Background()
+	participants := NewPOIParticipants()
+
+	require.NoError(t, participants.JoinPOI(ctx, "poi-1", "session-1"))
+	joinTimes, err := participants.GetJoinTimes(ctx, "poi-1")
+	require.NoError(t, err)
+	joinedAt := joinTimes["session-1"]
+	assert.WithinDuration(t, time.Now(), joinedAt, time.Second)
+
+	// Joining again keeps the original join time
+	require.NoError(t, participants.JoinPOIWithCapacityCheck(ctx, "poi-1", "session-1", 5))
+	joinTimes, err = participants.GetJoinTimes(ctx, "poi-1")
+	require.NoError(t, err)
+	assert.Equal(t, joinedAt, joinTimes["session-1"])
+
+	require.NoError(t, participants.LeavePOI(ctx, "poi-1", "session-1"))
+	joinTimes, err = participants.GetJoinTimes(ctx, "poi-1")
+	require.NoError(t, err)
+	assert.Empty(t, joinTimes)
+}
+
+func TestPOIParticipants_RecentJoins(t *testing.T) {
+	ctx := context.Background()
+	participants := NewPOIParticipants()
+	now := time.Now()
+
+	require.NoError(t, participants.RecordJoin(ctx, "poi-1", "user-1", now.Add(-2*time.Hour), time.Hour))
+	require.NoError(t, participants.RecordJoin(ctx, "poi-1", "user-2", now.Add(-time.Minute), time.Hour))
+	// Joining again counts the user once, at their last join
+	require.NoError(t, participants.RecordJoin(ctx, "poi-1", "user-2", now, time.Hour))
+	require.NoError(t, participants.RecordJoin(ctx, "poi-2", "user-1", now, time.Hour))
+
+	joins, err := participants.CountJoinsSince(ctx, []string{"poi-1", "poi-2", "poi-3"}, now.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"poi-1": 1, "poi-2": 1}, joins)
+
+	// Joins older than the window are forgotten
+	assert.NotContains(t, participants.recentJoins["poi-1"], "user-1")
+}
+
+func TestHandQueue_KeepsRaiseOrder(t *testing.T) {
+	ctx := context.Background()
+	hands := NewHandQueue()
+	start := time.Now()
+
+	raised, err := hands.RaiseHand(ctx, "map-1", "user-1", start)
+	require.NoError(t, err)
+	assert.True(t, raised)
+	_, _ = hands.RaiseHand(ctx, "map-1", "user-2", start.Add(time.Second))
+	raised, _ = hands.RaiseHand(ctx, "map-1", "user-1", start.Add(2*time.Second))
+	assert.False(t, raised, "raising again keeps the user's place")
+
+	queue, err := hands.GetHands(ctx, "map-1")
+	require.NoError(t, err)
+	assert.Equal(t, []models.RaisedHand{{UserID: "user-1", RaisedAt: start}, {UserID: "user-2", RaisedAt: start.Add(time.Second)}}, queue)
+
+	lowered, _ := hands.LowerHand(ctx, "map-1", "user-1")
+	assert.True(t, lowered)
+	lowered, _ = hands.LowerHand(ctx, "map-1", "user-1")
+	assert.False(t, lowered)
+	queue, _ = hands.GetHands(ctx, "map-1")
+	assert.Equal(t, []models.RaisedHand{{UserID: "user-2", RaisedAt: start.Add(time.Second)}}, queue)
+}
+
+func TestMapBans_Expiry(t *testing.T) {
+	bans := NewMapBans()
+	ctx := context.Background()
+
+	require.NoError(t, bans.AddMapBan(ctx, models.MapBan{MapID: "map-1", UserID: "user-1", BannedBy: "user-9", Until: time.Now().Add(time.Hour)}))
+	require.NoError(t, bans.AddMapBan(ctx, models.MapBan{MapID: "map-1", UserID: "user-2", BannedBy: "user-9", Until: time.Now().Add(-time.Second)}))
+
+	ban, err := bans.GetMapBan(ctx, "map-1", "user-1")
+	require.NoError(t, err)
+	require.NotNil(t, ban)
+	assert.Equal(t, "user-9", ban.BannedBy)
+
+	ban, err = bans.GetMapBan(ctx, "map-1", "user-2")
+	require.NoError(t, err)
+	assert.Nil(t, ban)
+	ban, err = bans.GetMapBan(ctx, "map-2", "user-1")
+	require.NoError(t, err)
+	assert.Nil(t, ban)
+}
+
+func TestSessionPresence_Expiry(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	presence := NewSessionPresence()
+	presence.now = func() time.Time { return now }
+
+	data := &redis.SessionPresenceData{UserID: "user-1", MapID: "default-map", LastActive: now}
+	require.NoError(t, presence.SetSessionPresence(ctx, "session-1", data, time.Minute))
+	require.NoError(t, presence.TouchPresence(ctx, "session-1", 30*time.Second))
+
+	counts, err := presence.CountParticipantsByMap(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"default-map": 1}, counts)
+
+	// The heartbeat presence expires first, then the presence data
+	now = now.Add(45 * time.Second)
+	present, err := presence.FilterPresent(ctx, []string{"session-1"})
+	require.NoError(t, err)
+	assert.Empty(t, present)
+	_, err = presence.GetSessionPresence(ctx, "session-1")
+	require.NoError(t, err)
+
+	now = now.Add(30 * time.Second)
+	_, err = presence.GetSessionPresence(ctx, "session-1")
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+}
+
+func TestPubSub_DeliversToSubscribers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pubsub := NewPubSub()
+
+	received := make(chan string, 1)
+	go pubsub.SubscribePOIEvents(ctx, func(eventType string, data interface{}) {
+		received <- eventType
+	})
+
+	// Publish until the subscription is registered
+	require.Eventually(t, func() bool {
+		if err := pubsub.PublishPOIJoined(ctx, redis.POIJoinedEvent{POIID: "poi-1", MapID: "default-map", UserID: "user-1"}); err != nil {
+			return false
+		}
+		select {
+		case eventType := <-received:
+			return eventType == string(redis.EventTypePOIJoined)
+		case <-time.After(10 * time.Millisecond):
+			return false
+		}
+	}, time.Second, 20*time.Millisecond)
+}
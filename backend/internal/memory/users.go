@@ -0,0 +1,172 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// UserRepository stores users in memory
+type UserRepository struct {
+	store *Store
+}
+
+// Create stores a new user
+func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
+	if user == nil {
+		return fmt.Errorf("user cannot be nil")
+	}
+
+	if err := user.Validate(); err != nil {
+		return fmt.Errorf("user validation failed: %w", err)
+	}
+
+	r.store.mutex.Lock()
+	defer r.store.mutex.Unlock()
+
+	if _, exists := r.store.users[user.ID]; exists {
+		return fmt.Errorf("failed to create user: user %s already exists", user.ID)
+	}
+	if user.Email != nil && r.store.userByEmail(*user.Email) != nil {
+		return fmt.Errorf("failed to create user: email already registered")
+	}
+
+	now := time.Now()
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = now
+	}
+	user.UpdatedAt = now
+	user.IsActive = true
+	r.store.users[user.ID] = *user
+	return nil
+}
+
+// GetByID retrieves a user by their ID
+func (r *UserRepository) GetByID(ctx context.Context, id string) (*models.User, error) {
+	r.store.mutex.RLock()
+	defer r.store.mutex.RUnlock()
+
+	user, exists := r.store.users[id]
+	if !exists {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &user, nil
+}
+
+// GetByEmail retrieves a user by their email address
+func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	if email == "" {
+		return nil, fmt.Errorf("email cannot be empty")
+	}
+
+	r.store.mutex.RLock()
+	defer r.store.mutex.RUnlock()
+
+	user := r.store.userByEmail(email)
+	if user == nil {
+		return nil, fmt.Errorf("user not found")
+	}
+	return user, nil
+}
+
+// ListByRole lists the active users with a role
+func (r *UserRepository) ListByRole(ctx context.Context, role models.UserRole) ([]*models.User, error) {
+	r.store.mutex.RLock()
+	defer r.store.mutex.RUnlock()
+
+	var users []*models.User
+	for _, user := range r.store.users {
+		if user.Role == role && user.IsActive {
+			user := user
+			users = append(users, &user)
+		}
+	}
+	return users, nil
+}
+
+// Update replaces a stored user
+func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
+	if user == nil {
+		return fmt.Errorf("user cannot be nil")
+	}
+
+	if err := user.Validate(); err != nil {
+		return fmt.Errorf("user validation failed: %w", err)
+	}
+
+	r.store.mutex.Lock()
+	defer r.store.mutex.Unlock()
+
+	if user.Email != nil {
+		if other := r.store.userByEmail(*user.Email); other != nil && other.ID != user.ID {
+			return fmt.Errorf("failed to update user: email already registered")
+		}
+	}
+
+	user.UpdatedAt = time.Now()
+	r.store.users[user.ID] = *user
+	return nil
+}
+
+// ClearAllUsers removes all users along with their sessions, POIs and maps, and
+// recreates the system user and default map - Development helper method
+func (r *UserRepository) ClearAllUsers(ctx context.Context) error {
+	r.store.mutex.Lock()
+	defer r.store.mutex.Unlock()
+
+	r.store.reset()
+	return nil
+}
+
+// CreateSuperAdmin creates the super admin account unless one exists. Nothing
+// is created without credentials.
+func (s *Store) CreateSuperAdmin(email, password string) error {
+	if email == "" || password == "" {
+		return nil
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(password), 12)
+	if err != nil {
+		return fmt.Errorf("failed to hash super admin password: %w", err)
+	}
+	passwordHashStr := string(passwordHash)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, user := range s.users {
+		if user.Role == models.UserRoleSuperAdmin {
+			return nil
+		}
+	}
+
+	now := time.Now()
+	s.users["superadmin"] = models.User{
+		ID:           "superadmin",
+		Email:        &email,
+		DisplayName:  "Super Admin",
+		PasswordHash: &passwordHashStr,
+		AccountType:  models.AccountTypeFull,
+		Role:         models.UserRoleSuperAdmin,
+		IsActive:     true,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	return nil
+}
+
+// userByEmail returns a copy of the user with the email, or nil. The caller
+// must hold the mutex.
+func (s *Store) userByEmail(email string) *models.User {
+	for _, user := range s.users {
+		if user.Email != nil && *user.Email == email {
+			return &user
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+// Package metrics holds the Prometheus helpers shared by the server's metrics
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// LatencyBuckets are upper bounds in seconds suited for delivery latencies
+var LatencyBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Sample is the value of one series of a metric collected at scrape time
+type Sample struct {
+	LabelValues []string
+	Value       float64
+}
+
+// FuncCollector is a metric whose values are kept elsewhere, like the number
+// of open connections, and collected when Prometheus scrapes. Unlike
+// prometheus.GaugeFunc, it can report a series per label value.
+type FuncCollector struct {
+	desc      *prometheus.Desc
+	valueType prometheus.ValueType
+	labels    int
+	collect   func() []Sample
+}
+
+// NewGaugeFunc creates a gauge whose samples, with label values in the order
+// of the label names, are collected at scrape time
+func NewGaugeFunc(name, help string, collect func() []Sample, labelNames ...string) *FuncCollector {
+	return newFuncCollector(name, help, prometheus.GaugeValue, collect, labelNames)
+}
+
+// NewCounterFunc creates a counter whose samples are collected at scrape
+// time. The collected values must only go up.
+func NewCounterFunc(name, help string, collect func() []Sample, labelNames ...string) *FuncCollector {
+	return newFuncCollector(name, help, prometheus.CounterValue, collect, labelNames)
+}
+
+func newFuncCollector(name, help string, valueType prometheus.ValueType, collect func() []Sample, labelNames []string) *FuncCollector {
+	return &FuncCollector{
+		desc:      prometheus.NewDesc(name, help, labelNames, nil),
+		valueType: valueType,
+		labels:    len(labelNames),
+		collect:   collect,
+	}
+}
+
+// Describe implements prometheus.Collector
+func (f *FuncCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- f.desc
+}
+
+// Collect implements prometheus.Collector. Samples with the wrong number of
+// label values are skipped.
+func (f *FuncCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, sample := range f.collect() {
+		if len(sample.LabelValues) != f.labels {
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(f.desc, f.valueType, sample.Value, sample.LabelValues...)
+	}
+}
@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGaugeFunc_Collect(t *testing.T) {
+	gauge := NewGaugeFunc("connections", "Open connections.", func() []Sample {
+		return []Sample{
+			{LabelValues: []string{"map-2"}, Value: 3},
+			{LabelValues: []string{"map-1"}, Value: 5},
+			// Wrong number of label values
+			{Value: 1},
+		}
+	}, "map_id")
+
+	assert.NoError(t, testutil.CollectAndCompare(gauge, strings.NewReader(`# HELP connections Open connections.
+# TYPE connections gauge
+connections{map_id="map-1"} 5
+connections{map_id="map-2"} 3
+`)))
+}
+
+func TestCounterFunc_CollectWithoutLabels(t *testing.T) {
+	counter := NewCounterFunc("broadcasts_total", "Broadcasts.", func() []Sample {
+		return []Sample{{Value: 42}}
+	})
+
+	assert.NoError(t, testutil.CollectAndCompare(counter, strings.NewReader(`# HELP broadcasts_total Broadcasts.
+# TYPE broadcasts_total counter
+broadcasts_total 42
+`)))
+}
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// APIVersion is the current version of the public REST API
+	APIVersion = "v1"
+	// APIBasePath is the prefix of the current API version. Routes are
+	// registered under /api, which stays an alias of the current version.
+	APIBasePath = "/api/" + APIVersion
+)
+
+// apiPrefix is the unversioned prefix routes are registered under
+const apiPrefix = "/api"
+
+// apiVersionKey is the request context key of the version a request asked for
+type apiVersionKey struct{}
+
+// APIVersions serves the versioned API paths of the current version by the
+// routes registered under /api, so /api/v1/maps and /api/maps reach the same
+// handler. Other versions are left alone and 404 until routes exist for them.
+// The rewrite has to happen before the router matches the path, so this wraps
+// the router instead of being a gin middleware.
+func APIVersions(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rest, ok := cutVersionPrefix(r.URL.Path); ok {
+			r = r.WithContext(context.WithValue(r.Context(), apiVersionKey{}, APIVersion))
+			r.URL.Path = apiPrefix + rest
+			if r.URL.RawPath != "" {
+				if rawRest, ok := cutVersionPrefix(r.URL.RawPath); ok {
+					r.URL.RawPath = apiPrefix + rawRest
+				}
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// cutVersionPrefix strips the current version's prefix from a path
+func cutVersionPrefix(path string) (string, bool) {
+	rest, ok := strings.CutPrefix(path, APIBasePath)
+	if !ok || (rest != "" && rest[0] != '/') {
+		return "", false
+	}
+	return rest, true
+}
+
+// RequestedAPIVersion returns the API version in the path of the request, or
+// an empty string for unversioned /api paths
+func RequestedAPIVersion(c *gin.Context) string {
+	version, _ := c.Request.Context().Value(apiVersionKey{}).(string)
+	return version
+}
+
+// Deprecation describes the end of life of an API version or route, announced
+// with the Deprecation and Sunset headers (RFC 9745 and RFC 8594)
+type Deprecation struct {
+	// DeprecatedAt is when the API was deprecated; zero if it just is
+	DeprecatedAt time.Time
+	// Sunset is when the API stops working; zero if not decided yet
+	Sunset time.Time
+	// Successor is the path of the replacement, linked as successor-version
+	Successor string
+}
+
+// Header writes the deprecation headers to a response
+func (d Deprecation) Header(header http.Header) {
+	if d.DeprecatedAt.IsZero() {
+		header.Set("Deprecation", "true")
+	} else {
+		header.Set("Deprecation", fmt.Sprintf("@%d", d.DeprecatedAt.Unix()))
+	}
+	if !d.Sunset.IsZero() {
+		header.Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if d.Successor != "" {
+		header.Add("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", d.Successor))
+	}
+}
+
+// Deprecated marks the routes it is used on as deprecated. Future versions
+// use it on the routes of the version they replace.
+func Deprecated(deprecation Deprecation) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		deprecation.Header(c.Writer.Header())
+		c.Next()
+	}
+}
+
+// APIVersioning sets X-API-Version on API responses, and marks requests
+// to unversioned /api paths as deprecated if unversioned is set, linking the
+// same path of the current version as successor
+func APIVersioning(unversioned *Deprecation) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.Request.URL.Path
+		if path != apiPrefix && !strings.HasPrefix(path, apiPrefix+"/") {
+			c.Next()
+			return
+		}
+
+		c.Header("X-API-Version", APIVersion)
+		if unversioned != nil && RequestedAPIVersion(c) == "" {
+			deprecation := *unversioned
+			deprecation.Successor = APIBasePath + strings.TrimPrefix(path, apiPrefix)
+			deprecation.Header(c.Writer.Header())
+		}
+		c.Next()
+	}
+}
+
+// ParseSunset parses a sunset date, either a day like 2027-01-31 or an RFC
+// 3339 timestamp
+func ParseSunset(value string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid sunset %q, expected a date like 2027-01-31 or an RFC 3339 timestamp", value)
+	}
+	return t, nil
+}
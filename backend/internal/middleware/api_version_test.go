@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAPIVersionRouter(unversioned *Deprecation) http.Handler {
+	router := setupTestRouter()
+	router.Use(APIVersioning(unversioned))
+	router.GET("/api/maps/:id", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"id": c.Param("id"), "version": RequestedAPIVersion(c)})
+	})
+	router.GET("/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+	return APIVersions(router)
+}
+
+// TestAPIVersions_AliasesCurrentVersion tests that /api/v1 and /api reach the same routes
+func TestAPIVersions_AliasesCurrentVersion(t *testing.T) {
+	router := setupAPIVersionRouter(nil)
+
+	for path, version := range map[string]string{"/api/v1/maps/map-1": "v1", "/api/maps/map-1": ""} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		require.Equal(t, http.StatusOK, w.Code, path)
+		assert.JSONEq(t, `{"id":"map-1","version":"`+version+`"}`, w.Body.String(), path)
+		assert.Equal(t, "v1", w.Header().Get("X-API-Version"), path)
+		assert.Empty(t, w.Header().Get("Deprecation"), path)
+	}
+
+	// Unknown versions and look-alike prefixes aren't rewritten
+	for _, path := range []string{"/api/v2/maps/map-1", "/api/v1maps/map-1"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusNotFound, w.Code, path)
+	}
+
+	// Routes outside the API don't get a version
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("X-API-Version"))
+}
+
+// TestAPIVersioning_DeprecatesUnversionedPaths tests the headers sent once /api is deprecated
+func TestAPIVersioning_DeprecatesUnversionedPaths(t *testing.T) {
+	deprecatedAt := time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2027, 5, 1, 0, 0, 0, 0, time.UTC)
+	router := setupAPIVersionRouter(&Deprecation{DeprecatedAt: deprecatedAt, Sunset: sunset})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/maps/map-1", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "@1793491200", w.Header().Get("Deprecation"))
+	assert.Equal(t, "Sat, 01 May 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+	assert.Equal(t, `</api/v1/maps/map-1>; rel="successor-version"`, w.Header().Get("Link"))
+
+	// The versioned path isn't deprecated
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/maps/map-1", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Deprecation"))
+	assert.Empty(t, w.Header().Get("Sunset"))
+}
+
+// TestDeprecated tests marking single routes as deprecated
+func TestDeprecated(t *testing.T) {
+	router := setupTestRouter()
+	router.GET("/api/pois/search", Deprecated(Deprecation{Successor: "/api/v2/pois/search"}), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/pois/search", nil))
+	assert.Equal(t, "true", w.Header().Get("Deprecation"))
+	assert.Empty(t, w.Header().Get("Sunset"))
+	assert.Equal(t, `</api/v2/pois/search>; rel="successor-version"`, w.Header().Get("Link"))
+}
+
+func TestParseSunset(t *testing.T) {
+	day, err := ParseSunset("2027-01-31")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2027, 1, 31, 0, 0, 0, 0, time.UTC), day)
+
+	timestamp, err := ParseSunset("2027-01-31T12:00:00Z")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2027, 1, 31, 12, 0, 0, 0, time.UTC), timestamp)
+
+	_, err = ParseSunset("next year")
+	assert.Error(t, err)
+}
@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"context"
+	"crypto/subtle"
 	"net/http"
 	"strings"
 
@@ -20,6 +22,29 @@ type UserService interface {
 	GetUser(c *gin.Context, userID string) (*models.User, error)
 }
 
+// SessionService interface for resolving session-scoped requests
+type SessionService interface {
+	GetSession(ctx context.Context, sessionID string) (*models.Session, error)
+}
+
+// SessionTokenVerifier verifies the secret tokens that prove a client owns a
+// session, since session IDs are known to everyone on the map
+type SessionTokenVerifier interface {
+	VerifySessionToken(sessionID, token string) bool
+}
+
+// SessionHeader carries the session ID of guests without a JWT
+const SessionHeader = "X-Session-ID"
+
+// SessionTokenHeader carries the token of the session in SessionHeader
+const SessionTokenHeader = "X-Session-Token"
+
+// SessionQueryParam carries the session ID of WebSocket upgrades
+const SessionQueryParam = "sessionId"
+
+// SessionTokenQueryParam carries the token of the session of WebSocket upgrades
+const SessionTokenQueryParam = "sessionToken"
+
 // RequireAuth middleware validates JWT token and sets user info in context
 func RequireAuth(authService AuthService) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -69,6 +94,7 @@ func RequireAuth(authService AuthService) gin.HandlerFunc {
 		c.Set("userID", claims.UserID)
 		c.Set("email", claims.Email)
 		c.Set("role", claims.Role)
+		c.Set("authSessionID", claims.SessionID)
 
 		c.Next()
 	}
@@ -213,6 +239,181 @@ func OptionalAuth(authService AuthService) gin.HandlerFunc {
 		c.Set("userID", claims.UserID)
 		c.Set("email", claims.Email)
 		c.Set("role", claims.Role)
+		c.Set("authSessionID", claims.SessionID)
+
+		c.Next()
+	}
+}
+
+// RequireIdentity middleware resolves the acting user of a request from a JWT
+// or, for guests, an active session in the X-Session-ID header proven by its
+// token in the X-Session-Token header. Handlers use the "userID" context value
+// instead of trusting user IDs in the request body.
+func RequireIdentity(authService AuthService, sessionService SessionService, sessionTokens SessionTokenVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Already resolved by an earlier auth middleware
+		if c.GetString("userID") != "" {
+			c.Next()
+			return
+		}
+
+		if authHeader := c.GetHeader("Authorization"); authHeader != "" && authService != nil {
+			claims, ok := bearerClaims(c, authService, authHeader)
+			if !ok {
+				return
+			}
+
+			c.Set("userID", claims.UserID)
+			c.Set("email", claims.Email)
+			c.Set("role", claims.Role)
+			c.Set("authSessionID", claims.SessionID)
+			c.Next()
+			return
+		}
+
+		sessionID := c.GetHeader(SessionHeader)
+		if sessionID == "" || sessionService == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"code":    "UNAUTHORIZED",
+				"message": "Authorization token or session required",
+			})
+			c.Abort()
+			return
+		}
+
+		session, ok := ownedSession(c, sessionService, sessionTokens, sessionID, c.GetHeader(SessionTokenHeader))
+		if !ok {
+			return
+		}
+
+		c.Set("userID", session.UserID)
+		c.Set("sessionID", session.ID)
+
+		c.Next()
+	}
+}
+
+// RequireSession middleware resolves the active session a request acts in from
+// the X-Session-ID and X-Session-Token headers or, for WebSocket upgrades that
+// browsers can't add headers to, the sessionId and sessionToken query
+// parameters. A JWT in the Authorization header must belong to the session's
+// user and adds the user's role. Handlers read the "session", "sessionID",
+// "userID" and, with a JWT, "role" context values.
+func RequireSession(authService AuthService, sessionService SessionService, sessionTokens SessionTokenVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Already resolved by an earlier auth middleware
+		if _, exists := c.Get("session"); exists {
+			c.Next()
+			return
+		}
+
+		sessionID, token := c.GetHeader(SessionHeader), c.GetHeader(SessionTokenHeader)
+		if sessionID == "" {
+			sessionID, token = c.Query(SessionQueryParam), c.Query(SessionTokenQueryParam)
+		}
+		if sessionID == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"code":    "UNAUTHORIZED",
+				"message": "Session required",
+			})
+			c.Abort()
+			return
+		}
+
+		session, ok := ownedSession(c, sessionService, sessionTokens, sessionID, token)
+		if !ok {
+			return
+		}
+
+		if authHeader := c.GetHeader("Authorization"); authHeader != "" && authService != nil {
+			claims, ok := bearerClaims(c, authService, authHeader)
+			if !ok {
+				return
+			}
+			if claims.UserID != session.UserID {
+				c.JSON(http.StatusForbidden, gin.H{
+					"code":    "SESSION_MISMATCH",
+					"message": "Session belongs to another user",
+				})
+				c.Abort()
+				return
+			}
+
+			c.Set("email", claims.Email)
+			c.Set("role", claims.Role)
+			c.Set("authSessionID", claims.SessionID)
+		}
+
+		c.Set("userID", session.UserID)
+		c.Set("sessionID", session.ID)
+		c.Set("session", session)
+
+		c.Next()
+	}
+}
+
+// ownedSession returns the active session the token proves the client owns.
+// Otherwise the request is answered with 401 and aborted.
+func ownedSession(c *gin.Context, sessionService SessionService, sessionTokens SessionTokenVerifier, sessionID, token string) (*models.Session, bool) {
+	if sessionTokens == nil || !sessionTokens.VerifySessionToken(sessionID, token) {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    "INVALID_SESSION_TOKEN",
+			"message": "Session token missing or invalid",
+		})
+		c.Abort()
+		return nil, false
+	}
+
+	session, err := sessionService.GetSession(c.Request.Context(), sessionID)
+	if err != nil || session == nil || !session.IsActive {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    "INVALID_SESSION",
+			"message": "Session not found or expired",
+		})
+		c.Abort()
+		return nil, false
+	}
+	return session, true
+}
+
+// bearerClaims validates the JWT of a Bearer Authorization header. Invalid
+// headers and tokens are answered with 401 and abort the request.
+func bearerClaims(c *gin.Context, authService AuthService, authHeader string) (*services.JWTClaims, bool) {
+	parts := strings.SplitN(authHeader, " ", 2)
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    "INVALID_TOKEN_FORMAT",
+			"message": "Authorization header must be in format: Bearer <token>",
+		})
+		c.Abort()
+		return nil, false
+	}
+
+	claims, err := authService.ValidateJWT(parts[1])
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"code":    "INVALID_TOKEN",
+			"message": "Invalid authentication token",
+		})
+		c.Abort()
+		return nil, false
+	}
+	return claims, true
+}
+
+// RequireStaticToken middleware only lets requests with the given bearer token
+// through, for endpoints scraped by infrastructure like Prometheus
+func RequireStaticToken(token string) gin.HandlerFunc {
+	expected := []byte("Bearer " + token)
+	return func(c *gin.Context) {
+		if subtle.ConstantTimeCompare([]byte(c.GetHeader("Authorization")), expected) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"code":    "INVALID_TOKEN",
+				"message": "Invalid or missing token",
+			})
+			c.Abort()
+			return
+		}
 
 		c.Next()
 	}
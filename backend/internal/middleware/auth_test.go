@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -46,6 +47,9 @@ func setupTestRouter() *gin.Engine {
 	return gin.New()
 }
 
+// testSessionTokens signs the session tokens of the session tests
+var testSessionTokens = services.NewSessionTokens([]byte("secret"))
+
 // Helper to create valid JWT claims
 func createTestClaims(userID, email string, role models.UserRole) *services.JWTClaims {
 	return &services.JWTClaims{
@@ -535,3 +539,203 @@ func TestAuthMiddleware_Integration(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "user-123")
 	assert.Contains(t, w.Body.String(), "test@example.com")
 }
+
+// MockSessionService for testing
+type MockSessionService struct {
+	mock.Mock
+}
+
+func (m *MockSessionService) GetSession(ctx context.Context, sessionID string) (*models.Session, error) {
+	args := m.Called(ctx, sessionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Session), args.Error(1)
+}
+
+// TestRequireIdentity_JWT tests identity resolution from a JWT
+func TestRequireIdentity_JWT(t *testing.T) {
+	mockAuthService := &MockAuthService{}
+	mockSessionService := &MockSessionService{}
+	router := setupTestRouter()
+
+	claims := createTestClaims("user-123", "test@example.com", models.UserRoleUser)
+	mockAuthService.On("ValidateJWT", "valid-token").Return(claims, nil)
+
+	router.POST("/act", RequireIdentity(mockAuthService, mockSessionService, testSessionTokens), func(c *gin.Context) {
+		assert.Equal(t, "user-123", c.GetString("userID"))
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("POST", "/act", nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	// The session header is ignored when a JWT is present
+	req.Header.Set(SessionHeader, "session-other")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockSessionService.AssertNotCalled(t, "GetSession", mock.Anything, mock.Anything)
+}
+
+// TestRequireIdentity_Session tests identity resolution from a guest session
+func TestRequireIdentity_Session(t *testing.T) {
+	mockSessionService := &MockSessionService{}
+	router := setupTestRouter()
+
+	mockSessionService.On("GetSession", mock.Anything, "session-1").Return(&models.Session{
+		ID:       "session-1",
+		UserID:   "guest-1",
+		IsActive: true,
+	}, nil)
+	mockSessionService.On("GetSession", mock.Anything, "session-ended").Return(&models.Session{
+		ID:       "session-ended",
+		UserID:   "guest-2",
+		IsActive: false,
+	}, nil)
+
+	router.POST("/act", RequireIdentity(&MockAuthService{}, mockSessionService, testSessionTokens), func(c *gin.Context) {
+		assert.Equal(t, "guest-1", c.GetString("userID"))
+		assert.Equal(t, "session-1", c.GetString("sessionID"))
+		c.Status(http.StatusOK)
+	})
+
+	serve := func(sessionID, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/act", nil)
+		req.Header.Set(SessionHeader, sessionID)
+		req.Header.Set(SessionTokenHeader, token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, serve("session-1", testSessionTokens.Issue("session-1")).Code)
+
+	w := serve("session-ended", testSessionTokens.Issue("session-ended"))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "INVALID_SESSION")
+
+	// Session IDs are public, so they don't act for their user without the token
+	for _, token := range []string{"", "session-1", testSessionTokens.Issue("session-ended")} {
+		w = serve("session-1", token)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "INVALID_SESSION_TOKEN")
+	}
+	mockSessionService.AssertNumberOfCalls(t, "GetSession", 2)
+}
+
+// TestRequireIdentity_Missing tests that anonymous requests are rejected
+func TestRequireIdentity_Missing(t *testing.T) {
+	router := setupTestRouter()
+
+	router.POST("/act", RequireIdentity(&MockAuthService{}, &MockSessionService{}, testSessionTokens), func(c *gin.Context) {
+		t.Fatal("handler should not be reached")
+	})
+
+	req := httptest.NewRequest("POST", "/act", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+// TestRequireSession tests session resolution for REST requests and WebSocket upgrades
+func TestRequireSession(t *testing.T) {
+	mockAuthService := &MockAuthService{}
+	mockSessionService := &MockSessionService{}
+	router := setupTestRouter()
+
+	mockSessionService.On("GetSession", mock.Anything, "session-1").Return(&models.Session{
+		ID:       "session-1",
+		UserID:   "user-123",
+		MapID:    "map-1",
+		IsActive: true,
+	}, nil)
+	mockAuthService.On("ValidateJWT", "admin-token").Return(createTestClaims("user-123", "admin@example.com", models.UserRoleAdmin), nil)
+	mockAuthService.On("ValidateJWT", "other-token").Return(createTestClaims("user-456", "other@example.com", models.UserRoleAdmin), nil)
+
+	router.GET("/ws", RequireSession(mockAuthService, mockSessionService, testSessionTokens), func(c *gin.Context) {
+		session, _ := c.Get("session")
+		require.IsType(t, &models.Session{}, session)
+		assert.Equal(t, "map-1", session.(*models.Session).MapID)
+		assert.Equal(t, "user-123", c.GetString("userID"))
+		assert.Equal(t, "session-1", c.GetString("sessionID"))
+		role, _ := c.Get("role")
+		c.String(http.StatusOK, "%v", role)
+	})
+
+	serve := func(path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	owned := "/ws?sessionId=session-1&sessionToken=" + testSessionTokens.Issue("session-1")
+
+	// Guests connect with the session alone
+	w := serve(owned, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "<nil>", w.Body.String())
+
+	// A JWT of the session's user adds the role
+	w = serve(owned, "admin-token")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "admin", w.Body.String())
+
+	w = serve(owned, "other-token")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "SESSION_MISMATCH")
+
+	w = serve("/ws", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// The session ID alone, even as bearer token, isn't enough
+	w = serve("/ws?sessionId=session-1", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = serve("/ws", "session-1")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+// TestRequireSession_AlreadyResolved tests that sessions resolved earlier in the chain are kept
+func TestRequireSession_AlreadyResolved(t *testing.T) {
+	router := setupTestRouter()
+	session := &models.Session{ID: "session-1", UserID: "user-123", IsActive: true}
+
+	router.GET("/ws", func(c *gin.Context) {
+		c.Set("session", session)
+		c.Next()
+	}, RequireSession(&MockAuthService{}, &MockSessionService{}, testSessionTokens), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/ws", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRequireStaticToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/metrics", RequireStaticToken("secret"), func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	for header, expected := range map[string]int{
+		"Bearer secret": http.StatusOK,
+		"Bearer wrong":  http.StatusUnauthorized,
+		"":              http.StatusUnauthorized,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, expected, w.Code, header)
+	}
+}
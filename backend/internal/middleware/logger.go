@@ -2,10 +2,11 @@ package middleware
 
 import (
 	"log/slog"
-	"net"
 	"strings"
 	"time"
 
+	"breakoutglobe/internal/logexport"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -116,27 +117,9 @@ func RequestLogger(logger *slog.Logger) gin.HandlerFunc {
 }
 
 // getClientIP extracts the real client IP from the request
+// Forwarded headers are only honored for the proxies trusted by the router
 func getClientIP(c *gin.Context) string {
-	// Check X-Forwarded-For header first
-	if xff := c.GetHeader("X-Forwarded-For"); xff != "" {
-		// X-Forwarded-For can contain multiple IPs, take the first one
-		ips := strings.Split(xff, ",")
-		if len(ips) > 0 {
-			return strings.TrimSpace(ips[0])
-		}
-	}
-	
-	// Check X-Real-IP header
-	if xri := c.GetHeader("X-Real-IP"); xri != "" {
-		return xri
-	}
-	
-	// Fall back to RemoteAddr
-	ip, _, err := net.SplitHostPort(c.Request.RemoteAddr)
-	if err != nil {
-		return c.Request.RemoteAddr
-	}
-	return ip
+	return c.ClientIP()
 }
 
 // filterSensitiveData removes or masks sensitive information from headers
@@ -227,4 +210,35 @@ func RequestLoggerWithConfig(config LoggerConfig) gin.HandlerFunc {
 		// Use the main logger middleware
 		RequestLogger(logger)(c)
 	}
-}
\ No newline at end of file
+}
+// AccessLogExporter receives structured log entries for export
+type AccessLogExporter interface {
+	Export(category string, fields map[string]interface{})
+}
+
+// ExportAccessLog exports every request to the configured access log sink.
+// Query strings are left out since they may carry tokens.
+func ExportAccessLog(exporter AccessLogExporter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		
+		c.Next()
+		
+		fields := map[string]interface{}{
+			"method":      c.Request.Method,
+			"path":        c.Request.URL.Path,
+			"status":      c.Writer.Status(),
+			"duration_ms": float64(time.Since(start).Nanoseconds()) / 1e6,
+			"client_ip":   getClientIP(c),
+			"user_agent":  c.Request.UserAgent(),
+		}
+		if requestID := c.GetString("requestID"); requestID != "" {
+			fields["request_id"] = requestID
+		}
+		if userID := c.GetString("userID"); userID != "" {
+			fields["user_id"] = userID
+		}
+		
+		exporter.Export(logexport.CategoryAccess, fields)
+	}
+}
@@ -376,4 +376,34 @@ func TestFilterSensitiveData(t *testing.T) {
 			assert.Equal(t, tt.expected, result)
 		})
 	}
-}
\ No newline at end of file
+}
+type recordingExporter struct {
+	category string
+	fields   map[string]interface{}
+}
+
+func (e *recordingExporter) Export(category string, fields map[string]interface{}) {
+	e.category = category
+	e.fields = fields
+}
+
+func TestExportAccessLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	exporter := &recordingExporter{}
+	router := gin.New()
+	router.Use(ExportAccessLog(exporter))
+	router.GET("/api/maps", func(c *gin.Context) {
+		c.Set("userID", "user-1")
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/maps?token=secret", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, "access", exporter.category)
+	assert.Equal(t, "/api/maps", exporter.fields["path"])
+	assert.Equal(t, http.StatusNoContent, exporter.fields["status"])
+	assert.Equal(t, "user-1", exporter.fields["user_id"])
+	assert.NotContains(t, exporter.fields, "query")
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maintenanceRetryAfter is the Retry-After sent with rejected writes, as the
+// end of maintenance isn't known up front
+const maintenanceRetryAfter = "60"
+
+// MaintenanceChecker reports whether the server is in maintenance mode
+type MaintenanceChecker interface {
+	Status() services.Maintenance
+}
+
+// Maintenance rejects write requests with 503 MAINTENANCE while the server
+// is in maintenance mode. Reads keep working, and so do the exempt paths, like
+// logging in and turning maintenance off again.
+func Maintenance(checker MaintenanceChecker, exemptPaths ...string) gin.HandlerFunc {
+	exempt := make(map[string]bool, len(exemptPaths))
+	for _, path := range exemptPaths {
+		exempt[path] = true
+	}
+
+	return func(c *gin.Context) {
+		if checker == nil || !isWriteMethod(c.Request.Method) || exempt[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		maintenance := checker.Status()
+		if !maintenance.Enabled {
+			c.Next()
+			return
+		}
+
+		c.Header("Retry-After", maintenanceRetryAfter)
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, ErrorResponse{
+			Code:      "MAINTENANCE",
+			Message:   "The server is in maintenance mode, changes are not possible right now",
+			Details:   maintenance.Message,
+			RequestID: c.GetString("requestID"),
+			Timestamp: time.Now(),
+		})
+	}
+}
+
+// isWriteMethod reports whether requests of a method may change data
+func isWriteMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type staticMaintenance services.Maintenance
+
+func (m staticMaintenance) Status() services.Maintenance {
+	return services.Maintenance(m)
+}
+
+func setupMaintenanceRouter(checker MaintenanceChecker) *gin.Engine {
+	router := setupTestRouter()
+	router.Use(Maintenance(checker, "/api/admin/maintenance"))
+	ok := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"message": "success"}) }
+	router.GET("/api/pois", ok)
+	router.POST("/api/pois", ok)
+	router.DELETE("/api/pois/:id", ok)
+	router.PUT("/api/admin/maintenance", ok)
+	return router
+}
+
+// TestMaintenance_RejectsWrites tests that writes are rejected while reads keep working
+func TestMaintenance_RejectsWrites(t *testing.T) {
+	router := setupMaintenanceRouter(staticMaintenance{Enabled: true, Message: "Back in 10 minutes"})
+
+	for _, method := range []string{http.MethodPost, http.MethodDelete} {
+		path := "/api/pois"
+		if method == http.MethodDelete {
+			path = "/api/pois/poi-1"
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code, method)
+		assert.Contains(t, w.Body.String(), `"code":"MAINTENANCE"`)
+		assert.Contains(t, w.Body.String(), "Back in 10 minutes")
+		assert.Equal(t, maintenanceRetryAfter, w.Header().Get("Retry-After"))
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/pois", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// TestMaintenance_ExemptPaths tests that exempt paths can still be written
+func TestMaintenance_ExemptPaths(t *testing.T) {
+	router := setupMaintenanceRouter(staticMaintenance{Enabled: true})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/api/admin/maintenance", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+// TestMaintenance_Disabled tests that writes pass outside of maintenance
+func TestMaintenance_Disabled(t *testing.T) {
+	for _, checker := range []MaintenanceChecker{staticMaintenance{}, nil} {
+		router := setupMaintenanceRouter(checker)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/pois", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+}
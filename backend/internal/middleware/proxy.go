@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// forwardedHeaders are the proxy headers only honored from trusted proxies
+var forwardedHeaders = []string{
+	"Forwarded",
+	"X-Forwarded-For",
+	"X-Forwarded-Host",
+	"X-Forwarded-Proto",
+	"X-Forwarded-Port",
+	"X-Real-IP",
+}
+
+// ParseTrustedProxies parses a list of CIDRs or single IPs into networks
+func ParseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+
+		// Single IPs are treated as /32 (or /128 for IPv6)
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy IP: %s", proxy)
+			}
+			if ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy CIDR: %s", proxy)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// TrustedProxyHeaders returns a middleware that strips X-Forwarded-* headers from
+// requests that don't come from a trusted proxy, so the client IP, scheme, host and
+// WebSocket upgrade handling can't be spoofed by direct clients
+func TrustedProxyHeaders(trustedProxies []*net.IPNet) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isTrustedProxy(c.RemoteIP(), trustedProxies) {
+			for _, header := range forwardedHeaders {
+				c.Request.Header.Del(header)
+			}
+		}
+		c.Next()
+	}
+}
+
+// RequestScheme returns the scheme the client used to reach the server.
+// X-Forwarded-Proto is only present here if it came from a trusted proxy.
+func RequestScheme(c *gin.Context) string {
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+		// Proxies may append values, the first one is the client-facing scheme
+		return strings.ToLower(strings.TrimSpace(strings.Split(proto, ",")[0]))
+	}
+	if c.Request.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// RequestHost returns the host the client used to reach the server
+func RequestHost(c *gin.Context) string {
+	if host := c.GetHeader("X-Forwarded-Host"); host != "" {
+		return strings.TrimSpace(strings.Split(host, ",")[0])
+	}
+	return c.Request.Host
+}
+
+// AbsoluteURL builds an absolute URL for path as seen by the client
+func AbsoluteURL(c *gin.Context, path string) string {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return fmt.Sprintf("%s://%s%s", RequestScheme(c), RequestHost(c), path)
+}
+
+// isTrustedProxy checks if an IP address belongs to one of the trusted networks
+func isTrustedProxy(remoteIP string, trustedProxies []*net.IPNet) bool {
+	ip := net.ParseIP(remoteIP)
+	if ip == nil {
+		return false
+	}
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	networks, err := ParseTrustedProxies([]string{"10.0.0.0/8", "127.0.0.1", "::1", " "})
+	require.NoError(t, err)
+	assert.Len(t, networks, 3)
+
+	_, err = ParseTrustedProxies([]string{"not-an-ip"})
+	assert.Error(t, err)
+
+	_, err = ParseTrustedProxies([]string{"10.0.0.0/99"})
+	assert.Error(t, err)
+}
+
+func TestTrustedProxyHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	trusted, err := ParseTrustedProxies([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		remoteAddr    string
+		expectedIP    string
+		expectedHost  string
+		expectedProto string
+	}{
+		{
+			name:          "trusted proxy headers are honored",
+			remoteAddr:    "10.1.2.3:4567",
+			expectedIP:    "198.51.100.7",
+			expectedHost:  "globe.example.com",
+			expectedProto: "https",
+		},
+		{
+			name:          "untrusted client headers are stripped",
+			remoteAddr:    "203.0.113.5:4567",
+			expectedIP:    "203.0.113.5",
+			expectedHost:  "",
+			expectedProto: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			require.NoError(t, router.SetTrustedProxies([]string{"10.0.0.0/8"}))
+			router.Use(TrustedProxyHeaders(trusted))
+
+			var clientIP, host, proto string
+			router.GET("/test", func(c *gin.Context) {
+				clientIP = c.ClientIP()
+				host = c.GetHeader("X-Forwarded-Host")
+				proto = c.GetHeader("X-Forwarded-Proto")
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.Host = "internal:8080"
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-Proto", "https")
+			req.Header.Set("X-Forwarded-For", "198.51.100.7")
+			req.Header.Set("X-Forwarded-Host", "globe.example.com")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.expectedIP, clientIP)
+			assert.Equal(t, tt.expectedHost, host)
+			assert.Equal(t, tt.expectedProto, proto)
+		})
+	}
+}
+
+func TestAbsoluteURL(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	trusted, err := ParseTrustedProxies([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		remoteAddr  string
+		expectedURL string
+	}{
+		{"behind a trusted proxy", "10.1.2.3:4567", "https://globe.example.com/uploads/a.png"},
+		{"direct client", "203.0.113.5:4567", "http://internal:8080/uploads/a.png"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(TrustedProxyHeaders(trusted))
+
+			var absoluteURL string
+			router.GET("/test", func(c *gin.Context) {
+				absoluteURL = AbsoluteURL(c, "uploads/a.png")
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.Host = "internal:8080"
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-Proto", "https")
+			req.Header.Set("X-Forwarded-Host", "globe.example.com")
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.expectedURL, absoluteURL)
+		})
+	}
+}
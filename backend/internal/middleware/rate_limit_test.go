@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// TestRateLimit_AllowedSetsHeaders tests that allowed requests reach the handler with headers
+func TestRateLimit_AllowedSetsHeaders(t *testing.T) {
+	mockRateLimiter := &services.MockRateLimiter{}
+	router := setupTestRouter()
+
+	mockRateLimiter.On("CheckRateLimit", mock.Anything, "user-123", services.ActionJoinPOI).Return(nil)
+	mockRateLimiter.On("GetRateLimitHeaders", mock.Anything, "user-123", services.ActionJoinPOI).Return(map[string]string{
+		"X-RateLimit-Limit":     "20",
+		"X-RateLimit-Remaining": "19",
+	}, nil)
+
+	router.POST("/join", RateLimit(mockRateLimiter, services.ActionJoinPOI, RateLimitByUser), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"message": "success"})
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/join", nil)
+	req.Header.Set("X-User-ID", "user-123")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "20", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "19", w.Header().Get("X-RateLimit-Remaining"))
+	mockRateLimiter.AssertExpectations(t)
+}
+
+// TestRateLimit_Exceeded tests that limited requests are rejected before the handler
+func TestRateLimit_Exceeded(t *testing.T) {
+	mockRateLimiter := &services.MockRateLimiter{}
+	router := setupTestRouter()
+
+	rateLimitErr := &services.RateLimitError{
+		UserID:     "ip:192.0.2.1",
+		Action:     services.ActionCreatePOI,
+		Limit:      5,
+		Window:     time.Hour,
+		RetryAfter: 90 * time.Second,
+	}
+	mockRateLimiter.On("CheckRateLimit", mock.Anything, "ip:192.0.2.1", services.ActionCreatePOI).Return(rateLimitErr)
+
+	handlerCalled := false
+	router.POST("/pois", RateLimit(mockRateLimiter, services.ActionCreatePOI, RateLimitByUser), func(c *gin.Context) {
+		handlerCalled = true
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/pois", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "90", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "RATE_LIMIT_EXCEEDED")
+	assert.False(t, handlerCalled)
+	mockRateLimiter.AssertExpectations(t)
+}
+
+// TestRateLimit_NilLimiter tests that routes without a limiter are not limited
+func TestRateLimit_NilLimiter(t *testing.T) {
+	router := setupTestRouter()
+	router.POST("/pois", RateLimit(nil, services.ActionCreatePOI, RateLimitByUser), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/pois", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+// TestRateLimitByField_PreservesBody tests that the key is read from the body without consuming it
+func TestRateLimitByField_PreservesBody(t *testing.T) {
+	mockRateLimiter := &services.MockRateLimiter{}
+	router := setupTestRouter()
+
+	mockRateLimiter.On("CheckRateLimit", mock.Anything, "login:test@example.com", services.ActionCreatePOI).Return(nil)
+	mockRateLimiter.On("GetRateLimitHeaders", mock.Anything, "login:test@example.com", services.ActionCreatePOI).Return(map[string]string{}, nil)
+
+	body := `{"email":"test@example.com","password":"Password123!"}`
+	router.POST("/login", RateLimit(mockRateLimiter, services.ActionCreatePOI, RateLimitByField("login:", "email")), func(c *gin.Context) {
+		received, err := io.ReadAll(c.Request.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, body, string(received))
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockRateLimiter.AssertExpectations(t)
+}
+
+// TestRateLimitByUser_PrefersResolvedIdentity tests the key precedence of RateLimitByUser
+func TestRateLimitByUser_PrefersResolvedIdentity(t *testing.T) {
+	router := setupTestRouter()
+	router.GET("/key", func(c *gin.Context) {
+		c.Set("userID", "user-from-token")
+		c.String(http.StatusOK, RateLimitByUser(c))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/key", nil)
+	req.Header.Set("X-User-ID", "user-from-header")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, "user-from-token", w.Body.String())
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuthSession is a sign-in of a full account on one device. Tokens issued for
+// the sign-in carry the session ID and stop working when it is revoked.
+type AuthSession struct {
+	ID         string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	UserID     string     `json:"userId" gorm:"index;type:varchar(36);not null"`
+	User       *User      `json:"-" gorm:"foreignKey:UserID;references:ID"`
+	UserAgent  string     `json:"userAgent" gorm:"type:varchar(512)"`
+	IPAddress  string     `json:"ipAddress" gorm:"type:varchar(45)"`
+	Lat        *float64   `json:"lat,omitempty"` // Approximate location of the IP address, if known
+	Lng        *float64   `json:"lng,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt" gorm:"not null"`
+	LastSeenAt time.Time  `json:"lastSeenAt" gorm:"not null"`
+	ExpiresAt  time.Time  `json:"expiresAt" gorm:"index;not null"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+// NewAuthSession creates a session for a sign-in with a generated ID
+func NewAuthSession(userID, userAgent, ipAddress string, expiresAt time.Time) (*AuthSession, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("user ID is required")
+	}
+
+	now := time.Now()
+	return &AuthSession{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		UserAgent:  userAgent,
+		IPAddress:  ipAddress,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  expiresAt,
+	}, nil
+}
+
+// IsActive reports whether tokens of the session are still accepted at the given time
+func (s *AuthSession) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// Location returns the approximate location of the session, if known
+func (s *AuthSession) Location() (LatLng, bool) {
+	if s.Lat == nil || s.Lng == nil {
+		return LatLng{}, false
+	}
+	return LatLng{Lat: *s.Lat, Lng: *s.Lng}, true
+}
+
+// SetLocation stores the approximate location of the session
+func (s *AuthSession) SetLocation(location LatLng) {
+	s.Lat = &location.Lat
+	s.Lng = &location.Lng
+}
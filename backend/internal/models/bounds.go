@@ -1,6 +1,9 @@
 package models
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+)
 
 // Bounds represents a rectangular geographic area defined by north, south, east, and west coordinates
 type Bounds struct {
@@ -57,6 +60,45 @@ func (b Bounds) Contains(point LatLng) bool {
 	return point.Lng >= b.West && point.Lng <= b.East
 }
 
+// Clamp returns the point inside these bounds that is closest to the given point
+func (b Bounds) Clamp(point LatLng) LatLng {
+	clamped := LatLng{Lat: math.Max(b.South, math.Min(b.North, point.Lat)), Lng: point.Lng}
+	if b.Contains(LatLng{Lat: clamped.Lat, Lng: point.Lng}) {
+		return clamped
+	}
+	
+	// Snap to the nearer of the west and east edges, measured around the globe
+	if lngDistance(point.Lng, b.West) <= lngDistance(point.Lng, b.East) {
+		clamped.Lng = b.West
+	} else {
+		clamped.Lng = b.East
+	}
+	return clamped
+}
+
+// Center returns the center point of the bounds
+func (b Bounds) Center() LatLng {
+	lat := (b.North + b.South) / 2
+	if b.West > b.East {
+		// Bounds cross the international date line
+		lng := (b.West + b.East + 360) / 2
+		if lng > 180 {
+			lng -= 360
+		}
+		return LatLng{Lat: lat, Lng: lng}
+	}
+	return LatLng{Lat: lat, Lng: (b.West + b.East) / 2}
+}
+
+// lngDistance returns the angular distance between two longitudes in degrees
+func lngDistance(a, b float64) float64 {
+	d := math.Abs(a - b)
+	if d > 180 {
+		d = 360 - d
+	}
+	return d
+}
+
 // Area calculates the approximate area of the bounds in square kilometers
 func (b Bounds) Area() float64 {
 	// Simple approximation - not accounting for Earth's curvature
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBounds_Clamp(t *testing.T) {
+	europe := Bounds{North: 70, South: 35, East: 40, West: -10}
+
+	assert.Equal(t, LatLng{Lat: 50, Lng: 10}, europe.Clamp(LatLng{Lat: 50, Lng: 10}))
+	assert.Equal(t, LatLng{Lat: 35, Lng: 10}, europe.Clamp(LatLng{Lat: 0, Lng: 10}))
+	assert.Equal(t, LatLng{Lat: 70, Lng: 40}, europe.Clamp(LatLng{Lat: 80, Lng: 90}))
+	assert.Equal(t, LatLng{Lat: 40, Lng: -10}, europe.Clamp(LatLng{Lat: 40, Lng: -90}))
+
+	// Bounds crossing the date line snap to the nearer edge around the globe
+	pacific := Bounds{North: 10, South: -10, East: -170, West: 170}
+	assert.Equal(t, LatLng{Lat: 0, Lng: 179}, pacific.Clamp(LatLng{Lat: 0, Lng: 179}))
+	assert.Equal(t, LatLng{Lat: 0, Lng: 170}, pacific.Clamp(LatLng{Lat: 0, Lng: 150}))
+	assert.Equal(t, LatLng{Lat: 0, Lng: -170}, pacific.Clamp(LatLng{Lat: 0, Lng: -150}))
+}
+
+func TestBounds_Center(t *testing.T) {
+	assert.Equal(t, LatLng{Lat: 52.5, Lng: 15}, Bounds{North: 70, South: 35, East: 40, West: -10}.Center())
+	assert.Equal(t, LatLng{Lat: 0, Lng: 180}, Bounds{North: 10, South: -10, East: -170, West: 170}.Center())
+	assert.Equal(t, LatLng{Lat: 0, Lng: -175}, Bounds{North: 10, South: -10, East: -160, West: 170}.Center())
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ConnectionInfo describes a live WebSocket connection for admin listings
+type ConnectionInfo struct {
+	SessionID     string    `json:"sessionId"`
+	UserID        string    `json:"userId"`
+	MapID         string    `json:"mapId"`
+	ClientVersion string    `json:"clientVersion,omitempty"`
+	UserAgent     string    `json:"userAgent,omitempty"`
+	ConnectedAt   time.Time `json:"connectedAt"`
+}
+
+// WebSocketStats describes the health of an instance's WebSocket hub. Totals
+// count since the instance started.
+type WebSocketStats struct {
+	Connections            int                 `json:"connections"`
+	QueuedMessages         int                 `json:"queuedMessages"`
+	BroadcastQueueDepth    int                 `json:"broadcastQueueDepth"`
+	BroadcastQueueCapacity int                 `json:"broadcastQueueCapacity"`
+	BroadcastsTotal        uint64              `json:"broadcastsTotal"`
+	BroadcastsPerSecond    float64             `json:"broadcastsPerSecond"` // over the last minute
+	DeliveredTotal         uint64              `json:"deliveredTotal"`
+	DroppedBroadcasts      uint64              `json:"droppedBroadcasts"`            // lost for all clients, the broadcast queue was full
+	DroppedDeliveries      uint64              `json:"droppedDeliveries"`            // lost for one client, its send queue was full
+	ShedMessages           uint64              `json:"shedMessages"`                 // transient broadcasts dropped to shed load
+	DegradedBroadcasts     *DegradedBroadcasts `json:"degradedBroadcasts,omitempty"` // set while broadcasts are degraded to shed load
+	Maps                   []MapWebSocketStats `json:"maps"`
+	CollectedAt            time.Time           `json:"collectedAt"`
+}
+
+// DegradedBroadcasts tells operators that the WebSocket hub sheds load: avatar
+// moves are coalesced over a longer window and transient messages are dropped
+type DegradedBroadcasts struct {
+	Reason             string    `json:"reason"` // broadcast_queue or cpu
+	Since              time.Time `json:"since"`
+	MovementIntervalMs int64     `json:"movementIntervalMs"`
+}
+
+// MapWebSocketStats describes the WebSocket connections of one map
+type MapWebSocketStats struct {
+	MapID             string `json:"mapId"`
+	Connections       int    `json:"connections"`
+	QueuedMessages    int    `json:"queuedMessages"`
+	MaxQueuedMessages int    `json:"maxQueuedMessages"` // of the most backed up client
+}
+
+// ConnectionError records an abnormal close of a WebSocket connection, so
+// reports of dropping connections can be matched to a cause
+type ConnectionError struct {
+	ID              string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	SessionID       string    `json:"sessionId" gorm:"index;type:varchar(36)"`
+	UserID          string    `json:"userId" gorm:"index;type:varchar(36)"`
+	MapID           string    `json:"mapId" gorm:"type:varchar(36)"`
+	CloseCode       int       `json:"closeCode"`
+	Reason          string    `json:"reason" gorm:"type:varchar(50)"` // e.g. idle_timeout
+	LastMessageType string    `json:"lastMessageType,omitempty" gorm:"type:varchar(50)"`
+	Error           string    `json:"error,omitempty" gorm:"type:varchar(512)"`
+	ClientVersion   string    `json:"clientVersion,omitempty" gorm:"type:varchar(50)"`
+	UserAgent       string    `json:"userAgent,omitempty" gorm:"type:varchar(256)"`
+	ConnectedAt     time.Time `json:"connectedAt"`
+	ClosedAt        time.Time `json:"closedAt" gorm:"index;not null"`
+}
+
+// MapEvent is a broadcast recorded in a map's event journal
+type MapEvent struct {
+	Seq       uint64          `json:"mapSeq"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	Timestamp time.Time       `json:"timestamp"`
+}
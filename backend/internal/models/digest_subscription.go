@@ -0,0 +1,89 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DigestFrequency is how often a map activity digest is sent
+type DigestFrequency string
+
+const (
+	DigestFrequencyDaily  DigestFrequency = "daily"
+	DigestFrequencyWeekly DigestFrequency = "weekly"
+)
+
+// Validate checks that the frequency is supported
+func (f DigestFrequency) Validate() error {
+	switch f {
+	case DigestFrequencyDaily, DigestFrequencyWeekly:
+		return nil
+	default:
+		return fmt.Errorf("invalid digest frequency %q: must be daily or weekly", f)
+	}
+}
+
+// Period returns the time covered by one digest
+func (f DigestFrequency) Period() time.Duration {
+	if f == DigestFrequencyWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// DigestSubscription subscribes a facilitator to activity digests of a map
+type DigestSubscription struct {
+	ID         string          `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	MapID      string          `json:"mapId" gorm:"uniqueIndex:idx_digest_subscriptions_map_user;type:varchar(36);not null"`
+	Map        *Map            `json:"-" gorm:"foreignKey:MapID;references:ID"`
+	UserID     string          `json:"userId" gorm:"uniqueIndex:idx_digest_subscriptions_map_user;type:varchar(36);not null"`
+	User       *User           `json:"-" gorm:"foreignKey:UserID;references:ID"`
+	Frequency  DigestFrequency `json:"frequency" gorm:"type:varchar(10);not null"`
+	LastSentAt *time.Time      `json:"lastSentAt,omitempty"`
+	CreatedAt  time.Time       `json:"createdAt" gorm:"not null"`
+	UpdatedAt  time.Time       `json:"updatedAt" gorm:"not null"`
+}
+
+// NewDigestSubscription creates a new digest subscription with a generated ID
+func NewDigestSubscription(mapID, userID string, frequency DigestFrequency) (*DigestSubscription, error) {
+	if mapID == "" {
+		return nil, fmt.Errorf("map ID is required")
+	}
+	if userID == "" {
+		return nil, fmt.Errorf("user ID is required")
+	}
+	if err := frequency.Validate(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &DigestSubscription{
+		ID:        uuid.New().String(),
+		MapID:     mapID,
+		UserID:    userID,
+		Frequency: frequency,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// IsDue reports whether the next digest should be sent at now
+func (s *DigestSubscription) IsDue(now time.Time) bool {
+	if s.LastSentAt == nil {
+		return true
+	}
+	return !now.Before(s.LastSentAt.Add(s.Frequency.Period()))
+}
+
+// TableName returns the table name for GORM
+func (DigestSubscription) TableName() string {
+	return "digest_subscriptions"
+}
+
+// MapActivity summarizes what happened in a map during a time range
+type MapActivity struct {
+	NewPOIs           []*POI
+	TotalParticipants int64
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDigestSubscription(t *testing.T) {
+	sub, err := NewDigestSubscription("map-1", "user-1", DigestFrequencyDaily)
+	require.NoError(t, err)
+	assert.NotEmpty(t, sub.ID)
+	assert.Nil(t, sub.LastSentAt)
+
+	_, err = NewDigestSubscription("map-1", "user-1", "monthly")
+	assert.Error(t, err)
+
+	_, err = NewDigestSubscription("", "user-1", DigestFrequencyDaily)
+	assert.Error(t, err)
+}
+
+func TestDigestSubscription_IsDue(t *testing.T) {
+	now := time.Now()
+	sub := &DigestSubscription{Frequency: DigestFrequencyWeekly}
+	assert.True(t, sub.IsDue(now))
+
+	sent := now.Add(-6 * 24 * time.Hour)
+	sub.LastSentAt = &sent
+	assert.False(t, sub.IsDue(now))
+	assert.True(t, sub.IsDue(now.Add(24*time.Hour)))
+}
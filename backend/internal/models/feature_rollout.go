@@ -0,0 +1,58 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Feature identifies a risky new feature that is rolled out gradually
+type Feature string
+
+const (
+	// FeatureBatchedMovement lets clients send several avatar positions in one avatar_move_batch message
+	FeatureBatchedMovement Feature = "batched_movement"
+)
+
+// KnownFeatures lists the features that can be rolled out
+var KnownFeatures = []Feature{FeatureBatchedMovement}
+
+// MaxRolloutAllowlist is the largest number of users a rollout can allowlist
+const MaxRolloutAllowlist = 500
+
+// IsKnown reports whether the feature can be rolled out
+func (f Feature) IsKnown() bool {
+	for _, known := range KnownFeatures {
+		if f == known {
+			return true
+		}
+	}
+	return false
+}
+
+// FeatureRollout configures which users get a feature: a percentage of all
+// users, chosen by a stable hash of their user ID, plus an allowlist
+type FeatureRollout struct {
+	Feature    Feature   `json:"feature"`
+	Percentage int       `json:"percentage"`
+	Allowlist  []string  `json:"allowlist"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// Validate checks that the feature is known and the percentage and allowlist are in range
+func (r FeatureRollout) Validate() error {
+	if !r.Feature.IsKnown() {
+		return fmt.Errorf("unknown feature %q", r.Feature)
+	}
+	if r.Percentage < 0 || r.Percentage > 100 {
+		return fmt.Errorf("percentage must be between 0 and 100")
+	}
+	if len(r.Allowlist) > MaxRolloutAllowlist {
+		return fmt.Errorf("allowlist must not contain more than %d users", MaxRolloutAllowlist)
+	}
+	for _, userID := range r.Allowlist {
+		if userID == "" {
+			return fmt.Errorf("allowlist must not contain empty user IDs")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaxGuestAnonymizationRetentionHours is the longest a map can keep the
+// identity of guests who left it
+const MaxGuestAnonymizationRetentionHours = 365 * 24
+
+// AnonymousDisplayName replaces the display name of anonymized guests
+const AnonymousDisplayName = "Anonymous participant"
+
+// GuestAnonymization configures whether guests who left a map are anonymized,
+// so workshops with external participants don't keep their identities around.
+// Anonymization applies to the guest account, so it shows on every map the
+// guest took part in.
+type GuestAnonymization struct {
+	Enabled bool `json:"enabled" gorm:"default:false"`
+	// RetentionHours is how long after their last session on the map ended
+	// guests keep their identity
+	RetentionHours int `json:"retentionHours" gorm:"default:0"`
+}
+
+// Validate checks that the retention window is in range
+func (g GuestAnonymization) Validate() error {
+	if g.RetentionHours < 0 || g.RetentionHours > MaxGuestAnonymizationRetentionHours {
+		return fmt.Errorf("guest anonymization retention must be between 0 and %d hours", MaxGuestAnonymizationRetentionHours)
+	}
+	return nil
+}
+
+// Cutoff returns the time before which guests' sessions must have ended for
+// them to be anonymized
+func (g GuestAnonymization) Cutoff(now time.Time) time.Time {
+	return now.Add(-time.Duration(g.RetentionHours) * time.Hour)
+}
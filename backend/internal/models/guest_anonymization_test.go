@@ -0,0 +1,43 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuestAnonymization_Validate(t *testing.T) {
+	assert.NoError(t, GuestAnonymization{}.Validate())
+	assert.NoError(t, GuestAnonymization{Enabled: true, RetentionHours: 72}.Validate())
+	assert.Error(t, GuestAnonymization{Enabled: true, RetentionHours: -1}.Validate())
+	assert.Error(t, GuestAnonymization{Enabled: true, RetentionHours: MaxGuestAnonymizationRetentionHours + 1}.Validate())
+}
+
+func TestGuestAnonymization_Cutoff(t *testing.T) {
+	now := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, now, GuestAnonymization{Enabled: true}.Cutoff(now))
+	assert.Equal(t, now.Add(-48*time.Hour), GuestAnonymization{Enabled: true, RetentionHours: 48}.Cutoff(now))
+}
+
+func TestUser_Anonymize(t *testing.T) {
+	user, err := NewGuestUser("Workshop Guest")
+	assert.NoError(t, err)
+	avatarURL := "https://example.com/avatar.png"
+	aboutMe := "Hi"
+	email := "guest@example.com"
+	user.AvatarURL = &avatarURL
+	user.AboutMe = &aboutMe
+	user.Email = &email
+	now := time.Now()
+
+	user.Anonymize(now)
+
+	assert.Equal(t, AnonymousDisplayName, user.DisplayName)
+	assert.Nil(t, user.AvatarURL)
+	assert.Nil(t, user.AboutMe)
+	assert.Nil(t, user.Email)
+	assert.Equal(t, &now, user.AnonymizedAt)
+	assert.NoError(t, user.Validate())
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// HeatmapCellSize is the edge length of a heatmap cell in degrees (about 1.1 km at the equator)
+const HeatmapCellSize = 0.01
+
+// HeatmapBucketSize is the time resolution of the aggregated heatmap
+const HeatmapBucketSize = time.Hour
+
+// MapHeatCell counts the avatar positions reported in one grid cell of a map
+// during one time bucket. Rows and columns are offsets from 0°,0° in cells.
+type MapHeatCell struct {
+	MapID       string    `json:"mapId" gorm:"primaryKey;type:varchar(36)"`
+	BucketStart time.Time `json:"bucketStart" gorm:"primaryKey"`
+	CellRow     int       `json:"cellRow" gorm:"primaryKey;autoIncrement:false"`
+	CellCol     int       `json:"cellCol" gorm:"primaryKey;autoIncrement:false"`
+	Count       int64     `json:"count" gorm:"not null;default:0"`
+}
+
+// TableName returns the table name for GORM
+func (MapHeatCell) TableName() string {
+	return "map_heat_cells"
+}
+
+// HeatCellTotal is the total count of a grid cell over a time range
+type HeatCellTotal struct {
+	CellRow int
+	CellCol int
+	Count   int64
+}
+
+// HeatCellFor returns the grid cell containing a position
+func HeatCellFor(position LatLng) (row, col int) {
+	return int(math.Floor(position.Lat / HeatmapCellSize)), int(math.Floor(position.Lng / HeatmapCellSize))
+}
+
+// HeatCellCenter returns the center of a grid cell
+func HeatCellCenter(row, col int) LatLng {
+	return LatLng{
+		Lat: (float64(row) + 0.5) * HeatmapCellSize,
+		Lng: (float64(col) + 0.5) * HeatmapCellSize,
+	}
+}
+
+// HeatmapBucketFor returns the start of the time bucket containing t
+func HeatmapBucketFor(t time.Time) time.Time {
+	return t.UTC().Truncate(HeatmapBucketSize)
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeatCellFor(t *testing.T) {
+	row, col := HeatCellFor(LatLng{Lat: 52.5201, Lng: 13.4051})
+	assert.Equal(t, 5252, row)
+	assert.Equal(t, 1340, col)
+
+	// Negative coordinates round down so cells don't straddle 0°
+	row, col = HeatCellFor(LatLng{Lat: -0.005, Lng: -0.015})
+	assert.Equal(t, -1, row)
+	assert.Equal(t, -2, col)
+}
+
+func TestHeatCellCenter(t *testing.T) {
+	center := HeatCellCenter(HeatCellFor(LatLng{Lat: 52.5201, Lng: 13.4051}))
+	assert.InDelta(t, 52.525, center.Lat, 1e-9)
+	assert.InDelta(t, 13.405, center.Lng, 1e-9)
+}
+
+func TestHeatmapBucketFor(t *testing.T) {
+	local := time.Date(2024, 5, 1, 12, 45, 10, 0, time.FixedZone("CEST", 2*60*60))
+	assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), HeatmapBucketFor(local))
+}
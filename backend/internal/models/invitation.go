@@ -0,0 +1,122 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// InvitationValidity is how long an invitation link can be used
+const InvitationValidity = 14 * 24 * time.Hour
+
+// Invitation invites an email address to a map. The invitee's guest profile is
+// created up front, so following the link lands them on the map with their
+// identity already set. Only a hash of the invitation token is stored.
+type Invitation struct {
+	ID         string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	MapID      string     `json:"mapId" gorm:"uniqueIndex:idx_invitations_map_email;type:varchar(36);not null"`
+	Map        *Map       `json:"-" gorm:"foreignKey:MapID;references:ID"`
+	Email      string     `json:"email" gorm:"uniqueIndex:idx_invitations_map_email;type:varchar(255);not null"`
+	UserID     string     `json:"userId" gorm:"index;type:varchar(36);not null"` // Pre-created guest profile
+	User       *User      `json:"-" gorm:"foreignKey:UserID;references:ID"`
+	InvitedBy  string     `json:"invitedBy" gorm:"type:varchar(36);not null"`
+	TokenHash  string     `json:"-" gorm:"uniqueIndex;type:varchar(64);not null"`
+	ExpiresAt  time.Time  `json:"expiresAt" gorm:"not null"`
+	SentAt     *time.Time `json:"sentAt,omitempty"`
+	AcceptedAt *time.Time `json:"acceptedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt" gorm:"not null"`
+	UpdatedAt  time.Time  `json:"updatedAt" gorm:"not null"`
+}
+
+// NewInvitation creates an invitation for an email address with a generated ID
+func NewInvitation(mapID, email, userID, invitedBy string) (*Invitation, error) {
+	if mapID == "" {
+		return nil, fmt.Errorf("map ID is required")
+	}
+	if email == "" {
+		return nil, fmt.Errorf("email is required")
+	}
+	if userID == "" {
+		return nil, fmt.Errorf("user ID is required")
+	}
+
+	now := time.Now()
+	return &Invitation{
+		ID:        uuid.New().String(),
+		MapID:     mapID,
+		Email:     email,
+		UserID:    userID,
+		InvitedBy: invitedBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// IssueToken generates a new invitation token valid from now, replacing any
+// previous token. The returned token is only sent to the invitee.
+func (i *Invitation) IssueToken(now time.Time) (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate invitation token: %w", err)
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	i.TokenHash = HashInvitationToken(token)
+	i.ExpiresAt = now.Add(InvitationValidity)
+	i.UpdatedAt = now
+	return token, nil
+}
+
+// IsExpired reports whether the invitation link can no longer be used
+func (i *Invitation) IsExpired(now time.Time) bool {
+	return !now.Before(i.ExpiresAt)
+}
+
+// HashInvitationToken returns the stored form of an invitation token
+func HashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// DisplayNameFromEmail derives a guest display name from the local part of an
+// email address, e.g. "jane.doe@example.com" becomes "Jane Doe"
+func DisplayNameFromEmail(email string) string {
+	local := email
+	if at := strings.LastIndex(email, "@"); at >= 0 {
+		local = email[:at]
+	}
+	if plus := strings.Index(local, "+"); plus >= 0 {
+		local = local[:plus]
+	}
+
+	words := strings.FieldsFunc(local, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		runes := []rune(strings.ToLower(word))
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+
+	name := strings.Join(words, " ")
+	for len(name) > 50 {
+		runes := []rune(name)
+		name = strings.TrimSpace(string(runes[:len(runes)-1]))
+	}
+	if ValidateDisplayName(name) != nil {
+		return "Guest " + strings.ToUpper(uuid.New().String()[:4])
+	}
+	return name
+}
+
+// TableName returns the table name for GORM
+func (Invitation) TableName() string {
+	return "invitations"
+}
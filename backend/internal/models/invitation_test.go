@@ -0,0 +1,48 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvitation_IssueToken(t *testing.T) {
+	invitation, err := NewInvitation("map-1", "jane@example.com", "user-1", "admin-1")
+	require.NoError(t, err)
+
+	now := time.Now()
+	token, err := invitation.IssueToken(now)
+	require.NoError(t, err)
+	assert.NotEmpty(t, token)
+	assert.Equal(t, HashInvitationToken(token), invitation.TokenHash)
+	assert.NotContains(t, invitation.TokenHash, token)
+
+	assert.False(t, invitation.IsExpired(now))
+	assert.True(t, invitation.IsExpired(now.Add(InvitationValidity)))
+
+	next, err := invitation.IssueToken(now)
+	require.NoError(t, err)
+	assert.NotEqual(t, token, next)
+}
+
+func TestDisplayNameFromEmail(t *testing.T) {
+	tests := map[string]string{
+		"jane.doe@example.com":       "Jane Doe",
+		"BOB_SMITH@example.com":      "Bob Smith",
+		"jane+workshop@example.com":  "Jane",
+		"müller-lüdenscheidt@web.de": "Müller Lüdenscheidt",
+	}
+	for email, expected := range tests {
+		assert.Equal(t, expected, DisplayNameFromEmail(email), email)
+	}
+
+	// Names too short to be valid fall back to a generated guest name
+	name := DisplayNameFromEmail("a@example.com")
+	assert.True(t, strings.HasPrefix(name, "Guest "), name)
+	assert.NoError(t, ValidateDisplayName(name))
+
+	assert.NoError(t, ValidateDisplayName(DisplayNameFromEmail(strings.Repeat("ab.", 40)+"@example.com")))
+}
@@ -16,6 +16,18 @@ type Map struct {
 	CreatedBy   string         `json:"createdBy" gorm:"index;type:varchar(36);not null"`
 	Creator     *User          `json:"creator,omitempty" gorm:"foreignKey:CreatedBy;references:ID"`
 	IsActive    bool           `json:"isActive" gorm:"default:true"`
+	Bounds      *Bounds        `json:"bounds,omitempty" gorm:"embedded;embeddedPrefix:bounds_"` // Optional area avatars spawn in
+	SpawnPoints []SpawnPoint   `json:"spawnPoints,omitempty" gorm:"type:jsonb;serializer:json"` // Optional areas new avatars are placed in
+	PersonalSpace PersonalSpace `json:"personalSpace" gorm:"embedded;embeddedPrefix:personal_space_"` // Optional minimum distance between avatars
+	SlowConsumer SlowConsumerPolicy `json:"slowConsumer" gorm:"embedded;embeddedPrefix:slow_consumer_"` // How clients that fall behind broadcasts are treated
+	PositionPrivacy PositionPrivacy `json:"positionPrivacy" gorm:"embedded;embeddedPrefix:position_privacy_"` // Optional coarse avatar positions for regular participants
+	ProfilePrivacy ProfilePrivacy `json:"profilePrivacy" gorm:"embedded;embeddedPrefix:profile_privacy_"` // Optional profile fields left out of presence payloads
+	GuestAnonymization GuestAnonymization `json:"guestAnonymization" gorm:"embedded;embeddedPrefix:guest_anonymization_"` // Optional scrubbing of guests who left
+	MessageAudit MessageAuditPolicy `json:"messageAudit" gorm:"embedded;embeddedPrefix:message_audit_"` // Optional sampling of WebSocket messages to the audit log
+	Discoverable bool           `json:"discoverable" gorm:"index;default:false"` // Listed in the public map directory
+	Tags         []string       `json:"tags,omitempty" gorm:"type:jsonb;serializer:json"` // Directory discovery tags
+	Locale       string         `json:"locale" gorm:"type:varchar(16);not null;default:'en'"` // Language of server-generated content
+	Deletion     MapDeletionRequest `json:"-" gorm:"embedded;embeddedPrefix:deletion_"` // Pending two-step deletion, if any
 	CreatedAt   time.Time      `json:"createdAt" gorm:"not null"`
 	UpdatedAt   time.Time      `json:"updatedAt" gorm:"not null"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"` // Soft delete support
@@ -30,6 +42,7 @@ func NewMap(name, description, createdBy string) (*Map, error) {
 		Description: description,
 		CreatedBy:   createdBy,
 		IsActive:    true,
+		Locale:      DefaultMapLocale,
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
@@ -63,6 +76,50 @@ func (m Map) Validate() error {
 		return fmt.Errorf("created at is required")
 	}
 
+	if m.Bounds != nil {
+		if err := m.Bounds.Validate(); err != nil {
+			return fmt.Errorf("invalid bounds: %w", err)
+		}
+	}
+
+	if err := ValidateSpawnPoints(m.SpawnPoints); err != nil {
+		return err
+	}
+
+	if err := m.PersonalSpace.Validate(); err != nil {
+		return err
+	}
+
+	if err := m.SlowConsumer.Validate(); err != nil {
+		return err
+	}
+
+	if err := m.PositionPrivacy.Validate(); err != nil {
+		return err
+	}
+
+	if err := m.GuestAnonymization.Validate(); err != nil {
+		return err
+	}
+
+	if err := m.MessageAudit.Validate(); err != nil {
+		return err
+	}
+
+	if m.Locale != "" {
+		normalized, err := NormalizeMapLocale(m.Locale)
+		if err != nil {
+			return err
+		}
+		if normalized != m.Locale {
+			return fmt.Errorf("locale %q must be given as %q", m.Locale, normalized)
+		}
+	}
+
+	if len(m.Tags) > MaxMapTags {
+		return fmt.Errorf("a map can have at most %d tags", MaxMapTags)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MapArchiveVersion is the version of the map archive format written by exports
+const MapArchiveVersion = 1
+
+// MaxArchivePOIs bounds the number of POIs a map archive can import
+const MaxArchivePOIs = 5000
+
+// MapArchive is a complete, ID-independent copy of a map's state used for
+// backups and for moving maps between environments
+type MapArchive struct {
+	Version    int                `json:"version"`
+	ExportedAt time.Time          `json:"exportedAt"`
+	SourceID   string             `json:"sourceId"` // ID of the exported map, for reference only
+	Map        MapArchiveSettings `json:"map"`
+	POIs       []MapArchivePOI    `json:"pois"`
+	Images     []MapArchiveImage  `json:"images"` // Manifest of the POI images referenced by the archive
+}
+
+// MapArchiveSettings holds the settings of an archived map
+type MapArchiveSettings struct {
+	Name               string             `json:"name"`
+	Description        string             `json:"description"`
+	Bounds             *Bounds            `json:"bounds,omitempty"`
+	SpawnPoints        []SpawnPoint       `json:"spawnPoints,omitempty"`
+	PersonalSpace      PersonalSpace      `json:"personalSpace"`
+	PositionPrivacy    PositionPrivacy    `json:"positionPrivacy"`
+	ProfilePrivacy     ProfilePrivacy     `json:"profilePrivacy"`
+	GuestAnonymization GuestAnonymization `json:"guestAnonymization"`
+}
+
+// MapArchivePOI is an archived POI. Its ID is only used to match the image
+// manifest and is replaced on import.
+type MapArchivePOI struct {
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	Description     string `json:"description"`
+	Position        LatLng `json:"position"`
+	MaxParticipants int    `json:"maxParticipants"`
+}
+
+// MapArchiveImage lists the image files of an archived POI. Images are
+// referenced by URL rather than embedded, so they must stay reachable from
+// the importing environment.
+type MapArchiveImage struct {
+	POIID        string `json:"poiId"`
+	ImageURL     string `json:"imageUrl"`
+	ThumbnailURL string `json:"thumbnailUrl,omitempty"`
+}
+
+// Validate checks that the archive can be imported
+func (a MapArchive) Validate() error {
+	if a.Version < 1 || a.Version > MapArchiveVersion {
+		return fmt.Errorf("unsupported archive version %d", a.Version)
+	}
+	if a.Map.Name == "" {
+		return fmt.Errorf("map name is required")
+	}
+	if len(a.POIs) > MaxArchivePOIs {
+		return fmt.Errorf("archive must not contain more than %d POIs", MaxArchivePOIs)
+	}
+
+	poiIDs := make(map[string]bool, len(a.POIs))
+	for _, poi := range a.POIs {
+		if poi.ID == "" {
+			return fmt.Errorf("POI ID is required")
+		}
+		if poiIDs[poi.ID] {
+			return fmt.Errorf("duplicate POI ID %s", poi.ID)
+		}
+		poiIDs[poi.ID] = true
+	}
+	for _, image := range a.Images {
+		if !poiIDs[image.POIID] {
+			return fmt.Errorf("image manifest references unknown POI %s", image.POIID)
+		}
+		if !isArchiveImageURL(image.ImageURL) || (image.ThumbnailURL != "" && !isArchiveImageURL(image.ThumbnailURL)) {
+			return fmt.Errorf("image URLs of POI %s must be http(s) URLs or absolute paths", image.POIID)
+		}
+	}
+	return nil
+}
+
+// isArchiveImageURL reports whether an image URL is safe to show to other users
+func isArchiveImageURL(url string) bool {
+	return strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "http://") ||
+		(strings.HasPrefix(url, "/") && !strings.HasPrefix(url, "//"))
+}
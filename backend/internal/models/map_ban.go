@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// MapBan keeps a user a facilitator kicked from a map from connecting to it
+// again until it expires
+type MapBan struct {
+	MapID    string    `json:"mapId"`
+	UserID   string    `json:"userId"`
+	BannedBy string    `json:"bannedBy"`
+	Until    time.Time `json:"until"`
+}
+
+// Active reports whether the ban still applies at the given time
+func (b MapBan) Active(now time.Time) bool {
+	return now.Before(b.Until)
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// MapDeletionCooldown is how long after a deletion request it can be
+	// confirmed, leaving time to notice and cancel an unwanted one
+	MapDeletionCooldown = 15 * time.Minute
+
+	// MapDeletionValidity is how long a deletion request can be confirmed
+	MapDeletionValidity = 24 * time.Hour
+)
+
+// MapDeletionRequest is the first step of deleting a map. The map is only
+// deleted when the requester confirms with the issued token after the
+// cool-down. Only a hash of the token is stored.
+type MapDeletionRequest struct {
+	RequestedBy   string    `json:"requestedBy" gorm:"type:varchar(36)"`
+	TokenHash     string    `json:"-" gorm:"type:varchar(64)"`
+	RequestedAt   time.Time `json:"requestedAt"`
+	ConfirmableAt time.Time `json:"confirmableAt"`
+	ExpiresAt     time.Time `json:"expiresAt"`
+}
+
+// NewMapDeletionRequest creates a deletion request made now and its
+// confirmation token. The token is only returned to the requester.
+func NewMapDeletionRequest(requestedBy string, now time.Time) (MapDeletionRequest, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return MapDeletionRequest{}, "", fmt.Errorf("failed to generate deletion token: %w", err)
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	request := MapDeletionRequest{
+		RequestedBy:   requestedBy,
+		TokenHash:     hashMapDeletionToken(token),
+		RequestedAt:   now,
+		ConfirmableAt: now.Add(MapDeletionCooldown),
+		ExpiresAt:     now.Add(MapDeletionValidity),
+	}
+	return request, token, nil
+}
+
+// IsPending reports whether the request can still be confirmed
+func (r MapDeletionRequest) IsPending(now time.Time) bool {
+	return r.TokenHash != "" && now.Before(r.ExpiresAt)
+}
+
+// Matches reports whether token confirms the request
+func (r MapDeletionRequest) Matches(token string) bool {
+	return r.TokenHash != "" && hashMapDeletionToken(token) == r.TokenHash
+}
+
+// hashMapDeletionToken returns the stored hash of a deletion token
+func hashMapDeletionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// MapSnapshot is the archive of a deleted map, exported right before the
+// deletion so the map's owner or a superadmin can restore it
+type MapSnapshot struct {
+	ID            string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	MapID         string     `json:"mapId" gorm:"type:varchar(36);index"`
+	OwnerID       string     `json:"ownerId" gorm:"type:varchar(36);index"`
+	DeletedBy     string     `json:"deletedBy" gorm:"type:varchar(36)"`
+	Archive       MapArchive `json:"archive" gorm:"type:jsonb;serializer:json"`
+	RestoredMapID string     `json:"restoredMapId,omitempty" gorm:"type:varchar(36)"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+// NewMapSnapshot creates the snapshot of a map deleted now
+func NewMapSnapshot(m *Map, deletedBy string, archive MapArchive, now time.Time) *MapSnapshot {
+	return &MapSnapshot{
+		ID:        uuid.New().String(),
+		MapID:     m.ID,
+		OwnerID:   m.CreatedBy,
+		DeletedBy: deletedBy,
+		Archive:   archive,
+		CreatedAt: now,
+	}
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// MaxMapTags is the maximum number of tags of a map
+	MaxMapTags = 10
+
+	// MaxMapTagLength is the maximum length of a map tag
+	MaxMapTagLength = 30
+)
+
+// MapListing controls whether and how a map appears in the public map directory
+type MapListing struct {
+	Discoverable bool     `json:"discoverable"`
+	Tags         []string `json:"tags"`
+}
+
+// Normalize lowercases, trims and deduplicates the tags of the listing and
+// validates them
+func (l *MapListing) Normalize() error {
+	tags, err := NormalizeMapTags(l.Tags)
+	if err != nil {
+		return err
+	}
+	l.Tags = tags
+	return nil
+}
+
+// NormalizeMapTags lowercases, trims and deduplicates tags. Tags may only
+// contain lowercase letters, digits and dashes.
+func NormalizeMapTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len(tag) > MaxMapTagLength {
+			return nil, fmt.Errorf("tag %q must be %d characters or less", tag, MaxMapTagLength)
+		}
+		for _, r := range tag {
+			if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' {
+				return nil, fmt.Errorf("tag %q may only contain letters, digits and dashes", tag)
+			}
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	if len(normalized) > MaxMapTags {
+		return nil, fmt.Errorf("a map can have at most %d tags", MaxMapTags)
+	}
+
+	return normalized, nil
+}
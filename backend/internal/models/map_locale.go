@@ -0,0 +1,42 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultMapLocale is the locale of maps that haven't chosen one
+const DefaultMapLocale = "en"
+
+// SupportedMapLocales are the locales server-generated content like digest
+// and invitation emails is available in
+var SupportedMapLocales = []string{"en", "de", "fr", "es"}
+
+// MapLocale is the language a map's server-generated content is written in
+type MapLocale struct {
+	Locale string `json:"locale"`
+}
+
+// NormalizeMapLocale lowercases and trims a locale and checks that it is
+// supported. Region subtags are dropped, so "de-AT" becomes "de".
+func NormalizeMapLocale(locale string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(normalized, "-_"); i >= 0 {
+		normalized = normalized[:i]
+	}
+	for _, supported := range SupportedMapLocales {
+		if normalized == supported {
+			return normalized, nil
+		}
+	}
+	return "", fmt.Errorf("locale %q is not supported, use one of %s", locale, strings.Join(SupportedMapLocales, ", "))
+}
+
+// ContentLocale returns the locale of the map's server-generated content,
+// which is the default for maps created before locales existed
+func (m Map) ContentLocale() string {
+	if m.Locale == "" {
+		return DefaultMapLocale
+	}
+	return m.Locale
+}
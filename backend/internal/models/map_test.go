@@ -6,6 +6,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMap_Validate(t *testing.T) {
@@ -342,6 +343,24 @@ func TestMap_IsOwnedBy(t *testing.T) {
 	}
 }
 
+func TestNormalizeMapLocale(t *testing.T) {
+	locale, err := NormalizeMapLocale(" DE-at ")
+	require.NoError(t, err)
+	assert.Equal(t, "de", locale)
+
+	_, err = NormalizeMapLocale("tlh")
+	assert.Error(t, err)
+
+	m, err := NewMap("Workshop", "", "creator-456")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultMapLocale, m.ContentLocale())
+	m.Locale = "DE"
+	assert.Error(t, m.Validate())
+	m.Locale = ""
+	assert.NoError(t, m.Validate())
+	assert.Equal(t, DefaultMapLocale, m.ContentLocale())
+}
+
 // Tests using NewMap() builder for multi-map support relationships
 func TestNewMap_WithBuilder(t *testing.T) {
 	t.Run("create map with builder pattern", func(t *testing.T) {
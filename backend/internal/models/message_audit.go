@@ -0,0 +1,48 @@
+package models
+
+import "fmt"
+
+// MaxMessageAuditFlaggedSessions is how many sessions a map can audit all messages of
+const MaxMessageAuditFlaggedSessions = 50
+
+// MessageAuditPolicy configures which WebSocket messages of a map are recorded
+// to the audit log, so protocol bugs can be analyzed without logging every
+// message. A sampled message is recorded with all its data.
+type MessageAuditPolicy struct {
+	// SampleRate is the share of messages recorded, from 0 (none) to 1 (all)
+	SampleRate float64 `json:"sampleRate" gorm:"default:0"`
+	// FlaggedSessions are sessions whose messages are all recorded, like the
+	// session of a user who reported a bug
+	FlaggedSessions []string `json:"flaggedSessions,omitempty" gorm:"type:jsonb;serializer:json"`
+}
+
+// IsFlagged reports whether all messages of the session are recorded
+func (p MessageAuditPolicy) IsFlagged(sessionID string) bool {
+	for _, flagged := range p.FlaggedSessions {
+		if flagged == sessionID {
+			return true
+		}
+	}
+	return false
+}
+
+// Enabled reports whether any message can be recorded
+func (p MessageAuditPolicy) Enabled() bool {
+	return p.SampleRate > 0 || len(p.FlaggedSessions) > 0
+}
+
+// Validate checks that the sample rate is a share and the flagged sessions are in range
+func (p MessageAuditPolicy) Validate() error {
+	if p.SampleRate < 0 || p.SampleRate > 1 {
+		return fmt.Errorf("message audit sample rate must be between 0 and 1")
+	}
+	if len(p.FlaggedSessions) > MaxMessageAuditFlaggedSessions {
+		return fmt.Errorf("a map can flag at most %d sessions for message audit", MaxMessageAuditFlaggedSessions)
+	}
+	for _, sessionID := range p.FlaggedSessions {
+		if sessionID == "" {
+			return fmt.Errorf("flagged session IDs must not be empty")
+		}
+	}
+	return nil
+}
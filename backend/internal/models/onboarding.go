@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+// OnboardingStep is a step of the welcome checklist new users work through
+type OnboardingStep string
+
+const (
+	// OnboardingProfileSet is completed by updating the profile or uploading an avatar
+	OnboardingProfileSet OnboardingStep = "profile_set"
+	// OnboardingFirstMove is completed by moving the avatar
+	OnboardingFirstMove OnboardingStep = "first_move"
+	// OnboardingFirstPOIJoin is completed by joining a POI
+	OnboardingFirstPOIJoin OnboardingStep = "first_poi_join"
+)
+
+// OnboardingSteps lists the steps of the welcome checklist in the order they
+// are shown
+var OnboardingSteps = []OnboardingStep{
+	OnboardingProfileSet,
+	OnboardingFirstMove,
+	OnboardingFirstPOIJoin,
+}
+
+// IsValid checks whether the step is part of the welcome checklist
+func (s OnboardingStep) IsValid() bool {
+	for _, step := range OnboardingSteps {
+		if s == step {
+			return true
+		}
+	}
+	return false
+}
+
+// UserOnboardingStep records when a user completed a step of the welcome checklist
+type UserOnboardingStep struct {
+	UserID      string         `json:"userId" gorm:"primaryKey;type:varchar(36)"`
+	Step        OnboardingStep `json:"step" gorm:"primaryKey;type:varchar(30)"`
+	CompletedAt time.Time      `json:"completedAt" gorm:"not null"`
+}
+
+// TableName returns the table name for the UserOnboardingStep model
+func (UserOnboardingStep) TableName() string {
+	return "user_onboarding_steps"
+}
+
+// OnboardingStepStatus is the state of one step of a user's welcome checklist
+type OnboardingStepStatus struct {
+	Step        OnboardingStep `json:"step"`
+	Completed   bool           `json:"completed"`
+	CompletedAt *time.Time     `json:"completedAt,omitempty"`
+}
+
+// OnboardingProgress is the state of a user's welcome checklist
+type OnboardingProgress struct {
+	Steps    []OnboardingStepStatus `json:"steps"`
+	Complete bool                   `json:"complete"`
+}
+
+// NewOnboardingProgress returns the progress of a user who completed the given
+// steps. Steps that are no longer part of the checklist are ignored.
+func NewOnboardingProgress(completed []*UserOnboardingStep) OnboardingProgress {
+	completedAt := make(map[OnboardingStep]time.Time, len(completed))
+	for _, step := range completed {
+		completedAt[step.Step] = step.CompletedAt
+	}
+
+	progress := OnboardingProgress{
+		Steps:    make([]OnboardingStepStatus, 0, len(OnboardingSteps)),
+		Complete: true,
+	}
+	for _, step := range OnboardingSteps {
+		status := OnboardingStepStatus{Step: step}
+		if at, ok := completedAt[step]; ok {
+			status.Completed = true
+			status.CompletedAt = &at
+		} else {
+			progress.Complete = false
+		}
+		progress.Steps = append(progress.Steps, status)
+	}
+	return progress
+}
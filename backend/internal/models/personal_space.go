@@ -0,0 +1,42 @@
+package models
+
+import "fmt"
+
+// PersonalSpaceMode is how the server handles avatar moves into another avatar's personal space
+type PersonalSpaceMode string
+
+const (
+	// PersonalSpaceOff lets avatars overlap
+	PersonalSpaceOff PersonalSpaceMode = ""
+	// PersonalSpaceReject keeps the avatar at its previous position
+	PersonalSpaceReject PersonalSpaceMode = "reject"
+	// PersonalSpaceAdjust moves the avatar to the edge of the other avatar's personal space
+	PersonalSpaceAdjust PersonalSpaceMode = "adjust"
+)
+
+// MaxPersonalSpaceMeters is the largest personal space radius a map can configure
+const MaxPersonalSpaceMeters = 1000.0
+
+// PersonalSpace configures the minimum distance kept between avatars on a map
+type PersonalSpace struct {
+	Mode         PersonalSpaceMode `json:"mode" gorm:"type:varchar(10);default:''"`
+	RadiusMeters float64           `json:"radiusMeters" gorm:"default:0"`
+}
+
+// Enabled reports whether avatar moves are checked for overlaps
+func (p PersonalSpace) Enabled() bool {
+	return p.Mode != PersonalSpaceOff && p.RadiusMeters > 0
+}
+
+// Validate checks that the mode is known and the radius is in range
+func (p PersonalSpace) Validate() error {
+	switch p.Mode {
+	case PersonalSpaceOff, PersonalSpaceReject, PersonalSpaceAdjust:
+	default:
+		return fmt.Errorf("invalid personal space mode %q: must be empty, reject or adjust", p.Mode)
+	}
+	if p.RadiusMeters < 0 || p.RadiusMeters > MaxPersonalSpaceMeters {
+		return fmt.Errorf("personal space radius must be between 0 and %.0f meters", MaxPersonalSpaceMeters)
+	}
+	return nil
+}
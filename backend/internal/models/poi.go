@@ -26,6 +26,10 @@ type POI struct {
 	DiscussionStartTime *time.Time `json:"discussionStartTime,omitempty" gorm:"type:timestamp"`
 	IsDiscussionActive  bool       `json:"isDiscussionActive" gorm:"default:false"`
 	
+	// Scheduled event fields - only set for POIs that host an event at a fixed time
+	StartsAt       *time.Time `json:"startsAt,omitempty" gorm:"index;type:timestamp"`
+	ReminderSentAt *time.Time `json:"-" gorm:"type:timestamp"`
+	
 	CreatedAt       time.Time      `json:"createdAt" gorm:"not null"`
 	UpdatedAt       time.Time      `json:"updatedAt" gorm:"not null"`
 	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"` // Soft delete support
@@ -98,6 +102,11 @@ func (p POI) Validate() error {
 	return nil
 }
 
+// IsScheduled checks if the POI hosts an event at a fixed start time
+func (p POI) IsScheduled() bool {
+	return p.StartsAt != nil
+}
+
 // DistanceTo calculates the distance in kilometers from this POI to a given position
 func (p POI) DistanceTo(position LatLng) float64 {
 	return p.Position.DistanceTo(position)
@@ -148,4 +157,10 @@ func (p *POI) Update() {
 // TableName returns the table name for GORM
 func (POI) TableName() string {
 	return "pois"
-}
\ No newline at end of file
+}
+// POIPopularity counts the users who joined a POI since the start of a recent
+// window, so clients can badge trending POIs
+type POIPopularity struct {
+	Since time.Time `json:"since"`
+	Joins int       `json:"joins"`
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RSVPStatus is a user's answer to a scheduled POI event
+type RSVPStatus string
+
+const (
+	RSVPGoing RSVPStatus = "going"
+	RSVPMaybe RSVPStatus = "maybe"
+	RSVPNo    RSVPStatus = "no"
+)
+
+// Validate checks that the status is supported
+func (s RSVPStatus) Validate() error {
+	switch s {
+	case RSVPGoing, RSVPMaybe, RSVPNo:
+		return nil
+	default:
+		return fmt.Errorf("invalid RSVP status %q: must be going, maybe or no", s)
+	}
+}
+
+// POIRSVP is a user's RSVP to a scheduled POI
+type POIRSVP struct {
+	ID        string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	POIID     string     `json:"poiId" gorm:"uniqueIndex:idx_poi_rsvps_poi_user;type:varchar(36);not null"`
+	POI       *POI       `json:"-" gorm:"foreignKey:POIID;references:ID"`
+	UserID    string     `json:"userId" gorm:"uniqueIndex:idx_poi_rsvps_poi_user;type:varchar(36);not null"`
+	User      *User      `json:"-" gorm:"foreignKey:UserID;references:ID"`
+	Status    RSVPStatus `json:"status" gorm:"type:varchar(10);not null"`
+	CreatedAt time.Time  `json:"createdAt" gorm:"not null"`
+	UpdatedAt time.Time  `json:"updatedAt" gorm:"not null"`
+}
+
+// NewPOIRSVP creates a new RSVP with a generated ID
+func NewPOIRSVP(poiID, userID string, status RSVPStatus) (*POIRSVP, error) {
+	if poiID == "" {
+		return nil, fmt.Errorf("POI ID is required")
+	}
+	if userID == "" {
+		return nil, fmt.Errorf("user ID is required")
+	}
+	if err := status.Validate(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &POIRSVP{
+		ID:        uuid.New().String(),
+		POIID:     poiID,
+		UserID:    userID,
+		Status:    status,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+// TableName returns the table name for GORM
+func (POIRSVP) TableName() string {
+	return "poi_rsvps"
+}
+
+// RSVPCounts counts the RSVPs of a POI by status
+type RSVPCounts struct {
+	Going int `json:"going"`
+	Maybe int `json:"maybe"`
+	No    int `json:"no"`
+}
+
+// Add counts n RSVPs with the given status
+func (c *RSVPCounts) Add(status RSVPStatus, n int) {
+	switch status {
+	case RSVPGoing:
+		c.Going += n
+	case RSVPMaybe:
+		c.Maybe += n
+	case RSVPNo:
+		c.No += n
+	}
+}
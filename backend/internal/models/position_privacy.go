@@ -0,0 +1,52 @@
+package models
+
+import (
+	"fmt"
+	"math"
+)
+
+// Grid sizes a map can configure for coarse avatar positions
+const (
+	MinPositionPrivacyGridMeters = 10.0
+	MaxPositionPrivacyGridMeters = 10000.0
+)
+
+// metersPerDegreeLat is the length of one degree of latitude
+const metersPerDegreeLat = 111320.0
+
+// PositionPrivacy configures whether regular participants only see avatar
+// positions rounded to a grid. Facilitators always see exact positions.
+type PositionPrivacy struct {
+	// GridMeters is the grid cell size; 0 shows exact positions to everyone
+	GridMeters float64 `json:"gridMeters" gorm:"default:0"`
+}
+
+// Enabled reports whether positions are coarsened for regular participants
+func (p PositionPrivacy) Enabled() bool {
+	return p.GridMeters > 0
+}
+
+// Validate checks that the grid size is off or in range
+func (p PositionPrivacy) Validate() error {
+	if p.GridMeters != 0 && (p.GridMeters < MinPositionPrivacyGridMeters || p.GridMeters > MaxPositionPrivacyGridMeters) {
+		return fmt.Errorf("position privacy grid must be 0 or between %.0f and %.0f meters", MinPositionPrivacyGridMeters, MaxPositionPrivacyGridMeters)
+	}
+	return nil
+}
+
+// Coarsen rounds a position to the center of its grid cell. Cells are about
+// GridMeters wide in both directions at the position's latitude.
+func (p PositionPrivacy) Coarsen(position LatLng) LatLng {
+	if !p.Enabled() {
+		return position
+	}
+
+	latStep := p.GridMeters / metersPerDegreeLat
+	lat := math.Max(-90, math.Min(90, math.Round(position.Lat/latStep)*latStep))
+
+	// Longitude degrees shrink towards the poles
+	lngStep := p.GridMeters / (metersPerDegreeLat * math.Max(math.Cos(lat*math.Pi/180), 0.01))
+	lng := math.Max(-180, math.Min(180, math.Round(position.Lng/lngStep)*lngStep))
+
+	return LatLng{Lat: lat, Lng: lng}
+}
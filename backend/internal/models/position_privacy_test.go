@@ -0,0 +1,32 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPositionPrivacy_Validate(t *testing.T) {
+	assert.NoError(t, PositionPrivacy{}.Validate())
+	assert.NoError(t, PositionPrivacy{GridMeters: 250}.Validate())
+	assert.Error(t, PositionPrivacy{GridMeters: 5}.Validate())
+	assert.Error(t, PositionPrivacy{GridMeters: 20000}.Validate())
+	assert.Error(t, PositionPrivacy{GridMeters: -1}.Validate())
+}
+
+func TestPositionPrivacy_Coarsen(t *testing.T) {
+	position := LatLng{Lat: 52.52003, Lng: 13.40495}
+	assert.Equal(t, position, PositionPrivacy{}.Coarsen(position))
+
+	privacy := PositionPrivacy{GridMeters: 500}
+	coarse := privacy.Coarsen(position)
+	assert.NotEqual(t, position, coarse)
+	// Within half a cell diagonal of the exact position
+	assert.Less(t, coarse.DistanceTo(position)*1000, 500*0.71)
+
+	// Nearby positions in the same cell are indistinguishable
+	assert.Equal(t, coarse, privacy.Coarsen(LatLng{Lat: coarse.Lat + 0.0001, Lng: coarse.Lng - 0.0001}))
+
+	// Positions near the poles and the antimeridian stay valid
+	assert.NoError(t, privacy.Coarsen(LatLng{Lat: 89.9999, Lng: 179.9999}).Validate())
+}
@@ -0,0 +1,14 @@
+package models
+
+// ProfilePrivacy configures which profile fields of a map's participants are
+// left out of the presence and participant payloads shared on the map. Display
+// names are always shared.
+type ProfilePrivacy struct {
+	HideAboutMe   bool `json:"hideAboutMe" gorm:"default:false"`
+	HideAvatarURL bool `json:"hideAvatarUrl" gorm:"default:false"`
+}
+
+// Enabled reports whether any profile field is left out
+func (p ProfilePrivacy) Enabled() bool {
+	return p.HideAboutMe || p.HideAvatarURL
+}
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// RaisedHand is a participant waiting in a map's raise-hand queue.
+// Facilitators call on raised hands in the order they were raised.
+type RaisedHand struct {
+	UserID   string    `json:"userId"`
+	RaisedAt time.Time `json:"raisedAt"`
+}
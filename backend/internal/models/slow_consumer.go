@@ -0,0 +1,71 @@
+package models
+
+import "fmt"
+
+// SlowConsumerDropPolicy is what happens to a broadcast when a client's send buffer is full
+type SlowConsumerDropPolicy string
+
+const (
+	// SlowConsumerDisconnect closes the connection of the client
+	SlowConsumerDisconnect SlowConsumerDropPolicy = ""
+	// SlowConsumerDropNewest discards the broadcast for the client
+	SlowConsumerDropNewest SlowConsumerDropPolicy = "drop_newest"
+	// SlowConsumerDropOldest discards the oldest queued message to make room for the broadcast
+	SlowConsumerDropOldest SlowConsumerDropPolicy = "drop_oldest"
+	// SlowConsumerDropMovement discards avatar movement broadcasts, which the
+	// next move supersedes, and closes the connection for any other broadcast
+	SlowConsumerDropMovement SlowConsumerDropPolicy = "drop_movement"
+)
+
+// String returns the name of the policy, "disconnect" for SlowConsumerDisconnect
+func (p SlowConsumerDropPolicy) String() string {
+	if p == SlowConsumerDisconnect {
+		return "disconnect"
+	}
+	return string(p)
+}
+
+// Send buffer sizes a map can configure; DefaultSendBufferSize applies when none is set
+const (
+	DefaultSendBufferSize = 256
+	MinSendBufferSize     = 16
+	MaxSendBufferSize     = 4096
+)
+
+// MaxSlowConsumerEvictAfter is the highest eviction threshold a map can configure
+const MaxSlowConsumerEvictAfter = 10000
+
+// SlowConsumerPolicy configures how a map treats clients that don't keep up with
+// its broadcasts. Large webinars may prefer dropping messages over reconnects,
+// small teams a short buffer that surfaces slow connections quickly.
+type SlowConsumerPolicy struct {
+	SendBufferSize int                    `json:"sendBufferSize" gorm:"default:0"`
+	DropPolicy     SlowConsumerDropPolicy `json:"dropPolicy" gorm:"type:varchar(20);default:''"`
+	// EvictAfter is how many messages in a row may be dropped before the client
+	// is disconnected anyway; 0 never evicts clients of a dropping policy
+	EvictAfter int `json:"evictAfter" gorm:"default:0"`
+}
+
+// BufferSize returns the send buffer size of clients on the map
+func (p SlowConsumerPolicy) BufferSize() int {
+	if p.SendBufferSize == 0 {
+		return DefaultSendBufferSize
+	}
+	return p.SendBufferSize
+}
+
+// Validate checks that the drop policy is known and the sizes are in range
+func (p SlowConsumerPolicy) Validate() error {
+	switch p.DropPolicy {
+	case SlowConsumerDisconnect, SlowConsumerDropNewest, SlowConsumerDropOldest, SlowConsumerDropMovement:
+	default:
+		return fmt.Errorf("invalid drop policy %q: must be empty, drop_newest, drop_oldest or drop_movement", string(p.DropPolicy))
+	}
+	if p.SendBufferSize != 0 && (p.SendBufferSize < MinSendBufferSize || p.SendBufferSize > MaxSendBufferSize) {
+		return fmt.Errorf("send buffer size must be between %d and %d", MinSendBufferSize, MaxSendBufferSize)
+	}
+	if p.EvictAfter < 0 || p.EvictAfter > MaxSlowConsumerEvictAfter {
+		return fmt.Errorf("eviction threshold must be between 0 and %d", MaxSlowConsumerEvictAfter)
+	}
+	return nil
+}
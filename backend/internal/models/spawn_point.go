@@ -0,0 +1,48 @@
+package models
+
+import "fmt"
+
+// DefaultSpawnRadiusMeters is the radius avatars are spread over around a spawn
+// point without an explicit radius, or around a requested position
+const DefaultSpawnRadiusMeters = 50.0
+
+// MaxSpawnPoints is the maximum number of spawn points a map can define
+const MaxSpawnPoints = 50
+
+// SpawnPoint is a circular area new avatars are placed in
+type SpawnPoint struct {
+	Position     LatLng  `json:"position"`
+	RadiusMeters float64 `json:"radiusMeters,omitempty"`
+}
+
+// Radius returns the spawn radius in meters, falling back to DefaultSpawnRadiusMeters
+func (p SpawnPoint) Radius() float64 {
+	if p.RadiusMeters > 0 {
+		return p.RadiusMeters
+	}
+	return DefaultSpawnRadiusMeters
+}
+
+// Validate checks that the spawn point has a valid position and radius
+func (p SpawnPoint) Validate() error {
+	if err := p.Position.Validate(); err != nil {
+		return fmt.Errorf("invalid spawn point position: %w", err)
+	}
+	if p.RadiusMeters < 0 || p.RadiusMeters > 10000 {
+		return fmt.Errorf("spawn point radius must be between 0 and 10000 meters")
+	}
+	return nil
+}
+
+// ValidateSpawnPoints checks a map's spawn points
+func ValidateSpawnPoints(points []SpawnPoint) error {
+	if len(points) > MaxSpawnPoints {
+		return fmt.Errorf("a map can have at most %d spawn points", MaxSpawnPoints)
+	}
+	for i, point := range points {
+		if err := point.Validate(); err != nil {
+			return fmt.Errorf("spawn point %d: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package models
+
+// SummonedUser is a participant a facilitator moved to a POI. The avatars of
+// all the user's sessions are placed at Position. Moved and Joined are false
+// if moving the avatar or joining the POI failed, for example because the POI
+// is full, and Error says why.
+type SummonedUser struct {
+	UserID     string   `json:"userId"`
+	SessionIDs []string `json:"sessionIds"`
+	Position   LatLng   `json:"position"`
+	Moved      bool     `json:"moved"`
+	Joined     bool     `json:"joined"`
+	Error      string   `json:"error,omitempty"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// UploadReference links a stored file key to the content hash of the blob backing it.
+// Identical uploads share one blob; the blob is deleted once no references remain.
+// OwnerID is who uploaded or claimed the file, empty for files stored by the server.
+type UploadReference struct {
+	Key         string    `json:"key" gorm:"primaryKey;type:varchar(512)"`
+	OwnerID     string    `json:"ownerId" gorm:"index;type:varchar(36)"`
+	ContentHash string    `json:"contentHash" gorm:"index;type:varchar(64);not null"`
+	CreatedAt   time.Time `json:"createdAt" gorm:"not null"`
+}
+
+// TableName returns the table name for GORM
+func (UploadReference) TableName() string {
+	return "upload_references"
+}
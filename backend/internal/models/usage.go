@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// UsageBucketSize is the time resolution of metered map usage
+const UsageBucketSize = time.Hour
+
+// UsageMetric is a kind of usage metered per map
+type UsageMetric string
+
+const (
+	// UsageWebSocketBytes is the size of the messages sent to the map's WebSocket clients
+	UsageWebSocketBytes UsageMetric = "websocket_bytes"
+	// UsageStorageBytes is the size of the files uploaded to the map, like POI images
+	UsageStorageBytes UsageMetric = "storage_bytes"
+	// UsageCallMinutes is the length of the calls held on the map
+	UsageCallMinutes UsageMetric = "call_minutes"
+)
+
+// MapUsage is the usage of a map metered during one time bucket
+type MapUsage struct {
+	MapID          string    `json:"mapId" gorm:"primaryKey;type:varchar(36)"`
+	BucketStart    time.Time `json:"bucketStart" gorm:"primaryKey"`
+	WebSocketBytes int64     `json:"webSocketBytes" gorm:"not null;default:0"`
+	StorageBytes   int64     `json:"storageBytes" gorm:"not null;default:0"`
+	CallSeconds    int64     `json:"callSeconds" gorm:"not null;default:0"`
+}
+
+// TableName returns the table name for GORM
+func (MapUsage) TableName() string {
+	return "map_usage"
+}
+
+// Add adds the usage of other to u
+func (u *MapUsage) Add(other *MapUsage) {
+	u.WebSocketBytes += other.WebSocketBytes
+	u.StorageBytes += other.StorageBytes
+	u.CallSeconds += other.CallSeconds
+}
+
+// Value returns the amount of a metric, with call seconds rounded up to minutes
+func (u *MapUsage) Value(metric UsageMetric) int64 {
+	switch metric {
+	case UsageWebSocketBytes:
+		return u.WebSocketBytes
+	case UsageStorageBytes:
+		return u.StorageBytes
+	case UsageCallMinutes:
+		return (u.CallSeconds + 59) / 60
+	default:
+		return 0
+	}
+}
+
+// UsageBucketFor returns the start of the usage bucket containing t
+func UsageBucketFor(t time.Time) time.Time {
+	return t.UTC().Truncate(UsageBucketSize)
+}
+
+// UsagePeriodFor returns the start of the calendar month containing t, the
+// period usage thresholds apply to
+func UsagePeriodFor(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
@@ -38,6 +38,7 @@ type User struct {
 	Role         UserRole       `json:"role" gorm:"type:varchar(20);not null;default:'user'"`
 	PasswordHash *string        `json:"-" gorm:"type:varchar(255)"` // Hidden from JSON
 	IsActive     bool           `json:"isActive" gorm:"default:true"`
+	AnonymizedAt *time.Time     `json:"anonymizedAt,omitempty" gorm:"index"` // Set once a guest's identity was scrubbed
 	CreatedAt    time.Time      `json:"createdAt"`
 	UpdatedAt    time.Time      `json:"updatedAt"`
 	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"` // Soft delete support
@@ -183,6 +184,16 @@ func (u *User) IsGuest() bool {
 	return u.AccountType == AccountTypeGuest
 }
 
+// Anonymize replaces the guest's name and removes their avatar, bio and email
+func (u *User) Anonymize(at time.Time) {
+	u.DisplayName = AnonymousDisplayName
+	u.AvatarURL = nil
+	u.AboutMe = nil
+	u.Email = nil
+	u.AnonymizedAt = &at
+	u.UpdatedAt = at
+}
+
 // IsFull returns true if the user is a full account
 func (u *User) IsFull() bool {
 	return u.AccountType == AccountTypeFull
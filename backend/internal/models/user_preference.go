@@ -0,0 +1,150 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PreferenceNamespace groups related user preferences
+type PreferenceNamespace string
+
+const (
+	PreferenceNotifications PreferenceNamespace = "notifications"
+	PreferenceA11y          PreferenceNamespace = "a11y"
+	PreferenceVideo         PreferenceNamespace = "video"
+	PreferencePrivacy       PreferenceNamespace = "privacy"
+)
+
+// preferenceSpec describes a single preference value
+type preferenceSpec struct {
+	defaultValue interface{}
+	min, max     float64  // Range of number preferences
+	options      []string // Allowed values of string preferences
+}
+
+// preferenceSchema lists the known preferences of every namespace with their defaults
+var preferenceSchema = map[PreferenceNamespace]map[string]preferenceSpec{
+	PreferenceNotifications: {
+		"sound":        {defaultValue: true},
+		"toasts":       {defaultValue: true},
+		"poiReminders": {defaultValue: true},
+		"digestEmails": {defaultValue: true},
+	},
+	PreferenceA11y: {
+		"reducedMotion": {defaultValue: false},
+		"highContrast":  {defaultValue: false},
+		"fontScale":     {defaultValue: 1.0, min: 0.75, max: 2},
+	},
+	PreferenceVideo: {
+		"cameraOn":   {defaultValue: true},
+		"micOn":      {defaultValue: true},
+		"resolution": {defaultValue: "auto", options: []string{"auto", "low", "medium", "high"}},
+	},
+	PreferencePrivacy: {
+		"doNotTrack": {defaultValue: false},
+	},
+}
+
+// Preferences maps namespaces to their preference values
+type Preferences map[PreferenceNamespace]map[string]interface{}
+
+// UserPreference stores the preference values of one namespace of a user
+type UserPreference struct {
+	UserID    string                 `json:"userId" gorm:"primaryKey;type:varchar(36)"`
+	Namespace PreferenceNamespace    `json:"namespace" gorm:"primaryKey;type:varchar(30)"`
+	Values    map[string]interface{} `json:"values" gorm:"type:jsonb;serializer:json;not null"`
+	UpdatedAt time.Time              `json:"updatedAt" gorm:"not null"`
+}
+
+// TableName returns the table name for the UserPreference model
+func (UserPreference) TableName() string {
+	return "user_preferences"
+}
+
+// DefaultPreferences returns the default values of all preferences
+func DefaultPreferences() Preferences {
+	preferences := make(Preferences, len(preferenceSchema))
+	for namespace, specs := range preferenceSchema {
+		values := make(map[string]interface{}, len(specs))
+		for key, spec := range specs {
+			values[key] = spec.defaultValue
+		}
+		preferences[namespace] = values
+	}
+	return preferences
+}
+
+// Merge overwrites the values of p with the values given in other
+func (p Preferences) Merge(other Preferences) {
+	for namespace, values := range other {
+		if p[namespace] == nil {
+			p[namespace] = make(map[string]interface{}, len(values))
+		}
+		for key, value := range values {
+			p[namespace][key] = value
+		}
+	}
+}
+
+// Validate checks that every namespace and key is known and every value has
+// the type and range of its preference
+func (p Preferences) Validate() error {
+	for namespace, values := range p {
+		specs, exists := preferenceSchema[namespace]
+		if !exists {
+			return fmt.Errorf("unknown preference namespace %q, expected one of %s", namespace, strings.Join(preferenceNamespaces(), ", "))
+		}
+		for key, value := range values {
+			spec, exists := specs[key]
+			if !exists {
+				return fmt.Errorf("unknown preference %s.%s", namespace, key)
+			}
+			if err := spec.validate(value); err != nil {
+				return fmt.Errorf("preference %s.%s %w", namespace, key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validate checks that value matches the type of the default value and its constraints
+func (s preferenceSpec) validate(value interface{}) error {
+	switch s.defaultValue.(type) {
+	case bool:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("must be a boolean")
+		}
+	case float64:
+		number, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("must be a number")
+		}
+		if number < s.min || number > s.max {
+			return fmt.Errorf("must be between %g and %g", s.min, s.max)
+		}
+	case string:
+		text, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("must be a string")
+		}
+		for _, option := range s.options {
+			if text == option {
+				return nil
+			}
+		}
+		return fmt.Errorf("must be one of %s", strings.Join(s.options, ", "))
+	}
+	return nil
+}
+
+// preferenceNamespaces returns the known namespaces in alphabetical order
+func preferenceNamespaces() []string {
+	namespaces := make([]string, 0, len(preferenceSchema))
+	for namespace := range preferenceSchema {
+		namespaces = append(namespaces, string(namespace))
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
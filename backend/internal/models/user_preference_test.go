@@ -0,0 +1,40 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreferences_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		preferences Preferences
+		wantErr     string
+	}{
+		{"valid", Preferences{PreferenceA11y: {"fontScale": 1.25, "reducedMotion": true}, PreferenceVideo: {"resolution": "low"}}, ""},
+		{"unknown namespace", Preferences{"theme": {"dark": true}}, "unknown preference namespace"},
+		{"unknown key", Preferences{PreferenceVideo: {"mirror": true}}, "unknown preference video.mirror"},
+		{"wrong type", Preferences{PreferenceNotifications: {"sound": "yes"}}, "must be a boolean"},
+		{"out of range", Preferences{PreferenceA11y: {"fontScale": 3.0}}, "must be between 0.75 and 2"},
+		{"unknown option", Preferences{PreferenceVideo: {"resolution": "4k"}}, "must be one of"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.preferences.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDefaultPreferences_AreValid(t *testing.T) {
+	preferences := DefaultPreferences()
+
+	assert.NoError(t, preferences.Validate())
+	assert.Equal(t, true, preferences[PreferenceVideo]["cameraOn"])
+	assert.Equal(t, 1.0, preferences[PreferenceA11y]["fontScale"])
+}
@@ -0,0 +1,90 @@
+package protocol
+
+import (
+	"sort"
+	"testing"
+
+	"breakoutglobe/internal/testdata"
+	"breakoutglobe/internal/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clientMessageTypes are all message types clients may send; each needs a fixture
+var clientMessageTypes = []string{
+	"heartbeat",
+	"auth_refresh",
+	"avatar_move",
+	"avatar_move_batch",
+	"request_initial_users",
+	"resync",
+	"resync_from",
+	"viewport_update",
+	"poi_join",
+	"poi_leave",
+	"speaking_state",
+	"call_request",
+	"call_accept",
+	"call_reject",
+	"call_end",
+	"presenting_state",
+	"screen_share_ended",
+	"webrtc_offer",
+	"webrtc_answer",
+	"ice_candidate",
+	"poi_call_offer",
+	"poi_call_answer",
+	"poi_call_ice_candidate",
+	"map_freeze",
+	"map_unfreeze",
+	"subscribe",
+	"unsubscribe",
+	"reaction",
+	"follow_start",
+	"follow_stop",
+	"hand_raise",
+	"hand_lower",
+	"summon_to_poi",
+	"activity_ping",
+	"kick_user",
+}
+
+func TestProtocolConformance(t *testing.T) {
+	fixtures, err := testdata.LoadProtocolFixtures("testdata")
+	require.NoError(t, err)
+	require.NotEmpty(t, fixtures)
+
+	for _, fixture := range fixtures {
+		fixture := fixture
+		t.Run(fixture.Name, func(t *testing.T) {
+			scenario := testdata.NewProtocolScenario()
+			defer scenario.Cleanup()
+
+			scenario.Run(t, fixture)
+		})
+	}
+}
+
+func TestProtocolFixturesCoverAllMessageTypes(t *testing.T) {
+	fixtures, err := testdata.LoadProtocolFixtures("testdata")
+	require.NoError(t, err)
+
+	covered := make(map[string]bool)
+	for _, fixture := range fixtures {
+		covered[fixture.Request.Type] = true
+	}
+
+	for _, messageType := range clientMessageTypes {
+		if !covered[messageType] {
+			t.Errorf("no protocol fixture for message type %s", messageType)
+		}
+	}
+}
+
+func TestClientMessageTypesMatchRegistry(t *testing.T) {
+	listed := append([]string(nil), clientMessageTypes...)
+	sort.Strings(listed)
+
+	assert.Equal(t, websocket.MessageTypes(websocket.ProtocolVersion), listed,
+		"clientMessageTypes must list every message type the server handles")
+}
@@ -0,0 +1,13 @@
+// Package protocol holds the WebSocket protocol conformance suite.
+//
+// Every client message type has a golden fixture in testdata/ describing the
+// request a client sends and the exact messages the sender and another client
+// on the same map receive. Changing the protocol means changing a fixture, so
+// protocol changes show up in review. Regenerate fixtures after an intentional
+// change with:
+//
+//	UPDATE_GOLDEN=1 go test ./internal/protocol/...
+//
+// Values that differ between runs, such as server timestamps, are written as
+// "<any>" in fixtures and are kept when fixtures are regenerated.
+package protocol
@@ -0,0 +1,142 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// eventJournalTTL drops the journal of maps that stopped receiving broadcasts
+const eventJournalTTL = 24 * time.Hour
+
+// appendEventScript numbers an event and adds it to the capped stream atomically.
+// Entry IDs are "<seq>-0", so replays can range over sequence numbers directly.
+const appendEventScript = `
+	local seq = redis.call('INCR', KEYS[1])
+	if seq == 1 then
+		-- A restarted counter must not collide with entries left in the stream
+		redis.call('DEL', KEYS[2])
+	end
+	redis.call('XADD', KEYS[2], 'MAXLEN', ARGV[1], seq .. '-0', 'type', ARGV[2], 'data', ARGV[3], 'ts', ARGV[4])
+	redis.call('EXPIRE', KEYS[1], ARGV[5])
+	redis.call('EXPIRE', KEYS[2], ARGV[5])
+	return seq
+`
+
+// EventJournal keeps the last broadcasts of each map in a capped Redis stream,
+// so clients that missed some can have them replayed instead of reloading the
+// whole map, and so the recent history of a map can be inspected.
+//
+// Journals are kept per server instance: most broadcasts only reach the clients
+// connected to the instance sending them, and events delivered through PubSub
+// are broadcast by every instance.
+type EventJournal struct {
+	client     *redis.Client
+	instanceID string
+	maxLen     int64
+}
+
+// NewEventJournal creates a new EventJournal keeping maxLen events per map
+func NewEventJournal(client *redis.Client, instanceID string, maxLen int64) *EventJournal {
+	return &EventJournal{
+		client:     client,
+		instanceID: instanceID,
+		maxLen:     maxLen,
+	}
+}
+
+// Append records a broadcast and returns its sequence number in the map's journal
+func (ej *EventJournal) Append(ctx context.Context, mapID, eventType string, data []byte) (uint64, error) {
+	keys := []string{ej.getSequenceKey(mapID), ej.getStreamKey(mapID)}
+	result, err := ej.client.Eval(ctx, appendEventScript, keys,
+		ej.maxLen, eventType, data, time.Now().UnixMilli(), int64(eventJournalTTL.Seconds())).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to append map event: %w", err)
+	}
+
+	return uint64(result), nil
+}
+
+// ReadAfter returns the events of a map following the given sequence number.
+// It returns false if some of them are no longer in the journal, in which case
+// the client needs a full snapshot instead.
+func (ej *EventJournal) ReadAfter(ctx context.Context, mapID string, seq uint64) ([]models.MapEvent, bool, error) {
+	last, err := ej.client.Get(ctx, ej.getSequenceKey(mapID)).Uint64()
+	if err == redis.Nil {
+		// The journal expired; sequence numbers the client knows are meaningless
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get map event sequence: %w", err)
+	}
+	if seq > last {
+		return nil, false, nil
+	}
+	if seq == last {
+		return []models.MapEvent{}, true, nil
+	}
+
+	messages, err := ej.client.XRange(ctx, ej.getStreamKey(mapID), fmt.Sprintf("%d-0", seq+1), "+").Result()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read map events: %w", err)
+	}
+
+	events := ej.parseEvents(messages)
+	if len(events) == 0 || events[0].Seq != seq+1 {
+		// Trimmed from the capped stream
+		return nil, false, nil
+	}
+
+	return events, true, nil
+}
+
+// Recent returns up to count of the latest events of a map, newest first
+func (ej *EventJournal) Recent(ctx context.Context, mapID string, count int64) ([]models.MapEvent, error) {
+	messages, err := ej.client.XRevRangeN(ctx, ej.getStreamKey(mapID), "+", "-", count).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read map events: %w", err)
+	}
+
+	return ej.parseEvents(messages), nil
+}
+
+// parseEvents converts stream entries to map events, skipping malformed entries
+func (ej *EventJournal) parseEvents(messages []redis.XMessage) []models.MapEvent {
+	events := make([]models.MapEvent, 0, len(messages))
+	for _, message := range messages {
+		seq, err := strconv.ParseUint(strings.TrimSuffix(message.ID, "-0"), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		event := models.MapEvent{Seq: seq}
+		event.Type, _ = message.Values["type"].(string)
+		if data, ok := message.Values["data"].(string); ok {
+			event.Data = json.RawMessage(data)
+		}
+		if ts, ok := message.Values["ts"].(string); ok {
+			if millis, err := strconv.ParseInt(ts, 10, 64); err == nil {
+				event.Timestamp = time.UnixMilli(millis)
+			}
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// getStreamKey returns the Redis key of a map's event stream
+func (ej *EventJournal) getStreamKey(mapID string) string {
+	return fmt.Sprintf("map:%s:journal:%s", mapID, ej.instanceID)
+}
+
+// getSequenceKey returns the Redis key of a map's event sequence counter
+func (ej *EventJournal) getSequenceKey(mapID string) string {
+	return fmt.Sprintf("map:%s:journal:%s:seq", mapID, ej.instanceID)
+}
@@ -0,0 +1,110 @@
+package redis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/suite"
+)
+
+type EventJournalTestSuite struct {
+	suite.Suite
+	client  *redis.Client
+	journal *EventJournal
+}
+
+func (suite *EventJournalTestSuite) SetupSuite() {
+	// Skip integration tests in short mode
+	if testing.Short() {
+		suite.T().Skip("Skipping Redis integration test in short mode")
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr: "localhost:6379",
+		DB:   1, // Use DB 1 for tests to avoid conflicts
+	})
+
+	_, err := client.Ping(context.Background()).Result()
+	suite.Require().NoError(err, "Redis connection failed - make sure Redis is running")
+
+	suite.client = client
+	suite.journal = NewEventJournal(client, "instance-1", 3)
+}
+
+func (suite *EventJournalTestSuite) SetupTest() {
+	suite.client.FlushDB(context.Background())
+}
+
+func (suite *EventJournalTestSuite) TearDownSuite() {
+	if suite.client != nil {
+		suite.client.Close()
+	}
+}
+
+func (suite *EventJournalTestSuite) TestAppendAndReadAfter() {
+	ctx := context.Background()
+
+	for i := 1; i <= 3; i++ {
+		seq, err := suite.journal.Append(ctx, "map-1", "poi_created", []byte(`{"poiId":"poi-1"}`))
+		suite.Require().NoError(err)
+		suite.Equal(uint64(i), seq)
+	}
+
+	events, complete, err := suite.journal.ReadAfter(ctx, "map-1", 1)
+	suite.Require().NoError(err)
+	suite.True(complete)
+	suite.Require().Len(events, 2)
+	suite.Equal(uint64(2), events[0].Seq)
+	suite.Equal("poi_created", events[0].Type)
+	suite.JSONEq(`{"poiId":"poi-1"}`, string(events[0].Data))
+
+	// Nothing was missed
+	events, complete, err = suite.journal.ReadAfter(ctx, "map-1", 3)
+	suite.Require().NoError(err)
+	suite.True(complete)
+	suite.Empty(events)
+}
+
+func (suite *EventJournalTestSuite) TestReadAfter_Trimmed() {
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		_, err := suite.journal.Append(ctx, "map-1", "poi_updated", []byte(`{}`))
+		suite.Require().NoError(err)
+	}
+
+	// Only the last three events are kept
+	_, complete, err := suite.journal.ReadAfter(ctx, "map-1", 1)
+	suite.Require().NoError(err)
+	suite.False(complete)
+
+	events, complete, err := suite.journal.ReadAfter(ctx, "map-1", 2)
+	suite.Require().NoError(err)
+	suite.True(complete)
+	suite.Len(events, 3)
+
+	// Unknown maps and sequence numbers from an expired journal need a snapshot
+	_, complete, err = suite.journal.ReadAfter(ctx, "map-2", 1)
+	suite.Require().NoError(err)
+	suite.False(complete)
+}
+
+func (suite *EventJournalTestSuite) TestRecent() {
+	ctx := context.Background()
+
+	for _, eventType := range []string{"poi_created", "poi_updated", "poi_deleted"} {
+		_, err := suite.journal.Append(ctx, "map-1", eventType, []byte(`{}`))
+		suite.Require().NoError(err)
+	}
+
+	events, err := suite.journal.Recent(ctx, "map-1", 2)
+	suite.Require().NoError(err)
+	suite.Require().Len(events, 2)
+	suite.Equal("poi_deleted", events[0].Type)
+	suite.Equal("poi_updated", events[1].Type)
+}
+
+func TestEventJournalTestSuite(t *testing.T) {
+	suite.Run(t, new(EventJournalTestSuite))
+}
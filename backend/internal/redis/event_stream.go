@@ -0,0 +1,187 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// eventStreamKey is the stream all instances read map events from
+	eventStreamKey = "events:stream"
+	// eventStreamMaxLen bounds the stream; an instance that falls further
+	// behind than this misses the trimmed events
+	eventStreamMaxLen = 10000
+	// eventStreamBlock is how long a read waits for new events
+	eventStreamBlock = 5 * time.Second
+	// eventStreamRetryDelay is the pause after a failed read, e.g. while Redis is unreachable
+	eventStreamRetryDelay = time.Second
+	eventStreamBatchSize  = 100
+)
+
+// EventStream distributes map events to all server instances through a Redis
+// stream. Each instance reads with its own consumer group and acknowledges
+// events once handled, so events published while an instance was briefly
+// disconnected are delivered when it reconnects. Delivery is at least once:
+// an event handled right before a crash may be handled again after a restart.
+type EventStream struct {
+	client   *redis.Client
+	group    string
+	consumer string
+	logger   *slog.Logger
+}
+
+// NewEventStream creates a new EventStream reading with the instance's consumer
+// group. The instance ID must be stable across restarts to resume reading
+// where the instance stopped.
+func NewEventStream(client *redis.Client, instanceID string) *EventStream {
+	return &EventStream{
+		client:   client,
+		group:    "instance:" + instanceID,
+		consumer: instanceID,
+		logger:   slog.Default(),
+	}
+}
+
+// Add appends a serialized event to the stream
+func (es *EventStream) Add(ctx context.Context, eventJSON []byte) error {
+	err := es.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: eventStreamKey,
+		MaxLen: eventStreamMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{"event": eventJSON},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to add event to stream: %w", err)
+	}
+	return nil
+}
+
+// Consume calls handle for every event in the stream until the context is
+// cancelled. It first redelivers events this instance read but didn't
+// acknowledge before, then waits for new ones. Read errors are retried.
+func (es *EventStream) Consume(ctx context.Context, handle func(Event)) error {
+	if err := es.ensureGroup(ctx); err != nil {
+		return err
+	}
+
+	// "0" reads this consumer's pending events, ">" new ones
+	start := "0"
+	for {
+		streams, err := es.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    es.group,
+			Consumer: es.consumer,
+			Streams:  []string{eventStreamKey, start},
+			Count:    eventStreamBatchSize,
+			Block:    eventStreamBlock,
+		}).Result()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			es.logger.Warn("Failed to read event stream, retrying", "group", es.group, "error", err)
+			if strings.HasPrefix(err.Error(), "NOGROUP") {
+				// The stream or group was deleted, e.g. by a Redis flush
+				es.ensureGroup(ctx)
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(eventStreamRetryDelay):
+			}
+			continue
+		}
+
+		messages := streams[0].Messages
+		if start != ">" {
+			if len(messages) == 0 {
+				start = ">"
+				continue
+			}
+			start = messages[len(messages)-1].ID
+		}
+
+		for _, message := range messages {
+			es.handleMessage(message, handle)
+			if err := es.client.XAck(ctx, eventStreamKey, es.group, message.ID).Err(); err != nil {
+				es.logger.Warn("Failed to acknowledge event", "group", es.group, "id", message.ID, "error", err)
+			}
+		}
+	}
+}
+
+// handleMessage decodes a stream entry and passes it on. Malformed entries are
+// skipped so they don't block the stream.
+func (es *EventStream) handleMessage(message redis.XMessage, handle func(Event)) {
+	payload, ok := message.Values["event"].(string)
+	if !ok {
+		return
+	}
+
+	var event Event
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		es.logger.Warn("Skipping malformed event", "id", message.ID, "error", err)
+		return
+	}
+	handle(event)
+}
+
+// ensureGroup creates the instance's consumer group starting at new events,
+// unless it already exists
+func (es *EventStream) ensureGroup(ctx context.Context) error {
+	err := es.client.XGroupCreateMkStream(ctx, eventStreamKey, es.group, "$").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create consumer group %s: %w", es.group, err)
+	}
+	return nil
+}
+
+// PruneGroups removes the consumer groups of other instances that haven't
+// read for maxIdle, e.g. after scaling down, and returns how many were removed.
+// Their pending events would otherwise be kept forever.
+func (es *EventStream) PruneGroups(ctx context.Context, maxIdle time.Duration) (int, error) {
+	groups, err := es.client.XInfoGroups(ctx, eventStreamKey).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list consumer groups: %w", err)
+	}
+
+	pruned := 0
+	for _, group := range groups {
+		if group.Name == es.group {
+			continue
+		}
+
+		consumers, err := es.client.XInfoConsumers(ctx, eventStreamKey, group.Name).Result()
+		if err != nil {
+			return pruned, fmt.Errorf("failed to list consumers of %s: %w", group.Name, err)
+		}
+		if !allConsumersIdle(consumers, maxIdle) {
+			continue
+		}
+
+		if err := es.client.XGroupDestroy(ctx, eventStreamKey, group.Name).Err(); err != nil {
+			return pruned, fmt.Errorf("failed to remove consumer group %s: %w", group.Name, err)
+		}
+		pruned++
+	}
+	return pruned, nil
+}
+
+// allConsumersIdle reports whether no consumer of a group read within maxIdle
+func allConsumersIdle(consumers []redis.XInfoConsumer, maxIdle time.Duration) bool {
+	for _, consumer := range consumers {
+		if consumer.Idle < maxIdle {
+			return false
+		}
+	}
+	return true
+}
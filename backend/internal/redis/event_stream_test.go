@@ -0,0 +1,136 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type EventStreamTestSuite struct {
+	suite.Suite
+	client *redis.Client
+}
+
+func (suite *EventStreamTestSuite) SetupSuite() {
+	// Skip integration tests in short mode
+	if testing.Short() {
+		suite.T().Skip("Skipping Redis integration test in short mode")
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr: "localhost:6379",
+		DB:   1, // Use DB 1 for tests to avoid conflicts
+	})
+
+	_, err := client.Ping(context.Background()).Result()
+	suite.Require().NoError(err, "Redis connection failed - make sure Redis is running")
+
+	suite.client = client
+}
+
+func (suite *EventStreamTestSuite) SetupTest() {
+	suite.client.FlushDB(context.Background())
+}
+
+func (suite *EventStreamTestSuite) TearDownSuite() {
+	if suite.client != nil {
+		suite.client.Close()
+	}
+}
+
+// consume collects the POI event types delivered to an instance until n arrived
+func (suite *EventStreamTestSuite) consume(pubsub *PubSub, n int) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var received []string
+	pubsub.SubscribePOIEvents(ctx, func(eventType string, data interface{}) {
+		received = append(received, eventType)
+		if len(received) == n {
+			cancel()
+		}
+	})
+	return received
+}
+
+func (suite *EventStreamTestSuite) TestDeliversToEveryInstance() {
+	ctx := context.Background()
+	first := NewEventStream(suite.client, "instance-1")
+	second := NewEventStream(suite.client, "instance-2")
+	suite.Require().NoError(first.ensureGroup(ctx))
+	suite.Require().NoError(second.ensureGroup(ctx))
+
+	publisher := NewPubSub(suite.client)
+	publisher.SetEventStream(first)
+
+	// Published while neither instance is reading
+	suite.Require().NoError(publisher.PublishPOICreated(ctx, POICreatedEvent{POIID: "poi-1", MapID: "map-1"}))
+	suite.Require().NoError(publisher.PublishPOIDeleted(ctx, POIDeletedEvent{POIID: "poi-1", MapID: "map-1"}))
+	// Avatar movement isn't streamed
+	suite.Require().NoError(publisher.PublishAvatarMovement(ctx, AvatarMovementEvent{MapID: "map-1"}))
+
+	for _, stream := range []*EventStream{first, second} {
+		subscriber := NewPubSub(suite.client)
+		subscriber.SetEventStream(stream)
+		suite.Equal([]string{"poi_created", "poi_deleted"}, suite.consume(subscriber, 2))
+	}
+
+	// Acknowledged events aren't delivered again
+	pending, err := suite.client.XPending(ctx, eventStreamKey, first.group).Result()
+	suite.Require().NoError(err)
+	suite.Zero(pending.Count)
+}
+
+func (suite *EventStreamTestSuite) TestRedeliversUnacknowledgedEvents() {
+	ctx := context.Background()
+	stream := NewEventStream(suite.client, "instance-1")
+	suite.Require().NoError(stream.ensureGroup(ctx))
+
+	publisher := NewPubSub(suite.client)
+	publisher.SetEventStream(stream)
+	suite.Require().NoError(publisher.PublishPOIUpdated(ctx, POIUpdatedEvent{POIID: "poi-1", MapID: "map-1"}))
+
+	// Read but not acknowledged, e.g. the instance crashed while handling it
+	_, err := suite.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    stream.group,
+		Consumer: stream.consumer,
+		Streams:  []string{eventStreamKey, ">"},
+	}).Result()
+	suite.Require().NoError(err)
+
+	subscriber := NewPubSub(suite.client)
+	subscriber.SetEventStream(stream)
+	suite.Equal([]string{"poi_updated"}, suite.consume(subscriber, 1))
+}
+
+func (suite *EventStreamTestSuite) TestPruneGroups() {
+	ctx := context.Background()
+	stream := NewEventStream(suite.client, "instance-1")
+	gone := NewEventStream(suite.client, "instance-2")
+	suite.Require().NoError(stream.ensureGroup(ctx))
+	suite.Require().NoError(gone.ensureGroup(ctx))
+
+	pruned, err := stream.PruneGroups(ctx, time.Hour)
+	suite.Require().NoError(err)
+	// The other group has no consumers yet, so it counts as idle
+	suite.Equal(1, pruned)
+
+	groups, err := suite.client.XInfoGroups(ctx, eventStreamKey).Result()
+	suite.Require().NoError(err)
+	suite.Require().Len(groups, 1)
+	suite.Equal(stream.group, groups[0].Name)
+}
+
+func TestEventStreamTestSuite(t *testing.T) {
+	suite.Run(t, new(EventStreamTestSuite))
+}
+
+func TestAllConsumersIdle(t *testing.T) {
+	assert.True(t, allConsumersIdle(nil, time.Hour))
+	assert.True(t, allConsumersIdle([]redis.XInfoConsumer{{Idle: 2 * time.Hour}}, time.Hour))
+	assert.False(t, allConsumersIdle([]redis.XInfoConsumer{{Idle: 2 * time.Hour}, {Idle: time.Minute}}, time.Hour))
+}
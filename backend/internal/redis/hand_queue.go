@@ -0,0 +1,82 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// handQueueTTL drops the raise-hand queue of a map nobody raised a hand on
+// for a day, so queues of finished workshops don't pile up
+const handQueueTTL = 24 * time.Hour
+
+// HandQueue keeps the raise-hand queue of each map in Redis, as a sorted set
+// of user IDs scored by when they raised their hand
+type HandQueue struct {
+	client *redis.Client
+}
+
+// NewHandQueue creates a new HandQueue instance
+func NewHandQueue(client *redis.Client) *HandQueue {
+	return &HandQueue{
+		client: client,
+	}
+}
+
+// RaiseHand adds a user to the end of a map's queue. It returns false if the
+// user's hand was already raised, keeping their place.
+func (hq *HandQueue) RaiseHand(ctx context.Context, mapID, userID string, at time.Time) (bool, error) {
+	key := hq.getQueueKey(mapID)
+
+	pipe := hq.client.TxPipeline()
+	added := pipe.ZAddNX(ctx, key, redis.Z{Score: float64(at.UnixMilli()), Member: userID})
+	pipe.Expire(ctx, key, handQueueTTL)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return false, fmt.Errorf("failed to raise hand: %w", err)
+	}
+
+	return added.Val() > 0, nil
+}
+
+// LowerHand removes a user from a map's queue. It returns false if the user's
+// hand wasn't raised.
+func (hq *HandQueue) LowerHand(ctx context.Context, mapID, userID string) (bool, error) {
+	removed, err := hq.client.ZRem(ctx, hq.getQueueKey(mapID), userID).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to lower hand: %w", err)
+	}
+
+	return removed > 0, nil
+}
+
+// GetHands returns the raised hands of a map, the earliest first
+func (hq *HandQueue) GetHands(ctx context.Context, mapID string) ([]models.RaisedHand, error) {
+	entries, err := hq.client.ZRangeWithScores(ctx, hq.getQueueKey(mapID), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get raised hands: %w", err)
+	}
+
+	hands := make([]models.RaisedHand, 0, len(entries))
+	for _, entry := range entries {
+		userID, ok := entry.Member.(string)
+		if !ok {
+			continue
+		}
+		hands = append(hands, models.RaisedHand{
+			UserID:   userID,
+			RaisedAt: time.UnixMilli(int64(entry.Score)),
+		})
+	}
+
+	return hands, nil
+}
+
+// getQueueKey generates the Redis key for a map's raise-hand queue
+func (hq *HandQueue) getQueueKey(mapID string) string {
+	return fmt.Sprintf("map:%s:hands", mapID)
+}
@@ -0,0 +1,141 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// heatmapCounterTTL keeps raw counters around long enough for the scheduler to
+// roll them up even after a few missed runs
+const heatmapCounterTTL = 48 * time.Hour
+
+// heatmapPendingKey is the set of buckets with counters that were not rolled up yet
+const heatmapPendingKey = "heatmap:pending"
+
+// HeatmapBucket identifies the raw counters of one map and time bucket
+type HeatmapBucket struct {
+	MapID       string
+	BucketStart time.Time
+}
+
+// HeatmapCellCount is the raw count of one grid cell
+type HeatmapCellCount struct {
+	Row   int
+	Col   int
+	Count int64
+}
+
+// HeatmapCounter counts avatar positions per map, time bucket and grid cell in Redis.
+// The counters are cheap to increment on every move and are periodically rolled
+// up into the database.
+type HeatmapCounter struct {
+	client *redis.Client
+}
+
+// NewHeatmapCounter creates a new HeatmapCounter instance
+func NewHeatmapCounter(client *redis.Client) *HeatmapCounter {
+	return &HeatmapCounter{
+		client: client,
+	}
+}
+
+// IncrementCell counts one avatar position in a grid cell
+func (hc *HeatmapCounter) IncrementCell(ctx context.Context, bucket HeatmapBucket, row, col int) error {
+	key := hc.getBucketKey(bucket)
+
+	pipe := hc.client.Pipeline()
+	pipe.HIncrBy(ctx, key, fmt.Sprintf("%d:%d", row, col), 1)
+	pipe.Expire(ctx, key, heatmapCounterTTL)
+	pipe.SAdd(ctx, heatmapPendingKey, hc.getBucketMember(bucket))
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to increment heatmap cell: %w", err)
+	}
+
+	return nil
+}
+
+// GetPendingBuckets returns all buckets with counters that were not rolled up yet
+func (hc *HeatmapCounter) GetPendingBuckets(ctx context.Context) ([]HeatmapBucket, error) {
+	members, err := hc.client.SMembers(ctx, heatmapPendingKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending heatmap buckets: %w", err)
+	}
+
+	buckets := make([]HeatmapBucket, 0, len(members))
+	for _, member := range members {
+		bucket, ok := hc.parseBucketMember(member)
+		if !ok {
+			continue
+		}
+		buckets = append(buckets, bucket)
+	}
+
+	return buckets, nil
+}
+
+// GetBucketCounts returns the raw cell counts of a bucket
+func (hc *HeatmapCounter) GetBucketCounts(ctx context.Context, bucket HeatmapBucket) ([]HeatmapCellCount, error) {
+	fields, err := hc.client.HGetAll(ctx, hc.getBucketKey(bucket)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get heatmap bucket: %w", err)
+	}
+
+	counts := make([]HeatmapCellCount, 0, len(fields))
+	for field, value := range fields {
+		var row, col int
+		if _, err := fmt.Sscanf(field, "%d:%d", &row, &col); err != nil {
+			continue
+		}
+		count, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		counts = append(counts, HeatmapCellCount{Row: row, Col: col, Count: count})
+	}
+
+	return counts, nil
+}
+
+// DeleteBucket removes the raw counters of a bucket after it was rolled up
+func (hc *HeatmapCounter) DeleteBucket(ctx context.Context, bucket HeatmapBucket) error {
+	pipe := hc.client.Pipeline()
+	pipe.Del(ctx, hc.getBucketKey(bucket))
+	pipe.SRem(ctx, heatmapPendingKey, hc.getBucketMember(bucket))
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to delete heatmap bucket: %w", err)
+	}
+
+	return nil
+}
+
+// getBucketKey returns the Redis key of a bucket's cell counters
+func (hc *HeatmapCounter) getBucketKey(bucket HeatmapBucket) string {
+	return fmt.Sprintf("heatmap:%s", hc.getBucketMember(bucket))
+}
+
+// getBucketMember returns the pending set member of a bucket
+func (hc *HeatmapCounter) getBucketMember(bucket HeatmapBucket) string {
+	return fmt.Sprintf("%s:%d", bucket.MapID, bucket.BucketStart.Unix())
+}
+
+// parseBucketMember parses a pending set member back into a bucket
+func (hc *HeatmapCounter) parseBucketMember(member string) (HeatmapBucket, bool) {
+	separator := strings.LastIndex(member, ":")
+	if separator <= 0 {
+		return HeatmapBucket{}, false
+	}
+
+	unix, err := strconv.ParseInt(member[separator+1:], 10, 64)
+	if err != nil {
+		return HeatmapBucket{}, false
+	}
+
+	return HeatmapBucket{MapID: member[:separator], BucketStart: time.Unix(unix, 0).UTC()}, true
+}
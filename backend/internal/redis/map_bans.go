@@ -0,0 +1,66 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// MapBans keeps the users banned from each map in Redis. Every ban is a key
+// expiring with the ban, so bans lift themselves.
+type MapBans struct {
+	client *redis.Client
+}
+
+// NewMapBans creates a new MapBans instance
+func NewMapBans(client *redis.Client) *MapBans {
+	return &MapBans{
+		client: client,
+	}
+}
+
+// AddMapBan bans a user from a map until the ban's end, replacing an earlier
+// ban of the user
+func (mb *MapBans) AddMapBan(ctx context.Context, ban models.MapBan) error {
+	ttl := time.Until(ban.Until)
+	if ttl <= 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(ban)
+	if err != nil {
+		return fmt.Errorf("failed to marshal map ban: %w", err)
+	}
+	if err := mb.client.Set(ctx, mb.getBanKey(ban.MapID, ban.UserID), data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to add map ban: %w", err)
+	}
+	return nil
+}
+
+// GetMapBan returns the ban of a user from a map, or nil if they aren't banned
+func (mb *MapBans) GetMapBan(ctx context.Context, mapID, userID string) (*models.MapBan, error) {
+	data, err := mb.client.Get(ctx, mb.getBanKey(mapID, userID)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get map ban: %w", err)
+	}
+
+	var ban models.MapBan
+	if err := json.Unmarshal(data, &ban); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal map ban: %w", err)
+	}
+	return &ban, nil
+}
+
+// getBanKey generates the Redis key for a user's ban from a map
+func (mb *MapBans) getBanKey(mapID, userID string) string {
+	return fmt.Sprintf("map:%s:ban:%s", mapID, userID)
+}
@@ -2,11 +2,17 @@ package redis
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// ErrPOIAtCapacity is returned when a POI has no room for another participant
+var ErrPOIAtCapacity = errors.New("POI is at capacity")
+
 // POIParticipants manages POI participant tracking using Redis sets
 type POIParticipants struct {
 	client *redis.Client
@@ -19,11 +25,14 @@ func NewPOIParticipants(client *redis.Client) *POIParticipants {
 	}
 }
 
-// JoinPOI adds a session to a POI's participant set
+// JoinPOI adds a session to a POI's participant set, recording when it joined
+// unless it already had
 func (pp *POIParticipants) JoinPOI(ctx context.Context, poiID, sessionID string) error {
-	key := pp.getPOIParticipantsKey(poiID)
+	pipe := pp.client.TxPipeline()
+	pipe.SAdd(ctx, pp.getPOIParticipantsKey(poiID), sessionID)
+	pipe.HSetNX(ctx, pp.getJoinTimesKey(poiID), sessionID, time.Now().UnixMilli())
 	
-	err := pp.client.SAdd(ctx, key, sessionID).Err()
+	_, err := pipe.Exec(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to add participant to POI: %w", err)
 	}
@@ -33,9 +42,11 @@ func (pp *POIParticipants) JoinPOI(ctx context.Context, poiID, sessionID string)
 
 // LeavePOI removes a session from a POI's participant set
 func (pp *POIParticipants) LeavePOI(ctx context.Context, poiID, sessionID string) error {
-	key := pp.getPOIParticipantsKey(poiID)
+	pipe := pp.client.TxPipeline()
+	pipe.SRem(ctx, pp.getPOIParticipantsKey(poiID), sessionID)
+	pipe.HDel(ctx, pp.getJoinTimesKey(poiID), sessionID)
 	
-	err := pp.client.SRem(ctx, key, sessionID).Err()
+	_, err := pipe.Exec(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to remove participant from POI: %w", err)
 	}
@@ -96,8 +107,10 @@ func (pp *POIParticipants) JoinPOIWithCapacityCheck(ctx context.Context, poiID,
 	// Use a Lua script to atomically check capacity and add participant
 	script := `
 		local key = KEYS[1]
+		local joinTimesKey = KEYS[2]
 		local sessionID = ARGV[1]
 		local maxParticipants = tonumber(ARGV[2])
+		local joinedAt = ARGV[3]
 		
 		-- Check if already a member
 		if redis.call('SISMEMBER', key, sessionID) == 1 then
@@ -112,17 +125,19 @@ func (pp *POIParticipants) JoinPOIWithCapacityCheck(ctx context.Context, poiID,
 		
 		-- Add participant
 		redis.call('SADD', key, sessionID)
+		redis.call('HSET', joinTimesKey, sessionID, joinedAt)
 		return 1  -- Successfully added
 	`
 	
-	result, err := pp.client.Eval(ctx, script, []string{key}, sessionID, maxParticipants).Result()
+	keys := []string{key, pp.getJoinTimesKey(poiID)}
+	result, err := pp.client.Eval(ctx, script, keys, sessionID, maxParticipants, time.Now().UnixMilli()).Result()
 	if err != nil {
 		return fmt.Errorf("failed to execute join with capacity check: %w", err)
 	}
 	
 	switch result.(int64) {
 	case -1:
-		return fmt.Errorf("POI is at capacity (max: %d participants)", maxParticipants)
+		return fmt.Errorf("%w (max: %d participants)", ErrPOIAtCapacity, maxParticipants)
 	case 0, 1:
 		return nil // Success (already member or newly added)
 	default:
@@ -132,10 +147,8 @@ func (pp *POIParticipants) JoinPOIWithCapacityCheck(ctx context.Context, poiID,
 
 // RemoveAllParticipants removes all participants from a POI
 func (pp *POIParticipants) RemoveAllParticipants(ctx context.Context, poiID string) error {
-	key := pp.getPOIParticipantsKey(poiID)
-	
-	// Delete the entire set
-	err := pp.client.Del(ctx, key).Err()
+	// Delete the entire set and its join times
+	err := pp.client.Del(ctx, pp.getPOIParticipantsKey(poiID), pp.getJoinTimesKey(poiID)).Err()
 	if err != nil {
 		return fmt.Errorf("failed to remove all participants: %w", err)
 	}
@@ -143,6 +156,89 @@ func (pp *POIParticipants) RemoveAllParticipants(ctx context.Context, poiID stri
 	return nil
 }
 
+// GetJoinTimes returns when each participant of a POI joined it. Sessions
+// that joined before join times were recorded are missing.
+func (pp *POIParticipants) GetJoinTimes(ctx context.Context, poiID string) (map[string]time.Time, error) {
+	values, err := pp.client.HGetAll(ctx, pp.getJoinTimesKey(poiID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get join times: %w", err)
+	}
+	
+	joinTimes := make(map[string]time.Time, len(values))
+	for sessionID, value := range values {
+		millis, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		joinTimes[sessionID] = time.UnixMilli(millis)
+	}
+	
+	return joinTimes, nil
+}
+
+// NextRosterSequence increments and returns the roster sequence number of a
+// POI. Every change to the participant set gets the next number.
+func (pp *POIParticipants) NextRosterSequence(ctx context.Context, poiID string) (int64, error) {
+	seq, err := pp.client.Incr(ctx, pp.getRosterSequenceKey(poiID)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment roster sequence: %w", err)
+	}
+	
+	return seq, nil
+}
+
+// GetRosterSequence returns the current roster sequence number of a POI, or 0
+// if its roster has never changed
+func (pp *POIParticipants) GetRosterSequence(ctx context.Context, poiID string) (int64, error) {
+	seq, err := pp.client.Get(ctx, pp.getRosterSequenceKey(poiID)).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get roster sequence: %w", err)
+	}
+	
+	return seq, nil
+}
+
+// RecordJoin records that a user joined a POI in a sorted set scored by the
+// time of their last join, so users joining again are counted once. Joins
+// older than the window are dropped, and the set expires with the last one.
+func (pp *POIParticipants) RecordJoin(ctx context.Context, poiID, userID string, at time.Time, window time.Duration) error {
+	key := pp.getRecentJoinsKey(poiID)
+	pipe := pp.client.TxPipeline()
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(at.UnixMilli()), Member: userID})
+	pipe.ZRemRangeByScore(ctx, key, "-inf", "("+strconv.FormatInt(at.Add(-window).UnixMilli(), 10))
+	pipe.Expire(ctx, key, window)
+	
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record POI join: %w", err)
+	}
+	
+	return nil
+}
+
+// CountJoinsSince counts the users who last joined each POI at or after since
+func (pp *POIParticipants) CountJoinsSince(ctx context.Context, poiIDs []string, since time.Time) (map[string]int, error) {
+	min := strconv.FormatInt(since.UnixMilli(), 10)
+	pipe := pp.client.Pipeline()
+	counts := make([]*redis.IntCmd, len(poiIDs))
+	for i, poiID := range poiIDs {
+		counts[i] = pipe.ZCount(ctx, pp.getRecentJoinsKey(poiID), min, "+inf")
+	}
+	
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to count recent POI joins: %w", err)
+	}
+	
+	joins := make(map[string]int, len(poiIDs))
+	for i, poiID := range poiIDs {
+		joins[poiID] = int(counts[i].Val())
+	}
+	
+	return joins, nil
+}
+
 // RemoveParticipantFromAllPOIs removes a session from all POIs they're participating in
 func (pp *POIParticipants) RemoveParticipantFromAllPOIs(ctx context.Context, sessionID string) error {
 	// Get all POI participant keys
@@ -159,9 +255,12 @@ func (pp *POIParticipants) RemoveParticipantFromAllPOIs(ctx context.Context, ses
 	// Use a pipeline for efficiency
 	pipe := pp.client.Pipeline()
 	
-	// Add SREM commands for each POI
+	// Add SREM commands for each POI, and drop the session's join times
 	for _, key := range keys {
 		pipe.SRem(ctx, key, sessionID)
+		if poiID := pp.extractPOIIDFromKey(key); poiID != "" {
+			pipe.HDel(ctx, pp.getJoinTimesKey(poiID), sessionID)
+		}
 	}
 	
 	// Execute pipeline
@@ -222,6 +321,24 @@ func (pp *POIParticipants) getPOIParticipantsKey(poiID string) string {
 	return fmt.Sprintf("poi:participants:%s", poiID)
 }
 
+// getJoinTimesKey returns the Redis key of the hash of a POI's join times,
+// outside the poi:participants:* namespace like the roster sequence
+func (pp *POIParticipants) getJoinTimesKey(poiID string) string {
+	return fmt.Sprintf("poi:joined_at:%s", poiID)
+}
+
+// getRecentJoinsKey returns the Redis key of the sorted set of users who
+// recently joined a POI, outside the poi:participants:* namespace
+func (pp *POIParticipants) getRecentJoinsKey(poiID string) string {
+	return fmt.Sprintf("poi:recent_joins:%s", poiID)
+}
+
+// getRosterSequenceKey returns the Redis key for a POI's roster sequence.
+// It deliberately lives outside the poi:participants:* namespace scanned above.
+func (pp *POIParticipants) getRosterSequenceKey(poiID string) string {
+	return fmt.Sprintf("poi:roster_seq:%s", poiID)
+}
+
 // extractPOIIDFromKey extracts the POI ID from a Redis key
 func (pp *POIParticipants) extractPOIIDFromKey(key string) string {
 	prefix := "poi:participants:"
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"time"
 
+	"breakoutglobe/internal/models"
+
 	"github.com/redis/go-redis/v9"
 )
 
@@ -18,6 +20,31 @@ const (
 	EventTypePOIUpdated     EventType = "poi_updated"
 	EventTypePOIJoined      EventType = "poi_joined"
 	EventTypePOILeft        EventType = "poi_left"
+	EventTypePOIDeleted     EventType = "poi_deleted"
+	EventTypePOIMerged      EventType = "poi_merged"
+
+	EventTypePOIParticipantAdded   EventType = "poi_participant_added"
+	EventTypePOIParticipantRemoved EventType = "poi_participant_removed"
+
+	EventTypeUserProfileUpdated EventType = "user_profile_updated"
+	EventTypeUserRoleChanged    EventType = "role_changed"
+
+	EventTypeMapFrozen   EventType = "map_frozen"
+	EventTypeMapUnfrozen EventType = "map_unfrozen"
+
+	EventTypeOnboardingProgress EventType = "onboarding_progress"
+
+	EventTypeMaintenanceChanged EventType = "maintenance_changed"
+
+	EventTypeHandQueueUpdated EventType = "hand_queue_updated"
+
+	EventTypeSummonedToPOI EventType = "summoned_to_poi"
+
+	EventTypeUserKicked EventType = "user_kicked"
+
+	EventTypeMapDeleted EventType = "map_deleted"
+
+	EventTypePOIReminder EventType = "poi_reminder"
 )
 
 // LatLng represents a geographic coordinate
@@ -59,20 +86,44 @@ type POICreatedEvent struct {
 
 // POIUpdatedEvent represents a POI being updated
 type POIUpdatedEvent struct {
-	POIID           string    `json:"poiId"`
-	MapID           string    `json:"mapId"`
-	Name            string    `json:"name"`
-	Description     string    `json:"description"`
-	MaxParticipants int       `json:"maxParticipants"`
-	CurrentCount    int       `json:"currentCount"`
-	Timestamp       time.Time `json:"timestamp"`
+	POIID           string     `json:"poiId"`
+	MapID           string     `json:"mapId"`
+	Name            string     `json:"name"`
+	Description     string     `json:"description"`
+	MaxParticipants int        `json:"maxParticipants"`
+	CurrentCount    int        `json:"currentCount"`
+	StartsAt        *time.Time `json:"startsAt,omitempty"`
+	// PopularSince counts who joined the POI recently, if joins are recorded
+	PopularSince    *models.POIPopularity `json:"popularSince,omitempty"`
+	Timestamp       time.Time  `json:"timestamp"`
+}
+
+// POIDeletedEvent represents a POI being deleted
+type POIDeletedEvent struct {
+	POIID     string    `json:"poiId"`
+	MapID     string    `json:"mapId"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// POIMergedEvent represents a POI being merged into another one on its map.
+// MovedUserIDs lists the participants of the source POI that are now in the
+// target POI; the source POI no longer exists.
+type POIMergedEvent struct {
+	SourcePOIID  string    `json:"sourcePoiId"`
+	TargetPOIID  string    `json:"targetPoiId"`
+	MapID        string    `json:"mapId"`
+	MergedBy     string    `json:"mergedBy"`
+	MovedUserIDs []string  `json:"movedUserIds"`
+	CurrentCount int       `json:"currentCount"`
+	Timestamp    time.Time `json:"timestamp"`
 }
 
 // POIParticipant represents a participant in a POI with avatar information
 type POIParticipant struct {
-	ID        string `json:"id"`
-	Name      string `json:"name"`
-	AvatarURL string `json:"avatarUrl"`
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	AvatarURL string     `json:"avatarUrl"`
+	JoinedAt  *time.Time `json:"joinedAt,omitempty"`
 }
 
 // POIJoinedEvent represents a user joining a POI
@@ -85,18 +136,6 @@ type POIJoinedEvent struct {
 	Timestamp    time.Time `json:"timestamp"`
 }
 
-// POIJoinedEventWithParticipants represents a user joining a POI with participant information
-type POIJoinedEventWithParticipants struct {
-	POIID        string           `json:"poiId"`
-	MapID        string           `json:"mapId"`
-	UserID       string           `json:"userId"`
-	SessionID    string           `json:"sessionId"`
-	CurrentCount int              `json:"currentCount"`
-	Participants []POIParticipant `json:"participants"`
-	JoiningUser  POIParticipant   `json:"joiningUser"` // Info about the user who is joining
-	Timestamp    time.Time        `json:"timestamp"`
-}
-
 // POILeftEvent represents a user leaving a POI
 type POILeftEvent struct {
 	POIID        string    `json:"poiId"`
@@ -107,20 +146,144 @@ type POILeftEvent struct {
 	Timestamp    time.Time `json:"timestamp"`
 }
 
-// POILeftEventWithParticipants represents a user leaving a POI with participant information
-type POILeftEventWithParticipants struct {
-	POIID        string           `json:"poiId"`
-	MapID        string           `json:"mapId"`
-	UserID       string           `json:"userId"`
-	SessionID    string           `json:"sessionId"`
-	CurrentCount int              `json:"currentCount"`
-	Participants []POIParticipant `json:"participants"`
-	Timestamp    time.Time        `json:"timestamp"`
+// POIParticipantDeltaEvent represents a single change to a POI's roster.
+// Sequence increases by one per change so clients can detect missed deltas.
+type POIParticipantDeltaEvent struct {
+	POIID        string          `json:"poiId"`
+	MapID        string          `json:"mapId"`
+	UserID       string          `json:"userId"`
+	Participant  *POIParticipant `json:"participant,omitempty"` // Only set for additions
+	Reason       string          `json:"reason,omitempty"`      // Only set for removals the user didn't choose, e.g. "deleted"
+	CurrentCount int             `json:"currentCount"`
+	Sequence     int64           `json:"seq"`
+	Timestamp    time.Time       `json:"timestamp"`
+}
+
+// UserProfileUpdatedEvent represents a user's profile changing while they are
+// connected to a map. One event is published per map the user is active in.
+type UserProfileUpdatedEvent struct {
+	UserID      string    `json:"userId"`
+	MapID       string    `json:"mapId"`
+	DisplayName string    `json:"displayName"`
+	AvatarURL   *string   `json:"avatarURL"`
+	AboutMe     *string   `json:"aboutMe"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// UserRoleChangedEvent represents an admin promoting or demoting a user who is
+// connected to a map. One event is published per map the user is active in.
+type UserRoleChangedEvent struct {
+	UserID    string    `json:"userId"`
+	MapID     string    `json:"mapId"`
+	Role      string    `json:"role"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// MapFreezeEvent represents a facilitator freezing or unfreezing a map. Until
+// is when a freeze ends on its own and is unset when unfreezing.
+type MapFreezeEvent struct {
+	MapID     string     `json:"mapId"`
+	UserID    string     `json:"userId"`
+	Until     *time.Time `json:"until,omitempty"`
+	Timestamp time.Time  `json:"timestamp"`
+}
+
+// MaintenanceEvent represents an admin turning maintenance mode on or off for
+// all instances. Since is when maintenance started and is unset when it ends.
+type MaintenanceEvent struct {
+	Enabled   bool       `json:"enabled"`
+	Message   string     `json:"message,omitempty"`
+	UserID    string     `json:"userId"`
+	Since     *time.Time `json:"since,omitempty"`
+	Timestamp time.Time  `json:"timestamp"`
+}
+
+// HandQueueEvent represents a user raising or lowering their hand on a map.
+// Hands carries the whole queue after the change, so clients replace theirs.
+type HandQueueEvent struct {
+	MapID     string              `json:"mapId"`
+	UserID    string              `json:"userId"`
+	Action    string              `json:"action"`
+	LoweredBy string              `json:"loweredBy,omitempty"`
+	Hands     []models.RaisedHand `json:"hands"`
+	Timestamp time.Time           `json:"timestamp"`
+}
+
+// SummonEvent represents a facilitator moving participants to a POI. Users
+// lists where their avatars were placed, which clients apply like a move.
+type SummonEvent struct {
+	MapID      string                `json:"mapId"`
+	POIID      string                `json:"poiId"`
+	SummonedBy string                `json:"summonedBy"`
+	Users      []models.SummonedUser `json:"users"`
+	Timestamp  time.Time             `json:"timestamp"`
+}
+
+// UserKickedEvent represents a facilitator kicking a user from a map.
+// BannedUntil is set if the user can't reconnect until then.
+type UserKickedEvent struct {
+	MapID       string     `json:"mapId"`
+	UserID      string     `json:"userId"`
+	KickedBy    string     `json:"kickedBy"`
+	BannedUntil *time.Time `json:"bannedUntil,omitempty"`
+	Timestamp   time.Time  `json:"timestamp"`
+}
+
+// MapDeletedEvent represents an organizer deleting a map
+type MapDeletedEvent struct {
+	MapID     string    `json:"mapId"`
+	DeletedBy string    `json:"deletedBy"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// POIReminderEvent represents the reminder that a scheduled POI starts soon,
+// sent to the users who RSVP'd going or maybe
+type POIReminderEvent struct {
+	POIID     string    `json:"poiId"`
+	MapID     string    `json:"mapId"`
+	Name      string    `json:"name"`
+	StartsAt  time.Time `json:"startsAt"`
+	UserIDs   []string  `json:"userIds"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// OnboardingProgressEvent represents a user completing a step of the welcome
+// checklist. One event is published per map the user is active in.
+type OnboardingProgressEvent struct {
+	UserID    string                    `json:"userId"`
+	MapID     string                    `json:"mapId"`
+	Step      models.OnboardingStep     `json:"step"`
+	Progress  models.OnboardingProgress `json:"progress"`
+	Timestamp time.Time                 `json:"timestamp"`
+}
+
+// streamedEventTypes are the events delivered to all instances by SubscribePOIEvents
+var streamedEventTypes = map[EventType]bool{
+	EventTypePOICreated:            true,
+	EventTypePOIJoined:             true,
+	EventTypePOILeft:               true,
+	EventTypePOIUpdated:            true,
+	EventTypePOIDeleted:            true,
+	EventTypePOIMerged:             true,
+	EventTypeUserProfileUpdated:    true,
+	EventTypeUserRoleChanged:       true,
+	EventTypeMapFrozen:             true,
+	EventTypeMapUnfrozen:           true,
+	EventTypeOnboardingProgress:    true,
+	EventTypeMaintenanceChanged:    true,
+	EventTypeHandQueueUpdated:      true,
+	EventTypeSummonedToPOI:         true,
+	EventTypeUserKicked:            true,
+	EventTypeMapDeleted:            true,
+	EventTypePOIReminder:           true,
+	EventTypePOIParticipantAdded:   true,
+	EventTypePOIParticipantRemoved: true,
 }
 
 // PubSub manages Redis pub/sub operations for real-time events
 type PubSub struct {
 	client *redis.Client
+	stream *EventStream
 }
 
 // NewPubSub creates a new PubSub instance
@@ -130,6 +293,13 @@ func NewPubSub(client *redis.Client) *PubSub {
 	}
 }
 
+// SetEventStream delivers POI and user events through a Redis stream, so
+// instances that were briefly disconnected don't miss them. Without it, they
+// are only published to channels.
+func (ps *PubSub) SetEventStream(stream *EventStream) {
+	ps.stream = stream
+}
+
 // PublishAvatarMovement publishes an avatar movement event
 func (ps *PubSub) PublishAvatarMovement(ctx context.Context, event AvatarMovementEvent) error {
 	return ps.publishEvent(ctx, EventTypeAvatarMovement, event, event.MapID, event.UserID)
@@ -145,13 +315,13 @@ func (ps *PubSub) PublishPOIUpdated(ctx context.Context, event POIUpdatedEvent)
 	return ps.publishEvent(ctx, EventTypePOIUpdated, event, event.MapID, "")
 }
 
-// PublishPOIJoined publishes a POI joined event
-func (ps *PubSub) PublishPOIJoined(ctx context.Context, event POIJoinedEvent) error {
-	return ps.publishEvent(ctx, EventTypePOIJoined, event, event.MapID, event.UserID)
+// PublishPOIDeleted publishes a POI deleted event
+func (ps *PubSub) PublishPOIDeleted(ctx context.Context, event POIDeletedEvent) error {
+	return ps.publishEvent(ctx, EventTypePOIDeleted, event, event.MapID, "")
 }
 
-// PublishPOIJoinedWithParticipants publishes a POI joined event with participant information
-func (ps *PubSub) PublishPOIJoinedWithParticipants(ctx context.Context, event POIJoinedEventWithParticipants) error {
+// PublishPOIJoined publishes a POI joined event
+func (ps *PubSub) PublishPOIJoined(ctx context.Context, event POIJoinedEvent) error {
 	return ps.publishEvent(ctx, EventTypePOIJoined, event, event.MapID, event.UserID)
 }
 
@@ -160,9 +330,76 @@ func (ps *PubSub) PublishPOILeft(ctx context.Context, event POILeftEvent) error
 	return ps.publishEvent(ctx, EventTypePOILeft, event, event.MapID, event.UserID)
 }
 
-// PublishPOILeftWithParticipants publishes a POI left event with participant information
-func (ps *PubSub) PublishPOILeftWithParticipants(ctx context.Context, event POILeftEventWithParticipants) error {
-	return ps.publishEvent(ctx, EventTypePOILeft, event, event.MapID, event.UserID)
+// PublishPOIParticipantAdded publishes a roster delta for a user joining a POI
+func (ps *PubSub) PublishPOIParticipantAdded(ctx context.Context, event POIParticipantDeltaEvent) error {
+	return ps.publishEvent(ctx, EventTypePOIParticipantAdded, event, event.MapID, event.UserID)
+}
+
+// PublishPOIParticipantRemoved publishes a roster delta for a user leaving a POI
+func (ps *PubSub) PublishPOIParticipantRemoved(ctx context.Context, event POIParticipantDeltaEvent) error {
+	return ps.publishEvent(ctx, EventTypePOIParticipantRemoved, event, event.MapID, event.UserID)
+}
+
+// PublishUserProfileUpdated publishes a user profile updated event
+func (ps *PubSub) PublishUserProfileUpdated(ctx context.Context, event UserProfileUpdatedEvent) error {
+	return ps.publishEvent(ctx, EventTypeUserProfileUpdated, event, event.MapID, "")
+}
+
+// PublishUserRoleChanged publishes a user role changed event
+func (ps *PubSub) PublishUserRoleChanged(ctx context.Context, event UserRoleChangedEvent) error {
+	return ps.publishEvent(ctx, EventTypeUserRoleChanged, event, event.MapID, "")
+}
+
+// PublishMapFrozen publishes a map frozen event
+func (ps *PubSub) PublishMapFrozen(ctx context.Context, event MapFreezeEvent) error {
+	return ps.publishEvent(ctx, EventTypeMapFrozen, event, event.MapID, "")
+}
+
+// PublishMapUnfrozen publishes a map unfrozen event
+func (ps *PubSub) PublishMapUnfrozen(ctx context.Context, event MapFreezeEvent) error {
+	return ps.publishEvent(ctx, EventTypeMapUnfrozen, event, event.MapID, "")
+}
+
+// PublishOnboardingProgress publishes an onboarding progress event
+func (ps *PubSub) PublishOnboardingProgress(ctx context.Context, event OnboardingProgressEvent) error {
+	return ps.publishEvent(ctx, EventTypeOnboardingProgress, event, event.MapID, event.UserID)
+}
+
+// PublishHandQueueUpdated publishes a hand queue updated event
+func (ps *PubSub) PublishHandQueueUpdated(ctx context.Context, event HandQueueEvent) error {
+	return ps.publishEvent(ctx, EventTypeHandQueueUpdated, event, event.MapID, "")
+}
+
+// PublishPOIMerged publishes a POI merged event
+func (ps *PubSub) PublishPOIMerged(ctx context.Context, event POIMergedEvent) error {
+	return ps.publishEvent(ctx, EventTypePOIMerged, event, event.MapID, event.MergedBy)
+}
+
+// PublishSummonedToPOI publishes a summoned to POI event
+func (ps *PubSub) PublishSummonedToPOI(ctx context.Context, event SummonEvent) error {
+	return ps.publishEvent(ctx, EventTypeSummonedToPOI, event, event.MapID, event.SummonedBy)
+}
+
+// PublishUserKicked publishes a user kicked event
+func (ps *PubSub) PublishUserKicked(ctx context.Context, event UserKickedEvent) error {
+	return ps.publishEvent(ctx, EventTypeUserKicked, event, event.MapID, event.UserID)
+}
+
+// PublishMapDeleted publishes a map deleted event
+func (ps *PubSub) PublishMapDeleted(ctx context.Context, event MapDeletedEvent) error {
+	return ps.publishEvent(ctx, EventTypeMapDeleted, event, event.MapID, "")
+}
+
+// PublishPOIReminder publishes a POI reminder event
+func (ps *PubSub) PublishPOIReminder(ctx context.Context, event POIReminderEvent) error {
+	return ps.publishEvent(ctx, EventTypePOIReminder, event, event.MapID, "")
+}
+
+// PublishMaintenanceChanged publishes a maintenance changed event. It isn't
+// about a map, so it goes to the channel without one, which every instance
+// still subscribes to through the map channel pattern.
+func (ps *PubSub) PublishMaintenanceChanged(ctx context.Context, event MaintenanceEvent) error {
+	return ps.publishEvent(ctx, EventTypeMaintenanceChanged, event, "", "")
 }
 
 // publishEvent is a generic method to publish events to appropriate channels
@@ -186,6 +423,12 @@ func (ps *PubSub) publishEvent(ctx context.Context, eventType EventType, eventDa
 		return fmt.Errorf("failed to marshal event: %w", err)
 	}
 
+	if ps.stream != nil && streamedEventTypes[eventType] {
+		if err := ps.stream.Add(ctx, eventJSON); err != nil {
+			return err
+		}
+	}
+
 	// Publish to map-specific channel
 	mapChannel := ps.getMapChannel(mapID)
 	err = ps.client.Publish(ctx, mapChannel, eventJSON).Err()
@@ -431,8 +674,15 @@ func ParsePOIUpdatedEvent(event Event) (*POIUpdatedEvent, error) {
 	return &updatedEvent, nil
 }
 
-// SubscribePOIEvents subscribes to all POI-related events across all maps and calls the callback for each event
+// SubscribePOIEvents subscribes to all POI-related events, user profile and role changes, map freezes, onboarding progress and maintenance changes across all maps and calls the callback for each event
+// With an event stream, events are read from it and acknowledged; otherwise they are received through channels and lost while disconnected.
 func (ps *PubSub) SubscribePOIEvents(ctx context.Context, callback func(eventType string, data interface{})) error {
+	if ps.stream != nil {
+		return ps.stream.Consume(ctx, func(event Event) {
+			DispatchPOIEvent(event, callback)
+		})
+	}
+
 	// Subscribe to all map channels using a pattern
 	// In Redis, we can use PSUBSCRIBE to subscribe to patterns
 	pubsub := ps.client.PSubscribe(ctx, "map:*:events")
@@ -459,76 +709,245 @@ func (ps *PubSub) SubscribePOIEvents(ctx context.Context, callback func(eventTyp
 				continue
 			}
 
-			// Only process POI-related events
-			if event.Type == EventTypePOICreated || 
-			   event.Type == EventTypePOIJoined || 
-			   event.Type == EventTypePOILeft || 
-			   event.Type == EventTypePOIUpdated {
-				
-				// Parse the event data based on type
-				var eventData interface{}
-				switch event.Type {
-				case EventTypePOICreated:
-					var poiEvent POICreatedEvent
-					if err := json.Unmarshal(event.Data, &poiEvent); err == nil {
-						eventData = map[string]interface{}{
-							"poiId":           poiEvent.POIID,
-							"mapId":           poiEvent.MapID,
-							"name":            poiEvent.Name,
-							"description":     poiEvent.Description,
-							"position":        poiEvent.Position,
-							"createdBy":       poiEvent.CreatedBy,
-							"maxParticipants": poiEvent.MaxParticipants,
-							"imageUrl":        poiEvent.ImageURL,
-							"thumbnailUrl":    poiEvent.ThumbnailURL,
-							"currentCount":    poiEvent.CurrentCount,
-							"timestamp":       poiEvent.Timestamp,
-						}
-					}
-				case EventTypePOIJoined:
-					var joinEvent POIJoinedEvent
-					if err := json.Unmarshal(event.Data, &joinEvent); err == nil {
-						eventData = map[string]interface{}{
-							"poiId":        joinEvent.POIID,
-							"mapId":        joinEvent.MapID,
-							"userId":       joinEvent.UserID,
-							"sessionId":    joinEvent.SessionID,
-							"currentCount": joinEvent.CurrentCount,
-							"timestamp":    joinEvent.Timestamp,
-						}
-					}
-				case EventTypePOILeft:
-					var leftEvent POILeftEvent
-					if err := json.Unmarshal(event.Data, &leftEvent); err == nil {
-						eventData = map[string]interface{}{
-							"poiId":        leftEvent.POIID,
-							"mapId":        leftEvent.MapID,
-							"userId":       leftEvent.UserID,
-							"sessionId":    leftEvent.SessionID,
-							"currentCount": leftEvent.CurrentCount,
-							"timestamp":    leftEvent.Timestamp,
-						}
-					}
-				case EventTypePOIUpdated:
-					var updatedEvent POIUpdatedEvent
-					if err := json.Unmarshal(event.Data, &updatedEvent); err == nil {
-						eventData = map[string]interface{}{
-							"poiId":           updatedEvent.POIID,
-							"mapId":           updatedEvent.MapID,
-							"name":            updatedEvent.Name,
-							"description":     updatedEvent.Description,
-							"maxParticipants": updatedEvent.MaxParticipants,
-							"currentCount":    updatedEvent.CurrentCount,
-							"timestamp":       updatedEvent.Timestamp,
-						}
-					}
-				}
-
-				// Call the callback with the parsed event
-				if eventData != nil {
-					callback(string(event.Type), eventData)
-				}
+			DispatchPOIEvent(event, callback)
+		}
+	}
+}
+
+// DispatchPOIEvent converts a POI-related event or profile update to the data
+// broadcast to clients and calls the callback with it. Other events are ignored.
+func DispatchPOIEvent(event Event, callback func(eventType string, data interface{})) {
+	if !streamedEventTypes[event.Type] {
+		return
+	}
+
+	// Parse the event data based on type
+	var eventData interface{}
+	switch event.Type {
+	case EventTypePOICreated:
+		var poiEvent POICreatedEvent
+		if err := json.Unmarshal(event.Data, &poiEvent); err == nil {
+			eventData = map[string]interface{}{
+				"poiId":           poiEvent.POIID,
+				"mapId":           poiEvent.MapID,
+				"name":            poiEvent.Name,
+				"description":     poiEvent.Description,
+				"position":        poiEvent.Position,
+				"createdBy":       poiEvent.CreatedBy,
+				"maxParticipants": poiEvent.MaxParticipants,
+				"imageUrl":        poiEvent.ImageURL,
+				"thumbnailUrl":    poiEvent.ThumbnailURL,
+				"currentCount":    poiEvent.CurrentCount,
+				"timestamp":       poiEvent.Timestamp,
+			}
+		}
+	case EventTypePOIJoined:
+		var joinEvent POIJoinedEvent
+		if err := json.Unmarshal(event.Data, &joinEvent); err == nil {
+			eventData = map[string]interface{}{
+				"poiId":        joinEvent.POIID,
+				"mapId":        joinEvent.MapID,
+				"userId":       joinEvent.UserID,
+				"sessionId":    joinEvent.SessionID,
+				"currentCount": joinEvent.CurrentCount,
+				"timestamp":    joinEvent.Timestamp,
+			}
+		}
+	case EventTypePOILeft:
+		var leftEvent POILeftEvent
+		if err := json.Unmarshal(event.Data, &leftEvent); err == nil {
+			eventData = map[string]interface{}{
+				"poiId":        leftEvent.POIID,
+				"mapId":        leftEvent.MapID,
+				"userId":       leftEvent.UserID,
+				"sessionId":    leftEvent.SessionID,
+				"currentCount": leftEvent.CurrentCount,
+				"timestamp":    leftEvent.Timestamp,
+			}
+		}
+	case EventTypePOIParticipantAdded, EventTypePOIParticipantRemoved:
+		var deltaEvent POIParticipantDeltaEvent
+		if err := json.Unmarshal(event.Data, &deltaEvent); err == nil {
+			data := map[string]interface{}{
+				"poiId":        deltaEvent.POIID,
+				"mapId":        deltaEvent.MapID,
+				"userId":       deltaEvent.UserID,
+				"currentCount": deltaEvent.CurrentCount,
+				"seq":          deltaEvent.Sequence,
+				"timestamp":    deltaEvent.Timestamp,
+			}
+			if deltaEvent.Participant != nil {
+				data["participant"] = deltaEvent.Participant
+			}
+			if deltaEvent.Reason != "" {
+				data["reason"] = deltaEvent.Reason
+			}
+			eventData = data
+		}
+	case EventTypePOIUpdated:
+		var updatedEvent POIUpdatedEvent
+		if err := json.Unmarshal(event.Data, &updatedEvent); err == nil {
+			data := map[string]interface{}{
+				"poiId":           updatedEvent.POIID,
+				"mapId":           updatedEvent.MapID,
+				"name":            updatedEvent.Name,
+				"description":     updatedEvent.Description,
+				"maxParticipants": updatedEvent.MaxParticipants,
+				"currentCount":    updatedEvent.CurrentCount,
+				"timestamp":       updatedEvent.Timestamp,
+			}
+			if updatedEvent.PopularSince != nil {
+				data["popularSince"] = updatedEvent.PopularSince
 			}
+			eventData = data
+		}
+	case EventTypePOIDeleted:
+		var deletedEvent POIDeletedEvent
+		if err := json.Unmarshal(event.Data, &deletedEvent); err == nil {
+			eventData = map[string]interface{}{
+				"poiId":     deletedEvent.POIID,
+				"mapId":     deletedEvent.MapID,
+				"timestamp": deletedEvent.Timestamp,
+			}
+		}
+	case EventTypePOIMerged:
+		var mergedEvent POIMergedEvent
+		if err := json.Unmarshal(event.Data, &mergedEvent); err == nil {
+			eventData = map[string]interface{}{
+				"sourcePoiId":  mergedEvent.SourcePOIID,
+				"targetPoiId":  mergedEvent.TargetPOIID,
+				"mapId":        mergedEvent.MapID,
+				"mergedBy":     mergedEvent.MergedBy,
+				"movedUserIds": mergedEvent.MovedUserIDs,
+				"currentCount": mergedEvent.CurrentCount,
+				"timestamp":    mergedEvent.Timestamp,
+			}
+		}
+	case EventTypeUserProfileUpdated:
+		var profileEvent UserProfileUpdatedEvent
+		if err := json.Unmarshal(event.Data, &profileEvent); err == nil {
+			eventData = map[string]interface{}{
+				"userId":      profileEvent.UserID,
+				"mapId":       profileEvent.MapID,
+				"displayName": profileEvent.DisplayName,
+				"avatarURL":   profileEvent.AvatarURL,
+				"aboutMe":     profileEvent.AboutMe,
+				"timestamp":   profileEvent.Timestamp,
+			}
+		}
+	case EventTypeUserRoleChanged:
+		var roleEvent UserRoleChangedEvent
+		if err := json.Unmarshal(event.Data, &roleEvent); err == nil {
+			eventData = map[string]interface{}{
+				"userId":    roleEvent.UserID,
+				"mapId":     roleEvent.MapID,
+				"role":      roleEvent.Role,
+				"timestamp": roleEvent.Timestamp,
+			}
+		}
+	case EventTypeMapFrozen, EventTypeMapUnfrozen:
+		var freezeEvent MapFreezeEvent
+		if err := json.Unmarshal(event.Data, &freezeEvent); err == nil {
+			data := map[string]interface{}{
+				"mapId":     freezeEvent.MapID,
+				"userId":    freezeEvent.UserID,
+				"timestamp": freezeEvent.Timestamp,
+			}
+			if freezeEvent.Until != nil {
+				data["until"] = *freezeEvent.Until
+			}
+			eventData = data
+		}
+	case EventTypeOnboardingProgress:
+		var onboardingEvent OnboardingProgressEvent
+		if err := json.Unmarshal(event.Data, &onboardingEvent); err == nil {
+			eventData = map[string]interface{}{
+				"userId":    onboardingEvent.UserID,
+				"mapId":     onboardingEvent.MapID,
+				"step":      onboardingEvent.Step,
+				"progress":  onboardingEvent.Progress,
+				"timestamp": onboardingEvent.Timestamp,
+			}
+		}
+	case EventTypeHandQueueUpdated:
+		var handEvent HandQueueEvent
+		if err := json.Unmarshal(event.Data, &handEvent); err == nil {
+			data := map[string]interface{}{
+				"mapId":     handEvent.MapID,
+				"userId":    handEvent.UserID,
+				"action":    handEvent.Action,
+				"hands":     handEvent.Hands,
+				"timestamp": handEvent.Timestamp,
+			}
+			if handEvent.LoweredBy != "" {
+				data["loweredBy"] = handEvent.LoweredBy
+			}
+			eventData = data
+		}
+	case EventTypeSummonedToPOI:
+		var summonEvent SummonEvent
+		if err := json.Unmarshal(event.Data, &summonEvent); err == nil {
+			eventData = map[string]interface{}{
+				"mapId":      summonEvent.MapID,
+				"poiId":      summonEvent.POIID,
+				"summonedBy": summonEvent.SummonedBy,
+				"users":      summonEvent.Users,
+				"timestamp":  summonEvent.Timestamp,
+			}
+		}
+	case EventTypeUserKicked:
+		var kickedEvent UserKickedEvent
+		if err := json.Unmarshal(event.Data, &kickedEvent); err == nil {
+			data := map[string]interface{}{
+				"mapId":     kickedEvent.MapID,
+				"userId":    kickedEvent.UserID,
+				"kickedBy":  kickedEvent.KickedBy,
+				"timestamp": kickedEvent.Timestamp,
+			}
+			if kickedEvent.BannedUntil != nil {
+				data["bannedUntil"] = *kickedEvent.BannedUntil
+			}
+			eventData = data
+		}
+	case EventTypeMapDeleted:
+		var deletedEvent MapDeletedEvent
+		if err := json.Unmarshal(event.Data, &deletedEvent); err == nil {
+			eventData = map[string]interface{}{
+				"mapId":     deletedEvent.MapID,
+				"deletedBy": deletedEvent.DeletedBy,
+				"timestamp": deletedEvent.Timestamp,
+			}
+		}
+	case EventTypePOIReminder:
+		var reminderEvent POIReminderEvent
+		if err := json.Unmarshal(event.Data, &reminderEvent); err == nil {
+			eventData = map[string]interface{}{
+				"poiId":     reminderEvent.POIID,
+				"mapId":     reminderEvent.MapID,
+				"name":      reminderEvent.Name,
+				"startsAt":  reminderEvent.StartsAt,
+				"userIds":   reminderEvent.UserIDs,
+				"timestamp": reminderEvent.Timestamp,
+			}
+		}
+	case EventTypeMaintenanceChanged:
+		var maintenanceEvent MaintenanceEvent
+		if err := json.Unmarshal(event.Data, &maintenanceEvent); err == nil {
+			data := map[string]interface{}{
+				"enabled":   maintenanceEvent.Enabled,
+				"message":   maintenanceEvent.Message,
+				"userId":    maintenanceEvent.UserID,
+				"timestamp": maintenanceEvent.Timestamp,
+			}
+			if maintenanceEvent.Since != nil {
+				data["since"] = *maintenanceEvent.Since
+			}
+			eventData = data
 		}
 	}
-}
\ No newline at end of file
+
+	// Call the callback with the parsed event
+	if eventData != nil {
+		callback(string(event.Type), eventData)
+	}
+}
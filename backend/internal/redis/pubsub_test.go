@@ -164,6 +164,24 @@ func (suite *PubSubTestSuite) TestPublishPOIUpdated() {
 	suite.NoError(err)
 }
 
+func (suite *PubSubTestSuite) TestPublishPOIDeleted() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	// Create POI deleted event
+	event := POIDeletedEvent{
+		POIID:     "poi-123",
+		MapID:     "map-456",
+		Timestamp: time.Now(),
+	}
+
+	// Execute
+	err := suite.pubsub.PublishPOIDeleted(ctx, event)
+
+	// Assert
+	suite.NoError(err)
+}
+
 func (suite *PubSubTestSuite) TestSubscribeToMapEvents() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
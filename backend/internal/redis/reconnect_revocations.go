@@ -0,0 +1,71 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ReconnectRevocations keeps when WebSocket reconnect tokens were revoked in
+// Redis, so tokens revoked on one instance are rejected by all. Every
+// revocation is a key expiring with the tokens it rejects.
+type ReconnectRevocations struct {
+	client *redis.Client
+}
+
+// NewReconnectRevocations creates a new ReconnectRevocations instance
+func NewReconnectRevocations(client *redis.Client) *ReconnectRevocations {
+	return &ReconnectRevocations{
+		client: client,
+	}
+}
+
+// RevokeReconnectTokens rejects the tokens issued until at under the
+// revocation key, for as long as ttl
+func (rr *ReconnectRevocations) RevokeReconnectTokens(ctx context.Context, key string, at time.Time, ttl time.Duration) error {
+	if err := rr.client.Set(ctx, rr.getRevocationKey(key), at.Unix(), ttl).Err(); err != nil {
+		return fmt.Errorf("failed to revoke reconnect tokens: %w", err)
+	}
+	return nil
+}
+
+// ReconnectTokensRevokedAt returns when the last of the revocation keys was
+// revoked, or the zero time if none was
+func (rr *ReconnectRevocations) ReconnectTokensRevokedAt(ctx context.Context, keys ...string) (time.Time, error) {
+	if len(keys) == 0 {
+		return time.Time{}, nil
+	}
+
+	redisKeys := make([]string, len(keys))
+	for i, key := range keys {
+		redisKeys[i] = rr.getRevocationKey(key)
+	}
+	values, err := rr.client.MGet(ctx, redisKeys...).Result()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get reconnect token revocations: %w", err)
+	}
+
+	var latest time.Time
+	for _, value := range values {
+		unix, ok := value.(string)
+		if !ok {
+			continue
+		}
+		seconds, err := strconv.ParseInt(unix, 10, 64)
+		if err != nil {
+			continue
+		}
+		if at := time.Unix(seconds, 0); at.After(latest) {
+			latest = at
+		}
+	}
+	return latest, nil
+}
+
+// getRevocationKey generates the Redis key of a revocation key
+func (rr *ReconnectRevocations) getRevocationKey(key string) string {
+	return "reconnect:revoked:" + key
+}
@@ -0,0 +1,57 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// rolloutKey is the hash of feature rollouts, keyed by feature
+const rolloutKey = "rollout:features"
+
+// RolloutStore stores feature rollouts in Redis so changes apply to all instances
+type RolloutStore struct {
+	client *redis.Client
+}
+
+// NewRolloutStore creates a new RolloutStore instance
+func NewRolloutStore(client *redis.Client) *RolloutStore {
+	return &RolloutStore{
+		client: client,
+	}
+}
+
+// GetRollouts returns all stored feature rollouts
+func (s *RolloutStore) GetRollouts(ctx context.Context) ([]models.FeatureRollout, error) {
+	values, err := s.client.HGetAll(ctx, rolloutKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rollouts: %w", err)
+	}
+
+	rollouts := make([]models.FeatureRollout, 0, len(values))
+	for feature, value := range values {
+		var rollout models.FeatureRollout
+		if err := json.Unmarshal([]byte(value), &rollout); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal rollout %s: %w", feature, err)
+		}
+		rollouts = append(rollouts, rollout)
+	}
+	return rollouts, nil
+}
+
+// SaveRollout stores a feature rollout, replacing the previous one
+func (s *RolloutStore) SaveRollout(ctx context.Context, rollout models.FeatureRollout) error {
+	value, err := json.Marshal(rollout)
+	if err != nil {
+		return fmt.Errorf("failed to marshal rollout: %w", err)
+	}
+
+	if err := s.client.HSet(ctx, rolloutKey, string(rollout.Feature), value).Err(); err != nil {
+		return fmt.Errorf("failed to save rollout: %w", err)
+	}
+	return nil
+}
@@ -12,6 +12,11 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+// PresenceTTL is how long a session counts as present without a heartbeat.
+// Clients heartbeat every 30 seconds, so a session disappears after missing
+// about three heartbeats.
+const PresenceTTL = 90 * time.Second
+
 // SessionPresenceData represents the data stored in Redis for session presence
 type SessionPresenceData struct {
 	UserID         string           `json:"userId"`
@@ -291,6 +296,99 @@ func (sp *SessionPresence) CleanupExpiredSessions(ctx context.Context, maxAge ti
 	return cleanedCount, nil
 }
 
+// TouchPresence marks a session as present for ttl. The presence key is kept
+// separate from the session data so liveness can be checked with a single
+// EXISTS and expires on its own when heartbeats stop.
+func (sp *SessionPresence) TouchPresence(ctx context.Context, sessionID string, ttl time.Duration) error {
+	key := sp.getPresenceKey(sessionID)
+	
+	if err := sp.client.Set(ctx, key, time.Now().Unix(), ttl).Err(); err != nil {
+		return fmt.Errorf("failed to touch session presence: %w", err)
+	}
+	
+	return nil
+}
+
+// ClearPresence removes the presence key of a session
+func (sp *SessionPresence) ClearPresence(ctx context.Context, sessionID string) error {
+	key := sp.getPresenceKey(sessionID)
+	
+	if err := sp.client.Del(ctx, key).Err(); err != nil {
+		return fmt.Errorf("failed to clear session presence: %w", err)
+	}
+	
+	return nil
+}
+
+// FilterPresent returns the sessions from sessionIDs that have a live presence key,
+// preserving their order
+func (sp *SessionPresence) FilterPresent(ctx context.Context, sessionIDs []string) ([]string, error) {
+	if len(sessionIDs) == 0 {
+		return []string{}, nil
+	}
+	
+	pipe := sp.client.Pipeline()
+	cmds := make([]*redis.IntCmd, len(sessionIDs))
+	for i, sessionID := range sessionIDs {
+		cmds[i] = pipe.Exists(ctx, sp.getPresenceKey(sessionID))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to check session presence: %w", err)
+	}
+	
+	present := make([]string, 0, len(sessionIDs))
+	for i, cmd := range cmds {
+		if cmd.Val() > 0 {
+			present = append(present, sessionIDs[i])
+		}
+	}
+	
+	return present, nil
+}
+
+// CountParticipantsByMap counts the distinct users with a live presence on each map.
+// Maps without participants are left out.
+func (sp *SessionPresence) CountParticipantsByMap(ctx context.Context) (map[string]int, error) {
+	keys, err := sp.client.Keys(ctx, "session:*").Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session keys: %w", err)
+	}
+	
+	sessionIDs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		sessionIDs = append(sessionIDs, sp.GetSessionIDFromKey(key))
+	}
+	present, err := sp.FilterPresent(ctx, sessionIDs)
+	if err != nil {
+		return nil, err
+	}
+	
+	users := make(map[string]map[string]bool)
+	for _, sessionID := range present {
+		data, err := sp.GetSessionPresence(ctx, sessionID)
+		if err != nil || data.MapID == "" {
+			// Skip sessions that expired in between or are malformed
+			continue
+		}
+		if users[data.MapID] == nil {
+			users[data.MapID] = make(map[string]bool)
+		}
+		users[data.MapID][data.UserID] = true
+	}
+	
+	counts := make(map[string]int, len(users))
+	for mapID, mapUsers := range users {
+		counts[mapID] = len(mapUsers)
+	}
+	
+	return counts, nil
+}
+
+// getPresenceKey generates the Redis key for a session's heartbeat presence
+func (sp *SessionPresence) getPresenceKey(sessionID string) string {
+	return fmt.Sprintf("presence:%s", sessionID)
+}
+
 // getSessionKey generates the Redis key for a session
 func (sp *SessionPresence) getSessionKey(sessionID string) string {
 	return fmt.Sprintf("session:%s", sessionID)
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// AuthSessionRepository stores the sign-ins of full accounts
+type AuthSessionRepository struct {
+	db *gorm.DB
+}
+
+// NewAuthSessionRepository creates a new auth session repository
+func NewAuthSessionRepository(db *gorm.DB) *AuthSessionRepository {
+	return &AuthSessionRepository{db: db}
+}
+
+// Create stores a new auth session
+func (r *AuthSessionRepository) Create(ctx context.Context, session *models.AuthSession) error {
+	if err := r.db.WithContext(ctx).Create(session).Error; err != nil {
+		return fmt.Errorf("failed to create auth session: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID returns an auth session, or nil if it does not exist
+func (r *AuthSessionRepository) GetByID(ctx context.Context, id string) (*models.AuthSession, error) {
+	var session models.AuthSession
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&session).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get auth session: %w", err)
+	}
+
+	return &session, nil
+}
+
+// ListByUser returns all auth sessions of a user created after since, newest first
+func (r *AuthSessionRepository) ListByUser(ctx context.Context, userID string, since time.Time) ([]*models.AuthSession, error) {
+	var sessions []*models.AuthSession
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND created_at > ?", userID, since).
+		Order("created_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list auth sessions: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// Touch updates when an auth session was last used
+func (r *AuthSessionRepository) Touch(ctx context.Context, id string, at time.Time) error {
+	err := r.db.WithContext(ctx).Model(&models.AuthSession{}).
+		Where("id = ?", id).
+		Update("last_seen_at", at).Error
+	if err != nil {
+		return fmt.Errorf("failed to touch auth session: %w", err)
+	}
+
+	return nil
+}
+
+// Revoke revokes the active auth sessions of a user with the given IDs and
+// returns how many were revoked
+func (r *AuthSessionRepository) Revoke(ctx context.Context, userID string, ids []string, at time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.AuthSession{}).
+		Where("user_id = ? AND id IN ? AND revoked_at IS NULL", userID, ids).
+		Update("revoked_at", at)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to revoke auth sessions: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// DeleteExpiredBefore deletes auth sessions that expired before the given time
+func (r *AuthSessionRepository) DeleteExpiredBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("expires_at < ?", before).Delete(&models.AuthSession{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired auth sessions: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ConnectionErrorRepository stores abnormal WebSocket closes
+type ConnectionErrorRepository struct {
+	db *gorm.DB
+}
+
+// NewConnectionErrorRepository creates a new connection error repository
+func NewConnectionErrorRepository(db *gorm.DB) *ConnectionErrorRepository {
+	return &ConnectionErrorRepository{db: db}
+}
+
+// RecordConnectionError stores an abnormal close
+func (r *ConnectionErrorRepository) RecordConnectionError(ctx context.Context, connectionError *models.ConnectionError) error {
+	if err := r.db.WithContext(ctx).Create(connectionError).Error; err != nil {
+		return fmt.Errorf("failed to record connection error: %w", err)
+	}
+
+	return nil
+}
+
+// ListConnectionErrors returns the most recent abnormal closes, newest first,
+// optionally limited to one user
+func (r *ConnectionErrorRepository) ListConnectionErrors(ctx context.Context, userID string, limit int) ([]models.ConnectionError, error) {
+	query := r.db.WithContext(ctx).Order("closed_at DESC").Limit(limit)
+	if userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var connectionErrors []models.ConnectionError
+	if err := query.Find(&connectionErrors).Error; err != nil {
+		return nil, fmt.Errorf("failed to list connection errors: %w", err)
+	}
+
+	return connectionErrors, nil
+}
+
+// DeleteBefore deletes abnormal closes recorded before the given time
+func (r *ConnectionErrorRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Where("closed_at < ?", before).Delete(&models.ConnectionError{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete connection errors: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
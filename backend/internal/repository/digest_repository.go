@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DigestRepository stores digest subscriptions and reads the map activity they report
+type DigestRepository struct {
+	db *gorm.DB
+}
+
+// NewDigestRepository creates a new digest repository
+func NewDigestRepository(db *gorm.DB) *DigestRepository {
+	return &DigestRepository{db: db}
+}
+
+// UpsertSubscription creates a subscription or updates the frequency of an existing one
+func (r *DigestRepository) UpsertSubscription(ctx context.Context, sub *models.DigestSubscription) (*models.DigestSubscription, error) {
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "map_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"frequency", "updated_at"}),
+	}).Create(sub).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to save digest subscription: %w", err)
+	}
+
+	// On conflict the stored row keeps its own ID and send time
+	return r.GetSubscription(ctx, sub.MapID, sub.UserID)
+}
+
+// GetSubscription returns the subscription of a user for a map
+func (r *DigestRepository) GetSubscription(ctx context.Context, mapID, userID string) (*models.DigestSubscription, error) {
+	var sub models.DigestSubscription
+	err := r.db.WithContext(ctx).Where("map_id = ? AND user_id = ?", mapID, userID).First(&sub).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get digest subscription: %w", err)
+	}
+
+	return &sub, nil
+}
+
+// DeleteSubscription removes the subscription of a user for a map
+func (r *DigestRepository) DeleteSubscription(ctx context.Context, mapID, userID string) error {
+	result := r.db.WithContext(ctx).Where("map_id = ? AND user_id = ?", mapID, userID).Delete(&models.DigestSubscription{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete digest subscription: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// ListSubscriptions returns all subscriptions with their user and map loaded
+func (r *DigestRepository) ListSubscriptions(ctx context.Context) ([]*models.DigestSubscription, error) {
+	var subs []*models.DigestSubscription
+	err := r.db.WithContext(ctx).Preload("User").Preload("Map").Find(&subs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list digest subscriptions: %w", err)
+	}
+
+	return subs, nil
+}
+
+// MarkSent records when the last digest of a subscription was sent
+func (r *DigestRepository) MarkSent(ctx context.Context, id string, sentAt time.Time) error {
+	err := r.db.WithContext(ctx).Model(&models.DigestSubscription{}).Where("id = ?", id).Update("last_sent_at", sentAt).Error
+	if err != nil {
+		return fmt.Errorf("failed to mark digest as sent: %w", err)
+	}
+
+	return nil
+}
+
+// GetMapActivity returns the POIs created in a map and the number of distinct
+// users with a session in it during [from, to)
+func (r *DigestRepository) GetMapActivity(ctx context.Context, mapID string, from, to time.Time) (*models.MapActivity, error) {
+	activity := &models.MapActivity{}
+
+	err := r.db.WithContext(ctx).
+		Where("map_id = ? AND created_at >= ? AND created_at < ?", mapID, from, to).
+		Order("created_at ASC").
+		Find(&activity.NewPOIs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get new POIs: %w", err)
+	}
+
+	// Ended sessions are soft deleted but still count as participation
+	err = r.db.WithContext(ctx).Unscoped().
+		Model(&models.Session{}).
+		Where("map_id = ? AND last_active >= ? AND created_at < ?", mapID, from, to).
+		Distinct("user_id").
+		Count(&activity.TotalParticipants).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count participants: %w", err)
+	}
+
+	return activity, nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// GuestAnonymizationRepository finds guests who left maps with guest
+// anonymization and scrubs their identity
+type GuestAnonymizationRepository struct {
+	db *gorm.DB
+}
+
+// NewGuestAnonymizationRepository creates a new guest anonymization repository
+func NewGuestAnonymizationRepository(db *gorm.DB) *GuestAnonymizationRepository {
+	return &GuestAnonymizationRepository{db: db}
+}
+
+// ListMapsWithGuestAnonymization returns the maps that anonymize guests who left them
+func (r *GuestAnonymizationRepository) ListMapsWithGuestAnonymization(ctx context.Context) ([]*models.Map, error) {
+	var maps []*models.Map
+	err := r.db.WithContext(ctx).
+		Where("guest_anonymization_enabled = ?", true).
+		Find(&maps).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list maps with guest anonymization: %w", err)
+	}
+
+	return maps, nil
+}
+
+// ListGuestsToAnonymize returns the guests not anonymized yet whose sessions
+// on a map all ended before endedBefore. Guests still connected to any map
+// keep their identity until they leave.
+func (r *GuestAnonymizationRepository) ListGuestsToAnonymize(ctx context.Context, mapID string, endedBefore time.Time) ([]string, error) {
+	var userIDs []string
+	err := r.db.WithContext(ctx).Unscoped().Model(&models.Session{}).
+		Joins("JOIN users ON users.id = sessions.user_id").
+		Where("sessions.map_id = ?", mapID).
+		Where("users.account_type = ? AND users.anonymized_at IS NULL AND users.deleted_at IS NULL", models.AccountTypeGuest).
+		Where("NOT EXISTS (SELECT 1 FROM sessions active WHERE active.user_id = sessions.user_id AND active.is_active AND active.deleted_at IS NULL)").
+		Group("sessions.user_id").
+		Having("MAX(sessions.last_active) < ?", endedBefore).
+		Pluck("sessions.user_id", &userIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list guests to anonymize on map %s: %w", mapID, err)
+	}
+
+	return userIDs, nil
+}
+
+// AnonymizeGuest replaces the guest's name, removes their avatar, bio and
+// email, and drops the user agent from their connection error records.
+// POIs, RSVPs and sessions keep pointing at the now anonymous account.
+func (r *GuestAnonymizationRepository) AnonymizeGuest(ctx context.Context, userID string, at time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Where("id = ? AND account_type = ?", userID, models.AccountTypeGuest).First(&user).Error; err != nil {
+			return fmt.Errorf("failed to get guest %s: %w", userID, err)
+		}
+
+		user.Anonymize(at)
+		err := tx.Model(&user).
+			Select("display_name", "avatar_url", "about_me", "email", "anonymized_at", "updated_at").
+			Updates(&user).Error
+		if err != nil {
+			return fmt.Errorf("failed to anonymize guest %s: %w", userID, err)
+		}
+
+		err = tx.Model(&models.ConnectionError{}).
+			Where("user_id = ?", userID).
+			Update("user_agent", "").Error
+		if err != nil {
+			return fmt.Errorf("failed to anonymize connection errors of guest %s: %w", userID, err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// guestHasContent matches guests who created POIs or maps, including deleted
+// ones that still reference them, or who have a pending invitation
+const guestHasContent = `EXISTS (SELECT 1 FROM pois WHERE pois.created_by = users.id)
+	OR EXISTS (SELECT 1 FROM maps WHERE maps.created_by = users.id)
+	OR EXISTS (SELECT 1 FROM invitations WHERE invitations.user_id = users.id AND invitations.accepted_at IS NULL AND invitations.expires_at > ?)`
+
+// GuestRetentionRepository finds expired guest profiles and purges them
+type GuestRetentionRepository struct {
+	db *gorm.DB
+}
+
+// NewGuestRetentionRepository creates a new guest retention repository
+func NewGuestRetentionRepository(db *gorm.DB) *GuestRetentionRepository {
+	return &GuestRetentionRepository{db: db}
+}
+
+// ListExpiredGuests returns the guests last active before inactiveSince that
+// are not connected and created nothing other users rely on. A guest was last
+// active when their latest session was, including ended ones, or when they
+// were created if they never had a session.
+func (r *GuestRetentionRepository) ListExpiredGuests(ctx context.Context, inactiveSince time.Time) ([]*models.User, error) {
+	var users []*models.User
+	err := r.db.WithContext(ctx).
+		Where("account_type = ? AND created_at < ?", models.AccountTypeGuest, inactiveSince).
+		Where("NOT EXISTS (SELECT 1 FROM sessions WHERE sessions.user_id = users.id AND sessions.last_active >= ?)", inactiveSince).
+		Where("NOT EXISTS (SELECT 1 FROM sessions WHERE sessions.user_id = users.id AND sessions.is_active AND sessions.deleted_at IS NULL)").
+		Where("NOT ("+guestHasContent+")", time.Now()).
+		Find(&users).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired guests: %w", err)
+	}
+
+	return users, nil
+}
+
+// LastActiveAt returns when the latest session of a user, including ended
+// ones, was last active, or the zero time if they never had a session
+func (r *GuestRetentionRepository) LastActiveAt(ctx context.Context, userID string) (time.Time, error) {
+	var sessions []models.Session
+	err := r.db.WithContext(ctx).Unscoped().
+		Select("last_active").
+		Where("user_id = ?", userID).
+		Order("last_active DESC").
+		Limit(1).
+		Find(&sessions).Error
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get last activity of user %s: %w", userID, err)
+	}
+	if len(sessions) == 0 {
+		return time.Time{}, nil
+	}
+
+	return sessions[0].LastActive, nil
+}
+
+// HasContent checks whether a guest created POIs or maps or has a pending
+// invitation, which keeps their profile from expiring
+func (r *GuestRetentionRepository) HasContent(ctx context.Context, userID string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Unscoped().Model(&models.User{}).
+		Where("id = ?", userID).
+		Where(guestHasContent, time.Now()).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check content of guest %s: %w", userID, err)
+	}
+
+	return count > 0, nil
+}
+
+// PurgeGuest deletes a guest and everything that only belonged to them:
+// sessions, RSVPs, preferences, onboarding progress, digest subscriptions,
+// connection error records and used invitations
+func (r *GuestRetentionRepository) PurgeGuest(ctx context.Context, userID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		owned := []interface{}{
+			&models.Session{},
+			&models.AuthSession{},
+			&models.POIRSVP{},
+			&models.UserPreference{},
+			&models.UserOnboardingStep{},
+			&models.DigestSubscription{},
+			&models.ConnectionError{},
+			&models.Invitation{},
+		}
+		for _, model := range owned {
+			if err := tx.Unscoped().Where("user_id = ?", userID).Delete(model).Error; err != nil {
+				return fmt.Errorf("failed to purge guest %s: %w", userID, err)
+			}
+		}
+
+		err := tx.Unscoped().
+			Where("id = ? AND account_type = ?", userID, models.AccountTypeGuest).
+			Delete(&models.User{}).Error
+		if err != nil {
+			return fmt.Errorf("failed to purge guest %s: %w", userID, err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/database"
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuestRetentionRepository_ListExpiredGuests_SQLite(t *testing.T) {
+	db, err := database.InitializeSQLite(filepath.Join(t.TempDir(), "breakoutglobe.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { database.CloseConnection(db) })
+
+	now := time.Now().UTC()
+	require.NoError(t, db.Create(&models.User{ID: "owner", DisplayName: "Owner", AccountType: models.AccountTypeFull, Role: models.UserRoleUser}).Error)
+	require.NoError(t, db.Create(&models.Map{ID: "map-1", Name: "Berlin Meetup", CreatedBy: "owner", IsActive: true}).Error)
+	for _, id := range []string{"idle-guest", "returning-guest", "sessionless-guest"} {
+		require.NoError(t, db.Create(&models.User{ID: id, DisplayName: id, AccountType: models.AccountTypeGuest, Role: models.UserRoleUser, CreatedAt: now.AddDate(0, 0, -60)}).Error)
+	}
+	require.NoError(t, db.Create(&models.Session{ID: "idle", UserID: "idle-guest", MapID: "map-1", LastActive: now.AddDate(0, 0, -40)}).Error)
+	require.NoError(t, db.Model(&models.Session{ID: "idle"}).Update("is_active", false).Error)
+	require.NoError(t, db.Create(&models.Session{ID: "returning", UserID: "returning-guest", MapID: "map-1", LastActive: now.AddDate(0, 0, -2)}).Error)
+	require.NoError(t, db.Delete(&models.Session{ID: "returning"}).Error)
+
+	repo := NewGuestRetentionRepository(db)
+	users, err := repo.ListExpiredGuests(context.Background(), now.AddDate(0, 0, -30))
+	require.NoError(t, err)
+	ids := []string{}
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+	// Ended sessions still count as activity
+	assert.ElementsMatch(t, []string{"idle-guest", "sessionless-guest"}, ids)
+
+	lastActive, err := repo.LastActiveAt(context.Background(), "returning-guest")
+	require.NoError(t, err)
+	assert.WithinDuration(t, now.AddDate(0, 0, -2), lastActive, time.Second)
+
+	lastActive, err = repo.LastActiveAt(context.Background(), "sessionless-guest")
+	require.NoError(t, err)
+	assert.True(t, lastActive.IsZero())
+}
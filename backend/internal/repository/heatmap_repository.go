@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// HeatmapRepository stores aggregated avatar position counts
+type HeatmapRepository struct {
+	db *gorm.DB
+}
+
+// NewHeatmapRepository creates a new heatmap repository
+func NewHeatmapRepository(db *gorm.DB) *HeatmapRepository {
+	return &HeatmapRepository{db: db}
+}
+
+// AddCounts adds cell counts to the stored counts of their buckets
+func (r *HeatmapRepository) AddCounts(ctx context.Context, cells []*models.MapHeatCell) error {
+	if len(cells) == 0 {
+		return nil
+	}
+
+	// A bucket may be rolled up more than once if a roll-up failed after writing,
+	// so counts are added rather than replaced
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "map_id"}, {Name: "bucket_start"}, {Name: "cell_row"}, {Name: "cell_col"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"count": gorm.Expr("map_heat_cells.count + EXCLUDED.count"),
+		}),
+	}).Create(&cells).Error
+	if err != nil {
+		return fmt.Errorf("failed to add heatmap counts: %w", err)
+	}
+
+	return nil
+}
+
+// GetCellTotals returns the count of every cell of a map with buckets in [from, to)
+func (r *HeatmapRepository) GetCellTotals(ctx context.Context, mapID string, from, to time.Time) ([]models.HeatCellTotal, error) {
+	var totals []models.HeatCellTotal
+	err := r.db.WithContext(ctx).
+		Model(&models.MapHeatCell{}).
+		Select("cell_row, cell_col, SUM(count) AS count").
+		Where("map_id = ? AND bucket_start >= ? AND bucket_start < ?", mapID, from, to).
+		Group("cell_row, cell_col").
+		Order("count DESC").
+		Scan(&totals).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get heatmap: %w", err)
+	}
+
+	return totals, nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"breakoutglobe/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ImageReferenceRepository lists the image URLs referenced by POIs, users and
+// map snapshots
+type ImageReferenceRepository struct {
+	db *gorm.DB
+}
+
+// NewImageReferenceRepository creates a new image reference repository
+func NewImageReferenceRepository(db *gorm.DB) *ImageReferenceRepository {
+	return &ImageReferenceRepository{db: db}
+}
+
+// ListImageURLs returns every POI image, POI thumbnail and user avatar URL,
+// and the POI images of the snapshots of deleted maps that can still be
+// restored. Soft-deleted rows are excluded, so their images count as
+// unreferenced.
+func (r *ImageReferenceRepository) ListImageURLs(ctx context.Context) ([]string, error) {
+	var urls []string
+	for _, column := range []string{"image_url", "thumbnail_url"} {
+		var poiURLs []string
+		err := r.db.WithContext(ctx).Model(&models.POI{}).
+			Where(column+" <> ''").
+			Pluck(column, &poiURLs).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to list POI images: %w", err)
+		}
+		urls = append(urls, poiURLs...)
+	}
+
+	var avatarURLs []string
+	err := r.db.WithContext(ctx).Model(&models.User{}).
+		Where("avatar_url IS NOT NULL AND avatar_url <> ''").
+		Pluck("avatar_url", &avatarURLs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user avatars: %w", err)
+	}
+	urls = append(urls, avatarURLs...)
+
+	var snapshots []models.MapSnapshot
+	err = r.db.WithContext(ctx).Select("archive").
+		Where("restored_map_id IS NULL OR restored_map_id = ''").
+		Find(&snapshots).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list map snapshot images: %w", err)
+	}
+	for _, snapshot := range snapshots {
+		for _, image := range snapshot.Archive.Images {
+			urls = append(urls, image.ImageURL)
+			if image.ThumbnailURL != "" {
+				urls = append(urls, image.ThumbnailURL)
+			}
+		}
+	}
+
+	return urls, nil
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/database"
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageReferenceRepository_ListImageURLs_SQLite(t *testing.T) {
+	db, err := database.InitializeSQLite(filepath.Join(t.TempDir(), "breakoutglobe.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { database.CloseConnection(db) })
+
+	avatar := "/uploads/avatars/user-1.jpg"
+	require.NoError(t, db.Create(&models.User{ID: "user-1", DisplayName: "Ada", AccountType: models.AccountTypeGuest, Role: models.UserRoleUser, AvatarURL: &avatar}).Error)
+	require.NoError(t, db.Create(&models.POI{ID: "poi-1", MapID: "default-map", Name: "Kept", CreatedBy: "user-1", ImageURL: "/uploads/blobs/aa/kept.jpg", ThumbnailURL: "/uploads/pois/kept-thumb.jpg"}).Error)
+
+	m := &models.Map{ID: "map-1", CreatedBy: "user-1"}
+	archive := models.MapArchive{Images: []models.MapArchiveImage{{POIID: "poi-2", ImageURL: "/uploads/blobs/bb/deleted.jpg"}}}
+	require.NoError(t, db.Create(models.NewMapSnapshot(m, "user-1", archive, time.Now())).Error)
+	restored := models.NewMapSnapshot(m, "user-1", models.MapArchive{Images: []models.MapArchiveImage{{POIID: "poi-3", ImageURL: "/uploads/blobs/cc/restored.jpg"}}}, time.Now())
+	restored.RestoredMapID = "map-2"
+	require.NoError(t, db.Create(restored).Error)
+
+	urls, err := NewImageReferenceRepository(db).ListImageURLs(context.Background())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"/uploads/blobs/aa/kept.jpg",
+		"/uploads/pois/kept-thumb.jpg",
+		"/uploads/avatars/user-1.jpg",
+		"/uploads/blobs/bb/deleted.jpg", // Snapshots that can still be restored keep their images
+	}, urls)
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"breakoutglobe/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// InvitationRepository stores map invitations
+type InvitationRepository struct {
+	db *gorm.DB
+}
+
+// NewInvitationRepository creates a new invitation repository
+func NewInvitationRepository(db *gorm.DB) *InvitationRepository {
+	return &InvitationRepository{db: db}
+}
+
+// Save creates an invitation or updates an existing one
+func (r *InvitationRepository) Save(ctx context.Context, invitation *models.Invitation) error {
+	if err := r.db.WithContext(ctx).Save(invitation).Error; err != nil {
+		return fmt.Errorf("failed to save invitation: %w", err)
+	}
+
+	return nil
+}
+
+// GetByMapAndEmail returns the invitation of an email address to a map
+func (r *InvitationRepository) GetByMapAndEmail(ctx context.Context, mapID, email string) (*models.Invitation, error) {
+	var invitation models.Invitation
+	err := r.db.WithContext(ctx).Where("map_id = ? AND email = ?", mapID, email).First(&invitation).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+
+	return &invitation, nil
+}
+
+// GetByTokenHash returns the invitation with the given token hash
+func (r *InvitationRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.Invitation, error) {
+	var invitation models.Invitation
+	err := r.db.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&invitation).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+
+	return &invitation, nil
+}
+
+// ListByMap returns all invitations to a map, newest first
+func (r *InvitationRepository) ListByMap(ctx context.Context, mapID string) ([]*models.Invitation, error) {
+	var invitations []*models.Invitation
+	err := r.db.WithContext(ctx).Where("map_id = ?", mapID).Order("created_at DESC").Find(&invitations).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list invitations: %w", err)
+	}
+
+	return invitations, nil
+}
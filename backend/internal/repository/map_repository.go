@@ -0,0 +1,316 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"breakoutglobe/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// MapRepository reads and stores maps
+type MapRepository struct {
+	db *gorm.DB
+}
+
+// NewMapRepository creates a new map repository
+func NewMapRepository(db *gorm.DB) *MapRepository {
+	return &MapRepository{db: db}
+}
+
+// GetByID retrieves a map by ID
+func (r *MapRepository) GetByID(ctx context.Context, id string) (*models.Map, error) {
+	var m models.Map
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&m).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get map: %w", err)
+	}
+
+	return &m, nil
+}
+
+// UpdateSpawnPoints replaces the spawn points of a map
+func (r *MapRepository) UpdateSpawnPoints(ctx context.Context, id string, points []models.SpawnPoint) error {
+	result := r.db.WithContext(ctx).Model(&models.Map{ID: id}).Select("spawn_points").Updates(&models.Map{SpawnPoints: points})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update spawn points: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// UpdatePersonalSpace replaces the personal space setting of a map
+func (r *MapRepository) UpdatePersonalSpace(ctx context.Context, id string, personalSpace models.PersonalSpace) error {
+	result := r.db.WithContext(ctx).Model(&models.Map{ID: id}).
+		Select("personal_space_mode", "personal_space_radius_meters").
+		Updates(&models.Map{PersonalSpace: personalSpace})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update personal space: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// UpdatePositionPrivacy replaces the position privacy setting of a map
+func (r *MapRepository) UpdatePositionPrivacy(ctx context.Context, id string, privacy models.PositionPrivacy) error {
+	result := r.db.WithContext(ctx).Model(&models.Map{ID: id}).
+		Select("position_privacy_grid_meters").
+		Updates(&models.Map{PositionPrivacy: privacy})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update position privacy: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// UpdateGuestAnonymization replaces the guest anonymization setting of a map
+func (r *MapRepository) UpdateGuestAnonymization(ctx context.Context, id string, anonymization models.GuestAnonymization) error {
+	result := r.db.WithContext(ctx).Model(&models.Map{ID: id}).
+		Select("guest_anonymization_enabled", "guest_anonymization_retention_hours").
+		Updates(&models.Map{GuestAnonymization: anonymization})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update guest anonymization: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// UpdateSlowConsumerPolicy replaces the slow consumer policy of a map
+func (r *MapRepository) UpdateSlowConsumerPolicy(ctx context.Context, id string, policy models.SlowConsumerPolicy) error {
+	result := r.db.WithContext(ctx).Model(&models.Map{ID: id}).
+		Select("slow_consumer_send_buffer_size", "slow_consumer_drop_policy", "slow_consumer_evict_after").
+		Updates(&models.Map{SlowConsumer: policy})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update slow consumer policy: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// UpdateMessageAuditPolicy replaces the message audit policy of a map
+func (r *MapRepository) UpdateMessageAuditPolicy(ctx context.Context, id string, policy models.MessageAuditPolicy) error {
+	result := r.db.WithContext(ctx).Model(&models.Map{ID: id}).
+		Select("message_audit_sample_rate", "message_audit_flagged_sessions").
+		Updates(&models.Map{MessageAudit: policy})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update message audit policy: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// UpdateLocale replaces the locale of a map's server-generated content
+func (r *MapRepository) UpdateLocale(ctx context.Context, id string, locale string) error {
+	result := r.db.WithContext(ctx).Model(&models.Map{ID: id}).
+		Select("locale").
+		Updates(&models.Map{Locale: locale})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update locale: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// UpdateOwner transfers a map to another user and drops its pending deletion
+// request, which the previous owner can't confirm anymore
+func (r *MapRepository) UpdateOwner(ctx context.Context, id string, ownerID string) error {
+	result := r.db.WithContext(ctx).Model(&models.Map{ID: id}).
+		Select("created_by", "deletion_requested_by", "deletion_token_hash", "deletion_requested_at", "deletion_confirmable_at", "deletion_expires_at").
+		Updates(&models.Map{CreatedBy: ownerID})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update owner: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// UpdateDeletionRequest replaces the pending deletion request of a map. An
+// empty request cancels it.
+func (r *MapRepository) UpdateDeletionRequest(ctx context.Context, id string, request models.MapDeletionRequest) error {
+	result := r.db.WithContext(ctx).Model(&models.Map{ID: id}).
+		Select("deletion_requested_by", "deletion_token_hash", "deletion_requested_at", "deletion_confirmable_at", "deletion_expires_at").
+		Updates(&models.Map{Deletion: request})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update deletion request: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// DeleteWithPOIs deletes a map together with its POIs and sessions, and
+// stores its snapshot, in one transaction
+func (r *MapRepository) DeleteWithPOIs(ctx context.Context, id string, snapshot *models.MapSnapshot) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(snapshot).Error; err != nil {
+			return fmt.Errorf("failed to store map snapshot: %w", err)
+		}
+		if err := tx.Where("map_id = ?", id).Delete(&models.POI{}).Error; err != nil {
+			return fmt.Errorf("failed to delete POIs: %w", err)
+		}
+		if err := tx.Where("map_id = ?", id).Delete(&models.Session{}).Error; err != nil {
+			return fmt.Errorf("failed to delete sessions: %w", err)
+		}
+		result := tx.Delete(&models.Map{ID: id})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete map: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// GetSnapshot retrieves the snapshot of a deleted map by ID
+func (r *MapRepository) GetSnapshot(ctx context.Context, id string) (*models.MapSnapshot, error) {
+	var snapshot models.MapSnapshot
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&snapshot).Error; err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// MarkSnapshotRestored records the map a snapshot was restored as. It returns
+// gorm.ErrRecordNotFound if the snapshot doesn't exist or was already restored.
+func (r *MapRepository) MarkSnapshotRestored(ctx context.Context, id string, mapID string) error {
+	result := r.db.WithContext(ctx).Model(&models.MapSnapshot{}).
+		Where("id = ? AND (restored_map_id IS NULL OR restored_map_id = '')", id).
+		Update("restored_map_id", mapID)
+	if result.Error != nil {
+		return fmt.Errorf("failed to mark snapshot restored: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// UpdateProfilePrivacy replaces the profile privacy setting of a map
+func (r *MapRepository) UpdateProfilePrivacy(ctx context.Context, id string, privacy models.ProfilePrivacy) error {
+	result := r.db.WithContext(ctx).Model(&models.Map{ID: id}).
+		Select("profile_privacy_hide_about_me", "profile_privacy_hide_avatar_url").
+		Updates(&models.Map{ProfilePrivacy: privacy})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update profile privacy: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// CreateWithPOIs creates a map together with its POIs in one transaction
+func (r *MapRepository) CreateWithPOIs(ctx context.Context, m *models.Map, pois []*models.POI) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(m).Error; err != nil {
+			return fmt.Errorf("failed to create map: %w", err)
+		}
+		if len(pois) == 0 {
+			return nil
+		}
+		if err := tx.CreateInBatches(pois, 100).Error; err != nil {
+			return fmt.Errorf("failed to create POIs: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListDiscoverable lists the active maps listed in the public directory. query
+// matches name or description case-insensitively, and a map must carry all tags.
+func (r *MapRepository) ListDiscoverable(ctx context.Context, query string, tags []string) ([]*models.Map, error) {
+	db := r.db.WithContext(ctx).Where("discoverable = ? AND is_active = ?", true, true)
+	if r.db.Dialector.Name() == "sqlite" {
+		return r.listDiscoverableSQLite(db, query, tags)
+	}
+	if query != "" {
+		pattern := "%" + escapeLike(query) + "%"
+		db = db.Where("(name ILIKE ? OR description ILIKE ?)", pattern, pattern)
+	}
+	if len(tags) > 0 {
+		encoded, err := json.Marshal(tags)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode tags: %w", err)
+		}
+		db = db.Where("tags @> ?::jsonb", string(encoded))
+	}
+
+	var maps []*models.Map
+	if err := db.Order("created_at DESC").Find(&maps).Error; err != nil {
+		return nil, fmt.Errorf("failed to list discoverable maps: %w", err)
+	}
+
+	return maps, nil
+}
+
+// listDiscoverableSQLite filters like ListDiscoverable with SQLite's LIKE,
+// which ignores ASCII case, and its JSON functions
+func (r *MapRepository) listDiscoverableSQLite(db *gorm.DB, query string, tags []string) ([]*models.Map, error) {
+	if query != "" {
+		pattern := "%" + escapeLike(query) + "%"
+		db = db.Where(`(name LIKE ? ESCAPE '\' OR description LIKE ? ESCAPE '\')`, pattern, pattern)
+	}
+	for _, tag := range tags {
+		db = db.Where("EXISTS (SELECT 1 FROM json_each(maps.tags) WHERE json_each.value = ?)", tag)
+	}
+
+	var maps []*models.Map
+	if err := db.Order("created_at DESC").Find(&maps).Error; err != nil {
+		return nil, fmt.Errorf("failed to list discoverable maps: %w", err)
+	}
+
+	return maps, nil
+}
+
+// UpdateListing replaces the directory listing of a map
+func (r *MapRepository) UpdateListing(ctx context.Context, id string, listing models.MapListing) error {
+	result := r.db.WithContext(ctx).Model(&models.Map{ID: id}).
+		Select("discoverable", "tags").
+		Updates(&models.Map{Discoverable: listing.Discoverable, Tags: listing.Tags})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update listing: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
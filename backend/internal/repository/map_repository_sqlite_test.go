@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/database"
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestMapRepository_ListDiscoverable_SQLite(t *testing.T) {
+	db, err := database.InitializeSQLite(filepath.Join(t.TempDir(), "breakoutglobe.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { database.CloseConnection(db) })
+
+	ctx := context.Background()
+	repo := NewMapRepository(db)
+	for _, m := range []*models.Map{
+		{ID: "map-1", Name: "Berlin Meetup", CreatedBy: "system", IsActive: true, Discoverable: true, Tags: []string{"berlin", "tech"}},
+		{ID: "map-2", Name: "100% Remote", CreatedBy: "system", IsActive: true, Discoverable: true, Tags: []string{"tech"}},
+		{ID: "map-3", Name: "Berlin Private", CreatedBy: "system", IsActive: true},
+	} {
+		require.NoError(t, db.Create(m).Error)
+	}
+
+	maps, err := repo.ListDiscoverable(ctx, "berlin", nil)
+	require.NoError(t, err)
+	require.Len(t, maps, 1)
+	assert.Equal(t, "map-1", maps[0].ID)
+
+	// % is matched literally
+	maps, err = repo.ListDiscoverable(ctx, "0%", nil)
+	require.NoError(t, err)
+	require.Len(t, maps, 1)
+	assert.Equal(t, "map-2", maps[0].ID)
+
+	maps, err = repo.ListDiscoverable(ctx, "", []string{"tech", "berlin"})
+	require.NoError(t, err)
+	require.Len(t, maps, 1)
+	assert.Equal(t, "map-1", maps[0].ID)
+
+	maps, err = repo.ListDiscoverable(ctx, "", []string{"tech"})
+	require.NoError(t, err)
+	assert.Len(t, maps, 2)
+}
+
+func TestMapRepository_DeleteWithPOIs_StoresSnapshot_SQLite(t *testing.T) {
+	db, err := database.InitializeSQLite(filepath.Join(t.TempDir(), "breakoutglobe.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { database.CloseConnection(db) })
+
+	ctx := context.Background()
+	repo := NewMapRepository(db)
+	m := &models.Map{ID: "map-1", Name: "Workshop", CreatedBy: "system", IsActive: true}
+	require.NoError(t, db.Create(m).Error)
+
+	archive := models.MapArchive{Version: models.MapArchiveVersion, SourceID: "map-1", Map: models.MapArchiveSettings{Name: "Workshop"}}
+	snapshot := models.NewMapSnapshot(m, "system", archive, time.Now())
+	require.NoError(t, repo.DeleteWithPOIs(ctx, "map-1", snapshot))
+
+	_, err = repo.GetByID(ctx, "map-1")
+	assert.Error(t, err)
+	stored, err := repo.GetSnapshot(ctx, snapshot.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Workshop", stored.Archive.Map.Name)
+	assert.Equal(t, "system", stored.OwnerID)
+
+	// A snapshot is only marked restored once
+	require.NoError(t, repo.MarkSnapshotRestored(ctx, snapshot.ID, "map-2"))
+	assert.ErrorIs(t, repo.MarkSnapshotRestored(ctx, snapshot.ID, "map-3"), gorm.ErrRecordNotFound)
+}
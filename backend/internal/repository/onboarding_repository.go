@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"breakoutglobe/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OnboardingRepository stores the welcome checklist steps users completed
+type OnboardingRepository struct {
+	db *gorm.DB
+}
+
+// NewOnboardingRepository creates a new onboarding repository
+func NewOnboardingRepository(db *gorm.DB) *OnboardingRepository {
+	return &OnboardingRepository{db: db}
+}
+
+// ListByUser returns the steps a user completed
+func (r *OnboardingRepository) ListByUser(ctx context.Context, userID string) ([]*models.UserOnboardingStep, error) {
+	var steps []*models.UserOnboardingStep
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("completed_at").Find(&steps).Error; err != nil {
+		return nil, fmt.Errorf("failed to list onboarding steps: %w", err)
+	}
+
+	return steps, nil
+}
+
+// Complete records a completed step. It returns false if the user had already
+// completed it, keeping the original completion time.
+func (r *OnboardingRepository) Complete(ctx context.Context, step *models.UserOnboardingStep) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(step)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to complete onboarding step: %w", result.Error)
+	}
+
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"breakoutglobe/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PreferenceRepository stores the preferences of users
+type PreferenceRepository struct {
+	db *gorm.DB
+}
+
+// NewPreferenceRepository creates a new preference repository
+func NewPreferenceRepository(db *gorm.DB) *PreferenceRepository {
+	return &PreferenceRepository{db: db}
+}
+
+// ListByUser returns the stored preference namespaces of a user
+func (r *PreferenceRepository) ListByUser(ctx context.Context, userID string) ([]*models.UserPreference, error) {
+	var preferences []*models.UserPreference
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&preferences).Error; err != nil {
+		return nil, fmt.Errorf("failed to list preferences: %w", err)
+	}
+
+	return preferences, nil
+}
+
+// SaveAll creates or replaces the given preference namespaces in one transaction
+func (r *PreferenceRepository) SaveAll(ctx context.Context, preferences []*models.UserPreference) error {
+	if len(preferences) == 0 {
+		return nil
+	}
+
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "namespace"}},
+		DoUpdates: clause.AssignmentColumns([]string{"values", "updated_at"}),
+	}).Create(preferences).Error
+	if err != nil {
+		return fmt.Errorf("failed to save preferences: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RSVPRepository stores RSVPs to scheduled POIs
+type RSVPRepository struct {
+	db *gorm.DB
+}
+
+// NewRSVPRepository creates a new RSVP repository
+func NewRSVPRepository(db *gorm.DB) *RSVPRepository {
+	return &RSVPRepository{db: db}
+}
+
+// rsvpConflict updates the status of an existing RSVP of the same user
+var rsvpConflict = clause.OnConflict{
+	Columns:   []clause.Column{{Name: "poi_id"}, {Name: "user_id"}},
+	DoUpdates: clause.AssignmentColumns([]string{"status", "updated_at"}),
+}
+
+// UpsertRSVP creates an RSVP or updates the status of an existing one
+func (r *RSVPRepository) UpsertRSVP(ctx context.Context, rsvp *models.POIRSVP) (*models.POIRSVP, error) {
+	if err := r.db.WithContext(ctx).Clauses(rsvpConflict).Create(rsvp).Error; err != nil {
+		return nil, fmt.Errorf("failed to save RSVP: %w", err)
+	}
+
+	// On conflict the stored row keeps its own ID
+	return r.GetRSVP(ctx, rsvp.POIID, rsvp.UserID)
+}
+
+// UpsertGoingRSVP saves an RSVP if fewer than seats other users are going.
+// The POI row is locked while the seats are counted, so concurrent RSVPs
+// can't take the last seat twice. It returns false if all seats are taken.
+func (r *RSVPRepository) UpsertGoingRSVP(ctx context.Context, rsvp *models.POIRSVP, seats int) (*models.POIRSVP, bool, error) {
+	seated := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var poi models.POI
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", rsvp.POIID).First(&poi).Error; err != nil {
+			return err
+		}
+
+		var taken int64
+		err := tx.Model(&models.POIRSVP{}).
+			Where("poi_id = ? AND status = ? AND user_id <> ?", rsvp.POIID, models.RSVPGoing, rsvp.UserID).
+			Count(&taken).Error
+		if err != nil || taken >= int64(seats) {
+			return err
+		}
+
+		seated = true
+		return tx.Clauses(rsvpConflict).Create(rsvp).Error
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to save RSVP: %w", err)
+	}
+	if !seated {
+		return nil, false, nil
+	}
+
+	saved, err := r.GetRSVP(ctx, rsvp.POIID, rsvp.UserID)
+	return saved, err == nil, err
+}
+
+// GetRSVP returns the RSVP of a user to a POI
+func (r *RSVPRepository) GetRSVP(ctx context.Context, poiID, userID string) (*models.POIRSVP, error) {
+	var rsvp models.POIRSVP
+	err := r.db.WithContext(ctx).Where("poi_id = ? AND user_id = ?", poiID, userID).First(&rsvp).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get RSVP: %w", err)
+	}
+
+	return &rsvp, nil
+}
+
+// DeleteRSVP removes the RSVP of a user to a POI
+func (r *RSVPRepository) DeleteRSVP(ctx context.Context, poiID, userID string) error {
+	result := r.db.WithContext(ctx).Where("poi_id = ? AND user_id = ?", poiID, userID).Delete(&models.POIRSVP{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete RSVP: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// MoveRSVPs moves the RSVPs of one POI to another. RSVPs of users who already
+// answered the other POI are dropped.
+func (r *RSVPRepository) MoveRSVPs(ctx context.Context, fromPOIID, toPOIID string) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		answered := tx.Model(&models.POIRSVP{}).Select("user_id").Where("poi_id = ?", toPOIID)
+		if err := tx.Where("poi_id = ? AND user_id IN (?)", fromPOIID, answered).Delete(&models.POIRSVP{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.POIRSVP{}).Where("poi_id = ?", fromPOIID).
+			Updates(map[string]interface{}{"poi_id": toPOIID, "updated_at": time.Now()}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to move RSVPs: %w", err)
+	}
+
+	return nil
+}
+
+// ListUserIDs returns the users who answered a POI with one of the given statuses
+func (r *RSVPRepository) ListUserIDs(ctx context.Context, poiID string, statuses ...models.RSVPStatus) ([]string, error) {
+	var userIDs []string
+	err := r.db.WithContext(ctx).Model(&models.POIRSVP{}).
+		Where("poi_id = ? AND status IN ?", poiID, statuses).
+		Order("created_at ASC").
+		Pluck("user_id", &userIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list RSVPs: %w", err)
+	}
+
+	return userIDs, nil
+}
+
+// CountByPOI counts the RSVPs of each POI by status
+func (r *RSVPRepository) CountByPOI(ctx context.Context, poiIDs []string) (map[string]models.RSVPCounts, error) {
+	counts := make(map[string]models.RSVPCounts, len(poiIDs))
+	if len(poiIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		POIID  string
+		Status models.RSVPStatus
+		Count  int
+	}
+	err := r.db.WithContext(ctx).Model(&models.POIRSVP{}).
+		Select("poi_id, status, COUNT(*) AS count").
+		Where("poi_id IN ?", poiIDs).
+		Group("poi_id, status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count RSVPs: %w", err)
+	}
+
+	for _, row := range rows {
+		c := counts[row.POIID]
+		c.Add(row.Status, row.Count)
+		counts[row.POIID] = c
+	}
+
+	return counts, nil
+}
+
+// ListPOIsStartingBetween returns scheduled POIs starting in (from, to] that
+// haven't had their reminder sent
+func (r *RSVPRepository) ListPOIsStartingBetween(ctx context.Context, from, to time.Time) ([]*models.POI, error) {
+	var pois []*models.POI
+	err := r.db.WithContext(ctx).
+		Where("starts_at > ? AND starts_at <= ? AND reminder_sent_at IS NULL", from, to).
+		Order("starts_at ASC").
+		Find(&pois).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list upcoming POIs: %w", err)
+	}
+
+	return pois, nil
+}
+
+// MarkReminderSent records when the start reminder of a POI was sent
+func (r *RSVPRepository) MarkReminderSent(ctx context.Context, poiID string, sentAt time.Time) error {
+	err := r.db.WithContext(ctx).Model(&models.POI{}).Where("id = ?", poiID).Update("reminder_sent_at", sentAt).Error
+	if err != nil {
+		return fmt.Errorf("failed to mark reminder as sent: %w", err)
+	}
+
+	return nil
+}
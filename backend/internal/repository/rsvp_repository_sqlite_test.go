@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/database"
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRSVPRepository_MoveRSVPs_SQLite(t *testing.T) {
+	db, err := database.InitializeSQLite(filepath.Join(t.TempDir(), "breakoutglobe.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { database.CloseConnection(db) })
+
+	startsAt := time.Now().Add(time.Hour)
+	for _, id := range []string{"user-1", "user-2", "user-3"} {
+		require.NoError(t, db.Create(&models.User{ID: id, DisplayName: id, AccountType: models.AccountTypeGuest, Role: models.UserRoleUser}).Error)
+	}
+	for _, id := range []string{"poi-1", "poi-2"} {
+		require.NoError(t, db.Create(&models.POI{ID: id, MapID: "default-map", Name: id, CreatedBy: "user-1", MaxParticipants: 10, StartsAt: &startsAt}).Error)
+	}
+
+	repo := NewRSVPRepository(db)
+	ctx := context.Background()
+	for _, rsvp := range []struct {
+		poiID, userID string
+		status        models.RSVPStatus
+	}{
+		{"poi-1", "user-1", models.RSVPGoing},
+		{"poi-1", "user-2", models.RSVPMaybe},
+		{"poi-2", "user-2", models.RSVPGoing},
+		{"poi-2", "user-3", models.RSVPNo},
+	} {
+		r, err := models.NewPOIRSVP(rsvp.poiID, rsvp.userID, rsvp.status)
+		require.NoError(t, err)
+		_, err = repo.UpsertRSVP(ctx, r)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, repo.MoveRSVPs(ctx, "poi-2", "poi-1"))
+
+	// user-2 keeps their answer to poi-1
+	counts, err := repo.CountByPOI(ctx, []string{"poi-1", "poi-2"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]models.RSVPCounts{"poi-1": {Going: 1, Maybe: 1, No: 1}}, counts)
+
+	rsvp, err := repo.GetRSVP(ctx, "poi-1", "user-2")
+	require.NoError(t, err)
+	assert.Equal(t, models.RSVPMaybe, rsvp.Status)
+}
+
+func TestRSVPRepository_UpsertGoingRSVP_SQLite(t *testing.T) {
+	db, err := database.InitializeSQLite(filepath.Join(t.TempDir(), "breakoutglobe.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { database.CloseConnection(db) })
+
+	startsAt := time.Now().Add(time.Hour)
+	for _, id := range []string{"user-1", "user-2"} {
+		require.NoError(t, db.Create(&models.User{ID: id, DisplayName: id, AccountType: models.AccountTypeGuest, Role: models.UserRoleUser}).Error)
+	}
+	require.NoError(t, db.Create(&models.POI{ID: "poi-1", MapID: "default-map", Name: "Standup", CreatedBy: "user-1", MaxParticipants: 1, StartsAt: &startsAt}).Error)
+
+	repo := NewRSVPRepository(db)
+	ctx := context.Background()
+	going := func(userID string) bool {
+		rsvp, err := models.NewPOIRSVP("poi-1", userID, models.RSVPGoing)
+		require.NoError(t, err)
+		_, seated, err := repo.UpsertGoingRSVP(ctx, rsvp, 1)
+		require.NoError(t, err)
+		return seated
+	}
+
+	assert.True(t, going("user-1"))
+	assert.False(t, going("user-2"))
+	// Users keep their own seat when answering again
+	assert.True(t, going("user-1"))
+
+	userIDs, err := repo.ListUserIDs(ctx, "poi-1", models.RSVPGoing)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user-1"}, userIDs)
+}
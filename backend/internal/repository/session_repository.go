@@ -2,17 +2,35 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"breakoutglobe/internal/database"
 	"breakoutglobe/internal/models"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrActiveSessionExists is returned when a user already has an active session in a map
+var ErrActiveSessionExists = errors.New("user already has an active session in this map")
+
+// activeSessionConflict targets the partial unique index on active sessions
+// (idx_sessions_active_user_map), so inserts racing for the same user and map
+// resolve to a single row
+var activeSessionConflict = clause.OnConflict{
+	Columns: []clause.Column{{Name: "user_id"}, {Name: "map_id"}},
+	TargetWhere: clause.Where{Exprs: []clause.Expression{
+		clause.Expr{SQL: "is_active = true AND deleted_at IS NULL"},
+	}},
+	DoNothing: true,
+}
+
 // SessionRepository defines the interface for session data operations
 type SessionRepository interface {
 	Create(session *models.Session) error
+	CreateOrGetActive(session *models.Session) (*models.Session, bool, error)
 	GetByID(id string) (*models.Session, error)
 	GetByIDWithUser(id string) (*models.Session, error)
 	GetByUserAndMap(userID, mapID string) (*models.Session, error)
@@ -20,6 +38,7 @@ type SessionRepository interface {
 	UpdateAvatarPosition(sessionID string, position models.LatLng) error
 	Delete(id string) error
 	GetActiveByMap(mapID string) ([]*models.Session, error)
+	GetActiveMapIDsByUser(userID string) ([]string, error)
 	ExpireOldSessions(timeout time.Duration) error
 }
 
@@ -50,11 +69,61 @@ func (r *sessionRepository) Create(session *models.Session) error {
 		First(&existingSession).Error
 
 	if err == nil {
-		return fmt.Errorf("user already has an active session in this map")
+		return ErrActiveSessionExists
 	} else if err != gorm.ErrRecordNotFound {
 		return fmt.Errorf("failed to check existing session: %w", err)
 	}
 
+	if err := prepareSession(session); err != nil {
+		return err
+	}
+
+	err = r.db.WithContext(ctx).Create(session).Error
+	if err != nil {
+		// A concurrent request created the session between the check and the insert
+		if r.isUniqueViolation(err) {
+			return ErrActiveSessionExists
+		}
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return nil
+}
+
+// CreateOrGetActive creates the session unless the user already has an active
+// session in the map, in which case the existing session is returned.
+// The returned bool reports whether a new session was created.
+func (r *sessionRepository) CreateOrGetActive(session *models.Session) (*models.Session, bool, error) {
+	if session == nil {
+		return nil, false, fmt.Errorf("session cannot be nil")
+	}
+
+	if err := prepareSession(session); err != nil {
+		return nil, false, err
+	}
+
+	ctx := context.Background()
+
+	result := r.db.WithContext(ctx).Clauses(activeSessionConflict).Create(session)
+	if result.Error != nil {
+		return nil, false, fmt.Errorf("failed to create session: %w", result.Error)
+	}
+
+	if result.RowsAffected > 0 {
+		return session, true, nil
+	}
+
+	// The insert lost against an existing active session
+	existing, err := r.GetByUserAndMap(session.UserID, session.MapID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get existing session: %w", err)
+	}
+
+	return existing, false, nil
+}
+
+// prepareSession fills in generated fields and validates a session before insert
+func prepareSession(session *models.Session) error {
 	// Generate ID if not set
 	if session.ID == "" {
 		newSession, err := models.NewSession(session.UserID, session.MapID, session.AvatarPos)
@@ -71,14 +140,26 @@ func (r *sessionRepository) Create(session *models.Session) error {
 		return fmt.Errorf("session validation failed: %w", err)
 	}
 
-	err = r.db.WithContext(ctx).Create(session).Error
-	if err != nil {
-		return fmt.Errorf("failed to create session: %w", err)
-	}
-
 	return nil
 }
 
+// isUniqueViolation checks if a database error is a unique constraint
+// violation, on postgres as well as SQLite
+func (r *sessionRepository) isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "23505"
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	// Without TranslateError, the dialector still knows its driver's errors
+	if translator, ok := r.db.Dialector.(gorm.ErrorTranslator); ok {
+		return errors.Is(translator.Translate(err), gorm.ErrDuplicatedKey)
+	}
+	return false
+}
+
 // GetByID retrieves a session by its ID
 func (r *sessionRepository) GetByID(id string) (*models.Session, error) {
 	ctx := context.Background()
@@ -207,6 +288,23 @@ func (r *sessionRepository) GetActiveByMap(mapID string) ([]*models.Session, err
 	return sessions, nil
 }
 
+// GetActiveMapIDsByUser retrieves the distinct maps a user has active sessions in
+func (r *sessionRepository) GetActiveMapIDsByUser(userID string) ([]string, error) {
+	ctx := context.Background()
+	var mapIDs []string
+
+	err := r.db.WithContext(ctx).Model(&models.Session{}).
+		Where("user_id = ? AND is_active = ?", userID, true).
+		Distinct().
+		Pluck("map_id", &mapIDs).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active maps for user %s: %w", userID, err)
+	}
+
+	return mapIDs, nil
+}
+
 // ExpireOldSessions marks old sessions as inactive
 func (r *sessionRepository) ExpireOldSessions(timeout time.Duration) error {
 	ctx := context.Background()
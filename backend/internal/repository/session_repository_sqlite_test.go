@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"breakoutglobe/internal/database"
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionRepository_IsUniqueViolation_SQLite(t *testing.T) {
+	db, err := database.InitializeSQLite(filepath.Join(t.TempDir(), "breakoutglobe.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { database.CloseConnection(db) })
+
+	require.NoError(t, db.Create(&models.User{ID: "user-1", DisplayName: "User 1", AccountType: models.AccountTypeFull, Role: models.UserRoleUser}).Error)
+	require.NoError(t, db.Create(&models.Map{ID: "map-1", Name: "Berlin Meetup", CreatedBy: "user-1", IsActive: true}).Error)
+
+	repo := &sessionRepository{db: db}
+	require.NoError(t, repo.Create(&models.Session{UserID: "user-1", MapID: "map-1", IsActive: true}))
+
+	// An insert racing past the active session check hits the unique index
+	racing, err := models.NewSession("user-1", "map-1", models.LatLng{})
+	require.NoError(t, err)
+	err = db.Create(racing).Error
+	require.Error(t, err)
+	assert.True(t, repo.isUniqueViolation(err))
+
+	assert.False(t, repo.isUniqueViolation(errors.New("database unavailable")))
+}
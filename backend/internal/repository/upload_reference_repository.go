@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UploadReferenceRepository tracks references to deduplicated upload blobs
+type UploadReferenceRepository struct {
+	db *gorm.DB
+}
+
+// NewUploadReferenceRepository creates a new upload reference repository
+func NewUploadReferenceRepository(db *gorm.DB) *UploadReferenceRepository {
+	return &UploadReferenceRepository{db: db}
+}
+
+// AddReference records that key, uploaded by ownerID, refers to the blob
+// with the given content hash
+func (r *UploadReferenceRepository) AddReference(ctx context.Context, ownerID, key, contentHash string) error {
+	ref := &models.UploadReference{
+		Key:         key,
+		OwnerID:     ownerID,
+		ContentHash: contentHash,
+		CreatedAt:   time.Now(),
+	}
+
+	// Re-uploading to the same key replaces its reference
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"owner_id", "content_hash", "created_at"}),
+	}).Create(ref).Error
+	if err != nil {
+		return fmt.Errorf("failed to add upload reference: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveReference removes the reference for key and returns the hash it pointed to.
+// Returns an empty hash if the key is not referenced.
+func (r *UploadReferenceRepository) RemoveReference(ctx context.Context, key string) (string, error) {
+	var ref models.UploadReference
+	err := r.db.WithContext(ctx).Where("key = ?", key).First(&ref).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get upload reference: %w", err)
+	}
+
+	if err := r.db.WithContext(ctx).Delete(&ref).Error; err != nil {
+		return "", fmt.Errorf("failed to delete upload reference: %w", err)
+	}
+
+	return ref.ContentHash, nil
+}
+
+// RemoveOwnerReference removes the oldest reference ownerID holds to the
+// given content hash and reports whether there was one
+func (r *UploadReferenceRepository) RemoveOwnerReference(ctx context.Context, ownerID, contentHash string) (bool, error) {
+	var ref models.UploadReference
+	err := r.db.WithContext(ctx).
+		Where("owner_id = ? AND content_hash = ?", ownerID, contentHash).
+		Order("created_at ASC").
+		First(&ref).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get upload reference: %w", err)
+	}
+
+	if err := r.db.WithContext(ctx).Delete(&ref).Error; err != nil {
+		return false, fmt.Errorf("failed to delete upload reference: %w", err)
+	}
+
+	return true, nil
+}
+
+// CountReferences returns how many keys reference the given content hash
+func (r *UploadReferenceRepository) CountReferences(ctx context.Context, contentHash string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.UploadReference{}).
+		Where("content_hash = ?", contentHash).
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count upload references: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UsageRepository stores metered map usage rolled up per time bucket
+type UsageRepository struct {
+	db *gorm.DB
+}
+
+// NewUsageRepository creates a new usage repository
+func NewUsageRepository(db *gorm.DB) *UsageRepository {
+	return &UsageRepository{db: db}
+}
+
+// AddUsage adds usage to the stored usage of its maps and buckets
+func (r *UsageRepository) AddUsage(ctx context.Context, usage []*models.MapUsage) error {
+	if len(usage) == 0 {
+		return nil
+	}
+
+	// Every instance meters its own connections, so usage is added rather than replaced
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "map_id"}, {Name: "bucket_start"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"web_socket_bytes": gorm.Expr("map_usage.web_socket_bytes + EXCLUDED.web_socket_bytes"),
+			"storage_bytes":    gorm.Expr("map_usage.storage_bytes + EXCLUDED.storage_bytes"),
+			"call_seconds":     gorm.Expr("map_usage.call_seconds + EXCLUDED.call_seconds"),
+		}),
+	}).Create(&usage).Error
+	if err != nil {
+		return fmt.Errorf("failed to add map usage: %w", err)
+	}
+
+	return nil
+}
+
+// GetTotals returns the usage of every map with buckets in [from, to), or of
+// one map if mapID is set. BucketStart of the totals is not set.
+func (r *UsageRepository) GetTotals(ctx context.Context, mapID string, from, to time.Time) ([]*models.MapUsage, error) {
+	query := r.db.WithContext(ctx).
+		Model(&models.MapUsage{}).
+		Select("map_id, SUM(web_socket_bytes) AS web_socket_bytes, SUM(storage_bytes) AS storage_bytes, SUM(call_seconds) AS call_seconds").
+		Where("bucket_start >= ? AND bucket_start < ?", from, to)
+	if mapID != "" {
+		query = query.Where("map_id = ?", mapID)
+	}
+
+	var totals []*models.MapUsage
+	if err := query.Group("map_id").Order("map_id").Scan(&totals).Error; err != nil {
+		return nil, fmt.Errorf("failed to get map usage: %w", err)
+	}
+
+	return totals, nil
+}
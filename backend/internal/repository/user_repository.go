@@ -68,6 +68,16 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.
 	return &user, nil
 }
 
+// ListByRole lists the active users with a role
+func (r *userRepository) ListByRole(ctx context.Context, role models.UserRole) ([]*models.User, error) {
+	var users []*models.User
+	err := r.db.WithContext(ctx).Where("role = ? AND is_active = ?", role, true).Find(&users).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users by role: %w", err)
+	}
+	return users, nil
+}
+
 // Update updates an existing user in the database
 func (r *userRepository) Update(ctx context.Context, user *models.User) error {
 	if user == nil {
@@ -0,0 +1,91 @@
+// Package scheduler runs periodic background jobs such as analytics roll-ups.
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// JobFunc is the work of a scheduled job
+type JobFunc func(ctx context.Context) error
+
+type job struct {
+	name     string
+	interval time.Duration
+	run      JobFunc
+}
+
+// Scheduler runs registered jobs at fixed intervals. A job never overlaps with
+// itself: the next run is scheduled once the previous one finished.
+type Scheduler struct {
+	mutex   sync.Mutex
+	jobs    []job
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	running bool
+}
+
+// New creates a new Scheduler
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// Register adds a job that runs every interval once the scheduler is started.
+// Jobs registered after Start are not run.
+func (s *Scheduler) Register(name string, interval time.Duration, run JobFunc) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.jobs = append(s.jobs, job{name: name, interval: interval, run: run})
+}
+
+// Start runs all registered jobs in the background until Stop is called or ctx is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.running {
+		return
+	}
+	s.running = true
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, j)
+	}
+}
+
+// Stop stops all jobs and waits for running ones to finish
+func (s *Scheduler) Stop() {
+	s.mutex.Lock()
+	if !s.running {
+		s.mutex.Unlock()
+		return
+	}
+	s.running = false
+	s.cancel()
+	s.mutex.Unlock()
+
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, j job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := j.run(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("⚠️ Scheduled job %s failed: %v", j.name, err)
+			}
+		}
+	}
+}
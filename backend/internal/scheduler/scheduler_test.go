@@ -0,0 +1,74 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduler_RunsJobsUntilStopped(t *testing.T) {
+	s := New()
+
+	var runs int32
+	s.Register("count", 5*time.Millisecond, func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	})
+
+	s.Start(context.Background())
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&runs) >= 3
+	}, time.Second, time.Millisecond)
+
+	s.Stop()
+	stopped := atomic.LoadInt32(&runs)
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, stopped, atomic.LoadInt32(&runs))
+}
+
+func TestScheduler_KeepsRunningAfterJobError(t *testing.T) {
+	s := New()
+
+	var runs int32
+	s.Register("failing", 5*time.Millisecond, func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		return errors.New("boom")
+	})
+
+	s.Start(context.Background())
+	defer s.Stop()
+
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&runs) >= 2
+	}, time.Second, time.Millisecond)
+}
+
+func TestScheduler_JobsDoNotOverlap(t *testing.T) {
+	s := New()
+
+	var active, maxActive int32
+	s.Register("slow", time.Millisecond, func(ctx context.Context) error {
+		current := atomic.AddInt32(&active, 1)
+		if current > atomic.LoadInt32(&maxActive) {
+			atomic.StoreInt32(&maxActive, current)
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&active, -1)
+		return nil
+	})
+
+	s.Start(context.Background())
+	time.Sleep(50 * time.Millisecond)
+	s.Stop()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&maxActive))
+}
+
+func TestScheduler_StopWithoutStart(t *testing.T) {
+	s := New()
+	s.Stop()
+}
@@ -2,8 +2,10 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
@@ -14,31 +16,99 @@ import (
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	redislib "github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 
+	"breakoutglobe/internal/analytics"
 	"breakoutglobe/internal/config"
 	"breakoutglobe/internal/database"
+	"breakoutglobe/internal/geoip"
 	"breakoutglobe/internal/handlers"
+	"breakoutglobe/internal/logexport"
+	"breakoutglobe/internal/mailer"
 	"breakoutglobe/internal/middleware"
 	"breakoutglobe/internal/redis"
 	"breakoutglobe/internal/repository"
+	"breakoutglobe/internal/scheduler"
 	"breakoutglobe/internal/services"
 	"breakoutglobe/internal/storage"
+	"breakoutglobe/internal/webhook"
 	"breakoutglobe/internal/websocket"
 )
 
+// eventJournalLength is the number of broadcasts kept per map for replays
+const eventJournalLength = 500
+
 type Server struct {
 	config *config.Config
 	router *gin.Engine
+	// Deprecation of unversioned /api paths, nil while they are a plain alias
+	unversionedAPI *middleware.Deprecation
+	// HTTP server started by Start and stopped by Shutdown
+	httpServer *http.Server
+	// WebSocket handler, drained on shutdown; nil without storage
+	wsHandler *websocket.Handler
+	// drainRequested is closed once a rolling restart asked the instance to drain
+	drainRequested chan struct{}
+	drainOnce      sync.Once
 	db     *gorm.DB
 	redis  *redislib.Client
+	// Repositories and real-time stores of the configured backend, nil in test
+	// mode without in-memory storage
+	stores *stores
 	// POI service for WebSocket handler
 	poiService *services.POIService
+	// Facilitator map freezes, checked by POI changes and WebSocket routing
+	mapFreeze *services.MapFreezeService
+	// Raise-hand queues of maps, changed over WebSocket and read by facilitators
+	handRaise *services.HandRaiseService
+	// Facilitators moving participants to a POI, over WebSocket and REST
+	summon *services.SummonService
+	// Kicks and map bans, issued over WebSocket and checked on connect
+	moderation *services.ModerationService
+	// Maintenance mode, checked by the write middleware and WebSocket routing
+	maintenance *services.MaintenanceService
 	// Shared rate limiter for all handlers
 	rateLimiter services.RateLimiterInterface
 	// Auth service for middleware
 	authService *services.AuthService
+	// Tokens proving session ownership, issued by session routes and checked
+	// by session-scoped REST routes and WebSocket upgrades
+	sessionTokens *services.SessionTokens
+	// Abuse heuristics shared by WebSocket and moderation handlers
+	abuseGuard *services.AbuseGuard
+	// Token bucket limits for bursty WebSocket actions like avatar dragging
+	burstLimiter *services.TokenBucketLimiter
+	// Content-addressed file storage shared by all upload paths
+	fileStorage *storage.DedupFileStorage
+	// Avatar traffic analytics, fed by the WebSocket handler's session service
+	heatmapService *services.HeatmapService
+	// Periodic background jobs like analytics roll-ups
+	scheduler *scheduler.Scheduler
+	// Avatar spawn positions and placement, shared by session and map routes
+	spawnService *services.SpawnService
+	// Per-map settings checked on avatar moves, like personal space
+	mapSettings *services.MapSettingsService
+	// Gradual rollouts of risky features, consulted by handlers and WebSocket routing
+	rolloutService *services.RolloutService
+	// Per-map usage for hosted billing, metered by the WebSocket handler and uploads
+	usageService *services.UsageService
+	// Welcome checklist progress, completed by profile, avatar and POI changes
+	onboarding *services.OnboardingService
+	// RSVPs to scheduled POIs, reminded through the WebSocket handler
+	rsvpService *services.RSVPService
+	// Delivers POI and user events to all instances, nil without Redis
+	eventStream *redis.EventStream
+	// Metrics served to Prometheus on /metrics
+	metrics *prometheus.Registry
+	// Exports access and connection event logs, nil if no sink is configured
+	logExporter *logexport.Exporter
+	// How long each log category is kept before it is purged
+	logRetention logexport.Retention
+	// Reports product events to the configured analytics service
+	analytics *analytics.Tracker
 }
 
 func New(cfg *config.Config) *Server {
@@ -48,8 +118,19 @@ func New(cfg *config.Config) *Server {
 	var db *gorm.DB
 	var redisClient *redislib.Client
 	
-	// Only connect to database and Redis if not in test mode
-	if cfg.GinMode != "test" {
+	// Only connect to database and Redis if not in test mode and not keeping
+	// everything in memory or in a SQLite file
+	if cfg.Storage == storageSQLite {
+		log.Printf("🔗 Opening SQLite database: %s", cfg.SQLitePath)
+		
+		var err error
+		db, err = database.InitializeSQLite(cfg.SQLitePath)
+		if err != nil {
+			log.Fatalf("❌ Failed to initialize database: %v", err)
+		}
+		
+		log.Println("✅ Database opened and migrations completed")
+	} else if cfg.GinMode != "test" && cfg.Storage != storageMemory {
 		log.Printf("🔗 Attempting to connect to database: %s", cfg.DatabaseURL)
 		
 		// Setup database connection and run migrations
@@ -60,8 +141,14 @@ func New(cfg *config.Config) *Server {
 		}
 		
 		log.Println("✅ Database connection established and migrations completed")
-		
-		// Setup Redis connection
+	} else if cfg.Storage == storageMemory {
+		log.Println("⚠️ Running with in-memory storage, skipping database connection")
+	} else {
+		log.Println("⚠️ Running in test mode, skipping database connection")
+	}
+	
+	// Redis is only needed to share real-time events between instances
+	if cfg.GinMode != "test" && pubSubBackend(cfg) == pubSubRedis {
 		log.Printf("🔗 Attempting to connect to Redis: %s", cfg.RedisURL)
 		redisConfig, err := parseRedisURL(cfg.RedisURL)
 		if err != nil {
@@ -74,12 +161,30 @@ func New(cfg *config.Config) *Server {
 		}
 		
 		log.Println("✅ Redis connection established")
-	} else {
-		log.Println("⚠️ Running in test mode, skipping database and Redis connections")
+	} else if pubSubBackend(cfg) == pubSubMemory {
+		log.Println("⚠️ Delivering real-time events in process, skipping Redis connection")
 	}
 	
 	router := gin.Default()
 	
+	// Only derive client IP and scheme from X-Forwarded-* headers set by trusted proxies
+	trustedProxies, err := middleware.ParseTrustedProxies(cfg.TrustedProxies)
+	if err != nil {
+		log.Fatalf("❌ Invalid TRUSTED_PROXIES: %v", err)
+	}
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("❌ Failed to configure trusted proxies: %v", err)
+	}
+	router.Use(middleware.TrustedProxyHeaders(trustedProxies))
+	log.Printf("🔧 Trusted proxies: %v", cfg.TrustedProxies)
+	
+	// Serve /api/v1 by the /api routes, deprecating /api once a sunset is set
+	unversionedAPI, err := unversionedAPIDeprecation(cfg)
+	if err != nil {
+		log.Fatalf("❌ Invalid API_UNVERSIONED_SUNSET: %v", err)
+	}
+	router.Use(middleware.APIVersioning(unversionedAPI))
+	
 	// CORS middleware with explicit preflight handling
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{
@@ -88,40 +193,239 @@ func New(cfg *config.Config) *Server {
 			"https://breakout-globe.up.railway.app",                   // Railway production (new)
 		},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-User-ID"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-User-ID", middleware.SessionHeader},
+		ExposeHeaders:    []string{"Content-Length", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", services.RateLimitWarningHeader, "X-API-Version", "Deprecation", "Sunset", "Link"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour, // Cache preflight requests for 12 hours
 	}))
 	
-	// Initialize shared rate limiter
+	// Keep logs only as long as configured, for self-hosters' retention requirements
+	logRetention, err := logexport.ParseRetention(cfg.LogRetention)
+	if err != nil {
+		log.Fatalf("❌ Invalid LOG_RETENTION: %v", err)
+	}
+	
+	// Export structured access logs to the configured sink
+	logExporter, err := newLogExporter(cfg)
+	if err != nil {
+		log.Fatalf("❌ Failed to set up log export: %v", err)
+	}
+	if logExporter != nil {
+		router.Use(middleware.ExportAccessLog(logExporter))
+		log.Printf("✅ Exporting access and event logs to %s", cfg.LogExportSink)
+	}
+	
+	// Report product events, skipping users who opted out of tracking
+	analyticsSink, err := newAnalyticsSink(cfg)
+	if err != nil {
+		log.Fatalf("❌ Failed to set up analytics: %v", err)
+	}
+	tracker := analytics.NewTracker(analyticsSink)
+	if cfg.AnalyticsSink != "" {
+		log.Printf("✅ Sending product events to %s", cfg.AnalyticsSink)
+	}
+	
+	// Initialize shared rate limiter, which keeps its counts in memory with
+	// either storage backend
 	// TODO: Replace with Redis-based rate limiter in production
 	var rateLimiter services.RateLimiterInterface = &SimpleRateLimiter{}
 	
 	s := &Server{
 		config:      cfg,
 		router:      router,
+		unversionedAPI: unversionedAPI,
+		httpServer:  &http.Server{Handler: middleware.APIVersions(router)},
+		drainRequested: make(chan struct{}),
 		db:          db,
 		redis:       redisClient,
 		rateLimiter: rateLimiter,
+		abuseGuard:  services.NewAbuseGuard(services.GetDefaultAbuseRules()),
+		burstLimiter: newBurstLimiter(cfg),
+		sessionTokens: newSessionTokens(cfg),
+		scheduler:   scheduler.New(),
+		metrics:     prometheus.NewRegistry(),
+		logExporter: logExporter,
+		logRetention: logRetention,
+		analytics:   tracker,
+	}
+	
+	// Deliver POI and user events to every instance, including ones that were
+	// briefly disconnected from Redis
+	if redisClient != nil {
+		s.eventStream = redis.NewEventStream(redisClient, cfg.InstanceID)
+		s.scheduler.Register("event_stream_group_cleanup", time.Hour, func(ctx context.Context) error {
+			pruned, err := s.eventStream.PruneGroups(ctx, 24*time.Hour)
+			if pruned > 0 {
+				log.Printf("✅ Removed %d idle event stream consumer groups", pruned)
+			}
+			return err
+		})
+	}
+	
+	// Sessions, users, POIs and maps live in Postgres and Redis, in SQLite for a
+	// single instance, or in memory for development without either
+	stores, err := newStores(cfg, db, redisClient, s.eventStream)
+	if err != nil {
+		log.Fatalf("❌ Failed to set up storage: %v", err)
+	}
+	s.stores = stores
+	logStorage(cfg, stores)
+	if stores != nil {
+		tracker.SetConsentChecker(services.NewPreferenceService(stores.preferences))
+	}
+	
+	// Delete exported logs past their retention from sinks that store them
+	if logExporter != nil {
+		s.scheduler.Register("log_export_purge", 24*time.Hour, func(ctx context.Context) error {
+			purged, err := logExporter.Purge(ctx, logRetention)
+			if purged > 0 {
+				log.Printf("✅ Purged %d expired log files", purged)
+			}
+			return err
+		})
 	}
 	
+	// Reject writes during maintenance. The middleware must be added before any
+	// route is registered to apply to all of them.
+	s.setupMaintenance()
+	
 	s.setupRoutes()
 	
 	return s
 }
 
+// newLogExporter creates the exporter for the configured sink, or nil if log
+// export is disabled
+func newLogExporter(cfg *config.Config) (*logexport.Exporter, error) {
+	switch cfg.LogExportSink {
+	case "":
+		return nil, nil
+	case "stdout":
+		return logexport.NewExporter(logexport.NewWriterSink(os.Stdout)), nil
+	case "file":
+		sink, err := logexport.NewFileSink(cfg.LogExportPath)
+		if err != nil {
+			return nil, err
+		}
+		return logexport.NewExporter(sink), nil
+	case "http":
+		if cfg.LogExportURL == "" {
+			return nil, fmt.Errorf("LOG_EXPORT_URL is required for the http sink")
+		}
+		return logexport.NewExporter(logexport.NewHTTPSink(cfg.LogExportURL)), nil
+	default:
+		return nil, fmt.Errorf("unknown LOG_EXPORT_SINK %q, expected stdout, file or http", cfg.LogExportSink)
+	}
+}
+
+// newAnalyticsSink creates the sink for the configured analytics service, which
+// discards events if none is configured
+func newAnalyticsSink(cfg *config.Config) (analytics.Sink, error) {
+	switch cfg.AnalyticsSink {
+	case "":
+		return analytics.NopSink{}, nil
+	case "log":
+		return analytics.NewLogSink(slog.Default()), nil
+	case "posthog":
+		if cfg.AnalyticsAPIKey == "" {
+			return nil, fmt.Errorf("ANALYTICS_API_KEY is required for the posthog sink")
+		}
+		endpoint := cfg.AnalyticsURL
+		if endpoint == "" {
+			endpoint = analytics.DefaultPostHogURL
+		}
+		return analytics.NewPostHogSink(endpoint, cfg.AnalyticsAPIKey), nil
+	case "segment":
+		if cfg.AnalyticsAPIKey == "" {
+			return nil, fmt.Errorf("ANALYTICS_API_KEY is required for the segment sink")
+		}
+		endpoint := cfg.AnalyticsURL
+		if endpoint == "" {
+			endpoint = analytics.DefaultSegmentURL
+		}
+		return analytics.NewSegmentSink(endpoint, cfg.AnalyticsAPIKey), nil
+	default:
+		return nil, fmt.Errorf("unknown ANALYTICS_SINK %q, expected log, posthog or segment", cfg.AnalyticsSink)
+	}
+}
+
+// newSessionTokens signs session tokens with the JWT secret, so instances
+// sharing it accept each other's. Without one, tokens are only valid on this
+// instance.
+func newSessionTokens(cfg *config.Config) *services.SessionTokens {
+	if cfg.JWTSecret == "" {
+		log.Println("⚠️ JWT_SECRET not set, session tokens are only valid on this instance")
+		return services.NewSessionTokens(nil)
+	}
+	return services.NewSessionTokens([]byte("session:" + cfg.JWTSecret))
+}
+
+// newBurstLimiter creates the token bucket limiter with the per-map avatar
+// movement bursts from the config
+func newBurstLimiter(cfg *config.Config) *services.TokenBucketLimiter {
+	burstLimiter := services.NewTokenBucketLimiter(services.GetDefaultBurstLimits())
+	
+	for _, value := range cfg.AvatarBurstLimits {
+		mapID, limit, err := services.ParseMapBurstLimit(value)
+		if err != nil {
+			log.Printf("⚠️ Ignoring avatar burst limit: %v", err)
+			continue
+		}
+		burstLimiter.SetMapLimit(mapID, services.ActionUpdateAvatar, limit)
+		log.Printf("✅ Avatar burst limit for map %s: %d moves, %.1f/s sustained", mapID, limit.Burst, limit.SustainedRate)
+	}
+	
+	return burstLimiter
+}
+
+// newSpawnService creates the spawn service, locating clients with MaxMind if configured
+func newSpawnService(cfg *config.Config, maps services.MapStore) *services.SpawnService {
+	spawnService := services.NewSpawnService(maps)
+	
+	if cfg.MaxMindAccountID == "" || cfg.MaxMindLicenseKey == "" {
+		log.Println("⚠️ MaxMind not configured, avatars without a position spawn at the map default")
+		return spawnService
+	}
+	
+	spawnService.SetGeoIPProvider(geoip.NewMaxMindProvider(cfg.MaxMindAccountID, cfg.MaxMindLicenseKey, cfg.MaxMindHost))
+	log.Printf("✅ GeoIP spawn positions enabled via %s", cfg.MaxMindHost)
+	return spawnService
+}
+
 func (s *Server) setupRoutes() {
 	log.Println("🔧 Setting up routes...")
 	
-	// Health check
+	// Health check, failing while draining so load balancers stop routing here
 	s.router.GET("/health", func(c *gin.Context) {
+		if s.wsHandler != nil && s.wsHandler.IsDraining() {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"status": "draining",
+				"service": "breakoutglobe-api",
+			})
+			return
+		}
 		c.JSON(http.StatusOK, gin.H{
 			"status": "ok",
 			"service": "breakoutglobe-api",
 		})
 	})
 	
+	// Rolling restarts take an instance down, so they always need the ops token
+	if s.config.MetricsToken != "" {
+		drainHandler := handlers.NewDrainHandler(s)
+		drainHandler.RegisterRoutes(s.router, middleware.RequireStaticToken(s.config.MetricsToken))
+	} else {
+		log.Println("⚠️ METRICS_TOKEN not set, drain endpoint not available (use SIGUSR1)")
+	}
+	
+	// Prometheus metrics, which name maps and load, so they need the ops token too
+	if s.config.MetricsToken != "" {
+		s.router.GET("/metrics", middleware.RequireStaticToken(s.config.MetricsToken),
+			gin.WrapH(promhttp.HandlerFor(s.metrics, promhttp.HandlerOpts{})))
+	} else {
+		log.Println("⚠️ METRICS_TOKEN not set, /metrics not available")
+	}
+	
 	// API status
 	api := s.router.Group("/api")
 	{
@@ -131,15 +435,46 @@ func (s *Server) setupRoutes() {
 			})
 		})
 		
+		// The welcome checklist is completed from several services and returned
+		// with the current user, so its progress is shared
+		if s.stores != nil {
+			s.onboarding = services.NewOnboardingService(s.stores.onboarding)
+			s.onboarding.SetPublisher(s.stores.newPubSub(), s.stores.sessions)
+		}
+		
 		// Setup authentication routes
 		s.setupAuthRoutes(api)
 		
+		// Map settings are also read by the session list and by the WebSocket
+		// handler on every avatar move
+		if s.stores != nil {
+			s.mapSettings = services.NewMapSettingsService(s.stores.maps)
+		}
+		
 		// Setup session routes with proper handlers
 		s.setupSessionRoutes(api)
 		
 		// Setup POI routes with proper handlers
 		s.setupPOIRoutes(api)
 		
+		// Setup map spawn point configuration
+		s.setupMapRoutes()
+		
+		// Setup map heatmap analytics before the WebSocket handler records positions
+		s.setupHeatmapRoutes()
+		
+		// Setup usage metering before the WebSocket handler sends messages
+		s.setupUsageRoutes()
+		
+		// Setup map activity digest subscriptions and the digest job
+		s.setupDigestRoutes()
+		
+		// Setup email invitations with pre-created guest profiles
+		s.setupInvitationRoutes()
+		
+		// Setup feature rollouts before the WebSocket handler routes gated messages
+		s.setupRolloutRoutes()
+		
 		// User profile endpoints with proper handlers
 		log.Println("About to call setupUserRoutes")
 		s.setupUserRoutes(api)
@@ -148,6 +483,18 @@ func (s *Server) setupRoutes() {
 		// Setup feedback routes
 		s.setupFeedbackRoutes()
 		
+		// Setup admin moderation routes
+		s.setupModerationRoutes()
+		
+		// Setup upload content lookup routes
+		s.setupUploadRoutes()
+		
+		// Public runtime configuration for the frontend, after rollouts
+		s.setupConfigRoutes()
+		
+		// Setup maintenance mode status and management
+		s.setupMaintenanceRoutes()
+		
 		// Serve uploaded avatar files
 		api.GET("/users/avatar/:filename", s.serveAvatar)
 		
@@ -159,20 +506,28 @@ func (s *Server) setupRoutes() {
 }
 
 func (s *Server) setupSessionRoutes(api *gin.RouterGroup) {
-	log.Printf("🔧 setupSessionRoutes called, db is nil: %v, redis is nil: %v", s.db == nil, s.redis == nil)
+	log.Printf("🔧 setupSessionRoutes called, stores is nil: %v", s.stores == nil)
 	
-	// Only setup session routes if database and Redis are available (not in test mode)
-	if s.db != nil && s.redis != nil {
-		log.Println("📊 Database and Redis available, setting up session routes with proper handlers")
+	// Only setup session routes if storage is available (not in test mode)
+	if s.stores != nil {
+		log.Println("📊 Storage available, setting up session routes with proper handlers")
 		
 		// Setup dependencies
-		sessionRepo := repository.NewSessionRepository(s.db)
-		sessionPresence := redis.NewSessionPresence(s.redis)
-		pubsub := redis.NewPubSub(s.redis) // Add the missing pubsub parameter
-		sessionService := services.NewSessionService(sessionRepo, sessionPresence, pubsub)
+		sessionService := services.NewSessionService(s.stores.sessions, s.stores.presence, s.stores.newPubSub())
+		
+		// Place new avatars in the map's spawn areas without stacking them
+		s.spawnService = newSpawnService(s.config, s.stores.maps)
+		sessionService.SetAvatarPlacer(s.spawnService)
+		sessionService.SetAnalytics(s.analytics)
+		sessionService.SetOnboarding(s.onboarding)
 		
 		// Create session handler with shared rate limiter
 		sessionHandler := handlers.NewSessionHandler(sessionService, s.rateLimiter)
+		sessionHandler.SetSpawnLocator(s.spawnService)
+		sessionHandler.SetSessionTokens(s.sessionTokens)
+		if s.mapSettings != nil {
+			sessionHandler.SetPositionPrivacyProvider(s.mapSettings)
+		}
 		
 		// Create auth middleware if auth service is available
 		var authMiddleware gin.HandlerFunc
@@ -190,24 +545,24 @@ func (s *Server) setupSessionRoutes(api *gin.RouterGroup) {
 		
 		log.Println("✅ Session routes setup complete with proper handlers including heartbeat")
 	} else {
-		log.Println("⚠️ Database or Redis not available, session endpoints not available in test mode")
+		log.Println("⚠️ Storage not available, session endpoints not available in test mode")
 		// No fallback handlers - proper service-backed handlers only
 	}
 }
 
 func (s *Server) setupAuthRoutes(api *gin.RouterGroup) {
-	log.Printf("🔧 setupAuthRoutes called, db is nil: %v", s.db == nil)
+	log.Printf("🔧 setupAuthRoutes called, stores is nil: %v", s.stores == nil)
 	
-	// Only setup auth routes if database is available (not in test mode)
-	if s.db != nil {
-		log.Println("📊 Database available, setting up authentication routes")
+	// Only setup auth routes if storage is available (not in test mode)
+	if s.stores != nil {
+		log.Println("📊 Storage available, setting up authentication routes")
 		
 		// Setup dependencies
-		userRepo := repository.NewUserRepository(s.db)
+		userRepo := s.stores.users
 		
 		// Initialize storage configuration
 		storageConfig := storage.GetStorageConfig()
-		fileStorage := storage.NewFileStorage(storageConfig)
+		fileStorage := s.getFileStorage(storageConfig)
 		
 		// Create user service
 		userService := services.NewUserService(userRepo, fileStorage)
@@ -223,33 +578,55 @@ func (s *Server) setupAuthRoutes(api *gin.RouterGroup) {
 		// Link auth service to user service for password operations
 		userService.SetAuthService(s.authService)
 		
+		// Preferences are synced across devices and returned with the current user
+		preferenceService := services.NewPreferenceService(s.stores.preferences)
+		
+		// Track sign-ins so users can revoke them and hear about unfamiliar ones
+		authSessionService := services.NewAuthSessionService(s.stores.authSessions, newMailer(s.config), jwtExpiry)
+		if s.config.MaxMindAccountID != "" && s.config.MaxMindLicenseKey != "" {
+			authSessionService.SetGeoIPProvider(geoip.NewMaxMindProvider(s.config.MaxMindAccountID, s.config.MaxMindLicenseKey, s.config.MaxMindHost))
+		}
+		s.authService.SetSessionValidator(authSessionService)
+		s.scheduler.Register("auth_session_cleanup", 24*time.Hour, func(ctx context.Context) error {
+			deleted, err := authSessionService.DeleteExpiredSessions(ctx)
+			if deleted > 0 {
+				log.Printf("✅ Deleted %d expired sign-ins", deleted)
+			}
+			return err
+		})
+		
 		// Create auth handler
 		authHandler := handlers.NewAuthHandler(s.authService, userService, s.rateLimiter)
+		authHandler.SetPreferenceService(preferenceService)
+		authHandler.SetOnboardingService(s.onboarding)
+		authHandler.SetSessionService(authSessionService)
 		
 		// Register auth routes
-		auth := api.Group("/auth")
-		{
-			auth.POST("/signup", authHandler.Signup)
-			auth.POST("/login", authHandler.Login)
-			auth.POST("/logout", authHandler.Logout)
-			// Note: /auth/me will be added with middleware in next step
-		}
+		authHandler.RegisterRoutes(s.router, middleware.RequireAuth(s.authService))
+		
+		// Sign-ins of the current user
+		authSessionHandler := handlers.NewAuthSessionHandler(authSessionService)
+		authSessionHandler.RegisterRoutes(s.router, middleware.RequireAuth(s.authService))
+		
+		// Guests keep preferences too, identified like on other user endpoints
+		preferenceHandler := handlers.NewPreferenceHandler(preferenceService)
+		preferenceHandler.RegisterRoutes(s.router, middleware.OptionalAuth(s.authService))
 		
 		log.Println("✅ Authentication routes setup complete")
 	} else {
-		log.Println("⚠️ Database not available, auth endpoints not available in test mode")
+		log.Println("⚠️ Storage not available, auth endpoints not available in test mode")
 	}
 }
 
 func (s *Server) setupUserRoutes(api *gin.RouterGroup) {
-	log.Printf("🔧 setupUserRoutes called, db is nil: %v", s.db == nil)
+	log.Printf("🔧 setupUserRoutes called, stores is nil: %v", s.stores == nil)
 	
-	// Only setup user routes if database is available (not in test mode)
-	if s.db != nil {
-		log.Println("📊 Database available, setting up user routes and WebSocket handler")
+	// Only setup user routes if storage is available (not in test mode)
+	if s.stores != nil {
+		log.Println("📊 Storage available, setting up user routes and WebSocket handler")
 		
 		// Setup dependencies
-		userRepo := repository.NewUserRepository(s.db)
+		userRepo := s.stores.users
 		
 		// Initialize storage configuration
 		storageConfig := storage.GetStorageConfig()
@@ -269,9 +646,10 @@ func (s *Server) setupUserRoutes(api *gin.RouterGroup) {
 			log.Println("✅ Storage path is writable - volume mount verified")
 		}
 		
-		fileStorage := storage.NewFileStorage(storageConfig)
+		fileStorage := s.getFileStorage(storageConfig)
 		
 		userService := services.NewUserService(userRepo, fileStorage)
+		userService.SetOnboarding(s.onboarding)
 		
 		// Use the shared rate limiter instance
 		userHandler := handlers.NewUserHandler(userService, s.rateLimiter)
@@ -290,34 +668,39 @@ func (s *Server) setupUserRoutes(api *gin.RouterGroup) {
 			userHandler.RegisterRoutes(s.router)
 		}
 		
+		// Promoting and demoting users is reserved for superadmins
+		if s.authService != nil {
+			userHandler.RegisterAdminRoutes(s.router, middleware.RequireAuth(s.authService), middleware.RequireSuperAdmin())
+		}
+		
 		// Setup WebSocket handler for multi-user functionality
 		s.setupWebSocketHandler(userService, s.rateLimiter, s.poiService)
 	} else {
-		log.Println("⚠️ Database not available, skipping user routes and WebSocket handler setup")
+		log.Println("⚠️ Storage not available, skipping user routes and WebSocket handler setup")
 	}
 }
 
 func (s *Server) setupPOIRoutes(api *gin.RouterGroup) {
-	log.Printf("🔧 setupPOIRoutes called, db is nil: %v, redis is nil: %v", s.db == nil, s.redis == nil)
+	log.Printf("🔧 setupPOIRoutes called, stores is nil: %v", s.stores == nil)
 	
-	// Only setup POI routes if database and Redis are available (not in test mode)
-	if s.db != nil && s.redis != nil {
-		log.Println("📊 Database and Redis available, setting up POI routes with proper handlers")
+	// Only setup POI routes if storage is available (not in test mode)
+	if s.stores != nil {
+		log.Println("📊 Storage available, setting up POI routes with proper handlers")
 		
 		// Setup dependencies
-		poiRepo := repository.NewPOIRepository(s.db)
-		poiParticipants := redis.NewPOIParticipants(s.redis)
-		pubsub := redis.NewPubSub(s.redis)
+		poiRepo := s.stores.pois
+		poiParticipants := s.stores.participants
+		pubsub := s.stores.newPubSub()
 		
 		// Create user service for participant name resolution
-		userRepo := repository.NewUserRepository(s.db)
+		userRepo := s.stores.users
 		
 		// Initialize storage configuration
 		storageConfig := storage.GetStorageConfig()
 		if err := storage.EnsureUploadDirectories(storageConfig); err != nil {
 			log.Printf("❌ Warning: Failed to create upload directories: %v", err)
 		}
-		fileStorage := storage.NewFileStorage(storageConfig)
+		fileStorage := s.getFileStorage(storageConfig)
 		
 		userService := services.NewUserService(userRepo, fileStorage)
 		
@@ -327,26 +710,88 @@ func (s *Server) setupPOIRoutes(api *gin.RouterGroup) {
 		// Create POI service with image processor and user service
 		s.poiService = services.NewPOIServiceWithImageProcessor(poiRepo, poiParticipants, pubsub, imageProcessor, userService)
 		
+		// Number roster changes so clients can spot missed participant deltas
+		s.poiService.SetRosterSequencer(poiParticipants)
+		// Show how long participants have been in a POI and report dwell times
+		s.poiService.SetParticipantJoinTimes(poiParticipants)
+		// Count recent joins so clients can badge trending POIs
+		s.poiService.SetJoinHistory(poiParticipants)
+		s.poiService.SetAnalytics(s.analytics)
+		s.poiService.SetOnboarding(s.onboarding)
+		
+		// POIs can't be changed while a facilitator froze their map
+		s.mapFreeze = services.NewMapFreezeService(pubsub)
+		s.poiService.SetMapFreeze(s.mapFreeze)
+		
+		// Maps can keep avatar URLs out of participant payloads
+		if s.mapSettings != nil {
+			s.poiService.SetProfilePrivacy(s.mapSettings)
+		}
+		
+		// Participants raise their hand over WebSocket, queued for all instances
+		s.handRaise = services.NewHandRaiseService(s.stores.hands, pubsub)
+		
 		// Create POI handler with user service for participant names
 		poiHandler := handlers.NewPOIHandler(s.poiService, userService, s.rateLimiter)
+		poiHandler.SetPopularity(s.poiService)
+		
+		// RSVPs to scheduled POIs reserve seats and are counted in POI responses.
+		// They're only kept in the database.
+		var rsvpHandler *handlers.RSVPHandler
+		if s.db != nil {
+			s.rsvpService = services.NewRSVPService(repository.NewRSVPRepository(s.db), poiRepo)
+			s.poiService.SetSeatReservations(s.rsvpService)
+			s.poiService.SetRSVPMover(s.rsvpService)
+			// Reminders reach users connected to any instance
+			s.rsvpService.SetPublisher(pubsub)
+			poiHandler.SetRSVPCounter(s.rsvpService)
+			rsvpHandler = handlers.NewRSVPHandler(s.rsvpService)
+			s.scheduler.Register("poi_reminders", time.Minute, func(ctx context.Context) error {
+				sent, err := s.rsvpService.SendDueReminders(ctx)
+				if sent > 0 {
+					log.Printf("✅ Sent %d POI reminders", sent)
+				}
+				return err
+			})
+		}
 		
 		// Create auth middleware if auth service is available
 		var authMiddleware gin.HandlerFunc
+		var jwtValidator middleware.AuthService
 		if s.authService != nil {
 			authMiddleware = middleware.OptionalAuth(s.authService)
+			jwtValidator = s.authService
 			log.Println("✅ POI routes will use optional authentication for write operations")
 		}
 		
+		// Joining and leaving act for the user behind the JWT or session header,
+		// never for a user ID taken from the request body
+		sessionService := services.NewSessionService(s.stores.sessions, s.stores.presence, pubsub)
+		poiHandler.SetIdentityMiddleware(middleware.RequireIdentity(jwtValidator, sessionService, s.sessionTokens))
+		
+		// Facilitators move participants to a POI for structured breakouts
+		s.summon = services.NewSummonService(sessionService, s.poiService, pubsub)
+		
+		// Facilitators kick disruptive users, optionally banning them for a while
+		s.moderation = services.NewModerationService(s.stores.bans, sessionService, pubsub)
+		
 		// Register POI routes with optional auth middleware
 		if authMiddleware != nil {
 			poiHandler.RegisterRoutes(s.router, authMiddleware)
 		} else {
 			poiHandler.RegisterRoutes(s.router)
 		}
+		if rsvpHandler != nil {
+			if authMiddleware != nil {
+				rsvpHandler.RegisterRoutes(s.router, authMiddleware)
+			} else {
+				rsvpHandler.RegisterRoutes(s.router)
+			}
+		}
 		
-		log.Println("✅ POI routes setup complete with database-backed handlers")
+		log.Println("✅ POI routes setup complete with storage-backed handlers")
 	} else {
-		log.Println("⚠️ Database or Redis not available, POI endpoints not available in test mode")
+		log.Println("⚠️ Storage not available, POI endpoints not available in test mode")
 		// No fallback handlers - proper service-backed handlers only
 	}
 }
@@ -355,31 +800,295 @@ func (s *Server) setupWebSocketHandler(userService *services.UserService, rateLi
 	log.Println("🔧 Setting up WebSocket handler...")
 	
 	// Use the proper session service with database validation
-	sessionRepo := repository.NewSessionRepository(s.db)
-	sessionPresence := redis.NewSessionPresence(s.redis)
-	pubsub := redis.NewPubSub(s.redis)
-	sessionService := services.NewSessionService(sessionRepo, sessionPresence, pubsub)
+	sessionRepo := s.stores.sessions
+	pubsub := s.stores.newPubSub()
+	sessionService := services.NewSessionService(sessionRepo, s.stores.presence, pubsub)
 	
 	// Create WebSocket handler
 	wsHandler := websocket.NewHandler(sessionService, rateLimiter, userService, poiService)
+	wsHandler.SetSessionTokens(s.sessionTokens)
+	
+	// Moving an avatar completes a step of the welcome checklist
+	sessionService.SetOnboarding(s.onboarding)
+	
+	// Meter the messages sent and calls held on each map
+	if s.usageService != nil {
+		wsHandler.SetUsageRecorder(s.usageService)
+	}
+	
+	// Count avatar positions for the map heatmap
+	if s.heatmapService != nil {
+		sessionService.SetPositionRecorder(s.heatmapService)
+	}
+	
+	// Set up PubSub integration
+	wsHandler.SetPubSub(pubsub)
+	log.Println("✅ WebSocket handler PubSub integration enabled")
+	
+	// Let other clients on the user's maps drop cached names and avatars
+	userService.SetProfilePublisher(pubsub, sessionRepo)
+	
+	// Let avatar dragging burst above the per-minute limit
+	wsHandler.SetBurstLimiter(s.burstLimiter)
+	wsHandler.SetMovementDeadZone(s.config.AvatarDeadZoneMeters)
+	wsHandler.SetPositionBatchInterval(s.config.AvatarBatchInterval)
+	
+	// Cancel calls that ring without an answer
+	wsHandler.SetCallRingTimeout(s.config.CallRingTimeout)
+	
+	// Close connections of clients that stopped sending heartbeats
+	wsHandler.SetHeartbeatTimeout(s.config.WebSocketHeartbeatTimeout)
+	
+	// Show users without activity as away
+	wsHandler.SetIdleTimeout(s.config.WebSocketIdleTimeout)
+	
+	// Coalesce movement and drop reactions while the hub is overloaded
+	wsHandler.SetLoadShedding(websocket.LoadShedding{
+		QueueThreshold:   s.config.LoadShedQueueThreshold,
+		CPUThreshold:     s.config.LoadShedCPUThreshold,
+		MovementInterval: s.config.LoadShedMovementInterval,
+	})
+	
+	// Compress large messages like initial_users for browsers that support it
+	wsHandler.SetCompressionThreshold(s.config.WebSocketCompressionThreshold)
+	
+	// Write bursts of broadcasts in fewer frames for clients that opt in
+	wsHandler.SetFrameBatching(s.config.WebSocketFrameBatchSize, s.config.WebSocketFrameBatchInterval)
+	
+	// Send the roster of huge maps in pages, the users in view first
+	wsHandler.SetInitialUsersPageSize(s.config.WebSocketInitialUsersPageSize)
+	
+	// Keep avatars out of each other's personal space on maps that enable it,
+	// apply each map's send buffer size and drop policy to its clients, show
+	// regular participants coarse positions on maps with position privacy, and
+	// leave out the profile fields maps hide
+	if s.mapSettings != nil {
+		wsHandler.SetPersonalSpaceProvider(s.mapSettings)
+		wsHandler.SetSlowConsumerPolicyProvider(s.mapSettings)
+		wsHandler.SetPositionPrivacyProvider(s.mapSettings)
+		wsHandler.SetProfilePrivacyProvider(s.mapSettings)
+		wsHandler.SetMessageAuditPolicyProvider(s.mapSettings)
+	}
+	
+	// Only report sessions with a live heartbeat presence key in initial users
+	wsHandler.SetPresenceChecker(sessionService)
+	
+	// Enable abuse heuristics with notifications to restricted users and facilitators
+	wsHandler.SetAbuseGuard(s.abuseGuard)
+	s.abuseGuard.SetNotifier(wsHandler.NotifyRestriction)
 	
-	// Set up PubSub integration if Redis is available
+	// Route risky new message types only for users in the feature's rollout
+	if s.rolloutService != nil {
+		wsHandler.SetFeatureRollout(s.rolloutService)
+	}
+	
+	// Periodically send full POI rosters so clients recover from missed deltas
+	if poiService != nil {
+		wsHandler.SetPOIRosterProvider(poiService)
+		s.scheduler.Register("poi_roster_sync", 30*time.Second, wsHandler.BroadcastPOIRosters)
+		
+		// Drop participants whose sessions stopped sending heartbeats, such as
+		// those left behind by a crashed instance
+		wsHandler.SetPOIParticipantReconciler(poiService, sessionService)
+		s.scheduler.Register("poi_participant_reconcile", time.Minute, wsHandler.ReconcilePOIParticipants)
+	}
+	
+	// Periodically send map presence so clients recover from missed joins and leaves
+	s.scheduler.Register("map_presence", 30*time.Second, wsHandler.BroadcastMapPresence)
+	
+	// Disconnect clients whose session ended while they were connected
+	if s.authService != nil {
+		wsHandler.SetTokenValidator(s.authService)
+	}
+	authRefreshInterval := s.config.WebSocketAuthRefreshInterval
+	if authRefreshInterval <= 0 {
+		authRefreshInterval = websocket.DefaultAuthRefreshInterval
+	}
+	s.scheduler.Register("websocket_auth_refresh", authRefreshInterval, wsHandler.RefreshAuth)
+	
+	// Let clients on flaky networks reconnect without a session lookup; the auth
+	// refresh disconnects them if their session ended meanwhile
+	if s.config.WebSocketReconnectTokenTTL > 0 && s.config.JWTSecret != "" {
+		wsHandler.SetReconnectTokens([]byte("reconnect:"+s.config.JWTSecret), s.config.WebSocketReconnectTokenTTL)
+		// Kicks and expired sessions revoke tokens on every instance
+		if s.stores != nil && s.stores.reconnectRevocations != nil {
+			wsHandler.SetReconnectRevocations(s.stores.reconnectRevocations)
+		}
+	}
+	
+	// Let facilitators freeze avatars and POI participation on their map
+	if s.mapFreeze != nil {
+		wsHandler.SetMapFreeze(s.mapFreeze)
+	}
+	
+	// Let participants raise their hand and facilitators call on them in order
+	if s.handRaise != nil {
+		wsHandler.SetHandQueue(s.handRaise)
+	}
+	
+	// Let facilitators move everyone or selected participants to a POI
+	if s.summon != nil {
+		wsHandler.SetSummon(s.summon)
+	}
+	
+	// Let facilitators kick users and keep banned users from reconnecting
+	if s.moderation != nil {
+		wsHandler.SetModeration(s.moderation)
+	}
+	
+	// Show the maintenance banner and reject stored changes during maintenance
+	wsHandler.SetMaintenance(s.maintenance)
+	
+	// Measure broadcast latency per map and message type
+	wsHandler.SetMetrics(s.metrics)
+	
+	// Ask clients older than the minimum version to reload
+	wsHandler.SetMinClientVersion(s.config.MinClientVersion)
+	
+	// Report started calls to product analytics
+	wsHandler.SetAnalytics(s.analytics)
+	
+	// Export connects, disconnects and the messages sampled by map audit
+	// policies along with the access logs
+	if s.logExporter != nil {
+		wsHandler.SetEventExporter(s.logExporter)
+	}
+	
+	// Record abnormal closes so dropped connections can be diagnosed; keep them
+	// for the connection_errors retention
+	var connectionErrors *repository.ConnectionErrorRepository
+	if s.db != nil {
+		connectionErrors = repository.NewConnectionErrorRepository(s.db)
+		wsHandler.SetConnectionErrorRecorder(connectionErrors)
+		s.scheduler.Register("connection_error_cleanup", 24*time.Hour, func(ctx context.Context) error {
+			deleted, err := connectionErrors.DeleteBefore(ctx, s.logRetention.Cutoff(logexport.CategoryConnectionErrors, time.Now()))
+			if deleted > 0 {
+				log.Printf("✅ Deleted %d old connection errors", deleted)
+			}
+			return err
+		})
+	}
+	
+	// Purge guest profiles nobody relies on once guest retention passed, and
+	// warn guests who connect shortly before
+	if s.db != nil && s.config.GuestRetentionDays > 0 {
+		guestRetention := services.NewGuestRetention(repository.NewGuestRetentionRepository(s.db),
+			s.getFileStorage(storage.GetStorageConfig()), s.config.GuestRetentionDays, s.config.GuestExpiryWarningDays)
+		wsHandler.SetGuestExpiryWarner(guestRetention)
+		s.scheduler.Register("guest_retention", 24*time.Hour, func(ctx context.Context) error {
+			purged, err := guestRetention.Run(ctx)
+			if purged > 0 {
+				log.Printf("✅ Purged %d expired guests", purged)
+			}
+			return err
+		})
+	}
+
+	// Journal map broadcasts so clients that missed some can replay them instead
+	// of reloading the whole map. Each instance broadcasts every event to its own
+	// clients, so each keeps its own journal.
+	var journal *redis.EventJournal
 	if s.redis != nil {
-		pubsub := redis.NewPubSub(s.redis)
-		wsHandler.SetPubSub(pubsub)
-		log.Println("✅ WebSocket handler PubSub integration enabled")
-	} else {
-		log.Println("⚠️ Redis not available, WebSocket handler will not receive real-time POI events")
+		journal = redis.NewEventJournal(s.redis, s.config.InstanceID, eventJournalLength)
+		wsHandler.SetEventJournal(journal)
 	}
 	
-	// Register the WebSocket handler
-	s.router.GET("/ws", wsHandler.HandleWebSocket)
+	// Register the WebSocket handler behind the session auth REST routes use. A
+	// JWT sent along gives the connection the user's role right away.
+	var jwtValidator middleware.AuthService
+	if s.authService != nil {
+		jwtValidator = s.authService
+	}
+	wsHandler.RegisterRoutes(s.router, jwtValidator)
+	s.wsHandler = wsHandler
+	
+	// List live connections with their client versions for admins
+	if s.authService != nil {
+		connectionHandler := handlers.NewConnectionHandler(wsHandler)
+		if connectionErrors != nil {
+			connectionHandler.SetConnectionErrorLister(connectionErrors)
+		}
+		if journal != nil {
+			connectionHandler.SetEventJournalReader(journal)
+		}
+		connectionHandler.RegisterRoutes(s.router, middleware.RequireAuth(s.authService), middleware.RequireAdmin())
+	}
+	
+	// Hub health for operators, protected like /metrics, or for admins without an ops token
+	statsHandler := handlers.NewWebSocketStatsHandler(wsHandler)
+	if s.config.MetricsToken != "" {
+		statsHandler.RegisterRoutes(s.router, middleware.RequireStaticToken(s.config.MetricsToken))
+	} else if s.authService != nil {
+		statsHandler.RegisterRoutes(s.router, middleware.RequireAuth(s.authService), middleware.RequireAdmin())
+	} else {
+		log.Println("⚠️ METRICS_TOKEN not set and auth not available, WebSocket stats endpoint not available")
+	}
 	
 	log.Println("✅ WebSocket handler setup complete - using proper multi-user handler")
 }
 
+// Start serves HTTP on addr until Shutdown is called
 func (s *Server) Start(addr string) error {
-	return s.router.Run(addr)
+	s.scheduler.Start(context.Background())
+	defer s.scheduler.Stop()
+	if s.logExporter != nil {
+		defer s.logExporter.Close()
+	}
+	defer s.analytics.Close()
+	
+	s.httpServer.Addr = addr
+	if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// RequestDrain asks the instance to drain for a rolling restart. It returns
+// false if a drain was already requested.
+func (s *Server) RequestDrain() bool {
+	requested := false
+	s.drainOnce.Do(func() {
+		close(s.drainRequested)
+		requested = true
+	})
+	return requested
+}
+
+// DrainRequested returns a channel that is closed once a drain was requested
+func (s *Server) DrainRequested() <-chan struct{} {
+	return s.drainRequested
+}
+
+// Drain stops accepting WebSocket connections, which fails the health check,
+// and hands the connected clients over to other instances spread over the
+// drain window. It returns once no clients are left or ctx ends.
+func (s *Server) Drain(ctx context.Context) error {
+	if s.wsHandler == nil {
+		return nil
+	}
+	return s.wsHandler.DrainGradually(ctx, s.config.DrainWindow)
+}
+
+// Shutdown drains WebSocket clients, which are told to reconnect and get their
+// queued messages before being closed, then stops accepting requests and waits
+// for open ones until ctx ends. Start returns once Shutdown is called.
+func (s *Server) Shutdown(ctx context.Context) error {
+	if s.wsHandler != nil {
+		if err := s.wsHandler.Shutdown(ctx); err != nil {
+			log.Printf("⚠️ Closed WebSocket connections before they drained: %v", err)
+		} else {
+			log.Println("✅ WebSocket connections drained")
+		}
+	}
+	
+	// Keep the usage metered since the last roll-up
+	if s.usageService != nil {
+		if _, err := s.usageService.Flush(ctx); err != nil {
+			log.Printf("⚠️ Failed to roll up usage on shutdown: %v", err)
+		}
+	}
+	
+	return s.httpServer.Shutdown(ctx)
 }
 
 // SimpleRateLimiter is a simple in-memory rate limiter for testing
@@ -389,27 +1098,45 @@ type SimpleRateLimiter struct {
 }
 
 func (r *SimpleRateLimiter) IsAllowed(ctx context.Context, userID string, action services.ActionType) (bool, error) {
-	allowed, _ := r.checkLimit(userID, action, false)
+	allowed, _, _ := r.checkLimit(userID, action, false)
 	return allowed, nil
 }
 
 func (r *SimpleRateLimiter) CheckRateLimit(ctx context.Context, userID string, action services.ActionType) error {
-	allowed, err := r.checkLimit(userID, action, true)
-	if err != nil {
-		return err
-	}
+	_, err := r.CheckRateLimitWithWarning(ctx, userID, action)
+	return err
+}
+
+// CheckRateLimitWithWarning checks the rate limit and returns a warning once the
+// user used most of the limit
+func (r *SimpleRateLimiter) CheckRateLimitWithWarning(ctx context.Context, userID string, action services.ActionType) (*services.RateLimitWarning, error) {
+	allowed, used, limit := r.checkLimit(userID, action, true)
 	if !allowed {
-		return &services.RateLimitError{
+		return nil, &services.RateLimitError{
 			UserID:     userID,
 			Action:     action,
 			Limit:      100,
 			RetryAfter: 3600, // 1 hour in seconds
 		}
 	}
-	return nil
+	
+	if !services.IsNearRateLimit(used, limit, services.DefaultRateLimitWarningThreshold) {
+		return nil, nil
+	}
+	
+	resetTime, _ := r.GetWindowResetTime(ctx, userID, action)
+	return &services.RateLimitWarning{
+		UserID:    userID,
+		Action:    action,
+		Limit:     limit,
+		Remaining: limit - used,
+		ResetTime: resetTime,
+	}, nil
 }
 
-func (r *SimpleRateLimiter) checkLimit(userID string, action services.ActionType, addRequest bool) (bool, error) {
+// checkLimit reports whether the request is allowed, along with the requests used
+// in the window and the limit for the action
+func (r *SimpleRateLimiter) checkLimit(userID string, action services.ActionType, addRequest bool) (bool, int, int) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	
@@ -437,6 +1164,15 @@ func (r *SimpleRateLimiter) checkLimit(userID string, action services.ActionType
 	case services.ActionUpdateProfile:
 		window = 1 * time.Minute
 		limit = 5 // 5 profile updates per minute
+	case services.ActionWSPOIJoin, services.ActionWSPOILeave, services.ActionWSCall, services.ActionWSReaction, services.ActionWSHand:
+		window = 1 * time.Minute
+		limit = 30 // 30 POI joins, leaves, call actions, reactions or hand raises per minute
+	case services.ActionWSSpeaking:
+		window = 1 * time.Minute
+		limit = 120 // 120 speaking state changes per minute
+	case services.ActionWSSignal:
+		window = 1 * time.Minute
+		limit = 600 // 600 WebRTC offers, answers and ICE candidates per minute
 	default:
 		window = 1 * time.Hour
 		limit = 100 // Default: 100 requests per hour
@@ -452,7 +1188,7 @@ func (r *SimpleRateLimiter) checkLimit(userID string, action services.ActionType
 	
 	// Check if limit exceeded
 	if len(validRequests) >= limit {
-		return false, nil
+		return false, len(validRequests), limit
 	}
 	
 	// Add current request if requested
@@ -461,7 +1197,7 @@ func (r *SimpleRateLimiter) checkLimit(userID string, action services.ActionType
 		r.requests[key] = validRequests
 	}
 	
-	return true, nil
+	return true, len(validRequests), limit
 }
 
 func (r *SimpleRateLimiter) GetRemainingRequests(ctx context.Context, userID string, action services.ActionType) (int, error) {
@@ -519,6 +1255,8 @@ func (r *SimpleRateLimiter) GetWindowResetTime(ctx context.Context, userID strin
 		window = 1 * time.Minute
 	case services.ActionUpdateProfile:
 		window = 1 * time.Minute
+	case services.ActionWSPOIJoin, services.ActionWSPOILeave, services.ActionWSCall, services.ActionWSSpeaking, services.ActionWSSignal, services.ActionWSReaction, services.ActionWSHand:
+		window = 1 * time.Minute
 	default:
 		window = 1 * time.Hour
 	}
@@ -567,10 +1305,16 @@ func (r *SimpleRateLimiter) GetUserStats(ctx context.Context, userID string) (*s
 
 func (r *SimpleRateLimiter) GetRateLimitHeaders(ctx context.Context, userID string, action services.ActionType) (map[string]string, error) {
 	remaining, _ := r.GetRemainingRequests(ctx, userID, action)
-	return map[string]string{
+	headers := map[string]string{
 		"X-RateLimit-Limit":     "100",
 		"X-RateLimit-Remaining": fmt.Sprintf("%d", remaining),
-	}, nil
+	}
+	
+	if _, used, limit := r.checkLimit(userID, action, false); services.IsNearRateLimit(used, limit, services.DefaultRateLimitWarningThreshold) {
+		headers[services.RateLimitWarningHeader] = "true"
+	}
+	
+	return headers, nil
 }
 
 // Mock handlers removed - using proper service-backed handlers only
@@ -724,4 +1468,405 @@ func (s *Server) setupFeedbackRoutes() {
 	feedbackHandler.RegisterRoutes(s.router)
 	
 	log.Println("✅ Feedback routes setup complete")
-}
\ No newline at end of file
+}
+
+// setupMapRoutes configures organizer endpoints for map settings, export and import
+func (s *Server) setupMapRoutes() {
+	log.Println("🔧 Setting up map routes...")
+	
+	// Scrub guests who left maps with guest anonymization once their retention window passed
+	if s.db != nil {
+		anonymizer := services.NewGuestAnonymizer(repository.NewGuestAnonymizationRepository(s.db))
+		s.scheduler.Register("guest_anonymization", time.Hour, func(ctx context.Context) error {
+			anonymized, err := anonymizer.Run(ctx)
+			if anonymized > 0 {
+				log.Printf("✅ Anonymized %d guests", anonymized)
+			}
+			return err
+		})
+	}
+	
+	// Spawn points and personal space are configured by organizers only
+	if s.spawnService == nil || s.mapSettings == nil || s.authService == nil {
+		log.Println("⚠️ Spawn service or auth service not available, map endpoints not available")
+		return
+	}
+	
+	mapHandler := handlers.NewMapHandler(s.spawnService, s.mapSettings)
+	mapHandler.SetSlowConsumerPolicyService(s.mapSettings)
+	mapHandler.SetPositionPrivacyService(s.mapSettings)
+	mapHandler.SetProfilePrivacyService(s.mapSettings)
+	mapHandler.SetGuestAnonymizationService(s.mapSettings)
+	mapHandler.SetMessageAuditPolicyService(s.mapSettings)
+	mapHandler.SetLocaleService(s.mapSettings)
+	mapHandler.RegisterRoutes(s.router, middleware.RequireAuth(s.authService), middleware.RequireAdmin())
+	
+	// Export and import of complete maps for backups and moving between environments
+	if s.poiService != nil {
+		archiveService := services.NewMapArchiveService(s.stores.maps, s.poiService)
+		archiveService.SetImageReferencer(storage.NewImageProcessor(s.getFileStorage(storage.GetStorageConfig())))
+		archiveHandler := handlers.NewMapArchiveHandler(archiveService)
+		archiveHandler.RegisterRoutes(s.router, middleware.RequireAuth(s.authService), middleware.RequireAdmin())
+
+		// Ownership transfer and deletion, which stores a snapshot with the deletion,
+		// emails the owner and superadmins about requests and disconnects the
+		// clients of deleted maps
+		ownershipService := services.NewMapOwnershipService(s.stores.maps, s.stores.users, archiveService)
+		ownershipService.SetMailer(newMailer(s.config))
+		ownershipService.SetPublisher(s.stores.newPubSub())
+		ownershipHandler := handlers.NewMapOwnershipHandler(ownershipService)
+		ownershipHandler.RegisterRoutes(s.router, middleware.RequireAuth(s.authService), middleware.RequireAdmin())
+	}
+	
+	// Public directory of the maps organizers opted in, with live participant counts
+	directoryService := services.NewMapDirectoryService(s.stores.maps, s.stores.presence)
+	directoryHandler := handlers.NewMapDirectoryHandler(directoryService)
+	directoryHandler.RegisterRoutes(s.router, middleware.RequireAuth(s.authService), middleware.RequireAdmin())
+	
+	// Facilitators call on raised hands in order
+	if s.handRaise != nil {
+		handHandler := handlers.NewHandQueueHandler(s.handRaise)
+		handHandler.RegisterRoutes(s.router, middleware.RequireAuth(s.authService), middleware.RequireAdmin())
+	}
+	
+	// Facilitators move participants to a POI without a WebSocket connection
+	if s.summon != nil {
+		summonHandler := handlers.NewSummonHandler(s.summon)
+		summonHandler.RegisterRoutes(s.router, middleware.RequireAuth(s.authService), middleware.RequireAdmin())
+	}
+	
+	// Facilitators merge duplicate POIs into one
+	if s.poiService != nil {
+		mergeHandler := handlers.NewPOIMergeHandler(s.poiService)
+		mergeHandler.RegisterRoutes(s.router, middleware.RequireAuth(s.authService), middleware.RequireAdmin())
+	}
+	
+	log.Println("✅ Map routes setup complete")
+}
+
+// setupHeatmapRoutes configures the map heatmap endpoint and its roll-up job
+func (s *Server) setupHeatmapRoutes() {
+	log.Println("🔧 Setting up heatmap routes...")
+	
+	// Positions are counted in Redis and rolled up into the database
+	if s.db == nil || s.redis == nil {
+		log.Println("⚠️ Database or Redis not available, heatmap not available")
+		return
+	}
+	
+	heatmapRepo := repository.NewHeatmapRepository(s.db)
+	heatmapCounter := redis.NewHeatmapCounter(s.redis)
+	s.heatmapService = services.NewHeatmapService(heatmapCounter, heatmapRepo, s.poiService)
+	
+	s.scheduler.Register("heatmap_rollup", 5*time.Minute, func(ctx context.Context) error {
+		rolledUp, err := s.heatmapService.RollUp(ctx)
+		if rolledUp > 0 {
+			log.Printf("✅ Rolled up %d heatmap buckets", rolledUp)
+		}
+		return err
+	})
+	
+	// Heatmaps are for organizers only
+	if s.authService == nil {
+		log.Println("⚠️ Auth service not available, heatmap endpoint not available")
+		return
+	}
+	
+	heatmapHandler := handlers.NewHeatmapHandler(s.heatmapService)
+	heatmapHandler.RegisterRoutes(s.router, middleware.RequireAuth(s.authService), middleware.RequireAdmin())
+	
+	log.Println("✅ Heatmap routes setup complete")
+}
+
+// setupUsageRoutes configures per-map usage metering, its roll-up job and the
+// usage report
+func (s *Server) setupUsageRoutes() {
+	log.Println("🔧 Setting up usage routes...")
+	
+	// Usage is metered in memory and rolled up into the database
+	if s.db == nil {
+		log.Println("⚠️ Database not available, usage not metered")
+		return
+	}
+	
+	s.usageService = services.NewUsageService(repository.NewUsageRepository(s.db))
+	if s.config.UsageWebhookURL != "" {
+		s.usageService.SetWebhook(webhook.NewSender(s.config.UsageWebhookURL, s.config.UsageWebhookSecret), services.UsageThresholds{
+			WebSocketBytes: int64(s.config.UsageWebSocketBytesThreshold),
+			StorageBytes:   int64(s.config.UsageStorageBytesThreshold),
+			CallMinutes:    int64(s.config.UsageCallMinutesThreshold),
+		})
+	}
+	if s.poiService != nil {
+		s.poiService.SetUsageRecorder(s.usageService)
+	}
+	
+	s.scheduler.Register("usage_rollup", 5*time.Minute, func(ctx context.Context) error {
+		_, err := s.usageService.Flush(ctx)
+		return err
+	})
+	
+	// Usage spans every map, so it is reported to the operator only
+	if s.authService == nil {
+		log.Println("⚠️ Auth service not available, usage endpoint not available")
+		return
+	}
+	
+	usageHandler := handlers.NewUsageHandler(s.usageService)
+	usageHandler.RegisterRoutes(s.router, middleware.RequireAuth(s.authService), middleware.RequireSuperAdmin())
+	
+	log.Println("✅ Usage routes setup complete")
+}
+
+// setupMaintenance creates the maintenance mode and rejects writes while it
+// is enabled, except for logging in and turning maintenance off again
+func (s *Server) setupMaintenance() {
+	// Changes are shared with all instances through PubSub
+	var publisher services.MaintenancePublisher
+	if s.stores != nil {
+		publisher = s.stores.newPubSub()
+	}
+	s.maintenance = services.NewMaintenanceService(publisher)
+	
+	// Only this instance starts in maintenance mode, others keep their mode
+	if s.config.MaintenanceMode {
+		since := time.Now()
+		s.maintenance.Apply(services.Maintenance{Enabled: true, Message: s.config.MaintenanceMessage, Since: &since})
+		log.Println("🚧 Starting in maintenance mode, writes are rejected")
+	}
+	
+	s.router.Use(middleware.Maintenance(s.maintenance, "/api/auth/login", "/api/auth/logout", "/api/admin/maintenance", "/api/internal/drain"))
+}
+
+// setupMaintenanceRoutes configures the maintenance status for clients and
+// maintenance management for the operator
+func (s *Server) setupMaintenanceRoutes() {
+	log.Println("🔧 Setting up maintenance routes...")
+	
+	maintenanceHandler := handlers.NewMaintenanceHandler(s.maintenance)
+	maintenanceHandler.RegisterRoutes(s.router)
+	
+	// Maintenance applies to every map, so only the operator changes it
+	if s.authService == nil {
+		log.Println("⚠️ Auth service not available, maintenance admin endpoints not available")
+		return
+	}
+	
+	maintenanceHandler.RegisterAdminRoutes(s.router, middleware.RequireAuth(s.authService), middleware.RequireSuperAdmin())
+	
+	log.Println("✅ Maintenance routes setup complete")
+}
+
+// setupDigestRoutes configures digest subscription endpoints and the digest job
+func (s *Server) setupDigestRoutes() {
+	log.Println("🔧 Setting up digest routes...")
+	
+	if s.db == nil {
+		log.Println("⚠️ Database not available, digests not available")
+		return
+	}
+	
+	digestService := services.NewDigestService(repository.NewDigestRepository(s.db), newMailer(s.config))
+	if s.heatmapService != nil {
+		digestService.SetTrafficSource(s.heatmapService)
+	}
+	
+	s.scheduler.Register("map_digests", time.Hour, func(ctx context.Context) error {
+		sent, err := digestService.SendDueDigests(ctx)
+		if sent > 0 {
+			log.Printf("✅ Sent %d map digests", sent)
+		}
+		return err
+	})
+	
+	// Subscriptions are managed by facilitators only
+	if s.authService == nil {
+		log.Println("⚠️ Auth service not available, digest subscription endpoints not available")
+		return
+	}
+	
+	digestHandler := handlers.NewDigestHandler(digestService)
+	digestHandler.RegisterRoutes(s.router, middleware.RequireAuth(s.authService), middleware.RequireAdmin())
+	
+	log.Println("✅ Digest routes setup complete")
+}
+
+// setupInvitationRoutes configures map invitation endpoints
+func (s *Server) setupInvitationRoutes() {
+	log.Println("🔧 Setting up invitation routes...")
+	
+	if s.db == nil {
+		log.Println("⚠️ Database not available, invitations not available")
+		return
+	}
+	
+	// Inviting is for facilitators only
+	if s.authService == nil {
+		log.Println("⚠️ Auth service not available, invitations not available")
+		return
+	}
+	
+	userService := services.NewUserService(repository.NewUserRepository(s.db), s.getFileStorage(storage.GetStorageConfig()))
+	invitationService := services.NewInvitationService(
+		repository.NewInvitationRepository(s.db),
+		repository.NewMapRepository(s.db),
+		userService,
+		newMailer(s.config),
+		s.config.FrontendURL,
+	)
+	
+	invitationHandler := handlers.NewInvitationHandler(invitationService)
+	invitationHandler.RegisterRoutes(s.router, middleware.RequireAuth(s.authService), middleware.RequireAdmin())
+	
+	log.Println("✅ Invitation routes setup complete")
+}
+
+// newMailer creates the SMTP mailer, or a mailer that only logs if SMTP is not configured
+func newMailer(cfg *config.Config) mailer.Mailer {
+	if cfg.SMTPHost == "" {
+		log.Println("⚠️ SMTP_HOST not set, emails will only be logged")
+		return mailer.LogMailer{}
+	}
+	
+	return mailer.NewSMTPMailer(mailer.SMTPConfig{
+		Host:     cfg.SMTPHost,
+		Port:     cfg.SMTPPort,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+		From:     cfg.SMTPFrom,
+	})
+}
+
+// setupRolloutRoutes configures feature lookups for clients and rollout management for admins
+func (s *Server) setupRolloutRoutes() {
+	log.Println("🔧 Setting up rollout routes...")
+	
+	// Share rollouts between instances through Redis so changes apply without redeploys
+	var store services.RolloutStore
+	if s.redis != nil {
+		store = redis.NewRolloutStore(s.redis)
+	}
+	s.rolloutService = services.NewRolloutService(store)
+	
+	rolloutHandler := handlers.NewRolloutHandler(s.rolloutService)
+	if s.authService == nil {
+		rolloutHandler.RegisterRoutes(s.router)
+		log.Println("⚠️ Auth service not available, rollout admin endpoints not available")
+		return
+	}
+	
+	rolloutHandler.RegisterRoutes(s.router, middleware.OptionalAuth(s.authService))
+	rolloutHandler.RegisterAdminRoutes(s.router, middleware.RequireAuth(s.authService), middleware.RequireAdmin())
+	
+	log.Println("✅ Rollout routes setup complete")
+}
+
+// setupModerationRoutes configures admin endpoints for reviewing and lifting automatic restrictions
+func (s *Server) setupModerationRoutes() {
+	log.Println("🔧 Setting up moderation routes...")
+	
+	// Admin endpoints require authentication
+	if s.authService == nil {
+		log.Println("⚠️ Auth service not available, moderation endpoints not available")
+		return
+	}
+	
+	moderationHandler := handlers.NewModerationHandler(s.abuseGuard)
+	moderationHandler.RegisterRoutes(s.router, middleware.RequireAuth(s.authService), middleware.RequireAdmin())
+	
+	log.Println("✅ Moderation routes setup complete")
+}
+
+// getFileStorage returns the shared content-addressed file storage, creating it on first use
+func (s *Server) getFileStorage(storageConfig storage.StorageConfig) *storage.DedupFileStorage {
+	if s.fileStorage == nil {
+		s.fileStorage = storage.NewDedupFileStorage(storage.NewFileStorage(storageConfig), s.stores.uploads)
+		log.Println("✅ Content-addressed upload deduplication enabled")
+	}
+	return s.fileStorage
+}
+
+// setupConfigRoutes serves the public runtime configuration of the frontend
+func (s *Server) setupConfigRoutes() {
+	storageConfig := storage.GetStorageConfig()
+	assetBaseURL := s.config.AssetBaseURL
+	if assetBaseURL == "" {
+		assetBaseURL = storageConfig.BaseURL + "/uploads"
+	}
+	
+	configHandler := handlers.NewConfigHandler(handlers.ClientConfig{
+		WebSocketURL: s.config.WebSocketURL,
+		AssetBaseURL: assetBaseURL,
+		TileStyle: handlers.TileStyle{
+			Tiles:       s.config.MapTileURLs,
+			TileSize:    256,
+			Attribution: s.config.MapTileAttribution,
+		},
+		MaxUploadSizes:   handlers.MaxUploadSizes{POIImage: storageConfig.MaxFileSize},
+		ProtocolVersion:  websocket.ProtocolVersion,
+		MinClientVersion: s.config.MinClientVersion,
+		API:              handlers.APIVersionInfo{UnversionedSunset: unversionedSunset(s.unversionedAPI)},
+	})
+	configHandler.SetRolloutService(s.rolloutService)
+	configHandler.RegisterRoutes(s.router)
+	
+	log.Println("✅ Config routes setup complete")
+}
+
+// unversionedAPIDeprecation returns the deprecation of unversioned /api paths
+// configured by API_UNVERSIONED_SUNSET, or nil if they aren't deprecated
+func unversionedAPIDeprecation(cfg *config.Config) (*middleware.Deprecation, error) {
+	if cfg.APIUnversionedSunset == "" {
+		return nil, nil
+	}
+	sunset, err := middleware.ParseSunset(cfg.APIUnversionedSunset)
+	if err != nil {
+		return nil, err
+	}
+	return &middleware.Deprecation{Sunset: sunset}, nil
+}
+
+// unversionedSunset returns the sunset of a deprecation for the config endpoint
+func unversionedSunset(deprecation *middleware.Deprecation) *time.Time {
+	if deprecation == nil {
+		return nil
+	}
+	return &deprecation.Sunset
+}
+
+// setupUploadRoutes configures routes for looking up and claiming already stored uploads by content hash
+func (s *Server) setupUploadRoutes() {
+	// Upload references are tracked in the database
+	if s.db == nil {
+		log.Println("⚠️ Database not available, upload lookup endpoints not available in test mode")
+		return
+	}
+	
+	// Only users can look up uploads, and claims are recorded as theirs
+	var jwtValidator middleware.AuthService
+	if s.authService != nil {
+		jwtValidator = s.authService
+	}
+	sessionService := services.NewSessionService(s.stores.sessions, s.stores.presence, s.stores.newPubSub())
+	
+	fileStorage := s.getFileStorage(storage.GetStorageConfig())
+	uploadHandler := handlers.NewUploadHandler(fileStorage)
+	uploadHandler.RegisterRoutes(s.router, middleware.RequireIdentity(jwtValidator, sessionService, s.sessionTokens))
+	
+	// Images of deleted POIs and replaced uploads are swept once nothing references them
+	imageSweeper := services.NewImageSweeper(fileStorage, repository.NewImageReferenceRepository(s.db))
+	imageSweeper.SetDryRun(s.config.ImageSweepDryRun)
+	imageSweeper.SetLimit(s.config.ImageSweepLimit)
+	s.scheduler.Register("image_sweep", 6*time.Hour, func(ctx context.Context) error {
+		deleted, err := imageSweeper.Sweep(ctx)
+		switch {
+		case deleted > 0 && s.config.ImageSweepDryRun:
+			log.Printf("✅ Found %d orphaned images, not deleted in dry run", deleted)
+		case deleted > 0:
+			log.Printf("✅ Swept %d orphaned images", deleted)
+		}
+		return err
+	})
+	
+	log.Println("✅ Upload routes setup complete")
+}
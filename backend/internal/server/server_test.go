@@ -39,4 +39,89 @@ func TestServer_APIStatus(t *testing.T) {
 	
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Contains(t, w.Body.String(), "BreakoutGlobe API is running")
-}
\ No newline at end of file
+}
+
+func TestServer_MaintenanceMode(t *testing.T) {
+	cfg := &config.Config{
+		GinMode:            "test",
+		MaintenanceMode:    true,
+		MaintenanceMessage: "Upgrading the database",
+	}
+	
+	server := New(cfg)
+	
+	// Reads keep working
+	req, _ := http.NewRequest("GET", "/api/maintenance", nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "Upgrading the database")
+	
+	// Writes are rejected
+	req, _ = http.NewRequest("POST", "/api/feedback", nil)
+	w = httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "MAINTENANCE")
+}
+
+func TestServer_RequestDrain(t *testing.T) {
+	server := New(&config.Config{GinMode: "test", MetricsToken: "ops-token"})
+	
+	req, _ := http.NewRequest("POST", "/api/internal/drain", nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	
+	req, _ = http.NewRequest("POST", "/api/internal/drain", nil)
+	req.Header.Set("Authorization", "Bearer ops-token")
+	w = httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	select {
+	case <-server.DrainRequested():
+	default:
+		t.Fatal("Drain was not requested")
+	}
+	assert.False(t, server.RequestDrain())
+}
+
+func TestServer_RequestDrain_RequiresToken(t *testing.T) {
+	server := New(&config.Config{GinMode: "test"})
+	
+	req, _ := http.NewRequest("POST", "/api/internal/drain", nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	select {
+	case <-server.DrainRequested():
+		t.Fatal("Drain was requested without a token")
+	default:
+	}
+}
+
+func TestServer_APIVersions(t *testing.T) {
+	server := New(&config.Config{GinMode: "test", APIUnversionedSunset: "2027-01-31"})
+	
+	req, _ := http.NewRequest("GET", "/api/v1/config", nil)
+	w := httptest.NewRecorder()
+	server.httpServer.Handler.ServeHTTP(w, req)
+	
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "v1", w.Header().Get("X-API-Version"))
+	assert.Empty(t, w.Header().Get("Deprecation"))
+	assert.Contains(t, w.Body.String(), `"unversionedSunset":"2027-01-31T00:00:00Z"`)
+	
+	req, _ = http.NewRequest("GET", "/api/config", nil)
+	w = httptest.NewRecorder()
+	server.httpServer.Handler.ServeHTTP(w, req)
+	
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "true", w.Header().Get("Deprecation"))
+	assert.Equal(t, "Sun, 31 Jan 2027 00:00:00 GMT", w.Header().Get("Sunset"))
+}
@@ -0,0 +1,253 @@
+package server
+
+import (
+	"fmt"
+	"log"
+
+	redislib "github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	"breakoutglobe/internal/config"
+	"breakoutglobe/internal/interfaces"
+	"breakoutglobe/internal/memory"
+	"breakoutglobe/internal/redis"
+	"breakoutglobe/internal/repository"
+	"breakoutglobe/internal/services"
+	"breakoutglobe/internal/storage"
+	"breakoutglobe/internal/websocket"
+)
+
+// Storage backends selected with STORAGE
+const (
+	storagePostgres = "postgres"
+	storageSQLite   = "sqlite"
+	storageMemory   = "memory"
+)
+
+// mapStore reads maps and updates their settings, spawn points, listing and
+// ownership
+type mapStore interface {
+	services.MapStore
+	services.MapSettingsStore
+	services.MapDirectoryStore
+	services.MapArchiveStore
+	services.MapOwnershipStore
+}
+
+// presenceStore tracks session presence and counts live participants per map
+type presenceStore interface {
+	services.SessionPresence
+	services.MapParticipantCounter
+}
+
+// participantStore tracks POI participants, their join times and who
+// recently joined, and numbers their roster changes
+type participantStore interface {
+	services.POIParticipantsInterface
+	services.RosterSequencerInterface
+	services.ParticipantJoinTimesInterface
+	services.POIJoinHistoryInterface
+}
+
+// eventPubSub publishes real-time events and delivers them to the WebSocket
+// handler
+type eventPubSub interface {
+	services.PubSub
+	services.ProfilePublisher
+	services.MapFreezePublisher
+	services.OnboardingPublisher
+	services.MaintenancePublisher
+	services.HandQueuePublisher
+	services.SummonPublisher
+	services.ModerationPublisher
+	services.MapDeletionPublisher
+	services.RSVPReminderPublisher
+	websocket.PubSubInterface
+}
+
+// PubSub backends selected with PUBSUB
+const (
+	pubSubRedis  = "redis"
+	pubSubMemory = "memory"
+)
+
+// stores are the repositories and real-time stores behind the core features:
+// sessions, users, POIs and maps. Features without an in-memory
+// implementation, like heatmaps and invitations, still need a database.
+type stores struct {
+	realtimeStores
+	sessions     services.SessionRepository
+	users        interfaces.UserRepositoryInterface
+	pois         services.POIRepositoryInterface
+	maps         mapStore
+	preferences  services.PreferenceStore
+	onboarding   services.OnboardingStore
+	authSessions services.AuthSessionStore
+	uploads      storage.UploadIndex
+}
+
+// realtimeStores track who is online, in which POI and banned from which
+// map, and deliver real-time events to the WebSocket handler
+type realtimeStores struct {
+	presence     presenceStore
+	participants participantStore
+	hands        services.HandQueueStore
+	bans         services.MapBanStore
+	newPubSub    func() eventPubSub
+	// reconnectRevocations shares revoked reconnect tokens between instances,
+	// nil if there is only this one
+	reconnectRevocations websocket.ReconnectRevocationStore
+}
+
+// newStores creates the stores of the configured storage and PubSub backends,
+// postgres and redis if none are configured. The postgres and sqlite storage
+// use db, the redis PubSub uses redisClient.
+func newStores(cfg *config.Config, db *gorm.DB, redisClient *redislib.Client, eventStream *redis.EventStream) (*stores, error) {
+	realtime, err := newRealtimeStores(cfg, redisClient, eventStream)
+	if err != nil {
+		return nil, err
+	}
+
+	var s *stores
+	switch cfg.Storage {
+	case "", storagePostgres, storageSQLite:
+		if db != nil {
+			s = newRepositoryStores(db)
+		}
+	case storageMemory:
+		s, err = newMemoryStores(cfg)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown STORAGE %q, expected postgres, sqlite or memory", cfg.Storage)
+	}
+
+	if s == nil || realtime == nil {
+		return nil, nil
+	}
+	s.realtimeStores = *realtime
+	return s, nil
+}
+
+// pubSubBackend returns the configured PubSub backend. Without one, Postgres
+// deployments share events through Redis, while the sqlite and memory storage,
+// which serve a single instance, keep them in process.
+func pubSubBackend(cfg *config.Config) string {
+	if cfg.PubSub != "" {
+		return cfg.PubSub
+	}
+	if cfg.Storage == "" || cfg.Storage == storagePostgres {
+		return pubSubRedis
+	}
+	return pubSubMemory
+}
+
+// newRealtimeStores creates the real-time stores of the configured PubSub
+// backend, or nil if it's redis and redisClient is nil
+func newRealtimeStores(cfg *config.Config, redisClient *redislib.Client, eventStream *redis.EventStream) (*realtimeStores, error) {
+	switch pubSubBackend(cfg) {
+	case pubSubRedis:
+		if redisClient == nil {
+			return nil, nil
+		}
+		return newRedisRealtimeStores(redisClient, eventStream), nil
+	case pubSubMemory:
+		return newMemoryRealtimeStores(), nil
+	default:
+		return nil, fmt.Errorf("unknown PUBSUB %q, expected redis or memory", cfg.PubSub)
+	}
+}
+
+// newRedisRealtimeStores creates real-time stores in Redis, where POI and
+// user events reach every instance through the event stream
+func newRedisRealtimeStores(redisClient *redislib.Client, eventStream *redis.EventStream) *realtimeStores {
+	return &realtimeStores{
+		presence:     redis.NewSessionPresence(redisClient),
+		participants: redis.NewPOIParticipants(redisClient),
+		hands:        redis.NewHandQueue(redisClient),
+		bans:         redis.NewMapBans(redisClient),
+		newPubSub: func() eventPubSub {
+			pubsub := redis.NewPubSub(redisClient)
+			if eventStream != nil {
+				pubsub.SetEventStream(eventStream)
+			}
+			return pubsub
+		},
+		reconnectRevocations: redis.NewReconnectRevocations(redisClient),
+	}
+}
+
+// newMemoryRealtimeStores creates real-time stores in process memory, which
+// only reach the clients of this instance
+func newMemoryRealtimeStores() *realtimeStores {
+	// Every publisher and subscriber shares one PubSub, like they share a
+	// Redis server
+	pubsub := memory.NewPubSub()
+
+	return &realtimeStores{
+		presence:     memory.NewSessionPresence(),
+		participants: memory.NewPOIParticipants(),
+		hands:        memory.NewHandQueue(),
+		bans:         memory.NewMapBans(),
+		newPubSub: func() eventPubSub {
+			return pubsub
+		},
+	}
+}
+
+// newRepositoryStores creates the stores keeping rows in db, in Postgres or
+// SQLite
+func newRepositoryStores(db *gorm.DB) *stores {
+	return &stores{
+		sessions:     repository.NewSessionRepository(db),
+		users:        repository.NewUserRepository(db),
+		pois:         repository.NewPOIRepository(db),
+		maps:         repository.NewMapRepository(db),
+		preferences:  repository.NewPreferenceRepository(db),
+		onboarding:   repository.NewOnboardingRepository(db),
+		authSessions: repository.NewAuthSessionRepository(db),
+		uploads:      repository.NewUploadReferenceRepository(db),
+	}
+}
+
+// newMemoryStores creates stores keeping all rows in process memory, with
+// the super admin from the config
+func newMemoryStores(cfg *config.Config) (*stores, error) {
+	store := memory.NewStore()
+	if err := store.CreateSuperAdmin(cfg.SuperAdminEmail, cfg.SuperAdminPassword); err != nil {
+		return nil, err
+	}
+
+	return &stores{
+		sessions:     store.Sessions(),
+		users:        store.Users(),
+		pois:         store.POIs(),
+		maps:         store.Maps(),
+		preferences:  store.Preferences(),
+		onboarding:   store.Onboarding(),
+		authSessions: store.AuthSessions(),
+		uploads:      storage.NewMemoryUploadIndex(),
+	}, nil
+}
+
+// logStorage reports which storage and PubSub backends the server runs on
+func logStorage(cfg *config.Config, s *stores) {
+	switch {
+	case s == nil:
+		log.Println("⚠️ No storage available, core endpoints not available in test mode")
+		return
+	case cfg.Storage == storageMemory:
+		log.Println("⚠️ Keeping all data in memory, everything is lost on restart")
+	case cfg.Storage == storageSQLite:
+		log.Printf("✅ Storing data in %s", cfg.SQLitePath)
+	default:
+		log.Println("✅ Storing data in Postgres")
+	}
+
+	if pubSubBackend(cfg) == pubSubMemory {
+		log.Println("✅ Keeping real-time state in memory, only one instance can serve clients")
+	} else {
+		log.Println("✅ Sharing real-time state between instances through Redis")
+	}
+}
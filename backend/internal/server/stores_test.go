@@ -0,0 +1,117 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"breakoutglobe/internal/config"
+	"breakoutglobe/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_MemoryStorage(t *testing.T) {
+	// Upload directories are created relative to the working directory
+	t.Chdir(t.TempDir())
+
+	server := New(&config.Config{
+		GinMode:   "test",
+		Storage:   "memory",
+		JWTSecret: "test-secret",
+		JWTExpiry: "24h",
+	})
+	require.NotNil(t, server.stores)
+
+	assertCoreEndpoints(t, server)
+}
+
+func TestServer_SQLiteStorage(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	cfg := &config.Config{
+		GinMode:    "test",
+		Storage:    "sqlite",
+		SQLitePath: "breakoutglobe.db",
+		JWTSecret:  "test-secret",
+		JWTExpiry:  "24h",
+	}
+	server := New(cfg)
+	require.NotNil(t, server.stores)
+	require.NotNil(t, server.db)
+	t.Cleanup(func() { database.CloseConnection(server.db) })
+
+	assertCoreEndpoints(t, server)
+
+	// The database file is locked to this server
+	_, err := database.NewSQLiteConnection(cfg.SQLitePath)
+	assert.Error(t, err)
+}
+
+// assertCoreEndpoints creates a profile and a session through the API and
+// reads the session back
+func assertCoreEndpoints(t *testing.T, server *Server) {
+	t.Helper()
+
+	post := func(path string, body interface{}) *httptest.ResponseRecorder {
+		payload, err := json.Marshal(body)
+		require.NoError(t, err)
+		req, _ := http.NewRequest("POST", path, bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post("/api/users/profile", map[string]string{"displayName": "Memory Guest", "accountType": "guest"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var profile struct {
+		ID string `json:"id"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &profile))
+
+	w = post("/api/sessions", map[string]interface{}{
+		"userId":         profile.ID,
+		"mapId":          "default-map",
+		"avatarPosition": map[string]float64{"lat": 52.52, "lng": 13.405},
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var session struct {
+		SessionID string `json:"sessionId"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &session))
+
+	req, _ := http.NewRequest("GET", "/api/sessions/"+session.SessionID, nil)
+	w = httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), profile.ID)
+}
+
+func TestServer_UnknownStorage(t *testing.T) {
+	_, err := newStores(&config.Config{Storage: "mysql"}, nil, nil, nil)
+	assert.Error(t, err)
+}
+
+func TestServer_PubSubBackend(t *testing.T) {
+	assert.Equal(t, "redis", pubSubBackend(&config.Config{}))
+	assert.Equal(t, "redis", pubSubBackend(&config.Config{Storage: "postgres"}))
+	assert.Equal(t, "memory", pubSubBackend(&config.Config{Storage: "postgres", PubSub: "memory"}))
+	assert.Equal(t, "memory", pubSubBackend(&config.Config{Storage: "sqlite"}))
+	assert.Equal(t, "memory", pubSubBackend(&config.Config{Storage: "memory"}))
+
+	// Stores are only available once Redis is connected
+	s, err := newStores(&config.Config{Storage: "memory", PubSub: "redis"}, nil, nil, nil)
+	require.NoError(t, err)
+	assert.Nil(t, s)
+
+	s, err = newStores(&config.Config{Storage: "memory", PubSub: "memory"}, nil, nil, nil)
+	require.NoError(t, err)
+	require.NotNil(t, s)
+	assert.NotNil(t, s.newPubSub())
+
+	_, err = newStores(&config.Config{Storage: "memory", PubSub: "nats"}, nil, nil, nil)
+	assert.Error(t, err)
+}
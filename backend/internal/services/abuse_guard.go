@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// AbuseSignal represents a per-user event watched by the abuse heuristics
+type AbuseSignal string
+
+const (
+	// SignalPing is a call request ringing another user, answered or not.
+	// Heartbeats and other automatic messages don't count.
+	SignalPing            AbuseSignal = "ping"
+	SignalMovementAnomaly AbuseSignal = "movement_anomaly"
+	SignalCallRejected    AbuseSignal = "call_rejected"
+)
+
+// RestrictionType represents an automatic restriction applied to a user
+type RestrictionType string
+
+const (
+	RestrictionBlockCalls RestrictionType = "block_calls"
+)
+
+// AbuseRule triggers a restriction when a signal exceeds a threshold within a window
+type AbuseRule struct {
+	Name        string          `json:"name"`
+	Signal      AbuseSignal     `json:"signal"`
+	Threshold   int             `json:"threshold"`   // Number of events that trigger the rule
+	Window      time.Duration   `json:"window"`      // Time window the events are counted in
+	Restriction RestrictionType `json:"restriction"` // Restriction applied when triggered
+	Duration    time.Duration   `json:"duration"`    // How long the restriction lasts
+}
+
+// Restriction represents an active automatic restriction for a user
+type Restriction struct {
+	UserID    string          `json:"userId"`
+	MapID     string          `json:"mapId"`
+	Type      RestrictionType `json:"type"`
+	Rule      string          `json:"rule"`
+	AppliedAt time.Time       `json:"appliedAt"`
+	ExpiresAt time.Time       `json:"expiresAt"`
+}
+
+// IsExpired checks if the restriction has run out
+func (r *Restriction) IsExpired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
+
+// RestrictionNotifier is called whenever a new restriction is applied
+type RestrictionNotifier func(restriction *Restriction)
+
+// AbuseGuardInterface defines the interface for abuse heuristics
+type AbuseGuardInterface interface {
+	// RecordSignal records an event for a user and returns any restrictions it triggered
+	RecordSignal(ctx context.Context, userID, mapID string, signal AbuseSignal) []*Restriction
+
+	// IsRestricted checks if a user currently has the given restriction
+	IsRestricted(ctx context.Context, userID string, restriction RestrictionType) bool
+
+	// GetRestrictions returns all active restrictions for a user
+	GetRestrictions(ctx context.Context, userID string) []*Restriction
+
+	// LiftRestriction removes a restriction from a user (empty type lifts all)
+	LiftRestriction(ctx context.Context, userID string, restriction RestrictionType) error
+}
+
+// AbuseGuard is an in-memory rules engine applying temporary automatic restrictions
+type AbuseGuard struct {
+	rules        []AbuseRule
+	events       map[string][]time.Time                      // userID:signal -> event times
+	restrictions map[string]map[RestrictionType]*Restriction // userID -> type -> restriction
+	notifier     RestrictionNotifier
+	mutex        sync.Mutex
+	now          func() time.Time
+	// maxWindow is the longest window of any rule, after which events no
+	// longer count and are pruned
+	maxWindow  time.Duration
+	lastPruned time.Time
+}
+
+// NewAbuseGuard creates a new abuse guard with the given rules
+func NewAbuseGuard(rules []AbuseRule) *AbuseGuard {
+	maxWindow := time.Duration(0)
+	for _, rule := range rules {
+		if rule.Window > maxWindow {
+			maxWindow = rule.Window
+		}
+	}
+
+	return &AbuseGuard{
+		rules:        rules,
+		events:       make(map[string][]time.Time),
+		restrictions: make(map[string]map[RestrictionType]*Restriction),
+		now:          time.Now,
+		maxWindow:    maxWindow,
+	}
+}
+
+// GetDefaultAbuseRules returns the default abuse heuristics
+func GetDefaultAbuseRules() []AbuseRule {
+	return []AbuseRule{
+		{
+			Name:        "ping_flood",
+			Signal:      SignalPing,
+			Threshold:   10,
+			Window:      1 * time.Minute,
+			Restriction: RestrictionBlockCalls,
+			Duration:    5 * time.Minute,
+		},
+		{
+			Name:        "movement_anomaly",
+			Signal:      SignalMovementAnomaly,
+			Threshold:   10,
+			Window:      1 * time.Minute,
+			Restriction: RestrictionBlockCalls,
+			Duration:    2 * time.Minute,
+		},
+		{
+			Name:        "rejected_calls",
+			Signal:      SignalCallRejected,
+			Threshold:   5,
+			Window:      5 * time.Minute,
+			Restriction: RestrictionBlockCalls,
+			Duration:    15 * time.Minute,
+		},
+	}
+}
+
+// SetNotifier sets the callback invoked when a restriction is applied
+func (g *AbuseGuard) SetNotifier(notifier RestrictionNotifier) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.notifier = notifier
+}
+
+// RecordSignal records an event for a user and returns any restrictions it triggered
+func (g *AbuseGuard) RecordSignal(ctx context.Context, userID, mapID string, signal AbuseSignal) []*Restriction {
+	g.mutex.Lock()
+
+	now := g.now()
+	key := fmt.Sprintf("%s:%s", userID, signal)
+	g.pruneEvents(now)
+
+	// Keep only events inside the longest window of any rule for this signal
+	maxWindow := time.Duration(0)
+	for _, rule := range g.rules {
+		if rule.Signal == signal && rule.Window > maxWindow {
+			maxWindow = rule.Window
+		}
+	}
+
+	var events []time.Time
+	for _, t := range g.events[key] {
+		if now.Sub(t) < maxWindow {
+			events = append(events, t)
+		}
+	}
+	events = append(events, now)
+	g.events[key] = events
+
+	var applied []*Restriction
+	for _, rule := range g.rules {
+		if rule.Signal != signal {
+			continue
+		}
+
+		count := 0
+		for _, t := range events {
+			if now.Sub(t) < rule.Window {
+				count++
+			}
+		}
+		if count < rule.Threshold {
+			continue
+		}
+
+		// Don't re-apply a restriction that is still active
+		if existing := g.activeRestriction(userID, rule.Restriction, now); existing != nil {
+			continue
+		}
+
+		restriction := &Restriction{
+			UserID:    userID,
+			MapID:     mapID,
+			Type:      rule.Restriction,
+			Rule:      rule.Name,
+			AppliedAt: now,
+			ExpiresAt: now.Add(rule.Duration),
+		}
+		if g.restrictions[userID] == nil {
+			g.restrictions[userID] = make(map[RestrictionType]*Restriction)
+		}
+		g.restrictions[userID][rule.Restriction] = restriction
+		applied = append(applied, restriction)
+
+		// Reset the counter so the user starts fresh once the restriction ends
+		delete(g.events, key)
+	}
+
+	notifier := g.notifier
+	g.mutex.Unlock()
+
+	// Notify outside the lock so notifiers may query the guard
+	if notifier != nil {
+		for _, restriction := range applied {
+			notifier(restriction)
+		}
+	}
+
+	return applied
+}
+
+// IsRestricted checks if a user currently has the given restriction
+func (g *AbuseGuard) IsRestricted(ctx context.Context, userID string, restriction RestrictionType) bool {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	return g.activeRestriction(userID, restriction, g.now()) != nil
+}
+
+// GetRestrictions returns all active restrictions for a user
+func (g *AbuseGuard) GetRestrictions(ctx context.Context, userID string) []*Restriction {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	now := g.now()
+	restrictions := []*Restriction{}
+	for restrictionType := range g.restrictions[userID] {
+		if r := g.activeRestriction(userID, restrictionType, now); r != nil {
+			restrictions = append(restrictions, r)
+		}
+	}
+	return restrictions
+}
+
+// LiftRestriction removes a restriction from a user (empty type lifts all)
+func (g *AbuseGuard) LiftRestriction(ctx context.Context, userID string, restriction RestrictionType) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if restriction == "" {
+		delete(g.restrictions, userID)
+		return nil
+	}
+
+	if restriction != RestrictionBlockCalls {
+		return fmt.Errorf("%w: unknown restriction type %s", ErrInvalidInput, restriction)
+	}
+
+	delete(g.restrictions[userID], restriction)
+	if len(g.restrictions[userID]) == 0 {
+		delete(g.restrictions, userID)
+	}
+	return nil
+}
+
+// pruneEvents drops the events of users who stopped sending a signal, once
+// per longest rule window, so their keys don't pile up. Must be called with
+// the mutex held.
+func (g *AbuseGuard) pruneEvents(now time.Time) {
+	if now.Sub(g.lastPruned) < g.maxWindow {
+		return
+	}
+	g.lastPruned = now
+
+	for key, events := range g.events {
+		if len(events) == 0 || now.Sub(events[len(events)-1]) >= g.maxWindow {
+			delete(g.events, key)
+		}
+	}
+}
+
+// activeRestriction returns the restriction if it is still active, dropping expired ones
+// Must be called with the mutex held
+func (g *AbuseGuard) activeRestriction(userID string, restriction RestrictionType, now time.Time) *Restriction {
+	userRestrictions, exists := g.restrictions[userID]
+	if !exists {
+		return nil
+	}
+
+	r, exists := userRestrictions[restriction]
+	if !exists {
+		return nil
+	}
+
+	if r.IsExpired(now) {
+		delete(userRestrictions, restriction)
+		if len(userRestrictions) == 0 {
+			delete(g.restrictions, userID)
+		}
+		return nil
+	}
+
+	return r
+}
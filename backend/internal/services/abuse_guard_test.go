@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAbuseGuard(now *time.Time) *AbuseGuard {
+	guard := NewAbuseGuard([]AbuseRule{
+		{
+			Name:        "rejected_calls",
+			Signal:      SignalCallRejected,
+			Threshold:   3,
+			Window:      1 * time.Minute,
+			Restriction: RestrictionBlockCalls,
+			Duration:    5 * time.Minute,
+		},
+	})
+	guard.now = func() time.Time { return *now }
+	return guard
+}
+
+func TestAbuseGuard_AppliesRestrictionAtThreshold(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	guard := newTestAbuseGuard(&now)
+
+	var notified []*Restriction
+	guard.SetNotifier(func(r *Restriction) {
+		notified = append(notified, r)
+	})
+
+	assert.Empty(t, guard.RecordSignal(ctx, "user-1", "map-1", SignalCallRejected))
+	assert.Empty(t, guard.RecordSignal(ctx, "user-1", "map-1", SignalCallRejected))
+	assert.False(t, guard.IsRestricted(ctx, "user-1", RestrictionBlockCalls))
+
+	applied := guard.RecordSignal(ctx, "user-1", "map-1", SignalCallRejected)
+	require.Len(t, applied, 1)
+	assert.Equal(t, RestrictionBlockCalls, applied[0].Type)
+	assert.Equal(t, "map-1", applied[0].MapID)
+	assert.Equal(t, now.Add(5*time.Minute), applied[0].ExpiresAt)
+
+	assert.True(t, guard.IsRestricted(ctx, "user-1", RestrictionBlockCalls))
+	assert.False(t, guard.IsRestricted(ctx, "user-2", RestrictionBlockCalls))
+	assert.Len(t, notified, 1)
+}
+
+func TestAbuseGuard_EventsOutsideWindowDoNotCount(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	guard := newTestAbuseGuard(&now)
+
+	guard.RecordSignal(ctx, "user-1", "map-1", SignalCallRejected)
+	guard.RecordSignal(ctx, "user-1", "map-1", SignalCallRejected)
+
+	now = now.Add(2 * time.Minute)
+	assert.Empty(t, guard.RecordSignal(ctx, "user-1", "map-1", SignalCallRejected))
+	assert.False(t, guard.IsRestricted(ctx, "user-1", RestrictionBlockCalls))
+}
+
+func TestAbuseGuard_RestrictionExpires(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	guard := newTestAbuseGuard(&now)
+
+	for i := 0; i < 3; i++ {
+		guard.RecordSignal(ctx, "user-1", "map-1", SignalCallRejected)
+	}
+	assert.True(t, guard.IsRestricted(ctx, "user-1", RestrictionBlockCalls))
+
+	now = now.Add(5 * time.Minute)
+	assert.False(t, guard.IsRestricted(ctx, "user-1", RestrictionBlockCalls))
+	assert.Empty(t, guard.GetRestrictions(ctx, "user-1"))
+}
+
+func TestAbuseGuard_LiftRestriction(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	guard := newTestAbuseGuard(&now)
+
+	for i := 0; i < 3; i++ {
+		guard.RecordSignal(ctx, "user-1", "map-1", SignalCallRejected)
+	}
+	assert.Len(t, guard.GetRestrictions(ctx, "user-1"), 1)
+
+	err := guard.LiftRestriction(ctx, "user-1", RestrictionBlockCalls)
+	assert.NoError(t, err)
+	assert.False(t, guard.IsRestricted(ctx, "user-1", RestrictionBlockCalls))
+
+	err = guard.LiftRestriction(ctx, "user-1", RestrictionType("mute_chat"))
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
+
+func TestAbuseGuard_PrunesIdleUsers(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	guard := newTestAbuseGuard(&now)
+
+	guard.RecordSignal(ctx, "user-1", "map-1", SignalCallRejected)
+	guard.RecordSignal(ctx, "user-2", "map-1", SignalCallRejected)
+
+	// Once a window passed, users who went quiet are forgotten
+	now = now.Add(time.Minute)
+	guard.RecordSignal(ctx, "user-2", "map-1", SignalCallRejected)
+	assert.NotContains(t, guard.events, "user-1:call_rejected")
+	assert.Len(t, guard.events["user-2:call_rejected"], 1)
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -14,13 +15,21 @@ type JWTClaims struct {
 	UserID string           `json:"userId"`
 	Email  string           `json:"email"`
 	Role   models.UserRole  `json:"role"`
+	// SessionID identifies the sign-in the token was issued for, if sign-ins are tracked
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// AuthSessionValidator defines the interface for checking that the sign-in of a token is still active
+type AuthSessionValidator interface {
+	ValidateSession(ctx context.Context, sessionID string) error
+}
+
 // AuthService handles authentication operations
 type AuthService struct {
 	jwtSecret   []byte
 	jwtExpiry   time.Duration
+	sessions    AuthSessionValidator
 }
 
 // NewAuthService creates a new AuthService instance
@@ -31,6 +40,17 @@ func NewAuthService(jwtSecret string, jwtExpiry time.Duration) *AuthService {
 	}
 }
 
+// SetSessionValidator makes tokens of revoked sign-ins invalid. Tokens issued
+// without a session stay valid until they expire.
+func (s *AuthService) SetSessionValidator(sessions AuthSessionValidator) {
+	s.sessions = sessions
+}
+
+// JWTExpiry returns how long issued tokens are valid
+func (s *AuthService) JWTExpiry() time.Duration {
+	return s.jwtExpiry
+}
+
 // HashPassword hashes a password using bcrypt with cost factor 12
 func (s *AuthService) HashPassword(password string) (string, error) {
 	if password == "" {
@@ -66,6 +86,12 @@ func (s *AuthService) VerifyPassword(password, hash string) error {
 
 // GenerateJWT generates a JWT token for a user
 func (s *AuthService) GenerateJWT(userID, email string, role models.UserRole) (string, time.Time, error) {
+	return s.GenerateSessionJWT(userID, email, role, "")
+}
+
+// GenerateSessionJWT generates a JWT token for a user's sign-in, which stops
+// working when the session is revoked
+func (s *AuthService) GenerateSessionJWT(userID, email string, role models.UserRole, sessionID string) (string, time.Time, error) {
 	if userID == "" {
 		return "", time.Time{}, fmt.Errorf("user ID cannot be empty")
 	}
@@ -84,6 +110,7 @@ func (s *AuthService) GenerateJWT(userID, email string, role models.UserRole) (s
 		UserID: userID,
 		Email:  email,
 		Role:   role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expiryTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -136,5 +163,12 @@ func (s *AuthService) ValidateJWT(tokenString string) (*JWTClaims, error) {
 		return nil, fmt.Errorf("token has expired")
 	}
 
+	// Check that the sign-in was not revoked
+	if s.sessions != nil && claims.SessionID != "" {
+		if err := s.sessions.ValidateSession(context.Background(), claims.SessionID); err != nil {
+			return nil, fmt.Errorf("invalid token: %w", err)
+		}
+	}
+
 	return claims, nil
 }
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"breakoutglobe/internal/mailer"
+	"breakoutglobe/internal/models"
+)
+
+// AuthSessionHistory is how long sign-ins are kept after they expire. Devices
+// and locations of kept sign-ins count as known for anomaly notifications.
+const AuthSessionHistory = 90 * 24 * time.Hour
+
+// knownLocationRadiusKm is how close a sign-in must be to an earlier one to
+// count as the same location. GeoIP locations are only city accurate.
+const knownLocationRadiusKm = 300.0
+
+// authSessionTouchInterval limits how often the last use of a session is stored
+const authSessionTouchInterval = 5 * time.Minute
+
+// AuthSessionStore defines the interface for storing sign-ins
+type AuthSessionStore interface {
+	Create(ctx context.Context, session *models.AuthSession) error
+	GetByID(ctx context.Context, id string) (*models.AuthSession, error)
+	ListByUser(ctx context.Context, userID string, since time.Time) ([]*models.AuthSession, error)
+	Touch(ctx context.Context, id string, at time.Time) error
+	Revoke(ctx context.Context, userID string, ids []string, at time.Time) (int64, error)
+	DeleteExpiredBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// AuthSessionService tracks the sign-ins of full accounts, so users can see
+// where they are signed in, sign out other devices and get an email when
+// someone signs in from a new device or location
+type AuthSessionService struct {
+	store    AuthSessionStore
+	mailer   mailer.Mailer
+	geoip    GeoIPProvider
+	lifetime time.Duration
+	now      func() time.Time
+}
+
+// NewAuthSessionService creates a new AuthSessionService instance
+// lifetime should match the expiry of the tokens issued for a session
+func NewAuthSessionService(store AuthSessionStore, m mailer.Mailer, lifetime time.Duration) *AuthSessionService {
+	return &AuthSessionService{
+		store:    store,
+		mailer:   m,
+		lifetime: lifetime,
+		now:      time.Now,
+	}
+}
+
+// SetGeoIPProvider enables locating sign-ins. Without it, only new devices are notified.
+func (s *AuthSessionService) SetGeoIPProvider(provider GeoIPProvider) {
+	s.geoip = provider
+}
+
+// StartSession records a sign-in and emails the user if it comes from a device
+// or location not seen before. The first sign-in of an account is never reported.
+func (s *AuthSessionService) StartSession(ctx context.Context, user *models.User, userAgent string, ip net.IP) (*models.AuthSession, error) {
+	now := s.now()
+
+	ipAddress := ""
+	if ip != nil {
+		ipAddress = ip.String()
+	}
+	session, err := models.NewAuthSession(user.ID, userAgent, ipAddress, now.Add(s.lifetime))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	session.CreatedAt = now
+	session.LastSeenAt = now
+
+	if s.geoip != nil && ip != nil {
+		if location, err := s.geoip.Locate(ctx, ip); err == nil {
+			session.SetLocation(location)
+		}
+	}
+
+	previous, err := s.store.ListByUser(ctx, user.ID, now.Add(-s.lifetime-AuthSessionHistory))
+	if err != nil {
+		// Without history every sign-in would look new, so skip the notification
+		fmt.Printf("Warning: failed to load sign-in history of user %s: %v\n", user.ID, err)
+		previous = nil
+	}
+
+	if err := s.store.Create(ctx, session); err != nil {
+		return nil, err
+	}
+
+	if len(previous) > 0 && !isKnownSignIn(previous, session) {
+		if err := s.notifyNewSignIn(ctx, user, session); err != nil {
+			fmt.Printf("Warning: failed to send sign-in notification to user %s: %v\n", user.ID, err)
+		}
+	}
+
+	return session, nil
+}
+
+// isKnownSignIn reports whether an earlier sign-in used the same device from
+// about the same place. Sign-ins without a location match any location.
+func isKnownSignIn(previous []*models.AuthSession, session *models.AuthSession) bool {
+	location, located := session.Location()
+	for _, earlier := range previous {
+		if earlier.UserAgent != session.UserAgent {
+			continue
+		}
+		earlierLocation, ok := earlier.Location()
+		if !located || !ok || earlierLocation.DistanceTo(location) <= knownLocationRadiusKm {
+			return true
+		}
+	}
+	return false
+}
+
+// notifyNewSignIn emails a user about a sign-in from a new device or location
+func (s *AuthSessionService) notifyNewSignIn(ctx context.Context, user *models.User, session *models.AuthSession) error {
+	if s.mailer == nil || user.Email == nil {
+		return nil
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Hi %s,\n\n", user.DisplayName)
+	body.WriteString("your BreakoutGlobe account was just signed in to from a new device or location.\n\n")
+	fmt.Fprintf(&body, "Time: %s\n", session.CreatedAt.UTC().Format("2006-01-02 15:04 MST"))
+	if session.UserAgent != "" {
+		fmt.Fprintf(&body, "Device: %s\n", session.UserAgent)
+	}
+	if session.IPAddress != "" {
+		fmt.Fprintf(&body, "IP address: %s\n", session.IPAddress)
+	}
+	if location, ok := session.Location(); ok {
+		fmt.Fprintf(&body, "Approximate location: %s\n", location)
+	}
+	body.WriteString("\nIf this was you, there is nothing to do. Otherwise, sign out the session under your active sessions and change your password.\n")
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      []string{*user.Email},
+		Subject: "New sign-in to your BreakoutGlobe account",
+		Body:    body.String(),
+	})
+}
+
+// ListActiveSessions returns the sessions of a user whose tokens are still accepted, newest first
+func (s *AuthSessionService) ListActiveSessions(ctx context.Context, userID string) ([]*models.AuthSession, error) {
+	now := s.now()
+	sessions, err := s.store.ListByUser(ctx, userID, now.Add(-s.lifetime))
+	if err != nil {
+		return nil, err
+	}
+
+	active := []*models.AuthSession{}
+	for _, session := range sessions {
+		if session.IsActive(now) {
+			active = append(active, session)
+		}
+	}
+	return active, nil
+}
+
+// RevokeSession signs out one session of a user
+func (s *AuthSessionService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	revoked, err := s.store.Revoke(ctx, userID, []string{sessionID}, s.now())
+	if err != nil {
+		return err
+	}
+	if revoked == 0 {
+		return fmt.Errorf("session %w", ErrNotFound)
+	}
+	return nil
+}
+
+// RevokeOtherSessions signs out all sessions of a user except the current one
+// and returns how many were revoked
+func (s *AuthSessionService) RevokeOtherSessions(ctx context.Context, userID, currentSessionID string) (int, error) {
+	sessions, err := s.ListActiveSessions(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	ids := []string{}
+	for _, session := range sessions {
+		if session.ID != currentSessionID {
+			ids = append(ids, session.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	revoked, err := s.store.Revoke(ctx, userID, ids, s.now())
+	return int(revoked), err
+}
+
+// ValidateSession returns ErrSessionRevoked unless tokens of the session are
+// still accepted, and records that the session was used
+func (s *AuthSessionService) ValidateSession(ctx context.Context, sessionID string) error {
+	session, err := s.store.GetByID(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	if session == nil || !session.IsActive(now) {
+		return ErrSessionRevoked
+	}
+
+	if now.Sub(session.LastSeenAt) > authSessionTouchInterval {
+		if err := s.store.Touch(ctx, sessionID, now); err != nil {
+			fmt.Printf("Warning: failed to record use of session %s: %v\n", sessionID, err)
+		}
+	}
+	return nil
+}
+
+// DeleteExpiredSessions deletes sessions that expired longer ago than
+// AuthSessionHistory and returns how many were deleted
+func (s *AuthSessionService) DeleteExpiredSessions(ctx context.Context) (int, error) {
+	deleted, err := s.store.DeleteExpiredBefore(ctx, s.now().Add(-AuthSessionHistory))
+	return int(deleted), err
+}
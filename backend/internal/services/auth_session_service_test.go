@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAuthSessionStore struct {
+	sessions map[string]*models.AuthSession
+}
+
+func (s *fakeAuthSessionStore) Create(ctx context.Context, session *models.AuthSession) error {
+	copied := *session
+	s.sessions[session.ID] = &copied
+	return nil
+}
+
+func (s *fakeAuthSessionStore) GetByID(ctx context.Context, id string) (*models.AuthSession, error) {
+	session, exists := s.sessions[id]
+	if !exists {
+		return nil, nil
+	}
+	copied := *session
+	return &copied, nil
+}
+
+func (s *fakeAuthSessionStore) ListByUser(ctx context.Context, userID string, since time.Time) ([]*models.AuthSession, error) {
+	var sessions []*models.AuthSession
+	for _, session := range s.sessions {
+		if session.UserID == userID && session.CreatedAt.After(since) {
+			copied := *session
+			sessions = append(sessions, &copied)
+		}
+	}
+	return sessions, nil
+}
+
+func (s *fakeAuthSessionStore) Touch(ctx context.Context, id string, at time.Time) error {
+	s.sessions[id].LastSeenAt = at
+	return nil
+}
+
+func (s *fakeAuthSessionStore) Revoke(ctx context.Context, userID string, ids []string, at time.Time) (int64, error) {
+	var revoked int64
+	for _, id := range ids {
+		session, exists := s.sessions[id]
+		if exists && session.UserID == userID && session.RevokedAt == nil {
+			session.RevokedAt = &at
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+func (s *fakeAuthSessionStore) DeleteExpiredBefore(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	for id, session := range s.sessions {
+		if session.ExpiresAt.Before(before) {
+			delete(s.sessions, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func newTestAuthSessionService(now *time.Time) (*AuthSessionService, *fakeAuthSessionStore, *fakeMailer) {
+	store := &fakeAuthSessionStore{sessions: make(map[string]*models.AuthSession)}
+	m := &fakeMailer{}
+	service := NewAuthSessionService(store, m, 24*time.Hour)
+	service.SetGeoIPProvider(&fakeGeoIPProvider{locations: map[string]models.LatLng{
+		"81.2.69.160": {Lat: 51.5142, Lng: -0.0931}, // London
+		"81.2.69.161": {Lat: 51.4545, Lng: -2.5879}, // Bristol
+		"8.8.8.8":     {Lat: 37.751, Lng: -97.822},  // United States
+	}})
+	service.now = func() time.Time { return *now }
+	return service, store, m
+}
+
+func TestAuthSessionService_StartSession_NotifiesNewDeviceOrLocation(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	service, _, m := newTestAuthSessionService(&now)
+	email := "ada@example.com"
+	user := &models.User{ID: "user-1", Email: &email, DisplayName: "Ada"}
+	ctx := context.Background()
+
+	// The first sign-in of an account is expected
+	_, err := service.StartSession(ctx, user, "Firefox", net.ParseIP("81.2.69.160"))
+	require.NoError(t, err)
+	assert.Empty(t, m.sent)
+
+	// Same device from a nearby city
+	now = now.Add(time.Hour)
+	_, err = service.StartSession(ctx, user, "Firefox", net.ParseIP("81.2.69.161"))
+	require.NoError(t, err)
+	assert.Empty(t, m.sent)
+
+	// Same device from another continent
+	now = now.Add(time.Hour)
+	_, err = service.StartSession(ctx, user, "Firefox", net.ParseIP("8.8.8.8"))
+	require.NoError(t, err)
+	require.Len(t, m.sent, 1)
+	assert.Equal(t, []string{email}, m.sent[0].To)
+	assert.Contains(t, m.sent[0].Body, "8.8.8.8")
+
+	// New device from a known location
+	now = now.Add(time.Hour)
+	_, err = service.StartSession(ctx, user, "Safari", net.ParseIP("81.2.69.160"))
+	require.NoError(t, err)
+	assert.Len(t, m.sent, 2)
+}
+
+func TestAuthSessionService_RevokeSessions(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	service, _, _ := newTestAuthSessionService(&now)
+	user := &models.User{ID: "user-1", DisplayName: "Ada"}
+	ctx := context.Background()
+
+	current, err := service.StartSession(ctx, user, "Firefox", nil)
+	require.NoError(t, err)
+	other, err := service.StartSession(ctx, user, "Safari", nil)
+	require.NoError(t, err)
+	third, err := service.StartSession(ctx, user, "Chrome", nil)
+	require.NoError(t, err)
+
+	require.NoError(t, service.RevokeSession(ctx, user.ID, other.ID))
+	assert.True(t, errors.Is(service.ValidateSession(ctx, other.ID), ErrSessionRevoked))
+	assert.True(t, errors.Is(service.RevokeSession(ctx, user.ID, other.ID), ErrNotFound))
+	assert.True(t, errors.Is(service.RevokeSession(ctx, "user-2", third.ID), ErrNotFound))
+
+	revoked, err := service.RevokeOtherSessions(ctx, user.ID, current.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, revoked)
+
+	sessions, err := service.ListActiveSessions(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, current.ID, sessions[0].ID)
+	assert.NoError(t, service.ValidateSession(ctx, current.ID))
+}
+
+func TestAuthSessionService_ValidateSession_Expired(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	service, store, _ := newTestAuthSessionService(&now)
+	ctx := context.Background()
+
+	session, err := service.StartSession(ctx, &models.User{ID: "user-1"}, "Firefox", nil)
+	require.NoError(t, err)
+
+	now = now.Add(10 * time.Minute)
+	require.NoError(t, service.ValidateSession(ctx, session.ID))
+	assert.Equal(t, now, store.sessions[session.ID].LastSeenAt)
+
+	now = now.Add(24 * time.Hour)
+	assert.True(t, errors.Is(service.ValidateSession(ctx, session.ID), ErrSessionRevoked))
+	assert.True(t, errors.Is(service.ValidateSession(ctx, "unknown"), ErrSessionRevoked))
+}
+
+func TestAuthService_ValidateJWT_RevokedSession(t *testing.T) {
+	now := time.Now()
+	sessions, _, _ := newTestAuthSessionService(&now)
+	authService := NewAuthService("test-secret", time.Hour)
+	authService.SetSessionValidator(sessions)
+	ctx := context.Background()
+
+	session, err := sessions.StartSession(ctx, &models.User{ID: "user-1"}, "Firefox", nil)
+	require.NoError(t, err)
+	token, _, err := authService.GenerateSessionJWT("user-1", "ada@example.com", models.UserRoleUser, session.ID)
+	require.NoError(t, err)
+
+	claims, err := authService.ValidateJWT(token)
+	require.NoError(t, err)
+	assert.Equal(t, session.ID, claims.SessionID)
+
+	require.NoError(t, sessions.RevokeSession(ctx, "user-1", session.ID))
+	_, err = authService.ValidateJWT(token)
+	assert.True(t, errors.Is(err, ErrSessionRevoked))
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"breakoutglobe/internal/mailer"
+	"breakoutglobe/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// digestTopDiscussions is the number of busiest POIs listed in a digest
+const digestTopDiscussions = 3
+
+// DigestStore defines the interface for digest subscriptions and map activity
+type DigestStore interface {
+	UpsertSubscription(ctx context.Context, sub *models.DigestSubscription) (*models.DigestSubscription, error)
+	GetSubscription(ctx context.Context, mapID, userID string) (*models.DigestSubscription, error)
+	DeleteSubscription(ctx context.Context, mapID, userID string) error
+	ListSubscriptions(ctx context.Context) ([]*models.DigestSubscription, error)
+	MarkSent(ctx context.Context, id string, sentAt time.Time) error
+	GetMapActivity(ctx context.Context, mapID string, from, to time.Time) (*models.MapActivity, error)
+}
+
+// MapTrafficSource defines the interface for reading POI traffic of a map
+type MapTrafficSource interface {
+	GetHeatmap(ctx context.Context, mapID string, from, to time.Time) (*Heatmap, error)
+}
+
+// DigestService manages map activity digest subscriptions and emails due
+// digests to subscribed facilitators
+type DigestService struct {
+	store   DigestStore
+	mailer  mailer.Mailer
+	traffic MapTrafficSource
+	now     func() time.Time
+}
+
+// NewDigestService creates a new DigestService instance
+func NewDigestService(store DigestStore, m mailer.Mailer) *DigestService {
+	return &DigestService{
+		store:  store,
+		mailer: m,
+		now:    time.Now,
+	}
+}
+
+// SetTrafficSource sets the source of POI traffic used for the top discussions
+// of a digest. Without one, digests leave that section out.
+func (s *DigestService) SetTrafficSource(traffic MapTrafficSource) {
+	s.traffic = traffic
+}
+
+// Subscribe subscribes a user to digests of a map, or changes the frequency of an existing subscription
+func (s *DigestService) Subscribe(ctx context.Context, mapID, userID string, frequency models.DigestFrequency) (*models.DigestSubscription, error) {
+	sub, err := models.NewDigestSubscription(mapID, userID, frequency)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription: %w", err)
+	}
+
+	return s.store.UpsertSubscription(ctx, sub)
+}
+
+// GetSubscription returns the subscription of a user for a map
+func (s *DigestService) GetSubscription(ctx context.Context, mapID, userID string) (*models.DigestSubscription, error) {
+	sub, err := s.store.GetSubscription(ctx, mapID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("digest subscription %w", ErrNotFound)
+	}
+	return sub, err
+}
+
+// Unsubscribe removes the subscription of a user for a map
+func (s *DigestService) Unsubscribe(ctx context.Context, mapID, userID string) error {
+	err := s.store.DeleteSubscription(ctx, mapID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("digest subscription %w", ErrNotFound)
+	}
+	return err
+}
+
+// SendDueDigests emails every subscription whose period has passed and returns
+// the number of digests sent. A failing subscription doesn't stop the others.
+func (s *DigestService) SendDueDigests(ctx context.Context) (int, error) {
+	subs, err := s.store.ListSubscriptions(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := s.now()
+	sent := 0
+	for _, sub := range subs {
+		if !sub.IsDue(now) {
+			continue
+		}
+		if err := s.sendDigest(ctx, sub, now); err != nil {
+			fmt.Printf("Warning: failed to send %s digest of map %s to user %s: %v\n", sub.Frequency, sub.MapID, sub.UserID, err)
+			continue
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
+// sendDigest compiles and emails the digest of one subscription
+func (s *DigestService) sendDigest(ctx context.Context, sub *models.DigestSubscription, now time.Time) error {
+	if sub.User == nil || sub.User.Email == nil || *sub.User.Email == "" {
+		return fmt.Errorf("user has no email address")
+	}
+
+	from := now.Add(-sub.Frequency.Period())
+	if sub.LastSentAt != nil && sub.LastSentAt.After(from) {
+		from = *sub.LastSentAt
+	}
+
+	activity, err := s.store.GetMapActivity(ctx, sub.MapID, from, now)
+	if err != nil {
+		return err
+	}
+
+	var topDiscussions []POITraffic
+	if s.traffic != nil {
+		heatmap, err := s.traffic.GetHeatmap(ctx, sub.MapID, from, now)
+		if err != nil {
+			// The digest is still useful without traffic
+			fmt.Printf("Warning: failed to get POI traffic for digest: %v\n", err)
+		} else {
+			for _, poi := range heatmap.POIs {
+				if poi.Count == 0 || len(topDiscussions) == digestTopDiscussions {
+					break
+				}
+				topDiscussions = append(topDiscussions, poi)
+			}
+		}
+	}
+
+	mapName := sub.MapID
+	locale := models.DefaultMapLocale
+	if sub.Map != nil {
+		if sub.Map.Name != "" {
+			mapName = sub.Map.Name
+		}
+		locale = sub.Map.ContentLocale()
+	}
+
+	msg := mailer.Message{
+		To:      []string{*sub.User.Email},
+		Subject: emailText(locale, "digest.subject."+string(sub.Frequency), mapName),
+		Body:    buildDigestBody(locale, mapName, from, now, activity, topDiscussions),
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		return err
+	}
+
+	return s.store.MarkSent(ctx, sub.ID, now)
+}
+
+// buildDigestBody formats the plain text body of a digest email in a map locale
+func buildDigestBody(locale, mapName string, from, to time.Time, activity *models.MapActivity, topDiscussions []POITraffic) string {
+	const timeFormat = "Mon, 02 Jan 2006 15:04 MST"
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n", emailText(locale, "digest.activity", mapName))
+	fmt.Fprintf(&b, "%s – %s\n\n", from.UTC().Format(timeFormat), to.UTC().Format(timeFormat))
+	fmt.Fprintf(&b, "%s\n\n", emailText(locale, "digest.participants", activity.TotalParticipants))
+
+	fmt.Fprintf(&b, "%s\n", emailText(locale, "digest.new_pois", len(activity.NewPOIs)))
+	for _, poi := range activity.NewPOIs {
+		fmt.Fprintf(&b, "- %s\n", poi.Name)
+	}
+
+	if len(topDiscussions) > 0 {
+		fmt.Fprintf(&b, "\n%s\n", emailText(locale, "digest.top"))
+		for i, poi := range topDiscussions {
+			fmt.Fprintf(&b, "%d. %s (%s)\n", i+1, poi.Name, emailText(locale, "digest.avatar_updates", poi.Count))
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/mailer"
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeDigestStore keeps digest subscriptions in memory
+type fakeDigestStore struct {
+	subs     map[string]*models.DigestSubscription // mapID:userID -> subscription
+	activity *models.MapActivity
+	from, to time.Time
+}
+
+func newFakeDigestStore() *fakeDigestStore {
+	return &fakeDigestStore{
+		subs:     make(map[string]*models.DigestSubscription),
+		activity: &models.MapActivity{},
+	}
+}
+
+func (s *fakeDigestStore) UpsertSubscription(ctx context.Context, sub *models.DigestSubscription) (*models.DigestSubscription, error) {
+	key := sub.MapID + ":" + sub.UserID
+	if existing, exists := s.subs[key]; exists {
+		existing.Frequency = sub.Frequency
+		return existing, nil
+	}
+	s.subs[key] = sub
+	return sub, nil
+}
+
+func (s *fakeDigestStore) GetSubscription(ctx context.Context, mapID, userID string) (*models.DigestSubscription, error) {
+	sub, exists := s.subs[mapID+":"+userID]
+	if !exists {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return sub, nil
+}
+
+func (s *fakeDigestStore) DeleteSubscription(ctx context.Context, mapID, userID string) error {
+	if _, exists := s.subs[mapID+":"+userID]; !exists {
+		return gorm.ErrRecordNotFound
+	}
+	delete(s.subs, mapID+":"+userID)
+	return nil
+}
+
+func (s *fakeDigestStore) ListSubscriptions(ctx context.Context) ([]*models.DigestSubscription, error) {
+	var subs []*models.DigestSubscription
+	for _, sub := range s.subs {
+		subs = append(subs, sub)
+	}
+	return subs, nil
+}
+
+func (s *fakeDigestStore) MarkSent(ctx context.Context, id string, sentAt time.Time) error {
+	for _, sub := range s.subs {
+		if sub.ID == id {
+			sub.LastSentAt = &sentAt
+		}
+	}
+	return nil
+}
+
+func (s *fakeDigestStore) GetMapActivity(ctx context.Context, mapID string, from, to time.Time) (*models.MapActivity, error) {
+	s.from, s.to = from, to
+	return s.activity, nil
+}
+
+type fakeMailer struct {
+	sent []mailer.Message
+}
+
+func (m *fakeMailer) Send(ctx context.Context, msg mailer.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+type fakeTrafficSource struct {
+	pois []POITraffic
+}
+
+func (s *fakeTrafficSource) GetHeatmap(ctx context.Context, mapID string, from, to time.Time) (*Heatmap, error) {
+	return &Heatmap{MapID: mapID, POIs: s.pois}, nil
+}
+
+func newTestDigestService(now *time.Time) (*DigestService, *fakeDigestStore, *fakeMailer) {
+	store := newFakeDigestStore()
+	m := &fakeMailer{}
+	service := NewDigestService(store, m)
+	service.now = func() time.Time { return *now }
+	return service, store, m
+}
+
+func subscribeWithUser(t *testing.T, service *DigestService, store *fakeDigestStore, frequency models.DigestFrequency, email *string) *models.DigestSubscription {
+	sub, err := service.Subscribe(context.Background(), "map-1", "user-1", frequency)
+	require.NoError(t, err)
+	sub.User = &models.User{ID: "user-1", Email: email}
+	sub.Map = &models.Map{ID: "map-1", Name: "Summer Workshop"}
+	return sub
+}
+
+func TestDigestService_SendsDueDigests(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	service, store, m := newTestDigestService(&now)
+	service.SetTrafficSource(&fakeTrafficSource{pois: []POITraffic{
+		{POIID: "poi-1", Name: "Main stage", Count: 42},
+		{POIID: "poi-2", Name: "Coffee corner", Count: 7},
+		{POIID: "poi-3", Name: "Empty room", Count: 0},
+	}})
+
+	email := "facilitator@example.com"
+	subscribeWithUser(t, service, store, models.DigestFrequencyDaily, &email)
+	store.activity = &models.MapActivity{
+		NewPOIs:           []*models.POI{{Name: "Main stage"}},
+		TotalParticipants: 12,
+	}
+
+	sent, err := service.SendDueDigests(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, now.Add(-24*time.Hour), store.from)
+	assert.Equal(t, now, store.to)
+
+	require.Len(t, m.sent, 1)
+	msg := m.sent[0]
+	assert.Equal(t, []string{email}, msg.To)
+	assert.Equal(t, "Your daily digest for Summer Workshop", msg.Subject)
+	assert.Contains(t, msg.Body, "Participants: 12")
+	assert.Contains(t, msg.Body, "New POIs: 1\n- Main stage")
+	assert.Contains(t, msg.Body, "1. Main stage (42 avatar updates)")
+	assert.Contains(t, msg.Body, "2. Coffee corner (7 avatar updates)")
+	assert.NotContains(t, msg.Body, "Empty room")
+
+	// Not due again until a day has passed
+	sent, err = service.SendDueDigests(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	now = now.Add(24 * time.Hour)
+	sent, err = service.SendDueDigests(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, now.Add(-24*time.Hour), store.from)
+}
+
+func TestDigestService_UsesMapLocale(t *testing.T) {
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	service, store, m := newTestDigestService(&now)
+
+	email := "facilitator@example.com"
+	sub := subscribeWithUser(t, service, store, models.DigestFrequencyWeekly, &email)
+	sub.Map.Locale = "de"
+	store.activity = &models.MapActivity{TotalParticipants: 3}
+
+	_, err := service.SendDueDigests(context.Background())
+	require.NoError(t, err)
+	require.Len(t, m.sent, 1)
+	assert.Equal(t, "Ihre wöchentliche Zusammenfassung für Summer Workshop", m.sent[0].Subject)
+	assert.Contains(t, m.sent[0].Body, "Teilnehmende: 3")
+}
+
+func TestEmailTexts_CoverSupportedLocales(t *testing.T) {
+	for _, locale := range models.SupportedMapLocales {
+		for key := range emailTexts[models.DefaultMapLocale] {
+			assert.NotEmpty(t, emailTexts[locale][key], "%s has no %s text", locale, key)
+		}
+	}
+	assert.Equal(t, "Activity in Summer Workshop", emailText("xx", "digest.activity", "Summer Workshop"))
+}
+
+func TestDigestService_SkipsUsersWithoutEmail(t *testing.T) {
+	now := time.Now()
+	service, store, m := newTestDigestService(&now)
+	subscribeWithUser(t, service, store, models.DigestFrequencyWeekly, nil)
+
+	sent, err := service.SendDueDigests(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	assert.Empty(t, m.sent)
+}
+
+func TestDigestService_Subscribe(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	service, _, _ := newTestDigestService(&now)
+
+	_, err := service.Subscribe(ctx, "map-1", "user-1", "hourly")
+	assert.ErrorContains(t, err, "invalid subscription")
+
+	first, err := service.Subscribe(ctx, "map-1", "user-1", models.DigestFrequencyDaily)
+	require.NoError(t, err)
+
+	second, err := service.Subscribe(ctx, "map-1", "user-1", models.DigestFrequencyWeekly)
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, second.ID)
+	assert.Equal(t, models.DigestFrequencyWeekly, second.Frequency)
+
+	require.NoError(t, service.Unsubscribe(ctx, "map-1", "user-1"))
+	_, err = service.GetSubscription(ctx, "map-1", "user-1")
+	assert.ErrorContains(t, err, "not found")
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"fmt"
+
+	"breakoutglobe/internal/models"
+)
+
+// emailTexts are the texts of server-generated emails by map locale and key.
+// Every locale in models.SupportedMapLocales has all keys of the default locale.
+var emailTexts = map[string]map[string]string{
+	"en": {
+		"digest.subject.daily":     "Your daily digest for %s",
+		"digest.subject.weekly":    "Your weekly digest for %s",
+		"digest.activity":          "Activity in %s",
+		"digest.participants":      "Participants: %d",
+		"digest.new_pois":          "New POIs: %d",
+		"digest.top":               "Top discussions:",
+		"digest.avatar_updates":    "%d avatar updates",
+		"invitation.subject":       "You're invited to %s",
+		"invitation.intro":         "You have been invited to join %s on BreakoutGlobe.",
+		"invitation.link":          "Open your personal link to join. A guest profile has already been set up for you:",
+		"invitation.expiry":        "The link is valid until %s. Please don't share it.",
+		"map_deletion.subject":     "Deletion of %s requested",
+		"map_deletion.requested":   "%s requested deleting the map %s.",
+		"map_deletion.confirmable": "The deletion can be confirmed from %s until %s. If it isn't intended, cancel it before then.",
+	},
+	"de": {
+		"digest.subject.daily":     "Ihre tägliche Zusammenfassung für %s",
+		"digest.subject.weekly":    "Ihre wöchentliche Zusammenfassung für %s",
+		"digest.activity":          "Aktivität in %s",
+		"digest.participants":      "Teilnehmende: %d",
+		"digest.new_pois":          "Neue POIs: %d",
+		"digest.top":               "Meistbesuchte Diskussionen:",
+		"digest.avatar_updates":    "%d Avatar-Bewegungen",
+		"invitation.subject":       "Sie sind zu %s eingeladen",
+		"invitation.intro":         "Sie wurden eingeladen, %s auf BreakoutGlobe beizutreten.",
+		"invitation.link":          "Öffnen Sie Ihren persönlichen Link, um beizutreten. Ein Gastprofil wurde bereits für Sie eingerichtet:",
+		"invitation.expiry":        "Der Link ist gültig bis %s. Bitte teilen Sie ihn nicht.",
+		"map_deletion.subject":     "Löschung von %s angefordert",
+		"map_deletion.requested":   "%s hat die Löschung der Karte %s angefordert.",
+		"map_deletion.confirmable": "Die Löschung kann ab %s bis %s bestätigt werden. Falls sie nicht beabsichtigt ist, brechen Sie sie vorher ab.",
+	},
+	"fr": {
+		"digest.subject.daily":     "Votre résumé quotidien pour %s",
+		"digest.subject.weekly":    "Votre résumé hebdomadaire pour %s",
+		"digest.activity":          "Activité dans %s",
+		"digest.participants":      "Participants : %d",
+		"digest.new_pois":          "Nouveaux POI : %d",
+		"digest.top":               "Discussions les plus actives :",
+		"digest.avatar_updates":    "%d mises à jour d'avatar",
+		"invitation.subject":       "Vous êtes invité(e) à %s",
+		"invitation.intro":         "Vous avez été invité(e) à rejoindre %s sur BreakoutGlobe.",
+		"invitation.link":          "Ouvrez votre lien personnel pour nous rejoindre. Un profil invité a déjà été créé pour vous :",
+		"invitation.expiry":        "Le lien est valable jusqu'au %s. Merci de ne pas le partager.",
+		"map_deletion.subject":     "Suppression de %s demandée",
+		"map_deletion.requested":   "%s a demandé la suppression de la carte %s.",
+		"map_deletion.confirmable": "La suppression peut être confirmée à partir du %s jusqu'au %s. Si elle n'est pas voulue, annulez-la avant.",
+	},
+	"es": {
+		"digest.subject.daily":     "Tu resumen diario de %s",
+		"digest.subject.weekly":    "Tu resumen semanal de %s",
+		"digest.activity":          "Actividad en %s",
+		"digest.participants":      "Participantes: %d",
+		"digest.new_pois":          "Nuevos POI: %d",
+		"digest.top":               "Debates principales:",
+		"digest.avatar_updates":    "%d actualizaciones de avatar",
+		"invitation.subject":       "Estás invitado a %s",
+		"invitation.intro":         "Te han invitado a unirte a %s en BreakoutGlobe.",
+		"invitation.link":          "Abre tu enlace personal para unirte. Ya se ha creado un perfil de invitado para ti:",
+		"invitation.expiry":        "El enlace es válido hasta el %s. Por favor, no lo compartas.",
+		"map_deletion.subject":     "Eliminación de %s solicitada",
+		"map_deletion.requested":   "%s ha solicitado eliminar el mapa %s.",
+		"map_deletion.confirmable": "La eliminación puede confirmarse desde el %s hasta el %s. Si no es intencionada, cancélala antes.",
+	},
+}
+
+// emailText formats the text of an email in a map locale, falling back to
+// the default locale for unknown locales and keys
+func emailText(locale, key string, args ...interface{}) string {
+	text, exists := emailTexts[locale][key]
+	if !exists {
+		text = emailTexts[models.DefaultMapLocale][key]
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
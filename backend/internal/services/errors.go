@@ -0,0 +1,53 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors returned by services, wrapped with details. Handlers map them
+// to responses with errors.Is instead of matching error messages.
+var (
+	// ErrNotFound indicates that a requested resource does not exist
+	ErrNotFound = errors.New("not found")
+
+	// ErrDuplicateLocation indicates that a POI already exists at a position
+	ErrDuplicateLocation = errors.New("duplicate POI location")
+
+	// ErrCapacityExceeded indicates that a POI has no free seats left
+	ErrCapacityExceeded = errors.New("capacity exceeded")
+
+	// ErrAlreadyJoined indicates that a user already participates in a POI
+	ErrAlreadyJoined = errors.New("already joined")
+
+	// ErrInvalidInput indicates that request data failed validation
+	ErrInvalidInput = errors.New("invalid input")
+
+	// ErrSessionRevoked indicates that a token belongs to a revoked or expired sign-in
+	ErrSessionRevoked = errors.New("session has been revoked")
+
+	// ErrMapFrozen indicates that a facilitator froze the map, so avatars and POIs can't change
+	ErrMapFrozen = errors.New("map is frozen")
+
+	// ErrForbidden indicates that the caller may not change a resource
+	ErrForbidden = errors.New("forbidden")
+
+	// ErrDeletionCoolingDown indicates that a map deletion was confirmed before its cool-down passed
+	ErrDeletionCoolingDown = errors.New("map deletion is cooling down")
+)
+
+// CapacityBelowOccupancyError is returned when maxParticipants of a POI would
+// drop below the number of participants currently in it
+type CapacityBelowOccupancyError struct {
+	MaxParticipants int
+	CurrentCount    int
+}
+
+func (e *CapacityBelowOccupancyError) Error() string {
+	return fmt.Sprintf("max participants (%d) cannot be below the %d current participants", e.MaxParticipants, e.CurrentCount)
+}
+
+// Unwrap makes the error match ErrInvalidInput
+func (e *CapacityBelowOccupancyError) Unwrap() error {
+	return ErrInvalidInput
+}
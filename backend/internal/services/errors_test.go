@@ -0,0 +1,35 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPOIService_JoinPOI_ReturnsSentinelErrors(t *testing.T) {
+	repo := &benchPOIRepository{pois: map[string]*models.POI{
+		"poi-1": {ID: "poi-1", MapID: "map-1", MaxParticipants: 1},
+	}}
+	participants := &benchPOIParticipants{participants: make(map[string]map[string]bool)}
+	service := NewPOIService(repo, participants, &recordingPubSub{}, &benchUserService{})
+	ctx := context.Background()
+
+	require.NoError(t, service.JoinPOI(ctx, "poi-1", "user-1"))
+
+	err := service.JoinPOI(ctx, "poi-1", "user-1")
+	assert.ErrorIs(t, err, ErrAlreadyJoined)
+
+	err = service.JoinPOI(ctx, "poi-1", "user-2")
+	assert.ErrorIs(t, err, ErrCapacityExceeded)
+
+	err = service.JoinPOI(ctx, "missing", "user-1")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.EqualError(t, err, "POI not found: missing")
+
+	err = service.LeavePOI(ctx, "missing", "user-1")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"breakoutglobe/internal/models"
+)
+
+// GuestAnonymizationStore defines the interface for finding and anonymizing guests who left maps
+type GuestAnonymizationStore interface {
+	ListMapsWithGuestAnonymization(ctx context.Context) ([]*models.Map, error)
+	ListGuestsToAnonymize(ctx context.Context, mapID string, endedBefore time.Time) ([]string, error)
+	AnonymizeGuest(ctx context.Context, userID string, at time.Time) error
+}
+
+// GuestAnonymizer anonymizes guests once their retention window on a map with
+// guest anonymization passed after their last session there ended
+type GuestAnonymizer struct {
+	store GuestAnonymizationStore
+	now   func() time.Time
+}
+
+// NewGuestAnonymizer creates a new GuestAnonymizer instance
+func NewGuestAnonymizer(store GuestAnonymizationStore) *GuestAnonymizer {
+	return &GuestAnonymizer{
+		store: store,
+		now:   time.Now,
+	}
+}
+
+// Run anonymizes the guests due on every map with guest anonymization and
+// returns how many were anonymized
+func (a *GuestAnonymizer) Run(ctx context.Context) (int, error) {
+	maps, err := a.store.ListMapsWithGuestAnonymization(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := a.now()
+	anonymized := 0
+	for _, m := range maps {
+		userIDs, err := a.store.ListGuestsToAnonymize(ctx, m.ID, m.GuestAnonymization.Cutoff(now))
+		if err != nil {
+			return anonymized, err
+		}
+
+		for _, userID := range userIDs {
+			if err := a.store.AnonymizeGuest(ctx, userID, now); err != nil {
+				return anonymized, fmt.Errorf("failed to anonymize guests of map %s: %w", m.ID, err)
+			}
+			anonymized++
+		}
+	}
+
+	return anonymized, nil
+}
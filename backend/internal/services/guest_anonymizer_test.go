@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeGuestAnonymizationStore struct {
+	maps       []*models.Map
+	lastActive map[string]map[string]time.Time // mapID -> userID -> end of the last session
+	anonymized map[string]time.Time
+}
+
+func (s *fakeGuestAnonymizationStore) ListMapsWithGuestAnonymization(ctx context.Context) ([]*models.Map, error) {
+	var maps []*models.Map
+	for _, m := range s.maps {
+		if m.GuestAnonymization.Enabled {
+			maps = append(maps, m)
+		}
+	}
+	return maps, nil
+}
+
+func (s *fakeGuestAnonymizationStore) ListGuestsToAnonymize(ctx context.Context, mapID string, endedBefore time.Time) ([]string, error) {
+	var userIDs []string
+	for userID, ended := range s.lastActive[mapID] {
+		if _, done := s.anonymized[userID]; !done && ended.Before(endedBefore) {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	return userIDs, nil
+}
+
+func (s *fakeGuestAnonymizationStore) AnonymizeGuest(ctx context.Context, userID string, at time.Time) error {
+	s.anonymized[userID] = at
+	return nil
+}
+
+func TestGuestAnonymizer_Run(t *testing.T) {
+	now := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+	store := &fakeGuestAnonymizationStore{
+		maps: []*models.Map{
+			{ID: "workshop", GuestAnonymization: models.GuestAnonymization{Enabled: true, RetentionHours: 24}},
+			{ID: "office"},
+		},
+		lastActive: map[string]map[string]time.Time{
+			"workshop": {
+				"guest-left-2-days-ago": now.Add(-48 * time.Hour),
+				"guest-left-1-hour-ago": now.Add(-time.Hour),
+			},
+			"office": {"office-guest": now.Add(-48 * time.Hour)},
+		},
+		anonymized: map[string]time.Time{},
+	}
+	anonymizer := NewGuestAnonymizer(store)
+	anonymizer.now = func() time.Time { return now }
+
+	count, err := anonymizer.Run(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, count)
+	assert.Equal(t, map[string]time.Time{"guest-left-2-days-ago": now}, store.anonymized)
+
+	// Anonymized guests aren't picked up again
+	count, err = anonymizer.Run(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"breakoutglobe/internal/models"
+)
+
+// GuestRetentionStore defines the interface for finding and purging expired guests
+type GuestRetentionStore interface {
+	ListExpiredGuests(ctx context.Context, inactiveSince time.Time) ([]*models.User, error)
+	LastActiveAt(ctx context.Context, userID string) (time.Time, error)
+	HasContent(ctx context.Context, userID string) (bool, error)
+	PurgeGuest(ctx context.Context, userID string) error
+}
+
+// GuestAvatarStorage defines the storage operation needed to remove avatars of purged guests
+type GuestAvatarStorage interface {
+	DeleteOwnedFile(ctx context.Context, ownerID, key string) error
+}
+
+// GuestExpiry tells a guest when their profile is purged
+type GuestExpiry struct {
+	ExpiresAt     time.Time `json:"expiresAt"`
+	RetentionDays int       `json:"retentionDays"`
+}
+
+// GuestRetention purges guest profiles a number of days after they were last
+// active, unless they are connected or created POIs or maps. Guests are last
+// active when their latest session was, or when they were created if they
+// never had one.
+type GuestRetention struct {
+	store     GuestRetentionStore
+	avatars   GuestAvatarStorage
+	retention time.Duration
+	warning   time.Duration
+	now       func() time.Time
+}
+
+// NewGuestRetention creates a new GuestRetention instance keeping guests for
+// retentionDays and warning them warningDays before their profile expires
+func NewGuestRetention(store GuestRetentionStore, avatars GuestAvatarStorage, retentionDays, warningDays int) *GuestRetention {
+	return &GuestRetention{
+		store:     store,
+		avatars:   avatars,
+		retention: time.Duration(retentionDays) * 24 * time.Hour,
+		warning:   time.Duration(warningDays) * 24 * time.Hour,
+		now:       time.Now,
+	}
+}
+
+// Run purges the expired guests and their avatar files and returns how many
+// were purged
+func (r *GuestRetention) Run(ctx context.Context) (int, error) {
+	users, err := r.store.ListExpiredGuests(ctx, r.now().Add(-r.retention))
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, user := range users {
+		if err := r.store.PurgeGuest(ctx, user.ID); err != nil {
+			return purged, err
+		}
+		purged++
+
+		// The profile is gone either way, a leftover file is swept later
+		if user.AvatarURL != nil && r.avatars != nil {
+			if key := extractFileKeyFromURL(*user.AvatarURL); key != "" {
+				if err := r.avatars.DeleteOwnedFile(ctx, user.ID, key); err != nil {
+					log.Printf("⚠️ Failed to delete avatar of purged guest %s: %v", user.ID, err)
+				}
+			}
+		}
+	}
+
+	return purged, nil
+}
+
+// ExpiryWarning returns when a guest's profile expires if that is within the
+// warning window, or nil for full accounts, guests further from expiry and
+// guests kept for their content
+func (r *GuestRetention) ExpiryWarning(ctx context.Context, user *models.User) (*GuestExpiry, error) {
+	if user == nil || !user.IsGuest() {
+		return nil, nil
+	}
+
+	lastActive, err := r.store.LastActiveAt(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check guest expiry: %w", err)
+	}
+	if lastActive.Before(user.CreatedAt) {
+		lastActive = user.CreatedAt
+	}
+
+	expiresAt := lastActive.Add(r.retention)
+	if r.now().Before(expiresAt.Add(-r.warning)) {
+		return nil, nil
+	}
+
+	hasContent, err := r.store.HasContent(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check guest expiry: %w", err)
+	}
+	if hasContent {
+		return nil, nil
+	}
+
+	return &GuestExpiry{
+		ExpiresAt:     expiresAt,
+		RetentionDays: int(r.retention / (24 * time.Hour)),
+	}, nil
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeGuestRetentionStore struct {
+	users      []*models.User
+	active     map[string]bool
+	lastActive map[string]time.Time
+	content    map[string]bool
+	purged     []string
+}
+
+func (s *fakeGuestRetentionStore) ListExpiredGuests(ctx context.Context, inactiveSince time.Time) ([]*models.User, error) {
+	var users []*models.User
+	for _, user := range s.users {
+		if user.IsGuest() && user.CreatedAt.Before(inactiveSince) && s.lastActive[user.ID].Before(inactiveSince) && !s.active[user.ID] && !s.content[user.ID] {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (s *fakeGuestRetentionStore) LastActiveAt(ctx context.Context, userID string) (time.Time, error) {
+	return s.lastActive[userID], nil
+}
+
+func (s *fakeGuestRetentionStore) HasContent(ctx context.Context, userID string) (bool, error) {
+	return s.content[userID], nil
+}
+
+func (s *fakeGuestRetentionStore) PurgeGuest(ctx context.Context, userID string) error {
+	s.purged = append(s.purged, userID)
+	var kept []*models.User
+	for _, user := range s.users {
+		if user.ID != userID {
+			kept = append(kept, user)
+		}
+	}
+	s.users = kept
+	return nil
+}
+
+type fakeGuestAvatarStorage struct {
+	deleted []string
+}
+
+func (s *fakeGuestAvatarStorage) DeleteOwnedFile(ctx context.Context, ownerID, key string) error {
+	s.deleted = append(s.deleted, ownerID+":"+key)
+	return nil
+}
+
+func TestGuestRetention_Run(t *testing.T) {
+	now := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+	avatarURL := "http://localhost:8080/uploads/avatars/old-guest.png"
+	store := &fakeGuestRetentionStore{
+		users: []*models.User{
+			{ID: "old-guest", AccountType: models.AccountTypeGuest, AvatarURL: &avatarURL, CreatedAt: now.AddDate(0, 0, -31)},
+			{ID: "new-guest", AccountType: models.AccountTypeGuest, CreatedAt: now.AddDate(0, 0, -5)},
+			{ID: "returning-guest", AccountType: models.AccountTypeGuest, CreatedAt: now.AddDate(0, 0, -60)},
+			{ID: "connected-guest", AccountType: models.AccountTypeGuest, CreatedAt: now.AddDate(0, 0, -40)},
+			{ID: "poi-creator", AccountType: models.AccountTypeGuest, CreatedAt: now.AddDate(0, 0, -40)},
+			{ID: "member", AccountType: models.AccountTypeFull, CreatedAt: now.AddDate(0, 0, -40)},
+		},
+		active:     map[string]bool{"connected-guest": true},
+		lastActive: map[string]time.Time{"old-guest": now.AddDate(0, 0, -31), "returning-guest": now.AddDate(0, 0, -2)},
+		content:    map[string]bool{"poi-creator": true},
+	}
+	avatars := &fakeGuestAvatarStorage{}
+	retention := NewGuestRetention(store, avatars, 30, 7)
+	retention.now = func() time.Time { return now }
+
+	count, err := retention.Run(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, count)
+	assert.Equal(t, []string{"old-guest"}, store.purged)
+	assert.Equal(t, []string{"old-guest:avatars/old-guest.png"}, avatars.deleted)
+}
+
+func TestGuestRetention_ExpiryWarning(t *testing.T) {
+	now := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+	store := &fakeGuestRetentionStore{
+		lastActive: map[string]time.Time{"inactive-guest": now.AddDate(0, 0, -24), "returning-guest": now.AddDate(0, 0, -1)},
+		content:    map[string]bool{"poi-creator": true},
+	}
+	retention := NewGuestRetention(store, nil, 30, 7)
+	retention.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	expiring := &models.User{ID: "guest", AccountType: models.AccountTypeGuest, CreatedAt: now.AddDate(0, 0, -25)}
+	expiry, err := retention.ExpiryWarning(ctx, expiring)
+	require.NoError(t, err)
+	require.NotNil(t, expiry)
+	assert.Equal(t, now.AddDate(0, 0, 5), expiry.ExpiresAt)
+	assert.Equal(t, 30, expiry.RetentionDays)
+
+	// Expiry is measured from the latest session
+	inactive := &models.User{ID: "inactive-guest", AccountType: models.AccountTypeGuest, CreatedAt: now.AddDate(0, 0, -90)}
+	expiry, err = retention.ExpiryWarning(ctx, inactive)
+	require.NoError(t, err)
+	require.NotNil(t, expiry)
+	assert.Equal(t, now.AddDate(0, 0, 6), expiry.ExpiresAt)
+
+	// Guests further from expiry, guests active recently, guests kept for their content and full accounts aren't warned
+	for _, user := range []*models.User{
+		{ID: "new-guest", AccountType: models.AccountTypeGuest, CreatedAt: now.AddDate(0, 0, -10)},
+		{ID: "returning-guest", AccountType: models.AccountTypeGuest, CreatedAt: now.AddDate(0, 0, -90)},
+		{ID: "poi-creator", AccountType: models.AccountTypeGuest, CreatedAt: now.AddDate(0, 0, -25)},
+		{ID: "member", AccountType: models.AccountTypeFull, CreatedAt: now.AddDate(0, 0, -25)},
+	} {
+		expiry, err := retention.ExpiryWarning(ctx, user)
+		require.NoError(t, err)
+		assert.Nil(t, expiry, user.ID)
+	}
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/redis"
+)
+
+// Actions of hand queue updates
+const (
+	HandRaised  = "raised"
+	HandLowered = "lowered"
+)
+
+// HandQueueStore defines the interface for storing the raise-hand queue of maps
+type HandQueueStore interface {
+	RaiseHand(ctx context.Context, mapID, userID string, at time.Time) (bool, error)
+	LowerHand(ctx context.Context, mapID, userID string) (bool, error)
+	GetHands(ctx context.Context, mapID string) ([]models.RaisedHand, error)
+}
+
+// HandQueuePublisher defines the interface for telling all instances about hand queue updates
+type HandQueuePublisher interface {
+	PublishHandQueueUpdated(ctx context.Context, event redis.HandQueueEvent) error
+}
+
+// HandRaiseService keeps an ordered raise-hand queue per map, so facilitators
+// can call on participants in the order they asked to speak. The queue is
+// stored once for all instances and every change is published with the whole
+// queue.
+type HandRaiseService struct {
+	store     HandQueueStore
+	publisher HandQueuePublisher
+	now       func() time.Time
+}
+
+// NewHandRaiseService creates a new HandRaiseService instance
+func NewHandRaiseService(store HandQueueStore, publisher HandQueuePublisher) *HandRaiseService {
+	return &HandRaiseService{
+		store:     store,
+		publisher: publisher,
+		now:       time.Now,
+	}
+}
+
+// RaiseHand puts a user at the end of a map's queue. It returns false if the
+// user's hand was already raised, which keeps their place.
+func (s *HandRaiseService) RaiseHand(ctx context.Context, mapID, userID string) (bool, error) {
+	if mapID == "" || userID == "" {
+		return false, fmt.Errorf("%w: map ID and user ID are required", ErrInvalidInput)
+	}
+
+	raised, err := s.store.RaiseHand(ctx, mapID, userID, s.now())
+	if err != nil || !raised {
+		return false, err
+	}
+
+	return true, s.publish(ctx, redis.HandQueueEvent{MapID: mapID, UserID: userID, Action: HandRaised})
+}
+
+// LowerHand takes a user out of a map's queue, either because they lowered
+// their hand or because a facilitator (loweredBy) called on them. It returns
+// false if the user's hand wasn't raised.
+func (s *HandRaiseService) LowerHand(ctx context.Context, mapID, userID, loweredBy string) (bool, error) {
+	if mapID == "" || userID == "" {
+		return false, fmt.Errorf("%w: map ID and user ID are required", ErrInvalidInput)
+	}
+
+	lowered, err := s.store.LowerHand(ctx, mapID, userID)
+	if err != nil || !lowered {
+		return false, err
+	}
+
+	event := redis.HandQueueEvent{MapID: mapID, UserID: userID, Action: HandLowered}
+	if loweredBy != userID {
+		event.LoweredBy = loweredBy
+	}
+	return true, s.publish(ctx, event)
+}
+
+// GetHands returns the raised hands of a map, the earliest first
+func (s *HandRaiseService) GetHands(ctx context.Context, mapID string) ([]models.RaisedHand, error) {
+	return s.store.GetHands(ctx, mapID)
+}
+
+// publish tells all instances about a queue change, with the queue after it
+func (s *HandRaiseService) publish(ctx context.Context, event redis.HandQueueEvent) error {
+	hands, err := s.store.GetHands(ctx, event.MapID)
+	if err != nil {
+		return err
+	}
+	event.Hands = hands
+	event.Timestamp = s.now()
+
+	if err := s.publisher.PublishHandQueueUpdated(ctx, event); err != nil {
+		return fmt.Errorf("failed to publish hand queue updated event: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/redis"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeHandQueueStore struct {
+	hands map[string][]models.RaisedHand
+}
+
+func (s *fakeHandQueueStore) RaiseHand(ctx context.Context, mapID, userID string, at time.Time) (bool, error) {
+	for _, hand := range s.hands[mapID] {
+		if hand.UserID == userID {
+			return false, nil
+		}
+	}
+	s.hands[mapID] = append(s.hands[mapID], models.RaisedHand{UserID: userID, RaisedAt: at})
+	return true, nil
+}
+
+func (s *fakeHandQueueStore) LowerHand(ctx context.Context, mapID, userID string) (bool, error) {
+	for i, hand := range s.hands[mapID] {
+		if hand.UserID == userID {
+			s.hands[mapID] = append(s.hands[mapID][:i:i], s.hands[mapID][i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *fakeHandQueueStore) GetHands(ctx context.Context, mapID string) ([]models.RaisedHand, error) {
+	return append([]models.RaisedHand{}, s.hands[mapID]...), nil
+}
+
+type recordingHandQueuePublisher struct {
+	events []redis.HandQueueEvent
+}
+
+func (p *recordingHandQueuePublisher) PublishHandQueueUpdated(ctx context.Context, event redis.HandQueueEvent) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func TestHandRaiseService_RaiseAndLower(t *testing.T) {
+	publisher := &recordingHandQueuePublisher{}
+	service := NewHandRaiseService(&fakeHandQueueStore{hands: make(map[string][]models.RaisedHand)}, publisher)
+	now := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	raised, err := service.RaiseHand(ctx, "map-1", "user-1")
+	require.NoError(t, err)
+	assert.True(t, raised)
+	now = now.Add(time.Second)
+	_, err = service.RaiseHand(ctx, "map-1", "user-2")
+	require.NoError(t, err)
+
+	// Raising again neither moves the hand nor publishes an update
+	raised, err = service.RaiseHand(ctx, "map-1", "user-1")
+	require.NoError(t, err)
+	assert.False(t, raised)
+	require.Len(t, publisher.events, 2)
+	assert.Equal(t, HandRaised, publisher.events[1].Action)
+	assert.Equal(t, []string{"user-1", "user-2"}, []string{publisher.events[1].Hands[0].UserID, publisher.events[1].Hands[1].UserID})
+
+	// A facilitator calls on the first hand
+	lowered, err := service.LowerHand(ctx, "map-1", "user-1", "facilitator-1")
+	require.NoError(t, err)
+	assert.True(t, lowered)
+	require.Len(t, publisher.events, 3)
+	assert.Equal(t, HandLowered, publisher.events[2].Action)
+	assert.Equal(t, "facilitator-1", publisher.events[2].LoweredBy)
+	assert.Equal(t, []models.RaisedHand{{UserID: "user-2", RaisedAt: now}}, publisher.events[2].Hands)
+
+	lowered, err = service.LowerHand(ctx, "map-1", "user-2", "user-2")
+	require.NoError(t, err)
+	assert.True(t, lowered)
+	assert.Empty(t, publisher.events[3].LoweredBy)
+
+	hands, err := service.GetHands(ctx, "map-1")
+	require.NoError(t, err)
+	assert.Empty(t, hands)
+
+	_, err = service.RaiseHand(ctx, "map-1", "")
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
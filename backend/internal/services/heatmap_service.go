@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/redis"
+)
+
+// DefaultHeatmapRange is the time range of a heatmap request without a start time
+const DefaultHeatmapRange = 24 * time.Hour
+
+// MaxHeatmapRange is the longest time range a heatmap can be requested for
+const MaxHeatmapRange = 90 * 24 * time.Hour
+
+// HeatmapCounter defines the interface for counting avatar positions per grid cell
+type HeatmapCounter interface {
+	IncrementCell(ctx context.Context, bucket redis.HeatmapBucket, row, col int) error
+	GetPendingBuckets(ctx context.Context) ([]redis.HeatmapBucket, error)
+	GetBucketCounts(ctx context.Context, bucket redis.HeatmapBucket) ([]redis.HeatmapCellCount, error)
+	DeleteBucket(ctx context.Context, bucket redis.HeatmapBucket) error
+}
+
+// HeatmapRepository defines the interface for aggregated heatmap storage
+type HeatmapRepository interface {
+	AddCounts(ctx context.Context, cells []*models.MapHeatCell) error
+	GetCellTotals(ctx context.Context, mapID string, from, to time.Time) ([]models.HeatCellTotal, error)
+}
+
+// HeatmapPOILister defines the interface for listing the POIs of a map
+type HeatmapPOILister interface {
+	GetPOIsForMap(ctx context.Context, mapID string) ([]*models.POI, error)
+}
+
+// HeatmapCell is the traffic of one grid cell
+type HeatmapCell struct {
+	Position models.LatLng `json:"position"`
+	Count    int64         `json:"count"`
+}
+
+// POITraffic is the traffic of the grid cell a POI is placed in
+type POITraffic struct {
+	POIID string `json:"poiId"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// Heatmap is the avatar traffic of a map over a time range
+type Heatmap struct {
+	MapID    string        `json:"mapId"`
+	From     time.Time     `json:"from"`
+	To       time.Time     `json:"to"`
+	CellSize float64       `json:"cellSize"`
+	Cells    []HeatmapCell `json:"cells"`
+	POIs     []POITraffic  `json:"pois"`
+}
+
+// HeatmapService records avatar positions and aggregates them into a per-cell
+// heat grid. Positions are counted in Redis and rolled up into the database by
+// the scheduler once their time bucket is complete.
+type HeatmapService struct {
+	counter HeatmapCounter
+	repo    HeatmapRepository
+	pois    HeatmapPOILister
+	now     func() time.Time
+}
+
+// NewHeatmapService creates a new HeatmapService instance
+func NewHeatmapService(counter HeatmapCounter, repo HeatmapRepository, pois HeatmapPOILister) *HeatmapService {
+	return &HeatmapService{
+		counter: counter,
+		repo:    repo,
+		pois:    pois,
+		now:     time.Now,
+	}
+}
+
+// RecordPosition counts an avatar position in the current time bucket
+func (s *HeatmapService) RecordPosition(ctx context.Context, mapID string, position models.LatLng) error {
+	row, col := models.HeatCellFor(position)
+	bucket := redis.HeatmapBucket{MapID: mapID, BucketStart: models.HeatmapBucketFor(s.now())}
+	return s.counter.IncrementCell(ctx, bucket, row, col)
+}
+
+// RollUp moves the counts of completed time buckets into the database and
+// returns the number of buckets rolled up. The current bucket is left alone
+// since it is still being counted.
+func (s *HeatmapService) RollUp(ctx context.Context) (int, error) {
+	buckets, err := s.counter.GetPendingBuckets(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	current := models.HeatmapBucketFor(s.now())
+	rolledUp := 0
+	for _, bucket := range buckets {
+		if !bucket.BucketStart.Before(current) {
+			continue
+		}
+
+		counts, err := s.counter.GetBucketCounts(ctx, bucket)
+		if err != nil {
+			return rolledUp, err
+		}
+
+		cells := make([]*models.MapHeatCell, 0, len(counts))
+		for _, count := range counts {
+			cells = append(cells, &models.MapHeatCell{
+				MapID:       bucket.MapID,
+				BucketStart: bucket.BucketStart,
+				CellRow:     count.Row,
+				CellCol:     count.Col,
+				Count:       count.Count,
+			})
+		}
+
+		if err := s.repo.AddCounts(ctx, cells); err != nil {
+			return rolledUp, err
+		}
+		if err := s.counter.DeleteBucket(ctx, bucket); err != nil {
+			return rolledUp, err
+		}
+		rolledUp++
+	}
+
+	return rolledUp, nil
+}
+
+// GetHeatmap returns the traffic of a map over [from, to). A zero to defaults to
+// now and a zero from to DefaultHeatmapRange before to.
+func (s *HeatmapService) GetHeatmap(ctx context.Context, mapID string, from, to time.Time) (*Heatmap, error) {
+	if mapID == "" {
+		return nil, fmt.Errorf("map ID is required")
+	}
+	if to.IsZero() {
+		to = s.now()
+	}
+	if from.IsZero() {
+		from = to.Add(-DefaultHeatmapRange)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("invalid time range: from must be before to")
+	}
+	if to.Sub(from) > MaxHeatmapRange {
+		return nil, fmt.Errorf("invalid time range: must not exceed %d days", int(MaxHeatmapRange.Hours()/24))
+	}
+
+	// Include the bucket that contains from
+	totals, err := s.repo.GetCellTotals(ctx, mapID, models.HeatmapBucketFor(from), to)
+	if err != nil {
+		return nil, err
+	}
+
+	heatmap := &Heatmap{
+		MapID:    mapID,
+		From:     from,
+		To:       to,
+		CellSize: models.HeatmapCellSize,
+		Cells:    make([]HeatmapCell, 0, len(totals)),
+		POIs:     []POITraffic{},
+	}
+
+	cellCounts := make(map[[2]int]int64, len(totals))
+	for _, total := range totals {
+		cellCounts[[2]int{total.CellRow, total.CellCol}] = total.Count
+		heatmap.Cells = append(heatmap.Cells, HeatmapCell{
+			Position: models.HeatCellCenter(total.CellRow, total.CellCol),
+			Count:    total.Count,
+		})
+	}
+
+	if s.pois != nil {
+		pois, err := s.pois.GetPOIsForMap(ctx, mapID)
+		if err != nil {
+			// The grid is still useful without POI totals
+			fmt.Printf("Warning: failed to get POIs for heatmap: %v\n", err)
+		}
+		for _, poi := range pois {
+			row, col := models.HeatCellFor(poi.Position)
+			heatmap.POIs = append(heatmap.POIs, POITraffic{
+				POIID: poi.ID,
+				Name:  poi.Name,
+				Count: cellCounts[[2]int{row, col}],
+			})
+		}
+		sort.SliceStable(heatmap.POIs, func(i, j int) bool {
+			return heatmap.POIs[i].Count > heatmap.POIs[j].Count
+		})
+	}
+
+	return heatmap, nil
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/redis"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeHeatmapCounter keeps raw heatmap counts in memory
+type fakeHeatmapCounter struct {
+	buckets map[redis.HeatmapBucket]map[[2]int]int64
+}
+
+func newFakeHeatmapCounter() *fakeHeatmapCounter {
+	return &fakeHeatmapCounter{buckets: make(map[redis.HeatmapBucket]map[[2]int]int64)}
+}
+
+func (c *fakeHeatmapCounter) IncrementCell(ctx context.Context, bucket redis.HeatmapBucket, row, col int) error {
+	if c.buckets[bucket] == nil {
+		c.buckets[bucket] = make(map[[2]int]int64)
+	}
+	c.buckets[bucket][[2]int{row, col}]++
+	return nil
+}
+
+func (c *fakeHeatmapCounter) GetPendingBuckets(ctx context.Context) ([]redis.HeatmapBucket, error) {
+	var buckets []redis.HeatmapBucket
+	for bucket := range c.buckets {
+		buckets = append(buckets, bucket)
+	}
+	return buckets, nil
+}
+
+func (c *fakeHeatmapCounter) GetBucketCounts(ctx context.Context, bucket redis.HeatmapBucket) ([]redis.HeatmapCellCount, error) {
+	var counts []redis.HeatmapCellCount
+	for cell, count := range c.buckets[bucket] {
+		counts = append(counts, redis.HeatmapCellCount{Row: cell[0], Col: cell[1], Count: count})
+	}
+	return counts, nil
+}
+
+func (c *fakeHeatmapCounter) DeleteBucket(ctx context.Context, bucket redis.HeatmapBucket) error {
+	delete(c.buckets, bucket)
+	return nil
+}
+
+// fakeHeatmapRepository keeps rolled up heatmap cells in memory
+type fakeHeatmapRepository struct {
+	cells []*models.MapHeatCell
+}
+
+func (r *fakeHeatmapRepository) AddCounts(ctx context.Context, cells []*models.MapHeatCell) error {
+	r.cells = append(r.cells, cells...)
+	return nil
+}
+
+func (r *fakeHeatmapRepository) GetCellTotals(ctx context.Context, mapID string, from, to time.Time) ([]models.HeatCellTotal, error) {
+	totals := make(map[[2]int]int64)
+	var order [][2]int
+	for _, cell := range r.cells {
+		if cell.MapID != mapID || cell.BucketStart.Before(from) || !cell.BucketStart.Before(to) {
+			continue
+		}
+		key := [2]int{cell.CellRow, cell.CellCol}
+		if _, exists := totals[key]; !exists {
+			order = append(order, key)
+		}
+		totals[key] += cell.Count
+	}
+
+	var result []models.HeatCellTotal
+	for _, key := range order {
+		result = append(result, models.HeatCellTotal{CellRow: key[0], CellCol: key[1], Count: totals[key]})
+	}
+	return result, nil
+}
+
+type fakeHeatmapPOILister struct {
+	pois []*models.POI
+	err  error
+}
+
+func (l *fakeHeatmapPOILister) GetPOIsForMap(ctx context.Context, mapID string) ([]*models.POI, error) {
+	return l.pois, l.err
+}
+
+func newTestHeatmapService(now *time.Time, pois HeatmapPOILister) (*HeatmapService, *fakeHeatmapCounter, *fakeHeatmapRepository) {
+	counter := newFakeHeatmapCounter()
+	repo := &fakeHeatmapRepository{}
+	service := NewHeatmapService(counter, repo, pois)
+	service.now = func() time.Time { return *now }
+	return service, counter, repo
+}
+
+func TestHeatmapService_RollUpSkipsCurrentBucket(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+	service, counter, repo := newTestHeatmapService(&now, nil)
+
+	position := models.LatLng{Lat: 52.5201, Lng: 13.4051}
+	require.NoError(t, service.RecordPosition(ctx, "map-1", position))
+	require.NoError(t, service.RecordPosition(ctx, "map-1", position))
+
+	rolledUp, err := service.RollUp(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, rolledUp)
+	assert.Empty(t, repo.cells)
+
+	now = now.Add(time.Hour)
+	rolledUp, err = service.RollUp(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, rolledUp)
+	assert.Empty(t, counter.buckets)
+
+	require.Len(t, repo.cells, 1)
+	row, col := models.HeatCellFor(position)
+	assert.Equal(t, &models.MapHeatCell{
+		MapID:       "map-1",
+		BucketStart: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		CellRow:     row,
+		CellCol:     col,
+		Count:       2,
+	}, repo.cells[0])
+}
+
+func TestHeatmapService_GetHeatmap(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+
+	busy := models.LatLng{Lat: 52.5201, Lng: 13.4051}
+	quiet := models.LatLng{Lat: 48.1371, Lng: 11.5754}
+	pois := &fakeHeatmapPOILister{pois: []*models.POI{
+		{ID: "poi-quiet", Name: "Quiet corner", Position: quiet},
+		{ID: "poi-busy", Name: "Main stage", Position: busy},
+	}}
+	service, _, _ := newTestHeatmapService(&now, pois)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, service.RecordPosition(ctx, "map-1", busy))
+	}
+	require.NoError(t, service.RecordPosition(ctx, "map-1", quiet))
+	require.NoError(t, service.RecordPosition(ctx, "map-2", quiet))
+
+	now = now.Add(time.Hour)
+	_, err := service.RollUp(ctx)
+	require.NoError(t, err)
+
+	heatmap, err := service.GetHeatmap(ctx, "map-1", time.Time{}, time.Time{})
+	require.NoError(t, err)
+
+	assert.Equal(t, now.Add(-DefaultHeatmapRange), heatmap.From)
+	assert.Equal(t, now, heatmap.To)
+	require.Len(t, heatmap.Cells, 2)
+	assert.Equal(t, int64(4), heatmap.Cells[0].Count+heatmap.Cells[1].Count)
+
+	require.Len(t, heatmap.POIs, 2)
+	assert.Equal(t, POITraffic{POIID: "poi-busy", Name: "Main stage", Count: 3}, heatmap.POIs[0])
+	assert.Equal(t, POITraffic{POIID: "poi-quiet", Name: "Quiet corner", Count: 1}, heatmap.POIs[1])
+}
+
+func TestHeatmapService_GetHeatmap_WithoutPOIs(t *testing.T) {
+	now := time.Now()
+	service, _, _ := newTestHeatmapService(&now, &fakeHeatmapPOILister{err: fmt.Errorf("database unavailable")})
+
+	heatmap, err := service.GetHeatmap(context.Background(), "map-1", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, heatmap.Cells)
+	assert.Empty(t, heatmap.POIs)
+}
+
+func TestHeatmapService_GetHeatmap_InvalidRange(t *testing.T) {
+	now := time.Now()
+	service, _, _ := newTestHeatmapService(&now, nil)
+
+	_, err := service.GetHeatmap(context.Background(), "map-1", now, now.Add(-time.Hour))
+	assert.ErrorContains(t, err, "invalid time range")
+
+	_, err = service.GetHeatmap(context.Background(), "map-1", now.Add(-MaxHeatmapRange-time.Hour), now)
+	assert.ErrorContains(t, err, "invalid time range")
+
+	_, err = service.GetHeatmap(context.Background(), "", time.Time{}, time.Time{})
+	assert.ErrorContains(t, err, "map ID is required")
+}
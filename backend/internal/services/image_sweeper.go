@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"breakoutglobe/internal/storage"
+)
+
+// ImageSweepGracePeriod is how long a stored image may go unreferenced before
+// it is swept. Images are stored before the row referencing them is saved.
+const ImageSweepGracePeriod = time.Hour
+
+// DefaultImageSweepLimit bounds the images deleted in one sweep, so a wrong
+// reference list can't wipe out the whole storage at once
+const DefaultImageSweepLimit = 500
+
+// imageSweepPrefixes are the storage keys the sweeper looks at: processed POI
+// images, POI images from the legacy uploader and deduplicated blobs
+var imageSweepPrefixes = []string{"pois/", "poi-", "blobs/"}
+
+// ImageSweepStorage defines the storage operations needed to sweep orphaned images
+type ImageSweepStorage interface {
+	ListFiles(ctx context.Context, prefix string) ([]storage.StoredFile, error)
+	DeleteFile(ctx context.Context, key string) error
+}
+
+// ImageReferenceSource defines the interface for listing image URLs still referenced in the database
+type ImageReferenceSource interface {
+	ListImageURLs(ctx context.Context) ([]string, error)
+}
+
+// ImageSweeper removes stored images that no POI, user or map snapshot
+// references anymore, such as images of deleted POIs and blobs left behind by
+// replaced uploads. Images are matched by storage key, so references stay
+// valid when the base URL of the storage changes.
+type ImageSweeper struct {
+	storage    ImageSweepStorage
+	references ImageReferenceSource
+	dryRun     bool
+	limit      int
+	now        func() time.Time
+}
+
+// NewImageSweeper creates a new ImageSweeper instance
+func NewImageSweeper(storage ImageSweepStorage, references ImageReferenceSource) *ImageSweeper {
+	return &ImageSweeper{
+		storage:    storage,
+		references: references,
+		limit:      DefaultImageSweepLimit,
+		now:        time.Now,
+	}
+}
+
+// SetDryRun makes sweeps only count the orphaned images instead of deleting them
+func (s *ImageSweeper) SetDryRun(dryRun bool) {
+	s.dryRun = dryRun
+}
+
+// SetLimit bounds the images deleted in one sweep; unbounded if 0. The rest
+// are deleted by the next sweeps.
+func (s *ImageSweeper) SetLimit(limit int) {
+	s.limit = limit
+}
+
+// Sweep deletes orphaned images and returns how many were deleted, or would
+// have been in a dry run
+func (s *ImageSweeper) Sweep(ctx context.Context) (int, error) {
+	urls, err := s.references.ListImageURLs(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list image references: %w", err)
+	}
+
+	referenced := make(map[string]bool, len(urls))
+	for _, url := range urls {
+		if key := extractFileKeyFromURL(url); key != "" {
+			referenced[key] = true
+		}
+	}
+
+	cutoff := s.now().Add(-ImageSweepGracePeriod)
+	deleted := 0
+	for _, prefix := range imageSweepPrefixes {
+		files, err := s.storage.ListFiles(ctx, prefix)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to list stored images: %w", err)
+		}
+
+		for _, file := range files {
+			if file.ModifiedAt.After(cutoff) || referenced[file.Key] {
+				continue
+			}
+			if s.limit > 0 && deleted >= s.limit {
+				return deleted, nil
+			}
+			if s.dryRun {
+				fmt.Printf("Image sweep dry run: would delete %s\n", file.Key)
+				deleted++
+				continue
+			}
+			if err := s.storage.DeleteFile(ctx, file.Key); err != nil {
+				return deleted, fmt.Errorf("failed to delete orphaned image %s: %w", file.Key, err)
+			}
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
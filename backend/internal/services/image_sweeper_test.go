@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSweepStorage struct {
+	files   []storage.StoredFile
+	deleted []string
+}
+
+func (f *fakeSweepStorage) ListFiles(ctx context.Context, prefix string) ([]storage.StoredFile, error) {
+	var files []storage.StoredFile
+	for _, file := range f.files {
+		if strings.HasPrefix(file.Key, prefix) {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+func (f *fakeSweepStorage) DeleteFile(ctx context.Context, key string) error {
+	f.deleted = append(f.deleted, key)
+	return nil
+}
+
+type fakeImageReferences []string
+
+func (f fakeImageReferences) ListImageURLs(ctx context.Context) ([]string, error) {
+	return f, nil
+}
+
+func TestImageSweeper_DeletesOnlyOldUnreferencedImages(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-2 * ImageSweepGracePeriod)
+
+	fileStorage := &fakeSweepStorage{files: []storage.StoredFile{
+		{Key: "pois/kept-thumb.jpg", ModifiedAt: old},
+		{Key: "pois/deleted-thumb.jpg", ModifiedAt: old},
+		{Key: "poi-legacy-123.png", ModifiedAt: old},
+		{Key: "blobs/ab/abcdef.jpg", ModifiedAt: old},
+		{Key: "blobs/cd/cdef01.jpg", ModifiedAt: now.Add(-time.Minute)}, // Upload in progress
+		{Key: "avatars/user-1_123.jpg", ModifiedAt: old},                // Not a POI image
+	}}
+	// References are matched by key, whatever base URL they were stored with
+	references := fakeImageReferences{
+		"https://example.com/uploads/pois/kept-thumb.jpg",
+		"http://localhost:8080/uploads/blobs/ab/abcdef.jpg",
+	}
+
+	sweeper := NewImageSweeper(fileStorage, references)
+	sweeper.now = func() time.Time { return now }
+
+	deleted, err := sweeper.Sweep(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+	assert.ElementsMatch(t, []string{"pois/deleted-thumb.jpg", "poi-legacy-123.png"}, fileStorage.deleted)
+}
+
+func TestImageSweeper_DryRunAndLimit(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-2 * ImageSweepGracePeriod)
+	fileStorage := &fakeSweepStorage{files: []storage.StoredFile{
+		{Key: "pois/a.jpg", ModifiedAt: old},
+		{Key: "pois/b.jpg", ModifiedAt: old},
+		{Key: "pois/c.jpg", ModifiedAt: old},
+	}}
+
+	sweeper := NewImageSweeper(fileStorage, fakeImageReferences{})
+	sweeper.now = func() time.Time { return now }
+
+	sweeper.SetDryRun(true)
+	deleted, err := sweeper.Sweep(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, deleted)
+	assert.Empty(t, fileStorage.deleted)
+
+	sweeper.SetDryRun(false)
+	sweeper.SetLimit(2)
+	deleted, err = sweeper.Sweep(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+	assert.Len(t, fileStorage.deleted, 2)
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"breakoutglobe/internal/mailer"
+	"breakoutglobe/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// MaxInvitationEmails is the maximum number of addresses invited in one request
+const MaxInvitationEmails = 200
+
+// InvitationStore defines the interface for storing map invitations
+type InvitationStore interface {
+	Save(ctx context.Context, invitation *models.Invitation) error
+	GetByMapAndEmail(ctx context.Context, mapID, email string) (*models.Invitation, error)
+	GetByTokenHash(ctx context.Context, tokenHash string) (*models.Invitation, error)
+	ListByMap(ctx context.Context, mapID string) ([]*models.Invitation, error)
+}
+
+// InvitationMapLookup defines the interface for looking up the invited map
+type InvitationMapLookup interface {
+	GetByID(ctx context.Context, id string) (*models.Map, error)
+}
+
+// GuestProfileCreator defines the interface for creating and loading the
+// guest profiles of invitees
+type GuestProfileCreator interface {
+	CreateGuestProfile(ctx context.Context, displayName string) (*models.User, error)
+	GetUser(ctx context.Context, userID string) (*models.User, error)
+}
+
+// InvitationStatus is the outcome of inviting one email address
+type InvitationStatus string
+
+const (
+	InvitationStatusSent    InvitationStatus = "sent"
+	InvitationStatusFailed  InvitationStatus = "failed"  // Invitation stored but not delivered
+	InvitationStatusInvalid InvitationStatus = "invalid" // Not a valid email address
+)
+
+// InvitationResult reports the outcome of inviting one email address
+type InvitationResult struct {
+	Email        string           `json:"email"`
+	Status       InvitationStatus `json:"status"`
+	InvitationID string           `json:"invitationId,omitempty"`
+	UserID       string           `json:"userId,omitempty"`
+	Error        string           `json:"error,omitempty"`
+}
+
+// InvitationService invites email addresses to maps. Every invitee gets a
+// pre-created guest profile and a personal join link that signs them in as it.
+type InvitationService struct {
+	store       InvitationStore
+	maps        InvitationMapLookup
+	users       GuestProfileCreator
+	mailer      mailer.Mailer
+	frontendURL string
+	now         func() time.Time
+}
+
+// NewInvitationService creates a new InvitationService instance
+// Join links point to frontendURL
+func NewInvitationService(store InvitationStore, maps InvitationMapLookup, users GuestProfileCreator, m mailer.Mailer, frontendURL string) *InvitationService {
+	return &InvitationService{
+		store:       store,
+		maps:        maps,
+		users:       users,
+		mailer:      m,
+		frontendURL: strings.TrimRight(frontendURL, "/"),
+		now:         time.Now,
+	}
+}
+
+// InviteEmails invites each email address to a map and returns one result per
+// distinct address. Re-inviting an address reuses its guest profile and
+// replaces its join link. A failing address doesn't stop the others.
+func (s *InvitationService) InviteEmails(ctx context.Context, mapID, invitedBy string, emails []string) ([]InvitationResult, error) {
+	if len(emails) == 0 {
+		return nil, fmt.Errorf("invalid invitation: at least one email address is required")
+	}
+	if len(emails) > MaxInvitationEmails {
+		return nil, fmt.Errorf("invalid invitation: at most %d email addresses can be invited at once", MaxInvitationEmails)
+	}
+
+	m, err := s.maps.GetByID(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]InvitationResult, 0, len(emails))
+	seen := make(map[string]bool, len(emails))
+	for _, raw := range emails {
+		address, err := mail.ParseAddress(strings.TrimSpace(raw))
+		if err != nil {
+			results = append(results, InvitationResult{
+				Email:  raw,
+				Status: InvitationStatusInvalid,
+				Error:  "invalid email address",
+			})
+			continue
+		}
+
+		email := strings.ToLower(address.Address)
+		if seen[email] {
+			continue
+		}
+		seen[email] = true
+
+		results = append(results, s.invite(ctx, m, invitedBy, email))
+	}
+
+	return results, nil
+}
+
+// invite stores the invitation of one address and emails its join link
+func (s *InvitationService) invite(ctx context.Context, m *models.Map, invitedBy, email string) InvitationResult {
+	result := InvitationResult{Email: email, Status: InvitationStatusFailed}
+
+	invitation, err := s.store.GetByMapAndEmail(ctx, m.ID, email)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			result.Error = err.Error()
+			return result
+		}
+
+		user, err := s.users.CreateGuestProfile(ctx, models.DisplayNameFromEmail(email))
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+
+		invitation, err = models.NewInvitation(m.ID, email, user.ID, invitedBy)
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+	}
+	result.InvitationID = invitation.ID
+	result.UserID = invitation.UserID
+
+	now := s.now()
+	token, err := invitation.IssueToken(now)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	invitation.InvitedBy = invitedBy
+
+	// Store the token before sending so the link works as soon as it arrives
+	if err := s.store.Save(ctx, invitation); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	msg := mailer.Message{
+		To:      []string{email},
+		Subject: emailText(m.ContentLocale(), "invitation.subject", m.Name),
+		Body:    buildInvitationBody(m.ContentLocale(), m.Name, s.joinURL(token), invitation.ExpiresAt),
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		fmt.Printf("Warning: failed to send invitation to map %s to %s: %v\n", m.ID, email, err)
+		result.Error = "failed to send invitation email"
+		return result
+	}
+
+	invitation.SentAt = &now
+	if err := s.store.Save(ctx, invitation); err != nil {
+		// The email is out, so the invitation still counts as sent
+		fmt.Printf("Warning: failed to mark invitation %s as sent: %v\n", invitation.ID, err)
+	}
+
+	result.Status = InvitationStatusSent
+	return result
+}
+
+// AcceptInvitation returns the invitation and pre-created guest profile of a
+// join link token. The link can be used until it expires, e.g. on another device.
+func (s *InvitationService) AcceptInvitation(ctx context.Context, token string) (*models.Invitation, *models.User, error) {
+	if token == "" {
+		return nil, nil, fmt.Errorf("invalid invitation: token is required")
+	}
+
+	invitation, err := s.store.GetByTokenHash(ctx, models.HashInvitationToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("invitation %w", ErrNotFound)
+		}
+		return nil, nil, err
+	}
+
+	now := s.now()
+	if invitation.IsExpired(now) {
+		return nil, nil, fmt.Errorf("invitation expired")
+	}
+
+	user, err := s.users.GetUser(ctx, invitation.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if invitation.AcceptedAt == nil {
+		invitation.AcceptedAt = &now
+		invitation.UpdatedAt = now
+		if err := s.store.Save(ctx, invitation); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return invitation, user, nil
+}
+
+// ListInvitations returns all invitations to a map
+func (s *InvitationService) ListInvitations(ctx context.Context, mapID string) ([]*models.Invitation, error) {
+	return s.store.ListByMap(ctx, mapID)
+}
+
+// joinURL returns the personal join link for an invitation token
+func (s *InvitationService) joinURL(token string) string {
+	return s.frontendURL + "/?invite=" + url.QueryEscape(token)
+}
+
+// buildInvitationBody formats the plain text body of an invitation email in a map locale
+func buildInvitationBody(locale, mapName, joinURL string, expiresAt time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", emailText(locale, "invitation.intro", mapName))
+	fmt.Fprintf(&b, "%s\n", emailText(locale, "invitation.link"))
+	fmt.Fprintf(&b, "%s\n\n", joinURL)
+	fmt.Fprintf(&b, "%s\n", emailText(locale, "invitation.expiry", expiresAt.UTC().Format("Mon, 02 Jan 2006 15:04 MST")))
+	return b.String()
+}
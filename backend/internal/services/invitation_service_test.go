@@ -0,0 +1,232 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/mailer"
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type fakeInvitationStore struct {
+	invitations map[string]*models.Invitation // ID -> invitation
+}
+
+func (s *fakeInvitationStore) Save(ctx context.Context, invitation *models.Invitation) error {
+	copied := *invitation
+	s.invitations[invitation.ID] = &copied
+	return nil
+}
+
+func (s *fakeInvitationStore) GetByMapAndEmail(ctx context.Context, mapID, email string) (*models.Invitation, error) {
+	for _, invitation := range s.invitations {
+		if invitation.MapID == mapID && invitation.Email == email {
+			copied := *invitation
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (s *fakeInvitationStore) GetByTokenHash(ctx context.Context, tokenHash string) (*models.Invitation, error) {
+	for _, invitation := range s.invitations {
+		if invitation.TokenHash == tokenHash {
+			copied := *invitation
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (s *fakeInvitationStore) ListByMap(ctx context.Context, mapID string) ([]*models.Invitation, error) {
+	var invitations []*models.Invitation
+	for _, invitation := range s.invitations {
+		if invitation.MapID == mapID {
+			invitations = append(invitations, invitation)
+		}
+	}
+	return invitations, nil
+}
+
+type fakeGuestProfiles struct {
+	users map[string]*models.User
+}
+
+func (p *fakeGuestProfiles) CreateGuestProfile(ctx context.Context, displayName string) (*models.User, error) {
+	user, err := models.NewGuestUser(displayName)
+	if err != nil {
+		return nil, err
+	}
+	p.users[user.ID] = user
+	return user, nil
+}
+
+func (p *fakeGuestProfiles) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	user, exists := p.users[userID]
+	if !exists {
+		return nil, fmt.Errorf("user not found")
+	}
+	return user, nil
+}
+
+type failingMailer struct{}
+
+func (failingMailer) Send(ctx context.Context, msg mailer.Message) error {
+	return fmt.Errorf("connection refused")
+}
+
+func newTestInvitationService(now *time.Time, m mailer.Mailer) (*InvitationService, *fakeInvitationStore, *fakeGuestProfiles) {
+	store := &fakeInvitationStore{invitations: make(map[string]*models.Invitation)}
+	profiles := &fakeGuestProfiles{users: make(map[string]*models.User)}
+	maps := &fakeMapStore{maps: map[string]*models.Map{
+		"map-1": {ID: "map-1", Name: "Summer Workshop"},
+	}}
+	service := NewInvitationService(store, maps, profiles, m, "https://globe.example.com/")
+	service.now = func() time.Time { return *now }
+	return service, store, profiles
+}
+
+// inviteToken extracts the join link token from an invitation email
+func inviteToken(t *testing.T, msg mailer.Message) string {
+	start := strings.Index(msg.Body, "https://globe.example.com/?invite=")
+	require.NotEqual(t, -1, start)
+	link, err := url.Parse(strings.Fields(msg.Body[start:])[0])
+	require.NoError(t, err)
+	return link.Query().Get("invite")
+}
+
+func TestInvitationService_InviteEmails(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	m := &fakeMailer{}
+	service, store, profiles := newTestInvitationService(&now, m)
+
+	results, err := service.InviteEmails(ctx, "map-1", "admin-1", []string{
+		"jane.doe@example.com",
+		"Jane.Doe@Example.com",
+		"not-an-email",
+		"Bob <bob_smith@example.com>",
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	assert.Equal(t, InvitationStatusSent, results[0].Status)
+	assert.Equal(t, "jane.doe@example.com", results[0].Email)
+	assert.Equal(t, InvitationStatusInvalid, results[1].Status)
+	assert.Equal(t, InvitationStatusSent, results[2].Status)
+	assert.Equal(t, "bob_smith@example.com", results[2].Email)
+
+	// Guest profiles are pre-created with names derived from the addresses
+	assert.Equal(t, "Jane Doe", profiles.users[results[0].UserID].DisplayName)
+	assert.Equal(t, "Bob Smith", profiles.users[results[2].UserID].DisplayName)
+	assert.Len(t, store.invitations, 2)
+
+	invitation := store.invitations[results[0].InvitationID]
+	require.NotNil(t, invitation.SentAt)
+	assert.Equal(t, now.Add(models.InvitationValidity), invitation.ExpiresAt)
+
+	require.Len(t, m.sent, 2)
+	assert.Equal(t, []string{"jane.doe@example.com"}, m.sent[0].To)
+	assert.Equal(t, "You're invited to Summer Workshop", m.sent[0].Subject)
+	assert.Equal(t, models.HashInvitationToken(inviteToken(t, m.sent[0])), invitation.TokenHash)
+}
+
+func TestInvitationService_ReinviteReusesGuestProfile(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	m := &fakeMailer{}
+	service, store, profiles := newTestInvitationService(&now, m)
+
+	first, err := service.InviteEmails(ctx, "map-1", "admin-1", []string{"jane@example.com"})
+	require.NoError(t, err)
+	second, err := service.InviteEmails(ctx, "map-1", "admin-1", []string{"jane@example.com"})
+	require.NoError(t, err)
+
+	assert.Equal(t, first[0].InvitationID, second[0].InvitationID)
+	assert.Equal(t, first[0].UserID, second[0].UserID)
+	assert.Len(t, profiles.users, 1)
+	assert.Len(t, store.invitations, 1)
+
+	// Only the latest link works
+	require.Len(t, m.sent, 2)
+	_, _, err = service.AcceptInvitation(ctx, inviteToken(t, m.sent[0]))
+	assert.Error(t, err)
+	_, _, err = service.AcceptInvitation(ctx, inviteToken(t, m.sent[1]))
+	assert.NoError(t, err)
+}
+
+func TestInvitationService_InviteEmails_Validation(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	service, _, _ := newTestInvitationService(&now, &fakeMailer{})
+
+	_, err := service.InviteEmails(ctx, "map-1", "admin-1", nil)
+	assert.ErrorContains(t, err, "invalid invitation")
+
+	emails := make([]string, MaxInvitationEmails+1)
+	for i := range emails {
+		emails[i] = fmt.Sprintf("user%d@example.com", i)
+	}
+	_, err = service.InviteEmails(ctx, "map-1", "admin-1", emails)
+	assert.ErrorContains(t, err, "invalid invitation")
+
+	_, err = service.InviteEmails(ctx, "missing-map", "admin-1", []string{"jane@example.com"})
+	assert.ErrorContains(t, err, "map not found")
+}
+
+func TestInvitationService_InviteEmails_SendFailure(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	service, store, _ := newTestInvitationService(&now, failingMailer{})
+
+	results, err := service.InviteEmails(ctx, "map-1", "admin-1", []string{"jane@example.com"})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, InvitationStatusFailed, results[0].Status)
+	assert.NotEmpty(t, results[0].Error)
+
+	// The invitation is kept so it can be sent again
+	invitation := store.invitations[results[0].InvitationID]
+	require.NotNil(t, invitation)
+	assert.Nil(t, invitation.SentAt)
+}
+
+func TestInvitationService_AcceptInvitation(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	m := &fakeMailer{}
+	service, store, _ := newTestInvitationService(&now, m)
+
+	results, err := service.InviteEmails(ctx, "map-1", "admin-1", []string{"jane.doe@example.com"})
+	require.NoError(t, err)
+	token := inviteToken(t, m.sent[0])
+
+	invitation, user, err := service.AcceptInvitation(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, "map-1", invitation.MapID)
+	assert.Equal(t, results[0].UserID, user.ID)
+	assert.Equal(t, "Jane Doe", user.DisplayName)
+	require.NotNil(t, store.invitations[invitation.ID].AcceptedAt)
+	assert.Equal(t, now, *store.invitations[invitation.ID].AcceptedAt)
+
+	// The link keeps working until it expires
+	now = now.Add(time.Hour)
+	_, _, err = service.AcceptInvitation(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-time.Hour), *store.invitations[invitation.ID].AcceptedAt)
+
+	now = now.Add(models.InvitationValidity)
+	_, _, err = service.AcceptInvitation(ctx, token)
+	assert.ErrorContains(t, err, "invitation expired")
+
+	_, _, err = service.AcceptInvitation(ctx, "unknown-token")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
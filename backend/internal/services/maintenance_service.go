@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"breakoutglobe/internal/redis"
+)
+
+// MaxMaintenanceMessageLength bounds the banner text shown to clients during maintenance
+const MaxMaintenanceMessageLength = 500
+
+// MaintenancePublisher defines the interface for telling all instances about maintenance mode
+type MaintenancePublisher interface {
+	PublishMaintenanceChanged(ctx context.Context, event redis.MaintenanceEvent) error
+}
+
+// Maintenance is the server's maintenance mode. While it is enabled, reads
+// keep working but writes are rejected, so migrations can run safely.
+type Maintenance struct {
+	Enabled   bool       `json:"enabled"`
+	Message   string     `json:"message,omitempty"`
+	Since     *time.Time `json:"since,omitempty"`
+	EnabledBy string     `json:"enabledBy,omitempty"`
+}
+
+// MaintenanceService tracks whether the server is in maintenance mode. Like
+// map freezes, the mode is kept in memory and shared through PubSub events,
+// which each instance applies with Apply; an instance started during
+// maintenance only knows about it if it was started with maintenance enabled.
+type MaintenanceService struct {
+	publisher   MaintenancePublisher
+	mutex       sync.RWMutex
+	maintenance Maintenance
+	now         func() time.Time
+}
+
+// NewMaintenanceService creates a new MaintenanceService instance. Without a
+// publisher, maintenance mode only applies to this instance.
+func NewMaintenanceService(publisher MaintenancePublisher) *MaintenanceService {
+	return &MaintenanceService{
+		publisher: publisher,
+		now:       time.Now,
+	}
+}
+
+// SetMaintenance turns maintenance mode on or off for all instances. The
+// message is shown to clients while maintenance is enabled.
+func (s *MaintenanceService) SetMaintenance(ctx context.Context, userID string, enabled bool, message string) (Maintenance, error) {
+	if len(message) > MaxMaintenanceMessageLength {
+		return Maintenance{}, fmt.Errorf("%w: message must be at most %d characters", ErrInvalidInput, MaxMaintenanceMessageLength)
+	}
+
+	now := s.now()
+	maintenance := Maintenance{Enabled: enabled}
+	if enabled {
+		// Keep the start of a maintenance whose message is only changed
+		since := now
+		if current := s.Status(); current.Enabled && current.Since != nil {
+			since = *current.Since
+		}
+		maintenance.Message = message
+		maintenance.Since = &since
+		maintenance.EnabledBy = userID
+	}
+
+	if s.publisher != nil {
+		event := redis.MaintenanceEvent{
+			Enabled:   maintenance.Enabled,
+			Message:   maintenance.Message,
+			UserID:    userID,
+			Since:     maintenance.Since,
+			Timestamp: now,
+		}
+		if err := s.publisher.PublishMaintenanceChanged(ctx, event); err != nil {
+			return Maintenance{}, fmt.Errorf("failed to publish maintenance changed event: %w", err)
+		}
+	}
+
+	// Apply it here right away rather than when the event comes back
+	s.Apply(maintenance)
+	return maintenance, nil
+}
+
+// Apply records a maintenance change published by any instance
+func (s *MaintenanceService) Apply(maintenance Maintenance) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.maintenance = maintenance
+}
+
+// Status returns the current maintenance mode
+func (s *MaintenanceService) Status() Maintenance {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.maintenance
+}
+
+// IsEnabled reports whether the server is in maintenance mode
+func (s *MaintenanceService) IsEnabled() bool {
+	return s.Status().Enabled
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/redis"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingMaintenancePublisher struct {
+	events []redis.MaintenanceEvent
+	err    error
+}
+
+func (p *recordingMaintenancePublisher) PublishMaintenanceChanged(ctx context.Context, event redis.MaintenanceEvent) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.events = append(p.events, event)
+	return nil
+}
+
+func TestMaintenanceService_EnableAndDisable(t *testing.T) {
+	publisher := &recordingMaintenancePublisher{}
+	service := NewMaintenanceService(publisher)
+	now := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	maintenance, err := service.SetMaintenance(ctx, "admin-1", true, "Upgrading the database")
+	require.NoError(t, err)
+	assert.True(t, service.IsEnabled())
+	assert.Equal(t, "Upgrading the database", maintenance.Message)
+	require.NotNil(t, maintenance.Since)
+	assert.Equal(t, now, *maintenance.Since)
+
+	require.Len(t, publisher.events, 1)
+	assert.True(t, publisher.events[0].Enabled)
+	assert.Equal(t, "admin-1", publisher.events[0].UserID)
+
+	// Changing the message keeps the start of the maintenance
+	now = now.Add(10 * time.Minute)
+	maintenance, err = service.SetMaintenance(ctx, "admin-1", true, "Almost done")
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-10*time.Minute), *maintenance.Since)
+	assert.Equal(t, "Almost done", service.Status().Message)
+
+	maintenance, err = service.SetMaintenance(ctx, "admin-1", false, "ignored")
+	require.NoError(t, err)
+	assert.Equal(t, Maintenance{}, maintenance)
+	assert.False(t, service.IsEnabled())
+	require.Len(t, publisher.events, 3)
+	assert.Nil(t, publisher.events[2].Since)
+}
+
+func TestMaintenanceService_Validation(t *testing.T) {
+	service := NewMaintenanceService(nil)
+
+	_, err := service.SetMaintenance(context.Background(), "admin-1", true, strings.Repeat("a", MaxMaintenanceMessageLength+1))
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	assert.False(t, service.IsEnabled())
+
+	// Without a publisher, maintenance only applies here
+	_, err = service.SetMaintenance(context.Background(), "admin-1", true, "")
+	require.NoError(t, err)
+	assert.True(t, service.IsEnabled())
+}
+
+func TestMaintenanceService_PublishFailure(t *testing.T) {
+	service := NewMaintenanceService(&recordingMaintenancePublisher{err: errors.New("redis down")})
+
+	_, err := service.SetMaintenance(context.Background(), "admin-1", true, "")
+	assert.Error(t, err)
+	assert.False(t, service.IsEnabled())
+}
+
+func TestMaintenanceService_Apply(t *testing.T) {
+	service := NewMaintenanceService(&recordingMaintenancePublisher{})
+
+	service.Apply(Maintenance{Enabled: true, Message: "Migrating"})
+	assert.True(t, service.IsEnabled())
+	assert.Equal(t, "Migrating", service.Status().Message)
+
+	service.Apply(Maintenance{})
+	assert.False(t, service.IsEnabled())
+}
@@ -0,0 +1,201 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"breakoutglobe/internal/models"
+)
+
+// MapArchiveStore defines the interface for reading maps and creating imported ones
+type MapArchiveStore interface {
+	GetByID(ctx context.Context, id string) (*models.Map, error)
+	CreateWithPOIs(ctx context.Context, m *models.Map, pois []*models.POI) error
+}
+
+// MapArchivePOILister defines the interface for listing the POIs of a map
+type MapArchivePOILister interface {
+	GetPOIsForMap(ctx context.Context, mapID string) ([]*models.POI, error)
+}
+
+// MapArchiveImageReferencer defines the interface for referencing the stored
+// images an imported POI shares with the POI it was exported from
+type MapArchiveImageReferencer interface {
+	ReferencePOIImages(ctx context.Context, poiID, originalURL, thumbnailURL string) error
+	DeletePOIImages(ctx context.Context, poiID string) error
+}
+
+// MapImportResult describes a map created from an archive
+type MapImportResult struct {
+	Map    *models.Map       `json:"map"`
+	POIIDs map[string]string `json:"poiIds"` // Archived POI ID -> new POI ID
+}
+
+// MapArchiveService exports maps to archives and recreates maps from them
+type MapArchiveService struct {
+	maps   MapArchiveStore
+	pois   MapArchivePOILister
+	images MapArchiveImageReferencer
+	now    func() time.Time
+}
+
+// NewMapArchiveService creates a new MapArchiveService instance
+func NewMapArchiveService(maps MapArchiveStore, pois MapArchivePOILister) *MapArchiveService {
+	return &MapArchiveService{
+		maps: maps,
+		pois: pois,
+		now:  time.Now,
+	}
+}
+
+// SetImageReferencer sets the image storage that imported POIs reference
+// their images in. Without it, imported images are deleted with the POIs
+// they were exported from.
+func (s *MapArchiveService) SetImageReferencer(images MapArchiveImageReferencer) {
+	s.images = images
+}
+
+// ExportMap returns an archive of the map's settings and POIs
+func (s *MapArchiveService) ExportMap(ctx context.Context, mapID string) (*models.MapArchive, error) {
+	m, err := s.maps.GetByID(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+
+	pois, err := s.pois.GetPOIsForMap(ctx, mapID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get POIs: %w", err)
+	}
+
+	archive := &models.MapArchive{
+		Version:    models.MapArchiveVersion,
+		ExportedAt: s.now().UTC(),
+		SourceID:   m.ID,
+		Map: models.MapArchiveSettings{
+			Name:               m.Name,
+			Description:        m.Description,
+			Bounds:             m.Bounds,
+			SpawnPoints:        m.SpawnPoints,
+			PersonalSpace:      m.PersonalSpace,
+			PositionPrivacy:    m.PositionPrivacy,
+			ProfilePrivacy:     m.ProfilePrivacy,
+			GuestAnonymization: m.GuestAnonymization,
+		},
+		POIs:   make([]models.MapArchivePOI, 0, len(pois)),
+		Images: []models.MapArchiveImage{},
+	}
+
+	for _, poi := range pois {
+		archive.POIs = append(archive.POIs, models.MapArchivePOI{
+			ID:              poi.ID,
+			Name:            poi.Name,
+			Description:     poi.Description,
+			Position:        poi.Position,
+			MaxParticipants: poi.MaxParticipants,
+		})
+		if poi.ImageURL != "" {
+			archive.Images = append(archive.Images, models.MapArchiveImage{
+				POIID:        poi.ID,
+				ImageURL:     poi.ImageURL,
+				ThumbnailURL: poi.ThumbnailURL,
+			})
+		}
+	}
+
+	return archive, nil
+}
+
+// ImportMap creates a new map with fresh IDs from an archive, owned by the importing user
+func (s *MapArchiveService) ImportMap(ctx context.Context, archive *models.MapArchive, importedBy string) (*MapImportResult, error) {
+	if err := archive.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid archive: %w", err)
+	}
+
+	m, err := models.NewMap(archive.Map.Name, archive.Map.Description, importedBy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive: %w", err)
+	}
+	m.Bounds = archive.Map.Bounds
+	m.SpawnPoints = archive.Map.SpawnPoints
+	m.PersonalSpace = archive.Map.PersonalSpace
+	m.PositionPrivacy = archive.Map.PositionPrivacy
+	m.ProfilePrivacy = archive.Map.ProfilePrivacy
+	m.GuestAnonymization = archive.Map.GuestAnonymization
+	if err := m.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid archive: %w", err)
+	}
+
+	images := make(map[string]models.MapArchiveImage, len(archive.Images))
+	for _, image := range archive.Images {
+		images[image.POIID] = image
+	}
+
+	result := &MapImportResult{Map: m, POIIDs: make(map[string]string, len(archive.POIs))}
+	pois := make([]*models.POI, 0, len(archive.POIs))
+	for _, archived := range archive.POIs {
+		poi, err := models.NewPOI(m.ID, archived.Name, archived.Description, archived.Position, importedBy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid archive: POI %s: %w", archived.ID, err)
+		}
+		if archived.MaxParticipants > 0 {
+			poi.MaxParticipants = archived.MaxParticipants
+		}
+		if image, exists := images[archived.ID]; exists {
+			poi.ImageURL = image.ImageURL
+			poi.ThumbnailURL = image.ThumbnailURL
+		}
+		if err := poi.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid archive: POI %s: %w", archived.ID, err)
+		}
+
+		pois = append(pois, poi)
+		result.POIIDs[archived.ID] = poi.ID
+	}
+
+	if err := s.referenceImages(ctx, pois); err != nil {
+		return nil, err
+	}
+	if err := s.maps.CreateWithPOIs(ctx, m, pois); err != nil {
+		s.releaseImages(ctx, pois)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// referenceImages references the stored images of imported POIs under their
+// own IDs, so they outlive the POIs they were exported from. References made
+// before a failure are released.
+func (s *MapArchiveService) referenceImages(ctx context.Context, pois []*models.POI) error {
+	if s.images == nil {
+		return nil
+	}
+
+	for i, poi := range pois {
+		if poi.ImageURL == "" {
+			continue
+		}
+		if err := s.images.ReferencePOIImages(ctx, poi.ID, poi.ImageURL, poi.ThumbnailURL); err != nil {
+			s.releaseImages(ctx, pois[:i])
+			return fmt.Errorf("failed to reference images of POI %s: %w", poi.ID, err)
+		}
+	}
+	return nil
+}
+
+// releaseImages releases the image references of POIs that weren't imported
+func (s *MapArchiveService) releaseImages(ctx context.Context, pois []*models.POI) {
+	if s.images == nil {
+		return
+	}
+
+	for _, poi := range pois {
+		if poi.ImageURL == "" {
+			continue
+		}
+		if err := s.images.DeletePOIImages(ctx, poi.ID); err != nil {
+			fmt.Printf("Warning: failed to release images of POI %s: %v\n", poi.ID, err)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeMapArchiveStore struct {
+	created   []*models.Map
+	pois      []*models.POI
+	createErr error
+}
+
+func (s *fakeMapArchiveStore) GetByID(ctx context.Context, id string) (*models.Map, error) {
+	return &models.Map{ID: id, Name: "Workshop", CreatedBy: "organizer", CreatedAt: time.Now()}, nil
+}
+
+func (s *fakeMapArchiveStore) CreateWithPOIs(ctx context.Context, m *models.Map, pois []*models.POI) error {
+	if s.createErr != nil {
+		return s.createErr
+	}
+	s.created = append(s.created, m)
+	s.pois = append(s.pois, pois...)
+	return nil
+}
+
+func (s *fakeMapArchiveStore) GetPOIsForMap(ctx context.Context, mapID string) ([]*models.POI, error) {
+	return []*models.POI{}, nil
+}
+
+// recordingImageReferencer records the POIs holding image references
+type recordingImageReferencer struct {
+	referenced map[string]string
+}
+
+func (r *recordingImageReferencer) ReferencePOIImages(ctx context.Context, poiID, originalURL, thumbnailURL string) error {
+	r.referenced[poiID] = originalURL
+	return nil
+}
+
+func (r *recordingImageReferencer) DeletePOIImages(ctx context.Context, poiID string) error {
+	delete(r.referenced, poiID)
+	return nil
+}
+
+func TestMapArchiveService_ExportEmptyMap(t *testing.T) {
+	store := &fakeMapArchiveStore{}
+	service := NewMapArchiveService(store, store)
+	service.now = func() time.Time { return time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC) }
+
+	archive, err := service.ExportMap(context.Background(), "map-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "map-1", archive.SourceID)
+	assert.Equal(t, "Workshop", archive.Map.Name)
+	assert.Empty(t, archive.POIs)
+	assert.NotNil(t, archive.Images)
+	assert.Equal(t, time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), archive.ExportedAt)
+}
+
+func TestMapArchiveService_ImportIsAllOrNothing(t *testing.T) {
+	store := &fakeMapArchiveStore{}
+	service := NewMapArchiveService(store, store)
+
+	archive := &models.MapArchive{
+		Version: models.MapArchiveVersion,
+		Map:     models.MapArchiveSettings{Name: "Workshop"},
+		POIs: []models.MapArchivePOI{
+			{ID: "poi-1", Name: "Coffee", Position: models.LatLng{Lat: 52.52, Lng: 13.405}},
+			{ID: "poi-2", Name: "", Position: models.LatLng{Lat: 48.137, Lng: 11.575}},
+		},
+	}
+
+	_, err := service.ImportMap(context.Background(), archive, "importer")
+	assert.ErrorContains(t, err, "invalid archive: POI poi-2")
+	assert.Empty(t, store.created)
+
+	// Archived POIs without a participant limit get the default
+	archive.POIs[1].Name = "Lounge"
+	result, err := service.ImportMap(context.Background(), archive, "importer")
+	assert.NoError(t, err)
+	assert.Len(t, store.pois, 2)
+	assert.Equal(t, 10, store.pois[1].MaxParticipants)
+	assert.Equal(t, store.pois[1].ID, result.POIIDs["poi-2"])
+}
+
+func TestMapArchiveService_ImportReferencesImages(t *testing.T) {
+	store := &fakeMapArchiveStore{}
+	images := &recordingImageReferencer{referenced: map[string]string{}}
+	service := NewMapArchiveService(store, store)
+	service.SetImageReferencer(images)
+
+	archive := &models.MapArchive{
+		Version: models.MapArchiveVersion,
+		Map:     models.MapArchiveSettings{Name: "Workshop"},
+		POIs: []models.MapArchivePOI{
+			{ID: "poi-1", Name: "Coffee", Position: models.LatLng{Lat: 52.52, Lng: 13.405}},
+			{ID: "poi-2", Name: "Lounge", Position: models.LatLng{Lat: 48.137, Lng: 11.575}},
+		},
+		Images: []models.MapArchiveImage{
+			{POIID: "poi-1", ImageURL: "/uploads/blobs/ab/ab.png", ThumbnailURL: "/uploads/blobs/cd/cd.jpg"},
+		},
+	}
+
+	// References of a failed import are released
+	store.createErr = errors.New("database unavailable")
+	_, err := service.ImportMap(context.Background(), archive, "importer")
+	assert.Error(t, err)
+	assert.Empty(t, images.referenced)
+
+	store.createErr = nil
+	result, err := service.ImportMap(context.Background(), archive, "importer")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{result.POIIDs["poi-1"]: "/uploads/blobs/ab/ab.png"}, images.referenced)
+}
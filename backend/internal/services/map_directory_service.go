@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"breakoutglobe/internal/models"
+)
+
+const (
+	// participantCountCacheTTL is how long the participant counts of the public
+	// directory are cached, since counting scans all sessions
+	participantCountCacheTTL = 15 * time.Second
+
+	// DefaultMapDirectoryLimit is the number of maps listed when no limit is given
+	DefaultMapDirectoryLimit = 50
+
+	// MaxMapDirectoryLimit is the maximum number of maps listed at once
+	MaxMapDirectoryLimit = 100
+)
+
+// Sort orders of the public map directory
+const (
+	MapDirectorySortActive = "active"
+	MapDirectorySortNewest = "newest"
+	MapDirectorySortName   = "name"
+)
+
+// MapDirectoryStore defines the interface for listing maps and updating their listing
+type MapDirectoryStore interface {
+	GetByID(ctx context.Context, id string) (*models.Map, error)
+	ListDiscoverable(ctx context.Context, query string, tags []string) ([]*models.Map, error)
+	UpdateListing(ctx context.Context, id string, listing models.MapListing) error
+}
+
+// MapParticipantCounter defines the interface for counting live participants per map
+type MapParticipantCounter interface {
+	CountParticipantsByMap(ctx context.Context) (map[string]int, error)
+}
+
+// PublicMap is a map as shown in the public directory
+type PublicMap struct {
+	ID               string    `json:"id"`
+	Name             string    `json:"name"`
+	Description      string    `json:"description"`
+	Tags             []string  `json:"tags"`
+	Locale           string    `json:"locale"`
+	ParticipantCount int       `json:"participantCount"`
+	CreatedAt        time.Time `json:"createdAt"`
+}
+
+// MapDirectoryQuery filters and orders the public map directory
+type MapDirectoryQuery struct {
+	Tags  []string
+	Query string
+	Sort  string
+	Limit int
+}
+
+// MapDirectoryService lists the maps organizers opted into the public directory
+// together with their live participant counts
+type MapDirectoryService struct {
+	maps     MapDirectoryStore
+	counter  MapParticipantCounter
+	mutex    sync.Mutex
+	counts   map[string]int
+	countsAt time.Time
+	now      func() time.Time
+}
+
+// NewMapDirectoryService creates a new MapDirectoryService instance.
+// counter is optional; without it every map reports no participants.
+func NewMapDirectoryService(maps MapDirectoryStore, counter MapParticipantCounter) *MapDirectoryService {
+	return &MapDirectoryService{
+		maps:    maps,
+		counter: counter,
+		now:     time.Now,
+	}
+}
+
+// ListPublicMaps lists the discoverable maps matching the query
+func (s *MapDirectoryService) ListPublicMaps(ctx context.Context, query MapDirectoryQuery) ([]PublicMap, error) {
+	tags, err := models.NormalizeMapTags(query.Tags)
+	if err != nil {
+		return nil, fmt.Errorf("invalid listing query: %w", err)
+	}
+
+	sortBy := query.Sort
+	if sortBy == "" {
+		sortBy = MapDirectorySortActive
+	}
+	if sortBy != MapDirectorySortActive && sortBy != MapDirectorySortNewest && sortBy != MapDirectorySortName {
+		return nil, fmt.Errorf("invalid listing query: unknown sort %q", sortBy)
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultMapDirectoryLimit
+	}
+	if limit > MaxMapDirectoryLimit {
+		limit = MaxMapDirectoryLimit
+	}
+
+	maps, err := s.maps.ListDiscoverable(ctx, strings.TrimSpace(query.Query), tags)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := s.participantCounts(ctx)
+	listed := make([]PublicMap, 0, len(maps))
+	for _, m := range maps {
+		mapTags := m.Tags
+		if mapTags == nil {
+			mapTags = []string{}
+		}
+		listed = append(listed, PublicMap{
+			ID:               m.ID,
+			Name:             m.Name,
+			Description:      m.Description,
+			Tags:             mapTags,
+			Locale:           m.ContentLocale(),
+			ParticipantCount: counts[m.ID],
+			CreatedAt:        m.CreatedAt,
+		})
+	}
+
+	sort.SliceStable(listed, func(i, j int) bool {
+		switch sortBy {
+		case MapDirectorySortName:
+			return strings.ToLower(listed[i].Name) < strings.ToLower(listed[j].Name)
+		case MapDirectorySortNewest:
+			return listed[i].CreatedAt.After(listed[j].CreatedAt)
+		default:
+			if listed[i].ParticipantCount != listed[j].ParticipantCount {
+				return listed[i].ParticipantCount > listed[j].ParticipantCount
+			}
+			return listed[i].CreatedAt.After(listed[j].CreatedAt)
+		}
+	})
+
+	if len(listed) > limit {
+		listed = listed[:limit]
+	}
+
+	return listed, nil
+}
+
+// GetListing returns the directory listing of a map
+func (s *MapDirectoryService) GetListing(ctx context.Context, mapID string) (models.MapListing, error) {
+	m, err := s.maps.GetByID(ctx, mapID)
+	if err != nil {
+		return models.MapListing{}, err
+	}
+
+	tags := m.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return models.MapListing{Discoverable: m.Discoverable, Tags: tags}, nil
+}
+
+// SetListing opts a map in or out of the public directory and replaces its tags
+func (s *MapDirectoryService) SetListing(ctx context.Context, mapID string, listing models.MapListing) (models.MapListing, error) {
+	if err := listing.Normalize(); err != nil {
+		return models.MapListing{}, fmt.Errorf("invalid listing: %w", err)
+	}
+
+	if err := s.maps.UpdateListing(ctx, mapID, listing); err != nil {
+		return models.MapListing{}, err
+	}
+
+	return listing, nil
+}
+
+// participantCounts returns the cached participant counts of all maps. A failing
+// counter is logged and reported as no participants rather than failing the listing.
+func (s *MapDirectoryService) participantCounts(ctx context.Context) map[string]int {
+	if s.counter == nil {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	if s.counts != nil && now.Sub(s.countsAt) < participantCountCacheTTL {
+		return s.counts
+	}
+
+	counts, err := s.counter.CountParticipantsByMap(ctx)
+	if err != nil {
+		fmt.Printf("Warning: failed to count map participants: %v\n", err)
+		return s.counts
+	}
+
+	s.counts = counts
+	s.countsAt = now
+	return counts
+}
@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeMapDirectoryStore struct {
+	maps map[string]*models.Map
+}
+
+func (s *fakeMapDirectoryStore) GetByID(ctx context.Context, id string) (*models.Map, error) {
+	m, exists := s.maps[id]
+	if !exists {
+		return nil, fmt.Errorf("map not found")
+	}
+	return m, nil
+}
+
+func (s *fakeMapDirectoryStore) ListDiscoverable(ctx context.Context, query string, tags []string) ([]*models.Map, error) {
+	var listed []*models.Map
+	for _, m := range s.maps {
+		if !m.Discoverable || !m.IsActive {
+			continue
+		}
+		hasTags := true
+		for _, tag := range tags {
+			found := false
+			for _, mapTag := range m.Tags {
+				found = found || mapTag == tag
+			}
+			hasTags = hasTags && found
+		}
+		if hasTags {
+			listed = append(listed, m)
+		}
+	}
+	return listed, nil
+}
+
+func (s *fakeMapDirectoryStore) UpdateListing(ctx context.Context, id string, listing models.MapListing) error {
+	m, exists := s.maps[id]
+	if !exists {
+		return fmt.Errorf("map not found")
+	}
+	m.Discoverable = listing.Discoverable
+	m.Tags = listing.Tags
+	return nil
+}
+
+type fakeParticipantCounter struct {
+	counts map[string]int
+	calls  int
+}
+
+func (c *fakeParticipantCounter) CountParticipantsByMap(ctx context.Context) (map[string]int, error) {
+	c.calls++
+	return c.counts, nil
+}
+
+func setupMapDirectoryTest() (*MapDirectoryService, *fakeMapDirectoryStore, *fakeParticipantCounter) {
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := &fakeMapDirectoryStore{maps: map[string]*models.Map{
+		"map-1":   {ID: "map-1", Name: "Zen garden", IsActive: true, Discoverable: true, Tags: []string{"quiet"}, CreatedAt: created},
+		"map-2":   {ID: "map-2", Name: "Agile meetup", IsActive: true, Discoverable: true, Tags: []string{"tech", "meetup"}, CreatedAt: created.Add(time.Hour)},
+		"map-3":   {ID: "map-3", Name: "Book club", IsActive: true, Discoverable: true, CreatedAt: created.Add(2 * time.Hour)},
+		"private": {ID: "private", Name: "Team room", IsActive: true, CreatedAt: created},
+	}}
+	counter := &fakeParticipantCounter{counts: map[string]int{"map-1": 7, "map-2": 3, "private": 12}}
+	return NewMapDirectoryService(store, counter), store, counter
+}
+
+func mapIDs(maps []PublicMap) []string {
+	ids := make([]string, len(maps))
+	for i, m := range maps {
+		ids[i] = m.ID
+	}
+	return ids
+}
+
+func TestMapDirectoryService_ListPublicMaps_Sorts(t *testing.T) {
+	service, _, _ := setupMapDirectoryTest()
+	ctx := context.Background()
+
+	maps, err := service.ListPublicMaps(ctx, MapDirectoryQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"map-1", "map-2", "map-3"}, mapIDs(maps))
+	assert.Equal(t, 7, maps[0].ParticipantCount)
+	assert.Equal(t, []string{}, maps[2].Tags)
+
+	maps, err = service.ListPublicMaps(ctx, MapDirectoryQuery{Sort: MapDirectorySortNewest})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"map-3", "map-2", "map-1"}, mapIDs(maps))
+
+	maps, err = service.ListPublicMaps(ctx, MapDirectoryQuery{Sort: MapDirectorySortName, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"map-2", "map-3"}, mapIDs(maps))
+
+	_, err = service.ListPublicMaps(ctx, MapDirectoryQuery{Sort: "popular"})
+	assert.ErrorContains(t, err, "invalid listing query")
+}
+
+func TestMapDirectoryService_ListPublicMaps_FiltersByNormalizedTags(t *testing.T) {
+	service, _, _ := setupMapDirectoryTest()
+
+	maps, err := service.ListPublicMaps(context.Background(), MapDirectoryQuery{Tags: []string{" Tech ", "meetup"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"map-2"}, mapIDs(maps))
+
+	_, err = service.ListPublicMaps(context.Background(), MapDirectoryQuery{Tags: []string{"c++"}})
+	assert.ErrorContains(t, err, "invalid listing query")
+}
+
+func TestMapDirectoryService_CachesParticipantCounts(t *testing.T) {
+	service, _, counter := setupMapDirectoryTest()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, _ = service.ListPublicMaps(ctx, MapDirectoryQuery{})
+	_, _ = service.ListPublicMaps(ctx, MapDirectoryQuery{})
+	assert.Equal(t, 1, counter.calls)
+
+	now = now.Add(participantCountCacheTTL)
+	_, _ = service.ListPublicMaps(ctx, MapDirectoryQuery{})
+	assert.Equal(t, 2, counter.calls)
+}
+
+func TestMapDirectoryService_SetListing(t *testing.T) {
+	service, store, _ := setupMapDirectoryTest()
+	ctx := context.Background()
+
+	listing, err := service.SetListing(ctx, "private", models.MapListing{Discoverable: true, Tags: []string{"Team", "team", " retro "}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"team", "retro"}, listing.Tags)
+	assert.True(t, store.maps["private"].Discoverable)
+
+	listing, err = service.GetListing(ctx, "private")
+	require.NoError(t, err)
+	assert.Equal(t, models.MapListing{Discoverable: true, Tags: []string{"team", "retro"}}, listing)
+
+	_, err = service.SetListing(ctx, "private", models.MapListing{Tags: []string{"no spaces"}})
+	assert.ErrorContains(t, err, "invalid listing")
+}
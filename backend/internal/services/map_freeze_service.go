@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"breakoutglobe/internal/redis"
+)
+
+const (
+	// DefaultMapFreezeDuration is how long a map stays frozen unless the
+	// facilitator asks for another duration or unfreezes it earlier
+	DefaultMapFreezeDuration = 5 * time.Minute
+
+	// MaxMapFreezeDuration bounds freezes, so a map can't stay frozen after
+	// its facilitator left
+	MaxMapFreezeDuration = time.Hour
+)
+
+// MapFreezePublisher defines the interface for telling all instances about map freezes
+type MapFreezePublisher interface {
+	PublishMapFrozen(ctx context.Context, event redis.MapFreezeEvent) error
+	PublishMapUnfrozen(ctx context.Context, event redis.MapFreezeEvent) error
+}
+
+// MapFreeze is a facilitator's freeze of a map
+type MapFreeze struct {
+	MapID    string
+	FrozenBy string
+	Until    time.Time
+}
+
+// MapFreezeService tracks which maps facilitators froze, for example to get
+// everyone's attention during a workshop. Freezes are kept in memory and
+// shared through PubSub events, which each instance applies with ApplyFrozen
+// and ApplyUnfrozen; an instance started during a freeze doesn't know about it.
+type MapFreezeService struct {
+	publisher MapFreezePublisher
+	mutex     sync.RWMutex
+	frozen    map[string]MapFreeze
+	now       func() time.Time
+}
+
+// NewMapFreezeService creates a new MapFreezeService instance
+func NewMapFreezeService(publisher MapFreezePublisher) *MapFreezeService {
+	return &MapFreezeService{
+		publisher: publisher,
+		frozen:    make(map[string]MapFreeze),
+		now:       time.Now,
+	}
+}
+
+// Freeze freezes a map for duration, or DefaultMapFreezeDuration if it is 0
+func (s *MapFreezeService) Freeze(ctx context.Context, mapID, userID string, duration time.Duration) (MapFreeze, error) {
+	if duration == 0 {
+		duration = DefaultMapFreezeDuration
+	}
+	if duration < 0 || duration > MaxMapFreezeDuration {
+		return MapFreeze{}, fmt.Errorf("%w: freeze duration must be between 0 and %s", ErrInvalidInput, MaxMapFreezeDuration)
+	}
+
+	now := s.now()
+	freeze := MapFreeze{MapID: mapID, FrozenBy: userID, Until: now.Add(duration)}
+	event := redis.MapFreezeEvent{
+		MapID:     mapID,
+		UserID:    userID,
+		Until:     &freeze.Until,
+		Timestamp: now,
+	}
+	if err := s.publisher.PublishMapFrozen(ctx, event); err != nil {
+		return MapFreeze{}, fmt.Errorf("failed to publish map frozen event: %w", err)
+	}
+
+	// Apply it here right away rather than when the event comes back
+	s.ApplyFrozen(freeze)
+	return freeze, nil
+}
+
+// Unfreeze ends the freeze of a map
+func (s *MapFreezeService) Unfreeze(ctx context.Context, mapID, userID string) error {
+	event := redis.MapFreezeEvent{
+		MapID:     mapID,
+		UserID:    userID,
+		Timestamp: s.now(),
+	}
+	if err := s.publisher.PublishMapUnfrozen(ctx, event); err != nil {
+		return fmt.Errorf("failed to publish map unfrozen event: %w", err)
+	}
+
+	s.ApplyUnfrozen(mapID)
+	return nil
+}
+
+// ApplyFrozen records a freeze published by any instance
+func (s *MapFreezeService) ApplyFrozen(freeze MapFreeze) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.frozen[freeze.MapID] = freeze
+}
+
+// ApplyUnfrozen records the end of a freeze published by any instance
+func (s *MapFreezeService) ApplyUnfrozen(mapID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.frozen, mapID)
+}
+
+// GetFreeze returns the current freeze of a map, if it is frozen
+func (s *MapFreezeService) GetFreeze(mapID string) (MapFreeze, bool) {
+	s.mutex.RLock()
+	freeze, exists := s.frozen[mapID]
+	s.mutex.RUnlock()
+
+	if !exists || !s.now().Before(freeze.Until) {
+		return MapFreeze{}, false
+	}
+	return freeze, true
+}
+
+// IsFrozen reports whether a map is frozen
+func (s *MapFreezeService) IsFrozen(mapID string) bool {
+	_, frozen := s.GetFreeze(mapID)
+	return frozen
+}
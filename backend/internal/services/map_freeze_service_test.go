@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/redis"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingMapFreezePublisher struct {
+	frozen   []redis.MapFreezeEvent
+	unfrozen []redis.MapFreezeEvent
+}
+
+func (p *recordingMapFreezePublisher) PublishMapFrozen(ctx context.Context, event redis.MapFreezeEvent) error {
+	p.frozen = append(p.frozen, event)
+	return nil
+}
+
+func (p *recordingMapFreezePublisher) PublishMapUnfrozen(ctx context.Context, event redis.MapFreezeEvent) error {
+	p.unfrozen = append(p.unfrozen, event)
+	return nil
+}
+
+func TestMapFreezeService_FreezeAndUnfreeze(t *testing.T) {
+	publisher := &recordingMapFreezePublisher{}
+	service := NewMapFreezeService(publisher)
+	now := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	freeze, err := service.Freeze(ctx, "map-1", "facilitator-1", 0)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(DefaultMapFreezeDuration), freeze.Until)
+	assert.True(t, service.IsFrozen("map-1"))
+	assert.False(t, service.IsFrozen("map-2"))
+
+	require.Len(t, publisher.frozen, 1)
+	assert.Equal(t, "facilitator-1", publisher.frozen[0].UserID)
+	require.NotNil(t, publisher.frozen[0].Until)
+	assert.Equal(t, freeze.Until, *publisher.frozen[0].Until)
+
+	require.NoError(t, service.Unfreeze(ctx, "map-1", "facilitator-1"))
+	assert.False(t, service.IsFrozen("map-1"))
+	require.Len(t, publisher.unfrozen, 1)
+	assert.Nil(t, publisher.unfrozen[0].Until)
+}
+
+func TestMapFreezeService_FreezeExpires(t *testing.T) {
+	service := NewMapFreezeService(&recordingMapFreezePublisher{})
+	now := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	_, err := service.Freeze(context.Background(), "map-1", "facilitator-1", time.Minute)
+	require.NoError(t, err)
+
+	now = now.Add(time.Minute)
+	assert.False(t, service.IsFrozen("map-1"))
+}
+
+func TestMapFreezeService_RejectsInvalidDuration(t *testing.T) {
+	publisher := &recordingMapFreezePublisher{}
+	service := NewMapFreezeService(publisher)
+
+	_, err := service.Freeze(context.Background(), "map-1", "facilitator-1", MaxMapFreezeDuration+time.Second)
+	assert.True(t, errors.Is(err, ErrInvalidInput))
+	assert.Empty(t, publisher.frozen)
+	assert.False(t, service.IsFrozen("map-1"))
+}
+
+func TestPOIService_RejectsChangesOnFrozenMap(t *testing.T) {
+	service, _ := newRosterTestService()
+	freeze := NewMapFreezeService(&recordingMapFreezePublisher{})
+	service.SetMapFreeze(freeze)
+	ctx := context.Background()
+
+	require.NoError(t, service.JoinPOI(ctx, "poi-1", "user-1"))
+	_, err := freeze.Freeze(ctx, "map-1", "facilitator-1", 0)
+	require.NoError(t, err)
+
+	assert.True(t, errors.Is(service.JoinPOI(ctx, "poi-2", "user-2"), ErrMapFrozen))
+	assert.True(t, errors.Is(service.LeavePOI(ctx, "poi-1", "user-1"), ErrMapFrozen))
+	assert.True(t, errors.Is(service.DeletePOI(ctx, "poi-1"), ErrMapFrozen))
+
+	// Stale participants are still reconciled
+	removed, err := service.ReconcileParticipants(ctx, "map-1", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+}
@@ -0,0 +1,310 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"breakoutglobe/internal/mailer"
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/redis"
+
+	"gorm.io/gorm"
+)
+
+// MapOwnershipStore defines the interface for transferring and deleting maps
+type MapOwnershipStore interface {
+	GetByID(ctx context.Context, id string) (*models.Map, error)
+	UpdateOwner(ctx context.Context, id string, ownerID string) error
+	UpdateDeletionRequest(ctx context.Context, id string, request models.MapDeletionRequest) error
+	DeleteWithPOIs(ctx context.Context, id string, snapshot *models.MapSnapshot) error
+	GetSnapshot(ctx context.Context, id string) (*models.MapSnapshot, error)
+	MarkSnapshotRestored(ctx context.Context, id string, mapID string) error
+}
+
+// MapOwnerLookup defines the interface for looking up the users maps are
+// transferred to and the superadmins told about deletion requests
+type MapOwnerLookup interface {
+	GetByID(ctx context.Context, id string) (*models.User, error)
+	ListByRole(ctx context.Context, role models.UserRole) ([]*models.User, error)
+}
+
+// MapSnapshotArchiver defines the interface for exporting a map before it is
+// deleted and restoring it from the snapshot
+type MapSnapshotArchiver interface {
+	ExportMap(ctx context.Context, mapID string) (*models.MapArchive, error)
+	ImportMap(ctx context.Context, archive *models.MapArchive, importedBy string) (*MapImportResult, error)
+}
+
+// MapDeletionPublisher defines the interface for telling all instances about
+// deleted maps
+type MapDeletionPublisher interface {
+	PublishMapDeleted(ctx context.Context, event redis.MapDeletedEvent) error
+}
+
+// MapActor is the user changing a map's ownership, with the role of their sign-in
+type MapActor struct {
+	UserID string
+	Role   models.UserRole
+}
+
+// MapDeletionTicket is a requested map deletion, with the token confirming it
+type MapDeletionTicket struct {
+	Token         string    `json:"token"`
+	ConfirmableAt time.Time `json:"confirmableAt"`
+	ExpiresAt     time.Time `json:"expiresAt"`
+}
+
+// MapOwnershipService transfers maps between organizers and deletes maps in
+// two steps: a request, and a confirmation with the request's token after a
+// cool-down. The owner and the superadmins are emailed about every request,
+// so an unwanted one can be cancelled in time. A snapshot of the map is
+// stored with the deletion, so an accidental or malicious deletion can be
+// undone by restoring it. Only a map's owner and superadmins can transfer,
+// delete or restore it.
+type MapOwnershipService struct {
+	maps      MapOwnershipStore
+	users     MapOwnerLookup
+	archiver  MapSnapshotArchiver
+	mailer    mailer.Mailer
+	publisher MapDeletionPublisher
+	now       func() time.Time
+}
+
+// NewMapOwnershipService creates a new MapOwnershipService instance
+func NewMapOwnershipService(maps MapOwnershipStore, users MapOwnerLookup, archiver MapSnapshotArchiver) *MapOwnershipService {
+	return &MapOwnershipService{
+		maps:     maps,
+		users:    users,
+		archiver: archiver,
+		now:      time.Now,
+	}
+}
+
+// SetMailer enables emailing the owner and the superadmins about deletion
+// requests. Without it, nobody is told.
+func (s *MapOwnershipService) SetMailer(m mailer.Mailer) {
+	s.mailer = m
+}
+
+// SetPublisher enables disconnecting the clients of deleted maps on every
+// instance. Without it, they stay connected until they reconnect.
+func (s *MapOwnershipService) SetPublisher(publisher MapDeletionPublisher) {
+	s.publisher = publisher
+}
+
+// TransferOwnership makes another organizer the owner of a map. A pending
+// deletion request is cancelled.
+func (s *MapOwnershipService) TransferOwnership(ctx context.Context, mapID string, actor MapActor, newOwnerID string) (*models.Map, error) {
+	if newOwnerID == "" {
+		return nil, fmt.Errorf("%w: new owner is required", ErrInvalidInput)
+	}
+
+	m, err := s.ownedMap(ctx, mapID, actor)
+	if err != nil {
+		return nil, err
+	}
+	if m.CreatedBy == newOwnerID {
+		return m, nil
+	}
+
+	owner, err := s.users.GetByID(ctx, newOwnerID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: user %s does not exist", ErrInvalidInput, newOwnerID)
+		}
+		return nil, fmt.Errorf("failed to get new owner: %w", err)
+	}
+	if !owner.IsAdmin() {
+		return nil, fmt.Errorf("%w: the new owner must be an organizer", ErrInvalidInput)
+	}
+
+	if err := s.maps.UpdateOwner(ctx, mapID, newOwnerID); err != nil {
+		return nil, err
+	}
+
+	m.CreatedBy = newOwnerID
+	m.Deletion = models.MapDeletionRequest{}
+	return m, nil
+}
+
+// RequestDeletion starts deleting a map. The returned token confirms the
+// deletion once the cool-down passed. Requesting again replaces the token and
+// restarts the cool-down. The owner and the superadmins are told.
+func (s *MapOwnershipService) RequestDeletion(ctx context.Context, mapID string, actor MapActor) (*MapDeletionTicket, error) {
+	m, err := s.ownedMap(ctx, mapID, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	request, token, err := models.NewMapDeletionRequest(actor.UserID, s.now())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.maps.UpdateDeletionRequest(ctx, mapID, request); err != nil {
+		return nil, err
+	}
+	s.notifyDeletionRequested(ctx, m, actor, request)
+
+	return &MapDeletionTicket{
+		Token:         token,
+		ConfirmableAt: request.ConfirmableAt,
+		ExpiresAt:     request.ExpiresAt,
+	}, nil
+}
+
+// GetDeletionRequest returns the pending deletion request of a map
+func (s *MapOwnershipService) GetDeletionRequest(ctx context.Context, mapID string, actor MapActor) (*models.MapDeletionRequest, error) {
+	m, err := s.ownedMap(ctx, mapID, actor)
+	if err != nil {
+		return nil, err
+	}
+	if !m.Deletion.IsPending(s.now()) {
+		return nil, fmt.Errorf("%w: no pending deletion request", ErrNotFound)
+	}
+	return &m.Deletion, nil
+}
+
+// CancelDeletion drops the pending deletion request of a map
+func (s *MapOwnershipService) CancelDeletion(ctx context.Context, mapID string, actor MapActor) error {
+	m, err := s.ownedMap(ctx, mapID, actor)
+	if err != nil {
+		return err
+	}
+	if !m.Deletion.IsPending(s.now()) {
+		return fmt.Errorf("%w: no pending deletion request", ErrNotFound)
+	}
+	return s.maps.UpdateDeletionRequest(ctx, mapID, models.MapDeletionRequest{})
+}
+
+// ConfirmDeletion deletes a map with its POIs and sessions, given the token of
+// its pending deletion request after the cool-down, and disconnects its
+// clients. It returns the snapshot stored with the deletion.
+func (s *MapOwnershipService) ConfirmDeletion(ctx context.Context, mapID string, actor MapActor, token string) (*models.MapSnapshot, error) {
+	m, err := s.ownedMap(ctx, mapID, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	if !m.Deletion.IsPending(now) {
+		return nil, fmt.Errorf("%w: no pending deletion request", ErrNotFound)
+	}
+	if !m.Deletion.Matches(token) {
+		return nil, fmt.Errorf("%w: invalid deletion token", ErrInvalidInput)
+	}
+	if now.Before(m.Deletion.ConfirmableAt) {
+		return nil, fmt.Errorf("%w: the deletion can be confirmed from %s", ErrDeletionCoolingDown, m.Deletion.ConfirmableAt.UTC().Format(time.RFC3339))
+	}
+
+	archive, err := s.archiver.ExportMap(ctx, mapID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export map snapshot: %w", err)
+	}
+	snapshot := models.NewMapSnapshot(m, actor.UserID, *archive, now)
+	if err := s.maps.DeleteWithPOIs(ctx, mapID, snapshot); err != nil {
+		return nil, err
+	}
+
+	if s.publisher != nil {
+		event := redis.MapDeletedEvent{MapID: mapID, DeletedBy: actor.UserID, Timestamp: now}
+		if err := s.publisher.PublishMapDeleted(ctx, event); err != nil {
+			fmt.Printf("Warning: failed to publish deletion of map %s: %v\n", mapID, err)
+		}
+	}
+
+	return snapshot, nil
+}
+
+// RestoreSnapshot recreates a deleted map from its snapshot, owned by the
+// map's owner at the time of the deletion. The map gets a new ID, and a
+// snapshot can only be restored once.
+func (s *MapOwnershipService) RestoreSnapshot(ctx context.Context, snapshotID string, actor MapActor) (*MapImportResult, error) {
+	snapshot, err := s.maps.GetSnapshot(ctx, snapshotID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: snapshot %s does not exist", ErrNotFound, snapshotID)
+		}
+		return nil, fmt.Errorf("failed to get snapshot: %w", err)
+	}
+	if snapshot.OwnerID != actor.UserID && actor.Role != models.UserRoleSuperAdmin {
+		return nil, fmt.Errorf("%w: only the owner of the deleted map can restore it", ErrForbidden)
+	}
+	if snapshot.RestoredMapID != "" {
+		return nil, fmt.Errorf("%w: the snapshot was already restored as map %s", ErrInvalidInput, snapshot.RestoredMapID)
+	}
+
+	result, err := s.archiver.ImportMap(ctx, &snapshot.Archive, snapshot.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.maps.MarkSnapshotRestored(ctx, snapshotID, result.Map.ID); err != nil {
+		fmt.Printf("Warning: failed to mark snapshot %s restored as map %s: %v\n", snapshotID, result.Map.ID, err)
+	}
+
+	return result, nil
+}
+
+// notifyDeletionRequested emails the owner of a map and the superadmins about
+// a deletion request. Failures are logged, the request stands.
+func (s *MapOwnershipService) notifyDeletionRequested(ctx context.Context, m *models.Map, actor MapActor, request models.MapDeletionRequest) {
+	if s.mailer == nil {
+		return
+	}
+
+	recipients, err := s.users.ListByRole(ctx, models.UserRoleSuperAdmin)
+	if err != nil {
+		fmt.Printf("Warning: failed to list superadmins to notify about deleting map %s: %v\n", m.ID, err)
+	}
+	if owner, err := s.users.GetByID(ctx, m.CreatedBy); err == nil {
+		recipients = append(recipients, owner)
+	}
+
+	requester := actor.UserID
+	if user, err := s.users.GetByID(ctx, actor.UserID); err == nil && user.DisplayName != "" {
+		requester = user.DisplayName
+	}
+
+	var to []string
+	seen := make(map[string]bool)
+	for _, user := range recipients {
+		if user.Email == nil || *user.Email == "" || seen[*user.Email] {
+			continue
+		}
+		seen[*user.Email] = true
+		to = append(to, *user.Email)
+	}
+	if len(to) == 0 {
+		return
+	}
+
+	const timeFormat = "Mon, 02 Jan 2006 15:04 MST"
+	locale := m.ContentLocale()
+	var body strings.Builder
+	fmt.Fprintf(&body, "%s\n\n", emailText(locale, "map_deletion.requested", requester, m.Name))
+	fmt.Fprintf(&body, "%s\n", emailText(locale, "map_deletion.confirmable",
+		request.ConfirmableAt.UTC().Format(timeFormat), request.ExpiresAt.UTC().Format(timeFormat)))
+
+	msg := mailer.Message{
+		To:      to,
+		Subject: emailText(locale, "map_deletion.subject", m.Name),
+		Body:    body.String(),
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		fmt.Printf("Warning: failed to notify about deleting map %s: %v\n", m.ID, err)
+	}
+}
+
+// ownedMap returns a map the actor may transfer or delete
+func (s *MapOwnershipService) ownedMap(ctx context.Context, mapID string, actor MapActor) (*models.Map, error) {
+	m, err := s.maps.GetByID(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+	if !m.IsOwnedBy(actor.UserID) && actor.Role != models.UserRoleSuperAdmin {
+		return nil, fmt.Errorf("%w: only the owner of the map can transfer or delete it", ErrForbidden)
+	}
+	return m, nil
+}
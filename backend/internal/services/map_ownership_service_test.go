@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/redis"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type fakeMapOwnershipStore struct {
+	maps      map[string]*models.Map
+	deleted   []string
+	snapshots map[string]*models.MapSnapshot
+}
+
+func (s *fakeMapOwnershipStore) GetByID(ctx context.Context, id string) (*models.Map, error) {
+	m, exists := s.maps[id]
+	if !exists {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *m
+	return &copied, nil
+}
+
+func (s *fakeMapOwnershipStore) UpdateOwner(ctx context.Context, id string, ownerID string) error {
+	s.maps[id].CreatedBy = ownerID
+	s.maps[id].Deletion = models.MapDeletionRequest{}
+	return nil
+}
+
+func (s *fakeMapOwnershipStore) UpdateDeletionRequest(ctx context.Context, id string, request models.MapDeletionRequest) error {
+	s.maps[id].Deletion = request
+	return nil
+}
+
+func (s *fakeMapOwnershipStore) DeleteWithPOIs(ctx context.Context, id string, snapshot *models.MapSnapshot) error {
+	delete(s.maps, id)
+	s.deleted = append(s.deleted, id)
+	s.snapshots[snapshot.ID] = snapshot
+	return nil
+}
+
+func (s *fakeMapOwnershipStore) GetSnapshot(ctx context.Context, id string) (*models.MapSnapshot, error) {
+	snapshot, exists := s.snapshots[id]
+	if !exists {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *snapshot
+	return &copied, nil
+}
+
+func (s *fakeMapOwnershipStore) MarkSnapshotRestored(ctx context.Context, id string, mapID string) error {
+	s.snapshots[id].RestoredMapID = mapID
+	return nil
+}
+
+type fakeMapOwnerLookup map[string]*models.User
+
+func (l fakeMapOwnerLookup) GetByID(ctx context.Context, id string) (*models.User, error) {
+	user, exists := l[id]
+	if !exists {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return user, nil
+}
+
+func (l fakeMapOwnerLookup) ListByRole(ctx context.Context, role models.UserRole) ([]*models.User, error) {
+	var users []*models.User
+	for _, user := range l {
+		if user.Role == role {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+type fakeMapSnapshotArchiver struct {
+	imported []string // Owners of the imported maps
+}
+
+func (a *fakeMapSnapshotArchiver) ExportMap(ctx context.Context, mapID string) (*models.MapArchive, error) {
+	return &models.MapArchive{Version: models.MapArchiveVersion, SourceID: mapID}, nil
+}
+
+func (a *fakeMapSnapshotArchiver) ImportMap(ctx context.Context, archive *models.MapArchive, importedBy string) (*MapImportResult, error) {
+	a.imported = append(a.imported, importedBy)
+	return &MapImportResult{Map: &models.Map{ID: "map-restored", CreatedBy: importedBy}}, nil
+}
+
+type recordingMapDeletionPublisher struct {
+	events []redis.MapDeletedEvent
+}
+
+func (p *recordingMapDeletionPublisher) PublishMapDeleted(ctx context.Context, event redis.MapDeletedEvent) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func newTestMapOwnershipService() (*MapOwnershipService, *fakeMapOwnershipStore, *time.Time) {
+	store := &fakeMapOwnershipStore{
+		maps:      map[string]*models.Map{"map-1": {ID: "map-1", Name: "Workshop", CreatedBy: "owner-1"}},
+		snapshots: make(map[string]*models.MapSnapshot),
+	}
+	ownerEmail, rootEmail := "owner@example.com", "root@example.com"
+	users := fakeMapOwnerLookup{
+		"owner-1":       {ID: "owner-1", DisplayName: "Olga", Email: &ownerEmail, Role: models.UserRoleAdmin},
+		"owner-2":       {ID: "owner-2", Role: models.UserRoleAdmin},
+		"participant-1": {ID: "participant-1", Role: models.UserRoleUser},
+		"root":          {ID: "root", Email: &rootEmail, Role: models.UserRoleSuperAdmin},
+	}
+	service := NewMapOwnershipService(store, users, &fakeMapSnapshotArchiver{})
+	now := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	return service, store, &now
+}
+
+func TestMapOwnershipService_TransferOwnership(t *testing.T) {
+	service, store, _ := newTestMapOwnershipService()
+	ctx := context.Background()
+	owner := MapActor{UserID: "owner-1", Role: models.UserRoleAdmin}
+
+	_, err := service.TransferOwnership(ctx, "map-1", MapActor{UserID: "admin-9", Role: models.UserRoleAdmin}, "owner-2")
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = service.TransferOwnership(ctx, "map-1", owner, "participant-1")
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	_, err = service.TransferOwnership(ctx, "map-1", owner, "missing")
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	// The new owner takes over, and the previous owner's deletion request is void
+	_, err = service.RequestDeletion(ctx, "map-1", owner)
+	require.NoError(t, err)
+	m, err := service.TransferOwnership(ctx, "map-1", owner, "owner-2")
+	require.NoError(t, err)
+	assert.Equal(t, "owner-2", m.CreatedBy)
+	assert.Equal(t, "owner-2", store.maps["map-1"].CreatedBy)
+	assert.Empty(t, store.maps["map-1"].Deletion.TokenHash)
+
+	// Superadmins can transfer any map
+	_, err = service.TransferOwnership(ctx, "map-1", MapActor{UserID: "root", Role: models.UserRoleSuperAdmin}, "owner-2")
+	assert.NoError(t, err)
+}
+
+func TestMapOwnershipService_DeletionFlow(t *testing.T) {
+	service, store, now := newTestMapOwnershipService()
+	ctx := context.Background()
+	owner := MapActor{UserID: "owner-1", Role: models.UserRoleAdmin}
+
+	_, err := service.ConfirmDeletion(ctx, "map-1", owner, "anything")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	ticket, err := service.RequestDeletion(ctx, "map-1", owner)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(models.MapDeletionCooldown), ticket.ConfirmableAt)
+	assert.NotEmpty(t, ticket.Token)
+
+	request, err := service.GetDeletionRequest(ctx, "map-1", owner)
+	require.NoError(t, err)
+	assert.Equal(t, "owner-1", request.RequestedBy)
+
+	// The token only works after the cool-down
+	_, err = service.ConfirmDeletion(ctx, "map-1", owner, ticket.Token)
+	assert.ErrorIs(t, err, ErrDeletionCoolingDown)
+	*now = now.Add(models.MapDeletionCooldown)
+	_, err = service.ConfirmDeletion(ctx, "map-1", owner, "wrong")
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	assert.Empty(t, store.deleted)
+
+	publisher := &recordingMapDeletionPublisher{}
+	service.SetPublisher(publisher)
+	snapshot, err := service.ConfirmDeletion(ctx, "map-1", owner, ticket.Token)
+	require.NoError(t, err)
+	assert.Equal(t, "map-1", snapshot.Archive.SourceID)
+	assert.Equal(t, "owner-1", snapshot.OwnerID)
+	assert.Equal(t, []string{"map-1"}, store.deleted)
+	assert.Contains(t, store.snapshots, snapshot.ID)
+
+	// Every instance disconnects the map's clients
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, "map-1", publisher.events[0].MapID)
+	assert.Equal(t, "owner-1", publisher.events[0].DeletedBy)
+}
+
+func TestMapOwnershipService_RequestDeletionNotifies(t *testing.T) {
+	service, _, _ := newTestMapOwnershipService()
+	mail := &fakeMailer{}
+	service.SetMailer(mail)
+
+	_, err := service.RequestDeletion(context.Background(), "map-1", MapActor{UserID: "owner-1", Role: models.UserRoleAdmin})
+	require.NoError(t, err)
+
+	require.Len(t, mail.sent, 1)
+	assert.ElementsMatch(t, []string{"owner@example.com", "root@example.com"}, mail.sent[0].To)
+	assert.Equal(t, "Deletion of Workshop requested", mail.sent[0].Subject)
+	assert.Contains(t, mail.sent[0].Body, "Olga requested deleting the map Workshop.")
+}
+
+func TestMapOwnershipService_RestoreSnapshot(t *testing.T) {
+	service, store, now := newTestMapOwnershipService()
+	ctx := context.Background()
+	owner := MapActor{UserID: "owner-1", Role: models.UserRoleAdmin}
+	archiver := service.archiver.(*fakeMapSnapshotArchiver)
+
+	ticket, err := service.RequestDeletion(ctx, "map-1", owner)
+	require.NoError(t, err)
+	*now = now.Add(models.MapDeletionCooldown)
+	snapshot, err := service.ConfirmDeletion(ctx, "map-1", owner, ticket.Token)
+	require.NoError(t, err)
+
+	_, err = service.RestoreSnapshot(ctx, "missing", owner)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = service.RestoreSnapshot(ctx, snapshot.ID, MapActor{UserID: "owner-2", Role: models.UserRoleAdmin})
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	// Superadmins restore the map for its owner
+	result, err := service.RestoreSnapshot(ctx, snapshot.ID, MapActor{UserID: "root", Role: models.UserRoleSuperAdmin})
+	require.NoError(t, err)
+	assert.Equal(t, "map-restored", result.Map.ID)
+	assert.Equal(t, []string{"owner-1"}, archiver.imported)
+	assert.Equal(t, "map-restored", store.snapshots[snapshot.ID].RestoredMapID)
+
+	// A snapshot is only restored once
+	_, err = service.RestoreSnapshot(ctx, snapshot.ID, owner)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
+
+func TestMapOwnershipService_CancelAndExpireDeletion(t *testing.T) {
+	service, store, now := newTestMapOwnershipService()
+	ctx := context.Background()
+	owner := MapActor{UserID: "owner-1", Role: models.UserRoleAdmin}
+
+	ticket, err := service.RequestDeletion(ctx, "map-1", owner)
+	require.NoError(t, err)
+	require.NoError(t, service.CancelDeletion(ctx, "map-1", owner))
+	*now = now.Add(models.MapDeletionCooldown)
+	_, err = service.ConfirmDeletion(ctx, "map-1", owner, ticket.Token)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	ticket, err = service.RequestDeletion(ctx, "map-1", owner)
+	require.NoError(t, err)
+	*now = now.Add(models.MapDeletionValidity)
+	_, err = service.ConfirmDeletion(ctx, "map-1", owner, ticket.Token)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Empty(t, store.deleted)
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"breakoutglobe/internal/models"
+)
+
+// mapSettingsCacheTTL is how long map settings read on every avatar move are cached.
+// Changes made on another instance apply after at most this long.
+const mapSettingsCacheTTL = 30 * time.Second
+
+// MapSettingsStore defines the interface for reading and updating map settings
+type MapSettingsStore interface {
+	GetByID(ctx context.Context, id string) (*models.Map, error)
+	UpdatePersonalSpace(ctx context.Context, id string, personalSpace models.PersonalSpace) error
+	UpdateSlowConsumerPolicy(ctx context.Context, id string, policy models.SlowConsumerPolicy) error
+	UpdatePositionPrivacy(ctx context.Context, id string, privacy models.PositionPrivacy) error
+	UpdateProfilePrivacy(ctx context.Context, id string, privacy models.ProfilePrivacy) error
+	UpdateGuestAnonymization(ctx context.Context, id string, anonymization models.GuestAnonymization) error
+	UpdateMessageAuditPolicy(ctx context.Context, id string, policy models.MessageAuditPolicy) error
+	UpdateLocale(ctx context.Context, id string, locale string) error
+}
+
+// MapSettingsService serves per-map settings that are checked on hot paths
+// like avatar movement, caching them in memory
+type MapSettingsService struct {
+	maps  MapSettingsStore
+	mutex sync.Mutex
+	cache map[string]cachedMapSettings
+	now   func() time.Time
+}
+
+type cachedMapSettings struct {
+	personalSpace models.PersonalSpace
+	slowConsumer  models.SlowConsumerPolicy
+	privacy       models.PositionPrivacy
+	profile       models.ProfilePrivacy
+	anonymization models.GuestAnonymization
+	messageAudit  models.MessageAuditPolicy
+	locale        string
+	expiresAt     time.Time
+}
+
+// NewMapSettingsService creates a new MapSettingsService instance
+func NewMapSettingsService(maps MapSettingsStore) *MapSettingsService {
+	return &MapSettingsService{
+		maps:  maps,
+		cache: make(map[string]cachedMapSettings),
+		now:   time.Now,
+	}
+}
+
+// GetPersonalSpace returns the personal space setting of a map
+func (s *MapSettingsService) GetPersonalSpace(ctx context.Context, mapID string) (models.PersonalSpace, error) {
+	settings, err := s.settings(ctx, mapID)
+	if err != nil {
+		return models.PersonalSpace{}, err
+	}
+	return settings.personalSpace, nil
+}
+
+// GetSlowConsumerPolicy returns how a map treats clients that fall behind its broadcasts
+func (s *MapSettingsService) GetSlowConsumerPolicy(ctx context.Context, mapID string) (models.SlowConsumerPolicy, error) {
+	settings, err := s.settings(ctx, mapID)
+	if err != nil {
+		return models.SlowConsumerPolicy{}, err
+	}
+	return settings.slowConsumer, nil
+}
+
+// GetPositionPrivacy returns whether a map shows coarse avatar positions to regular participants
+func (s *MapSettingsService) GetPositionPrivacy(ctx context.Context, mapID string) (models.PositionPrivacy, error) {
+	settings, err := s.settings(ctx, mapID)
+	if err != nil {
+		return models.PositionPrivacy{}, err
+	}
+	return settings.privacy, nil
+}
+
+// GetProfilePrivacy returns which profile fields a map leaves out of the
+// payloads shared with its participants
+func (s *MapSettingsService) GetProfilePrivacy(ctx context.Context, mapID string) (models.ProfilePrivacy, error) {
+	settings, err := s.settings(ctx, mapID)
+	if err != nil {
+		return models.ProfilePrivacy{}, err
+	}
+	return settings.profile, nil
+}
+
+// GetGuestAnonymization returns whether and when a map anonymizes guests who left it
+func (s *MapSettingsService) GetGuestAnonymization(ctx context.Context, mapID string) (models.GuestAnonymization, error) {
+	settings, err := s.settings(ctx, mapID)
+	if err != nil {
+		return models.GuestAnonymization{}, err
+	}
+	return settings.anonymization, nil
+}
+
+// GetMessageAuditPolicy returns which WebSocket messages of a map are recorded to the audit log
+func (s *MapSettingsService) GetMessageAuditPolicy(ctx context.Context, mapID string) (models.MessageAuditPolicy, error) {
+	settings, err := s.settings(ctx, mapID)
+	if err != nil {
+		return models.MessageAuditPolicy{}, err
+	}
+	return settings.messageAudit, nil
+}
+
+// GetLocale returns the locale of a map's server-generated content
+func (s *MapSettingsService) GetLocale(ctx context.Context, mapID string) (models.MapLocale, error) {
+	settings, err := s.settings(ctx, mapID)
+	if err != nil {
+		return models.MapLocale{}, err
+	}
+	return models.MapLocale{Locale: settings.locale}, nil
+}
+
+// settings returns the cached settings of a map, loading them if missing or expired
+func (s *MapSettingsService) settings(ctx context.Context, mapID string) (cachedMapSettings, error) {
+	s.mutex.Lock()
+	cached, exists := s.cache[mapID]
+	s.mutex.Unlock()
+	if exists && s.now().Before(cached.expiresAt) {
+		return cached, nil
+	}
+
+	m, err := s.maps.GetByID(ctx, mapID)
+	if err != nil {
+		return cachedMapSettings{}, err
+	}
+
+	cached = cachedMapSettings{
+		personalSpace: m.PersonalSpace,
+		slowConsumer:  m.SlowConsumer,
+		privacy:       m.PositionPrivacy,
+		profile:       m.ProfilePrivacy,
+		anonymization: m.GuestAnonymization,
+		messageAudit:  m.MessageAudit,
+		locale:        m.ContentLocale(),
+		expiresAt:     s.now().Add(mapSettingsCacheTTL),
+	}
+	s.mutex.Lock()
+	s.cache[mapID] = cached
+	s.mutex.Unlock()
+
+	return cached, nil
+}
+
+// SetPersonalSpace updates the personal space setting of a map
+func (s *MapSettingsService) SetPersonalSpace(ctx context.Context, mapID string, personalSpace models.PersonalSpace) error {
+	if err := personalSpace.Validate(); err != nil {
+		return fmt.Errorf("invalid personal space: %w", err)
+	}
+
+	if err := s.maps.UpdatePersonalSpace(ctx, mapID, personalSpace); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	delete(s.cache, mapID)
+	s.mutex.Unlock()
+
+	return nil
+}
+
+// SetSlowConsumerPolicy updates how a map treats clients that fall behind its
+// broadcasts. Connected clients keep their policy until they reconnect.
+func (s *MapSettingsService) SetSlowConsumerPolicy(ctx context.Context, mapID string, policy models.SlowConsumerPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return fmt.Errorf("invalid slow consumer policy: %w", err)
+	}
+
+	if err := s.maps.UpdateSlowConsumerPolicy(ctx, mapID, policy); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	delete(s.cache, mapID)
+	s.mutex.Unlock()
+
+	return nil
+}
+
+// SetPositionPrivacy updates whether a map shows coarse avatar positions to
+// regular participants
+func (s *MapSettingsService) SetPositionPrivacy(ctx context.Context, mapID string, privacy models.PositionPrivacy) error {
+	if err := privacy.Validate(); err != nil {
+		return fmt.Errorf("invalid position privacy: %w", err)
+	}
+
+	if err := s.maps.UpdatePositionPrivacy(ctx, mapID, privacy); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	delete(s.cache, mapID)
+	s.mutex.Unlock()
+
+	return nil
+}
+
+// SetProfilePrivacy updates which profile fields a map leaves out of the
+// payloads shared with its participants. Payloads already sent keep them.
+func (s *MapSettingsService) SetProfilePrivacy(ctx context.Context, mapID string, privacy models.ProfilePrivacy) error {
+	if err := s.maps.UpdateProfilePrivacy(ctx, mapID, privacy); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	delete(s.cache, mapID)
+	s.mutex.Unlock()
+
+	return nil
+}
+
+// SetGuestAnonymization updates whether and when a map anonymizes guests who left it
+func (s *MapSettingsService) SetGuestAnonymization(ctx context.Context, mapID string, anonymization models.GuestAnonymization) error {
+	if err := anonymization.Validate(); err != nil {
+		return fmt.Errorf("invalid guest anonymization: %w", err)
+	}
+
+	if err := s.maps.UpdateGuestAnonymization(ctx, mapID, anonymization); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	delete(s.cache, mapID)
+	s.mutex.Unlock()
+
+	return nil
+}
+
+// SetMessageAuditPolicy updates which WebSocket messages of a map are recorded
+// to the audit log. It applies to connected clients too.
+func (s *MapSettingsService) SetMessageAuditPolicy(ctx context.Context, mapID string, policy models.MessageAuditPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return fmt.Errorf("invalid message audit policy: %w", err)
+	}
+
+	if err := s.maps.UpdateMessageAuditPolicy(ctx, mapID, policy); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	delete(s.cache, mapID)
+	s.mutex.Unlock()
+
+	return nil
+}
+
+// SetLocale updates the locale of a map's server-generated content and
+// returns it normalized. Emails sent afterwards use the new locale.
+func (s *MapSettingsService) SetLocale(ctx context.Context, mapID string, locale models.MapLocale) (models.MapLocale, error) {
+	normalized, err := models.NormalizeMapLocale(locale.Locale)
+	if err != nil {
+		return models.MapLocale{}, fmt.Errorf("invalid locale: %w", err)
+	}
+
+	if err := s.maps.UpdateLocale(ctx, mapID, normalized); err != nil {
+		return models.MapLocale{}, err
+	}
+
+	s.mutex.Lock()
+	delete(s.cache, mapID)
+	s.mutex.Unlock()
+
+	return models.MapLocale{Locale: normalized}, nil
+}
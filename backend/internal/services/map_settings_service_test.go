@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeMapSettingsStore struct {
+	maps  map[string]*models.Map
+	reads int
+}
+
+func (s *fakeMapSettingsStore) GetByID(ctx context.Context, id string) (*models.Map, error) {
+	s.reads++
+	m, exists := s.maps[id]
+	if !exists {
+		return nil, fmt.Errorf("map not found")
+	}
+	return m, nil
+}
+
+func (s *fakeMapSettingsStore) UpdatePersonalSpace(ctx context.Context, id string, personalSpace models.PersonalSpace) error {
+	m, exists := s.maps[id]
+	if !exists {
+		return fmt.Errorf("map not found")
+	}
+	m.PersonalSpace = personalSpace
+	return nil
+}
+
+func (s *fakeMapSettingsStore) UpdateSlowConsumerPolicy(ctx context.Context, id string, policy models.SlowConsumerPolicy) error {
+	m, exists := s.maps[id]
+	if !exists {
+		return fmt.Errorf("map not found")
+	}
+	m.SlowConsumer = policy
+	return nil
+}
+
+func (s *fakeMapSettingsStore) UpdatePositionPrivacy(ctx context.Context, id string, privacy models.PositionPrivacy) error {
+	m, exists := s.maps[id]
+	if !exists {
+		return fmt.Errorf("map not found")
+	}
+	m.PositionPrivacy = privacy
+	return nil
+}
+
+func (s *fakeMapSettingsStore) UpdateProfilePrivacy(ctx context.Context, id string, privacy models.ProfilePrivacy) error {
+	m, exists := s.maps[id]
+	if !exists {
+		return fmt.Errorf("map not found")
+	}
+	m.ProfilePrivacy = privacy
+	return nil
+}
+
+func (s *fakeMapSettingsStore) UpdateGuestAnonymization(ctx context.Context, id string, anonymization models.GuestAnonymization) error {
+	m, exists := s.maps[id]
+	if !exists {
+		return fmt.Errorf("map not found")
+	}
+	m.GuestAnonymization = anonymization
+	return nil
+}
+
+func (s *fakeMapSettingsStore) UpdateMessageAuditPolicy(ctx context.Context, id string, policy models.MessageAuditPolicy) error {
+	m, exists := s.maps[id]
+	if !exists {
+		return fmt.Errorf("map not found")
+	}
+	m.MessageAudit = policy
+	return nil
+}
+
+func (s *fakeMapSettingsStore) UpdateLocale(ctx context.Context, id string, locale string) error {
+	m, exists := s.maps[id]
+	if !exists {
+		return fmt.Errorf("map not found")
+	}
+	m.Locale = locale
+	return nil
+}
+
+func TestMapSettingsService_CachesPersonalSpace(t *testing.T) {
+	store := &fakeMapSettingsStore{maps: map[string]*models.Map{"map-1": {ID: "map-1"}}}
+	service := NewMapSettingsService(store)
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	personalSpace, err := service.GetPersonalSpace(ctx, "map-1")
+	assert.NoError(t, err)
+	assert.False(t, personalSpace.Enabled())
+
+	_, _ = service.GetPersonalSpace(ctx, "map-1")
+	assert.Equal(t, 1, store.reads)
+
+	// Updates invalidate the cache
+	adjust := models.PersonalSpace{Mode: models.PersonalSpaceAdjust, RadiusMeters: 5}
+	assert.NoError(t, service.SetPersonalSpace(ctx, "map-1", adjust))
+	personalSpace, _ = service.GetPersonalSpace(ctx, "map-1")
+	assert.Equal(t, adjust, personalSpace)
+	assert.Equal(t, 2, store.reads)
+
+	// Cached entries expire
+	now = now.Add(mapSettingsCacheTTL)
+	_, _ = service.GetPersonalSpace(ctx, "map-1")
+	assert.Equal(t, 3, store.reads)
+}
+
+func TestMapSettingsService_SetPersonalSpace_Invalid(t *testing.T) {
+	store := &fakeMapSettingsStore{maps: map[string]*models.Map{"map-1": {ID: "map-1"}}}
+	service := NewMapSettingsService(store)
+
+	err := service.SetPersonalSpace(context.Background(), "map-1", models.PersonalSpace{Mode: "bounce", RadiusMeters: 5})
+	assert.ErrorContains(t, err, "invalid personal space")
+
+	err = service.SetPersonalSpace(context.Background(), "map-1", models.PersonalSpace{Mode: models.PersonalSpaceReject, RadiusMeters: 5000})
+	assert.ErrorContains(t, err, "invalid personal space")
+}
+
+func TestMapSettingsService_SlowConsumerPolicy(t *testing.T) {
+	store := &fakeMapSettingsStore{maps: map[string]*models.Map{"map-1": {ID: "map-1"}}}
+	service := NewMapSettingsService(store)
+	ctx := context.Background()
+
+	policy, err := service.GetSlowConsumerPolicy(ctx, "map-1")
+	assert.NoError(t, err)
+	assert.Equal(t, models.DefaultSendBufferSize, policy.BufferSize())
+	assert.Equal(t, models.SlowConsumerDisconnect, policy.DropPolicy)
+
+	// Personal space and slow consumer policy share one cached read
+	_, _ = service.GetPersonalSpace(ctx, "map-1")
+	assert.Equal(t, 1, store.reads)
+
+	webinar := models.SlowConsumerPolicy{SendBufferSize: 1024, DropPolicy: models.SlowConsumerDropOldest, EvictAfter: 500}
+	assert.NoError(t, service.SetSlowConsumerPolicy(ctx, "map-1", webinar))
+	policy, _ = service.GetSlowConsumerPolicy(ctx, "map-1")
+	assert.Equal(t, webinar, policy)
+	assert.Equal(t, 1024, policy.BufferSize())
+
+	err = service.SetSlowConsumerPolicy(ctx, "map-1", models.SlowConsumerPolicy{DropPolicy: "drop_all"})
+	assert.ErrorContains(t, err, "invalid slow consumer policy")
+
+	err = service.SetSlowConsumerPolicy(ctx, "map-1", models.SlowConsumerPolicy{SendBufferSize: 4})
+	assert.ErrorContains(t, err, "invalid slow consumer policy")
+}
+
+func TestMapSettingsService_PositionPrivacy(t *testing.T) {
+	store := &fakeMapSettingsStore{maps: map[string]*models.Map{"map-1": {ID: "map-1"}}}
+	service := NewMapSettingsService(store)
+	ctx := context.Background()
+
+	privacy, err := service.GetPositionPrivacy(ctx, "map-1")
+	assert.NoError(t, err)
+	assert.False(t, privacy.Enabled())
+
+	assert.NoError(t, service.SetPositionPrivacy(ctx, "map-1", models.PositionPrivacy{GridMeters: 500}))
+	privacy, _ = service.GetPositionPrivacy(ctx, "map-1")
+	assert.Equal(t, 500.0, privacy.GridMeters)
+
+	err = service.SetPositionPrivacy(ctx, "map-1", models.PositionPrivacy{GridMeters: 1})
+	assert.ErrorContains(t, err, "invalid position privacy")
+}
+
+func TestMapSettingsService_ProfilePrivacy(t *testing.T) {
+	store := &fakeMapSettingsStore{maps: map[string]*models.Map{"map-1": {ID: "map-1"}}}
+	service := NewMapSettingsService(store)
+	ctx := context.Background()
+
+	privacy, err := service.GetProfilePrivacy(ctx, "map-1")
+	assert.NoError(t, err)
+	assert.False(t, privacy.Enabled())
+
+	assert.NoError(t, service.SetProfilePrivacy(ctx, "map-1", models.ProfilePrivacy{HideAboutMe: true}))
+	privacy, _ = service.GetProfilePrivacy(ctx, "map-1")
+	assert.Equal(t, models.ProfilePrivacy{HideAboutMe: true}, privacy)
+
+	err = service.SetProfilePrivacy(ctx, "map-2", models.ProfilePrivacy{HideAvatarURL: true})
+	assert.Error(t, err)
+}
+
+func TestMapSettingsService_GuestAnonymization(t *testing.T) {
+	store := &fakeMapSettingsStore{maps: map[string]*models.Map{"map-1": {ID: "map-1"}}}
+	service := NewMapSettingsService(store)
+	ctx := context.Background()
+
+	anonymization, err := service.GetGuestAnonymization(ctx, "map-1")
+	assert.NoError(t, err)
+	assert.False(t, anonymization.Enabled)
+
+	assert.NoError(t, service.SetGuestAnonymization(ctx, "map-1", models.GuestAnonymization{Enabled: true, RetentionHours: 72}))
+	anonymization, _ = service.GetGuestAnonymization(ctx, "map-1")
+	assert.Equal(t, models.GuestAnonymization{Enabled: true, RetentionHours: 72}, anonymization)
+
+	err = service.SetGuestAnonymization(ctx, "map-1", models.GuestAnonymization{Enabled: true, RetentionHours: -1})
+	assert.ErrorContains(t, err, "invalid guest anonymization")
+}
+
+func TestMapSettingsService_MessageAuditPolicy(t *testing.T) {
+	store := &fakeMapSettingsStore{maps: map[string]*models.Map{"map-1": {ID: "map-1"}}}
+	service := NewMapSettingsService(store)
+	ctx := context.Background()
+
+	policy, err := service.GetMessageAuditPolicy(ctx, "map-1")
+	assert.NoError(t, err)
+	assert.False(t, policy.Enabled())
+
+	sampled := models.MessageAuditPolicy{SampleRate: 0.01, FlaggedSessions: []string{"session-1"}}
+	assert.NoError(t, service.SetMessageAuditPolicy(ctx, "map-1", sampled))
+	policy, _ = service.GetMessageAuditPolicy(ctx, "map-1")
+	assert.Equal(t, sampled, policy)
+	assert.True(t, policy.IsFlagged("session-1"))
+
+	err = service.SetMessageAuditPolicy(ctx, "map-1", models.MessageAuditPolicy{SampleRate: 1.5})
+	assert.ErrorContains(t, err, "invalid message audit policy")
+}
+
+func TestMapSettingsService_Locale(t *testing.T) {
+	store := &fakeMapSettingsStore{maps: map[string]*models.Map{"map-1": {ID: "map-1"}}}
+	service := NewMapSettingsService(store)
+	ctx := context.Background()
+
+	locale, err := service.GetLocale(ctx, "map-1")
+	assert.NoError(t, err)
+	assert.Equal(t, models.DefaultMapLocale, locale.Locale)
+
+	updated, err := service.SetLocale(ctx, "map-1", models.MapLocale{Locale: "fr-CA"})
+	assert.NoError(t, err)
+	assert.Equal(t, "fr", updated.Locale)
+	locale, _ = service.GetLocale(ctx, "map-1")
+	assert.Equal(t, "fr", locale.Locale)
+
+	_, err = service.SetLocale(ctx, "map-1", models.MapLocale{Locale: "xx"})
+	assert.ErrorContains(t, err, "invalid locale")
+}
@@ -100,6 +100,22 @@ func (m *MockUserService) UploadAvatar(ctx context.Context, userID string, filen
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
+func (m *MockUserService) DeleteAvatar(ctx context.Context, userID string) (*models.User, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserService) UpdateRole(ctx context.Context, userID string, role models.UserRole) (*models.User, error) {
+	args := m.Called(ctx, userID, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
 func (m *MockUserService) UpdateProfile(ctx context.Context, userID string, req *UpdateProfileRequest) (*models.User, error) {
 	args := m.Called(ctx, userID, req)
 	if args.Get(0) == nil {
@@ -127,6 +143,16 @@ func (m *MockPubSub) PublishPOIUpdated(ctx context.Context, event redis.POIUpdat
 	return args.Error(0)
 }
 
+func (m *MockPubSub) PublishPOIDeleted(ctx context.Context, event redis.POIDeletedEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockPubSub) PublishPOIMerged(ctx context.Context, event redis.POIMergedEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
 func (m *MockPubSub) PublishPOIJoined(ctx context.Context, event redis.POIJoinedEvent) error {
 	args := m.Called(ctx, event)
 	return args.Error(0)
@@ -142,12 +168,12 @@ func (m *MockPubSub) PublishAvatarMovement(ctx context.Context, event redis.Avat
 	return args.Error(0)
 }
 
-func (m *MockPubSub) PublishPOIJoinedWithParticipants(ctx context.Context, event redis.POIJoinedEventWithParticipants) error {
+func (m *MockPubSub) PublishPOIParticipantAdded(ctx context.Context, event redis.POIParticipantDeltaEvent) error {
 	args := m.Called(ctx, event)
 	return args.Error(0)
 }
 
-func (m *MockPubSub) PublishPOILeftWithParticipants(ctx context.Context, event redis.POILeftEventWithParticipants) error {
+func (m *MockPubSub) PublishPOIParticipantRemoved(ctx context.Context, event redis.POIParticipantDeltaEvent) error {
 	args := m.Called(ctx, event)
 	return args.Error(0)
 }
\ No newline at end of file
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/redis"
+)
+
+// MaxMapBanDuration bounds how long a kicked user can be banned from a map
+const MaxMapBanDuration = 30 * 24 * time.Hour
+
+// MapBanStore defines the interface for storing who is banned from maps
+type MapBanStore interface {
+	AddMapBan(ctx context.Context, ban models.MapBan) error
+	GetMapBan(ctx context.Context, mapID, userID string) (*models.MapBan, error)
+}
+
+// ModerationSessions defines the interface for finding and ending the sessions of a map
+type ModerationSessions interface {
+	GetActiveSessionsForMap(ctx context.Context, mapID string) ([]*models.Session, error)
+	EndSession(ctx context.Context, sessionID string) error
+}
+
+// ModerationPublisher defines the interface for telling all instances about kicks
+type ModerationPublisher interface {
+	PublishUserKicked(ctx context.Context, event redis.UserKickedEvent) error
+}
+
+// KickResult is the outcome of kicking a user from a map
+type KickResult struct {
+	UserID        string     `json:"userId"`
+	EndedSessions []string   `json:"endedSessions"`
+	BannedUntil   *time.Time `json:"bannedUntil,omitempty"`
+}
+
+// ModerationService lets facilitators remove disruptive users from a map:
+// their sessions on the map end, every instance closes their connections,
+// and a ban optionally keeps them from reconnecting for a while.
+type ModerationService struct {
+	bans      MapBanStore
+	sessions  ModerationSessions
+	publisher ModerationPublisher
+	now       func() time.Time
+}
+
+// NewModerationService creates a new ModerationService instance
+func NewModerationService(bans MapBanStore, sessions ModerationSessions, publisher ModerationPublisher) *ModerationService {
+	return &ModerationService{
+		bans:      bans,
+		sessions:  sessions,
+		publisher: publisher,
+		now:       time.Now,
+	}
+}
+
+// KickUser ends the sessions of a user on a map and disconnects them. With a
+// positive banFor, the user can't reconnect to the map until it passed.
+// Facilitators can't kick themselves.
+func (s *ModerationService) KickUser(ctx context.Context, mapID, kickedBy, userID string, banFor time.Duration) (*KickResult, error) {
+	if mapID == "" || userID == "" {
+		return nil, fmt.Errorf("%w: map ID and user ID are required", ErrInvalidInput)
+	}
+	if userID == kickedBy {
+		return nil, fmt.Errorf("%w: can't kick yourself", ErrInvalidInput)
+	}
+	if banFor < 0 || banFor > MaxMapBanDuration {
+		return nil, fmt.Errorf("%w: ban must be between 0 and %s", ErrInvalidInput, MaxMapBanDuration)
+	}
+
+	now := s.now()
+	result := &KickResult{UserID: userID, EndedSessions: []string{}}
+
+	// Ban before disconnecting, so the user can't slip back in
+	if banFor > 0 {
+		ban := models.MapBan{MapID: mapID, UserID: userID, BannedBy: kickedBy, Until: now.Add(banFor)}
+		if err := s.bans.AddMapBan(ctx, ban); err != nil {
+			return nil, fmt.Errorf("failed to ban user: %w", err)
+		}
+		result.BannedUntil = &ban.Until
+	}
+
+	sessions, err := s.sessions.GetActiveSessionsForMap(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+	for _, session := range sessions {
+		if session.UserID != userID {
+			continue
+		}
+		if err := s.sessions.EndSession(ctx, session.ID); err != nil {
+			return nil, fmt.Errorf("failed to end session %s: %w", session.ID, err)
+		}
+		result.EndedSessions = append(result.EndedSessions, session.ID)
+	}
+
+	event := redis.UserKickedEvent{
+		MapID:       mapID,
+		UserID:      userID,
+		KickedBy:    kickedBy,
+		BannedUntil: result.BannedUntil,
+		Timestamp:   now,
+	}
+	if err := s.publisher.PublishUserKicked(ctx, event); err != nil {
+		return nil, fmt.Errorf("failed to publish user kicked event: %w", err)
+	}
+	return result, nil
+}
+
+// GetMapBan returns the ban keeping a user from a map, or nil if they aren't banned
+func (s *ModerationService) GetMapBan(ctx context.Context, mapID, userID string) (*models.MapBan, error) {
+	ban, err := s.bans.GetMapBan(ctx, mapID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if ban == nil || !ban.Active(s.now()) {
+		return nil, nil
+	}
+	return ban, nil
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/redis"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeMapBans struct {
+	bans map[string]models.MapBan // mapID/userID -> ban
+}
+
+func (b *fakeMapBans) AddMapBan(ctx context.Context, ban models.MapBan) error {
+	b.bans[ban.MapID+"/"+ban.UserID] = ban
+	return nil
+}
+
+func (b *fakeMapBans) GetMapBan(ctx context.Context, mapID, userID string) (*models.MapBan, error) {
+	ban, ok := b.bans[mapID+"/"+userID]
+	if !ok {
+		return nil, nil
+	}
+	return &ban, nil
+}
+
+type fakeModerationSessions struct {
+	sessions []*models.Session
+	ended    []string
+}
+
+func (s *fakeModerationSessions) GetActiveSessionsForMap(ctx context.Context, mapID string) ([]*models.Session, error) {
+	return s.sessions, nil
+}
+
+func (s *fakeModerationSessions) EndSession(ctx context.Context, sessionID string) error {
+	s.ended = append(s.ended, sessionID)
+	return nil
+}
+
+type recordingModerationPublisher struct {
+	events []redis.UserKickedEvent
+}
+
+func (p *recordingModerationPublisher) PublishUserKicked(ctx context.Context, event redis.UserKickedEvent) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func newModerationTestService() (*ModerationService, *fakeMapBans, *fakeModerationSessions, *recordingModerationPublisher) {
+	bans := &fakeMapBans{bans: make(map[string]models.MapBan)}
+	sessions := &fakeModerationSessions{sessions: []*models.Session{
+		{ID: "session-1", UserID: "facilitator", MapID: "map-1"},
+		{ID: "session-2", UserID: "user-2", MapID: "map-1"},
+		{ID: "session-3", UserID: "user-2", MapID: "map-1"},
+	}}
+	publisher := &recordingModerationPublisher{}
+	return NewModerationService(bans, sessions, publisher), bans, sessions, publisher
+}
+
+func TestModerationService_KickUser(t *testing.T) {
+	service, bans, sessions, publisher := newModerationTestService()
+	ctx := context.Background()
+
+	result, err := service.KickUser(ctx, "map-1", "facilitator", "user-2", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"session-2", "session-3"}, result.EndedSessions)
+	assert.Equal(t, []string{"session-2", "session-3"}, sessions.ended)
+	assert.Nil(t, result.BannedUntil)
+	assert.Empty(t, bans.bans)
+
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, "map-1", publisher.events[0].MapID)
+	assert.Equal(t, "user-2", publisher.events[0].UserID)
+	assert.Equal(t, "facilitator", publisher.events[0].KickedBy)
+
+	ban, err := service.GetMapBan(ctx, "map-1", "user-2")
+	require.NoError(t, err)
+	assert.Nil(t, ban)
+}
+
+func TestModerationService_KickUser_Bans(t *testing.T) {
+	service, _, _, publisher := newModerationTestService()
+	now := time.Now()
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	result, err := service.KickUser(ctx, "map-1", "facilitator", "user-2", time.Hour)
+	require.NoError(t, err)
+	require.NotNil(t, result.BannedUntil)
+	assert.Equal(t, now.Add(time.Hour), *result.BannedUntil)
+	assert.Equal(t, result.BannedUntil, publisher.events[0].BannedUntil)
+
+	ban, err := service.GetMapBan(ctx, "map-1", "user-2")
+	require.NoError(t, err)
+	require.NotNil(t, ban)
+	assert.Equal(t, "facilitator", ban.BannedBy)
+
+	// Bans on other maps don't apply, and bans lift when they expire
+	ban, err = service.GetMapBan(ctx, "map-2", "user-2")
+	require.NoError(t, err)
+	assert.Nil(t, ban)
+	now = now.Add(time.Hour)
+	ban, err = service.GetMapBan(ctx, "map-1", "user-2")
+	require.NoError(t, err)
+	assert.Nil(t, ban)
+}
+
+func TestModerationService_KickUser_InvalidInput(t *testing.T) {
+	service, _, sessions, publisher := newModerationTestService()
+	ctx := context.Background()
+
+	_, err := service.KickUser(ctx, "map-1", "facilitator", "facilitator", 0)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	_, err = service.KickUser(ctx, "map-1", "facilitator", "", 0)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	_, err = service.KickUser(ctx, "map-1", "facilitator", "user-2", MaxMapBanDuration+time.Second)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	assert.Empty(t, sessions.ended)
+	assert.Empty(t, publisher.events)
+}
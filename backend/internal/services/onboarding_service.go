@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/redis"
+)
+
+// OnboardingStore defines the interface for reading and recording completed
+// welcome checklist steps
+type OnboardingStore interface {
+	ListByUser(ctx context.Context, userID string) ([]*models.UserOnboardingStep, error)
+	Complete(ctx context.Context, step *models.UserOnboardingStep) (bool, error)
+}
+
+// OnboardingPublisher defines the interface for publishing onboarding progress
+// to the user's connected clients
+type OnboardingPublisher interface {
+	PublishOnboardingProgress(ctx context.Context, event redis.OnboardingProgressEvent) error
+}
+
+// OnboardingTracker defines the interface for completing welcome checklist
+// steps from the features that make up the checklist
+type OnboardingTracker interface {
+	CompleteStep(ctx context.Context, userID string, step models.OnboardingStep) error
+}
+
+// OnboardingService tracks the welcome checklist of users server-side, so the
+// tutorial shows the same progress on every device and after reconnects
+type OnboardingService struct {
+	store      OnboardingStore
+	publisher  OnboardingPublisher
+	activeMaps ActiveMapLister
+	now        func() time.Time
+
+	// completed remembers steps known to be completed, so frequent actions like
+	// avatar moves don't hit the store once their step is done
+	mutex     sync.RWMutex
+	completed map[string]map[models.OnboardingStep]bool
+}
+
+// NewOnboardingService creates a new OnboardingService instance
+func NewOnboardingService(store OnboardingStore) *OnboardingService {
+	return &OnboardingService{
+		store:     store,
+		now:       time.Now,
+		completed: make(map[string]map[models.OnboardingStep]bool),
+	}
+}
+
+// SetPublisher sets where completed steps are published, so the user's
+// connected clients update their checklist. Without one, clients see progress
+// on their next /auth/me request.
+func (s *OnboardingService) SetPublisher(publisher OnboardingPublisher, activeMaps ActiveMapLister) {
+	s.publisher = publisher
+	s.activeMaps = activeMaps
+}
+
+// GetProgress returns the state of a user's welcome checklist
+func (s *OnboardingService) GetProgress(ctx context.Context, userID string) (models.OnboardingProgress, error) {
+	completed, err := s.store.ListByUser(ctx, userID)
+	if err != nil {
+		return models.OnboardingProgress{}, err
+	}
+	return models.NewOnboardingProgress(completed), nil
+}
+
+// CompleteStep records that a user completed a step of the welcome checklist
+// and publishes the new progress. Completing a step again changes nothing.
+func (s *OnboardingService) CompleteStep(ctx context.Context, userID string, step models.OnboardingStep) error {
+	if !step.IsValid() {
+		return fmt.Errorf("%w: unknown onboarding step %q", ErrInvalidInput, step)
+	}
+	if s.isCompleted(userID, step) {
+		return nil
+	}
+
+	created, err := s.store.Complete(ctx, &models.UserOnboardingStep{
+		UserID:      userID,
+		Step:        step,
+		CompletedAt: s.now(),
+	})
+	if err != nil {
+		return err
+	}
+	s.markCompleted(userID, step)
+	if !created {
+		return nil
+	}
+
+	s.publishProgress(ctx, userID, step)
+	return nil
+}
+
+// publishProgress publishes a user's progress to every map the user has an
+// active session in
+func (s *OnboardingService) publishProgress(ctx context.Context, userID string, step models.OnboardingStep) {
+	if s.publisher == nil || s.activeMaps == nil {
+		return
+	}
+
+	mapIDs, err := s.activeMaps.GetActiveMapIDsByUser(userID)
+	if err != nil {
+		log.Printf("Warning: failed to get active maps for onboarding progress of user %s: %v", userID, err)
+		return
+	}
+	if len(mapIDs) == 0 {
+		return
+	}
+
+	progress, err := s.GetProgress(ctx, userID)
+	if err != nil {
+		log.Printf("Warning: failed to get onboarding progress of user %s: %v", userID, err)
+		return
+	}
+
+	for _, mapID := range mapIDs {
+		event := redis.OnboardingProgressEvent{
+			UserID:    userID,
+			MapID:     mapID,
+			Step:      step,
+			Progress:  progress,
+			Timestamp: s.now(),
+		}
+		if err := s.publisher.PublishOnboardingProgress(ctx, event); err != nil {
+			log.Printf("Warning: failed to publish onboarding progress of user %s: %v", userID, err)
+		}
+	}
+}
+
+// isCompleted reports whether a step is known to be completed by a user
+func (s *OnboardingService) isCompleted(userID string, step models.OnboardingStep) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.completed[userID][step]
+}
+
+// markCompleted remembers that a user completed a step
+func (s *OnboardingService) markCompleted(userID string, step models.OnboardingStep) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.completed[userID] == nil {
+		s.completed[userID] = make(map[models.OnboardingStep]bool)
+	}
+	s.completed[userID][step] = true
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/memory"
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/redis"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingOnboardingPublisher struct {
+	events []redis.OnboardingProgressEvent
+}
+
+func (p *recordingOnboardingPublisher) PublishOnboardingProgress(ctx context.Context, event redis.OnboardingProgressEvent) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func TestOnboardingService_CompleteStep(t *testing.T) {
+	publisher := &recordingOnboardingPublisher{}
+	service := NewOnboardingService(memory.NewStore().Onboarding())
+	service.SetPublisher(publisher, staticActiveMaps{"map-1", "map-2"})
+	now := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	progress, err := service.GetProgress(ctx, "user-1")
+	require.NoError(t, err)
+	require.Len(t, progress.Steps, len(models.OnboardingSteps))
+	assert.False(t, progress.Complete)
+
+	require.NoError(t, service.CompleteStep(ctx, "user-1", models.OnboardingFirstMove))
+
+	progress, err = service.GetProgress(ctx, "user-1")
+	require.NoError(t, err)
+	for _, status := range progress.Steps {
+		assert.Equal(t, status.Step == models.OnboardingFirstMove, status.Completed, status.Step)
+	}
+	assert.Equal(t, now, *progress.Steps[1].CompletedAt)
+
+	// Every map the user is on hears about the step
+	require.Len(t, publisher.events, 2)
+	assert.Equal(t, "map-1", publisher.events[0].MapID)
+	assert.Equal(t, "map-2", publisher.events[1].MapID)
+	assert.Equal(t, models.OnboardingFirstMove, publisher.events[0].Step)
+	assert.Equal(t, progress, publisher.events[0].Progress)
+}
+
+func TestOnboardingService_CompleteStepAgainChangesNothing(t *testing.T) {
+	publisher := &recordingOnboardingPublisher{}
+	store := memory.NewStore().Onboarding()
+	service := NewOnboardingService(store)
+	service.SetPublisher(publisher, staticActiveMaps{"map-1"})
+	ctx := context.Background()
+
+	first := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return first }
+	require.NoError(t, service.CompleteStep(ctx, "user-1", models.OnboardingProfileSet))
+
+	service.now = func() time.Time { return first.Add(time.Hour) }
+	require.NoError(t, service.CompleteStep(ctx, "user-1", models.OnboardingProfileSet))
+
+	// A fresh service without the cache still keeps the first completion
+	restarted := NewOnboardingService(store)
+	restarted.SetPublisher(publisher, staticActiveMaps{"map-1"})
+	require.NoError(t, restarted.CompleteStep(ctx, "user-1", models.OnboardingProfileSet))
+
+	progress, err := service.GetProgress(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, first, *progress.Steps[0].CompletedAt)
+	assert.Len(t, publisher.events, 1)
+}
+
+func TestOnboardingService_CompleteAllSteps(t *testing.T) {
+	service := NewOnboardingService(memory.NewStore().Onboarding())
+	ctx := context.Background()
+
+	for _, step := range models.OnboardingSteps {
+		require.NoError(t, service.CompleteStep(ctx, "user-1", step))
+	}
+
+	progress, err := service.GetProgress(ctx, "user-1")
+	require.NoError(t, err)
+	assert.True(t, progress.Complete)
+}
+
+func TestOnboardingService_UnknownStep(t *testing.T) {
+	service := NewOnboardingService(memory.NewStore().Onboarding())
+
+	err := service.CompleteStep(context.Background(), "user-1", models.OnboardingStep("teleport"))
+	assert.True(t, errors.Is(err, ErrInvalidInput))
+}
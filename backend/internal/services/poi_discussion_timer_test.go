@@ -68,20 +68,16 @@ func TestPOIService_DiscussionTimer_SimplifiedLogic(t *testing.T) {
 	// Test: Add first user - should not start discussion (need 2+ users)
 	scenario.mockRepo.On("GetByID", mock.Anything, poiID).Return(initialPOI, nil).Once()
 	scenario.mockParts.On("IsParticipant", mock.Anything, poiID, user1ID).Return(false, nil).Once()
-	scenario.mockParts.On("CanJoinPOI", mock.Anything, poiID, 10).Return(true, nil).Once()
-	scenario.mockParts.On("JoinPOI", mock.Anything, poiID, user1ID).Return(nil).Once()
+	scenario.mockParts.On("JoinPOIWithCapacityCheck", mock.Anything, poiID, user1ID, 10).Return(nil).Once()
 	
 	// updateDiscussionTimer call with 1 participant
 	scenario.mockRepo.On("GetByID", mock.Anything, poiID).Return(initialPOI, nil).Once()
 	scenario.mockParts.On("GetParticipantCount", mock.Anything, poiID).Return(1, nil).Once() // For discussion timer
 	
-	// GetPOIParticipantsWithInfo call for event
-	scenario.mockParts.On("GetParticipants", mock.Anything, poiID).Return([]string{user1ID}, nil).Once()
-	scenario.mockUserService.On("GetUser", mock.Anything, user1ID).Return(&models.User{ID: user1ID, DisplayName: "User 1"}, nil).Once()
 	scenario.mockParts.On("GetParticipantCount", mock.Anything, poiID).Return(1, nil).Once() // For JoinPOI event
 	// Additional GetUser call for joiningUser field
 	scenario.mockUserService.On("GetUser", mock.Anything, user1ID).Return(&models.User{ID: user1ID, DisplayName: "User 1"}, nil).Once()
-	scenario.mockPubsub.On("PublishPOIJoinedWithParticipants", mock.Anything, mock.AnythingOfType("redis.POIJoinedEventWithParticipants")).Return(nil).Once()
+	scenario.mockPubsub.On("PublishPOIParticipantAdded", mock.Anything, mock.AnythingOfType("redis.POIParticipantDeltaEvent")).Return(nil).Once()
 	// Should not update POI since discussion should remain inactive (no Update call expected)
 	
 	// Note: POI join event is now broadcast directly by WebSocket handler, not via Redis
@@ -102,8 +98,7 @@ func TestPOIService_DiscussionTimer_SimplifiedLogic(t *testing.T) {
 	
 	scenario.mockRepo.On("GetByID", mock.Anything, poiID).Return(poiWith1User, nil).Once()
 	scenario.mockParts.On("IsParticipant", mock.Anything, poiID, user2ID).Return(false, nil).Once()
-	scenario.mockParts.On("CanJoinPOI", mock.Anything, poiID, 10).Return(true, nil).Once()
-	scenario.mockParts.On("JoinPOI", mock.Anything, poiID, user2ID).Return(nil).Once()
+	scenario.mockParts.On("JoinPOIWithCapacityCheck", mock.Anything, poiID, user2ID, 10).Return(nil).Once()
 	
 	// updateDiscussionTimer call with 2 participants - should start discussion
 	scenario.mockRepo.On("GetByID", mock.Anything, poiID).Return(poiWith1User, nil).Once()
@@ -114,14 +109,10 @@ func TestPOIService_DiscussionTimer_SimplifiedLogic(t *testing.T) {
 		return poi.ID == poiID && poi.IsDiscussionActive && poi.DiscussionStartTime != nil
 	})).Return(nil).Once()
 	
-	// GetPOIParticipantsWithInfo call for event
-	scenario.mockParts.On("GetParticipants", mock.Anything, poiID).Return([]string{user1ID, user2ID}, nil).Once()
-	scenario.mockUserService.On("GetUser", mock.Anything, user1ID).Return(&models.User{ID: user1ID, DisplayName: "User 1"}, nil).Once()
-	scenario.mockUserService.On("GetUser", mock.Anything, user2ID).Return(&models.User{ID: user2ID, DisplayName: "User 2"}, nil).Once()
 	scenario.mockParts.On("GetParticipantCount", mock.Anything, poiID).Return(2, nil).Once() // For JoinPOI event
 	// Additional GetUser call for joiningUser field (user2 is joining)
 	scenario.mockUserService.On("GetUser", mock.Anything, user2ID).Return(&models.User{ID: user2ID, DisplayName: "User 2"}, nil).Once()
-	scenario.mockPubsub.On("PublishPOIJoinedWithParticipants", mock.Anything, mock.AnythingOfType("redis.POIJoinedEventWithParticipants")).Return(nil).Once()
+	scenario.mockPubsub.On("PublishPOIParticipantAdded", mock.Anything, mock.AnythingOfType("redis.POIParticipantDeltaEvent")).Return(nil).Once()
 	
 	// Note: POI join event is now broadcast directly by WebSocket handler, not via Redis
 
@@ -153,11 +144,8 @@ func TestPOIService_DiscussionTimer_SimplifiedLogic(t *testing.T) {
 		return poi.ID == poiID && !poi.IsDiscussionActive && poi.DiscussionStartTime == nil
 	})).Return(nil).Once()
 	
-	// GetPOIParticipantsWithInfo call for event
-	scenario.mockParts.On("GetParticipants", mock.Anything, poiID).Return([]string{user1ID}, nil).Once()
-	scenario.mockUserService.On("GetUser", mock.Anything, user1ID).Return(&models.User{ID: user1ID, DisplayName: "User 1"}, nil).Once()
 	scenario.mockParts.On("GetParticipantCount", mock.Anything, poiID).Return(1, nil).Once() // For LeavePOI event
-	scenario.mockPubsub.On("PublishPOILeftWithParticipants", mock.Anything, mock.AnythingOfType("redis.POILeftEventWithParticipants")).Return(nil).Once()
+	scenario.mockPubsub.On("PublishPOIParticipantRemoved", mock.Anything, mock.AnythingOfType("redis.POIParticipantDeltaEvent")).Return(nil).Once()
 
 	err = scenario.service.LeavePOI(context.Background(), poiID, user2ID)
 	require.NoError(t, err)
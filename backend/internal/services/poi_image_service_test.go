@@ -5,6 +5,7 @@ import (
 	"mime/multipart"
 	"testing"
 
+	"breakoutglobe/internal/analytics"
 	"breakoutglobe/internal/models"
 
 	"github.com/stretchr/testify/assert"
@@ -134,6 +135,23 @@ func TestCreatePOIWithImage_WithoutImage_Success(t *testing.T) {
 	assert.Empty(t, poi.ImageURL)
 }
 
+func TestDeletePOI_RemovesUploadedImage(t *testing.T) {
+	scenario := newPOIImageServiceScenario(t)
+	defer scenario.cleanup()
+
+	poi := &models.POI{ID: "poi-123", MapID: "map-123", ImageURL: "https://example.com/uploads/poi-image.jpg"}
+	scenario.mockRepo.On("GetByID", mock.Anything, "poi-123").Return(poi, nil)
+	scenario.mockParts.On("GetParticipants", mock.Anything, "poi-123").Return([]string{}, nil)
+	scenario.mockParts.On("RemoveAllParticipants", mock.Anything, "poi-123").Return(nil)
+	scenario.mockUploader.On("DeletePOIImage", mock.Anything, "https://example.com/uploads/poi-image.jpg").Return(nil)
+	scenario.mockRepo.On("Delete", mock.Anything, "poi-123").Return(nil)
+	scenario.mockPubsub.On("PublishPOIDeleted", mock.Anything, mock.AnythingOfType("redis.POIDeletedEvent")).Return(nil)
+
+	err := scenario.service.DeletePOI(context.Background(), "poi-123")
+
+	assert.NoError(t, err)
+}
+
 // Mock interfaces for testing
 
 type MockImageUploader struct {
@@ -145,6 +163,11 @@ func (m *MockImageUploader) UploadPOIImage(ctx context.Context, imageFile *multi
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockImageUploader) DeletePOIImage(ctx context.Context, imageURL string) error {
+	args := m.Called(ctx, imageURL)
+	return args.Error(0)
+}
+
 // Mock POI Repository
 type MockPOIRepository struct {
 	mock.Mock
@@ -223,6 +246,11 @@ func (m *MockPOIParticipants) CanJoinPOI(ctx context.Context, poiID string, maxP
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockPOIParticipants) JoinPOIWithCapacityCheck(ctx context.Context, poiID, userID string, maxParticipants int) error {
+	args := m.Called(ctx, poiID, userID, maxParticipants)
+	return args.Error(0)
+}
+
 func (m *MockPOIParticipants) RemoveAllParticipants(ctx context.Context, poiID string) error {
 	args := m.Called(ctx, poiID)
 	return args.Error(0)
@@ -238,4 +266,39 @@ func (m *MockPOIParticipants) GetPOIsForParticipant(ctx context.Context, userID
 	return args.Get(0).([]string), args.Error(1)
 }
 
-// MockPubSub is now defined in mocks.go
\ No newline at end of file
+// MockPubSub is now defined in mocks.go
+type trackedEvent struct {
+	name       string
+	userID     string
+	properties map[string]interface{}
+}
+
+type recordingAnalytics struct {
+	events []trackedEvent
+}
+
+func (a *recordingAnalytics) Track(name, userID string, properties map[string]interface{}) {
+	a.events = append(a.events, trackedEvent{name: name, userID: userID, properties: properties})
+}
+
+func TestCreatePOIWithImage_TracksPOICreated(t *testing.T) {
+	scenario := newPOIImageServiceScenario(t)
+	defer scenario.cleanup()
+	tracked := &recordingAnalytics{}
+	scenario.service.SetAnalytics(tracked)
+
+	scenario.expectNoDuplicateLocation().
+		expectImageUploadSuccess().
+		expectPOICreationSuccess().
+		expectEventPublishing()
+
+	position := models.LatLng{Lat: 40.7128, Lng: -74.0060}
+	poi, err := scenario.service.CreatePOIWithImage(context.Background(), "map-123", "Coffee Shop", "", position, "user-123", 15, scenario.createMockImageFile())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []trackedEvent{{
+		name:       analytics.EventPOICreated,
+		userID:     "user-123",
+		properties: map[string]interface{}{"mapId": "map-123", "poiId": poi.ID, "hasImage": true},
+	}}, tracked.events)
+}
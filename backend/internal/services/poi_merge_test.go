@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"mime/multipart"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/redis"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mergeRecordingPubSub struct {
+	benchPubSub
+	merged []redis.POIMergedEvent
+}
+
+func (p *mergeRecordingPubSub) PublishPOIMerged(ctx context.Context, event redis.POIMergedEvent) error {
+	p.merged = append(p.merged, event)
+	return nil
+}
+
+type recordingImageProcessor struct {
+	deleted []string
+}
+
+func (p *recordingImageProcessor) ProcessPOIImage(ctx context.Context, poiID string, imageFile *multipart.FileHeader) (string, string, error) {
+	return "", "", nil
+}
+
+func (p *recordingImageProcessor) DeletePOIImages(ctx context.Context, poiID string) error {
+	p.deleted = append(p.deleted, poiID)
+	return nil
+}
+
+// newMergeTestService has two POIs on map-1, a third on map-2, and user-2 in
+// both poi-1 and poi-2
+type recordingRSVPMover struct {
+	moved [][2]string
+}
+
+func (m *recordingRSVPMover) MoveRSVPs(ctx context.Context, sourceID, targetID string) error {
+	m.moved = append(m.moved, [2]string{sourceID, targetID})
+	return nil
+}
+
+type fixedSeatReservations map[string][]string
+
+func (r fixedSeatReservations) GetReservedUserIDs(ctx context.Context, poi *models.POI) ([]string, error) {
+	return r[poi.ID], nil
+}
+
+func newMergeTestService() (*POIService, *benchPOIRepository, *benchPOIParticipants, *mergeRecordingPubSub, *recordingImageProcessor) {
+	repo := &benchPOIRepository{pois: map[string]*models.POI{
+		"poi-1": {ID: "poi-1", MapID: "map-1", Name: "Coffee Corner", MaxParticipants: 3, ImageURL: "/uploads/poi-1.jpg", ThumbnailURL: "/uploads/poi-1_thumb.jpg"},
+		"poi-2": {ID: "poi-2", MapID: "map-1", Name: "Coffee corner", MaxParticipants: 10},
+		"poi-3": {ID: "poi-3", MapID: "map-2", Name: "Coffee Corner", MaxParticipants: 10},
+	}}
+	participants := &benchPOIParticipants{participants: map[string]map[string]bool{
+		"poi-1": {"user-1": true, "user-2": true},
+		"poi-2": {"user-2": true, "user-3": true},
+	}}
+	pubsub := &mergeRecordingPubSub{}
+	images := &recordingImageProcessor{}
+	service := NewPOIServiceWithImageProcessor(repo, participants, pubsub, images, &benchUserService{})
+	return service, repo, participants, pubsub, images
+}
+
+func TestPOIService_MergePOIs(t *testing.T) {
+	service, repo, participants, pubsub, images := newMergeTestService()
+	rsvps := &recordingRSVPMover{}
+	service.SetRSVPMover(rsvps)
+	ctx := context.Background()
+
+	target, err := service.MergePOIs(ctx, "poi-2", "poi-1", "facilitator")
+	require.NoError(t, err)
+	assert.Equal(t, "poi-1", target.ID)
+
+	assert.Equal(t, map[string]bool{"user-1": true, "user-2": true, "user-3": true}, participants.participants["poi-1"])
+	assert.Empty(t, participants.participants["poi-2"])
+	assert.NotContains(t, repo.pois, "poi-2")
+
+	// The target has an image, so the source's images are deleted
+	assert.Equal(t, []string{"poi-2"}, images.deleted)
+	assert.Equal(t, [][2]string{{"poi-2", "poi-1"}}, rsvps.moved)
+
+	require.Len(t, pubsub.merged, 1)
+	event := pubsub.merged[0]
+	assert.Equal(t, "poi-2", event.SourcePOIID)
+	assert.Equal(t, "poi-1", event.TargetPOIID)
+	assert.Equal(t, "map-1", event.MapID)
+	assert.Equal(t, "facilitator", event.MergedBy)
+	assert.ElementsMatch(t, []string{"user-2", "user-3"}, event.MovedUserIDs)
+	assert.Equal(t, 3, event.CurrentCount)
+}
+
+func TestPOIService_MergePOIs_MovesImage(t *testing.T) {
+	service, repo, _, _, images := newMergeTestService()
+
+	target, err := service.MergePOIs(context.Background(), "poi-1", "poi-2", "facilitator")
+	require.NoError(t, err)
+
+	assert.Equal(t, "/uploads/poi-1.jpg", target.ImageURL)
+	assert.Equal(t, "/uploads/poi-1_thumb.jpg", repo.pois["poi-2"].ThumbnailURL)
+	assert.Empty(t, images.deleted)
+}
+
+func TestPOIService_MergePOIs_Validation(t *testing.T) {
+	service, repo, _, pubsub, _ := newMergeTestService()
+	ctx := context.Background()
+
+	_, err := service.MergePOIs(ctx, "poi-1", "poi-1", "facilitator")
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	_, err = service.MergePOIs(ctx, "poi-1", "poi-3", "facilitator")
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	_, err = service.MergePOIs(ctx, "poi-9", "poi-1", "facilitator")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = service.MergePOIs(ctx, "poi-1", "poi-9", "facilitator")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.Len(t, repo.pois, 3)
+	assert.Empty(t, pubsub.merged)
+}
+
+func TestPOIService_MergePOIs_RejectsOverCapacity(t *testing.T) {
+	service, repo, participants, pubsub, _ := newMergeTestService()
+	ctx := context.Background()
+
+	// user-1, user-2 and user-3 don't fit into two seats
+	repo.pois["poi-1"].MaxParticipants = 2
+	_, err := service.MergePOIs(ctx, "poi-2", "poi-1", "facilitator")
+	assert.ErrorIs(t, err, ErrCapacityExceeded)
+
+	// Seats reserved at a scheduled target count too
+	startsAt := time.Now().Add(time.Hour)
+	repo.pois["poi-1"].MaxParticipants = 3
+	repo.pois["poi-1"].StartsAt = &startsAt
+	service.SetSeatReservations(fixedSeatReservations{"poi-2": {"user-4"}})
+	_, err = service.MergePOIs(ctx, "poi-2", "poi-1", "facilitator")
+	assert.ErrorIs(t, err, ErrCapacityExceeded)
+
+	assert.Equal(t, map[string]bool{"user-1": true, "user-2": true}, participants.participants["poi-1"])
+	assert.Contains(t, repo.pois, "poi-2")
+	assert.Empty(t, pubsub.merged)
+}
+
+type failingDeletePOIRepository struct {
+	*benchPOIRepository
+}
+
+func (r *failingDeletePOIRepository) Delete(ctx context.Context, id string) error {
+	return errors.New("database unavailable")
+}
+
+func TestPOIService_MergePOIs_RollsBackOnFailure(t *testing.T) {
+	_, repo, participants, pubsub, images := newMergeTestService()
+	service := NewPOIServiceWithImageProcessor(&failingDeletePOIRepository{repo}, participants, pubsub, images, &benchUserService{})
+
+	_, err := service.MergePOIs(context.Background(), "poi-1", "poi-2", "facilitator")
+	require.Error(t, err)
+
+	// Participants and image are back where they were, and nothing is deleted
+	assert.Equal(t, map[string]bool{"user-1": true, "user-2": true}, participants.participants["poi-1"])
+	assert.Equal(t, map[string]bool{"user-2": true, "user-3": true}, participants.participants["poi-2"])
+	assert.Empty(t, repo.pois["poi-2"].ImageURL)
+	assert.Empty(t, images.deleted)
+	assert.Empty(t, pubsub.merged)
+}
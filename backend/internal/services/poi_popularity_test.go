@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeJoinHistory struct {
+	joins map[string]map[string]time.Time // poiID -> userID -> last joined at
+}
+
+func (h *fakeJoinHistory) RecordJoin(ctx context.Context, poiID, userID string, at time.Time, window time.Duration) error {
+	if h.joins[poiID] == nil {
+		h.joins[poiID] = make(map[string]time.Time)
+	}
+	h.joins[poiID][userID] = at
+	return nil
+}
+
+func (h *fakeJoinHistory) CountJoinsSince(ctx context.Context, poiIDs []string, since time.Time) (map[string]int, error) {
+	counts := make(map[string]int)
+	for _, poiID := range poiIDs {
+		for _, joinedAt := range h.joins[poiID] {
+			if !joinedAt.Before(since) {
+				counts[poiID]++
+			}
+		}
+	}
+	return counts, nil
+}
+
+func TestPOIService_GetPOIPopularity_CountsRecentJoins(t *testing.T) {
+	service, _, _ := newUpdateTestService()
+	history := &fakeJoinHistory{joins: map[string]map[string]time.Time{
+		"poi-2": {"user-9": time.Now().Add(-2 * POIPopularityWindow)},
+	}}
+	service.SetJoinHistory(history)
+	ctx := context.Background()
+
+	require.NoError(t, service.JoinPOI(ctx, "poi-1", "user-1"))
+	require.NoError(t, service.LeavePOI(ctx, "poi-1", "user-1"))
+	// Joining again counts the user once
+	require.NoError(t, service.JoinPOI(ctx, "poi-1", "user-1"))
+	require.NoError(t, service.JoinPOI(ctx, "poi-1", "user-2"))
+
+	popularity, err := service.GetPOIPopularity(ctx, []string{"poi-1", "poi-2"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, popularity["poi-1"].Joins)
+	assert.WithinDuration(t, time.Now().Add(-POIPopularityWindow), popularity["poi-1"].Since, time.Second)
+	// Joins before the window don't count
+	assert.Equal(t, 0, popularity["poi-2"].Joins)
+}
+
+func TestPOIService_GetPOIPopularity_WithoutJoinHistory(t *testing.T) {
+	service, _, _ := newUpdateTestService()
+
+	popularity, err := service.GetPOIPopularity(context.Background(), []string{"poi-1"})
+	require.NoError(t, err)
+	assert.Nil(t, popularity)
+}
+
+func TestPOIService_UpdatePOI_PublishesPopularity(t *testing.T) {
+	service, _, pubsub := newUpdateTestService()
+	ctx := context.Background()
+
+	_, err := service.UpdatePOI(ctx, "poi-1", POIUpdateData{Name: stringPtr("Tea Corner")})
+	require.NoError(t, err)
+	require.Len(t, pubsub.updated, 1)
+	assert.Nil(t, pubsub.updated[0].PopularSince)
+
+	service.SetJoinHistory(&fakeJoinHistory{joins: make(map[string]map[string]time.Time)})
+	require.NoError(t, service.JoinPOI(ctx, "poi-1", "user-1"))
+	_, err = service.UpdatePOI(ctx, "poi-1", POIUpdateData{Name: stringPtr("Coffee Corner")})
+	require.NoError(t, err)
+	require.Len(t, pubsub.updated, 2)
+	require.NotNil(t, pubsub.updated[1].PopularSince)
+	assert.Equal(t, 1, pubsub.updated[1].PopularSince.Joins)
+}
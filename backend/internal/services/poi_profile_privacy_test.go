@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type avatarUserService struct{}
+
+func (s *avatarUserService) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	avatarURL := "https://example.com/" + userID + ".png"
+	return &models.User{ID: userID, DisplayName: userID, AvatarURL: &avatarURL}, nil
+}
+
+type fakeProfilePrivacy struct {
+	privacy map[string]models.ProfilePrivacy
+}
+
+func (p *fakeProfilePrivacy) GetProfilePrivacy(ctx context.Context, mapID string) (models.ProfilePrivacy, error) {
+	return p.privacy[mapID], nil
+}
+
+func newProfilePrivacyTestService(privacy models.ProfilePrivacy) (*POIService, *recordingPubSub) {
+	repo := &rosterPOIRepository{benchPOIRepository{pois: map[string]*models.POI{
+		"poi-1": {ID: "poi-1", MapID: "map-1", MaxParticipants: 10},
+	}}}
+	participants := &benchPOIParticipants{participants: make(map[string]map[string]bool)}
+	pubsub := &recordingPubSub{}
+	service := NewPOIService(repo, participants, pubsub, &avatarUserService{})
+	service.SetProfilePrivacy(&fakeProfilePrivacy{privacy: map[string]models.ProfilePrivacy{"map-1": privacy}})
+	return service, pubsub
+}
+
+func TestPOIService_ProfilePrivacy_SharesAvatars(t *testing.T) {
+	service, pubsub := newProfilePrivacyTestService(models.ProfilePrivacy{HideAboutMe: true})
+	ctx := context.Background()
+
+	require.NoError(t, service.JoinPOI(ctx, "poi-1", "user-1"))
+
+	require.Len(t, pubsub.added, 1)
+	assert.Equal(t, "https://example.com/user-1.png", pubsub.added[0].Participant.AvatarURL)
+	participants, err := service.GetPOIParticipantsWithInfo(ctx, "poi-1")
+	require.NoError(t, err)
+	require.Len(t, participants, 1)
+	assert.Equal(t, "https://example.com/user-1.png", participants[0].AvatarURL)
+}
+
+func TestPOIService_ProfilePrivacy_HidesAvatars(t *testing.T) {
+	service, pubsub := newProfilePrivacyTestService(models.ProfilePrivacy{HideAvatarURL: true})
+	ctx := context.Background()
+
+	require.NoError(t, service.JoinPOI(ctx, "poi-1", "user-1"))
+
+	require.Len(t, pubsub.added, 1)
+	assert.Empty(t, pubsub.added[0].Participant.AvatarURL)
+	assert.Equal(t, "user-1", pubsub.added[0].Participant.Name)
+	participants, err := service.GetPOIParticipantsWithInfo(ctx, "poi-1")
+	require.NoError(t, err)
+	require.Len(t, participants, 1)
+	assert.Empty(t, participants[0].AvatarURL)
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/analytics"
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/redis"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type rosterPOIRepository struct {
+	benchPOIRepository
+}
+
+func (r *rosterPOIRepository) GetByMapID(ctx context.Context, mapID string) ([]*models.POI, error) {
+	var pois []*models.POI
+	for _, poi := range r.pois {
+		if poi.MapID == mapID {
+			pois = append(pois, poi)
+		}
+	}
+	return pois, nil
+}
+
+type recordingPubSub struct {
+	PubSub
+	added   []redis.POIParticipantDeltaEvent
+	removed []redis.POIParticipantDeltaEvent
+}
+
+func (p *recordingPubSub) PublishPOIParticipantAdded(ctx context.Context, event redis.POIParticipantDeltaEvent) error {
+	p.added = append(p.added, event)
+	return nil
+}
+
+func (p *recordingPubSub) PublishPOIParticipantRemoved(ctx context.Context, event redis.POIParticipantDeltaEvent) error {
+	p.removed = append(p.removed, event)
+	return nil
+}
+
+type fakeRosterSequencer struct {
+	sequences map[string]int64
+}
+
+func (s *fakeRosterSequencer) NextRosterSequence(ctx context.Context, poiID string) (int64, error) {
+	s.sequences[poiID]++
+	return s.sequences[poiID], nil
+}
+
+func (s *fakeRosterSequencer) GetRosterSequence(ctx context.Context, poiID string) (int64, error) {
+	return s.sequences[poiID], nil
+}
+
+func newRosterTestService() (*POIService, *recordingPubSub) {
+	repo := &rosterPOIRepository{benchPOIRepository{pois: map[string]*models.POI{
+		"poi-1": {ID: "poi-1", MapID: "map-1", MaxParticipants: 10},
+		"poi-2": {ID: "poi-2", MapID: "map-1", MaxParticipants: 10},
+	}}}
+	participants := &benchPOIParticipants{participants: make(map[string]map[string]bool)}
+	pubsub := &recordingPubSub{}
+	service := NewPOIService(repo, participants, pubsub, &benchUserService{})
+	service.SetRosterSequencer(&fakeRosterSequencer{sequences: make(map[string]int64)})
+	return service, pubsub
+}
+
+func TestPOIService_JoinLeave_PublishDeltas(t *testing.T) {
+	service, pubsub := newRosterTestService()
+	ctx := context.Background()
+
+	require.NoError(t, service.JoinPOI(ctx, "poi-1", "user-1"))
+	require.NoError(t, service.JoinPOI(ctx, "poi-1", "user-2"))
+	require.NoError(t, service.LeavePOI(ctx, "poi-1", "user-1"))
+
+	require.Len(t, pubsub.added, 2)
+	assert.Equal(t, "user-2", pubsub.added[1].Participant.ID)
+	assert.Equal(t, 2, pubsub.added[1].CurrentCount)
+	assert.Equal(t, int64(2), pubsub.added[1].Sequence)
+
+	require.Len(t, pubsub.removed, 1)
+	assert.Equal(t, "user-1", pubsub.removed[0].UserID)
+	assert.Nil(t, pubsub.removed[0].Participant)
+	assert.Equal(t, 1, pubsub.removed[0].CurrentCount)
+	assert.Equal(t, int64(3), pubsub.removed[0].Sequence)
+}
+
+func TestPOIService_GetPOIRostersForMap(t *testing.T) {
+	service, _ := newRosterTestService()
+	ctx := context.Background()
+
+	require.NoError(t, service.JoinPOI(ctx, "poi-1", "user-1"))
+
+	rosters, err := service.GetPOIRostersForMap(ctx, "map-1")
+	require.NoError(t, err)
+
+	// poi-2 was never joined, so it has nothing to reconcile
+	require.Len(t, rosters, 1)
+	assert.Equal(t, "poi-1", rosters[0].POIID)
+	assert.Equal(t, 1, rosters[0].CurrentCount)
+	assert.Equal(t, int64(1), rosters[0].Sequence)
+	assert.Equal(t, "user-1", rosters[0].Participants[0].ID)
+}
+
+func TestPOIService_ReconcileParticipants_RemovesInactiveUsers(t *testing.T) {
+	service, pubsub := newRosterTestService()
+	ctx := context.Background()
+
+	require.NoError(t, service.JoinPOI(ctx, "poi-1", "user-1"))
+	require.NoError(t, service.JoinPOI(ctx, "poi-1", "user-2"))
+	require.NoError(t, service.JoinPOI(ctx, "poi-2", "user-3"))
+
+	removed, err := service.ReconcileParticipants(ctx, "map-1", []string{"user-1"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+
+	participants, err := service.GetPOIParticipants(ctx, "poi-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user-1"}, participants)
+
+	count, err := service.GetPOIParticipantCount(ctx, "poi-2")
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	require.Len(t, pubsub.removed, 2)
+	for _, event := range pubsub.removed {
+		assert.NotEqual(t, "user-1", event.UserID)
+		if event.POIID == "poi-1" {
+			assert.Equal(t, 1, event.CurrentCount)
+		} else {
+			assert.Equal(t, 0, event.CurrentCount)
+		}
+	}
+}
+
+func TestPOIService_ReconcileParticipants_KeepsActiveUsers(t *testing.T) {
+	service, pubsub := newRosterTestService()
+	ctx := context.Background()
+
+	require.NoError(t, service.JoinPOI(ctx, "poi-1", "user-1"))
+
+	removed, err := service.ReconcileParticipants(ctx, "map-1", []string{"user-1"})
+	require.NoError(t, err)
+	assert.Equal(t, 0, removed)
+	assert.Empty(t, pubsub.removed)
+}
+
+type fakeJoinTimes map[string]time.Time
+
+func (j fakeJoinTimes) GetJoinTimes(ctx context.Context, poiID string) (map[string]time.Time, error) {
+	return j, nil
+}
+
+func TestPOIService_ParticipantJoinTimes(t *testing.T) {
+	service, pubsub := newRosterTestService()
+	ctx := context.Background()
+	joinedAt := time.Now().Add(-12 * time.Minute)
+	service.SetParticipantJoinTimes(fakeJoinTimes{"user-1": joinedAt})
+	tracked := &recordingAnalytics{}
+	service.SetAnalytics(tracked)
+
+	require.NoError(t, service.JoinPOI(ctx, "poi-1", "user-1"))
+	require.NoError(t, service.JoinPOI(ctx, "poi-1", "user-2"))
+	require.NotNil(t, pubsub.added[0].Participant.JoinedAt)
+	assert.Equal(t, joinedAt, *pubsub.added[0].Participant.JoinedAt)
+
+	participants, err := service.GetPOIParticipantsWithInfo(ctx, "poi-1")
+	require.NoError(t, err)
+	for _, participant := range participants {
+		if participant.ID == "user-1" {
+			require.NotNil(t, participant.JoinedAt)
+			assert.Equal(t, joinedAt, *participant.JoinedAt)
+		} else {
+			// Participants without a recorded join time have none
+			assert.Nil(t, participant.JoinedAt)
+		}
+	}
+
+	require.NoError(t, service.LeavePOI(ctx, "poi-1", "user-1"))
+	require.NoError(t, service.LeavePOI(ctx, "poi-1", "user-2"))
+	require.Len(t, tracked.events, 2)
+	assert.Equal(t, analytics.EventPOILeft, tracked.events[0].name)
+	assert.Equal(t, 12*60, tracked.events[0].properties["dwellSeconds"])
+	assert.NotContains(t, tracked.events[1].properties, "dwellSeconds")
+}
@@ -2,10 +2,14 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"mime/multipart"
+	"sort"
+	"strings"
 	"time"
 
+	"breakoutglobe/internal/analytics"
 	"breakoutglobe/internal/models"
 	"breakoutglobe/internal/redis"
 
@@ -54,6 +58,9 @@ type POIServiceInterface interface {
 	// GetUserPOIs retrieves all POIs a user is participating in
 	GetUserPOIs(ctx context.Context, userID string) ([]string, error)
 	
+	// GetCurrentPOI returns the POI a user is currently in, or an empty string
+	GetCurrentPOI(ctx context.Context, userID string) (string, error)
+	
 	// ValidatePOI validates that a POI exists
 	ValidatePOI(ctx context.Context, poiID string) (*models.POI, error)
 }
@@ -77,14 +84,42 @@ type POIParticipantsInterface interface {
 	GetParticipantCount(ctx context.Context, poiID string) (int, error)
 	IsParticipant(ctx context.Context, poiID, userID string) (bool, error)
 	CanJoinPOI(ctx context.Context, poiID string, maxParticipants int) (bool, error)
+	JoinPOIWithCapacityCheck(ctx context.Context, poiID, userID string, maxParticipants int) error
 	RemoveAllParticipants(ctx context.Context, poiID string) error
 	RemoveParticipantFromAllPOIs(ctx context.Context, userID string) error
 	GetPOIsForParticipant(ctx context.Context, userID string) ([]string, error)
 }
 
+// RosterSequencerInterface defines the interface for numbering POI roster changes
+type RosterSequencerInterface interface {
+	NextRosterSequence(ctx context.Context, poiID string) (int64, error)
+	GetRosterSequence(ctx context.Context, poiID string) (int64, error)
+}
+
+// ParticipantJoinTimesInterface defines the interface for looking up when POI participants joined
+type ParticipantJoinTimesInterface interface {
+	GetJoinTimes(ctx context.Context, poiID string) (map[string]time.Time, error)
+}
+
+// POIJoinHistoryInterface defines the interface for counting the users who
+// recently joined POIs
+type POIJoinHistoryInterface interface {
+	// RecordJoin records that a user joined a POI, forgetting joins older
+	// than the window
+	RecordJoin(ctx context.Context, poiID, userID string, at time.Time, window time.Duration) error
+	// CountJoinsSince counts the users who joined each POI since a time
+	CountJoinsSince(ctx context.Context, poiIDs []string, since time.Time) (map[string]int, error)
+}
+
+// ProfilePrivacyProvider defines the interface for per-map profile privacy settings
+type ProfilePrivacyProvider interface {
+	GetProfilePrivacy(ctx context.Context, mapID string) (models.ProfilePrivacy, error)
+}
+
 // ImageUploaderInterface defines the interface for image upload operations
 type ImageUploaderInterface interface {
 	UploadPOIImage(ctx context.Context, imageFile *multipart.FileHeader) (string, error)
+	DeletePOIImage(ctx context.Context, imageURL string) error
 }
 
 // ImageProcessorInterface defines the interface for image processing operations
@@ -100,6 +135,21 @@ type UserServiceInterface interface {
 
 
 
+// MapFreezeCheckerInterface defines the interface for checking whether a facilitator froze a map
+type MapFreezeCheckerInterface interface {
+	IsFrozen(mapID string) bool
+}
+
+// SeatReservationsInterface defines the interface for seats held at scheduled POIs
+type SeatReservationsInterface interface {
+	GetReservedUserIDs(ctx context.Context, poi *models.POI) ([]string, error)
+}
+
+// RSVPMoverInterface defines the interface for moving the RSVPs of a merged POI
+type RSVPMoverInterface interface {
+	MoveRSVPs(ctx context.Context, sourceID, targetID string) error
+}
+
 // POIService implements POI management operations
 type POIService struct {
 	poiRepo        POIRepositoryInterface
@@ -108,6 +158,16 @@ type POIService struct {
 	imageUploader  ImageUploaderInterface  // Deprecated: use imageProcessor instead
 	imageProcessor ImageProcessorInterface // New: handles both original and thumbnail
 	userService    UserServiceInterface
+	reservations   SeatReservationsInterface
+	rsvps          RSVPMoverInterface
+	sequencer      RosterSequencerInterface
+	joinTimes      ParticipantJoinTimesInterface
+	joinHistory    POIJoinHistoryInterface
+	profilePrivacy ProfilePrivacyProvider
+	analytics      ProductAnalytics
+	freeze         MapFreezeCheckerInterface
+	onboarding     OnboardingTracker
+	usage          StorageUsageRecorder
 }
 
 // POIBounds represents geographic bounds for POI queries
@@ -118,24 +178,39 @@ type POIBounds struct {
 	MaxLng float64 `json:"maxLng"`
 }
 
-// POIUpdateData represents data for updating a POI
+// POIUpdateData represents a partial update of a POI. Nil fields are left
+// unchanged, so an empty description clears it while an absent one keeps it.
 type POIUpdateData struct {
-	Name            string `json:"name,omitempty"`
-	Description     string `json:"description,omitempty"`
-	MaxParticipants int    `json:"maxParticipants,omitempty"`
+	Name            *string    `json:"name,omitempty"`
+	Description     *string    `json:"description,omitempty"`
+	MaxParticipants *int       `json:"maxParticipants,omitempty"`
+	StartsAt        *time.Time `json:"startsAt,omitempty"` // Schedules an event at the POI
 }
 
 // POIParticipantInfo represents a POI participant with display information
 type POIParticipantInfo struct {
-	ID        string `json:"id"`
-	Name      string `json:"name"`
-	AvatarURL string `json:"avatarUrl"`
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	AvatarURL string     `json:"avatarUrl"`
+	JoinedAt  *time.Time `json:"joinedAt,omitempty"` // Unknown for participants joined before it was recorded
+}
+
+// POIRoster is a full snapshot of a POI's participants. Sequence is the number
+// of the last roster change included, so clients can discard older snapshots.
+type POIRoster struct {
+	POIID        string               `json:"poiId"`
+	Participants []POIParticipantInfo `json:"participants"`
+	CurrentCount int                  `json:"currentCount"`
+	Sequence     int64                `json:"seq"`
 }
 
 // Default configuration values
 const (
 	MaxPOINameLength        = 100
 	MaxPOIDescriptionLength = 500
+
+	// POIPopularityWindow is how far back joins count towards a POI's popularity
+	POIPopularityWindow = time.Hour
 )
 
 // NewPOIService creates a new POIService instance
@@ -175,6 +250,156 @@ func NewPOIServiceWithImageProcessor(poiRepo POIRepositoryInterface, participant
 	}
 }
 
+// SetSeatReservations sets the source of seats held for users at scheduled
+// POIs. Without one, joining only checks the current participants.
+func (s *POIService) SetSeatReservations(reservations SeatReservationsInterface) {
+	s.reservations = reservations
+}
+
+// SetRSVPMover sets where the RSVPs of merged POIs are moved. Without it,
+// RSVPs stay with the deleted source POI.
+func (s *POIService) SetRSVPMover(rsvps RSVPMoverInterface) {
+	s.rsvps = rsvps
+}
+
+// SetRosterSequencer sets the source of roster sequence numbers. Without one,
+// participant deltas are published with a sequence of 0.
+func (s *POIService) SetRosterSequencer(sequencer RosterSequencerInterface) {
+	s.sequencer = sequencer
+}
+
+// SetParticipantJoinTimes sets where the join times of participants are
+// recorded. Without it, participants have no joinedAt and leaving a POI is
+// reported to analytics without its dwell time.
+func (s *POIService) SetParticipantJoinTimes(joinTimes ParticipantJoinTimesInterface) {
+	s.joinTimes = joinTimes
+}
+
+// SetJoinHistory sets where joins are recorded to tell how popular POIs are.
+// Without it, POIs and poi_updated events have no popularity.
+func (s *POIService) SetJoinHistory(joinHistory POIJoinHistoryInterface) {
+	s.joinHistory = joinHistory
+}
+
+// SetProfilePrivacy leaves avatar URLs out of participant payloads on maps
+// that hide them. Without it, avatar URLs are always included.
+func (s *POIService) SetProfilePrivacy(profilePrivacy ProfilePrivacyProvider) {
+	s.profilePrivacy = profilePrivacy
+}
+
+// SetMapFreeze rejects POI changes on maps a facilitator froze. Without it,
+// POIs can always be changed.
+func (s *POIService) SetMapFreeze(freeze MapFreezeCheckerInterface) {
+	s.freeze = freeze
+}
+
+// SetAnalytics sets where product events like creating a POI are reported
+func (s *POIService) SetAnalytics(analytics ProductAnalytics) {
+	s.analytics = analytics
+}
+
+// SetOnboarding sets the welcome checklist that joining a POI completes a
+// step of
+func (s *POIService) SetOnboarding(onboarding OnboardingTracker) {
+	s.onboarding = onboarding
+}
+
+// SetUsageRecorder sets where the size of uploaded POI images is metered per map
+func (s *POIService) SetUsageRecorder(usage StorageUsageRecorder) {
+	s.usage = usage
+}
+
+// trackPOICreated reports a created POI to product analytics
+func (s *POIService) trackPOICreated(poi *models.POI) {
+	if s.analytics == nil {
+		return
+	}
+	s.analytics.Track(analytics.EventPOICreated, poi.CreatedBy, map[string]interface{}{
+		"mapId":    poi.MapID,
+		"poiId":    poi.ID,
+		"hasImage": poi.ImageURL != "",
+	})
+}
+
+// trackPOILeft reports a participant leaving a POI to product analytics, with
+// how long they stayed if their join time is known
+func (s *POIService) trackPOILeft(poi *models.POI, userID string, joinedAt time.Time, joinTimeKnown bool) {
+	if s.analytics == nil {
+		return
+	}
+	properties := map[string]interface{}{
+		"mapId": poi.MapID,
+		"poiId": poi.ID,
+	}
+	if joinTimeKnown {
+		properties["dwellSeconds"] = int(time.Since(joinedAt).Seconds())
+	}
+	s.analytics.Track(analytics.EventPOILeft, userID, properties)
+}
+
+// getJoinTimes returns when the participants of a POI joined it. Join times
+// are only shown to clients and analytics, so lookup failures leave them out.
+func (s *POIService) getJoinTimes(ctx context.Context, poiID string) map[string]time.Time {
+	if s.joinTimes == nil {
+		return nil
+	}
+	joinTimes, err := s.joinTimes.GetJoinTimes(ctx, poiID)
+	if err != nil {
+		fmt.Printf("Warning: failed to get POI join times: %v\n", err)
+		return nil
+	}
+	return joinTimes
+}
+
+// avatarsHidden reports whether a map leaves avatar URLs out of participant
+// payloads. Settings that can't be read share them, like position privacy.
+func (s *POIService) avatarsHidden(ctx context.Context, mapID string) bool {
+	if s.profilePrivacy == nil {
+		return false
+	}
+	privacy, err := s.profilePrivacy.GetProfilePrivacy(ctx, mapID)
+	if err != nil {
+		fmt.Printf("Warning: failed to get profile privacy: %v\n", err)
+		return false
+	}
+	return privacy.HideAvatarURL
+}
+
+// GetPOIPopularity counts the users who joined each POI within the
+// popularity window. It returns nil if joins aren't recorded.
+func (s *POIService) GetPOIPopularity(ctx context.Context, poiIDs []string) (map[string]models.POIPopularity, error) {
+	if s.joinHistory == nil || len(poiIDs) == 0 {
+		return nil, nil
+	}
+
+	since := time.Now().Add(-POIPopularityWindow)
+	joins, err := s.joinHistory.CountJoinsSince(ctx, poiIDs, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count recent POI joins: %w", err)
+	}
+
+	popularity := make(map[string]models.POIPopularity, len(poiIDs))
+	for _, poiID := range poiIDs {
+		popularity[poiID] = models.POIPopularity{Since: since, Joins: joins[poiID]}
+	}
+	return popularity, nil
+}
+
+// getPOIPopularity returns how popular a POI is for events, or nil if it
+// can't be told
+func (s *POIService) getPOIPopularity(ctx context.Context, poiID string) *models.POIPopularity {
+	popularity, err := s.GetPOIPopularity(ctx, []string{poiID})
+	if err != nil {
+		fmt.Printf("Warning: failed to get POI popularity: %v\n", err)
+		return nil
+	}
+	if popularity == nil {
+		return nil
+	}
+	poiPopularity := popularity[poiID]
+	return &poiPopularity
+}
+
 // CreatePOI creates a new POI with duplicate location checking
 func (s *POIService) CreatePOI(ctx context.Context, mapID, name, description string, position models.LatLng, createdBy string, maxParticipants int) (*models.POI, error) {
 	// Validate input
@@ -187,13 +412,17 @@ func (s *POIService) CreatePOI(ctx context.Context, mapID, name, description str
 		return nil, fmt.Errorf("invalid position: %w", err)
 	}
 
+	if err := s.checkNotFrozen(mapID); err != nil {
+		return nil, err
+	}
+
 	// Check for duplicate location
 	duplicates, err := s.poiRepo.CheckDuplicateLocation(ctx, mapID, position.Lat, position.Lng, "")
 	if err != nil {
 		return nil, fmt.Errorf("failed to check duplicate location: %w", err)
 	}
 	if len(duplicates) > 0 {
-		return nil, fmt.Errorf("POI already exists at this location (lat: %f, lng: %f)", position.Lat, position.Lng)
+		return nil, fmt.Errorf("%w: a POI already exists at lat %f, lng %f", ErrDuplicateLocation, position.Lat, position.Lng)
 	}
 
 	// Create new POI
@@ -237,6 +466,8 @@ func (s *POIService) CreatePOI(ctx context.Context, mapID, name, description str
 		fmt.Printf("Warning: failed to publish POI created event: %v\n", err)
 	}
 
+	s.trackPOICreated(poi)
+
 	return poi, nil
 }
 
@@ -252,13 +483,17 @@ func (s *POIService) CreatePOIWithImage(ctx context.Context, mapID, name, descri
 		return nil, fmt.Errorf("invalid position: %w", err)
 	}
 
+	if err := s.checkNotFrozen(mapID); err != nil {
+		return nil, err
+	}
+
 	// Check for duplicate location
 	duplicates, err := s.poiRepo.CheckDuplicateLocation(ctx, mapID, position.Lat, position.Lng, "")
 	if err != nil {
 		return nil, fmt.Errorf("failed to check duplicate location: %w", err)
 	}
 	if len(duplicates) > 0 {
-		return nil, fmt.Errorf("POI already exists at this location (lat: %f, lng: %f)", position.Lat, position.Lng)
+		return nil, fmt.Errorf("%w: a POI already exists at lat %f, lng %f", ErrDuplicateLocation, position.Lat, position.Lng)
 	}
 
 	// Create POI ID first (needed for image processing)
@@ -280,6 +515,9 @@ func (s *POIService) CreatePOIWithImage(ctx context.Context, mapID, name, descri
 				return nil, fmt.Errorf("failed to upload POI image: %w", err)
 			}
 		}
+		if imageURL != "" && s.usage != nil {
+			s.usage.RecordStorageBytes(mapID, imageFile.Size)
+		}
 	}
 
 	// Create new POI
@@ -328,6 +566,8 @@ func (s *POIService) CreatePOIWithImage(ctx context.Context, mapID, name, descri
 		fmt.Printf("Warning: failed to publish POI created event: %v\n", err)
 	}
 
+	s.trackPOICreated(poi)
+
 	return poi, nil
 }
 
@@ -336,7 +576,7 @@ func (s *POIService) GetPOI(ctx context.Context, poiID string) (*models.POI, err
 	poi, err := s.poiRepo.GetByID(ctx, poiID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("POI not found: %s", poiID)
+			return nil, fmt.Errorf("POI %w: %s", ErrNotFound, poiID)
 		}
 		return nil, fmt.Errorf("failed to get POI: %w", err)
 	}
@@ -372,34 +612,66 @@ func (s *POIService) UpdatePOI(ctx context.Context, poiID string, updateData POI
 	poi, err := s.poiRepo.GetByID(ctx, poiID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("POI not found: %s", poiID)
+			return nil, fmt.Errorf("POI %w: %s", ErrNotFound, poiID)
 		}
 		return nil, fmt.Errorf("failed to get POI: %w", err)
 	}
+	if err := s.checkNotFrozen(poi.MapID); err != nil {
+		return nil, err
+	}
 
 	// Update fields if provided
 	updated := false
-	if updateData.Name != "" && updateData.Name != poi.Name {
-		if len(updateData.Name) > MaxPOINameLength {
-			return nil, fmt.Errorf("POI name too long (max %d characters)", MaxPOINameLength)
+	if updateData.Name != nil {
+		name := strings.TrimSpace(*updateData.Name)
+		if name == "" {
+			return nil, fmt.Errorf("%w: POI name cannot be empty", ErrInvalidInput)
+		}
+		if len(name) > MaxPOINameLength {
+			return nil, fmt.Errorf("%w: POI name too long (max %d characters)", ErrInvalidInput, MaxPOINameLength)
+		}
+		if name != poi.Name {
+			poi.Name = name
+			updated = true
 		}
-		poi.Name = updateData.Name
-		updated = true
 	}
 
-	if updateData.Description != poi.Description {
-		if len(updateData.Description) > MaxPOIDescriptionLength {
-			return nil, fmt.Errorf("POI description too long (max %d characters)", MaxPOIDescriptionLength)
+	if updateData.Description != nil && *updateData.Description != poi.Description {
+		if len(*updateData.Description) > MaxPOIDescriptionLength {
+			return nil, fmt.Errorf("%w: POI description too long (max %d characters)", ErrInvalidInput, MaxPOIDescriptionLength)
 		}
-		poi.Description = updateData.Description
+		poi.Description = *updateData.Description
 		updated = true
 	}
 
-	if updateData.MaxParticipants > 0 && updateData.MaxParticipants != poi.MaxParticipants {
-		if updateData.MaxParticipants < 1 {
-			return nil, fmt.Errorf("max participants must be at least 1")
+	if updateData.MaxParticipants != nil && *updateData.MaxParticipants != poi.MaxParticipants {
+		maxParticipants := *updateData.MaxParticipants
+		if maxParticipants < 1 {
+			return nil, fmt.Errorf("%w: max participants must be at least 1", ErrInvalidInput)
 		}
-		poi.MaxParticipants = updateData.MaxParticipants
+
+		// Lowering the limit below the current participants is rejected, as
+		// participants are never removed from a POI. A join racing with this
+		// check can still leave the POI over its new limit; joins check the
+		// saved limit atomically, so no one can join until enough have left.
+		if maxParticipants < poi.MaxParticipants {
+			currentCount, err := s.participants.GetParticipantCount(ctx, poiID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check POI participants: %w", err)
+			}
+			if maxParticipants < currentCount {
+				return nil, &CapacityBelowOccupancyError{MaxParticipants: maxParticipants, CurrentCount: currentCount}
+			}
+		}
+
+		poi.MaxParticipants = maxParticipants
+		updated = true
+	}
+
+	if updateData.StartsAt != nil && (poi.StartsAt == nil || !updateData.StartsAt.Equal(*poi.StartsAt)) {
+		startsAt := updateData.StartsAt.UTC()
+		poi.StartsAt = &startsAt
+		poi.ReminderSentAt = nil // Remind again for the new start time
 		updated = true
 	}
 
@@ -409,7 +681,7 @@ func (s *POIService) UpdatePOI(ctx context.Context, poiID string, updateData POI
 
 	// Validate updated POI
 	if err := poi.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid updated POI data: %w", err)
+		return nil, fmt.Errorf("%w: invalid updated POI data: %v", ErrInvalidInput, err)
 	}
 
 	// Save to database
@@ -424,6 +696,8 @@ func (s *POIService) UpdatePOI(ctx context.Context, poiID string, updateData POI
 		Name:            poi.Name,
 		Description:     poi.Description,
 		MaxParticipants: poi.MaxParticipants,
+		StartsAt:        poi.StartsAt,
+		PopularSince:    s.getPOIPopularity(ctx, poi.ID),
 		Timestamp:       time.Now(),
 	}
 
@@ -438,45 +712,234 @@ func (s *POIService) UpdatePOI(ctx context.Context, poiID string, updateData POI
 // DeletePOI deletes a POI and removes all participants
 func (s *POIService) DeletePOI(ctx context.Context, poiID string) error {
 	// Verify POI exists
-	_, err := s.poiRepo.GetByID(ctx, poiID)
+	poi, err := s.poiRepo.GetByID(ctx, poiID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return fmt.Errorf("POI not found: %s", poiID)
+			return fmt.Errorf("POI %w: %s", ErrNotFound, poiID)
 		}
 		return fmt.Errorf("failed to get POI: %w", err)
 	}
+	if err := s.checkNotFrozen(poi.MapID); err != nil {
+		return err
+	}
+
+	// Capture who is inside before clearing the roster so they can be told they were removed
+	removedUserIDs, err := s.participants.GetParticipants(ctx, poiID)
+	if err != nil {
+		return fmt.Errorf("failed to get POI participants: %w", err)
+	}
 
 	// Remove all participants first
 	if err := s.participants.RemoveAllParticipants(ctx, poiID); err != nil {
 		return fmt.Errorf("failed to remove POI participants: %w", err)
 	}
 
-	// Delete POI images if image processor is available
-	if s.imageProcessor != nil {
-		if err := s.imageProcessor.DeletePOIImages(ctx, poiID); err != nil {
-			// Log error but don't fail the deletion
-			fmt.Printf("Warning: failed to delete POI images: %v\n", err)
-		}
-	}
+	s.deletePOIImages(ctx, poi)
 
 	// Delete POI from database
 	if err := s.poiRepo.Delete(ctx, poiID); err != nil {
 		return fmt.Errorf("failed to delete POI from database: %w", err)
 	}
 
+	deletedEvent := redis.POIDeletedEvent{
+		POIID:     poiID,
+		MapID:     poi.MapID,
+		Timestamp: time.Now(),
+	}
+
+	if err := s.pubsub.PublishPOIDeleted(ctx, deletedEvent); err != nil {
+		// Log error but don't fail the operation
+		fmt.Printf("Warning: failed to publish POI deleted event: %v\n", err)
+	}
+
+	// Publish a removal per participant, so their clients leave the POI view
+	for _, userID := range removedUserIDs {
+		removedEvent := redis.POIParticipantDeltaEvent{
+			POIID:     poiID,
+			MapID:     poi.MapID,
+			UserID:    userID,
+			Reason:    "deleted",
+			Sequence:  s.nextRosterSequence(ctx, poiID),
+			Timestamp: time.Now(),
+		}
+
+		if err := s.pubsub.PublishPOIParticipantRemoved(ctx, removedEvent); err != nil {
+			// Log error but don't fail the operation
+			fmt.Printf("Warning: failed to publish POI participant removed event: %v\n", err)
+		}
+	}
+
 	return nil
 }
 
+// deletePOIImages deletes a POI's images, if any. Failures are logged since
+// they leave orphaned files at worst.
+func (s *POIService) deletePOIImages(ctx context.Context, poi *models.POI) {
+	if s.imageProcessor != nil {
+		if err := s.imageProcessor.DeletePOIImages(ctx, poi.ID); err != nil {
+			fmt.Printf("Warning: failed to delete POI images: %v\n", err)
+		}
+	} else if s.imageUploader != nil && poi.ImageURL != "" {
+		if err := s.imageUploader.DeletePOIImage(ctx, poi.ImageURL); err != nil {
+			fmt.Printf("Warning: failed to delete POI image: %v\n", err)
+		}
+	}
+}
+
+// MergePOIs moves the participants and RSVPs of the source POI into the
+// target POI on the same map and deletes the source. The merge is rejected
+// with ErrCapacityExceeded if the target can't seat everyone, so nobody is
+// left behind. A target without an image takes over the source's image.
+// The source's name, description and schedule are dropped in favour of the
+// target's, and POIs have no notes or chat of their own to merge. The merged
+// target is returned.
+//
+// Participants live in Redis and POIs in the database, so there's no shared
+// transaction. The source is only emptied once it's deleted; if a step before
+// fails, the participant moves and image takeover are undone.
+func (s *POIService) MergePOIs(ctx context.Context, sourceID, targetID, mergedBy string) (*models.POI, error) {
+	if sourceID == targetID {
+		return nil, fmt.Errorf("%w: a POI can't be merged into itself", ErrInvalidInput)
+	}
+
+	source, err := s.poiRepo.GetByID(ctx, sourceID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("POI %w: %s", ErrNotFound, sourceID)
+		}
+		return nil, fmt.Errorf("failed to get POI: %w", err)
+	}
+	target, err := s.poiRepo.GetByID(ctx, targetID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("POI %w: %s", ErrNotFound, targetID)
+		}
+		return nil, fmt.Errorf("failed to get POI: %w", err)
+	}
+	if source.MapID != target.MapID {
+		return nil, fmt.Errorf("%w: POIs %s and %s are on different maps", ErrInvalidInput, sourceID, targetID)
+	}
+	if err := s.checkNotFrozen(source.MapID); err != nil {
+		return nil, err
+	}
+
+	sourceParticipants, err := s.participants.GetParticipants(ctx, sourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get POI participants: %w", err)
+	}
+	if err := s.checkMergeCapacity(ctx, source, target, sourceParticipants); err != nil {
+		return nil, err
+	}
+
+	movedUserIDs := make([]string, 0, len(sourceParticipants))
+	var joinedUserIDs []string
+	rollBack := func() {
+		for _, userID := range joinedUserIDs {
+			if err := s.participants.LeavePOI(ctx, targetID, userID); err != nil {
+				fmt.Printf("Warning: failed to undo moving participant %s of merged POI: %v\n", userID, err)
+			}
+		}
+	}
+	for _, userID := range sourceParticipants {
+		isParticipant, err := s.participants.IsParticipant(ctx, targetID, userID)
+		if err != nil {
+			rollBack()
+			return nil, fmt.Errorf("failed to check participant status: %w", err)
+		}
+		if !isParticipant {
+			// Joins racing the merge can still fill the target
+			if err := s.participants.JoinPOIWithCapacityCheck(ctx, targetID, userID, target.MaxParticipants); err != nil {
+				rollBack()
+				if errors.Is(err, redis.ErrPOIAtCapacity) {
+					return nil, fmt.Errorf("%w: POI %s is at maximum capacity (%d participants)", ErrCapacityExceeded, targetID, target.MaxParticipants)
+				}
+				return nil, fmt.Errorf("failed to move participant: %w", err)
+			}
+			joinedUserIDs = append(joinedUserIDs, userID)
+		}
+		movedUserIDs = append(movedUserIDs, userID)
+	}
+
+	// Keep the source's image if the target has none, otherwise it goes with the source
+	movesImage := target.ImageURL == "" && source.ImageURL != ""
+	if movesImage {
+		original := *target
+		target.ImageURL = source.ImageURL
+		target.ThumbnailURL = source.ThumbnailURL
+		target.Update()
+		if err := s.poiRepo.Update(ctx, target); err != nil {
+			rollBack()
+			return nil, fmt.Errorf("failed to move POI image: %w", err)
+		}
+		undoMove := rollBack
+		rollBack = func() {
+			if err := s.poiRepo.Update(ctx, &original); err != nil {
+				fmt.Printf("Warning: failed to undo moving the image of merged POI: %v\n", err)
+			}
+			undoMove()
+		}
+	}
+
+	if err := s.poiRepo.Delete(ctx, sourceID); err != nil {
+		rollBack()
+		return nil, fmt.Errorf("failed to delete POI from database: %w", err)
+	}
+
+	// The source is gone, so leftover participants or images are only stale
+	if err := s.participants.RemoveAllParticipants(ctx, sourceID); err != nil {
+		fmt.Printf("Warning: failed to remove participants of merged POI: %v\n", err)
+	}
+	if !movesImage {
+		s.deletePOIImages(ctx, source)
+	}
+	if s.rsvps != nil {
+		if err := s.rsvps.MoveRSVPs(ctx, sourceID, targetID); err != nil {
+			fmt.Printf("Warning: failed to move RSVPs of merged POI: %v\n", err)
+		}
+	}
+
+	// Update discussion timer based on new participant count
+	if err := s.updateDiscussionTimer(ctx, targetID); err != nil {
+		// Discussion timer is not critical for POI functionality
+	}
+
+	currentCount, err := s.participants.GetParticipantCount(ctx, targetID)
+	if err != nil {
+		fmt.Printf("Warning: failed to get POI participant count for event: %v\n", err)
+		currentCount = 0
+	}
+
+	mergedEvent := redis.POIMergedEvent{
+		SourcePOIID:  sourceID,
+		TargetPOIID:  targetID,
+		MapID:        source.MapID,
+		MergedBy:     mergedBy,
+		MovedUserIDs: movedUserIDs,
+		CurrentCount: currentCount,
+		Timestamp:    time.Now(),
+	}
+
+	if err := s.pubsub.PublishPOIMerged(ctx, mergedEvent); err != nil {
+		// Log error but don't fail the operation
+		fmt.Printf("Warning: failed to publish POI merged event: %v\n", err)
+	}
+
+	return target, nil
+}
+
 // JoinPOI adds a user to a POI with capacity checking
 func (s *POIService) JoinPOI(ctx context.Context, poiID, userID string) error {
 	// Get POI to verify it exists and get capacity info
 	poi, err := s.poiRepo.GetByID(ctx, poiID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return fmt.Errorf("POI not found: %s", poiID)
+			return fmt.Errorf("POI %w: %s", ErrNotFound, poiID)
 		}
 		return fmt.Errorf("failed to get POI: %w", err)
 	}
+	if err := s.checkNotFrozen(poi.MapID); err != nil {
+		return err
+	}
 
 	// Check if user is already a participant
 	isParticipant, err := s.participants.IsParticipant(ctx, poiID, userID)
@@ -484,53 +947,42 @@ func (s *POIService) JoinPOI(ctx context.Context, poiID, userID string) error {
 		return fmt.Errorf("failed to check participant status: %w", err)
 	}
 	if isParticipant {
-		return fmt.Errorf("user is already a participant in POI %s", poiID)
+		return fmt.Errorf("%w: user is already a participant in POI %s", ErrAlreadyJoined, poiID)
 	}
 
-	// Check if POI has capacity
-	canJoin, err := s.participants.CanJoinPOI(ctx, poiID, poi.MaxParticipants)
-	if err != nil {
-		return fmt.Errorf("failed to check POI capacity: %w", err)
-	}
-	if !canJoin {
-		return fmt.Errorf("POI is at maximum capacity (%d participants)", poi.MaxParticipants)
+	// Seats reserved for other users at a scheduled POI are not available
+	if err := s.checkReservedSeats(ctx, poi, userID); err != nil {
+		return err
 	}
 
-	// Add user to POI
-	if err := s.participants.JoinPOI(ctx, poiID, userID); err != nil {
+	// Add user to POI, checking capacity atomically so concurrent joins and a
+	// lowered maxParticipants can't push the POI over its limit
+	if err := s.participants.JoinPOIWithCapacityCheck(ctx, poiID, userID, poi.MaxParticipants); err != nil {
+		if errors.Is(err, redis.ErrPOIAtCapacity) {
+			return fmt.Errorf("%w: POI is at maximum capacity (%d participants)", ErrCapacityExceeded, poi.MaxParticipants)
+		}
 		return fmt.Errorf("failed to join POI: %w", err)
 	}
 	
+	// Count the join towards the POI's popularity
+	if s.joinHistory != nil {
+		if err := s.joinHistory.RecordJoin(ctx, poiID, userID, time.Now(), POIPopularityWindow); err != nil {
+			fmt.Printf("Warning: failed to record POI join: %v\n", err)
+		}
+	}
+	
 	// Update discussion timer based on new participant count
 	if err := s.updateDiscussionTimer(ctx, poiID); err != nil {
 		// Log error but don't fail the join operation
 		// Discussion timer is not critical for POI functionality
 	}
 
-	// Get participant information for the event
-	participants, err := s.GetPOIParticipantsWithInfo(ctx, poiID)
-	if err != nil {
-		// Log error but don't fail the join operation
-		fmt.Printf("Warning: failed to get POI participants for event: %v\n", err)
-		participants = []POIParticipantInfo{} // Fallback to empty
-	}
-
 	// Get current participant count
 	currentCount, err := s.GetPOIParticipantCount(ctx, poiID)
 	if err != nil {
 		// Log error but don't fail the join operation
 		fmt.Printf("Warning: failed to get POI participant count for event: %v\n", err)
-		currentCount = len(participants) // Fallback
-	}
-
-	// Convert participants to Redis event format
-	var redisParticipants []redis.POIParticipant
-	for _, p := range participants {
-		redisParticipants = append(redisParticipants, redis.POIParticipant{
-			ID:        p.ID,
-			Name:      p.Name,
-			AvatarURL: p.AvatarURL,
-		})
+		currentCount = 0
 	}
 
 	// Get joining user information separately to ensure it's always available
@@ -539,34 +991,42 @@ func (s *POIService) JoinPOI(ctx context.Context, poiID, userID string) error {
 		Name:      userID, // Fallback to userID
 		AvatarURL: "",
 	}
+	if joinedAt, ok := s.getJoinTimes(ctx, poiID)[userID]; ok {
+		joiningUser.JoinedAt = &joinedAt
+	}
 
 	// Try to get user details for the joining user
 	if s.userService != nil {
 		user, err := s.userService.GetUser(ctx, userID)
 		if err == nil && user != nil {
 			joiningUser.Name = user.DisplayName
-			if user.AvatarURL != nil {
+			if user.AvatarURL != nil && !s.avatarsHidden(ctx, poi.MapID) {
 				joiningUser.AvatarURL = *user.AvatarURL
 			}
 		}
 		// If user service fails, we continue with fallback values
 	}
 
-	// Publish POI joined event with participant information
-	joinedEvent := redis.POIJoinedEventWithParticipants{
+	// Publish only the change; clients reconcile against periodic full rosters
+	addedEvent := redis.POIParticipantDeltaEvent{
 		POIID:        poiID,
 		MapID:        poi.MapID,
 		UserID:       userID,
-		SessionID:    "", // Will be filled by WebSocket handler if needed
+		Participant:  &joiningUser,
 		CurrentCount: currentCount,
-		Participants: redisParticipants,
-		JoiningUser:  joiningUser, // Always include joining user info
+		Sequence:     s.nextRosterSequence(ctx, poiID),
 		Timestamp:    time.Now(),
 	}
 
-	if err := s.pubsub.PublishPOIJoinedWithParticipants(ctx, joinedEvent); err != nil {
+	if err := s.pubsub.PublishPOIParticipantAdded(ctx, addedEvent); err != nil {
 		// Log error but don't fail the operation
-		fmt.Printf("Warning: failed to publish POI joined event with participants: %v\n", err)
+		fmt.Printf("Warning: failed to publish POI participant added event: %v\n", err)
+	}
+
+	if s.onboarding != nil {
+		if err := s.onboarding.CompleteStep(ctx, userID, models.OnboardingFirstPOIJoin); err != nil {
+			fmt.Printf("Warning: failed to complete onboarding step: %v\n", err)
+		}
 	}
 
 	return nil
@@ -578,10 +1038,13 @@ func (s *POIService) LeavePOI(ctx context.Context, poiID, userID string) error {
 	poi, err := s.poiRepo.GetByID(ctx, poiID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return fmt.Errorf("POI not found: %s", poiID)
+			return fmt.Errorf("POI %w: %s", ErrNotFound, poiID)
 		}
 		return fmt.Errorf("failed to get POI: %w", err)
 	}
+	if err := s.checkNotFrozen(poi.MapID); err != nil {
+		return err
+	}
 
 	// Check if user is a participant
 	isParticipant, err := s.participants.IsParticipant(ctx, poiID, userID)
@@ -592,10 +1055,54 @@ func (s *POIService) LeavePOI(ctx context.Context, poiID, userID string) error {
 		return fmt.Errorf("user is not a participant in POI %s", poiID)
 	}
 
+	return s.removeParticipant(ctx, poi, userID)
+}
+
+// ReconcileParticipants removes participants of a map's POIs that aren't in
+// activeUserIDs, such as users whose instance crashed before they left, and
+// publishes the corrected counts. It returns how many participants were removed.
+func (s *POIService) ReconcileParticipants(ctx context.Context, mapID string, activeUserIDs []string) (int, error) {
+	pois, err := s.GetPOIsForMap(ctx, mapID)
+	if err != nil {
+		return 0, err
+	}
+
+	active := make(map[string]bool, len(activeUserIDs))
+	for _, userID := range activeUserIDs {
+		active[userID] = true
+	}
+
+	removed := 0
+	for _, poi := range pois {
+		participants, err := s.participants.GetParticipants(ctx, poi.ID)
+		if err != nil {
+			return removed, fmt.Errorf("failed to get POI participants: %w", err)
+		}
+
+		for _, userID := range participants {
+			if active[userID] {
+				continue
+			}
+			if err := s.removeParticipant(ctx, poi, userID); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+	}
+
+	return removed, nil
+}
+
+// removeParticipant removes a user from a POI and publishes the change
+func (s *POIService) removeParticipant(ctx context.Context, poi *models.POI, userID string) error {
+	poiID := poi.ID
+	joinedAt, joinTimeKnown := s.getJoinTimes(ctx, poiID)[userID]
+
 	// Remove user from POI
 	if err := s.participants.LeavePOI(ctx, poiID, userID); err != nil {
 		return fmt.Errorf("failed to leave POI: %w", err)
 	}
+	s.trackPOILeft(poi, userID, joinedAt, joinTimeKnown)
 	
 	// Update discussion timer based on new participant count
 	if err := s.updateDiscussionTimer(ctx, poiID); err != nil {
@@ -609,38 +1116,19 @@ func (s *POIService) LeavePOI(ctx context.Context, poiID, userID string) error {
 		currentCount = 0 // Fallback to 0 if we can't get the count
 	}
 
-	// Get updated participant information for the event (after user left)
-	participantsInfo, err := s.GetPOIParticipantsWithInfo(ctx, poiID)
-	if err != nil {
-		// Log error but continue with basic event
-		fmt.Printf("Warning: failed to get participant info for POI left event: %v\n", err)
-		participantsInfo = []POIParticipantInfo{}
-	}
-
-	// Convert to Redis participant format
-	var redisParticipants []redis.POIParticipant
-	for _, p := range participantsInfo {
-		redisParticipants = append(redisParticipants, redis.POIParticipant{
-			ID:        p.ID,
-			Name:      p.Name,
-			AvatarURL: p.AvatarURL,
-		})
-	}
-
-	// Publish enhanced POI left event with participant information
-	leftEventWithParticipants := redis.POILeftEventWithParticipants{
+	// Publish only the change; clients reconcile against periodic full rosters
+	removedEvent := redis.POIParticipantDeltaEvent{
 		POIID:        poiID,
 		MapID:        poi.MapID,
 		UserID:       userID,
-		SessionID:    userID, // For now, use userID as sessionID
 		CurrentCount: currentCount,
-		Participants: redisParticipants,
+		Sequence:     s.nextRosterSequence(ctx, poiID),
 		Timestamp:    time.Now(),
 	}
 
-	if err := s.pubsub.PublishPOILeftWithParticipants(ctx, leftEventWithParticipants); err != nil {
+	if err := s.pubsub.PublishPOIParticipantRemoved(ctx, removedEvent); err != nil {
 		// Log error but don't fail the operation
-		fmt.Printf("Warning: failed to publish POI left event with participants: %v\n", err)
+		fmt.Printf("Warning: failed to publish POI participant removed event: %v\n", err)
 	}
 
 	return nil
@@ -672,6 +1160,18 @@ func (s *POIService) GetPOIParticipantsWithInfo(ctx context.Context, poiID strin
 		return nil, fmt.Errorf("failed to get POI participants: %w", err)
 	}
 
+	joinTimes := s.getJoinTimes(ctx, poiID)
+
+	// The POI's map decides whether avatars are shared
+	hideAvatars := false
+	if s.profilePrivacy != nil && len(participantIDs) > 0 {
+		poi, err := s.poiRepo.GetByID(ctx, poiID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get POI: %w", err)
+		}
+		hideAvatars = s.avatarsHidden(ctx, poi.MapID)
+	}
+
 	// Get user information for each participant
 	var participantsInfo []POIParticipantInfo
 	for _, userID := range participantIDs {
@@ -680,13 +1180,16 @@ func (s *POIService) GetPOIParticipantsWithInfo(ctx context.Context, poiID strin
 			Name:      userID, // Fallback to userID
 			AvatarURL: "",     // No avatar by default
 		}
+		if joinedAt, ok := joinTimes[userID]; ok {
+			participantInfo.JoinedAt = &joinedAt
+		}
 
 		// Try to get user details if user service is available
 		if s.userService != nil {
 			user, err := s.userService.GetUser(ctx, userID)
 			if err == nil && user != nil {
 				participantInfo.Name = user.DisplayName
-				if user.AvatarURL != nil {
+				if user.AvatarURL != nil && !hideAvatars {
 					participantInfo.AvatarURL = *user.AvatarURL
 				}
 			}
@@ -699,6 +1202,56 @@ func (s *POIService) GetPOIParticipantsWithInfo(ctx context.Context, poiID strin
 	return participantsInfo, nil
 }
 
+// GetPOIRoster returns a full snapshot of a POI's participants
+func (s *POIService) GetPOIRoster(ctx context.Context, poiID string) (*POIRoster, error) {
+	// Read the sequence first so the snapshot is never older than its number
+	var seq int64
+	if s.sequencer != nil {
+		current, err := s.sequencer.GetRosterSequence(ctx, poiID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get roster sequence: %w", err)
+		}
+		seq = current
+	}
+
+	participants, err := s.GetPOIParticipantsWithInfo(ctx, poiID)
+	if err != nil {
+		return nil, err
+	}
+	if participants == nil {
+		participants = []POIParticipantInfo{}
+	}
+
+	return &POIRoster{
+		POIID:        poiID,
+		Participants: participants,
+		CurrentCount: len(participants),
+		Sequence:     seq,
+	}, nil
+}
+
+// GetPOIRostersForMap returns roster snapshots for every POI on a map whose
+// roster has changed at least once. POIs nobody ever joined are omitted.
+func (s *POIService) GetPOIRostersForMap(ctx context.Context, mapID string) ([]POIRoster, error) {
+	pois, err := s.GetPOIsForMap(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+
+	rosters := []POIRoster{}
+	for _, poi := range pois {
+		roster, err := s.GetPOIRoster(ctx, poi.ID)
+		if err != nil {
+			return nil, err
+		}
+		if roster.Sequence == 0 && roster.CurrentCount == 0 {
+			continue
+		}
+		rosters = append(rosters, *roster)
+	}
+	return rosters, nil
+}
+
 // GetUserPOIs retrieves all POIs a user is participating in
 func (s *POIService) GetUserPOIs(ctx context.Context, userID string) ([]string, error) {
 	poiIDs, err := s.participants.GetPOIsForParticipant(ctx, userID)
@@ -708,12 +1261,29 @@ func (s *POIService) GetUserPOIs(ctx context.Context, userID string) ([]string,
 	return poiIDs, nil
 }
 
+// GetCurrentPOI returns the POI a user is currently in, or an empty string if
+// the user isn't in any. Clients leave a POI before joining another, so there is
+// normally at most one; should a user be in several, the lowest ID is returned
+// to keep the answer stable.
+func (s *POIService) GetCurrentPOI(ctx context.Context, userID string) (string, error) {
+	poiIDs, err := s.GetUserPOIs(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if len(poiIDs) == 0 {
+		return "", nil
+	}
+
+	sort.Strings(poiIDs)
+	return poiIDs[0], nil
+}
+
 // ValidatePOI validates that a POI exists
 func (s *POIService) ValidatePOI(ctx context.Context, poiID string) (*models.POI, error) {
 	poi, err := s.poiRepo.GetByID(ctx, poiID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("POI not found: %s", poiID)
+			return nil, fmt.Errorf("POI %w: %s", ErrNotFound, poiID)
 		}
 		return nil, fmt.Errorf("failed to validate POI: %w", err)
 	}
@@ -722,6 +1292,104 @@ func (s *POIService) ValidatePOI(ctx context.Context, poiID string) (*models.POI
 
 // Helper methods
 
+// checkMergeCapacity checks that the target of a merge can seat its own and
+// the source's participants, and at a scheduled target the users either POI
+// holds seats for
+func (s *POIService) checkMergeCapacity(ctx context.Context, source, target *models.POI, sourceParticipants []string) error {
+	targetParticipants, err := s.participants.GetParticipants(ctx, target.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check POI capacity: %w", err)
+	}
+
+	seated := make(map[string]bool, len(targetParticipants)+len(sourceParticipants))
+	for _, userID := range append(targetParticipants, sourceParticipants...) {
+		seated[userID] = true
+	}
+
+	if s.reservations != nil && target.IsScheduled() {
+		for _, poi := range []*models.POI{source, target} {
+			reserved, err := s.reservations.GetReservedUserIDs(ctx, poi)
+			if err != nil {
+				// Reservations are best effort, so don't block merging
+				fmt.Printf("Warning: failed to get reserved seats for POI %s: %v\n", poi.ID, err)
+				continue
+			}
+			for _, userID := range reserved {
+				seated[userID] = true
+			}
+		}
+	}
+
+	if len(seated) > target.MaxParticipants {
+		return fmt.Errorf("%w: POI %s can't seat the %d users of both POIs (%d participants)", ErrCapacityExceeded, target.ID, len(seated), target.MaxParticipants)
+	}
+
+	return nil
+}
+
+// checkReservedSeats checks that joining leaves enough seats for the users a
+// scheduled POI holds seats for and who haven't joined yet
+func (s *POIService) checkReservedSeats(ctx context.Context, poi *models.POI, userID string) error {
+	if s.reservations == nil || !poi.IsScheduled() {
+		return nil
+	}
+
+	reserved, err := s.reservations.GetReservedUserIDs(ctx, poi)
+	if err != nil {
+		// Reservations are best effort, so don't block joining
+		fmt.Printf("Warning: failed to get reserved seats for POI %s: %v\n", poi.ID, err)
+		return nil
+	}
+	if len(reserved) == 0 {
+		return nil
+	}
+
+	participants, err := s.participants.GetParticipants(ctx, poi.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check POI capacity: %w", err)
+	}
+
+	joined := make(map[string]bool, len(participants))
+	for _, participant := range participants {
+		joined[participant] = true
+	}
+
+	held := 0
+	for _, reservedUserID := range reserved {
+		if reservedUserID != userID && !joined[reservedUserID] {
+			held++
+		}
+	}
+
+	if len(participants)+held >= poi.MaxParticipants {
+		return fmt.Errorf("%w: POI is at maximum capacity (%d participants, %d seats reserved)", ErrCapacityExceeded, poi.MaxParticipants, held)
+	}
+
+	return nil
+}
+
+// checkNotFrozen returns ErrMapFrozen if a facilitator froze the map
+func (s *POIService) checkNotFrozen(mapID string) error {
+	if s.freeze != nil && s.freeze.IsFrozen(mapID) {
+		return fmt.Errorf("%w: %s", ErrMapFrozen, mapID)
+	}
+	return nil
+}
+
+// nextRosterSequence numbers a roster change, falling back to 0 (unsequenced)
+// when no sequencer is configured or it fails
+func (s *POIService) nextRosterSequence(ctx context.Context, poiID string) int64 {
+	if s.sequencer == nil {
+		return 0
+	}
+	seq, err := s.sequencer.NextRosterSequence(ctx, poiID)
+	if err != nil {
+		fmt.Printf("Warning: failed to get next roster sequence: %v\n", err)
+		return 0
+	}
+	return seq
+}
+
 // validatePOIInput validates basic POI input parameters
 func (s *POIService) validatePOIInput(mapID, name, createdBy string, maxParticipants int) error {
 	if mapID == "" {
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/redis"
+
+	"gorm.io/gorm"
+)
+
+// Benchmarks use small in-memory fakes instead of testify mocks, which record
+// every call and would dominate the measurements.
+
+type benchPOIRepository struct {
+	POIRepositoryInterface
+	mutex sync.RWMutex
+	pois  map[string]*models.POI
+}
+
+func (r *benchPOIRepository) GetByID(ctx context.Context, id string) (*models.POI, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	poi, ok := r.pois[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	clone := *poi
+	return &clone, nil
+}
+
+func (r *benchPOIRepository) Update(ctx context.Context, poi *models.POI) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	clone := *poi
+	r.pois[poi.ID] = &clone
+	return nil
+}
+
+func (r *benchPOIRepository) Delete(ctx context.Context, id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.pois, id)
+	return nil
+}
+
+type benchPOIParticipants struct {
+	POIParticipantsInterface
+	mutex        sync.RWMutex
+	participants map[string]map[string]bool
+}
+
+func (p *benchPOIParticipants) JoinPOI(ctx context.Context, poiID, userID string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.participants[poiID] == nil {
+		p.participants[poiID] = make(map[string]bool)
+	}
+	p.participants[poiID][userID] = true
+	return nil
+}
+
+func (p *benchPOIParticipants) LeavePOI(ctx context.Context, poiID, userID string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	delete(p.participants[poiID], userID)
+	return nil
+}
+
+func (p *benchPOIParticipants) GetParticipants(ctx context.Context, poiID string) ([]string, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	userIDs := make([]string, 0, len(p.participants[poiID]))
+	for userID := range p.participants[poiID] {
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, nil
+}
+
+func (p *benchPOIParticipants) GetParticipantCount(ctx context.Context, poiID string) (int, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return len(p.participants[poiID]), nil
+}
+
+func (p *benchPOIParticipants) IsParticipant(ctx context.Context, poiID, userID string) (bool, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.participants[poiID][userID], nil
+}
+
+func (p *benchPOIParticipants) CanJoinPOI(ctx context.Context, poiID string, maxParticipants int) (bool, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return len(p.participants[poiID]) < maxParticipants, nil
+}
+
+func (p *benchPOIParticipants) JoinPOIWithCapacityCheck(ctx context.Context, poiID, userID string, maxParticipants int) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.participants[poiID][userID] {
+		return nil
+	}
+	if len(p.participants[poiID]) >= maxParticipants {
+		return redis.ErrPOIAtCapacity
+	}
+	if p.participants[poiID] == nil {
+		p.participants[poiID] = make(map[string]bool)
+	}
+	p.participants[poiID][userID] = true
+	return nil
+}
+
+func (p *benchPOIParticipants) RemoveAllParticipants(ctx context.Context, poiID string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	delete(p.participants, poiID)
+	return nil
+}
+
+type benchPubSub struct {
+	PubSub
+}
+
+func (p *benchPubSub) PublishPOIParticipantAdded(ctx context.Context, event redis.POIParticipantDeltaEvent) error {
+	return nil
+}
+
+func (p *benchPubSub) PublishPOIParticipantRemoved(ctx context.Context, event redis.POIParticipantDeltaEvent) error {
+	return nil
+}
+
+type benchUserService struct{}
+
+func (s *benchUserService) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	return &models.User{ID: userID, DisplayName: userID}, nil
+}
+
+// BenchmarkJoinPOIContention measures joining (and leaving) a handful of hot
+// POIs from many goroutines at once
+func BenchmarkJoinPOIContention(b *testing.B) {
+	for _, poiCount := range []int{1, 10} {
+		b.Run(fmt.Sprintf("pois=%d", poiCount), func(b *testing.B) {
+			repo := &benchPOIRepository{pois: make(map[string]*models.POI)}
+			for i := 0; i < poiCount; i++ {
+				poiID := fmt.Sprintf("poi-%d", i)
+				repo.pois[poiID] = &models.POI{ID: poiID, MapID: "bench-map", MaxParticipants: 1000000}
+			}
+			participants := &benchPOIParticipants{participants: make(map[string]map[string]bool)}
+			service := NewPOIService(repo, participants, &benchPubSub{}, &benchUserService{})
+
+			var userSeq int64
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				userID := fmt.Sprintf("user-%d", atomic.AddInt64(&userSeq, 1))
+				i := 0
+				for pb.Next() {
+					poiID := fmt.Sprintf("poi-%d", i%poiCount)
+					if err := service.JoinPOI(ctx, poiID, userID); err != nil {
+						b.Fatal(err)
+					}
+					if err := service.LeavePOI(ctx, poiID, userID); err != nil {
+						b.Fatal(err)
+					}
+					i++
+				}
+			})
+		})
+	}
+}
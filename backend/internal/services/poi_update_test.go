@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/redis"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type updateRecordingPubSub struct {
+	benchPubSub
+	updated []redis.POIUpdatedEvent
+	removed []redis.POIParticipantDeltaEvent
+	deleted []redis.POIDeletedEvent
+}
+
+func (p *updateRecordingPubSub) PublishPOIUpdated(ctx context.Context, event redis.POIUpdatedEvent) error {
+	p.updated = append(p.updated, event)
+	return nil
+}
+
+func (p *updateRecordingPubSub) PublishPOIDeleted(ctx context.Context, event redis.POIDeletedEvent) error {
+	p.deleted = append(p.deleted, event)
+	return nil
+}
+
+func (p *updateRecordingPubSub) PublishPOIParticipantRemoved(ctx context.Context, event redis.POIParticipantDeltaEvent) error {
+	p.removed = append(p.removed, event)
+	return nil
+}
+
+func newUpdateTestService() (*POIService, *benchPOIParticipants, *updateRecordingPubSub) {
+	repo := &benchPOIRepository{pois: map[string]*models.POI{
+		"poi-1": {
+			ID:              "poi-1",
+			MapID:           "map-1",
+			Name:            "Coffee Corner",
+			Description:     "Bring your own mug",
+			Position:        models.LatLng{Lat: 40.7128, Lng: -74.0060},
+			CreatedBy:       "user-1",
+			MaxParticipants: 10,
+			CreatedAt:       time.Now(),
+		},
+	}}
+	participants := &benchPOIParticipants{participants: make(map[string]map[string]bool)}
+	pubsub := &updateRecordingPubSub{}
+	return NewPOIService(repo, participants, pubsub, &benchUserService{}), participants, pubsub
+}
+
+func stringPtr(s string) *string { return &s }
+
+func intPtr(i int) *int { return &i }
+
+func TestPOIService_UpdatePOI_PartialUpdate(t *testing.T) {
+	service, _, pubsub := newUpdateTestService()
+	ctx := context.Background()
+
+	// Omitted fields keep their value
+	poi, err := service.UpdatePOI(ctx, "poi-1", POIUpdateData{Name: stringPtr("Tea Corner")})
+	require.NoError(t, err)
+	assert.Equal(t, "Tea Corner", poi.Name)
+	assert.Equal(t, "Bring your own mug", poi.Description)
+	assert.Equal(t, 10, poi.MaxParticipants)
+
+	// An explicitly empty description clears it
+	poi, err = service.UpdatePOI(ctx, "poi-1", POIUpdateData{Description: stringPtr("")})
+	require.NoError(t, err)
+	assert.Equal(t, "", poi.Description)
+	assert.Equal(t, "Tea Corner", poi.Name)
+
+	// An empty update changes nothing and publishes nothing
+	_, err = service.UpdatePOI(ctx, "poi-1", POIUpdateData{})
+	require.NoError(t, err)
+	assert.Len(t, pubsub.updated, 2)
+}
+
+func TestPOIService_UpdatePOI_Validation(t *testing.T) {
+	service, participants, _ := newUpdateTestService()
+	ctx := context.Background()
+
+	for _, userID := range []string{"user-1", "user-2", "user-3"} {
+		require.NoError(t, participants.JoinPOI(ctx, "poi-1", userID))
+	}
+
+	_, err := service.UpdatePOI(ctx, "poi-1", POIUpdateData{Name: stringPtr("  ")})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	_, err = service.UpdatePOI(ctx, "poi-1", POIUpdateData{MaxParticipants: intPtr(0)})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	// The limit cannot drop below the participants already in the POI
+	_, err = service.UpdatePOI(ctx, "poi-1", POIUpdateData{MaxParticipants: intPtr(2)})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	var capacityErr *CapacityBelowOccupancyError
+	require.ErrorAs(t, err, &capacityErr)
+	assert.Equal(t, 3, capacityErr.CurrentCount)
+
+	poi, err := service.UpdatePOI(ctx, "poi-1", POIUpdateData{MaxParticipants: intPtr(3)})
+	require.NoError(t, err)
+	assert.Equal(t, 3, poi.MaxParticipants)
+}
+
+func TestPOIService_JoinPOI_BlockedAfterCapacityLowered(t *testing.T) {
+	service, participants, _ := newUpdateTestService()
+	ctx := context.Background()
+
+	require.NoError(t, service.JoinPOI(ctx, "poi-1", "user-1"))
+	_, err := service.UpdatePOI(ctx, "poi-1", POIUpdateData{MaxParticipants: intPtr(1)})
+	require.NoError(t, err)
+
+	// A join that slipped in before the limit was lowered leaves the POI over it
+	require.NoError(t, participants.JoinPOI(ctx, "poi-1", "user-2"))
+
+	err = service.JoinPOI(ctx, "poi-1", "user-3")
+	assert.ErrorIs(t, err, ErrCapacityExceeded)
+}
+
+func TestPOIService_DeletePOI_PublishesRemovedParticipants(t *testing.T) {
+	service, participants, pubsub := newUpdateTestService()
+	ctx := context.Background()
+
+	require.NoError(t, participants.JoinPOI(ctx, "poi-1", "user-1"))
+	require.NoError(t, participants.JoinPOI(ctx, "poi-1", "user-2"))
+
+	require.NoError(t, service.DeletePOI(ctx, "poi-1"))
+
+	var removedUserIDs []string
+	for _, event := range pubsub.removed {
+		assert.Equal(t, "map-1", event.MapID)
+		assert.Equal(t, "deleted", event.Reason)
+		removedUserIDs = append(removedUserIDs, event.UserID)
+	}
+	assert.ElementsMatch(t, []string{"user-1", "user-2"}, removedUserIDs)
+
+	count, err := participants.GetParticipantCount(ctx, "poi-1")
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestPOIService_DeletePOI_PublishesDeletion(t *testing.T) {
+	service, _, pubsub := newUpdateTestService()
+
+	require.NoError(t, service.DeletePOI(context.Background(), "poi-1"))
+
+	require.Len(t, pubsub.deleted, 1)
+	assert.Equal(t, "poi-1", pubsub.deleted[0].POIID)
+	assert.Equal(t, "map-1", pubsub.deleted[0].MapID)
+	assert.Empty(t, pubsub.removed)
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"breakoutglobe/internal/models"
+)
+
+// PreferenceStore defines the interface for reading and storing user preferences
+type PreferenceStore interface {
+	ListByUser(ctx context.Context, userID string) ([]*models.UserPreference, error)
+	SaveAll(ctx context.Context, preferences []*models.UserPreference) error
+}
+
+// PreferenceService manages the settings users carry across devices, like
+// notification, accessibility and video defaults
+type PreferenceService struct {
+	store PreferenceStore
+	now   func() time.Time
+}
+
+// NewPreferenceService creates a new PreferenceService instance
+func NewPreferenceService(store PreferenceStore) *PreferenceService {
+	return &PreferenceService{
+		store: store,
+		now:   time.Now,
+	}
+}
+
+// GetPreferences returns all preferences of a user, with defaults for the ones never set
+func (s *PreferenceService) GetPreferences(ctx context.Context, userID string) (models.Preferences, error) {
+	stored, err := s.storedPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences := models.DefaultPreferences()
+	preferences.Merge(stored)
+	return preferences, nil
+}
+
+// UpdatePreferences changes the given preferences of a user and returns all of
+// them. Namespaces and keys that aren't given keep their values.
+func (s *PreferenceService) UpdatePreferences(ctx context.Context, userID string, update models.Preferences) (models.Preferences, error) {
+	if err := update.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid preferences: %w", err)
+	}
+
+	stored, err := s.storedPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	stored.Merge(update)
+
+	// Only explicitly set values are stored so changed defaults still apply
+	now := s.now()
+	changed := make([]*models.UserPreference, 0, len(update))
+	for namespace := range update {
+		changed = append(changed, &models.UserPreference{
+			UserID:    userID,
+			Namespace: namespace,
+			Values:    stored[namespace],
+			UpdatedAt: now,
+		})
+	}
+	if err := s.store.SaveAll(ctx, changed); err != nil {
+		return nil, err
+	}
+
+	preferences := models.DefaultPreferences()
+	preferences.Merge(stored)
+	return preferences, nil
+}
+
+// DoNotTrack reports whether a user opted out of product analytics
+func (s *PreferenceService) DoNotTrack(ctx context.Context, userID string) (bool, error) {
+	preferences, err := s.GetPreferences(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	doNotTrack, _ := preferences[models.PreferencePrivacy]["doNotTrack"].(bool)
+	return doNotTrack, nil
+}
+
+// storedPreferences returns the preferences a user explicitly set. Stored values
+// of preferences that were since removed or changed type are skipped.
+func (s *PreferenceService) storedPreferences(ctx context.Context, userID string) (models.Preferences, error) {
+	stored, err := s.store.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences := make(models.Preferences, len(stored))
+	for _, preference := range stored {
+		for key, value := range preference.Values {
+			single := models.Preferences{preference.Namespace: {key: value}}
+			if single.Validate() == nil {
+				preferences.Merge(single)
+			}
+		}
+	}
+
+	return preferences, nil
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePreferenceStore struct {
+	preferences map[string]*models.UserPreference // userID:namespace -> preference
+}
+
+func (s *fakePreferenceStore) ListByUser(ctx context.Context, userID string) ([]*models.UserPreference, error) {
+	var preferences []*models.UserPreference
+	for _, preference := range s.preferences {
+		if preference.UserID == userID {
+			preferences = append(preferences, preference)
+		}
+	}
+	return preferences, nil
+}
+
+func (s *fakePreferenceStore) SaveAll(ctx context.Context, preferences []*models.UserPreference) error {
+	for _, preference := range preferences {
+		s.preferences[preference.UserID+":"+string(preference.Namespace)] = preference
+	}
+	return nil
+}
+
+func TestPreferenceService_UpdatePreferences_MergesAndStoresOnlySetValues(t *testing.T) {
+	store := &fakePreferenceStore{preferences: make(map[string]*models.UserPreference)}
+	service := NewPreferenceService(store)
+	ctx := context.Background()
+
+	_, err := service.UpdatePreferences(ctx, "user-1", models.Preferences{models.PreferenceVideo: {"cameraOn": false}})
+	require.NoError(t, err)
+
+	preferences, err := service.UpdatePreferences(ctx, "user-1", models.Preferences{models.PreferenceVideo: {"micOn": false}})
+	require.NoError(t, err)
+	assert.Equal(t, false, preferences[models.PreferenceVideo]["cameraOn"])
+	assert.Equal(t, false, preferences[models.PreferenceVideo]["micOn"])
+	assert.Equal(t, "auto", preferences[models.PreferenceVideo]["resolution"])
+	assert.Equal(t, true, preferences[models.PreferenceNotifications]["sound"])
+
+	assert.Equal(t, map[string]interface{}{"cameraOn": false, "micOn": false}, store.preferences["user-1:video"].Values)
+	assert.NotContains(t, store.preferences, "user-1:notifications")
+}
+
+func TestPreferenceService_UpdatePreferences_RejectsInvalidPreferences(t *testing.T) {
+	store := &fakePreferenceStore{preferences: make(map[string]*models.UserPreference)}
+	service := NewPreferenceService(store)
+
+	_, err := service.UpdatePreferences(context.Background(), "user-1", models.Preferences{
+		models.PreferenceVideo: {"cameraOn": false},
+		models.PreferenceA11y:  {"fontScale": 5.0},
+	})
+	assert.ErrorContains(t, err, "invalid preferences")
+	assert.Empty(t, store.preferences)
+}
+
+func TestPreferenceService_GetPreferences_SkipsStaleStoredValues(t *testing.T) {
+	store := &fakePreferenceStore{preferences: map[string]*models.UserPreference{
+		"user-1:a11y": {UserID: "user-1", Namespace: models.PreferenceA11y, Values: map[string]interface{}{
+			"highContrast": true,
+			"legacyTheme":  "dark",
+			"fontScale":    "large",
+		}},
+	}}
+	service := NewPreferenceService(store)
+
+	preferences, err := service.GetPreferences(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"highContrast": true, "reducedMotion": false, "fontScale": 1.0}, preferences[models.PreferenceA11y])
+}
+
+func TestPreferenceService_DoNotTrack(t *testing.T) {
+	store := &fakePreferenceStore{preferences: make(map[string]*models.UserPreference)}
+	service := NewPreferenceService(store)
+	ctx := context.Background()
+
+	doNotTrack, err := service.DoNotTrack(ctx, "user-1")
+	require.NoError(t, err)
+	assert.False(t, doNotTrack)
+
+	_, err = service.UpdatePreferences(ctx, "user-1", models.Preferences{models.PreferencePrivacy: {"doNotTrack": true}})
+	require.NoError(t, err)
+	doNotTrack, err = service.DoNotTrack(ctx, "user-1")
+	require.NoError(t, err)
+	assert.True(t, doNotTrack)
+}
@@ -22,18 +22,52 @@ const (
 	ActionLeavePOI      ActionType = "leave_poi"
 	ActionUpdatePOI     ActionType = "update_poi"
 	ActionDeletePOI     ActionType = "delete_poi"
+	
+	// WebSocket messages are limited per feature, apart from the REST endpoints,
+	// so a client flooding one feature can back off without losing the others
+	ActionWSPOIJoin  ActionType = "ws_poi_join"
+	ActionWSPOILeave ActionType = "ws_poi_leave"
+	ActionWSSpeaking ActionType = "ws_speaking"
+	ActionWSCall     ActionType = "ws_call"
+	ActionWSSignal   ActionType = "ws_signal"
+	ActionWSReaction ActionType = "ws_reaction"
+	ActionWSHand     ActionType = "ws_hand"
 )
 
 // RateLimit defines the limit configuration for an action
 type RateLimit struct {
 	Requests int           `json:"requests"` // Number of requests allowed
 	Window   time.Duration `json:"window"`   // Time window for the limit
+	
+	// Token bucket parameters, used by TokenBucketLimiter
+	Burst         int     `json:"burst,omitempty"`         // Requests allowed at once
+	SustainedRate float64 `json:"sustainedRate,omitempty"` // Requests per second the bucket refills with
+}
+
+// IsTokenBucket reports whether the limit defines burst and sustained rate parameters
+func (r RateLimit) IsTokenBucket() bool {
+	return r.Burst > 0 && r.SustainedRate > 0
+}
+
+// BurstWindow returns the time an empty token bucket needs to refill completely
+func (r RateLimit) BurstWindow() time.Duration {
+	if !r.IsTokenBucket() {
+		return 0
+	}
+	return time.Duration(float64(r.Burst) / r.SustainedRate * float64(time.Second))
 }
 
+// DefaultRateLimitWarningThreshold is the share of a limit after which clients are warned
+const DefaultRateLimitWarningThreshold = 0.8
+
+// RateLimitWarningHeader is set on responses when the client is close to a rate limit
+const RateLimitWarningHeader = "X-RateLimit-Warning"
+
 // RateLimiterConfig holds the configuration for the rate limiter
 type RateLimiterConfig struct {
-	DefaultLimits map[ActionType]RateLimit `json:"defaultLimits"`
-	KeyPrefix     string                   `json:"keyPrefix"`
+	DefaultLimits    map[ActionType]RateLimit `json:"defaultLimits"`
+	KeyPrefix        string                   `json:"keyPrefix"`
+	WarningThreshold float64                  `json:"warningThreshold"` // Share of a limit that triggers a warning, 0 disables warnings
 }
 
 // RedisClientInterface defines the interface for Redis operations needed by rate limiter
@@ -82,6 +116,31 @@ type RateLimiterInterface interface {
 	GetRateLimitHeaders(ctx context.Context, userID string, action ActionType) (map[string]string, error)
 }
 
+// RateLimitWarner is implemented by rate limiters that report soft warnings
+// before a limit is reached, so well-behaved clients can throttle themselves
+type RateLimitWarner interface {
+	// CheckRateLimitWithWarning works like CheckRateLimit, and additionally returns
+	// a warning when the request brings the user close to the limit
+	CheckRateLimitWithWarning(ctx context.Context, userID string, action ActionType) (*RateLimitWarning, error)
+}
+
+// RateLimitWarning describes a user approaching a rate limit
+type RateLimitWarning struct {
+	UserID    string     `json:"userId"`
+	Action    ActionType `json:"action"`
+	Limit     int        `json:"limit"`
+	Remaining int        `json:"remaining"`
+	ResetTime time.Time  `json:"resetTime"`
+}
+
+// IsNearRateLimit reports whether used requests reached the warning threshold of limit
+func IsNearRateLimit(used, limit int, threshold float64) bool {
+	if threshold <= 0 || limit <= 0 {
+		return false
+	}
+	return float64(used) >= threshold*float64(limit)
+}
+
 // RateLimiter implements sliding window rate limiting using Redis sorted sets
 type RateLimiter struct {
 	redis       RedisClientInterface
@@ -118,6 +177,17 @@ func NewRateLimiter(redis RedisClientInterface, config RateLimiterConfig) *RateL
 
 // IsAllowed checks if a user is allowed to perform an action using sliding window algorithm
 func (rl *RateLimiter) IsAllowed(ctx context.Context, userID string, action ActionType) (bool, error) {
+	count, limit, err := rl.recordRequest(ctx, userID, action)
+	if err != nil {
+		return false, err
+	}
+	
+	return count <= int64(limit.Requests), nil
+}
+
+// recordRequest adds a request to the sliding window and returns the number of
+// requests in the window, including this one
+func (rl *RateLimiter) recordRequest(ctx context.Context, userID string, action ActionType) (int64, RateLimit, error) {
 	limit := rl.getLimit(userID, action)
 	key := rl.getKey(userID, action)
 	now := time.Now()
@@ -142,20 +212,20 @@ func (rl *RateLimiter) IsAllowed(ctx context.Context, userID string, action Acti
 	// Execute pipeline
 	results, err := pipe.Exec(ctx)
 	if err != nil {
-		return false, fmt.Errorf("failed to execute rate limit check: %w", err)
+		return 0, limit, fmt.Errorf("failed to execute rate limit check: %w", err)
 	}
 	
 	// Get count from results (3rd operation - ZCard)
 	if len(results) < 3 {
-		return false, fmt.Errorf("unexpected pipeline results length: %d", len(results))
+		return 0, limit, fmt.Errorf("unexpected pipeline results length: %d", len(results))
 	}
 	
 	count, ok := results[2].(int64)
 	if !ok {
-		return false, fmt.Errorf("unexpected count type: %T", results[2])
+		return 0, limit, fmt.Errorf("unexpected count type: %T", results[2])
 	}
 	
-	return count <= int64(limit.Requests), nil
+	return count, limit, nil
 }
 
 // GetRemainingRequests returns the number of remaining requests for a user action
@@ -317,8 +387,16 @@ func GetDefaultRateLimiterConfig() RateLimiterConfig {
 			ActionLeavePOI:      {Requests: 20, Window: time.Minute},     // 20 POI leaves per minute
 			ActionUpdatePOI:     {Requests: 10, Window: time.Minute},     // 10 POI updates per minute
 			ActionDeletePOI:     {Requests: 5, Window: time.Minute},      // 5 POI deletions per minute
+			ActionWSPOIJoin:     {Requests: 20, Window: time.Minute},     // 20 POI joins per minute
+			ActionWSPOILeave:    {Requests: 20, Window: time.Minute},     // 20 POI leaves per minute
+			ActionWSSpeaking:    {Requests: 120, Window: time.Minute},    // 120 speaking state changes per minute
+			ActionWSCall:        {Requests: 30, Window: time.Minute},     // 30 call requests, accepts, rejects and ends per minute
+			ActionWSSignal:      {Requests: 600, Window: time.Minute},    // 600 WebRTC offers, answers and ICE candidates per minute
+			ActionWSReaction:    {Requests: 30, Window: time.Minute},     // 30 emoji reactions per minute
+			ActionWSHand:        {Requests: 30, Window: time.Minute},     // 30 hand raises and lowers per minute
 		},
-		KeyPrefix: "rate_limit:",
+		KeyPrefix:        "rate_limit:",
+		WarningThreshold: DefaultRateLimitWarningThreshold,
 	}
 }
 
@@ -332,6 +410,10 @@ func ValidateConfig(config RateLimiterConfig) error {
 		return fmt.Errorf("default limits cannot be empty")
 	}
 	
+	if config.WarningThreshold < 0 || config.WarningThreshold >= 1 {
+		return fmt.Errorf("warning threshold must be between 0 and 1")
+	}
+	
 	for action, limit := range config.DefaultLimits {
 		if limit.Requests <= 0 {
 			return fmt.Errorf("requests must be positive for action %s", action)
@@ -395,6 +477,33 @@ func (rl *RateLimiter) CheckRateLimit(ctx context.Context, userID string, action
 	return nil
 }
 
+// CheckRateLimitWithWarning checks the rate limit and returns a warning when the
+// user crossed the configured warning threshold
+func (rl *RateLimiter) CheckRateLimitWithWarning(ctx context.Context, userID string, action ActionType) (*RateLimitWarning, error) {
+	count, limit, err := rl.recordRequest(ctx, userID, action)
+	if err != nil {
+		return nil, fmt.Errorf("rate limit check failed: %w", err)
+	}
+	
+	if count > int64(limit.Requests) {
+		resetTime, _ := rl.GetWindowResetTime(ctx, userID, action)
+		return nil, NewRateLimitError(userID, action, limit, resetTime)
+	}
+	
+	if !IsNearRateLimit(int(count), limit.Requests, rl.config.WarningThreshold) {
+		return nil, nil
+	}
+	
+	resetTime, _ := rl.GetWindowResetTime(ctx, userID, action)
+	return &RateLimitWarning{
+		UserID:    userID,
+		Action:    action,
+		Limit:     limit.Requests,
+		Remaining: limit.Requests - int(count),
+		ResetTime: resetTime,
+	}, nil
+}
+
 // GetRateLimitHeaders returns HTTP headers for rate limiting information
 func (rl *RateLimiter) GetRateLimitHeaders(ctx context.Context, userID string, action ActionType) (map[string]string, error) {
 	limit := rl.getLimit(userID, action)
@@ -415,5 +524,9 @@ func (rl *RateLimiter) GetRateLimitHeaders(ctx context.Context, userID string, a
 		"X-RateLimit-Window":    limit.Window.String(),
 	}
 	
+	if IsNearRateLimit(limit.Requests-remaining, limit.Requests, rl.config.WarningThreshold) {
+		headers[RateLimitWarningHeader] = "true"
+	}
+	
 	return headers, nil
 }
\ No newline at end of file
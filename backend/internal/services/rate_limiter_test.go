@@ -261,6 +261,82 @@ func (suite *RateLimiterTestSuite) TestIsAllowed_DifferentActions() {
 	}
 }
 
+func (suite *RateLimiterTestSuite) expectSlidingWindowCheck(ctx context.Context, key string, count int64) {
+	mockPipeline := new(MockPipeline)
+	suite.mockRedis.On("Pipeline").Return(PipelineInterface(mockPipeline))
+	
+	mockPipeline.On("ZRemRangeByScore", ctx, key, "0", mock.AnythingOfType("string")).Return()
+	mockPipeline.On("ZAdd", ctx, key, mock.AnythingOfType("float64"), mock.AnythingOfType("string")).Return()
+	mockPipeline.On("ZCard", ctx, key).Return()
+	mockPipeline.On("Expire", ctx, key, time.Minute*2).Return()
+	mockPipeline.On("Exec", ctx).Return([]interface{}{nil, nil, count, nil}, nil)
+}
+
+func (suite *RateLimiterTestSuite) TestCheckRateLimitWithWarning_BelowThreshold() {
+	ctx := context.Background()
+	suite.rateLimiter.config.WarningThreshold = 0.8
+	
+	suite.expectSlidingWindowCheck(ctx, "rate_limit:user-123:create_session", 7)
+	
+	warning, err := suite.rateLimiter.CheckRateLimitWithWarning(ctx, "user-123", ActionCreateSession)
+	
+	suite.NoError(err)
+	suite.Nil(warning)
+}
+
+func (suite *RateLimiterTestSuite) TestCheckRateLimitWithWarning_NearLimit() {
+	ctx := context.Background()
+	suite.rateLimiter.config.WarningThreshold = 0.8
+	key := "rate_limit:user-123:create_session"
+	
+	suite.expectSlidingWindowCheck(ctx, key, 8)
+	suite.mockRedis.On("ZRangeWithScores", ctx, key, int64(0), int64(0)).Return([]interface{}{}, nil)
+	
+	warning, err := suite.rateLimiter.CheckRateLimitWithWarning(ctx, "user-123", ActionCreateSession)
+	
+	suite.NoError(err)
+	suite.Require().NotNil(warning)
+	suite.Equal(ActionCreateSession, warning.Action)
+	suite.Equal(10, warning.Limit)
+	suite.Equal(2, warning.Remaining)
+}
+
+func (suite *RateLimiterTestSuite) TestCheckRateLimitWithWarning_ExceedsLimit() {
+	ctx := context.Background()
+	suite.rateLimiter.config.WarningThreshold = 0.8
+	key := "rate_limit:user-123:create_session"
+	
+	suite.expectSlidingWindowCheck(ctx, key, 11)
+	suite.mockRedis.On("ZRangeWithScores", ctx, key, int64(0), int64(0)).Return([]interface{}{}, nil)
+	
+	warning, err := suite.rateLimiter.CheckRateLimitWithWarning(ctx, "user-123", ActionCreateSession)
+	
+	suite.Nil(warning)
+	var rateLimitErr *RateLimitError
+	suite.ErrorAs(err, &rateLimitErr)
+}
+
+func (suite *RateLimiterTestSuite) TestGetRateLimitHeaders_Warning() {
+	ctx := context.Background()
+	suite.rateLimiter.config.WarningThreshold = 0.8
+	key := "rate_limit:user-123:create_session"
+	
+	suite.mockRedis.On("ZCard", ctx, key).Return(int64(9), nil)
+	suite.mockRedis.On("ZRangeWithScores", ctx, key, int64(0), int64(0)).Return([]interface{}{}, nil)
+	
+	headers, err := suite.rateLimiter.GetRateLimitHeaders(ctx, "user-123", ActionCreateSession)
+	
+	suite.NoError(err)
+	suite.Equal("true", headers[RateLimitWarningHeader])
+}
+
+func TestIsNearRateLimit(t *testing.T) {
+	assert.False(t, IsNearRateLimit(7, 10, 0.8))
+	assert.True(t, IsNearRateLimit(8, 10, 0.8))
+	assert.True(t, IsNearRateLimit(10, 10, 0.8))
+	assert.False(t, IsNearRateLimit(10, 10, 0), "a zero threshold disables warnings")
+}
+
 func (suite *RateLimiterTestSuite) TestGetRemainingRequests() {
 	ctx := context.Background()
 	userID := "user-123"
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"breakoutglobe/internal/models"
+)
+
+// rolloutRefreshInterval is how often rollouts are reloaded from the store.
+// Changes made on another instance apply after at most this long.
+const rolloutRefreshInterval = 10 * time.Second
+
+// RolloutStore defines the interface for persisting feature rollouts
+type RolloutStore interface {
+	GetRollouts(ctx context.Context) ([]models.FeatureRollout, error)
+	SaveRollout(ctx context.Context, rollout models.FeatureRollout) error
+}
+
+// RolloutService decides which users get risky new features. Users are
+// enabled if they are allowlisted or a stable hash of their user ID falls
+// below the rollout percentage, so raising the percentage only adds users.
+type RolloutService struct {
+	store       RolloutStore
+	mutex       sync.Mutex
+	rollouts    map[models.Feature]models.FeatureRollout
+	refreshedAt time.Time
+	now         func() time.Time
+}
+
+// NewRolloutService creates a new RolloutService instance. Without a store,
+// rollouts only live in memory and apply to this instance.
+func NewRolloutService(store RolloutStore) *RolloutService {
+	return &RolloutService{
+		store:    store,
+		rollouts: make(map[models.Feature]models.FeatureRollout),
+		now:      time.Now,
+	}
+}
+
+// IsEnabled reports whether a feature is enabled for a user. Anonymous users
+// only get features rolled out to everyone.
+func (s *RolloutService) IsEnabled(ctx context.Context, feature models.Feature, userID string) bool {
+	rollout, exists := s.currentRollouts(ctx)[feature]
+	if !exists {
+		return false
+	}
+	return isInRollout(rollout, userID)
+}
+
+// EnabledFeatures returns the features enabled for a user
+func (s *RolloutService) EnabledFeatures(ctx context.Context, userID string) []models.Feature {
+	rollouts := s.currentRollouts(ctx)
+
+	features := []models.Feature{}
+	for _, feature := range models.KnownFeatures {
+		if rollout, exists := rollouts[feature]; exists && isInRollout(rollout, userID) {
+			features = append(features, feature)
+		}
+	}
+	return features
+}
+
+// GetRollouts returns the rollouts of all known features, including features
+// that were never rolled out
+func (s *RolloutService) GetRollouts(ctx context.Context) []models.FeatureRollout {
+	rollouts := s.currentRollouts(ctx)
+
+	result := make([]models.FeatureRollout, 0, len(models.KnownFeatures))
+	for _, feature := range models.KnownFeatures {
+		rollout, exists := rollouts[feature]
+		if !exists {
+			rollout = models.FeatureRollout{Feature: feature, Allowlist: []string{}}
+		}
+		result = append(result, rollout)
+	}
+	return result
+}
+
+// SetRollout changes the percentage and allowlist of a feature at runtime
+func (s *RolloutService) SetRollout(ctx context.Context, rollout models.FeatureRollout) (models.FeatureRollout, error) {
+	if err := rollout.Validate(); err != nil {
+		return models.FeatureRollout{}, fmt.Errorf("invalid rollout: %w", err)
+	}
+	if rollout.Allowlist == nil {
+		rollout.Allowlist = []string{}
+	}
+	rollout.UpdatedAt = s.now()
+
+	if s.store != nil {
+		if err := s.store.SaveRollout(ctx, rollout); err != nil {
+			return models.FeatureRollout{}, err
+		}
+	}
+
+	// Replace the map instead of changing it, callers read it without the lock
+	s.mutex.Lock()
+	rollouts := make(map[models.Feature]models.FeatureRollout, len(s.rollouts)+1)
+	for feature, existing := range s.rollouts {
+		rollouts[feature] = existing
+	}
+	rollouts[rollout.Feature] = rollout
+	s.rollouts = rollouts
+	s.mutex.Unlock()
+
+	return rollout, nil
+}
+
+// currentRollouts returns the rollouts, reloading them from the store when
+// they are stale. If the store fails, the last known rollouts stay in effect.
+func (s *RolloutService) currentRollouts(ctx context.Context) map[models.Feature]models.FeatureRollout {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.store == nil || s.now().Sub(s.refreshedAt) < rolloutRefreshInterval {
+		return s.rollouts
+	}
+	s.refreshedAt = s.now()
+
+	stored, err := s.store.GetRollouts(ctx)
+	if err != nil {
+		fmt.Printf("Warning: failed to refresh feature rollouts: %v\n", err)
+		return s.rollouts
+	}
+
+	rollouts := make(map[models.Feature]models.FeatureRollout, len(stored))
+	for _, rollout := range stored {
+		rollouts[rollout.Feature] = rollout
+	}
+	s.rollouts = rollouts
+	return s.rollouts
+}
+
+// isInRollout reports whether a user is allowlisted or hashed into the rollout percentage
+func isInRollout(rollout models.FeatureRollout, userID string) bool {
+	if rollout.Percentage >= 100 {
+		return true
+	}
+	if userID == "" {
+		return false
+	}
+	for _, allowed := range rollout.Allowlist {
+		if allowed == userID {
+			return true
+		}
+	}
+	return rolloutBucket(rollout.Feature, userID) < rollout.Percentage
+}
+
+// rolloutBucket maps a user to a stable bucket between 0 and 99. The feature
+// is part of the hash so each feature is rolled out to a different set of users.
+func rolloutBucket(feature models.Feature, userID string) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(string(feature) + ":" + userID))
+	return int(hash.Sum32() % 100)
+}
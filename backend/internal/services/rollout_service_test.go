@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRolloutStore struct {
+	rollouts map[models.Feature]models.FeatureRollout
+	err      error
+}
+
+func (s *fakeRolloutStore) GetRollouts(ctx context.Context) ([]models.FeatureRollout, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	rollouts := []models.FeatureRollout{}
+	for _, rollout := range s.rollouts {
+		rollouts = append(rollouts, rollout)
+	}
+	return rollouts, nil
+}
+
+func (s *fakeRolloutStore) SaveRollout(ctx context.Context, rollout models.FeatureRollout) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.rollouts[rollout.Feature] = rollout
+	return nil
+}
+
+func TestRolloutService_Percentage(t *testing.T) {
+	service := NewRolloutService(nil)
+	ctx := context.Background()
+
+	assert.False(t, service.IsEnabled(ctx, models.FeatureBatchedMovement, "user-1"))
+
+	_, err := service.SetRollout(ctx, models.FeatureRollout{Feature: models.FeatureBatchedMovement, Percentage: 20})
+	assert.NoError(t, err)
+
+	enabled := map[string]bool{}
+	for i := 0; i < 1000; i++ {
+		userID := fmt.Sprintf("user-%d", i)
+		enabled[userID] = service.IsEnabled(ctx, models.FeatureBatchedMovement, userID)
+	}
+	count := 0
+	for _, isEnabled := range enabled {
+		if isEnabled {
+			count++
+		}
+	}
+	assert.InDelta(t, 200, count, 50)
+
+	// Raising the percentage keeps every user that already had the feature
+	_, err = service.SetRollout(ctx, models.FeatureRollout{Feature: models.FeatureBatchedMovement, Percentage: 50})
+	assert.NoError(t, err)
+	for userID, isEnabled := range enabled {
+		if isEnabled {
+			assert.True(t, service.IsEnabled(ctx, models.FeatureBatchedMovement, userID), userID)
+		}
+	}
+}
+
+func TestRolloutService_Allowlist(t *testing.T) {
+	service := NewRolloutService(nil)
+	ctx := context.Background()
+
+	_, err := service.SetRollout(ctx, models.FeatureRollout{Feature: models.FeatureBatchedMovement, Allowlist: []string{"canary-user"}})
+	assert.NoError(t, err)
+
+	assert.True(t, service.IsEnabled(ctx, models.FeatureBatchedMovement, "canary-user"))
+	assert.False(t, service.IsEnabled(ctx, models.FeatureBatchedMovement, "other-user"))
+	assert.Equal(t, []models.Feature{models.FeatureBatchedMovement}, service.EnabledFeatures(ctx, "canary-user"))
+
+	// Anonymous users only get features rolled out to everyone
+	assert.Empty(t, service.EnabledFeatures(ctx, ""))
+	_, err = service.SetRollout(ctx, models.FeatureRollout{Feature: models.FeatureBatchedMovement, Percentage: 100})
+	assert.NoError(t, err)
+	assert.True(t, service.IsEnabled(ctx, models.FeatureBatchedMovement, ""))
+}
+
+func TestRolloutService_SetRollout_Invalid(t *testing.T) {
+	service := NewRolloutService(nil)
+	ctx := context.Background()
+
+	_, err := service.SetRollout(ctx, models.FeatureRollout{Feature: "teleport", Percentage: 10})
+	assert.ErrorContains(t, err, "invalid rollout")
+
+	_, err = service.SetRollout(ctx, models.FeatureRollout{Feature: models.FeatureBatchedMovement, Percentage: 101})
+	assert.ErrorContains(t, err, "invalid rollout")
+}
+
+func TestRolloutService_RefreshesFromStore(t *testing.T) {
+	store := &fakeRolloutStore{rollouts: map[models.Feature]models.FeatureRollout{}}
+	service := NewRolloutService(store)
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	assert.False(t, service.IsEnabled(ctx, models.FeatureBatchedMovement, "user-1"))
+
+	// Another instance changes the rollout
+	store.rollouts[models.FeatureBatchedMovement] = models.FeatureRollout{Feature: models.FeatureBatchedMovement, Percentage: 100}
+	assert.False(t, service.IsEnabled(ctx, models.FeatureBatchedMovement, "user-1"))
+
+	now = now.Add(rolloutRefreshInterval)
+	assert.True(t, service.IsEnabled(ctx, models.FeatureBatchedMovement, "user-1"))
+
+	// A failing store keeps the last known rollouts
+	store.err = fmt.Errorf("connection refused")
+	now = now.Add(rolloutRefreshInterval)
+	assert.True(t, service.IsEnabled(ctx, models.FeatureBatchedMovement, "user-1"))
+}
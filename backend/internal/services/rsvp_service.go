@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/redis"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// RSVPReminderLead is how long before a scheduled POI starts its reminder is sent
+	RSVPReminderLead = 15 * time.Minute
+
+	// RSVPReservationGrace is how long after the start seats stay reserved for
+	// "going" users who haven't joined yet
+	RSVPReservationGrace = 10 * time.Minute
+)
+
+// RSVPStore defines the interface for RSVPs and the reminder state of scheduled POIs
+type RSVPStore interface {
+	UpsertRSVP(ctx context.Context, rsvp *models.POIRSVP) (*models.POIRSVP, error)
+	// UpsertGoingRSVP saves an RSVP if fewer than seats other users are
+	// going, atomically, and returns false if all seats are taken
+	UpsertGoingRSVP(ctx context.Context, rsvp *models.POIRSVP, seats int) (*models.POIRSVP, bool, error)
+	GetRSVP(ctx context.Context, poiID, userID string) (*models.POIRSVP, error)
+	DeleteRSVP(ctx context.Context, poiID, userID string) error
+	MoveRSVPs(ctx context.Context, fromPOIID, toPOIID string) error
+	ListUserIDs(ctx context.Context, poiID string, statuses ...models.RSVPStatus) ([]string, error)
+	CountByPOI(ctx context.Context, poiIDs []string) (map[string]models.RSVPCounts, error)
+	ListPOIsStartingBetween(ctx context.Context, from, to time.Time) ([]*models.POI, error)
+	MarkReminderSent(ctx context.Context, poiID string, sentAt time.Time) error
+}
+
+// RSVPPOILookup defines the interface for looking up the POI of an RSVP
+type RSVPPOILookup interface {
+	GetByID(ctx context.Context, id string) (*models.POI, error)
+}
+
+// RSVPReminderPublisher defines the interface for publishing reminders that
+// scheduled POIs start soon to every instance
+type RSVPReminderPublisher interface {
+	PublishPOIReminder(ctx context.Context, event redis.POIReminderEvent) error
+}
+
+// RSVPService manages RSVPs to scheduled POIs, reserves seats for users who
+// are going and reminds them before the start
+type RSVPService struct {
+	store     RSVPStore
+	pois      RSVPPOILookup
+	publisher RSVPReminderPublisher
+	now       func() time.Time
+}
+
+// NewRSVPService creates a new RSVPService instance
+func NewRSVPService(store RSVPStore, pois RSVPPOILookup) *RSVPService {
+	return &RSVPService{
+		store: store,
+		pois:  pois,
+		now:   time.Now,
+	}
+}
+
+// SetPublisher sets where reminders are published, so they reach users
+// connected to any instance. Without one, due reminders are dropped.
+func (s *RSVPService) SetPublisher(publisher RSVPReminderPublisher) {
+	s.publisher = publisher
+}
+
+// SetRSVP records a user's answer to a scheduled POI. Going is limited to the
+// POI's capacity since every going user holds a seat.
+func (s *RSVPService) SetRSVP(ctx context.Context, poiID, userID string, status models.RSVPStatus) (*models.POIRSVP, error) {
+	rsvp, err := models.NewPOIRSVP(poiID, userID, status)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rsvp: %w", err)
+	}
+
+	poi, err := s.getScheduledPOI(ctx, poiID)
+	if err != nil {
+		return nil, err
+	}
+	if !s.now().Before(*poi.StartsAt) {
+		return nil, fmt.Errorf("invalid rsvp: the event has already started")
+	}
+
+	if status != models.RSVPGoing {
+		return s.store.UpsertRSVP(ctx, rsvp)
+	}
+
+	saved, seated, err := s.store.UpsertGoingRSVP(ctx, rsvp, poi.MaxParticipants)
+	if err != nil {
+		return nil, err
+	}
+	if !seated {
+		return nil, fmt.Errorf("RSVP %w: all %d seats are reserved", ErrCapacityExceeded, poi.MaxParticipants)
+	}
+	return saved, nil
+}
+
+// GetRSVP returns a user's RSVP to a POI
+func (s *RSVPService) GetRSVP(ctx context.Context, poiID, userID string) (*models.POIRSVP, error) {
+	rsvp, err := s.store.GetRSVP(ctx, poiID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("RSVP %w", ErrNotFound)
+	}
+	return rsvp, err
+}
+
+// CancelRSVP removes a user's RSVP to a POI, releasing a reserved seat
+func (s *RSVPService) CancelRSVP(ctx context.Context, poiID, userID string) error {
+	err := s.store.DeleteRSVP(ctx, poiID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("RSVP %w", ErrNotFound)
+	}
+	return err
+}
+
+// MoveRSVPs moves the RSVPs of a merged POI to the POI it was merged into.
+// Users who answered both keep their answer to the target.
+func (s *RSVPService) MoveRSVPs(ctx context.Context, sourceID, targetID string) error {
+	return s.store.MoveRSVPs(ctx, sourceID, targetID)
+}
+
+// GetRSVPCounts counts the RSVPs of each POI by status. POIs without RSVPs are
+// left out.
+func (s *RSVPService) GetRSVPCounts(ctx context.Context, poiIDs []string) (map[string]models.RSVPCounts, error) {
+	return s.store.CountByPOI(ctx, poiIDs)
+}
+
+// GetReservedUserIDs returns the users a scheduled POI holds seats for. Seats
+// are released once the grace period after the start has passed.
+func (s *RSVPService) GetReservedUserIDs(ctx context.Context, poi *models.POI) ([]string, error) {
+	if !poi.IsScheduled() || s.now().After(poi.StartsAt.Add(RSVPReservationGrace)) {
+		return nil, nil
+	}
+
+	return s.store.ListUserIDs(ctx, poi.ID, models.RSVPGoing)
+}
+
+// SendDueReminders notifies the going and maybe users of every POI starting
+// within RSVPReminderLead and returns the number of reminders sent. A failing
+// POI doesn't stop the others.
+func (s *RSVPService) SendDueReminders(ctx context.Context) (int, error) {
+	now := s.now()
+	pois, err := s.store.ListPOIsStartingBetween(ctx, now, now.Add(RSVPReminderLead))
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, poi := range pois {
+		userIDs, err := s.store.ListUserIDs(ctx, poi.ID, models.RSVPGoing, models.RSVPMaybe)
+		if err != nil {
+			fmt.Printf("Warning: failed to list RSVPs of POI %s for reminder: %v\n", poi.ID, err)
+			continue
+		}
+
+		if len(userIDs) > 0 && s.publisher != nil {
+			event := redis.POIReminderEvent{
+				POIID:     poi.ID,
+				MapID:     poi.MapID,
+				Name:      poi.Name,
+				StartsAt:  *poi.StartsAt,
+				UserIDs:   userIDs,
+				Timestamp: now,
+			}
+			if err := s.publisher.PublishPOIReminder(ctx, event); err != nil {
+				// Retried on the next run since the reminder isn't marked as sent
+				fmt.Printf("Warning: failed to publish reminder of POI %s: %v\n", poi.ID, err)
+				continue
+			}
+			sent++
+		}
+
+		// Mark POIs without RSVPs too so they aren't checked again
+		if err := s.store.MarkReminderSent(ctx, poi.ID, now); err != nil {
+			fmt.Printf("Warning: failed to mark reminder of POI %s as sent: %v\n", poi.ID, err)
+		}
+	}
+
+	return sent, nil
+}
+
+// getScheduledPOI returns a POI that accepts RSVPs
+func (s *RSVPService) getScheduledPOI(ctx context.Context, poiID string) (*models.POI, error) {
+	poi, err := s.pois.GetByID(ctx, poiID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("POI %w: %s", ErrNotFound, poiID)
+		}
+		return nil, fmt.Errorf("failed to get POI: %w", err)
+	}
+
+	if !poi.IsScheduled() {
+		return nil, fmt.Errorf("invalid rsvp: POI is not a scheduled event")
+	}
+
+	return poi, nil
+}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/redis"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+type fakeRSVPStore struct {
+	rsvps         map[string]*models.POIRSVP // poiID:userID -> RSVP
+	pois          []*models.POI
+	reminderSent  map[string]time.Time
+	nextCreatedAt time.Time
+}
+
+func newFakeRSVPStore() *fakeRSVPStore {
+	return &fakeRSVPStore{
+		rsvps:         make(map[string]*models.POIRSVP),
+		reminderSent:  make(map[string]time.Time),
+		nextCreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func (s *fakeRSVPStore) UpsertRSVP(ctx context.Context, rsvp *models.POIRSVP) (*models.POIRSVP, error) {
+	key := rsvp.POIID + ":" + rsvp.UserID
+	if existing, exists := s.rsvps[key]; exists {
+		existing.Status = rsvp.Status
+		return existing, nil
+	}
+	s.nextCreatedAt = s.nextCreatedAt.Add(time.Second)
+	rsvp.CreatedAt = s.nextCreatedAt
+	s.rsvps[key] = rsvp
+	return rsvp, nil
+}
+
+func (s *fakeRSVPStore) UpsertGoingRSVP(ctx context.Context, rsvp *models.POIRSVP, seats int) (*models.POIRSVP, bool, error) {
+	taken := 0
+	for _, existing := range s.rsvps {
+		if existing.POIID == rsvp.POIID && existing.UserID != rsvp.UserID && existing.Status == models.RSVPGoing {
+			taken++
+		}
+	}
+	if taken >= seats {
+		return nil, false, nil
+	}
+	saved, err := s.UpsertRSVP(ctx, rsvp)
+	return saved, err == nil, err
+}
+
+type recordingReminderPublisher struct {
+	reminders []redis.POIReminderEvent
+}
+
+func (p *recordingReminderPublisher) PublishPOIReminder(ctx context.Context, event redis.POIReminderEvent) error {
+	p.reminders = append(p.reminders, event)
+	return nil
+}
+
+func (s *fakeRSVPStore) GetRSVP(ctx context.Context, poiID, userID string) (*models.POIRSVP, error) {
+	rsvp, exists := s.rsvps[poiID+":"+userID]
+	if !exists {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return rsvp, nil
+}
+
+func (s *fakeRSVPStore) DeleteRSVP(ctx context.Context, poiID, userID string) error {
+	if _, exists := s.rsvps[poiID+":"+userID]; !exists {
+		return gorm.ErrRecordNotFound
+	}
+	delete(s.rsvps, poiID+":"+userID)
+	return nil
+}
+
+func (s *fakeRSVPStore) MoveRSVPs(ctx context.Context, fromPOIID, toPOIID string) error {
+	for key, rsvp := range s.rsvps {
+		if rsvp.POIID != fromPOIID {
+			continue
+		}
+		delete(s.rsvps, key)
+		if _, answered := s.rsvps[toPOIID+":"+rsvp.UserID]; !answered {
+			rsvp.POIID = toPOIID
+			s.rsvps[toPOIID+":"+rsvp.UserID] = rsvp
+		}
+	}
+	return nil
+}
+
+func (s *fakeRSVPStore) ListUserIDs(ctx context.Context, poiID string, statuses ...models.RSVPStatus) ([]string, error) {
+	var matching []*models.POIRSVP
+	for _, rsvp := range s.rsvps {
+		for _, status := range statuses {
+			if rsvp.POIID == poiID && rsvp.Status == status {
+				matching = append(matching, rsvp)
+			}
+		}
+	}
+	sort.Slice(matching, func(i, j int) bool { return matching[i].CreatedAt.Before(matching[j].CreatedAt) })
+
+	userIDs := make([]string, len(matching))
+	for i, rsvp := range matching {
+		userIDs[i] = rsvp.UserID
+	}
+	return userIDs, nil
+}
+
+func (s *fakeRSVPStore) CountByPOI(ctx context.Context, poiIDs []string) (map[string]models.RSVPCounts, error) {
+	counts := make(map[string]models.RSVPCounts)
+	for _, poiID := range poiIDs {
+		for _, rsvp := range s.rsvps {
+			if rsvp.POIID == poiID {
+				c := counts[poiID]
+				c.Add(rsvp.Status, 1)
+				counts[poiID] = c
+			}
+		}
+	}
+	return counts, nil
+}
+
+func (s *fakeRSVPStore) ListPOIsStartingBetween(ctx context.Context, from, to time.Time) ([]*models.POI, error) {
+	var pois []*models.POI
+	for _, poi := range s.pois {
+		_, sent := s.reminderSent[poi.ID]
+		if poi.StartsAt != nil && poi.StartsAt.After(from) && !poi.StartsAt.After(to) && !sent {
+			pois = append(pois, poi)
+		}
+	}
+	return pois, nil
+}
+
+func (s *fakeRSVPStore) MarkReminderSent(ctx context.Context, poiID string, sentAt time.Time) error {
+	s.reminderSent[poiID] = sentAt
+	return nil
+}
+
+type fakeRSVPPOILookup struct {
+	pois map[string]*models.POI
+}
+
+func (l *fakeRSVPPOILookup) GetByID(ctx context.Context, id string) (*models.POI, error) {
+	poi, exists := l.pois[id]
+	if !exists {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return poi, nil
+}
+
+func newTestRSVPService(now *time.Time, pois ...*models.POI) (*RSVPService, *fakeRSVPStore) {
+	store := newFakeRSVPStore()
+	store.pois = pois
+	lookup := &fakeRSVPPOILookup{pois: make(map[string]*models.POI)}
+	for _, poi := range pois {
+		lookup.pois[poi.ID] = poi
+	}
+
+	service := NewRSVPService(store, lookup)
+	service.now = func() time.Time { return *now }
+	return service, store
+}
+
+func scheduledPOI(id string, startsAt time.Time, maxParticipants int) *models.POI {
+	return &models.POI{ID: id, MapID: "map-1", Name: "Keynote", MaxParticipants: maxParticipants, StartsAt: &startsAt}
+}
+
+func TestRSVPService_SetRSVP(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	service, _ := newTestRSVPService(&now,
+		scheduledPOI("poi-1", now.Add(time.Hour), 2),
+		&models.POI{ID: "poi-unscheduled", MaxParticipants: 10},
+	)
+
+	rsvp, err := service.SetRSVP(ctx, "poi-1", "user-1", models.RSVPGoing)
+	require.NoError(t, err)
+	assert.Equal(t, models.RSVPGoing, rsvp.Status)
+
+	_, err = service.SetRSVP(ctx, "poi-1", "user-2", models.RSVPMaybe)
+	require.NoError(t, err)
+	_, err = service.SetRSVP(ctx, "poi-1", "user-3", models.RSVPNo)
+	require.NoError(t, err)
+
+	counts, err := service.GetRSVPCounts(ctx, []string{"poi-1", "poi-unscheduled"})
+	require.NoError(t, err)
+	assert.Equal(t, models.RSVPCounts{Going: 1, Maybe: 1, No: 1}, counts["poi-1"])
+	assert.NotContains(t, counts, "poi-unscheduled")
+
+	// Changing an answer updates the existing RSVP
+	rsvp, err = service.SetRSVP(ctx, "poi-1", "user-1", models.RSVPMaybe)
+	require.NoError(t, err)
+	assert.Equal(t, models.RSVPMaybe, rsvp.Status)
+
+	_, err = service.SetRSVP(ctx, "poi-1", "user-1", "definitely")
+	assert.ErrorContains(t, err, "invalid rsvp")
+	_, err = service.SetRSVP(ctx, "poi-unscheduled", "user-1", models.RSVPGoing)
+	assert.ErrorContains(t, err, "invalid rsvp")
+	_, err = service.SetRSVP(ctx, "missing", "user-1", models.RSVPGoing)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	now = now.Add(time.Hour)
+	_, err = service.SetRSVP(ctx, "poi-1", "user-4", models.RSVPGoing)
+	assert.ErrorContains(t, err, "already started")
+}
+
+func TestRSVPService_SetRSVP_GoingLimitedToCapacity(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	service, _ := newTestRSVPService(&now, scheduledPOI("poi-1", now.Add(time.Hour), 2))
+
+	for _, userID := range []string{"user-1", "user-2"} {
+		_, err := service.SetRSVP(ctx, "poi-1", userID, models.RSVPGoing)
+		require.NoError(t, err)
+	}
+
+	_, err := service.SetRSVP(ctx, "poi-1", "user-3", models.RSVPGoing)
+	assert.ErrorIs(t, err, ErrCapacityExceeded)
+
+	// Users already going can confirm again, and others can still answer maybe
+	_, err = service.SetRSVP(ctx, "poi-1", "user-1", models.RSVPGoing)
+	assert.NoError(t, err)
+	_, err = service.SetRSVP(ctx, "poi-1", "user-3", models.RSVPMaybe)
+	assert.NoError(t, err)
+
+	// Cancelling frees the seat
+	require.NoError(t, service.CancelRSVP(ctx, "poi-1", "user-2"))
+	_, err = service.SetRSVP(ctx, "poi-1", "user-3", models.RSVPGoing)
+	assert.NoError(t, err)
+}
+
+func TestRSVPService_GetReservedUserIDs(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	poi := scheduledPOI("poi-1", now.Add(time.Hour), 10)
+	service, _ := newTestRSVPService(&now, poi)
+
+	_, err := service.SetRSVP(ctx, "poi-1", "user-1", models.RSVPGoing)
+	require.NoError(t, err)
+	_, err = service.SetRSVP(ctx, "poi-1", "user-2", models.RSVPMaybe)
+	require.NoError(t, err)
+
+	reserved, err := service.GetReservedUserIDs(ctx, poi)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user-1"}, reserved)
+
+	// Seats are released after the grace period
+	now = poi.StartsAt.Add(RSVPReservationGrace + time.Second)
+	reserved, err = service.GetReservedUserIDs(ctx, poi)
+	require.NoError(t, err)
+	assert.Empty(t, reserved)
+
+	reserved, err = service.GetReservedUserIDs(ctx, &models.POI{ID: "poi-unscheduled"})
+	require.NoError(t, err)
+	assert.Empty(t, reserved)
+}
+
+func TestRSVPService_SendDueReminders(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	soon := scheduledPOI("poi-soon", now.Add(10*time.Minute), 10)
+	later := scheduledPOI("poi-later", now.Add(time.Hour), 10)
+	empty := scheduledPOI("poi-empty", now.Add(5*time.Minute), 10)
+	service, store := newTestRSVPService(&now, soon, later, empty)
+
+	publisher := &recordingReminderPublisher{}
+	service.SetPublisher(publisher)
+
+	for userID, status := range map[string]models.RSVPStatus{
+		"user-1": models.RSVPGoing,
+		"user-2": models.RSVPMaybe,
+		"user-3": models.RSVPNo,
+	} {
+		_, err := service.SetRSVP(ctx, "poi-soon", userID, status)
+		require.NoError(t, err)
+		_, err = service.SetRSVP(ctx, "poi-later", userID, status)
+		require.NoError(t, err)
+	}
+
+	sent, err := service.SendDueReminders(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	require.Len(t, publisher.reminders, 1)
+	assert.Equal(t, "poi-soon", publisher.reminders[0].POIID)
+	assert.ElementsMatch(t, []string{"user-1", "user-2"}, publisher.reminders[0].UserIDs)
+	assert.Contains(t, store.reminderSent, "poi-empty")
+
+	// Reminders are only sent once
+	sent, err = service.SendDueReminders(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+
+	now = now.Add(50 * time.Minute)
+	sent, err = service.SendDueReminders(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Equal(t, "poi-later", publisher.reminders[1].POIID)
+}
+
+type fakeSeatReservations struct {
+	userIDs []string
+}
+
+func (r *fakeSeatReservations) GetReservedUserIDs(ctx context.Context, poi *models.POI) ([]string, error) {
+	return r.userIDs, nil
+}
+
+func TestPOIService_CheckReservedSeats(t *testing.T) {
+	ctx := context.Background()
+	startsAt := time.Now().Add(time.Hour)
+	poi := &models.POI{ID: "poi-1", MaxParticipants: 3, StartsAt: &startsAt}
+
+	participants := new(MockPOIParticipants)
+	participants.On("GetParticipants", mock.Anything, "poi-1").Return([]string{"user-1"}, nil)
+
+	service := NewPOIService(new(MockPOIRepository), participants, new(MockPubSub), new(MockUserService))
+	service.SetSeatReservations(&fakeSeatReservations{userIDs: []string{"user-1", "user-2", "user-3"}})
+
+	// user-1 already joined, so user-2 and user-3 hold the remaining seats
+	assert.ErrorContains(t, service.checkReservedSeats(ctx, poi, "walk-in"), "maximum capacity")
+	assert.NoError(t, service.checkReservedSeats(ctx, poi, "user-2"))
+
+	// Unscheduled POIs don't reserve seats
+	assert.NoError(t, service.checkReservedSeats(ctx, &models.POI{ID: "poi-2", MaxParticipants: 3}, "walk-in"))
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"breakoutglobe/internal/analytics"
 	"breakoutglobe/internal/models"
 	"breakoutglobe/internal/redis"
 
@@ -15,6 +16,7 @@ import (
 // SessionRepository defines the interface for session data access
 type SessionRepository interface {
 	Create(session *models.Session) error
+	CreateOrGetActive(session *models.Session) (*models.Session, bool, error)
 	GetByID(id string) (*models.Session, error)
 	GetByIDWithUser(id string) (*models.Session, error)
 	GetByUserAndMap(userID, mapID string) (*models.Session, error)
@@ -22,6 +24,7 @@ type SessionRepository interface {
 	UpdateAvatarPosition(sessionID string, position models.LatLng) error
 	Delete(id string) error
 	GetActiveByMap(mapID string) ([]*models.Session, error)
+	GetActiveMapIDsByUser(userID string) ([]string, error)
 	ExpireOldSessions(timeout time.Duration) error
 }
 
@@ -36,6 +39,9 @@ type SessionPresence interface {
 	GetActiveSessionsForMap(ctx context.Context, mapID string) ([]*redis.SessionPresenceData, error)
 	SetCurrentPOI(ctx context.Context, sessionID, poiID string) error
 	CleanupExpiredSessions(ctx context.Context, maxAge time.Duration) (int, error)
+	TouchPresence(ctx context.Context, sessionID string, ttl time.Duration) error
+	ClearPresence(ctx context.Context, sessionID string) error
+	FilterPresent(ctx context.Context, sessionIDs []string) ([]string, error)
 }
 
 // PubSub defines the interface for publishing real-time events
@@ -43,17 +49,38 @@ type PubSub interface {
 	PublishAvatarMovement(ctx context.Context, event redis.AvatarMovementEvent) error
 	PublishPOICreated(ctx context.Context, event redis.POICreatedEvent) error
 	PublishPOIUpdated(ctx context.Context, event redis.POIUpdatedEvent) error
+	PublishPOIDeleted(ctx context.Context, event redis.POIDeletedEvent) error
+	PublishPOIMerged(ctx context.Context, event redis.POIMergedEvent) error
 	PublishPOIJoined(ctx context.Context, event redis.POIJoinedEvent) error
 	PublishPOILeft(ctx context.Context, event redis.POILeftEvent) error
-	PublishPOIJoinedWithParticipants(ctx context.Context, event redis.POIJoinedEventWithParticipants) error
-	PublishPOILeftWithParticipants(ctx context.Context, event redis.POILeftEventWithParticipants) error
+	PublishPOIParticipantAdded(ctx context.Context, event redis.POIParticipantDeltaEvent) error
+	PublishPOIParticipantRemoved(ctx context.Context, event redis.POIParticipantDeltaEvent) error
+}
+
+// PositionRecorder defines the interface for recording avatar positions for analytics
+type PositionRecorder interface {
+	RecordPosition(ctx context.Context, mapID string, position models.LatLng) error
+}
+
+// AvatarPlacer defines the interface for placing new avatars on a map
+type AvatarPlacer interface {
+	PlaceAvatar(ctx context.Context, mapID string, requested models.LatLng, occupied []models.LatLng) models.LatLng
+}
+
+// ProductAnalytics defines the interface for reporting product events
+type ProductAnalytics interface {
+	Track(name, userID string, properties map[string]interface{})
 }
 
 // SessionService handles session management business logic
 type SessionService struct {
-	repo     SessionRepository
-	presence SessionPresence
-	pubsub   PubSub
+	repo       SessionRepository
+	presence   SessionPresence
+	pubsub     PubSub
+	recorder   PositionRecorder
+	placer     AvatarPlacer
+	analytics  ProductAnalytics
+	onboarding OnboardingTracker
 }
 
 // NewSessionService creates a new SessionService instance
@@ -65,49 +92,92 @@ func NewSessionService(repo SessionRepository, presence SessionPresence, pubsub
 	}
 }
 
-// CreateSession creates a new user session for a map
+// SetPositionRecorder sets the recorder avatar positions are reported to for the map heatmap
+func (s *SessionService) SetPositionRecorder(recorder PositionRecorder) {
+	s.recorder = recorder
+}
+
+// SetAvatarPlacer sets how new avatars are placed on their map. Without a
+// placer they are placed at the requested position.
+func (s *SessionService) SetAvatarPlacer(placer AvatarPlacer) {
+	s.placer = placer
+}
+
+// SetAnalytics sets where product events like joining a map are reported
+func (s *SessionService) SetAnalytics(analytics ProductAnalytics) {
+	s.analytics = analytics
+}
+
+// SetOnboarding sets the welcome checklist that moving an avatar completes a
+// step of
+func (s *SessionService) SetOnboarding(onboarding OnboardingTracker) {
+	s.onboarding = onboarding
+}
+
+// CreateSession creates a new user session for a map, or resumes the user's
+// active session in that map if there is one
 func (s *SessionService) CreateSession(ctx context.Context, userID, mapID string, position models.LatLng) (*models.Session, error) {
+	session, _, err := s.ResumeOrCreateSession(ctx, userID, mapID, position)
+	return session, err
+}
+
+// ResumeOrCreateSession returns the user's active session in the map, creating
+// one if none exists. Concurrent calls for the same user and map all resolve to
+// the same session. The returned bool is true if an existing session was resumed.
+func (s *SessionService) ResumeOrCreateSession(ctx context.Context, userID, mapID string, position models.LatLng) (*models.Session, bool, error) {
 	// Validate input
 	if userID == "" {
-		return nil, fmt.Errorf("user ID is required")
+		return nil, false, fmt.Errorf("user ID is required")
 	}
 	if mapID == "" {
-		return nil, fmt.Errorf("map ID is required")
+		return nil, false, fmt.Errorf("map ID is required")
 	}
 	if err := position.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid position: %w", err)
+		return nil, false, fmt.Errorf("invalid position: %w", err)
 	}
 
-	// Check if user already has an active session in this map
-	existingSession, err := s.repo.GetByUserAndMap(userID, mapID)
-	if err != nil && err != gorm.ErrRecordNotFound {
-		return nil, fmt.Errorf("failed to check existing session: %w", err)
-	}
-	if existingSession != nil && existingSession.IsActive {
-		return nil, fmt.Errorf("user already has an active session in this map")
+	// Place new avatars at a free spot; resumed sessions keep their position
+	if s.placer != nil {
+		position = s.placeAvatar(ctx, mapID, position)
 	}
 
-	// Create new session
-	session := &models.Session{
+	now := time.Now()
+	newSession := &models.Session{
 		ID:         uuid.New().String(),
 		UserID:     userID,
 		MapID:      mapID,
 		AvatarPos:  position,
 		IsActive:   true,
-		CreatedAt:  time.Now(),
-		LastActive: time.Now(),
+		CreatedAt:  now,
+		LastActive: now,
+	}
+
+	// The repository enforces one active session per user and map
+	session, created, err := s.repo.CreateOrGetActive(newSession)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create session: %w", err)
 	}
 
-	// Save to database
-	if err := s.repo.Create(session); err != nil {
-		return nil, fmt.Errorf("failed to create session: %w", err)
+	s.touchPresence(ctx, session.ID)
+
+	if !created {
+		// Resume the existing session where the user left it
+		session.UpdateActivity()
+		if err := s.repo.Update(session); err != nil {
+			return nil, false, fmt.Errorf("failed to resume session: %w", err)
+		}
+
+		if err := s.presence.SessionHeartbeat(ctx, session.ID, 30*time.Minute); err == nil {
+			return session, true, nil
+		}
+		// Presence expired, recreate it below
 	}
 
 	// Set presence in Redis
 	presenceData := redis.SessionPresenceData{
 		UserID:         userID,
 		MapID:          mapID,
-		AvatarPosition: position,
+		AvatarPosition: session.AvatarPos,
 		LastActive:     time.Now(),
 		CurrentPOI:     nil, // No POI initially
 	}
@@ -118,7 +188,11 @@ func (s *SessionService) CreateSession(ctx context.Context, userID, mapID string
 		fmt.Printf("Warning: failed to set session presence: %v\n", err)
 	}
 
-	return session, nil
+	if created && s.analytics != nil {
+		s.analytics.Track(analytics.EventMapJoined, userID, map[string]interface{}{"mapId": mapID})
+	}
+
+	return session, !created, nil
 }
 
 // GetSession retrieves a session by ID
@@ -130,7 +204,7 @@ func (s *SessionService) GetSession(ctx context.Context, sessionID string) (*mod
 	session, err := s.repo.GetByIDWithUser(sessionID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("session not found")
+			return nil, fmt.Errorf("session %w", ErrNotFound)
 		}
 		return nil, fmt.Errorf("failed to get session: %w", err)
 	}
@@ -152,7 +226,7 @@ func (s *SessionService) UpdateAvatarPosition(ctx context.Context, sessionID str
 	session, err := s.repo.GetByID(sessionID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return fmt.Errorf("session not found")
+			return fmt.Errorf("session %w", ErrNotFound)
 		}
 		return fmt.Errorf("failed to get session: %w", err)
 	}
@@ -186,6 +260,20 @@ func (s *SessionService) UpdateAvatarPosition(ctx context.Context, sessionID str
 		fmt.Printf("Warning: failed to publish avatar movement event: %v\n", err)
 	}
 
+	if s.recorder != nil {
+		if err := s.recorder.RecordPosition(ctx, session.MapID, position); err != nil {
+			// Log error but don't fail the update
+			fmt.Printf("Warning: failed to record avatar position for heatmap: %v\n", err)
+		}
+	}
+
+	if s.onboarding != nil {
+		if err := s.onboarding.CompleteStep(ctx, session.UserID, models.OnboardingFirstMove); err != nil {
+			// Log error but don't fail the update
+			fmt.Printf("Warning: failed to complete onboarding step: %v\n", err)
+		}
+	}
+
 	return nil
 }
 
@@ -199,7 +287,7 @@ func (s *SessionService) SessionHeartbeat(ctx context.Context, sessionID string)
 	session, err := s.repo.GetByID(sessionID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return fmt.Errorf("session not found")
+			return fmt.Errorf("session %w", ErrNotFound)
 		}
 		return fmt.Errorf("failed to get session: %w", err)
 	}
@@ -219,6 +307,7 @@ func (s *SessionService) SessionHeartbeat(ctx context.Context, sessionID string)
 		// Log error but don't fail the heartbeat
 		fmt.Printf("Warning: failed to update session heartbeat in presence: %v\n", err)
 	}
+	s.touchPresence(ctx, sessionID)
 
 	return nil
 }
@@ -233,7 +322,7 @@ func (s *SessionService) EndSession(ctx context.Context, sessionID string) error
 	session, err := s.repo.GetByID(sessionID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return fmt.Errorf("session not found")
+			return fmt.Errorf("session %w", ErrNotFound)
 		}
 		return fmt.Errorf("failed to get session: %w", err)
 	}
@@ -250,11 +339,15 @@ func (s *SessionService) EndSession(ctx context.Context, sessionID string) error
 		// Log error but don't fail the session end
 		fmt.Printf("Warning: failed to remove session presence: %v\n", err)
 	}
+	if err := s.presence.ClearPresence(ctx, sessionID); err != nil {
+		fmt.Printf("Warning: failed to clear session presence key: %v\n", err)
+	}
 
 	return nil
 }
 
-// GetActiveSessionsForMap retrieves all active sessions for a map
+// GetActiveSessionsForMap retrieves the active sessions for a map whose
+// presence key is still being refreshed by heartbeats
 func (s *SessionService) GetActiveSessionsForMap(ctx context.Context, mapID string) ([]*models.Session, error) {
 	if mapID == "" {
 		return nil, fmt.Errorf("map ID is required")
@@ -265,7 +358,42 @@ func (s *SessionService) GetActiveSessionsForMap(ctx context.Context, mapID stri
 		return nil, fmt.Errorf("failed to get active sessions: %w", err)
 	}
 
-	return sessions, nil
+	sessionIDs := make([]string, len(sessions))
+	for i, session := range sessions {
+		sessionIDs[i] = session.ID
+	}
+
+	present, err := s.presence.FilterPresent(ctx, sessionIDs)
+	if err != nil {
+		// Fall back to the database view if presence is unavailable
+		fmt.Printf("Warning: failed to check session presence: %v\n", err)
+		return sessions, nil
+	}
+
+	presentSet := make(map[string]bool, len(present))
+	for _, sessionID := range present {
+		presentSet[sessionID] = true
+	}
+
+	presentSessions := make([]*models.Session, 0, len(present))
+	for _, session := range sessions {
+		if presentSet[session.ID] {
+			presentSessions = append(presentSessions, session)
+		}
+	}
+
+	return presentSessions, nil
+}
+
+// FilterPresentSessions returns the sessions from sessionIDs that have sent a
+// heartbeat within the presence TTL
+func (s *SessionService) FilterPresentSessions(ctx context.Context, sessionIDs []string) ([]string, error) {
+	present, err := s.presence.FilterPresent(ctx, sessionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check session presence: %w", err)
+	}
+
+	return present, nil
 }
 
 // GetActiveSessionsFromPresence retrieves active sessions from Redis presence
@@ -308,7 +436,7 @@ func (s *SessionService) SetCurrentPOI(ctx context.Context, sessionID, poiID str
 	session, err := s.repo.GetByID(sessionID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return fmt.Errorf("session not found")
+			return fmt.Errorf("session %w", ErrNotFound)
 		}
 		return fmt.Errorf("failed to get session: %w", err)
 	}
@@ -360,4 +488,29 @@ func (s *SessionService) ValidateSession(ctx context.Context, sessionID string)
 	}
 
 	return session, nil
-}
\ No newline at end of file
+}
+
+// touchPresence refreshes the short-lived presence key of a session
+func (s *SessionService) touchPresence(ctx context.Context, sessionID string) {
+	if err := s.presence.TouchPresence(ctx, sessionID, redis.PresenceTTL); err != nil {
+		// Log error but don't fail the caller
+		fmt.Printf("Warning: failed to touch session presence: %v\n", err)
+	}
+}
+
+// placeAvatar asks the placer for a free spot near the requested position,
+// avoiding the avatars of the map's active sessions
+func (s *SessionService) placeAvatar(ctx context.Context, mapID string, requested models.LatLng) models.LatLng {
+	sessions, err := s.repo.GetActiveByMap(mapID)
+	if err != nil {
+		// Placing without collision checks beats failing the session
+		fmt.Printf("Warning: failed to get active sessions for avatar placement: %v\n", err)
+	}
+
+	occupied := make([]models.LatLng, 0, len(sessions))
+	for _, session := range sessions {
+		occupied = append(occupied, session.AvatarPos)
+	}
+
+	return s.placer.PlaceAvatar(ctx, mapID, requested, occupied)
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// SessionTokens issues and verifies the secret tokens that prove a client
+// owns a session. Session IDs are shared with everyone on the map, so they
+// identify a session but don't authenticate requests made in it. Tokens are
+// signed session IDs, so instances sharing the secret accept each other's.
+type SessionTokens struct {
+	secret []byte
+}
+
+// NewSessionTokens creates session tokens signed with the secret. Without a
+// secret, a random one is used and tokens are only valid in this process.
+func NewSessionTokens(secret []byte) *SessionTokens {
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			panic("failed to generate session token secret: " + err.Error())
+		}
+	}
+	return &SessionTokens{secret: secret}
+}
+
+// Issue returns the token of a session
+func (t *SessionTokens) Issue(sessionID string) string {
+	return base64.RawURLEncoding.EncodeToString(t.sign(sessionID))
+}
+
+// VerifySessionToken reports whether token is the token of the session
+func (t *SessionTokens) VerifySessionToken(sessionID, token string) bool {
+	if sessionID == "" || token == "" {
+		return false
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(token)
+	return err == nil && hmac.Equal(mac, t.sign(sessionID))
+}
+
+func (t *SessionTokens) sign(sessionID string) []byte {
+	mac := hmac.New(sha256.New, t.secret)
+	mac.Write([]byte("session:" + sessionID))
+	return mac.Sum(nil)
+}
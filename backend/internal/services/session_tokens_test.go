@@ -0,0 +1,22 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionTokens(t *testing.T) {
+	tokens := NewSessionTokens([]byte("secret"))
+	token := tokens.Issue("session-1")
+
+	assert.True(t, tokens.VerifySessionToken("session-1", token))
+	assert.False(t, tokens.VerifySessionToken("session-2", token))
+	assert.False(t, tokens.VerifySessionToken("session-1", ""))
+	assert.False(t, tokens.VerifySessionToken("session-1", "session-1"))
+	assert.False(t, NewSessionTokens([]byte("other")).VerifySessionToken("session-1", token))
+
+	// Instances sharing the secret accept each other's tokens
+	assert.True(t, NewSessionTokens([]byte("secret")).VerifySessionToken("session-1", token))
+	assert.False(t, NewSessionTokens(nil).VerifySessionToken("session-1", NewSessionTokens(nil).Issue("session-1")))
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net"
+	"sort"
+
+	"breakoutglobe/internal/geoip"
+	"breakoutglobe/internal/models"
+)
+
+// GeoIPProvider defines the interface for locating client IP addresses
+type GeoIPProvider interface {
+	Locate(ctx context.Context, ip net.IP) (models.LatLng, error)
+}
+
+// MapStore defines the interface for reading maps and their spawn configuration
+type MapStore interface {
+	GetByID(ctx context.Context, id string) (*models.Map, error)
+	UpdateSpawnPoints(ctx context.Context, id string, points []models.SpawnPoint) error
+}
+
+// DefaultAvatarSpacingMeters is the minimum distance kept between newly placed avatars
+const DefaultAvatarSpacingMeters = 5.0
+
+// spawnAttempts is the number of random spots tried per spawn area
+const spawnAttempts = 20
+
+// SpawnService picks the position new avatars appear at: a default position
+// when the client doesn't request one, and a free spot in the map's spawn
+// areas so avatars don't stack on top of each other
+type SpawnService struct {
+	maps    MapStore
+	geoip   GeoIPProvider
+	spacing float64
+	random  func() float64
+}
+
+// NewSpawnService creates a new SpawnService instance
+func NewSpawnService(maps MapStore) *SpawnService {
+	return &SpawnService{
+		maps:    maps,
+		spacing: DefaultAvatarSpacingMeters,
+		random:  rand.Float64,
+	}
+}
+
+// SetGeoIPProvider enables spawning avatars near the approximate location of their client IP
+func (s *SpawnService) SetGeoIPProvider(provider GeoIPProvider) {
+	s.geoip = provider
+}
+
+// DefaultSpawnPosition returns the spawn position for a new avatar on a map.
+// It uses the client IP location if a GeoIP provider is configured and falls
+// back to the center of the map bounds, or 0,0 for maps without bounds. The
+// position is always clamped to the map bounds.
+func (s *SpawnService) DefaultSpawnPosition(ctx context.Context, mapID, clientIP string) models.LatLng {
+	var bounds *models.Bounds
+	if s.maps != nil {
+		m, err := s.maps.GetByID(ctx, mapID)
+		if err != nil {
+			// Session creation reports unknown maps itself
+			fmt.Printf("Warning: failed to get map for spawn position: %v\n", err)
+		} else {
+			bounds = m.Bounds
+		}
+	}
+
+	position := models.LatLng{}
+	if bounds != nil {
+		position = bounds.Center()
+	}
+
+	if located, ok := s.locate(ctx, clientIP); ok {
+		position = located
+	}
+
+	if bounds != nil {
+		position = bounds.Clamp(position)
+	}
+
+	return position
+}
+
+// locate returns the approximate location of a client IP, if known
+func (s *SpawnService) locate(ctx context.Context, clientIP string) (models.LatLng, bool) {
+	if s.geoip == nil {
+		return models.LatLng{}, false
+	}
+
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return models.LatLng{}, false
+	}
+
+	location, err := s.geoip.Locate(ctx, ip)
+	if err != nil {
+		if !errors.Is(err, geoip.ErrLocationUnknown) {
+			fmt.Printf("Warning: failed to locate client IP: %v\n", err)
+		}
+		return models.LatLng{}, false
+	}
+
+	return location, true
+}
+
+// GetSpawnPoints returns the spawn points of a map
+func (s *SpawnService) GetSpawnPoints(ctx context.Context, mapID string) ([]models.SpawnPoint, error) {
+	m, err := s.maps.GetByID(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+	if m.SpawnPoints == nil {
+		return []models.SpawnPoint{}, nil
+	}
+	return m.SpawnPoints, nil
+}
+
+// SetSpawnPoints replaces the spawn points of a map. An empty list places new
+// avatars around their requested position instead.
+func (s *SpawnService) SetSpawnPoints(ctx context.Context, mapID string, points []models.SpawnPoint) error {
+	if err := models.ValidateSpawnPoints(points); err != nil {
+		return fmt.Errorf("invalid spawn points: %w", err)
+	}
+	return s.maps.UpdateSpawnPoints(ctx, mapID, points)
+}
+
+// PlaceAvatar returns the position a new avatar is placed at. Maps with spawn
+// points place it in the spawn area nearest to the requested position, other
+// maps around the requested position. Within an area the spot is jittered and
+// kept at least the avatar spacing away from the occupied positions; if the
+// area is full the least crowded spot tried is used.
+func (s *SpawnService) PlaceAvatar(ctx context.Context, mapID string, requested models.LatLng, occupied []models.LatLng) models.LatLng {
+	var bounds *models.Bounds
+	areas := []models.SpawnPoint{{Position: requested}}
+
+	if m, err := s.maps.GetByID(ctx, mapID); err != nil {
+		fmt.Printf("Warning: failed to get map for avatar placement: %v\n", err)
+	} else {
+		bounds = m.Bounds
+		if len(m.SpawnPoints) > 0 {
+			areas = make([]models.SpawnPoint, len(m.SpawnPoints))
+			copy(areas, m.SpawnPoints)
+			sort.SliceStable(areas, func(i, j int) bool {
+				return requested.DistanceTo(areas[i].Position) < requested.DistanceTo(areas[j].Position)
+			})
+		}
+	}
+
+	best := areas[0].Position
+	bestClearance := -1.0
+	for _, area := range areas {
+		for attempt := 0; attempt <= spawnAttempts; attempt++ {
+			candidate := area.Position
+			if attempt > 0 {
+				candidate = s.jitter(area.Position, area.Radius())
+			}
+			if bounds != nil {
+				candidate = bounds.Clamp(candidate)
+			}
+
+			clearance := s.clearance(candidate, occupied)
+			if clearance >= s.spacing {
+				return candidate
+			}
+			if clearance > bestClearance {
+				best, bestClearance = candidate, clearance
+			}
+		}
+	}
+
+	return best
+}
+
+// jitter returns a uniformly distributed random point within radiusMeters of center
+func (s *SpawnService) jitter(center models.LatLng, radiusMeters float64) models.LatLng {
+	distance := radiusMeters * math.Sqrt(s.random())
+	bearing := 2 * math.Pi * s.random()
+	return offsetPosition(center, distance, bearing)
+}
+
+// offsetPosition returns the point distance meters from center in the direction
+// of bearing, in radians clockwise from north
+func offsetPosition(center models.LatLng, distance, bearing float64) models.LatLng {
+	const metersPerDegree = 111320.0
+	lat := center.Lat + distance*math.Cos(bearing)/metersPerDegree
+	lng := center.Lng + distance*math.Sin(bearing)/(metersPerDegree*math.Max(math.Cos(center.Lat*math.Pi/180), 0.01))
+
+	// Keep the point on the globe
+	lat = math.Max(-90, math.Min(90, lat))
+	if lng > 180 {
+		lng -= 360
+	} else if lng < -180 {
+		lng += 360
+	}
+	return models.LatLng{Lat: lat, Lng: lng}
+}
+
+// clearance returns the distance in meters from a position to the nearest occupied one
+func (s *SpawnService) clearance(position models.LatLng, occupied []models.LatLng) float64 {
+	clearance := math.Inf(1)
+	for _, other := range occupied {
+		clearance = math.Min(clearance, position.DistanceTo(other)*1000)
+	}
+	return clearance
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"breakoutglobe/internal/geoip"
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeMapStore struct {
+	maps map[string]*models.Map
+}
+
+func (l *fakeMapStore) GetByID(ctx context.Context, id string) (*models.Map, error) {
+	m, exists := l.maps[id]
+	if !exists {
+		return nil, fmt.Errorf("map not found")
+	}
+	return m, nil
+}
+
+func (l *fakeMapStore) UpdateSpawnPoints(ctx context.Context, id string, points []models.SpawnPoint) error {
+	m, exists := l.maps[id]
+	if !exists {
+		return fmt.Errorf("map not found")
+	}
+	m.SpawnPoints = points
+	return nil
+}
+
+type fakeGeoIPProvider struct {
+	locations map[string]models.LatLng
+}
+
+func (p *fakeGeoIPProvider) Locate(ctx context.Context, ip net.IP) (models.LatLng, error) {
+	location, exists := p.locations[ip.String()]
+	if !exists {
+		return models.LatLng{}, geoip.ErrLocationUnknown
+	}
+	return location, nil
+}
+
+func newTestSpawnService() *SpawnService {
+	service := NewSpawnService(&fakeMapStore{maps: map[string]*models.Map{
+		"world":  {ID: "world"},
+		"europe": {ID: "europe", Bounds: &models.Bounds{North: 70, South: 35, East: 40, West: -10}},
+	}})
+	service.SetGeoIPProvider(&fakeGeoIPProvider{locations: map[string]models.LatLng{
+		"81.2.69.160": {Lat: 51.5142, Lng: -0.0931}, // London
+		"8.8.8.8":     {Lat: 37.751, Lng: -97.822},  // United States
+	}})
+	return service
+}
+
+func TestSpawnService_UsesClientLocation(t *testing.T) {
+	service := newTestSpawnService()
+
+	position := service.DefaultSpawnPosition(context.Background(), "world", "81.2.69.160")
+	assert.Equal(t, models.LatLng{Lat: 51.5142, Lng: -0.0931}, position)
+}
+
+func TestSpawnService_ClampsToMapBounds(t *testing.T) {
+	service := newTestSpawnService()
+
+	position := service.DefaultSpawnPosition(context.Background(), "europe", "8.8.8.8")
+	assert.Equal(t, models.LatLng{Lat: 37.751, Lng: -10}, position)
+}
+
+func TestSpawnService_FallsBack(t *testing.T) {
+	service := newTestSpawnService()
+	ctx := context.Background()
+
+	// Unknown locations spawn at the center of the map bounds
+	assert.Equal(t, models.LatLng{Lat: 52.5, Lng: 15}, service.DefaultSpawnPosition(ctx, "europe", "10.0.0.1"))
+	assert.Equal(t, models.LatLng{Lat: 52.5, Lng: 15}, service.DefaultSpawnPosition(ctx, "europe", "not-an-ip"))
+
+	// Maps without bounds keep the previous default
+	assert.Equal(t, models.LatLng{}, service.DefaultSpawnPosition(ctx, "world", "10.0.0.1"))
+
+	// Without a provider the client IP is ignored
+	service.SetGeoIPProvider(nil)
+	assert.Equal(t, models.LatLng{}, service.DefaultSpawnPosition(ctx, "world", "81.2.69.160"))
+}
+
+func TestSpawnService_PlaceAvatar_KeepsFreeRequestedPosition(t *testing.T) {
+	service := newTestSpawnService()
+
+	requested := models.LatLng{Lat: 48.1371, Lng: 11.5754}
+	occupied := []models.LatLng{{Lat: 48.2, Lng: 11.6}}
+	assert.Equal(t, requested, service.PlaceAvatar(context.Background(), "world", requested, occupied))
+}
+
+func TestSpawnService_PlaceAvatar_AvoidsOccupiedPositions(t *testing.T) {
+	service := newTestSpawnService()
+
+	requested := models.LatLng{Lat: 48.1371, Lng: 11.5754}
+	occupied := []models.LatLng{requested}
+	for i := 0; i < 10; i++ {
+		placed := service.PlaceAvatar(context.Background(), "world", requested, occupied)
+
+		assert.GreaterOrEqual(t, service.clearance(placed, occupied), DefaultAvatarSpacingMeters)
+		assert.LessOrEqual(t, requested.DistanceTo(placed)*1000, models.DefaultSpawnRadiusMeters+0.01)
+		occupied = append(occupied, placed)
+	}
+}
+
+func TestSpawnService_PlaceAvatar_UsesNearestSpawnPoint(t *testing.T) {
+	service := newTestSpawnService()
+	ctx := context.Background()
+
+	berlin := models.SpawnPoint{Position: models.LatLng{Lat: 52.52, Lng: 13.405}, RadiusMeters: 20}
+	madrid := models.SpawnPoint{Position: models.LatLng{Lat: 40.4168, Lng: -3.7038}, RadiusMeters: 20}
+	assert.NoError(t, service.SetSpawnPoints(ctx, "europe", []models.SpawnPoint{madrid, berlin}))
+
+	london := models.LatLng{Lat: 51.5142, Lng: -0.0931}
+	assert.Equal(t, berlin.Position, service.PlaceAvatar(ctx, "europe", london, nil))
+
+	// Once the nearest spawn area is full, the next one is used
+	full := []models.LatLng{berlin.Position}
+	for i := 0; i < 30; i++ {
+		full = append(full, service.jitter(berlin.Position, berlin.RadiusMeters))
+	}
+	service.spacing = 100
+	placed := service.PlaceAvatar(ctx, "europe", london, full)
+	assert.LessOrEqual(t, madrid.Position.DistanceTo(placed)*1000, madrid.RadiusMeters+0.01)
+}
+
+func TestSpawnService_SetSpawnPoints_Invalid(t *testing.T) {
+	service := newTestSpawnService()
+
+	err := service.SetSpawnPoints(context.Background(), "europe", []models.SpawnPoint{{Position: models.LatLng{Lat: 95}}})
+	assert.ErrorContains(t, err, "invalid spawn points")
+}
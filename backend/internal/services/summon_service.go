@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/redis"
+)
+
+const (
+	// summonSpacingMeters is the distance between neighbouring avatars placed
+	// around a POI, so summoned avatars don't stack on top of each other
+	summonSpacingMeters = 2.0
+
+	// summonMinRadiusMeters is the smallest ring summoned avatars are placed on
+	summonMinRadiusMeters = 5.0
+)
+
+// SummonSessions defines the interface for finding and moving the sessions of a map
+type SummonSessions interface {
+	GetActiveSessionsForMap(ctx context.Context, mapID string) ([]*models.Session, error)
+	UpdateAvatarPosition(ctx context.Context, sessionID string, position models.LatLng) error
+}
+
+// SummonPOIs defines the interface for moving users into a POI
+type SummonPOIs interface {
+	GetPOI(ctx context.Context, poiID string) (*models.POI, error)
+	GetCurrentPOI(ctx context.Context, userID string) (string, error)
+	JoinPOI(ctx context.Context, poiID, userID string) error
+	LeavePOI(ctx context.Context, poiID, userID string) error
+}
+
+// SummonPublisher defines the interface for telling all instances about summons
+type SummonPublisher interface {
+	PublishSummonedToPOI(ctx context.Context, event redis.SummonEvent) error
+}
+
+// SummonResult is the outcome of moving participants to a POI
+type SummonResult struct {
+	POIID string                `json:"poiId"`
+	Users []models.SummonedUser `json:"users"`
+}
+
+// SummonService lets facilitators move participants to a POI to run
+// structured breakouts: their avatars are placed around the POI, they leave
+// the POI they were in and join this one, and all instances are told.
+type SummonService struct {
+	sessions  SummonSessions
+	pois      SummonPOIs
+	publisher SummonPublisher
+	now       func() time.Time
+}
+
+// NewSummonService creates a new SummonService instance
+func NewSummonService(sessions SummonSessions, pois SummonPOIs, publisher SummonPublisher) *SummonService {
+	return &SummonService{
+		sessions:  sessions,
+		pois:      pois,
+		publisher: publisher,
+		now:       time.Now,
+	}
+}
+
+// Summon moves the users connected to a map to one of its POIs. Without
+// userIDs, everyone but the facilitator (summonedBy) is moved. Users who
+// can't join the POI, for example because it is full, are still moved; the
+// result tells which ones joined. Selected users not connected to the map are
+// listed with an error.
+func (s *SummonService) Summon(ctx context.Context, mapID, summonedBy, poiID string, userIDs []string) (*SummonResult, error) {
+	if mapID == "" || poiID == "" {
+		return nil, fmt.Errorf("%w: map ID and POI ID are required", ErrInvalidInput)
+	}
+
+	poi, err := s.pois.GetPOI(ctx, poiID)
+	if err != nil {
+		return nil, err
+	}
+	if poi.MapID != mapID {
+		return nil, fmt.Errorf("POI %w: %s", ErrNotFound, poiID)
+	}
+
+	sessions, err := s.sessions.GetActiveSessionsForMap(ctx, mapID)
+	if err != nil {
+		return nil, err
+	}
+	users := summonedUsers(sessions, summonedBy, userIDs)
+
+	connected := 0
+	for _, user := range users {
+		if len(user.SessionIDs) > 0 {
+			connected++
+		}
+	}
+
+	// Place the avatars evenly on a ring around the POI
+	result := &SummonResult{POIID: poiID, Users: make([]models.SummonedUser, 0, len(users))}
+	radius := math.Max(summonMinRadiusMeters, float64(connected)*summonSpacingMeters/(2*math.Pi))
+	placed := 0
+	for _, user := range users {
+		if len(user.SessionIDs) == 0 {
+			user.Error = "not connected to the map"
+			result.Users = append(result.Users, user)
+			continue
+		}
+
+		bearing := 2 * math.Pi * float64(placed) / float64(connected)
+		placed++
+		user.Position = offsetPosition(poi.Position, radius, bearing)
+		s.summonUser(ctx, poiID, &user)
+		result.Users = append(result.Users, user)
+	}
+
+	event := redis.SummonEvent{
+		MapID:      mapID,
+		POIID:      poiID,
+		SummonedBy: summonedBy,
+		Users:      result.Users,
+		Timestamp:  s.now(),
+	}
+	if err := s.publisher.PublishSummonedToPOI(ctx, event); err != nil {
+		return nil, fmt.Errorf("failed to publish summoned to POI event: %w", err)
+	}
+	return result, nil
+}
+
+// summonUser moves the avatars of a user's sessions and makes the user a
+// participant of the POI, recording why if that failed
+func (s *SummonService) summonUser(ctx context.Context, poiID string, user *models.SummonedUser) {
+	for _, sessionID := range user.SessionIDs {
+		if err := s.sessions.UpdateAvatarPosition(ctx, sessionID, user.Position); err != nil {
+			user.Error = fmt.Sprintf("failed to move avatar: %v", err)
+			return
+		}
+	}
+	user.Moved = true
+
+	current, err := s.pois.GetCurrentPOI(ctx, user.UserID)
+	if err != nil {
+		user.Error = fmt.Sprintf("failed to get current POI: %v", err)
+		return
+	}
+	if current == poiID {
+		user.Joined = true
+		return
+	}
+	if current != "" {
+		if err := s.pois.LeavePOI(ctx, current, user.UserID); err != nil {
+			user.Error = fmt.Sprintf("failed to leave POI %s: %v", current, err)
+			return
+		}
+	}
+
+	if err := s.pois.JoinPOI(ctx, poiID, user.UserID); err != nil && !errors.Is(err, ErrAlreadyJoined) {
+		user.Error = err.Error()
+		return
+	}
+	user.Joined = true
+}
+
+// summonedUsers groups the sessions of the users to summon by user, in a
+// stable order so avatars keep their places when summoned again
+func summonedUsers(sessions []*models.Session, summonedBy string, userIDs []string) []models.SummonedUser {
+	selected := make(map[string]bool, len(userIDs))
+	for _, userID := range userIDs {
+		selected[userID] = true
+	}
+
+	byUser := make(map[string]*models.SummonedUser)
+	for _, session := range sessions {
+		if len(selected) > 0 && !selected[session.UserID] {
+			continue
+		}
+		if len(selected) == 0 && session.UserID == summonedBy {
+			continue
+		}
+		user := byUser[session.UserID]
+		if user == nil {
+			user = &models.SummonedUser{UserID: session.UserID}
+			byUser[session.UserID] = user
+		}
+		user.SessionIDs = append(user.SessionIDs, session.ID)
+	}
+	for userID := range selected {
+		if byUser[userID] == nil {
+			byUser[userID] = &models.SummonedUser{UserID: userID}
+		}
+	}
+
+	users := make([]models.SummonedUser, 0, len(byUser))
+	for _, user := range byUser {
+		sort.Strings(user.SessionIDs)
+		users = append(users, *user)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].UserID < users[j].UserID
+	})
+	return users
+}
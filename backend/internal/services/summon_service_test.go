@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/redis"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSummonSessions struct {
+	sessions  []*models.Session
+	positions map[string]models.LatLng
+}
+
+func (s *fakeSummonSessions) GetActiveSessionsForMap(ctx context.Context, mapID string) ([]*models.Session, error) {
+	return s.sessions, nil
+}
+
+func (s *fakeSummonSessions) UpdateAvatarPosition(ctx context.Context, sessionID string, position models.LatLng) error {
+	s.positions[sessionID] = position
+	return nil
+}
+
+type fakeSummonPOIs struct {
+	pois     map[string]*models.POI
+	current  map[string]string
+	capacity int
+}
+
+func (p *fakeSummonPOIs) GetPOI(ctx context.Context, poiID string) (*models.POI, error) {
+	poi, ok := p.pois[poiID]
+	if !ok {
+		return nil, fmt.Errorf("POI %w: %s", ErrNotFound, poiID)
+	}
+	return poi, nil
+}
+
+func (p *fakeSummonPOIs) GetCurrentPOI(ctx context.Context, userID string) (string, error) {
+	return p.current[userID], nil
+}
+
+func (p *fakeSummonPOIs) JoinPOI(ctx context.Context, poiID, userID string) error {
+	joined := 0
+	for _, current := range p.current {
+		if current == poiID {
+			joined++
+		}
+	}
+	if joined >= p.capacity {
+		return fmt.Errorf("%w: POI is at maximum capacity (%d participants)", ErrCapacityExceeded, p.capacity)
+	}
+	p.current[userID] = poiID
+	return nil
+}
+
+func (p *fakeSummonPOIs) LeavePOI(ctx context.Context, poiID, userID string) error {
+	delete(p.current, userID)
+	return nil
+}
+
+type recordingSummonPublisher struct {
+	events []redis.SummonEvent
+}
+
+func (p *recordingSummonPublisher) PublishSummonedToPOI(ctx context.Context, event redis.SummonEvent) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func newSummonTestService(capacity int) (*SummonService, *fakeSummonSessions, *fakeSummonPOIs, *recordingSummonPublisher) {
+	sessions := &fakeSummonSessions{
+		sessions: []*models.Session{
+			{ID: "session-1", UserID: "facilitator", MapID: "map-1"},
+			{ID: "session-2", UserID: "user-2", MapID: "map-1"},
+			{ID: "session-3", UserID: "user-3", MapID: "map-1"},
+			{ID: "session-4", UserID: "user-3", MapID: "map-1"},
+		},
+		positions: make(map[string]models.LatLng),
+	}
+	pois := &fakeSummonPOIs{
+		pois: map[string]*models.POI{
+			"poi-1": {ID: "poi-1", MapID: "map-1", Position: models.LatLng{Lat: 52.52, Lng: 13.405}},
+			"poi-2": {ID: "poi-2", MapID: "map-2"},
+		},
+		current:  map[string]string{"user-3": "poi-9"},
+		capacity: capacity,
+	}
+	publisher := &recordingSummonPublisher{}
+	return NewSummonService(sessions, pois, publisher), sessions, pois, publisher
+}
+
+func TestSummonService_Summon(t *testing.T) {
+	service, sessions, pois, publisher := newSummonTestService(10)
+	ctx := context.Background()
+
+	result, err := service.Summon(ctx, "map-1", "facilitator", "poi-1", nil)
+	require.NoError(t, err)
+	require.Len(t, result.Users, 2)
+	assert.Equal(t, "user-2", result.Users[0].UserID)
+	assert.Equal(t, []string{"session-3", "session-4"}, result.Users[1].SessionIDs)
+	for _, user := range result.Users {
+		assert.True(t, user.Moved)
+		assert.True(t, user.Joined)
+		assert.Empty(t, user.Error)
+		assert.Equal(t, "poi-1", pois.current[user.UserID])
+		// Placed around the POI rather than on top of each other
+		distance := user.Position.DistanceTo(models.LatLng{Lat: 52.52, Lng: 13.405}) * 1000
+		assert.InDelta(t, summonMinRadiusMeters, distance, 0.5)
+	}
+	assert.NotEqual(t, result.Users[0].Position, result.Users[1].Position)
+
+	// Every tab of a user is moved, the facilitator stays
+	assert.Equal(t, result.Users[1].Position, sessions.positions["session-3"])
+	assert.Equal(t, result.Users[1].Position, sessions.positions["session-4"])
+	assert.NotContains(t, sessions.positions, "session-1")
+	assert.Empty(t, pois.current["facilitator"])
+
+	require.Len(t, publisher.events, 1)
+	assert.Equal(t, "poi-1", publisher.events[0].POIID)
+	assert.Equal(t, "facilitator", publisher.events[0].SummonedBy)
+	assert.Equal(t, result.Users, publisher.events[0].Users)
+}
+
+func TestSummonService_Summon_SelectedUsers(t *testing.T) {
+	service, sessions, pois, _ := newSummonTestService(1)
+	ctx := context.Background()
+
+	result, err := service.Summon(ctx, "map-1", "facilitator", "poi-1", []string{"facilitator", "user-3", "user-9"})
+	require.NoError(t, err)
+	require.Len(t, result.Users, 3)
+	byUser := make(map[string]models.SummonedUser)
+	for _, user := range result.Users {
+		byUser[user.UserID] = user
+	}
+
+	assert.True(t, byUser["facilitator"].Joined)
+	// The POI is full, so user-3 is moved but stays out of it
+	assert.True(t, byUser["user-3"].Moved)
+	assert.False(t, byUser["user-3"].Joined)
+	assert.Contains(t, byUser["user-3"].Error, "capacity")
+	assert.Empty(t, pois.current["user-3"])
+	assert.Contains(t, sessions.positions, "session-4")
+	assert.False(t, byUser["user-9"].Moved)
+	assert.Equal(t, "not connected to the map", byUser["user-9"].Error)
+	assert.NotContains(t, sessions.positions, "session-2")
+}
+
+func TestSummonService_Summon_POIOfOtherMap(t *testing.T) {
+	service, _, _, publisher := newSummonTestService(10)
+	ctx := context.Background()
+
+	_, err := service.Summon(ctx, "map-1", "facilitator", "poi-2", nil)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = service.Summon(ctx, "map-1", "facilitator", "missing", nil)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = service.Summon(ctx, "map-1", "facilitator", "", nil)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+	assert.Empty(t, publisher.events)
+}
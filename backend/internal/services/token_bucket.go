@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenBucketIdleTimeout is how long an unused bucket is kept. Buckets refill
+// well within this time, so dropping them doesn't change the outcome.
+const tokenBucketIdleTimeout = 10 * time.Minute
+
+// TokenBucketLimiter limits actions with a token bucket per user, map and action.
+// Unlike the sliding window limits it lets clients spend a burst of requests at
+// once (e.g. while dragging an avatar) and only enforces the sustained rate over time.
+//
+// Buckets are kept in memory: they are consumed by the WebSocket connection of a
+// user, which lives on a single instance.
+type TokenBucketLimiter struct {
+	mutex     sync.Mutex
+	limits    map[ActionType]RateLimit
+	mapLimits map[string]map[ActionType]RateLimit // mapID -> action -> limit
+	buckets   map[string]*tokenBucket
+	threshold float64
+	now       func() time.Time
+
+	lastCleanup time.Time
+}
+
+type tokenBucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+// NewTokenBucketLimiter creates a token bucket limiter. Only limits with a burst
+// and sustained rate are applied; other actions are left to the sliding window limiter.
+func NewTokenBucketLimiter(limits map[ActionType]RateLimit) *TokenBucketLimiter {
+	return &TokenBucketLimiter{
+		limits:    limits,
+		mapLimits: make(map[string]map[ActionType]RateLimit),
+		buckets:   make(map[string]*tokenBucket),
+		threshold: DefaultRateLimitWarningThreshold,
+		now:       time.Now,
+	}
+}
+
+// GetDefaultBurstLimits returns the default token bucket limits
+func GetDefaultBurstLimits() map[ActionType]RateLimit {
+	return map[ActionType]RateLimit{
+		// Dragging sends several updates per second; allow short drags at full speed
+		// while keeping the sustained rate at the sliding window's 1 per second
+		ActionUpdateAvatar: {Burst: 30, SustainedRate: 1},
+	}
+}
+
+// ParseMapBurstLimit parses a per-map token bucket limit in the form
+// mapID:burst:sustainedRate, e.g. "workshop-map:60:2"
+func ParseMapBurstLimit(value string) (string, RateLimit, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 || parts[0] == "" {
+		return "", RateLimit{}, fmt.Errorf("invalid burst limit %q: expected mapID:burst:sustainedRate", value)
+	}
+
+	burst, err := strconv.Atoi(parts[1])
+	if err != nil || burst <= 0 {
+		return "", RateLimit{}, fmt.Errorf("invalid burst in %q: must be a positive integer", value)
+	}
+
+	rate, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil || rate <= 0 {
+		return "", RateLimit{}, fmt.Errorf("invalid sustained rate in %q: must be a positive number", value)
+	}
+
+	return parts[0], RateLimit{Burst: burst, SustainedRate: rate}, nil
+}
+
+// SetMapLimit overrides the token bucket limit of an action for a map
+func (l *TokenBucketLimiter) SetMapLimit(mapID string, action ActionType, limit RateLimit) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.mapLimits[mapID] == nil {
+		l.mapLimits[mapID] = make(map[ActionType]RateLimit)
+	}
+	l.mapLimits[mapID][action] = limit
+
+	// Existing buckets pick up the new limit on their next refill
+}
+
+// HasBurstLimit reports whether an action is limited by a token bucket on a map
+func (l *TokenBucketLimiter) HasBurstLimit(mapID string, action ActionType) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	_, ok := l.getLimit(mapID, action)
+	return ok
+}
+
+// CheckBurstLimit takes a token for the action. It returns a RateLimitError if
+// the bucket is empty, and a warning once the bucket is nearly drained.
+func (l *TokenBucketLimiter) CheckBurstLimit(ctx context.Context, userID, mapID string, action ActionType) (*RateLimitWarning, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	limit, ok := l.getLimit(mapID, action)
+	if !ok {
+		return nil, fmt.Errorf("no burst limit configured for action %s", action)
+	}
+
+	now := l.now()
+	if now.Sub(l.lastCleanup) > tokenBucketIdleTimeout {
+		l.cleanup(now)
+	}
+
+	key := fmt.Sprintf("%s:%s:%s", mapID, userID, action)
+	bucket, exists := l.buckets[key]
+	if !exists {
+		bucket = &tokenBucket{tokens: float64(limit.Burst), lastRefill: now}
+		l.buckets[key] = bucket
+	}
+
+	// Refill for the time passed since the last request, up to the burst size
+	elapsed := now.Sub(bucket.lastRefill).Seconds()
+	bucket.tokens = math.Min(float64(limit.Burst), bucket.tokens+elapsed*limit.SustainedRate)
+	bucket.lastRefill = now
+
+	if bucket.tokens < 1 {
+		retryAfter := time.Duration((1 - bucket.tokens) / limit.SustainedRate * float64(time.Second))
+		return nil, &RateLimitError{
+			UserID:     userID,
+			Action:     action,
+			Limit:      limit.Burst,
+			Window:     limit.BurstWindow(),
+			ResetTime:  now.Add(retryAfter),
+			RetryAfter: retryAfter,
+		}
+	}
+
+	bucket.tokens--
+
+	remaining := int(bucket.tokens)
+	if !IsNearRateLimit(limit.Burst-remaining, limit.Burst, l.threshold) {
+		return nil, nil
+	}
+
+	// Time until the bucket is full again
+	refill := time.Duration((float64(limit.Burst) - bucket.tokens) / limit.SustainedRate * float64(time.Second))
+	return &RateLimitWarning{
+		UserID:    userID,
+		Action:    action,
+		Limit:     limit.Burst,
+		Remaining: remaining,
+		ResetTime: now.Add(refill),
+	}, nil
+}
+
+// cleanup removes buckets that have not been used for tokenBucketIdleTimeout
+func (l *TokenBucketLimiter) cleanup(now time.Time) {
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.lastRefill) > tokenBucketIdleTimeout {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastCleanup = now
+}
+
+// getLimit returns the token bucket limit of an action, preferring the map's override
+func (l *TokenBucketLimiter) getLimit(mapID string, action ActionType) (RateLimit, bool) {
+	if mapLimits, exists := l.mapLimits[mapID]; exists {
+		if limit, exists := mapLimits[action]; exists {
+			return limit, limit.IsTokenBucket()
+		}
+	}
+
+	limit, exists := l.limits[action]
+	return limit, exists && limit.IsTokenBucket()
+}
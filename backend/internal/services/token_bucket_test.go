@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTokenBucketLimiter(now *time.Time) *TokenBucketLimiter {
+	limiter := NewTokenBucketLimiter(map[ActionType]RateLimit{
+		ActionUpdateAvatar: {Burst: 10, SustainedRate: 2},
+		ActionCreatePOI:    {Requests: 5, Window: time.Minute},
+	})
+	limiter.now = func() time.Time { return *now }
+	return limiter
+}
+
+func TestTokenBucketLimiter_AllowsBurst(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	limiter := newTestTokenBucketLimiter(&now)
+
+	for i := 0; i < 10; i++ {
+		_, err := limiter.CheckBurstLimit(ctx, "user-1", "map-1", ActionUpdateAvatar)
+		require.NoError(t, err, "request %d should be within the burst", i+1)
+	}
+
+	_, err := limiter.CheckBurstLimit(ctx, "user-1", "map-1", ActionUpdateAvatar)
+	var rateLimitErr *RateLimitError
+	require.ErrorAs(t, err, &rateLimitErr)
+	assert.Equal(t, 500*time.Millisecond, rateLimitErr.RetryAfter)
+}
+
+func TestTokenBucketLimiter_RefillsAtSustainedRate(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	limiter := newTestTokenBucketLimiter(&now)
+
+	for i := 0; i < 10; i++ {
+		_, err := limiter.CheckBurstLimit(ctx, "user-1", "map-1", ActionUpdateAvatar)
+		require.NoError(t, err)
+	}
+
+	// Two tokens per second
+	now = now.Add(time.Second)
+	for i := 0; i < 2; i++ {
+		_, err := limiter.CheckBurstLimit(ctx, "user-1", "map-1", ActionUpdateAvatar)
+		require.NoError(t, err)
+	}
+	_, err := limiter.CheckBurstLimit(ctx, "user-1", "map-1", ActionUpdateAvatar)
+	assert.Error(t, err)
+
+	// Other users have their own bucket
+	_, err = limiter.CheckBurstLimit(ctx, "user-2", "map-1", ActionUpdateAvatar)
+	assert.NoError(t, err)
+}
+
+func TestTokenBucketLimiter_WarnsWhenNearlyDrained(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	limiter := newTestTokenBucketLimiter(&now)
+
+	for i := 0; i < 7; i++ {
+		warning, err := limiter.CheckBurstLimit(ctx, "user-1", "map-1", ActionUpdateAvatar)
+		require.NoError(t, err)
+		assert.Nil(t, warning)
+	}
+
+	warning, err := limiter.CheckBurstLimit(ctx, "user-1", "map-1", ActionUpdateAvatar)
+	require.NoError(t, err)
+	require.NotNil(t, warning)
+	assert.Equal(t, 10, warning.Limit)
+	assert.Equal(t, 2, warning.Remaining)
+}
+
+func TestTokenBucketLimiter_MapLimits(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	limiter := newTestTokenBucketLimiter(&now)
+	limiter.SetMapLimit("workshop", ActionUpdateAvatar, RateLimit{Burst: 2, SustainedRate: 1})
+
+	assert.True(t, limiter.HasBurstLimit("workshop", ActionUpdateAvatar))
+	assert.True(t, limiter.HasBurstLimit("other", ActionUpdateAvatar))
+	assert.False(t, limiter.HasBurstLimit("other", ActionCreatePOI), "sliding window limits are not token buckets")
+
+	for i := 0; i < 2; i++ {
+		_, err := limiter.CheckBurstLimit(ctx, "user-1", "workshop", ActionUpdateAvatar)
+		require.NoError(t, err)
+	}
+	_, err := limiter.CheckBurstLimit(ctx, "user-1", "workshop", ActionUpdateAvatar)
+	assert.Error(t, err)
+
+	// The same user on another map still has the default burst
+	_, err = limiter.CheckBurstLimit(ctx, "user-1", "other", ActionUpdateAvatar)
+	assert.NoError(t, err)
+}
+
+func TestParseMapBurstLimit(t *testing.T) {
+	mapID, limit, err := ParseMapBurstLimit("workshop-map:60:2.5")
+	require.NoError(t, err)
+	assert.Equal(t, "workshop-map", mapID)
+	assert.Equal(t, RateLimit{Burst: 60, SustainedRate: 2.5}, limit)
+	assert.Equal(t, 24*time.Second, limit.BurstWindow())
+
+	for _, value := range []string{"", "workshop-map", ":60:2", "map:0:2", "map:60:x", "map:60:-1"} {
+		_, _, err := ParseMapBurstLimit(value)
+		assert.Error(t, err, value)
+	}
+}
@@ -13,6 +13,11 @@ import (
 	"github.com/google/uuid"
 )
 
+// poiImageOwner owns the POI images in storages that track who uploaded a
+// file. Uploads don't know their POI, so POI images share an owner and only
+// release references among each other.
+const poiImageOwner = "poi-images"
+
 // ImageUploader handles POI image uploads
 type ImageUploader struct {
 	storage storage.FileStorage
@@ -60,7 +65,12 @@ func (u *ImageUploader) UploadPOIImage(ctx context.Context, imageFile *multipart
 	}
 
 	// Upload using storage system
-	imageURL, err := u.storage.UploadFile(ctx, filename, fileData, contentType)
+	var imageURL string
+	if owned, ok := u.storage.(storage.OwnedFileStorage); ok {
+		imageURL, err = owned.UploadOwnedFile(ctx, poiImageOwner, filename, fileData, contentType)
+	} else {
+		imageURL, err = u.storage.UploadFile(ctx, filename, fileData, contentType)
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to upload POI image: %w", err)
 	}
@@ -68,6 +78,28 @@ func (u *ImageUploader) UploadPOIImage(ctx context.Context, imageFile *multipart
 	return imageURL, nil
 }
 
+// DeletePOIImage removes a previously uploaded POI image by its URL
+func (u *ImageUploader) DeletePOIImage(ctx context.Context, imageURL string) error {
+	// Only files served from our uploads path can be deleted
+	idx := strings.Index(imageURL, "/uploads/")
+	if idx == -1 {
+		return nil
+	}
+
+	key := imageURL[idx+len("/uploads/"):]
+	var err error
+	if owned, ok := u.storage.(storage.OwnedFileStorage); ok {
+		err = owned.DeleteOwnedFile(ctx, poiImageOwner, key)
+	} else {
+		err = u.storage.DeleteFile(ctx, key)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete POI image: %w", err)
+	}
+
+	return nil
+}
+
 // isValidImageType checks if the content type is a valid image type
 func isValidImageType(contentType string) bool {
 	validTypes := []string{
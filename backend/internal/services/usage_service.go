@@ -0,0 +1,324 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"breakoutglobe/internal/models"
+)
+
+// MaxCallDuration is how long a call is metered at most. Calls that end
+// without a call_end, e.g. when both clients disconnect, stop counting then.
+const MaxCallDuration = 12 * time.Hour
+
+// MaxUsageReportRange is the longest time range usage can be reported for
+const MaxUsageReportRange = 366 * 24 * time.Hour
+
+// UsageThresholdEvent is the webhook event sent when a map's usage in the
+// current calendar month crosses a threshold
+const UsageThresholdEvent = "usage.threshold_crossed"
+
+// UsageRepository defines the interface for rolled up map usage storage
+type UsageRepository interface {
+	AddUsage(ctx context.Context, usage []*models.MapUsage) error
+	GetTotals(ctx context.Context, mapID string, from, to time.Time) ([]*models.MapUsage, error)
+}
+
+// UsageWebhook defines the interface for notifying operators about usage
+type UsageWebhook interface {
+	Post(ctx context.Context, event string, data interface{}) error
+}
+
+// StorageUsageRecorder defines the interface for metering files uploaded to a map
+type StorageUsageRecorder interface {
+	RecordStorageBytes(mapID string, bytes int64)
+}
+
+// UsageThresholds are the usage per map and calendar month above which the
+// webhook is notified. Zero thresholds are not checked.
+type UsageThresholds struct {
+	WebSocketBytes int64
+	StorageBytes   int64
+	CallMinutes    int64
+}
+
+// limits returns the configured thresholds by metric
+func (t UsageThresholds) limits() map[models.UsageMetric]int64 {
+	limits := map[models.UsageMetric]int64{}
+	if t.WebSocketBytes > 0 {
+		limits[models.UsageWebSocketBytes] = t.WebSocketBytes
+	}
+	if t.StorageBytes > 0 {
+		limits[models.UsageStorageBytes] = t.StorageBytes
+	}
+	if t.CallMinutes > 0 {
+		limits[models.UsageCallMinutes] = t.CallMinutes
+	}
+	return limits
+}
+
+// UsageAlert is the data of a UsageThresholdEvent
+type UsageAlert struct {
+	MapID       string             `json:"mapId"`
+	Metric      models.UsageMetric `json:"metric"`
+	Threshold   int64              `json:"threshold"`
+	Total       int64              `json:"total"`
+	PeriodStart time.Time          `json:"periodStart"`
+}
+
+// MapUsageTotal is the usage of one map over a report's time range
+type MapUsageTotal struct {
+	MapID          string `json:"mapId"`
+	WebSocketBytes int64  `json:"webSocketBytes"`
+	StorageBytes   int64  `json:"storageBytes"`
+	CallMinutes    int64  `json:"callMinutes"`
+}
+
+// UsageReport is the usage of maps over a time range
+type UsageReport struct {
+	From time.Time       `json:"from"`
+	To   time.Time       `json:"to"`
+	Maps []MapUsageTotal `json:"maps"`
+}
+
+// usageKey identifies the pending usage of a map in a bucket
+type usageKey struct {
+	mapID       string
+	bucketStart time.Time
+}
+
+// meteredCall is a call whose time is added to its map's usage
+type meteredCall struct {
+	mapID     string
+	startedAt time.Time
+	// meteredUntil is how far the call's time was added to pending usage
+	meteredUntil time.Time
+}
+
+// UsageService meters WebSocket traffic, uploads and call time per map for
+// quotas and billing of hosted deployments. Usage is counted in memory by each
+// instance and added to the database by the scheduler.
+type UsageService struct {
+	repo       UsageRepository
+	webhook    UsageWebhook
+	thresholds UsageThresholds
+	now        func() time.Time
+
+	mutex   sync.Mutex
+	pending map[usageKey]*models.MapUsage
+	calls   map[string]*meteredCall
+}
+
+// NewUsageService creates a new UsageService instance
+func NewUsageService(repo UsageRepository) *UsageService {
+	return &UsageService{
+		repo:    repo,
+		now:     time.Now,
+		pending: make(map[usageKey]*models.MapUsage),
+		calls:   make(map[string]*meteredCall),
+	}
+}
+
+// SetWebhook notifies webhook once a map's usage in the current calendar month
+// crosses one of the thresholds. Without one, usage is only reported.
+func (s *UsageService) SetWebhook(webhook UsageWebhook, thresholds UsageThresholds) {
+	s.webhook = webhook
+	s.thresholds = thresholds
+}
+
+// RecordWebSocketBytes meters a message sent to a client of a map
+func (s *UsageService) RecordWebSocketBytes(mapID string, bytes int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.pendingUsage(mapID, s.now()).WebSocketBytes += int64(bytes)
+}
+
+// RecordStorageBytes meters a file uploaded to a map
+func (s *UsageService) RecordStorageBytes(mapID string, bytes int64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.pendingUsage(mapID, s.now()).StorageBytes += bytes
+}
+
+// StartCall starts metering the time of a call on a map
+func (s *UsageService) StartCall(mapID, callID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.calls[callID]; exists {
+		return
+	}
+	now := s.now()
+	s.calls[callID] = &meteredCall{mapID: mapID, startedAt: now, meteredUntil: now}
+}
+
+// EndCall meters the rest of a call's time. Unknown calls are ignored.
+func (s *UsageService) EndCall(callID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	call, exists := s.calls[callID]
+	if !exists {
+		return
+	}
+	s.meterCall(call, s.now())
+	delete(s.calls, callID)
+}
+
+// pendingUsage returns the pending usage of a map in the bucket of t. The
+// caller must hold the mutex.
+func (s *UsageService) pendingUsage(mapID string, t time.Time) *models.MapUsage {
+	key := usageKey{mapID: mapID, bucketStart: models.UsageBucketFor(t)}
+	usage, exists := s.pending[key]
+	if !exists {
+		usage = &models.MapUsage{MapID: key.mapID, BucketStart: key.bucketStart}
+		s.pending[key] = usage
+	}
+	return usage
+}
+
+// meterCall adds a call's time up to now, capped at MaxCallDuration, to the
+// current bucket. The caller must hold the mutex.
+func (s *UsageService) meterCall(call *meteredCall, now time.Time) {
+	if end := call.startedAt.Add(MaxCallDuration); now.After(end) {
+		now = end
+	}
+	if !now.After(call.meteredUntil) {
+		return
+	}
+	s.pendingUsage(call.mapID, now).CallSeconds += int64(now.Sub(call.meteredUntil).Seconds())
+	call.meteredUntil = now
+}
+
+// Flush adds the usage metered since the last flush to the database and
+// notifies the webhook of crossed thresholds. It returns the number of map
+// buckets written. Usage that fails to be written is kept for the next flush.
+func (s *UsageService) Flush(ctx context.Context) (int, error) {
+	s.mutex.Lock()
+	now := s.now()
+	for callID, call := range s.calls {
+		s.meterCall(call, now)
+		if !now.Before(call.startedAt.Add(MaxCallDuration)) {
+			delete(s.calls, callID)
+		}
+	}
+	flushed := make([]*models.MapUsage, 0, len(s.pending))
+	for _, usage := range s.pending {
+		flushed = append(flushed, usage)
+	}
+	s.pending = make(map[usageKey]*models.MapUsage)
+	s.mutex.Unlock()
+
+	if len(flushed) == 0 {
+		return 0, nil
+	}
+	if err := s.repo.AddUsage(ctx, flushed); err != nil {
+		s.restore(flushed)
+		return 0, err
+	}
+
+	s.checkThresholds(ctx, flushed)
+	return len(flushed), nil
+}
+
+// restore puts usage that failed to be written back into pending usage
+func (s *UsageService) restore(usage []*models.MapUsage) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, u := range usage {
+		s.pendingUsage(u.MapID, u.BucketStart).Add(u)
+	}
+}
+
+// checkThresholds notifies the webhook of every threshold that the flushed
+// usage pushed a map's monthly total across. Every instance adds its own
+// usage, so only the flush that crosses a threshold notifies.
+func (s *UsageService) checkThresholds(ctx context.Context, flushed []*models.MapUsage) {
+	limits := s.thresholds.limits()
+	if s.webhook == nil || len(limits) == 0 {
+		return
+	}
+
+	added := make(map[usageKey]*models.MapUsage)
+	for _, usage := range flushed {
+		key := usageKey{mapID: usage.MapID, bucketStart: models.UsagePeriodFor(usage.BucketStart)}
+		if added[key] == nil {
+			added[key] = &models.MapUsage{MapID: usage.MapID}
+		}
+		added[key].Add(usage)
+	}
+
+	for key, increase := range added {
+		totals, err := s.repo.GetTotals(ctx, key.mapID, key.bucketStart, key.bucketStart.AddDate(0, 1, 0))
+		if err != nil {
+			log.Printf("Warning: failed to get usage of map %s for thresholds: %v", key.mapID, err)
+			continue
+		}
+		if len(totals) == 0 {
+			continue
+		}
+
+		after := totals[0]
+		before := *after
+		before.WebSocketBytes -= increase.WebSocketBytes
+		before.StorageBytes -= increase.StorageBytes
+		before.CallSeconds -= increase.CallSeconds
+
+		for metric, threshold := range limits {
+			if before.Value(metric) >= threshold || after.Value(metric) < threshold {
+				continue
+			}
+			alert := UsageAlert{
+				MapID:       key.mapID,
+				Metric:      metric,
+				Threshold:   threshold,
+				Total:       after.Value(metric),
+				PeriodStart: key.bucketStart,
+			}
+			if err := s.webhook.Post(ctx, UsageThresholdEvent, alert); err != nil {
+				log.Printf("Warning: failed to notify usage webhook for map %s: %v", key.mapID, err)
+			}
+		}
+	}
+}
+
+// GetReport returns the usage of every map, or of one map if mapID is set,
+// with buckets in [from, to). A zero to defaults to now and a zero from to the
+// start of the calendar month of to. Usage not flushed yet is not included.
+func (s *UsageService) GetReport(ctx context.Context, mapID string, from, to time.Time) (*UsageReport, error) {
+	if to.IsZero() {
+		to = s.now()
+	}
+	if from.IsZero() {
+		from = models.UsagePeriodFor(to)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidInput)
+	}
+	if to.Sub(from) > MaxUsageReportRange {
+		return nil, fmt.Errorf("%w: time range must not exceed %d days", ErrInvalidInput, int(MaxUsageReportRange.Hours()/24))
+	}
+
+	totals, err := s.repo.GetTotals(ctx, mapID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &UsageReport{From: from, To: to, Maps: make([]MapUsageTotal, 0, len(totals))}
+	for _, total := range totals {
+		report.Maps = append(report.Maps, MapUsageTotal{
+			MapID:          total.MapID,
+			WebSocketBytes: total.WebSocketBytes,
+			StorageBytes:   total.StorageBytes,
+			CallMinutes:    total.Value(models.UsageCallMinutes),
+		})
+	}
+	sort.Slice(report.Maps, func(i, j int) bool {
+		return report.Maps[i].MapID < report.Maps[j].MapID
+	})
+	return report, nil
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeUsageRepository struct {
+	usage  map[usageKey]*models.MapUsage
+	addErr error
+}
+
+func newFakeUsageRepository() *fakeUsageRepository {
+	return &fakeUsageRepository{usage: make(map[usageKey]*models.MapUsage)}
+}
+
+func (r *fakeUsageRepository) AddUsage(ctx context.Context, usage []*models.MapUsage) error {
+	if r.addErr != nil {
+		return r.addErr
+	}
+	for _, u := range usage {
+		key := usageKey{mapID: u.MapID, bucketStart: u.BucketStart}
+		if r.usage[key] == nil {
+			r.usage[key] = &models.MapUsage{MapID: u.MapID, BucketStart: u.BucketStart}
+		}
+		r.usage[key].Add(u)
+	}
+	return nil
+}
+
+func (r *fakeUsageRepository) GetTotals(ctx context.Context, mapID string, from, to time.Time) ([]*models.MapUsage, error) {
+	totals := map[string]*models.MapUsage{}
+	for key, u := range r.usage {
+		if (mapID != "" && key.mapID != mapID) || key.bucketStart.Before(from) || !key.bucketStart.Before(to) {
+			continue
+		}
+		if totals[key.mapID] == nil {
+			totals[key.mapID] = &models.MapUsage{MapID: key.mapID}
+		}
+		totals[key.mapID].Add(u)
+	}
+	result := []*models.MapUsage{}
+	for _, total := range totals {
+		result = append(result, total)
+	}
+	return result, nil
+}
+
+type recordingUsageWebhook struct {
+	alerts []UsageAlert
+}
+
+func (w *recordingUsageWebhook) Post(ctx context.Context, event string, data interface{}) error {
+	w.alerts = append(w.alerts, data.(UsageAlert))
+	return nil
+}
+
+func newTestUsageService(now *time.Time) (*UsageService, *fakeUsageRepository) {
+	repo := newFakeUsageRepository()
+	service := NewUsageService(repo)
+	service.now = func() time.Time { return *now }
+	return service, repo
+}
+
+func TestUsageService_FlushAddsMeteredUsage(t *testing.T) {
+	now := time.Date(2026, 5, 4, 10, 15, 0, 0, time.UTC)
+	service, repo := newTestUsageService(&now)
+	ctx := context.Background()
+
+	service.RecordWebSocketBytes("map-1", 100)
+	service.RecordWebSocketBytes("map-1", 50)
+	service.RecordStorageBytes("map-1", 4096)
+	service.RecordWebSocketBytes("map-2", 10)
+
+	flushed, err := service.Flush(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, flushed)
+
+	usage := repo.usage[usageKey{mapID: "map-1", bucketStart: time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)}]
+	require.NotNil(t, usage)
+	assert.Equal(t, int64(150), usage.WebSocketBytes)
+	assert.Equal(t, int64(4096), usage.StorageBytes)
+
+	// Nothing is written twice
+	flushed, err = service.Flush(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, flushed)
+}
+
+func TestUsageService_FailedFlushKeepsUsage(t *testing.T) {
+	now := time.Date(2026, 5, 4, 10, 15, 0, 0, time.UTC)
+	service, repo := newTestUsageService(&now)
+	ctx := context.Background()
+
+	service.RecordWebSocketBytes("map-1", 100)
+	repo.addErr = errors.New("database down")
+	_, err := service.Flush(ctx)
+	require.Error(t, err)
+
+	repo.addErr = nil
+	service.RecordWebSocketBytes("map-1", 20)
+	_, err = service.Flush(ctx)
+	require.NoError(t, err)
+
+	totals, err := repo.GetTotals(ctx, "map-1", models.UsagePeriodFor(now), now.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, totals, 1)
+	assert.Equal(t, int64(120), totals[0].WebSocketBytes)
+}
+
+func TestUsageService_CallMinutes(t *testing.T) {
+	now := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	service, repo := newTestUsageService(&now)
+	ctx := context.Background()
+
+	service.StartCall("map-1", "call-1")
+	now = now.Add(90 * time.Second)
+	service.EndCall("call-1")
+
+	// Ongoing calls are metered up to each flush
+	service.StartCall("map-1", "call-2")
+	now = now.Add(time.Minute)
+	_, err := service.Flush(ctx)
+	require.NoError(t, err)
+
+	totals, err := repo.GetTotals(ctx, "map-1", models.UsagePeriodFor(now), now.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, totals, 1)
+	assert.Equal(t, int64(150), totals[0].CallSeconds)
+	assert.Equal(t, int64(3), totals[0].Value(models.UsageCallMinutes))
+
+	// Ending unknown or ended calls changes nothing
+	service.EndCall("call-1")
+	service.EndCall("unknown")
+}
+
+func TestUsageService_CallsStopCountingAfterMaxDuration(t *testing.T) {
+	now := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	service, repo := newTestUsageService(&now)
+	ctx := context.Background()
+
+	service.StartCall("map-1", "call-1")
+	now = now.Add(MaxCallDuration + time.Hour)
+	_, err := service.Flush(ctx)
+	require.NoError(t, err)
+
+	now = now.Add(time.Hour)
+	service.EndCall("call-1")
+	_, err = service.Flush(ctx)
+	require.NoError(t, err)
+
+	totals, err := repo.GetTotals(ctx, "map-1", models.UsagePeriodFor(now), now.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, totals, 1)
+	assert.Equal(t, int64(MaxCallDuration.Seconds()), totals[0].CallSeconds)
+}
+
+func TestUsageService_NotifiesCrossedThresholdsOnce(t *testing.T) {
+	now := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	service, _ := newTestUsageService(&now)
+	webhook := &recordingUsageWebhook{}
+	service.SetWebhook(webhook, UsageThresholds{WebSocketBytes: 1000, CallMinutes: 60})
+	ctx := context.Background()
+
+	service.RecordWebSocketBytes("map-1", 600)
+	_, err := service.Flush(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, webhook.alerts)
+
+	service.RecordWebSocketBytes("map-1", 600)
+	service.RecordWebSocketBytes("map-2", 10)
+	_, err = service.Flush(ctx)
+	require.NoError(t, err)
+	require.Len(t, webhook.alerts, 1)
+	assert.Equal(t, UsageAlert{
+		MapID:       "map-1",
+		Metric:      models.UsageWebSocketBytes,
+		Threshold:   1000,
+		Total:       1200,
+		PeriodStart: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC),
+	}, webhook.alerts[0])
+
+	service.RecordWebSocketBytes("map-1", 600)
+	_, err = service.Flush(ctx)
+	require.NoError(t, err)
+	assert.Len(t, webhook.alerts, 1)
+
+	// Thresholds apply per calendar month
+	now = time.Date(2026, 6, 1, 0, 30, 0, 0, time.UTC)
+	service.RecordWebSocketBytes("map-1", 1000)
+	_, err = service.Flush(ctx)
+	require.NoError(t, err)
+	require.Len(t, webhook.alerts, 2)
+	assert.Equal(t, time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), webhook.alerts[1].PeriodStart)
+}
+
+func TestUsageService_GetReport(t *testing.T) {
+	now := time.Date(2026, 5, 4, 10, 15, 0, 0, time.UTC)
+	service, _ := newTestUsageService(&now)
+	ctx := context.Background()
+
+	service.RecordWebSocketBytes("map-2", 10)
+	service.RecordWebSocketBytes("map-1", 20)
+	service.StartCall("map-1", "call-1")
+	now = now.Add(30 * time.Second)
+	service.EndCall("call-1")
+	_, err := service.Flush(ctx)
+	require.NoError(t, err)
+
+	report, err := service.GetReport(ctx, "", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), report.From)
+	assert.Equal(t, now, report.To)
+	assert.Equal(t, []MapUsageTotal{
+		{MapID: "map-1", WebSocketBytes: 20, CallMinutes: 1},
+		{MapID: "map-2", WebSocketBytes: 10},
+	}, report.Maps)
+
+	report, err = service.GetReport(ctx, "map-2", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, report.Maps, 1)
+	assert.Equal(t, "map-2", report.Maps[0].MapID)
+}
+
+func TestUsageService_GetReport_InvalidRange(t *testing.T) {
+	now := time.Date(2026, 5, 4, 10, 15, 0, 0, time.UTC)
+	service, _ := newTestUsageService(&now)
+
+	_, err := service.GetReport(context.Background(), "", now, now.Add(-time.Hour))
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	_, err = service.GetReport(context.Background(), "", now.Add(-2*MaxUsageReportRange), now)
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
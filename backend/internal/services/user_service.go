@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -9,7 +10,10 @@ import (
 
 	"breakoutglobe/internal/interfaces"
 	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/redis"
 	"breakoutglobe/internal/storage"
+
+	"gorm.io/gorm"
 )
 
 // UpdateProfileRequest represents a request to update user profile
@@ -18,11 +22,25 @@ type UpdateProfileRequest struct {
 	AboutMe     *string `json:"aboutMe,omitempty"`
 }
 
+// ProfilePublisher defines the interface for publishing profile changes to connected maps
+type ProfilePublisher interface {
+	PublishUserProfileUpdated(ctx context.Context, event redis.UserProfileUpdatedEvent) error
+	PublishUserRoleChanged(ctx context.Context, event redis.UserRoleChangedEvent) error
+}
+
+// ActiveMapLister defines the interface for finding the maps a user has active sessions in
+type ActiveMapLister interface {
+	GetActiveMapIDsByUser(userID string) ([]string, error)
+}
+
 // UserService handles user-related business logic
 type UserService struct {
-	userRepo    interfaces.UserRepositoryInterface
-	fileStorage storage.FileStorage
-	authService *AuthService
+	userRepo         interfaces.UserRepositoryInterface
+	fileStorage      storage.FileStorage
+	authService      *AuthService
+	profilePublisher ProfilePublisher
+	activeMaps       ActiveMapLister
+	onboarding       OnboardingTracker
 }
 
 // NewUserService creates a new UserService instance
@@ -38,6 +56,62 @@ func (s *UserService) SetAuthService(authService *AuthService) {
 	s.authService = authService
 }
 
+// SetProfilePublisher sets where profile changes are published, so other clients
+// on the user's maps drop cached names and avatars. Without one, they see changes on reconnect.
+func (s *UserService) SetProfilePublisher(publisher ProfilePublisher, activeMaps ActiveMapLister) {
+	s.profilePublisher = publisher
+	s.activeMaps = activeMaps
+}
+
+// SetOnboarding sets the welcome checklist that setting up a profile completes a step of
+func (s *UserService) SetOnboarding(onboarding OnboardingTracker) {
+	s.onboarding = onboarding
+}
+
+// completeProfileStep marks the profile step of the user's welcome checklist as completed
+func (s *UserService) completeProfileStep(ctx context.Context, userID string) {
+	if s.onboarding == nil {
+		return
+	}
+	if err := s.onboarding.CompleteStep(ctx, userID, models.OnboardingProfileSet); err != nil {
+		// Log error but don't fail the operation
+		fmt.Printf("Warning: failed to complete onboarding step: %v\n", err)
+	}
+}
+
+// activeMapIDs returns the maps the user has an active session in, or nothing
+// if profile changes are not published
+func (s *UserService) activeMapIDs(userID string) []string {
+	if s.profilePublisher == nil || s.activeMaps == nil {
+		return nil
+	}
+	
+	mapIDs, err := s.activeMaps.GetActiveMapIDsByUser(userID)
+	if err != nil {
+		fmt.Printf("Warning: failed to get active maps for user %s: %v\n", userID, err)
+		return nil
+	}
+	return mapIDs
+}
+
+// publishProfileUpdated publishes a profile change to every map the user has an active session in
+func (s *UserService) publishProfileUpdated(ctx context.Context, user *models.User) {
+	for _, mapID := range s.activeMapIDs(user.ID) {
+		event := redis.UserProfileUpdatedEvent{
+			UserID:      user.ID,
+			MapID:       mapID,
+			DisplayName: user.DisplayName,
+			AvatarURL:   user.AvatarURL,
+			AboutMe:     user.AboutMe,
+			Timestamp:   time.Now(),
+		}
+		if err := s.profilePublisher.PublishUserProfileUpdated(ctx, event); err != nil {
+			// Log error but don't fail the operation
+			fmt.Printf("Warning: failed to publish user profile updated event: %v\n", err)
+		}
+	}
+}
+
 // CreateGuestProfile creates a new guest user profile
 func (s *UserService) CreateGuestProfile(ctx context.Context, displayName string) (*models.User, error) {
 	// Create new guest user
@@ -96,7 +170,7 @@ func (s *UserService) GetUser(ctx context.Context, userID string) (*models.User,
 	
 	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("user not found")
+		return nil, fmt.Errorf("user %w", ErrNotFound)
 	}
 	
 	return user, nil
@@ -117,7 +191,7 @@ func (s *UserService) UploadAvatar(ctx context.Context, userID string, filename
 	contentType := getContentTypeFromFilename(filename)
 	
 	// Upload file to storage
-	avatarURL, err := s.fileStorage.UploadFile(ctx, fileKey, fileData, contentType)
+	avatarURL, err := s.uploadAvatarFile(ctx, userID, fileKey, fileData, contentType)
 	if err != nil {
 		return nil, fmt.Errorf("failed to upload avatar: %w", err)
 	}
@@ -126,7 +200,7 @@ func (s *UserService) UploadAvatar(ctx context.Context, userID string, filename
 	if user.AvatarURL != nil && *user.AvatarURL != "" {
 		// Extract old file key from URL and delete
 		if oldKey := extractFileKeyFromURL(*user.AvatarURL); oldKey != "" {
-			s.fileStorage.DeleteFile(ctx, oldKey)
+			s.deleteAvatarFile(ctx, userID, oldKey)
 		}
 	}
 	
@@ -141,6 +215,60 @@ func (s *UserService) UploadAvatar(ctx context.Context, userID string, filename
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
 
+	s.publishProfileUpdated(ctx, user)
+	s.completeProfileStep(ctx, user.ID)
+	return user, nil
+}
+
+// uploadAvatarFile stores an avatar as the user's upload, if the storage
+// tracks who uploaded files
+func (s *UserService) uploadAvatarFile(ctx context.Context, userID, key string, data []byte, contentType string) (string, error) {
+	if owned, ok := s.fileStorage.(storage.OwnedFileStorage); ok {
+		return owned.UploadOwnedFile(ctx, userID, key, data, contentType)
+	}
+	return s.fileStorage.UploadFile(ctx, key, data, contentType)
+}
+
+// deleteAvatarFile releases the user's avatar file, leaving other users'
+// uploads of the same file in place
+func (s *UserService) deleteAvatarFile(ctx context.Context, userID, key string) error {
+	if owned, ok := s.fileStorage.(storage.OwnedFileStorage); ok {
+		return owned.DeleteOwnedFile(ctx, userID, key)
+	}
+	return s.fileStorage.DeleteFile(ctx, key)
+}
+
+// DeleteAvatar removes a user's avatar and its stored file.
+// Deleting an avatar that is not set succeeds without changes.
+func (s *UserService) DeleteAvatar(ctx context.Context, userID string) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("user %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	
+	if user.AvatarURL == nil || *user.AvatarURL == "" {
+		return user, nil
+	}
+	oldKey := extractFileKeyFromURL(*user.AvatarURL)
+	
+	user.AvatarURL = nil
+	user.UpdatedAt = time.Now()
+	
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+	
+	// Delete the stored file once the user no longer points at it
+	if oldKey != "" {
+		if err := s.deleteAvatarFile(ctx, userID, oldKey); err != nil {
+			fmt.Printf("Warning: failed to delete avatar file: %v\n", err)
+		}
+	}
+	
+	s.publishProfileUpdated(ctx, user)
 	return user, nil
 }
 
@@ -190,6 +318,56 @@ func (s *UserService) UpdateProfile(ctx context.Context, userID string, req *Upd
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
 	
+	s.publishProfileUpdated(ctx, user)
+	s.completeProfileStep(ctx, user.ID)
+	return user, nil
+}
+
+// UpdateRole promotes or demotes a user. Superadmins are managed outside the
+// app, so they can be neither assigned nor changed here. Live connections of
+// the user learn about the change through a role_changed event.
+func (s *UserService) UpdateRole(ctx context.Context, userID string, role models.UserRole) (*models.User, error) {
+	if role != models.UserRoleUser && role != models.UserRoleAdmin {
+		return nil, fmt.Errorf("%w: role must be user or admin", ErrInvalidInput)
+	}
+	
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("user %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	
+	if user.IsSuperAdmin() {
+		return nil, fmt.Errorf("%w: cannot change the role of a superadmin", ErrInvalidInput)
+	}
+	if role == models.UserRoleAdmin && user.AccountType != models.AccountTypeFull {
+		return nil, fmt.Errorf("%w: only full accounts can be admins", ErrInvalidInput)
+	}
+	if user.Role == role {
+		return user, nil
+	}
+	
+	user.Role = role
+	user.UpdatedAt = time.Now()
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+	
+	for _, mapID := range s.activeMapIDs(user.ID) {
+		event := redis.UserRoleChangedEvent{
+			UserID:    user.ID,
+			MapID:     mapID,
+			Role:      string(user.Role),
+			Timestamp: time.Now(),
+		}
+		if err := s.profilePublisher.PublishUserRoleChanged(ctx, event); err != nil {
+			// Log error but don't fail the operation
+			fmt.Printf("Warning: failed to publish user role changed event: %v\n", err)
+		}
+	}
+	
 	return user, nil
 }
 
@@ -341,7 +519,7 @@ func (s *UserService) GetUserByEmail(ctx context.Context, email string) (*models
 
 	user, err := s.userRepo.GetByEmail(ctx, email)
 	if err != nil {
-		return nil, fmt.Errorf("user not found")
+		return nil, fmt.Errorf("user %w", ErrNotFound)
 	}
 
 	return user, nil
@@ -351,7 +529,7 @@ func (s *UserService) GetUserByEmail(ctx context.Context, email string) (*models
 func (s *UserService) VerifyPassword(ctx context.Context, userID, password string) error {
 	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		return fmt.Errorf("user not found")
+		return fmt.Errorf("user %w", ErrNotFound)
 	}
 
 	if user.PasswordHash == nil || *user.PasswordHash == "" {
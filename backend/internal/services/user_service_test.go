@@ -9,7 +9,9 @@ import (
 
 	"breakoutglobe/internal/interfaces"
 	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/redis"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
 // UserServiceTestScenario provides scenario-based testing for UserService using established patterns
@@ -321,6 +323,82 @@ func TestUserService_UploadAvatar_UpdateError(t *testing.T) {
 	}
 }
 
+type recordingProfilePublisher struct {
+	events      []redis.UserProfileUpdatedEvent
+	roleChanges []redis.UserRoleChangedEvent
+}
+
+func (p *recordingProfilePublisher) PublishUserProfileUpdated(ctx context.Context, event redis.UserProfileUpdatedEvent) error {
+	p.events = append(p.events, event)
+	return nil
+}
+
+func (p *recordingProfilePublisher) PublishUserRoleChanged(ctx context.Context, event redis.UserRoleChangedEvent) error {
+	p.roleChanges = append(p.roleChanges, event)
+	return nil
+}
+
+type staticActiveMaps []string
+
+func (m staticActiveMaps) GetActiveMapIDsByUser(userID string) ([]string, error) {
+	return m, nil
+}
+
+func TestUserService_DeleteAvatar_RemovesFileAndPublishes(t *testing.T) {
+	scenario := newUserServiceTestScenario(t)
+	defer scenario.cleanup()
+
+	userID := "user-123"
+	avatarURL := "http://localhost:8080/uploads/avatars/user-123_1700000000.jpg"
+	existingUser := &models.User{
+		ID:          userID,
+		DisplayName: "Test User",
+		AccountType: models.AccountTypeGuest,
+		AvatarURL:   &avatarURL,
+	}
+
+	publisher := &recordingProfilePublisher{}
+	scenario.service.SetProfilePublisher(publisher, staticActiveMaps{"map-1", "map-2"})
+
+	scenario.expectUserRetrievalSuccess(userID, existingUser).
+		expectUserUpdateSuccess()
+	scenario.mockStorage.On("DeleteFile", mock.Anything, "avatars/user-123_1700000000.jpg").Return(nil)
+
+	user, err := scenario.service.DeleteAvatar(context.Background(), userID)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if user.AvatarURL != nil {
+		t.Errorf("Expected avatar URL to be cleared, got %s", *user.AvatarURL)
+	}
+	if len(publisher.events) != 2 {
+		t.Fatalf("Expected one profile update per active map, got %d", len(publisher.events))
+	}
+	if publisher.events[0].UserID != userID || publisher.events[0].AvatarURL != nil {
+		t.Errorf("Expected cleared avatar to be published, got %+v", publisher.events[0])
+	}
+	scenario.mockStorage.AssertExpectations(t)
+}
+
+func TestUserService_DeleteAvatar_WithoutAvatar(t *testing.T) {
+	scenario := newUserServiceTestScenario(t)
+	defer scenario.cleanup()
+
+	userID := "user-123"
+	scenario.expectUserRetrievalSuccess(userID, &models.User{ID: userID, DisplayName: "Test User"})
+
+	// Nothing to update or delete
+	user, err := scenario.service.DeleteAvatar(context.Background(), userID)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if user.AvatarURL != nil {
+		t.Errorf("Expected no avatar URL")
+	}
+}
+
 // Helper function to check if a string contains a substring
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || 
@@ -448,6 +526,14 @@ func (m *MockUserRepository) GetByID(ctx context.Context, id string) (*models.Us
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
+func (m *MockUserRepository) ListByRole(ctx context.Context, role models.UserRole) ([]*models.User, error) {
+	args := m.Called(ctx, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.User), args.Error(1)
+}
+
 func (m *MockUserRepository) Update(ctx context.Context, user *models.User) error {
 	args := m.Called(ctx, user)
 	return args.Error(0)
@@ -526,4 +612,78 @@ func (m *MockFileStorage) FileExists(key string) bool {
 func (m *MockFileStorage) GenerateUniqueKey(prefix, userID, originalFilename string) string {
 	args := m.Called(prefix, userID, originalFilename)
 	return args.String(0)
-}
\ No newline at end of file
+}
+func TestUserService_UpdateRole_PublishesToActiveMaps(t *testing.T) {
+	scenario := newUserServiceTestScenario(t)
+	defer scenario.cleanup()
+
+	userID := "user-123"
+	email := "facilitator@example.com"
+	publisher := &recordingProfilePublisher{}
+	scenario.service.SetProfilePublisher(publisher, staticActiveMaps{"map-1"})
+
+	scenario.expectUserRetrievalSuccess(userID, &models.User{
+		ID:          userID,
+		Email:       &email,
+		DisplayName: "Facilitator",
+		AccountType: models.AccountTypeFull,
+		Role:        models.UserRoleUser,
+	}).expectUserUpdateSuccess()
+
+	user, err := scenario.service.UpdateRole(context.Background(), userID, models.UserRoleAdmin)
+
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if user.Role != models.UserRoleAdmin {
+		t.Errorf("Expected role admin, got %s", user.Role)
+	}
+	if len(publisher.roleChanges) != 1 || publisher.roleChanges[0].MapID != "map-1" || publisher.roleChanges[0].Role != "admin" {
+		t.Errorf("Expected role change to be published to map-1, got %+v", publisher.roleChanges)
+	}
+}
+
+func TestUserService_UpdateRole_Rejected(t *testing.T) {
+	tests := []struct {
+		name string
+		user *models.User
+		role models.UserRole
+	}{
+		{"superadmin role", &models.User{ID: "user-123", AccountType: models.AccountTypeFull, Role: models.UserRoleUser}, models.UserRoleSuperAdmin},
+		{"demote superadmin", &models.User{ID: "user-123", AccountType: models.AccountTypeFull, Role: models.UserRoleSuperAdmin}, models.UserRoleUser},
+		{"guest admin", &models.User{ID: "user-123", AccountType: models.AccountTypeGuest, Role: models.UserRoleUser}, models.UserRoleAdmin},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scenario := newUserServiceTestScenario(t)
+			defer scenario.cleanup()
+			scenario.mockUserRepo.On("GetByID", mock.Anything, tt.user.ID).Return(tt.user, nil).Maybe()
+
+			_, err := scenario.service.UpdateRole(context.Background(), tt.user.ID, tt.role)
+
+			if !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("Expected ErrInvalidInput, got %v", err)
+			}
+			scenario.mockUserRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestUserService_UpdateRole_LookupErrors(t *testing.T) {
+	scenario := newUserServiceTestScenario(t)
+	defer scenario.cleanup()
+	scenario.mockUserRepo.On("GetByID", mock.Anything, "missing").Return(nil, gorm.ErrRecordNotFound)
+	scenario.mockUserRepo.On("GetByID", mock.Anything, "user-123").Return(nil, errors.New("connection refused"))
+
+	_, err := scenario.service.UpdateRole(context.Background(), "missing", models.UserRoleAdmin)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	// Database failures aren't reported as missing users
+	_, err = scenario.service.UpdateRole(context.Background(), "user-123", models.UserRoleAdmin)
+	if err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected a lookup error, got %v", err)
+	}
+}
@@ -0,0 +1,344 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// UploadIndex tracks which file keys reference which content blobs, and who
+// uploaded them
+type UploadIndex interface {
+	// AddReference records that key, uploaded by ownerID, refers to the blob
+	// with the given content hash
+	AddReference(ctx context.Context, ownerID, key, contentHash string) error
+	// RemoveReference removes the reference for key and returns the hash it pointed to
+	RemoveReference(ctx context.Context, key string) (string, error)
+	// RemoveOwnerReference removes one reference ownerID holds to the given
+	// content hash and reports whether there was one
+	RemoveOwnerReference(ctx context.Context, ownerID, contentHash string) (bool, error)
+	// CountReferences returns how many keys reference the given content hash
+	CountReferences(ctx context.Context, contentHash string) (int64, error)
+}
+
+// DedupFileStorage stores uploads by content hash so identical files share one blob.
+// Blobs are reference counted and only removed from the underlying storage when
+// the last reference is deleted.
+type DedupFileStorage struct {
+	storage FileStorage
+	index   UploadIndex
+	mutex   sync.Mutex
+}
+
+// NewDedupFileStorage wraps a FileStorage with content-addressable deduplication
+func NewDedupFileStorage(storage FileStorage, index UploadIndex) *DedupFileStorage {
+	return &DedupFileStorage{
+		storage: storage,
+		index:   index,
+	}
+}
+
+// ContentHash returns the hex-encoded SHA-256 hash of data
+func ContentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// BlobKey returns the storage key of the blob for a content hash and extension
+func BlobKey(contentHash, ext string) string {
+	return fmt.Sprintf("blobs/%s/%s%s", contentHash[:2], contentHash, strings.ToLower(ext))
+}
+
+// UploadFile stores data under its content hash without an owner, see UploadOwnedFile
+func (d *DedupFileStorage) UploadFile(ctx context.Context, key string, data []byte, contentType string) (string, error) {
+	return d.UploadOwnedFile(ctx, "", key, data, contentType)
+}
+
+// UploadOwnedFile stores data under its content hash, skipping the write if the blob already exists.
+// key is recorded as ownerID's reference to the blob; the returned URL points at the shared blob.
+func (d *DedupFileStorage) UploadOwnedFile(ctx context.Context, ownerID, key string, data []byte, contentType string) (string, error) {
+	contentHash := ContentHash(data)
+	blobKey := BlobKey(contentHash, filepath.Ext(key))
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if !d.storage.FileExists(blobKey) {
+		if _, err := d.storage.UploadFile(ctx, blobKey, data, contentType); err != nil {
+			return "", err
+		}
+	}
+
+	if err := d.index.AddReference(ctx, ownerID, sanitizeFilePath(key), contentHash); err != nil {
+		return "", fmt.Errorf("failed to record upload reference: %w", err)
+	}
+
+	return d.storage.GetFileURL(blobKey), nil
+}
+
+// DeleteFile releases the reference of an original key. A blob key releases
+// nothing, since the blob may be shared; it is only deleted if no references
+// remain. The blob itself is deleted once no references remain.
+func (d *DedupFileStorage) DeleteFile(ctx context.Context, key string) error {
+	key = sanitizeFilePath(key)
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if hash, ok := parseBlobKey(key); ok {
+		return d.deleteUnreferencedBlobs(ctx, hash)
+	}
+	return d.releaseKey(ctx, key)
+}
+
+// DeleteOwnedFile releases ownerID's reference by its original key or by the
+// blob key, which is all callers holding a blob URL know. References of
+// other owners to the same blob are left alone. The blob itself is deleted
+// once no references remain.
+func (d *DedupFileStorage) DeleteOwnedFile(ctx context.Context, ownerID, key string) error {
+	key = sanitizeFilePath(key)
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	hash, ok := parseBlobKey(key)
+	if !ok {
+		return d.releaseKey(ctx, key)
+	}
+
+	released, err := d.index.RemoveOwnerReference(ctx, ownerID, hash)
+	if err != nil {
+		return fmt.Errorf("failed to release upload reference: %w", err)
+	}
+	if !released {
+		return nil
+	}
+	return d.deleteUnreferencedBlobs(ctx, hash)
+}
+
+// ClaimContent records ownerID's reference to an already stored blob, so
+// clients that skip uploading a known file still keep it from being deleted.
+// Claiming a blob twice keeps one reference. It returns the blob's URL, or
+// false if no blob has the content hash.
+func (d *DedupFileStorage) ClaimContent(ctx context.Context, ownerID, contentHash string) (string, bool, error) {
+	if !isContentHash(contentHash) {
+		return "", false, nil
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	for _, ext := range blobExtensions {
+		blobKey := BlobKey(contentHash, ext)
+		if !d.storage.FileExists(blobKey) {
+			continue
+		}
+		key := fmt.Sprintf("claims/%s/%s%s", ownerID, contentHash, ext)
+		if err := d.index.AddReference(ctx, ownerID, key, contentHash); err != nil {
+			return "", false, fmt.Errorf("failed to record upload reference: %w", err)
+		}
+		return d.storage.GetFileURL(blobKey), true, nil
+	}
+	return "", false, nil
+}
+
+// ReferenceBlob records key, held by ownerID, as another reference to the
+// stored blob at blobKey, so a copy of the blob's URL keeps it from being
+// deleted with the original. It returns false if blobKey isn't a stored blob.
+func (d *DedupFileStorage) ReferenceBlob(ctx context.Context, ownerID, key, blobKey string) (bool, error) {
+	blobKey = sanitizeFilePath(blobKey)
+	hash, ok := parseBlobKey(blobKey)
+	if !ok {
+		return false, nil
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if !d.storage.FileExists(blobKey) {
+		return false, nil
+	}
+	if err := d.index.AddReference(ctx, ownerID, sanitizeFilePath(key), hash); err != nil {
+		return false, fmt.Errorf("failed to record upload reference: %w", err)
+	}
+	return true, nil
+}
+
+// releaseKey releases the reference of an original key and deletes its blob
+// once no references remain. Keys that aren't deduplicated are deleted from
+// the underlying storage. The caller must hold the mutex.
+func (d *DedupFileStorage) releaseKey(ctx context.Context, key string) error {
+	hash, err := d.index.RemoveReference(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to release upload reference: %w", err)
+	}
+	if hash == "" {
+		// Not a deduplicated upload, fall back to the underlying storage
+		return d.storage.DeleteFile(ctx, key)
+	}
+	return d.deleteUnreferencedBlobs(ctx, hash)
+}
+
+// deleteUnreferencedBlobs deletes the blobs of a content hash if no
+// references remain. The caller must hold the mutex.
+func (d *DedupFileStorage) deleteUnreferencedBlobs(ctx context.Context, contentHash string) error {
+	count, err := d.index.CountReferences(ctx, contentHash)
+	if err != nil {
+		return fmt.Errorf("failed to count upload references: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	return d.deleteBlobs(ctx, contentHash)
+}
+
+// GetFileURL returns the public URL for a file key
+func (d *DedupFileStorage) GetFileURL(key string) string {
+	return d.storage.GetFileURL(key)
+}
+
+// FileExists checks if a file exists in the underlying storage
+func (d *DedupFileStorage) FileExists(key string) bool {
+	return d.storage.FileExists(key)
+}
+
+// ListFiles lists files in the underlying storage, including blobs
+func (d *DedupFileStorage) ListFiles(ctx context.Context, prefix string) ([]StoredFile, error) {
+	lister, ok := d.storage.(FileLister)
+	if !ok {
+		return nil, fmt.Errorf("underlying storage cannot list files")
+	}
+	return lister.ListFiles(ctx, prefix)
+}
+
+// GenerateUniqueKey generates a unique file key using the underlying storage
+func (d *DedupFileStorage) GenerateUniqueKey(prefix, userID, originalFilename string) string {
+	return d.storage.GenerateUniqueKey(prefix, userID, originalFilename)
+}
+
+// LookupContent returns the URL of an already stored blob for a content hash, if any.
+// Clients can use this to skip uploading files the server already has, and
+// claim them with ClaimContent.
+func (d *DedupFileStorage) LookupContent(ctx context.Context, contentHash string) (string, bool) {
+	if !isContentHash(contentHash) {
+		return "", false
+	}
+
+	for _, ext := range blobExtensions {
+		blobKey := BlobKey(contentHash, ext)
+		if d.storage.FileExists(blobKey) {
+			return d.storage.GetFileURL(blobKey), true
+		}
+	}
+	return "", false
+}
+
+// blobExtensions lists the extensions a blob may have been stored with
+var blobExtensions = []string{".jpg", ".jpeg", ".png", ".webp", ".gif", ""}
+
+// deleteBlobs removes every stored variant of a content hash
+func (d *DedupFileStorage) deleteBlobs(ctx context.Context, contentHash string) error {
+	var lastErr error
+	for _, ext := range blobExtensions {
+		blobKey := BlobKey(contentHash, ext)
+		if !d.storage.FileExists(blobKey) {
+			continue
+		}
+		if err := d.storage.DeleteFile(ctx, blobKey); err != nil {
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+// parseBlobKey extracts the content hash from a blob key
+func parseBlobKey(key string) (string, bool) {
+	if !strings.HasPrefix(key, "blobs/") {
+		return "", false
+	}
+
+	name := filepath.Base(key)
+	hash := strings.TrimSuffix(name, filepath.Ext(name))
+	if !isContentHash(hash) {
+		return "", false
+	}
+	return hash, true
+}
+
+// isContentHash checks if s looks like a hex-encoded SHA-256 hash
+func isContentHash(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// memoryUploadReference is a reference held by a MemoryUploadIndex
+type memoryUploadReference struct {
+	ownerID     string
+	contentHash string
+}
+
+// MemoryUploadIndex is an in-memory UploadIndex, useful for tests and single-instance setups
+type MemoryUploadIndex struct {
+	references map[string]memoryUploadReference // key -> reference
+	mutex      sync.Mutex
+}
+
+// NewMemoryUploadIndex creates a new in-memory upload index
+func NewMemoryUploadIndex() *MemoryUploadIndex {
+	return &MemoryUploadIndex{
+		references: make(map[string]memoryUploadReference),
+	}
+}
+
+// AddReference records that key, uploaded by ownerID, refers to the blob
+// with the given content hash
+func (m *MemoryUploadIndex) AddReference(ctx context.Context, ownerID, key, contentHash string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.references[key] = memoryUploadReference{ownerID: ownerID, contentHash: contentHash}
+	return nil
+}
+
+// RemoveReference removes the reference for key and returns the hash it pointed to
+func (m *MemoryUploadIndex) RemoveReference(ctx context.Context, key string) (string, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	ref := m.references[key]
+	delete(m.references, key)
+	return ref.contentHash, nil
+}
+
+// RemoveOwnerReference removes one reference ownerID holds to the given
+// content hash and reports whether there was one
+func (m *MemoryUploadIndex) RemoveOwnerReference(ctx context.Context, ownerID, contentHash string) (bool, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for key, ref := range m.references {
+		if ref.ownerID == ownerID && ref.contentHash == contentHash {
+			delete(m.references, key)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CountReferences returns how many keys reference the given content hash
+func (m *MemoryUploadIndex) CountReferences(ctx context.Context, contentHash string) (int64, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	var count int64
+	for _, ref := range m.references {
+		if ref.contentHash == contentHash {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDedupStorage(t *testing.T) (*DedupFileStorage, *MemoryUploadIndex) {
+	index := NewMemoryUploadIndex()
+	local := NewLocalFileStorage(StorageConfig{UploadPath: t.TempDir(), BaseURL: "http://localhost:8080", MaxFileSize: 1024})
+	return NewDedupFileStorage(local, index), index
+}
+
+func TestDedupFileStorage_DeleteOwnedFile(t *testing.T) {
+	d, index := newTestDedupStorage(t)
+	ctx := context.Background()
+	data := []byte("avatar")
+	blobKey := BlobKey(ContentHash(data), ".png")
+
+	_, err := d.UploadOwnedFile(ctx, "user-1", "avatars/user-1_1.png", data, "image/png")
+	require.NoError(t, err)
+	_, err = d.UploadOwnedFile(ctx, "user-2", "avatars/user-2_1.png", data, "image/png")
+	require.NoError(t, err)
+
+	// Other users' references survive deleting through the shared blob URL
+	require.NoError(t, d.DeleteOwnedFile(ctx, "user-1", blobKey))
+	require.NoError(t, d.DeleteOwnedFile(ctx, "user-1", blobKey))
+	count, err := index.CountReferences(ctx, ContentHash(data))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.True(t, d.FileExists(blobKey))
+
+	// Blob keys alone release nothing
+	require.NoError(t, d.DeleteFile(ctx, blobKey))
+	assert.True(t, d.FileExists(blobKey))
+
+	require.NoError(t, d.DeleteOwnedFile(ctx, "user-2", blobKey))
+	assert.False(t, d.FileExists(blobKey))
+}
+
+func TestDedupFileStorage_ClaimContent(t *testing.T) {
+	d, _ := newTestDedupStorage(t)
+	ctx := context.Background()
+	data := []byte("poi image")
+	contentHash := ContentHash(data)
+	blobKey := BlobKey(contentHash, ".jpg")
+
+	uploadedURL, err := d.UploadOwnedFile(ctx, "user-1", "pois/poi-1.jpg", data, "image/jpeg")
+	require.NoError(t, err)
+
+	url, exists, err := d.ClaimContent(ctx, "user-2", contentHash)
+	require.NoError(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, uploadedURL, url)
+
+	// Claiming twice keeps one reference
+	_, _, err = d.ClaimContent(ctx, "user-2", contentHash)
+	require.NoError(t, err)
+
+	// The claim keeps the blob after the uploader deleted it
+	require.NoError(t, d.DeleteFile(ctx, "pois/poi-1.jpg"))
+	assert.True(t, d.FileExists(blobKey))
+	require.NoError(t, d.DeleteOwnedFile(ctx, "user-2", blobKey))
+	assert.False(t, d.FileExists(blobKey))
+
+	_, exists, err = d.ClaimContent(ctx, "user-2", ContentHash([]byte("unknown")))
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestImageProcessor_ReferencePOIImages(t *testing.T) {
+	d, _ := newTestDedupStorage(t)
+	ctx := context.Background()
+	original := []byte("original")
+	thumbnail := []byte("thumbnail")
+
+	originalURL, err := d.UploadFile(ctx, "pois/poi-1-original.png", original, "image/png")
+	require.NoError(t, err)
+	thumbnailURL, err := d.UploadFile(ctx, "pois/poi-1-thumb.jpg", thumbnail, "image/jpeg")
+	require.NoError(t, err)
+
+	processor := NewImageProcessor(d)
+	require.NoError(t, processor.ReferencePOIImages(ctx, "poi-2", originalURL, thumbnailURL))
+	require.NoError(t, processor.ReferencePOIImages(ctx, "poi-3", "https://example.com/image.png", ""))
+
+	// The copies keep the blobs after the original POI's images are deleted
+	require.NoError(t, processor.DeletePOIImages(ctx, "poi-1"))
+	assert.True(t, d.FileExists(BlobKey(ContentHash(original), ".png")))
+	assert.True(t, d.FileExists(BlobKey(ContentHash(thumbnail), ".jpg")))
+
+	require.NoError(t, processor.DeletePOIImages(ctx, "poi-2"))
+	assert.False(t, d.FileExists(BlobKey(ContentHash(original), ".png")))
+	assert.False(t, d.FileExists(BlobKey(ContentHash(thumbnail), ".jpg")))
+}
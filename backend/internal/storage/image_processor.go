@@ -16,6 +16,9 @@ import (
 	"golang.org/x/image/webp"
 )
 
+// poiImageExtensions lists the extensions a POI's original image may have been stored with
+var poiImageExtensions = []string{".jpg", ".jpeg", ".png", ".webp"}
+
 // ImageProcessor handles image processing operations like thumbnail generation
 type ImageProcessor struct {
 	storage FileStorage
@@ -86,6 +89,13 @@ func (ip *ImageProcessor) ProcessPOIImage(
 		return "", "", fmt.Errorf("failed to upload thumbnail: %w", err)
 	}
 
+	// A replaced image in another format would otherwise be left behind
+	for _, oldExt := range poiImageExtensions {
+		if oldExt != ext {
+			_ = ip.storage.DeleteFile(ctx, fmt.Sprintf("pois/%s-original%s", poiID, oldExt))
+		}
+	}
+
 	return originalURL, thumbnailURL, nil
 }
 
@@ -130,14 +140,41 @@ func (ip *ImageProcessor) decodeImage(r io.Reader, contentType string) (image.Im
 	}
 }
 
+// ReferencePOIImages makes a POI the holder of copies of another POI's
+// image URLs, like the POIs of an imported map. The blobs they point at are
+// referenced under the POI's own keys, so they stay until DeletePOIImages
+// releases them, whatever happens to the original POI. URLs of files that
+// aren't stored blobs, like external images, are left alone.
+func (ip *ImageProcessor) ReferencePOIImages(ctx context.Context, poiID, originalURL, thumbnailURL string) error {
+	referencer, ok := ip.storage.(BlobReferencer)
+	if !ok {
+		return nil
+	}
+
+	if blobKey := uploadKeyFromURL(originalURL); blobKey != "" {
+		key := fmt.Sprintf("pois/%s-original%s", poiID, strings.ToLower(filepath.Ext(blobKey)))
+		if _, err := referencer.ReferenceBlob(ctx, "", key, blobKey); err != nil {
+			return fmt.Errorf("failed to reference original image: %w", err)
+		}
+	}
+
+	if blobKey := uploadKeyFromURL(thumbnailURL); blobKey != "" {
+		key := fmt.Sprintf("pois/%s-thumb.jpg", poiID)
+		if _, err := referencer.ReferenceBlob(ctx, "", key, blobKey); err != nil {
+			_ = ip.DeletePOIImages(ctx, poiID)
+			return fmt.Errorf("failed to reference thumbnail: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // DeletePOIImages deletes both original and thumbnail images for a POI
 func (ip *ImageProcessor) DeletePOIImages(ctx context.Context, poiID string) error {
 	// Try to delete both original and thumbnail
 	// We don't know the original extension, so we try common ones
-	extensions := []string{".jpg", ".jpeg", ".png", ".webp"}
-	
 	var lastErr error
-	for _, ext := range extensions {
+	for _, ext := range poiImageExtensions {
 		originalKey := fmt.Sprintf("pois/%s-original%s", poiID, ext)
 		if err := ip.storage.DeleteFile(ctx, originalKey); err != nil {
 			lastErr = err
@@ -153,3 +190,12 @@ func (ip *ImageProcessor) DeletePOIImages(ctx context.Context, poiID string) err
 	// Return last error if any (non-critical since files might not exist)
 	return lastErr
 }
+
+// uploadKeyFromURL returns the storage key of a URL served from the uploads
+// path, or "" for other URLs
+func uploadKeyFromURL(url string) string {
+	if idx := strings.Index(url, "/uploads/"); idx != -1 {
+		return url[idx+len("/uploads/"):]
+	}
+	return ""
+}
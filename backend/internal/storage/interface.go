@@ -1,6 +1,9 @@
 package storage
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // FileStorage defines the interface for file storage operations
 type FileStorage interface {
@@ -11,6 +14,30 @@ type FileStorage interface {
 	GenerateUniqueKey(prefix, userID, originalFilename string) string
 }
 
+// OwnedFileStorage is implemented by storages that record who uploaded a
+// file, so files shared between owners can be released by one of them
+type OwnedFileStorage interface {
+	UploadOwnedFile(ctx context.Context, ownerID, key string, data []byte, contentType string) (string, error)
+	DeleteOwnedFile(ctx context.Context, ownerID, key string) error
+}
+
+// BlobReferencer is implemented by storages that share blobs between keys,
+// so a stored blob can be referenced by another key without copying it
+type BlobReferencer interface {
+	ReferenceBlob(ctx context.Context, ownerID, key, blobKey string) (bool, error)
+}
+
+// StoredFile describes a file held in storage
+type StoredFile struct {
+	Key        string
+	ModifiedAt time.Time
+}
+
+// FileLister is implemented by storages that can enumerate the files they hold
+type FileLister interface {
+	ListFiles(ctx context.Context, prefix string) ([]StoredFile, error)
+}
+
 // NewFileStorage creates a new file storage instance based on configuration
 func NewFileStorage(config StorageConfig) FileStorage {
 	// For now, we only support local storage
@@ -74,6 +74,43 @@ func (l *LocalFileStorage) FileExists(key string) bool {
 	return err == nil
 }
 
+// ListFiles returns every stored file whose key starts with prefix
+func (l *LocalFileStorage) ListFiles(ctx context.Context, prefix string) ([]StoredFile, error) {
+	var files []StoredFile
+	err := filepath.WalkDir(l.config.UploadPath, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		
+		rel, err := filepath.Rel(l.config.UploadPath, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, StoredFile{Key: key, ModifiedAt: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %w", err)
+	}
+	
+	return files, nil
+}
+
 // GenerateUniqueKey generates a unique file key with timestamp
 func (l *LocalFileStorage) GenerateUniqueKey(prefix, userID, originalFilename string) string {
 	ext := filepath.Ext(originalFilename)
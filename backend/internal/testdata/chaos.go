@@ -0,0 +1,280 @@
+package testdata
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// ErrInjectedFault is returned for faults injected without a specific error
+var ErrInjectedFault = errors.New("chaos: injected fault")
+
+// ErrInjectedDisconnect is returned while a disconnection is being simulated
+var ErrInjectedDisconnect = errors.New("chaos: connection lost")
+
+// Fault describes a failure to inject into the Redis or database layer
+type Fault struct {
+	// Latency is added before the operation runs
+	Latency time.Duration
+	// Err is returned instead of running the operation
+	Err error
+	// Disconnect simulates a dropped connection: operations and new dials fail
+	Disconnect bool
+	// Operations limits the fault to these operations (Redis command names such
+	// as "sadd", or GORM operations: create, query, update, delete, row, raw).
+	// Empty means all operations.
+	Operations []string
+	// Times limits how often the fault triggers; 0 means until cleared
+	Times int
+}
+
+// FaultInjector injects faults into Redis clients and GORM connections.
+// Faults can be toggled at any point during a test, so resilience behavior
+// (degraded mode, retries, outbox) can be verified mid-flow.
+type FaultInjector struct {
+	mutex     sync.Mutex
+	faults    []*activeFault
+	triggered int
+}
+
+type activeFault struct {
+	Fault
+	remaining int
+}
+
+// NewFaultInjector creates a fault injector with no active faults
+func NewFaultInjector() *FaultInjector {
+	return &FaultInjector{}
+}
+
+// Inject activates a fault
+func (f *FaultInjector) Inject(fault Fault) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.faults = append(f.faults, &activeFault{Fault: fault, remaining: fault.Times})
+}
+
+// InjectLatency delays matching operations by the given duration
+func (f *FaultInjector) InjectLatency(latency time.Duration, operations ...string) {
+	f.Inject(Fault{Latency: latency, Operations: operations})
+}
+
+// InjectError makes matching operations fail with err
+func (f *FaultInjector) InjectError(err error, operations ...string) {
+	if err == nil {
+		err = ErrInjectedFault
+	}
+	f.Inject(Fault{Err: err, Operations: operations})
+}
+
+// InjectDisconnect makes matching operations fail as if the connection dropped
+func (f *FaultInjector) InjectDisconnect(operations ...string) {
+	f.Inject(Fault{Disconnect: true, Operations: operations})
+}
+
+// Clear removes all active faults
+func (f *FaultInjector) Clear() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.faults = nil
+}
+
+// Triggered returns how many operations were affected by injected faults
+func (f *FaultInjector) Triggered() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.triggered
+}
+
+// apply runs the faults matching operation, sleeping for injected latency and
+// returning the injected error, if any
+func (f *FaultInjector) apply(ctx context.Context, operation string, disconnectErr error) error {
+	latency, err := f.match(operation, disconnectErr)
+
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return err
+}
+
+func (f *FaultInjector) match(operation string, disconnectErr error) (time.Duration, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var latency time.Duration
+	var err error
+	matched := false
+
+	active := f.faults[:0]
+	for _, fault := range f.faults {
+		if !fault.matches(operation) {
+			active = append(active, fault)
+			continue
+		}
+
+		matched = true
+		latency += fault.Latency
+		if err == nil {
+			if fault.Disconnect {
+				err = disconnectErr
+			} else if fault.Err != nil {
+				err = fault.Err
+			}
+		}
+
+		if fault.Times > 0 {
+			fault.remaining--
+			if fault.remaining <= 0 {
+				continue
+			}
+		}
+		active = append(active, fault)
+	}
+	f.faults = active
+
+	if matched {
+		f.triggered++
+	}
+
+	return latency, err
+}
+
+func (fault *activeFault) matches(operation string) bool {
+	if len(fault.Operations) == 0 {
+		return true
+	}
+	for _, op := range fault.Operations {
+		if strings.EqualFold(op, operation) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedisHook returns a go-redis hook that applies the injector's faults
+func (f *FaultInjector) RedisHook() redis.Hook {
+	return &redisFaultHook{injector: f}
+}
+
+type redisFaultHook struct {
+	injector *FaultInjector
+}
+
+func (h *redisFaultHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if err := h.injector.apply(ctx, "dial", ErrInjectedDisconnect); err != nil {
+			return nil, err
+		}
+		return next(ctx, network, addr)
+	}
+}
+
+func (h *redisFaultHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if err := h.injector.apply(ctx, cmd.Name(), ErrInjectedDisconnect); err != nil {
+			cmd.SetErr(err)
+			return err
+		}
+		return next(ctx, cmd)
+	}
+}
+
+func (h *redisFaultHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		for _, cmd := range cmds {
+			if err := h.injector.apply(ctx, cmd.Name(), ErrInjectedDisconnect); err != nil {
+				for _, c := range cmds {
+					c.SetErr(err)
+				}
+				return err
+			}
+		}
+		return next(ctx, cmds)
+	}
+}
+
+// gormOperations are the GORM callback processors faults can be injected into
+var gormOperations = []string{"create", "query", "update", "delete", "row", "raw"}
+
+// GormPlugin returns a GORM plugin that applies the injector's faults
+func (f *FaultInjector) GormPlugin() gorm.Plugin {
+	return &gormFaultPlugin{injector: f}
+}
+
+type gormFaultPlugin struct {
+	injector *FaultInjector
+}
+
+func (p *gormFaultPlugin) Name() string {
+	return "chaos:fault_injector"
+}
+
+func (p *gormFaultPlugin) Initialize(db *gorm.DB) error {
+	for _, operation := range gormOperations {
+		if err := p.register(db, operation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *gormFaultPlugin) register(db *gorm.DB, operation string) error {
+	callback := func(tx *gorm.DB) {
+		ctx := tx.Statement.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		// driver.ErrBadConn is what database/sql reports for dropped connections
+		if err := p.injector.apply(ctx, operation, driver.ErrBadConn); err != nil {
+			tx.AddError(err)
+		}
+	}
+
+	name := "chaos:before_" + operation
+	callbacks := db.Callback()
+	switch operation {
+	case "create":
+		return callbacks.Create().Before("gorm:create").Register(name, callback)
+	case "query":
+		return callbacks.Query().Before("gorm:query").Register(name, callback)
+	case "update":
+		return callbacks.Update().Before("gorm:update").Register(name, callback)
+	case "delete":
+		return callbacks.Delete().Before("gorm:delete").Register(name, callback)
+	case "row":
+		return callbacks.Row().Before("gorm:row").Register(name, callback)
+	default:
+		return callbacks.Raw().Before("gorm:raw").Register(name, callback)
+	}
+}
+
+// EnableChaos attaches a fault injector to the test database connection
+func (tdb *TestDB) EnableChaos() *FaultInjector {
+	injector := NewFaultInjector()
+	if err := tdb.DB.Use(injector.GormPlugin()); err != nil {
+		tdb.t.Errorf("Failed to enable database fault injection: %v", err)
+	}
+	return injector
+}
+
+// EnableChaos attaches a fault injector to the test Redis client
+func (tr *TestRedis) EnableChaos() *FaultInjector {
+	injector := NewFaultInjector()
+	tr.Client().AddHook(injector.RedisHook())
+	return injector
+}
@@ -0,0 +1,96 @@
+package testdata
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestFaultInjector_MatchesOperations(t *testing.T) {
+	injector := NewFaultInjector()
+	injector.InjectError(nil, "create")
+
+	assert.NoError(t, injector.apply(context.Background(), "query", ErrInjectedDisconnect))
+	assert.ErrorIs(t, injector.apply(context.Background(), "CREATE", ErrInjectedDisconnect), ErrInjectedFault)
+	assert.Equal(t, 1, injector.Triggered())
+
+	injector.Clear()
+	assert.NoError(t, injector.apply(context.Background(), "create", ErrInjectedDisconnect))
+}
+
+func TestFaultInjector_Times(t *testing.T) {
+	injector := NewFaultInjector()
+	injector.Inject(Fault{Disconnect: true, Times: 2})
+
+	assert.ErrorIs(t, injector.apply(context.Background(), "get", ErrInjectedDisconnect), ErrInjectedDisconnect)
+	assert.ErrorIs(t, injector.apply(context.Background(), "get", ErrInjectedDisconnect), ErrInjectedDisconnect)
+	assert.NoError(t, injector.apply(context.Background(), "get", ErrInjectedDisconnect))
+	assert.Equal(t, 2, injector.Triggered())
+}
+
+func TestFaultInjector_LatencyHonorsContext(t *testing.T) {
+	injector := NewFaultInjector()
+	injector.InjectLatency(time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, injector.apply(ctx, "get", ErrInjectedDisconnect), context.DeadlineExceeded)
+}
+
+func TestFaultInjector_RedisHook(t *testing.T) {
+	// Nothing listens on this address; injected faults fail before dialing
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	defer client.Close()
+
+	injector := NewFaultInjector()
+	client.AddHook(injector.RedisHook())
+
+	injected := errors.New("redis unavailable")
+	injector.InjectError(injected, "sadd")
+
+	err := client.SAdd(context.Background(), "poi:participants", "user-1").Err()
+	assert.ErrorIs(t, err, injected)
+
+	injector.Clear()
+	injector.InjectDisconnect()
+
+	pipe := client.Pipeline()
+	pipe.Get(context.Background(), "key")
+	_, err = pipe.Exec(context.Background())
+	assert.ErrorIs(t, err, ErrInjectedDisconnect)
+}
+
+func TestFaultInjector_GormPlugin(t *testing.T) {
+	// The connection is never used: injected errors stop GORM before it queries
+	db, err := gorm.Open(postgres.New(postgres.Config{
+		DSN: "host=127.0.0.1 port=1 user=postgres dbname=chaos sslmode=disable",
+	}), &gorm.Config{DisableAutomaticPing: true})
+	require.NoError(t, err)
+
+	injector := NewFaultInjector()
+	require.NoError(t, db.Use(injector.GormPlugin()))
+
+	injected := errors.New("database unavailable")
+	injector.InjectError(injected, "query")
+
+	var poi models.POI
+	assert.ErrorIs(t, db.First(&poi, "id = ?", "poi-1").Error, injected)
+
+	injector.Clear()
+	injector.InjectDisconnect("create")
+
+	err = db.Create(&models.POI{ID: "poi-1"}).Error
+	assert.ErrorIs(t, err, driver.ErrBadConn)
+	assert.Equal(t, 2, injector.Triggered())
+}
@@ -0,0 +1,149 @@
+package testdata
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// TestContainers holds the Postgres and Redis containers started for a test package
+type TestContainers struct {
+	postgres testcontainers.Container
+	redis    testcontainers.Container
+}
+
+// ContainersEnabled reports whether integration tests should start their own
+// infrastructure instead of relying on pre-provisioned Postgres and Redis
+func ContainersEnabled() bool {
+	return os.Getenv("TEST_CONTAINERS") != ""
+}
+
+// RunWithContainers runs the tests of a package, starting Postgres and Redis
+// containers first when TEST_CONTAINERS is set. The container addresses are
+// exported through the TEST_DB_* and TEST_REDIS_* environment variables, so
+// Setup, SetupRedis and SetupFlowTest work unchanged.
+//
+// Usage:
+//
+//	func TestMain(m *testing.M) {
+//		os.Exit(testdata.RunWithContainers(m))
+//	}
+func RunWithContainers(m *testing.M) int {
+	if !ContainersEnabled() {
+		return m.Run()
+	}
+
+	ctx := context.Background()
+	containers, err := StartContainers(ctx)
+	if err != nil {
+		fmt.Printf("Failed to start test containers: %v\n", err)
+		return 1
+	}
+	defer containers.Terminate(ctx)
+
+	return m.Run()
+}
+
+// StartContainers starts Postgres and Redis containers and points the test
+// environment variables at them
+func StartContainers(ctx context.Context) (*TestContainers, error) {
+	config := DefaultTestDBConfig()
+
+	postgres, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        getEnvOrDefault("TEST_POSTGRES_IMAGE", "postgres:15-alpine"),
+			ExposedPorts: []string{"5432/tcp"},
+			Env: map[string]string{
+				"POSTGRES_USER":     config.User,
+				"POSTGRES_PASSWORD": config.Password,
+				"POSTGRES_DB":       "postgres",
+			},
+			WaitingFor: wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2).
+				WithStartupTimeout(60 * time.Second),
+		},
+		Started: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start postgres container: %w", err)
+	}
+
+	containers := &TestContainers{postgres: postgres}
+
+	redis, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        getEnvOrDefault("TEST_REDIS_IMAGE", "redis:7-alpine"),
+			ExposedPorts: []string{"6379/tcp"},
+			WaitingFor:   wait.ForLog("Ready to accept connections").WithStartupTimeout(30 * time.Second),
+		},
+		Started: true,
+	})
+	if err != nil {
+		containers.Terminate(ctx)
+		return nil, fmt.Errorf("failed to start redis container: %w", err)
+	}
+	containers.redis = redis
+
+	if err := containers.exportEnv(ctx); err != nil {
+		containers.Terminate(ctx)
+		return nil, err
+	}
+
+	return containers, nil
+}
+
+// Terminate stops and removes the containers
+func (tc *TestContainers) Terminate(ctx context.Context) {
+	if tc.redis != nil {
+		if err := tc.redis.Terminate(ctx); err != nil {
+			fmt.Printf("Warning: Failed to terminate redis container: %v\n", err)
+		}
+	}
+	if tc.postgres != nil {
+		if err := tc.postgres.Terminate(ctx); err != nil {
+			fmt.Printf("Warning: Failed to terminate postgres container: %v\n", err)
+		}
+	}
+}
+
+// exportEnv sets the TEST_DB_* and TEST_REDIS_* variables to the container addresses
+func (tc *TestContainers) exportEnv(ctx context.Context) error {
+	dbHost, err := tc.postgres.Host(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get postgres host: %w", err)
+	}
+	dbPort, err := tc.postgres.MappedPort(ctx, "5432/tcp")
+	if err != nil {
+		return fmt.Errorf("failed to get postgres port: %w", err)
+	}
+
+	redisHost, err := tc.redis.Host(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get redis host: %w", err)
+	}
+	redisPort, err := tc.redis.MappedPort(ctx, "6379/tcp")
+	if err != nil {
+		return fmt.Errorf("failed to get redis port: %w", err)
+	}
+
+	env := map[string]string{
+		"TEST_DB_HOST":        dbHost,
+		"TEST_DB_PORT":        dbPort.Port(),
+		"TEST_DB_SSLMODE":     "disable",
+		"TEST_REDIS_HOST":     redisHost,
+		"TEST_REDIS_PORT":     redisPort.Port(),
+		"TEST_REDIS_PASSWORD": "",
+	}
+	for key, value := range env {
+		if err := os.Setenv(key, value); err != nil {
+			return fmt.Errorf("failed to set %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
@@ -133,7 +133,7 @@ func (e *POIGetExpectation) ReturnsNotFound() {
 	e.mock.On("GetPOI",
 		mock.Anything,
 		e.poiID,
-	).Return(nil, fmt.Errorf("POI not found: %s", e.poiID))
+	).Return(nil, fmt.Errorf("POI %w: %s", services.ErrNotFound, e.poiID))
 }
 
 // ReturnsError sets up the mock to return a custom error
@@ -436,6 +436,11 @@ func (m *MockPOIService) GetUserPOIs(ctx context.Context, userID string) ([]stri
 	return args.Get(0).([]string), args.Error(1)
 }
 
+func (m *MockPOIService) GetCurrentPOI(ctx context.Context, userID string) (string, error) {
+	args := m.Called(ctx, userID)
+	return args.String(0), args.Error(1)
+}
+
 func (m *MockPOIService) ValidatePOI(ctx context.Context, poiID string) (*models.POI, error) {
 	args := m.Called(ctx, poiID)
 	if args.Get(0) == nil {
@@ -711,6 +716,22 @@ func (m *MockUserService) UploadAvatar(ctx context.Context, userID string, filen
 	return args.Get(0).(*models.User), args.Error(1)
 }
 
+func (m *MockUserService) DeleteAvatar(ctx context.Context, userID string) (*models.User, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockUserService) UpdateRole(ctx context.Context, userID string, role models.UserRole) (*models.User, error) {
+	args := m.Called(ctx, userID, role)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
 func (m *MockUserService) UpdateProfile(ctx context.Context, userID string, req *services.UpdateProfileRequest) (*models.User, error) {
 	args := m.Called(ctx, userID, req)
 	if args.Get(0) == nil {
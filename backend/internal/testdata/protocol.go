@@ -0,0 +1,463 @@
+package testdata
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+	"breakoutglobe/internal/websocket"
+	"github.com/gin-gonic/gin"
+	ws "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/mock"
+)
+
+// Fixed identities used by protocol fixtures, so golden files can refer to them literally
+const (
+	ProtocolMapID         = "protocol-map"
+	ProtocolSenderSession = "sender-session"
+	ProtocolSenderUser    = "sender-user"
+	ProtocolPeerSession   = "peer-session"
+	ProtocolPeerUser      = "peer-user"
+	ProtocolPOIID         = "protocol-poi"
+
+	// ProtocolAnyValue matches any value in a fixture expectation (e.g. server timestamps)
+	ProtocolAnyValue = "<any>"
+)
+
+// protocolQuietPeriod is how long a connection must stay silent before the
+// messages caused by a request are considered complete
+const protocolQuietPeriod = 150 * time.Millisecond
+
+// ProtocolFixture is a golden WebSocket exchange: one request sent by the
+// sender and every message the sender and the peer receive in response
+type ProtocolFixture struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Request     ProtocolMessage  `json:"request"`
+	Expect      ProtocolExpected `json:"expect"`
+
+	path string
+}
+
+// ProtocolExpected holds the ordered messages each client should receive
+type ProtocolExpected struct {
+	Sender []ProtocolMessage `json:"sender"`
+	Peer   []ProtocolMessage `json:"peer"`
+}
+
+// ProtocolMessage is a WebSocket message without its timestamp
+type ProtocolMessage struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data"`
+	// Lane is the priority lane of map broadcasts; sequence numbers aren't
+	// recorded since they depend on what a connection received before
+	Lane string `json:"lane,omitempty"`
+}
+
+// LoadProtocolFixtures loads all *.json fixtures in dir, sorted by file name
+func LoadProtocolFixtures(dir string) ([]*ProtocolFixture, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list protocol fixtures: %w", err)
+	}
+	sort.Strings(paths)
+
+	fixtures := make([]*ProtocolFixture, 0, len(paths))
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read protocol fixture %s: %w", path, err)
+		}
+
+		var fixture ProtocolFixture
+		if err := json.Unmarshal(content, &fixture); err != nil {
+			return nil, fmt.Errorf("failed to parse protocol fixture %s: %w", path, err)
+		}
+		if fixture.Name == "" {
+			fixture.Name = strings.TrimSuffix(filepath.Base(path), ".json")
+		}
+		fixture.path = path
+		fixtures = append(fixtures, &fixture)
+	}
+
+	return fixtures, nil
+}
+
+// UpdateGoldenEnabled reports whether fixture expectations should be rewritten
+// from the actual server output instead of being asserted
+func UpdateGoldenEnabled() bool {
+	return os.Getenv("UPDATE_GOLDEN") != ""
+}
+
+// ProtocolScenario runs protocol fixtures against a real WebSocket handler
+// backed by permissive mocks. Two clients, a sender and a peer, are connected
+// to the same map for every fixture.
+type ProtocolScenario struct {
+	mockSetup *MockSetup
+	handler   *websocket.Handler
+	server    *httptest.Server
+	wsURL     string
+}
+
+// NewProtocolScenario creates a protocol scenario with deterministic sessions and users
+func NewProtocolScenario() *ProtocolScenario {
+	mockSetup := NewMockSetup()
+
+	scenario := &ProtocolScenario{mockSetup: mockSetup}
+	scenario.setupMocks()
+
+	scenario.handler = websocket.NewHandler(
+		mockSetup.SessionService.Mock(),
+		mockSetup.RateLimiter.Mock(),
+		mockSetup.UserService.Mock(),
+		mockSetup.POIService.Mock(),
+	)
+	scenario.handler.SetSessionTokens(SessionTokens)
+	// Announce speaker changes immediately so fixtures see them without waiting
+	scenario.handler.SetSpeakerDebounce(0)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	scenario.handler.RegisterRoutes(router, nil)
+
+	scenario.server = httptest.NewServer(router)
+	scenario.wsURL = "ws" + strings.TrimPrefix(scenario.server.URL, "http") + "/ws"
+
+	return scenario
+}
+
+// Handler returns the WebSocket handler so tests can attach optional collaborators
+func (s *ProtocolScenario) Handler() *websocket.Handler {
+	return s.handler
+}
+
+// setupMocks makes every service call succeed with stable data
+func (s *ProtocolScenario) setupMocks() {
+	sessions := map[string]*models.Session{
+		ProtocolSenderSession: protocolSession(ProtocolSenderSession, ProtocolSenderUser, "Sender", models.LatLng{Lat: 52.52, Lng: 13.405}),
+		ProtocolPeerSession:   protocolSession(ProtocolPeerSession, ProtocolPeerUser, "Peer", models.LatLng{Lat: 48.8566, Lng: 2.3522}),
+	}
+	for sessionID, session := range sessions {
+		s.mockSetup.SessionService.Mock().On("GetSession", mock.Anything, sessionID).Return(session, nil)
+		s.mockSetup.UserService.Mock().On("GetUser", mock.Anything, session.UserID).Return(session.User, nil)
+	}
+
+	s.mockSetup.SessionService.Mock().On("SessionHeartbeat", mock.Anything, mock.Anything).Return(nil)
+	s.mockSetup.SessionService.Mock().On("UpdateAvatarPosition", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	s.mockSetup.RateLimiter.Mock().On("CheckRateLimit", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	s.mockSetup.POIService.Mock().On("JoinPOI", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	s.mockSetup.POIService.Mock().On("LeavePOI", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	s.mockSetup.POIService.Mock().On("GetPOIParticipantsWithInfo", mock.Anything, mock.Anything).Return([]services.POIParticipantInfo{
+		{ID: ProtocolSenderUser, Name: "Sender"},
+	}, nil)
+	s.mockSetup.POIService.Mock().On("GetPOIParticipantCount", mock.Anything, mock.Anything).Return(1, nil)
+	s.mockSetup.POIService.Mock().On("GetCurrentPOI", mock.Anything, mock.Anything).Return("", nil)
+	s.mockSetup.POIService.Mock().On("GetPOIsForMap", mock.Anything, ProtocolMapID).Return([]*models.POI{
+		{ID: "protocol-poi", MapID: ProtocolMapID, Name: "Coffee", Position: models.LatLng{Lat: 52.52, Lng: 13.405}, CreatedBy: ProtocolPeerUser, MaxParticipants: 10},
+	}, nil)
+}
+
+func protocolSession(sessionID, userID, displayName string, position models.LatLng) *models.Session {
+	return &models.Session{
+		ID:        sessionID,
+		UserID:    userID,
+		MapID:     ProtocolMapID,
+		AvatarPos: position,
+		IsActive:  true,
+		User: &models.User{
+			ID:          userID,
+			DisplayName: displayName,
+		},
+	}
+}
+
+// Run sends the fixture request from the sender and asserts the messages both
+// clients receive. With UPDATE_GOLDEN set, the fixture file is rewritten instead.
+func (s *ProtocolScenario) Run(t TestingT, fixture *ProtocolFixture) {
+	t.Helper()
+
+	sender := s.connect(t, ProtocolSenderSession)
+	if sender == nil {
+		return
+	}
+	defer sender.close()
+	if !sender.waitFor(t, "welcome", "initial_users") {
+		return
+	}
+
+	peer := s.connect(t, ProtocolPeerSession)
+	if peer == nil {
+		return
+	}
+	defer peer.close()
+	if !peer.waitFor(t, "welcome", "initial_users") || !sender.waitFor(t, "user_joined") {
+		return
+	}
+
+	if err := sender.conn.WriteJSON(fixture.Request); err != nil {
+		t.Errorf("Failed to send %s request: %v", fixture.Request.Type, err)
+		return
+	}
+
+	actual := ProtocolExpected{
+		Sender: sender.collect(),
+		Peer:   peer.collect(),
+	}
+
+	if UpdateGoldenEnabled() {
+		if err := fixture.writeGolden(actual); err != nil {
+			t.Errorf("Failed to update golden fixture %s: %v", fixture.Name, err)
+		}
+		return
+	}
+
+	assertProtocolMessages(t, fixture.Name, "sender", fixture.Expect.Sender, actual.Sender)
+	assertProtocolMessages(t, fixture.Name, "peer", fixture.Expect.Peer, actual.Peer)
+}
+
+// Cleanup closes the test server
+func (s *ProtocolScenario) Cleanup() {
+	if s.server != nil {
+		s.server.Close()
+	}
+}
+
+// protocolClient reads messages from a connection in the background
+type protocolClient struct {
+	conn     *ws.Conn
+	messages chan ProtocolMessage
+}
+
+func (s *ProtocolScenario) connect(t TestingT, sessionID string) *protocolClient {
+	t.Helper()
+
+	conn, _, err := ws.DefaultDialer.Dial(s.wsURL, SessionHeader(sessionID))
+	if err != nil {
+		t.Errorf("Failed to connect to WebSocket as %s: %v", sessionID, err)
+		return nil
+	}
+
+	client := &protocolClient{
+		conn:     conn,
+		messages: make(chan ProtocolMessage, 64),
+	}
+	go client.readLoop()
+
+	return client
+}
+
+func (c *protocolClient) readLoop() {
+	defer close(c.messages)
+	for {
+		var msg ProtocolMessage
+		if err := c.conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		c.messages <- msg
+	}
+}
+
+// waitFor consumes messages until each of the given types has been received in order
+func (c *protocolClient) waitFor(t TestingT, types ...string) bool {
+	t.Helper()
+
+	for _, expected := range types {
+		select {
+		case msg, ok := <-c.messages:
+			if !ok {
+				t.Errorf("Connection closed while waiting for %s", expected)
+				return false
+			}
+			if msg.Type != expected {
+				t.Errorf("Expected %s message, got %s", expected, msg.Type)
+				return false
+			}
+		case <-time.After(2 * time.Second):
+			t.Errorf("Timed out waiting for %s message", expected)
+			return false
+		}
+	}
+
+	return true
+}
+
+// collect returns all messages received until the connection goes quiet
+func (c *protocolClient) collect() []ProtocolMessage {
+	messages := []ProtocolMessage{}
+	for {
+		select {
+		case msg, ok := <-c.messages:
+			if !ok {
+				return messages
+			}
+			messages = append(messages, msg)
+		case <-time.After(protocolQuietPeriod):
+			return messages
+		}
+	}
+}
+
+func (c *protocolClient) close() {
+	c.conn.Close()
+}
+
+// assertProtocolMessages compares received messages with the fixture expectation
+func assertProtocolMessages(t TestingT, fixture, recipient string, expected, actual []ProtocolMessage) {
+	t.Helper()
+
+	if len(expected) != len(actual) {
+		t.Errorf("%s: %s expected %d messages %v, got %d %v",
+			fixture, recipient, len(expected), protocolTypes(expected), len(actual), protocolTypes(actual))
+		return
+	}
+
+	for i := range expected {
+		if expected[i].Type != actual[i].Type {
+			t.Errorf("%s: %s message %d expected type %s, got %s",
+				fixture, recipient, i, expected[i].Type, actual[i].Type)
+			continue
+		}
+		if expected[i].Lane != actual[i].Lane {
+			t.Errorf("%s: %s %s expected lane %q, got %q",
+				fixture, recipient, actual[i].Type, expected[i].Lane, actual[i].Lane)
+		}
+		if !protocolValueMatches(normalizeProtocolValue(expected[i].Data), normalizeProtocolValue(actual[i].Data)) {
+			expectedJSON, _ := json.Marshal(expected[i].Data)
+			actualJSON, _ := json.Marshal(actual[i].Data)
+			t.Errorf("%s: %s %s data mismatch\nexpected: %s\nactual:   %s",
+				fixture, recipient, actual[i].Type, expectedJSON, actualJSON)
+		}
+	}
+}
+
+// protocolValueMatches compares decoded JSON values, honoring ProtocolAnyValue
+func protocolValueMatches(expected, actual interface{}) bool {
+	if expected == ProtocolAnyValue {
+		return true
+	}
+
+	switch exp := expected.(type) {
+	case map[string]interface{}:
+		act, ok := actual.(map[string]interface{})
+		if !ok || len(exp) != len(act) {
+			return false
+		}
+		for key, value := range exp {
+			actualValue, exists := act[key]
+			if !exists || !protocolValueMatches(value, actualValue) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		act, ok := actual.([]interface{})
+		if !ok || len(exp) != len(act) {
+			return false
+		}
+		for i := range exp {
+			if !protocolValueMatches(exp[i], act[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(expected, actual)
+	}
+}
+
+// normalizeProtocolValue round-trips a value through JSON so values built in
+// Go and values decoded from fixtures compare the same way
+func normalizeProtocolValue(value interface{}) interface{} {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(encoded, &normalized); err != nil {
+		return value
+	}
+	return normalized
+}
+
+// keepProtocolWildcards copies ProtocolAnyValue markers from the old
+// expectation into the new one, so regenerated fixtures stay stable
+func keepProtocolWildcards(old, updated interface{}) interface{} {
+	if old == ProtocolAnyValue {
+		return ProtocolAnyValue
+	}
+
+	switch o := old.(type) {
+	case map[string]interface{}:
+		u, ok := updated.(map[string]interface{})
+		if !ok {
+			return updated
+		}
+		for key, value := range u {
+			if oldValue, exists := o[key]; exists {
+				u[key] = keepProtocolWildcards(oldValue, value)
+			}
+		}
+		return u
+	case []interface{}:
+		u, ok := updated.([]interface{})
+		if !ok {
+			return updated
+		}
+		for i := range u {
+			if i < len(o) {
+				u[i] = keepProtocolWildcards(o[i], u[i])
+			}
+		}
+		return u
+	default:
+		return updated
+	}
+}
+
+func mergeProtocolMessages(old, updated []ProtocolMessage) []ProtocolMessage {
+	merged := make([]ProtocolMessage, len(updated))
+	for i, msg := range updated {
+		data := normalizeProtocolValue(msg.Data)
+		if i < len(old) && old[i].Type == msg.Type {
+			data = keepProtocolWildcards(normalizeProtocolValue(old[i].Data), data)
+		}
+		merged[i] = ProtocolMessage{Type: msg.Type, Data: data, Lane: msg.Lane}
+	}
+	return merged
+}
+
+// writeGolden replaces the fixture expectation with the actual messages
+func (f *ProtocolFixture) writeGolden(actual ProtocolExpected) error {
+	f.Expect = ProtocolExpected{
+		Sender: mergeProtocolMessages(f.Expect.Sender, actual.Sender),
+		Peer:   mergeProtocolMessages(f.Expect.Peer, actual.Peer),
+	}
+
+	// Keep the <any> wildcard readable instead of HTML-escaping it
+	var content bytes.Buffer
+	encoder := json.NewEncoder(&content)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(f); err != nil {
+		return fmt.Errorf("failed to encode fixture: %w", err)
+	}
+	return os.WriteFile(f.path, content.Bytes(), 0644)
+}
+
+func protocolTypes(messages []ProtocolMessage) []string {
+	types := make([]string, len(messages))
+	for i, msg := range messages {
+		types[i] = msg.Type
+	}
+	return types
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"breakoutglobe/internal/handlers"
+	"breakoutglobe/internal/middleware"
 	"breakoutglobe/internal/models"
 	"breakoutglobe/internal/services"
 	"breakoutglobe/internal/websocket"
@@ -20,6 +21,17 @@ import (
 	"gorm.io/gorm"
 )
 
+// SessionTokens signs the session tokens of the handlers in test scenarios
+var SessionTokens = services.NewSessionTokens([]byte("test-session-secret"))
+
+// SessionHeader returns the headers of a WebSocket upgrade in the session
+func SessionHeader(sessionID string) http.Header {
+	header := http.Header{}
+	header.Set(middleware.SessionHeader, sessionID)
+	header.Set(middleware.SessionTokenHeader, SessionTokens.Issue(sessionID))
+	return header
+}
+
 // Request/Response types for POI testing
 type CreatePOIRequest struct {
 	MapID           string         `json:"mapId"`
@@ -270,7 +282,7 @@ func (s *POITestScenario) ExpectDuplicateLocation() *POITestScenario {
 		mock.AnythingOfType("models.LatLng"), // position
 		s.userID.String(),
 		mock.AnythingOfType("int"), // maxParticipants
-	).Return((*models.POI)(nil), fmt.Errorf("duplicate POI location"))
+	).Return((*models.POI)(nil), fmt.Errorf("%w: a POI already exists at this location", services.ErrDuplicateLocation))
 	
 	return s
 }
@@ -324,13 +336,13 @@ func (s *POITestScenario) ExpectPOINotFound() *POITestScenario {
 		mock.Anything,
 		mock.AnythingOfType("string"),
 		s.userID.String(),
-	).Return(gorm.ErrRecordNotFound)
+	).Return(services.ErrNotFound)
 	
 	s.mockSetup.POIService.Mock().On("LeavePOI",
 		mock.Anything,
 		mock.AnythingOfType("string"),
 		s.userID.String(),
-	).Return(gorm.ErrRecordNotFound)
+	).Return(services.ErrNotFound)
 	
 	return s
 }
@@ -364,7 +376,7 @@ func (s *POITestScenario) ExpectCapacityExceeded() *POITestScenario {
 		mock.Anything, 
 		mock.AnythingOfType("string"),
 		s.userID.String(),
-	).Return(fmt.Errorf("POI capacity exceeded"))
+	).Return(fmt.Errorf("%w: POI is at maximum capacity", services.ErrCapacityExceeded))
 	
 	return s
 }
@@ -375,7 +387,7 @@ func (s *POITestScenario) ExpectNotFound() *POITestScenario {
 		mock.Anything, 
 		mock.AnythingOfType("string"),
 		s.userID.String(),
-	).Return(fmt.Errorf("POI not found"))
+	).Return(fmt.Errorf("POI %w", services.ErrNotFound))
 	
 	return s
 }
@@ -618,6 +630,7 @@ func NewSessionTestScenario() *SessionTestScenario {
 		mockSetup.SessionService.Mock(),
 		mockSetup.RateLimiter.Mock(),
 	)
+	scenario.handler.SetSessionTokens(SessionTokens)
 	
 	// Setup router
 	gin.SetMode(gin.TestMode)
@@ -873,6 +886,7 @@ func (s *SessionTestScenario) UpdateAvatarPosition(t TestingT, sessionID string,
 	url := fmt.Sprintf("/api/sessions/%s/avatar", sessionID)
 	req := httptest.NewRequest(http.MethodPut, url, bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.SessionTokenHeader, SessionTokens.Issue(sessionID))
 	recorder := httptest.NewRecorder()
 	
 	// Execute request
@@ -887,6 +901,7 @@ func (s *SessionTestScenario) SessionHeartbeat(t TestingT, sessionID string) *ht
 	
 	url := fmt.Sprintf("/api/sessions/%s/heartbeat", sessionID)
 	req := httptest.NewRequest(http.MethodPost, url, nil)
+	req.Header.Set(middleware.SessionTokenHeader, SessionTokens.Issue(sessionID))
 	recorder := httptest.NewRecorder()
 	
 	// Execute request
@@ -958,11 +973,12 @@ func NewWebSocketTestScenario() *WebSocketTestScenario {
 		mockSetup.UserService.Mock(),
 		mockSetup.POIService.Mock(),
 	)
+	scenario.handler.SetSessionTokens(SessionTokens)
 	
 	// Setup HTTP server with WebSocket endpoint
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	router.GET("/ws", scenario.handler.HandleWebSocket)
+	scenario.handler.RegisterRoutes(router, nil)
 	
 	scenario.server = httptest.NewServer(router)
 	scenario.wsURL = "ws" + strings.TrimPrefix(scenario.server.URL, "http") + "/ws"
@@ -1068,12 +1084,8 @@ func (s *WebSocketTestScenario) ExpectAvatarMoveRateLimited() *WebSocketTestScen
 func (s *WebSocketTestScenario) Connect(t TestingT) (*ws.Conn, *WebSocketMessage) {
 	t.Helper()
 	
-	// Setup authorization header
-	header := http.Header{}
-	header.Set("Authorization", fmt.Sprintf("Bearer %s", s.sessionID))
-	
 	// Connect to WebSocket
-	conn, _, err := ws.DefaultDialer.Dial(s.wsURL, header)
+	conn, _, err := ws.DefaultDialer.Dial(s.wsURL, SessionHeader(s.sessionID))
 	if err != nil {
 		t.Errorf("Failed to connect to WebSocket: %v", err)
 		return nil, nil
@@ -1094,12 +1106,8 @@ func (s *WebSocketTestScenario) Connect(t TestingT) (*ws.Conn, *WebSocketMessage
 func (s *WebSocketTestScenario) ConnectExpectingError(t TestingT) error {
 	t.Helper()
 	
-	// Setup authorization header
-	header := http.Header{}
-	header.Set("Authorization", fmt.Sprintf("Bearer %s", s.sessionID))
-	
 	// Try to connect to WebSocket
-	conn, resp, err := ws.DefaultDialer.Dial(s.wsURL, header)
+	conn, resp, err := ws.DefaultDialer.Dial(s.wsURL, SessionHeader(s.sessionID))
 	if conn != nil {
 		conn.Close()
 	}
@@ -101,6 +101,7 @@ func (tdb *TestDB) RunMigrations() error {
 		&models.Map{},     // Add Map model for relationships
 		&models.Session{},
 		&models.POI{},
+		&models.UploadReference{},
 		// Add other models as needed
 	)
 }
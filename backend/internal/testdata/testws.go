@@ -54,13 +54,14 @@ func SetupWebSocket(t TestingT) *TestWebSocket {
 
 	// Create WebSocket handler (it creates its own manager internally)
 	handler := websocket.NewHandler(sessionService, rateLimiter, userService, poiService)
+	handler.SetSessionTokens(SessionTokens)
 
 	// Setup Gin router
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 
 	// Add WebSocket endpoint
-	router.GET("/ws", handler.HandleWebSocket)
+	handler.RegisterRoutes(router, nil)
 
 	// Create test server
 	server := httptest.NewServer(router)
@@ -100,12 +101,8 @@ func (tws *TestWebSocket) CreateClient(sessionID, userID, mapID string) *TestWSC
 		return nil
 	}
 
-	// Create headers with Authorization Bearer token
-	headers := http.Header{}
-	headers.Set("Authorization", "Bearer "+sessionID)
-
 	// Create WebSocket connection with proper authentication
-	conn, _, err := ws.DefaultDialer.Dial(u.String(), headers)
+	conn, _, err := ws.DefaultDialer.Dial(u.String(), SessionHeader(sessionID))
 	if err != nil {
 		tws.t.Errorf("Failed to connect WebSocket: %v", err)
 		return nil
@@ -605,6 +602,21 @@ func (m *MockUserServiceForWS) UploadAvatar(ctx context.Context, userID string,
 	}, nil
 }
 
+func (m *MockUserServiceForWS) DeleteAvatar(ctx context.Context, userID string) (*models.User, error) {
+	return &models.User{
+		ID:          userID,
+		DisplayName: "Test User",
+	}, nil
+}
+
+func (m *MockUserServiceForWS) UpdateRole(ctx context.Context, userID string, role models.UserRole) (*models.User, error) {
+	return &models.User{
+		ID:          userID,
+		DisplayName: "Test User",
+		Role:        role,
+	}, nil
+}
+
 func (m *MockUserServiceForWS) UpdateProfile(ctx context.Context, userID string, req *services.UpdateProfileRequest) (*models.User, error) {
 	return &models.User{
 		ID:          userID,
@@ -671,6 +683,10 @@ func (m *MockPOIServiceForWS) GetUserPOIs(ctx context.Context, userID string) ([
 	return []string{}, nil
 }
 
+func (m *MockPOIServiceForWS) GetCurrentPOI(ctx context.Context, userID string) (string, error) {
+	return "", nil
+}
+
 func (m *MockPOIServiceForWS) ValidatePOI(ctx context.Context, poiID string) (*models.POI, error) {
 	return &models.POI{}, nil
 }
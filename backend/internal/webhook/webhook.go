@@ -0,0 +1,81 @@
+// Package webhook posts event notifications to an operator-configured URL.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// SignatureHeader carries the hex-encoded HMAC-SHA256 of the request body,
+// keyed with the shared secret, if one is configured
+const SignatureHeader = "X-BreakoutGlobe-Signature"
+
+const webhookTimeout = 10 * time.Second
+
+// Notification is the body of a webhook request
+type Notification struct {
+	Event     string      `json:"event"`
+	Data      interface{} `json:"data"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// Sender posts notifications as JSON to a webhook URL
+type Sender struct {
+	url    string
+	secret string
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewSender creates a sender posting to url. Requests are signed if secret is
+// set, so receivers can check they come from this deployment.
+func NewSender(url, secret string) *Sender {
+	return &Sender{
+		url:    url,
+		secret: secret,
+		client: &http.Client{Timeout: webhookTimeout},
+		now:    time.Now,
+	}
+}
+
+// Post sends an event with its data. Receivers must answer with a 2xx status.
+func (s *Sender) Post(ctx context.Context, event string, data interface{}) error {
+	body, err := json.Marshal(Notification{Event: event, Data: data, Timestamp: s.now().UTC()})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.secret != "" {
+		req.Header.Set(SignatureHeader, Sign(s.secret, body))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns the signature of a request body for SignatureHeader
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,52 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSender_PostSignsBody(t *testing.T) {
+	var received Notification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, Sign("secret", body), r.Header.Get(SignatureHeader))
+		require.NoError(t, json.Unmarshal(body, &received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(server.Close)
+
+	sender := NewSender(server.URL, "secret")
+	require.NoError(t, sender.Post(context.Background(), "usage.threshold_crossed", map[string]interface{}{"mapId": "map-1"}))
+
+	assert.Equal(t, "usage.threshold_crossed", received.Event)
+	assert.Equal(t, map[string]interface{}{"mapId": "map-1"}, received.Data)
+	assert.False(t, received.Timestamp.IsZero())
+}
+
+func TestSender_PostWithoutSecretIsUnsigned(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get(SignatureHeader))
+	}))
+	t.Cleanup(server.Close)
+
+	require.NoError(t, NewSender(server.URL, "").Post(context.Background(), "event", nil))
+}
+
+func TestSender_PostFailsOnErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	t.Cleanup(server.Close)
+
+	err := NewSender(server.URL, "").Post(context.Background(), "event", nil)
+	assert.ErrorContains(t, err, "status 502")
+}
@@ -0,0 +1,81 @@
+package websocket
+
+import (
+	"net/http"
+
+	"breakoutglobe/internal/middleware"
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// reconnectClaimsKey is the gin context key of the reconnect token an upgrade
+// was authenticated with
+const reconnectClaimsKey = "reconnectClaims"
+
+// RegisterRoutes serves WebSocket upgrades on /ws. Upgrades are authenticated
+// by a reconnect token or, like session-scoped REST routes, by
+// middleware.RequireSession; tokens validates the JWTs that give a connection
+// its user's role right away and may be nil.
+func (h *Handler) RegisterRoutes(router *gin.Engine, tokens middleware.AuthService) {
+	router.GET("/ws", h.rejectWhileDraining, h.ReconnectTokenAuth(), middleware.RequireSession(tokens, h.sessionService, h.sessionTokens), h.HandleWebSocket)
+}
+
+// SetSessionTokens sets the tokens clients prove they own the session they
+// connect with by, which must be the ones the session REST API issues. Call it
+// before RegisterRoutes.
+func (h *Handler) SetSessionTokens(tokens *services.SessionTokens) {
+	h.sessionTokens = tokens
+}
+
+// rejectWhileDraining answers upgrades with 503 while the server shuts down,
+// before any session is looked up, so clients reconnect to another instance
+func (h *Handler) rejectWhileDraining(c *gin.Context) {
+	if h.draining.Load() {
+		c.Header("Retry-After", "1")
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Server is shutting down"})
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
+// ReconnectTokenAuth middleware authenticates WebSocket upgrades carrying a
+// valid reconnect token from the last welcome, so auth middlewares chained
+// after it, like middleware.RequireSession, skip the session lookup. Other
+// upgrades pass through unauthenticated.
+func (h *Handler) ReconnectTokenAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session, claims := h.reconnectSession(c.Request.Context(), c.Query("reconnectToken"), c.Query("sessionId"))
+		if session != nil {
+			c.Set("userID", session.UserID)
+			c.Set("sessionID", session.ID)
+			c.Set("session", session)
+			c.Set(reconnectClaimsKey, claims)
+		}
+		c.Next()
+	}
+}
+
+// connectingSession returns the session an auth middleware resolved for a
+// WebSocket upgrade in the "session" context value and, if it reconnects with
+// a token, the token's claims. Upgrades without one are answered with 401 and
+// return nil.
+func (h *Handler) connectingSession(c *gin.Context) (*models.Session, *reconnectClaims) {
+	session, _ := c.Value("session").(*models.Session)
+	if session == nil {
+		h.logger.Warn("WebSocket connection failed: not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Session required"})
+		return nil, nil
+	}
+	claims, _ := c.Value(reconnectClaimsKey).(*reconnectClaims)
+	return session, claims
+}
+
+// tokenRole returns the role of the JWT an auth middleware validated for the
+// upgrade, or "" if the client connected without one
+func tokenRole(c *gin.Context) models.UserRole {
+	role, _ := c.Value("role").(models.UserRole)
+	return role
+}
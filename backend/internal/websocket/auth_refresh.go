@@ -0,0 +1,163 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"breakoutglobe/internal/services"
+)
+
+// DefaultAuthRefreshInterval is how often the sessions of connected clients
+// are revalidated
+const DefaultAuthRefreshInterval = time.Minute
+
+// TokenValidatorInterface defines the interface for validating the JWTs clients refresh their authentication with
+type TokenValidatorInterface interface {
+	ValidateJWT(token string) (*services.JWTClaims, error)
+}
+
+// AuthRefreshPayload is the data of auth_refresh messages. Signed in clients
+// send their current JWT, so the connection ends when it expires or is revoked
+// instead of outliving it.
+type AuthRefreshPayload struct {
+	Token string `json:"token,omitempty"`
+}
+
+// Validate accepts any payload; the token is checked when handling the message
+func (p AuthRefreshPayload) Validate() error {
+	return nil
+}
+
+// SetTokenValidator enables refreshing the authentication of a connection with
+// a JWT. Without it, auth_refresh only revalidates the session.
+func (h *Handler) SetTokenValidator(tokens TokenValidatorInterface) {
+	h.tokens = tokens
+}
+
+// validateAuthRefresh validates auth_refresh messages, which may come without data
+func validateAuthRefresh(msg Message) error {
+	if msg.Data == nil {
+		return nil
+	}
+	var payload AuthRefreshPayload
+	if err := decodePayload(msg, &payload); err != nil {
+		return err
+	}
+	return payload.Validate()
+}
+
+// handleAuthRefresh revalidates the client's session and, if sent, its JWT
+// right away. Clients still authenticated get auth_refreshed; others are
+// disconnected.
+func (h *Handler) handleAuthRefresh(ctx context.Context, client *Client, msg Message) {
+	var payload AuthRefreshPayload
+	if msg.Data != nil && !h.decodeMessagePayload(client, msg, &payload) {
+		return
+	}
+
+	if !h.revalidateSession(ctx, client) {
+		return
+	}
+
+	data := map[string]interface{}{
+		"sessionId": client.SessionID,
+	}
+	if payload.Token != "" {
+		if h.tokens == nil {
+			h.sendErrorMessage(ctx, client, "Token refresh is not supported")
+			return
+		}
+		claims, err := h.tokens.ValidateJWT(payload.Token)
+		if err == nil && claims.UserID != client.UserID {
+			err = fmt.Errorf("token of user %s", claims.UserID)
+		}
+		if err != nil {
+			h.expireSession(client, fmt.Errorf("token refresh failed: %w", err))
+			return
+		}
+		if claims.ExpiresAt != nil {
+			client.tokenExpiresAt.Store(claims.ExpiresAt.UnixNano())
+			data["tokenExpiresAt"] = claims.ExpiresAt.Time
+		}
+	}
+
+	h.withReconnectToken(client, data)
+
+	client.Send <- replyTo(ctx, Message{
+		Type:      "auth_refreshed",
+		Data:      data,
+		Timestamp: time.Now(),
+	})
+}
+
+// RefreshAuth revalidates the connected clients and disconnects those whose
+// session ended or whose last refreshed JWT expired, which other clients see
+// as user_left. Without it, connections outlive their sessions.
+func (h *Handler) RefreshAuth(ctx context.Context) error {
+	now := time.Now()
+	expired := 0
+	for _, client := range h.manager.connectedClients() {
+		if expiresAt := client.tokenExpiresAt.Load(); expiresAt != 0 && now.UnixNano() >= expiresAt {
+			h.expireSession(client, errors.New("token expired"))
+			expired++
+			continue
+		}
+		if !h.revalidateSession(ctx, client) {
+			expired++
+		}
+	}
+
+	if expired > 0 {
+		h.logger.Info("🔐 Disconnected clients with expired authentication", "clients", expired)
+	}
+	return nil
+}
+
+// revalidateSession checks that the client's session still exists and is
+// active, and disconnects the client otherwise. Sessions that can't be read
+// right now count as valid, so a database outage doesn't disconnect everyone.
+func (h *Handler) revalidateSession(ctx context.Context, client *Client) bool {
+	session, err := h.sessionService.GetSession(ctx, client.SessionID)
+	if err != nil {
+		if !errors.Is(err, services.ErrNotFound) {
+			h.requestLogger(ctx).Warn("Failed to revalidate session", "sessionId", client.SessionID, "error", err.Error())
+			return true
+		}
+		h.expireSession(client, err)
+		return false
+	}
+
+	if !session.IsActive {
+		h.expireSession(client, errors.New("session is not active"))
+		return false
+	}
+	if session.UserID != client.UserID {
+		h.expireSession(client, fmt.Errorf("session belongs to user %s", session.UserID))
+		return false
+	}
+	return true
+}
+
+// expireSession disconnects a client whose authentication ended, telling it
+// not to reconnect with the same session
+func (h *Handler) expireSession(client *Client, cause error) {
+	h.revokeReconnectTokens(sessionRevocationKey(client.SessionID))
+	disconnected := h.manager.disconnectClient(client, &closeCause{
+		code:     CloseCodeSessionExpired,
+		reason:   CloseReasonSessionExpired,
+		err:      cause,
+		sendable: true,
+		final:    true,
+	})
+	if !disconnected {
+		return
+	}
+
+	h.logger.Info("🔐 WebSocket session expired, disconnecting",
+		"sessionId", client.SessionID,
+		"userId", client.UserID,
+		"mapId", client.MapID,
+		"cause", cause.Error())
+}
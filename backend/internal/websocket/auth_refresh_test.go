@@ -0,0 +1,149 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type stubTokenValidator struct {
+	claims *services.JWTClaims
+	err    error
+}
+
+func (v *stubTokenValidator) ValidateJWT(token string) (*services.JWTClaims, error) {
+	return v.claims, v.err
+}
+
+func newAuthRefreshTestHandler() (*Handler, *MockSessionService) {
+	sessionService := new(MockSessionService)
+	handler := NewHandler(sessionService, new(MockRateLimiter), nil, new(MockPOIService))
+	return handler, sessionService
+}
+
+func newAuthRefreshTestClient(handler *Handler, sessionID, userID string) *Client {
+	client := &Client{SessionID: sessionID, UserID: userID, MapID: "map-1", Send: make(chan Message, 10)}
+	handler.manager.registerClient(client)
+	return client
+}
+
+// assertSessionExpired checks that the client was disconnected for an expired session
+func assertSessionExpired(t *testing.T, handler *Handler, client *Client) {
+	t.Helper()
+	assert.False(t, handler.manager.IsClientConnected(client.SessionID))
+
+	cause := client.getCloseCause()
+	require.NotNil(t, cause)
+	assert.Equal(t, CloseCodeSessionExpired, cause.code)
+	assert.Equal(t, CloseReasonSessionExpired, cause.reason)
+	assert.True(t, cause.final)
+}
+
+func TestHandler_RefreshAuth_DisconnectsEndedSessions(t *testing.T) {
+	handler, sessionService := newAuthRefreshTestHandler()
+	defer handler.manager.Shutdown()
+
+	active := newAuthRefreshTestClient(handler, "session-active", "user-1")
+	deleted := newAuthRefreshTestClient(handler, "session-deleted", "user-2")
+	inactive := newAuthRefreshTestClient(handler, "session-inactive", "user-3")
+	unreadable := newAuthRefreshTestClient(handler, "session-unreadable", "user-4")
+
+	sessionService.On("GetSession", mock.Anything, "session-active").Return(&models.Session{ID: "session-active", UserID: "user-1", IsActive: true}, nil)
+	sessionService.On("GetSession", mock.Anything, "session-deleted").Return(nil, fmt.Errorf("session %w", services.ErrNotFound))
+	sessionService.On("GetSession", mock.Anything, "session-inactive").Return(&models.Session{ID: "session-inactive", UserID: "user-3", IsActive: false}, nil)
+	sessionService.On("GetSession", mock.Anything, "session-unreadable").Return(nil, errors.New("connection refused"))
+
+	require.NoError(t, handler.RefreshAuth(context.Background()))
+
+	assert.True(t, handler.manager.IsClientConnected(active.SessionID))
+	assertSessionExpired(t, handler, deleted)
+	assertSessionExpired(t, handler, inactive)
+	// A database outage doesn't disconnect everyone
+	assert.True(t, handler.manager.IsClientConnected(unreadable.SessionID))
+
+	// The client is told why, then the write pump ends the connection with a
+	// close frame telling the client not to retry
+	reason := <-deleted.Send
+	assert.Equal(t, "disconnect_reason", reason.Type)
+	assert.Equal(t, map[string]interface{}{"code": CloseCodeSessionExpired, "reason": CloseReasonSessionExpired, "retry": false}, reason.Data)
+	_, open := <-deleted.Send
+	assert.False(t, open)
+	frame := deleted.takeCloseFrame()
+	require.NotNil(t, frame)
+	assert.Contains(t, string(frame), `"retry":false`)
+}
+
+func TestHandler_RefreshAuth_DisconnectsExpiredTokens(t *testing.T) {
+	handler, sessionService := newAuthRefreshTestHandler()
+	defer handler.manager.Shutdown()
+
+	client := newAuthRefreshTestClient(handler, "session-1", "user-1")
+	client.tokenExpiresAt.Store(time.Now().Add(-time.Second).UnixNano())
+
+	require.NoError(t, handler.RefreshAuth(context.Background()))
+
+	assertSessionExpired(t, handler, client)
+	sessionService.AssertNotCalled(t, "GetSession", mock.Anything, mock.Anything)
+}
+
+func TestHandler_AuthRefresh_RefreshesToken(t *testing.T) {
+	handler, sessionService := newAuthRefreshTestHandler()
+	defer handler.manager.Shutdown()
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	handler.SetTokenValidator(&stubTokenValidator{claims: &services.JWTClaims{
+		UserID:           "user-1",
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(expiresAt)},
+	}})
+
+	client := newAuthRefreshTestClient(handler, "session-1", "user-1")
+	sessionService.On("GetSession", mock.Anything, "session-1").Return(&models.Session{ID: "session-1", UserID: "user-1", IsActive: true}, nil)
+
+	handler.handleMessage(client, Message{Type: "auth_refresh", Data: map[string]interface{}{"token": "jwt"}})
+
+	select {
+	case msg := <-client.Send:
+		require.Equal(t, "auth_refreshed", msg.Type)
+		data := msg.Data.(map[string]interface{})
+		assert.Equal(t, "session-1", data["sessionId"])
+		assert.True(t, expiresAt.Equal(data["tokenExpiresAt"].(time.Time)))
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Expected auth_refreshed not received")
+	}
+	assert.Equal(t, expiresAt.UnixNano(), client.tokenExpiresAt.Load())
+	assert.True(t, handler.manager.IsClientConnected("session-1"))
+}
+
+func TestHandler_AuthRefresh_DisconnectsOnTokenOfAnotherUser(t *testing.T) {
+	handler, sessionService := newAuthRefreshTestHandler()
+	defer handler.manager.Shutdown()
+	handler.SetTokenValidator(&stubTokenValidator{claims: &services.JWTClaims{UserID: "user-2"}})
+
+	client := newAuthRefreshTestClient(handler, "session-1", "user-1")
+	sessionService.On("GetSession", mock.Anything, "session-1").Return(&models.Session{ID: "session-1", UserID: "user-1", IsActive: true}, nil)
+
+	handler.handleMessage(client, Message{Type: "auth_refresh", Data: map[string]interface{}{"token": "jwt"}})
+
+	assertSessionExpired(t, handler, client)
+}
+
+func TestHandler_AuthRefresh_DisconnectsEndedSession(t *testing.T) {
+	handler, sessionService := newAuthRefreshTestHandler()
+	defer handler.manager.Shutdown()
+
+	client := newAuthRefreshTestClient(handler, "session-1", "user-1")
+	sessionService.On("GetSession", mock.Anything, "session-1").Return(nil, fmt.Errorf("session %w", services.ErrNotFound))
+
+	handler.handleMessage(client, Message{Type: "auth_refresh"})
+
+	assertSessionExpired(t, handler, client)
+}
@@ -0,0 +1,124 @@
+package websocket
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/middleware"
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+	ws "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newAuthChainTestServer serves the WebSocket handler behind the auth
+// middleware chain of the server, with a JWT validator issuing admin tokens
+// for user-1
+func newAuthChainTestServer(t *testing.T) (*Handler, *MockSessionService, string) {
+	gin.SetMode(gin.TestMode)
+
+	sessionService := new(MockSessionService)
+	mockPOIService := new(MockPOIService)
+	mockPOIService.On("GetCurrentPOI", mock.Anything, mock.Anything).Return("", nil).Maybe()
+	sessionService.On("GetSession", mock.Anything, "session-1").Return(&models.Session{
+		ID:        "session-1",
+		UserID:    "user-1",
+		MapID:     "map-1",
+		AvatarPos: models.LatLng{Lat: 52.52, Lng: 13.405},
+		IsActive:  true,
+	}, nil)
+	handler := NewHandler(sessionService, new(MockRateLimiter), nil, mockPOIService)
+	t.Cleanup(handler.manager.Shutdown)
+	tokens := &stubTokenValidator{claims: &services.JWTClaims{UserID: "user-1", Role: models.UserRoleAdmin}}
+
+	router := gin.New()
+	handler.RegisterRoutes(router, tokens)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return handler, sessionService, "ws" + strings.TrimPrefix(server.URL, "http") + "/ws?"
+}
+
+func TestHandler_AuthMiddleware_JWTRole(t *testing.T) {
+	handler, _, wsURL := newAuthChainTestServer(t)
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer admin-token")
+	conn, _, err := ws.DefaultDialer.Dial(wsURL+sessionQuery(handler, "session-1"), header)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var welcomeMsg Message
+	require.NoError(t, conn.ReadJSON(&welcomeMsg))
+	require.Equal(t, "welcome", welcomeMsg.Type)
+	assert.Equal(t, "user-1", welcomeMsg.Data.(map[string]interface{})["userId"])
+
+	// Facilitator features are available without a profile lookup
+	assert.Eventually(t, func() bool {
+		return len(handler.manager.GetMapFacilitatorUserIDs("map-1")) == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestHandler_AuthMiddleware_RejectsInvalidSession(t *testing.T) {
+	handler, sessionService, wsURL := newAuthChainTestServer(t)
+	sessionService.On("GetSession", mock.Anything, "session-unknown").Return(nil, services.ErrNotFound)
+
+	_, response, err := ws.DefaultDialer.Dial(wsURL+sessionQuery(handler, "session-unknown"), nil)
+	require.Error(t, err)
+	require.NotNil(t, response)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	_, response, err = ws.DefaultDialer.Dial(wsURL, nil)
+	require.Error(t, err)
+	require.NotNil(t, response)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+
+	// Session IDs are broadcast to the map, so they need their token
+	_, response, err = ws.DefaultDialer.Dial(wsURL+"sessionId=session-1", nil)
+	require.Error(t, err)
+	require.NotNil(t, response)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+}
+
+// sessionQuery returns the query parameters of a WebSocket upgrade in the session
+func sessionQuery(h *Handler, sessionID string) string {
+	return "sessionId=" + sessionID + "&sessionToken=" + h.sessionTokens.Issue(sessionID)
+}
+
+// sessionHeader returns the headers of a WebSocket upgrade in the session
+func sessionHeader(h *Handler, sessionID string) http.Header {
+	header := http.Header{}
+	header.Set(middleware.SessionHeader, sessionID)
+	header.Set(middleware.SessionTokenHeader, h.sessionTokens.Issue(sessionID))
+	return header
+}
+
+func TestHandler_AuthMiddleware_ReconnectTokenSkipsSessionLookup(t *testing.T) {
+	handler, sessionService, wsURL := newAuthChainTestServer(t)
+	handler.SetReconnectTokens([]byte("secret"), time.Minute)
+
+	conn, _, err := ws.DefaultDialer.Dial(wsURL+sessionQuery(handler, "session-1"), nil)
+	require.NoError(t, err)
+	var welcomeMsg Message
+	require.NoError(t, conn.ReadJSON(&welcomeMsg))
+	token, _ := welcomeMsg.Data.(map[string]interface{})["reconnectToken"].(string)
+	require.NotEmpty(t, token)
+	conn.Close()
+	require.Eventually(t, func() bool {
+		return handler.manager.GetConnectedClients() == 0
+	}, time.Second, 10*time.Millisecond)
+
+	conn, _, err = ws.DefaultDialer.Dial(wsURL+"reconnectToken="+url.QueryEscape(token), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.ReadJSON(&welcomeMsg))
+	assert.Equal(t, "session-1", welcomeMsg.Data.(map[string]interface{})["sessionId"])
+	sessionService.AssertNumberOfCalls(t, "GetSession", 1)
+}
@@ -0,0 +1,131 @@
+package websocket
+
+import (
+	"sync"
+	"time"
+
+	"breakoutglobe/internal/analytics"
+)
+
+// DefaultCallRingTimeout is how long a call rings before it is cancelled as missed
+const DefaultCallRingTimeout = 30 * time.Second
+
+// ringingCall is a requested call that wasn't answered yet
+type ringingCall struct {
+	callID       string
+	mapID        string
+	callerUserID string
+	targetUserID string
+	timer        *time.Timer
+}
+
+// callRinger cancels calls that ring longer than the timeout, so the caller
+// isn't left waiting for a callee that never responds
+type callRinger struct {
+	mutex     sync.Mutex
+	timeout   time.Duration
+	calls     map[string]*ringingCall // callID -> call
+	onTimeout func(call *ringingCall)
+}
+
+func newCallRinger(timeout time.Duration, onTimeout func(call *ringingCall)) *callRinger {
+	return &callRinger{
+		timeout:   timeout,
+		calls:     make(map[string]*ringingCall),
+		onTimeout: onTimeout,
+	}
+}
+
+// ring starts the ring timeout of a call. A repeated request for the same
+// call restarts it.
+func (r *callRinger) ring(callID, mapID, callerUserID, targetUserID string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.timeout <= 0 {
+		return
+	}
+	if previous, exists := r.calls[callID]; exists {
+		previous.timer.Stop()
+	}
+
+	call := &ringingCall{callID: callID, mapID: mapID, callerUserID: callerUserID, targetUserID: targetUserID}
+	call.timer = time.AfterFunc(r.timeout, func() {
+		r.mutex.Lock()
+		if r.calls[callID] != call {
+			r.mutex.Unlock()
+			return
+		}
+		delete(r.calls, callID)
+		r.mutex.Unlock()
+
+		r.onTimeout(call)
+	})
+	r.calls[callID] = call
+}
+
+// answer stops ringing a call that was accepted, rejected or cancelled. It
+// returns false if the call wasn't ringing anymore.
+func (r *callRinger) answer(callID string) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	call, exists := r.calls[callID]
+	if !exists {
+		return false
+	}
+	call.timer.Stop()
+	delete(r.calls, callID)
+	return true
+}
+
+// SetCallRingTimeout sets how long a call rings before both parties get
+// call_timeout. Zero lets calls ring until they are answered.
+func (h *Handler) SetCallRingTimeout(timeout time.Duration) {
+	h.ringer.mutex.Lock()
+	defer h.ringer.mutex.Unlock()
+
+	h.ringer.timeout = timeout
+}
+
+// handleCallTimeout cancels a call that rang out: both parties get
+// call_timeout, the map sees both out of the call, and the called user gets
+// it recorded as a missed call
+func (h *Handler) handleCallTimeout(call *ringingCall) {
+	h.logger.Info("⏰ Call rang out without an answer",
+		"callId", call.callID,
+		"caller", call.callerUserID,
+		"target", call.targetUserID)
+
+	timeoutMsg := Message{
+		Type: "call_timeout",
+		Data: map[string]interface{}{
+			"callId":       call.callID,
+			"callerUserId": call.callerUserID,
+			"targetUserId": call.targetUserID,
+		},
+		Timestamp: time.Now(),
+	}
+	h.manager.BroadcastToUser(call.callerUserID, timeoutMsg, "")
+	h.manager.BroadcastToUser(call.targetUserID, timeoutMsg, "")
+
+	for _, userID := range []string{call.callerUserID, call.targetUserID} {
+		callStatusMsg := Message{
+			Type: "user_call_status",
+			Data: map[string]interface{}{
+				"userId":   userID,
+				"isInCall": false,
+			},
+			Timestamp: time.Now(),
+		}
+		h.manager.BroadcastToMap(call.mapID, onTopics(callStatusMsg, userTopic(userID)))
+	}
+
+	if h.analytics != nil {
+		h.analytics.Track(analytics.EventCallMissed, call.targetUserID, map[string]interface{}{
+			"callId":       call.callID,
+			"mapId":        call.mapID,
+			"callerUserId": call.callerUserID,
+		})
+	}
+}
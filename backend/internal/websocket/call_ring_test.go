@@ -0,0 +1,155 @@
+package websocket
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/analytics"
+	"breakoutglobe/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingCallAnalytics struct {
+	mutex  sync.Mutex
+	events []string
+	users  []string
+}
+
+func (a *recordingCallAnalytics) Track(name, userID string, properties map[string]interface{}) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.events = append(a.events, name)
+	a.users = append(a.users, userID)
+}
+
+func (a *recordingCallAnalytics) tracked() ([]string, []string) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return append([]string(nil), a.events...), append([]string(nil), a.users...)
+}
+
+func newCallRingTestHandler(t *testing.T, timeout time.Duration) (*Handler, *Client, *Client) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, new(MockPOIService))
+	t.Cleanup(handler.manager.Shutdown)
+	handler.SetCallRingTimeout(timeout)
+
+	caller := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 10)}
+	callee := &Client{SessionID: "session-2", UserID: "user-2", MapID: "map-1", Send: make(chan Message, 10)}
+	handler.manager.registerClient(caller)
+	handler.manager.registerClient(callee)
+	return handler, caller, callee
+}
+
+// receiveType returns the next message of a type sent to a client, skipping others
+func receiveType(t *testing.T, client *Client, messageType string) Message {
+	t.Helper()
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case msg := <-client.Send:
+			if msg.Type == messageType {
+				return msg
+			}
+		case <-timeout:
+			t.Fatalf("no %s received", messageType)
+			return Message{}
+		}
+	}
+}
+
+func TestHandler_CallRingTimeout(t *testing.T) {
+	handler, caller, callee := newCallRingTestHandler(t, 20*time.Millisecond)
+	recorder := &recordingCallAnalytics{}
+	handler.SetAnalytics(recorder)
+
+	handler.handleCallRequest(context.Background(), caller, Message{Type: "call_request", Data: map[string]interface{}{
+		"callId":       "call-1",
+		"targetUserId": "user-2",
+	}})
+	require.Equal(t, "call_request", (<-callee.Send).Type)
+
+	// Both parties learn that the call rang out, and see both out of the call
+	expected := map[string]interface{}{"callId": "call-1", "callerUserId": "user-1", "targetUserId": "user-2"}
+	assert.Equal(t, expected, receiveType(t, caller, "call_timeout").Data)
+	assert.Equal(t, expected, receiveType(t, callee, "call_timeout").Data)
+	status := receiveType(t, caller, "user_call_status")
+	assert.Equal(t, false, status.Data.(map[string]interface{})["isInCall"])
+
+	events, users := recorder.tracked()
+	assert.Equal(t, []string{analytics.EventCallMissed}, events)
+	assert.Equal(t, []string{"user-2"}, users)
+}
+
+func TestHandler_CallRingTimeout_StopsWhenAnswered(t *testing.T) {
+	answers := map[string]func(*Handler, context.Context, *Client, Message){
+		"call_accept": (*Handler).handleCallAccept,
+		"call_reject": (*Handler).handleCallReject,
+	}
+	for answer, handle := range answers {
+		t.Run(answer, func(t *testing.T) {
+			handler, caller, callee := newCallRingTestHandler(t, 50*time.Millisecond)
+
+			handler.handleCallRequest(context.Background(), caller, Message{Type: "call_request", Data: map[string]interface{}{
+				"callId":       "call-1",
+				"targetUserId": "user-2",
+			}})
+			handle(handler, context.Background(), callee, Message{Type: answer, Data: map[string]interface{}{
+				"callId":       "call-1",
+				"callerUserId": "user-1",
+			}})
+
+			assert.False(t, handler.ringer.answer("call-1"))
+		})
+	}
+
+	// Callers hanging up stop the ringing too
+	handler, caller, _ := newCallRingTestHandler(t, 50*time.Millisecond)
+	handler.handleCallRequest(context.Background(), caller, Message{Type: "call_request", Data: map[string]interface{}{
+		"callId":       "call-1",
+		"targetUserId": "user-2",
+	}})
+	handler.handleCallEnd(context.Background(), caller, Message{Type: "call_end", Data: map[string]interface{}{
+		"callId":      "call-1",
+		"otherUserId": "user-2",
+	}})
+	assert.False(t, handler.ringer.answer("call-1"))
+
+	time.Sleep(100 * time.Millisecond)
+	for len(caller.Send) > 0 {
+		assert.NotEqual(t, "call_timeout", (<-caller.Send).Type)
+	}
+}
+
+func TestHandler_CallRequestsCountAsPings(t *testing.T) {
+	handler, caller, _ := newCallRingTestHandler(t, time.Minute)
+	guard := services.NewAbuseGuard([]services.AbuseRule{{
+		Name:        "ping_flood",
+		Signal:      services.SignalPing,
+		Threshold:   2,
+		Window:      time.Minute,
+		Restriction: services.RestrictionBlockCalls,
+		Duration:    time.Minute,
+	}})
+	handler.SetAbuseGuard(guard)
+
+	// Heartbeats keep the connection alive and aren't pings
+	sessionService := handler.sessionService.(*MockSessionService)
+	sessionService.On("SessionHeartbeat", mock.Anything, "session-1").Return(nil)
+	for i := 0; i < 5; i++ {
+		handler.handleHeartbeat(context.Background(), caller, Message{Type: "heartbeat"})
+	}
+	assert.False(t, guard.IsRestricted(context.Background(), "user-1", services.RestrictionBlockCalls))
+
+	for _, callID := range []string{"call-1", "call-2"} {
+		handler.handleCallRequest(context.Background(), caller, Message{Type: "call_request", Data: map[string]interface{}{
+			"callId":       callID,
+			"targetUserId": "user-2",
+		}})
+	}
+	assert.True(t, guard.IsRestricted(context.Background(), "user-1", services.RestrictionBlockCalls))
+}
@@ -0,0 +1,85 @@
+package websocket
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// UpgradeRequiredCloseCode is the close code sent to clients older than the
+// minimum client version, after an upgrade_required message
+const UpgradeRequiredCloseCode = 4426
+
+// maxUserAgentLength bounds the user agent kept per connection
+const maxUserAgentLength = 256
+
+// clientVersionFromRequest returns the client app version sent as the
+// clientVersion query parameter or the X-Client-Version header
+func clientVersionFromRequest(r *http.Request) string {
+	version := r.URL.Query().Get("clientVersion")
+	if version == "" {
+		version = r.Header.Get("X-Client-Version")
+	}
+	return strings.TrimSpace(version)
+}
+
+// userAgentFromRequest returns the request's user agent, truncated
+func userAgentFromRequest(r *http.Request) string {
+	userAgent := r.UserAgent()
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+	return userAgent
+}
+
+// parseVersion parses versions like "1.4.2", "v1.4" or "1.4.2-beta.1" into
+// major, minor and patch. Pre-release and build suffixes are ignored.
+func parseVersion(version string) ([3]int, bool) {
+	var parts [3]int
+
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	if version == "" {
+		return parts, false
+	}
+
+	fields := strings.Split(version, ".")
+	if len(fields) > len(parts) {
+		return parts, false
+	}
+	for i, field := range fields {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
+
+// isClientVersionSupported reports whether a client version satisfies the
+// minimum version. Missing or unparsable versions come from clients that
+// predate version reporting and are not supported once a minimum is set.
+func isClientVersionSupported(version, minVersion string) bool {
+	if minVersion == "" {
+		return true
+	}
+
+	minimum, ok := parseVersion(minVersion)
+	if !ok {
+		return true
+	}
+	current, ok := parseVersion(version)
+	if !ok {
+		return false
+	}
+
+	for i := range current {
+		if current[i] != minimum[i] {
+			return current[i] > minimum[i]
+		}
+	}
+	return true
+}
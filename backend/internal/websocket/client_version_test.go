@@ -0,0 +1,31 @@
+package websocket
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsClientVersionSupported(t *testing.T) {
+	tests := []struct {
+		version    string
+		minVersion string
+		supported  bool
+	}{
+		{"1.4.0", "", true},
+		{"", "", true},
+		{"1.4.0", "1.4.0", true},
+		{"1.10.0", "1.9.3", true},
+		{"v2", "1.9.3", true},
+		{"1.4.1-beta.2", "1.4.1", true},
+		{"1.3.9", "1.4.0", false},
+		{"0.9", "1", false},
+		{"", "1.0.0", false},
+		{"latest", "1.0.0", false},
+		{"1.0.0.1", "1.0.0", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.supported, isClientVersionSupported(tt.version, tt.minVersion), "version %q, minimum %q", tt.version, tt.minVersion)
+	}
+}
@@ -0,0 +1,239 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/google/uuid"
+	ws "github.com/gorilla/websocket"
+)
+
+// Close codes sent when the server ends a connection. The close reason is a
+// JSON encoded CloseReason.
+const (
+	CloseCodeInvalidMessage   = 4400
+	CloseCodeSessionExpired   = 4401
+	CloseCodeKicked           = 4403
+	CloseCodeMapDeleted       = 4404
+	CloseCodeIdleTimeout      = 4408
+	CloseCodeDuplicateSession = 4409
+	CloseCodeSlowConsumer     = 4429
+)
+
+// Close reasons of ended connections, as recorded and sent to clients
+const (
+	CloseReasonClientClosed     = "client_closed"
+	CloseReasonConnectionLost   = "connection_lost"
+	CloseReasonInvalidMessage   = "invalid_message"
+	CloseReasonIdleTimeout      = "idle_timeout"
+	CloseReasonHeartbeatTimeout = "heartbeat_timeout"
+	CloseReasonSlowConsumer     = "slow_consumer"
+	CloseReasonSessionExpired   = "session_expired"
+	CloseReasonKicked           = "kicked"
+	CloseReasonMapDeleted       = "map_deleted"
+	CloseReasonDuplicateSession = "duplicate_session"
+	CloseReasonWriteFailed      = "write_failed"
+	CloseReasonServerShutdown   = "server_shutdown"
+)
+
+// maxCloseReasonBytes is the longest close reason that fits into a close frame
+const maxCloseReasonBytes = 123
+
+// maxRecordedErrorLength bounds the error text kept per connection error
+const maxRecordedErrorLength = 512
+
+// ConnectionErrorRecorderInterface defines the interface for recording abnormal closes
+type ConnectionErrorRecorderInterface interface {
+	RecordConnectionError(ctx context.Context, connectionError *models.ConnectionError) error
+}
+
+// CloseReason is the structured reason of a close frame sent by the server.
+// ErrorID references the recorded connection error for support requests.
+type CloseReason struct {
+	Reason  string `json:"reason"`
+	Retry   bool   `json:"retry"`
+	ErrorID string `json:"errorId,omitempty"`
+}
+
+// closeCause is why a connection ended. The first cause seen wins, since e.g. a
+// failed write also makes the pending read fail.
+type closeCause struct {
+	code   int
+	reason string
+	err    error
+	// sendable is set if the connection is still usable to send a close frame
+	sendable bool
+	// final is set if reconnecting with the same session can't succeed
+	final bool
+	// errorID identifies the recorded connection error of an abnormal close
+	errorID string
+}
+
+// abnormal reports whether the close should be recorded as a connection error.
+// Clients closing without a status code, server shutdowns and connections
+// the server ended on purpose count as normal closes.
+func (c *closeCause) abnormal() bool {
+	switch c.code {
+	case ws.CloseNormalClosure, ws.CloseGoingAway, ws.CloseNoStatusReceived, ws.CloseServiceRestart,
+		CloseCodeKicked, CloseCodeMapDeleted, CloseCodeDuplicateSession:
+		return false
+	}
+	return true
+}
+
+// disconnectReasonMessage is the last message of a connection the server
+// ends, sent ahead of the close frame for clients that can't read close
+// reasons, like browsers reporting only the code
+func disconnectReasonMessage(cause *closeCause) Message {
+	return Message{
+		Type: "disconnect_reason",
+		Data: map[string]interface{}{
+			"code":   cause.code,
+			"reason": cause.reason,
+			"retry":  !cause.final,
+		},
+		Timestamp: time.Now(),
+	}
+}
+
+// closeCauseFromReadError classifies the error that ended the read loop
+func closeCauseFromReadError(err error) *closeCause {
+	var closeErr *ws.CloseError
+	if errors.As(err, &closeErr) {
+		// gorilla reports a connection dropped without a close frame as 1006
+		reason := CloseReasonClientClosed
+		if closeErr.Code == ws.CloseAbnormalClosure {
+			reason = CloseReasonConnectionLost
+		}
+		return &closeCause{code: closeErr.Code, reason: reason, err: err}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return &closeCause{code: CloseCodeIdleTimeout, reason: CloseReasonIdleTimeout, err: err, sendable: true}
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+		return &closeCause{code: CloseCodeInvalidMessage, reason: CloseReasonInvalidMessage, err: err, sendable: true}
+	}
+
+	return &closeCause{code: ws.CloseAbnormalClosure, reason: CloseReasonConnectionLost, err: err}
+}
+
+// setCloseCause records why the client's connection ends, unless a cause is already known
+func (c *Client) setCloseCause(cause *closeCause) {
+	c.closeMutex.Lock()
+	defer c.closeMutex.Unlock()
+
+	if c.closeCause == nil {
+		if cause.abnormal() {
+			cause.errorID = uuid.New().String()
+		}
+		c.closeCause = cause
+	}
+}
+
+// getCloseCause returns why the client's connection ends, if known
+func (c *Client) getCloseCause() *closeCause {
+	c.closeMutex.Lock()
+	defer c.closeMutex.Unlock()
+
+	return c.closeCause
+}
+
+// takeCloseFrame returns the close frame telling the client why its connection
+// ends, or nil if there is none or it was already taken
+func (c *Client) takeCloseFrame() []byte {
+	c.closeMutex.Lock()
+	defer c.closeMutex.Unlock()
+
+	if c.closeCause == nil || !c.closeCause.sendable || c.closeFrameSent {
+		return nil
+	}
+	c.closeFrameSent = true
+
+	reason := CloseReason{Reason: c.closeCause.reason, Retry: !c.closeCause.final, ErrorID: c.closeCause.errorID}
+	return formatCloseMessage(c.closeCause.code, reason)
+}
+
+// formatCloseMessage encodes a close frame payload with a structured reason.
+// The error ID is dropped if the reason would not fit into the frame.
+func formatCloseMessage(code int, reason CloseReason) []byte {
+	text, _ := json.Marshal(reason)
+	if len(text) > maxCloseReasonBytes {
+		reason.ErrorID = ""
+		text, _ = json.Marshal(reason)
+	}
+	return ws.FormatCloseMessage(code, string(text))
+}
+
+// finishConnection records an abnormal close and, if the connection is still
+// usable, tells the client why it is closed
+func (h *Handler) finishConnection(c *Client) {
+	cause := c.getCloseCause()
+
+	disconnected := map[string]interface{}{
+		"connectedAt": c.ConnectedAt,
+	}
+	if cause != nil {
+		disconnected["closeCode"] = cause.code
+		disconnected["reason"] = cause.reason
+	}
+	h.exportEvent(c, "disconnected", disconnected)
+
+	if cause == nil || !cause.abnormal() {
+		return
+	}
+
+	// WriteControl may be used concurrently with the write pump
+	if frame := c.takeCloseFrame(); frame != nil && c.Conn != nil {
+		c.Conn.WriteControl(ws.CloseMessage, frame, time.Now().Add(time.Second))
+	}
+
+	h.logger.Warn("WebSocket connection closed abnormally",
+		"sessionId", c.SessionID,
+		"userId", c.UserID,
+		"closeCode", cause.code,
+		"reason", cause.reason,
+		"lastMessageType", c.lastMessageType,
+		"errorId", cause.errorID)
+
+	if h.connectionErrors == nil {
+		return
+	}
+
+	errorText := ""
+	if cause.err != nil {
+		errorText = cause.err.Error()
+		if len(errorText) > maxRecordedErrorLength {
+			errorText = errorText[:maxRecordedErrorLength]
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := h.connectionErrors.RecordConnectionError(ctx, &models.ConnectionError{
+		ID:              cause.errorID,
+		SessionID:       c.SessionID,
+		UserID:          c.UserID,
+		MapID:           c.MapID,
+		CloseCode:       cause.code,
+		Reason:          cause.reason,
+		LastMessageType: c.lastMessageType,
+		Error:           errorText,
+		ClientVersion:   c.ClientVersion,
+		UserAgent:       c.UserAgent,
+		ConnectedAt:     c.ConnectedAt,
+		ClosedAt:        time.Now(),
+	})
+	if err != nil {
+		h.logger.Error("Failed to record connection error", "sessionId", c.SessionID, "error", err.Error())
+	}
+}
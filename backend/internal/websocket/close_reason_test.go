@@ -0,0 +1,175 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	ws "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingConnectionErrors struct {
+	mutex  sync.Mutex
+	errors []models.ConnectionError
+}
+
+func (r *recordingConnectionErrors) RecordConnectionError(ctx context.Context, connectionError *models.ConnectionError) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.errors = append(r.errors, *connectionError)
+	return nil
+}
+
+func (r *recordingConnectionErrors) recorded() []models.ConnectionError {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]models.ConnectionError(nil), r.errors...)
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestCloseCauseFromReadError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		code     int
+		reason   string
+		abnormal bool
+	}{
+		{"normal close", &ws.CloseError{Code: ws.CloseNormalClosure}, ws.CloseNormalClosure, CloseReasonClientClosed, false},
+		{"going away", &ws.CloseError{Code: ws.CloseGoingAway}, ws.CloseGoingAway, CloseReasonClientClosed, false},
+		{"abnormal close", &ws.CloseError{Code: ws.CloseAbnormalClosure}, ws.CloseAbnormalClosure, CloseReasonConnectionLost, true},
+		{"idle timeout", timeoutError{}, CloseCodeIdleTimeout, CloseReasonIdleTimeout, true},
+		{"invalid json", &json.SyntaxError{}, CloseCodeInvalidMessage, CloseReasonInvalidMessage, true},
+		{"other", errors.New("connection reset by peer"), ws.CloseAbnormalClosure, CloseReasonConnectionLost, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cause := closeCauseFromReadError(tt.err)
+			assert.Equal(t, tt.code, cause.code)
+			assert.Equal(t, tt.reason, cause.reason)
+			assert.Equal(t, tt.abnormal, cause.abnormal())
+		})
+	}
+}
+
+func TestFormatCloseMessage_FitsCloseFrame(t *testing.T) {
+	frame := formatCloseMessage(CloseCodeIdleTimeout, CloseReason{Reason: CloseReasonIdleTimeout, Retry: true, ErrorID: "0b7c1b9e-5d8f-4a53-9d3e-8f1f0a4a2c11"})
+	var reason CloseReason
+	require.NoError(t, json.Unmarshal(frame[2:], &reason))
+	assert.Equal(t, "0b7c1b9e-5d8f-4a53-9d3e-8f1f0a4a2c11", reason.ErrorID)
+
+	// The error ID is dropped rather than exceeding the close frame limit
+	frame = formatCloseMessage(CloseCodeIdleTimeout, CloseReason{Reason: CloseReasonIdleTimeout, ErrorID: strings.Repeat("x", 120)})
+	assert.LessOrEqual(t, len(frame)-2, maxCloseReasonBytes)
+	var truncated CloseReason
+	require.NoError(t, json.Unmarshal(frame[2:], &truncated))
+	assert.Equal(t, CloseReasonIdleTimeout, truncated.Reason)
+	assert.Empty(t, truncated.ErrorID)
+}
+
+func TestClient_SetCloseCause_FirstCauseWins(t *testing.T) {
+	client := &Client{}
+	client.setCloseCause(&closeCause{code: CloseCodeSlowConsumer, reason: CloseReasonSlowConsumer, sendable: true})
+	client.setCloseCause(&closeCause{code: ws.CloseAbnormalClosure, reason: CloseReasonWriteFailed})
+
+	cause := client.getCloseCause()
+	assert.Equal(t, CloseReasonSlowConsumer, cause.reason)
+	assert.NotEmpty(t, cause.errorID)
+
+	assert.NotNil(t, client.takeCloseFrame())
+	assert.Nil(t, client.takeCloseFrame())
+}
+
+func (suite *WebSocketHandlerTestSuite) TestWebSocketConnection_InvalidMessageClose() {
+	session := &models.Session{
+		ID:       "session-123",
+		UserID:   "user-456",
+		MapID:    "map-789",
+		IsActive: true,
+	}
+	suite.mockSessionService.On("GetSession", mock.Anything, "session-123").Return(session, nil)
+	suite.mockSessionService.On("SessionHeartbeat", mock.Anything, "session-123").Return(nil).Maybe()
+	recorder := &recordingConnectionErrors{}
+	suite.handler.SetConnectionErrorRecorder(recorder)
+
+	header := sessionHeader(suite.handler, "session-123")
+
+	conn, _, err := ws.DefaultDialer.Dial(suite.wsURL+"?clientVersion=1.4.0", header)
+	suite.Require().NoError(err)
+	defer conn.Close()
+
+	var msg Message
+	suite.NoError(conn.ReadJSON(&msg))
+	suite.Equal("welcome", msg.Type)
+
+	suite.NoError(conn.WriteJSON(Message{Type: "heartbeat"}))
+	suite.NoError(conn.WriteMessage(ws.TextMessage, []byte("{not json")))
+
+	// The client is told why it was disconnected, referencing the recorded error
+	var closeErr *ws.CloseError
+	for {
+		_, _, err = conn.ReadMessage()
+		if err != nil {
+			break
+		}
+	}
+	suite.Require().True(errors.As(err, &closeErr))
+	suite.Equal(CloseCodeInvalidMessage, closeErr.Code)
+	var reason CloseReason
+	suite.Require().NoError(json.Unmarshal([]byte(closeErr.Text), &reason))
+	suite.Equal(CloseReasonInvalidMessage, reason.Reason)
+	suite.True(reason.Retry)
+
+	suite.Eventually(func() bool {
+		return len(recorder.recorded()) == 1
+	}, time.Second, 10*time.Millisecond)
+	recorded := recorder.recorded()[0]
+	suite.Equal(reason.ErrorID, recorded.ID)
+	suite.Equal("user-456", recorded.UserID)
+	suite.Equal("map-789", recorded.MapID)
+	suite.Equal(CloseCodeInvalidMessage, recorded.CloseCode)
+	suite.Equal("heartbeat", recorded.LastMessageType)
+	suite.Equal("1.4.0", recorded.ClientVersion)
+	suite.NotEmpty(recorded.Error)
+}
+
+type recordingEventExporter struct {
+	events []map[string]interface{}
+}
+
+func (e *recordingEventExporter) Export(category string, fields map[string]interface{}) {
+	fields["category"] = category
+	e.events = append(e.events, fields)
+}
+
+func TestHandler_FinishConnection_ExportsDisconnect(t *testing.T) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, new(MockPOIService))
+	exporter := &recordingEventExporter{}
+	handler.SetEventExporter(exporter)
+	client := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1"}
+	client.setCloseCause(&closeCause{code: ws.CloseNormalClosure, reason: "client_closed"})
+
+	handler.finishConnection(client)
+
+	require.Len(t, exporter.events, 1)
+	event := exporter.events[0]
+	assert.Equal(t, "events", event["category"])
+	assert.Equal(t, "disconnected", event["event"])
+	assert.Equal(t, "session-1", event["sessionId"])
+	assert.Equal(t, "client_closed", event["reason"])
+}
@@ -0,0 +1,44 @@
+package websocket
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// SetCompressionThreshold compresses messages of at least the given size in
+// bytes with permessage-deflate, for clients that support it. Smaller messages
+// like avatar moves aren't worth the CPU. Zero disables compression.
+func (h *Handler) SetCompressionThreshold(bytes int) {
+	if bytes < 0 {
+		bytes = 0
+	}
+	h.compressionThreshold = bytes
+	h.upgrader.EnableCompression = bytes > 0
+}
+
+// compressionThresholdFor returns the compression threshold of a connection
+// from the request, or 0 if the client didn't offer permessage-deflate
+func (h *Handler) compressionThresholdFor(r *http.Request) int {
+	if h.compressionThreshold == 0 {
+		return 0
+	}
+	for _, header := range r.Header.Values("Sec-WebSocket-Extensions") {
+		for _, extension := range strings.Split(header, ",") {
+			name, _, _ := strings.Cut(extension, ";")
+			if strings.TrimSpace(name) == "permessage-deflate" {
+				return h.compressionThreshold
+			}
+		}
+	}
+	return 0
+}
+
+// recordPayloadBytes counts the bytes of a written message, before
+// compression, by whether it was compressed
+func (c *Client) recordPayloadBytes(size int, compressed bool) {
+	if c.payloadBytes == nil {
+		return
+	}
+	c.payloadBytes.WithLabelValues(strconv.FormatBool(compressed)).Add(float64(size))
+}
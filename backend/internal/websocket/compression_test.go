@@ -0,0 +1,104 @@
+package websocket
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/gin-gonic/gin"
+	ws "github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_CompressesLargeMessages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSessionService := new(MockSessionService)
+	mockPOIService := new(MockPOIService)
+	mockPOIService.On("GetCurrentPOI", mock.Anything, mock.Anything).Return("", nil).Maybe()
+	// On separate maps, so neither gets the other's user_joined
+	for i, sessionID := range []string{"session-1", "session-2"} {
+		mockSessionService.On("GetSession", mock.Anything, sessionID).Return(&models.Session{
+			ID:        sessionID,
+			UserID:    "user-" + sessionID,
+			MapID:     fmt.Sprintf("map-%d", i+1),
+			IsActive:  true,
+			AvatarPos: models.LatLng{Lat: 52.52, Lng: 13.405},
+		}, nil)
+	}
+	handler := NewHandler(mockSessionService, new(MockRateLimiter), nil, mockPOIService)
+	registry := prometheus.NewRegistry()
+	handler.SetMetrics(registry)
+	// The welcome message is larger, initial_users of an empty map smaller
+	handler.SetCompressionThreshold(150)
+
+	router := gin.New()
+	handler.RegisterRoutes(router, nil)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws?"
+
+	dialer := *ws.DefaultDialer
+	dialer.EnableCompression = true
+	conn, _, err := dialer.Dial(wsURL+sessionQuery(handler, "session-1"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var welcomeMsg, initialUsersMsg Message
+	require.NoError(t, conn.ReadJSON(&welcomeMsg))
+	require.NoError(t, conn.ReadJSON(&initialUsersMsg))
+	assert.Equal(t, "welcome", welcomeMsg.Type)
+	assert.Equal(t, "initial_users", initialUsersMsg.Type)
+
+	metricsOutput := func() string {
+		return scrapeMetrics(t, registry)
+	}
+	// Payloads are counted once written
+	require.Eventually(t, func() bool {
+		output := metricsOutput()
+		return strings.Contains(output, `breakoutglobe_websocket_payload_bytes_total{compressed="true"}`) &&
+			strings.Contains(output, `breakoutglobe_websocket_payload_bytes_total{compressed="false"}`)
+	}, time.Second, 10*time.Millisecond)
+
+	// Clients without permessage-deflate get everything uncompressed
+	before := metricsOutput()
+	plain, _, err := ws.DefaultDialer.Dial(wsURL+sessionQuery(handler, "session-2"), nil)
+	require.NoError(t, err)
+	defer plain.Close()
+	require.NoError(t, plain.ReadJSON(&welcomeMsg))
+	assert.Equal(t, "welcome", welcomeMsg.Type)
+
+	require.Eventually(t, func() bool {
+		return metricsOutput() != before
+	}, time.Second, 10*time.Millisecond)
+	compressedLine := func(output string) string {
+		for _, line := range strings.Split(output, "\n") {
+			if strings.HasPrefix(line, `breakoutglobe_websocket_payload_bytes_total{compressed="true"}`) {
+				return line
+			}
+		}
+		return ""
+	}
+	assert.Equal(t, compressedLine(before), compressedLine(metricsOutput()))
+}
+
+func TestHandler_CompressionThresholdFor(t *testing.T) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, new(MockPOIService))
+	request := httptest.NewRequest("GET", "/ws", nil)
+	request.Header.Set("Sec-WebSocket-Extensions", "permessage-deflate; client_max_window_bits")
+
+	// Disabled by default
+	assert.Equal(t, 0, handler.compressionThresholdFor(request))
+
+	handler.SetCompressionThreshold(1024)
+	assert.True(t, handler.upgrader.EnableCompression)
+	assert.Equal(t, 1024, handler.compressionThresholdFor(request))
+	assert.Equal(t, 0, handler.compressionThresholdFor(httptest.NewRequest("GET", "/ws", nil)))
+}
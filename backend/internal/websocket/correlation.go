@@ -0,0 +1,53 @@
+package websocket
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// maxRequestIDLength bounds the request IDs clients correlate replies with
+const maxRequestIDLength = 64
+
+// requestIDKey is the context key of the request ID of the message being handled
+type requestIDKey struct{}
+
+// withRequestID returns a context carrying the request ID of a client message,
+// which replies to the message echo back
+func withRequestID(ctx context.Context, requestID string) context.Context {
+	if requestID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// requestIDFrom returns the request ID of the message being handled, if the
+// client sent one
+func requestIDFrom(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// replyTo marks a message as the reply to the message being handled, so the
+// client can correlate it, like acks and errors
+func replyTo(ctx context.Context, message Message) Message {
+	message.RequestID = requestIDFrom(ctx)
+	return message
+}
+
+// requestLogger returns the handler's logger, tagged with the request ID of
+// the message being handled if the client sent one
+func (h *Handler) requestLogger(ctx context.Context) *slog.Logger {
+	if requestID := requestIDFrom(ctx); requestID != "" {
+		return h.logger.With("requestId", requestID)
+	}
+	return h.logger
+}
+
+// validateRequestID checks that a client's request ID fits
+func validateRequestID(requestID string) error {
+	if len(requestID) > maxRequestIDLength {
+		return fmt.Errorf("requestId must be at most %d characters", maxRequestIDLength)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package websocket
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_HandleMessage_EchoesRequestID(t *testing.T) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, new(MockPOIService))
+	defer handler.manager.Shutdown()
+	client := newTopicTestClient(handler.manager, "session-1", "user-1", "map-1")
+
+	handler.handleMessage(client, Message{Type: "subscribe", Data: map[string]interface{}{"topic": "user:user-2"}, RequestID: "req-1"})
+	reply := <-client.Send
+	assert.Equal(t, "subscribed", reply.Type)
+	assert.Equal(t, "req-1", reply.RequestID)
+
+	// Errors echo it too, like for invalid payloads
+	handler.handleMessage(client, Message{Type: "subscribe", Data: map[string]interface{}{"topic": "map:map-1"}, RequestID: "req-2"})
+	reply = <-client.Send
+	assert.Equal(t, "error", reply.Type)
+	assert.Equal(t, "req-2", reply.RequestID)
+
+	// Replies to messages without one carry none
+	handler.handleMessage(client, Message{Type: "unsubscribe", Data: map[string]interface{}{"topic": "user:user-2"}})
+	reply = <-client.Send
+	assert.Equal(t, "unsubscribed", reply.Type)
+	assert.Empty(t, reply.RequestID)
+}
+
+func TestIncomingMessage_RequestID(t *testing.T) {
+	var incoming incomingMessage
+	require.NoError(t, json.Unmarshal([]byte(`{"type":"heartbeat","requestId":"req-1"}`), &incoming))
+	msg := incoming.message()
+	assert.Equal(t, "req-1", msg.RequestID)
+	assert.NoError(t, validateMessage(msg))
+
+	msg.RequestID = strings.Repeat("x", maxRequestIDLength+1)
+	assert.Error(t, validateMessage(msg))
+}
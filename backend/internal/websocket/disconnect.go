@@ -0,0 +1,100 @@
+package websocket
+
+import "errors"
+
+// disconnectMapClients ends the connections on a map that match, each for
+// the cause made for it, and returns the clients disconnected
+func (m *Manager) disconnectMapClients(mapID string, matches func(*Client) bool, cause func() *closeCause) []*Client {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var disconnected []*Client
+	for _, client := range m.mapClients[mapID] {
+		if matches(client) {
+			disconnected = append(disconnected, client)
+		}
+	}
+	for _, client := range disconnected {
+		m.endClient(client, cause())
+	}
+	return disconnected
+}
+
+// KickUser disconnects all connections of a user on a map, telling them not
+// to reconnect. It returns how many connections were closed.
+func (h *Handler) KickUser(mapID, userID string) int {
+	// Kicked clients must not resume with the reconnect token of their ended session
+	h.revokeReconnectTokens(userRevocationKey(mapID, userID))
+	disconnected := h.manager.disconnectMapClients(mapID,
+		func(client *Client) bool { return client.UserID == userID },
+		func() *closeCause {
+			return &closeCause{code: CloseCodeKicked, reason: CloseReasonKicked, err: errors.New("kicked from the map"), sendable: true, final: true}
+		})
+	if len(disconnected) > 0 {
+		h.logger.Info("👢 User kicked, disconnecting", "userId", userID, "mapId", mapID, "connections", len(disconnected))
+	}
+	return len(disconnected)
+}
+
+// DisconnectMap disconnects every client of a deleted map, telling them not
+// to reconnect. It returns how many connections were closed.
+func (h *Handler) DisconnectMap(mapID string) int {
+	disconnected := h.manager.disconnectMapClients(mapID,
+		func(*Client) bool { return true },
+		func() *closeCause {
+			return &closeCause{code: CloseCodeMapDeleted, reason: CloseReasonMapDeleted, err: errors.New("map deleted"), sendable: true, final: true}
+		})
+	if len(disconnected) > 0 {
+		h.logger.Info("🗑️ Map deleted, disconnecting clients", "mapId", mapID, "connections", len(disconnected))
+	}
+	return len(disconnected)
+}
+
+// handleMapDeletedEvent disconnects the clients of a deleted map on this
+// instance
+func (h *Handler) handleMapDeletedEvent(data interface{}) {
+	deletedData, ok := data.(map[string]interface{})
+	if !ok {
+		h.logger.Error("❌ Invalid map deleted event data", "data", data)
+		return
+	}
+
+	mapID, _ := deletedData["mapId"].(string)
+	if mapID == "" {
+		h.logger.Error("❌ Missing mapId in map deleted event", "data", data)
+		return
+	}
+
+	h.DisconnectMap(mapID)
+}
+
+// closeReplacedSessions disconnects the connections of the client's user on
+// its map that still use an older session. Users have one active session per
+// map, so a connection with a newer one replaces them; tabs sharing the
+// session stay connected. It returns the sessions replaced.
+func (h *Handler) closeReplacedSessions(client *Client) []string {
+	disconnected := h.manager.disconnectMapClients(client.MapID,
+		func(other *Client) bool {
+			return other != client && other.UserID == client.UserID && other.SessionID != client.SessionID
+		},
+		func() *closeCause {
+			return &closeCause{code: CloseCodeDuplicateSession, reason: CloseReasonDuplicateSession, err: errors.New("replaced by session " + client.SessionID), sendable: true, final: true}
+		})
+
+	replaced := make([]string, 0, len(disconnected))
+	seen := make(map[string]bool)
+	for _, old := range disconnected {
+		if !seen[old.SessionID] {
+			seen[old.SessionID] = true
+			replaced = append(replaced, old.SessionID)
+		}
+	}
+	if len(replaced) > 0 {
+		h.logger.Info("🔁 Session replaced, disconnecting older connections",
+			"sessionId", client.SessionID,
+			"userId", client.UserID,
+			"mapId", client.MapID,
+			"replacedSessions", replaced)
+	}
+	return replaced
+}
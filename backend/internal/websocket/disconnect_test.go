@@ -0,0 +1,118 @@
+package websocket
+
+import (
+	"encoding/json"
+	"testing"
+
+	"breakoutglobe/internal/redis"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDisconnectTestClient(handler *Handler, connectionID, sessionID, userID, mapID string) *Client {
+	client := &Client{connectionID: connectionID, SessionID: sessionID, UserID: userID, MapID: mapID, Send: make(chan Message, 10)}
+	handler.manager.registerClient(client)
+	return client
+}
+
+// assertDisconnected checks that the client got disconnect_reason followed by
+// the end of its connection
+func assertDisconnected(t *testing.T, client *Client, code int, reason string) {
+	t.Helper()
+	message, open := <-client.Send
+	require.True(t, open)
+	assert.Equal(t, "disconnect_reason", message.Type)
+	assert.Equal(t, map[string]interface{}{"code": code, "reason": reason, "retry": false}, message.Data)
+	_, open = <-client.Send
+	assert.False(t, open)
+
+	cause := client.getCloseCause()
+	require.NotNil(t, cause)
+	assert.Equal(t, code, cause.code)
+	assert.False(t, cause.abnormal())
+}
+
+func TestHandler_KickUser(t *testing.T) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, nil)
+	defer handler.manager.Shutdown()
+
+	firstTab := newDisconnectTestClient(handler, "conn-1", "session-1", "user-1", "map-1")
+	secondTab := newDisconnectTestClient(handler, "conn-2", "session-1", "user-1", "map-1")
+	otherMap := newDisconnectTestClient(handler, "conn-3", "session-2", "user-1", "map-2")
+	other := newDisconnectTestClient(handler, "conn-4", "session-3", "user-2", "map-1")
+
+	assert.Equal(t, 2, handler.KickUser("map-1", "user-1"))
+
+	assertDisconnected(t, firstTab, CloseCodeKicked, CloseReasonKicked)
+	assertDisconnected(t, secondTab, CloseCodeKicked, CloseReasonKicked)
+	assert.True(t, handler.manager.IsClientConnected(otherMap.SessionID))
+	assert.True(t, handler.manager.IsClientConnected(other.SessionID))
+	assert.Zero(t, handler.KickUser("map-1", "user-1"))
+}
+
+func TestHandler_DisconnectMap(t *testing.T) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, nil)
+	defer handler.manager.Shutdown()
+
+	first := newDisconnectTestClient(handler, "conn-1", "session-1", "user-1", "map-1")
+	second := newDisconnectTestClient(handler, "conn-2", "session-2", "user-2", "map-1")
+	otherMap := newDisconnectTestClient(handler, "conn-3", "session-3", "user-3", "map-2")
+
+	assert.Equal(t, 2, handler.DisconnectMap("map-1"))
+
+	assertDisconnected(t, first, CloseCodeMapDeleted, CloseReasonMapDeleted)
+	assertDisconnected(t, second, CloseCodeMapDeleted, CloseReasonMapDeleted)
+	assert.Equal(t, 1, handler.manager.GetConnectedClients())
+	assert.True(t, handler.manager.IsClientConnected(otherMap.SessionID))
+}
+
+func TestHandler_MapDeletedEvent(t *testing.T) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, nil)
+	defer handler.manager.Shutdown()
+
+	client := newDisconnectTestClient(handler, "conn-1", "session-1", "user-1", "map-1")
+	otherMap := newDisconnectTestClient(handler, "conn-2", "session-2", "user-2", "map-2")
+
+	data, err := json.Marshal(redis.MapDeletedEvent{MapID: "map-1", DeletedBy: "owner-1"})
+	require.NoError(t, err)
+	redis.DispatchPOIEvent(redis.Event{Type: redis.EventTypeMapDeleted, Data: data}, handler.handlePubSubEvent)
+
+	assertDisconnected(t, client, CloseCodeMapDeleted, CloseReasonMapDeleted)
+	assert.True(t, handler.manager.IsClientConnected(otherMap.SessionID))
+}
+
+func TestHandler_CloseReplacedSessions(t *testing.T) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, nil)
+	defer handler.manager.Shutdown()
+
+	old := newDisconnectTestClient(handler, "conn-1", "session-old", "user-1", "map-1")
+	otherMap := newDisconnectTestClient(handler, "conn-2", "session-map-2", "user-1", "map-2")
+	tab := newDisconnectTestClient(handler, "conn-3", "session-new", "user-1", "map-1")
+	client := newDisconnectTestClient(handler, "conn-4", "session-new", "user-1", "map-1")
+
+	assert.Equal(t, []string{"session-old"}, handler.closeReplacedSessions(client))
+
+	assertDisconnected(t, old, CloseCodeDuplicateSession, CloseReasonDuplicateSession)
+	// Tabs sharing the new session and sessions on other maps stay connected
+	assert.Empty(t, tab.Send)
+	assert.True(t, handler.manager.IsClientConnected(otherMap.SessionID))
+	assert.True(t, handler.manager.IsClientConnected(client.SessionID))
+}
+
+func TestManager_DisconnectClient_FullBufferSkipsReason(t *testing.T) {
+	manager := NewManager()
+	defer manager.Shutdown()
+
+	client := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 1)}
+	manager.registerClient(client)
+	client.Send <- Message{Type: "user_joined"}
+
+	require.True(t, manager.disconnectClient(client, &closeCause{code: CloseCodeKicked, reason: CloseReasonKicked, sendable: true, final: true}))
+
+	// The close frame still tells the client why
+	assert.Equal(t, "user_joined", (<-client.Send).Type)
+	_, open := <-client.Send
+	assert.False(t, open)
+	assert.Contains(t, string(client.takeCloseFrame()), `"reason":"kicked"`)
+}
@@ -0,0 +1,213 @@
+package websocket
+
+import (
+	"context"
+	"time"
+
+	"breakoutglobe/internal/models"
+)
+
+// Reasons a facilitator's follow me mode ends
+const (
+	followStoppedByLeader = "stopped"
+	followStoppedOptedOut = "opted_out"
+	followStoppedLeft     = "leader_left"
+	followStoppedRole     = "role_changed"
+)
+
+// followState is a facilitator's follow me mode on a map. Everyone else on
+// the map follows the leader's avatar, unless they opted out. The state is
+// kept per instance, like topic subscriptions.
+type followState struct {
+	leaderUserID string
+	optedOut     map[string]bool // userID -> stopped following
+}
+
+// startFollow makes a user the leader of the map's follow me mode, taking it
+// over from another facilitator. It returns the previous leader, if any.
+func (m *Manager) startFollow(mapID, leaderUserID string) string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var previous string
+	if state := m.follows[mapID]; state != nil {
+		previous = state.leaderUserID
+	}
+	m.follows[mapID] = &followState{leaderUserID: leaderUserID, optedOut: make(map[string]bool)}
+	return previous
+}
+
+// stopFollow ends the map's follow me mode if the user leads it. It returns
+// false if they don't.
+func (m *Manager) stopFollow(mapID, leaderUserID string) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	state := m.follows[mapID]
+	if state == nil || state.leaderUserID != leaderUserID {
+		return false
+	}
+	delete(m.follows, mapID)
+	return true
+}
+
+// setFollowing opts a user in or out of the map's follow me mode and returns
+// its leader, or an empty string if nobody leads one
+func (m *Manager) setFollowing(mapID, userID string, following bool) string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	state := m.follows[mapID]
+	if state == nil {
+		return ""
+	}
+	if following {
+		delete(state.optedOut, userID)
+	} else {
+		state.optedOut[userID] = true
+	}
+	return state.leaderUserID
+}
+
+// FollowLeader returns the user leading the map's follow me mode, if any
+func (m *Manager) FollowLeader(mapID string) string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if state := m.follows[mapID]; state != nil {
+		return state.leaderUserID
+	}
+	return ""
+}
+
+// sendToFollowers delivers a message to the connections following the map's
+// leader, bypassing their viewport since their view tracks the leader
+func (m *Manager) sendToFollowers(mapID, leaderUserID string, message Message) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	state := m.follows[mapID]
+	if state == nil || state.leaderUserID != leaderUserID {
+		return
+	}
+	message = stampPublished(message)
+	for _, client := range m.mapClients[mapID] {
+		if client.UserID == leaderUserID || state.optedOut[client.UserID] {
+			continue
+		}
+		m.deliver(client, message)
+	}
+}
+
+// endFollowOf ends the map's follow me mode if the user leads it, telling the
+// map why. The caller must hold the lock.
+func (m *Manager) endFollowOf(mapID, userID, reason string) {
+	state := m.follows[mapID]
+	if state == nil || state.leaderUserID != userID {
+		return
+	}
+	delete(m.follows, mapID)
+	m.BroadcastToMap(mapID, followStoppedMessage(userID, reason))
+}
+
+// endFollowIfLeaderLeft ends the follow me mode of a client's map once the
+// last connection of its leader is gone. The caller must hold the lock.
+func (m *Manager) endFollowIfLeaderLeft(client *Client) {
+	for _, other := range m.mapClients[client.MapID] {
+		if other.UserID == client.UserID {
+			return
+		}
+	}
+	m.endFollowOf(client.MapID, client.UserID, followStoppedLeft)
+}
+
+// followStoppedMessage tells clients that they don't follow the leader anymore
+func followStoppedMessage(leaderUserID, reason string) Message {
+	return Message{
+		Type: "follow_stopped",
+		Data: map[string]interface{}{
+			"userId": leaderUserID,
+			"reason": reason,
+		},
+		Timestamp: time.Now(),
+	}
+}
+
+// handleFollowStart starts follow me mode when a facilitator sends it: the map
+// views of everyone else on the map track the facilitator's avatar with
+// camera_follow. Participants who opted out send it to follow again.
+func (h *Handler) handleFollowStart(ctx context.Context, client *Client, msg Message) {
+	if !h.manager.IsFacilitator(client) {
+		leader := h.manager.setFollowing(client.MapID, client.UserID, true)
+		if leader == "" {
+			h.sendFollowReply(ctx, client, Message{
+				Type: "error",
+				Data: map[string]interface{}{
+					"code":    "FORBIDDEN",
+					"message": "Only facilitators can start follow mode",
+				},
+				Timestamp: time.Now(),
+			})
+			return
+		}
+		h.sendFollowReply(ctx, client, Message{
+			Type:      "follow_started",
+			Data:      map[string]interface{}{"userId": leader},
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	previous := h.manager.startFollow(client.MapID, client.UserID)
+	h.requestLogger(ctx).Info("🎥 Follow mode started",
+		"mapId", client.MapID,
+		"userId", client.UserID,
+		"previousLeader", previous)
+
+	h.manager.BroadcastToMap(client.MapID, Message{
+		Type:      "follow_started",
+		Data:      map[string]interface{}{"userId": client.UserID},
+		Timestamp: time.Now(),
+	})
+}
+
+// handleFollowStop ends follow me mode when its leader sends it. Anyone else
+// stops following the leader.
+func (h *Handler) handleFollowStop(ctx context.Context, client *Client, msg Message) {
+	if h.manager.stopFollow(client.MapID, client.UserID) {
+		h.requestLogger(ctx).Info("🎥 Follow mode stopped", "mapId", client.MapID, "userId", client.UserID)
+		h.manager.BroadcastToMap(client.MapID, followStoppedMessage(client.UserID, followStoppedByLeader))
+		return
+	}
+
+	if leader := h.manager.setFollowing(client.MapID, client.UserID, false); leader != "" {
+		h.sendFollowReply(ctx, client, followStoppedMessage(leader, followStoppedOptedOut))
+	}
+}
+
+// followLeaderMoved points the followers' map views at the leader's new
+// position, coarsened on maps with position privacy
+func (h *Handler) followLeaderMoved(client *Client, privacy models.PositionPrivacy, position models.LatLng) {
+	if h.manager.FollowLeader(client.MapID) != client.UserID {
+		return
+	}
+
+	message := withCoarsePosition(Message{
+		Type: "camera_follow",
+		Data: map[string]interface{}{
+			"userId":   client.UserID,
+			"position": position,
+		},
+		Timestamp: time.Now(),
+	}, privacy, position)
+	h.manager.sendToFollowers(client.MapID, client.UserID, message)
+}
+
+// sendFollowReply answers a follow_start or follow_stop of the client
+func (h *Handler) sendFollowReply(ctx context.Context, client *Client, message Message) {
+	select {
+	case client.Send <- replyTo(ctx, message):
+	default:
+		h.requestLogger(ctx).Warn("Failed to send follow reply to client", "sessionId", client.SessionID, "type", message.Type)
+	}
+}
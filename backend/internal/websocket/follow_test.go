@@ -0,0 +1,80 @@
+package websocket
+
+import (
+	"context"
+	"testing"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFollowTestHandler(t *testing.T) (*Handler, *Client, *Client) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, nil)
+	t.Cleanup(handler.manager.Shutdown)
+	facilitator := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1", role: models.UserRoleAdmin, Send: make(chan Message, 10), movement: make(chan Message, 10)}
+	participant := &Client{SessionID: "session-2", UserID: "user-2", MapID: "map-1", Send: make(chan Message, 10), movement: make(chan Message, 10)}
+	handler.manager.registerClient(facilitator)
+	handler.manager.registerClient(participant)
+	return handler, facilitator, participant
+}
+
+func TestHandler_Follow(t *testing.T) {
+	handler, facilitator, participant := newFollowTestHandler(t)
+	ctx := context.Background()
+	position := models.LatLng{Lat: 52.52, Lng: 13.405}
+
+	handler.handleFollowStart(ctx, facilitator, Message{Type: "follow_start"})
+	assert.Equal(t, "user-1", handler.manager.FollowLeader("map-1"))
+	started := receiveType(t, participant, "follow_started")
+	assert.Equal(t, map[string]interface{}{"userId": "user-1"}, started.Data)
+
+	handler.followLeaderMoved(facilitator, models.PositionPrivacy{}, position)
+	camera := <-participant.movement
+	assert.Equal(t, "camera_follow", camera.Type)
+	assert.Equal(t, map[string]interface{}{"userId": "user-1", "position": position}, camera.Data)
+	assert.Empty(t, facilitator.movement)
+
+	// Followers can opt out and back in
+	handler.handleFollowStop(ctx, participant, Message{Type: "follow_stop"})
+	assert.Equal(t, followStoppedOptedOut, receiveType(t, participant, "follow_stopped").Data.(map[string]interface{})["reason"])
+	handler.followLeaderMoved(facilitator, models.PositionPrivacy{}, position)
+	assert.Empty(t, participant.movement)
+	handler.handleFollowStart(ctx, participant, Message{Type: "follow_start"})
+	receiveType(t, participant, "follow_started")
+	assert.Equal(t, "user-1", handler.manager.FollowLeader("map-1"))
+	handler.followLeaderMoved(facilitator, models.PositionPrivacy{}, position)
+	assert.Len(t, participant.movement, 1)
+
+	handler.handleFollowStop(ctx, facilitator, Message{Type: "follow_stop"})
+	assert.Empty(t, handler.manager.FollowLeader("map-1"))
+	assert.Equal(t, followStoppedByLeader, receiveType(t, participant, "follow_stopped").Data.(map[string]interface{})["reason"])
+}
+
+func TestHandler_FollowStart_RequiresFacilitator(t *testing.T) {
+	handler, _, participant := newFollowTestHandler(t)
+
+	handler.handleFollowStart(withRequestID(context.Background(), "req-1"), participant, Message{Type: "follow_start", RequestID: "req-1"})
+	rejected := receiveType(t, participant, "error")
+	assert.Equal(t, "FORBIDDEN", rejected.Data.(map[string]interface{})["code"])
+	assert.Equal(t, "req-1", rejected.RequestID)
+	assert.Empty(t, handler.manager.FollowLeader("map-1"))
+}
+
+func TestManager_FollowEndsWithLeader(t *testing.T) {
+	handler, facilitator, participant := newFollowTestHandler(t)
+	handler.handleFollowStart(context.Background(), facilitator, Message{Type: "follow_start"})
+	receiveType(t, participant, "follow_started")
+
+	handler.manager.SetUserRole("map-1", "user-1", models.UserRoleUser)
+	assert.Empty(t, handler.manager.FollowLeader("map-1"))
+	assert.Equal(t, followStoppedRole, receiveType(t, participant, "follow_stopped").Data.(map[string]interface{})["reason"])
+
+	handler.manager.SetUserRole("map-1", "user-1", models.UserRoleAdmin)
+	handler.handleFollowStart(context.Background(), facilitator, Message{Type: "follow_start"})
+	receiveType(t, participant, "follow_started")
+	handler.manager.unregisterClient(facilitator)
+	require.Empty(t, handler.manager.FollowLeader("map-1"))
+	assert.Equal(t, followStoppedLeft, receiveType(t, participant, "follow_stopped").Data.(map[string]interface{})["reason"])
+}
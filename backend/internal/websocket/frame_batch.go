@@ -0,0 +1,105 @@
+package websocket
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Defaults of SetFrameBatching
+const (
+	DefaultFrameBatchSize     = 20
+	DefaultFrameBatchInterval = 50 * time.Millisecond
+)
+
+// frameBatching is how many messages and for how long clients that opted in
+// collect before writing them in one frame
+type frameBatching struct {
+	maxMessages int
+	interval    time.Duration
+}
+
+// frameBatch collects the messages a client's write pump writes together as
+// a JSON array frame. It is only used by the write pump.
+type frameBatch struct {
+	frameBatching
+	pending []Message
+	timer   *time.Timer
+	// due fires once the oldest pending message waited for the interval; nil
+	// while nothing is pending
+	due <-chan time.Time
+}
+
+// SetFrameBatching lets clients that connect with frameBatching=true get up
+// to maxMessages messages in one frame, written once it is full or its first
+// message waited for the interval. A map of hundreds of clients then takes
+// far fewer writes per broadcast burst. Signaling messages are written right
+// away along with what was collected. Disabled if maxMessages is below 2 or
+// the interval isn't positive.
+func (h *Handler) SetFrameBatching(maxMessages int, interval time.Duration) {
+	if maxMessages < 2 || interval <= 0 {
+		h.frameBatching = nil
+		return
+	}
+	h.frameBatching = &frameBatching{maxMessages: maxMessages, interval: interval}
+}
+
+// frameBatchFor returns the frame batch of a connection, or nil if batching
+// is disabled or the client didn't opt in with the frameBatching query
+// parameter
+func (h *Handler) frameBatchFor(r *http.Request) *frameBatch {
+	if h.frameBatching == nil {
+		return nil
+	}
+	if optedIn, err := strconv.ParseBool(r.URL.Query().Get("frameBatching")); err != nil || !optedIn {
+		return nil
+	}
+
+	timer := time.NewTimer(h.frameBatching.interval)
+	timer.Stop()
+	return &frameBatch{
+		frameBatching: *h.frameBatching,
+		pending:       make([]Message, 0, h.frameBatching.maxMessages),
+		timer:         timer,
+	}
+}
+
+// batchMessage adds a message to the client's frame batch, writing the batch
+// if it is full or the message is signaling. It returns false if the write
+// failed.
+func (c *Client) batchMessage(message Message) bool {
+	batch := c.frames
+	batch.pending = append(batch.pending, message)
+	if len(batch.pending) >= batch.maxMessages || priorityOf(message.Type) == prioritySignaling {
+		return c.flushFrame()
+	}
+	if len(batch.pending) == 1 {
+		batch.timer.Reset(batch.interval)
+		batch.due = batch.timer.C
+	}
+	return true
+}
+
+// flushFrame writes the batched messages, if any. It returns false if the
+// write failed.
+func (c *Client) flushFrame() bool {
+	batch := c.frames
+	if batch == nil || len(batch.pending) == 0 {
+		return true
+	}
+
+	batch.timer.Stop()
+	batch.due = nil
+	messages := batch.pending
+	batch.pending = batch.pending[:0]
+	return c.writeFrame(messages)
+}
+
+// frameDue returns the channel that fires when the batched messages are due,
+// or nil if nothing is batched
+func (c *Client) frameDue() <-chan time.Time {
+	if c.frames == nil {
+		return nil
+	}
+	return c.frames.due
+}
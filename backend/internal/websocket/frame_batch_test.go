@@ -0,0 +1,91 @@
+package websocket
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/gin-gonic/gin"
+	ws "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_FrameBatching(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSessionService := new(MockSessionService)
+	mockPOIService := new(MockPOIService)
+	mockPOIService.On("GetCurrentPOI", mock.Anything, mock.Anything).Return("", nil).Maybe()
+	mockSessionService.On("GetSession", mock.Anything, "session-1").Return(&models.Session{
+		ID:        "session-1",
+		UserID:    "user-1",
+		MapID:     "map-1",
+		IsActive:  true,
+		AvatarPos: models.LatLng{Lat: 52.52, Lng: 13.405},
+	}, nil)
+	handler := NewHandler(mockSessionService, new(MockRateLimiter), nil, mockPOIService)
+	defer handler.manager.Shutdown()
+	handler.SetFrameBatching(3, 50*time.Millisecond)
+
+	router := gin.New()
+	handler.RegisterRoutes(router, nil)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws?" + sessionQuery(handler, "session-1") + "&frameBatching=true"
+
+	conn, _, err := ws.DefaultDialer.Dial(wsURL, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Signaling messages like welcome are written right away, on their own
+	var welcomeMsg Message
+	require.NoError(t, conn.ReadJSON(&welcomeMsg))
+	assert.Equal(t, "welcome", welcomeMsg.Type)
+	assert.Equal(t, map[string]interface{}{"maxMessages": float64(3), "intervalMs": float64(50)}, welcomeMsg.Data.(map[string]interface{})["frameBatching"])
+
+	// A lone message is written as an object once the interval passed
+	var initialUsersMsg Message
+	require.NoError(t, conn.ReadJSON(&initialUsersMsg))
+	assert.Equal(t, "initial_users", initialUsersMsg.Type)
+
+	for _, poiID := range []string{"poi-1", "poi-2", "poi-3"} {
+		require.NoError(t, handler.manager.BroadcastToMap("map-1", Message{Type: "poi_created", Data: map[string]interface{}{"poiId": poiID}}))
+	}
+
+	_, frame, err := conn.ReadMessage()
+	require.NoError(t, err)
+	var batch []Message
+	require.NoError(t, json.Unmarshal(frame, &batch))
+	require.Len(t, batch, 3)
+	for i, message := range batch {
+		assert.Equal(t, "poi_created", message.Type)
+		assert.Equal(t, uint64(i+1), message.Seq)
+	}
+}
+
+func TestHandler_FrameBatchFor(t *testing.T) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, new(MockPOIService))
+	request := httptest.NewRequest("GET", "/ws?frameBatching=true", nil)
+
+	// Disabled by default
+	assert.Nil(t, handler.frameBatchFor(request))
+
+	handler.SetFrameBatching(DefaultFrameBatchSize, DefaultFrameBatchInterval)
+	batch := handler.frameBatchFor(request)
+	require.NotNil(t, batch)
+	assert.Equal(t, DefaultFrameBatchSize, batch.maxMessages)
+	assert.Nil(t, batch.due)
+
+	// Clients that didn't opt in get a message per frame
+	assert.Nil(t, handler.frameBatchFor(httptest.NewRequest("GET", "/ws", nil)))
+	assert.Nil(t, handler.frameBatchFor(httptest.NewRequest("GET", "/ws?frameBatching=false", nil)))
+
+	handler.SetFrameBatching(1, DefaultFrameBatchInterval)
+	assert.Nil(t, handler.frameBatchFor(request))
+}
@@ -0,0 +1,167 @@
+package websocket
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// maxFuzzMessageSize bounds the fuzzed messages, like a proxy's frame limit would
+const maxFuzzMessageSize = 64 * 1024
+
+// fuzzSeeds are valid and adversarial messages the fuzzers start from
+var fuzzSeeds = []string{
+	`{"type":"heartbeat"}`,
+	`{"type":"avatar_move","data":{"position":{"lat":52.52,"lng":13.405}},"requestId":"req-1"}`,
+	`{"type":"avatar_move","data":{"position":{"lat":1e308,"lng":-1e308}}}`,
+	`{"type":"avatar_move","data":{"position":"north"}}`,
+	`{"type":"avatar_move_batch","data":{"positions":[{"lat":1,"lng":2,"t":0},{"lat":1,"lng":2}]}}`,
+	`{"type":"poi_join","data":{"poiId":"poi-1"}}`,
+	`{"type":"poi_leave","data":{"poiId":""}}`,
+	`{"type":"speaking_state","data":{"poiId":"poi-1","speaking":"yes"}}`,
+	`{"type":"call_request","data":{"targetUserId":"user-2","callId":"call-1"}}`,
+	`{"type":"call_accept","data":{"callId":null}}`,
+	`{"type":"webrtc_offer","data":{"targetUserId":"user-2","sdp":{"type":"offer","sdp":"v=0"}}}`,
+	`{"type":"ice_candidate","data":{"candidate":[1,2,3]}}`,
+	`{"type":"viewport_update","data":{"north":91,"south":-91,"east":181,"west":-181}}`,
+	`{"type":"resync_from","data":{"lastSeq":-1}}`,
+	`{"type":"subscribe","data":{"topic":"poi:"}}`,
+	`{"type":"reaction","data":{"emoji":"👍","targetUserId":"user-2"}}`,
+	`{"type":"map_freeze","data":{"durationSeconds":-5}}`,
+	`{"type":"presenting_state","data":{"presenting":true}}`,
+	`{"type":"follow_start"}`,
+	`{"type":"auth_refresh","data":{"token":42}}`,
+	`{"type":"heartbeat","version":999}`,
+	`{"type":"no_such_type","data":{}}`,
+	`{"type":"avatar_move","data":[]}`,
+	`{"type":"avatar_move","data":"\u0000"}`,
+	`{"type":"","data":null}`,
+	`{"type":"poi_join","data":{"poiId":"poi-1","poiId":"poi-2"}}`,
+	`[{"type":"heartbeat"}]`,
+	`{"type":{"nested":{"deeply":[[[[[[[[[[]]]]]]]]]]}}}`,
+}
+
+// newFuzzHandler returns a handler whose mocked services accept every call,
+// and a registered client to send messages as. The handler's manager is shut
+// down when the fuzz iteration ends.
+func newFuzzHandler(t *testing.T) (*Handler, *Client) {
+	session := &models.Session{ID: "session-1", UserID: "user-1", MapID: "map-1", AvatarPos: models.LatLng{Lat: 52.52, Lng: 13.405}}
+
+	sessionService := new(MockSessionService)
+	sessionService.On("GetSession", mock.Anything, mock.Anything).Return(session, nil).Maybe()
+	sessionService.On("SessionHeartbeat", mock.Anything, mock.Anything).Return(nil).Maybe()
+	sessionService.On("UpdateAvatarPosition", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	rateLimiter := new(MockRateLimiter)
+	rateLimiter.On("CheckRateLimit", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	poiService := new(MockPOIService)
+	poiService.On("JoinPOI", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	poiService.On("LeavePOI", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	poiService.On("GetPOIParticipantsWithInfo", mock.Anything, mock.Anything).Return([]services.POIParticipantInfo{{ID: "user-1"}}, nil).Maybe()
+	poiService.On("GetPOIParticipantCount", mock.Anything, mock.Anything).Return(1, nil).Maybe()
+	poiService.On("GetCurrentPOI", mock.Anything, mock.Anything).Return("", nil).Maybe()
+	poiService.On("GetPOIsForMap", mock.Anything, mock.Anything).Return([]*models.POI{{ID: "poi-1", MapID: "map-1"}}, nil).Maybe()
+
+	handler := NewHandler(sessionService, rateLimiter, nil, poiService)
+	t.Cleanup(handler.manager.Shutdown)
+	client := &Client{
+		SessionID: "session-1",
+		UserID:    "user-1",
+		MapID:     "map-1",
+		role:      models.UserRoleAdmin,
+		Send:      make(chan Message, 256),
+		movement:  make(chan Message, 256),
+		signaling: make(chan Message, 256),
+	}
+	handler.manager.registerClient(client)
+	handler.manager.registerClient(&Client{SessionID: "session-2", UserID: "user-2", MapID: "map-1", Send: make(chan Message, 256)})
+	return handler, client
+}
+
+// decodeFuzzMessage decodes a fuzzed frame as the read pump does. It returns
+// false for frames the read pump would reject before validating them.
+func decodeFuzzMessage(data []byte) (Message, bool) {
+	if len(data) > maxFuzzMessageSize {
+		return Message{}, false
+	}
+	var incoming incomingMessage
+	if err := json.Unmarshal(data, &incoming); err != nil {
+		return Message{}, false
+	}
+	return incoming.message(), true
+}
+
+func FuzzValidateMessage(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		msg, ok := decodeFuzzMessage(data)
+		if !ok {
+			return
+		}
+
+		// Validation allocates in proportion to the payload, not beyond
+		allocs := testing.AllocsPerRun(1, func() {
+			validateMessage(msg)
+		})
+		if limit := float64(64 + len(data)); allocs > limit {
+			t.Fatalf("validating %d bytes allocated %.0f times, more than %.0f", len(data), allocs, limit)
+		}
+	})
+}
+
+func FuzzHandleMessage(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add([]byte(seed))
+	}
+	// Every registered type with an adversarial payload
+	for _, messageType := range MessageTypes(ProtocolVersion) {
+		f.Add([]byte(`{"type":"` + messageType + `","data":{"poiId":"poi-1","targetUserId":"user-2","callId":"c","topic":"user:user-2"}}`))
+		f.Add([]byte(`{"type":"` + messageType + `","data":{"poiId":1,"targetUserId":[],"callId":{},"topic":null}}`))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		msg, ok := decodeFuzzMessage(data)
+		if !ok {
+			return
+		}
+		// The read pump answers invalid messages itself
+		if validateMessage(msg) != nil {
+			return
+		}
+
+		handler, client := newFuzzHandler(t)
+		handler.handleMessage(client, msg)
+
+		// Errors tell the client what went wrong and which message caused it
+		deadline := time.After(10 * time.Millisecond)
+		for {
+			select {
+			case reply := <-client.Send:
+				if reply.Type != "error" {
+					continue
+				}
+				payload, ok := reply.Data.(map[string]interface{})
+				if !ok {
+					t.Fatalf("error reply to %s has no structured data: %#v", msg.Type, reply.Data)
+				}
+				if message, _ := payload["message"].(string); message == "" {
+					t.Fatalf("error reply to %s has no message: %#v", msg.Type, payload)
+				}
+				if reply.RequestID != msg.RequestID {
+					t.Fatalf("error reply to %s has requestId %q, want %q", msg.Type, reply.RequestID, msg.RequestID)
+				}
+			case <-deadline:
+				return
+			}
+		}
+	})
+}
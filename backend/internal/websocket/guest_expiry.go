@@ -0,0 +1,51 @@
+package websocket
+
+import (
+	"context"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+)
+
+// GuestExpiryWarnerInterface defines the interface for telling guests their
+// profile is about to be purged
+type GuestExpiryWarnerInterface interface {
+	ExpiryWarning(ctx context.Context, user *models.User) (*services.GuestExpiry, error)
+}
+
+// SetGuestExpiryWarner warns guests nearing the end of guest retention when
+// they connect, so they can sign up before their profile is purged
+func (h *Handler) SetGuestExpiryWarner(warner GuestExpiryWarnerInterface) {
+	h.guestExpiry = warner
+}
+
+// sendGuestExpiryWarning sends guest_expiry_warning to a connecting guest
+// whose profile expires soon
+func (h *Handler) sendGuestExpiryWarning(ctx context.Context, client *Client, user *models.User) {
+	if h.guestExpiry == nil {
+		return
+	}
+
+	expiry, err := h.guestExpiry.ExpiryWarning(ctx, user)
+	if err != nil {
+		h.logger.Warn("Failed to check guest expiry", "userId", user.ID, "error", err)
+		return
+	}
+	if expiry == nil {
+		return
+	}
+
+	select {
+	case client.Send <- Message{
+		Type: "guest_expiry_warning",
+		Data: map[string]interface{}{
+			"expiresAt":     expiry.ExpiresAt,
+			"retentionDays": expiry.RetentionDays,
+		},
+		Timestamp: time.Now(),
+	}:
+	default:
+		h.logger.Warn("Failed to send guest expiry warning", "sessionId", client.SessionID)
+	}
+}
@@ -0,0 +1,42 @@
+package websocket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeGuestExpiryWarner struct {
+	expiring map[string]*services.GuestExpiry
+}
+
+func (w *fakeGuestExpiryWarner) ExpiryWarning(ctx context.Context, user *models.User) (*services.GuestExpiry, error) {
+	return w.expiring[user.ID], nil
+}
+
+func TestHandler_SendGuestExpiryWarning(t *testing.T) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, nil)
+	defer handler.manager.Shutdown()
+	expiresAt := time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC)
+	handler.SetGuestExpiryWarner(&fakeGuestExpiryWarner{expiring: map[string]*services.GuestExpiry{
+		"guest-1": {ExpiresAt: expiresAt, RetentionDays: 30},
+	}})
+
+	client := &Client{SessionID: "session-1", UserID: "guest-1", MapID: "map-1", Send: make(chan Message, 10)}
+	handler.sendGuestExpiryWarning(context.Background(), client, &models.User{ID: "guest-1"})
+	require.Len(t, client.Send, 1)
+	warning := <-client.Send
+	assert.Equal(t, "guest_expiry_warning", warning.Type)
+	assert.Equal(t, map[string]interface{}{"expiresAt": expiresAt, "retentionDays": 30}, warning.Data)
+
+	// Users not nearing expiry aren't warned
+	other := &Client{SessionID: "session-2", UserID: "user-2", MapID: "map-1", Send: make(chan Message, 10)}
+	handler.sendGuestExpiryWarning(context.Background(), other, &models.User{ID: "user-2"})
+	assert.Empty(t, other.Send)
+}
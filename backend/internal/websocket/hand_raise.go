@@ -0,0 +1,135 @@
+package websocket
+
+import (
+	"context"
+	"time"
+)
+
+// HandQueueInterface defines the interface for the raise-hand queue of maps
+type HandQueueInterface interface {
+	RaiseHand(ctx context.Context, mapID, userID string) (bool, error)
+	LowerHand(ctx context.Context, mapID, userID, loweredBy string) (bool, error)
+}
+
+// SetHandQueue enables the hand_raise and hand_lower messages. Without it,
+// participants can't raise their hand.
+func (h *Handler) SetHandQueue(hands HandQueueInterface) {
+	h.handQueue = hands
+}
+
+// HandLowerPayload is the data of hand_lower messages. Without a user, the
+// client lowers its own hand.
+type HandLowerPayload struct {
+	UserID string `json:"userId,omitempty"`
+}
+
+// Validate accepts any user; whether the client may lower their hand is
+// checked against its role when handled
+func (p HandLowerPayload) Validate() error {
+	return nil
+}
+
+// validateHandLower validates hand_lower messages, whose user is optional
+func validateHandLower(msg Message) error {
+	if msg.Data == nil {
+		return nil
+	}
+	var payload HandLowerPayload
+	if err := decodePayload(msg, &payload); err != nil {
+		return err
+	}
+	return payload.Validate()
+}
+
+// handleHandRaise puts the client's user at the end of their map's raise-hand
+// queue. Raising a raised hand again keeps its place.
+func (h *Handler) handleHandRaise(ctx context.Context, client *Client, msg Message) {
+	if h.handQueue == nil {
+		h.sendErrorMessage(ctx, client, "Raising hands is not available")
+		return
+	}
+
+	raised, err := h.handQueue.RaiseHand(ctx, client.MapID, client.UserID)
+	if err != nil {
+		h.requestLogger(ctx).Error("Failed to raise hand", "mapId", client.MapID, "userId", client.UserID, "error", err)
+		h.sendErrorMessage(ctx, client, "Failed to raise hand")
+		return
+	}
+
+	if raised {
+		h.requestLogger(ctx).Info("✋ Hand raised", "mapId", client.MapID, "userId", client.UserID)
+	}
+}
+
+// handleHandLower takes a user out of the client's map's raise-hand queue.
+// Participants lower their own hand; facilitators also lower the hand of the
+// participant they call on.
+func (h *Handler) handleHandLower(ctx context.Context, client *Client, msg Message) {
+	var payload HandLowerPayload
+	if msg.Data != nil && !h.decodeMessagePayload(client, msg, &payload) {
+		return
+	}
+	if h.handQueue == nil {
+		h.sendErrorMessage(ctx, client, "Raising hands is not available")
+		return
+	}
+
+	userID := client.UserID
+	if payload.UserID != "" && payload.UserID != client.UserID {
+		if !h.manager.IsFacilitator(client) {
+			h.sendHandReply(ctx, client, Message{
+				Type: "error",
+				Data: map[string]interface{}{
+					"code":    "FORBIDDEN",
+					"message": "Only facilitators can lower other participants' hands",
+				},
+				Timestamp: time.Now(),
+			})
+			return
+		}
+		userID = payload.UserID
+	}
+
+	lowered, err := h.handQueue.LowerHand(ctx, client.MapID, userID, client.UserID)
+	if err != nil {
+		h.requestLogger(ctx).Error("Failed to lower hand", "mapId", client.MapID, "userId", userID, "error", err)
+		h.sendErrorMessage(ctx, client, "Failed to lower hand")
+		return
+	}
+
+	if lowered {
+		h.requestLogger(ctx).Info("✋ Hand lowered", "mapId", client.MapID, "userId", userID, "loweredBy", client.UserID)
+	}
+}
+
+// handleHandQueueEvent tells the clients of a map about a raise-hand queue
+// change published by any instance
+func (h *Handler) handleHandQueueEvent(data interface{}) {
+	handData, ok := data.(map[string]interface{})
+	if !ok {
+		h.logger.Error("❌ Invalid hand queue event data", "data", data)
+		return
+	}
+
+	mapID, _ := handData["mapId"].(string)
+	if mapID == "" {
+		h.logger.Error("❌ Missing mapId in hand queue event", "data", data)
+		return
+	}
+
+	h.manager.BroadcastToMap(mapID, Message{
+		Type:        "hand_queue_updated",
+		Data:        handData,
+		Timestamp:   time.Now(),
+		publishedAt: eventPublishedAt(handData),
+	})
+}
+
+// sendHandReply answers a hand_raise or hand_lower of the client
+func (h *Handler) sendHandReply(ctx context.Context, client *Client, message Message) {
+	select {
+	case client.Send <- replyTo(ctx, message):
+	default:
+		h.requestLogger(ctx).Warn("Failed to send hand reply to client", "sessionId", client.SessionID, "type", message.Type)
+	}
+}
@@ -0,0 +1,93 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"breakoutglobe/internal/memory"
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/redis"
+	"breakoutglobe/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dispatchingHandQueuePublisher hands published events straight to the
+// handler, like the PubSub listener does
+type dispatchingHandQueuePublisher struct {
+	handler *Handler
+}
+
+func (p *dispatchingHandQueuePublisher) PublishHandQueueUpdated(ctx context.Context, event redis.HandQueueEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	redis.DispatchPOIEvent(redis.Event{Type: redis.EventTypeHandQueueUpdated, Data: data}, p.handler.handlePubSubEvent)
+	return nil
+}
+
+func newHandRaiseTestHandler(t *testing.T) (*Handler, *services.HandRaiseService, *Client, *Client) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, nil)
+	t.Cleanup(handler.manager.Shutdown)
+	hands := services.NewHandRaiseService(memory.NewHandQueue(), &dispatchingHandQueuePublisher{handler: handler})
+	handler.SetHandQueue(hands)
+
+	facilitator := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1", role: models.UserRoleAdmin, Send: make(chan Message, 10)}
+	participant := &Client{SessionID: "session-2", UserID: "user-2", MapID: "map-1", Send: make(chan Message, 10)}
+	handler.manager.registerClient(facilitator)
+	handler.manager.registerClient(participant)
+	return handler, hands, facilitator, participant
+}
+
+func TestHandler_HandRaise(t *testing.T) {
+	handler, hands, facilitator, participant := newHandRaiseTestHandler(t)
+	ctx := context.Background()
+
+	handler.handleHandRaise(ctx, participant, Message{Type: "hand_raise"})
+	updated := receiveType(t, facilitator, "hand_queue_updated")
+	data := updated.Data.(map[string]interface{})
+	assert.Equal(t, services.HandRaised, data["action"])
+	assert.Equal(t, "user-2", data["userId"])
+	receiveType(t, participant, "hand_queue_updated")
+
+	queue, err := hands.GetHands(ctx, "map-1")
+	require.NoError(t, err)
+	require.Len(t, queue, 1)
+	assert.Equal(t, "user-2", queue[0].UserID)
+
+	// The facilitator calls on the participant
+	handler.handleHandLower(ctx, facilitator, Message{Type: "hand_lower", Data: map[string]interface{}{"userId": "user-2"}})
+	data = receiveType(t, participant, "hand_queue_updated").Data.(map[string]interface{})
+	assert.Equal(t, services.HandLowered, data["action"])
+	assert.Equal(t, "user-1", data["loweredBy"])
+	assert.Empty(t, data["hands"])
+}
+
+func TestHandler_HandLower_OthersRequiresFacilitator(t *testing.T) {
+	handler, hands, facilitator, participant := newHandRaiseTestHandler(t)
+	ctx := withRequestID(context.Background(), "req-1")
+
+	handler.handleHandRaise(ctx, facilitator, Message{Type: "hand_raise"})
+	receiveType(t, participant, "hand_queue_updated")
+
+	handler.handleHandLower(ctx, participant, Message{Type: "hand_lower", Data: map[string]interface{}{"userId": "user-1"}, RequestID: "req-1"})
+	rejected := receiveType(t, participant, "error")
+	assert.Equal(t, "FORBIDDEN", rejected.Data.(map[string]interface{})["code"])
+	assert.Equal(t, "req-1", rejected.RequestID)
+
+	queue, err := hands.GetHands(ctx, "map-1")
+	require.NoError(t, err)
+	assert.Len(t, queue, 1)
+}
+
+func TestHandler_HandRaise_Unavailable(t *testing.T) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, nil)
+	defer handler.manager.Shutdown()
+	client := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 10)}
+
+	handler.handleHandRaise(context.Background(), client, Message{Type: "hand_raise"})
+	assert.Equal(t, "Raising hands is not available", receiveError(t, client)["message"])
+}
@@ -2,18 +2,25 @@ package websocket
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
-	"strings"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"breakoutglobe/internal/analytics"
+	"breakoutglobe/internal/logexport"
 	"breakoutglobe/internal/models"
 	"breakoutglobe/internal/services"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	ws "github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // Message represents a WebSocket message
@@ -21,18 +28,128 @@ type Message struct {
 	Type      string      `json:"type"`
 	Data      interface{} `json:"data"`
 	Timestamp time.Time   `json:"timestamp"`
+	// Version is the protocol version of the message. Messages from clients
+	// without one are treated as the legacy version.
+	Version int `json:"version,omitempty"`
+	// Seq numbers the map broadcasts of a lane per connection, so clients can
+	// detect missed broadcasts and send a resync. Unset on direct messages.
+	Seq  uint64 `json:"seq,omitempty"`
+	Lane string `json:"lane,omitempty"`
+	// MapSeq is the map-wide journal sequence number of a broadcast, used to
+	// request a replay with resync_from
+	MapSeq uint64 `json:"mapSeq,omitempty"`
+	// RequestID is set by clients that want to correlate the replies to a
+	// message, like acks and errors, and echoed back in them
+	RequestID string `json:"requestId,omitempty"`
+
+	// publishedAt is when the broadcast or the event it was created from was
+	// published, used to measure delivery latency
+	publishedAt time.Time
+	// coarseData replaces Data for recipients who aren't facilitators on maps
+	// with position privacy
+	coarseData interface{}
+	// positions are where the broadcast happens, for clients with a viewport;
+	// coarsePositions are the ones regular participants see on maps with
+	// position privacy
+	positions       []models.LatLng
+	coarsePositions []models.LatLng
+	// topics are the subscription topics of the broadcast, see onTopics
+	topics []string
 }
 
 // Client represents a WebSocket client connection
 type Client struct {
+	// connectionID identifies the connection, since tabs of a user share their session
+	connectionID string
 	SessionID string
 	UserID    string
 	MapID     string
 	Conn      *ws.Conn
 	Send      chan Message
 	Manager   *Manager
+	
+	// signaling and movement are the lanes written before and after Send; see priorityOf
+	signaling chan Message
+	movement  chan Message
+
+	// ClientVersion is the app version the client reported when connecting
+	ClientVersion string
+	// protocolVersion is the message protocol version negotiated when connecting
+	protocolVersion int
+	// UserAgent is the user agent of the connecting browser
+	UserAgent string
+	// ConnectedAt is when the connection was established
+	ConnectedAt time.Time
+
+	// features are the rolled out features enabled for the user at connect time
+	features map[models.Feature]bool
+	// role is the user's role, kept current by role_changed events. Guarded by the manager's mutex.
+	role models.UserRole
+	// viewport is the map area the client displays, plus a margin. Clients
+	// without one get all broadcasts. Guarded by the manager's mutex.
+	viewport *models.Bounds
+	// topics are the topics the client subscribed to. Guarded by the manager's mutex.
+	topics map[string]bool
+
+	// rateLimitWarnedUntil suppresses repeated rate limit warnings per action
+	rateLimitWarnedUntil map[services.ActionType]time.Time
+	// lastPosition is the avatar position last stored for the session
+	lastPosition *models.LatLng
+	// lastMessageType is the type of the last message read, kept for connection error reports
+	lastMessageType string
+	// slowConsumer is the map's policy for a full send buffer, fixed at connect time
+	slowConsumer models.SlowConsumerPolicy
+	// droppedMessages counts broadcasts dropped in a row under a dropping policy
+	droppedMessages atomic.Int32
+	// laggingWarnedAt is when the client was last sent client_lagging. Guarded
+	// by the manager's mutex.
+	laggingWarnedAt time.Time
+	// slowConsumerEvents counts lagging warnings, drops and evictions, if metrics are enabled
+	slowConsumerEvents *prometheus.CounterVec
+	// sequences are the last broadcast sequence numbers per lane, indexed by messagePriority
+	sequences [laneCount]atomic.Uint64
+	// lastResyncAt limits how often the client can request a state snapshot
+	lastResyncAt time.Time
+	// deliveryLatency records the age of broadcasts when written, if metrics are enabled
+	deliveryLatency *prometheus.HistogramVec
+	// compressionThreshold is the size from which messages are compressed, 0
+	// if the client doesn't support compression
+	compressionThreshold int
+	// frames collects messages written together in one frame, if the client
+	// opted in to frame batching
+	frames *frameBatch
+	// payloadBytes counts written bytes by compression, if metrics are enabled
+	payloadBytes *prometheus.CounterVec
+	// audit exports written messages sampled by the map's message audit policy
+	audit func(direction string, message Message)
+	// usage meters written bytes against the map, if usage is metered
+	usage UsageRecorderInterface
+	// tokenExpiresAt is when the JWT last sent with auth_refresh expires, in
+	// Unix nanoseconds, or 0 if the client never sent one
+	tokenExpiresAt atomic.Int64
+	// lastHeartbeat is when the client last sent a heartbeat message, in Unix
+	// nanoseconds, starting at registration
+	lastHeartbeat atomic.Int64
+	// lastActivity is when the client last showed user activity, like
+	// activity_ping or avatar moves, in Unix nanoseconds, starting at
+	// registration
+	lastActivity atomic.Int64
+	
+	// closeCause is why the connection ends, once known
+	closeMutex     sync.Mutex
+	closeCause     *closeCause
+	closeFrameSent bool
+	
+	// drain is closed when the server shuts down, and writeDone once the write
+	// pump wrote the queued messages and stopped
+	drain     chan struct{}
+	drainOnce sync.Once
+	writeDone chan struct{}
 }
 
+// DefaultMovementDeadZoneMeters is the default minimum distance an avatar move must cover to be stored
+const DefaultMovementDeadZoneMeters = 0.5
+
 // SessionServiceInterface defines the interface for session operations
 type SessionServiceInterface interface {
 	GetSession(ctx context.Context, sessionID string) (*models.Session, error)
@@ -45,6 +162,12 @@ type RateLimiterInterface interface {
 	CheckRateLimit(ctx context.Context, userID string, action services.ActionType) error
 }
 
+// BurstLimiterInterface defines the interface for token bucket rate limits per map
+type BurstLimiterInterface interface {
+	HasBurstLimit(mapID string, action services.ActionType) bool
+	CheckBurstLimit(ctx context.Context, userID, mapID string, action services.ActionType) (*services.RateLimitWarning, error)
+}
+
 // UserServiceInterface defines the interface for user operations
 type UserServiceInterface interface {
 	GetUser(ctx context.Context, userID string) (*models.User, error)
@@ -56,6 +179,8 @@ type POIServiceInterface interface {
 	LeavePOI(ctx context.Context, poiID, userID string) error
 	GetPOIParticipantsWithInfo(ctx context.Context, poiID string) ([]services.POIParticipantInfo, error)
 	GetPOIParticipantCount(ctx context.Context, poiID string) (int, error)
+	GetCurrentPOI(ctx context.Context, userID string) (string, error)
+	GetPOIsForMap(ctx context.Context, mapID string) ([]*models.POI, error)
 }
 
 // PubSubInterface defines the interface for PubSub operations
@@ -63,27 +188,155 @@ type PubSubInterface interface {
 	SubscribePOIEvents(ctx context.Context, callback func(eventType string, data interface{})) error
 }
 
+// AbuseGuardInterface defines the interface for abuse heuristics
+type AbuseGuardInterface interface {
+	RecordSignal(ctx context.Context, userID, mapID string, signal services.AbuseSignal) []*services.Restriction
+	IsRestricted(ctx context.Context, userID string, restriction services.RestrictionType) bool
+}
+
+// PresenceCheckerInterface defines the interface for heartbeat-based presence checks
+type PresenceCheckerInterface interface {
+	FilterPresentSessions(ctx context.Context, sessionIDs []string) ([]string, error)
+}
+
+// PersonalSpaceProviderInterface defines the interface for per-map personal space settings
+type PersonalSpaceProviderInterface interface {
+	GetPersonalSpace(ctx context.Context, mapID string) (models.PersonalSpace, error)
+}
+
+// SlowConsumerPolicyProviderInterface defines the interface for per-map slow consumer policies
+type SlowConsumerPolicyProviderInterface interface {
+	GetSlowConsumerPolicy(ctx context.Context, mapID string) (models.SlowConsumerPolicy, error)
+}
+
+// PositionPrivacyProviderInterface defines the interface for per-map position privacy settings
+type PositionPrivacyProviderInterface interface {
+	GetPositionPrivacy(ctx context.Context, mapID string) (models.PositionPrivacy, error)
+}
+
+// POIRosterProviderInterface defines the interface for full POI roster snapshots
+type POIRosterProviderInterface interface {
+	GetPOIRostersForMap(ctx context.Context, mapID string) ([]services.POIRoster, error)
+}
+
+// EventExporterInterface defines the interface for exporting connection events to the log sink
+type EventExporterInterface interface {
+	Export(category string, fields map[string]interface{})
+}
+
+// AnalyticsInterface defines the interface for reporting product events
+type AnalyticsInterface interface {
+	Track(name, userID string, properties map[string]interface{})
+}
+
+// FeatureRolloutInterface defines the interface for gradually rolled out features
+type FeatureRolloutInterface interface {
+	EnabledFeatures(ctx context.Context, userID string) []models.Feature
+}
+
+// maxAvatarMoveBatchSize bounds the positions in one avatar_move_batch message
+const maxAvatarMoveBatchSize = 50
+
+// featureGatedMessages are message types that are only routed for clients with the feature enabled
+var featureGatedMessages = map[string]models.Feature{
+	"avatar_move_batch": models.FeatureBatchedMovement,
+}
+
+// defaultMessageRateLimits returns the rate limit bucket of each limited message
+// type. Avatar moves share the REST bucket, which per-map burst limits apply to.
+func defaultMessageRateLimits() map[string]services.ActionType {
+	return map[string]services.ActionType{
+		"avatar_move":            services.ActionUpdateAvatar,
+		"avatar_move_batch":      services.ActionUpdateAvatar,
+		"poi_join":               services.ActionWSPOIJoin,
+		"poi_leave":              services.ActionWSPOILeave,
+		"speaking_state":         services.ActionWSSpeaking,
+		"call_request":           services.ActionWSCall,
+		"call_accept":            services.ActionWSCall,
+		"call_reject":            services.ActionWSCall,
+		"call_end":               services.ActionWSCall,
+		"webrtc_offer":           services.ActionWSSignal,
+		"webrtc_answer":          services.ActionWSSignal,
+		"ice_candidate":          services.ActionWSSignal,
+		"poi_call_offer":         services.ActionWSSignal,
+		"poi_call_answer":        services.ActionWSSignal,
+		"poi_call_ice_candidate": services.ActionWSSignal,
+		"reaction":               services.ActionWSReaction,
+		"hand_raise":             services.ActionWSHand,
+		"hand_lower":             services.ActionWSHand,
+	}
+}
+
 // Handler handles WebSocket connections and messages
 type Handler struct {
 	sessionService SessionServiceInterface
 	rateLimiter    RateLimiterInterface
+	burstLimiter   BurstLimiterInterface
+	// Rate limit bucket per message type, messages of other types aren't limited
+	messageRateLimits map[string]services.ActionType
 	userService    UserServiceInterface
 	poiService     POIServiceInterface
 	pubsub         PubSubInterface
+	abuseGuard     AbuseGuardInterface
+	presence       PresenceCheckerInterface
+	personalSpace  PersonalSpaceProviderInterface
+	slowConsumer   SlowConsumerPolicyProviderInterface
+	privacy        PositionPrivacyProviderInterface
+	profilePrivacy ProfilePrivacyProviderInterface
+	rollout        FeatureRolloutInterface
+	rosters        POIRosterProviderInterface
+	reconciler     POIParticipantReconcilerInterface
+	activeSessions ActiveSessionProviderInterface
+	mapFreeze      MapFreezeInterface
+	handQueue      HandQueueInterface
+	summon         SummonInterface
+	moderation     ModerationInterface
+	tokens         TokenValidatorInterface
+	maintenance    MaintenanceInterface
+	connectionErrors ConnectionErrorRecorderInterface
+	journal        EventJournalInterface
+	reconnects     *reconnectTokens
+	// sessionTokens prove clients own the session they connect with
+	sessionTokens  *services.SessionTokens
+	deliveryLatency *prometheus.HistogramVec
+	payloadBytes   *prometheus.CounterVec
+	slowConsumerEvents *prometheus.CounterVec
+	// Messages of at least this many bytes are compressed; 0 disables compression
+	compressionThreshold int
+	// frameBatching is offered to clients if set, see frame_batch.go
+	frameBatching  *frameBatching
+	eventExporter  EventExporterInterface
+	messageAudit   MessageAuditPolicyProviderInterface
+	// auditSample draws the number compared against a map's audit sample rate
+	auditSample    func() float64
+	analytics      AnalyticsInterface
+	usage          UsageRecorderInterface
+	guestExpiry    GuestExpiryWarnerInterface
 	manager        *Manager
+	avatars        *avatarPositions
+	speakers       *speakerTracker
+	ringer         *callRinger
+	deadZoneMeters float64
+	// initialUsersPageSize splits larger initial users into pages; 0 never does
+	initialUsersPageSize int
+	minClientVersion string
+	// draining is set once the server shuts down and no longer accepts connections
+	draining       atomic.Bool
 	upgrader       ws.Upgrader
 	logger         *slog.Logger
 }
 
 // NewHandler creates a new WebSocket handler
 func NewHandler(sessionService SessionServiceInterface, rateLimiter RateLimiterInterface, userService UserServiceInterface, poiService POIServiceInterface) *Handler {
-	return &Handler{
+	h := &Handler{
 		sessionService: sessionService,
 		rateLimiter:    rateLimiter,
+		messageRateLimits: defaultMessageRateLimits(),
 		userService:    userService,
 		poiService:     poiService,
 		pubsub:         nil, // Will be set via SetPubSub if needed
 		manager:        NewManager(),
+		avatars:        newAvatarPositions(),
 		upgrader: ws.Upgrader{
 			CheckOrigin: func(r *http.Request) bool {
 				// In production, implement proper origin checking
@@ -92,8 +345,17 @@ func NewHandler(sessionService SessionServiceInterface, rateLimiter RateLimiterI
 			ReadBufferSize:  1024,
 			WriteBufferSize: 1024,
 		},
-		logger: slog.Default(),
+		sessionTokens:  services.NewSessionTokens(nil),
+		logger:         slog.Default(),
+		deadZoneMeters: DefaultMovementDeadZoneMeters,
+		initialUsersPageSize: DefaultInitialUsersPageSize,
 	}
+	h.speakers = newSpeakerTracker(DefaultSpeakerDebounce, func(mapID string, msg Message) {
+		h.manager.BroadcastToMap(mapID, msg)
+	})
+	h.ringer = newCallRinger(DefaultCallRingTimeout, h.handleCallTimeout)
+	
+	return h
 }
 
 // SetPubSub sets the PubSub interface for real-time event broadcasting
@@ -106,38 +368,181 @@ func (h *Handler) SetPubSub(pubsub PubSubInterface) {
 	}
 }
 
-// HandleWebSocket handles WebSocket connection upgrades
-func (h *Handler) HandleWebSocket(c *gin.Context) {
-	// Extract session ID from query parameter (preferred for WebSocket) or Authorization header
-	sessionID := c.Query("sessionId")
-	if sessionID == "" {
-		// Fallback to Authorization header
-		authHeader := c.GetHeader("Authorization")
-		var err error
-		sessionID, err = extractSessionID(authHeader)
-		if err != nil {
-			h.logger.Warn("WebSocket connection failed: missing sessionId")
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Missing sessionId query parameter or Authorization header"})
-			return
-		}
+// SetAbuseGuard sets the abuse heuristics used to apply automatic restrictions
+func (h *Handler) SetAbuseGuard(abuseGuard AbuseGuardInterface) {
+	h.abuseGuard = abuseGuard
+}
+
+// SetBurstLimiter sets the token bucket limiter used instead of the sliding window
+// limits for actions with a burst allowance
+func (h *Handler) SetBurstLimiter(burstLimiter BurstLimiterInterface) {
+	h.burstLimiter = burstLimiter
+}
+
+// SetMovementDeadZone sets the distance in meters below which avatar moves are
+// acknowledged without being stored or broadcast. Zero disables the dead zone.
+func (h *Handler) SetMovementDeadZone(meters float64) {
+	h.deadZoneMeters = meters
+}
+
+// SetPositionBatchInterval coalesces avatar moves into one avatar_positions_batch
+// broadcast per map and interval. Zero broadcasts every move.
+func (h *Handler) SetPositionBatchInterval(interval time.Duration) {
+	h.manager.SetPositionBatchInterval(interval)
+}
+
+// SetSpeakerDebounce sets how long a POI's speaking state must be stable before
+// the active speaker is broadcast. Zero broadcasts every change immediately.
+func (h *Handler) SetSpeakerDebounce(debounce time.Duration) {
+	h.speakers.mutex.Lock()
+	defer h.speakers.mutex.Unlock()
+	
+	h.speakers.debounce = debounce
+}
+
+// SetPresenceChecker sets the presence checker used to hide stale sessions from initial users
+func (h *Handler) SetPresenceChecker(presence PresenceCheckerInterface) {
+	h.presence = presence
+}
+
+// SetPersonalSpaceProvider enables per-map personal space checks that reject or
+// adjust avatar moves into another avatar's personal space
+func (h *Handler) SetPersonalSpaceProvider(provider PersonalSpaceProviderInterface) {
+	h.personalSpace = provider
+}
+
+// SetSlowConsumerPolicyProvider enables per-map send buffer sizes and drop policies.
+// Without it, clients whose send buffer is full are disconnected.
+func (h *Handler) SetSlowConsumerPolicyProvider(provider SlowConsumerPolicyProviderInterface) {
+	h.slowConsumer = provider
+}
+
+// SetPositionPrivacyProvider enables per-map coarse avatar positions for
+// regular participants. Without it, everyone sees exact positions.
+func (h *Handler) SetPositionPrivacyProvider(provider PositionPrivacyProviderInterface) {
+	h.privacy = provider
+}
+
+// SetPOIRosterProvider enables periodic POI roster reconciliation. Without it,
+// clients only see participant deltas.
+func (h *Handler) SetPOIRosterProvider(provider POIRosterProviderInterface) {
+	h.rosters = provider
+}
+
+// SetFeatureRollout enables rolled out features per user. Without it, gated
+// features like batched movement are disabled for everyone.
+func (h *Handler) SetFeatureRollout(rollout FeatureRolloutInterface) {
+	h.rollout = rollout
+}
+
+// SetMinClientVersion rejects connections from clients older than the given
+// version with an upgrade_required message. Empty accepts all clients.
+func (h *Handler) SetMinClientVersion(version string) {
+	if _, ok := parseVersion(version); version != "" && !ok {
+		h.logger.Warn("Ignoring invalid minimum client version", "version", version)
+		version = ""
+	}
+	h.minClientVersion = version
+}
+
+// SetConnectionErrorRecorder enables recording abnormal closes. Without it,
+// they are only logged.
+func (h *Handler) SetConnectionErrorRecorder(recorder ConnectionErrorRecorderInterface) {
+	h.connectionErrors = recorder
+}
+
+// SetEventExporter exports connects and disconnects to the log sink. Without
+// it, they are only logged.
+func (h *Handler) SetEventExporter(exporter EventExporterInterface) {
+	h.eventExporter = exporter
+}
+
+// SetAnalytics sets where product events like starting a call are reported
+func (h *Handler) SetAnalytics(analytics AnalyticsInterface) {
+	h.analytics = analytics
+}
+
+// exportEvent exports a connection event of the client, if enabled
+func (h *Handler) exportEvent(c *Client, event string, fields map[string]interface{}) {
+	if h.eventExporter == nil {
+		return
 	}
 	
-	h.logger.Info("WebSocket connection attempt", "sessionId", sessionID)
+	fields["event"] = event
+	fields["sessionId"] = c.SessionID
+	fields["userId"] = c.UserID
+	fields["mapId"] = c.MapID
+	h.eventExporter.Export(logexport.CategoryEvents, fields)
+}
+
+// Connections returns the live connections, optionally limited to one map
+func (h *Handler) Connections(mapID string) []models.ConnectionInfo {
+	return h.manager.ListConnections(mapID)
+}
+
+// NotifyRestriction informs the restricted user and the map facilitators about a new restriction
+func (h *Handler) NotifyRestriction(restriction *services.Restriction) {
+	h.logger.Warn("🚨 Automatic restriction applied",
+		"userId", restriction.UserID,
+		"mapId", restriction.MapID,
+		"restriction", restriction.Type,
+		"rule", restriction.Rule,
+		"expiresAt", restriction.ExpiresAt)
+	
+	restrictionData := map[string]interface{}{
+		"userId":      restriction.UserID,
+		"restriction": string(restriction.Type),
+		"rule":        restriction.Rule,
+		"expiresAt":   restriction.ExpiresAt,
+	}
+	
+	// Tell the restricted user
+	h.manager.BroadcastToUser(restriction.UserID, Message{
+		Type:      "restriction_applied",
+		Data:      restrictionData,
+		Timestamp: time.Now(),
+	}, "")
 	
-	// Validate session
-	session, err := h.sessionService.GetSession(c.Request.Context(), sessionID)
-	if err != nil {
-		h.logger.Warn("WebSocket connection failed: invalid session", 
-			"sessionId", sessionID, 
-			"error", err.Error())
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid session"})
+	// Tell facilitators (admins) connected to the same map
+	for _, userID := range h.manager.GetMapFacilitatorUserIDs(restriction.MapID) {
+		if userID == restriction.UserID {
+			continue
+		}
+		
+		h.manager.BroadcastToUser(userID, Message{
+			Type:      "moderation_alert",
+			Data:      restrictionData,
+			Timestamp: time.Now(),
+		}, "")
+	}
+}
+
+// recordAbuseSignal records an abuse signal for a client if abuse heuristics are enabled
+func (h *Handler) recordAbuseSignal(ctx context.Context, userID, mapID string, signal services.AbuseSignal) {
+	if h.abuseGuard == nil {
+		return
+	}
+	h.abuseGuard.RecordSignal(ctx, userID, mapID, signal)
+}
+
+// HandleWebSocket handles WebSocket connection upgrades
+func (h *Handler) HandleWebSocket(c *gin.Context) {
+	// Resolved by the auth middleware of RegisterRoutes
+	session, reconnect := h.connectingSession(c)
+	if session == nil {
 		return
 	}
+	sessionID := session.ID
 	
-	if !session.IsActive {
-		h.logger.Warn("WebSocket connection failed: inactive session", 
-			"sessionId", sessionID)
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Session is not active"})
+	h.logger.Info("WebSocket connection attempt", "sessionId", sessionID, "reconnectToken", reconnect != nil)
+	
+	// Users a facilitator banned from the map stay out until the ban ends
+	if ban := h.mapBan(c.Request.Context(), session.MapID, session.UserID); ban != nil {
+		h.logger.Warn("WebSocket connection failed: banned from map", 
+			"sessionId", sessionID, 
+			"userId", session.UserID, 
+			"mapId", session.MapID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Banned from this map", "bannedUntil": ban.Until})
 		return
 	}
 	
@@ -150,23 +555,77 @@ func (h *Handler) HandleWebSocket(c *gin.Context) {
 		return
 	}
 	
+	// Reject outdated clients before they join the map
+	clientVersion := clientVersionFromRequest(c.Request)
+	if !isClientVersionSupported(clientVersion, h.minClientVersion) {
+		h.rejectOutdatedClient(conn, sessionID, clientVersion)
+		return
+	}
+	
+	// Clients speaking only protocol versions the server dropped can't be served
+	requestedProtocol := protocolVersionFromRequest(c.Request)
+	protocolVersion, ok := negotiateProtocolVersion(requestedProtocol)
+	if !ok {
+		h.rejectProtocolMismatch(conn, sessionID, requestedProtocol)
+		return
+	}
+	
 	// Create client
+	storedPosition := session.AvatarPos
+	slowConsumer := h.slowConsumerPolicy(c.Request.Context(), session.MapID)
 	client := &Client{
+		connectionID: uuid.New().String(),
 		SessionID: sessionID,
 		UserID:    session.UserID,
 		MapID:     session.MapID,
 		Conn:      conn,
-		Send:      make(chan Message, 256),
+		Send:      make(chan Message, slowConsumer.BufferSize()),
 		Manager:   h.manager,
+		signaling:     make(chan Message, signalingLaneSize),
+		movement:      make(chan Message, slowConsumer.BufferSize()),
+		slowConsumer:  slowConsumer,
+		ClientVersion: clientVersion,
+		protocolVersion: protocolVersion,
+		UserAgent:     userAgentFromRequest(c.Request),
+		ConnectedAt:   time.Now(),
+		features:      h.enabledFeatures(c.Request.Context(), session.UserID),
+		deliveryLatency: h.deliveryLatency,
+		payloadBytes:  h.payloadBytes,
+		slowConsumerEvents: h.slowConsumerEvents,
+		usage:         h.usage,
+		compressionThreshold: h.compressionThresholdFor(c.Request),
+		frames:        h.frameBatchFor(c.Request),
+		lastPosition: &storedPosition,
+		role:          tokenRole(c),
+		drain:         make(chan struct{}),
+		writeDone:     make(chan struct{}),
+	}
+	client.audit = func(direction string, message Message) {
+		h.auditMessage(context.Background(), client, direction, message)
+	}
+	
+	// Register client. Further tabs of the user share the avatar of the first,
+	// while a newer session of the user takes over the avatar of the old one.
+	h.manager.RegisterClient(client)
+	replacedSessions := h.closeReplacedSessions(client)
+	firstConnection := h.manager.connectUser(session.MapID, session.UserID)
+	if firstConnection || len(replacedSessions) > 0 {
+		h.avatars.Set(session.MapID, sessionID, storedPosition)
+	}
+	for _, replaced := range replacedSessions {
+		h.avatars.Remove(session.MapID, replaced)
 	}
 	
-	// Register client
-	h.manager.RegisterClient(client)
+	h.exportEvent(client, "connected", map[string]interface{}{
+		"clientVersion": clientVersion,
+		"userAgent":     client.UserAgent,
+	})
 	
 	h.logger.Info("WebSocket client connected", 
 		"sessionId", sessionID, 
 		"userId", session.UserID, 
-		"mapId", session.MapID)
+		"mapId", session.MapID,
+		"clientVersion", clientVersion)
 	
 	// Send welcome message
 	welcomeMsg := Message{
@@ -175,19 +634,56 @@ func (h *Handler) HandleWebSocket(c *gin.Context) {
 			"sessionId": sessionID,
 			"userId":    session.UserID,
 			"mapId":     session.MapID,
+			"features":  client.featureList(),
+			// The client speaks this version from now on
+			"protocolVersion":   protocolVersion,
+			"supportedVersions": supportedProtocolVersions(),
+			// Passed back as the epoch query parameter to resume after a reconnect
+			"journalEpoch": h.manager.JournalEpoch(),
 		},
 		Timestamp: time.Now(),
 	}
+	// Clients joining a frozen map learn when it thaws
+	if freeze, frozen := h.mapFrozen(session.MapID); frozen {
+		welcomeMsg.Data.(map[string]interface{})["frozenUntil"] = freeze.Until
+	}
+	// Clients that asked for frame batching learn that frames can hold arrays
+	if client.frames != nil {
+		welcomeMsg.Data.(map[string]interface{})["frameBatching"] = map[string]interface{}{
+			"maxMessages": client.frames.maxMessages,
+			"intervalMs":  client.frames.interval.Milliseconds(),
+		}
+	}
+	// Clients connecting during maintenance show the banner right away
+	if maintenance := h.maintenanceStatus(); maintenance.Enabled {
+		welcomeMsg.Data.(map[string]interface{})["maintenance"] = maintenanceMessage(maintenance).Data
+	}
+	// Presented on the next reconnect to skip the session lookup
+	h.withReconnectToken(client, welcomeMsg.Data.(map[string]interface{}))
 	client.Send <- welcomeMsg
 	
-	// Automatically send initial users to the new client
-	h.logger.Info("📋 Automatically sending initial users to new client", "sessionId", sessionID)
-	h.handleRequestInitialUsers(c.Request.Context(), client, Message{Type: "request_initial_users"})
+	// Reconnecting clients get what they missed; new clients get the initial users.
+	// Reconnect tokens carry the journal epoch, so their clients only pass lastSeq.
+	epoch := c.Query("epoch")
+	if epoch == "" && reconnect != nil {
+		epoch = reconnect.Epoch
+	}
+	if lastSeq, err := strconv.ParseUint(c.Query("lastSeq"), 10, 64); err == nil {
+		h.resume(c.Request.Context(), client, lastSeq, epoch)
+	} else {
+		h.logger.Info("📋 Automatically sending initial users to new client", "sessionId", sessionID)
+		h.handleRequestInitialUsers(c.Request.Context(), client, Message{Type: "request_initial_users"})
+	}
 	
 	// Try to get user profile for display name, avatar, and about me
 	displayName := session.UserID
 	var avatarURL *string
 	var aboutMe *string
+	role := models.UserRoleUser
+	// The profile's role, if found, takes precedence over the one of the JWT the client connected with
+	if client.role != "" {
+		role = client.role
+	}
 	
 	if h.userService != nil {
 		user, err := h.userService.GetUser(c.Request.Context(), session.UserID)
@@ -195,6 +691,11 @@ func (h *Handler) HandleWebSocket(c *gin.Context) {
 			displayName = user.DisplayName
 			avatarURL = user.AvatarURL
 			aboutMe = user.AboutMe
+			if user.Role != "" {
+				role = user.Role
+			}
+			h.manager.setClientRole(client, role)
+			h.sendGuestExpiryWarning(c.Request.Context(), client, user)
 		} else {
 			h.logger.Debug("Could not get user profile for user_joined", 
 				"userId", session.UserID, 
@@ -229,11 +730,15 @@ func (h *Handler) HandleWebSocket(c *gin.Context) {
 				"lat": session.AvatarPos.Lat,
 				"lng": session.AvatarPos.Lng,
 			},
-			"role": "user", // Default role
+			"role": string(role),
+			"currentPoiId": h.currentPOIID(c.Request.Context(), session.UserID),
+			"status": UserStatusActive,
 		},
 		Timestamp: time.Now(),
 	}
 	
+	redactProfile(userJoinedMsg.Data.(map[string]interface{}), h.mapProfilePrivacy(c.Request.Context(), session.MapID))
+	
 	mapClientCount := h.manager.GetMapClients(session.MapID)
 	h.logger.Info("📡 Broadcasting user joined", 
 		"sessionId", sessionID, 
@@ -242,7 +747,11 @@ func (h *Handler) HandleWebSocket(c *gin.Context) {
 		"mapClientCount", mapClientCount,
 		"broadcastType", "user_joined")
 	
-	h.manager.BroadcastToMapExcept(session.MapID, sessionID, userJoinedMsg)
+	// Other users already see the avatar of a user opening another tab
+	if firstConnection {
+		userJoinedMsg = withCoarsePosition(userJoinedMsg, h.positionPrivacy(c.Request.Context(), session.MapID), session.AvatarPos)
+		h.manager.BroadcastToMapExcept(session.MapID, sessionID, userJoinedMsg)
+	}
 	
 	// Start goroutines for reading and writing
 	go client.writePump()
@@ -252,17 +761,34 @@ func (h *Handler) HandleWebSocket(c *gin.Context) {
 // readPump handles reading messages from the WebSocket connection
 func (c *Client) readPump(handler *Handler) {
 	defer func() {
-		// Broadcast user left to other clients in the same map
-		userLeftMsg := Message{
-			Type: "user_left",
-			Data: map[string]interface{}{
-				"sessionId": c.SessionID,
-				"userId":    c.UserID,
-			},
-			Timestamp: time.Now(),
-		}
-		c.Manager.BroadcastToMapExcept(c.MapID, c.SessionID, userLeftMsg)
+		// Record abnormal closes and tell the client why, if it can still hear it
+		handler.finishConnection(c)
 		
+		// The user only leaves the map with their last tab
+		if c.Manager.disconnectUser(c.MapID, c.UserID) {
+			// Broadcast user left to other clients in the same map
+			userLeftMsg := Message{
+				Type: "user_left",
+				Data: map[string]interface{}{
+					"sessionId": c.SessionID,
+					"userId":    c.UserID,
+				},
+				Timestamp: time.Now(),
+			}
+			c.Manager.BroadcastToMapExcept(c.MapID, c.SessionID, userLeftMsg)
+			
+			// Stop showing a disconnected user as the active speaker
+			handler.speakers.ClearUserEverywhere(c.UserID)
+			
+			// Users stop presenting once they left every map
+			if !c.Manager.userConnected(c.UserID) {
+				handler.setPresenting(c.MapID, c.UserID, false)
+			}
+			
+			handler.avatars.Remove(c.MapID, c.SessionID)
+			c.Manager.userWentOffline(c.MapID, c.UserID)
+		}
+		handler.rememberLeftSession(c)
 		c.Manager.UnregisterClient(c)
 		c.Conn.Close()
 	}()
@@ -275,27 +801,38 @@ func (c *Client) readPump(handler *Handler) {
 	})
 	
 	for {
-		var msg Message
-		err := c.Conn.ReadJSON(&msg)
+		var incoming incomingMessage
+		err := c.Conn.ReadJSON(&incoming)
 		if err != nil {
 			if ws.IsUnexpectedCloseError(err, ws.CloseGoingAway, ws.CloseAbnormalClosure) {
 				handler.logger.Error("WebSocket read error", 
 					"sessionId", c.SessionID, 
 					"error", err.Error())
 			}
+			c.setCloseCause(closeCauseFromReadError(err))
 			break
 		}
 		
+		msg := incoming.message()
 		msg.Timestamp = time.Now()
+		c.lastMessageType = msg.Type
+		if msg.Version == 0 {
+			msg.Version = c.protocolVersion
+		}
 		
 		// Validate message
 		if err := validateMessage(msg); err != nil {
+			if errors.Is(err, errUnsupportedProtocolVersion) {
+				handler.sendProtocolMismatch(c, msg.Version)
+				continue
+			}
 			errorMsg := Message{
 				Type: "error",
 				Data: map[string]interface{}{
 					"message": "Invalid message format: " + err.Error(),
 				},
 				Timestamp: time.Now(),
+				RequestID: msg.RequestID,
 			}
 			c.Send <- errorMsg
 			continue
@@ -312,229 +849,495 @@ func (c *Client) writePump() {
 	defer func() {
 		ticker.Stop()
 		c.Conn.Close()
+		if c.writeDone != nil {
+			close(c.writeDone)
+		}
 	}()
 	
 	for {
+		// Drain the signaling lane first, then Send, and movement only when both are empty
+		select {
+		case message := <-c.signaling:
+			if !c.writeMessage(message) {
+				return
+			}
+			continue
+		default:
+		}
+		
 		select {
 		case message, ok := <-c.Send:
-			c.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
-			if !ok {
-				c.Conn.WriteMessage(ws.CloseMessage, []byte{})
+			if !c.writeQueued(message, ok) {
+				return
+			}
+			continue
+		default:
+		}
+		
+		select {
+		case message := <-c.signaling:
+			if !c.writeMessage(message) {
+				return
+			}
+			
+		case message, ok := <-c.Send:
+			if !c.writeQueued(message, ok) {
+				return
+			}
+			
+		case message := <-c.movement:
+			if !c.writeMessage(message) {
 				return
 			}
 			
-			if err := c.Conn.WriteJSON(message); err != nil {
+		case <-c.frameDue():
+			if !c.flushFrame() {
 				return
 			}
 			
+		case <-c.drain:
+			c.writeDrained()
+			return
+			
 		case <-ticker.C:
 			c.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
 			if err := c.Conn.WriteMessage(ws.PingMessage, nil); err != nil {
+				c.setCloseCause(&closeCause{code: ws.CloseAbnormalClosure, reason: CloseReasonWriteFailed, err: err})
 				return
 			}
 		}
 	}
 }
 
+// writeQueued writes a message read from Send. A closed Send ends the
+// connection with a close frame. It returns false once the pump must stop.
+func (c *Client) writeQueued(message Message, ok bool) bool {
+	if !ok {
+		// Batched messages go out ahead of the close frame
+		if !c.flushFrame() {
+			return false
+		}
+		c.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+		frame := c.takeCloseFrame()
+		if frame == nil {
+			frame = []byte{}
+		}
+		c.Conn.WriteMessage(ws.CloseMessage, frame)
+		return false
+	}
+	
+	return c.writeMessage(message)
+}
+
+// writeMessage writes a message to the connection, or adds it to the frame
+// batch if the client batches frames. It returns false if the write failed.
+func (c *Client) writeMessage(message Message) bool {
+	if c.frames != nil {
+		return c.batchMessage(message)
+	}
+	return c.writeFrame([]Message{message})
+}
+
+// writeFrame writes messages to the connection in one frame: a lone message
+// as an object, several as a JSON array of them. It returns false if the
+// write failed.
+func (c *Client) writeFrame(messages []Message) bool {
+	for i := range messages {
+		messages[i].Version = c.protocolVersion
+	}
+	var payload []byte
+	var err error
+	if len(messages) == 1 {
+		payload, err = json.Marshal(messages[0])
+	} else {
+		payload, err = json.Marshal(messages)
+	}
+	if err != nil {
+		c.setCloseCause(&closeCause{code: ws.CloseAbnormalClosure, reason: CloseReasonWriteFailed, err: err})
+		return false
+	}
+	
+	// Large payloads like initial_users are compressed, small ones aren't worth it
+	compressed := c.compressionThreshold > 0 && len(payload) >= c.compressionThreshold
+	c.Conn.EnableWriteCompression(compressed)
+	c.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	if err := c.Conn.WriteMessage(ws.TextMessage, payload); err != nil {
+		c.setCloseCause(&closeCause{code: ws.CloseAbnormalClosure, reason: CloseReasonWriteFailed, err: err})
+		return false
+	}
+	c.recordPayloadBytes(len(payload), compressed)
+	c.recordUsageBytes(len(payload))
+	for _, message := range messages {
+		c.recordDeliveryLatency(message)
+		if c.audit != nil {
+			c.audit(auditOutbound, message)
+		}
+	}
+	return true
+}
+
+// rejectOutdatedClient tells a client below the minimum version to upgrade and closes the connection
+func (h *Handler) rejectOutdatedClient(conn *ws.Conn, sessionID, clientVersion string) {
+	defer conn.Close()
+	
+	h.logger.Warn("WebSocket connection rejected: client upgrade required",
+		"sessionId", sessionID,
+		"clientVersion", clientVersion,
+		"minClientVersion", h.minClientVersion)
+	
+	conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	conn.WriteJSON(Message{
+		Type: "upgrade_required",
+		Data: map[string]interface{}{
+			"clientVersion":    clientVersion,
+			"minClientVersion": h.minClientVersion,
+		},
+		Timestamp: time.Now(),
+	})
+	conn.WriteMessage(ws.CloseMessage, ws.FormatCloseMessage(UpgradeRequiredCloseCode, "upgrade_required"))
+}
+
 // handleMessage processes incoming WebSocket messages
 func (h *Handler) handleMessage(client *Client, msg Message) {
-	ctx := context.Background()
+	ctx := withRequestID(context.Background(), msg.RequestID)
+	h.requestLogger(ctx).Debug("Handling message",
+		"sessionId", client.SessionID,
+		"messageType", msg.Type)
 	
-	switch msg.Type {
-	case "heartbeat":
-		h.handleHeartbeat(ctx, client, msg)
-	case "avatar_move":
-		h.handleAvatarMove(ctx, client, msg)
-	case "request_initial_users":
-		h.logger.Info("📋 Request initial users received", "sessionId", client.SessionID)
-		h.handleRequestInitialUsers(ctx, client, msg)
-	case "poi_join":
-		h.handlePOIJoin(ctx, client, msg)
-	case "poi_leave":
-		h.handlePOILeave(ctx, client, msg)
-	case "call_request":
-		h.handleCallRequest(ctx, client, msg)
-	case "call_accept":
-		h.handleCallAccept(ctx, client, msg)
-	case "call_reject":
-		h.handleCallReject(ctx, client, msg)
-	case "call_end":
-		h.handleCallEnd(ctx, client, msg)
-	case "webrtc_offer":
-		h.handleWebRTCOffer(ctx, client, msg)
-	case "webrtc_answer":
-		h.handleWebRTCAnswer(ctx, client, msg)
-	case "ice_candidate":
-		h.handleICECandidate(ctx, client, msg)
-	case "poi_call_offer":
-		h.handlePOICallOffer(ctx, client, msg)
-	case "poi_call_answer":
-		h.handlePOICallAnswer(ctx, client, msg)
-	case "poi_call_ice_candidate":
-		h.handlePOICallICECandidate(ctx, client, msg)
-	default:
-		errorMsg := Message{
+	// Audit messages before they can be rejected, so rejections can be analyzed too
+	h.auditMessage(ctx, client, auditInbound, msg)
+	
+	// Risky new message types are only routed for users in the feature's rollout
+	if feature, gated := featureGatedMessages[msg.Type]; gated && !client.features[feature] {
+		client.Send <- replyTo(ctx, Message{
 			Type: "error",
 			Data: map[string]interface{}{
-				"message": fmt.Sprintf("Unknown message type: %s", msg.Type),
+				"code":    "FEATURE_NOT_ENABLED",
+				"message": fmt.Sprintf("Feature not enabled: %s", feature),
+				"feature": string(feature),
 			},
 			Timestamp: time.Now(),
+		})
+		return
+	}
+	
+	// Facilitators can freeze avatars and POI participation on their map
+	if h.rejectIfFrozen(ctx, client, msg.Type) {
+		return
+	}
+	
+	// Nothing is stored while the server is in maintenance mode
+	if h.rejectIfMaintenance(ctx, client, msg.Type) {
+		return
+	}
+	
+	// Each feature is limited in its own bucket so clients can back off per feature
+	if !h.checkMessageRateLimit(ctx, client, msg.Type) {
+		return
+	}
+	
+	version := messageVersion(msg)
+	handler, ok := lookupMessageHandler(version, msg.Type)
+	if !ok {
+		if _, supported := messageRegistry[version]; !supported {
+			h.sendProtocolMismatch(client, version)
+			return
 		}
+		errorMsg := replyTo(ctx, Message{
+			Type: "error",
+			Data: map[string]interface{}{
+				"message": fmt.Sprintf("Unknown message type: %s", msg.Type),
+			},
+			Timestamp: time.Now(),
+		})
 		client.Send <- errorMsg
+		return
+	}
+	
+	// Heartbeats are sent without the user doing anything, so only some
+	// messages keep the user from being shown as away
+	if activityMessageTypes[msg.Type] {
+		h.manager.recordActivity(client, time.Now())
+	}
+	
+	handler.handle(h, ctx, client, msg)
+}
+
+// enabledFeatures returns the rolled out features enabled for a user
+func (h *Handler) enabledFeatures(ctx context.Context, userID string) map[models.Feature]bool {
+	features := make(map[models.Feature]bool)
+	if h.rollout == nil {
+		return features
+	}
+	
+	for _, feature := range h.rollout.EnabledFeatures(ctx, userID) {
+		features[feature] = true
+	}
+	return features
+}
+
+// featureList returns the client's enabled features in a stable order
+func (c *Client) featureList() []string {
+	features := []string{}
+	for _, feature := range models.KnownFeatures {
+		if c.features[feature] {
+			features = append(features, string(feature))
+		}
+	}
+	return features
+}
+
+// handleInitialUsersRequest processes a client's request for the initial users
+func (h *Handler) handleInitialUsersRequest(ctx context.Context, client *Client, msg Message) {
+	h.requestLogger(ctx).Info("📋 Request initial users received", "sessionId", client.SessionID)
+	h.handleRequestInitialUsers(ctx, client, msg)
+}
+
+// handleAvatarMoveBatch processes batched avatar moves. Clients in the batched
+// movement rollout send the positions sampled since their last message; only
+// the latest one is stored and broadcast, like a single avatar_move.
+func (h *Handler) handleAvatarMoveBatch(ctx context.Context, client *Client, msg Message) {
+	var payload AvatarMoveBatchPayload
+	if !h.decodeMessagePayload(client, msg, &payload) {
+		return
 	}
+	
+	h.moveAvatar(ctx, client, payload.Positions[len(payload.Positions)-1].LatLng())
 }
 
 // handleHeartbeat processes heartbeat messages
 func (h *Handler) handleHeartbeat(ctx context.Context, client *Client, msg Message) {
+	client.recordHeartbeat(time.Now())
+	
 	// Update session heartbeat
 	if err := h.sessionService.SessionHeartbeat(ctx, client.SessionID); err != nil {
-		h.logger.Error("Failed to update session heartbeat", 
+		h.requestLogger(ctx).Error("Failed to update session heartbeat", 
 			"sessionId", client.SessionID, 
 			"error", err.Error())
 		
-		errorMsg := Message{
+		errorMsg := replyTo(ctx, Message{
 			Type: "error",
 			Data: map[string]interface{}{
 				"message": "Failed to update session heartbeat",
 			},
 			Timestamp: time.Now(),
-		}
+		})
 		client.Send <- errorMsg
 		return
 	}
 	
 	// Send pong response
-	pongMsg := Message{
+	pongMsg := replyTo(ctx, Message{
 		Type: "pong",
 		Data: map[string]interface{}{
 			"timestamp": time.Now().Unix(),
 		},
 		Timestamp: time.Now(),
-	}
+	})
 	client.Send <- pongMsg
 }
 
-// handleAvatarMove processes avatar movement messages
-func (h *Handler) handleAvatarMove(ctx context.Context, client *Client, msg Message) {
-	h.logger.Info("🏃 Avatar move request received", 
-		"sessionId", client.SessionID, 
-		"userId", client.UserID, 
-		"mapId", client.MapID)
+// checkRateLimit checks the rate limit for an action. Actions with a burst
+// allowance on the client's map use the token bucket limiter. If the limiter
+// supports soft warnings, the client is sent a rate_limit_warning once per
+// window when it gets close to the limit.
+func (h *Handler) checkRateLimit(ctx context.Context, client *Client, action services.ActionType) error {
+	var warning *services.RateLimitWarning
+	var err error
+	
+	if h.burstLimiter != nil && h.burstLimiter.HasBurstLimit(client.MapID, action) {
+		warning, err = h.burstLimiter.CheckBurstLimit(ctx, client.UserID, client.MapID, action)
+	} else if warner, ok := h.rateLimiter.(services.RateLimitWarner); ok {
+		warning, err = warner.CheckRateLimitWithWarning(ctx, client.UserID, action)
+	} else {
+		err = h.rateLimiter.CheckRateLimit(ctx, client.UserID, action)
+	}
+	if err != nil {
+		return err
+	}
 	
-	// Check rate limit
-	if err := h.rateLimiter.CheckRateLimit(ctx, client.UserID, services.ActionUpdateAvatar); err != nil {
-		if rateLimitErr, ok := err.(*services.RateLimitError); ok {
-			errorMsg := Message{
-				Type: "error",
-				Data: map[string]interface{}{
-					"code":       "RATE_LIMIT_EXCEEDED",
-					"message":    "Avatar movement rate limit exceeded",
-					"retryAfter": rateLimitErr.RetryAfter.Seconds(),
-				},
-				Timestamp: time.Now(),
-			}
-			client.Send <- errorMsg
-			return
+	if warning != nil && time.Now().After(client.rateLimitWarnedUntil[action]) {
+		if client.rateLimitWarnedUntil == nil {
+			client.rateLimitWarnedUntil = make(map[services.ActionType]time.Time)
 		}
+		client.rateLimitWarnedUntil[action] = warning.ResetTime
 		
-		h.logger.Error("Rate limit check failed", 
-			"sessionId", client.SessionID, 
-			"error", err.Error())
-		
-		errorMsg := Message{
-			Type: "error",
+		warningMsg := Message{
+			Type: "rate_limit_warning",
 			Data: map[string]interface{}{
-				"message": "Rate limit check failed",
+				"action":     string(warning.Action),
+				"limit":      warning.Limit,
+				"remaining":  warning.Remaining,
+				"retryAfter": time.Until(warning.ResetTime).Seconds(),
 			},
 			Timestamp: time.Now(),
 		}
-		client.Send <- errorMsg
-		return
-	}
-	
-	// Extract position from message
-	data, ok := msg.Data.(map[string]interface{})
-	if !ok {
-		errorMsg := Message{
+		
+		select {
+		case client.Send <- warningMsg:
+		default:
+			h.requestLogger(ctx).Warn("Failed to send rate limit warning to client", 
+				"sessionId", client.SessionID, 
+				"action", action)
+		}
+	}
+	
+	return nil
+}
+
+// checkMessageRateLimit checks the rate limit bucket of a message type and
+// reports whether the message may be handled. Clients over the limit get an
+// error naming the bucket and when they may retry.
+func (h *Handler) checkMessageRateLimit(ctx context.Context, client *Client, messageType string) bool {
+	action, limited := h.messageRateLimits[messageType]
+	if !limited {
+		return true
+	}
+	
+	err := h.checkRateLimit(ctx, client, action)
+	if err == nil {
+		return true
+	}
+	
+	if rateLimitErr, ok := err.(*services.RateLimitError); ok {
+		// Sustained movement over the limit is treated as a movement anomaly
+		if action == services.ActionUpdateAvatar {
+			h.recordAbuseSignal(ctx, client.UserID, client.MapID, services.SignalMovementAnomaly)
+		}
+		
+		h.requestLogger(ctx).Warn("WebSocket message rate limited", 
+			"sessionId", client.SessionID, 
+			"userId", client.UserID, 
+			"messageType", messageType, 
+			"bucket", action)
+		
+		client.Send <- replyTo(ctx, Message{
 			Type: "error",
 			Data: map[string]interface{}{
-				"message": "Invalid message data format",
+				"code":        "RATE_LIMIT_EXCEEDED",
+				"message":     fmt.Sprintf("%s rate limit exceeded", action),
+				"bucket":      string(action),
+				"messageType": messageType,
+				"retryAfter":  rateLimitErr.RetryAfter.Seconds(),
 			},
 			Timestamp: time.Now(),
-		}
-		client.Send <- errorMsg
+		})
+		return false
+	}
+	
+	h.requestLogger(ctx).Error("Rate limit check failed", 
+		"sessionId", client.SessionID, 
+		"error", err.Error())
+	
+	client.Send <- replyTo(ctx, Message{
+		Type: "error",
+		Data: map[string]interface{}{
+			"message": "Rate limit check failed",
+		},
+		Timestamp: time.Now(),
+	})
+	return false
+}
+
+// handleAvatarMove processes avatar movement messages
+func (h *Handler) handleAvatarMove(ctx context.Context, client *Client, msg Message) {
+	h.requestLogger(ctx).Info("🏃 Avatar move request received", 
+		"sessionId", client.SessionID, 
+		"userId", client.UserID, 
+		"mapId", client.MapID)
+	
+	var payload AvatarMovePayload
+	if !h.decodeMessagePayload(client, msg, &payload) {
 		return
 	}
 	
-	positionData, ok := data["position"].(map[string]interface{})
-	if !ok {
-		errorMsg := Message{
+	h.moveAvatar(ctx, client, payload.Position.LatLng())
+}
+
+// moveAvatar validates, stores and broadcasts a position requested by a client
+func (h *Handler) moveAvatar(ctx context.Context, client *Client, requested models.LatLng) {
+	// Validate position
+	if err := requested.Validate(); err != nil {
+		errorMsg := replyTo(ctx, Message{
 			Type: "error",
 			Data: map[string]interface{}{
-				"message": "Invalid position data format",
+				"message": "Invalid position: " + err.Error(),
 			},
 			Timestamp: time.Now(),
-		}
+		})
 		client.Send <- errorMsg
 		return
 	}
 	
-	lat, ok1 := positionData["lat"].(float64)
-	lng, ok2 := positionData["lng"].(float64)
-	if !ok1 || !ok2 {
-		errorMsg := Message{
-			Type: "error",
+	// Keep avatars out of each other's personal space if the map asks for it
+	position, allowed := h.applyPersonalSpace(ctx, client, requested)
+	if !allowed {
+		ackMsg := replyTo(ctx, Message{
+			Type: "avatar_move_ack",
 			Data: map[string]interface{}{
-				"message": "Invalid position coordinates",
+				"sessionId": client.SessionID,
+				"position":  client.lastPosition,
+				"rejected":  true,
+				"reason":    "personal_space",
 			},
 			Timestamp: time.Now(),
-		}
-		client.Send <- errorMsg
+		})
+		client.Send <- ackMsg
 		return
 	}
+	adjusted := position != requested
 	
-	position := models.LatLng{Lat: lat, Lng: lng}
-	
-	// Validate position
-	if err := position.Validate(); err != nil {
-		errorMsg := Message{
-			Type: "error",
+	// Acknowledge jitter within the dead zone without storing or broadcasting it
+	if client.lastPosition != nil && client.lastPosition.DistanceTo(position)*1000 < h.deadZoneMeters {
+		ackMsg := replyTo(ctx, Message{
+			Type: "avatar_move_ack",
 			Data: map[string]interface{}{
-				"message": "Invalid position: " + err.Error(),
+				"sessionId": client.SessionID,
+				"position":  *client.lastPosition,
+				"skipped":   true,
 			},
 			Timestamp: time.Now(),
-		}
-		client.Send <- errorMsg
+		})
+		client.Send <- ackMsg
 		return
 	}
 	
 	// Update avatar position
 	if err := h.sessionService.UpdateAvatarPosition(ctx, client.SessionID, position); err != nil {
-		h.logger.Error("Failed to update avatar position", 
+		h.requestLogger(ctx).Error("Failed to update avatar position", 
 			"sessionId", client.SessionID, 
 			"position", position, 
 			"error", err.Error())
 		
-		errorMsg := Message{
+		errorMsg := replyTo(ctx, Message{
 			Type: "error",
 			Data: map[string]interface{}{
 				"message": "Failed to update avatar position",
 			},
 			Timestamp: time.Now(),
-		}
+		})
 		client.Send <- errorMsg
 		return
 	}
 	
-	// Send acknowledgment
-	ackMsg := Message{
-		Type: "avatar_move_ack",
-		Data: map[string]interface{}{
-			"sessionId": client.SessionID,
-			"position":  position,
-		},
-		Timestamp: time.Now(),
+	previous := client.lastPosition
+	client.lastPosition = &position
+	h.avatars.Set(client.MapID, client.SessionID, position)
+	
+	// Send acknowledgment, with the corrected position if it was adjusted
+	ackData := map[string]interface{}{
+		"sessionId": client.SessionID,
+		"position":  position,
+	}
+	if adjusted {
+		ackData["adjusted"] = true
+		ackData["requestedPosition"] = requested
 	}
+	ackMsg := replyTo(ctx, Message{
+		Type:      "avatar_move_ack",
+		Data:      ackData,
+		Timestamp: time.Now(),
+	})
 	client.Send <- ackMsg
 	
 	// Broadcast movement to other clients in the same map
@@ -550,7 +1353,7 @@ func (h *Handler) handleAvatarMove(ctx context.Context, client *Client, msg Mess
 	
 	// Get current map clients for logging
 	mapClientCount := h.manager.GetMapClients(client.MapID)
-	h.logger.Info("📡 Broadcasting avatar movement", 
+	h.requestLogger(ctx).Info("📡 Broadcasting avatar movement", 
 		"sessionId", client.SessionID, 
 		"userId", client.UserID, 
 		"mapId", client.MapID,
@@ -559,282 +1362,236 @@ func (h *Handler) handleAvatarMove(ctx context.Context, client *Client, msg Mess
 		"broadcastType", "avatar_moved")
 	
 	// Broadcast to all clients in the same map except the sender
-	h.manager.BroadcastToMapExcept(client.MapID, client.SessionID, broadcastMsg)
+	privacy := h.positionPrivacy(ctx, client.MapID)
+	broadcastMsg = withCoarsePosition(broadcastMsg, privacy, position)
 	
-	h.logger.Info("✅ Avatar position updated and broadcasted", 
+	// Clients whose viewport the avatar left see it leave too
+	if previous != nil {
+		broadcastMsg = atPositions(broadcastMsg, privacy, *previous, position)
+	} else {
+		broadcastMsg = atPositions(broadcastMsg, privacy, position)
+	}
+	h.manager.BroadcastAvatarMove(client.MapID, client.SessionID, broadcastMsg)
+	h.followLeaderMoved(client, privacy, position)
+	
+	h.requestLogger(ctx).Info("✅ Avatar position updated and broadcasted", 
 		"sessionId", client.SessionID, 
 		"userId", client.UserID, 
 		"position", position)
 }
 
-// Helper functions
-
-// extractSessionID extracts session ID from Authorization header
-func extractSessionID(authHeader string) (string, error) {
-	if authHeader == "" {
-		return "", errors.New("missing authorization header")
+// slowConsumerPolicy returns how the map treats clients that fall behind its broadcasts
+func (h *Handler) slowConsumerPolicy(ctx context.Context, mapID string) models.SlowConsumerPolicy {
+	if h.slowConsumer == nil {
+		return models.SlowConsumerPolicy{}
 	}
 	
-	parts := strings.SplitN(authHeader, " ", 2)
-	if len(parts) != 2 || parts[0] != "Bearer" {
-		return "", errors.New("invalid authorization header format")
+	policy, err := h.slowConsumer.GetSlowConsumerPolicy(ctx, mapID)
+	if err != nil {
+		// Fall back to the default buffer rather than refusing the connection
+		h.requestLogger(ctx).Warn("Failed to get slow consumer policy",
+			"mapId", mapID,
+			"error", err.Error())
+		return models.SlowConsumerPolicy{}
+	}
+	
+	return policy
+}
+
+// applyPersonalSpace checks a move against the personal space setting of the
+// client's map. It returns the position to store and whether the move is allowed.
+func (h *Handler) applyPersonalSpace(ctx context.Context, client *Client, position models.LatLng) (models.LatLng, bool) {
+	if h.personalSpace == nil {
+		return position, true
 	}
 	
-	sessionID := strings.TrimSpace(parts[1])
-	if sessionID == "" {
-		return "", errors.New("empty session ID")
+	personalSpace, err := h.personalSpace.GetPersonalSpace(ctx, client.MapID)
+	if err != nil {
+		// Don't block movement because the setting can't be read
+		h.requestLogger(ctx).Warn("Failed to get personal space setting",
+			"mapId", client.MapID,
+			"error", err.Error())
+		return position, true
 	}
 	
-	return sessionID, nil
+	return resolvePersonalSpace(personalSpace, client.lastPosition, position, h.avatars.Others(client.MapID, client.SessionID))
 }
 
-// validateMessage validates incoming WebSocket messages
+// Helper functions
+
+// validateMessage validates an incoming WebSocket message against the
+// message types of its protocol version
 func validateMessage(msg Message) error {
 	if msg.Type == "" {
 		return errors.New("message type is required")
 	}
+	if err := validateRequestID(msg.RequestID); err != nil {
+		return err
+	}
 	
-	switch msg.Type {
-	case "heartbeat":
-		// Heartbeat messages don't need additional validation
-		return nil
-		
-	case "avatar_move":
-		// Validate avatar movement message
-		data, ok := msg.Data.(map[string]interface{})
-		if !ok {
-			return errors.New("invalid data format")
-		}
-		
-		position, ok := data["position"]
-		if !ok {
-			return errors.New("position is required for avatar_move")
-		}
-		
-		positionMap, ok := position.(map[string]interface{})
-		if !ok {
-			return errors.New("position must be an object")
-		}
-		
-		if _, ok := positionMap["lat"]; !ok {
-			return errors.New("latitude is required")
-		}
-		
-		if _, ok := positionMap["lng"]; !ok {
-			return errors.New("longitude is required")
-		}
-		
-		return nil
-		
-	case "request_initial_users":
-		// Request initial users message - no additional validation needed
-		return nil
-		
-	case "poi_join", "poi_leave":
-		// Validate POI messages
-		data, ok := msg.Data.(map[string]interface{})
-		if !ok {
-			return errors.New("invalid data format")
-		}
-		
-		poiID, ok := data["poiId"].(string)
-		if !ok || poiID == "" {
-			return errors.New("poiId is required")
-		}
-		
-		return nil
-		
-	case "call_request":
-		// Validate call request message
-		data, ok := msg.Data.(map[string]interface{})
-		if !ok {
-			return errors.New("invalid data format")
-		}
-		
-		if _, ok := data["targetUserId"].(string); !ok {
-			return errors.New("targetUserId is required for call_request")
-		}
-		
-		if _, ok := data["callId"].(string); !ok {
-			return errors.New("callId is required for call_request")
-		}
-		
-		return nil
-		
-	case "call_accept", "call_reject":
-		// Validate call accept/reject messages
-		data, ok := msg.Data.(map[string]interface{})
-		if !ok {
-			return errors.New("invalid data format")
-		}
-		
-		if _, ok := data["callId"].(string); !ok {
-			return errors.New("callId is required")
-		}
-		
-		if _, ok := data["callerUserId"].(string); !ok {
-			return errors.New("callerUserId is required")
-		}
-		
-		return nil
-		
-	case "call_end":
-		// Validate call end message
-		data, ok := msg.Data.(map[string]interface{})
-		if !ok {
-			return errors.New("invalid data format")
-		}
-		
-		if _, ok := data["callId"].(string); !ok {
-			return errors.New("callId is required for call_end")
-		}
-		
-		if _, ok := data["otherUserId"].(string); !ok {
-			return errors.New("otherUserId is required for call_end")
-		}
-		
-		return nil
-		
-	case "webrtc_offer", "webrtc_answer":
-		// Validate WebRTC offer/answer messages
-		data, ok := msg.Data.(map[string]interface{})
-		if !ok {
-			return errors.New("invalid data format")
-		}
-		
-		if _, ok := data["callId"].(string); !ok {
-			return errors.New("callId is required for WebRTC offer/answer")
-		}
-		
-		if _, ok := data["targetUserId"].(string); !ok {
-			return errors.New("targetUserId is required for WebRTC offer/answer")
-		}
-		
-		if _, ok := data["sdp"]; !ok {
-			return errors.New("sdp is required for WebRTC offer/answer")
-		}
-		
-		return nil
-		
-	case "ice_candidate":
-		// Validate ICE candidate messages
-		data, ok := msg.Data.(map[string]interface{})
-		if !ok {
-			return errors.New("invalid data format")
-		}
-		
-		if _, ok := data["callId"].(string); !ok {
-			return errors.New("callId is required for ICE candidate")
-		}
-		
-		if _, ok := data["targetUserId"].(string); !ok {
-			return errors.New("targetUserId is required for ICE candidate")
-		}
-		
-		if _, ok := data["candidate"]; !ok {
-			return errors.New("candidate is required for ICE candidate")
-		}
-		
-		return nil
-		
-	case "poi_call_offer", "poi_call_answer":
-		// Validate POI call offer/answer messages
-		data, ok := msg.Data.(map[string]interface{})
-		if !ok {
-			return errors.New("invalid data format")
-		}
-		
-		if _, ok := data["poiId"].(string); !ok {
-			return errors.New("poiId is required for POI call offer/answer")
-		}
-		
-		if _, ok := data["targetUserId"].(string); !ok {
-			return errors.New("targetUserId is required for POI call offer/answer")
-		}
-		
-		if _, ok := data["sdp"]; !ok {
-			return errors.New("sdp is required for POI call offer/answer")
-		}
-		
-		return nil
-		
-	case "poi_call_ice_candidate":
-		// Validate POI call ICE candidate messages
-		data, ok := msg.Data.(map[string]interface{})
-		if !ok {
-			return errors.New("invalid data format")
-		}
-		
-		if _, ok := data["poiId"].(string); !ok {
-			return errors.New("poiId is required for POI call ICE candidate")
-		}
-		
-		if _, ok := data["targetUserId"].(string); !ok {
-			return errors.New("targetUserId is required for POI call ICE candidate")
-		}
-		
-		if _, ok := data["candidate"]; !ok {
-			return errors.New("candidate is required for POI call ICE candidate")
+	version := messageVersion(msg)
+	handler, ok := lookupMessageHandler(version, msg.Type)
+	if !ok {
+		if _, supported := messageRegistry[version]; !supported {
+			return fmt.Errorf("%w: %d", errUnsupportedProtocolVersion, version)
 		}
-		
-		return nil
-		
-	default:
 		return fmt.Errorf("unknown message type: %s", msg.Type)
 	}
+	
+	return handler.validate(msg)
 }
 
-// handlePOIJoin handles POI join events
-func (h *Handler) handlePOIJoin(ctx context.Context, client *Client, msg Message) {
-	// Validate message
-	data, ok := msg.Data.(map[string]interface{})
-	if !ok {
-		h.logger.Error("Invalid POI join message format", "sessionId", client.SessionID)
-		return
+// validateNoData accepts messages that carry no data, like heartbeats and
+// snapshot requests
+func validateNoData(msg Message) error {
+	return nil
+}
+
+// validateAvatarMove validates avatar movement messages
+func validateAvatarMove(msg Message) error {
+	var payload AvatarMovePayload
+	if err := decodePayload(msg, &payload); err != nil {
+		return err
 	}
-	
-	poiID, ok := data["poiId"].(string)
-	if !ok || poiID == "" {
-		h.logger.Error("Missing or invalid POI ID in join message", "sessionId", client.SessionID)
-		return
+	return payload.Validate()
+}
+
+// validateAvatarMoveBatch validates batched avatar movement messages
+func validateAvatarMoveBatch(msg Message) error {
+	var payload AvatarMoveBatchPayload
+	if err := decodePayload(msg, &payload); err != nil {
+		return err
 	}
-	
-	// Check rate limit
-	if err := h.rateLimiter.CheckRateLimit(ctx, client.UserID, services.ActionJoinPOI); err != nil {
-		if rateLimitErr, ok := err.(*services.RateLimitError); ok {
-			errorMsg := Message{
-				Type: "error",
-				Data: map[string]interface{}{
-					"message": "Rate limit exceeded: " + rateLimitErr.Error(),
-				},
-				Timestamp: time.Now(),
-			}
-			client.Send <- errorMsg
-		}
-		h.logger.Warn("POI join rate limited", "sessionId", client.SessionID, "userId", client.UserID)
+	return payload.Validate()
+}
+
+// validateResyncFrom validates replay requests
+func validateResyncFrom(msg Message) error {
+	var payload ResyncFromPayload
+	if err := decodePayload(msg, &payload); err != nil {
+		return err
+	}
+	return payload.Validate()
+}
+
+// validateViewportUpdate validates viewport bounds
+func validateViewportUpdate(msg Message) error {
+	var payload ViewportUpdatePayload
+	if err := decodePayload(msg, &payload); err != nil {
+		return err
+	}
+	return payload.Validate()
+}
+
+// validatePOIMembership validates POI join and leave messages
+func validatePOIMembership(msg Message) error {
+	var payload POIMembershipPayload
+	if err := decodePayload(msg, &payload); err != nil {
+		return err
+	}
+	return payload.Validate()
+}
+
+// validateSpeakingState validates speaking state messages
+func validateSpeakingState(msg Message) error {
+	var payload SpeakingStatePayload
+	if err := decodePayload(msg, &payload); err != nil {
+		return err
+	}
+	return payload.Validate()
+}
+
+// validateCallRequest validates call request messages
+func validateCallRequest(msg Message) error {
+	var payload CallRequestPayload
+	if err := decodePayload(msg, &payload); err != nil {
+		return err
+	}
+	return payload.Validate()
+}
+
+// validateCallResponse validates call accept and reject messages
+func validateCallResponse(msg Message) error {
+	var payload CallResponsePayload
+	if err := decodePayload(msg, &payload); err != nil {
+		return err
+	}
+	return payload.Validate()
+}
+
+// validateCallEnd validates call end messages
+func validateCallEnd(msg Message) error {
+	var payload CallEndPayload
+	if err := decodePayload(msg, &payload); err != nil {
+		return err
+	}
+	return payload.Validate()
+}
+
+// validateWebRTCDescription validates WebRTC offer and answer messages
+func validateWebRTCDescription(msg Message) error {
+	var payload WebRTCDescriptionPayload
+	if err := decodePayload(msg, &payload); err != nil {
+		return err
+	}
+	return payload.Validate()
+}
+
+// validateICECandidate validates ICE candidate messages
+func validateICECandidate(msg Message) error {
+	var payload ICECandidatePayload
+	if err := decodePayload(msg, &payload); err != nil {
+		return err
+	}
+	return payload.Validate()
+}
+
+// validatePOICallDescription validates POI call offer and answer messages
+func validatePOICallDescription(msg Message) error {
+	var payload POICallDescriptionPayload
+	if err := decodePayload(msg, &payload); err != nil {
+		return err
+	}
+	return payload.Validate()
+}
+
+// validatePOICallICECandidate validates POI call ICE candidate messages
+func validatePOICallICECandidate(msg Message) error {
+	var payload POICallICECandidatePayload
+	if err := decodePayload(msg, &payload); err != nil {
+		return err
+	}
+	return payload.Validate()
+}
+
+// handlePOIJoin handles POI join events
+func (h *Handler) handlePOIJoin(ctx context.Context, client *Client, msg Message) {
+	var payload POIMembershipPayload
+	if !h.decodeMessagePayload(client, msg, &payload) {
 		return
 	}
+	poiID := payload.POIID
 	
 	// Call POI service to join the POI
 	if err := h.poiService.JoinPOI(ctx, poiID, client.UserID); err != nil {
-		h.logger.Error("Failed to join POI", "sessionId", client.SessionID, "userId", client.UserID, "poiId", poiID, "error", err)
-		errorMsg := Message{
+		h.requestLogger(ctx).Error("Failed to join POI", "sessionId", client.SessionID, "userId", client.UserID, "poiId", poiID, "error", err)
+		errorMsg := replyTo(ctx, Message{
 			Type: "error",
 			Data: map[string]interface{}{
 				"message": "Failed to join POI: " + err.Error(),
 			},
 			Timestamp: time.Now(),
-		}
+		})
 		select {
 		case client.Send <- errorMsg:
 		default:
-			h.logger.Warn("Failed to send POI join error", "sessionId", client.SessionID)
+			h.requestLogger(ctx).Warn("Failed to send POI join error", "sessionId", client.SessionID)
 		}
 		return
 	}
 	
 	// Send acknowledgment
-	ackMsg := Message{
+	ackMsg := replyTo(ctx, Message{
 		Type: "poi_join_ack",
 		Timestamp: time.Now(),
 		Data: map[string]interface{}{
@@ -842,12 +1599,12 @@ func (h *Handler) handlePOIJoin(ctx context.Context, client *Client, msg Message
 			"poiId": poiID,
 			"success": true,
 		},
-	}
+	})
 	
 	select {
 	case client.Send <- ackMsg:
 	default:
-		h.logger.Warn("Failed to send POI join acknowledgment", "sessionId", client.SessionID)
+		h.requestLogger(ctx).Warn("Failed to send POI join acknowledgment", "sessionId", client.SessionID)
 	}
 	
 	// Get user display name for the broadcast
@@ -862,37 +1619,21 @@ func (h *Handler) handlePOIJoin(ctx context.Context, client *Client, msg Message
 		}
 	}
 	
-	h.logger.Info("🏷️ POI join display name resolved", 
+	h.requestLogger(ctx).Info("🏷️ POI join display name resolved", 
 		"sessionId", client.SessionID,
 		"userId", client.UserID,
 		"displayName", displayName,
 		"hasSession", session != nil,
 		"hasUser", session != nil && session.User != nil)
 
-	// Get all participants for complete event data
-	participants, err := h.poiService.GetPOIParticipantsWithInfo(ctx, poiID)
-	if err != nil {
-		h.logger.Error("Failed to get POI participants", "poiId", poiID, "error", err)
-		participants = []services.POIParticipantInfo{} // Fallback to empty
-	}
-
-	// Convert to frontend format
-	var participantData []map[string]interface{}
-	for _, p := range participants {
-		participantData = append(participantData, map[string]interface{}{
-			"id":        p.ID,
-			"name":      p.Name,
-			"avatarUrl": p.AvatarURL,
-		})
-	}
-
 	// Get current count
 	currentCount, err := h.poiService.GetPOIParticipantCount(ctx, poiID)
 	if err != nil {
-		currentCount = len(participants) // Fallback
+		currentCount = 0 // Fallback
 	}
 
-	// Broadcast complete POI join event to other clients in the same map
+	// Broadcast the join to other clients in the same map. Rosters are kept in
+	// sync by participant deltas from the POI service, not by this message.
 	broadcastMsg := Message{
 		Type: "poi_joined",
 		Timestamp: time.Now(),
@@ -901,66 +1642,45 @@ func (h *Handler) handlePOIJoin(ctx context.Context, client *Client, msg Message
 			"userId":       client.UserID,
 			"poiId":        poiID,
 			"currentCount": currentCount,
-			"participants": participantData,
+			"participant":  sessionParticipant(session, client.UserID, displayName, h.mapProfilePrivacy(ctx, client.MapID)),
 		},
 	}
 	
 	h.manager.BroadcastToMapExcept(client.MapID, client.SessionID, broadcastMsg)
 	
-	h.logger.Info("User joined POI", "sessionId", client.SessionID, "userId", client.UserID, "poiId", poiID)
+	h.requestLogger(ctx).Info("User joined POI", "sessionId", client.SessionID, "userId", client.UserID, "poiId", poiID)
 }
 
 // handlePOILeave handles POI leave events
 func (h *Handler) handlePOILeave(ctx context.Context, client *Client, msg Message) {
-	// Validate message
-	data, ok := msg.Data.(map[string]interface{})
-	if !ok {
-		h.logger.Error("Invalid POI leave message format", "sessionId", client.SessionID)
-		return
-	}
-	
-	poiID, ok := data["poiId"].(string)
-	if !ok || poiID == "" {
-		h.logger.Error("Missing or invalid POI ID in leave message", "sessionId", client.SessionID)
-		return
-	}
-	
-	// Check rate limit
-	if err := h.rateLimiter.CheckRateLimit(ctx, client.UserID, services.ActionLeavePOI); err != nil {
-		if rateLimitErr, ok := err.(*services.RateLimitError); ok {
-			errorMsg := Message{
-				Type: "error",
-				Data: map[string]interface{}{
-					"message": "Rate limit exceeded: " + rateLimitErr.Error(),
-				},
-				Timestamp: time.Now(),
-			}
-			client.Send <- errorMsg
-		}
-		h.logger.Warn("POI leave rate limited", "sessionId", client.SessionID, "userId", client.UserID)
+	var payload POIMembershipPayload
+	if !h.decodeMessagePayload(client, msg, &payload) {
 		return
 	}
+	poiID := payload.POIID
 	
 	// Call POI service to leave the POI
 	if err := h.poiService.LeavePOI(ctx, poiID, client.UserID); err != nil {
-		h.logger.Error("Failed to leave POI", "sessionId", client.SessionID, "userId", client.UserID, "poiId", poiID, "error", err)
-		errorMsg := Message{
+		h.requestLogger(ctx).Error("Failed to leave POI", "sessionId", client.SessionID, "userId", client.UserID, "poiId", poiID, "error", err)
+		errorMsg := replyTo(ctx, Message{
 			Type: "error",
 			Data: map[string]interface{}{
 				"message": "Failed to leave POI: " + err.Error(),
 			},
 			Timestamp: time.Now(),
-		}
+		})
 		select {
 		case client.Send <- errorMsg:
 		default:
-			h.logger.Warn("Failed to send POI leave error", "sessionId", client.SessionID)
+			h.requestLogger(ctx).Warn("Failed to send POI leave error", "sessionId", client.SessionID)
 		}
 		return
 	}
 	
+	h.speakers.ClearUser(poiID, client.UserID)
+	
 	// Send acknowledgment
-	ackMsg := Message{
+	ackMsg := replyTo(ctx, Message{
 		Type: "poi_leave_ack",
 		Timestamp: time.Now(),
 		Data: map[string]interface{}{
@@ -968,12 +1688,12 @@ func (h *Handler) handlePOILeave(ctx context.Context, client *Client, msg Messag
 			"poiId": poiID,
 			"success": true,
 		},
-	}
+	})
 	
 	select {
 	case client.Send <- ackMsg:
 	default:
-		h.logger.Warn("Failed to send POI leave acknowledgment", "sessionId", client.SessionID)
+		h.requestLogger(ctx).Warn("Failed to send POI leave acknowledgment", "sessionId", client.SessionID)
 	}
 	
 	// Get user display name for the broadcast
@@ -988,161 +1708,306 @@ func (h *Handler) handlePOILeave(ctx context.Context, client *Client, msg Messag
 		}
 	}
 
+	// Get current count
+	currentCount, err := h.poiService.GetPOIParticipantCount(ctx, poiID)
+	if err != nil {
+		currentCount = 0 // Fallback
+	}
+
 	// Broadcast POI leave event to other clients in the same map
 	broadcastMsg := Message{
 		Type: "poi_left",
 		Timestamp: time.Now(),
 		Data: map[string]interface{}{
-			"sessionId": client.SessionID,
-			"userId": client.UserID,
-			"poiId": poiID,
-			"participants": []map[string]interface{}{
-				{
-					"id": client.UserID,
-					"name": displayName,
-					"avatarUrl": nil, // TODO: Add avatar URL if available
-				},
-			},
+			"sessionId":    client.SessionID,
+			"userId":       client.UserID,
+			"poiId":        poiID,
+			"currentCount": currentCount,
+			"participant":  sessionParticipant(session, client.UserID, displayName, h.mapProfilePrivacy(ctx, client.MapID)),
 		},
 	}
 	
 	h.manager.BroadcastToMapExcept(client.MapID, client.SessionID, broadcastMsg)
 	
-	h.logger.Info("User left POI", "sessionId", client.SessionID, "userId", client.UserID, "poiId", poiID)
+	h.requestLogger(ctx).Info("User left POI", "sessionId", client.SessionID, "userId", client.UserID, "poiId", poiID)
+}
+
+
+// handleSpeakingState records whether the client is speaking in a POI call. The
+// active speaker of the POI is broadcast to the map once the state settles.
+func (h *Handler) handleSpeakingState(ctx context.Context, client *Client, msg Message) {
+	var payload SpeakingStatePayload
+	if !h.decodeMessagePayload(client, msg, &payload) {
+		return
+	}
+	poiID, speaking := payload.POIID, *payload.Speaking
+	
+	// Only participants of the POI may be announced as its speaker
+	if speaking && !h.isPOIParticipant(ctx, poiID, client.UserID) {
+		h.requestLogger(ctx).Warn("Ignoring speaking state from non-participant", 
+			"sessionId", client.SessionID, 
+			"userId", client.UserID, 
+			"poiId", poiID)
+		return
+	}
+	
+	h.speakers.SetSpeaking(client.MapID, poiID, client.UserID, speaking)
 }
 
+// isPOIParticipant checks whether a user has joined a POI
+func (h *Handler) isPOIParticipant(ctx context.Context, poiID, userID string) bool {
+	if h.poiService == nil {
+		return false
+	}
+	
+	participants, err := h.poiService.GetPOIParticipantsWithInfo(ctx, poiID)
+	if err != nil {
+		h.requestLogger(ctx).Warn("Failed to get POI participants", "poiId", poiID, "error", err)
+		return false
+	}
+	
+	for _, participant := range participants {
+		if participant.ID == userID {
+			return true
+		}
+	}
+	return false
+}
 
-// handleRequestInitialUsers sends the list of currently connected users to a new client
+// handleRequestInitialUsers sends the list of currently connected users to a
+// new client, in pages on huge maps
 func (h *Handler) handleRequestInitialUsers(ctx context.Context, client *Client, msg Message) {
-	h.logger.Info("📋 Processing initial users request", 
+	h.requestLogger(ctx).Info("📋 Processing initial users request", 
 		"sessionId", client.SessionID, 
 		"mapId", client.MapID)
 	
-	// Get all sessions for the current map
-	sessions := h.manager.GetMapClientSessions(client.MapID)
+	users := h.initialUsers(ctx, client)
+	if h.initialUsersPageSize > 0 && len(users) > h.initialUsersPageSize {
+		h.sendInitialUsersPages(ctx, client, users)
+		return
+	}
+	
+	// Send initial users message
+	initialUsersMsg := Message{
+		Type: "initial_users",
+		Data: map[string]interface{}{
+			"users": users,
+		},
+		Timestamp: time.Now(),
+	}
+	
+	select {
+	case client.Send <- initialUsersMsg:
+		h.requestLogger(ctx).Info("Sent initial users to client", 
+			"sessionId", client.SessionID, 
+			"userCount", len(users))
+	default:
+		h.requestLogger(ctx).Warn("Failed to send initial users to client", 
+			"sessionId", client.SessionID)
+	}
+}
+
+// initialUsers returns every other active session on the client's map with its user's profile
+func (h *Handler) initialUsers(ctx context.Context, client *Client) []map[string]interface{} {
+	sessions := h.presentSessions(ctx, client.MapID)
 	
 	var users []map[string]interface{}
 	
-	// For each session, get the user information
+	// Regular participants only see coarse positions on maps with position privacy
+	privacy := h.positionPrivacy(ctx, client.MapID)
+	if privacy.Enabled() && h.manager.IsFacilitator(client) {
+		privacy = models.PositionPrivacy{}
+	}
+	
 	for _, sessionID := range sessions {
 		if sessionID == client.SessionID {
 			continue // Skip the requesting client
 		}
 		
-		// Get session info from the session service
-		session, err := h.sessionService.GetSession(ctx, sessionID)
+		if userData, ok := h.presenceUser(ctx, sessionID, privacy); ok {
+			users = append(users, userData)
+		}
+	}
+	
+	return users
+}
+
+// presentSessions returns the sessions connected to a map, skipping those
+// whose session stopped sending heartbeats
+func (h *Handler) presentSessions(ctx context.Context, mapID string) []string {
+	sessions := h.manager.GetMapClientSessions(mapID)
+	
+	if h.presence != nil {
+		present, err := h.presence.FilterPresentSessions(ctx, sessions)
 		if err != nil {
-			h.logger.Warn("Failed to get session for initial users", 
-				"sessionId", sessionID, 
+			h.requestLogger(ctx).Warn("Failed to check session presence", 
+				"mapId", mapID, 
 				"error", err.Error())
-			continue
-		}
-		
-		if !session.IsActive {
-			continue
+		} else {
+			sessions = present
 		}
-		
-		// Try to get user profile for display name, avatar, and about me
-		displayName := session.UserID
-		var avatarURL *string
-		var aboutMe *string
-		
-		if h.userService != nil {
-			user, err := h.userService.GetUser(ctx, session.UserID)
-			if err == nil && user != nil {
-				displayName = user.DisplayName
-				avatarURL = user.AvatarURL
-				aboutMe = user.AboutMe
-				h.logger.Info("📸 User profile found for initial users", 
-					"userId", session.UserID, 
-					"displayName", displayName,
-					"hasAvatar", avatarURL != nil,
-					"avatarURL", func() string {
-						if avatarURL != nil {
-							return *avatarURL
-						}
-						return "nil"
-					}())
-			} else {
-				h.logger.Debug("Could not get user profile for display name", 
-					"userId", session.UserID, 
-					"error", err)
-				// Fallback to first 8 characters of UUID
-				if len(session.UserID) > 8 {
-					displayName = session.UserID[:8]
-				}
+	}
+	
+	return sessions
+}
+
+// presenceUser describes an active session with its user's profile for
+// presence payloads. It returns false if the session is gone or inactive.
+func (h *Handler) presenceUser(ctx context.Context, sessionID string, privacy models.PositionPrivacy) (map[string]interface{}, bool) {
+	// Get session info from the session service
+	session, err := h.sessionService.GetSession(ctx, sessionID)
+	if err != nil {
+		h.requestLogger(ctx).Warn("Failed to get session for presence", 
+			"sessionId", sessionID, 
+			"error", err.Error())
+		return nil, false
+	}
+	
+	if !session.IsActive {
+		return nil, false
+	}
+	
+	// Try to get user profile for display name, avatar, and about me
+	displayName := session.UserID
+	var avatarURL *string
+	var aboutMe *string
+	role := models.UserRoleUser
+	
+	if h.userService != nil {
+		user, err := h.userService.GetUser(ctx, session.UserID)
+		if err == nil && user != nil {
+			displayName = user.DisplayName
+			avatarURL = user.AvatarURL
+			aboutMe = user.AboutMe
+			if user.Role != "" {
+				role = user.Role
 			}
+			h.requestLogger(ctx).Info("📸 User profile found for presence", 
+				"userId", session.UserID, 
+				"displayName", displayName,
+				"hasAvatar", avatarURL != nil,
+				"avatarURL", func() string {
+					if avatarURL != nil {
+						return *avatarURL
+					}
+					return "nil"
+				}())
 		} else {
+			h.requestLogger(ctx).Debug("Could not get user profile for display name", 
+				"userId", session.UserID, 
+				"error", err)
 			// Fallback to first 8 characters of UUID
 			if len(session.UserID) > 8 {
 				displayName = session.UserID[:8]
 			}
 		}
-		
-		// Use avatar URL as-is (it's already a full URL from storage)
-		var fullAvatarURL *string
-		if avatarURL != nil && *avatarURL != "" {
-			fullAvatarURL = avatarURL
-		}
-		
-		userData := map[string]interface{}{
-			"sessionId":   sessionID,
-			"userId":      session.UserID,
-			"displayName": displayName,
-			"avatarURL":   fullAvatarURL,
-			"aboutMe":     aboutMe,
-			"position": map[string]float64{
-				"lat": session.AvatarPos.Lat,
-				"lng": session.AvatarPos.Lng,
-			},
-			"role": "user", // Default role
+	} else {
+		// Fallback to first 8 characters of UUID
+		if len(session.UserID) > 8 {
+			displayName = session.UserID[:8]
 		}
-		
-		users = append(users, userData)
 	}
 	
-	// Send initial users message
-	initialUsersMsg := Message{
-		Type: "initial_users",
-		Data: map[string]interface{}{
-			"users": users,
+	// Use avatar URL as-is (it's already a full URL from storage)
+	var fullAvatarURL *string
+	if avatarURL != nil && *avatarURL != "" {
+		fullAvatarURL = avatarURL
+	}
+	
+	userData := map[string]interface{}{
+		"sessionId":   sessionID,
+		"userId":      session.UserID,
+		"displayName": displayName,
+		"avatarURL":   fullAvatarURL,
+		"aboutMe":     aboutMe,
+		"position": map[string]float64{
+			"lat": session.AvatarPos.Lat,
+			"lng": session.AvatarPos.Lng,
 		},
-		Timestamp: time.Now(),
+		"role": string(role),
+		"currentPoiId": h.currentPOIID(ctx, session.UserID),
+		"status": h.manager.UserStatus(session.MapID, session.UserID),
 	}
+	redactProfile(userData, h.mapProfilePrivacy(ctx, session.MapID))
 	
-	select {
-	case client.Send <- initialUsersMsg:
-		h.logger.Info("Sent initial users to client", 
-			"sessionId", client.SessionID, 
-			"userCount", len(users))
-	default:
-		h.logger.Warn("Failed to send initial users to client", 
-			"sessionId", client.SessionID)
-	}}
+	if privacy.Enabled() {
+		userData = coarsePositionData(userData, privacy, session.AvatarPos)
+	}
+	
+	return userData, true
+}
+
+// currentPOIID returns the POI a user is in for presence payloads, or nil if
+// the user isn't in one or it can't be resolved
+func (h *Handler) currentPOIID(ctx context.Context, userID string) *string {
+	if h.poiService == nil {
+		return nil
+	}
+	
+	poiID, err := h.poiService.GetCurrentPOI(ctx, userID)
+	if err != nil {
+		h.requestLogger(ctx).Debug("Could not resolve current POI", "userId", userID, "error", err)
+		return nil
+	}
+	if poiID == "" {
+		return nil
+	}
+	return &poiID
+}
+
+// sessionParticipant describes the user behind a session for POI join and
+// leave broadcasts, without the profile fields the map hides
+func sessionParticipant(session *models.Session, userID, displayName string, privacy models.ProfilePrivacy) map[string]interface{} {
+	var avatarURL interface{}
+	if session != nil && session.User != nil && session.User.AvatarURL != nil {
+		avatarURL = *session.User.AvatarURL
+	}
+	participant := map[string]interface{}{
+		"id":        userID,
+		"name":      displayName,
+		"avatarUrl": avatarURL,
+	}
+	redactProfile(participant, privacy)
+	return participant
+}
 
 // Video Call Handlers
 
 // handleCallRequest processes incoming call requests
 func (h *Handler) handleCallRequest(ctx context.Context, client *Client, msg Message) {
-	h.logger.Info("📞 Call request received", 
+	h.requestLogger(ctx).Info("📞 Call request received", 
 		"sessionId", client.SessionID, 
 		"userId", client.UserID)
 	
-	data, ok := msg.Data.(map[string]interface{})
-	if !ok {
-		h.sendErrorMessage(client, "Invalid call request data format")
+	var payload CallRequestPayload
+	if !h.decodeMessagePayload(client, msg, &payload) {
 		return
 	}
+	targetUserId, callId := payload.TargetUserID, payload.CallID
 	
-	targetUserId, ok := data["targetUserId"].(string)
-	if !ok || targetUserId == "" {
-		h.sendErrorMessage(client, "Target user ID is required for call request")
+	// Reject calls from users blocked by the abuse heuristics
+	if h.abuseGuard != nil && h.abuseGuard.IsRestricted(ctx, client.UserID, services.RestrictionBlockCalls) {
+		h.requestLogger(ctx).Warn("🚫 Call request blocked by restriction",
+			"callId", callId,
+			"caller", client.UserID,
+			"target", targetUserId)
+		
+		client.Send <- replyTo(ctx, Message{
+			Type: "error",
+			Data: map[string]interface{}{
+				"code":    "CALLS_BLOCKED",
+				"message": "Calling is temporarily blocked for your account",
+				"callId":  callId,
+			},
+			Timestamp: time.Now(),
+		})
 		return
 	}
+	// Every ring counts towards the ping heuristics, answered or not
+	h.recordAbuseSignal(ctx, client.UserID, client.MapID, services.SignalPing)
 	
-	callId, ok := data["callId"].(string)
-	if !ok || callId == "" {
-		h.sendErrorMessage(client, "Call ID is required for call request")
+	// Presenting users don't want to be interrupted
+	if h.declineWhilePresenting(ctx, client, callId, targetUserId) {
 		return
 	}
 	
@@ -1150,7 +2015,7 @@ func (h *Handler) handleCallRequest(ctx context.Context, client *Client, msg Mes
 	callerInfo := map[string]interface{}{
 		"userId":      client.UserID,
 		"sessionId":   client.SessionID,
-		"displayName": data["callerName"], // Optional caller name
+		"displayName": payload.CallerName,
 	}
 	
 	// Create call request message for target user
@@ -1165,8 +2030,9 @@ func (h *Handler) handleCallRequest(ctx context.Context, client *Client, msg Mes
 	
 	// Find target user and send call request
 	h.manager.BroadcastToUser(targetUserId, callRequestMsg, client.SessionID)
+	h.ringer.ring(callId, client.MapID, client.UserID, targetUserId)
 	
-	h.logger.Info("📞 Call request sent to target user", 
+	h.requestLogger(ctx).Info("📞 Call request sent to target user", 
 		"callId", callId,
 		"caller", client.UserID,
 		"target", targetUserId)
@@ -1174,27 +2040,16 @@ func (h *Handler) handleCallRequest(ctx context.Context, client *Client, msg Mes
 
 // handleCallAccept processes call accept messages
 func (h *Handler) handleCallAccept(ctx context.Context, client *Client, msg Message) {
-	h.logger.Info("✅ Call accept received", 
+	h.requestLogger(ctx).Info("✅ Call accept received", 
 		"sessionId", client.SessionID, 
 		"userId", client.UserID)
 	
-	data, ok := msg.Data.(map[string]interface{})
-	if !ok {
-		h.sendErrorMessage(client, "Invalid call accept data format")
-		return
-	}
-	
-	callId, ok := data["callId"].(string)
-	if !ok || callId == "" {
-		h.sendErrorMessage(client, "Call ID is required for call accept")
-		return
-	}
-	
-	callerUserId, ok := data["callerUserId"].(string)
-	if !ok || callerUserId == "" {
-		h.sendErrorMessage(client, "Caller user ID is required for call accept")
+	var payload CallResponsePayload
+	if !h.decodeMessagePayload(client, msg, &payload) {
 		return
 	}
+	callId, callerUserId := payload.CallID, payload.CallerUserID
+	h.ringer.answer(callId)
 	
 	// Create call accept message for caller
 	callAcceptMsg := Message{
@@ -1208,6 +2063,17 @@ func (h *Handler) handleCallAccept(ctx context.Context, client *Client, msg Mess
 	
 	// Send accept message to caller
 	h.manager.BroadcastToUser(callerUserId, callAcceptMsg, client.SessionID)
+	h.startCallUsage(client.MapID, callId)
+	
+	// Both participants started the call
+	if h.analytics != nil {
+		for _, userID := range []string{callerUserId, client.UserID} {
+			h.analytics.Track(analytics.EventCallStarted, userID, map[string]interface{}{
+				"callId": callId,
+				"mapId":  client.MapID,
+			})
+		}
+	}
 	
 	// Broadcast call status update to all users on the map (both users are now in call)
 	callStatusMsg := Message{
@@ -1218,8 +2084,8 @@ func (h *Handler) handleCallAccept(ctx context.Context, client *Client, msg Mess
 		},
 		Timestamp: time.Now(),
 	}
-	h.manager.BroadcastToMap(client.MapID, callStatusMsg)
-	h.logger.Info("📡 Broadcasting call status", "userId", client.UserID, "isInCall", true, "mapId", client.MapID)
+	h.manager.BroadcastToMap(client.MapID, onTopics(callStatusMsg, userTopic(client.UserID)))
+	h.requestLogger(ctx).Info("📡 Broadcasting call status", "userId", client.UserID, "isInCall", true, "mapId", client.MapID)
 	
 	callerStatusMsg := Message{
 		Type: "user_call_status",
@@ -1229,10 +2095,10 @@ func (h *Handler) handleCallAccept(ctx context.Context, client *Client, msg Mess
 		},
 		Timestamp: time.Now(),
 	}
-	h.manager.BroadcastToMap(client.MapID, callerStatusMsg)
-	h.logger.Info("📡 Broadcasting call status", "userId", callerUserId, "isInCall", true, "mapId", client.MapID)
+	h.manager.BroadcastToMap(client.MapID, onTopics(callerStatusMsg, userTopic(callerUserId)))
+	h.requestLogger(ctx).Info("📡 Broadcasting call status", "userId", callerUserId, "isInCall", true, "mapId", client.MapID)
 	
-	h.logger.Info("✅ Call accept sent to caller", 
+	h.requestLogger(ctx).Info("✅ Call accept sent to caller", 
 		"callId", callId,
 		"accepter", client.UserID,
 		"caller", callerUserId)
@@ -1240,27 +2106,16 @@ func (h *Handler) handleCallAccept(ctx context.Context, client *Client, msg Mess
 
 // handleCallReject processes call reject messages
 func (h *Handler) handleCallReject(ctx context.Context, client *Client, msg Message) {
-	h.logger.Info("❌ Call reject received", 
+	h.requestLogger(ctx).Info("❌ Call reject received", 
 		"sessionId", client.SessionID, 
 		"userId", client.UserID)
 	
-	data, ok := msg.Data.(map[string]interface{})
-	if !ok {
-		h.sendErrorMessage(client, "Invalid call reject data format")
-		return
-	}
-	
-	callId, ok := data["callId"].(string)
-	if !ok || callId == "" {
-		h.sendErrorMessage(client, "Call ID is required for call reject")
-		return
-	}
-	
-	callerUserId, ok := data["callerUserId"].(string)
-	if !ok || callerUserId == "" {
-		h.sendErrorMessage(client, "Caller user ID is required for call reject")
+	var payload CallResponsePayload
+	if !h.decodeMessagePayload(client, msg, &payload) {
 		return
 	}
+	callId, callerUserId := payload.CallID, payload.CallerUserID
+	h.ringer.answer(callId)
 	
 	// Create call reject message for caller
 	callRejectMsg := Message{
@@ -1275,6 +2130,9 @@ func (h *Handler) handleCallReject(ctx context.Context, client *Client, msg Mess
 	// Send reject message to caller
 	h.manager.BroadcastToUser(callerUserId, callRejectMsg, client.SessionID)
 	
+	// Repeatedly rejected callers are tracked by the abuse heuristics
+	h.recordAbuseSignal(ctx, callerUserId, client.MapID, services.SignalCallRejected)
+	
 	// Broadcast call status update to all users on the map (both users are no longer in call)
 	callStatusMsg := Message{
 		Type: "user_call_status",
@@ -1284,8 +2142,8 @@ func (h *Handler) handleCallReject(ctx context.Context, client *Client, msg Mess
 		},
 		Timestamp: time.Now(),
 	}
-	h.manager.BroadcastToMap(client.MapID, callStatusMsg)
-	h.logger.Info("📡 Broadcasting call status", "userId", client.UserID, "isInCall", false, "mapId", client.MapID)
+	h.manager.BroadcastToMap(client.MapID, onTopics(callStatusMsg, userTopic(client.UserID)))
+	h.requestLogger(ctx).Info("📡 Broadcasting call status", "userId", client.UserID, "isInCall", false, "mapId", client.MapID)
 	
 	callerStatusMsg := Message{
 		Type: "user_call_status",
@@ -1295,10 +2153,10 @@ func (h *Handler) handleCallReject(ctx context.Context, client *Client, msg Mess
 		},
 		Timestamp: time.Now(),
 	}
-	h.manager.BroadcastToMap(client.MapID, callerStatusMsg)
-	h.logger.Info("📡 Broadcasting call status", "userId", callerUserId, "isInCall", false, "mapId", client.MapID)
+	h.manager.BroadcastToMap(client.MapID, onTopics(callerStatusMsg, userTopic(callerUserId)))
+	h.requestLogger(ctx).Info("📡 Broadcasting call status", "userId", callerUserId, "isInCall", false, "mapId", client.MapID)
 	
-	h.logger.Info("❌ Call reject sent to caller", 
+	h.requestLogger(ctx).Info("❌ Call reject sent to caller", 
 		"callId", callId,
 		"rejecter", client.UserID,
 		"caller", callerUserId)
@@ -1306,27 +2164,17 @@ func (h *Handler) handleCallReject(ctx context.Context, client *Client, msg Mess
 
 // handleCallEnd processes call end messages
 func (h *Handler) handleCallEnd(ctx context.Context, client *Client, msg Message) {
-	h.logger.Info("📵 Call end received", 
+	h.requestLogger(ctx).Info("📵 Call end received", 
 		"sessionId", client.SessionID, 
 		"userId", client.UserID)
 	
-	data, ok := msg.Data.(map[string]interface{})
-	if !ok {
-		h.sendErrorMessage(client, "Invalid call end data format")
-		return
-	}
-	
-	callId, ok := data["callId"].(string)
-	if !ok || callId == "" {
-		h.sendErrorMessage(client, "Call ID is required for call end")
-		return
-	}
-	
-	otherUserId, ok := data["otherUserId"].(string)
-	if !ok || otherUserId == "" {
-		h.sendErrorMessage(client, "Other user ID is required for call end")
+	var payload CallEndPayload
+	if !h.decodeMessagePayload(client, msg, &payload) {
 		return
 	}
+	callId, otherUserId := payload.CallID, payload.OtherUserID
+	// Callers hang up calls still ringing with call_end
+	h.ringer.answer(callId)
 	
 	// Create call end message for other user
 	callEndMsg := Message{
@@ -1340,6 +2188,7 @@ func (h *Handler) handleCallEnd(ctx context.Context, client *Client, msg Message
 	
 	// Send end message to other user
 	h.manager.BroadcastToUser(otherUserId, callEndMsg, client.SessionID)
+	h.endCallUsage(callId)
 	
 	// Broadcast call status update to all users on the map (both users are no longer in call)
 	callStatusMsg := Message{
@@ -1350,8 +2199,8 @@ func (h *Handler) handleCallEnd(ctx context.Context, client *Client, msg Message
 		},
 		Timestamp: time.Now(),
 	}
-	h.manager.BroadcastToMap(client.MapID, callStatusMsg)
-	h.logger.Info("📡 Broadcasting call status", "userId", client.UserID, "isInCall", false, "mapId", client.MapID)
+	h.manager.BroadcastToMap(client.MapID, onTopics(callStatusMsg, userTopic(client.UserID)))
+	h.requestLogger(ctx).Info("📡 Broadcasting call status", "userId", client.UserID, "isInCall", false, "mapId", client.MapID)
 	
 	otherStatusMsg := Message{
 		Type: "user_call_status",
@@ -1361,32 +2210,32 @@ func (h *Handler) handleCallEnd(ctx context.Context, client *Client, msg Message
 		},
 		Timestamp: time.Now(),
 	}
-	h.manager.BroadcastToMap(client.MapID, otherStatusMsg)
-	h.logger.Info("📡 Broadcasting call status", "userId", otherUserId, "isInCall", false, "mapId", client.MapID)
+	h.manager.BroadcastToMap(client.MapID, onTopics(otherStatusMsg, userTopic(otherUserId)))
+	h.requestLogger(ctx).Info("📡 Broadcasting call status", "userId", otherUserId, "isInCall", false, "mapId", client.MapID)
 	
-	h.logger.Info("📵 Call end sent to other user", 
+	h.requestLogger(ctx).Info("📵 Call end sent to other user", 
 		"callId", callId,
 		"ender", client.UserID,
 		"other", otherUserId)
 }
 
 // sendErrorMessage sends an error message to a client
-func (h *Handler) sendErrorMessage(client *Client, message string) {
-	errorMsg := Message{
+func (h *Handler) sendErrorMessage(ctx context.Context, client *Client, message string) {
+	errorMsg := replyTo(ctx, Message{
 		Type: "error",
 		Data: map[string]interface{}{
 			"message": message,
 		},
 		Timestamp: time.Now(),
-	}
+	})
 	
 	select {
 	case client.Send <- errorMsg:
-		h.logger.Warn("Error message sent to client", 
+		h.requestLogger(ctx).Warn("Error message sent to client", 
 			"sessionId", client.SessionID, 
 			"message", message)
 	default:
-		h.logger.Error("Failed to send error message to client", 
+		h.requestLogger(ctx).Error("Failed to send error message to client", 
 			"sessionId", client.SessionID, 
 			"message", message)
 	}
@@ -1396,33 +2245,15 @@ func (h *Handler) sendErrorMessage(client *Client, message string) {
 
 // handleWebRTCOffer processes WebRTC offer messages
 func (h *Handler) handleWebRTCOffer(ctx context.Context, client *Client, msg Message) {
-	h.logger.Info("📝 WebRTC offer received", 
+	h.requestLogger(ctx).Info("📝 WebRTC offer received", 
 		"sessionId", client.SessionID, 
 		"userId", client.UserID)
 	
-	data, ok := msg.Data.(map[string]interface{})
-	if !ok {
-		h.sendErrorMessage(client, "Invalid WebRTC offer data format")
-		return
-	}
-	
-	callId, ok := data["callId"].(string)
-	if !ok || callId == "" {
-		h.sendErrorMessage(client, "Call ID is required for WebRTC offer")
-		return
-	}
-	
-	targetUserId, ok := data["targetUserId"].(string)
-	if !ok || targetUserId == "" {
-		h.sendErrorMessage(client, "Target user ID is required for WebRTC offer")
-		return
-	}
-	
-	sdp := data["sdp"]
-	if sdp == nil {
-		h.sendErrorMessage(client, "SDP is required for WebRTC offer")
+	var payload WebRTCDescriptionPayload
+	if !h.decodeMessagePayload(client, msg, &payload) {
 		return
 	}
+	callId, targetUserId, sdp := payload.CallID, payload.TargetUserID, payload.SDP
 	
 	// Create WebRTC offer message for target user
 	offerMsg := Message{
@@ -1438,7 +2269,7 @@ func (h *Handler) handleWebRTCOffer(ctx context.Context, client *Client, msg Mes
 	// Send offer to target user
 	h.manager.BroadcastToUser(targetUserId, offerMsg, client.SessionID)
 	
-	h.logger.Info("📝 WebRTC offer sent to target user", 
+	h.requestLogger(ctx).Info("📝 WebRTC offer sent to target user", 
 		"callId", callId,
 		"from", client.UserID,
 		"to", targetUserId)
@@ -1446,33 +2277,15 @@ func (h *Handler) handleWebRTCOffer(ctx context.Context, client *Client, msg Mes
 
 // handleWebRTCAnswer processes WebRTC answer messages
 func (h *Handler) handleWebRTCAnswer(ctx context.Context, client *Client, msg Message) {
-	h.logger.Info("📋 WebRTC answer received", 
+	h.requestLogger(ctx).Info("📋 WebRTC answer received", 
 		"sessionId", client.SessionID, 
 		"userId", client.UserID)
 	
-	data, ok := msg.Data.(map[string]interface{})
-	if !ok {
-		h.sendErrorMessage(client, "Invalid WebRTC answer data format")
-		return
-	}
-	
-	callId, ok := data["callId"].(string)
-	if !ok || callId == "" {
-		h.sendErrorMessage(client, "Call ID is required for WebRTC answer")
-		return
-	}
-	
-	targetUserId, ok := data["targetUserId"].(string)
-	if !ok || targetUserId == "" {
-		h.sendErrorMessage(client, "Target user ID is required for WebRTC answer")
-		return
-	}
-	
-	sdp := data["sdp"]
-	if sdp == nil {
-		h.sendErrorMessage(client, "SDP is required for WebRTC answer")
+	var payload WebRTCDescriptionPayload
+	if !h.decodeMessagePayload(client, msg, &payload) {
 		return
 	}
+	callId, targetUserId, sdp := payload.CallID, payload.TargetUserID, payload.SDP
 	
 	// Create WebRTC answer message for target user
 	answerMsg := Message{
@@ -1488,7 +2301,7 @@ func (h *Handler) handleWebRTCAnswer(ctx context.Context, client *Client, msg Me
 	// Send answer to target user
 	h.manager.BroadcastToUser(targetUserId, answerMsg, client.SessionID)
 	
-	h.logger.Info("📋 WebRTC answer sent to target user", 
+	h.requestLogger(ctx).Info("📋 WebRTC answer sent to target user", 
 		"callId", callId,
 		"from", client.UserID,
 		"to", targetUserId)
@@ -1496,33 +2309,15 @@ func (h *Handler) handleWebRTCAnswer(ctx context.Context, client *Client, msg Me
 
 // handleICECandidate processes ICE candidate messages
 func (h *Handler) handleICECandidate(ctx context.Context, client *Client, msg Message) {
-	h.logger.Info("🧊 ICE candidate received", 
+	h.requestLogger(ctx).Info("🧊 ICE candidate received", 
 		"sessionId", client.SessionID, 
 		"userId", client.UserID)
 	
-	data, ok := msg.Data.(map[string]interface{})
-	if !ok {
-		h.sendErrorMessage(client, "Invalid ICE candidate data format")
-		return
-	}
-	
-	callId, ok := data["callId"].(string)
-	if !ok || callId == "" {
-		h.sendErrorMessage(client, "Call ID is required for ICE candidate")
-		return
-	}
-	
-	targetUserId, ok := data["targetUserId"].(string)
-	if !ok || targetUserId == "" {
-		h.sendErrorMessage(client, "Target user ID is required for ICE candidate")
-		return
-	}
-	
-	candidate := data["candidate"]
-	if candidate == nil {
-		h.sendErrorMessage(client, "Candidate is required for ICE candidate")
+	var payload ICECandidatePayload
+	if !h.decodeMessagePayload(client, msg, &payload) {
 		return
 	}
+	callId, targetUserId, candidate := payload.CallID, payload.TargetUserID, payload.Candidate
 	
 	// Create ICE candidate message for target user
 	candidateMsg := Message{
@@ -1538,7 +2333,7 @@ func (h *Handler) handleICECandidate(ctx context.Context, client *Client, msg Me
 	// Send candidate to target user
 	h.manager.BroadcastToUser(targetUserId, candidateMsg, client.SessionID)
 	
-	h.logger.Info("🧊 ICE candidate sent to target user", 
+	h.requestLogger(ctx).Info("🧊 ICE candidate sent to target user", 
 		"callId", callId,
 		"from", client.UserID,
 		"to", targetUserId)
@@ -1575,6 +2370,32 @@ func (h *Handler) handlePubSubEvent(eventType string, data interface{}) {
 		h.handlePOILeftEvent(data)
 	case "poi_updated":
 		h.handlePOIUpdatedEvent(data)
+	case "poi_deleted":
+		h.handlePOIDeletedEvent(data)
+	case "poi_merged":
+		h.handlePOIMergedEvent(data)
+	case "poi_participant_added", "poi_participant_removed":
+		h.handlePOIParticipantDeltaEvent(eventType, data)
+	case "user_profile_updated":
+		h.handleUserProfileUpdatedEvent(data)
+	case "role_changed":
+		h.handleRoleChangedEvent(data)
+	case "map_frozen", "map_unfrozen":
+		h.handleMapFreezeEvent(eventType, data)
+	case "onboarding_progress":
+		h.handleOnboardingProgressEvent(data)
+	case "maintenance_changed":
+		h.handleMaintenanceEvent(data)
+	case "hand_queue_updated":
+		h.handleHandQueueEvent(data)
+	case "summoned_to_poi":
+		h.handleSummonEvent(data)
+	case "user_kicked":
+		h.handleUserKickedEvent(data)
+	case "map_deleted":
+		h.handleMapDeletedEvent(data)
+	case "poi_reminder":
+		h.handlePOIReminderEvent(data)
 	default:
 		h.logger.Warn("❓ Unknown PubSub event type", "type", eventType)
 	}
@@ -1599,7 +2420,12 @@ func (h *Handler) handlePOICreatedEvent(data interface{}) {
 		Type:      "poi_created",
 		Data:      poiData,
 		Timestamp: time.Now(),
+		publishedAt: eventPublishedAt(poiData),
 	}
+	if position, ok := eventPosition(poiData); ok {
+		message = atPositions(message, models.PositionPrivacy{}, position)
+	}
+	message = onTopics(message, poiEventTopics(poiData)...)
 	
 	// Broadcast to all clients on the same map
 	h.manager.BroadcastToMap(mapID, message)
@@ -1626,10 +2452,11 @@ func (h *Handler) handlePOIJoinedEvent(data interface{}) {
 		Type:      "poi_joined",
 		Data:      poiData,
 		Timestamp: time.Now(),
+		publishedAt: eventPublishedAt(poiData),
 	}
 	
-	// Broadcast to all clients on the same map
-	h.manager.BroadcastToMap(mapID, message)
+	// Broadcast to all clients on the same map and the POI's subscribers
+	h.manager.BroadcastToMap(mapID, onTopics(message, poiEventTopics(poiData)...))
 	
 	h.logger.Info("📢 Broadcasted POI joined event", "mapId", mapID, "poiId", poiData["poiId"], "userId", poiData["userId"])
 }
@@ -1653,10 +2480,11 @@ func (h *Handler) handlePOILeftEvent(data interface{}) {
 		Type:      "poi_left",
 		Data:      poiData,
 		Timestamp: time.Now(),
+		publishedAt: eventPublishedAt(poiData),
 	}
 	
-	// Broadcast to all clients on the same map
-	h.manager.BroadcastToMap(mapID, message)
+	// Broadcast to all clients on the same map and the POI's subscribers
+	h.manager.BroadcastToMap(mapID, onTopics(message, poiEventTopics(poiData)...))
 	
 	h.logger.Info("📢 Broadcasted POI left event", "mapId", mapID, "poiId", poiData["poiId"], "userId", poiData["userId"])
 }
@@ -1680,51 +2508,228 @@ func (h *Handler) handlePOIUpdatedEvent(data interface{}) {
 		Type:      "poi_updated",
 		Data:      poiData,
 		Timestamp: time.Now(),
+		publishedAt: eventPublishedAt(poiData),
 	}
 	
-	// Broadcast to all clients on the same map
-	h.manager.BroadcastToMap(mapID, message)
+	// Broadcast to all clients on the same map and the POI's subscribers
+	h.manager.BroadcastToMap(mapID, onTopics(message, poiEventTopics(poiData)...))
 	
 	h.logger.Info("📢 Broadcasted POI updated event", "mapId", mapID, "poiId", poiData["poiId"])
 }
 
-// POI Call Handlers
+// handlePOIDeletedEvent broadcasts POI deletion to all clients on the same map
+func (h *Handler) handlePOIDeletedEvent(data interface{}) {
+	poiData, ok := data.(map[string]interface{})
+	if !ok {
+		h.logger.Error("❌ Invalid POI deleted event data", "data", data)
+		return
+	}
+	
+	mapID, ok := poiData["mapId"].(string)
+	if !ok {
+		h.logger.Error("❌ Missing mapId in POI deleted event", "data", data)
+		return
+	}
+	
+	// Broadcast to all clients on the same map and the POI's subscribers
+	h.manager.BroadcastToMap(mapID, onTopics(Message{
+		Type: "poi_deleted",
+		Data: map[string]interface{}{
+			"poiId": poiData["poiId"],
+			"mapId": mapID,
+		},
+		Timestamp: time.Now(),
+		publishedAt: eventPublishedAt(poiData),
+	}, poiEventTopics(poiData)...))
+	
+	h.logger.Info("📢 Broadcasted POI deleted event", "mapId", mapID, "poiId", poiData["poiId"])
+}
 
-// handlePOICallOffer processes POI-based WebRTC offers
-func (h *Handler) handlePOICallOffer(ctx context.Context, client *Client, msg Message) {
-	h.logger.Info("📞 POI call offer received", 
-		"sessionId", client.SessionID, 
-		"userId", client.UserID)
+// handlePOIMergedEvent broadcasts a POI merge to all clients on the same map
+// and the subscribers of both POIs, so they replace the source POI's ID with
+// the target's
+func (h *Handler) handlePOIMergedEvent(data interface{}) {
+	mergeData, ok := data.(map[string]interface{})
+	if !ok {
+		h.logger.Error("❌ Invalid POI merged event data", "data", data)
+		return
+	}
+
+	mapID, _ := mergeData["mapId"].(string)
+	sourcePOIID, _ := mergeData["sourcePoiId"].(string)
+	targetPOIID, _ := mergeData["targetPoiId"].(string)
+	if mapID == "" || sourcePOIID == "" || targetPOIID == "" {
+		h.logger.Error("❌ Missing mapId or POI IDs in POI merged event", "data", data)
+		return
+	}
+
+	h.manager.BroadcastToMap(mapID, onTopics(Message{
+		Type: "poi_merged",
+		Data: map[string]interface{}{
+			"sourcePoiId":  sourcePOIID,
+			"targetPoiId":  targetPOIID,
+			"mapId":        mapID,
+			"movedUserIds": mergeData["movedUserIds"],
+			"currentCount": mergeData["currentCount"],
+		},
+		Timestamp:   time.Now(),
+		publishedAt: eventPublishedAt(mergeData),
+	}, poiTopic(sourcePOIID), poiTopic(targetPOIID)))
+
+	h.logger.Info("📢 Broadcasted POI merged event", "mapId", mapID, "sourcePoiId", sourcePOIID, "targetPoiId", targetPOIID)
+}
+
+// handleUserProfileUpdatedEvent broadcasts a profile change to all clients on the
+// map, so they drop cached display names and avatars
+func (h *Handler) handleUserProfileUpdatedEvent(data interface{}) {
+	profileData, ok := data.(map[string]interface{})
+	if !ok {
+		h.logger.Error("❌ Invalid user profile updated event data", "data", data)
+		return
+	}
+	
+	mapID, ok := profileData["mapId"].(string)
+	if !ok {
+		h.logger.Error("❌ Missing mapId in user profile updated event", "data", data)
+		return
+	}
 	
-	data, ok := msg.Data.(map[string]interface{})
+	redactProfile(profileData, h.mapProfilePrivacy(context.Background(), mapID))
+	
+	// Create WebSocket message
+	message := Message{
+		Type:      "user_profile_updated",
+		Data:      profileData,
+		Timestamp: time.Now(),
+		publishedAt: eventPublishedAt(profileData),
+	}
+	
+	// Broadcast to all clients on the same map
+	h.manager.BroadcastToMap(mapID, message)
+	
+	h.logger.Info("📢 Broadcasted user profile updated event", "mapId", mapID, "userId", profileData["userId"])
+}
+
+// handleRoleChangedEvent updates the cached role of the user's connections on the
+// map, so facilitator actions follow promotions and demotions without a reconnect,
+// and tells everyone on the map about the new role
+func (h *Handler) handleRoleChangedEvent(data interface{}) {
+	roleData, ok := data.(map[string]interface{})
 	if !ok {
-		h.sendErrorMessage(client, "Invalid POI call offer data format")
+		h.logger.Error("❌ Invalid role changed event data", "data", data)
 		return
 	}
 	
-	poiID, ok := data["poiId"].(string)
-	if !ok || poiID == "" {
-		h.sendErrorMessage(client, "POI ID is required for POI call offer")
+	mapID, _ := roleData["mapId"].(string)
+	userID, _ := roleData["userId"].(string)
+	role, _ := roleData["role"].(string)
+	if mapID == "" || userID == "" || role == "" {
+		h.logger.Error("❌ Missing fields in role changed event", "data", data)
 		return
 	}
 	
-	targetUserId, ok := data["targetUserId"].(string)
-	if !ok || targetUserId == "" {
-		h.sendErrorMessage(client, "Target user ID is required for POI call offer")
+	h.manager.SetUserRole(mapID, userID, models.UserRole(role))
+	
+	h.manager.BroadcastToMap(mapID, Message{
+		Type:      "role_changed",
+		Data:      roleData,
+		Timestamp: time.Now(),
+		publishedAt: eventPublishedAt(roleData),
+	})
+	
+	h.logger.Info("📢 Broadcasted role changed event", "mapId", mapID, "userId", userID, "role", role)
+}
+
+// handlePOIParticipantDeltaEvent broadcasts a single POI roster change to all clients on the same map
+func (h *Handler) handlePOIParticipantDeltaEvent(eventType string, data interface{}) {
+	poiData, ok := data.(map[string]interface{})
+	if !ok {
+		h.logger.Error("❌ Invalid POI participant delta event data", "data", data)
 		return
 	}
 	
-	sdp, ok := data["sdp"]
+	mapID, ok := poiData["mapId"].(string)
 	if !ok {
-		h.sendErrorMessage(client, "SDP is required for POI call offer")
+		h.logger.Error("❌ Missing mapId in POI participant delta event", "data", data)
+		return
+	}
+	
+	message := Message{
+		Type:      eventType,
+		Data:      poiData,
+		Timestamp: time.Now(),
+		publishedAt: eventPublishedAt(poiData),
+	}
+	
+	h.manager.BroadcastToMap(mapID, onTopics(message, poiEventTopics(poiData)...))
+	
+	// Users removed by someone else, e.g. by deleting the POI, are also told
+	// directly so their client exits the POI view and group call immediately
+	reason, _ := poiData["reason"].(string)
+	userID, _ := poiData["userId"].(string)
+	if eventType == "poi_participant_removed" && reason != "" && userID != "" {
+		h.manager.BroadcastToUser(userID, Message{
+			Type: "poi_removed_you",
+			Data: map[string]interface{}{
+				"poiId":  poiData["poiId"],
+				"mapId":  mapID,
+				"reason": reason,
+			},
+			Timestamp: time.Now(),
+			publishedAt: eventPublishedAt(poiData),
+		}, "")
+	}
+}
+
+// BroadcastPOIRosters sends full roster snapshots of every active POI to the
+// clients of each map with local connections. Clients use the sequence numbers
+// to repair rosters after missing a participant delta.
+func (h *Handler) BroadcastPOIRosters(ctx context.Context) error {
+	if h.rosters == nil {
+		return nil
+	}
+	
+	for _, mapID := range h.manager.GetClientMaps() {
+		rosters, err := h.rosters.GetPOIRostersForMap(ctx, mapID)
+		if err != nil {
+			return fmt.Errorf("failed to get POI rosters for map %s: %w", mapID, err)
+		}
+		if len(rosters) == 0 {
+			continue
+		}
+		
+		h.manager.BroadcastToMap(mapID, Message{
+			Type:      "poi_roster_sync",
+			Timestamp: time.Now(),
+			Data: map[string]interface{}{
+				"mapId":   mapID,
+				"rosters": rosters,
+			},
+		})
+	}
+	
+	return nil
+}
+
+// POI Call Handlers
+
+// handlePOICallOffer processes POI-based WebRTC offers
+func (h *Handler) handlePOICallOffer(ctx context.Context, client *Client, msg Message) {
+	h.requestLogger(ctx).Info("📞 POI call offer received", 
+		"sessionId", client.SessionID, 
+		"userId", client.UserID)
+	
+	var payload POICallDescriptionPayload
+	if !h.decodeMessagePayload(client, msg, &payload) {
 		return
 	}
+	poiID, targetUserId, sdp := payload.POIID, payload.TargetUserID, payload.SDP
 	
 	// Get user display name for the offer
 	session, err := h.sessionService.GetSession(ctx, client.SessionID)
 	var displayName string
 	if err != nil || session == nil {
-		h.logger.Warn("Failed to get session for display name", "sessionId", client.SessionID, "error", err)
+		h.requestLogger(ctx).Warn("Failed to get session for display name", "sessionId", client.SessionID, "error", err)
 		displayName = client.UserID // Fallback to user ID
 	} else if session.User != nil {
 		displayName = session.User.DisplayName
@@ -1735,7 +2740,7 @@ func (h *Handler) handlePOICallOffer(ctx context.Context, client *Client, msg Me
 		displayName = client.UserID // Fallback if user not loaded
 	}
 	
-	h.logger.Info("🏷️ POI call offer display name resolved", 
+	h.requestLogger(ctx).Info("🏷️ POI call offer display name resolved", 
 		"sessionId", client.SessionID,
 		"userId", client.UserID,
 		"displayName", displayName,
@@ -1757,7 +2762,7 @@ func (h *Handler) handlePOICallOffer(ctx context.Context, client *Client, msg Me
 	// Send offer to target user
 	h.manager.BroadcastToUser(targetUserId, offerMsg, client.SessionID)
 	
-	h.logger.Info("📞 POI call offer sent to target user", 
+	h.requestLogger(ctx).Info("📞 POI call offer sent to target user", 
 		"poiId", poiID,
 		"from", client.UserID,
 		"fromDisplayName", displayName,
@@ -1766,33 +2771,15 @@ func (h *Handler) handlePOICallOffer(ctx context.Context, client *Client, msg Me
 
 // handlePOICallAnswer processes POI-based WebRTC answers
 func (h *Handler) handlePOICallAnswer(ctx context.Context, client *Client, msg Message) {
-	h.logger.Info("✅ POI call answer received", 
+	h.requestLogger(ctx).Info("✅ POI call answer received", 
 		"sessionId", client.SessionID, 
 		"userId", client.UserID)
 	
-	data, ok := msg.Data.(map[string]interface{})
-	if !ok {
-		h.sendErrorMessage(client, "Invalid POI call answer data format")
-		return
-	}
-	
-	poiID, ok := data["poiId"].(string)
-	if !ok || poiID == "" {
-		h.sendErrorMessage(client, "POI ID is required for POI call answer")
-		return
-	}
-	
-	targetUserId, ok := data["targetUserId"].(string)
-	if !ok || targetUserId == "" {
-		h.sendErrorMessage(client, "Target user ID is required for POI call answer")
-		return
-	}
-	
-	sdp, ok := data["sdp"]
-	if !ok {
-		h.sendErrorMessage(client, "SDP is required for POI call answer")
+	var payload POICallDescriptionPayload
+	if !h.decodeMessagePayload(client, msg, &payload) {
 		return
 	}
+	poiID, targetUserId, sdp := payload.POIID, payload.TargetUserID, payload.SDP
 	
 	// Create POI call answer message for target user
 	answerMsg := Message{
@@ -1808,7 +2795,7 @@ func (h *Handler) handlePOICallAnswer(ctx context.Context, client *Client, msg M
 	// Send answer to target user
 	h.manager.BroadcastToUser(targetUserId, answerMsg, client.SessionID)
 	
-	h.logger.Info("✅ POI call answer sent to target user", 
+	h.requestLogger(ctx).Info("✅ POI call answer sent to target user", 
 		"poiId", poiID,
 		"from", client.UserID,
 		"to", targetUserId)
@@ -1816,33 +2803,15 @@ func (h *Handler) handlePOICallAnswer(ctx context.Context, client *Client, msg M
 
 // handlePOICallICECandidate processes POI-based ICE candidates
 func (h *Handler) handlePOICallICECandidate(ctx context.Context, client *Client, msg Message) {
-	h.logger.Info("🧊 POI call ICE candidate received", 
+	h.requestLogger(ctx).Info("🧊 POI call ICE candidate received", 
 		"sessionId", client.SessionID, 
 		"userId", client.UserID)
 	
-	data, ok := msg.Data.(map[string]interface{})
-	if !ok {
-		h.sendErrorMessage(client, "Invalid POI call ICE candidate data format")
-		return
-	}
-	
-	poiID, ok := data["poiId"].(string)
-	if !ok || poiID == "" {
-		h.sendErrorMessage(client, "POI ID is required for POI call ICE candidate")
-		return
-	}
-	
-	targetUserId, ok := data["targetUserId"].(string)
-	if !ok || targetUserId == "" {
-		h.sendErrorMessage(client, "Target user ID is required for POI call ICE candidate")
-		return
-	}
-	
-	candidate, ok := data["candidate"]
-	if !ok {
-		h.sendErrorMessage(client, "Candidate is required for POI call ICE candidate")
+	var payload POICallICECandidatePayload
+	if !h.decodeMessagePayload(client, msg, &payload) {
 		return
 	}
+	poiID, targetUserId, candidate := payload.POIID, payload.TargetUserID, payload.Candidate
 	
 	// Create POI call ICE candidate message for target user
 	candidateMsg := Message{
@@ -1858,7 +2827,7 @@ func (h *Handler) handlePOICallICECandidate(ctx context.Context, client *Client,
 	// Send ICE candidate to target user
 	h.manager.BroadcastToUser(targetUserId, candidateMsg, client.SessionID)
 	
-	h.logger.Info("🧊 POI call ICE candidate sent to target user", 
+	h.requestLogger(ctx).Info("🧊 POI call ICE candidate sent to target user", 
 		"poiId", poiID,
 		"from", client.UserID,
 		"to", targetUserId)
@@ -171,6 +171,11 @@ func (m *MockPOIService) GetUserPOIs(ctx context.Context, userID string) ([]stri
 	return args.Get(0).([]string), args.Error(1)
 }
 
+func (m *MockPOIService) GetCurrentPOI(ctx context.Context, userID string) (string, error) {
+	args := m.Called(ctx, userID)
+	return args.String(0), args.Error(1)
+}
+
 func (m *MockPOIService) ValidatePOI(ctx context.Context, poiID string) (*models.POI, error) {
 	args := m.Called(ctx, poiID)
 	if args.Get(0) == nil {
@@ -179,6 +184,32 @@ func (m *MockPOIService) ValidatePOI(ctx context.Context, poiID string) (*models
 	return args.Get(0).(*models.POI), args.Error(1)
 }
 
+// MockWarningRateLimiter is a mock rate limiter that reports soft limit warnings
+type MockWarningRateLimiter struct {
+	MockRateLimiter
+}
+
+func (m *MockWarningRateLimiter) CheckRateLimitWithWarning(ctx context.Context, userID string, action services.ActionType) (*services.RateLimitWarning, error) {
+	args := m.Called(ctx, userID, action)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.RateLimitWarning), args.Error(1)
+}
+
+// MockPresenceChecker is a mock implementation of PresenceCheckerInterface
+type MockPresenceChecker struct {
+	mock.Mock
+}
+
+func (m *MockPresenceChecker) FilterPresentSessions(ctx context.Context, sessionIDs []string) ([]string, error) {
+	args := m.Called(ctx, sessionIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
 // WebSocketHandlerTestSuite contains the test suite for WebSocket handler
 type WebSocketHandlerTestSuite struct {
 	suite.Suite
@@ -196,13 +227,15 @@ func (suite *WebSocketHandlerTestSuite) SetupTest() {
 	suite.mockSessionService = new(MockSessionService)
 	suite.mockRateLimiter = new(MockRateLimiter)
 	suite.mockPOIService = new(MockPOIService)
+	// Presence payloads resolve the POI each user is in
+	suite.mockPOIService.On("GetCurrentPOI", mock.Anything, mock.Anything).Return("", nil).Maybe()
 	
 	// Create handler with mock services
 	suite.handler = NewHandler(suite.mockSessionService, suite.mockRateLimiter, nil, suite.mockPOIService)
 	
 	// Setup test server
 	router := gin.New()
-	router.GET("/ws", suite.handler.HandleWebSocket)
+	suite.handler.RegisterRoutes(router, nil)
 	
 	suite.server = httptest.NewServer(router)
 	suite.wsURL = "ws" + strings.TrimPrefix(suite.server.URL, "http") + "/ws"
@@ -226,8 +259,7 @@ func (suite *WebSocketHandlerTestSuite) TestWebSocketConnection_Success() {
 	suite.mockSessionService.On("GetSession", mock.Anything, "session-123").Return(session, nil)
 	
 	// Connect to WebSocket
-	header := http.Header{}
-	header.Set("Authorization", "Bearer session-123")
+	header := sessionHeader(suite.handler, "session-123")
 	
 	conn, _, err := ws.DefaultDialer.Dial(suite.wsURL, header)
 	suite.NoError(err)
@@ -246,8 +278,7 @@ func (suite *WebSocketHandlerTestSuite) TestWebSocketConnection_InvalidSession()
 	suite.mockSessionService.On("GetSession", mock.Anything, "invalid-session").Return((*models.Session)(nil), assert.AnError)
 	
 	// Try to connect with invalid session
-	header := http.Header{}
-	header.Set("Authorization", "Bearer invalid-session")
+	header := sessionHeader(suite.handler, "invalid-session")
 	
 	conn, resp, err := ws.DefaultDialer.Dial(suite.wsURL, header)
 	if conn != nil {
@@ -266,9 +297,70 @@ func (suite *WebSocketHandlerTestSuite) TestWebSocketConnection_MissingAuth() {
 		conn.Close()
 	}
 	
-	// Should fail with bad request (missing auth header)
+	// Should fail with unauthorized (missing session)
 	suite.Error(err)
-	suite.Equal(http.StatusBadRequest, resp.StatusCode)
+	suite.Equal(http.StatusUnauthorized, resp.StatusCode)
+}
+
+func (suite *WebSocketHandlerTestSuite) TestWebSocketConnection_ClientMetadata() {
+	session := &models.Session{
+		ID:     "session-123",
+		UserID: "user-456",
+		MapID:  "map-789",
+		IsActive: true,
+	}
+	suite.mockSessionService.On("GetSession", mock.Anything, "session-123").Return(session, nil)
+	suite.handler.SetMinClientVersion("1.2.0")
+	
+	header := sessionHeader(suite.handler, "session-123")
+	header.Set("User-Agent", "Mozilla/5.0 (X11; Linux x86_64)")
+	
+	conn, _, err := ws.DefaultDialer.Dial(suite.wsURL+"?clientVersion=1.4.0", header)
+	suite.NoError(err)
+	defer conn.Close()
+	
+	var msg Message
+	suite.NoError(conn.ReadJSON(&msg))
+	suite.Equal("welcome", msg.Type)
+	
+	// Version and user agent are listed for admins
+	suite.Eventually(func() bool {
+		return len(suite.handler.Connections("map-789")) == 1
+	}, time.Second, 10*time.Millisecond)
+	connection := suite.handler.Connections("map-789")[0]
+	suite.Equal("session-123", connection.SessionID)
+	suite.Equal("1.4.0", connection.ClientVersion)
+	suite.Equal("Mozilla/5.0 (X11; Linux x86_64)", connection.UserAgent)
+	suite.False(connection.ConnectedAt.IsZero())
+	suite.Empty(suite.handler.Connections("other-map"))
+}
+
+func (suite *WebSocketHandlerTestSuite) TestWebSocketConnection_UpgradeRequired() {
+	session := &models.Session{
+		ID:     "session-123",
+		UserID: "user-456",
+		MapID:  "map-789",
+		IsActive: true,
+	}
+	suite.mockSessionService.On("GetSession", mock.Anything, "session-123").Return(session, nil)
+	suite.handler.SetMinClientVersion("1.2.0")
+	
+	header := sessionHeader(suite.handler, "session-123")
+	header.Set("X-Client-Version", "1.1.9")
+	
+	conn, _, err := ws.DefaultDialer.Dial(suite.wsURL, header)
+	suite.NoError(err)
+	defer conn.Close()
+	
+	// Outdated clients are told to upgrade instead of being welcomed
+	var msg Message
+	suite.NoError(conn.ReadJSON(&msg))
+	suite.Equal("upgrade_required", msg.Type)
+	suite.Equal("1.2.0", msg.Data.(map[string]interface{})["minClientVersion"])
+	
+	_, _, err = conn.ReadMessage()
+	suite.True(ws.IsCloseError(err, UpgradeRequiredCloseCode))
+	suite.Empty(suite.handler.Connections(""))
 }
 
 func (suite *WebSocketHandlerTestSuite) TestAvatarMovement_Success() {
@@ -284,8 +376,7 @@ func (suite *WebSocketHandlerTestSuite) TestAvatarMovement_Success() {
 	suite.mockSessionService.On("UpdateAvatarPosition", mock.Anything, "session-123", mock.AnythingOfType("models.LatLng")).Return(nil)
 	
 	// Connect
-	header := http.Header{}
-	header.Set("Authorization", "Bearer session-123")
+	header := sessionHeader(suite.handler, "session-123")
 	conn, _, err := ws.DefaultDialer.Dial(suite.wsURL, header)
 	suite.NoError(err)
 	defer conn.Close()
@@ -321,6 +412,239 @@ func (suite *WebSocketHandlerTestSuite) TestAvatarMovement_Success() {
 	suite.Equal("avatar_move_ack", ackMsg.Type)
 }
 
+func (suite *WebSocketHandlerTestSuite) TestAvatarMovement_DeadZone() {
+	session := &models.Session{
+		ID:        "session-123",
+		UserID:    "user-456",
+		MapID:     "map-789",
+		AvatarPos: models.LatLng{Lat: 40.7128, Lng: -74.0060},
+		IsActive:  true,
+	}
+	suite.mockSessionService.On("GetSession", mock.Anything, "session-123").Return(session, nil)
+	suite.mockRateLimiter.On("CheckRateLimit", mock.Anything, "user-456", services.ActionUpdateAvatar).Return(nil)
+	// Only the move outside the dead zone is stored
+	suite.mockSessionService.On("UpdateAvatarPosition", mock.Anything, "session-123", models.LatLng{Lat: 40.7138, Lng: -74.0060}).Return(nil).Once()
+	
+	header := sessionHeader(suite.handler, "session-123")
+	conn, _, err := ws.DefaultDialer.Dial(suite.wsURL, header)
+	suite.NoError(err)
+	defer conn.Close()
+	
+	var welcomeMsg, initialUsersMsg Message
+	conn.ReadJSON(&welcomeMsg)
+	conn.ReadJSON(&initialUsersMsg)
+	suite.Equal("initial_users", initialUsersMsg.Type)
+	
+	move := func(lat, lng float64) map[string]interface{} {
+		suite.NoError(conn.WriteJSON(Message{
+			Type: "avatar_move",
+			Data: map[string]interface{}{
+				"position": map[string]float64{"lat": lat, "lng": lng},
+			},
+		}))
+		
+		var ackMsg Message
+		suite.NoError(conn.ReadJSON(&ackMsg))
+		suite.Equal("avatar_move_ack", ackMsg.Type)
+		return ackMsg.Data.(map[string]interface{})
+	}
+	
+	// About 0.1m from the stored position
+	ack := move(40.7128009, -74.0060)
+	suite.Equal(true, ack["skipped"])
+	suite.Equal(40.7128, ack["position"].(map[string]interface{})["lat"])
+	
+	// About 110m away
+	ack = move(40.7138, -74.0060)
+	suite.Nil(ack["skipped"])
+	
+	// Jitter is measured from the newly stored position
+	ack = move(40.7138001, -74.0060001)
+	suite.Equal(true, ack["skipped"])
+}
+
+type stubPersonalSpaceProvider struct {
+	personalSpace models.PersonalSpace
+}
+
+func (p *stubPersonalSpaceProvider) GetPersonalSpace(ctx context.Context, mapID string) (models.PersonalSpace, error) {
+	return p.personalSpace, nil
+}
+
+func (suite *WebSocketHandlerTestSuite) TestAvatarMovement_PersonalSpace() {
+	provider := &stubPersonalSpaceProvider{personalSpace: models.PersonalSpace{Mode: models.PersonalSpaceAdjust, RadiusMeters: 10}}
+	suite.handler.SetPersonalSpaceProvider(provider)
+	
+	// Another avatar stands about 110m north of the stored position
+	other := models.LatLng{Lat: 40.7138, Lng: -74.0060}
+	suite.handler.avatars.Set("map-789", "session-other", other)
+	
+	session := &models.Session{
+		ID:        "session-123",
+		UserID:    "user-456",
+		MapID:     "map-789",
+		AvatarPos: models.LatLng{Lat: 40.7128, Lng: -74.0060},
+		IsActive:  true,
+	}
+	suite.mockSessionService.On("GetSession", mock.Anything, "session-123").Return(session, nil)
+	suite.mockRateLimiter.On("CheckRateLimit", mock.Anything, "user-456", services.ActionUpdateAvatar).Return(nil)
+	suite.mockSessionService.On("UpdateAvatarPosition", mock.Anything, "session-123", mock.Anything).Return(nil).Once()
+	
+	header := sessionHeader(suite.handler, "session-123")
+	conn, _, err := ws.DefaultDialer.Dial(suite.wsURL, header)
+	suite.NoError(err)
+	defer conn.Close()
+	
+	var welcomeMsg, initialUsersMsg Message
+	conn.ReadJSON(&welcomeMsg)
+	conn.ReadJSON(&initialUsersMsg)
+	
+	move := func(lat, lng float64) map[string]interface{} {
+		suite.NoError(conn.WriteJSON(Message{
+			Type: "avatar_move",
+			Data: map[string]interface{}{
+				"position": map[string]float64{"lat": lat, "lng": lng},
+			},
+		}))
+		
+		var ackMsg Message
+		suite.NoError(conn.ReadJSON(&ackMsg))
+		suite.Equal("avatar_move_ack", ackMsg.Type)
+		return ackMsg.Data.(map[string]interface{})
+	}
+	
+	// Moving onto the other avatar is corrected to the edge of its personal space
+	ack := move(other.Lat, other.Lng)
+	suite.Equal(true, ack["adjusted"])
+	position := ack["position"].(map[string]interface{})
+	adjusted := models.LatLng{Lat: position["lat"].(float64), Lng: position["lng"].(float64)}
+	suite.InDelta(10.1, adjusted.DistanceTo(other)*1000, 0.2)
+	suite.Less(adjusted.Lat, other.Lat, "pushed back toward where the avatar came from")
+	
+	// In reject mode the avatar stays where it is
+	provider.personalSpace.Mode = models.PersonalSpaceReject
+	ack = move(other.Lat, other.Lng)
+	suite.Equal(true, ack["rejected"])
+	suite.Equal("personal_space", ack["reason"])
+	position = ack["position"].(map[string]interface{})
+	suite.Equal(adjusted.Lat, position["lat"])
+}
+
+type stubFeatureRollout struct {
+	features map[string][]models.Feature
+}
+
+func (r *stubFeatureRollout) EnabledFeatures(ctx context.Context, userID string) []models.Feature {
+	return r.features[userID]
+}
+
+func (suite *WebSocketHandlerTestSuite) TestAvatarMovement_Batch() {
+	suite.handler.SetFeatureRollout(&stubFeatureRollout{features: map[string][]models.Feature{
+		"user-456": {models.FeatureBatchedMovement},
+	}})
+	
+	session := &models.Session{
+		ID:     "session-123",
+		UserID: "user-456",
+		MapID:  "map-789",
+		IsActive: true,
+	}
+	suite.mockSessionService.On("GetSession", mock.Anything, "session-123").Return(session, nil)
+	suite.mockRateLimiter.On("CheckRateLimit", mock.Anything, "user-456", services.ActionUpdateAvatar).Return(nil)
+	suite.mockSessionService.On("UpdateAvatarPosition", mock.Anything, "session-123", models.LatLng{Lat: 40.7130, Lng: -74.0062}).Return(nil).Once()
+	
+	header := sessionHeader(suite.handler, "session-123")
+	conn, _, err := ws.DefaultDialer.Dial(suite.wsURL, header)
+	suite.NoError(err)
+	defer conn.Close()
+	
+	// The welcome message tells the client which rolled out features it may use
+	var welcomeMsg, initialUsersMsg Message
+	conn.ReadJSON(&welcomeMsg)
+	conn.ReadJSON(&initialUsersMsg)
+	suite.Equal([]interface{}{"batched_movement"}, welcomeMsg.Data.(map[string]interface{})["features"])
+	
+	// Only the latest position of a batch is stored
+	err = conn.WriteJSON(Message{
+		Type: "avatar_move_batch",
+		Data: map[string]interface{}{
+			"positions": []map[string]float64{
+				{"lat": 40.7128, "lng": -74.0060},
+				{"lat": 40.7130, "lng": -74.0062},
+			},
+		},
+	})
+	suite.NoError(err)
+	
+	var ackMsg Message
+	suite.NoError(conn.ReadJSON(&ackMsg))
+	suite.Equal("avatar_move_ack", ackMsg.Type)
+	suite.mockSessionService.AssertExpectations(suite.T())
+}
+
+func (suite *WebSocketHandlerTestSuite) TestAvatarMovement_RateLimitWarning() {
+	rateLimiter := new(MockWarningRateLimiter)
+	suite.handler.rateLimiter = rateLimiter
+	
+	session := &models.Session{
+		ID:     "session-123",
+		UserID: "user-456",
+		MapID:  "map-789",
+		IsActive: true,
+	}
+	warning := &services.RateLimitWarning{
+		UserID:    "user-456",
+		Action:    services.ActionUpdateAvatar,
+		Limit:     60,
+		Remaining: 12,
+		ResetTime: time.Now().Add(30 * time.Second),
+	}
+	suite.mockSessionService.On("GetSession", mock.Anything, "session-123").Return(session, nil)
+	rateLimiter.On("CheckRateLimitWithWarning", mock.Anything, "user-456", services.ActionUpdateAvatar).Return(warning, nil)
+	suite.mockSessionService.On("UpdateAvatarPosition", mock.Anything, "session-123", mock.AnythingOfType("models.LatLng")).Return(nil)
+	
+	header := sessionHeader(suite.handler, "session-123")
+	conn, _, err := ws.DefaultDialer.Dial(suite.wsURL, header)
+	suite.NoError(err)
+	defer conn.Close()
+	
+	var welcomeMsg, initialUsersMsg Message
+	conn.ReadJSON(&welcomeMsg)
+	conn.ReadJSON(&initialUsersMsg)
+	suite.Equal("initial_users", initialUsersMsg.Type)
+	
+	moveMsg := Message{
+		Type: "avatar_move",
+		Data: map[string]interface{}{
+			"position": map[string]float64{
+				"lat": 40.7128,
+				"lng": -74.0060,
+			},
+		},
+	}
+	
+	// First move near the limit is warned about, but still processed
+	suite.NoError(conn.WriteJSON(moveMsg))
+	
+	var warningMsg Message
+	suite.NoError(conn.ReadJSON(&warningMsg))
+	suite.Equal("rate_limit_warning", warningMsg.Type)
+	data := warningMsg.Data.(map[string]interface{})
+	suite.Equal("update_avatar", data["action"])
+	suite.Equal(float64(12), data["remaining"])
+	
+	var ackMsg Message
+	suite.NoError(conn.ReadJSON(&ackMsg))
+	suite.Equal("avatar_move_ack", ackMsg.Type)
+	
+	// The warning is not repeated within the same window
+	suite.NoError(conn.WriteJSON(moveMsg))
+	suite.NoError(conn.ReadJSON(&ackMsg))
+	suite.Equal("avatar_move_ack", ackMsg.Type)
+	
+	rateLimiter.AssertExpectations(suite.T())
+}
+
 func (suite *WebSocketHandlerTestSuite) TestAvatarMovement_RateLimited() {
 	// Setup connection
 	session := &models.Session{
@@ -342,8 +666,7 @@ func (suite *WebSocketHandlerTestSuite) TestAvatarMovement_RateLimited() {
 	suite.mockRateLimiter.On("CheckRateLimit", mock.Anything, "user-456", services.ActionUpdateAvatar).Return(rateLimitErr)
 	
 	// Connect
-	header := http.Header{}
-	header.Set("Authorization", "Bearer session-123")
+	header := sessionHeader(suite.handler, "session-123")
 	conn, _, err := ws.DefaultDialer.Dial(suite.wsURL, header)
 	suite.NoError(err)
 	defer conn.Close()
@@ -377,7 +700,12 @@ func (suite *WebSocketHandlerTestSuite) TestAvatarMovement_RateLimited() {
 	err = conn.ReadJSON(&errorMsg)
 	suite.NoError(err)
 	suite.Equal("error", errorMsg.Type)
-	suite.Contains(errorMsg.Data.(map[string]interface{})["message"], "rate limit")
+	errorData := errorMsg.Data.(map[string]interface{})
+	suite.Contains(errorData["message"], "rate limit")
+	suite.Equal("RATE_LIMIT_EXCEEDED", errorData["code"])
+	suite.Equal("update_avatar", errorData["bucket"])
+	suite.Equal("avatar_move", errorData["messageType"])
+	suite.Equal(float64(60), errorData["retryAfter"])
 }
 
 func (suite *WebSocketHandlerTestSuite) TestHeartbeat() {
@@ -392,8 +720,7 @@ func (suite *WebSocketHandlerTestSuite) TestHeartbeat() {
 	suite.mockSessionService.On("SessionHeartbeat", mock.Anything, "session-123").Return(nil)
 	
 	// Connect
-	header := http.Header{}
-	header.Set("Authorization", "Bearer session-123")
+	header := sessionHeader(suite.handler, "session-123")
 	conn, _, err := ws.DefaultDialer.Dial(suite.wsURL, header)
 	suite.NoError(err)
 	defer conn.Close()
@@ -435,8 +762,7 @@ func (suite *WebSocketHandlerTestSuite) TestConnectionCleanup() {
 	suite.mockSessionService.On("GetSession", mock.Anything, "session-123").Return(session, nil)
 	
 	// Connect
-	header := http.Header{}
-	header.Set("Authorization", "Bearer session-123")
+	header := sessionHeader(suite.handler, "session-123")
 	conn, _, err := ws.DefaultDialer.Dial(suite.wsURL, header)
 	suite.NoError(err)
 	
@@ -457,6 +783,55 @@ func (suite *WebSocketHandlerTestSuite) TestConnectionCleanup() {
 	suite.False(suite.handler.manager.IsClientConnected("session-123"))
 }
 
+func (suite *WebSocketHandlerTestSuite) TestPresence_IncludesCurrentPOI() {
+	session1 := &models.Session{ID: "session-1", UserID: "user-1", MapID: "map-789", IsActive: true}
+	session2 := &models.Session{ID: "session-2", UserID: "user-2", MapID: "map-789", IsActive: true}
+	suite.mockSessionService.On("GetSession", mock.Anything, "session-1").Return(session1, nil)
+	suite.mockSessionService.On("GetSession", mock.Anything, "session-2").Return(session2, nil)
+	suite.mockPOIService.ExpectedCalls = nil
+	suite.mockPOIService.On("GetCurrentPOI", mock.Anything, "user-1").Return("poi-1", nil).Maybe()
+	suite.mockPOIService.On("GetCurrentPOI", mock.Anything, "user-2").Return("", nil).Maybe()
+	
+	header1 := sessionHeader(suite.handler, "session-1")
+	conn1, _, err := ws.DefaultDialer.Dial(suite.wsURL, header1)
+	suite.Require().NoError(err)
+	defer conn1.Close()
+	
+	var welcomeMsg, initialUsersMsg Message
+	conn1.ReadJSON(&welcomeMsg)
+	conn1.ReadJSON(&initialUsersMsg)
+	suite.Equal("initial_users", initialUsersMsg.Type)
+	
+	header2 := sessionHeader(suite.handler, "session-2")
+	conn2, _, err := ws.DefaultDialer.Dial(suite.wsURL, header2)
+	suite.Require().NoError(err)
+	defer conn2.Close()
+	
+	// The newcomer sees that user-1 is in a POI
+	conn2.ReadJSON(&welcomeMsg)
+	conn2.ReadJSON(&initialUsersMsg)
+	suite.Equal("initial_users", initialUsersMsg.Type)
+	users := initialUsersMsg.Data.(map[string]interface{})["users"].([]interface{})
+	var found bool
+	for _, user := range users {
+		userData := user.(map[string]interface{})
+		if userData["userId"] == "user-1" {
+			found = true
+			suite.Equal("poi-1", userData["currentPoiId"])
+		}
+	}
+	suite.True(found)
+	
+	// And user-1 learns that user-2 isn't in one
+	var userJoinedMsg Message
+	conn1.ReadJSON(&userJoinedMsg)
+	suite.Equal("user_joined", userJoinedMsg.Type)
+	joined := userJoinedMsg.Data.(map[string]interface{})
+	suite.Equal("user-2", joined["userId"])
+	suite.Contains(joined, "currentPoiId")
+	suite.Nil(joined["currentPoiId"])
+}
+
 func (suite *WebSocketHandlerTestSuite) TestBroadcastToMap() {
 	// Setup two connections for the same map
 	session1 := &models.Session{
@@ -476,15 +851,13 @@ func (suite *WebSocketHandlerTestSuite) TestBroadcastToMap() {
 	suite.mockSessionService.On("GetSession", mock.Anything, "session-2").Return(session2, nil)
 	
 	// Connect first client
-	header1 := http.Header{}
-	header1.Set("Authorization", "Bearer session-1")
+	header1 := sessionHeader(suite.handler, "session-1")
 	conn1, _, err := ws.DefaultDialer.Dial(suite.wsURL, header1)
 	suite.NoError(err)
 	defer conn1.Close()
 	
 	// Connect second client
-	header2 := http.Header{}
-	header2.Set("Authorization", "Bearer session-2")
+	header2 := sessionHeader(suite.handler, "session-2")
 	conn2, _, err := ws.DefaultDialer.Dial(suite.wsURL, header2)
 	suite.NoError(err)
 	defer conn2.Close()
@@ -530,6 +903,55 @@ func (suite *WebSocketHandlerTestSuite) TestBroadcastToMap() {
 	suite.Equal("test_broadcast", msg2.Type)
 }
 
+func (suite *WebSocketHandlerTestSuite) TestInitialUsers_SkipsStalePresence() {
+	presence := new(MockPresenceChecker)
+	suite.handler.SetPresenceChecker(presence)
+	
+	staleSession := &models.Session{
+		ID:     "session-stale",
+		UserID: "user-stale",
+		MapID:  "map-789",
+		IsActive: true,
+	}
+	session := &models.Session{
+		ID:     "session-live",
+		UserID: "user-live",
+		MapID:  "map-789",
+		IsActive: true,
+	}
+	
+	suite.mockSessionService.On("GetSession", mock.Anything, "session-stale").Return(staleSession, nil)
+	suite.mockSessionService.On("GetSession", mock.Anything, "session-live").Return(session, nil)
+	// The stale session is still connected but stopped sending heartbeats
+	presence.On("FilterPresentSessions", mock.Anything, mock.Anything).Return([]string{"session-live"}, nil)
+	
+	header1 := sessionHeader(suite.handler, "session-stale")
+	conn1, _, err := ws.DefaultDialer.Dial(suite.wsURL, header1)
+	suite.NoError(err)
+	defer conn1.Close()
+	
+	var welcomeMsg1, initialUsersMsg1 Message
+	conn1.ReadJSON(&welcomeMsg1)
+	conn1.ReadJSON(&initialUsersMsg1)
+	suite.Equal("initial_users", initialUsersMsg1.Type)
+	
+	header2 := sessionHeader(suite.handler, "session-live")
+	conn2, _, err := ws.DefaultDialer.Dial(suite.wsURL, header2)
+	suite.NoError(err)
+	defer conn2.Close()
+	
+	var welcomeMsg2, initialUsersMsg2 Message
+	conn2.ReadJSON(&welcomeMsg2)
+	suite.Equal("welcome", welcomeMsg2.Type)
+	conn2.ReadJSON(&initialUsersMsg2)
+	suite.Equal("initial_users", initialUsersMsg2.Type)
+	
+	// The stale session should not be reported to the new client
+	users := initialUsersMsg2.Data.(map[string]interface{})["users"]
+	suite.Nil(users)
+	presence.AssertExpectations(suite.T())
+}
+
 func (suite *WebSocketHandlerTestSuite) TestPOIJoin() {
 	// Setup connection
 	session := &models.Session{
@@ -539,19 +961,14 @@ func (suite *WebSocketHandlerTestSuite) TestPOIJoin() {
 		IsActive: true,
 	}
 	suite.mockSessionService.On("GetSession", mock.Anything, "session-123").Return(session, nil)
-	suite.mockRateLimiter.On("CheckRateLimit", mock.Anything, "user-456", services.ActionJoinPOI).Return(nil)
+	suite.mockRateLimiter.On("CheckRateLimit", mock.Anything, "user-456", services.ActionWSPOIJoin).Return(nil)
 	suite.mockPOIService.On("JoinPOI", mock.Anything, "poi-123", "user-456").Return(nil)
 	
-	// Mock the new methods for participant information
-	participantsInfo := []services.POIParticipantInfo{
-		{ID: "user-456", Name: "Test User", AvatarURL: ""},
-	}
-	suite.mockPOIService.On("GetPOIParticipantsWithInfo", mock.Anything, "poi-123").Return(participantsInfo, nil)
+	// Joins only broadcast the participant count, not the roster
 	suite.mockPOIService.On("GetPOIParticipantCount", mock.Anything, "poi-123").Return(1, nil)
 	
 	// Connect to WebSocket
-	header := http.Header{}
-	header.Set("Authorization", "Bearer session-123")
+	header := sessionHeader(suite.handler, "session-123")
 	conn, _, err := ws.DefaultDialer.Dial(suite.wsURL, header)
 	suite.NoError(err)
 	defer conn.Close()
@@ -600,12 +1017,12 @@ func (suite *WebSocketHandlerTestSuite) TestPOILeave() {
 		IsActive: true,
 	}
 	suite.mockSessionService.On("GetSession", mock.Anything, "session-123").Return(session, nil)
-	suite.mockRateLimiter.On("CheckRateLimit", mock.Anything, "user-456", services.ActionLeavePOI).Return(nil)
+	suite.mockRateLimiter.On("CheckRateLimit", mock.Anything, "user-456", services.ActionWSPOILeave).Return(nil)
 	suite.mockPOIService.On("LeavePOI", mock.Anything, "poi-123", "user-456").Return(nil)
+	suite.mockPOIService.On("GetPOIParticipantCount", mock.Anything, "poi-123").Return(0, nil)
 	
 	// Connect to WebSocket
-	header := http.Header{}
-	header.Set("Authorization", "Bearer session-123")
+	header := sessionHeader(suite.handler, "session-123")
 	conn, _, err := ws.DefaultDialer.Dial(suite.wsURL, header)
 	suite.NoError(err)
 	defer conn.Close()
@@ -662,26 +1079,20 @@ func (suite *WebSocketHandlerTestSuite) TestPOIEventBroadcasting() {
 	
 	suite.mockSessionService.On("GetSession", mock.Anything, "session-1").Return(session1, nil)
 	suite.mockSessionService.On("GetSession", mock.Anything, "session-2").Return(session2, nil)
-	suite.mockRateLimiter.On("CheckRateLimit", mock.Anything, "user-1", services.ActionJoinPOI).Return(nil)
+	suite.mockRateLimiter.On("CheckRateLimit", mock.Anything, "user-1", services.ActionWSPOIJoin).Return(nil)
 	suite.mockPOIService.On("JoinPOI", mock.Anything, "poi-123", "user-1").Return(nil)
 	
-	// Mock the new methods for participant information
-	participantsInfo := []services.POIParticipantInfo{
-		{ID: "user-1", Name: "Test User", AvatarURL: ""},
-	}
-	suite.mockPOIService.On("GetPOIParticipantsWithInfo", mock.Anything, "poi-123").Return(participantsInfo, nil)
+	// Joins only broadcast the participant count, not the roster
 	suite.mockPOIService.On("GetPOIParticipantCount", mock.Anything, "poi-123").Return(1, nil)
 	
 	// Connect first client
-	header1 := http.Header{}
-	header1.Set("Authorization", "Bearer session-1")
+	header1 := sessionHeader(suite.handler, "session-1")
 	conn1, _, err := ws.DefaultDialer.Dial(suite.wsURL, header1)
 	suite.NoError(err)
 	defer conn1.Close()
 	
 	// Connect second client
-	header2 := http.Header{}
-	header2.Set("Authorization", "Bearer session-2")
+	header2 := sessionHeader(suite.handler, "session-2")
 	conn2, _, err := ws.DefaultDialer.Dial(suite.wsURL, header2)
 	suite.NoError(err)
 	defer conn2.Close()
@@ -747,8 +1158,7 @@ func (suite *WebSocketHandlerTestSuite) TestInvalidMessageFormat() {
 	suite.mockSessionService.On("GetSession", mock.Anything, "session-123").Return(session, nil)
 	
 	// Connect
-	header := http.Header{}
-	header.Set("Authorization", "Bearer session-123")
+	header := sessionHeader(suite.handler, "session-123")
 	conn, _, err := ws.DefaultDialer.Dial(suite.wsURL, header)
 	suite.NoError(err)
 	defer conn.Close()
@@ -786,55 +1196,6 @@ func TestWebSocketHandlerTestSuite(t *testing.T) {
 	suite.Run(t, new(WebSocketHandlerTestSuite))
 }
 
-// Test helper functions
-func TestExtractSessionID(t *testing.T) {
-	tests := []struct {
-		name        string
-		header      string
-		expectedID  string
-		expectError bool
-	}{
-		{
-			name:        "Valid Bearer token",
-			header:      "Bearer session-123",
-			expectedID:  "session-123",
-			expectError: false,
-		},
-		{
-			name:        "Missing Bearer prefix",
-			header:      "session-123",
-			expectedID:  "",
-			expectError: true,
-		},
-		{
-			name:        "Empty header",
-			header:      "",
-			expectedID:  "",
-			expectError: true,
-		},
-		{
-			name:        "Only Bearer",
-			header:      "Bearer",
-			expectedID:  "",
-			expectError: true,
-		},
-	}
-	
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			sessionID, err := extractSessionID(tt.header)
-			
-			if tt.expectError {
-				assert.Error(t, err)
-				assert.Empty(t, sessionID)
-			} else {
-				assert.NoError(t, err)
-				assert.Equal(t, tt.expectedID, sessionID)
-			}
-		})
-	}
-}
-
 func TestValidateMessage(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -901,4 +1262,134 @@ func TestValidateMessage(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestHandler_UserProfileUpdated_BroadcastsToMap(t *testing.T) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, new(MockPOIService))
+
+	neighbour := &Client{SessionID: "session-1", UserID: "user-2", MapID: "map-1", Send: make(chan Message, 10)}
+	elsewhere := &Client{SessionID: "session-2", UserID: "user-3", MapID: "map-2", Send: make(chan Message, 10)}
+	handler.manager.registerClient(neighbour)
+	handler.manager.registerClient(elsewhere)
+
+	handler.handlePubSubEvent("user_profile_updated", map[string]interface{}{
+		"userId":      "user-1",
+		"mapId":       "map-1",
+		"displayName": "Alice",
+		"avatarURL":   (*string)(nil),
+	})
+
+	select {
+	case msg := <-neighbour.Send:
+		assert.Equal(t, "user_profile_updated", msg.Type)
+		data, ok := msg.Data.(map[string]interface{})
+		assert.True(t, ok)
+		assert.Equal(t, "user-1", data["userId"])
+		assert.Nil(t, data["avatarURL"])
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Expected profile update not received")
+	}
+
+	select {
+	case msg := <-elsewhere.Send:
+		t.Fatalf("Unexpected message on another map: %s", msg.Type)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestHandler_RoleChanged_UpdatesFacilitatorAlerts(t *testing.T) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, new(MockPOIService))
+
+	promoted := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 10)}
+	restricted := &Client{SessionID: "session-2", UserID: "user-2", MapID: "map-1", Send: make(chan Message, 10)}
+	handler.manager.registerClient(promoted)
+	handler.manager.registerClient(restricted)
+
+	handler.handlePubSubEvent("role_changed", map[string]interface{}{
+		"userId": "user-1",
+		"mapId":  "map-1",
+		"role":   "admin",
+	})
+
+	// Everyone on the map learns about the new role
+	for _, client := range []*Client{promoted, restricted} {
+		select {
+		case msg := <-client.Send:
+			assert.Equal(t, "role_changed", msg.Type)
+		case <-time.After(100 * time.Millisecond):
+			t.Fatalf("Expected role change for %s", client.SessionID)
+		}
+	}
+
+	handler.NotifyRestriction(&services.Restriction{UserID: "user-2", MapID: "map-1", Type: services.RestrictionBlockCalls})
+
+	select {
+	case msg := <-promoted.Send:
+		assert.Equal(t, "moderation_alert", msg.Type)
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Expected promoted user to receive moderation alert")
+	}
+
+	// Demotion stops the alerts without a reconnect
+	handler.handlePubSubEvent("role_changed", map[string]interface{}{
+		"userId": "user-1",
+		"mapId":  "map-1",
+		"role":   "user",
+	})
+	<-promoted.Send
+	handler.NotifyRestriction(&services.Restriction{UserID: "user-2", MapID: "map-1", Type: services.RestrictionBlockCalls})
+
+	select {
+	case msg := <-promoted.Send:
+		t.Fatalf("Unexpected message after demotion: %s", msg.Type)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestHandler_MessageRateLimits_PerFeatureBuckets(t *testing.T) {
+	mockSessionService := new(MockSessionService)
+	mockRateLimiter := new(MockRateLimiter)
+	mockPOIService := new(MockPOIService)
+	handler := NewHandler(mockSessionService, mockRateLimiter, nil, mockPOIService)
+
+	client := &Client{
+		SessionID: "session-1",
+		UserID:    "user-1",
+		MapID:     "map-1",
+		Send:      make(chan Message, 10),
+	}
+
+	// Signalling is exhausted, POI joins are not
+	mockRateLimiter.On("CheckRateLimit", mock.Anything, "user-1", services.ActionWSSignal).Return(&services.RateLimitError{
+		UserID:     "user-1",
+		Action:     services.ActionWSSignal,
+		Limit:      600,
+		Window:     time.Minute,
+		RetryAfter: 20 * time.Second,
+	})
+	mockRateLimiter.On("CheckRateLimit", mock.Anything, "user-1", services.ActionWSPOIJoin).Return(nil)
+	mockPOIService.On("JoinPOI", mock.Anything, "poi-1", "user-1").Return(nil)
+	mockPOIService.On("GetPOIParticipantCount", mock.Anything, "poi-1").Return(1, nil)
+	mockSessionService.On("GetSession", mock.Anything, "session-1").Return(nil, nil)
+
+	handler.handleMessage(client, Message{
+		Type: "ice_candidate",
+		Data: map[string]interface{}{"targetUserId": "user-2", "candidate": map[string]interface{}{}},
+	})
+
+	errorMsg := <-client.Send
+	assert.Equal(t, "error", errorMsg.Type)
+	data := errorMsg.Data.(map[string]interface{})
+	assert.Equal(t, "RATE_LIMIT_EXCEEDED", data["code"])
+	assert.Equal(t, "ws_signal", data["bucket"])
+	assert.Equal(t, "ice_candidate", data["messageType"])
+	assert.Equal(t, float64(20), data["retryAfter"])
+
+	handler.handleMessage(client, Message{
+		Type: "poi_join",
+		Data: map[string]interface{}{"poiId": "poi-1"},
+	})
+
+	ackMsg := <-client.Send
+	assert.Equal(t, "poi_join_ack", ackMsg.Type)
+	mockRateLimiter.AssertExpectations(t)
+}
@@ -0,0 +1,99 @@
+package websocket
+
+import (
+	"fmt"
+	"time"
+)
+
+// minHeartbeatReapInterval bounds how often stale connections are looked for
+const minHeartbeatReapInterval = time.Second
+
+// heartbeatReaper closes connections that stopped sending heartbeat messages
+type heartbeatReaper struct {
+	timeout time.Duration
+	stop    chan struct{}
+}
+
+// SetHeartbeatTimeout closes connections that sent no heartbeat message for
+// the timeout, even if the TCP connection still looks alive, like behind
+// proxies answering pings. Zero keeps connections without heartbeats open.
+func (h *Handler) SetHeartbeatTimeout(timeout time.Duration) {
+	h.manager.SetHeartbeatTimeout(timeout)
+}
+
+// SetHeartbeatTimeout starts reaping connections whose last heartbeat is
+// older than the timeout
+func (m *Manager) SetHeartbeatTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		return
+	}
+
+	reaper := &heartbeatReaper{timeout: timeout, stop: make(chan struct{})}
+	m.mutex.Lock()
+	m.reaper = reaper
+	m.mutex.Unlock()
+
+	go m.runHeartbeatReaper(reaper)
+}
+
+// runHeartbeatReaper looks for stale connections a few times per timeout, so
+// they are closed soon after their timeout passed
+func (m *Manager) runHeartbeatReaper(reaper *heartbeatReaper) {
+	interval := reaper.timeout / 4
+	if interval < minHeartbeatReapInterval {
+		interval = minHeartbeatReapInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-reaper.stop:
+			return
+		case now := <-ticker.C:
+			m.reapStaleClients(reaper.timeout, now)
+		}
+	}
+}
+
+// reapStaleClients disconnects the clients whose last heartbeat is older than
+// the timeout and returns how many were disconnected. Their write pump ends
+// the connection after the close frame, and the read pump then broadcasts
+// user_left as for any other disconnect.
+func (m *Manager) reapStaleClients(timeout time.Duration, now time.Time) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var stale []*Client
+	for _, client := range m.clients {
+		if now.Sub(client.lastHeartbeatAt()) > timeout {
+			stale = append(stale, client)
+		}
+	}
+	for _, client := range stale {
+		silence := now.Sub(client.lastHeartbeatAt()).Round(time.Second)
+		m.endClient(client, &closeCause{
+			code:     CloseCodeIdleTimeout,
+			reason:   CloseReasonHeartbeatTimeout,
+			err:      fmt.Errorf("no heartbeat for %s", silence),
+			sendable: true,
+		})
+		m.logger.Info("💀 Closing connection without heartbeat",
+			"sessionId", client.SessionID,
+			"userId", client.UserID,
+			"mapId", client.MapID,
+			"silence", silence)
+	}
+	return len(stale)
+}
+
+// recordHeartbeat notes that the client sent a heartbeat
+func (c *Client) recordHeartbeat(at time.Time) {
+	c.lastHeartbeat.Store(at.UnixNano())
+}
+
+// lastHeartbeatAt returns when the client last sent a heartbeat, or when it
+// was registered if it never sent one
+func (c *Client) lastHeartbeatAt() time.Time {
+	return time.Unix(0, c.lastHeartbeat.Load())
+}
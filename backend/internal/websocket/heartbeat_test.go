@@ -0,0 +1,63 @@
+package websocket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_ReapStaleClients(t *testing.T) {
+	manager := NewManager()
+	defer manager.Shutdown()
+	now := time.Now()
+
+	stale := &Client{SessionID: "session-stale", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 10)}
+	fresh := &Client{SessionID: "session-fresh", UserID: "user-2", MapID: "map-1", Send: make(chan Message, 10)}
+	manager.registerClient(stale)
+	manager.registerClient(fresh)
+	stale.recordHeartbeat(now.Add(-2 * time.Minute))
+	fresh.recordHeartbeat(now.Add(-30 * time.Second))
+
+	assert.Equal(t, 1, manager.reapStaleClients(90*time.Second, now))
+
+	assert.False(t, manager.IsClientConnected(stale.SessionID))
+	assert.True(t, manager.IsClientConnected(fresh.SessionID))
+	reason := <-stale.Send
+	assert.Equal(t, "disconnect_reason", reason.Type)
+	assert.Equal(t, map[string]interface{}{"code": CloseCodeIdleTimeout, "reason": CloseReasonHeartbeatTimeout, "retry": true}, reason.Data)
+	_, open := <-stale.Send
+	assert.False(t, open)
+	assert.Empty(t, fresh.Send)
+}
+
+func TestManager_ReapStaleClients_CountsFromRegistration(t *testing.T) {
+	manager := NewManager()
+	defer manager.Shutdown()
+
+	client := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 10)}
+	manager.registerClient(client)
+
+	// Clients that never sent a heartbeat get the timeout from connecting
+	assert.Zero(t, manager.reapStaleClients(time.Minute, time.Now()))
+	assert.Equal(t, 1, manager.reapStaleClients(time.Minute, time.Now().Add(2*time.Minute)))
+}
+
+func TestHandler_Heartbeat_RecordsHeartbeat(t *testing.T) {
+	sessionService := new(MockSessionService)
+	handler := NewHandler(sessionService, new(MockRateLimiter), nil, nil)
+	defer handler.manager.Shutdown()
+	sessionService.On("SessionHeartbeat", mock.Anything, "session-1").Return(nil)
+
+	client := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 10)}
+	handler.manager.registerClient(client)
+	client.recordHeartbeat(time.Now().Add(-time.Hour))
+
+	handler.handleHeartbeat(context.Background(), client, Message{Type: "heartbeat"})
+
+	require.Equal(t, "pong", (<-client.Send).Type)
+	assert.WithinDuration(t, time.Now(), client.lastHeartbeatAt(), time.Second)
+}
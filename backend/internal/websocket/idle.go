@@ -0,0 +1,187 @@
+package websocket
+
+import (
+	"context"
+	"time"
+)
+
+// Statuses of users broadcast with user_status and included in initial_users
+const (
+	UserStatusActive  = "active"
+	UserStatusAway    = "away"
+	UserStatusOffline = "offline"
+)
+
+// minIdleCheckInterval bounds how often idle users are looked for
+const minIdleCheckInterval = time.Second
+
+// activityMessageTypes count as user activity besides activity_ping, unlike
+// heartbeats, which clients send on their own
+var activityMessageTypes = map[string]bool{
+	"avatar_move":       true,
+	"avatar_move_batch": true,
+	"poi_join":          true,
+	"poi_leave":         true,
+	"reaction":          true,
+	"hand_raise":        true,
+}
+
+// idleDetector marks users away once none of their connections on a map
+// showed activity for the timeout
+type idleDetector struct {
+	timeout time.Duration
+	stop    chan struct{}
+}
+
+// SetIdleTimeout shows users as away after they showed no activity for the
+// timeout, and broadcasts user_status when they become away, active or go
+// offline. Zero disables idle tracking.
+func (h *Handler) SetIdleTimeout(timeout time.Duration) {
+	h.manager.SetIdleTimeout(timeout)
+}
+
+// SetIdleTimeout starts looking for users whose last activity is older than
+// the timeout
+func (m *Manager) SetIdleTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		return
+	}
+
+	detector := &idleDetector{timeout: timeout, stop: make(chan struct{})}
+	m.mutex.Lock()
+	m.idle = detector
+	m.mutex.Unlock()
+
+	go m.runIdleDetector(detector)
+}
+
+// runIdleDetector looks for idle users a few times per timeout, so they are
+// shown as away soon after their timeout passed
+func (m *Manager) runIdleDetector(detector *idleDetector) {
+	interval := detector.timeout / 4
+	if interval < minIdleCheckInterval {
+		interval = minIdleCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-detector.stop:
+			return
+		case now := <-ticker.C:
+			m.markIdleUsersAway(detector.timeout, now)
+		}
+	}
+}
+
+// markIdleUsersAway marks the users whose connections on a map all showed no
+// activity for the timeout as away, tells the map, and returns how many
+// users became away
+func (m *Manager) markIdleUsersAway(timeout time.Duration, now time.Time) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	marked := 0
+	for mapID, clients := range m.mapClients {
+		active := make(map[string]bool)
+		for _, client := range clients {
+			if now.Sub(client.lastActivityAt()) <= timeout {
+				active[client.UserID] = true
+			} else if !active[client.UserID] {
+				active[client.UserID] = false
+			}
+		}
+
+		for userID, isActive := range active {
+			if isActive || m.away[mapID][userID] {
+				continue
+			}
+			if m.away[mapID] == nil {
+				m.away[mapID] = make(map[string]bool)
+			}
+			m.away[mapID][userID] = true
+			m.BroadcastToMap(mapID, userStatusMessage(userID, UserStatusAway))
+			marked++
+		}
+	}
+
+	if marked > 0 {
+		m.logger.Info("💤 Users idle, shown as away", "users", marked, "timeout", timeout)
+	}
+	return marked
+}
+
+// recordActivity notes that the client's user is active, telling the map if
+// they were away
+func (m *Manager) recordActivity(client *Client, at time.Time) {
+	client.lastActivity.Store(at.UnixNano())
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.markActive(client.MapID, client.UserID)
+}
+
+// markActive tells the map that an away user is active again. The caller
+// must hold the mutex.
+func (m *Manager) markActive(mapID, userID string) {
+	if m.idle == nil || !m.away[mapID][userID] {
+		return
+	}
+	delete(m.away[mapID], userID)
+	if len(m.away[mapID]) == 0 {
+		delete(m.away, mapID)
+	}
+	m.BroadcastToMap(mapID, userStatusMessage(userID, UserStatusActive))
+}
+
+// userWentOffline forgets the status of a user who left the map with their
+// last connection and tells the map
+func (m *Manager) userWentOffline(mapID, userID string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.idle == nil {
+		return
+	}
+	delete(m.away[mapID], userID)
+	if len(m.away[mapID]) == 0 {
+		delete(m.away, mapID)
+	}
+	m.BroadcastToMap(mapID, userStatusMessage(userID, UserStatusOffline))
+}
+
+// UserStatus returns whether a user connected to a map is active or away
+func (m *Manager) UserStatus(mapID, userID string) string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if m.away[mapID][userID] {
+		return UserStatusAway
+	}
+	return UserStatusActive
+}
+
+// userStatusMessage tells clients that a user became active, away or offline
+func userStatusMessage(userID, status string) Message {
+	return Message{
+		Type: "user_status",
+		Data: map[string]interface{}{
+			"userId": userID,
+			"status": status,
+		},
+		Timestamp: time.Now(),
+	}
+}
+
+// handleActivityPing records that the client's user is active, like moving
+// the mouse or typing, so they aren't shown as away
+func (h *Handler) handleActivityPing(ctx context.Context, client *Client, msg Message) {
+	h.manager.recordActivity(client, time.Now())
+}
+
+// lastActivityAt returns when the client last showed user activity, or when
+// it was registered if it never did
+func (c *Client) lastActivityAt() time.Time {
+	return time.Unix(0, c.lastActivity.Load())
+}
@@ -0,0 +1,129 @@
+package websocket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newIdleTestHandler(t *testing.T) (*Handler, *MockSessionService) {
+	t.Helper()
+	sessionService := new(MockSessionService)
+	handler := NewHandler(sessionService, new(MockRateLimiter), nil, nil)
+	handler.SetIdleTimeout(time.Hour)
+	t.Cleanup(handler.manager.Shutdown)
+	return handler, sessionService
+}
+
+func TestManager_MarkIdleUsersAway(t *testing.T) {
+	handler, _ := newIdleTestHandler(t)
+	manager := handler.manager
+	now := time.Now()
+
+	// user-1 is active in one of their tabs, user-2 in none
+	idleTab := &Client{SessionID: "session-1a", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 10)}
+	activeTab := &Client{SessionID: "session-1b", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 10)}
+	idle := &Client{SessionID: "session-2", UserID: "user-2", MapID: "map-1", Send: make(chan Message, 10)}
+	manager.registerClient(idleTab)
+	manager.registerClient(activeTab)
+	manager.registerClient(idle)
+	idleTab.lastActivity.Store(now.Add(-10 * time.Minute).UnixNano())
+	activeTab.lastActivity.Store(now.Add(-time.Minute).UnixNano())
+	idle.lastActivity.Store(now.Add(-10 * time.Minute).UnixNano())
+
+	assert.Equal(t, 1, manager.markIdleUsersAway(5*time.Minute, now))
+
+	status := receiveType(t, activeTab, "user_status")
+	assert.Equal(t, map[string]interface{}{"userId": "user-2", "status": UserStatusAway}, status.Data)
+	assert.Equal(t, UserStatusAway, manager.UserStatus("map-1", "user-2"))
+	assert.Equal(t, UserStatusActive, manager.UserStatus("map-1", "user-1"))
+
+	// Users already away aren't announced again
+	assert.Zero(t, manager.markIdleUsersAway(5*time.Minute, now))
+}
+
+func TestHandler_ActivityPing_MarksAwayUserActive(t *testing.T) {
+	handler, _ := newIdleTestHandler(t)
+	client := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 10)}
+	handler.manager.registerClient(client)
+	require.Equal(t, 1, handler.manager.markIdleUsersAway(time.Minute, time.Now().Add(time.Hour)))
+	receiveType(t, client, "user_status")
+
+	handler.handleMessage(client, Message{Type: "activity_ping"})
+
+	status := receiveType(t, client, "user_status")
+	assert.Equal(t, map[string]interface{}{"userId": "user-1", "status": UserStatusActive}, status.Data)
+	assert.Equal(t, UserStatusActive, handler.manager.UserStatus("map-1", "user-1"))
+	assert.WithinDuration(t, time.Now(), client.lastActivityAt(), time.Second)
+}
+
+func TestHandler_Heartbeat_IsNotActivity(t *testing.T) {
+	handler, sessionService := newIdleTestHandler(t)
+	sessionService.On("SessionHeartbeat", mock.Anything, "session-1").Return(nil)
+	client := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 10)}
+	handler.manager.registerClient(client)
+	idleSince := time.Now().Add(-time.Hour)
+	client.lastActivity.Store(idleSince.UnixNano())
+
+	handler.handleMessage(client, Message{Type: "heartbeat"})
+
+	receiveType(t, client, "pong")
+	assert.Equal(t, idleSince.UnixNano(), client.lastActivityAt().UnixNano())
+}
+
+func TestManager_RegisterClient_MarksAwayUserActive(t *testing.T) {
+	handler, _ := newIdleTestHandler(t)
+	first := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 10)}
+	handler.manager.registerClient(first)
+	handler.manager.markIdleUsersAway(time.Minute, time.Now().Add(time.Hour))
+	receiveType(t, first, "user_status")
+
+	// Opening another tab is activity
+	handler.manager.registerClient(&Client{SessionID: "session-2", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 10)})
+
+	status := receiveType(t, first, "user_status")
+	assert.Equal(t, UserStatusActive, status.Data.(map[string]interface{})["status"])
+}
+
+func TestManager_UserWentOffline(t *testing.T) {
+	handler, _ := newIdleTestHandler(t)
+	observer := &Client{SessionID: "session-2", UserID: "user-2", MapID: "map-1", Send: make(chan Message, 10)}
+	handler.manager.registerClient(&Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 10)})
+	handler.manager.registerClient(observer)
+	handler.manager.markIdleUsersAway(time.Minute, time.Now().Add(time.Hour))
+
+	handler.manager.userWentOffline("map-1", "user-1")
+
+	for {
+		status := receiveType(t, observer, "user_status")
+		data := status.Data.(map[string]interface{})
+		if data["userId"] == "user-1" && data["status"] == UserStatusOffline {
+			break
+		}
+	}
+	handler.manager.mutex.RLock()
+	assert.False(t, handler.manager.away["map-1"]["user-1"])
+	handler.manager.mutex.RUnlock()
+}
+
+func TestHandler_PresenceUser_IncludesStatus(t *testing.T) {
+	handler, sessionService := newIdleTestHandler(t)
+	sessionService.On("GetSession", mock.Anything, "session-1").Return(&models.Session{ID: "session-1", UserID: "user-1", MapID: "map-1", IsActive: true}, nil)
+	client := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 10)}
+	handler.manager.registerClient(client)
+
+	userData, ok := handler.presenceUser(context.Background(), "session-1", models.PositionPrivacy{})
+	require.True(t, ok)
+	assert.Equal(t, UserStatusActive, userData["status"])
+
+	handler.manager.markIdleUsersAway(time.Minute, time.Now().Add(time.Hour))
+	userData, ok = handler.presenceUser(context.Background(), "session-1", models.PositionPrivacy{})
+	require.True(t, ok)
+	assert.Equal(t, UserStatusAway, userData["status"])
+}
@@ -0,0 +1,111 @@
+package websocket
+
+import (
+	"context"
+	"time"
+
+	"breakoutglobe/internal/models"
+)
+
+// DefaultInitialUsersPageSize is the most users sent in one initial_users_page
+const DefaultInitialUsersPageSize = 200
+
+// SetInitialUsersPageSize splits the initial users of maps with more than
+// size other users into initial_users_page messages of up to size users,
+// followed by initial_users_complete, instead of one huge initial_users
+// message. Users inside the client's viewport come first. Zero always sends
+// one initial_users message.
+func (h *Handler) SetInitialUsersPageSize(size int) {
+	if size < 0 {
+		size = 0
+	}
+	h.initialUsersPageSize = size
+}
+
+// sendInitialUsersPages sends the users in pages, the users the client sees
+// first, and marks the end with initial_users_complete. Pages that don't fit
+// into the client's send buffer end it early, so the client never gets the
+// marker and can request the initial users again.
+func (h *Handler) sendInitialUsersPages(ctx context.Context, client *Client, users []map[string]interface{}) {
+	users = h.viewportFirst(client, users)
+	size := h.initialUsersPageSize
+	totalPages := (len(users) + size - 1) / size
+
+	for page := 0; page < totalPages; page++ {
+		end := (page + 1) * size
+		if end > len(users) {
+			end = len(users)
+		}
+		pageMsg := Message{
+			Type: "initial_users_page",
+			Data: map[string]interface{}{
+				"users":      users[page*size : end],
+				"page":       page + 1,
+				"totalPages": totalPages,
+			},
+			Timestamp: time.Now(),
+		}
+		select {
+		case client.Send <- pageMsg:
+		default:
+			h.requestLogger(ctx).Warn("Failed to send initial users page to client",
+				"sessionId", client.SessionID,
+				"page", page+1,
+				"totalPages", totalPages)
+			return
+		}
+	}
+
+	completeMsg := Message{
+		Type: "initial_users_complete",
+		Data: map[string]interface{}{
+			"totalUsers": len(users),
+			"totalPages": totalPages,
+		},
+		Timestamp: time.Now(),
+	}
+	select {
+	case client.Send <- completeMsg:
+		h.requestLogger(ctx).Info("Sent initial users to client in pages",
+			"sessionId", client.SessionID,
+			"userCount", len(users),
+			"pages", totalPages)
+	default:
+		h.requestLogger(ctx).Warn("Failed to send initial users complete to client",
+			"sessionId", client.SessionID)
+	}
+}
+
+// viewportFirst orders the users inside the client's viewport ahead of the
+// others, keeping their order otherwise. Users are placed by the position the
+// client sees, so coarse positions stay coarse.
+func (h *Handler) viewportFirst(client *Client, users []map[string]interface{}) []map[string]interface{} {
+	viewport := h.manager.Viewport(client)
+	if viewport == nil {
+		return users
+	}
+
+	ordered := make([]map[string]interface{}, 0, len(users))
+	var outside []map[string]interface{}
+	for _, user := range users {
+		if position, ok := user["position"].(map[string]float64); ok && viewport.Contains(models.LatLng{Lat: position["lat"], Lng: position["lng"]}) {
+			ordered = append(ordered, user)
+		} else {
+			outside = append(outside, user)
+		}
+	}
+	return append(ordered, outside...)
+}
+
+// Viewport returns the map area the client displays, plus a margin, or nil
+// if the client didn't send one
+func (m *Manager) Viewport(client *Client) *models.Bounds {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if client.viewport == nil {
+		return nil
+	}
+	viewport := *client.viewport
+	return &viewport
+}
@@ -0,0 +1,90 @@
+package websocket
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newInitialUsersTestHandler connects the requesting client and four other
+// users to map-1. Only user-4 is near Berlin, the others are in New York.
+func newInitialUsersTestHandler(t *testing.T) (*Handler, *Client) {
+	sessionService := new(MockSessionService)
+	handler := NewHandler(sessionService, new(MockRateLimiter), nil, nil)
+	t.Cleanup(handler.manager.Shutdown)
+
+	for i := 1; i <= 5; i++ {
+		sessionID := fmt.Sprintf("session-%d", i)
+		position := models.LatLng{Lat: 40.7128, Lng: -74.006}
+		if i == 4 {
+			position = models.LatLng{Lat: 52.52, Lng: 13.405}
+		}
+		sessionService.On("GetSession", mock.Anything, sessionID).Return(&models.Session{
+			ID:        sessionID,
+			UserID:    fmt.Sprintf("user-%d", i),
+			MapID:     "map-1",
+			AvatarPos: position,
+			IsActive:  true,
+		}, nil)
+		if i > 1 {
+			handler.manager.registerClient(&Client{SessionID: sessionID, UserID: fmt.Sprintf("user-%d", i), MapID: "map-1", Send: make(chan Message, 10)})
+		}
+	}
+
+	client := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 10)}
+	handler.manager.registerClient(client)
+	return handler, client
+}
+
+func TestHandler_InitialUsers_Pages(t *testing.T) {
+	handler, client := newInitialUsersTestHandler(t)
+	handler.SetInitialUsersPageSize(3)
+	handler.manager.SetViewport(client, models.Bounds{North: 53, South: 52, East: 14, West: 13})
+
+	handler.handleRequestInitialUsers(context.Background(), client, Message{Type: "request_initial_users"})
+
+	first := receiveType(t, client, "initial_users_page")
+	firstData := first.Data.(map[string]interface{})
+	assert.Equal(t, 1, firstData["page"])
+	assert.Equal(t, 2, firstData["totalPages"])
+	firstUsers := firstData["users"].([]map[string]interface{})
+	require.Len(t, firstUsers, 3)
+	// The user in the client's viewport comes first
+	assert.Equal(t, "user-4", firstUsers[0]["userId"])
+
+	second := receiveType(t, client, "initial_users_page")
+	secondData := second.Data.(map[string]interface{})
+	assert.Equal(t, 2, secondData["page"])
+	assert.Len(t, secondData["users"], 1)
+
+	complete := receiveType(t, client, "initial_users_complete")
+	assert.Equal(t, map[string]interface{}{"totalUsers": 4, "totalPages": 2}, complete.Data)
+
+	seen := make(map[interface{}]bool)
+	for _, user := range append(firstUsers, secondData["users"].([]map[string]interface{})...) {
+		seen[user["userId"]] = true
+	}
+	assert.Len(t, seen, 4)
+	assert.False(t, seen["user-1"])
+}
+
+func TestHandler_InitialUsers_SmallMapsInOneMessage(t *testing.T) {
+	handler, client := newInitialUsersTestHandler(t)
+
+	handler.handleRequestInitialUsers(context.Background(), client, Message{Type: "request_initial_users"})
+
+	initialUsers := receiveType(t, client, "initial_users")
+	assert.Len(t, initialUsers.Data.(map[string]interface{})["users"], 4)
+
+	// Paging disabled
+	handler.SetInitialUsersPageSize(0)
+	handler.handleRequestInitialUsers(context.Background(), client, Message{Type: "request_initial_users"})
+	initialUsers = receiveType(t, client, "initial_users")
+	assert.Len(t, initialUsers.Data.(map[string]interface{})["users"], 4)
+}
@@ -0,0 +1,266 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"breakoutglobe/internal/models"
+)
+
+// journalAppendTimeout bounds how long the journal writer waits for an append
+const journalAppendTimeout = time.Second
+
+// journalQueueSize bounds the broadcasts waiting for the journal writer
+const journalQueueSize = 256
+
+// EventJournalInterface defines the interface for the per-map broadcast journal
+type EventJournalInterface interface {
+	Append(ctx context.Context, mapID, eventType string, data []byte) (uint64, error)
+	ReadAfter(ctx context.Context, mapID string, seq uint64) ([]models.MapEvent, bool, error)
+}
+
+// journalWriter appends map broadcasts to the journal off the manager's run
+// loop, in the order they were queued, and hands them back to the run loop
+// stamped with their map-wide sequence number
+type journalWriter struct {
+	journal EventJournalInterface
+	queue   chan journalEntry
+	stop    chan struct{}
+}
+
+// journalEntry is a map broadcast queued for the journal writer
+type journalEntry struct {
+	BroadcastMessage
+	// journaled is set for broadcasts worth replaying, and appended for those
+	// to append; the others only wait behind the map's queued broadcasts, so
+	// clients get the default lane in order
+	journaled bool
+	appended  bool
+}
+
+// SetEventJournal enables replaying missed map broadcasts on resync_from.
+// Without it, every resync falls back to a full initial_state snapshot.
+func (h *Handler) SetEventJournal(journal EventJournalInterface) {
+	h.journal = journal
+	h.manager.SetEventJournal(journal)
+}
+
+// SetEventJournal journals map broadcasts of the default lane and stamps them
+// with their map-wide sequence number
+func (m *Manager) SetEventJournal(journal EventJournalInterface) {
+	writer := &journalWriter{
+		journal: journal,
+		queue:   make(chan journalEntry, journalQueueSize),
+		stop:    make(chan struct{}),
+	}
+
+	m.mutex.Lock()
+	if m.journalWriter != nil {
+		close(m.journalWriter.stop)
+	}
+	m.journalWriter = writer
+	m.mutex.Unlock()
+
+	go m.runJournalWriter(writer)
+}
+
+// queueForJournal hands a map broadcast to the journal writer and reports
+// whether it did. Movement, signaling and transient messages like reactions
+// aren't worth replaying, and maps without local clients have nobody to
+// replay to; those broadcasts are only queued behind the map's journaled ones.
+func (m *Manager) queueForJournal(broadcastMsg BroadcastMessage) bool {
+	message := broadcastMsg.Message
+	if priorityOf(message.Type) != priorityDefault {
+		return false
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	writer := m.journalWriter
+	if writer == nil {
+		return false
+	}
+	entry := journalEntry{
+		BroadcastMessage: broadcastMsg,
+		journaled:        !transientMessageTypes[message.Type],
+	}
+	entry.appended = entry.journaled && len(m.mapClients[broadcastMsg.MapID]) > 0
+	if m.journalPending[broadcastMsg.MapID] == 0 && !entry.appended {
+		if entry.journaled {
+			m.markJournalGapLocked(broadcastMsg.MapID)
+		}
+		return false
+	}
+
+	select {
+	case writer.queue <- entry:
+		m.journalPending[broadcastMsg.MapID]++
+		return true
+	default:
+		m.logger.Warn("Journal queue full, broadcasting without journaling", "mapId", broadcastMsg.MapID, "messageType", message.Type)
+		if entry.journaled {
+			m.markJournalGapLocked(broadcastMsg.MapID)
+		}
+		return false
+	}
+}
+
+// runJournalWriter appends the queued broadcasts until the writer is stopped
+func (m *Manager) runJournalWriter(writer *journalWriter) {
+	for {
+		select {
+		case <-writer.stop:
+			return
+		case entry := <-writer.queue:
+			if entry.appended {
+				entry.Message.MapSeq = m.appendToJournal(writer.journal, entry.MapID, entry.Message)
+			}
+			select {
+			case m.journaled <- entry:
+			case <-writer.stop:
+				return
+			}
+		}
+	}
+}
+
+// appendToJournal appends a map broadcast to the journal and returns its
+// map-wide sequence number, or 0 if it couldn't be journaled
+func (m *Manager) appendToJournal(journal EventJournalInterface, mapID string, message Message) uint64 {
+	// Replays go to everyone, so they only carry coarse positions
+	payload := message.Data
+	if message.coarseData != nil {
+		payload = message.coarseData
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		m.logger.Warn("Failed to encode broadcast for journal", "mapId", mapID, "messageType", message.Type, "error", err)
+		return 0
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), journalAppendTimeout)
+	defer cancel()
+	mapSeq, err := journal.Append(ctx, mapID, message.Type, data)
+	if err != nil {
+		m.logger.Warn("Failed to journal broadcast", "mapId", mapID, "messageType", message.Type, "error", err)
+		return 0
+	}
+	return mapSeq
+}
+
+// deliverJournaled broadcasts a map broadcast the journal writer handed back.
+// Journal heads advance as broadcasts are delivered, so a gap marked by a
+// broadcast delivered in between covers everything clients may have missed.
+func (m *Manager) deliverJournaled(entry journalEntry) {
+	m.mutex.Lock()
+	if entry.Message.MapSeq != 0 {
+		m.journalHeads[entry.MapID] = entry.Message.MapSeq
+	} else if entry.journaled {
+		m.markJournalGapLocked(entry.MapID)
+	}
+	m.mutex.Unlock()
+
+	m.deliverToMap(entry.BroadcastMessage)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.journalPending[entry.MapID]--; m.journalPending[entry.MapID] <= 0 {
+		delete(m.journalPending, entry.MapID)
+	}
+}
+
+// markJournalGapLocked records that a broadcast of a map wasn't journaled, so
+// replays from before it would silently miss it. The caller must hold the
+// manager's mutex.
+func (m *Manager) markJournalGapLocked(mapID string) {
+	if head, exists := m.journalHeads[mapID]; exists {
+		m.journalGaps[mapID] = head
+		delete(m.journalHeads, mapID)
+	}
+}
+
+// replayable reports whether the journal of a map holds every broadcast after seq
+func (m *Manager) replayable(mapID string, seq uint64) bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	gap, exists := m.journalGaps[mapID]
+	return !exists || seq > gap
+}
+
+// JournalEpoch identifies the journal sequence numbers handed out by this
+// process. Journals are kept per instance, so a reconnecting client's last
+// sequence number only means something to the same process.
+func (m *Manager) JournalEpoch() string {
+	return m.journalEpoch
+}
+
+// handleResyncFrom replays the map broadcasts after the client's last seen
+// map sequence number. Falls back to a full snapshot when the journal can't
+// cover the gap.
+func (h *Handler) handleResyncFrom(ctx context.Context, client *Client, msg Message) {
+	now := time.Now()
+	if now.Sub(client.lastResyncAt) < minResyncInterval {
+		h.sendErrorMessage(ctx, client, "Resync requested too often")
+		return
+	}
+
+	var payload ResyncFromPayload
+	if !h.decodeMessagePayload(client, msg, &payload) {
+		return
+	}
+
+	if !h.replayFrom(ctx, client, *payload.Seq) {
+		h.handleResync(ctx, client, msg)
+		return
+	}
+	client.lastResyncAt = now
+}
+
+// resume answers the resume handshake of a reconnecting client, which passes
+// the last map sequence number and journal epoch it saw as lastSeq and epoch
+// query parameters. The client gets the broadcasts it missed instead of the
+// initial users, or an initial_state snapshot if they can't be replayed.
+func (h *Handler) resume(ctx context.Context, client *Client, lastSeq uint64, epoch string) {
+	if epoch == h.manager.JournalEpoch() && h.replayFrom(ctx, client, lastSeq) {
+		return
+	}
+
+	h.requestLogger(ctx).Info("🔄 Resume not possible, sending snapshot",
+		"sessionId", client.SessionID,
+		"mapId", client.MapID,
+		"lastSeq", lastSeq)
+	h.handleResync(ctx, client, Message{Type: "resync"})
+}
+
+// replayFrom sends the client an event_replay of the map broadcasts after seq.
+// It returns false if the journal doesn't hold all of them.
+func (h *Handler) replayFrom(ctx context.Context, client *Client, seq uint64) bool {
+	if h.journal == nil || !h.manager.replayable(client.MapID, seq) {
+		return false
+	}
+
+	events, complete, err := h.journal.ReadAfter(ctx, client.MapID, seq)
+	if err != nil {
+		h.requestLogger(ctx).Warn("Failed to read event journal", "mapId", client.MapID, "error", err.Error())
+		return false
+	}
+	if !complete {
+		return false
+	}
+
+	h.requestLogger(ctx).Info("🔄 Replaying journaled events",
+		"sessionId", client.SessionID,
+		"mapId", client.MapID,
+		"fromSeq", seq,
+		"events", len(events))
+
+	select {
+	case client.Send <- replyTo(ctx, Message{Type: "event_replay", Data: map[string]interface{}{"events": events}, Timestamp: time.Now()}):
+	default:
+		h.requestLogger(ctx).Warn("Failed to send event replay to client", "sessionId", client.SessionID)
+	}
+	return true
+}
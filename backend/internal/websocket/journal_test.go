@@ -0,0 +1,197 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeEventJournal struct {
+	events []models.MapEvent
+	maxLen int
+}
+
+func (j *fakeEventJournal) Append(ctx context.Context, mapID, eventType string, data []byte) (uint64, error) {
+	seq := uint64(1)
+	if len(j.events) > 0 {
+		seq = j.events[len(j.events)-1].Seq + 1
+	}
+	j.events = append(j.events, models.MapEvent{Seq: seq, Type: eventType, Data: data})
+	if len(j.events) > j.maxLen {
+		j.events = j.events[1:]
+	}
+	return seq, nil
+}
+
+func (j *fakeEventJournal) ReadAfter(ctx context.Context, mapID string, seq uint64) ([]models.MapEvent, bool, error) {
+	if len(j.events) == 0 || seq+1 < j.events[0].Seq {
+		return nil, false, nil
+	}
+	events := []models.MapEvent{}
+	for _, event := range j.events {
+		if event.Seq > seq {
+			events = append(events, event)
+		}
+	}
+	return events, true, nil
+}
+
+// blockingEventJournal holds appends until release is closed
+type blockingEventJournal struct {
+	fakeEventJournal
+	release chan struct{}
+}
+
+func (j *blockingEventJournal) Append(ctx context.Context, mapID, eventType string, data []byte) (uint64, error) {
+	<-j.release
+	return j.fakeEventJournal.Append(ctx, mapID, eventType, data)
+}
+
+// waitForJournal waits until the journal writer's broadcasts were delivered
+func waitForJournal(t *testing.T, manager *Manager) {
+	require.Eventually(t, func() bool {
+		manager.mutex.RLock()
+		defer manager.mutex.RUnlock()
+		return len(manager.journalPending) == 0
+	}, time.Second, time.Millisecond)
+}
+
+func TestManager_BroadcastToMap_JournalsDefaultLane(t *testing.T) {
+	manager := NewManager()
+	defer manager.Shutdown()
+	journal := &fakeEventJournal{maxLen: 10}
+	manager.SetEventJournal(journal)
+	client := &Client{SessionID: "session-1", MapID: "map-1", Send: make(chan Message, 4), movement: make(chan Message, 4)}
+	manager.mapClients["map-1"] = map[string]*Client{"session-1": client}
+
+	manager.broadcastToMap(BroadcastMessage{MapID: "map-1", Message: Message{Type: "poi_created", Data: map[string]interface{}{"poiId": "poi-1"}}})
+	manager.broadcastToMap(BroadcastMessage{MapID: "map-1", Message: Message{Type: "avatar_moved"}})
+	// Maps without local clients aren't journaled
+	manager.broadcastToMap(BroadcastMessage{MapID: "map-2", Message: Message{Type: "poi_created"}})
+
+	created := <-client.Send
+	assert.Equal(t, uint64(1), created.MapSeq)
+	assert.Zero(t, (<-client.movement).MapSeq)
+	require.Len(t, journal.events, 1)
+	assert.JSONEq(t, `{"poiId":"poi-1"}`, string(journal.events[0].Data))
+}
+
+func TestHandler_ResyncFrom_ReplaysJournal(t *testing.T) {
+	journal := &fakeEventJournal{maxLen: 2}
+	for i := 0; i < 3; i++ {
+		journal.Append(context.Background(), "map-1", "poi_updated", []byte(`{}`))
+	}
+	poiService := new(MockPOIService)
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, poiService)
+	handler.SetEventJournal(journal)
+	client := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 4)}
+
+	handler.handleResyncFrom(context.Background(), client, Message{Type: "resync_from", Data: map[string]interface{}{"seq": float64(1)}})
+
+	msg := <-client.Send
+	require.Equal(t, "event_replay", msg.Type)
+	encoded, err := json.Marshal(msg.Data)
+	require.NoError(t, err)
+	var replay struct {
+		Events []models.MapEvent `json:"events"`
+	}
+	require.NoError(t, json.Unmarshal(encoded, &replay))
+	require.Len(t, replay.Events, 2)
+	assert.Equal(t, uint64(2), replay.Events[0].Seq)
+}
+
+func TestHandler_ResyncFrom_FallsBackToSnapshot(t *testing.T) {
+	journal := &fakeEventJournal{maxLen: 2}
+	for i := 0; i < 5; i++ {
+		journal.Append(context.Background(), "map-1", "poi_updated", []byte(`{}`))
+	}
+	poiService := new(MockPOIService)
+	poiService.On("GetPOIsForMap", mock.Anything, "map-1").Return([]*models.POI{}, nil).Once()
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, poiService)
+	handler.SetEventJournal(journal)
+	client := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 4)}
+
+	// Events 2 and 3 were trimmed
+	handler.handleResyncFrom(context.Background(), client, Message{Type: "resync_from", Data: map[string]interface{}{"seq": float64(1)}})
+
+	assert.Equal(t, "initial_state", (<-client.Send).Type)
+	poiService.AssertExpectations(t)
+}
+
+func TestManager_JournalGap_PreventsReplay(t *testing.T) {
+	manager := NewManager()
+	defer manager.Shutdown()
+	journal := &fakeEventJournal{maxLen: 10}
+	manager.SetEventJournal(journal)
+	client := &Client{SessionID: "session-1", MapID: "map-1", Send: make(chan Message, 4)}
+	manager.mapClients["map-1"] = map[string]*Client{"session-1": client}
+
+	manager.broadcastToMap(BroadcastMessage{MapID: "map-1", Message: Message{Type: "poi_created"}})
+	waitForJournal(t, manager)
+	assert.True(t, manager.replayable("map-1", 1))
+
+	// Broadcasts while the map has no local clients aren't journaled
+	delete(manager.mapClients, "map-1")
+	manager.broadcastToMap(BroadcastMessage{MapID: "map-1", Message: Message{Type: "poi_updated"}})
+	manager.mapClients["map-1"] = map[string]*Client{"session-1": client}
+	manager.broadcastToMap(BroadcastMessage{MapID: "map-1", Message: Message{Type: "poi_deleted"}})
+	waitForJournal(t, manager)
+
+	assert.False(t, manager.replayable("map-1", 1))
+	assert.True(t, manager.replayable("map-1", 2))
+}
+
+func TestManager_BroadcastToMap_JournalsOffRunLoop(t *testing.T) {
+	manager := NewManager()
+	defer manager.Shutdown()
+	journal := &blockingEventJournal{fakeEventJournal: fakeEventJournal{maxLen: 10}, release: make(chan struct{})}
+	manager.SetEventJournal(journal)
+	waiting := &Client{SessionID: "session-1", MapID: "map-1", Send: make(chan Message, 4), movement: make(chan Message, 4)}
+	other := &Client{SessionID: "session-2", MapID: "map-2", Send: make(chan Message, 4)}
+	manager.registerClient(waiting)
+	manager.registerClient(other)
+
+	require.NoError(t, manager.BroadcastToMap("map-1", Message{Type: "poi_created"}))
+	require.NoError(t, manager.BroadcastToMap("map-1", Message{Type: "reaction"}))
+	require.NoError(t, manager.BroadcastToMap("map-1", Message{Type: "avatar_moved"}))
+	require.NoError(t, manager.BroadcastToMap("map-2", Message{Type: "reaction"}))
+
+	// A slow journal holds back the map's default lane, but nothing else
+	receiveType(t, other, "reaction")
+	assert.Equal(t, "avatar_moved", (<-waiting.movement).Type)
+	assert.Empty(t, waiting.Send)
+
+	close(journal.release)
+	created := <-waiting.Send
+	assert.Equal(t, "poi_created", created.Type)
+	assert.Equal(t, uint64(1), created.MapSeq)
+	assert.Equal(t, "reaction", (<-waiting.Send).Type)
+}
+
+func TestHandler_Resume(t *testing.T) {
+	journal := &fakeEventJournal{maxLen: 10}
+	for i := 0; i < 3; i++ {
+		journal.Append(context.Background(), "map-1", "poi_updated", []byte(`{}`))
+	}
+	poiService := new(MockPOIService)
+	poiService.On("GetPOIsForMap", mock.Anything, "map-1").Return([]*models.POI{}, nil).Once()
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, poiService)
+	handler.SetEventJournal(journal)
+
+	client := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 4)}
+	handler.resume(context.Background(), client, 2, handler.manager.JournalEpoch())
+	assert.Equal(t, "event_replay", (<-client.Send).Type)
+
+	// Sequence numbers from another instance or process can't be replayed
+	client = &Client{SessionID: "session-2", UserID: "user-2", MapID: "map-1", Send: make(chan Message, 4)}
+	handler.resume(context.Background(), client, 2, "other-epoch")
+	assert.Equal(t, "initial_state", (<-client.Send).Type)
+	poiService.AssertExpectations(t)
+}
@@ -0,0 +1,64 @@
+package websocket
+
+import (
+	"time"
+
+	"breakoutglobe/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// SetMetrics records how long broadcasts take from publication until they are
+// written to each client, per message type, how many bytes are
+// written compressed and uncompressed, how often clients fall behind, and
+// the connections, queue depths and broadcasts of the hub. Without it, none
+// of these are measured.
+func (h *Handler) SetMetrics(registry prometheus.Registerer) {
+	// Not labelled by map, since every map would add a full set of buckets
+	h.deliveryLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "breakoutglobe_broadcast_delivery_seconds",
+		Help:    "Time from publishing a broadcast until it is written to a WebSocket client.",
+		Buckets: metrics.LatencyBuckets,
+	}, []string{"type"})
+	registry.MustRegister(h.deliveryLatency)
+
+	h.payloadBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "breakoutglobe_websocket_payload_bytes_total",
+		Help: "Bytes of messages written to WebSocket clients before compression, by whether they were compressed.",
+	}, []string{"compressed"})
+	registry.MustRegister(h.payloadBytes)
+
+	h.slowConsumerEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "breakoutglobe_websocket_slow_consumer_events_total",
+		Help: "WebSocket clients falling behind broadcasts, by map, slow consumer policy and event: lagging, dropped or evicted.",
+	}, []string{"map_id", "policy", "event"})
+	registry.MustRegister(h.slowConsumerEvents)
+
+	h.manager.registerMetrics(registry)
+}
+
+// eventPublishedAt returns when a PubSub event was published, or now for
+// events without a publication timestamp
+func eventPublishedAt(data map[string]interface{}) time.Time {
+	if publishedAt, ok := data["timestamp"].(time.Time); ok && !publishedAt.IsZero() {
+		return publishedAt
+	}
+	return time.Now()
+}
+
+// stampPublished marks a broadcast published now unless it carries the
+// publication time of the event it was created from
+func stampPublished(message Message) Message {
+	if message.publishedAt.IsZero() {
+		message.publishedAt = time.Now()
+	}
+	return message
+}
+
+// recordDeliveryLatency records the age of a broadcast written to the client
+func (c *Client) recordDeliveryLatency(message Message) {
+	if c.deliveryLatency == nil || message.publishedAt.IsZero() {
+		return
+	}
+	c.deliveryLatency.WithLabelValues(message.Type).Observe(time.Since(message.publishedAt).Seconds())
+}
@@ -0,0 +1,73 @@
+package websocket
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_RecordsDeliveryLatencyFromPublication(t *testing.T) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, new(MockPOIService))
+	registry := prometheus.NewRegistry()
+	handler.SetMetrics(registry)
+
+	client := &Client{
+		SessionID:       "session-1",
+		MapID:           "map-1",
+		Send:            make(chan Message, 10),
+		deliveryLatency: handler.deliveryLatency,
+	}
+	handler.manager.registerClient(client)
+
+	// Published two seconds ago, e.g. by another instance
+	publishedAt := time.Now().Add(-2 * time.Second)
+	handler.handlePubSubEvent("poi_created", map[string]interface{}{
+		"poiId":     "poi-1",
+		"mapId":     "map-1",
+		"timestamp": publishedAt,
+	})
+
+	var msg Message
+	select {
+	case msg = <-client.Send:
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Expected POI created broadcast not received")
+	}
+	assert.Equal(t, publishedAt, msg.publishedAt)
+	client.recordDeliveryLatency(msg)
+
+	output := scrapeMetrics(t, registry)
+	assert.Contains(t, output, `breakoutglobe_broadcast_delivery_seconds_bucket{type="poi_created",le="1"} 0`)
+	assert.Contains(t, output, `breakoutglobe_broadcast_delivery_seconds_bucket{type="poi_created",le="2.5"} 1`)
+	assert.NotContains(t, output, `breakoutglobe_broadcast_delivery_seconds_bucket{map_id=`)
+}
+
+func TestStampPublished(t *testing.T) {
+	publishedAt := time.Now().Add(-time.Second)
+	assert.Equal(t, publishedAt, stampPublished(Message{publishedAt: publishedAt}).publishedAt)
+	assert.False(t, stampPublished(Message{}).publishedAt.IsZero())
+
+	// Direct messages aren't measured
+	latency := prometheus.NewHistogramVec(prometheus.HistogramOpts{Name: "latency", Buckets: metrics.LatencyBuckets}, []string{"type"})
+	client := &Client{MapID: "map-1", deliveryLatency: latency}
+	client.recordDeliveryLatency(Message{Type: "welcome"})
+	assert.Equal(t, 0, testutil.CollectAndCount(latency))
+}
+
+// scrapeMetrics returns the metrics of a registry in the Prometheus text format
+func scrapeMetrics(t *testing.T, registry *prometheus.Registry) string {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	return recorder.Body.String()
+}
@@ -0,0 +1,264 @@
+package websocket
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"breakoutglobe/internal/models"
+)
+
+// loadCheckInterval is how often the hub's load is checked when load shedding
+// is enabled
+const loadCheckInterval = time.Second
+
+// loadSheddingCooldown is how long the load must stay below the thresholds
+// before broadcasts stop being degraded, so spikes don't flap the mode
+const loadSheddingCooldown = 10 * time.Second
+
+// Reasons broadcasts are degraded
+const (
+	overloadBroadcastQueue = "broadcast_queue"
+	overloadCPU            = "cpu"
+)
+
+// LoadShedding sets when the hub sheds load. While degraded, avatar moves are
+// coalesced over a longer window and transient messages like reactions are
+// dropped; calls, POI state and everything else are delivered as usual.
+type LoadShedding struct {
+	// QueueThreshold is the share of the broadcast queue capacity in use that
+	// degrades broadcasts; unchecked if 0
+	QueueThreshold float64
+	// CPUThreshold is the share of the available CPU used by the process that
+	// degrades broadcasts; unchecked if 0
+	CPUThreshold float64
+	// MovementInterval is the window avatar moves are coalesced over while degraded
+	MovementInterval time.Duration
+}
+
+// loadShedder watches the hub's load and degrades broadcasts while it's too high
+type loadShedder struct {
+	config LoadShedding
+	cpu    *cpuSampler
+	stop   chan struct{}
+
+	mutex sync.Mutex
+	// reason is why broadcasts are degraded, empty while they aren't
+	reason string
+	since  time.Time
+	// overloadedAt is the last check that found the load too high
+	overloadedAt time.Time
+}
+
+// SetLoadShedding degrades broadcasts while the broadcast queue or the CPU
+// are above their thresholds, see LoadShedding
+func (h *Handler) SetLoadShedding(config LoadShedding) {
+	h.manager.SetLoadShedding(config)
+}
+
+// SetLoadShedding starts checking the hub's load. Without position batching,
+// avatar moves are only batched while degraded.
+func (m *Manager) SetLoadShedding(config LoadShedding) {
+	if config.QueueThreshold <= 0 && config.CPUThreshold <= 0 {
+		return
+	}
+	if config.MovementInterval <= 0 {
+		config.MovementInterval = loadCheckInterval / 2
+	}
+
+	shedder := &loadShedder{config: config, stop: make(chan struct{})}
+	if config.CPUThreshold > 0 {
+		shedder.cpu = newCPUSampler()
+	}
+
+	m.mutex.Lock()
+	m.shedder = shedder
+	var batcher *positionBatcher
+	if m.positions == nil {
+		batcher = newPositionBatcher(config.MovementInterval)
+		batcher.onlyWhileDegraded = true
+		m.positions = batcher
+	}
+	m.mutex.Unlock()
+
+	if batcher != nil {
+		go m.runPositionBatches(batcher)
+	}
+	go m.runLoadShedder(shedder)
+}
+
+// runLoadShedder checks the hub's load every loadCheckInterval
+func (m *Manager) runLoadShedder(shedder *loadShedder) {
+	ticker := time.NewTicker(loadCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-shedder.stop:
+			return
+		case now := <-ticker.C:
+			m.checkLoad(shedder, now)
+		}
+	}
+}
+
+// checkLoad degrades broadcasts as soon as the load is too high and restores
+// them once it stayed below the thresholds for loadSheddingCooldown
+func (m *Manager) checkLoad(shedder *loadShedder, now time.Time) {
+	reason, load := m.overloaded(shedder)
+
+	shedder.mutex.Lock()
+	defer shedder.mutex.Unlock()
+
+	switch {
+	case reason != "":
+		shedder.overloadedAt = now
+		if shedder.reason == "" {
+			shedder.since = now
+			m.logger.Warn("⚠️ WebSocket hub overloaded, degrading broadcasts",
+				"reason", reason,
+				"load", load,
+				"movementInterval", shedder.config.MovementInterval)
+		}
+		shedder.reason = reason
+		m.degraded.Store(true)
+	case shedder.reason != "" && now.Sub(shedder.overloadedAt) >= loadSheddingCooldown:
+		m.logger.Info("✅ WebSocket hub load back to normal, restoring broadcasts",
+			"degradedFor", now.Sub(shedder.since).Round(time.Second))
+		shedder.reason = ""
+		shedder.since = time.Time{}
+		m.degraded.Store(false)
+	}
+}
+
+// overloaded returns why the hub is overloaded and the load above the
+// threshold, or an empty reason if it isn't
+func (m *Manager) overloaded(shedder *loadShedder) (string, float64) {
+	if threshold := shedder.config.QueueThreshold; threshold > 0 && cap(m.broadcast) > 0 {
+		if load := float64(len(m.broadcast)) / float64(cap(m.broadcast)); load >= threshold {
+			return overloadBroadcastQueue, load
+		}
+	}
+	if threshold := shedder.config.CPUThreshold; threshold > 0 && shedder.cpu != nil {
+		if load, ok := shedder.cpu.sample(); ok && load >= threshold {
+			return overloadCPU, load
+		}
+	}
+	return "", 0
+}
+
+// shed reports whether a broadcast is dropped to shed load, counting it if so
+func (m *Manager) shed(message Message) bool {
+	if !m.degraded.Load() || !transientMessageTypes[message.Type] {
+		return false
+	}
+	m.stats.shedMessages.Add(1)
+	return true
+}
+
+// movementInterval returns how often a position batcher flushes, which is
+// stretched to the load shedding window while broadcasts are degraded
+func (m *Manager) movementInterval(batcher *positionBatcher) time.Duration {
+	if !m.degraded.Load() {
+		return batcher.interval
+	}
+
+	m.mutex.RLock()
+	shedder := m.shedder
+	m.mutex.RUnlock()
+	if shedder == nil || shedder.config.MovementInterval < batcher.interval {
+		return batcher.interval
+	}
+	return shedder.config.MovementInterval
+}
+
+// degradedBroadcasts describes why and since when broadcasts are degraded,
+// or nil if they aren't
+func (m *Manager) degradedBroadcasts() *models.DegradedBroadcasts {
+	m.mutex.RLock()
+	shedder := m.shedder
+	m.mutex.RUnlock()
+	if shedder == nil {
+		return nil
+	}
+
+	shedder.mutex.Lock()
+	defer shedder.mutex.Unlock()
+	if shedder.reason == "" {
+		return nil
+	}
+	return &models.DegradedBroadcasts{
+		Reason:             shedder.reason,
+		Since:              shedder.since,
+		MovementIntervalMs: shedder.config.MovementInterval.Milliseconds(),
+	}
+}
+
+// cpuSampler measures the share of the available CPU the process used since
+// the last sample. It reads /proc, so CPU load isn't checked on other systems.
+type cpuSampler struct {
+	lastCPU  time.Duration
+	lastWall time.Time
+}
+
+func newCPUSampler() *cpuSampler {
+	sampler := &cpuSampler{}
+	sampler.sample()
+	return sampler
+}
+
+// sample returns the CPU share used since the last sample, or false if it
+// can't be measured
+func (s *cpuSampler) sample() (float64, bool) {
+	cpu, err := processCPUTime()
+	if err != nil {
+		return 0, false
+	}
+	now := time.Now()
+	lastCPU, lastWall := s.lastCPU, s.lastWall
+	s.lastCPU, s.lastWall = cpu, now
+	if lastWall.IsZero() {
+		return 0, false
+	}
+
+	available := now.Sub(lastWall) * time.Duration(runtime.GOMAXPROCS(0))
+	if available <= 0 {
+		return 0, false
+	}
+	return float64(cpu-lastCPU) / float64(available), true
+}
+
+// clockTicksPerSecond is the unit of the CPU times in /proc, fixed on Linux
+const clockTicksPerSecond = 100
+
+// processCPUTime returns the user and system CPU time of the process
+func processCPUTime() (time.Duration, error) {
+	stat, err := os.ReadFile("/proc/self/stat")
+	if err != nil {
+		return 0, err
+	}
+
+	// The command name can contain spaces, the fields after it can't
+	end := bytes.LastIndexByte(stat, ')')
+	if end < 0 {
+		return 0, fmt.Errorf("unexpected /proc/self/stat format")
+	}
+	fields := bytes.Fields(stat[end+1:])
+	// utime and stime are the 14th and 15th fields, the 12th and 13th after the name
+	if len(fields) < 13 {
+		return 0, fmt.Errorf("unexpected /proc/self/stat format")
+	}
+	utime, err := strconv.ParseInt(string(fields[11]), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	stime, err := strconv.ParseInt(string(fields[12]), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(utime+stime) * time.Second / clockTicksPerSecond, nil
+}
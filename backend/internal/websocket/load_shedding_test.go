@@ -0,0 +1,110 @@
+package websocket
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_CheckLoad_DegradesOnBroadcastQueue(t *testing.T) {
+	// Without a hub goroutine draining the broadcast queue
+	manager := &Manager{broadcast: make(chan BroadcastMessage, 10), logger: slog.Default()}
+	shedder := &loadShedder{config: LoadShedding{QueueThreshold: 0.5, MovementInterval: time.Second}}
+	manager.shedder = shedder
+
+	now := time.Now()
+	manager.checkLoad(shedder, now)
+	assert.False(t, manager.degraded.Load())
+	assert.Nil(t, manager.degradedBroadcasts())
+
+	for i := 0; i < 5; i++ {
+		manager.broadcast <- BroadcastMessage{MapID: "map-1"}
+	}
+	manager.checkLoad(shedder, now)
+	require.True(t, manager.degraded.Load())
+	degraded := manager.degradedBroadcasts()
+	require.NotNil(t, degraded)
+	assert.Equal(t, overloadBroadcastQueue, degraded.Reason)
+	assert.Equal(t, now, degraded.Since)
+	assert.Equal(t, int64(1000), degraded.MovementIntervalMs)
+
+	// Broadcasts stay degraded until the load stayed low for the cooldown
+	for len(manager.broadcast) > 0 {
+		<-manager.broadcast
+	}
+	manager.checkLoad(shedder, now.Add(loadSheddingCooldown/2))
+	assert.True(t, manager.degraded.Load())
+	manager.checkLoad(shedder, now.Add(loadSheddingCooldown))
+	assert.False(t, manager.degraded.Load())
+	assert.Nil(t, manager.degradedBroadcasts())
+}
+
+func TestManager_LoadShedding_DropsTransientMessages(t *testing.T) {
+	manager := NewManager()
+	defer manager.Shutdown()
+	client := newTopicTestClient(manager, "session-1", "user-1", "map-1")
+	manager.degraded.Store(true)
+
+	manager.BroadcastToMap("map-1", Message{Type: "reaction"})
+	manager.BroadcastToMap("map-1", Message{Type: "poi_updated"})
+	assert.Equal(t, "poi_updated", receiveType(t, client, "poi_updated").Type)
+	assert.Empty(t, client.Send)
+	assert.Equal(t, uint64(1), manager.Stats().ShedMessages)
+
+	manager.degraded.Store(false)
+	manager.BroadcastToMap("map-1", Message{Type: "reaction"})
+	receiveType(t, client, "reaction")
+}
+
+func TestManager_LoadShedding_CoalescesMovesWhileDegraded(t *testing.T) {
+	manager := NewManager()
+	defer manager.Shutdown()
+	manager.SetLoadShedding(LoadShedding{QueueThreshold: 0.9, MovementInterval: time.Hour})
+	batcher := manager.positions
+	require.NotNil(t, batcher)
+	client := &Client{SessionID: "session-9", MapID: "map-1", Send: make(chan Message, 4), movement: make(chan Message, 4)}
+	manager.mapClients["map-1"] = map[string]*Client{"session-9": client}
+
+	// Moves are broadcast one by one while the hub isn't overloaded
+	manager.BroadcastAvatarMove("map-1", "session-1", Message{Type: "avatar_moved"})
+	select {
+	case msg := <-client.movement:
+		assert.Equal(t, "avatar_moved", msg.Type)
+	case <-time.After(time.Second):
+		t.Fatal("no avatar_moved broadcast")
+	}
+
+	manager.degraded.Store(true)
+	manager.BroadcastAvatarMove("map-1", "session-1", Message{Type: "avatar_moved"})
+	manager.BroadcastAvatarMove("map-1", "session-1", Message{Type: "avatar_moved"})
+	assert.Equal(t, time.Hour, manager.movementInterval(batcher))
+	manager.flushPositionBatches(batcher)
+	select {
+	case msg := <-client.movement:
+		require.Equal(t, "avatar_positions_batch", msg.Type)
+		assert.Len(t, msg.Data.(map[string]interface{})["positions"], 1)
+	case <-time.After(time.Second):
+		t.Fatal("no position batch broadcast")
+	}
+
+	// A move queued when the load drops is replaced by the next one
+	manager.BroadcastAvatarMove("map-1", "session-1", Message{Type: "avatar_moved"})
+	manager.degraded.Store(false)
+	manager.BroadcastAvatarMove("map-1", "session-1", Message{Type: "avatar_moved"})
+	assert.Empty(t, batcher.drain())
+}
+
+func TestProcessCPUTime(t *testing.T) {
+	if _, err := processCPUTime(); err != nil {
+		t.Skipf("no /proc on this system: %v", err)
+	}
+	sampler := newCPUSampler()
+	for start := time.Now(); time.Since(start) < 20*time.Millisecond; {
+	}
+	load, ok := sampler.sample()
+	require.True(t, ok)
+	assert.GreaterOrEqual(t, load, 0.0)
+}
@@ -0,0 +1,110 @@
+package websocket
+
+import (
+	"context"
+	"time"
+
+	"breakoutglobe/internal/services"
+)
+
+// MaintenanceInterface defines the interface for the server's maintenance mode
+type MaintenanceInterface interface {
+	Status() services.Maintenance
+	Apply(maintenance services.Maintenance)
+}
+
+// maintenanceMessageTypes change stored state and are rejected with
+// MAINTENANCE while the server is in maintenance mode. Calls and other
+// messages that aren't stored keep working.
+var maintenanceMessageTypes = map[string]bool{
+	"avatar_move":       true,
+	"avatar_move_batch": true,
+	"poi_join":          true,
+	"poi_leave":         true,
+	"map_freeze":        true,
+	"map_unfreeze":      true,
+}
+
+// SetMaintenance enables maintenance mode for WebSocket clients: the
+// maintenance banner and rejecting messages that change stored state. Without
+// it, messages are handled during maintenance.
+func (h *Handler) SetMaintenance(maintenance MaintenanceInterface) {
+	h.maintenance = maintenance
+}
+
+// maintenanceStatus returns the current maintenance mode
+func (h *Handler) maintenanceStatus() services.Maintenance {
+	if h.maintenance == nil {
+		return services.Maintenance{}
+	}
+	return h.maintenance.Status()
+}
+
+// rejectIfMaintenance tells the client that a message can't be handled while
+// the server is in maintenance mode. It returns true if the message was rejected.
+func (h *Handler) rejectIfMaintenance(ctx context.Context, client *Client, messageType string) bool {
+	if !maintenanceMessageTypes[messageType] {
+		return false
+	}
+	maintenance := h.maintenanceStatus()
+	if !maintenance.Enabled {
+		return false
+	}
+
+	select {
+	case client.Send <- replyTo(ctx, Message{
+		Type: "error",
+		Data: map[string]interface{}{
+			"code":        "MAINTENANCE",
+			"message":     "The server is in maintenance mode",
+			"messageType": messageType,
+		},
+		Timestamp: time.Now(),
+	}):
+	default:
+		h.requestLogger(ctx).Warn("Failed to send maintenance error", "sessionId", client.SessionID)
+	}
+	return true
+}
+
+// maintenanceMessage is the maintenance banner event sent to clients
+func maintenanceMessage(maintenance services.Maintenance) Message {
+	data := map[string]interface{}{
+		"enabled": maintenance.Enabled,
+		"message": maintenance.Message,
+	}
+	if maintenance.Since != nil {
+		data["since"] = *maintenance.Since
+	}
+	return Message{
+		Type:      "maintenance",
+		Data:      data,
+		Timestamp: time.Now(),
+	}
+}
+
+// handleMaintenanceEvent applies a maintenance change published by any
+// instance and tells all clients of this instance about it
+func (h *Handler) handleMaintenanceEvent(data interface{}) {
+	maintenanceData, ok := data.(map[string]interface{})
+	if !ok {
+		h.logger.Error("❌ Invalid maintenance event data", "data", data)
+		return
+	}
+
+	maintenance := services.Maintenance{}
+	maintenance.Enabled, _ = maintenanceData["enabled"].(bool)
+	if maintenance.Enabled {
+		maintenance.Message, _ = maintenanceData["message"].(string)
+		maintenance.EnabledBy, _ = maintenanceData["userId"].(string)
+		if since, ok := maintenanceData["since"].(time.Time); ok {
+			maintenance.Since = &since
+		}
+	}
+	if h.maintenance != nil {
+		h.maintenance.Apply(maintenance)
+	}
+
+	h.logger.Info("🚧 Maintenance mode changed", "enabled", maintenance.Enabled)
+	h.manager.BroadcastToAll(maintenanceMessage(maintenance))
+}
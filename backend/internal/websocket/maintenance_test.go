@@ -0,0 +1,82 @@
+package websocket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newMaintenanceTestHandler() (*Handler, *services.MaintenanceService) {
+	mockRateLimiter := new(MockRateLimiter)
+	mockRateLimiter.On("CheckRateLimit", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	handler := NewHandler(new(MockSessionService), mockRateLimiter, nil, new(MockPOIService))
+	maintenance := services.NewMaintenanceService(nil)
+	handler.SetMaintenance(maintenance)
+	return handler, maintenance
+}
+
+func TestHandler_Maintenance_RejectsWrites(t *testing.T) {
+	handler, maintenance := newMaintenanceTestHandler()
+	defer handler.manager.Shutdown()
+	client := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 10)}
+	maintenance.Apply(services.Maintenance{Enabled: true})
+
+	handler.handleMessage(client, Message{
+		Type: "avatar_move",
+		Data: map[string]interface{}{"position": map[string]interface{}{"lat": 40.7128, "lng": -74.0060}},
+	})
+	data := receiveError(t, client)
+	assert.Equal(t, "MAINTENANCE", data["code"])
+	assert.Equal(t, "avatar_move", data["messageType"])
+
+	// Heartbeats aren't stored and keep working
+	assert.False(t, handler.rejectIfMaintenance(context.Background(), client, "heartbeat"))
+
+	maintenance.Apply(services.Maintenance{})
+	assert.False(t, handler.rejectIfMaintenance(context.Background(), client, "avatar_move"))
+}
+
+func TestHandler_MaintenanceEvent_AppliesAndBroadcasts(t *testing.T) {
+	handler, maintenance := newMaintenanceTestHandler()
+	defer handler.manager.Shutdown()
+	client1 := &Client{SessionID: "session-1", MapID: "map-1", Send: make(chan Message, 4)}
+	client2 := &Client{SessionID: "session-2", MapID: "map-2", Send: make(chan Message, 4)}
+	handler.manager.registerClient(client1)
+	handler.manager.registerClient(client2)
+
+	since := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	handler.handleMaintenanceEvent(map[string]interface{}{
+		"enabled": true,
+		"message": "Upgrading the database",
+		"userId":  "admin-1",
+		"since":   since,
+	})
+
+	status := maintenance.Status()
+	assert.True(t, status.Enabled)
+	assert.Equal(t, "Upgrading the database", status.Message)
+	require.NotNil(t, status.Since)
+	assert.Equal(t, since, *status.Since)
+
+	// Every client gets the banner, whatever its map
+	for _, client := range []*Client{client1, client2} {
+		select {
+		case msg := <-client.Send:
+			assert.Equal(t, "maintenance", msg.Type)
+			data := msg.Data.(map[string]interface{})
+			assert.Equal(t, true, data["enabled"])
+			assert.Equal(t, "Upgrading the database", data["message"])
+		case <-time.After(100 * time.Millisecond):
+			t.Fatal("Expected maintenance broadcast not received")
+		}
+	}
+
+	handler.handleMaintenanceEvent(map[string]interface{}{"enabled": false, "userId": "admin-1"})
+	assert.False(t, maintenance.IsEnabled())
+}
@@ -1,18 +1,65 @@
 package websocket
 
 import (
+	"fmt"
 	"log/slog"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"breakoutglobe/internal/models"
+	
+	"github.com/google/uuid"
 )
 
 // Manager manages WebSocket client connections
 type Manager struct {
-	clients    map[string]*Client // sessionID -> Client
-	mapClients map[string]map[string]*Client // mapID -> sessionID -> Client
+	clients    map[string]*Client // connection key -> Client
+	mapClients map[string]map[string]*Client // mapID -> connection key -> Client
+	// userConnections counts the open connections of each user per map, so
+	// users with several tabs join once and leave with their last tab
+	userConnections map[string]map[string]int // mapID -> userID -> connections
+	// topics holds the subscribers of each topic, see topics.go
+	// presenting holds the users who present, see presenting.go
+	presenting map[string]bool // userID -> presenting
+	// follows holds each map's follow me mode, see follow.go
+	follows    map[string]*followState // mapID -> follow me mode
+	topics     map[string]map[string]*Client // topic -> connection key -> Client
 	register   chan *Client
 	unregister chan *Client
 	broadcast  chan BroadcastMessage
+	// stop ends the main loop on shutdown
+	stop       chan struct{}
 	mutex      sync.RWMutex
+	// journalWriter appends broadcasts to the event journal, if one is set,
+	// and hands them back on journaled. journalPending counts the broadcasts
+	// of each map it holds.
+	journalWriter  *journalWriter
+	journaled      chan journalEntry
+	journalPending map[string]int
+	// journalEpoch identifies this process's journal sequence numbers to
+	// reconnecting clients. journalHeads and journalGaps track the last journaled
+	// and the last replayable sequence number per map.
+	journalEpoch string
+	journalHeads map[string]uint64
+	journalGaps  map[string]uint64
+	// positions coalesces avatar moves when position batching is enabled
+	positions  *positionBatcher
+	// reaper closes connections without heartbeats, if a timeout is set
+	reaper     *heartbeatReaper
+	// idle shows users without activity as away, if a timeout is set, and
+	// away holds who is away
+	idle       *idleDetector
+	away       map[string]map[string]bool // mapID -> userID -> away
+	// shedder degrades broadcasts under overload, if load shedding is enabled,
+	// and degraded is set while it does
+	shedder    *loadShedder
+	degraded   atomic.Bool
+	// presence holds each map's last map_presence snapshot
+	presence   map[string]*mapPresence
+	// stats counts broadcasts for the stats endpoint and metrics
+	stats      hubStats
 	logger     *slog.Logger
 }
 
@@ -28,9 +75,21 @@ func NewManager() *Manager {
 	manager := &Manager{
 		clients:    make(map[string]*Client),
 		mapClients: make(map[string]map[string]*Client),
+		userConnections: make(map[string]map[string]int),
+		topics:     make(map[string]map[string]*Client),
+		presenting: make(map[string]bool),
+		follows:    make(map[string]*followState),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
 		broadcast:  make(chan BroadcastMessage, 100), // Buffer for 100 messages
+		stop:       make(chan struct{}),
+		journaled:      make(chan journalEntry),
+		journalPending: make(map[string]int),
+		journalEpoch: uuid.New().String(),
+		journalHeads: make(map[string]uint64),
+		journalGaps:  make(map[string]uint64),
+		presence:     make(map[string]*mapPresence),
+		away:         make(map[string]map[string]bool),
 		logger:     slog.Default(),
 	}
 	
@@ -42,6 +101,9 @@ func NewManager() *Manager {
 func (m *Manager) run() {
 	for {
 		select {
+		case <-m.stop:
+			return
+			
 		case client := <-m.register:
 			m.registerClient(client)
 			
@@ -50,31 +112,46 @@ func (m *Manager) run() {
 			
 		case broadcastMsg := <-m.broadcast:
 			m.broadcastToMap(broadcastMsg)
+			
+		case entry := <-m.journaled:
+			m.deliverJournaled(entry)
 		}
 	}
 }
 
 // RegisterClient registers a new client connection
 func (m *Manager) RegisterClient(client *Client) {
-	m.register <- client
+	select {
+	case m.register <- client:
+	case <-m.stop:
+	}
 }
 
-// UnregisterClient unregisters a client connection
+// UnregisterClient unregisters a client connection. Connections closing after
+// shutdown are already gone.
 func (m *Manager) UnregisterClient(client *Client) {
-	m.unregister <- client
+	select {
+	case m.unregister <- client:
+	case <-m.stop:
+	}
 }
 
 // BroadcastToMap broadcasts a message to all clients in a specific map
 func (m *Manager) BroadcastToMap(mapID string, message Message) error {
+	if m.shed(message) {
+		return nil
+	}
+	
 	broadcastMsg := BroadcastMessage{
 		MapID:   mapID,
-		Message: message,
+		Message: stampPublished(message),
 	}
 	
 	select {
 	case m.broadcast <- broadcastMsg:
 		return nil
 	default:
+		m.stats.droppedBroadcasts.Add(1)
 		m.logger.Warn("Broadcast channel full, dropping message", 
 			"mapId", mapID, 
 			"messageType", message.Type)
@@ -84,9 +161,13 @@ func (m *Manager) BroadcastToMap(mapID string, message Message) error {
 
 // BroadcastToMapExcept broadcasts a message to all clients in a map except one
 func (m *Manager) BroadcastToMapExcept(mapID, exceptSessionID string, message Message) error {
+	if m.shed(message) {
+		return nil
+	}
+	
 	broadcastMsg := BroadcastMessage{
 		MapID:    mapID,
-		Message:  message,
+		Message:  stampPublished(message),
 		ExceptID: exceptSessionID,
 	}
 	
@@ -94,6 +175,7 @@ func (m *Manager) BroadcastToMapExcept(mapID, exceptSessionID string, message Me
 	case m.broadcast <- broadcastMsg:
 		return nil
 	default:
+		m.stats.droppedBroadcasts.Add(1)
 		m.logger.Warn("Broadcast channel full, dropping message", 
 			"mapId", mapID, 
 			"messageType", message.Type)
@@ -103,40 +185,153 @@ func (m *Manager) BroadcastToMapExcept(mapID, exceptSessionID string, message Me
 
 // BroadcastToAll broadcasts a message to all connected clients
 func (m *Manager) BroadcastToAll(message Message) error {
-	m.mutex.RLock()
-	defer m.mutex.RUnlock()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
 	
+	message = stampPublished(message)
+	m.stats.recordBroadcast(time.Now())
 	for _, client := range m.clients {
-		select {
-		case client.Send <- message:
-		default:
-			// Client's send channel is full, close it
+		if !m.deliver(client, message) {
 			m.logger.Warn("Client send channel full, closing connection", 
 				"sessionId", client.SessionID)
-			
-			// Close channel safely
-			select {
-			case <-client.Send:
-				// Channel is already closed
-			default:
-				close(client.Send)
-			}
-			
-			delete(m.clients, client.SessionID)
-			
-			// Remove from map clients
-			if mapClients, exists := m.mapClients[client.MapID]; exists {
-				delete(mapClients, client.SessionID)
-				if len(mapClients) == 0 {
-					delete(m.mapClients, client.MapID)
-				}
-			}
+			m.evictSlowConsumer(client)
 		}
 	}
 	
 	return nil
 }
 
+// deliver numbers a broadcast and queues it in the client's lane for its
+// priority, following the map's slow consumer policy. It returns false if the client fell too far behind and must be evicted.
+func (m *Manager) deliver(client *Client, message Message) bool {
+	// Dropped broadcasts use up their number too, so the client sees the gap
+	if message.coarseData != nil && !client.isFacilitator() {
+		message.Data = message.coarseData
+	}
+	
+	priority := priorityOf(message.Type)
+	message.Seq = client.sequences[priority].Add(1)
+	message.Lane = priority.String()
+	
+	lane := client.laneFor(priority)
+	select {
+	case lane <- message:
+		m.stats.delivered.Add(1)
+		client.droppedMessages.Store(0)
+		if len(lane)*laggingQueueDenominator >= cap(lane)*laggingQueueNumerator {
+			m.warnLagging(client, priority, lane)
+		}
+		return true
+	default:
+	}
+	
+	m.stats.droppedDeliveries.Add(1)
+	policy := client.slowConsumer
+	switch policy.DropPolicy {
+	case models.SlowConsumerDropNewest:
+		// The broadcast is discarded for this client
+	case models.SlowConsumerDropOldest:
+		select {
+		case <-lane:
+		default:
+		}
+		select {
+		case lane <- message:
+		default:
+		}
+	case models.SlowConsumerDropMovement:
+		// Only moves are discarded; the next move of the avatar supersedes them
+		if priority != priorityMovement {
+			return false
+		}
+	default:
+		return false
+	}
+	
+	dropped := client.droppedMessages.Add(1)
+	client.recordSlowConsumerEvent(slowConsumerDropped)
+	m.warnLagging(client, priority, lane)
+	return policy.EvictAfter == 0 || int(dropped) < policy.EvictAfter
+}
+
+// evictSlowConsumer closes the connection of a client that can't keep up with
+// broadcasts. The caller must hold the write lock.
+func (m *Manager) evictSlowConsumer(client *Client) {
+	client.setCloseCause(&closeCause{
+		code:     CloseCodeSlowConsumer,
+		reason:   CloseReasonSlowConsumer,
+		err:      fmt.Errorf("send buffer full, %d messages dropped in a row", client.droppedMessages.Load()),
+		sendable: true,
+	})
+	
+	client.recordSlowConsumerEvent(slowConsumerEvicted)
+	m.closeClient(client)
+}
+
+// closeClient removes a registered client and closes its send channel, which
+// makes the write pump send the close frame of its close cause and end the
+// connection. The caller must hold the write lock.
+func (m *Manager) closeClient(client *Client) {
+	// Registered clients always have an open send channel
+	close(client.Send)
+	delete(m.clients, client.connectionKey())
+	m.removeSubscriptions(client)
+	
+	// A queued move would bring back the avatar after user_left
+	if m.positions != nil {
+		m.positions.remove(client.MapID, client.SessionID)
+	}
+	
+	if mapClients, exists := m.mapClients[client.MapID]; exists {
+		delete(mapClients, client.connectionKey())
+		if len(mapClients) == 0 {
+			delete(m.mapClients, client.MapID)
+			delete(m.presence, client.MapID)
+		}
+	}
+	m.endFollowIfLeaderLeft(client)
+}
+
+// disconnectClient ends the connection of a client for the given cause. The
+// read pump then broadcasts user_left as for any other disconnect. It returns
+// false if the client already disconnected.
+func (m *Manager) disconnectClient(client *Client, cause *closeCause) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	
+	if m.clients[client.connectionKey()] != client {
+		return false
+	}
+	m.endClient(client, cause)
+	return true
+}
+
+// endClient tells a client why its connection ends with disconnect_reason and
+// closes it. The caller must hold the write lock.
+func (m *Manager) endClient(client *Client, cause *closeCause) {
+	client.setCloseCause(cause)
+	if cause := client.getCloseCause(); cause.sendable {
+		// Queued behind what the client still has to read, so it's the last message
+		select {
+		case client.Send <- disconnectReasonMessage(cause):
+		default:
+		}
+	}
+	m.closeClient(client)
+}
+
+// connectedClients returns the connected clients
+func (m *Manager) connectedClients() []*Client {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	
+	clients := make([]*Client, 0, len(m.clients))
+	for _, client := range m.clients {
+		clients = append(clients, client)
+	}
+	return clients
+}
+
 // GetConnectedClients returns the number of connected clients
 func (m *Manager) GetConnectedClients() int {
 	m.mutex.RLock()
@@ -155,13 +350,17 @@ func (m *Manager) GetMapClients(mapID string) int {
 	return 0
 }
 
-// IsClientConnected checks if a client is connected
+// IsClientConnected checks if a session has a connected client
 func (m *Manager) IsClientConnected(sessionID string) bool {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 	
-	_, exists := m.clients[sessionID]
-	return exists
+	for _, client := range m.clients {
+		if client.SessionID == sessionID {
+			return true
+		}
+	}
+	return false
 }
 
 // GetClientMaps returns all map IDs that have connected clients
@@ -176,19 +375,119 @@ func (m *Manager) GetClientMaps() []string {
 	return maps
 }
 
-// GetMapClientSessions returns all session IDs for clients in a specific map
+// GetMapClientSessions returns the distinct session IDs of clients in a specific map
 func (m *Manager) GetMapClientSessions(mapID string) []string {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 	
-	if mapClients, exists := m.mapClients[mapID]; exists {
-		sessions := make([]string, 0, len(mapClients))
-		for sessionID := range mapClients {
-			sessions = append(sessions, sessionID)
+	seen := make(map[string]bool)
+	sessions := []string{}
+	for _, client := range m.mapClients[mapID] {
+		if !seen[client.SessionID] {
+			seen[client.SessionID] = true
+			sessions = append(sessions, client.SessionID)
 		}
-		return sessions
 	}
-	return []string{}
+	return sessions
+}
+
+// GetMapClientUserIDs returns the distinct user IDs of clients in a specific map
+func (m *Manager) GetMapClientUserIDs(mapID string) []string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	
+	seen := make(map[string]bool)
+	userIDs := []string{}
+	for _, client := range m.mapClients[mapID] {
+		if !seen[client.UserID] {
+			seen[client.UserID] = true
+			userIDs = append(userIDs, client.UserID)
+		}
+	}
+	return userIDs
+}
+
+// mapClientUserIDsBySession returns the user ID of every client in a specific map by session ID
+func (m *Manager) mapClientUserIDsBySession(mapID string) map[string]string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	
+	userIDs := make(map[string]string, len(m.mapClients[mapID]))
+	for _, client := range m.mapClients[mapID] {
+		userIDs[client.SessionID] = client.UserID
+	}
+	return userIDs
+}
+
+// GetMapFacilitatorUserIDs returns the distinct user IDs of admins connected to a specific map
+func (m *Manager) GetMapFacilitatorUserIDs(mapID string) []string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	
+	seen := make(map[string]bool)
+	userIDs := []string{}
+	for _, client := range m.mapClients[mapID] {
+		if client.role != models.UserRoleAdmin && client.role != models.UserRoleSuperAdmin {
+			continue
+		}
+		if !seen[client.UserID] {
+			seen[client.UserID] = true
+			userIDs = append(userIDs, client.UserID)
+		}
+	}
+	return userIDs
+}
+
+// SetUserRole updates the role of a user's clients in a specific map
+func (m *Manager) SetUserRole(mapID, userID string, role models.UserRole) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	
+	for _, client := range m.mapClients[mapID] {
+		if client.UserID == userID {
+			client.role = role
+		}
+	}
+	
+	// Only facilitators lead follow me mode
+	if role != models.UserRoleAdmin && role != models.UserRoleSuperAdmin {
+		m.endFollowOf(mapID, userID, followStoppedRole)
+	}
+}
+
+// setClientRole sets the role of a client that may not be registered yet
+func (m *Manager) setClientRole(client *Client, role models.UserRole) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	
+	client.role = role
+}
+
+// ListConnections returns the live connections sorted by connection time,
+// optionally limited to one map
+func (m *Manager) ListConnections(mapID string) []models.ConnectionInfo {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	
+	connections := []models.ConnectionInfo{}
+	for _, client := range m.clients {
+		if mapID != "" && client.MapID != mapID {
+			continue
+		}
+		connections = append(connections, models.ConnectionInfo{
+			SessionID:     client.SessionID,
+			UserID:        client.UserID,
+			MapID:         client.MapID,
+			ClientVersion: client.ClientVersion,
+			UserAgent:     client.UserAgent,
+			ConnectedAt:   client.ConnectedAt,
+		})
+	}
+	
+	sort.Slice(connections, func(i, j int) bool {
+		return connections[i].ConnectedAt.Before(connections[j].ConnectedAt)
+	})
+	return connections
 }
 
 // registerClient handles client registration
@@ -197,18 +496,23 @@ func (m *Manager) registerClient(client *Client) {
 	defer m.mutex.Unlock()
 	
 	// Add to clients map
-	m.clients[client.SessionID] = client
+	m.clients[client.connectionKey()] = client
+	client.lastHeartbeat.CompareAndSwap(0, time.Now().UnixNano())
+	client.lastActivity.CompareAndSwap(0, time.Now().UnixNano())
+	
+	// Opening another tab makes an away user active
+	m.markActive(client.MapID, client.UserID)
 	
 	// Add to map clients
 	if m.mapClients[client.MapID] == nil {
 		m.mapClients[client.MapID] = make(map[string]*Client)
 	}
-	m.mapClients[client.MapID][client.SessionID] = client
+	m.mapClients[client.MapID][client.connectionKey()] = client
 	
 	// Log all clients in this map for debugging
 	var mapClientSessions []string
-	for sessionID := range m.mapClients[client.MapID] {
-		mapClientSessions = append(mapClientSessions, sessionID)
+	for _, mapClient := range m.mapClients[client.MapID] {
+		mapClientSessions = append(mapClientSessions, mapClient.SessionID)
 	}
 	
 	m.logger.Info("✅ Client registered", 
@@ -225,19 +529,28 @@ func (m *Manager) unregisterClient(client *Client) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 	
-	// Remove from clients map
-	if _, exists := m.clients[client.SessionID]; exists {
-		delete(m.clients, client.SessionID)
-		close(client.Send)
+	// Remove from clients map; clients already evicted are gone
+	if m.clients[client.connectionKey()] != client {
+		return
+	}
+	delete(m.clients, client.connectionKey())
+	close(client.Send)
+	m.removeSubscriptions(client)
+	
+	// A queued move would bring back the avatar after user_left
+	if m.positions != nil {
+		m.positions.remove(client.MapID, client.SessionID)
 	}
 	
 	// Remove from map clients
 	if mapClients, exists := m.mapClients[client.MapID]; exists {
-		delete(mapClients, client.SessionID)
+		delete(mapClients, client.connectionKey())
 		if len(mapClients) == 0 {
 			delete(m.mapClients, client.MapID)
+			delete(m.presence, client.MapID)
 		}
 	}
+	m.endFollowIfLeaderLeft(client)
 	
 	m.logger.Info("Client unregistered", 
 		"sessionId", client.SessionID, 
@@ -246,10 +559,27 @@ func (m *Manager) unregisterClient(client *Client) {
 		"totalClients", len(m.clients))
 }
 
-// broadcastToMap handles broadcasting messages to a specific map
+// broadcastToMap handles broadcasting messages to a specific map, after the
+// journal writer journaled them if there is an event journal
 func (m *Manager) broadcastToMap(broadcastMsg BroadcastMessage) {
-	m.mutex.RLock()
-	defer m.mutex.RUnlock()
+	if m.queueForJournal(broadcastMsg) {
+		return
+	}
+	m.deliverToMap(broadcastMsg)
+}
+
+// deliverToMap sends a broadcast to the clients of its map and its topics' subscribers
+func (m *Manager) deliverToMap(broadcastMsg BroadcastMessage) {
+	// Evicting slow consumers modifies the client maps
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	
+	// Subscribers of the broadcast's topics get it unless they did as clients of the map
+	var delivered map[*Client]bool
+	if len(broadcastMsg.Message.topics) > 0 {
+		delivered = make(map[*Client]bool)
+		defer m.deliverToTopics(broadcastMsg, delivered)
+	}
 	
 	mapClients, exists := m.mapClients[broadcastMsg.MapID]
 	if !exists {
@@ -258,6 +588,7 @@ func (m *Manager) broadcastToMap(broadcastMsg BroadcastMessage) {
 			"messageType", broadcastMsg.Message.Type)
 		return
 	}
+	m.stats.recordBroadcast(time.Now())
 	
 	totalClients := len(mapClients)
 	eligibleClients := 0
@@ -265,8 +596,8 @@ func (m *Manager) broadcastToMap(broadcastMsg BroadcastMessage) {
 	failedCount := 0
 	
 	// Count eligible clients (excluding sender)
-	for sessionID := range mapClients {
-		if broadcastMsg.ExceptID == "" || sessionID != broadcastMsg.ExceptID {
+	for _, client := range mapClients {
+		if broadcastMsg.ExceptID == "" || client.SessionID != broadcastMsg.ExceptID {
 			eligibleClients++
 		}
 	}
@@ -278,7 +609,8 @@ func (m *Manager) broadcastToMap(broadcastMsg BroadcastMessage) {
 		"eligibleClients", eligibleClients,
 		"exceptId", broadcastMsg.ExceptID)
 	
-	for sessionID, client := range mapClients {
+	for _, client := range mapClients {
+		sessionID := client.SessionID
 		// Skip the excluded session if specified
 		if broadcastMsg.ExceptID != "" && sessionID == broadcastMsg.ExceptID {
 			m.logger.Debug("⏭️ Skipping sender client", 
@@ -287,38 +619,36 @@ func (m *Manager) broadcastToMap(broadcastMsg BroadcastMessage) {
 			continue
 		}
 		
+		// Position-bound broadcasts only go to clients displaying that area
+		if !client.inViewport(broadcastMsg.Message) {
+			continue
+		}
+		
 		m.logger.Debug("📤 Attempting to send message to client", 
 			"sessionId", sessionID,
 			"userId", client.UserID,
 			"mapId", broadcastMsg.MapID,
 			"messageType", broadcastMsg.Message.Type)
 		
-		select {
-		case client.Send <- broadcastMsg.Message:
+		if m.deliver(client, broadcastMsg.Message) {
 			sentCount++
+			if delivered != nil {
+				delivered[client] = true
+			}
 			m.logger.Debug("✅ Message sent successfully to client", 
 				"sessionId", sessionID,
 				"userId", client.UserID,
 				"messageType", broadcastMsg.Message.Type)
-		default:
+		} else {
 			failedCount++
-			// Client's send channel is full, close it
+			// Client fell too far behind, close it
 			m.logger.Warn("❌ Client send channel full during broadcast, closing connection", 
 				"sessionId", client.SessionID,
 				"userId", client.UserID,
 				"mapId", broadcastMsg.MapID,
 				"messageType", broadcastMsg.Message.Type)
 			
-			// Close channel safely
-			select {
-			case <-client.Send:
-				// Channel is already closed
-			default:
-				close(client.Send)
-			}
-			
-			delete(m.clients, client.SessionID)
-			delete(mapClients, client.SessionID)
+			m.evictSlowConsumer(client)
 		}
 	}
 	
@@ -355,43 +685,80 @@ func (m *Manager) Shutdown() {
 	// Clear maps
 	m.clients = make(map[string]*Client)
 	m.mapClients = make(map[string]map[string]*Client)
+	m.userConnections = make(map[string]map[string]int)
+	
+	if m.positions != nil {
+		close(m.positions.stop)
+		m.positions = nil
+	}
+	if m.reaper != nil {
+		close(m.reaper.stop)
+		m.reaper = nil
+	}
+	if m.idle != nil {
+		close(m.idle.stop)
+		m.idle = nil
+	}
+	m.away = make(map[string]map[string]bool)
+	if m.shedder != nil {
+		close(m.shedder.stop)
+		m.shedder = nil
+	}
+	if m.journalWriter != nil {
+		close(m.journalWriter.stop)
+		m.journalWriter = nil
+	}
+	m.journalPending = make(map[string]int)
+	if m.stop != nil {
+		select {
+		case <-m.stop:
+		default:
+			close(m.stop)
+		}
+	}
 	
 	m.logger.Info("WebSocket manager shutdown complete")
 }
 
-// BroadcastToUser sends a message to a specific user by their user ID
+// BroadcastToUser sends a message to every connection of a user, like all of
+// their tabs, except those of the given session
 func (m *Manager) BroadcastToUser(userID string, message Message, exceptSessionID string) {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 	
-	var targetClient *Client
+	if m.withheldWhilePresenting(userID, message) {
+		m.logger.Debug("🖥️ Withholding notification from presenting user",
+			"targetUserId", userID,
+			"messageType", message.Type)
+		return
+	}
 	
-	// Find the client with the matching user ID
-	for sessionID, client := range m.clients {
-		if client.UserID == userID && sessionID != exceptSessionID {
-			targetClient = client
-			break
+	sent := 0
+	for _, client := range m.clients {
+		if client.UserID != userID || client.SessionID == exceptSessionID {
+			continue
+		}
+		
+		select {
+		case client.lane(message) <- message:
+			sent++
+		default:
+			m.logger.Warn("📨 Failed to send message to user (channel full)", 
+				"targetUserId", userID,
+				"targetSessionId", client.SessionID,
+				"messageType", message.Type)
 		}
 	}
 	
-	if targetClient == nil {
+	if sent == 0 {
 		m.logger.Warn("🚫 Target user not found for message", 
 			"targetUserId", userID, 
 			"messageType", message.Type)
 		return
 	}
 	
-	// Send message to target user
-	select {
-	case targetClient.Send <- message:
-		m.logger.Info("📨 Message sent to user", 
-			"targetUserId", userID,
-			"targetSessionId", targetClient.SessionID,
-			"messageType", message.Type)
-	default:
-		m.logger.Warn("📨 Failed to send message to user (channel full)", 
-			"targetUserId", userID,
-			"targetSessionId", targetClient.SessionID,
-			"messageType", message.Type)
-	}
-}
\ No newline at end of file
+	m.logger.Info("📨 Message sent to user", 
+		"targetUserId", userID,
+		"connections", sent,
+		"messageType", message.Type)
+}
@@ -0,0 +1,89 @@
+package websocket
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+// newBenchmarkManager creates a manager whose main loop is not running, so
+// benchmarks can call broadcastToMap synchronously and time the fan-out itself
+func newBenchmarkManager() *Manager {
+	return &Manager{
+		clients:    make(map[string]*Client),
+		mapClients: make(map[string]map[string]*Client),
+		broadcast:  make(chan BroadcastMessage, 100),
+		logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+}
+
+// benchmarkDrainInterval is how many broadcasts are sent before client
+// channels are drained; it must stay below the send buffer size
+const benchmarkDrainInterval = 128
+
+// addBenchmarkClients registers clients on a map and returns their send channels
+func addBenchmarkClients(m *Manager, mapID string, count int) []chan Message {
+	channels := make([]chan Message, count)
+	for i := 0; i < count; i++ {
+		client := &Client{
+			SessionID: fmt.Sprintf("session-%d", i),
+			UserID:    fmt.Sprintf("user-%d", i),
+			MapID:     mapID,
+			Send:      make(chan Message, 256),
+			Manager:   m,
+		}
+		m.registerClient(client)
+		channels[i] = client.Send
+	}
+	return channels
+}
+
+// drainBenchmarkClients empties client channels, standing in for writePump
+func drainBenchmarkClients(channels []chan Message) {
+	for _, send := range channels {
+		for len(send) > 0 {
+			<-send
+		}
+	}
+}
+
+func BenchmarkBroadcastToMap(b *testing.B) {
+	for _, clients := range []int{1, 100, 5000} {
+		b.Run(fmt.Sprintf("clients=%d", clients), func(b *testing.B) {
+			manager := newBenchmarkManager()
+			channels := addBenchmarkClients(manager, "bench-map", clients)
+
+			message := Message{
+				Type: "avatar_moved",
+				Data: map[string]interface{}{
+					"sessionId": "session-0",
+					"userId":    "user-0",
+					"position":  map[string]float64{"lat": 52.52, "lng": 13.405},
+				},
+				Timestamp: time.Now(),
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if i > 0 && i%benchmarkDrainInterval == 0 {
+					b.StopTimer()
+					drainBenchmarkClients(channels)
+					b.StartTimer()
+				}
+				manager.broadcastToMap(BroadcastMessage{
+					MapID:   "bench-map",
+					Message: message,
+				})
+			}
+			b.StopTimer()
+
+			// Full channels would drop clients and make later iterations cheaper
+			if connected := manager.GetMapClients("bench-map"); connected != clients {
+				b.Fatalf("expected %d clients after benchmark, got %d", clients, connected)
+			}
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"testing"
 	"time"
 
+	"breakoutglobe/internal/models"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
@@ -389,6 +391,59 @@ func (suite *ManagerTestSuite) TestShutdown() {
 	suite.False(suite.manager.IsClientConnected("session-2"))
 }
 
+func (suite *ManagerTestSuite) TestBroadcast_SlowConsumerPolicy() {
+	// Disconnects on a full buffer, as maps do by default
+	strict := &Client{
+		SessionID: "session-strict",
+		UserID:    "user-1",
+		MapID:     "map-1",
+		Send:      make(chan Message, 1),
+	}
+	// Keeps the latest messages and is evicted after three drops in a row
+	webinar := &Client{
+		SessionID: "session-webinar",
+		UserID:    "user-2",
+		MapID:     "map-1",
+		Send:      make(chan Message, 1),
+		slowConsumer: models.SlowConsumerPolicy{DropPolicy: models.SlowConsumerDropOldest, EvictAfter: 3},
+	}
+	// Never evicted
+	lenient := &Client{
+		SessionID: "session-lenient",
+		UserID:    "user-3",
+		MapID:     "map-1",
+		Send:      make(chan Message, 1),
+		slowConsumer: models.SlowConsumerPolicy{DropPolicy: models.SlowConsumerDropNewest},
+	}
+	
+	for _, client := range []*Client{strict, webinar, lenient} {
+		suite.manager.RegisterClient(client)
+	}
+	time.Sleep(10 * time.Millisecond)
+	
+	send := func(messageType string) {
+		suite.manager.broadcastToMap(BroadcastMessage{MapID: "map-1", Message: Message{Type: messageType}})
+	}
+	
+	send("first")
+	send("second")
+	suite.False(suite.manager.IsClientConnected("session-strict"))
+	suite.Equal(CloseReasonSlowConsumer, strict.getCloseCause().reason)
+	suite.True(suite.manager.IsClientConnected("session-webinar"))
+	suite.Equal("second", (<-webinar.Send).Type)
+	suite.Equal("first", (<-lenient.Send).Type)
+	
+	// Draining the buffer resets the count of dropped messages
+	send("third")
+	send("fourth")
+	send("fifth")
+	suite.True(suite.manager.IsClientConnected("session-webinar"))
+	send("sixth")
+	suite.False(suite.manager.IsClientConnected("session-webinar"))
+	suite.True(suite.manager.IsClientConnected("session-lenient"))
+	suite.Equal(1, suite.manager.GetMapClients("map-1"))
+}
+
 func TestManagerTestSuite(t *testing.T) {
 	suite.Run(t, new(ManagerTestSuite))
 }
@@ -405,4 +460,23 @@ func TestNewManager(t *testing.T) {
 	
 	// Cleanup
 	manager.Shutdown()
-}
\ No newline at end of file
+}
+func TestManager_Shutdown_StopsMainLoop(t *testing.T) {
+	manager := NewManager()
+	manager.Shutdown()
+	manager.Shutdown()
+
+	// Nothing receives on the main loop's channels anymore, and clients closing late don't block
+	done := make(chan struct{})
+	go func() {
+		client := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 1)}
+		manager.RegisterClient(client)
+		manager.UnregisterClient(client)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("registering after shutdown blocked")
+	}
+}
@@ -0,0 +1,184 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"breakoutglobe/internal/services"
+)
+
+// MapFreezeInterface defines the interface for facilitator map freezes
+type MapFreezeInterface interface {
+	Freeze(ctx context.Context, mapID, userID string, duration time.Duration) (services.MapFreeze, error)
+	Unfreeze(ctx context.Context, mapID, userID string) error
+	GetFreeze(mapID string) (services.MapFreeze, bool)
+	ApplyFrozen(freeze services.MapFreeze)
+	ApplyUnfrozen(mapID string)
+}
+
+// frozenMessageTypes are rejected with MAP_FROZEN while the client's map is frozen
+var frozenMessageTypes = map[string]bool{
+	"avatar_move":       true,
+	"avatar_move_batch": true,
+	"poi_join":          true,
+	"poi_leave":         true,
+}
+
+// SetMapFreeze enables the map_freeze and map_unfreeze facilitator commands.
+// Without it, maps can't be frozen.
+func (h *Handler) SetMapFreeze(freeze MapFreezeInterface) {
+	h.mapFreeze = freeze
+}
+
+// mapFrozen returns the current freeze of a map, if it is frozen
+func (h *Handler) mapFrozen(mapID string) (services.MapFreeze, bool) {
+	if h.mapFreeze == nil {
+		return services.MapFreeze{}, false
+	}
+	return h.mapFreeze.GetFreeze(mapID)
+}
+
+// rejectIfFrozen tells the client that a message can't be handled while its
+// map is frozen. It returns true if the message was rejected.
+func (h *Handler) rejectIfFrozen(ctx context.Context, client *Client, messageType string) bool {
+	if !frozenMessageTypes[messageType] {
+		return false
+	}
+	freeze, frozen := h.mapFrozen(client.MapID)
+	if !frozen {
+		return false
+	}
+
+	select {
+	case client.Send <- replyTo(ctx, Message{
+		Type: "error",
+		Data: map[string]interface{}{
+			"code":        "MAP_FROZEN",
+			"message":     "The map is frozen by a facilitator",
+			"messageType": messageType,
+			"until":       freeze.Until,
+		},
+		Timestamp: time.Now(),
+	}):
+	default:
+		h.requestLogger(ctx).Warn("Failed to send map frozen error", "sessionId", client.SessionID)
+	}
+	return true
+}
+
+// handleMapFreeze freezes the client's map for the requested number of seconds,
+// or the default duration. Only facilitators can freeze a map.
+func (h *Handler) handleMapFreeze(ctx context.Context, client *Client, msg Message) {
+	if !h.authorizeMapFreeze(ctx, client) {
+		return
+	}
+
+	var payload MapFreezePayload
+	if msg.Data != nil && !h.decodeMessagePayload(client, msg, &payload) {
+		return
+	}
+	var duration time.Duration
+	if payload.DurationSeconds != nil {
+		duration = time.Duration(*payload.DurationSeconds * float64(time.Second))
+	}
+
+	freeze, err := h.mapFreeze.Freeze(ctx, client.MapID, client.UserID, duration)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			h.sendErrorMessage(ctx, client, err.Error())
+			return
+		}
+		h.requestLogger(ctx).Error("Failed to freeze map", "mapId", client.MapID, "userId", client.UserID, "error", err)
+		h.sendErrorMessage(ctx, client, "Failed to freeze map")
+		return
+	}
+
+	h.requestLogger(ctx).Info("🧊 Map frozen", "mapId", client.MapID, "userId", client.UserID, "until", freeze.Until)
+}
+
+// handleMapUnfreeze ends the freeze of the client's map. Only facilitators can
+// unfreeze a map.
+func (h *Handler) handleMapUnfreeze(ctx context.Context, client *Client, msg Message) {
+	if !h.authorizeMapFreeze(ctx, client) {
+		return
+	}
+
+	if err := h.mapFreeze.Unfreeze(ctx, client.MapID, client.UserID); err != nil {
+		h.requestLogger(ctx).Error("Failed to unfreeze map", "mapId", client.MapID, "userId", client.UserID, "error", err)
+		h.sendErrorMessage(ctx, client, "Failed to unfreeze map")
+		return
+	}
+
+	h.requestLogger(ctx).Info("🧊 Map unfrozen", "mapId", client.MapID, "userId", client.UserID)
+}
+
+// authorizeMapFreeze checks that freezes are enabled and the client is a
+// facilitator, telling the client otherwise
+func (h *Handler) authorizeMapFreeze(ctx context.Context, client *Client) bool {
+	if h.mapFreeze == nil {
+		h.sendErrorMessage(ctx, client, "Map freezing is not available")
+		return false
+	}
+	if !h.manager.IsFacilitator(client) {
+		select {
+		case client.Send <- replyTo(ctx, Message{
+			Type: "error",
+			Data: map[string]interface{}{
+				"code":    "FORBIDDEN",
+				"message": "Only facilitators can freeze the map",
+			},
+			Timestamp: time.Now(),
+		}):
+		default:
+			h.requestLogger(ctx).Warn("Failed to send forbidden error", "sessionId", client.SessionID)
+		}
+		return false
+	}
+	return true
+}
+
+// handleMapFreezeEvent applies a freeze published by any instance and tells
+// the clients of the map about it
+func (h *Handler) handleMapFreezeEvent(eventType string, data interface{}) {
+	freezeData, ok := data.(map[string]interface{})
+	if !ok {
+		h.logger.Error("❌ Invalid map freeze event data", "data", data)
+		return
+	}
+
+	mapID, _ := freezeData["mapId"].(string)
+	if mapID == "" {
+		h.logger.Error("❌ Missing mapId in map freeze event", "data", data)
+		return
+	}
+
+	if h.mapFreeze != nil {
+		if eventType == "map_frozen" {
+			userID, _ := freezeData["userId"].(string)
+			until, _ := freezeData["until"].(time.Time)
+			h.mapFreeze.ApplyFrozen(services.MapFreeze{MapID: mapID, FrozenBy: userID, Until: until})
+		} else {
+			h.mapFreeze.ApplyUnfrozen(mapID)
+		}
+	}
+
+	h.manager.BroadcastToMap(mapID, Message{
+		Type:        eventType,
+		Data:        freezeData,
+		Timestamp:   time.Now(),
+		publishedAt: eventPublishedAt(freezeData),
+	})
+}
+
+// validateMapFreeze validates map freeze commands, whose duration is optional
+func validateMapFreeze(msg Message) error {
+	if msg.Data == nil {
+		return nil
+	}
+	var payload MapFreezePayload
+	if err := decodePayload(msg, &payload); err != nil {
+		return err
+	}
+	return payload.Validate()
+}
@@ -0,0 +1,103 @@
+package websocket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/redis"
+	"breakoutglobe/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type nopMapFreezePublisher struct{}
+
+func (nopMapFreezePublisher) PublishMapFrozen(ctx context.Context, event redis.MapFreezeEvent) error {
+	return nil
+}
+
+func (nopMapFreezePublisher) PublishMapUnfrozen(ctx context.Context, event redis.MapFreezeEvent) error {
+	return nil
+}
+
+func newMapFreezeTestHandler() (*Handler, *services.MapFreezeService) {
+	mockRateLimiter := new(MockRateLimiter)
+	mockRateLimiter.On("CheckRateLimit", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	handler := NewHandler(new(MockSessionService), mockRateLimiter, nil, new(MockPOIService))
+	freeze := services.NewMapFreezeService(nopMapFreezePublisher{})
+	handler.SetMapFreeze(freeze)
+	return handler, freeze
+}
+
+func receiveError(t *testing.T, client *Client) map[string]interface{} {
+	t.Helper()
+	select {
+	case msg := <-client.Send:
+		require.Equal(t, "error", msg.Type)
+		data, ok := msg.Data.(map[string]interface{})
+		require.True(t, ok)
+		return data
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Expected error not received")
+		return nil
+	}
+}
+
+func TestHandler_MapFreeze_RequiresFacilitator(t *testing.T) {
+	handler, freeze := newMapFreezeTestHandler()
+	defer handler.manager.Shutdown()
+	client := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 10), role: models.UserRoleUser}
+
+	handler.handleMessage(client, Message{Type: "map_freeze"})
+
+	assert.Equal(t, "FORBIDDEN", receiveError(t, client)["code"])
+	assert.False(t, freeze.IsFrozen("map-1"))
+}
+
+func TestHandler_MapFreeze_RejectsMovesUntilUnfrozen(t *testing.T) {
+	handler, freeze := newMapFreezeTestHandler()
+	defer handler.manager.Shutdown()
+	facilitator := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 10), role: models.UserRoleAdmin}
+	participant := &Client{SessionID: "session-2", UserID: "user-2", MapID: "map-1", Send: make(chan Message, 10), role: models.UserRoleUser}
+
+	handler.handleMessage(facilitator, Message{Type: "map_freeze", Data: map[string]interface{}{"durationSeconds": float64(60)}})
+	require.True(t, freeze.IsFrozen("map-1"))
+
+	handler.handleMessage(participant, Message{
+		Type: "avatar_move",
+		Data: map[string]interface{}{"position": map[string]interface{}{"lat": 40.7128, "lng": -74.0060}},
+	})
+	data := receiveError(t, participant)
+	assert.Equal(t, "MAP_FROZEN", data["code"])
+	assert.Equal(t, "avatar_move", data["messageType"])
+
+	handler.handleMessage(facilitator, Message{Type: "map_unfreeze"})
+	assert.False(t, freeze.IsFrozen("map-1"))
+}
+
+func TestHandler_MapFreezeEvent_AppliesAndBroadcasts(t *testing.T) {
+	handler, freeze := newMapFreezeTestHandler()
+	defer handler.manager.Shutdown()
+	client := &Client{SessionID: "session-1", MapID: "map-1", Send: make(chan Message, 4), movement: make(chan Message, 4)}
+	handler.manager.mutex.Lock()
+	handler.manager.mapClients["map-1"] = map[string]*Client{"session-1": client}
+	handler.manager.mutex.Unlock()
+
+	until := time.Now().Add(time.Minute)
+	handler.handleMapFreezeEvent("map_frozen", map[string]interface{}{"mapId": "map-1", "userId": "user-1", "until": until})
+
+	assert.True(t, freeze.IsFrozen("map-1"))
+	select {
+	case msg := <-client.Send:
+		assert.Equal(t, "map_frozen", msg.Type)
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Expected map_frozen broadcast not received")
+	}
+
+	handler.handleMapFreezeEvent("map_unfrozen", map[string]interface{}{"mapId": "map-1", "userId": "user-1"})
+	assert.False(t, freeze.IsFrozen("map-1"))
+}
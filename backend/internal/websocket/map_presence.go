@@ -0,0 +1,158 @@
+package websocket
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// presenceFullSnapshotEvery is how many presence diffs of a map are taken per
+// full snapshot. The others only carry the changes since the previous
+// broadcast and aren't sent if nothing changed.
+const presenceFullSnapshotEvery = 5
+
+// mapPresence is the presence of a map as of its last map_presence broadcast
+type mapPresence struct {
+	seq uint64
+	// sessions maps the present session IDs to their users
+	sessions map[string]string
+	// sinceFull counts the diffs since the last full snapshot
+	sinceFull int
+}
+
+// PresenceChange is the change of a map's presence since its previous
+// map_presence broadcast
+type PresenceChange struct {
+	MapID string
+	// Seq numbers the map's presence broadcasts; BaseSeq is the broadcast the
+	// change applies to
+	Seq     uint64
+	BaseSeq uint64
+	// Full is set on full snapshots, whose Joined holds every present session
+	Full   bool
+	Joined []string
+	// Left maps the sessions that left to their users
+	Left map[string]string
+}
+
+// DiffPresence records the sessions present on a map, mapped to their users,
+// as its new presence snapshot and returns the change since the previous one.
+// The first diff of a map and every presenceFullSnapshotEvery-th are full.
+// It returns false if nothing changed and no full snapshot is due.
+func (m *Manager) DiffPresence(mapID string, sessions map[string]string) (PresenceChange, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	previous, exists := m.presence[mapID]
+	if !exists {
+		previous = &mapPresence{sessions: map[string]string{}, sinceFull: presenceFullSnapshotEvery}
+		m.presence[mapID] = previous
+	}
+
+	change := PresenceChange{
+		MapID:   mapID,
+		BaseSeq: previous.seq,
+		Full:    previous.sinceFull+1 >= presenceFullSnapshotEvery,
+		Left:    map[string]string{},
+	}
+	for sessionID := range sessions {
+		if _, present := previous.sessions[sessionID]; change.Full || !present {
+			change.Joined = append(change.Joined, sessionID)
+		}
+	}
+	for sessionID, userID := range previous.sessions {
+		if _, present := sessions[sessionID]; !present {
+			change.Left[sessionID] = userID
+		}
+	}
+	sort.Strings(change.Joined)
+
+	previous.sinceFull++
+	if !change.Full && len(change.Joined) == 0 && len(change.Left) == 0 {
+		return PresenceChange{}, false
+	}
+
+	previous.seq++
+	previous.sessions = sessions
+	if change.Full {
+		previous.sinceFull = 0
+	}
+	change.Seq = previous.seq
+	return change, true
+}
+
+// GetMapClientSessionUsers returns the session IDs of clients in a specific
+// map, mapped to their users
+func (m *Manager) GetMapClientSessionUsers(mapID string) map[string]string {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	sessions := make(map[string]string, len(m.mapClients[mapID]))
+	for _, client := range m.mapClients[mapID] {
+		sessions[client.SessionID] = client.UserID
+	}
+	return sessions
+}
+
+// BroadcastMapPresence sends the presence of every map with local connections
+// to its clients, so clients that missed a user_joined or user_left can
+// reconcile their avatars. Full snapshots replace the client's avatars;
+// patches add the joined and remove the left sessions.
+func (h *Handler) BroadcastMapPresence(ctx context.Context) error {
+	for _, mapID := range h.manager.GetClientMaps() {
+		connected := h.manager.GetMapClientSessionUsers(mapID)
+		sessions := make(map[string]string, len(connected))
+		for _, sessionID := range h.presentSessions(ctx, mapID) {
+			if userID, ok := connected[sessionID]; ok {
+				sessions[sessionID] = userID
+			}
+		}
+
+		change, changed := h.manager.DiffPresence(mapID, sessions)
+		if !changed {
+			continue
+		}
+		h.manager.BroadcastToMap(mapID, h.mapPresenceMessage(ctx, change))
+	}
+
+	return nil
+}
+
+// mapPresenceMessage builds the map_presence message of a presence change.
+// It goes to every client of the map, so positions are coarse on maps with
+// position privacy.
+func (h *Handler) mapPresenceMessage(ctx context.Context, change PresenceChange) Message {
+	privacy := h.positionPrivacy(ctx, change.MapID)
+
+	users := []map[string]interface{}{}
+	for _, sessionID := range change.Joined {
+		if userData, ok := h.presenceUser(ctx, sessionID, privacy); ok {
+			users = append(users, userData)
+		}
+	}
+
+	data := map[string]interface{}{
+		"mapId": change.MapID,
+		"seq":   change.Seq,
+		"full":  change.Full,
+	}
+	if change.Full {
+		data["users"] = users
+	} else {
+		left := []map[string]interface{}{}
+		for sessionID, userID := range change.Left {
+			left = append(left, map[string]interface{}{
+				"sessionId": sessionID,
+				"userId":    userID,
+			})
+		}
+		sort.Slice(left, func(i, j int) bool {
+			return left[i]["sessionId"].(string) < left[j]["sessionId"].(string)
+		})
+		data["baseSeq"] = change.BaseSeq
+		data["joined"] = users
+		data["left"] = left
+	}
+
+	return Message{Type: "map_presence", Data: data, Timestamp: time.Now()}
+}
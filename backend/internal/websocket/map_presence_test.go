@@ -0,0 +1,98 @@
+package websocket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_DiffPresence(t *testing.T) {
+	manager := NewManager()
+	defer manager.Shutdown()
+
+	// The first snapshot of a map is full
+	change, changed := manager.DiffPresence("map-1", map[string]string{"session-1": "user-1"})
+	require.True(t, changed)
+	assert.True(t, change.Full)
+	assert.Equal(t, uint64(1), change.Seq)
+	assert.Equal(t, []string{"session-1"}, change.Joined)
+
+	// Nothing changed and no full snapshot is due
+	_, changed = manager.DiffPresence("map-1", map[string]string{"session-1": "user-1"})
+	assert.False(t, changed)
+
+	change, changed = manager.DiffPresence("map-1", map[string]string{"session-2": "user-2"})
+	require.True(t, changed)
+	assert.False(t, change.Full)
+	assert.Equal(t, uint64(1), change.BaseSeq)
+	assert.Equal(t, uint64(2), change.Seq)
+	assert.Equal(t, []string{"session-2"}, change.Joined)
+	assert.Equal(t, map[string]string{"session-1": "user-1"}, change.Left)
+
+	// A full snapshot is due every presenceFullSnapshotEvery diffs
+	for i := 0; i < presenceFullSnapshotEvery-3; i++ {
+		_, changed = manager.DiffPresence("map-1", map[string]string{"session-2": "user-2"})
+		assert.False(t, changed)
+	}
+	change, changed = manager.DiffPresence("map-1", map[string]string{"session-2": "user-2"})
+	require.True(t, changed)
+	assert.True(t, change.Full)
+	assert.Equal(t, []string{"session-2"}, change.Joined)
+}
+
+func TestHandler_BroadcastMapPresence(t *testing.T) {
+	mockSessionService := new(MockSessionService)
+	mockSessionService.On("GetSession", mock.Anything, "session-2").Return(&models.Session{
+		ID:        "session-2",
+		UserID:    "user-2",
+		MapID:     "map-1",
+		IsActive:  true,
+		AvatarPos: models.LatLng{Lat: 52.52, Lng: 13.405},
+	}, nil)
+	mockPOIService := new(MockPOIService)
+	mockPOIService.On("GetCurrentPOI", mock.Anything, mock.Anything).Return("", nil).Maybe()
+	handler := NewHandler(mockSessionService, new(MockRateLimiter), nil, mockPOIService)
+	defer handler.manager.Shutdown()
+
+	client := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 10)}
+	other := &Client{SessionID: "session-2", UserID: "user-2", MapID: "map-1", Send: make(chan Message, 10)}
+	handler.manager.registerClient(client)
+	handler.manager.registerClient(other)
+	mockSessionService.On("GetSession", mock.Anything, "session-1").Return(&models.Session{
+		ID:       "session-1",
+		UserID:   "user-1",
+		MapID:    "map-1",
+		IsActive: true,
+	}, nil)
+
+	require.NoError(t, handler.BroadcastMapPresence(context.Background()))
+	full := receivePresence(t, client)
+	assert.Equal(t, true, full["full"])
+	assert.Len(t, full["users"], 2)
+
+	handler.manager.unregisterClient(other)
+	require.NoError(t, handler.BroadcastMapPresence(context.Background()))
+	patch := receivePresence(t, client)
+	assert.Equal(t, false, patch["full"])
+	assert.Equal(t, uint64(1), patch["baseSeq"])
+	assert.Empty(t, patch["joined"])
+	assert.Equal(t, []map[string]interface{}{{"sessionId": "session-2", "userId": "user-2"}}, patch["left"])
+}
+
+func receivePresence(t *testing.T, client *Client) map[string]interface{} {
+	t.Helper()
+	select {
+	case msg := <-client.Send:
+		require.Equal(t, "map_presence", msg.Type)
+		return msg.Data.(map[string]interface{})
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Expected map_presence not received")
+		return nil
+	}
+}
@@ -0,0 +1,67 @@
+package websocket
+
+import (
+	"context"
+	"math/rand"
+
+	"breakoutglobe/internal/logexport"
+	"breakoutglobe/internal/models"
+)
+
+// Directions of audited messages
+const (
+	auditInbound  = "in"
+	auditOutbound = "out"
+)
+
+// MessageAuditPolicyProviderInterface defines the interface for per-map message audit policies
+type MessageAuditPolicyProviderInterface interface {
+	GetMessageAuditPolicy(ctx context.Context, mapID string) (models.MessageAuditPolicy, error)
+}
+
+// SetMessageAuditPolicyProvider enables exporting the WebSocket messages each
+// map's audit policy samples, received and sent, to the log sink. Without it
+// or without an event exporter, no messages are audited.
+func (h *Handler) SetMessageAuditPolicyProvider(provider MessageAuditPolicyProviderInterface) {
+	h.messageAudit = provider
+	if h.auditSample == nil {
+		h.auditSample = rand.Float64
+	}
+}
+
+// auditMessage exports the message if the audit policy of the client's map
+// samples it. All messages of flagged sessions are exported.
+func (h *Handler) auditMessage(ctx context.Context, client *Client, direction string, message Message) {
+	if h.messageAudit == nil || h.eventExporter == nil {
+		return
+	}
+
+	policy, err := h.messageAudit.GetMessageAuditPolicy(ctx, client.MapID)
+	if err != nil || !policy.Enabled() {
+		return
+	}
+	flagged := policy.IsFlagged(client.SessionID)
+	if !flagged && h.auditSample() >= policy.SampleRate {
+		return
+	}
+
+	fields := map[string]interface{}{
+		"direction":     direction,
+		"sessionId":     client.SessionID,
+		"userId":        client.UserID,
+		"mapId":         client.MapID,
+		"clientVersion": client.ClientVersion,
+		"flagged":       flagged,
+		"type":          message.Type,
+		"data":          message.Data,
+		"timestamp":     message.Timestamp,
+	}
+	if message.Seq != 0 {
+		fields["seq"] = message.Seq
+		fields["lane"] = message.Lane
+	}
+	if message.MapSeq != 0 {
+		fields["mapSeq"] = message.MapSeq
+	}
+	h.eventExporter.Export(logexport.CategoryMessages, fields)
+}
@@ -0,0 +1,76 @@
+package websocket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubMessageAuditPolicyProvider struct {
+	policies map[string]models.MessageAuditPolicy
+}
+
+func (p *stubMessageAuditPolicyProvider) GetMessageAuditPolicy(ctx context.Context, mapID string) (models.MessageAuditPolicy, error) {
+	return p.policies[mapID], nil
+}
+
+func TestHandler_AuditMessage_SamplesPerMap(t *testing.T) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, new(MockPOIService))
+	exporter := &recordingEventExporter{}
+	handler.SetEventExporter(exporter)
+	handler.SetMessageAuditPolicyProvider(&stubMessageAuditPolicyProvider{policies: map[string]models.MessageAuditPolicy{
+		"map-1": {SampleRate: 0.01, FlaggedSessions: []string{"flagged-session"}},
+	}})
+	sample := 0.5
+	handler.auditSample = func() float64 { return sample }
+	ctx := context.Background()
+
+	sampled := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1"}
+	flagged := &Client{SessionID: "flagged-session", UserID: "user-2", MapID: "map-1"}
+	unaudited := &Client{SessionID: "session-3", UserID: "user-3", MapID: "map-2"}
+	message := Message{Type: "avatar_move", Data: map[string]interface{}{"lat": 52.52}, Timestamp: time.Now()}
+
+	// Outside the 1% sample only flagged sessions are audited
+	handler.auditMessage(ctx, sampled, auditInbound, message)
+	handler.auditMessage(ctx, flagged, auditInbound, message)
+	handler.auditMessage(ctx, unaudited, auditInbound, message)
+	require.Len(t, exporter.events, 1)
+	event := exporter.events[0]
+	assert.Equal(t, "messages", event["category"])
+	assert.Equal(t, "flagged-session", event["sessionId"])
+	assert.Equal(t, true, event["flagged"])
+	assert.Equal(t, "in", event["direction"])
+	assert.Equal(t, "avatar_move", event["type"])
+	assert.Equal(t, message.Data, event["data"])
+
+	sample = 0.005
+	handler.auditMessage(ctx, sampled, auditOutbound, message)
+	handler.auditMessage(ctx, unaudited, auditOutbound, message)
+	require.Len(t, exporter.events, 2)
+	assert.Equal(t, "session-1", exporter.events[1]["sessionId"])
+	assert.Equal(t, false, exporter.events[1]["flagged"])
+	assert.Equal(t, "out", exporter.events[1]["direction"])
+}
+
+func TestHandler_HandleMessage_AuditsRejectedMessages(t *testing.T) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, new(MockPOIService))
+	exporter := &recordingEventExporter{}
+	handler.SetEventExporter(exporter)
+	handler.SetMessageAuditPolicyProvider(&stubMessageAuditPolicyProvider{policies: map[string]models.MessageAuditPolicy{
+		"map-1": {FlaggedSessions: []string{"session-1"}},
+	}})
+
+	client := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 10), features: map[models.Feature]bool{}}
+
+	// Batched movement isn't enabled for the client
+	handler.handleMessage(client, Message{Type: "avatar_move_batch", Timestamp: time.Now()})
+
+	require.Len(t, exporter.events, 1)
+	assert.Equal(t, "avatar_move_batch", exporter.events[0]["type"])
+	assert.Equal(t, "FEATURE_NOT_ENABLED", (<-client.Send).Data.(map[string]interface{})["code"])
+}
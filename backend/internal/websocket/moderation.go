@@ -0,0 +1,148 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+)
+
+// ModerationInterface defines the interface for kicking and banning users from maps
+type ModerationInterface interface {
+	KickUser(ctx context.Context, mapID, kickedBy, userID string, banFor time.Duration) (*services.KickResult, error)
+	GetMapBan(ctx context.Context, mapID, userID string) (*models.MapBan, error)
+}
+
+// SetModeration enables the kick_user facilitator command and rejects
+// connections of users banned from their map. Without it, nobody can be
+// kicked over the WebSocket.
+func (h *Handler) SetModeration(moderation ModerationInterface) {
+	h.moderation = moderation
+}
+
+// KickUserPayload is the data of kick_user messages. With BanSeconds, the
+// user can't reconnect to the map for that long.
+type KickUserPayload struct {
+	UserID     string `json:"userId"`
+	BanSeconds int    `json:"banSeconds,omitempty"`
+}
+
+// Validate checks that the user is set and the ban is within
+// services.MaxMapBanDuration
+func (p KickUserPayload) Validate() error {
+	if p.UserID == "" {
+		return errors.New("userId is required")
+	}
+	if p.BanSeconds < 0 || time.Duration(p.BanSeconds)*time.Second > services.MaxMapBanDuration {
+		return fmt.Errorf("banSeconds must be between 0 and %d", int(services.MaxMapBanDuration.Seconds()))
+	}
+	return nil
+}
+
+// validateKickUser validates kick_user messages
+func validateKickUser(msg Message) error {
+	var payload KickUserPayload
+	if err := decodePayload(msg, &payload); err != nil {
+		return err
+	}
+	return payload.Validate()
+}
+
+// handleKickUser ends the sessions of a user on the client's map, which
+// disconnects them on every instance, and optionally bans them. Only
+// facilitators can kick.
+func (h *Handler) handleKickUser(ctx context.Context, client *Client, msg Message) {
+	if h.moderation == nil {
+		h.sendErrorMessage(ctx, client, "Kicking is not available")
+		return
+	}
+	if !h.manager.IsFacilitator(client) {
+		h.sendKickReply(ctx, client, Message{
+			Type: "error",
+			Data: map[string]interface{}{
+				"code":    "FORBIDDEN",
+				"message": "Only facilitators can kick users",
+			},
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	var payload KickUserPayload
+	if !h.decodeMessagePayload(client, msg, &payload) {
+		return
+	}
+
+	banFor := time.Duration(payload.BanSeconds) * time.Second
+	result, err := h.moderation.KickUser(ctx, client.MapID, client.UserID, payload.UserID, banFor)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidInput) {
+			h.sendErrorMessage(ctx, client, err.Error())
+			return
+		}
+		h.requestLogger(ctx).Error("Failed to kick user", "mapId", client.MapID, "userId", payload.UserID, "error", err)
+		h.sendErrorMessage(ctx, client, "Failed to kick user")
+		return
+	}
+
+	h.requestLogger(ctx).Info("👢 User kicked from map",
+		"mapId", client.MapID,
+		"userId", payload.UserID,
+		"kickedBy", client.UserID,
+		"banSeconds", payload.BanSeconds)
+
+	h.sendKickReply(ctx, client, Message{
+		Type:      "kick_user_ack",
+		Data:      result,
+		Timestamp: time.Now(),
+	})
+}
+
+// handleUserKickedEvent disconnects the connections of a kicked user on this
+// instance
+func (h *Handler) handleUserKickedEvent(data interface{}) {
+	kickData, ok := data.(map[string]interface{})
+	if !ok {
+		h.logger.Error("❌ Invalid user kicked event data", "data", data)
+		return
+	}
+
+	mapID, _ := kickData["mapId"].(string)
+	userID, _ := kickData["userId"].(string)
+	if mapID == "" || userID == "" {
+		h.logger.Error("❌ Missing mapId or userId in user kicked event", "data", data)
+		return
+	}
+
+	h.KickUser(mapID, userID)
+}
+
+// mapBan returns the ban keeping a user from a map, or nil if they aren't
+// banned. Bans that can't be looked up let the user connect.
+func (h *Handler) mapBan(ctx context.Context, mapID, userID string) *models.MapBan {
+	if h.moderation == nil {
+		return nil
+	}
+
+	ban, err := h.moderation.GetMapBan(ctx, mapID, userID)
+	if err != nil {
+		h.requestLogger(ctx).Warn("Failed to get map ban",
+			"mapId", mapID,
+			"userId", userID,
+			"error", err.Error())
+		return nil
+	}
+	return ban
+}
+
+// sendKickReply answers a kick_user of the client
+func (h *Handler) sendKickReply(ctx context.Context, client *Client, message Message) {
+	select {
+	case client.Send <- replyTo(ctx, message):
+	default:
+		h.requestLogger(ctx).Warn("Failed to send kick reply to client", "sessionId", client.SessionID, "type", message.Type)
+	}
+}
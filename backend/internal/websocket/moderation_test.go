@@ -0,0 +1,120 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/redis"
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// dispatchingModeration kicks users and hands the event straight to the
+// handler, like the PubSub listener does
+type dispatchingModeration struct {
+	handler *Handler
+	bans    map[string]*models.MapBan // userID -> ban
+}
+
+func (m *dispatchingModeration) KickUser(ctx context.Context, mapID, kickedBy, userID string, banFor time.Duration) (*services.KickResult, error) {
+	if userID == kickedBy {
+		return nil, services.ErrInvalidInput
+	}
+	result := &services.KickResult{UserID: userID, EndedSessions: []string{"session-2"}}
+	if banFor > 0 {
+		until := time.Now().Add(banFor)
+		m.bans[userID] = &models.MapBan{MapID: mapID, UserID: userID, BannedBy: kickedBy, Until: until}
+		result.BannedUntil = &until
+	}
+	data, err := json.Marshal(redis.UserKickedEvent{MapID: mapID, UserID: userID, KickedBy: kickedBy, BannedUntil: result.BannedUntil})
+	if err != nil {
+		return nil, err
+	}
+	redis.DispatchPOIEvent(redis.Event{Type: redis.EventTypeUserKicked, Data: data}, m.handler.handlePubSubEvent)
+	return result, nil
+}
+
+func (m *dispatchingModeration) GetMapBan(ctx context.Context, mapID, userID string) (*models.MapBan, error) {
+	return m.bans[userID], nil
+}
+
+func newModerationTestHandler(t *testing.T) (*Handler, *dispatchingModeration, *Client, *Client) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, nil)
+	t.Cleanup(handler.manager.Shutdown)
+	moderation := &dispatchingModeration{handler: handler, bans: make(map[string]*models.MapBan)}
+	handler.SetModeration(moderation)
+
+	facilitator := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1", role: models.UserRoleAdmin, Send: make(chan Message, 10)}
+	participant := &Client{SessionID: "session-2", UserID: "user-2", MapID: "map-1", Send: make(chan Message, 10)}
+	handler.manager.registerClient(facilitator)
+	handler.manager.registerClient(participant)
+	return handler, moderation, facilitator, participant
+}
+
+func TestHandler_KickUserMessage(t *testing.T) {
+	handler, moderation, facilitator, participant := newModerationTestHandler(t)
+	ctx := withRequestID(context.Background(), "req-1")
+
+	handler.handleKickUser(ctx, facilitator, Message{Type: "kick_user", Data: map[string]interface{}{"userId": "user-2", "banSeconds": 600}, RequestID: "req-1"})
+
+	assertDisconnected(t, participant, CloseCodeKicked, CloseReasonKicked)
+	ack := receiveType(t, facilitator, "kick_user_ack")
+	assert.Equal(t, "req-1", ack.RequestID)
+	result := ack.Data.(*services.KickResult)
+	assert.Equal(t, "user-2", result.UserID)
+	require.NotNil(t, result.BannedUntil)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), *result.BannedUntil, time.Second)
+	assert.NotNil(t, moderation.bans["user-2"])
+}
+
+func TestHandler_KickUserMessage_RequiresFacilitator(t *testing.T) {
+	handler, _, facilitator, participant := newModerationTestHandler(t)
+	ctx := context.Background()
+
+	handler.handleKickUser(ctx, participant, Message{Type: "kick_user", Data: map[string]interface{}{"userId": "user-1"}})
+
+	reply := receiveType(t, participant, "error")
+	assert.Equal(t, "FORBIDDEN", reply.Data.(map[string]interface{})["code"])
+	assert.True(t, handler.manager.IsClientConnected(facilitator.SessionID))
+
+	// Facilitators can't kick themselves
+	handler.handleKickUser(ctx, facilitator, Message{Type: "kick_user", Data: map[string]interface{}{"userId": "user-1"}})
+	receiveType(t, facilitator, "error")
+	assert.True(t, handler.manager.IsClientConnected(facilitator.SessionID))
+}
+
+func TestValidateKickUser(t *testing.T) {
+	assert.NoError(t, validateKickUser(Message{Type: "kick_user", Data: map[string]interface{}{"userId": "user-2", "banSeconds": 3600}}))
+	assert.Error(t, validateKickUser(Message{Type: "kick_user", Data: map[string]interface{}{}}))
+	assert.Error(t, validateKickUser(Message{Type: "kick_user", Data: map[string]interface{}{"userId": "user-2", "banSeconds": -1}}))
+	assert.Error(t, validateKickUser(Message{Type: "kick_user", Data: map[string]interface{}{"userId": "user-2", "banSeconds": int(services.MaxMapBanDuration.Seconds()) + 1}}))
+}
+
+func TestHandler_HandleWebSocket_RejectsBannedUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	sessionService := new(MockSessionService)
+	sessionService.On("GetSession", mock.Anything, "session-2").Return(&models.Session{ID: "session-2", UserID: "user-2", MapID: "map-1", IsActive: true}, nil)
+	handler := NewHandler(sessionService, new(MockRateLimiter), nil, nil)
+	defer handler.manager.Shutdown()
+	handler.SetModeration(&dispatchingModeration{handler: handler, bans: map[string]*models.MapBan{
+		"user-2": {MapID: "map-1", UserID: "user-2", BannedBy: "user-1", Until: time.Now().Add(time.Hour)},
+	}})
+
+	router := gin.New()
+	handler.RegisterRoutes(router, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ws?"+sessionQuery(handler, "session-2"), nil))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "Banned from this map")
+}
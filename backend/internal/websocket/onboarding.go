@@ -0,0 +1,50 @@
+package websocket
+
+import "time"
+
+// SendToUserOnMap sends a message to every connection a user has on a map,
+// numbered like broadcasts, unlike BroadcastToUser which reaches all their maps
+func (m *Manager) SendToUserOnMap(mapID, userID string, message Message) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.withheldWhilePresenting(userID, message) {
+		return
+	}
+	message = stampPublished(message)
+	for _, client := range m.mapClients[mapID] {
+		if client.UserID != userID {
+			continue
+		}
+		if !m.deliver(client, message) {
+			m.logger.Warn("Client send channel full, closing connection",
+				"sessionId", client.SessionID)
+			m.evictSlowConsumer(client)
+		}
+	}
+}
+
+// handleOnboardingProgressEvent sends a user's new welcome checklist progress
+// to their connections on the map, so every open tab and device checks off
+// the step
+func (h *Handler) handleOnboardingProgressEvent(data interface{}) {
+	progressData, ok := data.(map[string]interface{})
+	if !ok {
+		h.logger.Error("❌ Invalid onboarding progress event data", "data", data)
+		return
+	}
+
+	mapID, _ := progressData["mapId"].(string)
+	userID, _ := progressData["userId"].(string)
+	if mapID == "" || userID == "" {
+		h.logger.Error("❌ Missing mapId or userId in onboarding progress event", "data", data)
+		return
+	}
+
+	h.manager.SendToUserOnMap(mapID, userID, Message{
+		Type:        "onboarding_progress",
+		Data:        progressData,
+		Timestamp:   time.Now(),
+		publishedAt: eventPublishedAt(progressData),
+	})
+}
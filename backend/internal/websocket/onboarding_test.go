@@ -0,0 +1,40 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_OnboardingProgressEvent_ReachesOnlyTheUser(t *testing.T) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, new(MockPOIService))
+	defer handler.manager.Shutdown()
+
+	tab := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 10)}
+	otherTab := &Client{SessionID: "session-2", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 10)}
+	otherUser := &Client{SessionID: "session-3", UserID: "user-2", MapID: "map-1", Send: make(chan Message, 10)}
+	otherMap := &Client{SessionID: "session-4", UserID: "user-1", MapID: "map-2", Send: make(chan Message, 10)}
+	for _, client := range []*Client{tab, otherTab, otherUser, otherMap} {
+		handler.manager.registerClient(client)
+	}
+
+	handler.handlePubSubEvent("onboarding_progress", map[string]interface{}{
+		"userId": "user-1",
+		"mapId":  "map-1",
+		"step":   "first_move",
+	})
+
+	for _, client := range []*Client{tab, otherTab} {
+		select {
+		case msg := <-client.Send:
+			require.Equal(t, "onboarding_progress", msg.Type)
+			assert.Equal(t, "first_move", msg.Data.(map[string]interface{})["step"])
+		case <-time.After(100 * time.Millisecond):
+			t.Fatalf("Expected onboarding_progress for %s", client.SessionID)
+		}
+	}
+	assert.Empty(t, otherUser.Send)
+	assert.Empty(t, otherMap.Send)
+}
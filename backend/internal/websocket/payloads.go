@@ -0,0 +1,361 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"breakoutglobe/internal/models"
+)
+
+// errInvalidPayload is returned for message data that isn't a JSON object of
+// the message type's payload
+var errInvalidPayload = errors.New("invalid data format")
+
+// incomingMessage is a message as read from a client. Its data is kept raw
+// until the handler of the message type decodes it into its payload struct.
+type incomingMessage struct {
+	Type    string          `json:"type"`
+	Data    json.RawMessage `json:"data"`
+	Version int             `json:"version,omitempty"`
+	// RequestID correlates the replies to the message, see Message.RequestID
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// message returns the incoming message as a Message carrying the raw data
+func (m incomingMessage) message() Message {
+	msg := Message{Type: m.Type, Version: m.Version, RequestID: m.RequestID}
+	if len(m.Data) > 0 {
+		msg.Data = m.Data
+	}
+	return msg
+}
+
+// decodePayload decodes the data of a client message into the payload struct
+// of its type. Messages read from a connection carry raw JSON; messages built
+// in process, like in tests, are encoded first.
+func decodePayload(msg Message, payload interface{}) error {
+	var raw []byte
+	switch data := msg.Data.(type) {
+	case nil:
+		return errInvalidPayload
+	case json.RawMessage:
+		raw = data
+	default:
+		encoded, err := json.Marshal(data)
+		if err != nil {
+			return errInvalidPayload
+		}
+		raw = encoded
+	}
+
+	if !present(raw) {
+		return errInvalidPayload
+	}
+	if err := json.Unmarshal(raw, payload); err != nil {
+		return errInvalidPayload
+	}
+	return nil
+}
+
+// present reports whether a raw JSON value was sent and isn't null
+func present(raw json.RawMessage) bool {
+	return len(raw) > 0 && string(raw) != "null"
+}
+
+// PositionPayload is a position sent by a client. The coordinates are pointers
+// so a missing one can be told apart from zero.
+type PositionPayload struct {
+	Lat *float64 `json:"lat"`
+	Lng *float64 `json:"lng"`
+}
+
+// Validate checks that both coordinates are set
+func (p PositionPayload) Validate() error {
+	if p.Lat == nil {
+		return errors.New("latitude is required")
+	}
+	if p.Lng == nil {
+		return errors.New("longitude is required")
+	}
+	return nil
+}
+
+// LatLng returns the position, which must be valid
+func (p PositionPayload) LatLng() models.LatLng {
+	return models.LatLng{Lat: *p.Lat, Lng: *p.Lng}
+}
+
+// AvatarMovePayload is the data of avatar_move messages
+type AvatarMovePayload struct {
+	Position *PositionPayload `json:"position"`
+}
+
+// Validate checks that the position is set
+func (p AvatarMovePayload) Validate() error {
+	if p.Position == nil {
+		return errors.New("position is required for avatar_move")
+	}
+	return p.Position.Validate()
+}
+
+// AvatarMoveBatchPayload is the data of avatar_move_batch messages, holding
+// the positions sampled since the client's last message, oldest first
+type AvatarMoveBatchPayload struct {
+	Positions []PositionPayload `json:"positions"`
+}
+
+// Validate checks that the batch holds between one and maxAvatarMoveBatchSize positions
+func (p AvatarMoveBatchPayload) Validate() error {
+	if len(p.Positions) == 0 {
+		return errors.New("positions are required for avatar_move_batch")
+	}
+	if len(p.Positions) > maxAvatarMoveBatchSize {
+		return fmt.Errorf("avatar_move_batch must not contain more than %d positions", maxAvatarMoveBatchSize)
+	}
+	for _, position := range p.Positions {
+		if err := position.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResyncFromPayload is the data of resync_from messages
+type ResyncFromPayload struct {
+	Seq *uint64 `json:"seq"`
+}
+
+// Validate checks that the sequence number is set
+func (p ResyncFromPayload) Validate() error {
+	if p.Seq == nil {
+		return errors.New("seq is required for resync_from")
+	}
+	return nil
+}
+
+// ViewportUpdatePayload is the data of viewport_update messages
+type ViewportUpdatePayload struct {
+	North *float64 `json:"north"`
+	South *float64 `json:"south"`
+	East  *float64 `json:"east"`
+	West  *float64 `json:"west"`
+}
+
+// Validate checks that all sides are set
+func (p ViewportUpdatePayload) Validate() error {
+	if p.North == nil || p.South == nil || p.East == nil || p.West == nil {
+		return errors.New("north, south, east and west are required for viewport_update")
+	}
+	return nil
+}
+
+// Bounds returns the viewport, which must be valid
+func (p ViewportUpdatePayload) Bounds() models.Bounds {
+	return models.Bounds{North: *p.North, South: *p.South, East: *p.East, West: *p.West}
+}
+
+// POIMembershipPayload is the data of poi_join and poi_leave messages
+type POIMembershipPayload struct {
+	POIID string `json:"poiId"`
+}
+
+// Validate checks that the POI is set
+func (p POIMembershipPayload) Validate() error {
+	if p.POIID == "" {
+		return errors.New("poiId is required")
+	}
+	return nil
+}
+
+// SpeakingStatePayload is the data of speaking_state messages
+type SpeakingStatePayload struct {
+	POIID    string `json:"poiId"`
+	Speaking *bool  `json:"speaking"`
+}
+
+// Validate checks that the POI and the state are set
+func (p SpeakingStatePayload) Validate() error {
+	if p.POIID == "" {
+		return errors.New("poiId is required for speaking_state")
+	}
+	if p.Speaking == nil {
+		return errors.New("speaking must be a boolean")
+	}
+	return nil
+}
+
+// CallRequestPayload is the data of call_request messages
+type CallRequestPayload struct {
+	TargetUserID string `json:"targetUserId"`
+	CallID       string `json:"callId"`
+	// CallerName is shown to the called user if set
+	CallerName string `json:"callerName,omitempty"`
+}
+
+// Validate checks that the target user and the call are set
+func (p CallRequestPayload) Validate() error {
+	if p.TargetUserID == "" {
+		return errors.New("targetUserId is required for call_request")
+	}
+	if p.CallID == "" {
+		return errors.New("callId is required for call_request")
+	}
+	return nil
+}
+
+// CallResponsePayload is the data of call_accept and call_reject messages
+type CallResponsePayload struct {
+	CallID       string `json:"callId"`
+	CallerUserID string `json:"callerUserId"`
+}
+
+// Validate checks that the call and the caller are set
+func (p CallResponsePayload) Validate() error {
+	if p.CallID == "" {
+		return errors.New("callId is required")
+	}
+	if p.CallerUserID == "" {
+		return errors.New("callerUserId is required")
+	}
+	return nil
+}
+
+// CallEndPayload is the data of call_end messages
+type CallEndPayload struct {
+	CallID      string `json:"callId"`
+	OtherUserID string `json:"otherUserId"`
+}
+
+// Validate checks that the call and the other user are set
+func (p CallEndPayload) Validate() error {
+	if p.CallID == "" {
+		return errors.New("callId is required for call_end")
+	}
+	if p.OtherUserID == "" {
+		return errors.New("otherUserId is required for call_end")
+	}
+	return nil
+}
+
+// WebRTCDescriptionPayload is the data of webrtc_offer and webrtc_answer
+// messages. The session description is forwarded to the target user as sent.
+type WebRTCDescriptionPayload struct {
+	CallID       string          `json:"callId"`
+	TargetUserID string          `json:"targetUserId"`
+	SDP          json.RawMessage `json:"sdp"`
+}
+
+// Validate checks that the call, the target user and the description are set
+func (p WebRTCDescriptionPayload) Validate() error {
+	if p.CallID == "" {
+		return errors.New("callId is required for WebRTC offer/answer")
+	}
+	if p.TargetUserID == "" {
+		return errors.New("targetUserId is required for WebRTC offer/answer")
+	}
+	if !present(p.SDP) {
+		return errors.New("sdp is required for WebRTC offer/answer")
+	}
+	return nil
+}
+
+// ICECandidatePayload is the data of ice_candidate messages. The candidate is
+// forwarded to the target user as sent.
+type ICECandidatePayload struct {
+	CallID       string          `json:"callId"`
+	TargetUserID string          `json:"targetUserId"`
+	Candidate    json.RawMessage `json:"candidate"`
+}
+
+// Validate checks that the call, the target user and the candidate are set
+func (p ICECandidatePayload) Validate() error {
+	if p.CallID == "" {
+		return errors.New("callId is required for ICE candidate")
+	}
+	if p.TargetUserID == "" {
+		return errors.New("targetUserId is required for ICE candidate")
+	}
+	if !present(p.Candidate) {
+		return errors.New("candidate is required for ICE candidate")
+	}
+	return nil
+}
+
+// POICallDescriptionPayload is the data of poi_call_offer and poi_call_answer
+// messages
+type POICallDescriptionPayload struct {
+	POIID        string          `json:"poiId"`
+	TargetUserID string          `json:"targetUserId"`
+	SDP          json.RawMessage `json:"sdp"`
+}
+
+// Validate checks that the POI, the target user and the description are set
+func (p POICallDescriptionPayload) Validate() error {
+	if p.POIID == "" {
+		return errors.New("poiId is required for POI call offer/answer")
+	}
+	if p.TargetUserID == "" {
+		return errors.New("targetUserId is required for POI call offer/answer")
+	}
+	if !present(p.SDP) {
+		return errors.New("sdp is required for POI call offer/answer")
+	}
+	return nil
+}
+
+// POICallICECandidatePayload is the data of poi_call_ice_candidate messages
+type POICallICECandidatePayload struct {
+	POIID        string          `json:"poiId"`
+	TargetUserID string          `json:"targetUserId"`
+	Candidate    json.RawMessage `json:"candidate"`
+}
+
+// Validate checks that the POI, the target user and the candidate are set
+func (p POICallICECandidatePayload) Validate() error {
+	if p.POIID == "" {
+		return errors.New("poiId is required for POI call ICE candidate")
+	}
+	if p.TargetUserID == "" {
+		return errors.New("targetUserId is required for POI call ICE candidate")
+	}
+	if !present(p.Candidate) {
+		return errors.New("candidate is required for POI call ICE candidate")
+	}
+	return nil
+}
+
+// MapFreezePayload is the data of map_freeze messages. Without a duration the
+// map is frozen for the default duration.
+type MapFreezePayload struct {
+	DurationSeconds *float64 `json:"durationSeconds,omitempty"`
+}
+
+// Validate checks that the duration isn't negative
+func (p MapFreezePayload) Validate() error {
+	if p.DurationSeconds != nil && *p.DurationSeconds < 0 {
+		return errors.New("durationSeconds must be a non-negative number")
+	}
+	return nil
+}
+
+// messagePayload is implemented by the payload structs
+type messagePayload interface {
+	Validate() error
+}
+
+// decodeMessagePayload decodes and validates the payload of a message for its
+// handler, telling the client if it's invalid. Messages read from a connection
+// were validated already; this guards against messages built in process.
+func (h *Handler) decodeMessagePayload(client *Client, msg Message, payload messagePayload) bool {
+	err := decodePayload(msg, payload)
+	if err == nil {
+		err = payload.Validate()
+	}
+	if err != nil {
+		h.sendErrorMessage(withRequestID(context.Background(), msg.RequestID), client, fmt.Sprintf("Invalid %s: %s", msg.Type, err))
+		return false
+	}
+	return true
+}
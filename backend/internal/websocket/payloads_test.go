@@ -0,0 +1,94 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodePayload_RawAndInProcessData(t *testing.T) {
+	var incoming incomingMessage
+	require.NoError(t, json.Unmarshal([]byte(`{"type":"avatar_move","data":{"position":{"lat":0,"lng":13.4}}}`), &incoming))
+
+	var fromRaw AvatarMovePayload
+	require.NoError(t, decodePayload(incoming.message(), &fromRaw))
+	require.NoError(t, fromRaw.Validate())
+	assert.Equal(t, models.LatLng{Lat: 0, Lng: 13.4}, fromRaw.Position.LatLng())
+
+	var fromMap AvatarMovePayload
+	require.NoError(t, decodePayload(Message{
+		Type: "avatar_move",
+		Data: map[string]interface{}{"position": map[string]interface{}{"lat": 0.0, "lng": 13.4}},
+	}, &fromMap))
+	assert.Equal(t, fromRaw, fromMap)
+}
+
+func TestDecodePayload_InvalidData(t *testing.T) {
+	var payload AvatarMovePayload
+	assert.ErrorIs(t, decodePayload(Message{Type: "avatar_move"}, &payload), errInvalidPayload)
+	assert.ErrorIs(t, decodePayload(Message{Type: "avatar_move", Data: json.RawMessage(`null`)}, &payload), errInvalidPayload)
+	assert.ErrorIs(t, decodePayload(Message{Type: "avatar_move", Data: json.RawMessage(`"north"`)}, &payload), errInvalidPayload)
+}
+
+func TestIncomingMessage_WithoutData(t *testing.T) {
+	var incoming incomingMessage
+	require.NoError(t, json.Unmarshal([]byte(`{"type":"heartbeat","version":1}`), &incoming))
+
+	msg := incoming.message()
+	assert.Equal(t, "heartbeat", msg.Type)
+	assert.Equal(t, 1, msg.Version)
+	assert.Nil(t, msg.Data)
+}
+
+func TestPayloadValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		msg     Message
+		wantErr string
+	}{
+		{"missing latitude", Message{Type: "avatar_move", Data: json.RawMessage(`{"position":{"lng":1}}`)}, "latitude is required"},
+		{"empty batch", Message{Type: "avatar_move_batch", Data: json.RawMessage(`{"positions":[]}`)}, "positions are required for avatar_move_batch"},
+		{"missing seq", Message{Type: "resync_from", Data: json.RawMessage(`{}`)}, "seq is required for resync_from"},
+		{"missing speaking", Message{Type: "speaking_state", Data: json.RawMessage(`{"poiId":"poi-1"}`)}, "speaking must be a boolean"},
+		{"null sdp", Message{Type: "webrtc_offer", Data: json.RawMessage(`{"callId":"c","targetUserId":"u","sdp":null}`)}, "sdp is required for WebRTC offer/answer"},
+		{"negative freeze", Message{Type: "map_freeze", Data: json.RawMessage(`{"durationSeconds":-1}`)}, "durationSeconds must be a non-negative number"},
+		{"valid candidate", Message{Type: "ice_candidate", Data: json.RawMessage(`{"callId":"c","targetUserId":"u","candidate":{}}`)}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateMessage(tt.msg)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestHandler_ForwardsSDPAsSent(t *testing.T) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, new(MockPOIService))
+	defer handler.manager.Shutdown()
+	caller := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 4)}
+	callee := &Client{SessionID: "session-2", UserID: "user-2", MapID: "map-1", Send: make(chan Message, 4)}
+	handler.manager.registerClient(caller)
+	handler.manager.registerClient(callee)
+
+	sdp := `{"type":"offer","sdp":"v=0"}`
+	handler.handleWebRTCOffer(context.Background(), caller, Message{
+		Type: "webrtc_offer",
+		Data: json.RawMessage(`{"callId":"call-1","targetUserId":"user-2","sdp":` + sdp + `}`),
+	})
+
+	msg := <-callee.Send
+	assert.Equal(t, "webrtc_offer", msg.Type)
+	encoded, err := json.Marshal(msg.Data)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"callId":"call-1","fromUserId":"user-1","sdp":`+sdp+`}`, string(encoded))
+}
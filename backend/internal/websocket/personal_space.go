@@ -0,0 +1,147 @@
+package websocket
+
+import (
+	"math"
+	"sync"
+
+	"breakoutglobe/internal/models"
+)
+
+// personalSpaceAdjustAttempts bounds how often an adjusted position is pushed
+// out again when it lands in another avatar's personal space
+const personalSpaceAdjustAttempts = 5
+
+// metersPerDegree approximates the length of one degree of latitude
+const metersPerDegree = 111320.0
+
+// avatarPositions indexes the current avatar position of every connected
+// session by map, so moves can be checked against the other avatars without
+// touching other clients' state
+type avatarPositions struct {
+	mutex     sync.RWMutex
+	positions map[string]map[string]models.LatLng // mapID -> sessionID -> position
+}
+
+func newAvatarPositions() *avatarPositions {
+	return &avatarPositions{
+		positions: make(map[string]map[string]models.LatLng),
+	}
+}
+
+// Set records the position of a session's avatar
+func (p *avatarPositions) Set(mapID, sessionID string, position models.LatLng) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.positions[mapID] == nil {
+		p.positions[mapID] = make(map[string]models.LatLng)
+	}
+	p.positions[mapID][sessionID] = position
+}
+
+// Remove forgets the avatar of a session, e.g. on disconnect
+func (p *avatarPositions) Remove(mapID, sessionID string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	delete(p.positions[mapID], sessionID)
+	if len(p.positions[mapID]) == 0 {
+		delete(p.positions, mapID)
+	}
+}
+
+// Others returns the positions of all avatars on a map except the session's own
+func (p *avatarPositions) Others(mapID, sessionID string) []models.LatLng {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	others := make([]models.LatLng, 0, len(p.positions[mapID]))
+	for otherID, position := range p.positions[mapID] {
+		if otherID != sessionID {
+			others = append(others, position)
+		}
+	}
+	return others
+}
+
+// All returns the positions of all avatars on a map by session ID
+func (p *avatarPositions) All(mapID string) map[string]models.LatLng {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	all := make(map[string]models.LatLng, len(p.positions[mapID]))
+	for sessionID, position := range p.positions[mapID] {
+		all[sessionID] = position
+	}
+	return all
+}
+
+// resolvePersonalSpace checks a move against the other avatars. It returns the
+// position to store, and false if the move must be rejected. In adjust mode a
+// position inside another avatar's personal space is pushed to its edge, away
+// from that avatar; if that is not possible the move is rejected too.
+func resolvePersonalSpace(personalSpace models.PersonalSpace, from *models.LatLng, to models.LatLng, others []models.LatLng) (models.LatLng, bool) {
+	if !personalSpace.Enabled() {
+		return to, true
+	}
+
+	radius := personalSpace.RadiusMeters
+	position := to
+	for attempt := 0; attempt <= personalSpaceAdjustAttempts; attempt++ {
+		nearest, distance := nearestAvatar(position, others)
+		if distance >= radius {
+			return position, true
+		}
+		if personalSpace.Mode != models.PersonalSpaceAdjust || attempt == personalSpaceAdjustAttempts {
+			return to, false
+		}
+
+		position = pushOut(nearest, position, from, radius)
+	}
+
+	return to, false
+}
+
+// nearestAvatar returns the closest avatar to a position and its distance in meters
+func nearestAvatar(position models.LatLng, others []models.LatLng) (models.LatLng, float64) {
+	var nearest models.LatLng
+	distance := math.Inf(1)
+	for _, other := range others {
+		if d := position.DistanceTo(other) * 1000; d < distance {
+			nearest, distance = other, d
+		}
+	}
+	return nearest, distance
+}
+
+// pushOut moves position directly away from other until it is just outside
+// radiusMeters. A position exactly on top of the other avatar is pushed back
+// toward where the avatar came from, or north if unknown.
+func pushOut(other, position models.LatLng, from *models.LatLng, radiusMeters float64) models.LatLng {
+	cosLat := math.Max(math.Cos(other.Lat*math.Pi/180), 0.01)
+
+	// Local east/north offsets in meters
+	east := (position.Lng - other.Lng) * cosLat * metersPerDegree
+	north := (position.Lat - other.Lat) * metersPerDegree
+	if east == 0 && north == 0 && from != nil {
+		east = (from.Lng - other.Lng) * cosLat * metersPerDegree
+		north = (from.Lat - other.Lat) * metersPerDegree
+	}
+	length := math.Hypot(east, north)
+	if length == 0 {
+		east, north, length = 0, 1, 1
+	}
+
+	// Land slightly outside the radius so rounding doesn't put it back inside
+	scale := radiusMeters * 1.01 / length
+	lng := other.Lng + east*scale/(cosLat*metersPerDegree)
+	if lng > 180 {
+		lng -= 360
+	} else if lng < -180 {
+		lng += 360
+	}
+	return models.LatLng{
+		Lat: math.Max(-90, math.Min(90, other.Lat+north*scale/metersPerDegree)),
+		Lng: lng,
+	}
+}
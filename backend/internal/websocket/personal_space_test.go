@@ -0,0 +1,65 @@
+package websocket
+
+import (
+	"testing"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolvePersonalSpace(t *testing.T) {
+	other := models.LatLng{Lat: 52.52, Lng: 13.405}
+	near := models.LatLng{Lat: 52.52, Lng: 13.40505} // about 3.4m east
+	far := models.LatLng{Lat: 52.521, Lng: 13.405}   // about 111m north
+
+	reject := models.PersonalSpace{Mode: models.PersonalSpaceReject, RadiusMeters: 10}
+	_, allowed := resolvePersonalSpace(reject, nil, near, []models.LatLng{other})
+	assert.False(t, allowed)
+
+	position, allowed := resolvePersonalSpace(reject, nil, far, []models.LatLng{other})
+	assert.True(t, allowed)
+	assert.Equal(t, far, position)
+
+	// Adjusting pushes the avatar directly away from the other one
+	adjust := models.PersonalSpace{Mode: models.PersonalSpaceAdjust, RadiusMeters: 10}
+	position, allowed = resolvePersonalSpace(adjust, nil, near, []models.LatLng{other})
+	assert.True(t, allowed)
+	assert.InDelta(t, 10.1, position.DistanceTo(other)*1000, 0.1)
+	assert.InDelta(t, other.Lat, position.Lat, 1e-6)
+	assert.Greater(t, position.Lng, near.Lng)
+
+	// Disabled settings let avatars overlap
+	position, allowed = resolvePersonalSpace(models.PersonalSpace{RadiusMeters: 10}, nil, other, []models.LatLng{other})
+	assert.True(t, allowed)
+	assert.Equal(t, other, position)
+}
+
+func TestResolvePersonalSpace_CrowdedAdjustRejects(t *testing.T) {
+	center := models.LatLng{Lat: 0, Lng: 0}
+
+	// A grid of avatars 12m apart leaves no spot 10m away from all of them
+	const step = 12 / metersPerDegree
+	var others []models.LatLng
+	for row := -10; row <= 10; row++ {
+		for col := -10; col <= 10; col++ {
+			others = append(others, models.LatLng{Lat: center.Lat + float64(row)*step, Lng: center.Lng + float64(col)*step})
+		}
+	}
+
+	adjust := models.PersonalSpace{Mode: models.PersonalSpaceAdjust, RadiusMeters: 10}
+	_, allowed := resolvePersonalSpace(adjust, nil, center, others)
+	assert.False(t, allowed)
+}
+
+func TestAvatarPositions(t *testing.T) {
+	positions := newAvatarPositions()
+	positions.Set("map-1", "session-1", models.LatLng{Lat: 1})
+	positions.Set("map-1", "session-2", models.LatLng{Lat: 2})
+	positions.Set("map-2", "session-3", models.LatLng{Lat: 3})
+
+	assert.Equal(t, []models.LatLng{{Lat: 2}}, positions.Others("map-1", "session-1"))
+
+	positions.Remove("map-1", "session-2")
+	assert.Empty(t, positions.Others("map-1", "session-1"))
+}
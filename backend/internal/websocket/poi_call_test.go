@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
 
 	"github.com/gin-gonic/gin"
 	ws "github.com/gorilla/websocket"
@@ -32,12 +33,15 @@ func (suite *POICallTestSuite) SetupTest() {
 	suite.mockSessionService = new(MockSessionService)
 	suite.mockRateLimiter = new(MockRateLimiter)
 	suite.mockPOIService = new(MockPOIService)
+	// Presence payloads resolve the POI each user is in
+	suite.mockPOIService.On("GetCurrentPOI", mock.Anything, mock.Anything).Return("", nil).Maybe()
+	suite.mockRateLimiter.On("CheckRateLimit", mock.Anything, mock.Anything, services.ActionWSSignal).Return(nil).Maybe()
 	
 	suite.handler = NewHandler(suite.mockSessionService, suite.mockRateLimiter, nil, suite.mockPOIService)
 	
 	// Setup test server
 	router := gin.New()
-	router.GET("/ws", suite.handler.HandleWebSocket)
+	suite.handler.RegisterRoutes(router, nil)
 	
 	suite.server = httptest.NewServer(router)
 	suite.wsURL = "ws" + strings.TrimPrefix(suite.server.URL, "http") + "/ws"
@@ -72,11 +76,11 @@ func (suite *POICallTestSuite) TestPOICallOffer() {
 	suite.mockSessionService.On("GetSession", mock.Anything, "session-user2").Return(session2, nil)
 	
 	// Connect both clients
-	conn1, _, err := ws.DefaultDialer.Dial(suite.wsURL+"?sessionId=session-user1", nil)
+	conn1, _, err := ws.DefaultDialer.Dial(suite.wsURL+"?"+sessionQuery(suite.handler, "session-user1"), nil)
 	suite.Require().NoError(err)
 	defer conn1.Close()
 	
-	conn2, _, err := ws.DefaultDialer.Dial(suite.wsURL+"?sessionId=session-user2", nil)
+	conn2, _, err := ws.DefaultDialer.Dial(suite.wsURL+"?"+sessionQuery(suite.handler, "session-user2"), nil)
 	suite.Require().NoError(err)
 	defer conn2.Close()
 	
@@ -162,11 +166,11 @@ func (suite *POICallTestSuite) TestPOICallAnswer() {
 	suite.mockSessionService.On("GetSession", mock.Anything, "session-user2").Return(session2, nil)
 	
 	// Connect both clients
-	conn1, _, err := ws.DefaultDialer.Dial(suite.wsURL+"?sessionId=session-user1", nil)
+	conn1, _, err := ws.DefaultDialer.Dial(suite.wsURL+"?"+sessionQuery(suite.handler, "session-user1"), nil)
 	suite.Require().NoError(err)
 	defer conn1.Close()
 	
-	conn2, _, err := ws.DefaultDialer.Dial(suite.wsURL+"?sessionId=session-user2", nil)
+	conn2, _, err := ws.DefaultDialer.Dial(suite.wsURL+"?"+sessionQuery(suite.handler, "session-user2"), nil)
 	suite.Require().NoError(err)
 	defer conn2.Close()
 	
@@ -248,7 +252,7 @@ func (suite *POICallTestSuite) TestPOICallICECandidate() {
 	suite.mockSessionService.On("GetSession", mock.Anything, "session-user2").Return(session2, nil)
 	
 	// Connect first client
-	conn1, _, err := ws.DefaultDialer.Dial(suite.wsURL+"?sessionId=session-user1", nil)
+	conn1, _, err := ws.DefaultDialer.Dial(suite.wsURL+"?"+sessionQuery(suite.handler, "session-user1"), nil)
 	suite.Require().NoError(err)
 	defer conn1.Close()
 	
@@ -264,7 +268,7 @@ func (suite *POICallTestSuite) TestPOICallICECandidate() {
 	suite.Require().Equal("initial_users", initialUsersMsg1.Type)
 	
 	// Connect second client
-	conn2, _, err := ws.DefaultDialer.Dial(suite.wsURL+"?sessionId=session-user2", nil)
+	conn2, _, err := ws.DefaultDialer.Dial(suite.wsURL+"?"+sessionQuery(suite.handler, "session-user2"), nil)
 	suite.Require().NoError(err)
 	defer conn2.Close()
 	
@@ -330,7 +334,7 @@ func (suite *POICallTestSuite) TestPOICallInvalidMessage() {
 	suite.mockSessionService.On("GetSession", mock.Anything, "session-user1").Return(session, nil)
 	
 	// Connect client
-	conn, _, err := ws.DefaultDialer.Dial(suite.wsURL+"?sessionId=session-user1", nil)
+	conn, _, err := ws.DefaultDialer.Dial(suite.wsURL+"?"+sessionQuery(suite.handler, "session-user1"), nil)
 	suite.Require().NoError(err)
 	defer conn.Close()
 	
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"breakoutglobe/internal/models"
 	"breakoutglobe/internal/services"
 
 	"github.com/stretchr/testify/assert"
@@ -31,14 +32,9 @@ func TestHandler_POIJoin_Success(t *testing.T) {
 
 	// Setup expectations
 	mockSessionService.On("GetSession", mock.Anything, "session-123").Return(nil, nil) // Session not needed for this test
-	mockRateLimiter.On("CheckRateLimit", mock.Anything, "user-456", services.ActionJoinPOI).Return(nil)
 	mockPOIService.On("JoinPOI", mock.Anything, "poi-123", "user-456").Return(nil)
 	
-	// Mock the new methods for participant information
-	participantsInfo := []services.POIParticipantInfo{
-		{ID: "user-456", Name: "Test User", AvatarURL: ""},
-	}
-	mockPOIService.On("GetPOIParticipantsWithInfo", mock.Anything, "poi-123").Return(participantsInfo, nil)
+	// Joins only broadcast the participant count, not the roster
 	mockPOIService.On("GetPOIParticipantCount", mock.Anything, "poi-123").Return(1, nil)
 
 	// Create test message
@@ -90,8 +86,8 @@ func TestHandler_POILeave_Success(t *testing.T) {
 
 	// Setup expectations
 	mockSessionService.On("GetSession", mock.Anything, "session-123").Return(nil, nil) // Session not needed for this test
-	mockRateLimiter.On("CheckRateLimit", mock.Anything, "user-456", services.ActionLeavePOI).Return(nil)
 	mockPOIService.On("LeavePOI", mock.Anything, "poi-123", "user-456").Return(nil)
+	mockPOIService.On("GetPOIParticipantCount", mock.Anything, "poi-123").Return(0, nil)
 
 	// Create test message
 	msg := Message{
@@ -141,7 +137,6 @@ func TestHandler_POIJoin_ServiceError(t *testing.T) {
 	}
 
 	// Setup expectations - POI service returns error
-	mockRateLimiter.On("CheckRateLimit", mock.Anything, "user-456", services.ActionJoinPOI).Return(nil)
 	mockPOIService.On("JoinPOI", mock.Anything, "poi-123", "user-456").Return(assert.AnError)
 
 	// Create test message
@@ -170,4 +165,217 @@ func TestHandler_POIJoin_ServiceError(t *testing.T) {
 	// Verify mocks were called
 	mockRateLimiter.AssertExpectations(t)
 	mockPOIService.AssertExpectations(t)
-}
\ No newline at end of file
+}
+
+type stubPOIRosterProvider struct {
+	rosters map[string][]services.POIRoster
+}
+
+func (p *stubPOIRosterProvider) GetPOIRostersForMap(ctx context.Context, mapID string) ([]services.POIRoster, error) {
+	return p.rosters[mapID], nil
+}
+
+func TestHandler_POIParticipantDelta_BroadcastsToMap(t *testing.T) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, new(MockPOIService))
+
+	client := &Client{
+		SessionID: "session-123",
+		UserID:    "user-456",
+		MapID:     "map-789",
+		Send:      make(chan Message, 10),
+	}
+	handler.manager.registerClient(client)
+
+	handler.handlePubSubEvent("poi_participant_added", map[string]interface{}{
+		"poiId":        "poi-123",
+		"mapId":        "map-789",
+		"userId":       "user-1",
+		"currentCount": 3,
+		"seq":          int64(7),
+	})
+
+	select {
+	case msg := <-client.Send:
+		assert.Equal(t, "poi_participant_added", msg.Type)
+		data, ok := msg.Data.(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, "poi-123", data["poiId"])
+		assert.Equal(t, int64(7), data["seq"])
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Expected participant delta not received")
+	}
+}
+
+func TestHandler_POIDeleted_BroadcastsToMap(t *testing.T) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, new(MockPOIService))
+
+	client := &Client{
+		SessionID: "session-123",
+		UserID:    "user-456",
+		MapID:     "map-789",
+		Send:      make(chan Message, 10),
+	}
+	handler.manager.registerClient(client)
+
+	handler.handlePubSubEvent("poi_deleted", map[string]interface{}{
+		"poiId": "poi-123",
+		"mapId": "map-789",
+	})
+
+	select {
+	case msg := <-client.Send:
+		assert.Equal(t, "poi_deleted", msg.Type)
+		data, ok := msg.Data.(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, "poi-123", data["poiId"])
+		assert.Equal(t, "map-789", data["mapId"])
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Expected POI deleted message not received")
+	}
+}
+
+func TestHandler_POIParticipantRemoved_NotifiesRemovedUser(t *testing.T) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, new(MockPOIService))
+
+	participant := &Client{
+		SessionID: "session-1",
+		UserID:    "user-1",
+		MapID:     "map-789",
+		Send:      make(chan Message, 10),
+	}
+	bystander := &Client{
+		SessionID: "session-2",
+		UserID:    "user-2",
+		MapID:     "map-789",
+		Send:      make(chan Message, 10),
+	}
+	handler.manager.registerClient(participant)
+	handler.manager.registerClient(bystander)
+
+	handler.handlePubSubEvent("poi_participant_removed", map[string]interface{}{
+		"poiId":        "poi-123",
+		"mapId":        "map-789",
+		"userId":       "user-1",
+		"reason":       "deleted",
+		"currentCount": 0,
+	})
+
+	received := func(client *Client) []string {
+		var types []string
+		for {
+			select {
+			case msg := <-client.Send:
+				types = append(types, msg.Type)
+				if msg.Type == "poi_removed_you" {
+					data, ok := msg.Data.(map[string]interface{})
+					require.True(t, ok)
+					assert.Equal(t, "poi-123", data["poiId"])
+					assert.Equal(t, "deleted", data["reason"])
+				}
+			case <-time.After(100 * time.Millisecond):
+				return types
+			}
+		}
+	}
+
+	assert.ElementsMatch(t, []string{"poi_participant_removed", "poi_removed_you"}, received(participant))
+	assert.Equal(t, []string{"poi_participant_removed"}, received(bystander))
+}
+
+func TestHandler_POIMerged_BroadcastsToMap(t *testing.T) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, new(MockPOIService))
+	defer handler.manager.Shutdown()
+
+	client := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-789", Send: make(chan Message, 10)}
+	handler.manager.registerClient(client)
+
+	handler.handlePubSubEvent("poi_merged", map[string]interface{}{
+		"sourcePoiId":  "poi-2",
+		"targetPoiId":  "poi-1",
+		"mapId":        "map-789",
+		"movedUserIds": []string{"user-1"},
+		"currentCount": 3,
+	})
+
+	merged := receiveType(t, client, "poi_merged")
+	assert.Equal(t, map[string]interface{}{
+		"sourcePoiId":  "poi-2",
+		"targetPoiId":  "poi-1",
+		"mapId":        "map-789",
+		"movedUserIds": []string{"user-1"},
+		"currentCount": 3,
+	}, merged.Data)
+}
+
+func TestHandler_BroadcastPOIRosters(t *testing.T) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, new(MockPOIService))
+	handler.SetPOIRosterProvider(&stubPOIRosterProvider{rosters: map[string][]services.POIRoster{
+		"map-789": {{
+			POIID:        "poi-123",
+			Participants: []services.POIParticipantInfo{{ID: "user-1", Name: "User 1"}},
+			CurrentCount: 1,
+			Sequence:     4,
+		}},
+	}})
+
+	client := &Client{
+		SessionID: "session-123",
+		UserID:    "user-456",
+		MapID:     "map-789",
+		Send:      make(chan Message, 10),
+	}
+	handler.manager.registerClient(client)
+
+	require.NoError(t, handler.BroadcastPOIRosters(context.Background()))
+
+	select {
+	case msg := <-client.Send:
+		assert.Equal(t, "poi_roster_sync", msg.Type)
+		data, ok := msg.Data.(map[string]interface{})
+		require.True(t, ok)
+		rosters, ok := data["rosters"].([]services.POIRoster)
+		require.True(t, ok)
+		require.Len(t, rosters, 1)
+		assert.Equal(t, int64(4), rosters[0].Sequence)
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Expected roster sync not received")
+	}
+}
+
+type stubActiveSessions struct {
+	sessions map[string][]*models.Session
+}
+
+func (s *stubActiveSessions) GetActiveSessionsForMap(ctx context.Context, mapID string) ([]*models.Session, error) {
+	return s.sessions[mapID], nil
+}
+
+type recordingReconciler struct {
+	activeUserIDs map[string][]string
+}
+
+func (r *recordingReconciler) ReconcileParticipants(ctx context.Context, mapID string, activeUserIDs []string) (int, error) {
+	r.activeUserIDs[mapID] = activeUserIDs
+	return 0, nil
+}
+
+func TestHandler_ReconcilePOIParticipants(t *testing.T) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, new(MockPOIService))
+	reconciler := &recordingReconciler{activeUserIDs: make(map[string][]string)}
+	handler.SetPOIParticipantReconciler(reconciler, &stubActiveSessions{sessions: map[string][]*models.Session{
+		"map-789": {{ID: "session-remote", UserID: "user-remote", MapID: "map-789"}},
+	}})
+
+	handler.manager.registerClient(&Client{
+		SessionID: "session-123",
+		UserID:    "user-456",
+		MapID:     "map-789",
+		Send:      make(chan Message, 10),
+	})
+
+	require.NoError(t, handler.ReconcilePOIParticipants(context.Background()))
+
+	// Users connected here or on another instance are both kept
+	require.Contains(t, reconciler.activeUserIDs, "map-789")
+	assert.ElementsMatch(t, []string{"user-456", "user-remote"}, reconciler.activeUserIDs["map-789"])
+}
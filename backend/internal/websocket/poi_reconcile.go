@@ -0,0 +1,60 @@
+package websocket
+
+import (
+	"context"
+	"fmt"
+
+	"breakoutglobe/internal/models"
+)
+
+// POIParticipantReconcilerInterface defines the interface for removing POI
+// participants who are no longer connected
+type POIParticipantReconcilerInterface interface {
+	ReconcileParticipants(ctx context.Context, mapID string, activeUserIDs []string) (int, error)
+}
+
+// ActiveSessionProviderInterface defines the interface for the sessions of a
+// map that still send heartbeats, on any instance
+type ActiveSessionProviderInterface interface {
+	GetActiveSessionsForMap(ctx context.Context, mapID string) ([]*models.Session, error)
+}
+
+// SetPOIParticipantReconciler enables periodic removal of POI participants
+// whose sessions are gone, like those of an instance that crashed. Without it,
+// such participants stay until someone clears the POI.
+func (h *Handler) SetPOIParticipantReconciler(reconciler POIParticipantReconcilerInterface, sessions ActiveSessionProviderInterface) {
+	h.reconciler = reconciler
+	h.activeSessions = sessions
+}
+
+// ReconcilePOIParticipants removes the POI participants of each map with local
+// connections who have neither a live session nor a connection here. Removals
+// are published as participant deltas, so every instance sees the new counts.
+func (h *Handler) ReconcilePOIParticipants(ctx context.Context) error {
+	if h.reconciler == nil || h.activeSessions == nil {
+		return nil
+	}
+
+	for _, mapID := range h.manager.GetClientMaps() {
+		sessions, err := h.activeSessions.GetActiveSessionsForMap(ctx, mapID)
+		if err != nil {
+			return fmt.Errorf("failed to get active sessions for map %s: %w", mapID, err)
+		}
+
+		// Local clients count even if their heartbeat presence lapsed
+		activeUserIDs := h.manager.GetMapClientUserIDs(mapID)
+		for _, session := range sessions {
+			activeUserIDs = append(activeUserIDs, session.UserID)
+		}
+
+		removed, err := h.reconciler.ReconcileParticipants(ctx, mapID, activeUserIDs)
+		if removed > 0 {
+			h.logger.Info("🧹 Removed stale POI participants", "mapId", mapID, "count", removed)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to reconcile POI participants of map %s: %w", mapID, err)
+		}
+	}
+
+	return nil
+}
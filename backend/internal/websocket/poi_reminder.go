@@ -0,0 +1,32 @@
+package websocket
+
+import "time"
+
+// handlePOIReminderEvent reminds the users on this instance who RSVP'd to a
+// scheduled POI that it starts soon
+func (h *Handler) handlePOIReminderEvent(data interface{}) {
+	reminderData, ok := data.(map[string]interface{})
+	if !ok {
+		h.logger.Error("❌ Invalid POI reminder event data", "data", data)
+		return
+	}
+
+	userIDs, _ := reminderData["userIds"].([]string)
+	message := Message{
+		Type: "poi_reminder",
+		Data: map[string]interface{}{
+			"poiId":    reminderData["poiId"],
+			"mapId":    reminderData["mapId"],
+			"name":     reminderData["name"],
+			"startsAt": reminderData["startsAt"],
+		},
+		Timestamp: time.Now(),
+	}
+
+	// Users without a connection miss the reminder
+	for _, userID := range userIDs {
+		h.manager.BroadcastToUser(userID, message, "")
+	}
+
+	h.logger.Info("⏰ Sent POI reminder", "poiId", reminderData["poiId"], "mapId", reminderData["mapId"], "users", len(userIDs))
+}
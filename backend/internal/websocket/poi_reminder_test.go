@@ -0,0 +1,31 @@
+package websocket
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/redis"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_POIReminderEvent(t *testing.T) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, nil)
+	defer handler.manager.Shutdown()
+
+	going := newDisconnectTestClient(handler, "conn-1", "session-1", "user-1", "map-1")
+	other := newDisconnectTestClient(handler, "conn-2", "session-2", "user-2", "map-1")
+
+	startsAt := time.Date(2024, 5, 1, 8, 15, 0, 0, time.UTC)
+	data, err := json.Marshal(redis.POIReminderEvent{POIID: "poi-1", MapID: "map-1", Name: "Standup", StartsAt: startsAt, UserIDs: []string{"user-1", "user-3"}})
+	require.NoError(t, err)
+	redis.DispatchPOIEvent(redis.Event{Type: redis.EventTypePOIReminder, Data: data}, handler.handlePubSubEvent)
+
+	require.Len(t, going.Send, 1)
+	message := <-going.Send
+	assert.Equal(t, "poi_reminder", message.Type)
+	assert.Equal(t, map[string]interface{}{"poiId": "poi-1", "mapId": "map-1", "name": "Standup", "startsAt": startsAt}, message.Data)
+	assert.Empty(t, other.Send)
+}
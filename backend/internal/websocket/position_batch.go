@@ -0,0 +1,193 @@
+package websocket
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"breakoutglobe/internal/models"
+)
+
+// positionBatcher coalesces the avatar moves of each map between flushes,
+// keeping only the latest move of every session
+type positionBatcher struct {
+	mutex    sync.Mutex
+	interval time.Duration
+	pending  map[string]map[string]Message // mapID -> sessionID -> latest avatar_moved
+	stop     chan struct{}
+	// onlyWhileDegraded batches moves only while load shedding degrades
+	// broadcasts, for hubs without position batching
+	onlyWhileDegraded bool
+}
+
+func newPositionBatcher(interval time.Duration) *positionBatcher {
+	return &positionBatcher{
+		interval: interval,
+		pending:  make(map[string]map[string]Message),
+		stop:     make(chan struct{}),
+	}
+}
+
+// add queues a move, replacing the session's earlier move in the same batch
+// but keeping its publish time, so delivery latency includes the wait
+func (b *positionBatcher) add(mapID, sessionID string, message Message) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.pending[mapID] == nil {
+		b.pending[mapID] = make(map[string]Message)
+	}
+	if previous, exists := b.pending[mapID][sessionID]; exists {
+		message.publishedAt = previous.publishedAt
+	}
+	b.pending[mapID][sessionID] = message
+}
+
+// remove drops the queued move of a session that disconnected
+func (b *positionBatcher) remove(mapID, sessionID string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	delete(b.pending[mapID], sessionID)
+	if len(b.pending[mapID]) == 0 {
+		delete(b.pending, mapID)
+	}
+}
+
+// drain returns the queued moves per map, ordered by session ID
+func (b *positionBatcher) drain() map[string][]Message {
+	b.mutex.Lock()
+	pending := b.pending
+	b.pending = make(map[string]map[string]Message)
+	b.mutex.Unlock()
+
+	batches := make(map[string][]Message, len(pending))
+	for mapID, moves := range pending {
+		sessionIDs := make([]string, 0, len(moves))
+		for sessionID := range moves {
+			sessionIDs = append(sessionIDs, sessionID)
+		}
+		sort.Strings(sessionIDs)
+
+		batch := make([]Message, 0, len(moves))
+		for _, sessionID := range sessionIDs {
+			batch = append(batch, moves[sessionID])
+		}
+		batches[mapID] = batch
+	}
+	return batches
+}
+
+// positionsBatchMessage combines avatar_moved broadcasts into one
+// avatar_positions_batch. Recipients skip their own session's position.
+func positionsBatchMessage(moves []Message) Message {
+	positions := make([]interface{}, 0, len(moves))
+	coarse := make([]interface{}, 0, len(moves))
+	hasCoarse := false
+	var publishedAt time.Time
+	// The batch reaches every viewport one of its moves happens in
+	var at, coarseAt []models.LatLng
+	unbound := false
+	for _, move := range moves {
+		if len(move.positions) == 0 {
+			unbound = true
+		}
+		at = append(at, move.positions...)
+		if move.coarsePositions != nil {
+			coarseAt = append(coarseAt, move.coarsePositions...)
+		} else {
+			coarseAt = append(coarseAt, move.positions...)
+		}
+		positions = append(positions, move.Data)
+		if move.coarseData != nil {
+			coarse = append(coarse, move.coarseData)
+			hasCoarse = true
+		} else {
+			coarse = append(coarse, move.Data)
+		}
+		if publishedAt.IsZero() || move.publishedAt.Before(publishedAt) {
+			publishedAt = move.publishedAt
+		}
+	}
+
+	message := Message{
+		Type:        "avatar_positions_batch",
+		Data:        map[string]interface{}{"positions": positions},
+		Timestamp:   time.Now(),
+		publishedAt: publishedAt,
+	}
+	if hasCoarse {
+		message.coarseData = map[string]interface{}{"positions": coarse}
+	}
+	if !unbound {
+		message.positions = at
+		if hasCoarse {
+			message.coarsePositions = coarseAt
+		}
+	}
+	return message
+}
+
+// SetPositionBatchInterval coalesces the avatar moves of each map into one
+// avatar_positions_batch broadcast per interval, so large maps don't fan out
+// every move. Zero broadcasts every move as avatar_moved.
+func (m *Manager) SetPositionBatchInterval(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	batcher := newPositionBatcher(interval)
+	m.mutex.Lock()
+	m.positions = batcher
+	m.mutex.Unlock()
+
+	go m.runPositionBatches(batcher)
+}
+
+// BroadcastAvatarMove broadcasts an avatar_moved message to the map except the
+// moving session, or queues it for the map's next position batch
+func (m *Manager) BroadcastAvatarMove(mapID, sessionID string, message Message) {
+	m.mutex.RLock()
+	batcher := m.positions
+	m.mutex.RUnlock()
+
+	if batcher == nil {
+		m.BroadcastToMapExcept(mapID, sessionID, message)
+		return
+	}
+	if batcher.onlyWhileDegraded && !m.degraded.Load() {
+		// A move queued while degraded would otherwise overwrite this newer one
+		batcher.remove(mapID, sessionID)
+		m.BroadcastToMapExcept(mapID, sessionID, message)
+		return
+	}
+	batcher.add(mapID, sessionID, stampPublished(message))
+}
+
+// runPositionBatches broadcasts the queued moves of every map each interval,
+// or less often while load shedding stretches the interval
+func (m *Manager) runPositionBatches(batcher *positionBatcher) {
+	ticker := time.NewTicker(batcher.interval)
+	defer ticker.Stop()
+
+	var flushedAt time.Time
+	for {
+		select {
+		case <-batcher.stop:
+			return
+		case now := <-ticker.C:
+			if now.Sub(flushedAt) < m.movementInterval(batcher) {
+				continue
+			}
+			flushedAt = now
+			m.flushPositionBatches(batcher)
+		}
+	}
+}
+
+// flushPositionBatches broadcasts one avatar_positions_batch per map with queued moves
+func (m *Manager) flushPositionBatches(batcher *positionBatcher) {
+	for mapID, moves := range batcher.drain() {
+		m.BroadcastToMap(mapID, positionsBatchMessage(moves))
+	}
+}
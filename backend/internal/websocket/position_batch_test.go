@@ -0,0 +1,79 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_BroadcastAvatarMove_Batches(t *testing.T) {
+	manager := NewManager()
+	defer manager.Shutdown()
+	// Flushed by the test rather than the ticker
+	manager.positions = newPositionBatcher(time.Hour)
+	client := &Client{SessionID: "session-9", MapID: "map-1", Send: make(chan Message, 4), movement: make(chan Message, 4)}
+	manager.mapClients["map-1"] = map[string]*Client{"session-9": client}
+
+	move := func(sessionID string, lat float64) Message {
+		return Message{Type: "avatar_moved", Data: map[string]interface{}{"sessionId": sessionID, "position": models.LatLng{Lat: lat, Lng: 13.4}}}
+	}
+	manager.BroadcastAvatarMove("map-1", "session-2", move("session-2", 52.1))
+	manager.BroadcastAvatarMove("map-1", "session-1", move("session-1", 52.2))
+	manager.BroadcastAvatarMove("map-1", "session-1", move("session-1", 52.3))
+	assert.Empty(t, client.movement)
+
+	manager.flushPositionBatches(manager.positions)
+
+	select {
+	case batch := <-client.movement:
+		require.Equal(t, "avatar_positions_batch", batch.Type)
+		positions := batch.Data.(map[string]interface{})["positions"].([]interface{})
+		require.Len(t, positions, 2)
+		// Only the latest move of each session is sent
+		assert.Equal(t, move("session-1", 52.3).Data, positions[0])
+		assert.Equal(t, move("session-2", 52.1).Data, positions[1])
+	case <-time.After(time.Second):
+		t.Fatal("no position batch broadcast")
+	}
+
+	// Nothing queued, nothing sent
+	manager.flushPositionBatches(manager.positions)
+	select {
+	case msg := <-client.movement:
+		t.Fatalf("unexpected broadcast %s", msg.Type)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestPositionBatcher_RemoveDropsQueuedMove(t *testing.T) {
+	batcher := newPositionBatcher(time.Hour)
+	batcher.add("map-1", "session-1", Message{Type: "avatar_moved"})
+	batcher.add("map-1", "session-2", Message{Type: "avatar_moved"})
+
+	batcher.remove("map-1", "session-1")
+
+	batches := batcher.drain()
+	assert.Len(t, batches["map-1"], 1)
+	assert.Empty(t, batcher.drain())
+}
+
+func TestPositionsBatchMessage_KeepsCoarsePositions(t *testing.T) {
+	privacy := models.PositionPrivacy{GridMeters: 500}
+	position := models.LatLng{Lat: 52.52003, Lng: 13.40495}
+	published := time.Now().Add(-time.Second)
+	exact := Message{Type: "avatar_moved", Data: map[string]interface{}{"sessionId": "session-1", "position": position}, publishedAt: published}
+
+	batch := positionsBatchMessage([]Message{
+		withCoarsePosition(exact, privacy, position),
+		{Type: "avatar_moved", Data: map[string]interface{}{"sessionId": "session-2"}, publishedAt: time.Now()},
+	})
+
+	coarse := batch.coarseData.(map[string]interface{})["positions"].([]interface{})
+	require.Len(t, coarse, 2)
+	assert.NotEqual(t, position, coarse[0].(map[string]interface{})["position"])
+	assert.Equal(t, published, batch.publishedAt)
+}
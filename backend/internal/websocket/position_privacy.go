@@ -0,0 +1,66 @@
+package websocket
+
+import (
+	"context"
+
+	"breakoutglobe/internal/models"
+)
+
+// positionPrivacy returns the map's position privacy setting, or no privacy if
+// it can't be resolved
+func (h *Handler) positionPrivacy(ctx context.Context, mapID string) models.PositionPrivacy {
+	if h.privacy == nil {
+		return models.PositionPrivacy{}
+	}
+
+	privacy, err := h.privacy.GetPositionPrivacy(ctx, mapID)
+	if err != nil {
+		h.requestLogger(ctx).Warn("Failed to get position privacy",
+			"mapId", mapID,
+			"error", err.Error())
+		return models.PositionPrivacy{}
+	}
+
+	return privacy
+}
+
+// withCoarsePosition sets the payload regular participants receive instead of
+// the message's exact one, if the map has position privacy enabled
+func withCoarsePosition(message Message, privacy models.PositionPrivacy, position models.LatLng) Message {
+	data, ok := message.Data.(map[string]interface{})
+	if !ok || !privacy.Enabled() {
+		return message
+	}
+
+	message.coarseData = coarsePositionData(data, privacy, position)
+	return message
+}
+
+// coarsePositionData copies an avatar payload with its position rounded to
+// the privacy grid
+func coarsePositionData(data map[string]interface{}, privacy models.PositionPrivacy, position models.LatLng) map[string]interface{} {
+	coarse := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		coarse[key] = value
+	}
+	coarsened := privacy.Coarsen(position)
+	coarse["position"] = map[string]float64{
+		"lat": coarsened.Lat,
+		"lng": coarsened.Lng,
+	}
+	return coarse
+}
+
+// isFacilitator reports whether the client sees exact avatar positions. The
+// caller must hold the manager's mutex.
+func (c *Client) isFacilitator() bool {
+	return c.role == models.UserRoleAdmin || c.role == models.UserRoleSuperAdmin
+}
+
+// IsFacilitator reports whether a client is a facilitator on its map
+func (m *Manager) IsFacilitator(client *Client) bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return client.isFacilitator()
+}
@@ -0,0 +1,45 @@
+package websocket
+
+import (
+	"testing"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_BroadcastToMap_CoarsensPositionsForParticipants(t *testing.T) {
+	manager := NewManager()
+	defer manager.Shutdown()
+	journal := &fakeEventJournal{maxLen: 10}
+	manager.SetEventJournal(journal)
+	participant := &Client{SessionID: "session-1", MapID: "map-1", Send: make(chan Message, 4), movement: make(chan Message, 4), role: models.UserRoleUser}
+	facilitator := &Client{SessionID: "session-2", MapID: "map-1", Send: make(chan Message, 4), movement: make(chan Message, 4), role: models.UserRoleAdmin}
+	manager.mapClients["map-1"] = map[string]*Client{"session-1": participant, "session-2": facilitator}
+
+	privacy := models.PositionPrivacy{GridMeters: 500}
+	position := models.LatLng{Lat: 52.52003, Lng: 13.40495}
+	coarse := privacy.Coarsen(position)
+
+	moved := withCoarsePosition(Message{Type: "avatar_moved", Data: map[string]interface{}{"sessionId": "session-3", "position": position}}, privacy, position)
+	manager.broadcastToMap(BroadcastMessage{MapID: "map-1", Message: moved})
+
+	assert.Equal(t, map[string]float64{"lat": coarse.Lat, "lng": coarse.Lng}, (<-participant.movement).Data.(map[string]interface{})["position"])
+	assert.Equal(t, position, (<-facilitator.movement).Data.(map[string]interface{})["position"])
+
+	// Replays go to everyone, so the journal only has the coarse position
+	joined := withCoarsePosition(Message{Type: "user_joined", Data: map[string]interface{}{"sessionId": "session-3", "position": position}}, privacy, position)
+	manager.broadcastToMap(BroadcastMessage{MapID: "map-1", Message: joined})
+	waitForJournal(t, manager)
+	require.Len(t, journal.events, 1)
+	assert.Contains(t, string(journal.events[0].Data), "session-3")
+	assert.NotContains(t, string(journal.events[0].Data), "52.52003")
+}
+
+func TestWithCoarsePosition_Disabled(t *testing.T) {
+	position := models.LatLng{Lat: 52.52003, Lng: 13.40495}
+	message := withCoarsePosition(Message{Type: "avatar_moved", Data: map[string]interface{}{"position": position}}, models.PositionPrivacy{}, position)
+
+	assert.Nil(t, message.coarseData)
+}
@@ -0,0 +1,144 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// nonCriticalNotificationTypes are withheld from users while they present.
+// Calls, moderation and connection messages still reach them.
+var nonCriticalNotificationTypes = map[string]bool{
+	"poi_reminder":        true,
+	"onboarding_progress": true,
+}
+
+// PresentingStatePayload is the data of presenting_state messages
+type PresentingStatePayload struct {
+	Presenting *bool `json:"presenting"`
+}
+
+// Validate checks that the state is set
+func (p PresentingStatePayload) Validate() error {
+	if p.Presenting == nil {
+		return errors.New("presenting must be a boolean")
+	}
+	return nil
+}
+
+// setPresenting records whether a user presents. It returns false if the
+// state didn't change.
+func (m *Manager) setPresenting(userID string, presenting bool) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.presenting[userID] == presenting {
+		return false
+	}
+	if presenting {
+		m.presenting[userID] = true
+	} else {
+		delete(m.presenting, userID)
+	}
+	return true
+}
+
+// IsPresenting reports whether a user presents, like sharing their screen
+func (m *Manager) IsPresenting(userID string) bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.presenting[userID]
+}
+
+// withheldWhilePresenting reports whether a message to a user is withheld
+// because they present. The caller must hold the lock.
+func (m *Manager) withheldWhilePresenting(userID string, message Message) bool {
+	return m.presenting[userID] && nonCriticalNotificationTypes[message.Type]
+}
+
+// userConnected reports whether a user still has connections on any map
+func (m *Manager) userConnected(userID string) bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	for _, users := range m.userConnections {
+		if users[userID] > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// validatePresentingState validates presenting_state messages
+func validatePresentingState(msg Message) error {
+	var payload PresentingStatePayload
+	if err := decodePayload(msg, &payload); err != nil {
+		return err
+	}
+	return payload.Validate()
+}
+
+// handlePresentingState sets whether the user presents. Presenting users
+// have incoming calls declined with call_declined_presenting and don't get
+// non-critical notifications.
+func (h *Handler) handlePresentingState(ctx context.Context, client *Client, msg Message) {
+	var payload PresentingStatePayload
+	if !h.decodeMessagePayload(client, msg, &payload) {
+		return
+	}
+	h.setPresenting(client.MapID, client.UserID, *payload.Presenting)
+}
+
+// handleScreenShareEnded stops presenting once the user's screen share ends
+func (h *Handler) handleScreenShareEnded(ctx context.Context, client *Client, msg Message) {
+	h.setPresenting(client.MapID, client.UserID, false)
+}
+
+// setPresenting changes whether a user presents, confirms it to all their
+// connections and shows it to the map
+func (h *Handler) setPresenting(mapID, userID string, presenting bool) {
+	if !h.manager.setPresenting(userID, presenting) {
+		return
+	}
+
+	h.logger.Info("🖥️ Presenting state changed", "userId", userID, "mapId", mapID, "presenting", presenting)
+
+	status := Message{
+		Type: "user_presenting_status",
+		Data: map[string]interface{}{
+			"userId":     userID,
+			"presenting": presenting,
+		},
+		Timestamp: time.Now(),
+	}
+	h.manager.BroadcastToUser(userID, status, "")
+	h.manager.BroadcastToMap(mapID, onTopics(status, userTopic(userID)))
+}
+
+// declineWhilePresenting answers a call request to a presenting user with
+// call_declined_presenting. It returns true if the call was declined.
+func (h *Handler) declineWhilePresenting(ctx context.Context, client *Client, callID, targetUserID string) bool {
+	if !h.manager.IsPresenting(targetUserID) {
+		return false
+	}
+
+	h.requestLogger(ctx).Info("🖥️ Call declined, target is presenting",
+		"callId", callID,
+		"caller", client.UserID,
+		"target", targetUserID)
+
+	select {
+	case client.Send <- replyTo(ctx, Message{
+		Type: "call_declined_presenting",
+		Data: map[string]interface{}{
+			"callId":       callID,
+			"targetUserId": targetUserID,
+		},
+		Timestamp: time.Now(),
+	}):
+	default:
+		h.requestLogger(ctx).Warn("Failed to send call_declined_presenting", "sessionId", client.SessionID)
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+package websocket
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_PresentingDeclinesCalls(t *testing.T) {
+	handler, caller, callee := newCallRingTestHandler(t, 0)
+
+	handler.handlePresentingState(context.Background(), callee, Message{Type: "presenting_state", Data: map[string]interface{}{"presenting": true}})
+	assert.True(t, handler.manager.IsPresenting("user-2"))
+	status := receiveType(t, callee, "user_presenting_status")
+	assert.Equal(t, map[string]interface{}{"userId": "user-2", "presenting": true}, status.Data)
+
+	handler.handleCallRequest(context.Background(), caller, Message{Type: "call_request", Data: map[string]interface{}{
+		"callId":       "call-1",
+		"targetUserId": "user-2",
+	}})
+	declined := receiveType(t, caller, "call_declined_presenting")
+	assert.Equal(t, map[string]interface{}{"callId": "call-1", "targetUserId": "user-2"}, declined.Data)
+	for len(callee.Send) > 0 {
+		assert.NotEqual(t, "call_request", (<-callee.Send).Type)
+	}
+
+	// Calls ring again once the screen share ended
+	handler.handleScreenShareEnded(context.Background(), callee, Message{Type: "screen_share_ended"})
+	assert.False(t, handler.manager.IsPresenting("user-2"))
+	handler.handleCallRequest(context.Background(), caller, Message{Type: "call_request", Data: map[string]interface{}{
+		"callId":       "call-2",
+		"targetUserId": "user-2",
+	}})
+	assert.Equal(t, "call-2", receiveType(t, callee, "call_request").Data.(map[string]interface{})["callId"])
+}
+
+func TestManager_PresentingWithholdsNonCriticalNotifications(t *testing.T) {
+	manager := NewManager()
+	defer manager.Shutdown()
+
+	client := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 10)}
+	manager.registerClient(client)
+	require.True(t, manager.setPresenting("user-1", true))
+	assert.False(t, manager.setPresenting("user-1", true))
+
+	manager.BroadcastToUser("user-1", Message{Type: "poi_reminder"}, "")
+	manager.SendToUserOnMap("map-1", "user-1", Message{Type: "onboarding_progress"})
+	assert.Empty(t, client.Send)
+
+	// Critical messages still get through
+	manager.BroadcastToUser("user-1", Message{Type: "restriction_applied"}, "")
+	assert.Len(t, client.Send, 1)
+
+	manager.setPresenting("user-1", false)
+	manager.BroadcastToUser("user-1", Message{Type: "poi_reminder"}, "")
+	assert.Len(t, client.Send, 2)
+}
+
+func TestPresentingStatePayload_Validate(t *testing.T) {
+	assert.Error(t, validatePresentingState(Message{Type: "presenting_state", Data: map[string]interface{}{}}))
+	assert.NoError(t, validatePresentingState(Message{Type: "presenting_state", Data: map[string]interface{}{"presenting": false}}))
+}
@@ -0,0 +1,106 @@
+package websocket
+
+// messagePriority is the lane an outbound message is queued in. The write pump
+// drains higher lanes first, so a flood of avatar movement never delays call
+// signaling.
+type messagePriority int
+
+const (
+	// priorityMovement carries avatar movement, which is superseded quickly
+	priorityMovement messagePriority = iota
+	// priorityDefault carries POI, chat and all other messages
+	priorityDefault
+	// prioritySignaling carries call signaling and system messages
+	prioritySignaling
+
+	laneCount = 3
+)
+
+// String returns the lane name sent with broadcasts
+func (p messagePriority) String() string {
+	switch p {
+	case priorityMovement:
+		return "movement"
+	case prioritySignaling:
+		return "signaling"
+	default:
+		return "default"
+	}
+}
+
+// signalingLaneSize is the buffer of the signaling lane. Its messages are rare,
+// so it doesn't follow the map's send buffer size.
+const signalingLaneSize = 64
+
+// signalingMessageTypes are sent ahead of everything else
+var signalingMessageTypes = map[string]bool{
+	"welcome":                  true,
+	"error":                    true,
+	"upgrade_required":         true,
+	"server_shutdown":          true,
+	"rate_limit_warning":       true,
+	"client_lagging":           true,
+	"role_changed":             true,
+	"map_frozen":               true,
+	"map_unfrozen":             true,
+	"maintenance":              true,
+	"restriction_applied":      true,
+	"moderation_alert":         true,
+	"poi_removed_you":          true,
+	"call_request":             true,
+	"call_accept":              true,
+	"call_reject":              true,
+	"call_end":                 true,
+	"call_timeout":             true,
+	"call_declined_presenting": true,
+	"webrtc_offer":             true,
+	"webrtc_answer":            true,
+	"ice_candidate":            true,
+	"poi_call_offer":           true,
+	"poi_call_answer":          true,
+	"poi_call_ice_candidate":   true,
+}
+
+// movementMessageTypes are sent once nothing else is queued
+var movementMessageTypes = map[string]bool{
+	"avatar_move":     true,
+	"avatar_moved":    true,
+	"avatar_move_ack": true,
+	// camera_follow moves the map views of a facilitator's followers
+	"camera_follow": true,
+	// avatar_positions_batch replaces avatar_moved when position batching is enabled
+	"avatar_positions_batch": true,
+}
+
+// priorityOf returns the lane of an outbound message type
+func priorityOf(messageType string) messagePriority {
+	switch {
+	case signalingMessageTypes[messageType]:
+		return prioritySignaling
+	case movementMessageTypes[messageType]:
+		return priorityMovement
+	default:
+		return priorityDefault
+	}
+}
+
+// lane returns the channel a message to the client is queued in
+func (c *Client) lane(message Message) chan Message {
+	return c.laneFor(priorityOf(message.Type))
+}
+
+// laneFor returns the channel of a priority. Clients without separate lanes
+// queue everything in Send.
+func (c *Client) laneFor(priority messagePriority) chan Message {
+	switch priority {
+	case prioritySignaling:
+		if c.signaling != nil {
+			return c.signaling
+		}
+	case priorityMovement:
+		if c.movement != nil {
+			return c.movement
+		}
+	}
+	return c.Send
+}
@@ -0,0 +1,96 @@
+package websocket
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"breakoutglobe/internal/models"
+
+	ws "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriorityOf(t *testing.T) {
+	assert.Equal(t, prioritySignaling, priorityOf("call_accept"))
+	assert.Equal(t, prioritySignaling, priorityOf("poi_call_ice_candidate"))
+	assert.Equal(t, priorityDefault, priorityOf("poi_created"))
+	assert.Equal(t, priorityDefault, priorityOf("unknown"))
+	assert.Equal(t, priorityMovement, priorityOf("avatar_moved"))
+}
+
+func TestClient_Lane_FallsBackToSend(t *testing.T) {
+	client := &Client{Send: make(chan Message, 1)}
+	assert.Equal(t, client.Send, client.lane(Message{Type: "call_accept"}))
+	assert.Equal(t, client.Send, client.lane(Message{Type: "avatar_moved"}))
+}
+
+func TestWritePump_DrainsLanesByPriority(t *testing.T) {
+	client := &Client{
+		Send:      make(chan Message, 8),
+		signaling: make(chan Message, 8),
+		movement:  make(chan Message, 8),
+	}
+
+	// A movement flood queued before a call is accepted
+	manager := NewManager()
+	defer manager.Shutdown()
+	for i := 0; i < 5; i++ {
+		manager.deliver(client, Message{Type: "avatar_moved"})
+	}
+	manager.deliver(client, Message{Type: "poi_created"})
+	manager.deliver(client, Message{Type: "call_accept"})
+
+	upgrader := ws.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		client.Conn = conn
+		client.writePump()
+	}))
+	defer server.Close()
+
+	conn, _, err := ws.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var types []string
+	for len(types) < 7 {
+		var msg Message
+		require.NoError(t, conn.ReadJSON(&msg))
+		types = append(types, msg.Type)
+	}
+	close(client.Send)
+
+	assert.Equal(t, []string{"call_accept", "poi_created", "avatar_moved", "avatar_moved", "avatar_moved", "avatar_moved", "avatar_moved"}, types)
+}
+
+func TestManager_Deliver_NumbersBroadcastsPerLane(t *testing.T) {
+	manager := NewManager()
+	defer manager.Shutdown()
+	client := &Client{
+		Send:         make(chan Message, 1),
+		signaling:    make(chan Message, 8),
+		movement:     make(chan Message, 8),
+		slowConsumer: models.SlowConsumerPolicy{DropPolicy: models.SlowConsumerDropNewest},
+	}
+
+	manager.deliver(client, Message{Type: "avatar_moved"})
+	manager.deliver(client, Message{Type: "poi_created"})
+	manager.deliver(client, Message{Type: "avatar_moved"})
+	// Dropped, so the client sees a gap in the default lane
+	manager.deliver(client, Message{Type: "poi_updated"})
+	<-client.Send
+	manager.deliver(client, Message{Type: "poi_deleted"})
+
+	first, second := <-client.movement, <-client.movement
+	assert.Equal(t, uint64(1), first.Seq)
+	assert.Equal(t, uint64(2), second.Seq)
+	assert.Equal(t, "movement", second.Lane)
+
+	deleted := <-client.Send
+	assert.Equal(t, uint64(3), deleted.Seq)
+	assert.Equal(t, "default", deleted.Lane)
+}
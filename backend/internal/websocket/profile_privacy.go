@@ -0,0 +1,54 @@
+package websocket
+
+import (
+	"context"
+
+	"breakoutglobe/internal/models"
+)
+
+// ProfilePrivacyProviderInterface defines the interface for per-map profile privacy settings
+type ProfilePrivacyProviderInterface interface {
+	GetProfilePrivacy(ctx context.Context, mapID string) (models.ProfilePrivacy, error)
+}
+
+// SetProfilePrivacyProvider leaves the profile fields a map hides out of
+// user_joined, initial_users, initial_state, user_profile_updated and POI
+// participant payloads. Without it, every field is shared.
+func (h *Handler) SetProfilePrivacyProvider(provider ProfilePrivacyProviderInterface) {
+	h.profilePrivacy = provider
+}
+
+// mapProfilePrivacy returns the map's profile privacy setting, or no privacy
+// if it can't be resolved
+func (h *Handler) mapProfilePrivacy(ctx context.Context, mapID string) models.ProfilePrivacy {
+	if h.profilePrivacy == nil {
+		return models.ProfilePrivacy{}
+	}
+
+	privacy, err := h.profilePrivacy.GetProfilePrivacy(ctx, mapID)
+	if err != nil {
+		h.requestLogger(ctx).Warn("Failed to get profile privacy",
+			"mapId", mapID,
+			"error", err.Error())
+		return models.ProfilePrivacy{}
+	}
+
+	return privacy
+}
+
+// redactProfile clears the profile fields the map hides in a user or
+// participant payload. The fields stay present, so clients see them as unset.
+func redactProfile(data map[string]interface{}, privacy models.ProfilePrivacy) {
+	if privacy.HideAboutMe {
+		if _, ok := data["aboutMe"]; ok {
+			data["aboutMe"] = nil
+		}
+	}
+	if privacy.HideAvatarURL {
+		for _, key := range []string{"avatarURL", "avatarUrl"} {
+			if _, ok := data[key]; ok {
+				data[key] = nil
+			}
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package websocket
+
+import (
+	"context"
+	"testing"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type stubProfileUserService struct {
+	users map[string]*models.User
+}
+
+func (s *stubProfileUserService) GetUser(ctx context.Context, userID string) (*models.User, error) {
+	return s.users[userID], nil
+}
+
+type stubProfilePrivacyProvider struct {
+	privacy map[string]models.ProfilePrivacy
+}
+
+func (s *stubProfilePrivacyProvider) GetProfilePrivacy(ctx context.Context, mapID string) (models.ProfilePrivacy, error) {
+	return s.privacy[mapID], nil
+}
+
+func TestHandler_PresenceUser_RedactsHiddenProfileFields(t *testing.T) {
+	avatarURL := "https://example.com/avatar.png"
+	aboutMe := "Coffee enthusiast"
+	sessionService := new(MockSessionService)
+	userService := &stubProfileUserService{users: map[string]*models.User{
+		"user-1": {ID: "user-1", DisplayName: "Ada", AvatarURL: &avatarURL, AboutMe: &aboutMe},
+	}}
+	handler := NewHandler(sessionService, new(MockRateLimiter), userService, nil)
+	t.Cleanup(handler.manager.Shutdown)
+	sessionService.On("GetSession", mock.Anything, "session-1").Return(&models.Session{ID: "session-1", UserID: "user-1", MapID: "map-1", IsActive: true}, nil)
+
+	userData, ok := handler.presenceUser(context.Background(), "session-1", models.PositionPrivacy{})
+	require.True(t, ok)
+	assert.Equal(t, &avatarURL, userData["avatarURL"])
+	assert.Equal(t, &aboutMe, userData["aboutMe"])
+
+	handler.SetProfilePrivacyProvider(&stubProfilePrivacyProvider{privacy: map[string]models.ProfilePrivacy{
+		"map-1": {HideAboutMe: true},
+	}})
+	userData, ok = handler.presenceUser(context.Background(), "session-1", models.PositionPrivacy{})
+	require.True(t, ok)
+	assert.Equal(t, &avatarURL, userData["avatarURL"])
+	assert.Nil(t, userData["aboutMe"])
+	assert.Equal(t, "Ada", userData["displayName"])
+}
+
+func TestHandler_UserProfileUpdated_RedactsHiddenProfileFields(t *testing.T) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, nil)
+	t.Cleanup(handler.manager.Shutdown)
+	handler.SetProfilePrivacyProvider(&stubProfilePrivacyProvider{privacy: map[string]models.ProfilePrivacy{
+		"map-1": {HideAboutMe: true, HideAvatarURL: true},
+	}})
+	client := &Client{SessionID: "session-1", UserID: "user-2", MapID: "map-1", Send: make(chan Message, 10)}
+	handler.manager.registerClient(client)
+
+	handler.handleUserProfileUpdatedEvent(map[string]interface{}{
+		"mapId":       "map-1",
+		"userId":      "user-1",
+		"displayName": "Ada",
+		"aboutMe":     "Coffee enthusiast",
+		"avatarUrl":   "https://example.com/avatar.png",
+	})
+
+	msg := receiveType(t, client, "user_profile_updated")
+	data := msg.Data.(map[string]interface{})
+	assert.Equal(t, "Ada", data["displayName"])
+	assert.Nil(t, data["aboutMe"])
+	assert.Nil(t, data["avatarUrl"])
+}
+
+func TestSessionParticipant_RedactsHiddenAvatar(t *testing.T) {
+	avatarURL := "https://example.com/avatar.png"
+	session := &models.Session{UserID: "user-1", User: &models.User{ID: "user-1", AvatarURL: &avatarURL}}
+
+	assert.Equal(t, avatarURL, sessionParticipant(session, "user-1", "Ada", models.ProfilePrivacy{})["avatarUrl"])
+
+	participant := sessionParticipant(session, "user-1", "Ada", models.ProfilePrivacy{HideAvatarURL: true})
+	assert.Nil(t, participant["avatarUrl"])
+	assert.Equal(t, "Ada", participant["name"])
+}
@@ -0,0 +1,188 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	ws "github.com/gorilla/websocket"
+)
+
+// ProtocolVersion is the newest version of the WebSocket message protocol. It
+// is increased on changes that clients must be updated for.
+const ProtocolVersion = 1
+
+// MinProtocolVersion is the oldest protocol version the server still speaks.
+// Clients below it get a protocol_mismatch message when connecting.
+const MinProtocolVersion = 1
+
+// legacyProtocolVersion is the version of clients that don't send one, which
+// predate versioned envelopes
+const legacyProtocolVersion = 1
+
+// ProtocolMismatchCloseCode is the close code sent after a protocol_mismatch
+// message to clients whose protocol version isn't supported
+const ProtocolMismatchCloseCode = 4505
+
+// errUnsupportedProtocolVersion is returned for messages of a protocol version
+// the server doesn't speak
+var errUnsupportedProtocolVersion = errors.New("unsupported protocol version")
+
+// messageHandler validates and handles a message type of a protocol version
+type messageHandler struct {
+	validate func(msg Message) error
+	handle   func(h *Handler, ctx context.Context, client *Client, msg Message)
+}
+
+// messageRegistry holds the message types clients can send, by protocol
+// version. A new version gets its own table, so changing a message's schema
+// doesn't break clients still speaking an older version.
+var messageRegistry = map[int]map[string]messageHandler{
+	1: {
+		"heartbeat":              {validateNoData, (*Handler).handleHeartbeat},
+		"auth_refresh":           {validateAuthRefresh, (*Handler).handleAuthRefresh},
+		"avatar_move":            {validateAvatarMove, (*Handler).handleAvatarMove},
+		"avatar_move_batch":      {validateAvatarMoveBatch, (*Handler).handleAvatarMoveBatch},
+		"request_initial_users":  {validateNoData, (*Handler).handleInitialUsersRequest},
+		"resync":                 {validateNoData, (*Handler).handleResync},
+		"resync_from":            {validateResyncFrom, (*Handler).handleResyncFrom},
+		"viewport_update":        {validateViewportUpdate, (*Handler).handleViewportUpdate},
+		"poi_join":               {validatePOIMembership, (*Handler).handlePOIJoin},
+		"poi_leave":              {validatePOIMembership, (*Handler).handlePOILeave},
+		"speaking_state":         {validateSpeakingState, (*Handler).handleSpeakingState},
+		"call_request":           {validateCallRequest, (*Handler).handleCallRequest},
+		"call_accept":            {validateCallResponse, (*Handler).handleCallAccept},
+		"call_reject":            {validateCallResponse, (*Handler).handleCallReject},
+		"call_end":               {validateCallEnd, (*Handler).handleCallEnd},
+		"presenting_state":       {validatePresentingState, (*Handler).handlePresentingState},
+		"screen_share_ended":     {validateNoData, (*Handler).handleScreenShareEnded},
+		"webrtc_offer":           {validateWebRTCDescription, (*Handler).handleWebRTCOffer},
+		"webrtc_answer":          {validateWebRTCDescription, (*Handler).handleWebRTCAnswer},
+		"ice_candidate":          {validateICECandidate, (*Handler).handleICECandidate},
+		"poi_call_offer":         {validatePOICallDescription, (*Handler).handlePOICallOffer},
+		"poi_call_answer":        {validatePOICallDescription, (*Handler).handlePOICallAnswer},
+		"poi_call_ice_candidate": {validatePOICallICECandidate, (*Handler).handlePOICallICECandidate},
+		"map_freeze":             {validateMapFreeze, (*Handler).handleMapFreeze},
+		"map_unfreeze":           {validateNoData, (*Handler).handleMapUnfreeze},
+		"subscribe":              {validateTopic, (*Handler).handleSubscribe},
+		"unsubscribe":            {validateTopic, (*Handler).handleUnsubscribe},
+		"reaction":               {validateReaction, (*Handler).handleReaction},
+		"follow_start":           {validateNoData, (*Handler).handleFollowStart},
+		"follow_stop":            {validateNoData, (*Handler).handleFollowStop},
+		"hand_raise":             {validateNoData, (*Handler).handleHandRaise},
+		"hand_lower":             {validateHandLower, (*Handler).handleHandLower},
+		"summon_to_poi":          {validateSummonToPOI, (*Handler).handleSummonToPOI},
+		"kick_user":              {validateKickUser, (*Handler).handleKickUser},
+		"activity_ping":          {validateNoData, (*Handler).handleActivityPing},
+	},
+}
+
+// lookupMessageHandler returns the handler of a message type in a protocol version
+func lookupMessageHandler(version int, messageType string) (messageHandler, bool) {
+	handler, ok := messageRegistry[version][messageType]
+	return handler, ok
+}
+
+// MessageTypes returns the message types clients can send in a protocol
+// version, sorted
+func MessageTypes(version int) []string {
+	types := make([]string, 0, len(messageRegistry[version]))
+	for messageType := range messageRegistry[version] {
+		types = append(types, messageType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// messageVersion returns the protocol version of a message, treating
+// unversioned messages as legacy
+func messageVersion(msg Message) int {
+	if msg.Version == 0 {
+		return legacyProtocolVersion
+	}
+	return msg.Version
+}
+
+// supportedProtocolVersions returns the protocol versions the server speaks, oldest first
+func supportedProtocolVersions() []int {
+	versions := []int{}
+	for version := MinProtocolVersion; version <= ProtocolVersion; version++ {
+		if _, ok := messageRegistry[version]; ok {
+			versions = append(versions, version)
+		}
+	}
+	return versions
+}
+
+// protocolVersionFromRequest returns the protocol version requested with the
+// protocolVersion query parameter or the X-Protocol-Version header
+func protocolVersionFromRequest(r *http.Request) string {
+	version := r.URL.Query().Get("protocolVersion")
+	if version == "" {
+		version = r.Header.Get("X-Protocol-Version")
+	}
+	return strings.TrimSpace(version)
+}
+
+// negotiateProtocolVersion picks the protocol version of a connection. Clients
+// send the newest version they speak and get it, or the server's newest if the
+// client is ahead. It returns false if the client is too old or the version is
+// unparsable.
+func negotiateProtocolVersion(requested string) (int, bool) {
+	if requested == "" {
+		return legacyProtocolVersion, true
+	}
+
+	version, err := strconv.Atoi(requested)
+	if err != nil || version < MinProtocolVersion {
+		return 0, false
+	}
+	if version > ProtocolVersion {
+		return ProtocolVersion, true
+	}
+	return version, true
+}
+
+// protocolMismatchMessage tells a client which protocol versions the server speaks
+func protocolMismatchMessage(requested string) Message {
+	return Message{
+		Type: "protocol_mismatch",
+		Data: map[string]interface{}{
+			"requestedVersion":  requested,
+			"supportedVersions": supportedProtocolVersions(),
+		},
+		Timestamp: time.Now(),
+	}
+}
+
+// sendProtocolMismatch tells a client that a message's protocol version isn't
+// supported. The message is dropped, but the connection stays open.
+func (h *Handler) sendProtocolMismatch(client *Client, version int) {
+	h.logger.Warn("Dropping message of unsupported protocol version",
+		"sessionId", client.SessionID,
+		"protocolVersion", version)
+
+	select {
+	case client.Send <- protocolMismatchMessage(strconv.Itoa(version)):
+	default:
+		h.logger.Warn("Failed to send protocol mismatch to client", "sessionId", client.SessionID)
+	}
+}
+
+// rejectProtocolMismatch tells a client whose protocol version isn't supported
+// which versions are, and closes the connection
+func (h *Handler) rejectProtocolMismatch(conn *ws.Conn, sessionID, requested string) {
+	defer conn.Close()
+
+	h.logger.Warn("WebSocket connection rejected: protocol mismatch",
+		"sessionId", sessionID,
+		"protocolVersion", requested)
+
+	conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	conn.WriteJSON(protocolMismatchMessage(requested))
+	conn.WriteMessage(ws.CloseMessage, ws.FormatCloseMessage(ProtocolMismatchCloseCode, "protocol_mismatch"))
+}
@@ -0,0 +1,135 @@
+package websocket
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/gin-gonic/gin"
+	ws "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateProtocolVersion(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested string
+		expected  int
+		ok        bool
+	}{
+		{"legacy client", "", legacyProtocolVersion, true},
+		{"current version", "1", 1, true},
+		{"newer client", "99", ProtocolVersion, true},
+		{"dropped version", "0", 0, false},
+		{"unparsable", "v1", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, ok := negotiateProtocolVersion(tt.requested)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, version)
+		})
+	}
+}
+
+func TestValidateMessage_ByProtocolVersion(t *testing.T) {
+	assert.NoError(t, validateMessage(Message{Type: "heartbeat", Version: 1}))
+	assert.NoError(t, validateMessage(Message{Type: "heartbeat"}), "unversioned messages are legacy")
+
+	err := validateMessage(Message{Type: "heartbeat", Version: 99})
+	assert.True(t, errors.Is(err, errUnsupportedProtocolVersion))
+
+	err = validateMessage(Message{Type: "no_such_type", Version: 1})
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, errUnsupportedProtocolVersion))
+}
+
+func TestMessageRegistry_ValidatesAndHandlesEveryType(t *testing.T) {
+	for version, handlers := range messageRegistry {
+		for messageType, handler := range handlers {
+			assert.NotNil(t, handler.validate, "version %d %s", version, messageType)
+			assert.NotNil(t, handler.handle, "version %d %s", version, messageType)
+		}
+	}
+}
+
+func TestHandler_HandleMessage_UnsupportedVersionSendsProtocolMismatch(t *testing.T) {
+	mockRateLimiter := new(MockRateLimiter)
+	mockRateLimiter.On("CheckRateLimit", mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+	handler := NewHandler(new(MockSessionService), mockRateLimiter, nil, new(MockPOIService))
+	client := &Client{
+		SessionID: "session-123",
+		UserID:    "user-456",
+		MapID:     "map-789",
+		Send:      make(chan Message, 10),
+	}
+
+	handler.handleMessage(client, Message{Type: "heartbeat", Version: 99})
+
+	select {
+	case msg := <-client.Send:
+		assert.Equal(t, "protocol_mismatch", msg.Type)
+		data, ok := msg.Data.(map[string]interface{})
+		require.True(t, ok)
+		assert.Equal(t, "99", data["requestedVersion"])
+		assert.Equal(t, []int{1}, data["supportedVersions"])
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("Expected protocol mismatch not received")
+	}
+}
+
+func TestHandler_ProtocolNegotiation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSessionService := new(MockSessionService)
+	mockSessionService.On("GetSession", mock.Anything, "session-123").Return(&models.Session{
+		ID:       "session-123",
+		UserID:   "user-456",
+		MapID:    "map-789",
+		IsActive: true,
+	}, nil)
+	mockPOIService := new(MockPOIService)
+	mockPOIService.On("GetCurrentPOI", mock.Anything, mock.Anything).Return("", nil).Maybe()
+	handler := NewHandler(mockSessionService, new(MockRateLimiter), nil, mockPOIService)
+
+	router := gin.New()
+	handler.RegisterRoutes(router, nil)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws?" + sessionQuery(handler, "session-123")
+
+	t.Run("welcome reports the negotiated version", func(t *testing.T) {
+		conn, _, err := ws.DefaultDialer.Dial(wsURL+"&protocolVersion=99", nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		var msg map[string]interface{}
+		require.NoError(t, conn.ReadJSON(&msg))
+		assert.Equal(t, "welcome", msg["type"])
+		assert.Equal(t, float64(ProtocolVersion), msg["version"])
+		data := msg["data"].(map[string]interface{})
+		assert.Equal(t, float64(ProtocolVersion), data["protocolVersion"])
+	})
+
+	t.Run("unsupported version is rejected", func(t *testing.T) {
+		conn, _, err := ws.DefaultDialer.Dial(wsURL+"&protocolVersion=0", nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		var msg Message
+		require.NoError(t, conn.ReadJSON(&msg))
+		assert.Equal(t, "protocol_mismatch", msg.Type)
+
+		_, _, err = conn.ReadMessage()
+		var closeErr *ws.CloseError
+		require.ErrorAs(t, err, &closeErr)
+		assert.Equal(t, ProtocolMismatchCloseCode, closeErr.Code)
+	})
+}
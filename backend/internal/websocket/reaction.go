@@ -0,0 +1,104 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// reactionEmoji are the emoji users can react with. A fixed set keeps
+// reactions readable above avatars and leaves no room for abuse.
+var reactionEmoji = map[string]bool{
+	"👍":  true,
+	"👎":  true,
+	"👏":  true,
+	"❤️": true,
+	"😂":  true,
+	"😮":  true,
+	"🎉":  true,
+	"🤔":  true,
+	"✋":  true,
+	"🙏":  true,
+}
+
+// transientMessageTypes are shown briefly and never replayed, so they aren't
+// journaled
+var transientMessageTypes = map[string]bool{
+	"reaction": true,
+}
+
+// ReactionPayload is the data of reaction messages
+type ReactionPayload struct {
+	Emoji string `json:"emoji"`
+	// TargetPOIID or TargetUserID is what the reaction is about, if anything
+	TargetPOIID  string `json:"targetPoiId,omitempty"`
+	TargetUserID string `json:"targetUserId,omitempty"`
+}
+
+// Validate checks that the emoji is one users can react with and that the
+// reaction has at most one target
+func (p ReactionPayload) Validate() error {
+	if p.Emoji == "" {
+		return errors.New("emoji is required for reaction")
+	}
+	if !reactionEmoji[p.Emoji] {
+		return errors.New("emoji is not a supported reaction")
+	}
+	if p.TargetPOIID != "" && p.TargetUserID != "" {
+		return errors.New("reaction can target a POI or a user, not both")
+	}
+	return nil
+}
+
+// validateReaction validates reaction messages
+func validateReaction(msg Message) error {
+	var payload ReactionPayload
+	if err := decodePayload(msg, &payload); err != nil {
+		return err
+	}
+	return payload.Validate()
+}
+
+// handleReaction shows an emoji reaction of the client's user to everyone on
+// the map, above the user's avatar. Reactions targeting a POI or a user are
+// also delivered to that topic's subscribers.
+func (h *Handler) handleReaction(ctx context.Context, client *Client, msg Message) {
+	var payload ReactionPayload
+	if !h.decodeMessagePayload(client, msg, &payload) {
+		return
+	}
+
+	topics := []string{userTopic(client.UserID)}
+	data := map[string]interface{}{
+		"userId": client.UserID,
+		"emoji":  payload.Emoji,
+	}
+	switch {
+	case payload.TargetPOIID != "":
+		if err := h.authorizeTopic(ctx, client, topicKindPOI, payload.TargetPOIID); err != nil {
+			h.sendErrorMessage(ctx, client, "Cannot react to "+payload.TargetPOIID+": "+err.Error())
+			return
+		}
+		data["targetPoiId"] = payload.TargetPOIID
+		topics = append(topics, poiTopic(payload.TargetPOIID))
+	case payload.TargetUserID != "":
+		if h.manager.userConnectionCount(client.MapID, payload.TargetUserID) == 0 {
+			h.sendErrorMessage(ctx, client, "Cannot react to "+payload.TargetUserID+": user is not on this map")
+			return
+		}
+		data["targetUserId"] = payload.TargetUserID
+		topics = append(topics, userTopic(payload.TargetUserID))
+	}
+
+	h.requestLogger(ctx).Debug("Reaction",
+		"sessionId", client.SessionID,
+		"userId", client.UserID,
+		"mapId", client.MapID,
+		"emoji", payload.Emoji)
+
+	h.manager.BroadcastToMap(client.MapID, onTopics(Message{
+		Type:      "reaction",
+		Data:      data,
+		Timestamp: time.Now(),
+	}, topics...))
+}
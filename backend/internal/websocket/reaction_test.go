@@ -0,0 +1,62 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestReactionPayload_Validate(t *testing.T) {
+	assert.NoError(t, ReactionPayload{Emoji: "👍"}.Validate())
+	assert.NoError(t, ReactionPayload{Emoji: "🎉", TargetPOIID: "poi-1"}.Validate())
+	assert.Error(t, ReactionPayload{}.Validate())
+	assert.Error(t, ReactionPayload{Emoji: "hello"}.Validate())
+	assert.Error(t, ReactionPayload{Emoji: "👍", TargetPOIID: "poi-1", TargetUserID: "user-2"}.Validate())
+}
+
+func TestHandler_Reaction(t *testing.T) {
+	rateLimiter := new(MockRateLimiter)
+	poiService := new(MockPOIService)
+	handler := NewHandler(new(MockSessionService), rateLimiter, nil, poiService)
+	defer handler.manager.Shutdown()
+	sender := newTopicTestClient(handler.manager, "session-1", "user-1", "map-1")
+	other := newTopicTestClient(handler.manager, "session-2", "user-2", "map-1")
+	rateLimiter.On("CheckRateLimit", mock.Anything, "user-1", services.ActionWSReaction).Return(nil)
+	poiService.On("GetPOIsForMap", mock.Anything, "map-1").Return([]*models.POI{{ID: "poi-1", MapID: "map-1"}}, nil)
+
+	handler.handleMessage(sender, Message{Type: "reaction", Data: map[string]interface{}{"emoji": "👏", "targetPoiId": "poi-1"}})
+	reaction := receiveType(t, other, "reaction")
+	assert.Equal(t, map[string]interface{}{"userId": "user-1", "emoji": "👏", "targetPoiId": "poi-1"}, reaction.Data)
+	receiveType(t, sender, "reaction")
+
+	// Targets must be on the sender's map
+	handler.handleMessage(sender, Message{Type: "reaction", Data: map[string]interface{}{"emoji": "👍", "targetUserId": "user-3"}, RequestID: "req-1"})
+	assert.Equal(t, "req-1", receiveType(t, sender, "error").RequestID)
+	handler.handleMessage(sender, Message{Type: "reaction", Data: map[string]interface{}{"emoji": "👍", "targetPoiId": "poi-2"}})
+	receiveType(t, sender, "error")
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, other.Send)
+}
+
+func TestHandler_Reaction_RateLimited(t *testing.T) {
+	rateLimiter := new(MockRateLimiter)
+	handler := NewHandler(new(MockSessionService), rateLimiter, nil, nil)
+	defer handler.manager.Shutdown()
+	sender := newTopicTestClient(handler.manager, "session-1", "user-1", "map-1")
+	other := newTopicTestClient(handler.manager, "session-2", "user-2", "map-1")
+	rateLimiter.On("CheckRateLimit", mock.Anything, "user-1", services.ActionWSReaction).
+		Return(&services.RateLimitError{Action: services.ActionWSReaction, RetryAfter: time.Second})
+
+	handler.handleMessage(sender, Message{Type: "reaction", Data: map[string]interface{}{"emoji": "👍"}})
+	rejected := receiveType(t, sender, "error")
+	assert.Equal(t, "RATE_LIMIT_EXCEEDED", rejected.Data.(map[string]interface{})["code"])
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, other.Send)
+}
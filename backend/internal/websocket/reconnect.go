@@ -0,0 +1,324 @@
+package websocket
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"breakoutglobe/internal/models"
+)
+
+// DefaultReconnectTokenTTL is how long a reconnect token is accepted after it
+// was issued
+const DefaultReconnectTokenTTL = 2 * time.Minute
+
+// errInvalidReconnectToken is returned for reconnect tokens that are malformed,
+// forged or expired
+var errInvalidReconnectToken = errors.New("invalid reconnect token")
+
+// errRevokedReconnectToken is returned for reconnect tokens of kicked users and
+// expired sessions
+var errRevokedReconnectToken = errors.New("reconnect token revoked")
+
+// reconnectClaims is what a reconnect token vouches for: the connection's
+// session and where it left off
+type reconnectClaims struct {
+	SessionID string        `json:"sid"`
+	UserID    string        `json:"uid"`
+	MapID     string        `json:"mid"`
+	Epoch     string        `json:"epoch"`
+	Position  models.LatLng `json:"pos"`
+	ExpiresAt int64         `json:"exp"`
+}
+
+// ReconnectRevocationStore records when the reconnect tokens of a session or
+// map user were revoked. Instances sharing a store reject the tokens revoked
+// by any of them.
+type ReconnectRevocationStore interface {
+	// RevokeReconnectTokens rejects the tokens issued until at under the
+	// revocation key, for as long as ttl
+	RevokeReconnectTokens(ctx context.Context, key string, at time.Time, ttl time.Duration) error
+	// ReconnectTokensRevokedAt returns when the last of the revocation keys
+	// was revoked, or the zero time if none was
+	ReconnectTokensRevokedAt(ctx context.Context, keys ...string) (time.Time, error)
+}
+
+// reconnectTokens issues and verifies the signed tokens clients reconnect
+// with. A valid token stands in for the session lookup, so clients on flaky
+// networks resume without waiting for the database; sessions ended in the
+// meantime are still caught by the next auth refresh. Tokens of kicked users
+// and expired sessions are revoked, so their clients go through the session
+// lookup instead.
+type reconnectTokens struct {
+	secret      []byte
+	ttl         time.Duration
+	revocations ReconnectRevocationStore
+
+	mutex sync.Mutex
+	// left holds the last avatar positions of recently closed connections by
+	// session, fresher than the position in their token
+	left map[string]leftSession
+}
+
+// leftSession is the avatar position of a closed connection
+type leftSession struct {
+	position models.LatLng
+	at       time.Time
+}
+
+// SetReconnectTokens includes a reconnect token valid for ttl in welcome and
+// auth_refreshed messages. Clients pass it back as the reconnectToken query
+// parameter to skip the session lookup; instances sharing the secret accept
+// each other's tokens. Revocations only reach this instance unless
+// SetReconnectRevocations shares them. Disabled if the secret is empty.
+func (h *Handler) SetReconnectTokens(secret []byte, ttl time.Duration) {
+	if len(secret) == 0 {
+		h.reconnects = nil
+		return
+	}
+	if ttl <= 0 {
+		ttl = DefaultReconnectTokenTTL
+	}
+	h.reconnects = &reconnectTokens{
+		secret:      secret,
+		ttl:         ttl,
+		revocations: newLocalReconnectRevocations(),
+		left:        make(map[string]leftSession),
+	}
+}
+
+// SetReconnectRevocations keeps the revocations of reconnect tokens in a
+// store shared with the other instances, so a user kicked on one instance
+// can't resume on another. Call it after SetReconnectTokens.
+func (h *Handler) SetReconnectRevocations(store ReconnectRevocationStore) {
+	if h.reconnects == nil {
+		return
+	}
+	h.reconnects.revocations = store
+}
+
+// issue returns a token for the client to reconnect with and its expiry
+func (r *reconnectTokens) issue(client *Client, epoch string, now time.Time) (string, time.Time) {
+	expiresAt := now.Add(r.ttl)
+	claims := reconnectClaims{
+		SessionID: client.SessionID,
+		UserID:    client.UserID,
+		MapID:     client.MapID,
+		Epoch:     epoch,
+		ExpiresAt: expiresAt.Unix(),
+	}
+	if client.lastPosition != nil {
+		claims.Position = *client.lastPosition
+	}
+
+	payload, _ := json.Marshal(claims)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(r.sign(encoded)), expiresAt
+}
+
+// verify returns the claims of a token signed with the secret and not yet expired
+func (r *reconnectTokens) verify(token string, now time.Time) (*reconnectClaims, error) {
+	encoded, signature, found := strings.Cut(token, ".")
+	if !found {
+		return nil, errInvalidReconnectToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, r.sign(encoded)) {
+		return nil, errInvalidReconnectToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errInvalidReconnectToken
+	}
+
+	var claims reconnectClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.SessionID == "" {
+		return nil, errInvalidReconnectToken
+	}
+	if now.Unix() >= claims.ExpiresAt {
+		return nil, errInvalidReconnectToken
+	}
+	return &claims, nil
+}
+
+func (r *reconnectTokens) sign(encoded string) []byte {
+	mac := hmac.New(sha256.New, r.secret)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
+
+// remember keeps the avatar position of a closed connection for as long as
+// its tokens may be presented, dropping positions older than that
+func (r *reconnectTokens) remember(sessionID string, position models.LatLng, now time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for id, left := range r.left {
+		if now.Sub(left.at) >= r.ttl {
+			delete(r.left, id)
+		}
+	}
+	r.left[sessionID] = leftSession{position: position, at: now}
+}
+
+// lastPosition returns the avatar position a session left with on this
+// instance, falling back to the position in its token
+func (r *reconnectTokens) lastPosition(claims *reconnectClaims, now time.Time) models.LatLng {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	left, ok := r.left[claims.SessionID]
+	if !ok || now.Sub(left.at) >= r.ttl {
+		return claims.Position
+	}
+	delete(r.left, claims.SessionID)
+	return left.position
+}
+
+// sessionRevocationKey is the revocation key of a session's tokens
+func sessionRevocationKey(sessionID string) string {
+	return "session:" + sessionID
+}
+
+// userRevocationKey is the revocation key of the tokens of a user on a map
+func userRevocationKey(mapID, userID string) string {
+	return "user:" + mapID + ":" + userID
+}
+
+// revoke rejects the tokens issued until now under the revocation key, for
+// as long as they may be presented
+func (r *reconnectTokens) revoke(ctx context.Context, key string, now time.Time) error {
+	return r.revocations.RevokeReconnectTokens(ctx, key, now, r.ttl)
+}
+
+// isRevoked reports whether the token was issued before its session or its
+// user on the map were revoked. Tokens issued in the second of a revocation
+// count as revoked.
+func (r *reconnectTokens) isRevoked(ctx context.Context, claims *reconnectClaims) (bool, error) {
+	revokedAt, err := r.revocations.ReconnectTokensRevokedAt(ctx,
+		sessionRevocationKey(claims.SessionID), userRevocationKey(claims.MapID, claims.UserID))
+	if err != nil {
+		return false, err
+	}
+	issuedAt := claims.ExpiresAt - int64(r.ttl/time.Second)
+	return !revokedAt.IsZero() && issuedAt <= revokedAt.Unix(), nil
+}
+
+// revokeReconnectTokens makes the reconnect tokens issued so far under the
+// revocation key fall back to the session lookup
+func (h *Handler) revokeReconnectTokens(key string) {
+	if h.reconnects == nil {
+		return
+	}
+	if err := h.reconnects.revoke(context.Background(), key, time.Now()); err != nil {
+		h.logger.Warn("Failed to revoke reconnect tokens", "key", key, "error", err.Error())
+	}
+}
+
+// reconnectSession returns the session a valid reconnect token vouches for,
+// or nil if reconnect tokens are disabled or the token doesn't belong to the
+// requested session and must be validated the usual way
+func (h *Handler) reconnectSession(ctx context.Context, token, sessionID string) (*models.Session, *reconnectClaims) {
+	if h.reconnects == nil || token == "" {
+		return nil, nil
+	}
+
+	now := time.Now()
+	claims, err := h.reconnects.verify(token, now)
+	if err == nil && sessionID != "" && claims.SessionID != sessionID {
+		err = errInvalidReconnectToken
+	}
+	if err == nil {
+		// Tokens that can't be checked for revocation aren't trusted either
+		revoked, revokedErr := h.reconnects.isRevoked(ctx, claims)
+		if revokedErr != nil {
+			err = revokedErr
+		} else if revoked {
+			err = errRevokedReconnectToken
+		}
+	}
+	if err != nil {
+		h.logger.Info("🔁 Reconnect token rejected, validating session", "sessionId", sessionID, "error", err.Error())
+		return nil, nil
+	}
+
+	return &models.Session{
+		ID:        claims.SessionID,
+		UserID:    claims.UserID,
+		MapID:     claims.MapID,
+		AvatarPos: h.reconnects.lastPosition(claims, now),
+		IsActive:  true,
+	}, claims
+}
+
+// withReconnectToken adds a fresh reconnect token for the client to a
+// welcome or auth_refreshed payload
+func (h *Handler) withReconnectToken(client *Client, data map[string]interface{}) {
+	if h.reconnects == nil {
+		return
+	}
+	token, expiresAt := h.reconnects.issue(client, h.manager.JournalEpoch(), time.Now())
+	data["reconnectToken"] = token
+	data["reconnectTokenExpiresAt"] = expiresAt
+}
+
+// rememberLeftSession keeps a closed connection's avatar position for its
+// reconnect token
+func (h *Handler) rememberLeftSession(client *Client) {
+	if h.reconnects == nil || client.lastPosition == nil {
+		return
+	}
+	h.reconnects.remember(client.SessionID, *client.lastPosition, time.Now())
+}
+
+// localReconnectRevocations keeps revocations in process, which is enough for
+// a single instance
+type localReconnectRevocations struct {
+	mutex sync.Mutex
+	// revoked holds when and until when tokens were revoked, by revocation key
+	revoked map[string]localRevocation
+}
+
+type localRevocation struct {
+	at      time.Time
+	expires time.Time
+}
+
+func newLocalReconnectRevocations() *localReconnectRevocations {
+	return &localReconnectRevocations{revoked: make(map[string]localRevocation)}
+}
+
+// RevokeReconnectTokens records the revocation, dropping revocations whose
+// tokens all expired
+func (l *localReconnectRevocations) RevokeReconnectTokens(ctx context.Context, key string, at time.Time, ttl time.Duration) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	for revokedKey, revocation := range l.revoked {
+		if !at.Before(revocation.expires) {
+			delete(l.revoked, revokedKey)
+		}
+	}
+	l.revoked[key] = localRevocation{at: at, expires: at.Add(ttl)}
+	return nil
+}
+
+// ReconnectTokensRevokedAt returns when the last of the keys was revoked
+func (l *localReconnectRevocations) ReconnectTokensRevokedAt(ctx context.Context, keys ...string) (time.Time, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	var latest time.Time
+	for _, key := range keys {
+		if revocation, ok := l.revoked[key]; ok && revocation.at.After(latest) {
+			latest = revocation.at
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,207 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+
+	"github.com/gin-gonic/gin"
+	ws "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconnectTokens_IssueAndVerify(t *testing.T) {
+	tokens := &reconnectTokens{secret: []byte("secret"), ttl: time.Minute, left: make(map[string]leftSession)}
+	position := models.LatLng{Lat: 52.52, Lng: 13.405}
+	client := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1", lastPosition: &position}
+	now := time.Now()
+
+	token, expiresAt := tokens.issue(client, "epoch-1", now)
+	assert.Equal(t, now.Add(time.Minute), expiresAt)
+
+	claims, err := tokens.verify(token, now)
+	require.NoError(t, err)
+	assert.Equal(t, reconnectClaims{SessionID: "session-1", UserID: "user-1", MapID: "map-1", Epoch: "epoch-1", Position: position, ExpiresAt: expiresAt.Unix()}, *claims)
+
+	_, err = tokens.verify(token, now.Add(time.Minute))
+	assert.ErrorIs(t, err, errInvalidReconnectToken)
+	forged := &reconnectTokens{secret: []byte("other"), ttl: time.Minute}
+	_, err = forged.verify(token, now)
+	assert.ErrorIs(t, err, errInvalidReconnectToken)
+	payload, signature, _ := strings.Cut(token, ".")
+	_, err = tokens.verify(payload+"x."+signature, now)
+	assert.ErrorIs(t, err, errInvalidReconnectToken)
+	_, err = tokens.verify("garbage", now)
+	assert.ErrorIs(t, err, errInvalidReconnectToken)
+}
+
+func TestReconnectTokens_LastPosition(t *testing.T) {
+	tokens := &reconnectTokens{secret: []byte("secret"), ttl: time.Minute, left: make(map[string]leftSession)}
+	claims := &reconnectClaims{SessionID: "session-1", Position: models.LatLng{Lat: 1, Lng: 1}}
+	moved := models.LatLng{Lat: 2, Lng: 2}
+	now := time.Now()
+
+	assert.Equal(t, claims.Position, tokens.lastPosition(claims, now))
+	tokens.remember("session-1", moved, now)
+	assert.Equal(t, moved, tokens.lastPosition(claims, now))
+
+	// Positions are forgotten once their tokens expired
+	tokens.remember("session-1", moved, now)
+	assert.Equal(t, claims.Position, tokens.lastPosition(claims, now.Add(time.Minute)))
+	tokens.remember("session-2", moved, now.Add(2*time.Minute))
+	assert.NotContains(t, tokens.left, "session-1")
+}
+
+func TestHandler_ReconnectToken_SkipsSessionLookup(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSessionService := new(MockSessionService)
+	mockPOIService := new(MockPOIService)
+	mockPOIService.On("GetCurrentPOI", mock.Anything, mock.Anything).Return("", nil).Maybe()
+	session := &models.Session{ID: "session-1", UserID: "user-1", MapID: "map-1", AvatarPos: models.LatLng{Lat: 52.52, Lng: 13.405}, IsActive: true}
+	mockSessionService.On("GetSession", mock.Anything, "session-1").Return(session, nil)
+	handler := NewHandler(mockSessionService, new(MockRateLimiter), nil, mockPOIService)
+	handler.SetReconnectTokens([]byte("secret"), time.Minute)
+
+	router := gin.New()
+	handler.RegisterRoutes(router, nil)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws?"
+
+	connect := func(query string) (*ws.Conn, Message) {
+		conn, _, err := ws.DefaultDialer.Dial(wsURL+query, nil)
+		require.NoError(t, err)
+		var welcomeMsg Message
+		require.NoError(t, conn.ReadJSON(&welcomeMsg))
+		require.Equal(t, "welcome", welcomeMsg.Type)
+		return conn, welcomeMsg
+	}
+
+	conn, welcomeMsg := connect(sessionQuery(handler, "session-1"))
+	data := welcomeMsg.Data.(map[string]interface{})
+	token, _ := data["reconnectToken"].(string)
+	require.NotEmpty(t, token)
+	assert.NotEmpty(t, data["reconnectTokenExpiresAt"])
+	conn.Close()
+	require.Eventually(t, func() bool {
+		return handler.manager.GetConnectedClients() == 0
+	}, time.Second, 10*time.Millisecond)
+
+	conn, welcomeMsg = connect("reconnectToken=" + url.QueryEscape(token))
+	defer conn.Close()
+	data = welcomeMsg.Data.(map[string]interface{})
+	assert.Equal(t, "session-1", data["sessionId"])
+	assert.Equal(t, "user-1", data["userId"])
+	assert.NotEmpty(t, data["reconnectToken"])
+	mockSessionService.AssertNumberOfCalls(t, "GetSession", 1)
+
+	// Tokens of another session are ignored and the session is looked up
+	other, _ := connect(sessionQuery(handler, "session-1") + "&reconnectToken=forged")
+	defer other.Close()
+	mockSessionService.AssertNumberOfCalls(t, "GetSession", 2)
+}
+
+func TestReconnectTokens_Revoke(t *testing.T) {
+	revocations := newLocalReconnectRevocations()
+	tokens := &reconnectTokens{secret: []byte("secret"), ttl: time.Minute, revocations: revocations, left: make(map[string]leftSession)}
+	ctx := context.Background()
+	now := time.Now()
+	issued := &reconnectClaims{SessionID: "session-1", UserID: "user-1", MapID: "map-1", ExpiresAt: now.Add(time.Minute).Unix()}
+	otherMap := &reconnectClaims{SessionID: "session-2", UserID: "user-1", MapID: "map-2", ExpiresAt: now.Add(time.Minute).Unix()}
+
+	isRevoked := func(claims *reconnectClaims) bool {
+		revoked, err := tokens.isRevoked(ctx, claims)
+		require.NoError(t, err)
+		return revoked
+	}
+
+	assert.False(t, isRevoked(issued))
+	require.NoError(t, tokens.revoke(ctx, userRevocationKey("map-1", "user-1"), now))
+	assert.True(t, isRevoked(issued))
+	assert.False(t, isRevoked(otherMap))
+
+	// Tokens issued after the revocation are accepted again
+	later := &reconnectClaims{SessionID: "session-3", UserID: "user-1", MapID: "map-1", ExpiresAt: now.Add(time.Minute + time.Second).Unix()}
+	assert.False(t, isRevoked(later))
+
+	require.NoError(t, tokens.revoke(ctx, sessionRevocationKey("session-2"), now))
+	assert.True(t, isRevoked(otherMap))
+
+	// Revocations are dropped once the tokens they rejected expired
+	require.NoError(t, tokens.revoke(ctx, sessionRevocationKey("session-4"), now.Add(time.Minute)))
+	assert.NotContains(t, revocations.revoked, userRevocationKey("map-1", "user-1"))
+}
+
+// failingRevocations is a revocation store that can't be reached
+type failingRevocations struct{}
+
+func (failingRevocations) RevokeReconnectTokens(ctx context.Context, key string, at time.Time, ttl time.Duration) error {
+	return errors.New("revocation store unavailable")
+}
+
+func (failingRevocations) ReconnectTokensRevokedAt(ctx context.Context, keys ...string) (time.Time, error) {
+	return time.Time{}, errors.New("revocation store unavailable")
+}
+
+func TestHandler_ReconnectRevocationsAreShared(t *testing.T) {
+	shared := newLocalReconnectRevocations()
+	kicking := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, new(MockPOIService))
+	kicking.SetReconnectTokens([]byte("secret"), time.Minute)
+	kicking.SetReconnectRevocations(shared)
+	resuming := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, new(MockPOIService))
+	resuming.SetReconnectTokens([]byte("secret"), time.Minute)
+	resuming.SetReconnectRevocations(shared)
+
+	ctx := context.Background()
+	token, _ := resuming.reconnects.issue(&Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1"}, "epoch-1", time.Now())
+	session, _ := resuming.reconnectSession(ctx, token, "")
+	require.NotNil(t, session)
+
+	// A kick on another instance revokes the token here too
+	kicking.KickUser("map-1", "user-1")
+	session, _ = resuming.reconnectSession(ctx, token, "")
+	assert.Nil(t, session)
+
+	// Tokens that can't be checked fall back to the session lookup
+	resuming.SetReconnectRevocations(failingRevocations{})
+	token, _ = resuming.reconnects.issue(&Client{SessionID: "session-2", UserID: "user-2", MapID: "map-1"}, "epoch-1", time.Now())
+	session, _ = resuming.reconnectSession(ctx, token, "")
+	assert.Nil(t, session)
+}
+
+func TestHandler_ReconnectToken_RevokedOnKick(t *testing.T) {
+	handler, sessionService, wsURL := newAuthChainTestServer(t)
+	handler.SetReconnectTokens([]byte("secret"), time.Minute)
+
+	conn, _, err := ws.DefaultDialer.Dial(wsURL+sessionQuery(handler, "session-1"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	var welcomeMsg Message
+	require.NoError(t, conn.ReadJSON(&welcomeMsg))
+	token, _ := welcomeMsg.Data.(map[string]interface{})["reconnectToken"].(string)
+	require.NotEmpty(t, token)
+
+	require.Equal(t, 1, handler.KickUser("map-1", "user-1"))
+	require.Eventually(t, func() bool {
+		return handler.manager.GetConnectedClients() == 0
+	}, time.Second, 10*time.Millisecond)
+
+	// The kick ended the session, which the lookup the token falls back to finds
+	sessionService.ExpectedCalls = nil
+	sessionService.On("GetSession", mock.Anything, "session-1").Return(nil, services.ErrNotFound)
+	_, response, err := ws.DefaultDialer.Dial(wsURL+"reconnectToken="+url.QueryEscape(token), nil)
+	require.Error(t, err)
+	require.NotNil(t, response)
+	assert.Equal(t, http.StatusUnauthorized, response.StatusCode)
+}
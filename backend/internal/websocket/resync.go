@@ -0,0 +1,61 @@
+package websocket
+
+import (
+	"context"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+)
+
+// minResyncInterval limits how often a client can request a state snapshot, so
+// a client that keeps seeing gaps can't turn resyncs into a query storm
+const minResyncInterval = 5 * time.Second
+
+// handleResync sends an initial_state snapshot of the client's map after the
+// client detected a gap in broadcast sequence numbers
+func (h *Handler) handleResync(ctx context.Context, client *Client, msg Message) {
+	now := time.Now()
+	if now.Sub(client.lastResyncAt) < minResyncInterval {
+		h.sendErrorMessage(ctx, client, "Resync requested too often")
+		return
+	}
+	client.lastResyncAt = now
+
+	h.requestLogger(ctx).Info("🔄 Resync requested",
+		"sessionId", client.SessionID,
+		"mapId", client.MapID)
+
+	data := map[string]interface{}{
+		"mapId": client.MapID,
+		"users": h.initialUsers(ctx, client),
+	}
+
+	pois := []*models.POI{}
+	if h.poiService != nil {
+		mapPOIs, err := h.poiService.GetPOIsForMap(ctx, client.MapID)
+		if err != nil {
+			h.requestLogger(ctx).Warn("Failed to get POIs for resync", "mapId", client.MapID, "error", err.Error())
+		} else {
+			pois = mapPOIs
+		}
+	}
+	data["pois"] = pois
+
+	rosters := []services.POIRoster{}
+	if h.rosters != nil {
+		mapRosters, err := h.rosters.GetPOIRostersForMap(ctx, client.MapID)
+		if err != nil {
+			h.requestLogger(ctx).Warn("Failed to get POI rosters for resync", "mapId", client.MapID, "error", err.Error())
+		} else {
+			rosters = mapRosters
+		}
+	}
+	data["rosters"] = rosters
+
+	select {
+	case client.Send <- replyTo(ctx, Message{Type: "initial_state", Data: data, Timestamp: now}):
+	default:
+		h.requestLogger(ctx).Warn("Failed to send initial state to client", "sessionId", client.SessionID)
+	}
+}
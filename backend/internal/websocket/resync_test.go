@@ -0,0 +1,38 @@
+package websocket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_Resync_SendsInitialState(t *testing.T) {
+	poiService := new(MockPOIService)
+	poiService.On("GetPOIsForMap", mock.Anything, "map-1").Return([]*models.POI{{ID: "poi-1", MapID: "map-1"}}, nil).Once()
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, poiService)
+	client := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 4)}
+
+	handler.handleResync(context.Background(), client, Message{Type: "resync"})
+
+	msg := <-client.Send
+	require.Equal(t, "initial_state", msg.Type)
+	data := msg.Data.(map[string]interface{})
+	assert.Equal(t, "map-1", data["mapId"])
+	assert.Len(t, data["pois"], 1)
+
+	// Repeated requests are refused until the interval passed
+	handler.handleResync(context.Background(), client, Message{Type: "resync"})
+	assert.Equal(t, "error", (<-client.Send).Type)
+
+	client.lastResyncAt = time.Now().Add(-minResyncInterval)
+	poiService.On("GetPOIsForMap", mock.Anything, "map-1").Return([]*models.POI{}, nil).Once()
+	handler.handleResync(context.Background(), client, Message{Type: "resync"})
+	assert.Equal(t, "initial_state", (<-client.Send).Type)
+	poiService.AssertExpectations(t)
+}
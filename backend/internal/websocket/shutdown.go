@@ -0,0 +1,190 @@
+package websocket
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	ws "github.com/gorilla/websocket"
+)
+
+// DefaultShutdownReconnectSpread is the window over which clients of a
+// shutting down server are told to reconnect, so they don't all reconnect at
+// once
+const DefaultShutdownReconnectSpread = 5 * time.Second
+
+// Shutdown stops accepting connections and drains the connected clients. Each
+// is sent a server_shutdown message with a reconnect delay, gets its queued
+// messages written and is closed with CloseServiceRestart. Connections still
+// open when ctx ends are closed without waiting.
+func (h *Handler) Shutdown(ctx context.Context) error {
+	h.draining.Store(true)
+	h.logger.Info("Draining WebSocket connections", "clients", h.manager.GetConnectedClients())
+	return h.manager.Drain(ctx, DefaultShutdownReconnectSpread)
+}
+
+// DrainGradually stops accepting connections and hands the connected clients
+// over to other instances spread over window, for rolling restarts. Once it
+// returns, the instance has no clients left and can be stopped.
+func (h *Handler) DrainGradually(ctx context.Context, window time.Duration) error {
+	h.draining.Store(true)
+	h.logger.Info("Draining WebSocket connections gradually",
+		"clients", h.manager.GetConnectedClients(),
+		"window", window.String())
+	return h.manager.DrainGradually(ctx, window)
+}
+
+// IsDraining reports whether the server stopped accepting connections, so
+// load balancer health checks can take the instance out of rotation
+func (h *Handler) IsDraining() bool {
+	return h.draining.Load()
+}
+
+// Drain tells every client the server is shutting down and when to reconnect,
+// and ends their connections once their queued messages are written. It
+// returns when all write pumps stopped, or with ctx's error once ctx ends, after
+// closing the remaining connections.
+func (m *Manager) Drain(ctx context.Context, reconnectSpread time.Duration) error {
+	m.mutex.RLock()
+	clients := make([]*Client, 0, len(m.clients))
+	for _, client := range m.clients {
+		// Send is only closed under the write lock, so it's open while we hold the read lock
+		client.startDrain(serverShutdownMessage(reconnectDelay(reconnectSpread)))
+		clients = append(clients, client)
+	}
+	m.mutex.RUnlock()
+
+	return waitDrained(ctx, clients)
+}
+
+// DrainGradually hands the clients over to other instances one after another,
+// spread evenly over window, so the other instances aren't hit by all
+// reconnects at once. Each client is sent reconnect_to_other_instance, gets
+// its queued messages written and is closed with CloseServiceRestart. It
+// returns when all write pumps stopped, or with ctx's error once ctx ends,
+// after closing the remaining connections.
+func (m *Manager) DrainGradually(ctx context.Context, window time.Duration) error {
+	clients := m.connectedClients()
+	if len(clients) == 0 {
+		return nil
+	}
+	interval := window / time.Duration(len(clients))
+
+	for i, client := range clients {
+		if i > 0 && interval > 0 {
+			select {
+			case <-time.After(interval):
+			case <-ctx.Done():
+				return waitDrained(ctx, clients)
+			}
+		}
+		m.startDrainIfConnected(client, reconnectToOtherInstanceMessage(len(clients)-i-1))
+	}
+
+	return waitDrained(ctx, clients)
+}
+
+// startDrainIfConnected starts draining a client unless it disconnected in
+// the meantime, which closed its send channel
+func (m *Manager) startDrainIfConnected(client *Client, message Message) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if m.clients[client.connectionKey()] == client {
+		client.startDrain(message)
+	}
+}
+
+// waitDrained waits until the write pumps of the clients stopped. Once ctx
+// ends, it closes the remaining connections and returns ctx's error.
+func waitDrained(ctx context.Context, clients []*Client) error {
+	for i, client := range clients {
+		if client.writeDone == nil {
+			continue
+		}
+		select {
+		case <-client.writeDone:
+		case <-ctx.Done():
+			for _, remaining := range clients[i:] {
+				if remaining.Conn != nil {
+					remaining.Conn.Close()
+				}
+			}
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// reconnectDelay picks a random delay within spread
+func reconnectDelay(spread time.Duration) time.Duration {
+	if spread <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(spread)))
+}
+
+// serverShutdownMessage tells a client the server shuts down and when to reconnect
+func serverShutdownMessage(reconnectAfter time.Duration) Message {
+	return Message{
+		Type: "server_shutdown",
+		Data: map[string]interface{}{
+			"reconnectAfterMs": reconnectAfter.Milliseconds(),
+		},
+		Timestamp: time.Now(),
+	}
+}
+
+// reconnectToOtherInstanceMessage tells a client of a draining instance to
+// reconnect right away, which the load balancer routes to another instance
+func reconnectToOtherInstanceMessage(remaining int) Message {
+	return Message{
+		Type: "reconnect_to_other_instance",
+		Data: map[string]interface{}{
+			"reconnectAfterMs": 0,
+			"remainingClients": remaining,
+		},
+		Timestamp: time.Now(),
+	}
+}
+
+// startDrain queues the message announcing the end of the connection and
+// makes the write pump end the connection once the queued messages are written
+func (c *Client) startDrain(message Message) {
+	c.drainOnce.Do(func() {
+		select {
+		case c.lane(message) <- message:
+		default:
+		}
+
+		c.setCloseCause(&closeCause{code: ws.CloseServiceRestart, reason: CloseReasonServerShutdown, sendable: true})
+		if c.drain != nil {
+			close(c.drain)
+		}
+	})
+}
+
+// writeDrained writes the signaling and default messages still queued, then
+// the close frame. Queued movement is dropped, since clients reload positions
+// when they reconnect.
+func (c *Client) writeDrained() {
+	for _, lane := range []chan Message{c.signaling, c.Send} {
+		for queued := len(lane); queued > 0; queued-- {
+			message, ok := <-lane
+			if !ok {
+				break
+			}
+			if !c.writeMessage(message) {
+				return
+			}
+		}
+	}
+	if !c.flushFrame() {
+		return
+	}
+
+	c.Conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	if frame := c.takeCloseFrame(); frame != nil {
+		c.Conn.WriteMessage(ws.CloseMessage, frame)
+	}
+}
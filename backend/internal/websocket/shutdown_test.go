@@ -0,0 +1,178 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/gin-gonic/gin"
+	ws "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_Shutdown_DrainsConnections(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSessionService := new(MockSessionService)
+	mockPOIService := new(MockPOIService)
+	mockPOIService.On("GetCurrentPOI", mock.Anything, mock.Anything).Return("", nil).Maybe()
+	mockSessionService.On("GetSession", mock.Anything, "session-1").Return(&models.Session{
+		ID:        "session-1",
+		UserID:    "user-1",
+		MapID:     "map-1",
+		IsActive:  true,
+		AvatarPos: models.LatLng{Lat: 52.52, Lng: 13.405},
+	}, nil)
+	handler := NewHandler(mockSessionService, new(MockRateLimiter), nil, mockPOIService)
+
+	router := gin.New()
+	handler.RegisterRoutes(router, nil)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws?" + sessionQuery(handler, "session-1")
+
+	conn, _, err := ws.DefaultDialer.Dial(wsURL, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var welcomeMsg, initialUsersMsg Message
+	require.NoError(t, conn.ReadJSON(&welcomeMsg))
+	require.NoError(t, conn.ReadJSON(&initialUsersMsg))
+
+	require.Eventually(t, func() bool {
+		return handler.manager.GetConnectedClients() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// A message queued before the shutdown is still written
+	client := handler.manager.connectedClients()[0]
+	client.Send <- Message{Type: "poi_created", Data: map[string]interface{}{"poiId": "poi-1"}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- handler.Shutdown(ctx) }()
+
+	var types []string
+	for {
+		var msg Message
+		err := conn.ReadJSON(&msg)
+		if err != nil {
+			var closeErr *ws.CloseError
+			require.True(t, errors.As(err, &closeErr), err.Error())
+			assert.Equal(t, ws.CloseServiceRestart, closeErr.Code)
+
+			var reason CloseReason
+			require.NoError(t, json.Unmarshal([]byte(closeErr.Text), &reason))
+			assert.Equal(t, CloseReasonServerShutdown, reason.Reason)
+			assert.True(t, reason.Retry)
+			break
+		}
+		types = append(types, msg.Type)
+		if msg.Type == "server_shutdown" {
+			delay := msg.Data.(map[string]interface{})["reconnectAfterMs"].(float64)
+			assert.Less(t, delay, float64(DefaultShutdownReconnectSpread.Milliseconds()))
+		}
+	}
+	assert.Contains(t, types, "server_shutdown")
+	assert.Contains(t, types, "poi_created")
+	require.NoError(t, <-shutdownErr)
+
+	// New connections are turned away
+	_, resp, err := ws.DefaultDialer.Dial(wsURL, nil)
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func TestManager_Drain_ClosesConnectionsWhenContextEnds(t *testing.T) {
+	manager := NewManager()
+	client := &Client{
+		SessionID: "session-1",
+		UserID:    "user-1",
+		MapID:     "map-1",
+		Send:      make(chan Message, 10),
+		drain:     make(chan struct{}),
+		writeDone: make(chan struct{}),
+	}
+	manager.RegisterClient(client)
+	require.Eventually(t, func() bool {
+		return manager.GetConnectedClients() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// Without a write pump the client never finishes draining
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := manager.Drain(ctx, time.Second)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	msg := <-client.Send
+	assert.Equal(t, "server_shutdown", msg.Type)
+}
+
+func TestManager_DrainGradually_SpreadsReconnectHints(t *testing.T) {
+	manager := NewManager()
+	defer manager.Shutdown()
+
+	var clients []*Client
+	for _, sessionID := range []string{"session-1", "session-2", "session-3"} {
+		client := &Client{
+			SessionID: sessionID,
+			UserID:    "user-" + sessionID,
+			MapID:     "map-1",
+			Send:      make(chan Message, 10),
+			drain:     make(chan struct{}),
+		}
+		manager.registerClient(client)
+		clients = append(clients, client)
+	}
+
+	start := time.Now()
+	require.NoError(t, manager.DrainGradually(context.Background(), 90*time.Millisecond))
+	// The last client is handed over two intervals after the first
+	assert.GreaterOrEqual(t, time.Since(start), 60*time.Millisecond)
+
+	remaining := map[int]bool{}
+	for _, client := range clients {
+		msg := <-client.Send
+		assert.Equal(t, "reconnect_to_other_instance", msg.Type)
+		remaining[msg.Data.(map[string]interface{})["remainingClients"].(int)] = true
+
+		select {
+		case <-client.drain:
+		default:
+			t.Fatalf("%s was not drained", client.SessionID)
+		}
+		assert.Equal(t, CloseReasonServerShutdown, client.getCloseCause().reason)
+	}
+	assert.Equal(t, map[int]bool{0: true, 1: true, 2: true}, remaining)
+}
+
+func TestManager_DrainGradually_SkipsDisconnectedClients(t *testing.T) {
+	manager := NewManager()
+	defer manager.Shutdown()
+
+	client := &Client{SessionID: "session-1", MapID: "map-1", Send: make(chan Message, 10), drain: make(chan struct{})}
+	manager.registerClient(client)
+	other := &Client{SessionID: "session-2", MapID: "map-1", Send: make(chan Message, 10), drain: make(chan struct{})}
+	manager.registerClient(other)
+
+	// The client leaves while the first one is handed over
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		manager.disconnectClient(other, &closeCause{code: ws.CloseNormalClosure, reason: CloseReasonClientClosed})
+		manager.disconnectClient(client, &closeCause{code: ws.CloseNormalClosure, reason: CloseReasonClientClosed})
+	}()
+
+	assert.NotPanics(t, func() {
+		require.NoError(t, manager.DrainGradually(context.Background(), 100*time.Millisecond))
+	})
+}
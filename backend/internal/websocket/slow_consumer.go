@@ -0,0 +1,63 @@
+package websocket
+
+import "time"
+
+// A lane filled to laggingQueueNumerator/laggingQueueDenominator of its
+// buffer makes the client lagging, before broadcasts have to be dropped
+const (
+	laggingQueueNumerator   = 3
+	laggingQueueDenominator = 4
+)
+
+// laggingWarningInterval is how often a client is sent client_lagging at most
+const laggingWarningInterval = 10 * time.Second
+
+// Slow consumer events counted by the slow consumer metric
+const (
+	slowConsumerLagging = "lagging"
+	slowConsumerDropped = "dropped"
+	slowConsumerEvicted = "evicted"
+)
+
+// warnLagging tells a client that it falls behind the broadcasts of its map,
+// so the browser can show it and operators can spot overwhelmed clients. The
+// warning skips the full lane. The caller must hold the write lock.
+func (m *Manager) warnLagging(client *Client, priority messagePriority, lane chan Message) {
+	now := time.Now()
+	if now.Sub(client.laggingWarnedAt) < laggingWarningInterval {
+		return
+	}
+	client.laggingWarnedAt = now
+	client.recordSlowConsumerEvent(slowConsumerLagging)
+
+	warning := Message{
+		Type: "client_lagging",
+		Data: map[string]interface{}{
+			"lane":       priority.String(),
+			"queued":     len(lane),
+			"bufferSize": cap(lane),
+			"dropped":    client.droppedMessages.Load(),
+			"policy":     client.slowConsumer.DropPolicy.String(),
+		},
+		Timestamp: now,
+	}
+	select {
+	case client.laneFor(prioritySignaling) <- warning:
+	default:
+	}
+
+	m.logger.Warn("🐢 WebSocket client lagging behind broadcasts",
+		"sessionId", client.SessionID,
+		"mapId", client.MapID,
+		"lane", priority.String(),
+		"queued", len(lane),
+		"dropped", client.droppedMessages.Load())
+}
+
+// recordSlowConsumerEvent counts a slow consumer event of the client
+func (c *Client) recordSlowConsumerEvent(event string) {
+	if c.slowConsumerEvents == nil {
+		return
+	}
+	c.slowConsumerEvents.WithLabelValues(c.MapID, c.slowConsumer.DropPolicy.String(), event).Inc()
+}
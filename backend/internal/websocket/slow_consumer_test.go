@@ -0,0 +1,92 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_Deliver_DropMovementPolicy(t *testing.T) {
+	manager := NewManager()
+	defer manager.Shutdown()
+	client := &Client{
+		Send:         make(chan Message, 1),
+		signaling:    make(chan Message, 8),
+		movement:     make(chan Message, 1),
+		slowConsumer: models.SlowConsumerPolicy{DropPolicy: models.SlowConsumerDropMovement},
+	}
+
+	// Moves are dropped once the movement lane is full
+	assert.True(t, manager.deliver(client, Message{Type: "avatar_moved"}))
+	assert.True(t, manager.deliver(client, Message{Type: "avatar_moved"}))
+	assert.Equal(t, int32(1), client.droppedMessages.Load())
+
+	// Anything else that doesn't fit disconnects the client
+	assert.True(t, manager.deliver(client, Message{Type: "poi_created"}))
+	assert.False(t, manager.deliver(client, Message{Type: "poi_updated"}))
+}
+
+func TestManager_Deliver_WarnsLaggingClients(t *testing.T) {
+	manager := NewManager()
+	defer manager.Shutdown()
+	client := &Client{
+		MapID:        "map-1",
+		Send:         make(chan Message, 4),
+		signaling:    make(chan Message, 8),
+		movement:     make(chan Message, 4),
+		slowConsumer: models.SlowConsumerPolicy{DropPolicy: models.SlowConsumerDropNewest},
+	}
+
+	for i := 0; i < 2; i++ {
+		manager.deliver(client, Message{Type: "poi_updated"})
+	}
+	assert.Empty(t, client.signaling)
+
+	// Three of four queued messages make the client lagging
+	manager.deliver(client, Message{Type: "poi_updated"})
+	require.Len(t, client.signaling, 1)
+	warning := <-client.signaling
+	assert.Equal(t, "client_lagging", warning.Type)
+	data := warning.Data.(map[string]interface{})
+	assert.Equal(t, "default", data["lane"])
+	assert.Equal(t, 3, data["queued"])
+	assert.Equal(t, 4, data["bufferSize"])
+	assert.Equal(t, "drop_newest", data["policy"])
+
+	// Warnings are rate limited, also once messages are dropped
+	manager.deliver(client, Message{Type: "poi_updated"})
+	manager.deliver(client, Message{Type: "poi_updated"})
+	assert.Empty(t, client.signaling)
+
+	client.laggingWarnedAt = time.Now().Add(-laggingWarningInterval)
+	manager.deliver(client, Message{Type: "poi_updated"})
+	require.Len(t, client.signaling, 1)
+	assert.Equal(t, int32(2), (<-client.signaling).Data.(map[string]interface{})["dropped"])
+}
+
+func TestManager_SlowConsumerMetrics(t *testing.T) {
+	manager := NewManager()
+	defer manager.Shutdown()
+	events := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "slow_consumer_events_total"}, []string{"map_id", "policy", "event"})
+	client := &Client{
+		SessionID:          "session-1",
+		MapID:              "map-1",
+		Send:               make(chan Message, 1),
+		signaling:          make(chan Message, 8),
+		slowConsumerEvents: events,
+	}
+	manager.registerClient(client)
+
+	manager.broadcastToMap(BroadcastMessage{MapID: "map-1", Message: Message{Type: "poi_created"}})
+	manager.broadcastToMap(BroadcastMessage{MapID: "map-1", Message: Message{Type: "poi_updated"}})
+	assert.False(t, manager.IsClientConnected("session-1"))
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(events.WithLabelValues("map-1", "disconnect", "lagging")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(events.WithLabelValues("map-1", "disconnect", "evicted")))
+}
@@ -0,0 +1,149 @@
+package websocket
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultSpeakerDebounce is how long the speaking state of a POI must be stable
+// before its active speaker is broadcast
+const DefaultSpeakerDebounce = 500 * time.Millisecond
+
+// speakerTracker keeps the speaking state reported by POI call participants and
+// announces the active speaker of each POI once the state settles, so short
+// pauses and voice activity flapping don't flood map clients with updates.
+type speakerTracker struct {
+	mutex     sync.Mutex
+	debounce  time.Duration
+	pois      map[string]*poiSpeakers
+	broadcast func(mapID string, msg Message)
+}
+
+type poiSpeakers struct {
+	mapID     string
+	speaking  map[string]time.Time // userID -> speaking since
+	announced string               // active speaker last broadcast, empty for none
+	timer     *time.Timer
+}
+
+func newSpeakerTracker(debounce time.Duration, broadcast func(mapID string, msg Message)) *speakerTracker {
+	return &speakerTracker{
+		debounce:  debounce,
+		pois:      make(map[string]*poiSpeakers),
+		broadcast: broadcast,
+	}
+}
+
+// SetSpeaking records whether a user is speaking in a POI call
+func (t *speakerTracker) SetSpeaking(mapID, poiID, userID string, speaking bool) {
+	t.mutex.Lock()
+
+	state, exists := t.pois[poiID]
+	if !exists {
+		if !speaking {
+			t.mutex.Unlock()
+			return
+		}
+		state = &poiSpeakers{mapID: mapID, speaking: make(map[string]time.Time)}
+		t.pois[poiID] = state
+	}
+
+	if speaking {
+		if _, already := state.speaking[userID]; !already {
+			state.speaking[userID] = time.Now()
+		}
+	} else {
+		delete(state.speaking, userID)
+	}
+
+	t.schedule(poiID, state)
+}
+
+// ClearUser removes a user's speaking state from a POI, e.g. when they leave it
+func (t *speakerTracker) ClearUser(poiID, userID string) {
+	t.SetSpeaking("", poiID, userID, false)
+}
+
+// ClearUserEverywhere removes a user's speaking state from all POIs, e.g. on disconnect
+func (t *speakerTracker) ClearUserEverywhere(userID string) {
+	t.mutex.Lock()
+	var poiIDs []string
+	for poiID, state := range t.pois {
+		if _, speaking := state.speaking[userID]; speaking {
+			poiIDs = append(poiIDs, poiID)
+		}
+	}
+	t.mutex.Unlock()
+
+	for _, poiID := range poiIDs {
+		t.ClearUser(poiID, userID)
+	}
+}
+
+// schedule (re)starts the debounce timer of a POI. It is called with the mutex
+// held and releases it.
+func (t *speakerTracker) schedule(poiID string, state *poiSpeakers) {
+	if t.debounce <= 0 {
+		t.mutex.Unlock()
+		t.flush(poiID)
+		return
+	}
+
+	if state.timer != nil {
+		state.timer.Stop()
+	}
+	state.timer = time.AfterFunc(t.debounce, func() {
+		t.flush(poiID)
+	})
+	t.mutex.Unlock()
+}
+
+// flush broadcasts the active speaker of a POI if it changed since the last announcement
+func (t *speakerTracker) flush(poiID string) {
+	t.mutex.Lock()
+
+	state, exists := t.pois[poiID]
+	if !exists {
+		t.mutex.Unlock()
+		return
+	}
+
+	active := state.activeSpeaker()
+	if len(state.speaking) == 0 {
+		delete(t.pois, poiID)
+	}
+	if active == state.announced {
+		t.mutex.Unlock()
+		return
+	}
+	state.announced = active
+	mapID := state.mapID
+	t.mutex.Unlock()
+
+	var userID interface{}
+	if active != "" {
+		userID = active
+	}
+
+	t.broadcast(mapID, onTopics(Message{
+		Type: "poi_active_speaker",
+		Data: map[string]interface{}{
+			"poiId":    poiID,
+			"userId":   userID,
+			"speaking": active != "",
+		},
+		Timestamp: time.Now(),
+	}, poiTopic(poiID)))
+}
+
+// activeSpeaker returns the participant who most recently started speaking
+func (s *poiSpeakers) activeSpeaker() string {
+	var active string
+	var since time.Time
+	for userID, started := range s.speaking {
+		if active == "" || started.After(since) {
+			active, since = userID, started
+		}
+	}
+	return active
+}
@@ -0,0 +1,85 @@
+package websocket
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedBroadcast struct {
+	mapID string
+	msg   Message
+}
+
+type broadcastRecorder struct {
+	mutex      sync.Mutex
+	broadcasts []recordedBroadcast
+}
+
+func (r *broadcastRecorder) record(mapID string, msg Message) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.broadcasts = append(r.broadcasts, recordedBroadcast{mapID: mapID, msg: msg})
+}
+
+func (r *broadcastRecorder) speakers() []interface{} {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var speakers []interface{}
+	for _, broadcast := range r.broadcasts {
+		speakers = append(speakers, broadcast.msg.Data.(map[string]interface{})["userId"])
+	}
+	return speakers
+}
+
+func TestSpeakerTracker_DebouncesFlapping(t *testing.T) {
+	recorder := &broadcastRecorder{}
+	tracker := newSpeakerTracker(30*time.Millisecond, recorder.record)
+
+	// Voice activity flaps while the user talks
+	tracker.SetSpeaking("map-1", "poi-1", "user-1", true)
+	tracker.SetSpeaking("map-1", "poi-1", "user-1", false)
+	tracker.SetSpeaking("map-1", "poi-1", "user-1", true)
+
+	require.Eventually(t, func() bool { return len(recorder.speakers()) == 1 }, time.Second, 5*time.Millisecond)
+	time.Sleep(60 * time.Millisecond)
+
+	assert.Equal(t, []interface{}{"user-1"}, recorder.speakers())
+	assert.Equal(t, "map-1", recorder.broadcasts[0].mapID)
+	assert.Equal(t, "poi_active_speaker", recorder.broadcasts[0].msg.Type)
+}
+
+func TestSpeakerTracker_AnnouncesMostRecentSpeaker(t *testing.T) {
+	recorder := &broadcastRecorder{}
+	tracker := newSpeakerTracker(0, recorder.record)
+
+	tracker.SetSpeaking("map-1", "poi-1", "user-1", true)
+	time.Sleep(time.Millisecond)
+	tracker.SetSpeaking("map-1", "poi-1", "user-2", true)
+	// user-1 is still speaking, but user-2 started later
+	tracker.SetSpeaking("map-1", "poi-1", "user-1", true)
+	tracker.SetSpeaking("map-1", "poi-1", "user-2", false)
+	tracker.SetSpeaking("map-1", "poi-1", "user-1", false)
+
+	assert.Equal(t, []interface{}{"user-1", "user-2", "user-1", nil}, recorder.speakers())
+
+	data := recorder.broadcasts[3].msg.Data.(map[string]interface{})
+	assert.Equal(t, false, data["speaking"])
+	assert.Empty(t, tracker.pois, "POIs without speakers should be forgotten")
+}
+
+func TestSpeakerTracker_ClearUserEverywhere(t *testing.T) {
+	recorder := &broadcastRecorder{}
+	tracker := newSpeakerTracker(0, recorder.record)
+
+	tracker.SetSpeaking("map-1", "poi-1", "user-1", true)
+	tracker.SetSpeaking("map-1", "poi-2", "user-1", true)
+	tracker.ClearUserEverywhere("user-1")
+
+	assert.Equal(t, []interface{}{"user-1", "user-1", nil, nil}, recorder.speakers())
+	assert.Empty(t, tracker.pois)
+}
@@ -0,0 +1,202 @@
+package websocket
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"breakoutglobe/internal/metrics"
+	"breakoutglobe/internal/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// throughputWindow is how many seconds broadcast throughput is averaged over
+const throughputWindow = 60
+
+// hubStats counts the broadcasts of a Manager for the stats endpoint and metrics
+type hubStats struct {
+	broadcasts        atomic.Uint64
+	delivered         atomic.Uint64
+	droppedBroadcasts atomic.Uint64
+	droppedDeliveries atomic.Uint64
+	// shedMessages counts transient broadcasts dropped under overload
+	shedMessages atomic.Uint64
+
+	// throughput holds the broadcasts of each of the last seconds, indexed by
+	// the second modulo throughputWindow
+	mutex      sync.Mutex
+	throughput [throughputWindow]uint64
+	seconds    [throughputWindow]int64
+}
+
+// recordBroadcast counts a broadcast about to be delivered to clients
+func (s *hubStats) recordBroadcast(now time.Time) {
+	s.broadcasts.Add(1)
+
+	second := now.Unix()
+	i := second % throughputWindow
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.seconds[i] != second {
+		s.seconds[i] = second
+		s.throughput[i] = 0
+	}
+	s.throughput[i]++
+}
+
+// broadcastsPerSecond returns the average broadcasts per second over the
+// last throughputWindow seconds
+func (s *hubStats) broadcastsPerSecond(now time.Time) float64 {
+	second := now.Unix()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var total uint64
+	for i, at := range s.seconds {
+		if second-at < throughputWindow {
+			total += s.throughput[i]
+		}
+	}
+	return float64(total) / throughputWindow
+}
+
+// queuedMessages returns how many messages wait in the client's lanes
+func (c *Client) queuedMessages() int {
+	// The length of a nil lane is zero
+	return len(c.Send) + len(c.signaling) + len(c.movement)
+}
+
+// Stats returns the connections, queue depths and broadcast totals of the hub
+func (m *Manager) Stats() models.WebSocketStats {
+	now := time.Now()
+
+	m.mutex.RLock()
+	stats := models.WebSocketStats{
+		Connections: len(m.clients),
+		Maps:        make([]models.MapWebSocketStats, 0, len(m.mapClients)),
+	}
+	for mapID, mapClients := range m.mapClients {
+		mapStats := models.MapWebSocketStats{
+			MapID:       mapID,
+			Connections: len(mapClients),
+		}
+		for _, client := range mapClients {
+			queued := client.queuedMessages()
+			mapStats.QueuedMessages += queued
+			if queued > mapStats.MaxQueuedMessages {
+				mapStats.MaxQueuedMessages = queued
+			}
+		}
+		stats.QueuedMessages += mapStats.QueuedMessages
+		stats.Maps = append(stats.Maps, mapStats)
+	}
+	m.mutex.RUnlock()
+
+	sort.Slice(stats.Maps, func(i, j int) bool {
+		return stats.Maps[i].MapID < stats.Maps[j].MapID
+	})
+
+	stats.BroadcastQueueDepth = len(m.broadcast)
+	stats.BroadcastQueueCapacity = cap(m.broadcast)
+	stats.BroadcastsTotal = m.stats.broadcasts.Load()
+	stats.BroadcastsPerSecond = m.stats.broadcastsPerSecond(now)
+	stats.DeliveredTotal = m.stats.delivered.Load()
+	stats.DroppedBroadcasts = m.stats.droppedBroadcasts.Load()
+	stats.DroppedDeliveries = m.stats.droppedDeliveries.Load()
+	stats.ShedMessages = m.stats.shedMessages.Load()
+	stats.DegradedBroadcasts = m.degradedBroadcasts()
+	stats.CollectedAt = now
+	return stats
+}
+
+// WebSocketStats returns the health of the WebSocket hub for operators
+func (h *Handler) WebSocketStats() models.WebSocketStats {
+	return h.manager.Stats()
+}
+
+// registerMetrics exposes the hub statistics as Prometheus metrics, collected
+// when scraped
+func (m *Manager) registerMetrics(registry prometheus.Registerer) {
+	registry.MustRegister(metrics.NewGaugeFunc(
+		"breakoutglobe_websocket_connections",
+		"Open WebSocket connections by map.",
+		func() []metrics.Sample {
+			return m.mapSamples(func(stats models.MapWebSocketStats) int { return stats.Connections })
+		},
+		"map_id",
+	))
+
+	registry.MustRegister(metrics.NewGaugeFunc(
+		"breakoutglobe_websocket_send_queue_depth",
+		"Messages waiting in the send queues of WebSocket clients by map.",
+		func() []metrics.Sample {
+			return m.mapSamples(func(stats models.MapWebSocketStats) int { return stats.QueuedMessages })
+		},
+		"map_id",
+	))
+
+	registry.MustRegister(metrics.NewGaugeFunc(
+		"breakoutglobe_websocket_broadcast_queue_depth",
+		"Broadcasts waiting to be delivered to the clients of their map.",
+		func() []metrics.Sample {
+			return []metrics.Sample{{Value: float64(len(m.broadcast))}}
+		},
+	))
+
+	registry.MustRegister(metrics.NewGaugeFunc(
+		"breakoutglobe_websocket_broadcasts_degraded",
+		"1 while broadcasts are degraded to shed load, 0 otherwise.",
+		func() []metrics.Sample {
+			value := 0.0
+			if m.degraded.Load() {
+				value = 1
+			}
+			return []metrics.Sample{{Value: value}}
+		},
+	))
+
+	registry.MustRegister(metrics.NewCounterFunc(
+		"breakoutglobe_websocket_broadcasts_total",
+		"Broadcasts delivered to the clients of a map or all clients.",
+		func() []metrics.Sample {
+			return []metrics.Sample{{Value: float64(m.stats.broadcasts.Load())}}
+		},
+	))
+
+	registry.MustRegister(metrics.NewCounterFunc(
+		"breakoutglobe_websocket_messages_delivered_total",
+		"Broadcast messages queued for a WebSocket client.",
+		func() []metrics.Sample {
+			return []metrics.Sample{{Value: float64(m.stats.delivered.Load())}}
+		},
+	))
+
+	registry.MustRegister(metrics.NewCounterFunc(
+		"breakoutglobe_websocket_messages_dropped_total",
+		"Broadcast messages dropped because the broadcast queue or a client's send queue was full, or to shed load.",
+		func() []metrics.Sample {
+			return []metrics.Sample{
+				{LabelValues: []string{"broadcast_queue_full"}, Value: float64(m.stats.droppedBroadcasts.Load())},
+				{LabelValues: []string{"send_queue_full"}, Value: float64(m.stats.droppedDeliveries.Load())},
+				{LabelValues: []string{"load_shed"}, Value: float64(m.stats.shedMessages.Load())},
+			}
+		},
+		"reason",
+	))
+}
+
+// mapSamples returns one sample per map with connected clients
+func (m *Manager) mapSamples(value func(stats models.MapWebSocketStats) int) []metrics.Sample {
+	stats := m.Stats()
+	samples := make([]metrics.Sample, 0, len(stats.Maps))
+	for _, mapStats := range stats.Maps {
+		samples = append(samples, metrics.Sample{
+			LabelValues: []string{mapStats.MapID},
+			Value:       float64(value(mapStats)),
+		})
+	}
+	return samples
+}
@@ -0,0 +1,70 @@
+package websocket
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_Stats(t *testing.T) {
+	manager := NewManager()
+	defer manager.Shutdown()
+
+	manager.registerClient(&Client{SessionID: "session-1", MapID: "map-1", Send: make(chan Message, 10)})
+	manager.registerClient(&Client{SessionID: "session-2", MapID: "map-1", Send: make(chan Message, 1)})
+	manager.registerClient(&Client{SessionID: "session-3", MapID: "map-2", Send: make(chan Message, 10)})
+
+	// session-2 can't take the second broadcast and is evicted
+	manager.broadcastToMap(BroadcastMessage{MapID: "map-1", Message: Message{Type: "poi_created"}})
+	manager.broadcastToMap(BroadcastMessage{MapID: "map-1", Message: Message{Type: "poi_updated"}})
+
+	stats := manager.Stats()
+	assert.Equal(t, 2, stats.Connections)
+	assert.Equal(t, 2, stats.QueuedMessages)
+	assert.Equal(t, uint64(2), stats.BroadcastsTotal)
+	assert.Equal(t, uint64(3), stats.DeliveredTotal)
+	assert.Equal(t, uint64(1), stats.DroppedDeliveries)
+	assert.Equal(t, 100, stats.BroadcastQueueCapacity)
+	assert.InDelta(t, 2.0/throughputWindow, stats.BroadcastsPerSecond, 0.0001)
+
+	require.Len(t, stats.Maps, 2)
+	assert.Equal(t, "map-1", stats.Maps[0].MapID)
+	assert.Equal(t, 1, stats.Maps[0].Connections)
+	assert.Equal(t, 2, stats.Maps[0].MaxQueuedMessages)
+	assert.Equal(t, "map-2", stats.Maps[1].MapID)
+	assert.Equal(t, 0, stats.Maps[1].QueuedMessages)
+}
+
+func TestHubStats_ThroughputForgetsOldSeconds(t *testing.T) {
+	var stats hubStats
+	start := time.Unix(1700000000, 0)
+
+	for i := 0; i < 30; i++ {
+		stats.recordBroadcast(start)
+	}
+	stats.recordBroadcast(start.Add(time.Second))
+	assert.InDelta(t, 31.0/throughputWindow, stats.broadcastsPerSecond(start.Add(time.Second)), 0.0001)
+
+	// A minute later only the second broadcast is within the window
+	assert.InDelta(t, 1.0/throughputWindow, stats.broadcastsPerSecond(start.Add(throughputWindow*time.Second)), 0.0001)
+	assert.Equal(t, uint64(31), stats.broadcasts.Load())
+}
+
+func TestHandler_SetMetricsExposesHubStats(t *testing.T) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, new(MockPOIService))
+	registry := prometheus.NewRegistry()
+	handler.SetMetrics(registry)
+
+	handler.manager.registerClient(&Client{SessionID: "session-1", MapID: "map-1", Send: make(chan Message, 10)})
+	handler.manager.broadcastToMap(BroadcastMessage{MapID: "map-1", Message: Message{Type: "poi_created"}})
+
+	output := scrapeMetrics(t, registry)
+	assert.Contains(t, output, `breakoutglobe_websocket_connections{map_id="map-1"} 1`)
+	assert.Contains(t, output, `breakoutglobe_websocket_send_queue_depth{map_id="map-1"} 1`)
+	assert.Contains(t, output, "breakoutglobe_websocket_broadcast_queue_depth 0")
+	assert.Contains(t, output, "breakoutglobe_websocket_broadcasts_total 1")
+	assert.Contains(t, output, `breakoutglobe_websocket_messages_dropped_total{reason="send_queue_full"} 0`)
+}
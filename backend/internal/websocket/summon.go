@@ -0,0 +1,146 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/services"
+)
+
+// maxSummonUsers bounds the users selected in one summon_to_poi message
+const maxSummonUsers = 500
+
+// SummonInterface defines the interface for moving participants to a POI
+type SummonInterface interface {
+	Summon(ctx context.Context, mapID, summonedBy, poiID string, userIDs []string) (*services.SummonResult, error)
+}
+
+// SetSummon enables the summon_to_poi facilitator command. Without it,
+// participants can't be moved to a POI.
+func (h *Handler) SetSummon(summon SummonInterface) {
+	h.summon = summon
+}
+
+// SummonToPOIPayload is the data of summon_to_poi messages. Without users,
+// everyone on the map but the facilitator is summoned.
+type SummonToPOIPayload struct {
+	POIID   string   `json:"poiId"`
+	UserIDs []string `json:"userIds,omitempty"`
+}
+
+// Validate checks that the POI is set and at most maxSummonUsers are selected
+func (p SummonToPOIPayload) Validate() error {
+	if p.POIID == "" {
+		return errors.New("poiId is required")
+	}
+	if len(p.UserIDs) > maxSummonUsers {
+		return fmt.Errorf("summon_to_poi must not select more than %d users", maxSummonUsers)
+	}
+	return nil
+}
+
+// validateSummonToPOI validates summon_to_poi messages
+func validateSummonToPOI(msg Message) error {
+	var payload SummonToPOIPayload
+	if err := decodePayload(msg, &payload); err != nil {
+		return err
+	}
+	return payload.Validate()
+}
+
+// handleSummonToPOI moves participants of the client's map to a POI and
+// answers with who was moved and joined. Only facilitators can summon.
+func (h *Handler) handleSummonToPOI(ctx context.Context, client *Client, msg Message) {
+	if h.summon == nil {
+		h.sendErrorMessage(ctx, client, "Summoning is not available")
+		return
+	}
+	if !h.manager.IsFacilitator(client) {
+		h.sendSummonReply(ctx, client, Message{
+			Type: "error",
+			Data: map[string]interface{}{
+				"code":    "FORBIDDEN",
+				"message": "Only facilitators can summon participants",
+			},
+			Timestamp: time.Now(),
+		})
+		return
+	}
+
+	var payload SummonToPOIPayload
+	if !h.decodeMessagePayload(client, msg, &payload) {
+		return
+	}
+
+	result, err := h.summon.Summon(ctx, client.MapID, client.UserID, payload.POIID, payload.UserIDs)
+	if err != nil {
+		if errors.Is(err, services.ErrNotFound) || errors.Is(err, services.ErrInvalidInput) {
+			h.sendErrorMessage(ctx, client, err.Error())
+			return
+		}
+		h.requestLogger(ctx).Error("Failed to summon participants", "mapId", client.MapID, "poiId", payload.POIID, "error", err)
+		h.sendErrorMessage(ctx, client, "Failed to summon participants")
+		return
+	}
+
+	h.requestLogger(ctx).Info("📣 Participants summoned to POI",
+		"mapId", client.MapID,
+		"poiId", payload.POIID,
+		"userId", client.UserID,
+		"users", len(result.Users))
+
+	h.sendSummonReply(ctx, client, Message{
+		Type:      "summon_to_poi_ack",
+		Data:      result,
+		Timestamp: time.Now(),
+	})
+}
+
+// handleSummonEvent places the summoned avatars connected to this instance
+// and tells the clients of the map, so everyone moves them and the summoned
+// clients follow their avatar to the POI
+func (h *Handler) handleSummonEvent(data interface{}) {
+	summonData, ok := data.(map[string]interface{})
+	if !ok {
+		h.logger.Error("❌ Invalid summon event data", "data", data)
+		return
+	}
+
+	mapID, _ := summonData["mapId"].(string)
+	if mapID == "" {
+		h.logger.Error("❌ Missing mapId in summon event", "data", data)
+		return
+	}
+
+	if users, ok := summonData["users"].([]models.SummonedUser); ok {
+		for _, user := range users {
+			if !user.Moved {
+				continue
+			}
+			for _, sessionID := range user.SessionIDs {
+				if h.manager.IsClientConnected(sessionID) {
+					h.avatars.Set(mapID, sessionID, user.Position)
+				}
+			}
+		}
+	}
+
+	h.manager.BroadcastToMap(mapID, Message{
+		Type:        "summoned_to_poi",
+		Data:        summonData,
+		Timestamp:   time.Now(),
+		publishedAt: eventPublishedAt(summonData),
+	})
+}
+
+// sendSummonReply answers a summon_to_poi of the client
+func (h *Handler) sendSummonReply(ctx context.Context, client *Client, message Message) {
+	select {
+	case client.Send <- replyTo(ctx, message):
+	default:
+		h.requestLogger(ctx).Warn("Failed to send summon reply to client", "sessionId", client.SessionID, "type", message.Type)
+	}
+}
@@ -0,0 +1,87 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"breakoutglobe/internal/models"
+	"breakoutglobe/internal/redis"
+	"breakoutglobe/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dispatchingSummon summons everyone to the POI and hands the event straight
+// to the handler, like the PubSub listener does
+type dispatchingSummon struct {
+	handler *Handler
+	users   []models.SummonedUser
+}
+
+func (s *dispatchingSummon) Summon(ctx context.Context, mapID, summonedBy, poiID string, userIDs []string) (*services.SummonResult, error) {
+	if poiID != "poi-1" {
+		return nil, services.ErrNotFound
+	}
+	data, err := json.Marshal(redis.SummonEvent{MapID: mapID, POIID: poiID, SummonedBy: summonedBy, Users: s.users})
+	if err != nil {
+		return nil, err
+	}
+	redis.DispatchPOIEvent(redis.Event{Type: redis.EventTypeSummonedToPOI, Data: data}, s.handler.handlePubSubEvent)
+	return &services.SummonResult{POIID: poiID, Users: s.users}, nil
+}
+
+func newSummonTestHandler(t *testing.T) (*Handler, *Client, *Client) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, nil)
+	t.Cleanup(handler.manager.Shutdown)
+	position := models.LatLng{Lat: 52.52005, Lng: 13.405}
+	handler.SetSummon(&dispatchingSummon{handler: handler, users: []models.SummonedUser{
+		{UserID: "user-2", SessionIDs: []string{"session-2"}, Position: position, Moved: true, Joined: true},
+		{UserID: "user-3", Error: "not connected to the map"},
+	}})
+
+	facilitator := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1", role: models.UserRoleAdmin, Send: make(chan Message, 10)}
+	participant := &Client{SessionID: "session-2", UserID: "user-2", MapID: "map-1", Send: make(chan Message, 10)}
+	handler.manager.registerClient(facilitator)
+	handler.manager.registerClient(participant)
+	return handler, facilitator, participant
+}
+
+func TestHandler_SummonToPOI(t *testing.T) {
+	handler, facilitator, participant := newSummonTestHandler(t)
+	ctx := withRequestID(context.Background(), "req-1")
+
+	handler.handleSummonToPOI(ctx, facilitator, Message{Type: "summon_to_poi", Data: map[string]interface{}{"poiId": "poi-1"}, RequestID: "req-1"})
+
+	summoned := receiveType(t, participant, "summoned_to_poi")
+	data := summoned.Data.(map[string]interface{})
+	assert.Equal(t, "poi-1", data["poiId"])
+	assert.Equal(t, "user-1", data["summonedBy"])
+	assert.Equal(t, []models.LatLng{{Lat: 52.52005, Lng: 13.405}}, handler.avatars.Others("map-1", "session-1"))
+
+	ack := receiveType(t, facilitator, "summon_to_poi_ack")
+	assert.Equal(t, "req-1", ack.RequestID)
+	result := ack.Data.(*services.SummonResult)
+	require.Len(t, result.Users, 2)
+	assert.True(t, result.Users[0].Joined)
+}
+
+func TestHandler_SummonToPOI_Rejected(t *testing.T) {
+	handler, facilitator, participant := newSummonTestHandler(t)
+	ctx := context.Background()
+
+	handler.handleSummonToPOI(ctx, participant, Message{Type: "summon_to_poi", Data: map[string]interface{}{"poiId": "poi-1"}})
+	rejected := receiveType(t, participant, "error")
+	assert.Equal(t, "FORBIDDEN", rejected.Data.(map[string]interface{})["code"])
+
+	handler.handleSummonToPOI(ctx, facilitator, Message{Type: "summon_to_poi", Data: map[string]interface{}{"poiId": "poi-2"}})
+	receiveType(t, facilitator, "error")
+	assert.Empty(t, participant.Send)
+}
+
+func TestValidateSummonToPOI(t *testing.T) {
+	assert.NoError(t, validateSummonToPOI(Message{Type: "summon_to_poi", Data: map[string]interface{}{"poiId": "poi-1", "userIds": []string{"user-2"}}}))
+	assert.Error(t, validateSummonToPOI(Message{Type: "summon_to_poi", Data: map[string]interface{}{}}))
+	assert.Error(t, validateSummonToPOI(Message{Type: "summon_to_poi", Data: map[string]interface{}{"poiId": "poi-1", "userIds": make([]string, maxSummonUsers+1)}}))
+}
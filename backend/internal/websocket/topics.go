@@ -0,0 +1,235 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxTopicSubscriptions bounds the topics one connection can subscribe to
+const maxTopicSubscriptions = 50
+
+// Topic kinds clients can subscribe to, as in poi:<poiId> and user:<userId>
+const (
+	topicKindPOI  = "poi"
+	topicKindUser = "user"
+)
+
+// errTooManySubscriptions is returned when a connection subscribes to more
+// than maxTopicSubscriptions topics
+var errTooManySubscriptions = fmt.Errorf("at most %d topics can be subscribed to", maxTopicSubscriptions)
+
+// TopicPayload is the data of subscribe and unsubscribe messages
+type TopicPayload struct {
+	Topic string `json:"topic"`
+}
+
+// Validate checks that the topic is of a known kind
+func (p TopicPayload) Validate() error {
+	_, _, err := parseTopic(p.Topic)
+	return err
+}
+
+// parseTopic splits a topic into its kind and the ID of what it's about
+func parseTopic(topic string) (string, string, error) {
+	kind, id, ok := strings.Cut(topic, ":")
+	if !ok || id == "" {
+		return "", "", errors.New("topic must look like poi:<poiId> or user:<userId>")
+	}
+	switch kind {
+	case topicKindPOI, topicKindUser:
+		return kind, id, nil
+	default:
+		return "", "", fmt.Errorf("unknown topic kind %q", kind)
+	}
+}
+
+// poiTopic returns the topic of a POI's events
+func poiTopic(poiID string) string {
+	return topicKindPOI + ":" + poiID
+}
+
+// userTopic returns the topic of a user's status events, like call status
+func userTopic(userID string) string {
+	return topicKindUser + ":" + userID
+}
+
+// poiEventTopics returns the topic of the POI a pub/sub event is about
+func poiEventTopics(data map[string]interface{}) []string {
+	poiID, _ := data["poiId"].(string)
+	if poiID == "" {
+		return nil
+	}
+	return []string{poiTopic(poiID)}
+}
+
+// onTopics marks the topics a map broadcast belongs to. Subscribers of the
+// topics get it too, even if they're on another map or the broadcast is
+// outside their viewport.
+func onTopics(message Message, topics ...string) Message {
+	message.topics = topics
+	return message
+}
+
+// Subscribe adds a topic to the client's subscriptions
+func (m *Manager) Subscribe(client *Client, topic string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// Clients that disconnected already are left alone
+	if m.clients[client.connectionKey()] != client {
+		return nil
+	}
+	if client.topics[topic] {
+		return nil
+	}
+	if len(client.topics) >= maxTopicSubscriptions {
+		return errTooManySubscriptions
+	}
+
+	if client.topics == nil {
+		client.topics = make(map[string]bool)
+	}
+	client.topics[topic] = true
+	if m.topics[topic] == nil {
+		m.topics[topic] = make(map[string]*Client)
+	}
+	m.topics[topic][client.connectionKey()] = client
+	return nil
+}
+
+// Unsubscribe removes a topic from the client's subscriptions
+func (m *Manager) Unsubscribe(client *Client, topic string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	delete(client.topics, topic)
+	m.removeSubscriber(topic, client)
+}
+
+// removeSubscriptions removes all subscriptions of a client that
+// disconnected. The caller must hold the write lock.
+func (m *Manager) removeSubscriptions(client *Client) {
+	for topic := range client.topics {
+		m.removeSubscriber(topic, client)
+	}
+	client.topics = nil
+}
+
+// removeSubscriber removes a client from the subscribers of a topic. The
+// caller must hold the write lock.
+func (m *Manager) removeSubscriber(topic string, client *Client) {
+	subscribers, exists := m.topics[topic]
+	if !exists || subscribers[client.connectionKey()] != client {
+		return
+	}
+	delete(subscribers, client.connectionKey())
+	if len(subscribers) == 0 {
+		delete(m.topics, topic)
+	}
+}
+
+// deliverToTopics delivers a map broadcast to the subscribers of its topics
+// that didn't get it as clients of the map. Subscribers on other maps get it
+// without the map's journal sequence number, which only means something on
+// the map. The caller must hold the write lock.
+func (m *Manager) deliverToTopics(broadcastMsg BroadcastMessage, delivered map[*Client]bool) {
+	for _, topic := range broadcastMsg.Message.topics {
+		for _, client := range m.topics[topic] {
+			if delivered[client] {
+				continue
+			}
+			if broadcastMsg.ExceptID != "" && client.SessionID == broadcastMsg.ExceptID {
+				continue
+			}
+			delivered[client] = true
+
+			message := broadcastMsg.Message
+			if client.MapID != broadcastMsg.MapID {
+				message.MapSeq = 0
+			}
+			if !m.deliver(client, message) {
+				m.logger.Warn("❌ Client send channel full during topic delivery, closing connection",
+					"sessionId", client.SessionID,
+					"topic", topic,
+					"messageType", message.Type)
+				m.evictSlowConsumer(client)
+			}
+		}
+	}
+}
+
+// validateTopic validates subscribe and unsubscribe messages
+func validateTopic(msg Message) error {
+	var payload TopicPayload
+	if err := decodePayload(msg, &payload); err != nil {
+		return err
+	}
+	return payload.Validate()
+}
+
+// handleSubscribe subscribes the client to a topic, so it gets the topic's
+// events beyond its map's broadcasts
+func (h *Handler) handleSubscribe(ctx context.Context, client *Client, msg Message) {
+	var payload TopicPayload
+	if !h.decodeMessagePayload(client, msg, &payload) {
+		return
+	}
+	kind, id, _ := parseTopic(payload.Topic)
+
+	if err := h.authorizeTopic(ctx, client, kind, id); err != nil {
+		h.sendErrorMessage(ctx, client, "Cannot subscribe to "+payload.Topic+": "+err.Error())
+		return
+	}
+	if err := h.manager.Subscribe(client, payload.Topic); err != nil {
+		h.sendErrorMessage(ctx, client, "Cannot subscribe to "+payload.Topic+": "+err.Error())
+		return
+	}
+
+	h.sendTopicReply(ctx, client, "subscribed", payload.Topic)
+}
+
+// handleUnsubscribe ends a subscription of the client
+func (h *Handler) handleUnsubscribe(ctx context.Context, client *Client, msg Message) {
+	var payload TopicPayload
+	if !h.decodeMessagePayload(client, msg, &payload) {
+		return
+	}
+
+	h.manager.Unsubscribe(client, payload.Topic)
+	h.sendTopicReply(ctx, client, "unsubscribed", payload.Topic)
+}
+
+// authorizeTopic checks that the client may follow a topic. POIs can only be
+// followed on the client's own map; user topics only carry status everyone
+// on the user's map sees, so any user can be followed.
+func (h *Handler) authorizeTopic(ctx context.Context, client *Client, kind, id string) error {
+	if kind != topicKindPOI {
+		return nil
+	}
+	if h.poiService == nil {
+		return errors.New("POIs are not available")
+	}
+
+	pois, err := h.poiService.GetPOIsForMap(ctx, client.MapID)
+	if err != nil {
+		return errors.New("failed to look up the POI")
+	}
+	for _, poi := range pois {
+		if poi.ID == id {
+			return nil
+		}
+	}
+	return errors.New("POI not found on this map")
+}
+
+// sendTopicReply confirms a subscription change to the client
+func (h *Handler) sendTopicReply(ctx context.Context, client *Client, messageType, topic string) {
+	select {
+	case client.Send <- replyTo(ctx, Message{Type: messageType, Data: map[string]interface{}{"topic": topic}, Timestamp: time.Now()}):
+	default:
+		h.requestLogger(ctx).Warn("Failed to send topic reply to client", "sessionId", client.SessionID, "type", messageType)
+	}
+}
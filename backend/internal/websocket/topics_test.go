@@ -0,0 +1,124 @@
+package websocket
+
+import (
+	"fmt"
+	"testing"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTopicTestClient(manager *Manager, sessionID, userID, mapID string) *Client {
+	client := &Client{SessionID: sessionID, UserID: userID, MapID: mapID, Send: make(chan Message, 10)}
+	manager.registerClient(client)
+	return client
+}
+
+func TestParseTopic(t *testing.T) {
+	kind, id, err := parseTopic("poi:poi-1")
+	require.NoError(t, err)
+	assert.Equal(t, topicKindPOI, kind)
+	assert.Equal(t, "poi-1", id)
+
+	kind, id, err = parseTopic("user:user-1")
+	require.NoError(t, err)
+	assert.Equal(t, topicKindUser, kind)
+	assert.Equal(t, "user-1", id)
+
+	for _, topic := range []string{"", "poi", "poi:", "map:map-1"} {
+		_, _, err := parseTopic(topic)
+		assert.Error(t, err, topic)
+	}
+}
+
+func TestManager_BroadcastToMap_DeliversToTopicSubscribers(t *testing.T) {
+	manager := NewManager()
+	defer manager.Shutdown()
+	manager.SetEventJournal(&fakeEventJournal{maxLen: 10})
+
+	onMap := newTopicTestClient(manager, "session-1", "user-1", "map-1")
+	elsewhere := newTopicTestClient(manager, "session-2", "user-2", "map-2")
+	notSubscribed := newTopicTestClient(manager, "session-3", "user-3", "map-2")
+	require.NoError(t, manager.Subscribe(onMap, userTopic("user-4")))
+	require.NoError(t, manager.Subscribe(elsewhere, userTopic("user-4")))
+
+	status := onTopics(Message{Type: "user_call_status", Data: map[string]interface{}{"userId": "user-4", "isInCall": true}}, userTopic("user-4"))
+	manager.broadcastToMap(BroadcastMessage{MapID: "map-1", Message: status})
+	waitForJournal(t, manager)
+
+	// Clients of the map get it once, subscribers elsewhere without the map's sequence number
+	require.Len(t, onMap.Send, 1)
+	require.Len(t, elsewhere.Send, 1)
+	assert.Empty(t, notSubscribed.Send)
+	assert.NotZero(t, (<-onMap.Send).MapSeq)
+	received := <-elsewhere.Send
+	assert.Equal(t, "user_call_status", received.Type)
+	assert.Zero(t, received.MapSeq)
+
+	// Unsubscribed and disconnected clients don't get topic broadcasts anymore
+	manager.Unsubscribe(elsewhere, userTopic("user-4"))
+	manager.broadcastToMap(BroadcastMessage{MapID: "map-1", Message: status})
+	waitForJournal(t, manager)
+	assert.Empty(t, elsewhere.Send)
+
+	require.NoError(t, manager.Subscribe(notSubscribed, userTopic("user-4")))
+	manager.unregisterClient(notSubscribed)
+	assert.NotContains(t, manager.topics[userTopic("user-4")], notSubscribed.connectionKey())
+}
+
+func TestManager_BroadcastToMap_TopicsBypassViewport(t *testing.T) {
+	manager := NewManager()
+	defer manager.Shutdown()
+
+	client := newTopicTestClient(manager, "session-1", "user-1", "map-1")
+	manager.SetViewport(client, models.Bounds{North: 38, South: 34, East: 142, West: 138})
+	require.NoError(t, manager.Subscribe(client, poiTopic("poi-1")))
+
+	berlin := models.LatLng{Lat: 52.52, Lng: 13.405}
+	elsewhere := atPositions(Message{Type: "poi_created"}, models.PositionPrivacy{}, berlin)
+	manager.broadcastToMap(BroadcastMessage{MapID: "map-1", Message: onTopics(elsewhere, poiTopic("poi-2"))})
+	assert.Empty(t, client.Send)
+
+	manager.broadcastToMap(BroadcastMessage{MapID: "map-1", Message: onTopics(elsewhere, poiTopic("poi-1"))})
+	assert.Len(t, client.Send, 1)
+}
+
+func TestManager_Subscribe_LimitsTopics(t *testing.T) {
+	manager := NewManager()
+	defer manager.Shutdown()
+	client := newTopicTestClient(manager, "session-1", "user-1", "map-1")
+
+	for i := 0; i < maxTopicSubscriptions; i++ {
+		require.NoError(t, manager.Subscribe(client, userTopic(fmt.Sprintf("user-%d", i))))
+	}
+	// Subscribing again to a topic doesn't count
+	require.NoError(t, manager.Subscribe(client, userTopic("user-0")))
+	assert.ErrorIs(t, manager.Subscribe(client, userTopic("user-x")), errTooManySubscriptions)
+}
+
+func TestHandler_Subscribe(t *testing.T) {
+	poiService := new(MockPOIService)
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, poiService)
+	defer handler.manager.Shutdown()
+	client := newTopicTestClient(handler.manager, "session-1", "user-1", "map-1")
+	poiService.On("GetPOIsForMap", mock.Anything, "map-1").Return([]*models.POI{{ID: "poi-1", MapID: "map-1"}}, nil)
+
+	handler.handleMessage(client, Message{Type: "subscribe", Data: map[string]interface{}{"topic": "poi:poi-1"}})
+	reply := <-client.Send
+	assert.Equal(t, "subscribed", reply.Type)
+	assert.Equal(t, map[string]interface{}{"topic": "poi:poi-1"}, reply.Data)
+
+	// POIs of other maps can't be followed
+	handler.handleMessage(client, Message{Type: "subscribe", Data: map[string]interface{}{"topic": "poi:poi-2"}})
+	reply = <-client.Send
+	assert.Equal(t, "error", reply.Type)
+	assert.NotContains(t, handler.manager.topics, poiTopic("poi-2"))
+
+	handler.handleMessage(client, Message{Type: "unsubscribe", Data: map[string]interface{}{"topic": "poi:poi-1"}})
+	reply = <-client.Send
+	assert.Equal(t, "unsubscribed", reply.Type)
+	assert.Empty(t, handler.manager.topics)
+}
@@ -0,0 +1,40 @@
+package websocket
+
+// UsageRecorderInterface defines the interface for metering map usage for
+// quotas and billing
+type UsageRecorderInterface interface {
+	RecordWebSocketBytes(mapID string, bytes int)
+	StartCall(mapID, callID string)
+	EndCall(callID string)
+}
+
+// SetUsageRecorder meters the messages sent to each map's clients and the time
+// of the calls held on it
+func (h *Handler) SetUsageRecorder(usage UsageRecorderInterface) {
+	h.usage = usage
+}
+
+// recordUsageBytes meters a written message against the client's map. The
+// size is taken before compression, like the payload metrics.
+func (c *Client) recordUsageBytes(size int) {
+	if c.usage == nil {
+		return
+	}
+	c.usage.RecordWebSocketBytes(c.MapID, size)
+}
+
+// startCallUsage starts metering an accepted call
+func (h *Handler) startCallUsage(mapID, callID string) {
+	if h.usage == nil || callID == "" {
+		return
+	}
+	h.usage.StartCall(mapID, callID)
+}
+
+// endCallUsage stops metering a call
+func (h *Handler) endCallUsage(callID string) {
+	if h.usage == nil || callID == "" {
+		return
+	}
+	h.usage.EndCall(callID)
+}
@@ -0,0 +1,67 @@
+package websocket
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingUsage struct {
+	bytes   map[string]int
+	started map[string]string
+	ended   []string
+}
+
+func newRecordingUsage() *recordingUsage {
+	return &recordingUsage{bytes: map[string]int{}, started: map[string]string{}}
+}
+
+func (u *recordingUsage) RecordWebSocketBytes(mapID string, bytes int) {
+	u.bytes[mapID] += bytes
+}
+
+func (u *recordingUsage) StartCall(mapID, callID string) {
+	u.started[callID] = mapID
+}
+
+func (u *recordingUsage) EndCall(callID string) {
+	u.ended = append(u.ended, callID)
+}
+
+func TestHandler_CallUsage(t *testing.T) {
+	handler := NewHandler(new(MockSessionService), new(MockRateLimiter), nil, new(MockPOIService))
+	defer handler.manager.Shutdown()
+	usage := newRecordingUsage()
+	handler.SetUsageRecorder(usage)
+
+	caller := &Client{SessionID: "session-1", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 10)}
+	callee := &Client{SessionID: "session-2", UserID: "user-2", MapID: "map-1", Send: make(chan Message, 10)}
+	handler.manager.registerClient(caller)
+	handler.manager.registerClient(callee)
+
+	handler.handleCallAccept(context.Background(), callee, Message{Type: "call_accept", Data: map[string]interface{}{
+		"callId":       "call-1",
+		"callerUserId": "user-1",
+	}})
+	assert.Equal(t, map[string]string{"call-1": "map-1"}, usage.started)
+
+	handler.handleCallEnd(context.Background(), caller, Message{Type: "call_end", Data: map[string]interface{}{
+		"callId":      "call-1",
+		"otherUserId": "user-2",
+	}})
+	assert.Equal(t, []string{"call-1"}, usage.ended)
+}
+
+func TestClient_RecordUsageBytes(t *testing.T) {
+	usage := newRecordingUsage()
+	client := &Client{MapID: "map-1", usage: usage}
+
+	client.recordUsageBytes(120)
+	client.recordUsageBytes(30)
+	assert.Equal(t, 150, usage.bytes["map-1"])
+
+	// Without metering nothing is recorded
+	(&Client{MapID: "map-1"}).recordUsageBytes(10)
+	assert.Equal(t, 150, usage.bytes["map-1"])
+}
@@ -0,0 +1,50 @@
+package websocket
+
+// connectionKey identifies the client among the manager's clients. Tabs of a
+// user share their session, so each connection has its own ID; clients
+// created without one, like in tests, are keyed by their session.
+func (c *Client) connectionKey() string {
+	if c.connectionID != "" {
+		return c.connectionID
+	}
+	return c.SessionID
+}
+
+// connectUser counts a new connection of a user on a map. It returns true for
+// the user's first connection, which makes the user join the map.
+func (m *Manager) connectUser(mapID, userID string) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.userConnections[mapID] == nil {
+		m.userConnections[mapID] = make(map[string]int)
+	}
+	m.userConnections[mapID][userID]++
+	return m.userConnections[mapID][userID] == 1
+}
+
+// disconnectUser counts a closed connection of a user on a map. It returns
+// true once the user's last connection closed, which makes the user leave the map.
+func (m *Manager) disconnectUser(mapID, userID string) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	connections := m.userConnections[mapID]
+	if connections[userID] <= 1 {
+		delete(connections, userID)
+		if len(connections) == 0 {
+			delete(m.userConnections, mapID)
+		}
+		return true
+	}
+	connections[userID]--
+	return false
+}
+
+// userConnectionCount returns the open connections of a user on a map
+func (m *Manager) userConnectionCount(mapID, userID string) int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.userConnections[mapID][userID]
+}
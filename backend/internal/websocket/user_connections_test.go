@@ -0,0 +1,131 @@
+package websocket
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/gin-gonic/gin"
+	ws "github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_UserConnections(t *testing.T) {
+	manager := NewManager()
+	defer manager.Shutdown()
+
+	assert.True(t, manager.connectUser("map-1", "user-1"))
+	assert.False(t, manager.connectUser("map-1", "user-1"))
+	// Connections on other maps are counted separately
+	assert.True(t, manager.connectUser("map-2", "user-1"))
+	assert.Equal(t, 2, manager.userConnectionCount("map-1", "user-1"))
+
+	assert.False(t, manager.disconnectUser("map-1", "user-1"))
+	assert.True(t, manager.disconnectUser("map-1", "user-1"))
+	assert.Equal(t, 0, manager.userConnectionCount("map-1", "user-1"))
+	assert.Equal(t, 1, manager.userConnectionCount("map-2", "user-1"))
+}
+
+func TestManager_BroadcastToUser_ReachesAllTabs(t *testing.T) {
+	manager := NewManager()
+	defer manager.Shutdown()
+
+	first := &Client{connectionID: "connection-1", SessionID: "session-1", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 10)}
+	second := &Client{connectionID: "connection-2", SessionID: "session-1", UserID: "user-1", MapID: "map-1", Send: make(chan Message, 10)}
+	other := &Client{connectionID: "connection-3", SessionID: "session-2", UserID: "user-2", MapID: "map-1", Send: make(chan Message, 10)}
+	manager.registerClient(first)
+	manager.registerClient(second)
+	manager.registerClient(other)
+
+	manager.BroadcastToUser("user-1", Message{Type: "call_request"}, "")
+
+	assert.Len(t, first.Send, 1)
+	assert.Len(t, second.Send, 1)
+	assert.Len(t, other.Send, 0)
+
+	// Closing one tab keeps the other registered
+	manager.unregisterClient(first)
+	assert.True(t, manager.IsClientConnected("session-1"))
+	assert.ElementsMatch(t, []string{"session-1", "session-2"}, manager.GetMapClientSessions("map-1"))
+}
+
+func TestHandler_MultipleTabs_JoinAndLeaveOnce(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockSessionService := new(MockSessionService)
+	mockPOIService := new(MockPOIService)
+	mockPOIService.On("GetCurrentPOI", mock.Anything, mock.Anything).Return("", nil).Maybe()
+	for _, session := range []*models.Session{
+		{ID: "session-1", UserID: "user-1", MapID: "map-1", IsActive: true},
+		{ID: "session-2", UserID: "user-2", MapID: "map-1", IsActive: true},
+	} {
+		mockSessionService.On("GetSession", mock.Anything, session.ID).Return(session, nil)
+	}
+	handler := NewHandler(mockSessionService, new(MockRateLimiter), nil, mockPOIService)
+
+	router := gin.New()
+	handler.RegisterRoutes(router, nil)
+	server := httptest.NewServer(router)
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws?"
+
+	dial := func(sessionID string) *ws.Conn {
+		conn, _, err := ws.DefaultDialer.Dial(wsURL+sessionQuery(handler, sessionID), nil)
+		require.NoError(t, err)
+		var welcomeMsg, initialUsersMsg Message
+		require.NoError(t, conn.ReadJSON(&welcomeMsg))
+		require.NoError(t, conn.ReadJSON(&initialUsersMsg))
+		return conn
+	}
+	waitForClients := func(count int) {
+		require.Eventually(t, func() bool {
+			return handler.manager.GetConnectedClients() == count
+		}, time.Second, 10*time.Millisecond)
+	}
+	// readUntilMarker returns the types of the messages read before the marker
+	// broadcast by broadcastMarker
+	broadcastMarker := func() {
+		handler.manager.BroadcastToMap("map-1", Message{Type: "marker"})
+	}
+	readUntilMarker := func(conn *ws.Conn) []string {
+		var types []string
+		for {
+			var msg Message
+			require.NoError(t, conn.ReadJSON(&msg))
+			if msg.Type == "marker" {
+				return types
+			}
+			types = append(types, msg.Type)
+		}
+	}
+
+	observer := dial("session-2")
+	defer observer.Close()
+	waitForClients(1)
+
+	firstTab := dial("session-1")
+	waitForClients(2)
+	secondTab := dial("session-1")
+	defer secondTab.Close()
+	waitForClients(3)
+	broadcastMarker()
+	assert.Equal(t, []string{"user_joined"}, readUntilMarker(observer))
+	assert.Equal(t, 2, handler.manager.userConnectionCount("map-1", "user-1"))
+
+	// Closing one tab doesn't make the user leave, and the other tab keeps receiving broadcasts
+	firstTab.Close()
+	waitForClients(2)
+	broadcastMarker()
+	assert.Empty(t, readUntilMarker(observer))
+	assert.NotContains(t, readUntilMarker(secondTab), "user_left")
+
+	secondTab.Close()
+	waitForClients(1)
+	broadcastMarker()
+	assert.Equal(t, []string{"user_left"}, readUntilMarker(observer))
+}
@@ -0,0 +1,193 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sort"
+	"time"
+
+	"breakoutglobe/internal/models"
+)
+
+// viewportMarginRatio widens a client's viewport on every side by this share
+// of its size, so avatars and POIs just outside the visible area are already up
+// to date when the map is panned
+const viewportMarginRatio = 0.25
+
+// atPositions marks where a broadcast happens, so clients with a viewport only
+// get it if one of the positions is inside. On maps with position privacy,
+// regular participants are filtered by the coarse positions they see, so a
+// narrow viewport can't reveal exact positions.
+func atPositions(message Message, privacy models.PositionPrivacy, positions ...models.LatLng) Message {
+	message.positions = positions
+	if privacy.Enabled() {
+		message.coarsePositions = make([]models.LatLng, len(positions))
+		for i, position := range positions {
+			message.coarsePositions[i] = privacy.Coarsen(position)
+		}
+	}
+	return message
+}
+
+// inViewport reports whether a broadcast happens inside the client's viewport.
+// Clients without a viewport and broadcasts without positions aren't filtered.
+// The caller must hold the manager's mutex.
+func (c *Client) inViewport(message Message) bool {
+	if c.viewport == nil || len(message.positions) == 0 {
+		return true
+	}
+
+	positions := message.positions
+	if message.coarsePositions != nil && !c.isFacilitator() {
+		positions = message.coarsePositions
+	}
+	for _, position := range positions {
+		if c.viewport.Contains(position) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetViewport limits the client's position-bound broadcasts, like avatar_moved
+// and poi_created, to the bounds plus a margin and returns the widened bounds
+func (m *Manager) SetViewport(client *Client, bounds models.Bounds) models.Bounds {
+	expanded := expandBounds(bounds, viewportMarginRatio)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	client.viewport = &expanded
+	return expanded
+}
+
+// expandBounds widens bounds on every side by ratio of their size, covering
+// all longitudes once they wrap around the globe
+func expandBounds(bounds models.Bounds, ratio float64) models.Bounds {
+	latMargin := (bounds.North - bounds.South) * ratio
+	lngSpan := bounds.East - bounds.West
+	if bounds.West > bounds.East {
+		// Bounds cross the international date line
+		lngSpan += 360
+	}
+	lngMargin := lngSpan * ratio
+
+	expanded := models.Bounds{
+		North: math.Min(90, bounds.North+latMargin),
+		South: math.Max(-90, bounds.South-latMargin),
+	}
+	if lngSpan+2*lngMargin >= 360 {
+		expanded.West, expanded.East = -180, 180
+		return expanded
+	}
+	expanded.West = wrapLng(bounds.West - lngMargin)
+	expanded.East = wrapLng(bounds.East + lngMargin)
+	return expanded
+}
+
+// wrapLng brings a longitude that was moved past the date line back into range
+func wrapLng(lng float64) float64 {
+	if lng < -180 {
+		return lng + 360
+	}
+	if lng > 180 {
+		return lng - 360
+	}
+	return lng
+}
+
+// eventPosition returns the position of a POI event, whichever type it was
+// decoded into
+func eventPosition(data map[string]interface{}) (models.LatLng, bool) {
+	raw, exists := data["position"]
+	if !exists {
+		return models.LatLng{}, false
+	}
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return models.LatLng{}, false
+	}
+	var position models.LatLng
+	if err := json.Unmarshal(encoded, &position); err != nil {
+		return models.LatLng{}, false
+	}
+	return position, true
+}
+
+// handleViewportUpdate subscribes the client to the broadcasts of the map area
+// it displays and sends what happened there while it was outside
+func (h *Handler) handleViewportUpdate(ctx context.Context, client *Client, msg Message) {
+	var payload ViewportUpdatePayload
+	if !h.decodeMessagePayload(client, msg, &payload) {
+		return
+	}
+
+	bounds := payload.Bounds()
+	if err := bounds.Validate(); err != nil {
+		h.sendErrorMessage(ctx, client, "Invalid viewport: "+err.Error())
+		return
+	}
+
+	expanded := h.manager.SetViewport(client, bounds)
+
+	select {
+	case client.Send <- replyTo(ctx, Message{Type: "viewport_state", Data: h.viewportState(ctx, client, expanded), Timestamp: time.Now()}):
+	default:
+		h.requestLogger(ctx).Warn("Failed to send viewport state to client", "sessionId", client.SessionID)
+	}
+}
+
+// viewportState returns the other avatars and the POIs inside the bounds
+func (h *Handler) viewportState(ctx context.Context, client *Client, bounds models.Bounds) map[string]interface{} {
+	// Regular participants only see coarse positions on maps with position privacy
+	privacy := h.positionPrivacy(ctx, client.MapID)
+	if privacy.Enabled() && h.manager.IsFacilitator(client) {
+		privacy = models.PositionPrivacy{}
+	}
+
+	userIDs := h.manager.mapClientUserIDsBySession(client.MapID)
+	avatars := h.avatars.All(client.MapID)
+	sessionIDs := make([]string, 0, len(avatars))
+	for sessionID := range avatars {
+		sessionIDs = append(sessionIDs, sessionID)
+	}
+	sort.Strings(sessionIDs)
+
+	positions := []map[string]interface{}{}
+	for _, sessionID := range sessionIDs {
+		position := avatars[sessionID]
+		if sessionID == client.SessionID {
+			continue
+		}
+		if privacy.Enabled() {
+			position = privacy.Coarsen(position)
+		}
+		if !bounds.Contains(position) {
+			continue
+		}
+		positions = append(positions, map[string]interface{}{
+			"sessionId": sessionID,
+			"userId":    userIDs[sessionID],
+			"position":  position,
+		})
+	}
+
+	pois := []*models.POI{}
+	if h.poiService != nil {
+		mapPOIs, err := h.poiService.GetPOIsForMap(ctx, client.MapID)
+		if err != nil {
+			h.requestLogger(ctx).Warn("Failed to get POIs for viewport", "mapId", client.MapID, "error", err.Error())
+		}
+		for _, poi := range mapPOIs {
+			if bounds.Contains(poi.Position) {
+				pois = append(pois, poi)
+			}
+		}
+	}
+
+	return map[string]interface{}{
+		"bounds":    bounds,
+		"positions": positions,
+		"pois":      pois,
+	}
+}
@@ -0,0 +1,71 @@
+package websocket
+
+import (
+	"testing"
+
+	"breakoutglobe/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandBounds(t *testing.T) {
+	expanded := expandBounds(models.Bounds{North: 60, South: 40, East: 20, West: -10}, 0.25)
+	assert.Equal(t, models.Bounds{North: 65, South: 35, East: 27.5, West: -17.5}, expanded)
+
+	// Across the date line the margin wraps around
+	expanded = expandBounds(models.Bounds{North: 10, South: -10, East: -170, West: 170}, 0.25)
+	assert.Equal(t, models.Bounds{North: 15, South: -15, East: -165, West: 165}, expanded)
+
+	// Viewports covering most of the globe get all longitudes
+	expanded = expandBounds(models.Bounds{North: 80, South: -80, East: 170, West: -170}, 0.25)
+	assert.Equal(t, models.Bounds{North: 90, South: -90, East: 180, West: -180}, expanded)
+}
+
+func TestManager_BroadcastToMap_FiltersByViewport(t *testing.T) {
+	manager := NewManager()
+	defer manager.Shutdown()
+	berlin := &Client{SessionID: "session-1", MapID: "map-1", Send: make(chan Message, 4), movement: make(chan Message, 4)}
+	tokyo := &Client{SessionID: "session-2", MapID: "map-1", Send: make(chan Message, 4), movement: make(chan Message, 4)}
+	everywhere := &Client{SessionID: "session-3", MapID: "map-1", Send: make(chan Message, 4), movement: make(chan Message, 4)}
+	manager.mapClients["map-1"] = map[string]*Client{"session-1": berlin, "session-2": tokyo, "session-3": everywhere}
+
+	manager.SetViewport(berlin, models.Bounds{North: 55, South: 50, East: 16, West: 10})
+	manager.SetViewport(tokyo, models.Bounds{North: 38, South: 34, East: 142, West: 138})
+
+	position := models.LatLng{Lat: 52.52, Lng: 13.405}
+	moved := atPositions(Message{Type: "avatar_moved", Data: map[string]interface{}{"sessionId": "session-4", "position": position}}, models.PositionPrivacy{}, position)
+	manager.broadcastToMap(BroadcastMessage{MapID: "map-1", Message: moved})
+
+	assert.Len(t, berlin.movement, 1)
+	assert.Len(t, tokyo.movement, 0)
+	assert.Len(t, everywhere.movement, 1)
+
+	// Moving out of a viewport is still sent there, so the avatar leaves it
+	paris := models.LatLng{Lat: 48.8566, Lng: 2.3522}
+	left := atPositions(Message{Type: "avatar_moved", Data: map[string]interface{}{"sessionId": "session-4", "position": paris}}, models.PositionPrivacy{}, position, paris)
+	manager.broadcastToMap(BroadcastMessage{MapID: "map-1", Message: left})
+
+	assert.Len(t, berlin.movement, 2)
+	assert.Len(t, tokyo.movement, 0)
+
+	// Broadcasts without a position go to everyone
+	manager.broadcastToMap(BroadcastMessage{MapID: "map-1", Message: Message{Type: "poi_updated"}})
+	assert.Len(t, tokyo.Send, 1)
+}
+
+func TestClient_InViewport_UsesCoarsePositionsForParticipants(t *testing.T) {
+	privacy := models.PositionPrivacy{GridMeters: 50000}
+	position := models.LatLng{Lat: 52.52003, Lng: 13.40495}
+	coarse := privacy.Coarsen(position)
+	message := atPositions(Message{Type: "avatar_moved"}, privacy, position)
+
+	// A viewport around the exact position but not the coarse one
+	around := models.Bounds{North: position.Lat + 0.001, South: position.Lat - 0.001, East: position.Lng + 0.001, West: position.Lng - 0.001}
+	assert.False(t, around.Contains(coarse))
+
+	participant := &Client{viewport: &around, role: models.UserRoleUser}
+	facilitator := &Client{viewport: &around, role: models.UserRoleAdmin}
+
+	assert.False(t, participant.inViewport(message))
+	assert.True(t, facilitator.inViewport(message))
+}
@@ -22,9 +22,9 @@ import { avatarStore } from './stores/avatarStore'
 import { videoCallStore, setWebSocketClient } from './stores/videoCallStore'
 import { toastStore } from './stores/toastStore'
 import { authStore } from './stores/authStore'
-import { WebSocketClient, ConnectionStatus as WSConnectionStatus } from './services/websocket-client'
+import { WebSocketClient, ConnectionStatus as WSConnectionStatus, CLIENT_VERSION, PROTOCOL_VERSION } from './services/websocket-client'
 import { SessionService } from './services/session-service'
-import { getCurrentUserProfile, createPOI, transformToCreatePOIRequest, transformFromPOIResponse, joinPOI, leavePOI, deletePOI, getPOIs, clearAllPOIs, clearAllUsers } from './services/api'
+import { getCurrentUserProfile, acceptInvitation, createPOI, transformToCreatePOIRequest, transformFromPOIResponse, joinPOI, leavePOI, deletePOI, getPOIs, clearAllPOIs, clearAllUsers, getRuntimeConfig } from './services/api'
 import { userProfileStore } from './stores/userProfileStore'
 import type { Map } from 'maplibre-gl'
 
@@ -164,6 +164,31 @@ function App() {
           setProfileCheckComplete(true);
           // Continue with session initialization
         } else {
+          // An invitation link signs the invitee in as their pre-created guest profile
+          const inviteToken = new URLSearchParams(window.location.search).get('invite')
+          if (inviteToken) {
+            const url = new URL(window.location.href)
+            url.searchParams.delete('invite')
+            window.history.replaceState(null, '', url.toString())
+
+            try {
+              const invitation = await acceptInvitation(inviteToken)
+              const cachedProfile = userProfileStore.getState().getProfileOffline()
+              if (cachedProfile && cachedProfile.id !== invitation.profile.id) {
+                localStorage.removeItem('sessionId')
+                sessionStore.getState().reset()
+              }
+              userProfileStore.getState().setProfile(invitation.profile)
+              console.info('✅ Invitation accepted for:', invitation.profile.displayName)
+            } catch (inviteError) {
+              console.warn('⚠️ Failed to accept invitation:', inviteError)
+              toastStore.getState().addToast({
+                type: 'error',
+                message: 'This invitation link is invalid or has expired',
+              })
+            }
+          }
+
           // Check if user has a profile first - try localStorage first, then backend
           let profile = userProfileStore.getState().getProfileOffline()
 
@@ -228,44 +253,19 @@ function App() {
         }
         } // End of authUser check
 
-        // Create or restore session
-        let sessionId = sessionState.sessionId
+        // Create or restore session. Sessions are only restored with the token
+        // they were created with, session IDs alone don't prove ownership.
+        let sessionId = sessionState.sessionToken ? sessionState.sessionId : null
+        let sessionToken = sessionState.sessionToken
 
         // Get the current profile from the store (more reliable than state)
         const currentProfile = userProfileStore.getState().getProfileOffline()
         console.log('🔍 Session creation - currentProfile:', currentProfile?.id, currentProfile?.displayName)
 
         const API_BASE_URL = import.meta.env.VITE_API_BASE_URL || 'http://localhost:8080';
-        const WS_BASE_URL = import.meta.env.VITE_WS_URL || 'ws://localhost:8080';
-
-        // First, check if we have an existing session for this user
-        if (currentProfile?.id) {
-          try {
-            console.log('🔍 Checking for existing sessions for user:', currentProfile.id)
-            const mapResponse = await fetch(`${API_BASE_URL}/api/maps/default-map/sessions`)
-            if (mapResponse.ok) {
-              const mapData = await mapResponse.json()
-              console.log('📋 Map sessions:', mapData.sessions?.length || 0)
-              const existingSession = mapData.sessions?.find((s: any) => s.userId === currentProfile.id)
-              if (existingSession) {
-                console.log('✅ Found existing session for user:', existingSession.sessionId)
-                sessionId = existingSession.sessionId
-                // Update session store with existing session
-                sessionStore.getState().createSession(sessionId, existingSession.avatarPosition || mockSession.position)
-              } else {
-                console.log('❌ No existing session found for user:', currentProfile.id)
-              }
-            } else {
-              console.warn('⚠️ Failed to fetch map sessions:', mapResponse.status)
-            }
-          } catch (error) {
-            console.warn('⚠️ Failed to check existing sessions:', error)
-          }
-        } else {
-          console.warn('⚠️ No user profile available for session creation')
-        }
+        const { webSocketUrl } = await getRuntimeConfig();
 
-        if (!sessionId) {
+        if (!sessionId || !sessionToken) {
           // Ensure we have a user profile before creating session
           if (!currentProfile?.id) {
             throw new Error('Cannot create session: User profile not loaded')
@@ -273,7 +273,7 @@ function App() {
 
           console.log('🔄 Creating new session for user:', currentProfile.id)
 
-          // Create new session via API
+          // Create new session via API, which resumes an active session of the user
           const response = await fetch(`${API_BASE_URL}/api/sessions`, {
             method: 'POST',
             headers: {
@@ -286,47 +286,26 @@ function App() {
             }),
           })
 
-          let position = mockSession.position
-
           if (!response.ok) {
-            if (response.status === 409) {
-              // User already has an active session, get existing sessions for this map
-              console.log('🔄 User already has active session, fetching existing sessions')
-              const mapResponse = await fetch(`${API_BASE_URL}/api/maps/default-map/sessions`)
-              if (mapResponse.ok) {
-                const mapData = await mapResponse.json()
-                const userSession = mapData.sessions?.find((s: any) => s.userId === currentProfile.id)
-                if (userSession) {
-                  sessionId = userSession.sessionId
-                  position = userSession.avatarPosition || mockSession.position
-                  console.log('✅ Using existing session:', sessionId)
-                } else {
-                  throw new Error('User has active session but could not find it')
-                }
-              } else {
-                throw new Error('Failed to get existing sessions')
-              }
-            } else {
-              throw new Error('Failed to create session')
-            }
-          } else {
-            // New session created successfully
-            const sessionData = await response.json()
-            sessionId = sessionData.sessionId || sessionData.id
-            position = sessionData.position || mockSession.position
+            throw new Error('Failed to create session')
           }
 
+          const sessionData = await response.json()
+          sessionId = sessionData.sessionId || sessionData.id
+          sessionToken = sessionData.sessionToken
+          const position = sessionData.position || mockSession.position
+
           // Update session store
-          sessionStore.getState().createSession(sessionId!, position)
+          sessionStore.getState().createSession(sessionId!, sessionToken!, position)
         }
 
         // Initialize session service for heartbeats (for both new and existing sessions)
-        const sessionSvc = new SessionService(sessionId!);
+        const sessionSvc = new SessionService(sessionId!, sessionToken!);
         setSessionService(sessionSvc);
         sessionSvc.startHeartbeat();
 
         // Initialize WebSocket connection
-        const wsUrl = `${WS_BASE_URL}/ws?sessionId=${sessionId}`;
+        const wsUrl = `${webSocketUrl}?sessionId=${sessionId}&sessionToken=${encodeURIComponent(sessionToken!)}&clientVersion=${encodeURIComponent(CLIENT_VERSION)}&protocolVersion=${PROTOCOL_VERSION}`;
 
         // Initialize WebSocket connection
         const client = new WebSocketClient(wsUrl, sessionId!);
@@ -627,6 +606,29 @@ function App() {
     }
   }, [poiState.pois])
 
+  // Only receive avatar moves and new POIs for the displayed area of the map
+  useEffect(() => {
+    if (!mapInstance || !wsClient) return
+
+    const sendViewport = () => {
+      const bounds = mapInstance.getBounds()
+      const wrap = (lng: number) => lng > 180 ? lng - 360 : lng < -180 ? lng + 360 : lng
+      const wholeGlobe = bounds.getEast() - bounds.getWest() >= 360
+      wsClient.updateViewport({
+        north: Math.min(90, bounds.getNorth()),
+        south: Math.max(-90, bounds.getSouth()),
+        east: wholeGlobe ? 180 : wrap(bounds.getEast()),
+        west: wholeGlobe ? -180 : wrap(bounds.getWest()),
+      })
+    }
+
+    sendViewport()
+    mapInstance.on('moveend', sendViewport)
+    return () => {
+      mapInstance.off('moveend', sendViewport)
+    }
+  }, [mapInstance, wsClient])
+
   // Handle POI sidebar click - pan to POI and select it
   const handlePOISidebarClick = useCallback((poi: POIData) => {
     if (mapInstance) {
@@ -648,6 +650,9 @@ function App() {
       const updatedPOI = poiState.pois.find(p => p.id === selectedPOI.id)
       if (updatedPOI) {
         setSelectedPOI(updatedPOI)
+      } else {
+        // The POI was deleted, possibly by another user
+        setSelectedPOI(null)
       }
     }
   }, [poiState.pois, selectedPOI])
@@ -684,7 +689,7 @@ function App() {
       }
 
       // Call API to join POI
-      await joinPOI(poiId, userProfile.id)
+      await joinPOI(poiId, userProfile.id, sessionStore.getState().sessionId)
       console.log('✅ Successfully joined POI:', poiId)
 
       // Confirm the optimistic update
@@ -747,7 +752,7 @@ function App() {
       }
 
       // Call API to leave POI
-      await leavePOI(poiId, userProfile.id)
+      await leavePOI(poiId, userProfile.id, sessionStore.getState().sessionId)
       console.log('✅ Successfully left POI:', poiId)
 
       // Leave group call if user was in one for this POI
@@ -858,7 +863,7 @@ function App() {
     const initializeWithProfile = async () => {
       try {
         const API_BASE_URL = import.meta.env.VITE_API_BASE_URL || 'http://localhost:8080';
-        const WS_BASE_URL = import.meta.env.VITE_WS_URL || 'ws://localhost:8080';
+        const { webSocketUrl } = await getRuntimeConfig();
 
         // Create new session via API
         const response = await fetch(`${API_BASE_URL}/api/sessions`, {
@@ -879,12 +884,13 @@ function App() {
 
         const sessionData = await response.json()
         const sessionId = sessionData.sessionId || sessionData.id
+        const sessionToken = sessionData.sessionToken
 
         // Update session store
-        sessionStore.getState().createSession(sessionId, sessionData.position || mockSession.position)
+        sessionStore.getState().createSession(sessionId, sessionToken, sessionData.position || mockSession.position)
 
         // Initialize WebSocket connection
-        const wsUrl = `${WS_BASE_URL}/ws?sessionId=${sessionId}`;
+        const wsUrl = `${webSocketUrl}?sessionId=${sessionId}&sessionToken=${encodeURIComponent(sessionToken)}&clientVersion=${encodeURIComponent(CLIENT_VERSION)}&protocolVersion=${PROTOCOL_VERSION}`;
         const client = new WebSocketClient(wsUrl, sessionId);
 
         // Make WebSocket client globally accessible for WebRTC signaling
@@ -42,7 +42,7 @@ describe('POI Membership Integration Test', () => {
     
     // Setup initial state
     poiStore.getState().addPOI(mockPOI);
-    sessionStore.getState().createSession(mockSessionId, { lat: 40.7128, lng: -74.0060 });
+    sessionStore.getState().createSession(mockSessionId, 'session-token', { lat: 40.7128, lng: -74.0060 });
     
     // Setup mock WebSocket client behavior
     mockWsClient.leaveCurrentPOI.mockImplementation(() => {
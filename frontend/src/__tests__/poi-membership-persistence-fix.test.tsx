@@ -45,7 +45,7 @@ describe('POI Membership Persistence Fix', () => {
     
     // Setup initial state
     poiStore.getState().addPOI(mockPOI);
-    sessionStore.getState().createSession('session-123', { lat: 40.7128, lng: -74.0060 });
+    sessionStore.getState().createSession('session-123', 'session-token', { lat: 40.7128, lng: -74.0060 });
   });
 
   it('should persist currentUserPOI after browser refresh', () => {
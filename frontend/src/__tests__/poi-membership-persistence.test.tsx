@@ -46,7 +46,7 @@ describe('POI Membership Persistence Issue', () => {
     
     // Setup initial state
     poiStore.getState().addPOI(mockPOI);
-    sessionStore.getState().createSession('session-123', { lat: 40.7128, lng: -74.0060 });
+    sessionStore.getState().createSession('session-123', 'session-token', { lat: 40.7128, lng: -74.0060 });
   });
 
   it('should reproduce the POI membership persistence issue', async () => {
@@ -5,6 +5,7 @@ import { POIContextMenu } from './POIContextMenu';
 import { ProfileCard } from './ProfileCard';
 import { createPOIMarkerElement, updatePOIMarkerElement } from './POIMarker';
 import { createAvatarMarkerElement } from './AvatarMarker';
+import { getRuntimeConfig } from '../services/api';
 
 export interface AvatarData {
   sessionId: string;
@@ -20,6 +21,7 @@ export interface AvatarData {
   isMoving?: boolean;
   isInCall?: boolean;
   role?: 'user' | 'admin' | 'superadmin';
+  currentPoiId?: string | null;
 }
 
 export interface POIParticipant {
@@ -47,6 +49,11 @@ export interface POIData {
   discussionStartTime?: Date | null;
   isDiscussionActive?: boolean;
   discussionDuration?: number; // Duration in seconds (for testing or when provided directly)
+  // Participant currently speaking in the POI call, relayed by the server
+  activeSpeakerId?: string | null;
+  // Scheduled event fields - rsvps are only included for scheduled POIs
+  startsAt?: Date | null;
+  rsvps?: { going: number; maybe: number; no: number };
 }
 
 export interface MapContainerProps {
@@ -187,6 +194,23 @@ export const MapContainer: React.FC<MapContainerProps> = ({
       crossSourceCollisions: false // Disable collision detection between sources
     });
 
+    // Swap in the tiles configured for this deployment once the style is loaded
+    const mapInstance = map.current;
+    mapInstance.on('load', async () => {
+      const { tileStyle } = await getRuntimeConfig();
+      if (map.current !== mapInstance) return;
+
+      mapInstance.removeLayer('osm');
+      mapInstance.removeSource('osm');
+      mapInstance.addSource('osm', {
+        type: 'raster',
+        tiles: tileStyle.tiles,
+        tileSize: tileStyle.tileSize,
+        attribution: tileStyle.attribution
+      });
+      mapInstance.addLayer({ id: 'osm', type: 'raster', source: 'osm' });
+    });
+
     // Add controls
     map.current.addControl(new NavigationControl({}), 'top-right');
     map.current.addControl(new ScaleControl({}), 'bottom-left');
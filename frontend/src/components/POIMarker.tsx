@@ -473,4 +473,7 @@ export const updatePOIMarkerElement = (element: HTMLElement, poi: POIData): void
   }
 
   element.title = `${poi.name} - ${poi.participantCount}/${poi.maxParticipants} participants`;
+
+  // Pulse the marker while a discussion is audibly active
+  element.classList.toggle('animate-pulse', !!poi.activeSpeakerId);
 };
\ No newline at end of file
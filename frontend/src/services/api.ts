@@ -1,4 +1,5 @@
 import { UserProfile, UserProfileAPI, transformUserProfileFromAPI } from '../types/models';
+import { sessionStore } from '../stores/sessionStore';
 
 const API_BASE_URL = import.meta.env.VITE_API_BASE_URL || 'http://localhost:8080';
 
@@ -30,9 +31,27 @@ export interface POIResponse {
   discussionStartTime?: string;
   isDiscussionActive?: boolean;
   
+  // Scheduled event fields - rsvps are only included for scheduled POIs
+  startsAt?: string;
+  rsvps?: RSVPCounts;
+  
   createdAt: string;
 }
 
+export type RSVPStatus = 'going' | 'maybe' | 'no';
+
+export interface RSVPCounts {
+  going: number;
+  maybe: number;
+  no: number;
+}
+
+export interface RSVPsResponse {
+  poiId: string;
+  counts: RSVPCounts;
+  status?: RSVPStatus;
+}
+
 export interface POIListResponse {
   mapId: string;
   pois: POIResponse[];
@@ -43,6 +62,7 @@ export interface UpdatePOIRequest {
   name?: string;
   description?: string;
   maxParticipants?: number;
+  startsAt?: string; // Schedules an event at the POI
 }
 
 export interface JoinPOIRequest {
@@ -171,6 +191,29 @@ export async function getCurrentUserProfile(userId?: string): Promise<UserProfil
   }
 }
 
+export interface AcceptedInvitation {
+  mapId: string;
+  profile: UserProfile;
+}
+
+// Accept an emailed invitation and return the guest profile created for the invitee
+export async function acceptInvitation(token: string): Promise<AcceptedInvitation> {
+  const response = await fetch(`${API_BASE_URL}/api/invitations/accept`, {
+    method: 'POST',
+    headers: {
+      'Content-Type': 'application/json',
+    },
+    credentials: 'include',
+    body: JSON.stringify({ token }),
+  });
+
+  const result = await handleResponse<{ mapId: string; profile: UserProfileAPI }>(response);
+  return {
+    mapId: result.mapId,
+    profile: transformUserProfileFromAPI(result.profile),
+  };
+}
+
 export async function updateUserProfile(updates: Partial<Pick<UserProfile, 'displayName' | 'aboutMe'>>, userID?: string): Promise<UserProfile> {
   const headers: Record<string, string> = {
     'Content-Type': 'application/json',
@@ -225,6 +268,221 @@ export async function uploadAvatar(avatarFile: File, userId?: string): Promise<U
   return transformed;
 }
 
+export async function deleteAvatar(userId?: string): Promise<UserProfile> {
+  const headers: Record<string, string> = {};
+
+  // Check for JWT token first (for full account users)
+  const authToken = localStorage.getItem('authToken');
+  if (authToken) {
+    headers['Authorization'] = `Bearer ${authToken}`;
+  } else if (userId) {
+    // Fallback to X-User-ID header for guest users
+    headers['X-User-ID'] = userId;
+  }
+
+  const response = await fetch(`${API_BASE_URL}/api/users/me/avatar`, {
+    method: 'DELETE',
+    headers,
+    credentials: 'include',
+  });
+
+  const apiProfile = await handleResponse<UserProfileAPI>(response);
+  return transformUserProfileFromAPI(apiProfile);
+}
+
+// Runtime Config API Functions
+
+export interface RuntimeConfig {
+  webSocketUrl: string;
+  assetBaseUrl: string;
+  features: string[];
+  tileStyle: {
+    tiles: string[];
+    tileSize: number;
+    attribution: string;
+  };
+  maxUploadSizes: {
+    avatar: number;
+    poiImage: number;
+    mapArchive: number;
+  };
+  protocolVersion: number;
+  minClientVersion?: string;
+}
+
+// Build-time settings, used if the server can't be reached
+const fallbackRuntimeConfig: RuntimeConfig = {
+  webSocketUrl: `${import.meta.env.VITE_WS_URL || 'ws://localhost:8080'}/ws`,
+  assetBaseUrl: `${API_BASE_URL}/uploads`,
+  features: [],
+  tileStyle: {
+    tiles: ['https://tile.openstreetmap.org/{z}/{x}/{y}.png'],
+    tileSize: 256,
+    attribution: '© OpenStreetMap contributors',
+  },
+  maxUploadSizes: {
+    avatar: 2 * 1024 * 1024,
+    poiImage: 5 * 1024 * 1024,
+    mapArchive: 10 * 1024 * 1024,
+  },
+  protocolVersion: 1,
+};
+
+let runtimeConfig: Promise<RuntimeConfig> | null = null;
+
+// getRuntimeConfig loads the deployment's public configuration once per page load
+export function getRuntimeConfig(): Promise<RuntimeConfig> {
+  if (!runtimeConfig) {
+    runtimeConfig = fetch(`${API_BASE_URL}/api/config`)
+      .then(async (response) => {
+        if (!response.ok) {
+          throw new Error(`Failed to load runtime config: ${response.status}`);
+        }
+        return (await response.json()) as RuntimeConfig;
+      })
+      .catch((error) => {
+        console.warn('⚠️ Using build-time config:', error);
+        return fallbackRuntimeConfig;
+      });
+  }
+  return runtimeConfig;
+}
+
+// Onboarding
+
+export type OnboardingStep = 'profile_set' | 'first_move' | 'first_poi_join';
+
+// Welcome checklist progress, kept by the server so every device shows the same
+export interface OnboardingProgress {
+  steps: Array<{
+    step: OnboardingStep;
+    completed: boolean;
+    completedAt?: string;
+  }>;
+  complete: boolean;
+}
+
+// Preferences API Functions
+
+export type PreferenceNamespace = 'notifications' | 'a11y' | 'video' | 'privacy';
+
+export interface UserPreferences {
+  notifications: {
+    sound: boolean;
+    toasts: boolean;
+    poiReminders: boolean;
+    digestEmails: boolean;
+  };
+  a11y: {
+    reducedMotion: boolean;
+    highContrast: boolean;
+    fontScale: number;
+  };
+  video: {
+    cameraOn: boolean;
+    micOn: boolean;
+    resolution: 'auto' | 'low' | 'medium' | 'high';
+  };
+  privacy: {
+    doNotTrack: boolean;
+  };
+}
+
+export type PreferencesUpdate = {
+  [N in PreferenceNamespace]?: Partial<UserPreferences[N]>;
+};
+
+function preferenceHeaders(userId?: string): Record<string, string> {
+  const headers: Record<string, string> = {
+    'Content-Type': 'application/json',
+  };
+
+  // Check for JWT token first (for full account users)
+  const authToken = localStorage.getItem('authToken');
+  if (authToken) {
+    headers['Authorization'] = `Bearer ${authToken}`;
+  } else if (userId) {
+    // Fallback to X-User-ID header for guest users
+    headers['X-User-ID'] = userId;
+  }
+  return headers;
+}
+
+export async function getPreferences(userId?: string): Promise<UserPreferences> {
+  const response = await fetch(`${API_BASE_URL}/api/users/me/preferences`, {
+    headers: preferenceHeaders(userId),
+    credentials: 'include',
+  });
+
+  const result = await handleResponse<{ preferences: UserPreferences }>(response);
+  return result.preferences;
+}
+
+// Only the given namespaces and keys change; returns all preferences
+export async function updatePreferences(update: PreferencesUpdate, userId?: string): Promise<UserPreferences> {
+  const response = await fetch(`${API_BASE_URL}/api/users/me/preferences`, {
+    method: 'PUT',
+    headers: preferenceHeaders(userId),
+    credentials: 'include',
+    body: JSON.stringify(update),
+  });
+
+  const result = await handleResponse<{ preferences: UserPreferences }>(response);
+  return result.preferences;
+}
+
+// Auth session API Functions (full accounts only)
+
+export interface AuthSession {
+  id: string;
+  userAgent: string;
+  ipAddress: string;
+  location?: { lat: number; lng: number };
+  createdAt: string;
+  lastSeenAt: string;
+  expiresAt: string;
+  current: boolean;
+}
+
+function authSessionHeaders(): Record<string, string> {
+  return {
+    'Content-Type': 'application/json',
+    'Authorization': `Bearer ${localStorage.getItem('authToken') ?? ''}`,
+  };
+}
+
+export async function getAuthSessions(): Promise<AuthSession[]> {
+  const response = await fetch(`${API_BASE_URL}/api/users/me/sessions`, {
+    headers: authSessionHeaders(),
+    credentials: 'include',
+  });
+
+  const result = await handleResponse<{ sessions: AuthSession[] }>(response);
+  return result.sessions;
+}
+
+export async function revokeAuthSession(sessionId: string): Promise<void> {
+  const response = await fetch(`${API_BASE_URL}/api/users/me/sessions/${sessionId}`, {
+    method: 'DELETE',
+    headers: authSessionHeaders(),
+    credentials: 'include',
+  });
+
+  await handleResponse<{ revoked: number }>(response);
+}
+
+// Signs out every device except this one; returns how many sessions were revoked
+export async function revokeOtherAuthSessions(): Promise<number> {
+  const response = await fetch(`${API_BASE_URL}/api/users/me/sessions`, {
+    method: 'DELETE',
+    headers: authSessionHeaders(),
+    credentials: 'include',
+  });
+
+  const result = await handleResponse<{ revoked: number }>(response);
+  return result.revoked;
+}
+
 // POI API Functions
 
 export async function createPOI(request: CreatePOIRequest): Promise<POIResponse> {
@@ -331,16 +589,31 @@ export async function deletePOI(poiId: string): Promise<void> {
   console.log('✅ API: POI deleted successfully');
 }
 
-export async function joinPOI(poiId: string, userId: string): Promise<void> {
+// Headers identifying the acting user for session-scoped endpoints: the JWT
+// for full accounts, otherwise the guest's session and its token
+function identityHeaders(sessionId?: string | null): Record<string, string> {
+  const headers: Record<string, string> = {
+    'Content-Type': 'application/json',
+  };
+
+  const authToken = localStorage.getItem('authToken');
+  if (authToken) {
+    headers['Authorization'] = `Bearer ${authToken}`;
+  } else if (sessionId) {
+    headers['X-Session-ID'] = sessionId;
+    headers['X-Session-Token'] = sessionStore.getState().sessionToken || '';
+  }
+  return headers;
+}
+
+export async function joinPOI(poiId: string, userId: string, sessionId?: string | null): Promise<void> {
   console.log('🌐 API: joinPOI called for poiId:', poiId, 'userId:', userId);
 
   const request: JoinPOIRequest = { userId };
 
   const response = await fetch(`${API_BASE_URL}/api/pois/${encodeURIComponent(poiId)}/join`, {
     method: 'POST',
-    headers: {
-      'Content-Type': 'application/json',
-    },
+    headers: identityHeaders(sessionId),
     body: JSON.stringify(request),
   });
 
@@ -348,16 +621,55 @@ export async function joinPOI(poiId: string, userId: string): Promise<void> {
   console.log('✅ API: Joined POI successfully');
 }
 
-export async function leavePOI(poiId: string, userId: string): Promise<void> {
+// RSVP to a scheduled POI; "going" reserves a seat
+export async function setRSVP(poiId: string, userId: string, status: RSVPStatus): Promise<void> {
+  const response = await fetch(`${API_BASE_URL}/api/pois/${encodeURIComponent(poiId)}/rsvp`, {
+    method: 'PUT',
+    headers: {
+      'Content-Type': 'application/json',
+      'X-User-ID': userId,
+    },
+    body: JSON.stringify({ status }),
+  });
+
+  await handleResponse<{ status: RSVPStatus }>(response);
+}
+
+export async function cancelRSVP(poiId: string, userId: string): Promise<void> {
+  const response = await fetch(`${API_BASE_URL}/api/pois/${encodeURIComponent(poiId)}/rsvp`, {
+    method: 'DELETE',
+    headers: {
+      'X-User-ID': userId,
+    },
+  });
+
+  if (!response.ok) {
+    await handleResponse<void>(response);
+  }
+}
+
+export async function getRSVPs(poiId: string, userId?: string): Promise<RSVPsResponse> {
+  const headers: Record<string, string> = {};
+  if (userId) {
+    headers['X-User-ID'] = userId;
+  }
+
+  const response = await fetch(`${API_BASE_URL}/api/pois/${encodeURIComponent(poiId)}/rsvps`, {
+    method: 'GET',
+    headers,
+  });
+
+  return handleResponse<RSVPsResponse>(response);
+}
+
+export async function leavePOI(poiId: string, userId: string, sessionId?: string | null): Promise<void> {
   console.log('🌐 API: leavePOI called for poiId:', poiId, 'userId:', userId);
 
   const request = { userId };
 
   const response = await fetch(`${API_BASE_URL}/api/pois/${encodeURIComponent(poiId)}/leave`, {
     method: 'POST',
-    headers: {
-      'Content-Type': 'application/json',
-    },
+    headers: identityHeaders(sessionId),
     body: JSON.stringify(request),
   });
 
@@ -365,6 +677,13 @@ export async function leavePOI(poiId: string, userId: string): Promise<void> {
   console.log('✅ API: Left POI successfully');
 }
 
+// Resolves the POI a user is currently in, or null
+export async function getUserCurrentPOI(userId: string): Promise<string | null> {
+  const response = await fetch(`${API_BASE_URL}/api/users/${encodeURIComponent(userId)}/current-poi`);
+  const result = await handleResponse<{ userId: string; currentPoiId: string | null }>(response);
+  return result.currentPoiId;
+}
+
 export async function getPOIParticipants(poiId: string): Promise<string[]> {
   console.log('🌐 API: getPOIParticipants called for poiId:', poiId);
 
@@ -420,6 +739,9 @@ export function transformFromPOIResponse(apiResponse: POIResponse): {
   // Discussion timer fields
   discussionStartTime?: Date | null;
   isDiscussionActive?: boolean;
+  // Scheduled event fields
+  startsAt?: Date | null;
+  rsvps?: RSVPCounts;
 } {
   return {
     id: apiResponse.id,
@@ -435,7 +757,9 @@ export function transformFromPOIResponse(apiResponse: POIResponse): {
     createdAt: new Date(apiResponse.createdAt),
     // Transform discussion timer fields - backend only tracks when 2+ users are present
     discussionStartTime: apiResponse.discussionStartTime ? new Date(apiResponse.discussionStartTime) : null,
-    isDiscussionActive: apiResponse.isDiscussionActive || false
+    isDiscussionActive: apiResponse.isDiscussionActive || false,
+    startsAt: apiResponse.startsAt ? new Date(apiResponse.startsAt) : null,
+    rsvps: apiResponse.rsvps
   };
 }
 
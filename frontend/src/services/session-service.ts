@@ -1,8 +1,8 @@
 export class SessionService {
   private heartbeatInterval: number | null = null;
-  private readonly HEARTBEAT_INTERVAL = 5 * 60 * 1000; // 5 minutes
+  private readonly HEARTBEAT_INTERVAL = 30 * 1000; // 30 seconds, keeps the 90s server presence key alive
 
-  constructor(private sessionId: string) {}
+  constructor(private sessionId: string, private sessionToken: string) {}
 
   startHeartbeat(): void {
     if (this.heartbeatInterval) {
@@ -42,6 +42,7 @@ export class SessionService {
         method: 'POST',
         headers: {
           'Content-Type': 'application/json',
+          'X-Session-Token': this.sessionToken,
         },
       });
 
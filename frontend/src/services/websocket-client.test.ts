@@ -201,7 +201,7 @@ describe('WebSocketClient', () => {
 
             client.send(message);
 
-            expect(mockWebSocket.send).toHaveBeenCalledWith(JSON.stringify(message));
+            expect(mockWebSocket.send).toHaveBeenCalledWith(JSON.stringify({ ...message, version: 1 }));
         });
 
         it('should handle incoming messages', () => {
@@ -248,8 +248,8 @@ describe('WebSocketClient', () => {
 
             const newMock = await connectAndGetMock();
 
-            expect(newMock.send).toHaveBeenCalledWith(JSON.stringify(message1));
-            expect(newMock.send).toHaveBeenCalledWith(JSON.stringify(message2));
+            expect(newMock.send).toHaveBeenCalledWith(JSON.stringify({ ...message1, version: 1 }));
+            expect(newMock.send).toHaveBeenCalledWith(JSON.stringify({ ...message2, version: 1 }));
             expect(client.getQueuedMessages()).toHaveLength(0);
         });
     });
@@ -269,7 +269,7 @@ describe('WebSocketClient', () => {
             };
 
             // Set up session store with test session
-            sessionStore.getState().createSession('test-session-id', { lat: 0, lng: 0 });
+            sessionStore.getState().createSession('test-session-id', 'session-token', { lat: 0, lng: 0 });
 
             mockWebSocket.simulateMessage(message);
 
@@ -330,7 +330,7 @@ describe('WebSocketClient', () => {
     describe('Optimistic Updates', () => {
         beforeEach(async () => {
             mockWebSocket = await connectAndGetMock();
-            sessionStore.getState().createSession('test-session-id', { lat: 0, lng: 0 });
+            sessionStore.getState().createSession('test-session-id', 'session-token', { lat: 0, lng: 0 });
         });
 
         it('should handle avatar move confirmation', () => {
@@ -3,9 +3,24 @@ import { poiStore } from '../stores/poiStore';
 import { avatarStore } from '../stores/avatarStore';
 import { videoCallStore } from '../stores/videoCallStore';
 import { userProfileStore } from '../stores/userProfileStore';
+import { toastStore } from '../stores/toastStore';
+import { authStore } from '../stores/authStore';
 import type { POIData, AvatarData } from '../components/MapContainer';
+import { transformFromPOIResponse } from './api';
 import { eventBus, GroupCallEvents, type UserJoinedPOIEvent } from '../utils/eventBus';
 
+// App version reported when connecting; the server may require a minimum version
+export const CLIENT_VERSION: string = import.meta.env.VITE_APP_VERSION || '0.0.0';
+
+// Close code the server uses after an upgrade_required message
+const UPGRADE_REQUIRED_CLOSE_CODE = 4426;
+
+// Newest WebSocket message protocol version this client speaks, requested when connecting
+export const PROTOCOL_VERSION = 1;
+
+// Close code the server uses after a protocol_mismatch message
+const PROTOCOL_MISMATCH_CLOSE_CODE = 4505;
+
 export enum ConnectionStatus {
   DISCONNECTED = 'disconnected',
   CONNECTING = 'connecting',
@@ -17,14 +32,48 @@ export interface WebSocketMessage {
   type: string;
   data: any;
   timestamp: Date;
+  // Protocol version of the message; unversioned messages are treated as version 1
+  version?: number;
+  // Map broadcasts are numbered per lane so missed ones can be detected
+  seq?: number;
+  lane?: string;
+  // Journaled map broadcasts carry a map-wide number to replay missed ones from
+  mapSeq?: number;
 }
 
+// How long broadcasts are held back while waiting for an event_replay
+const REPLAY_TIMEOUT_MS = 10000;
+
+// How often a client_lagging warning is shown at most
+const LAGGING_TOAST_INTERVAL_MS = 60000;
+
 export interface WebSocketError {
   message: string;
   code?: number;
+  errorId?: string;
   timestamp: Date;
 }
 
+// Structured reason the server sends when it closes a connection; errorId
+// references the server's record of the close for support requests
+export interface CloseReason {
+  reason: string;
+  retry: boolean;
+  errorId?: string;
+}
+
+export function parseCloseReason(text: string): CloseReason | null {
+  if (!text) {
+    return null;
+  }
+  try {
+    const parsed = JSON.parse(text);
+    return typeof parsed?.reason === 'string' ? parsed : null;
+  } catch {
+    return null;
+  }
+}
+
 export interface StateSync {
   type: 'avatar' | 'poi' | 'session';
   data: any;
@@ -40,7 +89,22 @@ export class WebSocketClient {
   private maxReconnectAttempts = 5;
   private reconnectDelay = 1000; // Start with 1 second
   private reconnectTimer: number | null = null;
+  // Delay the server asked for before it shut down, used for the next reconnect
+  private shutdownReconnectAfterMs: number | null = null;
+  private lastCloseReason: CloseReason | null = null;
+  private lastSequences: Record<string, number> = {};
+  private lastMapSeq = 0;
+  private journalEpoch: string | null = null;
+  // Protocol version negotiated in the welcome message
+  private protocolVersion = PROTOCOL_VERSION;
+  private awaitingReplaySince: number | null = null;
+  private replayBuffer: WebSocketMessage[] = [];
   private messageQueue: WebSocketMessage[] = [];
+  // Rate limit buckets the server rejected messages of, and the message types
+  // in each, so only the affected feature backs off
+  private rateLimitedUntil: Record<string, number> = {};
+  private messageBuckets: Record<string, string> = {};
+  private laggingNotifiedAt = 0;
   private statusChangeCallbacks: ((status: ConnectionStatus) => void)[] = [];
   private messageCallbacks: ((message: WebSocketMessage) => void)[] = [];
   private errorCallbacks: ((error: WebSocketError) => void)[] = [];
@@ -61,13 +125,25 @@ export class WebSocketClient {
     this.connectionStatus = ConnectionStatus.CONNECTING;
     this.notifyStatusChange();
 
+    // After a reconnect, ask for the broadcasts missed since the last one seen
+    const resuming = this.journalEpoch !== null && this.lastMapSeq > 0;
+    const url = resuming ? this.resumeUrl() : this.url;
+
     return new Promise((resolve, reject) => {
       try {
-        this.ws = new WebSocket(this.url);
+        this.ws = new WebSocket(url);
 
         this.ws.onopen = () => {
-          console.log('🔌 WebSocket: Connected successfully to', this.url);
+          console.log('🔌 WebSocket: Connected successfully to', this.url, { resuming });
           this.connectionStatus = ConnectionStatus.CONNECTED;
+          // Lane sequence numbers restart with every connection
+          this.lastSequences = {};
+          // A resumed connection holds back broadcasts until the missed ones are replayed
+          if (!resuming) {
+            this.lastMapSeq = 0;
+          }
+          this.awaitingReplaySince = resuming ? Date.now() : null;
+          this.replayBuffer = [];
           this.reconnectAttempts = 0;
           this.reconnectDelay = 1000; // Reset delay
           this.notifyStatusChange();
@@ -78,10 +154,33 @@ export class WebSocketClient {
         this.ws.onclose = (event) => {
           this.connectionStatus = ConnectionStatus.DISCONNECTED;
           this.ws = null;
+          this.lastCloseReason = parseCloseReason(event.reason);
           this.notifyStatusChange();
 
-          // Auto-reconnect on unexpected closure
-          if (event.code !== 1000 && event.code !== 1001) {
+          // A server shutting down said when to reconnect, so there's nothing to report
+          if (this.lastCloseReason?.reason === 'server_shutdown' || this.shutdownReconnectAfterMs !== null) {
+            const delay = this.shutdownReconnectAfterMs ?? 0;
+            this.shutdownReconnectAfterMs = null;
+            console.log('🔄 WebSocket: Server shut down, reconnecting in', delay, 'ms');
+            this.scheduleReconnect(delay);
+            return;
+          }
+
+          if (this.lastCloseReason) {
+            console.warn('WebSocket closed by server:', event.code, this.lastCloseReason);
+            this.notifyError({
+              message: `Connection closed: ${this.lastCloseReason.reason}`,
+              code: event.code,
+              errorId: this.lastCloseReason.errorId,
+              timestamp: new Date()
+            });
+          }
+
+          // Auto-reconnect on unexpected closure; outdated clients must reload instead
+          if (this.lastCloseReason && !this.lastCloseReason.retry) {
+            return;
+          }
+          if (event.code !== 1000 && event.code !== 1001 && event.code !== UPGRADE_REQUIRED_CLOSE_CODE && event.code !== PROTOCOL_MISMATCH_CLOSE_CODE) {
             this.scheduleReconnect();
           }
         };
@@ -143,6 +242,11 @@ export class WebSocketClient {
     return this.connectionStatus;
   }
 
+  // Why the server last closed the connection, if it said so
+  getLastCloseReason(): CloseReason | null {
+    return this.lastCloseReason;
+  }
+
   getQueuedMessageCount(): number {
     return this.messageQueue.length;
   }
@@ -206,7 +310,7 @@ export class WebSocketClient {
   }
 
   // Reconnection Logic
-  private scheduleReconnect(): void {
+  private scheduleReconnect(delayOverride?: number): void {
     if (this.reconnectAttempts >= this.maxReconnectAttempts) {
       return;
     }
@@ -215,7 +319,7 @@ export class WebSocketClient {
     this.reconnectAttempts++;
     this.notifyStatusChange();
 
-    const delay = this.reconnectDelay * Math.pow(2, this.reconnectAttempts - 1); // Exponential backoff
+    const delay = delayOverride ?? this.reconnectDelay * Math.pow(2, this.reconnectAttempts - 1); // Exponential backoff
 
     this.reconnectTimer = setTimeout(() => {
       this.connect().catch(() => {
@@ -234,6 +338,12 @@ export class WebSocketClient {
 
   // Message Handling
   send(message: WebSocketMessage): void {
+    const bucket = this.messageBuckets[message.type];
+    if (bucket && Date.now() < (this.rateLimitedUntil[bucket] ?? 0)) {
+      console.warn('⏳ WebSocket: Dropping rate limited message', { type: message.type, bucket });
+      return;
+    }
+
     console.log('📤 WebSocket: Sending message', {
       type: message.type,
       data: message.data,
@@ -243,7 +353,7 @@ export class WebSocketClient {
     });
 
     if (this.isConnected() && this.ws) {
-      this.ws.send(JSON.stringify(message));
+      this.ws.send(JSON.stringify({ ...message, version: this.protocolVersion }));
     } else {
       console.log('📋 WebSocket: Queueing message (not connected)', message.type);
       // Queue message for later
@@ -254,7 +364,7 @@ export class WebSocketClient {
   private sendQueuedMessages(): void {
     while (this.messageQueue.length > 0 && this.isConnected() && this.ws) {
       const message = this.messageQueue.shift()!;
-      this.ws.send(JSON.stringify(message));
+      this.ws.send(JSON.stringify({ ...message, version: this.protocolVersion }));
     }
   }
 
@@ -276,8 +386,11 @@ export class WebSocketClient {
         timestamp: message.timestamp
       });
 
-      this.notifyMessage(message);
-      this.processMessage(message);
+      this.checkSequence(message);
+      if (this.deferUntilReplayed(message)) {
+        return;
+      }
+      this.dispatchMessage(message);
     } catch (error) {
       console.error('❌ WebSocket: Failed to parse message', error, event.data);
       this.notifyError({
@@ -287,6 +400,91 @@ export class WebSocketClient {
     }
   }
 
+  // Notifies listeners and handles a message, skipping journaled broadcasts
+  // that were already handled through a replay
+  private dispatchMessage(message: WebSocketMessage): void {
+    if (message.mapSeq) {
+      if (message.mapSeq <= this.lastMapSeq) {
+        return;
+      }
+      this.lastMapSeq = message.mapSeq;
+    }
+
+    this.notifyMessage(message);
+    this.processMessage(message);
+  }
+
+  // Requests the missed broadcasts when one was dropped, e.g. because the
+  // server dropped it for a slow connection. Missed default lane broadcasts are
+  // replayed from the map's journal if possible; otherwise the server answers
+  // with a snapshot of the map.
+  private checkSequence(message: WebSocketMessage): void {
+    if (!message.seq || !message.lane) {
+      return;
+    }
+
+    const last = this.lastSequences[message.lane];
+    this.lastSequences[message.lane] = message.seq;
+    if (last === undefined || message.seq === last + 1) {
+      return;
+    }
+
+    console.warn('⚠️ WebSocket: Missed broadcasts, requesting resync', {
+      lane: message.lane,
+      expected: last + 1,
+      received: message.seq,
+      lastMapSeq: this.lastMapSeq
+    });
+
+    if (message.lane === 'default' && this.lastMapSeq > 0) {
+      if (this.awaitingReplaySince === null) {
+        this.awaitingReplaySince = Date.now();
+        this.send({
+          type: 'resync_from',
+          data: { seq: this.lastMapSeq },
+          timestamp: new Date()
+        });
+      }
+      return;
+    }
+
+    this.send({
+      type: 'resync',
+      data: {},
+      timestamp: new Date()
+    });
+  }
+
+  // Holds back journaled broadcasts while a replay is pending, so missed
+  // events are applied before newer ones. Gives up with a snapshot request if
+  // the replay doesn't arrive in time.
+  private deferUntilReplayed(message: WebSocketMessage): boolean {
+    if (this.awaitingReplaySince === null || !message.mapSeq) {
+      return false;
+    }
+
+    if (Date.now() - this.awaitingReplaySince < REPLAY_TIMEOUT_MS) {
+      this.replayBuffer.push(message);
+      return true;
+    }
+
+    console.warn('⚠️ WebSocket: Event replay timed out, requesting resync');
+    this.flushReplayBuffer();
+    this.send({
+      type: 'resync',
+      data: {},
+      timestamp: new Date()
+    });
+    return false;
+  }
+
+  private flushReplayBuffer(): void {
+    const buffered = this.replayBuffer;
+    this.awaitingReplaySince = null;
+    this.replayBuffer = [];
+    buffered.forEach(message => this.dispatchMessage(message));
+  }
+
   private processMessage(message: WebSocketMessage): void {
     console.log('🔄 WebSocket: Processing message', {
       type: message.type,
@@ -317,12 +515,36 @@ export class WebSocketClient {
       case 'avatar_moved':
         this.handleAvatarMoved(message.data);
         break;
+      case 'avatar_positions_batch':
+        this.handleAvatarPositionsBatch(message.data);
+        break;
+      case 'viewport_state':
+        this.handleViewportState(message.data);
+        break;
       case 'user_joined':
         this.handleUserJoined(message.data);
         break;
       case 'user_left':
         this.handleUserLeft(message.data);
         break;
+      case 'user_profile_updated':
+        this.handleUserProfileUpdated(message.data);
+        break;
+      case 'role_changed':
+        this.handleRoleChanged(message.data);
+        break;
+      case 'onboarding_progress':
+        this.handleOnboardingProgress(message.data);
+        break;
+      case 'client_lagging':
+        this.handleClientLagging(message.data);
+        break;
+      case 'initial_state':
+        this.handleInitialState(message.data);
+        break;
+      case 'event_replay':
+        this.handleEventReplay(message.data);
+        break;
       case 'initial_users':
         this.handleInitialUsers(message.data);
         break;
@@ -350,6 +572,24 @@ export class WebSocketClient {
       case 'poi_updated':
         this.handlePOIUpdated(message.data);
         break;
+      case 'poi_deleted':
+        this.handlePOIDeleted(message.data);
+        break;
+      case 'poi_removed_you':
+        this.handlePOIRemovedYou(message.data);
+        break;
+      case 'poi_participant_added':
+        this.handlePOIParticipantDelta('added', message.data);
+        break;
+      case 'poi_participant_removed':
+        this.handlePOIParticipantDelta('removed', message.data);
+        break;
+      case 'map_presence':
+        this.handleMapPresence(message.data);
+        break;
+      case 'poi_roster_sync':
+        this.handlePOIRosterSync(message.data);
+        break;
       case 'call_request':
         this.handleCallRequest(message.data);
         break;
@@ -371,6 +611,9 @@ export class WebSocketClient {
       case 'ice_candidate':
         this.handleICECandidate(message.data);
         break;
+      case 'poi_active_speaker':
+        this.handlePOIActiveSpeaker(message.data);
+        break;
       case 'poi_call_offer':
         this.handlePOICallOffer(message.data);
         break;
@@ -383,6 +626,27 @@ export class WebSocketClient {
       case 'user_call_status':
         this.handleUserCallStatus(message.data);
         break;
+      case 'rate_limit_warning':
+        this.handleRateLimitWarning(message.data);
+        break;
+      case 'upgrade_required':
+        this.handleUpgradeRequired(message.data);
+        break;
+      case 'protocol_mismatch':
+        this.handleProtocolMismatch(message.data);
+        break;
+      case 'server_shutdown':
+        this.handleServerShutdown(message.data);
+        break;
+      case 'poi_reminder':
+        this.handlePOIReminder(message.data);
+        break;
+      case 'map_frozen':
+        this.handleMapFrozen(message.data);
+        break;
+      case 'map_unfrozen':
+        this.handleMapUnfrozen();
+        break;
       default:
         console.log('❓ WebSocket: Unknown message type', message.type);
         break;
@@ -392,11 +656,33 @@ export class WebSocketClient {
   // Store Integration Handlers
   private handleWelcome(data: any): void {
     console.log('🎉 WebSocket: Welcome message received', data);
-    // Welcome message - connection established successfully
+    // Map sequence numbers of another server process can't be compared
+    this.protocolVersion = data?.protocolVersion ?? PROTOCOL_VERSION;
+    const epoch = data?.journalEpoch ?? null;
+    if (epoch !== this.journalEpoch) {
+      this.lastMapSeq = 0;
+      this.journalEpoch = epoch;
+    }
+  }
+
+  private resumeUrl(): string {
+    const separator = this.url.includes('?') ? '&' : '?';
+    return `${this.url}${separator}lastSeq=${this.lastMapSeq}&epoch=${encodeURIComponent(this.journalEpoch ?? '')}`;
   }
 
   private handleError(data: any): void {
     console.error('❌ WebSocket: Server error', data);
+    if (data.code === 'RATE_LIMIT_EXCEEDED' && data.bucket) {
+      // Hold back further messages of the same feature until the server accepts them again
+      this.rateLimitedUntil[data.bucket] = Date.now() + (data.retryAfter ?? 0) * 1000;
+      if (data.messageType) {
+        this.messageBuckets[data.messageType] = data.bucket;
+      }
+    }
+    if (data.code === 'MAP_FROZEN' && data.messageType === 'avatar_move') {
+      // The move was not applied, so snap back to the last confirmed position
+      sessionStore.getState().rollbackAvatarPosition();
+    }
     this.notifyError({
       message: data.message || 'Server error',
       timestamp: new Date()
@@ -411,6 +697,75 @@ export class WebSocketClient {
     }
   }
 
+  private handleRateLimitWarning(data: any): void {
+    // Informational only: the request was processed, but the client is close to the limit
+    console.warn('⚠️ WebSocket: Approaching rate limit', {
+      action: data.action,
+      remaining: data.remaining,
+      retryAfter: data.retryAfter
+    });
+  }
+
+  private handleServerShutdown(data: any): void {
+    // The server closes the connection once its queued messages are sent
+    this.shutdownReconnectAfterMs = data?.reconnectAfterMs ?? 0;
+    console.warn('🔌 WebSocket: Server shutting down', { reconnectAfterMs: this.shutdownReconnectAfterMs });
+  }
+
+  private handleUpgradeRequired(data: any): void {
+    console.error('⛔ WebSocket: Client upgrade required', {
+      clientVersion: data.clientVersion,
+      minClientVersion: data.minClientVersion
+    });
+    this.notifyError({
+      message: 'A newer version of BreakoutGlobe is available. Please reload the page.',
+      code: UPGRADE_REQUIRED_CLOSE_CODE,
+      timestamp: new Date()
+    });
+  }
+
+  private handleProtocolMismatch(data: any): void {
+    console.error('⛔ WebSocket: Protocol version not supported by the server', {
+      requestedVersion: data.requestedVersion,
+      supportedVersions: data.supportedVersions
+    });
+    this.notifyError({
+      message: 'A newer version of BreakoutGlobe is available. Please reload the page.',
+      code: PROTOCOL_MISMATCH_CLOSE_CODE,
+      timestamp: new Date()
+    });
+  }
+
+  private handlePOIReminder(data: any): void {
+    // Sent shortly before a scheduled POI the user RSVP'd to starts
+    const minutes = Math.max(0, Math.round((new Date(data.startsAt).getTime() - Date.now()) / 60000));
+    toastStore.getState().addToast({
+      message: minutes > 0 ? `${data.name} starts in ${minutes} min` : `${data.name} is starting`,
+      type: 'info',
+      duration: 10000
+    });
+  }
+
+  private handleMapFrozen(data: any): void {
+    // Moves and POI joins are rejected until a facilitator unfreezes the map
+    const until = data.until ? new Date(data.until) : null;
+    toastStore.getState().addToast({
+      message: until
+        ? `A facilitator froze the map until ${until.toLocaleTimeString([], { hour: '2-digit', minute: '2-digit' })}`
+        : 'A facilitator froze the map',
+      type: 'info',
+      duration: 10000
+    });
+  }
+
+  private handleMapUnfrozen(): void {
+    toastStore.getState().addToast({
+      message: 'The map is no longer frozen',
+      type: 'info',
+      duration: 5000
+    });
+  }
+
   private handleAvatarUpdate(data: any): void {
     if (data.sessionId === this.sessionId) {
       sessionStore.getState().confirmAvatarPosition(data.position);
@@ -517,14 +872,12 @@ export class WebSocketClient {
   private handlePOIJoined(data: any): void {
     console.log('👥 WebSocket: User joined POI', data);
 
-    // Update participant count for the POI
+    // Rosters are kept up to date by participant deltas; this message only
+    // carries the joining user
     const poiId = data.poiId;
     const userId = data.userId;
     const currentCount = data.currentCount;
-    const participants = data.participants || [];
-
-    // Update the POI in the store with participant information
-    poiStore.getState().updatePOIParticipants(poiId, currentCount, participants);
+    const participants = poiStore.getState().getPOIById(poiId)?.participants || [];
 
     // Hide the avatar for the user who joined the POI
     if (userId) {
@@ -543,10 +896,10 @@ export class WebSocketClient {
       // Emit event for group call coordination (event-driven approach)
       // The video call store will listen and handle based on its state
       // Try to get participant info from multiple sources:
-      // 1. joiningUser field (most reliable - always populated by backend)
+      // 1. participant field (most reliable - always populated by backend)
       // 2. participants array (may be empty due to timing)
       // 3. avatar store (fallback for existing users)
-      const joiningUser = (data as any).joiningUser;
+      const joiningUser = data.participant;
       const participantInfo = participants.find((p: any) => p.id === userId);
       
       let displayName = joiningUser?.name || participantInfo?.name;
@@ -582,14 +935,12 @@ export class WebSocketClient {
   private handlePOILeft(data: any): void {
     console.log('👋 WebSocket: User left POI', data);
 
-    // Update participant count for the POI
+    // Rosters are kept up to date by participant deltas; this message only
+    // carries the leaving user
     const poiId = data.poiId;
     const userId = data.userId;
     const currentCount = data.currentCount;
-    const participants = data.participants || [];
-
-    // Update the POI in the store with participant information
-    poiStore.getState().updatePOIParticipants(poiId, currentCount, participants);
+    const participants = poiStore.getState().getPOIById(poiId)?.participants || [];
 
     // Show the avatar for the user who left the POI
     if (userId) {
@@ -623,6 +974,69 @@ export class WebSocketClient {
     });
   }
 
+  private handlePOIDeleted(data: any): void {
+    console.log('🗑️ WebSocket: POI deleted', data);
+
+    poiStore.getState().handleRealtimeDelete(data.poiId);
+
+    this.notifyStateSync({
+      type: 'poi',
+      data: { action: 'deleted', poiId: data.poiId },
+      timestamp: new Date()
+    });
+  }
+
+  // Sent only to the users who were inside a POI when it was deleted, so they
+  // exit the POI view and group call immediately
+  private handlePOIRemovedYou(data: any): void {
+    console.log('🚪 WebSocket: Removed from POI', data);
+
+    const videoStore = videoCallStore.getState();
+    if (videoStore.isGroupCallActive && videoStore.currentPOI === data.poiId) {
+      videoStore.leavePOICall();
+    }
+
+    poiStore.getState().handleRealtimeDelete(data.poiId);
+
+    this.notifyStateSync({
+      type: 'poi',
+      data: { action: 'removed_you', poiId: data.poiId, reason: data.reason },
+      timestamp: new Date()
+    });
+  }
+
+  private handlePOIParticipantDelta(action: 'added' | 'removed', data: any): void {
+    const applied = poiStore.getState().applyParticipantDelta({
+      poiId: data.poiId,
+      userId: data.userId,
+      action,
+      participant: data.participant,
+      currentCount: data.currentCount,
+      seq: data.seq || 0
+    });
+    if (!applied) {
+      console.log('⏭️ WebSocket: Ignoring stale POI participant delta', data);
+    }
+  }
+
+  private handlePOIRosterSync(data: any): void {
+    for (const roster of data.rosters || []) {
+      poiStore.getState().applyRosterSnapshot(
+        roster.poiId,
+        roster.currentCount,
+        roster.participants || [],
+        roster.seq || 0
+      );
+    }
+  }
+
+  private handlePOIActiveSpeaker(data: any): void {
+    console.log('🗣️ WebSocket: POI active speaker changed', data);
+    poiStore.getState().updatePOI(data.poiId, {
+      activeSpeakerId: data.speaking ? data.userId : null
+    });
+  }
+
   private handlePOIUpdated(data: any): void {
     console.log('📝 WebSocket: POI updated', data);
 
@@ -635,7 +1049,8 @@ export class WebSocketClient {
       createdBy: '', // Not included in update events
       maxParticipants: data.maxParticipants,
       participantCount: data.currentCount || 0,
-      participants: []
+      participants: [],
+      startsAt: data.startsAt ? new Date(data.startsAt) : null
     };
 
     // Update the POI in the store
@@ -817,6 +1232,26 @@ export class WebSocketClient {
     }
   }
 
+  // Applies the latest position of every avatar that moved since the last batch
+  private handleAvatarPositionsBatch(data: any): void {
+    const positions: any[] = data?.positions || [];
+    positions.forEach(position => this.handleAvatarMoved(position));
+  }
+
+  // Catches up on the avatars and POIs of an area the map was panned to; their
+  // moves and creations are only sent while they are in the viewport
+  private handleViewportState(data: any): void {
+    const positions: any[] = data?.positions || [];
+    positions.forEach(position => this.handleAvatarMoved(position));
+
+    const pois: any[] = data?.pois || [];
+    pois.forEach(poi => {
+      if (!poiStore.getState().getPOIById(poi.id)) {
+        poiStore.getState().addPOI(transformFromPOIResponse(poi));
+      }
+    });
+  }
+
   private handleUserJoined(data: any): void {
     console.log('👋 WebSocket: Received user_joined', data);
     // Handle new user joining the map
@@ -830,7 +1265,8 @@ export class WebSocketClient {
         position: data.position || { lat: 0, lng: 0 },
         isCurrentUser: false,
         isMoving: false,
-        role: data.role || 'user'
+        role: data.role || 'user',
+        currentPoiId: data.currentPoiId ?? null
       };
 
       console.log('➕ WebSocket: Adding new user avatar', avatarData);
@@ -846,6 +1282,80 @@ export class WebSocketClient {
     }
   }
 
+  private handleUserProfileUpdated(data: any): void {
+    console.log('👤 WebSocket: User profile updated', data);
+    avatarStore.getState().updateAvatarProfile(data.userId, {
+      displayName: data.displayName || data.userId,
+      avatarURL: data.avatarURL ?? undefined,
+      aboutMe: data.aboutMe ?? undefined
+    });
+
+    this.notifyStateSync({
+      type: 'avatar',
+      data: { action: 'profile_updated', userId: data.userId },
+      timestamp: new Date()
+    });
+  }
+
+  private handleOnboardingProgress(data: any): void {
+    console.log('🎓 WebSocket: Onboarding progress', data);
+
+    // Only our own progress is sent to us; keep the checklist of every tab in step
+    const authUser = authStore.getState().user;
+    if (authUser?.id === data.userId) {
+      authStore.getState().setUser({ ...authUser, onboarding: data.progress });
+    }
+    if (data.progress?.complete) {
+      toastStore.getState().addToast({
+        message: 'Welcome checklist complete!',
+        type: 'success',
+        duration: 5000
+      });
+    }
+  }
+
+  private handleClientLagging(data: any): void {
+    // The server can't keep up sending to this browser and may drop updates
+    console.warn('🐢 WebSocket: Client lagging behind broadcasts', data);
+
+    const now = Date.now();
+    if (now - this.laggingNotifiedAt < LAGGING_TOAST_INTERVAL_MS) {
+      return;
+    }
+    this.laggingNotifiedAt = now;
+    toastStore.getState().addToast({
+      message: 'Your connection is falling behind, some updates may be skipped',
+      type: 'warning',
+      duration: 5000
+    });
+  }
+
+  private handleRoleChanged(data: any): void {
+    console.log('🛡️ WebSocket: User role changed', data);
+    avatarStore.getState().updateAvatarProfile(data.userId, { role: data.role });
+
+    // Our own role changed: show or hide facilitator controls without reconnecting
+    const currentProfile = userProfileStore.getState().getProfileOffline();
+    if (currentProfile?.id === data.userId) {
+      userProfileStore.getState().setProfile({ ...currentProfile, role: data.role });
+      const authUser = authStore.getState().user;
+      if (authUser?.id === data.userId) {
+        authStore.getState().setUser({ ...authUser, role: data.role });
+      }
+      toastStore.getState().addToast({
+        message: data.role === 'user' ? 'You are no longer a facilitator' : 'You are now a facilitator',
+        type: 'info',
+        duration: 5000
+      });
+    }
+
+    this.notifyStateSync({
+      type: 'avatar',
+      data: { action: 'role_changed', userId: data.userId, role: data.role },
+      timestamp: new Date()
+    });
+  }
+
   private handleUserLeft(data: any): void {
     // Handle user leaving the map
     if (data.sessionId !== this.sessionId) {
@@ -859,6 +1369,25 @@ export class WebSocketClient {
     }
   }
 
+  // Reconciles the avatars with the server's presence snapshot or patch. Known
+  // avatars keep their live positions; only who is on the map is corrected.
+  private handleMapPresence(data: any): void {
+    const isKnown = (user: any) => avatarStore.getState().getAvatarBySessionId(user.sessionId) !== undefined;
+    let joined: any[] = data.joined || [];
+    let left: any[] = data.left || [];
+
+    if (data.full) {
+      const users: any[] = data.users || [];
+      const present = new Set(users.map(user => user.sessionId));
+      joined = users;
+      left = avatarStore.getState().getOtherUsersAvatars()
+        .filter(avatar => !present.has(avatar.sessionId));
+    }
+
+    joined.filter(user => !isKnown(user)).forEach(user => this.handleUserJoined(user));
+    left.forEach(user => this.handleUserLeft(user));
+  }
+
   private handleInitialUsers(data: any): void {
     console.log('📋 WebSocket: Received initial_users', data);
     // Handle initial user state when joining a map
@@ -874,7 +1403,8 @@ export class WebSocketClient {
           position: user.position || { lat: 0, lng: 0 },
           isCurrentUser: false,
           isMoving: false,
-          role: user.role || 'user'
+          role: user.role || 'user',
+          currentPoiId: user.currentPoiId ?? null
         }));
 
       console.log('📥 WebSocket: Loading initial users', otherUsers);
@@ -888,6 +1418,43 @@ export class WebSocketClient {
     }
   }
 
+  // Replaces users, POIs and rosters with the snapshot sent after a resync
+  private handleInitialState(data: any): void {
+    console.log('🔄 WebSocket: Received initial_state', data);
+    // The snapshot already contains the broadcasts held back for a replay
+    this.replayBuffer.forEach(message => {
+      this.lastMapSeq = Math.max(this.lastMapSeq, message.mapSeq || 0);
+    });
+    this.awaitingReplaySince = null;
+    this.replayBuffer = [];
+    this.handleInitialUsers({ users: data.users || [] });
+    poiStore.getState().setPOIs((data.pois || []).map(transformFromPOIResponse));
+    this.handlePOIRosterSync({ rosters: data.rosters || [] });
+  }
+
+  // Applies the journaled broadcasts missed since resync_from, then the ones
+  // held back while waiting for them
+  private handleEventReplay(data: any): void {
+    const events: { mapSeq: number; type: string; data: any; timestamp: string }[] = data.events || [];
+    console.log('🔄 WebSocket: Replaying missed broadcasts', { count: events.length });
+    events.forEach(event => this.dispatchMessage({
+      type: event.type,
+      data: event.data,
+      timestamp: new Date(event.timestamp),
+      mapSeq: event.mapSeq
+    }));
+    this.flushReplayBuffer();
+  }
+
+  // Limits avatar moves and new POIs to the displayed area of the map
+  updateViewport(bounds: { north: number; south: number; east: number; west: number }): void {
+    this.send({
+      type: 'viewport_update',
+      data: bounds,
+      timestamp: new Date()
+    });
+  }
+
   // Request initial users when connecting
   requestInitialUsers(): void {
     console.log('📋 WebSocket: Requesting initial users');
@@ -1019,6 +1586,18 @@ export class WebSocketClient {
     });
   }
 
+  // Reports whether the local user is speaking in a POI call, so the map can show active discussions
+  sendSpeakingState(poiId: string, speaking: boolean): void {
+    this.send({
+      type: 'speaking_state',
+      data: {
+        poiId,
+        speaking
+      },
+      timestamp: new Date()
+    });
+  }
+
   sendPOICallICECandidate(poiId: string, targetUserId: string, candidate: RTCIceCandidate): void {
     console.log('🧊 WebSocket: Sending POI call ICE candidate', { poiId, targetUserId });
     this.send({
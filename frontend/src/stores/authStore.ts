@@ -1,4 +1,5 @@
 import { create } from 'zustand';
+import type { OnboardingProgress, UserPreferences } from '../services/api';
 
 export interface UserProfile {
   id: string;
@@ -9,6 +10,9 @@ export interface UserProfile {
   avatarUrl?: string;
   aboutMe?: string;
   createdAt: string;
+  // Only included by /api/auth/me
+  preferences?: UserPreferences;
+  onboarding?: OnboardingProgress;
 }
 
 export interface AuthState {
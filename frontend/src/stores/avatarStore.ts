@@ -12,6 +12,7 @@ interface AvatarState {
   removeAvatar: (sessionId: string) => void;
   updateAvatarPosition: (sessionId: string, position: { lat: number; lng: number }, isMoving: boolean) => void;
   updateAvatarCallStatus: (userId: string, isInCall: boolean) => void;
+  updateAvatarProfile: (userId: string, profile: Partial<Pick<AvatarData, 'displayName' | 'avatarURL' | 'aboutMe' | 'role'>>) => void;
   loadInitialUsers: (users: AvatarData[]) => void;
   setCurrentMap: (mapId: string | null) => void;
   clearAllAvatars: () => void;
@@ -98,6 +99,23 @@ export const avatarStore = create<AvatarState>((set, get) => ({
     });
   },
   
+  updateAvatarProfile: (userId: string, profile: Partial<Pick<AvatarData, 'displayName' | 'avatarURL' | 'aboutMe' | 'role'>>) => {
+    set((state) => {
+      const newAvatars = new Map(state.avatars);
+      let updated = false;
+      
+      // A user may be connected with several sessions
+      for (const [sessionId, avatar] of state.avatars) {
+        if (avatar.userId === userId) {
+          newAvatars.set(sessionId, { ...avatar, ...profile });
+          updated = true;
+        }
+      }
+      
+      return updated ? { avatars: newAvatars } : state;
+    });
+  },
+  
   loadInitialUsers: (users: AvatarData[]) => {
     set(() => {
       const newAvatars = new Map<string, AvatarData>();
@@ -408,6 +408,55 @@ describe('poiStore', () => {
       const state = poiStore.getState();
       expect(state.pois).toHaveLength(0);
     });
+
+    it('should apply participant deltas in sequence order', () => {
+      poiStore.getState().addPOI({ ...mockPOI, participants: [] });
+
+      const added = poiStore.getState().applyParticipantDelta({
+        poiId: mockPOI.id,
+        userId: 'user-1',
+        action: 'added',
+        participant: { id: 'user-1', name: 'User 1' },
+        currentCount: 1,
+        seq: 2
+      });
+      // A duplicate of an earlier change is ignored
+      const stale = poiStore.getState().applyParticipantDelta({
+        poiId: mockPOI.id,
+        userId: 'user-1',
+        action: 'removed',
+        currentCount: 0,
+        seq: 1
+      });
+
+      expect(added).toBe(true);
+      expect(stale).toBe(false);
+      const poi = poiStore.getState().getPOIById(mockPOI.id);
+      expect(poi?.participantCount).toBe(1);
+      expect(poi?.participants?.map(p => p.id)).toEqual(['user-1']);
+    });
+
+    it('should replace the roster from a newer snapshot only', () => {
+      poiStore.getState().addPOI({ ...mockPOI, participants: [] });
+      poiStore.getState().applyParticipantDelta({
+        poiId: mockPOI.id,
+        userId: 'user-1',
+        action: 'added',
+        participant: { id: 'user-1', name: 'User 1' },
+        currentCount: 1,
+        seq: 5
+      });
+
+      expect(poiStore.getState().applyRosterSnapshot(mockPOI.id, 0, [], 4)).toBe(false);
+      expect(poiStore.getState().applyRosterSnapshot(mockPOI.id, 2, [
+        { id: 'user-1', name: 'User 1' },
+        { id: 'user-2', name: 'User 2' }
+      ], 6)).toBe(true);
+
+      const poi = poiStore.getState().getPOIById(mockPOI.id);
+      expect(poi?.participantCount).toBe(2);
+      expect(poiStore.getState().rosterSequences[mockPOI.id]).toBe(6);
+    });
   });
 
   describe('Discussion Timer', () => {
@@ -14,6 +14,9 @@ export interface POIState {
   // Optimistic update tracking
   optimisticOperations: Map<string, 'create' | 'join' | 'leave'>;
 
+  // Last roster sequence applied per POI, used to drop stale participant deltas
+  rosterSequences: Record<string, number>;
+
   // Actions
   addPOI: (poi: POIData) => void;
   updatePOI: (id: string, updates: Partial<POIData>) => void;
@@ -49,6 +52,8 @@ export interface POIState {
   handleRealtimeDelete: (poiId: string) => void;
   updatePOIParticipantCount: (poiId: string, count: number) => void;
   updatePOIParticipants: (poiId: string, count: number, participants: any[]) => void;
+  applyParticipantDelta: (delta: POIParticipantDelta) => boolean;
+  applyRosterSnapshot: (poiId: string, count: number, participants: any[], seq: number) => boolean;
 
   // Discussion timer methods
   updateDiscussionTimer: (poiId: string, duration: number) => void;
@@ -58,12 +63,22 @@ export interface POIState {
   reset: () => void;
 }
 
+export interface POIParticipantDelta {
+  poiId: string;
+  userId: string;
+  action: 'added' | 'removed';
+  participant?: { id: string; name: string; avatarUrl?: string | null };
+  currentCount: number;
+  seq: number; // 0 when the backend could not number the change
+}
+
 const initialState = {
   pois: [],
   isLoading: false,
   error: null,
   currentUserPOI: null,
   optimisticOperations: new Map<string, 'create' | 'join' | 'leave'>(),
+  rosterSequences: {} as Record<string, number>,
 };
 
 export const poiStore = create<POIState>()(
@@ -345,6 +360,7 @@ export const poiStore = create<POIState>()(
       handleRealtimeDelete: (poiId: string) => {
         set((state) => ({
           pois: state.pois.filter(poi => poi.id !== poiId),
+          currentUserPOI: state.currentUserPOI === poiId ? null : state.currentUserPOI,
         }));
       },
 
@@ -376,6 +392,49 @@ export const poiStore = create<POIState>()(
         }));
       },
 
+      applyParticipantDelta: (delta: POIParticipantDelta) => {
+        const lastSeq = get().rosterSequences[delta.poiId] ?? 0;
+        // Already covered by an earlier delta or roster snapshot
+        if (delta.seq > 0 && delta.seq <= lastSeq) {
+          return false;
+        }
+
+        set((state) => ({
+          pois: state.pois.map(poi => {
+            if (poi.id !== delta.poiId) {
+              return poi;
+            }
+            const others = (poi.participants || []).filter(p => p.id !== delta.userId);
+            const participants = delta.action === 'added' && delta.participant
+              ? [...others, {
+                id: delta.participant.id,
+                name: delta.participant.name,
+                avatarUrl: delta.participant.avatarUrl ?? undefined
+              }]
+              : others;
+            return { ...poi, participantCount: delta.currentCount, participants };
+          }),
+          rosterSequences: delta.seq > 0
+            ? { ...state.rosterSequences, [delta.poiId]: delta.seq }
+            : state.rosterSequences,
+        }));
+        return true;
+      },
+
+      applyRosterSnapshot: (poiId: string, count: number, participants: any[], seq: number) => {
+        const lastSeq = get().rosterSequences[poiId] ?? 0;
+        // A delta newer than this snapshot has already been applied
+        if (seq < lastSeq) {
+          return false;
+        }
+
+        get().updatePOIParticipants(poiId, count, participants);
+        set((state) => ({
+          rosterSequences: { ...state.rosterSequences, [poiId]: seq },
+        }));
+        return true;
+      },
+
       // Discussion timer methods
       updateDiscussionTimer: (poiId: string, duration: number) => {
         set((state) => ({
@@ -405,6 +464,7 @@ export const poiStore = create<POIState>()(
           error: null,
           currentUserPOI: null,
           optimisticOperations: new Map(),
+          rosterSequences: {},
         });
       },
     }),
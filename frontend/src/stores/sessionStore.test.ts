@@ -34,10 +34,11 @@ describe('sessionStore', () => {
       const sessionId = 'test-session-123';
       const initialPosition = { lat: 40.7128, lng: -74.0060 };
       
-      sessionStore.getState().createSession(sessionId, initialPosition);
+      sessionStore.getState().createSession(sessionId, 'session-token', initialPosition);
       
       const state = sessionStore.getState();
       expect(state.sessionId).toBe(sessionId);
+      expect(state.sessionToken).toBe('session-token');
       expect(state.avatarPosition).toEqual(initialPosition);
       expect(state.isConnected).toBe(true);
       expect(state.lastHeartbeat).toBeInstanceOf(Date);
@@ -48,7 +49,7 @@ describe('sessionStore', () => {
       const initialPosition = { lat: 40.7128, lng: -74.0060 };
       const newPosition = { lat: 51.5074, lng: -0.1278 };
       
-      sessionStore.getState().createSession(sessionId, initialPosition);
+      sessionStore.getState().createSession(sessionId, 'session-token', initialPosition);
       sessionStore.getState().updateAvatarPosition(newPosition);
       
       const state = sessionStore.getState();
@@ -79,7 +80,7 @@ describe('sessionStore', () => {
       const sessionId = 'test-session-123';
       const initialPosition = { lat: 40.7128, lng: -74.0060 };
       
-      sessionStore.getState().createSession(sessionId, initialPosition);
+      sessionStore.getState().createSession(sessionId, 'session-token', initialPosition);
       sessionStore.getState().disconnect();
       
       const state = sessionStore.getState();
@@ -91,7 +92,7 @@ describe('sessionStore', () => {
       const sessionId = 'test-session-123';
       const initialPosition = { lat: 40.7128, lng: -74.0060 };
       
-      sessionStore.getState().createSession(sessionId, initialPosition);
+      sessionStore.getState().createSession(sessionId, 'session-token', initialPosition);
       sessionStore.getState().reset();
       
       const state = sessionStore.getState();
@@ -114,7 +115,7 @@ describe('sessionStore', () => {
       const sessionId = 'test-session-123';
       const initialPosition = { lat: 40.7128, lng: -74.0060 };
       
-      sessionStore.getState().createSession(sessionId, initialPosition);
+      sessionStore.getState().createSession(sessionId, 'session-token', initialPosition);
       
       // Test that partialize function includes the right fields
       const options = sessionStore.persist.getOptions();
@@ -133,7 +134,7 @@ describe('sessionStore', () => {
       const sessionId = 'test-session-123';
       const initialPosition = { lat: 40.7128, lng: -74.0060 };
       
-      sessionStore.getState().createSession(sessionId, initialPosition);
+      sessionStore.getState().createSession(sessionId, 'session-token', initialPosition);
       sessionStore.getState().reset();
       
       // After reset, state should be back to initial
@@ -149,7 +150,7 @@ describe('sessionStore', () => {
       const initialPosition = { lat: 40.7128, lng: -74.0060 };
       const newPosition = { lat: 51.5074, lng: -0.1278 };
       
-      sessionStore.getState().createSession(sessionId, initialPosition);
+      sessionStore.getState().createSession(sessionId, 'session-token', initialPosition);
       
       // Optimistic update should be immediate
       sessionStore.getState().updateAvatarPosition(newPosition, true);
@@ -164,7 +165,7 @@ describe('sessionStore', () => {
       const initialPosition = { lat: 40.7128, lng: -74.0060 };
       const newPosition = { lat: 51.5074, lng: -0.1278 };
       
-      sessionStore.getState().createSession(sessionId, initialPosition);
+      sessionStore.getState().createSession(sessionId, 'session-token', initialPosition);
       sessionStore.getState().updateAvatarPosition(newPosition, true);
       
       // Server rejects the update
@@ -180,7 +181,7 @@ describe('sessionStore', () => {
       const initialPosition = { lat: 40.7128, lng: -74.0060 };
       const newPosition = { lat: 51.5074, lng: -0.1278 };
       
-      sessionStore.getState().createSession(sessionId, initialPosition);
+      sessionStore.getState().createSession(sessionId, 'session-token', initialPosition);
       sessionStore.getState().updateAvatarPosition(newPosition, true);
       
       // Server confirms the update
@@ -9,6 +9,8 @@ export interface Position {
 export interface SessionState {
   // Session data
   sessionId: string | null;
+  // Proves ownership of the session, since session IDs are visible to the map
+  sessionToken: string | null;
   isConnected: boolean;
   avatarPosition: Position;
   isMoving: boolean;
@@ -18,7 +20,7 @@ export interface SessionState {
   previousPosition: Position | null;
   
   // Actions
-  createSession: (sessionId: string, initialPosition: Position) => void;
+  createSession: (sessionId: string, sessionToken: string, initialPosition: Position) => void;
   updateAvatarPosition: (position: Position, optimistic?: boolean) => void;
   setMoving: (moving: boolean) => void;
   updateHeartbeat: () => void;
@@ -32,6 +34,7 @@ export interface SessionState {
 
 const initialState = {
   sessionId: null,
+  sessionToken: null,
   isConnected: false,
   avatarPosition: { lat: 52.5200, lng: 13.4050 }, // Berlin, Germany
   isMoving: false,
@@ -44,9 +47,10 @@ export const sessionStore = create<SessionState>()(
     (set, get) => ({
       ...initialState,
       
-      createSession: (sessionId: string, initialPosition: Position) => {
+      createSession: (sessionId: string, sessionToken: string, initialPosition: Position) => {
         set({
           sessionId,
+          sessionToken,
           avatarPosition: initialPosition,
           isConnected: true,
           lastHeartbeat: new Date(),
@@ -86,6 +90,7 @@ export const sessionStore = create<SessionState>()(
         set({
           isConnected: false,
           sessionId: null,
+          sessionToken: null,
         });
       },
       
@@ -116,6 +121,7 @@ export const sessionStore = create<SessionState>()(
       name: 'breakout-globe-session',
       partialize: (state) => ({
         sessionId: state.sessionId,
+        sessionToken: state.sessionToken,
         avatarPosition: state.avatarPosition,
         isConnected: state.isConnected,
         isMoving: state.isMoving,
@@ -45,6 +45,7 @@ describe.skip('Session Flow Integration', () => {
         if (key === 'breakoutglobe-session') {
           return JSON.stringify({
             sessionId: 'test-session-123',
+            sessionToken: 'test-session-token',
             position: { lat: 40.7128, lng: -74.0060 }
           });
         }
@@ -73,6 +74,7 @@ describe.skip('Session Flow Integration', () => {
           ok: true,
           json: async () => ({
             sessionId: 'test-session-123',
+            sessionToken: 'test-session-token',
             position: { lat: 40.7128, lng: -74.0060 }
           })
         });
@@ -225,6 +227,7 @@ describe.skip('Session Flow Integration', () => {
     // Mock existing session in localStorage
     mockLocalStorage.getItem.mockReturnValue(JSON.stringify({
       sessionId: 'existing-session-456',
+      sessionToken: 'existing-session-token',
       position: { lat: 51.5074, lng: -0.1278 }
     }));
 